@@ -9,9 +9,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"decred.org/dcrdex/client/core"
+	"decred.org/dcrdex/client/db"
 	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/client/rpcserver"
 	"decred.org/dcrdex/client/webserver"
@@ -47,6 +50,10 @@ type RPCConfig struct {
 	RPCPass string `long:"rpcpass" description:"RPC server password"`
 	RPCCert string `long:"rpccert" description:"RPC server certificate file location"`
 	RPCKey  string `long:"rpckey" description:"RPC server key file location"`
+	// TLS settings for the RPC server listener.
+	RPCTLSMinVersion   string   `long:"rpctlsminversion" description:"Minimum TLS version accepted by the RPC server, 1.2 or 1.3. Default is 1.2."`
+	RPCTLSCipherSuites []string `long:"rpctlsciphersuite" description:"Allowed TLS 1.2 cipher suite for the RPC server, e.g. TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384. May be specified multiple times. Default is Go's secure defaults."`
+	RPCClientCA        string   `long:"rpcclientca" description:"Path to a PEM file of CA certificates. If set, RPC clients must present a certificate signed by one of them."`
 	// CertHosts is a list of hosts given to certgen.NewTLSCertPair for the
 	// "Subject Alternate Name" values of the generated TLS certificate. It is
 	// set automatically, not via the config file or cli args.
@@ -85,6 +92,11 @@ func (cfg *RPCConfig) RPC(c *core.Core, marketMaker *mm.MarketMaker, log dex.Log
 		Cert:        cfg.RPCCert,
 		Key:         cfg.RPCKey,
 		BWVersion:   bwVersion,
+
+		TLSMinVersion:   cfg.RPCTLSMinVersion,
+		TLSCipherSuites: cfg.RPCTLSCipherSuites,
+		ClientCA:        cfg.RPCClientCA,
+
 		CertHosts: []string{
 			defaultTestnetHost, defaultSimnetHost, defaultMainnetHost,
 			walletPairOneHost, walletPairTwoHost,
@@ -105,6 +117,31 @@ type CoreConfig struct {
 	NoAutoDBBackup     bool `long:"no-db-backup" description:"Disable creation of a database backup on shutdown."`
 	UnlockCoinsOnLogin bool `long:"release-wallet-coins" description:"On login or wallet creation, instruct the wallet to release any coins that it may have locked."`
 
+	DBPruneAge time.Duration `long:"db-prune-age" description:"Delete completed orders and matches older than this from the database, at startup and daily. Active orders and matches, such as those awaiting a refund, are kept. Default is 0 (disabled)."`
+
+	ReconnectInterval    time.Duration `long:"reconnectinterval" description:"Initial wait between attempts to reconnect to a DEX server. The wait doubles after each failed attempt. Default is 5s."`
+	MaxReconnectInterval time.Duration `long:"maxreconnectinterval" description:"Maximum wait between attempts to reconnect to a DEX server. Default is 1m."`
+	MaxMessageSize       int64         `long:"maxmessagesize" description:"Maximum size in bytes of a message from a DEX server. Default is no limit."`
+
+	NotifyWebhook  string   `long:"notify-webhook" description:"URL to which notifications are POSTed as JSON."`
+	NotifySMTPHost string   `long:"notify-smtp-host" description:"SMTP server host:port for emailing notifications."`
+	NotifySMTPUser string   `long:"notify-smtp-user" description:"SMTP server username."`
+	NotifySMTPPass string   `long:"notify-smtp-pass" description:"SMTP server password."`
+	NotifySMTPFrom string   `long:"notify-smtp-from" description:"Email address that notifications are sent from."`
+	NotifySMTPTo   []string `long:"notify-smtp-to" description:"Email address that notifications are sent to. May be specified multiple times."`
+	NotifySeverity string   `long:"notify-severity" choice:"success" choice:"warning" choice:"error" description:"The lowest severity of notification that is delivered to the notify-webhook or by email. Default is warning."`
+	NotifyRedact   bool     `long:"notify-redact" description:"Omit notification details, which may include amounts, addresses, and order IDs, from delivered notifications."`
+
+	Faucet string `long:"faucet" description:"URL of a testnet faucet from which funds may be requested. Ignored on mainnet."`
+
+	ExplorerURLs []string `long:"explorerurl" description:"Block explorer URL template for an asset's transactions as symbol=template, overriding the asset's default. {txid} in the template is replaced with the transaction ID, e.g. btc=https://mempool.space/tx/{txid}. May be specified multiple times."`
+
+	RedeemConfs []string `long:"redeemconfs" description:"Confirmations to wait for on a counterparty's swap before redeeming it, as symbol=confs, e.g. btc=3. Counts that are not more than the server's required confirmations have no effect. May be specified multiple times."`
+
+	PriceOracle float64 `long:"priceoracle" description:"Threshold, in percent, of the divergence of a synced market's mid-gap price from the fiat reference price at which a warning notification is emitted. 0 disables the check."`
+
+	PriceBand float64 `long:"priceband" description:"Maximum deviation, in percent, of a limit order's rate from the market's mid-gap or fiat reference price. Orders outside of the band are rejected unless the trade overrides the band. 0 disables the check."`
+
 	ExtensionModeFile string `long:"extension-mode-file" description:"path to a file that specifies options for running core as an extension."`
 }
 
@@ -117,6 +154,25 @@ type WebConfig struct {
 	HTTPProfile bool   `long:"httpprof" description:"Start HTTP profiler on /pprof."`
 	// Deprecated
 	Experimental bool `long:"experimental" description:"DEPRECATED: Enable experimental features"`
+
+	// HTTPS settings. These require webtls or a publicly routable webaddr.
+	WebTLSMinVersion   string   `long:"webtlsminversion" description:"Minimum TLS version accepted by the web server, 1.2 or 1.3. Default is 1.2."`
+	WebTLSCipherSuites []string `long:"webtlsciphersuite" description:"Allowed TLS 1.2 cipher suite for the web server, e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256. May be specified multiple times. Default is Go's secure defaults."`
+	WebALPN            []string `long:"webalpn" description:"Application protocol offered by the web server, h2 or http/1.1, in order of preference. May be specified multiple times. Default is h2 and http/1.1, which enables HTTP/2."`
+	WebSNICerts        []string `long:"websnicert" description:"Additional web server certificate served to clients requesting a particular host via SNI, as host,certfile,keyfile. The host may begin with a *. wildcard label. May be specified multiple times."`
+}
+
+// parseSNICert parses a websnicert setting of the form host,certfile,keyfile.
+func parseSNICert(s string) (*webserver.SNICert, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("invalid websnicert %q, expected host,certfile,keyfile", s)
+	}
+	return &webserver.SNICert{
+		Host:     parts[0],
+		CertFile: dex.CleanAndExpandPath(parts[1]),
+		KeyFile:  dex.CleanAndExpandPath(parts[2]),
+	}, nil
 }
 
 // LogConfig encapsulates the logging-related settings.
@@ -182,6 +238,14 @@ func (cfg *Config) Web(c *core.Core, mm *mm.MarketMaker, log dex.Logger, utc boo
 		keyFile = filepath.Join(cfg.AppData, "web.key")
 	}
 
+	sniCerts := make([]*webserver.SNICert, 0, len(cfg.WebSNICerts))
+	for _, s := range cfg.WebSNICerts {
+		// Validated by ResolveConfig.
+		if sc, err := parseSNICert(s); err == nil {
+			sniCerts = append(sniCerts, sc)
+		}
+	}
+
 	return &webserver.Config{
 		Core:          c,
 		MarketMaker:   mmCore,
@@ -194,6 +258,11 @@ func (cfg *Config) Web(c *core.Core, mm *mm.MarketMaker, log dex.Logger, utc boo
 		NoEmbed:       cfg.NoEmbedSite,
 		HttpProf:      cfg.HTTPProfile,
 		Language:      cfg.Language,
+
+		TLSMinVersion:   cfg.WebTLSMinVersion,
+		TLSCipherSuites: cfg.WebTLSCipherSuites,
+		ALPN:            cfg.WebALPN,
+		SNICerts:        sniCerts,
 	}
 }
 
@@ -213,7 +282,129 @@ func (cfg *Config) Core(log dex.Logger) *core.Config {
 		NoAutoWalletLock:   cfg.NoAutoWalletLock,
 		NoAutoDBBackup:     cfg.NoAutoDBBackup,
 		ExtensionModeFile:  cfg.ExtensionModeFile,
+		DBPruneAge:         cfg.DBPruneAge,
+
+		ReconnectInterval:    cfg.ReconnectInterval,
+		MaxReconnectInterval: cfg.MaxReconnectInterval,
+		MaxMessageSize:       cfg.MaxMessageSize,
+
+		NoteDelivery: cfg.noteDelivery(),
+		Faucet:       cfg.faucet(),
+		ExplorerURLs: cfg.explorerURLs(),
+		RedeemConfs:  cfg.redeemConfs(),
+		PriceOracle:  cfg.priceOracle(),
+		PriceBand:    cfg.PriceBand,
+	}
+}
+
+// priceOracle is the core.PriceOracleConfig for the priceoracle setting, or
+// nil if the check is disabled.
+func (cfg *Config) priceOracle() *core.PriceOracleConfig {
+	if cfg.PriceOracle == 0 {
+		return nil
+	}
+	return &core.PriceOracleConfig{Threshold: cfg.PriceOracle / 100}
+}
+
+// redeemConfs creates the redemption confirmation counts by asset ID from the
+// redeemconfs settings.
+func (cfg *CoreConfig) redeemConfs() map[uint32]uint32 {
+	if len(cfg.RedeemConfs) == 0 {
+		return nil
+	}
+	confs := make(map[uint32]uint32, len(cfg.RedeemConfs))
+	for _, s := range cfg.RedeemConfs {
+		// Validated by ResolveConfig.
+		if assetID, n, err := parseRedeemConfs(s); err == nil {
+			confs[assetID] = n
+		}
+	}
+	return confs
+}
+
+// parseRedeemConfs parses a redeemconfs setting of the form symbol=confs.
+func parseRedeemConfs(s string) (uint32, uint32, error) {
+	symbol, confsStr, found := strings.Cut(s, "=")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid redeemconfs %q, expected symbol=confs", s)
+	}
+	assetID, found := dex.BipSymbolID(strings.ToLower(symbol))
+	if !found {
+		return 0, 0, fmt.Errorf("invalid redeemconfs %q, unknown asset %q", s, symbol)
+	}
+	confs, err := strconv.ParseUint(confsStr, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid redeemconfs %q, bad confirmation count: %w", s, err)
+	}
+	return assetID, uint32(confs), nil
+}
+
+// explorerURLs creates the block explorer URL templates by asset ID from the
+// explorerurl settings.
+func (cfg *CoreConfig) explorerURLs() map[uint32]string {
+	if len(cfg.ExplorerURLs) == 0 {
+		return nil
+	}
+	urls := make(map[uint32]string, len(cfg.ExplorerURLs))
+	for _, s := range cfg.ExplorerURLs {
+		// Validated by ResolveConfig.
+		if assetID, tmpl, err := parseExplorerURL(s); err == nil {
+			urls[assetID] = tmpl
+		}
+	}
+	return urls
+}
+
+// parseExplorerURL parses an explorerurl setting of the form symbol=template.
+func parseExplorerURL(s string) (uint32, string, error) {
+	symbol, tmpl, found := strings.Cut(s, "=")
+	if !found || tmpl == "" {
+		return 0, "", fmt.Errorf("invalid explorerurl %q, expected symbol=template", s)
+	}
+	assetID, found := dex.BipSymbolID(strings.ToLower(symbol))
+	if !found {
+		return 0, "", fmt.Errorf("invalid explorerurl %q, unknown asset %q", s, symbol)
+	}
+	return assetID, tmpl, nil
+}
+
+// faucet creates the core.FaucetConfig, or returns nil if a faucet is not
+// configured.
+func (cfg *CoreConfig) faucet() *core.FaucetConfig {
+	if cfg.Faucet == "" {
+		return nil
+	}
+	return &core.FaucetConfig{URL: cfg.Faucet}
+}
+
+// noteDelivery creates the core.NoteDeliveryConfig from the notify-* settings,
+// or returns nil if external notification delivery is not configured.
+func (cfg *CoreConfig) noteDelivery() *core.NoteDeliveryConfig {
+	if cfg.NotifyWebhook == "" && cfg.NotifySMTPHost == "" {
+		return nil
+	}
+	noteCfg := &core.NoteDeliveryConfig{
+		WebhookURL: cfg.NotifyWebhook,
+		Redact:     cfg.NotifyRedact,
+	}
+	if cfg.NotifySMTPHost != "" {
+		noteCfg.SMTP = &core.SMTPConfig{
+			Host:     cfg.NotifySMTPHost,
+			Username: cfg.NotifySMTPUser,
+			Password: cfg.NotifySMTPPass,
+			From:     cfg.NotifySMTPFrom,
+			To:       cfg.NotifySMTPTo,
+		}
+	}
+	switch cfg.NotifySeverity {
+	case "success":
+		noteCfg.MinSeverity = db.Success
+	case "error":
+		noteCfg.MinSeverity = db.ErrorLevel
+	default:
+		noteCfg.MinSeverity = db.WarningLevel
 	}
+	return noteCfg
 }
 
 var DefaultConfig = Config{
@@ -322,6 +513,32 @@ func ResolveConfig(appData string, cfg *Config) error {
 		cfg.RPCAddr = net.JoinHostPort(defaultHost, defaultRPCPort)
 	}
 
+	for _, s := range cfg.WebSNICerts {
+		if _, err := parseSNICert(s); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range cfg.ExplorerURLs {
+		if _, _, err := parseExplorerURL(s); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range cfg.RedeemConfs {
+		if _, _, err := parseRedeemConfs(s); err != nil {
+			return err
+		}
+	}
+
+	if cfg.PriceOracle < 0 {
+		return fmt.Errorf("invalid priceoracle %f, must not be negative", cfg.PriceOracle)
+	}
+
+	if cfg.PriceBand < 0 {
+		return fmt.Errorf("invalid priceband %f, must not be negative", cfg.PriceBand)
+	}
+
 	if cfg.RPCCert == "" {
 		cfg.RPCCert = filepath.Join(appData, defaultRPCCertFile)
 	}
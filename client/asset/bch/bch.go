@@ -64,7 +64,7 @@ var (
 		Type:             walletTypeSPV,
 		Tab:              "Native",
 		Description:      "Use the built-in SPV wallet",
-		ConfigOpts:       btc.CommonConfigOpts("BCH", true),
+		ConfigOpts:       append(btc.CommonConfigOpts("BCH", true), btc.GapLimitOpt),
 		Seeded:           true,
 		MultiFundingOpts: btc.MultiFundingOpts,
 	}
@@ -82,6 +82,7 @@ var (
 	WalletInfo = &asset.WalletInfo{
 		Name:              "Bitcoin Cash",
 		SupportedVersions: []uint32{version},
+		BlockInterval:     10 * time.Minute,
 		// Same as bitcoin. That's dumb.
 		UnitInfo: dexbch.UnitInfo,
 		AvailableWallets: []*asset.WalletDefinition{
@@ -116,8 +117,8 @@ func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Ne
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Bitcoin Cash.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Bitcoin Cash and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Info returns basic information about the wallet and asset.
@@ -125,6 +126,18 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return WalletInfo
 }
 
+// explorerTxURLs are the block explorer transaction URL templates by network.
+var explorerTxURLs = map[dex.Network]string{
+	dex.Mainnet: "https://bch.loping.net/tx/" + asset.ExplorerTxID,
+	dex.Testnet: "https://tbch4.loping.net/tx/" + asset.ExplorerTxID,
+}
+
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return explorerTxURLs[net], ""
+}
+
 // Exists checks the existence of the wallet. Part of the Creator interface, so
 // only used for wallets with WalletDefinition.Seeded = true.
 func (d *Driver) Exists(walletType, dataDir string, settings map[string]string, net dex.Network) (bool, error) {
@@ -236,7 +249,11 @@ func NewWallet(cfg *asset.WalletConfig, logger dex.Logger, network dex.Network)
 		// then, they modified it from the old Bitcoin Core estimatefee by
 		// removing the confirmation target argument.
 		cloneCFG.FeeEstimator = estimateFee
-		return btc.BTCCloneWallet(cloneCFG)
+		w, err := btc.BTCCloneWallet(cloneCFG)
+		if err != nil {
+			return nil, err
+		}
+		return &fullNodeWallet{w, &legacyAddresser{cloneParams}}, nil
 	// case walletTypeElectrum:
 	// 	logger.Warnf("\n\nUNTESTED Bitcoin Cash ELECTRUM WALLET IMPLEMENTATION! DO NOT USE ON mainnet!\n\n")
 	// 	cloneCFG.FeeEstimator = nil        // Electrum can do it, use the feeRate method
@@ -244,11 +261,49 @@ func NewWallet(cfg *asset.WalletConfig, logger dex.Logger, network dex.Network)
 	// 	cloneCFG.Ports = dexbtc.NetPorts{} // no default ports for Electrum wallet
 	// 	return btc.ElectrumWallet(cloneCFG)
 	case walletTypeSPV:
-		return btc.OpenSPVWallet(cloneCFG, openSPVWallet)
+		w, err := btc.OpenSPVWallet(cloneCFG, openSPVWallet)
+		if err != nil {
+			return nil, err
+		}
+		return &spvWallet{w, &legacyAddresser{cloneParams}}, nil
 	}
 	return nil, fmt.Errorf("wallet type %q not known", cfg.Type)
 }
 
+// fullNodeWallet is a Bitcoin Cash full node wallet that can show its
+// addresses in the legacy format.
+type fullNodeWallet struct {
+	*btc.ExchangeWalletFullNode
+	*legacyAddresser
+}
+
+// spvWallet is a Bitcoin Cash SPV wallet that can show its addresses in the
+// legacy format.
+type spvWallet struct {
+	*btc.ExchangeWalletSPV
+	*legacyAddresser
+}
+
+var _ asset.LegacyAddresser = (*fullNodeWallet)(nil)
+var _ asset.LegacyAddresser = (*spvWallet)(nil)
+
+// legacyAddresser converts CashAddr addresses to the legacy base-58 format.
+type legacyAddresser struct {
+	chainParams *chaincfg.Params
+}
+
+// LegacyAddress returns the legacy base-58 encoding of the CashAddr address,
+// for display to users of services that have not adopted CashAddr. Deposit
+// addresses are always generated in the CashAddr format. Part of the
+// asset.LegacyAddresser interface.
+func (la *legacyAddresser) LegacyAddress(addrStr string) (string, error) {
+	addr, err := dexbch.DecodeCashAddress(addrStr, la.chainParams)
+	if err != nil {
+		return "", fmt.Errorf("error decoding address %s: %w", addrStr, err)
+	}
+	return dexbch.EncodeLegacyAddress(addr, la.chainParams)
+}
+
 // rawTxInSigner signs the transaction using Bitcoin Cash's custom signature
 // hash and signing algorithm.
 func rawTxInSigner(btcTx *wire.MsgTx, idx int, subScript []byte, hashType txscript.SigHashType,
@@ -358,7 +413,7 @@ func WithdrawSPVFunds(ctx context.Context, walletPW []byte, recipient, dataDir s
 	if err != nil {
 		return nil, fmt.Errorf("error constructing wallet: %w", err)
 	}
-	w := wi.(*btc.ExchangeWalletSPV)
+	w := wi.(*spvWallet)
 
 	btcTx, err := w.WithdrawTx(ctx, walletPW, addr)
 	if err != nil {
@@ -0,0 +1,53 @@
+package bch
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+	dexbch "decred.org/dcrdex/dex/networks/bch"
+	"github.com/btcsuite/btcd/btcutil"
+)
+
+func TestLegacyAddress(t *testing.T) {
+	cfg := &asset.WalletConfig{
+		Type:        walletTypeRPC,
+		Settings:    map[string]string{"rpcuser": "user", "rpcpassword": "pass"},
+		Emit:        asset.NewWalletEmitter(make(chan asset.WalletNotification, 1), BipID, dex.StdOutLogger("T", dex.LevelOff)),
+		PeersChange: func(uint32, error) {},
+		DataDir:     os.TempDir(),
+	}
+	w, err := NewWallet(cfg, dex.StdOutLogger("T", dex.LevelOff), dex.Mainnet)
+	if err != nil {
+		t.Fatalf("NewWallet error: %v", err)
+	}
+	la, is := w.(asset.LegacyAddresser)
+	if !is {
+		t.Fatalf("Bitcoin Cash wallet is not a LegacyAddresser")
+	}
+
+	pkh, _ := btcutil.NewAddressPubKeyHash(encode.RandomBytes(20), dexbch.MainNetParams)
+	cashAddr, err := dexbch.EncodeCashAddress(pkh, dexbch.MainNetParams)
+	if err != nil {
+		t.Fatalf("EncodeCashAddress error: %v", err)
+	}
+	legacyAddr, err := la.LegacyAddress(cashAddr)
+	if err != nil {
+		t.Fatalf("LegacyAddress error: %v", err)
+	}
+	if legacyAddr != pkh.String() || strings.Contains(legacyAddr, ":") {
+		t.Fatalf("wrong legacy address %s for %s, wanted %s", legacyAddr, cashAddr, pkh)
+	}
+
+	if _, err := la.LegacyAddress("notanaddress"); err == nil {
+		t.Fatalf("no error for an invalid address")
+	}
+	testnetAddr, _ := btcutil.NewAddressPubKeyHash(encode.RandomBytes(20), dexbch.TestNet4Params)
+	testnetCashAddr, _ := dexbch.EncodeCashAddress(testnetAddr, dexbch.TestNet4Params)
+	if _, err := la.LegacyAddress(testnetCashAddr); err == nil {
+		t.Fatalf("no error for a testnet address")
+	}
+}
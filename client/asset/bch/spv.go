@@ -56,6 +56,9 @@ const (
 	neutrinoDBName         = "neutrino.db"
 	defaultAcctNum         = 0
 	defaultAcctName        = "default"
+	// defaultGapLimit is the default gap limit, which is the recovery window
+	// of bchwallet's address discovery. Borrowed from bchwallet directly.
+	defaultGapLimit = 250
 )
 
 var (
@@ -75,6 +78,9 @@ type bchSPVWallet struct {
 	btcParams   *chaincfg.Params
 	log         dex.Logger
 
+	// gapLimit is the recovery window of the wallet loader.
+	gapLimit atomic.Uint32
+
 	// This section is populated in Start.
 	*wallet.Wallet
 	chainClient *labschain.NeutrinoClient
@@ -106,6 +112,11 @@ func openSPVWallet(dir string, cfg *btc.WalletConfig, btcParams *chaincfg.Params
 		btcParams:   btcParams,
 		log:         log,
 	}
+	gapLimit := cfg.GapLimit
+	if gapLimit < defaultGapLimit {
+		gapLimit = defaultGapLimit
+	}
+	w.gapLimit.Store(gapLimit)
 	return w
 }
 
@@ -115,7 +126,7 @@ func createSPVWallet(privPass []byte, seed []byte, bday time.Time, walletDir str
 		return fmt.Errorf("error initializing bchwallet+neutrino logging: %w", err)
 	}
 
-	loader := wallet.NewLoader(net, walletDir, true, 250)
+	loader := wallet.NewLoader(net, walletDir, true, defaultGapLimit)
 
 	pubPass := []byte(wallet.InsecurePubPassphrase)
 
@@ -160,7 +171,7 @@ func (w *bchSPVWallet) Start() (btc.SPVService, error) {
 		return nil, fmt.Errorf("error initializing bchwallet+neutrino logging: %v", err)
 	}
 	// recoverWindow arguments borrowed from bchwallet directly.
-	w.loader = wallet.NewLoader(w.chainParams, w.dir, true, 250)
+	w.loader = wallet.NewLoader(w.chainParams, w.dir, true, w.gapLimit.Load())
 
 	exists, err := w.loader.WalletExists()
 	if err != nil {
@@ -587,6 +598,65 @@ func (w *bchSPVWallet) RescanAsync() error {
 	return nil
 }
 
+// GapLimit is the number of unused addresses past the last used address that
+// are scanned during address discovery.
+func (w *bchSPVWallet) GapLimit() uint32 {
+	return w.gapLimit.Load()
+}
+
+// ExtendGapLimit raises the gap limit by n, derives n more addresses on each
+// branch of the default account, and begins a full rescan.
+func (w *bchSPVWallet) ExtendGapLimit(n uint32) (uint32, error) {
+	props, err := w.Wallet.AccountProperties(bchwaddrmgr.KeyScopeBIP0044, defaultAcctNum)
+	if err != nil {
+		return 0, fmt.Errorf("error getting account properties: %w", err)
+	}
+	gapLimit := w.gapLimit.Load() + n
+	// The key counts are one past the last derived index.
+	extIdx, intIdx := props.ExternalKeyCount+n-1, props.InternalKeyCount+n-1
+	if err := extendAddresses(extIdx, intIdx, w.Wallet); err != nil {
+		return 0, fmt.Errorf("error deriving addresses: %w", err)
+	}
+	w.gapLimit.Store(gapLimit)
+	w.log.Infof("Derived addresses through external index %d and internal index %d. Gap limit is now %d.",
+		extIdx, intIdx, gapLimit)
+	return gapLimit, w.RescanAsync()
+}
+
+// UsedNearEdge reports whether any used address of the default account is
+// within margin of the last derived address of its branch.
+func (w *bchSPVWallet) UsedNearEdge(margin uint32) (bool, error) {
+	props, err := w.Wallet.AccountProperties(bchwaddrmgr.KeyScopeBIP0044, defaultAcctNum)
+	if err != nil {
+		return false, fmt.Errorf("error getting account properties: %w", err)
+	}
+	scopedKeyManager, err := w.Manager.FetchScopedKeyManager(bchwaddrmgr.KeyScopeBIP0044)
+	if err != nil {
+		return false, err
+	}
+	var nearEdge bool
+	err = walletdb.View(w.Database(), func(dbtx walletdb.ReadTx) error {
+		ns := dbtx.ReadBucket(waddrmgrNamespace)
+		return scopedKeyManager.ForEachAccountAddress(ns, defaultAcctNum, func(addr bchwaddrmgr.ManagedAddress) error {
+			pkAddr, is := addr.(bchwaddrmgr.ManagedPubKeyAddress)
+			if nearEdge || !is {
+				return nil
+			}
+			_, path, ok := pkAddr.DerivationInfo()
+			if !ok {
+				return nil
+			}
+			keyCount := props.ExternalKeyCount
+			if path.Branch == bchwaddrmgr.InternalBranch {
+				keyCount = props.InternalKeyCount
+			}
+			nearEdge = path.Index+margin >= keyCount && addr.Used(ns)
+			return nil
+		})
+	})
+	return nearEdge, err
+}
+
 // ForceRescan forces a full rescan with active address discovery on wallet
 // restart by dropping the complete transaction history and setting the
 // "synced to" field to nil. See the btcwallet/cmd/dropwtxmgr app for more
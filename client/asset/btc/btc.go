@@ -33,6 +33,8 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
@@ -57,6 +59,20 @@ const (
 	// target in blocks used by estimatesmartfee to get the optimal fee for a
 	// redeem transaction.
 	defaultRedeemConfTarget = 2
+	// defaultFeeConfTarget is the default confirmation target in blocks used
+	// by estimatesmartfee to get the fee rate for non-swap sends and
+	// withdraws.
+	defaultFeeConfTarget = 1
+	// maxFeeConfTarget is the largest confirmation target supported by
+	// estimatesmartfee.
+	maxFeeConfTarget = 1008
+	// maxGapLimitExtension is the largest number of addresses by which the
+	// gap limit can be extended at once.
+	maxGapLimitExtension = 10000
+	// maxAutoGapLimitExtensions is the largest number of times that a gap
+	// limit extension is automatically repeated because used addresses were
+	// found near the edge of the extension.
+	maxAutoGapLimitExtensions = 10
 
 	minNetworkVersion  = 270000
 	minProtocolVersion = 70015
@@ -104,6 +120,13 @@ var (
 	walletBlockAllowance         = time.Second * 10
 	conventionalConversionFactor = float64(dexbtc.UnitInfo.Conventional.ConversionFactor)
 
+	// explorerTxURLs are the block explorer transaction URL templates by
+	// network.
+	explorerTxURLs = map[dex.Network]string{
+		dex.Mainnet: "https://mempool.space/tx/" + asset.ExplorerTxID,
+		dex.Testnet: "https://mempool.space/testnet/tx/" + asset.ExplorerTxID,
+	}
+
 	ElectrumConfigOpts = []*asset.ConfigOption{
 		{
 			Key:         "rpcuser",
@@ -184,14 +207,14 @@ var (
 		Tab:               "External",
 		Description:       "Connect to bitcoind",
 		DefaultConfigPath: dexbtc.SystemConfigPath("bitcoin"),
-		ConfigOpts:        append(RPCConfigOpts("Bitcoin", "8332"), CommonConfigOpts("BTC", true)...),
+		ConfigOpts:        append(append(RPCConfigOpts("Bitcoin", "8332"), blockFiltersOpt), CommonConfigOpts("BTC", true)...),
 		MultiFundingOpts:  MultiFundingOpts,
 	}
 	spvWalletDefinition = &asset.WalletDefinition{
 		Type:             walletTypeSPV,
 		Tab:              "Native",
 		Description:      "Use the built-in SPV wallet",
-		ConfigOpts:       CommonConfigOpts("BTC", true),
+		ConfigOpts:       append(CommonConfigOpts("BTC", true), GapLimitOpt),
 		Seeded:           true,
 		MultiFundingOpts: MultiFundingOpts,
 	}
@@ -210,6 +233,7 @@ var (
 		Name:              "Bitcoin",
 		SupportedVersions: []uint32{version},
 		UnitInfo:          dexbtc.UnitInfo,
+		BlockInterval:     10 * time.Minute,
 		AvailableWallets: []*asset.WalletDefinition{
 			spvWalletDefinition,
 			rpcWalletDefinition,
@@ -219,6 +243,36 @@ var (
 	}
 )
 
+// blockFiltersOpt is the config option for using the node's compact block
+// filter index in place of full block scans.
+var blockFiltersOpt = &asset.ConfigOption{
+	Key:         "blockfilters",
+	DisplayName: "Use compact block filters",
+	Description: "Use the node's BIP-157/158 compact block filters to skip " +
+		"blocks that cannot contain a swap redemption when searching for " +
+		"redemptions. Other block searches, such as for bonds that were " +
+		"not funded by this wallet, still download full blocks. Requires " +
+		"the node to be run with blockfilterindex=1.",
+	IsBoolean:    true,
+	DefaultValue: false,
+}
+
+// gapRescanPollInterval is how often the sync status is checked while waiting
+// for the rescan of a gap limit extension to complete.
+var gapRescanPollInterval = 5 * time.Second
+
+// GapLimitOpt is the config option for the gap limit of the native wallets'
+// address discovery.
+var GapLimitOpt = &asset.ConfigOption{
+	Key:         "gaplimit",
+	DisplayName: "Address gap limit",
+	Description: "The number of unused addresses past the last used address " +
+		"that are scanned for funds during wallet recovery. Increase this if " +
+		"funds were received at addresses far beyond the last used address, " +
+		fmt.Sprintf("e.g. by another wallet restored from the same seed. (default: %d)", defaultGapLimit),
+	DefaultValue: defaultGapLimit,
+}
+
 func apiFallbackOpt(defaultV bool) *asset.ConfigOption {
 	return &asset.ConfigOption{
 		Key:         "apifeefallback",
@@ -258,6 +312,15 @@ func CommonConfigOpts(symbol string /* upper-case */, withApiFallback bool) []*a
 				"(default: 2 blocks)",
 			DefaultValue: defaultRedeemConfTarget,
 		},
+		{
+			Key:         "feeconftarget",
+			DisplayName: "Send confirmation target",
+			Description: "The target number of blocks for sends and withdraws " +
+				"to be mined. A higher target is cheaper but slower. Swap " +
+				"transactions use the fee rate required by the server. " +
+				fmt.Sprintf("(default: %d block, maximum: %d blocks)", defaultFeeConfTarget, maxFeeConfTarget),
+			DefaultValue: defaultFeeConfTarget,
+		},
 		{
 			Key:         "txsplit",
 			DisplayName: "Pre-size funding inputs",
@@ -329,6 +392,10 @@ type BTCCloneCFG struct {
 	Logger             dex.Logger
 	Network            dex.Network
 	ChainParams        *chaincfg.Params
+	// OtherChainParams are the chain parameters of the asset's other
+	// networks. If provided, CheckAddress can distinguish an address for
+	// another network from a malformed address.
+	OtherChainParams []*chaincfg.Params
 	// Ports is the default wallet RPC tcp ports used when undefined in
 	// WalletConfig.
 	Ports               dexbtc.NetPorts
@@ -434,6 +501,14 @@ type BTCCloneCFG struct {
 type RPCConfig struct {
 	dexbtc.RPCConfig `ini:",extends"`
 	WalletName       string `ini:"walletname"`
+	// BlockFilters enables the use of the node's BIP-157/158 compact block
+	// filter index to skip downloading full blocks that cannot contain
+	// transactions of interest. The node must be run with blockfilterindex=1.
+	// Only the redemption search uses the filters. Swaps, audits and refunds
+	// are located with the wallet and gettxout, which need neither the filters
+	// nor txindex, and the bond search in FindBond does not know the bond's
+	// output scripts, so it still scans full blocks.
+	BlockFilters bool `ini:"blockfilters"`
 }
 
 // RPCWalletConfig is a combination of RPCConfig and WalletConfig. Used for a
@@ -451,6 +526,8 @@ type WalletConfig struct {
 	RedeemConfTarget uint64  `ini:"redeemconftarget"`
 	ActivelyUsed     bool    `ini:"special_activelyUsed"` // injected by core
 	ApiFeeFallback   bool    `ini:"apifeefallback"`
+	FeeConfTarget    uint64  `ini:"feeconftarget"`
+	GapLimit         uint32  `ini:"gaplimit"` // SPV only
 }
 
 func readBaseWalletConfig(walletCfg *WalletConfig) (*baseWalletConfig, error) {
@@ -466,6 +543,13 @@ func readBaseWalletConfig(walletCfg *WalletConfig) (*baseWalletConfig, error) {
 	if walletCfg.RedeemConfTarget == 0 {
 		walletCfg.RedeemConfTarget = defaultRedeemConfTarget
 	}
+	if walletCfg.FeeConfTarget == 0 {
+		walletCfg.FeeConfTarget = defaultFeeConfTarget
+	}
+	if walletCfg.FeeConfTarget > maxFeeConfTarget {
+		return nil, fmt.Errorf("fee confirmation target %d exceeds the maximum of %d blocks",
+			walletCfg.FeeConfTarget, maxFeeConfTarget)
+	}
 	// If set in the user config, the fallback fee will be in conventional units
 	// per kB, e.g. BTC/kB. Translate that to sats/byte.
 	cfg.fallbackFeeRate = toSatoshi(walletCfg.FallbackFeeRate / 1000)
@@ -482,6 +566,7 @@ func readBaseWalletConfig(walletCfg *WalletConfig) (*baseWalletConfig, error) {
 	}
 
 	cfg.redeemConfTarget = walletCfg.RedeemConfTarget
+	cfg.feeConfTarget = walletCfg.FeeConfTarget
 	cfg.useSplitTx = walletCfg.UseSplitTx
 	cfg.apiFeeFallback = walletCfg.ApiFeeFallback
 
@@ -557,8 +642,8 @@ func (d *Driver) Exists(walletType, dataDir string, settings map[string]string,
 
 // walletExists checks the existence of the wallet.
 func walletExists(dir string, chainParams *chaincfg.Params) (bool, error) {
-	// timeout and recoverWindow arguments borrowed from btcwallet directly.
-	loader := wallet.NewLoader(chainParams, dir, true, dbTimeout, 250)
+	// timeout argument borrowed from btcwallet directly.
+	loader := wallet.NewLoader(chainParams, dir, true, dbTimeout, defaultGapLimit)
 	return loader.WalletExists()
 }
 
@@ -605,6 +690,20 @@ func (d *Driver) Create(params *asset.CreateWalletParams) error {
 		params.Logger, cfg.NumExternalAddresses, cfg.NumInternalAddresses, chainParams)
 }
 
+// ValidateImport checks that the seed can be used to create an SPV wallet.
+// The seed is used as the BIP32 master seed, as it is for seeds derived from
+// the application seed. ValidateImport satisfies asset.Importer.
+func (d *Driver) ValidateImport(walletType string, seed []byte) error {
+	if walletType != walletTypeSPV {
+		return fmt.Errorf("only %q wallets can be imported, requested %q", walletTypeSPV, walletType)
+	}
+	if len(seed) < hdkeychain.MinSeedBytes || len(seed) > hdkeychain.MaxSeedBytes {
+		return fmt.Errorf("invalid seed length %d. must be between %d and %d bytes",
+			len(seed), hdkeychain.MinSeedBytes, hdkeychain.MaxSeedBytes)
+	}
+	return nil
+}
+
 // Open opens or connects to the BTC exchange wallet. Start the wallet with its
 // Run method.
 func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Network) (asset.Wallet, error) {
@@ -614,11 +713,8 @@ func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Ne
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Bitcoin.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	txid, vout, err := decodeCoinID(coinID)
-	if err != nil {
-		return "<invalid>", err
-	}
-	return fmt.Sprintf("%v:%d", txid, vout), err
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Info returns basic information about the wallet and asset.
@@ -626,6 +722,12 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return WalletInfo
 }
 
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return explorerTxURLs[net], ""
+}
+
 // MinLotSize calculates the minimum bond size for a given fee rate that avoids
 // dust outputs on the swap and refund txs, assuming the maxFeeRate doesn't
 // change.
@@ -724,6 +826,7 @@ type baseWalletConfig struct {
 	fallbackFeeRate  uint64 // atoms/byte
 	feeRateLimit     uint64 // atoms/byte
 	redeemConfTarget uint64
+	feeConfTarget    uint64
 	useSplitTx       bool
 	apiFeeFallback   bool
 }
@@ -846,6 +949,10 @@ func (w *baseWallet) redeemConfTarget() uint64 {
 	return w.cfgV.Load().(*baseWalletConfig).redeemConfTarget
 }
 
+func (w *baseWallet) feeConfTarget() uint64 {
+	return w.cfgV.Load().(*baseWalletConfig).feeConfTarget
+}
+
 func (w *baseWallet) useSplitTx() bool {
 	return w.cfgV.Load().(*baseWalletConfig).useSplitTx
 }
@@ -873,6 +980,10 @@ type ExchangeWalletSPV struct {
 	*authAddOn
 
 	spvNode *spvWallet
+
+	// gapExtending is set while the rescan of a gap limit extension is
+	// monitored for used addresses near the edge of the extension.
+	gapExtending atomic.Bool
 }
 
 // ExchangeWalletFullNode implements Wallet and adds the FeeRate method.
@@ -897,6 +1008,7 @@ var _ asset.Accelerator = (*ExchangeWalletAccelerator)(nil)
 var _ asset.Accelerator = (*ExchangeWalletSPV)(nil)
 var _ asset.Withdrawer = (*baseWallet)(nil)
 var _ asset.FeeRater = (*baseWallet)(nil)
+var _ asset.SwapSimulator = (*baseWallet)(nil)
 var _ asset.Rescanner = (*ExchangeWalletSPV)(nil)
 var _ asset.LogFiler = (*ExchangeWalletSPV)(nil)
 var _ asset.Recoverer = (*ExchangeWalletSPV)(nil)
@@ -908,6 +1020,11 @@ var _ asset.Authenticator = (*ExchangeWalletFullNode)(nil)
 var _ asset.Authenticator = (*ExchangeWalletAccelerator)(nil)
 var _ asset.AddressReturner = (*baseWallet)(nil)
 var _ asset.WalletHistorian = (*ExchangeWalletSPV)(nil)
+var _ asset.GapLimiter = (*ExchangeWalletSPV)(nil)
+var _ asset.DerivationVerifier = (*ExchangeWalletSPV)(nil)
+var _ asset.CoinLockLister = (*baseWallet)(nil)
+var _ asset.DuplicateSwapFinder = (*baseWallet)(nil)
+var _ asset.SwapReplacementFinder = (*baseWallet)(nil)
 
 // RecoveryCfg is the information that is transferred from the old wallet
 // to the new one when the wallet is recovered.
@@ -962,6 +1079,147 @@ func (btc *ExchangeWalletSPV) Rescan(_ context.Context, _ /* bday already stored
 	return nil
 }
 
+// GapLimit returns the gap limit of the wallet's address discovery. Part of
+// the asset.GapLimiter interface.
+func (btc *ExchangeWalletSPV) GapLimit() (uint32, error) {
+	gl, is := btc.spvNode.wallet.(gapLimiter)
+	if !is {
+		return 0, errors.New("wallet does not have a gap limit")
+	}
+	return gl.GapLimit(), nil
+}
+
+// ExtendGapLimit raises the gap limit of the wallet's address discovery by n
+// and begins a rescan so that funds received at addresses past the previous
+// gap limit are found. If the rescan finds used addresses within n of the
+// last derived address, more funds may have been received past the new gap
+// limit, so the extension is repeated, up to maxAutoGapLimitExtensions times.
+// Part of the asset.GapLimiter interface.
+func (btc *ExchangeWalletSPV) ExtendGapLimit(n uint32) (uint32, error) {
+	gl, is := btc.spvNode.wallet.(gapLimiter)
+	if !is {
+		return 0, errors.New("wallet does not support extending the gap limit")
+	}
+	if n == 0 || n > maxGapLimitExtension {
+		return 0, fmt.Errorf("gap limit extension must be between 1 and %d", maxGapLimitExtension)
+	}
+	gapLimit, err := btc.extendGapLimit(gl, n)
+	if err != nil {
+		return 0, err
+	}
+	if btc.gapExtending.CompareAndSwap(false, true) {
+		go func() {
+			defer btc.gapExtending.Store(false)
+			btc.autoExtendGapLimit(gl, n)
+		}()
+	}
+	return gapLimit, nil
+}
+
+// SeedAddresses derives the first n external addresses of a wallet created
+// from the seed, i.e. the addresses m/84'/0'/0'/0/i. Part of the
+// asset.DerivationVerifier interface.
+func (btc *ExchangeWalletSPV) SeedAddresses(seed []byte, n int) ([]string, error) {
+	if _, is := btc.spvNode.wallet.(externalAddresser); !is {
+		return nil, errors.New("wallet does not support derivation verification")
+	}
+	if n < 1 {
+		return nil, nil
+	}
+	addrs, err := seedAddresses(seed, uint32(n), btc.chainParams)
+	if err != nil {
+		return nil, err
+	}
+	return btc.addressStrings(addrs)
+}
+
+// WalletAddresses returns the first n external addresses of the wallet's
+// default account. Part of the asset.DerivationVerifier interface.
+func (btc *ExchangeWalletSPV) WalletAddresses(n int) ([]string, error) {
+	ea, is := btc.spvNode.wallet.(externalAddresser)
+	if !is {
+		return nil, errors.New("wallet does not support derivation verification")
+	}
+	if n < 1 {
+		return nil, nil
+	}
+	addrs, err := ea.ExternalAddresses(uint32(n))
+	if err != nil {
+		return nil, err
+	}
+	return btc.addressStrings(addrs)
+}
+
+func (btc *ExchangeWalletSPV) addressStrings(addrs []btcutil.Address) ([]string, error) {
+	strs := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		s, err := btc.stringAddr(addr, btc.chainParams)
+		if err != nil {
+			return nil, err
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
+func (btc *ExchangeWalletSPV) extendGapLimit(gl gapLimiter, n uint32) (uint32, error) {
+	atomic.StoreInt64(&btc.tipAtConnect, 0) // for progress
+	gapLimit, err := gl.ExtendGapLimit(n)
+	if err != nil {
+		return 0, err
+	}
+	btc.receiveTxLastQuery.Store(0)
+	return gapLimit, nil
+}
+
+// autoExtendGapLimit waits for the rescan of a gap limit extension by n to
+// complete, and extends the gap limit by n again if any address within n of
+// the last derived address was found to be used.
+func (btc *ExchangeWalletSPV) autoExtendGapLimit(gl gapLimiter, n uint32) {
+	for i := 0; i < maxAutoGapLimitExtensions; i++ {
+		if !btc.waitForRescan() {
+			return
+		}
+		nearEdge, err := gl.UsedNearEdge(n)
+		if err != nil {
+			btc.log.Errorf("Error checking for used addresses near the gap limit: %v", err)
+			return
+		}
+		if !nearEdge {
+			return
+		}
+		gapLimit, err := btc.extendGapLimit(gl, n)
+		if err != nil {
+			btc.log.Errorf("Error extending the gap limit: %v", err)
+			return
+		}
+		btc.log.Infof("Found used addresses near the end of the gap limit extension. "+
+			"Extended the gap limit by %d to %d.", n, gapLimit)
+	}
+}
+
+// waitForRescan waits for the wallet to be synced. false is returned if the
+// wallet is shut down first.
+func (btc *ExchangeWalletSPV) waitForRescan() bool {
+	ticker := time.NewTicker(gapRescanPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ss, err := btc.spvNode.syncStatus()
+			if err != nil {
+				btc.log.Errorf("Error getting sync status: %v", err)
+				continue
+			}
+			if ss.Synced {
+				return true
+			}
+		case <-btc.ctx.Done():
+			return false
+		}
+	}
+}
+
 // Peers returns a list of peers that the wallet is connected to.
 func (btc *ExchangeWalletSPV) Peers() ([]*asset.WalletPeer, error) {
 	return btc.spvNode.peers()
@@ -992,6 +1250,16 @@ func (btc *baseWallet) FeeRate() uint64 {
 	return rate
 }
 
+// sendFeeRate is the fee rate for non-swap sends and withdraws. The rate is
+// estimated for the configured send confirmation target, falling back to the
+// suggestion via feeRateWithFallback.
+func (btc *baseWallet) sendFeeRate(feeSuggestion uint64) uint64 {
+	confTarget := btc.feeConfTarget()
+	feeRate := btc.targetFeeRateWithFallback(confTarget, feeSuggestion)
+	btc.log.Debugf("Using send fee rate %d for a %d-block confirmation target", feeRate, confTarget)
+	return feeRate
+}
+
 // LogFilePath returns the path to the neutrino log file.
 func (btc *ExchangeWalletSPV) LogFilePath() string {
 	return btc.spvNode.logFilePath()
@@ -1013,7 +1281,7 @@ func (btc *ExchangeWalletSPV) WithdrawTx(ctx context.Context, walletPW []byte, a
 		return nil, fmt.Errorf("error unlocking wallet: %w", err)
 	}
 
-	feeRate := btc.FeeRate()
+	feeRate := btc.sendFeeRate(btc.FeeRate())
 	if feeRate == 0 {
 		return nil, errors.New("no fee rate")
 	}
@@ -1064,6 +1332,18 @@ func parseChainParams(net dex.Network) (*chaincfg.Params, error) {
 	return nil, fmt.Errorf("unknown network ID %v", net)
 }
 
+// otherChainParams returns the chain parameters of every network except net.
+func otherChainParams(net dex.Network) []*chaincfg.Params {
+	var params []*chaincfg.Params
+	for _, n := range []dex.Network{dex.Mainnet, dex.Testnet, dex.Regtest} {
+		if n != net {
+			p, _ := parseChainParams(n)
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
 // NewWallet is the exported constructor by which the DEX will import the
 // exchange wallet.
 func NewWallet(cfg *asset.WalletConfig, logger dex.Logger, net dex.Network) (asset.Wallet, error) {
@@ -1080,6 +1360,7 @@ func NewWallet(cfg *asset.WalletConfig, logger dex.Logger, net dex.Network) (ass
 		Logger:              logger,
 		Network:             net,
 		ChainParams:         params,
+		OtherChainParams:    otherChainParams(net),
 		Ports:               dexbtc.RPCPorts,
 		DefaultFallbackFee:  defaultFee,
 		DefaultFeeRateLimit: defaultFeeRateLimit,
@@ -2438,13 +2719,16 @@ func (btc *baseWallet) FundOrder(ord *asset.Order) (asset.Coins, []dex.Bytes, ui
 	if customCfg.Split != nil {
 		useSplit = *customCfg.Split
 	}
+	if ord.DryRun {
+		useSplit = false // would broadcast
+	}
 
 	reserves := btc.bondReserves.Load()
 	minConfs := uint32(0)
 	coins, fundingCoins, spents, redeemScripts, inputsSize, sum, err := btc.cm.Fund(reserves, minConfs, true,
 		orderEnough(ord.Value, ord.MaxSwapCount, bumpedMaxRate, btc.initTxSizeBase, btc.initTxSize, btc.segwit, useSplit))
 	if err != nil {
-		if !useSplit && reserves > 0 {
+		if !useSplit && reserves > 0 && !ord.DryRun {
 			// Force a split if funding failure may be due to reserves.
 			btc.log.Infof("Retrying order funding with a forced split transaction to help respect reserves.")
 			useSplit = true
@@ -2972,6 +3256,28 @@ func (btc *baseWallet) FundingCoins(ids []dex.Bytes) (asset.Coins, error) {
 	return btc.cm.FundingCoins(ids)
 }
 
+// LockedCoins returns the unspent coins that this wallet has locked, e.g. to
+// fund orders. Outputs locked in the node by other means, such as by another
+// application using the same node wallet, are not included. Locked outputs
+// that are spent or otherwise not found are skipped. Part of the
+// asset.CoinLockLister interface.
+func (btc *baseWallet) LockedCoins() (asset.Coins, error) {
+	utxos := btc.cm.LockedUTXOs()
+	coins := make(asset.Coins, 0, len(utxos))
+	for _, utxo := range utxos {
+		txOut, _, err := btc.node.getTxOut(utxo.TxHash, utxo.Vout, nil, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		if txOut == nil {
+			btc.log.Debugf("Locked output %s:%d is spent or not found", utxo.TxHash, utxo.Vout)
+			continue
+		}
+		coins = append(coins, NewOutput(utxo.TxHash, utxo.Vout, uint64(txOut.Value)))
+	}
+	return coins, nil
+}
+
 // authAddOn implements the asset.Authenticator.
 type authAddOn struct {
 	w Wallet
@@ -3712,47 +4018,124 @@ func (btc *baseWallet) addTxToHistory(wt *asset.WalletTransaction, txHash *chain
 	}
 }
 
-// Swap sends the swaps in a single transaction and prepares the receipts. The
-// Receipts returned can be used to refund a failed transaction. The Input coins
-// are NOT manually unlocked because they're auto-unlocked when the transaction
-// is broadcasted.
-func (btc *baseWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, uint64, error) {
+// swapTx is a signed but unbroadcast swap transaction.
+type swapTx struct {
+	msgTx       *wire.MsgTx
+	txHash      *chainhash.Hash
+	contracts   [][]byte
+	refundAddrs []btcutil.Address
+	changeAddr  btcutil.Address
+	change      *Output
+	pts         []OutPoint
+	totalOut    uint64
+	fees        uint64
+}
+
+// buildSwap funds and signs the swap transaction for the swaps, but does not
+// broadcast it.
+func (btc *baseWallet) buildSwap(swaps *asset.Swaps) (*swapTx, error) {
 	if swaps.FeeRate == 0 {
-		return nil, nil, 0, fmt.Errorf("cannot send swap with with zero fee rate")
+		return nil, fmt.Errorf("cannot send swap with with zero fee rate")
 	}
 
-	contracts := make([][]byte, 0, len(swaps.Contracts))
-	var totalOut uint64
 	// Start with an empty MsgTx.
 	baseTx, totalIn, pts, err := btc.fundedTx(swaps.Inputs)
 	if err != nil {
-		return nil, nil, 0, err
+		return nil, err
+	}
+
+	feeRate, err := btc.swapFeeRate(swaps)
+	if err != nil {
+		return nil, err
+	}
+
+	// revokeAddr is the address belonging to the key that may be used to
+	// sign and refund a swap past its encoded refund locktime.
+	revokeAddr := func() (btcutil.Address, error) {
+		revokeAddrStr, err := btc.recyclableAddress()
+		if err != nil {
+			return nil, fmt.Errorf("error creating revocation address: %w", err)
+		}
+		revokeAddr, err := btc.decodeAddr(revokeAddrStr, btc.chainParams)
+		if err != nil {
+			return nil, fmt.Errorf("refund address decode error: %v", err)
+		}
+		return revokeAddr, nil
+	}
+	contracts, refundAddrs, totalOut, err := btc.addSwapOutputs(baseTx, swaps.Contracts, revokeAddr)
+	if err != nil {
+		return nil, err
+	}
+	if totalIn < totalOut {
+		return nil, fmt.Errorf("unfunded contract. %d < %d", totalIn, totalOut)
+	}
+
+	// Ensure we have enough outputs before broadcasting.
+	swapCount := len(swaps.Contracts)
+	if len(baseTx.TxOut) < swapCount {
+		return nil, fmt.Errorf("fewer outputs than swaps. %d < %d", len(baseTx.TxOut), swapCount)
+	}
+
+	// Grab a change address.
+	changeAddr, err := btc.node.changeAddress()
+	if err != nil {
+		return nil, fmt.Errorf("error creating change address: %w", err)
+	}
+
+	// Sign, add change, but don't send the transaction yet until
+	// the individual swap refund txs are prepared and signed.
+	msgTx, change, fees, err := btc.signTxAndAddChange(baseTx, changeAddr, totalIn, totalOut, feeRate)
+	if err != nil {
+		return nil, err
 	}
 
+	return &swapTx{
+		msgTx:       msgTx,
+		txHash:      btc.hashTx(msgTx),
+		contracts:   contracts,
+		refundAddrs: refundAddrs,
+		changeAddr:  changeAddr,
+		change:      change,
+		pts:         pts,
+		totalOut:    totalOut,
+		fees:        fees,
+	}, nil
+}
+
+// swapFeeRate is the fee rate of the swaps, bumped by the FeeBump swap
+// option.
+func (btc *baseWallet) swapFeeRate(swaps *asset.Swaps) (uint64, error) {
 	customCfg := new(swapOptions)
-	err = config.Unmapify(swaps.Options, customCfg)
+	err := config.Unmapify(swaps.Options, customCfg)
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("error parsing swap options: %w", err)
+		return 0, fmt.Errorf("error parsing swap options: %w", err)
 	}
+	feeRate, err := calcBumpedRate(swaps.FeeRate, customCfg.FeeBump)
+	if err != nil {
+		btc.log.Errorf("ignoring invalid fee bump factor, %s: %v", float64PtrStr(customCfg.FeeBump), err)
+	}
+	return feeRate, nil
+}
+
+// addSwapOutputs adds the contract outputs for the swaps to baseTx. The
+// revokeAddr function provides the address of the key that may refund each
+// swap.
+func (btc *baseWallet) addSwapOutputs(baseTx *wire.MsgTx, swaps []*asset.Contract,
+	revokeAddr func() (btcutil.Address, error)) (contracts [][]byte, refundAddrs []btcutil.Address, totalOut uint64, err error) {
 
-	refundAddrs := make([]btcutil.Address, 0, len(swaps.Contracts))
+	contracts = make([][]byte, 0, len(swaps))
+	refundAddrs = make([]btcutil.Address, 0, len(swaps))
 
 	// Add the contract outputs.
 	// TODO: Make P2WSH contract and P2WPKH change outputs instead of
 	// legacy/non-segwit swap contracts pkScripts.
-	for _, contract := range swaps.Contracts {
+	for _, contract := range swaps {
 		totalOut += contract.Value
-		// revokeAddr is the address belonging to the key that may be used to
-		// sign and refund a swap past its encoded refund locktime.
-		revokeAddrStr, err := btc.recyclableAddress()
+		refundAddr, err := revokeAddr()
 		if err != nil {
-			return nil, nil, 0, fmt.Errorf("error creating revocation address: %w", err)
-		}
-		revokeAddr, err := btc.decodeAddr(revokeAddrStr, btc.chainParams)
-		if err != nil {
-			return nil, nil, 0, fmt.Errorf("refund address decode error: %v", err)
+			return nil, nil, 0, err
 		}
-		refundAddrs = append(refundAddrs, revokeAddr)
+		refundAddrs = append(refundAddrs, refundAddr)
 
 		contractAddr, err := btc.decodeAddr(contract.Address, btc.chainParams)
 		if err != nil {
@@ -3760,7 +4143,7 @@ func (btc *baseWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, ui
 		}
 
 		// Create the contract, a P2SH redeem script.
-		contractScript, err := dexbtc.MakeContract(contractAddr, revokeAddr,
+		contractScript, err := dexbtc.MakeContract(contractAddr, refundAddr,
 			contract.SecretHash, int64(contract.LockTime), btc.segwit, btc.chainParams)
 		if err != nil {
 			return nil, nil, 0, fmt.Errorf("unable to create pubkey script for address %s: %w", contract.Address, err)
@@ -3782,34 +4165,114 @@ func (btc *baseWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, ui
 		txOut := wire.NewTxOut(int64(contract.Value), pkScript)
 		baseTx.AddTxOut(txOut)
 	}
+	return contracts, refundAddrs, totalOut, nil
+}
+
+// SimulateSwap builds the unsigned swap transaction for the swaps without
+// broadcasting it, so nothing is spent. No addresses are taken from the
+// wallet. The refund keys of the contracts and the change output are
+// placeholders, and the fees are estimated with worst case signature sizes.
+// Part of the asset.SwapSimulator interface.
+func (btc *baseWallet) SimulateSwap(swaps *asset.Swaps) (*asset.SimulatedSwap, error) {
+	if swaps.FeeRate == 0 {
+		return nil, fmt.Errorf("cannot simulate swap with zero fee rate")
+	}
+	baseTx, totalIn, _, err := btc.fundedTx(swaps.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	feeRate, err := btc.swapFeeRate(swaps)
+	if err != nil {
+		return nil, err
+	}
+	placeholderAddr, err := btc.placeholderAddress()
+	if err != nil {
+		return nil, err
+	}
+	swapContracts, _, totalOut, err := btc.addSwapOutputs(baseTx, swaps.Contracts, func() (btcutil.Address, error) {
+		return placeholderAddr, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	if totalIn < totalOut {
-		return nil, nil, 0, fmt.Errorf("unfunded contract. %d < %d", totalIn, totalOut)
+		return nil, fmt.Errorf("unfunded contract. %d < %d", totalIn, totalOut)
 	}
 
-	// Ensure we have enough outputs before broadcasting.
-	swapCount := len(swaps.Contracts)
-	if len(baseTx.TxOut) < swapCount {
-		return nil, nil, 0, fmt.Errorf("fewer outputs than swaps. %d < %d", len(baseTx.TxOut), swapCount)
+	remaining := totalIn - totalOut
+	fees := feeRate * btc.signedTxSize(baseTx)
+	if fees > remaining {
+		return nil, fmt.Errorf("not enough funds to cover minimum fee rate. %.8f < %.8f",
+			toBTC(totalIn), toBTC(fees+totalOut))
 	}
-
-	// Grab a change address.
-	changeAddr, err := btc.node.changeAddress()
+	changeScript, err := txscript.PayToAddrScript(placeholderAddr)
 	if err != nil {
-		return nil, nil, 0, fmt.Errorf("error creating change address: %w", err)
+		return nil, fmt.Errorf("error creating change script: %w", err)
+	}
+	changeOutput := wire.NewTxOut(0, changeScript)
+	changeFees := feeRate * uint64(changeOutput.SerializeSize())
+	if remaining > fees+changeFees {
+		changeOutput.Value = int64(remaining - fees - changeFees)
+	}
+	if btc.IsDust(changeOutput, feeRate) {
+		// The dust goes to the miners.
+		fees = remaining
+	} else {
+		baseTx.AddTxOut(changeOutput)
+		fees += changeFees
 	}
 
-	feeRate, err := calcBumpedRate(swaps.FeeRate, customCfg.FeeBump)
+	txB, err := serializeMsgTx(baseTx)
 	if err != nil {
-		btc.log.Errorf("ignoring invalid fee bump factor, %s: %v", float64PtrStr(customCfg.FeeBump), err)
+		return nil, fmt.Errorf("error serializing swap tx: %w", err)
 	}
+	contracts := make([]dex.Bytes, 0, len(swapContracts))
+	for _, contract := range swapContracts {
+		contracts = append(contracts, contract)
+	}
+	return &asset.SimulatedSwap{
+		TxID:      btc.hashTx(baseTx).String(),
+		Tx:        txB,
+		Contracts: contracts,
+		Fees:      fees,
+	}, nil
+}
 
-	// Sign, add change, but don't send the transaction yet until
-	// the individual swap refund txs are prepared and signed.
-	msgTx, change, fees, err := btc.signTxAndAddChange(baseTx, changeAddr, totalIn, totalOut, feeRate)
+// placeholderAddress is an address with a zero pubkey hash, of the type of the
+// wallet's own addresses, for transactions that are never broadcast.
+func (btc *baseWallet) placeholderAddress() (btcutil.Address, error) {
+	if btc.segwit {
+		return btcutil.NewAddressWitnessPubKeyHash(make([]byte, 20), btc.chainParams)
+	}
+	return btcutil.NewAddressPubKeyHash(make([]byte, 20), btc.chainParams)
+}
+
+// signedTxSize is the size of the transaction once its P2WPKH or P2PKH inputs
+// are signed, using worst case signature sizes.
+func (btc *baseWallet) signedTxSize(tx *wire.MsgTx) uint64 {
+	signedTx := tx.Copy()
+	for _, txIn := range signedTx.TxIn {
+		if btc.segwit {
+			txIn.Witness = wire.TxWitness{make([]byte, dexbtc.DERSigLength), make([]byte, dexbtc.PubKeyLength)}
+		} else {
+			txIn.SignatureScript = make([]byte, dexbtc.RedeemP2PKHSigScriptSize)
+		}
+	}
+	return btc.calcTxSize(signedTx)
+}
+
+// Swap sends the swaps in a single transaction and prepares the receipts. The
+// Receipts returned can be used to refund a failed transaction. The Input coins
+// are NOT manually unlocked because they're auto-unlocked when the transaction
+// is broadcasted.
+func (btc *baseWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, uint64, error) {
+	stx, err := btc.buildSwap(swaps)
 	if err != nil {
 		return nil, nil, 0, err
 	}
-	txHash := btc.hashTx(msgTx)
+	msgTx, txHash, contracts, refundAddrs := stx.msgTx, stx.txHash, stx.contracts, stx.refundAddrs
+	changeAddr, change, pts, totalOut, fees := stx.changeAddr, stx.change, stx.pts, stx.totalOut, stx.fees
+	swapCount := len(swaps.Contracts)
 
 	// Prepare the receipts.
 	receipts := make([]asset.Receipt, 0, swapCount)
@@ -4369,6 +4832,9 @@ func (btc *baseWallet) DepositAddress() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	if err := btc.checkAddressFormat(addr, addrStr); err != nil {
+		return "", err
+	}
 	if btc.node.locked() {
 		return addrStr, nil
 	}
@@ -4384,6 +4850,59 @@ func (btc *baseWallet) DepositAddress() (string, error) {
 	return addrStr, nil
 }
 
+// checkAddressFormat checks that a deposit address is in the canonical format
+// for the asset and network, i.e. that the wallet did not give us a legacy
+// address, that a segwit address has the checksum of its witness version
+// (bech32 for version 0, bech32m for taproot and later versions), and that
+// the encoded address decodes to the same output script.
+func (btc *baseWallet) checkAddressFormat(addr btcutil.Address, addrStr string) error {
+	witAddr, isWitness := addr.(interface{ WitnessVersion() byte })
+	if btc.segwit && !isWitness {
+		return fmt.Errorf("wallet returned non-segwit address %s", addrStr)
+	}
+	if isWitness {
+		_, _, bechVersion, err := bech32.DecodeGeneric(addrStr)
+		if err != nil {
+			return fmt.Errorf("error decoding segwit address %s: %w", addrStr, err)
+		}
+		wantVersion := bech32.Version0
+		if witAddr.WitnessVersion() > 0 {
+			wantVersion = bech32.VersionM
+		}
+		if bechVersion != wantVersion {
+			return fmt.Errorf("segwit version %d address %s does not have a %s checksum",
+				witAddr.WitnessVersion(), addrStr, bechName(wantVersion))
+		}
+	}
+	decoded, err := btc.decodeAddr(addrStr, btc.chainParams)
+	if err != nil {
+		return fmt.Errorf("error decoding address %s: %w", addrStr, err)
+	}
+	if !decoded.IsForNet(btc.chainParams) {
+		return fmt.Errorf("address %s is not for the %s network", addrStr, btc.chainParams.Name)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return fmt.Errorf("error creating pubkey script for %s: %w", addrStr, err)
+	}
+	decodedScript, err := txscript.PayToAddrScript(decoded)
+	if err != nil {
+		return fmt.Errorf("error creating pubkey script for decoded %s: %w", addrStr, err)
+	}
+	if !bytes.Equal(pkScript, decodedScript) {
+		return fmt.Errorf("address %s does not decode to the wallet's address", addrStr)
+	}
+	return nil
+}
+
+// bechName is the name of the bech32 checksum variant.
+func bechName(v bech32.Version) string {
+	if v == bech32.VersionM {
+		return "bech32m"
+	}
+	return "bech32"
+}
+
 // RedemptionAddress gets an address for use in redeeming the counterparty's
 // swap. This would be included in their swap initialization.
 func (btc *baseWallet) RedemptionAddress() (string, error) {
@@ -4445,10 +4964,11 @@ func (btc *baseWallet) NewAddress() (string, error) {
 }
 
 // Withdraw withdraws funds to the specified address. Fees are subtracted from
-// the value. feeRate is in units of sats/byte.
+// the value. The fee rate is estimated for the send confirmation target, with
+// feeRate, in units of sats/byte, as the fallback.
 // Withdraw satisfies asset.Withdrawer.
 func (btc *baseWallet) Withdraw(address string, value, feeRate uint64) (asset.Coin, error) {
-	txHash, vout, sent, err := btc.send(address, value, btc.feeRateWithFallback(feeRate), true)
+	txHash, vout, sent, err := btc.send(address, value, btc.sendFeeRate(feeRate), true)
 	if err != nil {
 		return nil, err
 	}
@@ -4456,10 +4976,11 @@ func (btc *baseWallet) Withdraw(address string, value, feeRate uint64) (asset.Co
 }
 
 // Send sends the exact value to the specified address. This is different from
-// Withdraw, which subtracts the tx fees from the amount sent. feeRate is in
-// units of sats/byte.
+// Withdraw, which subtracts the tx fees from the amount sent. The fee rate is
+// estimated for the send confirmation target, with feeRate, in units of
+// sats/byte, as the fallback.
 func (btc *baseWallet) Send(address string, value, feeRate uint64) (asset.Coin, error) {
-	txHash, vout, sent, err := btc.send(address, value, btc.feeRateWithFallback(feeRate), false)
+	txHash, vout, sent, err := btc.send(address, value, btc.sendFeeRate(feeRate), false)
 	if err != nil {
 		return nil, err
 	}
@@ -4582,6 +5103,162 @@ func (btc *baseWallet) SwapConfirmations(_ context.Context, id dex.Bytes, contra
 	return btc.node.swapConfirmations(txHash, vout, pkScript, startTime)
 }
 
+// duplicateSwapSearchBuffer is how many blocks before the block of a swap are
+// searched for duplicates of the swap, which may have been mined first.
+const duplicateSwapSearchBuffer = 6
+
+// swapReplacementSearchBlocks is how many recent blocks are searched for the
+// replacement of a swap transaction.
+const swapReplacementSearchBlocks = 24
+
+// searchWalletSends calls check with each transaction funded by the wallet
+// since the block height, including unmined transactions, until check returns
+// true.
+func (btc *baseWallet) searchWalletSends(ctx context.Context, since int32, check func(*chainhash.Hash, *wire.MsgTx) bool) error {
+	if since < 0 {
+		since = 0
+	}
+	txs, err := btc.node.listTransactionsSinceBlock(since)
+	if err != nil {
+		return fmt.Errorf("error listing transactions since block %d: %w", since, err)
+	}
+	seen := make(map[chainhash.Hash]bool, len(txs))
+	for _, tx := range txs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !tx.Send {
+			continue
+		}
+		hash, err := chainhash.NewHashFromStr(tx.TxID)
+		if err != nil {
+			return fmt.Errorf("error decoding tx hash %s: %w", tx.TxID, err)
+		}
+		if seen[*hash] {
+			continue
+		}
+		seen[*hash] = true
+		txRaw, _, err := btc.rawWalletTx(hash)
+		if err != nil {
+			btc.log.Errorf("Error getting wallet transaction %s: %v", hash, err)
+			continue
+		}
+		msgTx, err := btc.deserializeTx(txRaw)
+		if err != nil {
+			btc.log.Errorf("Error decoding wallet transaction %s: %v", hash, err)
+			continue
+		}
+		if check(hash, msgTx) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// FindDuplicateSwaps searches the wallet's transactions, from shortly before
+// the swap identified by coinID was mined, for other outputs paying to the
+// same contract, e.g. if the swap was broadcast twice. Part of the
+// asset.DuplicateSwapFinder interface.
+func (btc *baseWallet) FindDuplicateSwaps(ctx context.Context, coinID, contract dex.Bytes) ([]dex.Bytes, error) {
+	txHash, vout, err := decodeCoinID(coinID)
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := btc.scriptHashScript(contract)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := btc.node.getBestBlockHeight()
+	if err != nil {
+		return nil, fmt.Errorf("error getting best block height: %w", err)
+	}
+	_, confs, err := btc.rawWalletTx(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("error finding swap transaction %s: %w", txHash, err)
+	}
+	searchFrom := tip - duplicateSwapSearchBuffer
+	if confs > 0 {
+		searchFrom = tip - int32(confs) + 1 - duplicateSwapSearchBuffer
+	}
+
+	var dups []dex.Bytes
+	err = btc.searchWalletSends(ctx, searchFrom, func(hash *chainhash.Hash, msgTx *wire.MsgTx) bool {
+		for i, txOut := range msgTx.TxOut {
+			if *hash == *txHash && uint32(i) == vout {
+				continue
+			}
+			if bytes.Equal(txOut.PkScript, pkScript) {
+				dups = append(dups, ToCoinID(hash, uint32(i)))
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dups, nil
+}
+
+// FindSwapReplacement searches the wallet's recent transactions for one that
+// replaced the swap transaction identified by coinID, e.g. by RBF, and pays to
+// the same contract. If the wallet still has the replaced transaction, the
+// replacement must spend one of its inputs. Part of the
+// asset.SwapReplacementFinder interface.
+func (btc *baseWallet) FindSwapReplacement(ctx context.Context, coinID, contract dex.Bytes) (dex.Bytes, error) {
+	txHash, _, err := decodeCoinID(coinID)
+	if err != nil {
+		return nil, err
+	}
+	pkScript, err := btc.scriptHashScript(contract)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := btc.node.getBestBlockHeight()
+	if err != nil {
+		return nil, fmt.Errorf("error getting best block height: %w", err)
+	}
+
+	// The inputs of the replaced transaction, if the wallet still has it.
+	var prevOuts map[wire.OutPoint]bool
+	if txRaw, _, err := btc.rawWalletTx(txHash); err == nil {
+		if msgTx, err := btc.deserializeTx(txRaw); err == nil {
+			prevOuts = make(map[wire.OutPoint]bool, len(msgTx.TxIn))
+			for _, txIn := range msgTx.TxIn {
+				prevOuts[txIn.PreviousOutPoint] = true
+			}
+		}
+	}
+	spendsPrevOut := func(msgTx *wire.MsgTx) bool {
+		for _, txIn := range msgTx.TxIn {
+			if prevOuts[txIn.PreviousOutPoint] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var replacement dex.Bytes
+	err = btc.searchWalletSends(ctx, tip-swapReplacementSearchBlocks, func(hash *chainhash.Hash, msgTx *wire.MsgTx) bool {
+		if *hash == *txHash || (prevOuts != nil && !spendsPrevOut(msgTx)) {
+			return false
+		}
+		for i, txOut := range msgTx.TxOut {
+			if bytes.Equal(txOut.PkScript, pkScript) {
+				replacement = ToCoinID(hash, uint32(i))
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	if replacement == nil {
+		return nil, fmt.Errorf("no replacement found for swap transaction %s: %w", txHash, asset.CoinNotFoundError)
+	}
+	return replacement, nil
+}
+
 // RegFeeConfirmations gets the number of confirmations for the specified output
 // by first checking for a unspent output, and if not found, searching indexed
 // wallet transactions.
@@ -4938,6 +5615,21 @@ func (btc *baseWallet) ValidateAddress(address string) bool {
 	return err == nil
 }
 
+// CheckAddress checks that the provided address is valid for the wallet's
+// network. Part of the asset.AddressChecker interface.
+func (btc *baseWallet) CheckAddress(address string) error {
+	_, err := btc.decodeAddr(address, btc.chainParams)
+	if err == nil {
+		return nil
+	}
+	for _, params := range btc.cloneParams.OtherChainParams {
+		if _, err := btc.decodeAddr(address, params); err == nil {
+			return dex.NewError(asset.ErrWrongNetworkAddress, fmt.Sprintf("%s address", params.Name))
+		}
+	}
+	return dex.NewError(asset.ErrMalformedAddress, err.Error())
+}
+
 // dummyP2PKHScript only has to be a valid 25-byte pay-to-pubkey-hash pkScript
 // for EstimateSendTxFee when an empty or invalid address is provided.
 var dummyP2PKHScript = []byte{0x76, 0xa9, 0x14, 0xe4, 0x28, 0x61, 0xa,
@@ -4971,7 +5663,7 @@ func (btc *intermediaryWallet) EstimateSendTxFee(address string, sendAmount, fee
 
 	tx := wire.NewMsgTx(btc.txVersion())
 	tx.AddTxOut(wireOP)
-	fee, err = btc.txFeeEstimator.estimateSendTxFee(tx, btc.feeRateWithFallback(feeRate), subtract)
+	fee, err = btc.txFeeEstimator.estimateSendTxFee(tx, btc.sendFeeRate(feeRate), subtract)
 	if err != nil {
 		return 0, false, err
 	}
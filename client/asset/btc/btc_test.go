@@ -29,10 +29,14 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/btcutil/gcs"
+	"github.com/btcsuite/btcd/btcutil/gcs/builder"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcwallet/wallet"
 )
 
 var (
@@ -169,6 +173,9 @@ type testData struct {
 	// returned for all requests. Otherwise the tx id is looked up.
 	getTransactionMap map[string]*GetTransactionResult
 	getTransactionErr error
+	// listTransactions are the wallet's transactions returned by
+	// listsinceblock, regardless of the block requested.
+	listTransactions []*ListTransactionsResult
 
 	getBlockchainInfoErr error
 	unlockErr            error
@@ -181,6 +188,11 @@ type testData struct {
 	listUnspentErr       error
 	tipChanged           chan asset.WalletNotification
 
+	// smartFeeRates are the estimatesmartfee rates, in sats/vbyte, by
+	// confirmation target. If nil, optimalFeeRate is returned for any target.
+	smartFeeRates   map[uint64]uint64
+	smartFeeTargets []uint64
+
 	// spv
 	fetchInputInfoTx  *wire.MsgTx
 	getCFilterScripts map[chainhash.Hash][][]byte
@@ -193,6 +205,15 @@ type testData struct {
 	ownedAddresses    map[string]bool
 	ownsAddress       bool
 	locked            bool
+	gapLimit          uint32
+	// beyondGap are deposits by external address index, which are only found
+	// by a rescan if the index is within the gap limit.
+	beyondGap map[uint32]float64
+	// foundGap are the indexes of the deposits that have been found.
+	foundGap []uint32
+
+	// rpc block filters
+	blockFilterIndex bool
 }
 
 func newTestData() *testData {
@@ -271,7 +292,21 @@ func (c *tRawRequester) RawRequest(_ context.Context, method string, params []js
 		if c.testData.estFeeErr != nil {
 			return nil, c.testData.estFeeErr
 		}
+		var confTarget uint64
+		if err := json.Unmarshal(params[0], &confTarget); err != nil {
+			return nil, err
+		}
+		c.testData.smartFeeTargets = append(c.testData.smartFeeTargets, confTarget)
 		optimalRate := float64(optimalFeeRate) * 1e-5 // ~0.00024
+		if c.testData.smartFeeRates != nil {
+			r, found := c.testData.smartFeeRates[confTarget]
+			if !found {
+				return json.Marshal(&btcjson.EstimateSmartFeeResult{
+					Errors: []string{"insufficient data"},
+				})
+			}
+			optimalRate = float64(r) * 1e-5
+		}
 		return json.Marshal(&btcjson.EstimateSmartFeeResult{
 			Blocks:  2,
 			FeeRate: &optimalRate,
@@ -467,6 +502,23 @@ func (c *tRawRequester) RawRequest(_ context.Context, method string, params []js
 			return nil, WalletTransactionNotFound
 		}
 		return json.Marshal(txData)
+	case methodListSinceBlock:
+		c.blockchainMtx.RLock()
+		defer c.blockchainMtx.RUnlock()
+		txs := make([]btcjson.ListTransactionsResult, 0, len(c.listTransactions))
+		for _, tx := range c.listTransactions {
+			category := "receive"
+			if tx.Send {
+				category = "send"
+			}
+			blockHeight := int32(tx.BlockHeight)
+			txs = append(txs, btcjson.ListTransactionsResult{
+				TxID:        tx.TxID,
+				Category:    category,
+				BlockHeight: &blockHeight,
+			})
+		}
+		return json.Marshal(map[string]any{"transactions": txs})
 	case methodGetBlockchainInfo:
 		c.blockchainMtx.RLock()
 		defer c.blockchainMtx.RUnlock()
@@ -506,6 +558,29 @@ func (c *tRawRequester) RawRequest(_ context.Context, method string, params []js
 		return json.Marshal(&btcjson.GetAddressInfoResult{
 			IsMine: owns,
 		})
+	case methodGetIndexInfo:
+		res := make(map[string]any)
+		if c.blockFilterIndex {
+			res[basicFilterIndexName] = map[string]any{"synced": true}
+		}
+		return json.Marshal(res)
+	case methodGetBlockFilter:
+		var blkHashStr string
+		_ = json.Unmarshal(params[0], &blkHashStr)
+		blkHash, err := chainhash.NewHashFromStr(blkHashStr)
+		if err != nil {
+			return nil, err
+		}
+		c.blockchainMtx.RLock()
+		scripts := c.getCFilterScripts[*blkHash]
+		c.blockchainMtx.RUnlock()
+		scripts = append(scripts, encode.RandomBytes(10))
+		filter, err := gcs.BuildGCSFilter(builder.DefaultP, builder.DefaultM, builder.DeriveKey(blkHash), scripts)
+		if err != nil {
+			return nil, err
+		}
+		b, _ := filter.NBytes()
+		return json.Marshal(map[string]string{"filter": hex.EncodeToString(b)})
 	}
 	panic("method not registered: " + method)
 }
@@ -731,6 +806,7 @@ func tNewWallet(segwit bool, walletType string) (*intermediaryWallet, *testData,
 		Hash:   *bestHash,
 	}
 	wallet.tipMtx.Unlock()
+	wallet.ctx = walletCtx // set by Connect
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -2519,6 +2595,146 @@ func (c *tCoin) String() string { return hex.EncodeToString(c.id) }
 func (c *tCoin) TxID() string   { return hex.EncodeToString(c.id) }
 func (c *tCoin) Value() uint64  { return 100 }
 
+func TestDepositAddressFormat(t *testing.T) {
+	privKey, _ := btcec.NewPrivateKey()
+	wif, err := btcutil.NewWIF(privKey, &chaincfg.MainNetParams, true)
+	if err != nil {
+		t.Fatalf("error encoding wif: %v", err)
+	}
+
+	t.Run("segwit", func(t *testing.T) {
+		wallet, node, shutdown := tNewWallet(true, walletTypeRPC)
+		defer shutdown()
+		node.privKeyForAddr = wif
+
+		// The canonical format for a segwit wallet is bech32.
+		node.newAddress = tP2WPKHAddr
+		addr, err := wallet.DepositAddress()
+		if err != nil {
+			t.Fatalf("DepositAddress error: %v", err)
+		}
+		if addr != tP2WPKHAddr {
+			t.Fatalf("wrong address %s, wanted %s", addr, tP2WPKHAddr)
+		}
+
+		// A legacy address from a segwit wallet is an error.
+		node.newAddress = tP2PKHAddr
+		if _, err = wallet.DepositAddress(); err == nil {
+			t.Fatalf("no error for a legacy address from a segwit wallet")
+		}
+
+		// The canonical format of a taproot address is bech32m.
+		trAddr, err := btcutil.NewAddressTaproot(encode.RandomBytes(32), &chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatalf("NewAddressTaproot error: %v", err)
+		}
+		node.newAddress = trAddr.String()
+		if addr, err = wallet.DepositAddress(); err != nil {
+			t.Fatalf("DepositAddress error for a taproot address: %v", err)
+		}
+		if _, _, v, _ := bech32.DecodeGeneric(addr); v != bech32.VersionM {
+			t.Fatalf("taproot address %s is not bech32m", addr)
+		}
+
+		// Segwit addresses encoded with the checksum of the wrong witness
+		// version are rejected.
+		encodeSegwit := func(witVer byte, prog []byte, v bech32.Version) string {
+			t.Helper()
+			data, err := bech32.ConvertBits(prog, 8, 5, true)
+			if err != nil {
+				t.Fatalf("ConvertBits error: %v", err)
+			}
+			data = append([]byte{witVer}, data...)
+			var s string
+			if v == bech32.VersionM {
+				s, err = bech32.EncodeM("bc", data)
+			} else {
+				s, err = bech32.Encode("bc", data)
+			}
+			if err != nil {
+				t.Fatalf("bech32 encoding error: %v", err)
+			}
+			return s
+		}
+		if err := wallet.checkAddressFormat(trAddr, encodeSegwit(1, trAddr.WitnessProgram(), bech32.Version0)); err == nil || !strings.Contains(err.Error(), "bech32m checksum") {
+			t.Fatalf("wrong error for a bech32 taproot address: %v", err)
+		}
+		wpkhAddr, _ := btcutil.DecodeAddress(tP2WPKHAddr, &chaincfg.MainNetParams)
+		if err := wallet.checkAddressFormat(wpkhAddr, encodeSegwit(0, wpkhAddr.ScriptAddress(), bech32.VersionM)); err == nil || !strings.Contains(err.Error(), "bech32 checksum") {
+			t.Fatalf("wrong error for a bech32m segwit version 0 address: %v", err)
+		}
+
+		// Bitcoin has no legacy format for display.
+		if _, is := any(wallet).(asset.LegacyAddresser); is {
+			t.Fatalf("Bitcoin wallet has a legacy address format")
+		}
+	})
+
+	t.Run("non-segwit", func(t *testing.T) {
+		wallet, node, shutdown := tNewWallet(false, walletTypeRPC)
+		defer shutdown()
+		node.privKeyForAddr = wif
+
+		node.newAddress = tP2PKHAddr
+		addr, err := wallet.DepositAddress()
+		if err != nil {
+			t.Fatalf("DepositAddress error: %v", err)
+		}
+		if addr != tP2PKHAddr {
+			t.Fatalf("wrong address %s, wanted %s", addr, tP2PKHAddr)
+		}
+
+		// An address for a different network is an error.
+		node.newAddress = "mjqAiNeRe8jWzgyJ8FFYF6FRAnQrBLYJqd"
+		if _, err = wallet.DepositAddress(); err == nil {
+			t.Fatalf("no error for a testnet address")
+		}
+	})
+}
+
+func TestLockedCoins(t *testing.T) {
+	wallet, node, shutdown := tNewWallet(true, walletTypeRPC)
+	defer shutdown()
+
+	// Outputs locked in the node by other means are not reported.
+	node.listLockUnspent = []*RPCOutpoint{
+		{TxID: tTxID, Vout: 0},
+		{TxID: tTxID, Vout: 1},
+		{TxID: tTxID, Vout: 2},
+	}
+	wallet.cm.LockUTXOs([]*UTxO{
+		{TxHash: tTxHash, Vout: 0, Amount: 1e6},
+		{TxHash: tTxHash, Vout: 1, Amount: 1e6},
+	})
+	node.txOutRes = newTxOutResult(nil, 1e6, 2)
+
+	coins, err := wallet.LockedCoins()
+	if err != nil {
+		t.Fatalf("LockedCoins error: %v", err)
+	}
+	if len(coins) != 2 {
+		t.Fatalf("expected 2 locked coins, got %d", len(coins))
+	}
+	if coins[0].Value() != 1e6 {
+		t.Fatalf("wrong coin value %d", coins[0].Value())
+	}
+
+	// Spent outputs are skipped.
+	node.txOutRes = nil
+	coins, err = wallet.LockedCoins()
+	if err != nil {
+		t.Fatalf("LockedCoins error for spent outputs: %v", err)
+	}
+	if len(coins) != 0 {
+		t.Fatalf("expected no coins for spent outputs, got %d", len(coins))
+	}
+
+	node.txOutErr = tErr
+	if _, err = wallet.LockedCoins(); err == nil {
+		t.Fatalf("no error for gettxout error")
+	}
+}
+
 func TestReturnCoins(t *testing.T) {
 	wallet, node, shutdown := tNewWallet(true, walletTypeRPC)
 	defer shutdown()
@@ -3133,6 +3349,41 @@ func testSwap(t *testing.T, segwit bool, walletType string) {
 		t.Fatalf("sent fees, %d, less than required fees, %d", feesPaid, minFees)
 	}
 
+	// A simulated swap is constructed but not broadcast, and no change is
+	// locked. No addresses are taken from the wallet.
+	node.sentRawTx = nil
+	node.newAddressErr, node.changeAddrErr = tErr, tErr
+	sim, err := wallet.SimulateSwap(swaps)
+	node.newAddressErr, node.changeAddrErr = nil, nil
+	if err != nil {
+		t.Fatalf("SimulateSwap error: %v", err)
+	}
+	if node.sentRawTx != nil {
+		t.Fatalf("simulated swap was broadcast")
+	}
+	if len(node.lockedCoins) != 1 {
+		t.Fatalf("simulated swap locked coins")
+	}
+	simTx, err := msgTxFromBytes(sim.Tx)
+	if err != nil {
+		t.Fatalf("error decoding simulated swap tx: %v", err)
+	}
+	if simTx.TxHash().String() != sim.TxID {
+		t.Fatalf("wrong simulated swap tx ID")
+	}
+	for _, txIn := range simTx.TxIn {
+		if len(txIn.SignatureScript) > 0 || len(txIn.Witness) > 0 {
+			t.Fatalf("simulated swap tx is signed")
+		}
+	}
+	if len(sim.Contracts) != 1 || simTx.TxOut[0].Value != int64(swapVal) {
+		t.Fatalf("wrong simulated swap contracts")
+	}
+	// The fees are for the signed tx, and no less than the broadcast swap's.
+	if sim.Fees < tBTC.MaxFeeRate*dexbtc.MsgTxVBytes(simTx) || sim.Fees < feesPaid {
+		t.Fatalf("simulated swap fees, %d, less than required fees", sim.Fees)
+	}
+
 	// Not enough funds
 	swaps.Inputs = coins[:1]
 	_, _, _, err = wallet.Swap(swaps)
@@ -3482,6 +3733,70 @@ func TestFindRedemption(t *testing.T) {
 	runRubric(t, testFindRedemption)
 }
 
+func TestRPCBlockFilters(t *testing.T) {
+	wallet, node, shutdown := tNewWallet(true, walletTypeRPC)
+	defer shutdown()
+	rpcNode := wallet.node.(*rpcClient)
+
+	// The filter index must be enabled on the node.
+	if err := rpcNode.checkBlockFilterIndex(); err == nil {
+		t.Fatalf("no error for missing block filter index")
+	}
+	node.blockFilterIndex = true
+	if err := rpcNode.checkBlockFilterIndex(); err != nil {
+		t.Fatalf("checkBlockFilterIndex error: %v", err)
+	}
+
+	rpcNode.rpcConfig.BlockFilters = true
+	defer func() { rpcNode.rpcConfig.BlockFilters = false }()
+
+	otherTxHash, _ := chainhash.NewHashFromStr("7a7b3b5c3638516bc8e7f19b4a3dec00f052a599fed5036c2b89829de2367bb6")
+	secret, _, pkScript, contract, addr, _, _ := makeSwapContract(true, time.Hour*12)
+	otherScript, _ := txscript.PayToAddrScript(addr)
+	inputs := []*wire.TxIn{makeRPCVin(otherTxHash, 0, nil, [][]byte{randBytes(73), randBytes(33)})}
+	contractTx := makeRawTx([]dex.Bytes{otherScript, pkScript}, inputs)
+	contractTxHash := contractTx.TxHash()
+	contractOutPt := NewOutPoint(&contractTxHash, 1)
+
+	redemptionWitness := dexbtc.RedeemP2WSHContract(contract, randBytes(73), randBytes(33), secret)
+	redeemVin := makeRPCVin(&contractTxHash, 1, nil, redemptionWitness)
+	redeemBlockHash, _ := node.addRawTx(node.GetBestBlockHeight()+1, makeRawTx([]dex.Bytes{otherScript}, []*wire.TxIn{redeemVin}))
+
+	newReqs := func() map[OutPoint]*FindRedemptionReq {
+		return map[OutPoint]*FindRedemptionReq{
+			contractOutPt: {
+				outPt:        contractOutPt,
+				pkScript:     pkScript,
+				contractHash: dexbtc.ExtractScriptHash(pkScript),
+				resultChan:   make(chan *FindRedemptionResult, 1),
+			},
+		}
+	}
+
+	// A filter that doesn't match the contract script means the block is
+	// skipped.
+	discovered := rpcNode.searchBlockForRedemptions(tCtx, newReqs(), *redeemBlockHash)
+	if len(discovered) != 0 {
+		t.Fatalf("redemption found in block with non-matching filter")
+	}
+
+	// A matching filter results in a full block search.
+	node.blockchainMtx.Lock()
+	node.getCFilterScripts[*redeemBlockHash] = [][]byte{pkScript}
+	node.blockchainMtx.Unlock()
+	discovered = rpcNode.searchBlockForRedemptions(tCtx, newReqs(), *redeemBlockHash)
+	res, found := discovered[contractOutPt]
+	if !found {
+		t.Fatalf("redemption not found in block with matching filter")
+	}
+	if res.err != nil {
+		t.Fatalf("redemption search error: %v", res.err)
+	}
+	if !bytes.Equal(res.secret, secret) {
+		t.Fatalf("wrong secret. expected %x, got %x", secret, res.secret)
+	}
+}
+
 func testFindRedemption(t *testing.T, segwit bool, walletType string) {
 	wallet, node, shutdown := tNewWallet(segwit, walletType)
 	defer shutdown()
@@ -3773,6 +4088,9 @@ func testSender(t *testing.T, senderType tSenderType, segwit bool, walletType st
 	node.signFunc = func(tx *wire.MsgTx) {
 		signFunc(tx, 0, wallet.segwit)
 	}
+	// With no estimate for the send confirmation target, the suggested fee
+	// rate is used.
+	node.smartFeeRates = map[uint64]uint64{}
 
 	addr := btcAddr(segwit)
 	node.setTxFee = true
@@ -4174,6 +4492,128 @@ func testConfirmations(t *testing.T, segwit bool, walletType string) {
 	}
 }
 
+func TestFindDuplicateSwaps(t *testing.T) {
+	runRubric(t, testFindDuplicateSwaps)
+}
+
+func testFindDuplicateSwaps(t *testing.T, segwit bool, walletType string) {
+	wallet, node, shutdown := tNewWallet(segwit, walletType)
+	defer shutdown()
+
+	_, _, pkScript, contract, _, _, _ := makeSwapContract(segwit, time.Hour*12)
+	otherScript := randBytes(22)
+
+	addTx := func(send bool, pkScripts ...dex.Bytes) *chainhash.Hash {
+		tx := makeRawTx(pkScripts, []*wire.TxIn{makeRPCVin(&chainhash.Hash{byte(len(node.listTransactions) + 1)}, 0, nil, nil)})
+		txB, _ := serializeMsgTx(tx)
+		txHash := tx.TxHash()
+		node.getTransactionMap[txHash.String()] = &GetTransactionResult{TxID: txHash.String(), Bytes: txB}
+		node.listTransactions = append(node.listTransactions, &ListTransactionsResult{TxID: txHash.String(), Send: send})
+		return &txHash
+	}
+
+	swapHash := addTx(true, pkScript, otherScript)
+	coinID := ToCoinID(swapHash, 0)
+	dupHash := addTx(true, otherScript, pkScript)
+	// Unrelated, not funded by the wallet, and another duplicate.
+	addTx(true, otherScript)
+	addTx(false, pkScript)
+	addTx(true, pkScript, otherScript)
+	// The duplicate is listed twice.
+	node.listTransactions = append(node.listTransactions, node.listTransactions[1])
+
+	dups, err := wallet.FindDuplicateSwaps(tCtx, coinID, contract)
+	if err != nil {
+		t.Fatalf("FindDuplicateSwaps error: %v", err)
+	}
+	if len(dups) != 2 || !bytes.Equal(dups[0], ToCoinID(dupHash, 1)) {
+		t.Fatalf("wrong duplicates %v", dups)
+	}
+	dupTxHash, _, _ := decodeCoinID(dups[1])
+	if *dupTxHash == *swapHash || *dupTxHash == *dupHash {
+		t.Fatalf("wrong second duplicate %v", dups[1])
+	}
+
+	// No duplicates.
+	node.listTransactions = node.listTransactions[:1]
+	if dups, err = wallet.FindDuplicateSwaps(tCtx, coinID, contract); err != nil {
+		t.Fatalf("FindDuplicateSwaps error: %v", err)
+	}
+	if len(dups) != 0 {
+		t.Fatalf("unexpected duplicates %v", dups)
+	}
+
+	// Bad coin ID.
+	if _, err = wallet.FindDuplicateSwaps(tCtx, randBytes(35), contract); err == nil {
+		t.Fatalf("no error for bad coin ID")
+	}
+
+	// Swap transaction not found.
+	node.getTransactionErr = tErr
+	if _, err = wallet.FindDuplicateSwaps(tCtx, coinID, contract); err == nil {
+		t.Fatalf("no error for missing swap transaction")
+	}
+	node.getTransactionErr = nil
+}
+
+func TestFindSwapReplacement(t *testing.T) {
+	runRubric(t, testFindSwapReplacement)
+}
+
+func testFindSwapReplacement(t *testing.T, segwit bool, walletType string) {
+	wallet, node, shutdown := tNewWallet(segwit, walletType)
+	defer shutdown()
+
+	_, _, pkScript, contract, _, _, _ := makeSwapContract(segwit, time.Hour*12)
+	otherScript := randBytes(22)
+
+	addTx := func(prevOut *wire.OutPoint, pkScripts ...dex.Bytes) *chainhash.Hash {
+		tx := makeRawTx(pkScripts, []*wire.TxIn{wire.NewTxIn(prevOut, nil, nil)})
+		txB, _ := serializeMsgTx(tx)
+		txHash := tx.TxHash()
+		node.getTransactionMap[txHash.String()] = &GetTransactionResult{TxID: txHash.String(), Bytes: txB}
+		node.listTransactions = append(node.listTransactions, &ListTransactionsResult{TxID: txHash.String(), Send: true})
+		return &txHash
+	}
+
+	swapInput := wire.NewOutPoint(&chainhash.Hash{0x01}, 0)
+	swapHash := addTx(swapInput, otherScript, pkScript)
+	coinID := ToCoinID(swapHash, 1)
+	// A transaction paying to the contract that does not spend the swap's
+	// inputs is not a replacement while the swap is known.
+	otherHash := addTx(wire.NewOutPoint(&chainhash.Hash{0x02}, 0), pkScript)
+	replacementHash := addTx(swapInput, pkScript)
+
+	newCoinID, err := wallet.FindSwapReplacement(tCtx, coinID, contract)
+	if err != nil {
+		t.Fatalf("FindSwapReplacement error: %v", err)
+	}
+	if !bytes.Equal(newCoinID, ToCoinID(replacementHash, 0)) {
+		t.Fatalf("wrong replacement %x", newCoinID)
+	}
+
+	// If the wallet no longer has the swap, any other transaction paying to
+	// the contract is the replacement.
+	delete(node.getTransactionMap, swapHash.String())
+	if newCoinID, err = wallet.FindSwapReplacement(tCtx, coinID, contract); err != nil {
+		t.Fatalf("FindSwapReplacement error: %v", err)
+	}
+	if !bytes.Equal(newCoinID, ToCoinID(otherHash, 0)) {
+		t.Fatalf("wrong replacement %x", newCoinID)
+	}
+
+	// No replacement.
+	node.listTransactions = node.listTransactions[:1]
+	if _, err = wallet.FindSwapReplacement(tCtx, coinID, contract); !errors.Is(err, asset.CoinNotFoundError) {
+		t.Fatalf("wrong error for no replacement: %v", err)
+	}
+
+	// Bad coin ID.
+	if _, err = wallet.FindSwapReplacement(tCtx, randBytes(35), contract); err == nil {
+		t.Fatalf("no error for bad coin ID")
+	}
+}
+
 func TestSendEdges(t *testing.T) {
 	runRubric(t, testSendEdges)
 }
@@ -6431,6 +6871,63 @@ func testIDUnknownTx(t *testing.T, segwit bool) {
 	}
 }
 
+func TestFeeConfTarget(t *testing.T) {
+	wallet, node, shutdown := tNewWallet(true, walletTypeRPC)
+	defer shutdown()
+
+	node.smartFeeRates = map[uint64]uint64{
+		1:   40,
+		6:   20,
+		144: 5,
+	}
+	const feeSuggestion = 30
+	for _, tt := range []struct {
+		confTarget uint64
+		expRate    uint64
+	}{
+		{1, 40},
+		{6, 20},
+		{144, 5},
+		{2, feeSuggestion}, // no estimate
+	} {
+		node.walletCfg.feeConfTarget = tt.confTarget
+		node.smartFeeTargets = nil
+		if r := wallet.sendFeeRate(feeSuggestion); r != tt.expRate {
+			t.Fatalf("wrong send fee rate for %d-block target. wanted %d, got %d", tt.confTarget, tt.expRate, r)
+		}
+		if len(node.smartFeeTargets) != 1 || node.smartFeeTargets[0] != tt.confTarget {
+			t.Fatalf("wrong requested confirmation target. wanted %d, got %v", tt.confTarget, node.smartFeeTargets)
+		}
+	}
+
+	// The FeeRater rate, which core also uses for swaps and redeems, is
+	// unaffected, as are redeems.
+	node.walletCfg.feeConfTarget = 144
+	node.smartFeeTargets = nil
+	if r := wallet.FeeRate(); r != 40 {
+		t.Fatalf("wrong FeeRater rate %d", r)
+	}
+	wallet.targetFeeRateWithFallback(wallet.redeemConfTarget(), 0)
+	if len(node.smartFeeTargets) != 2 || node.smartFeeTargets[0] != 1 || node.smartFeeTargets[1] != defaultRedeemConfTarget {
+		t.Fatalf("fee rates requested for wrong targets %v", node.smartFeeTargets)
+	}
+
+	// Config validation
+	cfg, err := readBaseWalletConfig(&WalletConfig{})
+	if err != nil {
+		t.Fatalf("readBaseWalletConfig error: %v", err)
+	}
+	if cfg.feeConfTarget != defaultFeeConfTarget {
+		t.Fatalf("default fee confirmation target not set")
+	}
+	if _, err = readBaseWalletConfig(&WalletConfig{FeeConfTarget: maxFeeConfTarget}); err != nil {
+		t.Fatalf("error for max fee confirmation target: %v", err)
+	}
+	if _, err = readBaseWalletConfig(&WalletConfig{FeeConfTarget: maxFeeConfTarget + 1}); err == nil {
+		t.Fatalf("no error for fee confirmation target out of range")
+	}
+}
+
 func TestFeeRateCache(t *testing.T) {
 	const okRate = 1
 	var n int
@@ -6513,3 +7010,137 @@ func TestFeeRateCache(t *testing.T) {
 		t.Fatal("counter not incremented for recovered rate")
 	}
 }
+
+func TestValidateImport(t *testing.T) {
+	drv := &Driver{}
+	seed := make([]byte, 32)
+	if err := drv.ValidateImport(walletTypeRPC, seed); err == nil {
+		t.Fatalf("no error for non-SPV wallet type")
+	}
+	if err := drv.ValidateImport(walletTypeSPV, seed[:15]); err == nil {
+		t.Fatalf("no error for short seed")
+	}
+	if err := drv.ValidateImport(walletTypeSPV, make([]byte, 65)); err == nil {
+		t.Fatalf("no error for long seed")
+	}
+	if err := drv.ValidateImport(walletTypeSPV, seed); err != nil {
+		t.Fatalf("ValidateImport error: %v", err)
+	}
+
+	// The BIP-84 test vector. The seed is the BIP-39 seed of the mnemonic
+	// "abandon abandon abandon abandon abandon abandon abandon abandon abandon
+	// abandon abandon about", and the addresses are its first receive
+	// addresses. An imported seed must derive the same addresses, both here
+	// and in the btcwallet created from it.
+	seed, _ = hex.DecodeString("5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc1" +
+		"9a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4")
+	expAddrs := []string{
+		"bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+		"bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g",
+		"bc1qp59yckz4ae5c4efgw2s5wfyvrz0ala7rgvuz8z",
+	}
+	if err := drv.ValidateImport(walletTypeSPV, seed); err != nil {
+		t.Fatalf("ValidateImport error for the test vector: %v", err)
+	}
+	addressStrings := func(addrs []btcutil.Address) []string {
+		strs := make([]string, 0, len(addrs))
+		for _, addr := range addrs {
+			strs = append(strs, addr.String())
+		}
+		return strs
+	}
+	derived, err := seedAddresses(seed, uint32(len(expAddrs)), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("seedAddresses error: %v", err)
+	}
+	if derivedStrs := addressStrings(derived); !reflect.DeepEqual(derivedStrs, expAddrs) {
+		t.Fatalf("wrong derived addresses. wanted %v, got %v", expAddrs, derivedStrs)
+	}
+	loader := wallet.NewLoader(&chaincfg.MainNetParams, t.TempDir(), true, dbTimeout, defaultGapLimit)
+	btcw, err := loader.CreateNewWallet([]byte(wallet.InsecurePubPassphrase), []byte("abc"), seed, time.Now())
+	if err != nil {
+		t.Fatalf("CreateNewWallet error: %v", err)
+	}
+	defer loader.UnloadWallet()
+	walletAddrs, err := (&btcSPVWallet{Wallet: btcw, chainParams: &chaincfg.MainNetParams}).ExternalAddresses(uint32(len(expAddrs)))
+	if err != nil {
+		t.Fatalf("ExternalAddresses error: %v", err)
+	}
+	if walletStrs := addressStrings(walletAddrs); !reflect.DeepEqual(walletStrs, expAddrs) {
+		t.Fatalf("wrong wallet addresses. wanted %v, got %v", expAddrs, walletStrs)
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	wallet, _, shutdown := tNewWallet(true, walletTypeRPC)
+	defer shutdown()
+	wallet.cloneParams.OtherChainParams = otherChainParams(dex.Mainnet)
+	// The test wallet's btcutil.DecodeAddress does not check the network.
+	wallet.decodeAddr = decodeAddress
+
+	pkh := encode.RandomBytes(20)
+	segwitAddr := func(params *chaincfg.Params) string {
+		addr, _ := btcutil.NewAddressWitnessPubKeyHash(pkh, params)
+		return addr.String()
+	}
+	legacyAddr := func(params *chaincfg.Params) string {
+		addr, _ := btcutil.NewAddressPubKeyHash(pkh, params)
+		return addr.String()
+	}
+	badChecksum := []byte(segwitAddr(&chaincfg.MainNetParams))
+	badChecksum[len(badChecksum)-1] ^= 0x01
+
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr error
+	}{
+		{"mainnet segwit", segwitAddr(&chaincfg.MainNetParams), nil},
+		{"mainnet legacy", legacyAddr(&chaincfg.MainNetParams), nil},
+		{"testnet segwit", segwitAddr(&chaincfg.TestNet3Params), asset.ErrWrongNetworkAddress},
+		{"testnet legacy", legacyAddr(&chaincfg.TestNet3Params), asset.ErrWrongNetworkAddress},
+		{"regtest segwit", segwitAddr(&chaincfg.RegressionNetParams), asset.ErrWrongNetworkAddress},
+		{"bad checksum", string(badChecksum), asset.ErrMalformedAddress},
+		{"garbage", "notanaddress", asset.ErrMalformedAddress},
+	}
+	for _, tt := range tests {
+		err := wallet.CheckAddress(tt.addr)
+		if tt.wantErr == nil {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	// Without the other networks' params, a wrong-network address is only
+	// known to be malformed.
+	wallet.cloneParams.OtherChainParams = nil
+	if err := wallet.CheckAddress(segwitAddr(&chaincfg.TestNet3Params)); !errors.Is(err, asset.ErrMalformedAddress) {
+		t.Fatalf("expected malformed address error, got %v", err)
+	}
+}
+
+func TestExplorerURLs(t *testing.T) {
+	const txID = "abcd"
+	tests := []struct {
+		net  dex.Network
+		want string
+	}{
+		{dex.Mainnet, "https://mempool.space/tx/" + txID},
+		{dex.Testnet, "https://mempool.space/testnet/tx/" + txID},
+		{dex.Simnet, ""},
+	}
+	for _, tt := range tests {
+		txURL, addrURL := (&Driver{}).ExplorerURLs(tt.net)
+		if u := strings.ReplaceAll(txURL, asset.ExplorerTxID, txID); u != tt.want {
+			t.Fatalf("%s: wrong URL %q, expected %q", tt.net, u, tt.want)
+		}
+		if addrURL != "" {
+			t.Fatalf("%s: unexpected address URL %q", tt.net, addrURL)
+		}
+	}
+}
@@ -105,8 +105,12 @@ func TestWallet(t *testing.T) {
 
 		time.Sleep(time.Second * 3)
 
+		syncer, ok := w.(asset.SyncStatuser)
+		if !ok {
+			return fmt.Errorf("%s wallet is not a SyncStatuser", name)
+		}
 		for {
-			ss, err := w.SyncStatus()
+			ss, err := syncer.SyncStatus()
 			if err != nil {
 				return fmt.Errorf("SyncStatus error: %w", err)
 			}
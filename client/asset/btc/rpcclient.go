@@ -22,6 +22,8 @@ import (
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/gcs"
+	"github.com/btcsuite/btcd/btcutil/gcs/builder"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
@@ -61,6 +63,12 @@ const (
 	methodGetBlockchainInfo  = "getblockchaininfo"
 	methodFundRawTransaction = "fundrawtransaction"
 	methodListSinceBlock     = "listsinceblock"
+	methodGetBlockFilter     = "getblockfilter"
+	methodGetIndexInfo       = "getindexinfo"
+
+	// basicFilterIndexName is the name of the BIP-158 basic block filter index
+	// as reported by getindexinfo.
+	basicFilterIndexName = "basic block filter index"
 )
 
 // IsTxNotFoundErr will return true if the error indicates that the requested
@@ -193,6 +201,12 @@ func (wc *rpcClient) connect(ctx context.Context, _ *sync.WaitGroup) error {
 		}
 		wc.log.Debug("Using a descriptor wallet.")
 	}
+	if wc.rpcConfig.BlockFilters {
+		if err := wc.checkBlockFilterIndex(); err != nil {
+			return err
+		}
+		wc.log.Info("Using compact block filters for redemption searches.")
+	}
 	return nil
 }
 
@@ -1152,7 +1166,23 @@ func FindRedemptionsInMempool(
 
 // searchBlockForRedemptions attempts to find spending info for the specified
 // contracts by searching every input of all txs in the provided block range.
+// With BlockFilters, blocks whose filter does not match any of the contract
+// scripts are skipped. Filters have false positives, so a matched block is
+// still searched in full.
 func (wc *rpcClient) searchBlockForRedemptions(ctx context.Context, reqs map[OutPoint]*FindRedemptionReq, blockHash chainhash.Hash) (discovered map[OutPoint]*FindRedemptionResult) {
+	if wc.rpcConfig.BlockFilters {
+		scripts := make([][]byte, 0, len(reqs))
+		for _, req := range reqs {
+			scripts = append(scripts, req.pkScript)
+		}
+		matched, err := wc.matchPkScript(&blockHash, scripts)
+		if err != nil {
+			// Fall through to a full block scan.
+			wc.log.Errorf("Error checking block filter for %s: %v", blockHash, err)
+		} else if !matched {
+			return
+		}
+	}
 	msgBlock, err := wc.getBlock(blockHash)
 	if err != nil {
 		wc.log.Errorf("RPC GetBlock error: %v", err)
@@ -1161,6 +1191,53 @@ func (wc *rpcClient) searchBlockForRedemptions(ctx context.Context, reqs map[Out
 	return SearchBlockForRedemptions(ctx, reqs, msgBlock, wc.segwit, wc.hashTx, wc.chainParams)
 }
 
+// checkBlockFilterIndex checks that the node is maintaining the basic block
+// filter index required for compact block filter scans.
+func (wc *rpcClient) checkBlockFilterIndex() error {
+	res := make(map[string]json.RawMessage)
+	if err := wc.call(methodGetIndexInfo, anylist{basicFilterIndexName}, &res); err != nil {
+		if isMethodNotFoundErr(err) {
+			return errors.New("node does not support compact block filters")
+		}
+		return fmt.Errorf("%s error: %w", methodGetIndexInfo, err)
+	}
+	if _, found := res[basicFilterIndexName]; !found {
+		return errors.New("compact block filters requested, but the node's " +
+			"block filter index is not enabled. Set blockfilterindex=1 in the node config")
+	}
+	return nil
+}
+
+// getBlockFilter fetches the BIP-158 basic filter for the block.
+func (wc *rpcClient) getBlockFilter(blockHash *chainhash.Hash) (*gcs.Filter, error) {
+	var res struct {
+		Filter string `json:"filter"`
+	}
+	if err := wc.call(methodGetBlockFilter, anylist{blockHash.String(), "basic"}, &res); err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(res.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding filter for block %s: %w", blockHash, err)
+	}
+	return gcs.FromNBytes(builder.DefaultP, builder.DefaultM, b)
+}
+
+// matchPkScript checks whether the block's compact filter matches any of the
+// supplied scripts. The basic filter commits to both the output scripts and
+// the previous output scripts spent in the block, so a match is possible for
+// blocks that create or spend an output with one of the scripts.
+func (wc *rpcClient) matchPkScript(blockHash *chainhash.Hash, scripts [][]byte) (bool, error) {
+	filter, err := wc.getBlockFilter(blockHash)
+	if err != nil {
+		return false, fmt.Errorf("%s error: %w", methodGetBlockFilter, err)
+	}
+	if filter.N() == 0 {
+		return false, fmt.Errorf("unexpected empty filter for %s", blockHash)
+	}
+	return filter.MatchAny(builder.DeriveKey(blockHash), scripts)
+}
+
 func SearchBlockForRedemptions(
 	ctx context.Context,
 	reqs map[OutPoint]*FindRedemptionReq,
@@ -13,6 +13,7 @@ import (
 	"decred.org/dcrdex/dex"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
@@ -29,6 +30,11 @@ import (
 
 const (
 	dbTimeout = 20 * time.Second
+	// defaultGapLimit is the default gap limit, which is the recovery window
+	// of btcwallet's address discovery. During recovery, addresses are scanned
+	// up to the gap limit past the last used address, and the scan is extended
+	// each time a used address is found. Borrowed from btcwallet directly.
+	defaultGapLimit = 250
 )
 
 // btcSPVWallet implements BTCWallet for Bitcoin.
@@ -48,6 +54,9 @@ type btcSPVWallet struct {
 	// transactions from the wallet db.
 	rescanStarting uint32 // atomic
 
+	// gapLimit is the recovery window of the wallet loader.
+	gapLimit atomic.Uint32
+
 	peerManager *SPVPeerManager
 }
 
@@ -59,7 +68,7 @@ func createSPVWallet(privPass []byte, seed []byte, bday time.Time, walletDir str
 		return fmt.Errorf("error initializing btcwallet+neutrino logging: %w", err)
 	}
 
-	loader := wallet.NewLoader(net, walletDir, true, dbTimeout, 250)
+	loader := wallet.NewLoader(net, walletDir, true, dbTimeout, defaultGapLimit)
 
 	pubPass := []byte(wallet.InsecurePubPassphrase)
 
@@ -111,6 +120,11 @@ func openSPVWallet(dir string, cfg *WalletConfig,
 		chainParams: chainParams,
 		log:         log,
 	}
+	gapLimit := cfg.GapLimit
+	if gapLimit < defaultGapLimit {
+		gapLimit = defaultGapLimit
+	}
+	w.gapLimit.Store(gapLimit)
 	return w
 }
 
@@ -144,8 +158,8 @@ func (w *btcSPVWallet) Start() (SPVService, error) {
 	if err := logNeutrino(w.dir); err != nil {
 		return nil, fmt.Errorf("error initializing btcwallet+neutrino logging: %v", err)
 	}
-	// timeout and recoverWindow arguments borrowed from btcwallet directly.
-	w.loader = wallet.NewLoader(w.chainParams, w.dir, true, dbTimeout, 250)
+	// timeout argument borrowed from btcwallet directly.
+	w.loader = wallet.NewLoader(w.chainParams, w.dir, true, dbTimeout, w.gapLimit.Load())
 
 	exists, err := w.loader.WalletExists()
 	if err != nil {
@@ -300,6 +314,136 @@ func (w *btcSPVWallet) ForceRescan() {
 	}
 }
 
+// GapLimit is the number of unused addresses past the last used address that
+// are scanned during address discovery.
+func (w *btcSPVWallet) GapLimit() uint32 {
+	return w.gapLimit.Load()
+}
+
+// ExtendGapLimit raises the gap limit by n, derives n more addresses on each
+// branch of the default account, and begins a full rescan. The address
+// discovery of the rescan starts from the last derived address, so funds sent
+// to addresses up to n past the previous gap limit are found. The new gap limit
+// is used for address discovery after the wallet is restarted.
+func (w *btcSPVWallet) ExtendGapLimit(n uint32) (uint32, error) {
+	props, err := w.AccountProperties(waddrmgr.KeyScopeBIP0084, defaultAcctNum)
+	if err != nil {
+		return 0, fmt.Errorf("error getting account properties: %w", err)
+	}
+	gapLimit := w.gapLimit.Load() + n
+	// The key counts are one past the last derived index.
+	extIdx, intIdx := props.ExternalKeyCount+n-1, props.InternalKeyCount+n-1
+	if err := extendAddresses(extIdx, intIdx, w.Wallet); err != nil {
+		return 0, fmt.Errorf("error deriving addresses: %w", err)
+	}
+	w.gapLimit.Store(gapLimit)
+	w.log.Infof("Derived addresses through external index %d and internal index %d. Gap limit is now %d.",
+		extIdx, intIdx, gapLimit)
+	return gapLimit, w.RescanAsync()
+}
+
+// UsedNearEdge reports whether any used address of the default account is
+// within margin of the last derived address of its branch.
+func (w *btcSPVWallet) UsedNearEdge(margin uint32) (bool, error) {
+	props, err := w.AccountProperties(waddrmgr.KeyScopeBIP0084, defaultAcctNum)
+	if err != nil {
+		return false, fmt.Errorf("error getting account properties: %w", err)
+	}
+	scopedKeyManager, err := w.Manager.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		return false, err
+	}
+	var nearEdge bool
+	err = walletdb.View(w.Database(), func(dbtx walletdb.ReadTx) error {
+		ns := dbtx.ReadBucket(wAddrMgrBkt)
+		return scopedKeyManager.ForEachAccountAddress(ns, defaultAcctNum, func(addr waddrmgr.ManagedAddress) error {
+			pkAddr, is := addr.(waddrmgr.ManagedPubKeyAddress)
+			if nearEdge || !is {
+				return nil
+			}
+			_, path, ok := pkAddr.DerivationInfo()
+			if !ok {
+				return nil
+			}
+			keyCount := props.ExternalKeyCount
+			if path.Branch == waddrmgr.InternalBranch {
+				keyCount = props.InternalKeyCount
+			}
+			nearEdge = path.Index+margin >= keyCount && addr.Used(ns)
+			return nil
+		})
+	})
+	return nearEdge, err
+}
+
+// ExternalAddresses derives the first n external addresses of the default
+// account from the account key in the wallet database.
+func (w *btcSPVWallet) ExternalAddresses(n uint32) ([]btcutil.Address, error) {
+	scopedKeyManager, err := w.Manager.FetchScopedKeyManager(waddrmgr.KeyScopeBIP0084)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]btcutil.Address, 0, n)
+	err = walletdb.View(w.Database(), func(dbtx walletdb.ReadTx) error {
+		ns := dbtx.ReadBucket(wAddrMgrBkt)
+		for i := uint32(0); i < n; i++ {
+			addr, err := scopedKeyManager.DeriveFromKeyPath(ns, waddrmgr.DerivationPath{
+				InternalAccount: defaultAcctNum,
+				Account:         defaultAcctNum,
+				Branch:          waddrmgr.ExternalBranch,
+				Index:           i,
+			})
+			if err != nil {
+				return err
+			}
+			addrs = append(addrs, addr.Address())
+		}
+		return nil
+	})
+	return addrs, err
+}
+
+// seedAddresses derives the first n external P2WPKH addresses of the BIP84
+// account m/84'/0'/0' of a wallet created from the seed. The non-standard
+// derivation of btcwallet is used, so that the addresses match those of the
+// SPV wallet.
+func seedAddresses(seed []byte, n uint32, chainParams *chaincfg.Params) ([]btcutil.Address, error) {
+	master, err := hdkeychain.NewMaster(seed, chainParams)
+	if err != nil {
+		return nil, err
+	}
+	defer master.Zero()
+	branchKey := master
+	for _, i := range []uint32{
+		hdkeychain.HardenedKeyStart + waddrmgr.KeyScopeBIP0084.Purpose,
+		hdkeychain.HardenedKeyStart + waddrmgr.KeyScopeBIP0084.Coin,
+		hdkeychain.HardenedKeyStart + defaultAcctNum,
+		waddrmgr.ExternalBranch,
+	} {
+		branchKey, err = branchKey.DeriveNonStandard(i) // nolint:staticcheck
+		if err != nil {
+			return nil, err
+		}
+	}
+	addrs := make([]btcutil.Address, 0, n)
+	for i := uint32(0); i < n; i++ {
+		k, err := branchKey.DeriveNonStandard(i) // nolint:staticcheck
+		if err != nil {
+			return nil, err
+		}
+		pubKey, err := k.ECPubKey()
+		if err != nil {
+			return nil, err
+		}
+		addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pubKey.SerializeCompressed()), chainParams)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
 // WalletTransaction pulls the transaction from the database.
 func (w *btcSPVWallet) WalletTransaction(txHash *chainhash.Hash) (*wtxmgr.TxDetails, error) {
 	details, err := wallet.UnstableAPI(w.Wallet).TxDetails(txHash)
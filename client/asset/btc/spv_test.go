@@ -9,6 +9,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
@@ -47,7 +48,21 @@ func (c *tBtcWallet) AccountInfo() XCWalletAccount {
 }
 
 func (c *tBtcWallet) GetTransactions(startBlock, endBlock int32, accountName string, cancel <-chan struct{}) (*wallet.GetTransactionsResult, error) {
-	return nil, nil
+	c.blockchainMtx.RLock()
+	defer c.blockchainMtx.RUnlock()
+	res := new(wallet.GetTransactionsResult)
+	for _, tx := range c.listTransactions {
+		hash, err := chainhash.NewHashFromStr(tx.TxID)
+		if err != nil {
+			return nil, err
+		}
+		summary := wallet.TransactionSummary{Hash: hash}
+		if tx.Send {
+			summary.MyInputs = []wallet.TransactionSummaryInput{{}}
+		}
+		res.UnminedTransactions = append(res.UnminedTransactions, summary)
+	}
+	return res, nil
 }
 
 func (c *tBtcWallet) PublishTransaction(tx *wire.MsgTx, label string) error {
@@ -347,6 +362,31 @@ func (c *tBtcWallet) ForceRescan() {}
 
 func (c *tBtcWallet) RescanAsync() error { return nil }
 
+func (c *tBtcWallet) GapLimit() uint32 {
+	return c.gapLimit
+}
+
+func (c *tBtcWallet) ExtendGapLimit(n uint32) (uint32, error) {
+	c.gapLimit += n
+	for idx, amt := range c.beyondGap {
+		if idx < c.gapLimit {
+			c.getBalances.Mine.Trusted += amt
+			c.foundGap = append(c.foundGap, idx)
+			delete(c.beyondGap, idx)
+		}
+	}
+	return c.gapLimit, nil
+}
+
+func (c *tBtcWallet) UsedNearEdge(margin uint32) (bool, error) {
+	for _, idx := range c.foundGap {
+		if idx+margin >= c.gapLimit {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (c *tBtcWallet) Birthday() time.Time {
 	return time.Time{}
 }
@@ -929,3 +969,147 @@ func TestGetBlockHeader(t *testing.T) {
 	}
 	node.mainchain = prevMainchain // clean up
 }
+
+func TestExtendGapLimit(t *testing.T) {
+	wallet, node, shutdown := tNewWallet(true, walletTypeSPV)
+	defer shutdown()
+	spv := &ExchangeWalletSPV{intermediaryWallet: wallet, spvNode: wallet.node.(*spvWallet)}
+
+	const depositIdx = defaultGapLimit + 5
+	node.gapLimit = defaultGapLimit
+	node.getBalances = &GetBalancesResult{}
+	node.beyondGap = map[uint32]float64{depositIdx: 1}
+
+	balance := func() uint64 {
+		t.Helper()
+		bal, err := spv.Balance()
+		if err != nil {
+			t.Fatalf("Balance error: %v", err)
+		}
+		return bal.Available
+	}
+	if bal := balance(); bal != 0 {
+		t.Fatalf("found funds beyond the gap limit before extending it. balance = %d", bal)
+	}
+
+	if _, err := spv.ExtendGapLimit(0); err == nil {
+		t.Fatalf("no error for zero extension")
+	}
+	if _, err := spv.ExtendGapLimit(maxGapLimitExtension + 1); err == nil {
+		t.Fatalf("no error for excessive extension")
+	}
+
+	// An extension short of the deposit does not find it.
+	gapLimit, err := spv.ExtendGapLimit(5)
+	if err != nil {
+		t.Fatalf("ExtendGapLimit error: %v", err)
+	}
+	if gapLimit != depositIdx {
+		t.Fatalf("wrong gap limit. wanted %d, got %d", depositIdx, gapLimit)
+	}
+	if bal := balance(); bal != 0 {
+		t.Fatalf("found funds beyond the gap limit. balance = %d", bal)
+	}
+
+	if _, err = spv.ExtendGapLimit(10); err != nil {
+		t.Fatalf("ExtendGapLimit error: %v", err)
+	}
+	if gapLimit, err = spv.GapLimit(); err != nil {
+		t.Fatalf("GapLimit error: %v", err)
+	}
+	if gapLimit != depositIdx+10 {
+		t.Fatalf("wrong gap limit. wanted %d, got %d", depositIdx+10, gapLimit)
+	}
+	if bal := balance(); bal != 1e8 {
+		t.Fatalf("funds not found after extending the gap limit. wanted balance %d, got %d", int(1e8), bal)
+	}
+}
+
+func TestAutoExtendGapLimit(t *testing.T) {
+	wallet, node, shutdown := tNewWallet(true, walletTypeSPV)
+	defer shutdown()
+	spv := &ExchangeWalletSPV{intermediaryWallet: wallet, spvNode: wallet.node.(*spvWallet)}
+
+	defer func(d time.Duration) { gapRescanPollInterval = d }(gapRescanPollInterval)
+	gapRescanPollInterval = time.Millisecond
+
+	// Synced.
+	node.getBlockchainInfo = &GetBlockchainInfoResult{Headers: 100, Blocks: 100}
+	blkHash, msgBlock := node.addRawTx(100, dummyTx())
+	node.birthdayTime = msgBlock.Header.Timestamp.Add(-time.Minute)
+	node.mainchain[100] = blkHash
+
+	// Each deposit is found near the end of an extension, so the extension is
+	// repeated until an extension finds nothing near its end.
+	node.gapLimit = defaultGapLimit
+	node.getBalances = &GetBalancesResult{}
+	node.beyondGap = map[uint32]float64{defaultGapLimit + 5: 1, defaultGapLimit + 15: 1}
+
+	gapLimit, err := spv.ExtendGapLimit(10)
+	if err != nil {
+		t.Fatalf("ExtendGapLimit error: %v", err)
+	}
+	if gapLimit != defaultGapLimit+10 {
+		t.Fatalf("wrong gap limit. wanted %d, got %d", defaultGapLimit+10, gapLimit)
+	}
+	timeout := time.After(5 * time.Second)
+	for spv.gapExtending.Load() {
+		select {
+		case <-timeout:
+			t.Fatalf("gap limit extensions not completed")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	if gapLimit, _ = spv.GapLimit(); gapLimit != defaultGapLimit+30 {
+		t.Fatalf("wrong gap limit after automatic extensions. wanted %d, got %d", defaultGapLimit+30, gapLimit)
+	}
+	bal, err := spv.Balance()
+	if err != nil {
+		t.Fatalf("Balance error: %v", err)
+	}
+	if bal.Available != 2e8 {
+		t.Fatalf("funds not found after automatic extensions. wanted balance %d, got %d", int(2e8), bal.Available)
+	}
+}
+
+func TestDerivationVerifier(t *testing.T) {
+	w, _, shutdown := tNewWallet(true, walletTypeSPV)
+	defer shutdown()
+	spv := &ExchangeWalletSPV{intermediaryWallet: w, spvNode: w.node.(*spvWallet)}
+
+	seed := encode.RandomBytes(32)
+	const n = 5
+
+	// The test wallet can't derive addresses.
+	if _, err := spv.SeedAddresses(seed, n); err == nil {
+		t.Fatalf("no error for a wallet that can't derive addresses")
+	}
+
+	loader := wallet.NewLoader(w.chainParams, t.TempDir(), true, dbTimeout, defaultGapLimit)
+	btcw, err := loader.CreateNewWallet([]byte(wallet.InsecurePubPassphrase), []byte("abc"), seed, time.Now())
+	if err != nil {
+		t.Fatalf("CreateNewWallet error: %v", err)
+	}
+	defer loader.UnloadWallet()
+	spv.spvNode.wallet = &btcSPVWallet{Wallet: btcw, chainParams: w.chainParams}
+
+	expected, err := spv.SeedAddresses(seed, n)
+	if err != nil {
+		t.Fatalf("SeedAddresses error: %v", err)
+	}
+	reported, err := spv.WalletAddresses(n)
+	if err != nil {
+		t.Fatalf("WalletAddresses error: %v", err)
+	}
+	if len(expected) != n || !reflect.DeepEqual(expected, reported) {
+		t.Fatalf("addresses don't match. expected %v, reported %v", expected, reported)
+	}
+
+	other, err := spv.SeedAddresses(encode.RandomBytes(32), 1)
+	if err != nil {
+		t.Fatalf("SeedAddresses error: %v", err)
+	}
+	if other[0] == reported[0] {
+		t.Fatalf("different seed derived the same address")
+	}
+}
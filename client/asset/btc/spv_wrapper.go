@@ -202,6 +202,27 @@ func extendAddresses(extIdx, intIdx uint32, btcw *wallet.Wallet) error {
 	})
 }
 
+// gapLimiter is satisfied by BTCWallet implementations that support extending
+// the gap limit of address discovery.
+type gapLimiter interface {
+	GapLimit() uint32
+	// ExtendGapLimit raises the gap limit by n and begins a rescan, returning
+	// the new gap limit.
+	ExtendGapLimit(n uint32) (uint32, error)
+	// UsedNearEdge reports whether any used address of the default account
+	// is within margin of the last derived address of its branch.
+	UsedNearEdge(margin uint32) (bool, error)
+}
+
+// externalAddresser is satisfied by BTCWallet implementations whose default
+// account is the BIP84 account m/84'/0'/0', and that can derive its external
+// addresses from their key store.
+type externalAddresser interface {
+	// ExternalAddresses derives the first n external addresses of the default
+	// account, without recording them as returned.
+	ExternalAddresses(n uint32) ([]btcutil.Address, error)
+}
+
 // spvWallet is an in-process btcwallet.Wallet + neutrino light-filter-based
 // Bitcoin wallet. spvWallet controls an instance of btcwallet.Wallet directly
 // and does not run or connect to the RPC server.
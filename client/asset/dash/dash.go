@@ -5,6 +5,7 @@ package dash
 
 import (
 	"fmt"
+	"time"
 
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/asset/btc"
@@ -65,6 +66,7 @@ var (
 		Name:              "Dash",
 		SupportedVersions: []uint32{version},
 		UnitInfo:          dexdash.UnitInfo,
+		BlockInterval:     150 * time.Second,
 		AvailableWallets: []*asset.WalletDefinition{
 			{
 				Type:              walletTypeRPC,
@@ -91,8 +93,8 @@ func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Ne
 
 // DecodeCoinID creates a human-readable representation of a coin ID for Dash
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Dash and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Info returns basic information about the wallet and asset.
@@ -100,6 +102,18 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return WalletInfo
 }
 
+// explorerTxURLs are the block explorer transaction URL templates by network.
+var explorerTxURLs = map[dex.Network]string{
+	dex.Mainnet: "https://blockexplorer.one/dash/mainnet/tx/" + asset.ExplorerTxID,
+	dex.Testnet: "https://blockexplorer.one/dash/testnet/tx/" + asset.ExplorerTxID,
+}
+
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return explorerTxURLs[net], ""
+}
+
 // MinLotSize calculates the minimum bond size for a given fee rate that avoids
 // dust outputs on the swap and refund txs, assuming the maxFeeRate doesn't
 // change.
@@ -119,6 +119,21 @@ var (
 	conventionalConversionFactor = float64(dexdcr.UnitInfo.Conventional.ConversionFactor)
 	walletBlockAllowance         = time.Second * 10
 
+	// explorerTxURLs are the block explorer transaction URL templates by
+	// network.
+	explorerTxURLs = map[dex.Network]string{
+		dex.Mainnet: "https://explorer.dcrdata.org/tx/" + asset.ExplorerTxID,
+		dex.Testnet: "https://testnet.dcrdata.org/tx/" + asset.ExplorerTxID,
+		dex.Simnet:  "http://127.0.0.1:17779/tx/" + asset.ExplorerTxID, // dcrdata harness
+	}
+	// explorerOutputURLs are the block explorer transaction output URL
+	// templates by network.
+	explorerOutputURLs = map[dex.Network]string{
+		dex.Mainnet: "https://explorer.dcrdata.org/tx/" + asset.ExplorerTxID + "/out/" + asset.ExplorerVout,
+		dex.Testnet: "https://testnet.dcrdata.org/tx/" + asset.ExplorerTxID + "/out/" + asset.ExplorerVout,
+		dex.Simnet:  "http://127.0.0.1:17779/tx/" + asset.ExplorerTxID + "/out/" + asset.ExplorerVout,
+	}
+
 	// maxRedeemMempoolAge is the max amount of time the wallet will let a
 	// redeem transaction sit in mempool from the time it is first seen
 	// until it attempts to abandon it and try to send a new transaction.
@@ -271,6 +286,7 @@ var (
 		Name:              "Decred",
 		SupportedVersions: []uint32{version},
 		UnitInfo:          dexdcr.UnitInfo,
+		BlockInterval:     5 * time.Minute,
 		AvailableWallets: []*asset.WalletDefinition{
 			{
 				Type:             walletTypeSPV,
@@ -488,11 +504,8 @@ func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Ne
 
 // DecodeCoinID creates a human-readable representation of a coin ID for Decred.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	txid, vout, err := decodeCoinID(coinID)
-	if err != nil {
-		return "<invalid>", err
-	}
-	return fmt.Sprintf("%v:%d", txid, vout), err
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Info returns basic information about the wallet and asset.
@@ -500,6 +513,18 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return WalletInfo
 }
 
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return explorerTxURLs[net], ""
+}
+
+// ExplorerOutputURL returns the block explorer URL template for a transaction
+// output on the network. Part of the asset.OutputExplorer interface.
+func (d *Driver) ExplorerOutputURL(net dex.Network) string {
+	return explorerOutputURLs[net]
+}
+
 // Exists checks the existence of the wallet. Part of the Creator interface.
 func (d *Driver) Exists(walletType, dataDir string, _ map[string]string, net dex.Network) (bool, error) {
 	if walletType != walletTypeSPV {
@@ -540,6 +565,19 @@ func (d *Driver) Create(params *asset.CreateWalletParams) error {
 		recoveryCfg.NumInternalAddresses, recoveryCfg.GapLimit, chainParams)
 }
 
+// ValidateImport checks that the seed can be used to create an SPV wallet.
+// ValidateImport satisfies asset.Importer.
+func (d *Driver) ValidateImport(walletType string, seed []byte) error {
+	if walletType != walletTypeSPV {
+		return fmt.Errorf("only %q wallets can be imported, requested %q", walletTypeSPV, walletType)
+	}
+	if len(seed) < hdkeychain.MinSeedBytes || len(seed) > hdkeychain.MaxSeedBytes {
+		return fmt.Errorf("invalid seed length %d. must be between %d and %d bytes",
+			len(seed), hdkeychain.MinSeedBytes, hdkeychain.MaxSeedBytes)
+	}
+	return nil
+}
+
 // MinLotSize calculates the minimum bond size for a given fee rate that avoids
 // dust outputs on the swap and refund txs, assuming the maxFeeRate doesn't
 // change.
@@ -707,6 +745,7 @@ var _ asset.Bonder = (*ExchangeWallet)(nil)
 var _ asset.Authenticator = (*ExchangeWallet)(nil)
 var _ asset.TicketBuyer = (*ExchangeWallet)(nil)
 var _ asset.WalletHistorian = (*ExchangeWallet)(nil)
+var _ asset.DerivationVerifier = (*ExchangeWallet)(nil)
 
 type block struct {
 	height int64
@@ -877,16 +916,20 @@ func openSPVWallet(dataDir string, gapLimit uint32, chainParams *chaincfg.Params
 		return nil, fmt.Errorf("wallet at %q doesn't exists", dir)
 	}
 
-	return &spvWallet{
+	if gapLimit < wallet.DefaultGapLimit {
+		gapLimit = wallet.DefaultGapLimit
+	}
+	w := &spvWallet{
 		dir:         dir,
 		chainParams: chainParams,
 		log:         log.SubLogger("SPV"),
 		blockCache: blockCache{
 			blocks: make(map[chainhash.Hash]*cachedBlock),
 		},
-		tipChan:  make(chan *block, 16),
-		gapLimit: gapLimit,
-	}, nil
+		tipChan: make(chan *block, 16),
+	}
+	w.gapLimit.Store(gapLimit)
+	return w, nil
 }
 
 // Info returns basic information about the wallet and asset.
@@ -4141,6 +4184,76 @@ func (dcr *ExchangeWallet) NewAddress() (string, error) {
 	return dcr.DepositAddress()
 }
 
+// SeedAddresses derives the first n external addresses of a native wallet
+// created from the seed, i.e. the addresses m/44'/coin'/0'/0/i with the
+// SLIP-0044 coin type. Part of the asset.DerivationVerifier interface.
+func (dcr *ExchangeWallet) SeedAddresses(seed []byte, n int) ([]string, error) {
+	if _, is := dcr.wallet.(externalAddresser); !is {
+		return nil, errors.New("wallet does not support derivation verification")
+	}
+	if n < 1 {
+		return nil, nil
+	}
+	return seedAddresses(seed, uint32(n), dcr.chainParams)
+}
+
+// WalletAddresses returns the first n external addresses of the wallet's
+// default account. Part of the asset.DerivationVerifier interface.
+func (dcr *ExchangeWallet) WalletAddresses(n int) ([]string, error) {
+	ea, is := dcr.wallet.(externalAddresser)
+	if !is {
+		return nil, errors.New("wallet does not support derivation verification")
+	}
+	if n < 1 {
+		return nil, nil
+	}
+	addrs, err := ea.ExternalAddresses(dcr.ctx, uint32(n))
+	if err != nil {
+		return nil, err
+	}
+	strs := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		strs = append(strs, addr.String())
+	}
+	return strs, nil
+}
+
+// seedAddresses derives the first n external P2PKH addresses of the account
+// m/44'/coin'/0' of a wallet created from the seed, as dcrwallet does after
+// upgrading to the SLIP-0044 coin type.
+func seedAddresses(seed []byte, n uint32, chainParams *chaincfg.Params) ([]string, error) {
+	master, err := hdkeychain.NewMaster(seed, chainParams)
+	if err != nil {
+		return nil, err
+	}
+	defer master.Zero()
+	branchKey := master
+	for _, i := range []uint32{
+		hdkeychain.HardenedKeyStart + 44,
+		hdkeychain.HardenedKeyStart + chainParams.SLIP0044CoinType,
+		hdkeychain.HardenedKeyStart + defaultAcct,
+		0, // external branch
+	} {
+		branchKey, err = branchKey.Child(i)
+		if err != nil {
+			return nil, err
+		}
+	}
+	addrs := make([]string, 0, n)
+	for i := uint32(0); i < n; i++ {
+		k, err := branchKey.Child(i)
+		if err != nil {
+			return nil, err
+		}
+		addr, err := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(stdaddr.Hash160(k.SerializedPubKey()), chainParams)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr.String())
+	}
+	return addrs, nil
+}
+
 // Unlock unlocks the exchange wallet.
 func (dcr *ExchangeWallet) Unlock(pw []byte) error {
 	// Older SPV wallet potentially need an upgrade while we have a password.
@@ -5305,6 +5418,25 @@ func (dcr *ExchangeWallet) ValidateAddress(address string) bool {
 	return err == nil
 }
 
+// CheckAddress checks that the provided address is valid for the wallet's
+// network. Part of the asset.AddressChecker interface.
+func (dcr *ExchangeWallet) CheckAddress(address string) error {
+	_, err := stdaddr.DecodeAddress(address, dcr.chainParams)
+	if err == nil {
+		return nil
+	}
+	for _, net := range []dex.Network{dex.Mainnet, dex.Testnet, dex.Simnet} {
+		params, _ := parseChainParams(net)
+		if params.Net == dcr.chainParams.Net {
+			continue
+		}
+		if _, err := stdaddr.DecodeAddress(address, params); err == nil {
+			return dex.NewError(asset.ErrWrongNetworkAddress, fmt.Sprintf("%s address", params.Name))
+		}
+	}
+	return dex.NewError(asset.ErrMalformedAddress, err.Error())
+}
+
 // dummyP2PKHScript only has to be a valid 25-byte pay-to-pubkey-hash pkScript
 // for EstimateSendTxFee when an empty or invalid address is provided.
 var dummyP2PKHScript = []byte{0x76, 0xa9, 0x14, 0xe4, 0x28, 0x61, 0xa,
@@ -5057,3 +5057,66 @@ func TestRescanSync(t *testing.T) {
 	checkProgress(true, 1)
 
 }
+
+func TestCheckAddress(t *testing.T) {
+	wallet, _, shutdown := tNewWallet()
+	defer shutdown()
+
+	pkh := encode.RandomBytes(20)
+	addrForNet := func(params *chaincfg.Params) string {
+		addr, _ := stdaddr.NewAddressPubKeyHashEcdsaSecp256k1V0(pkh, params)
+		return addr.String()
+	}
+	badChecksum := []byte(addrForNet(tChainParams))
+	badChecksum[len(badChecksum)-1]++
+
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr error
+	}{
+		{"mainnet", addrForNet(tChainParams), nil},
+		{"testnet", addrForNet(chaincfg.TestNet3Params()), asset.ErrWrongNetworkAddress},
+		{"simnet", addrForNet(chaincfg.SimNetParams()), asset.ErrWrongNetworkAddress},
+		{"bad checksum", string(badChecksum), asset.ErrMalformedAddress},
+		{"garbage", "notanaddress", asset.ErrMalformedAddress},
+	}
+	for _, tt := range tests {
+		err := wallet.CheckAddress(tt.addr)
+		if tt.wantErr == nil {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestExplorerURLs(t *testing.T) {
+	const txID = "abcd"
+	tests := []struct {
+		net     dex.Network
+		want    string
+		wantOut string
+	}{
+		{dex.Mainnet, "https://explorer.dcrdata.org/tx/" + txID, "https://explorer.dcrdata.org/tx/" + txID + "/out/1"},
+		{dex.Testnet, "https://testnet.dcrdata.org/tx/" + txID, "https://testnet.dcrdata.org/tx/" + txID + "/out/1"},
+		{dex.Simnet, "http://127.0.0.1:17779/tx/" + txID, "http://127.0.0.1:17779/tx/" + txID + "/out/1"},
+	}
+	for _, tt := range tests {
+		txURL, addrURL := (&Driver{}).ExplorerURLs(tt.net)
+		if u := strings.ReplaceAll(txURL, asset.ExplorerTxID, txID); u != tt.want {
+			t.Fatalf("%s: wrong URL %q, expected %q", tt.net, u, tt.want)
+		}
+		if addrURL != "" {
+			t.Fatalf("%s: unexpected address URL %q", tt.net, addrURL)
+		}
+		outURL := strings.NewReplacer(asset.ExplorerTxID, txID, asset.ExplorerVout, "1").Replace((&Driver{}).ExplorerOutputURL(tt.net))
+		if outURL != tt.wantOut {
+			t.Fatalf("%s: wrong output URL %q, expected %q", tt.net, outURL, tt.wantOut)
+		}
+	}
+}
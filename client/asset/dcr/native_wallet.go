@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"decred.org/dcrdex/client/asset"
@@ -24,6 +25,9 @@ import (
 
 const (
 	csppConfigFileName = "cspp_config.json"
+	// maxGapLimitExtension is the largest number of addresses by which the
+	// gap limit can be extended at once.
+	maxGapLimitExtension = 10000
 )
 
 var nativeAccounts = []string{defaultAccountName, mixedAccountName, tradingAccountName}
@@ -67,11 +71,14 @@ type NativeWallet struct {
 	spvw               *spvWallet
 
 	mixer mixer
+
+	gapExtending atomic.Bool
 }
 
 // NativeWallet must also satisfy the following interface(s).
 var _ asset.FundsMixer = (*NativeWallet)(nil)
 var _ asset.Rescanner = (*NativeWallet)(nil)
+var _ asset.GapLimiter = (*NativeWallet)(nil)
 
 func initNativeWallet(ew *ExchangeWallet) (*NativeWallet, error) {
 	spvWallet, ok := ew.wallet.(*spvWallet)
@@ -403,3 +410,56 @@ func (w *NativeWallet) Rescan(ctx context.Context, bday uint64) (err error) {
 	}()
 	return <-errC
 }
+
+// GapLimit returns the gap limit of the wallet's address discovery. Part of
+// the asset.GapLimiter interface.
+func (w *NativeWallet) GapLimit() (uint32, error) {
+	return w.spvw.gapLimit.Load(), nil
+}
+
+// ExtendGapLimit raises the gap limit of the wallet's address discovery by n,
+// then asynchronously runs address discovery from the wallet birthday and
+// rescans. dcrwallet's address discovery continues the scan past every used
+// address that it finds, so funds received beyond the new gap limit are found
+// too, as long as no gap between used addresses exceeds it. The new gap limit
+// is returned. Part of the asset.GapLimiter interface.
+func (w *NativeWallet) ExtendGapLimit(n uint32) (uint32, error) {
+	if n == 0 || n > maxGapLimitExtension {
+		return 0, fmt.Errorf("gap limit extension must be between 1 and %d", maxGapLimitExtension)
+	}
+	w.rescan.RLock()
+	rescanInProgress := w.rescan.progress != nil
+	w.rescan.RUnlock()
+	if rescanInProgress {
+		return 0, errors.New("rescan already in progress")
+	}
+	if !w.gapExtending.CompareAndSwap(false, true) {
+		return 0, errors.New("gap limit extension already in progress")
+	}
+	gapLimit := w.spvw.gapLimit.Add(n)
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer w.gapExtending.Store(false)
+		if err := w.discoverAddresses(gapLimit); err != nil {
+			w.log.Errorf("Error discovering addresses with gap limit %d: %v", gapLimit, err)
+			return
+		}
+		if err := w.Rescan(w.ctx, 0); err != nil {
+			w.log.Errorf("Error rescanning after extending the gap limit: %v", err)
+		}
+	}()
+	return gapLimit, nil
+}
+
+// discoverAddresses runs address discovery from the default wallet birthday
+// with the provided gap limit.
+func (w *NativeWallet) discoverAddresses(gapLimit uint32) error {
+	bdayHeight := w.birthdayBlockHeight(w.ctx, defaultWalletBirthdayUnix)
+	startBlock, err := w.spvw.GetBlockHash(w.ctx, int64(bdayHeight))
+	if err != nil {
+		return fmt.Errorf("error getting birthday block hash: %w", err)
+	}
+	w.log.Infof("Discovering addresses from block %d with gap limit %d", bdayHeight, gapLimit)
+	return w.spvw.discoverAddresses(w.ctx, startBlock, gapLimit)
+}
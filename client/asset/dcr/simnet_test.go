@@ -127,8 +127,12 @@ func tBackend(t *testing.T, name string, isInternal bool, blkFunc func(string))
 
 	if isInternal {
 		i := 0
+		syncer, ok := backend.(asset.SyncStatuser)
+		if !ok {
+			t.Fatal("wallet is not a SyncStatuser")
+		}
 		for {
-			ss, err := backend.SyncStatus()
+			ss, err := syncer.SyncStatus()
 			if err != nil {
 				t.Fatal(err)
 			}
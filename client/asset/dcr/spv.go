@@ -107,6 +107,7 @@ type dcrWallet interface {
 	NewVSPTicket(ctx context.Context, hash *chainhash.Hash) (*wallet.VSPTicket, error)
 	RescanProgressFromHeight(ctx context.Context, n wallet.NetworkBackend, startHeight int32, p chan<- wallet.RescanProgress)
 	RescanPoint(ctx context.Context) (*chainhash.Hash, error)
+	DiscoverActiveAddresses(ctx context.Context, n wallet.NetworkBackend, startBlock *chainhash.Hash, discoverAccts bool, gapLimit uint32) error
 }
 
 // Interface for *spv.Syncer so that we can test with a stub.
@@ -158,7 +159,7 @@ type spvWallet struct {
 	spv               spvSyncer // *spv.Syncer
 	bestSpvPeerHeight int32     // atomic
 	tipChan           chan *block
-	gapLimit          uint32
+	gapLimit          atomic.Uint32
 
 	blockCache blockCache
 
@@ -170,6 +171,7 @@ type spvWallet struct {
 
 var _ Wallet = (*spvWallet)(nil)
 var _ tipNotifier = (*spvWallet)(nil)
+var _ externalAddresser = (*spvWallet)(nil)
 
 func createSPVWallet(pw, seed []byte, dataDir string, extIdx, intIdx, gapLimit uint32, chainParams *chaincfg.Params) error {
 	netDir := filepath.Join(dataDir, chainParams.Name)
@@ -381,7 +383,7 @@ func (w *spvWallet) startWallet(ctx context.Context) error {
 		return fmt.Errorf("wallet.OpenDB error: %w", err)
 	}
 
-	dcrw, err := wallet.Open(ctx, newWalletConfig(db, w.chainParams, w.gapLimit))
+	dcrw, err := wallet.Open(ctx, newWalletConfig(db, w.chainParams, w.gapLimit.Load()))
 	if err != nil {
 		// If this function does not return to completion the database must be
 		// closed.  Otherwise, because the database is locked on open, any
@@ -713,6 +715,20 @@ func (w *spvWallet) ExternalAddress(ctx context.Context, accountName string) (st
 	return w.NewExternalAddress(ctx, acctNum, wallet.WithGapPolicyWrap())
 }
 
+// ExternalAddresses derives the first n external addresses of the default
+// account without recording them as returned.
+func (w *spvWallet) ExternalAddresses(ctx context.Context, n uint32) ([]stdaddr.Address, error) {
+	addrs := make([]stdaddr.Address, 0, n)
+	for i := uint32(0); i < n; i++ {
+		addr, err := w.dcrWallet.AddressAtIdx(ctx, defaultAcct, udb.ExternalBranch, i)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
 // InternalAddress returns an internal address using GapPolicyIgnore.
 // Part of the Wallet interface.
 func (w *spvWallet) InternalAddress(ctx context.Context, accountName string) (stdaddr.Address, error) {
@@ -1069,6 +1085,13 @@ func (w *spvWallet) rescan(ctx context.Context, fromHeight int32, c chan wallet.
 	w.dcrWallet.RescanProgressFromHeight(ctx, w.spv, fromHeight, c)
 }
 
+// discoverAddresses runs address discovery from startBlock with the provided
+// gap limit. Accounts are not discovered, since that requires the wallet to be
+// unlocked.
+func (w *spvWallet) discoverAddresses(ctx context.Context, startBlock *chainhash.Hash, gapLimit uint32) error {
+	return w.dcrWallet.DiscoverActiveAddresses(ctx, w.spv, startBlock, false, gapLimit)
+}
+
 // PurchaseTickets purchases n tickets, tells the provided vspd to monitor the
 // ticket, and pays the vsp fee.
 func (w *spvWallet) PurchaseTickets(ctx context.Context, n int, vspHost, vspPubKey string, mixing bool) ([]*asset.Ticket, error) {
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -83,6 +84,8 @@ type tDcrWallet struct {
 	lockedOutpoint   *wire.OutPoint
 	stakeInfo        wallet.StakeInfoData
 	rescanUpdates    []wallet.RescanProgress
+	discoveredGap    uint32
+	discoverErr      error
 }
 
 func (w *tDcrWallet) KnownAddress(ctx context.Context, a stdaddr.Address) (wallet.KnownAddress, error) {
@@ -430,6 +433,11 @@ func (w *tDcrWallet) RescanPoint(ctx context.Context) (*chainhash.Hash, error) {
 	return nil, nil
 }
 
+func (w *tDcrWallet) DiscoverActiveAddresses(ctx context.Context, n wallet.NetworkBackend, startBlock *chainhash.Hash, discoverAccts bool, gapLimit uint32) error {
+	w.discoveredGap = gapLimit
+	return w.discoverErr
+}
+
 func tNewSpvWallet() (*spvWallet, *tDcrWallet) {
 	dcrw := &tDcrWallet{
 		blockInfo:      make(map[int32]*wallet.BlockInfo),
@@ -1050,3 +1058,103 @@ func TestRescan(t *testing.T) {
 	w.rescan.Unlock()
 	ensureErr("rescan already in progress", []wallet.RescanProgress{{}})
 }
+
+func TestExtendGapLimit(t *testing.T) {
+	const tipHeight = 20
+
+	spvw, dcrw := tNewSpvWallet()
+	spvw.gapLimit.Store(wallet.DefaultGapLimit)
+	w := &NativeWallet{
+		ExchangeWallet: &ExchangeWallet{
+			ctx:        tCtx,
+			wallet:     spvw,
+			log:        dex.StdOutLogger("T", dex.LevelInfo),
+			currentTip: &block{height: tipHeight},
+		},
+		spvw: spvw,
+	}
+
+	dcrw.makeBlocks(0, tipHeight)
+	dcrw.rescanUpdates = []wallet.RescanProgress{{}}
+
+	if _, err := w.ExtendGapLimit(0); err == nil {
+		t.Fatalf("no error for zero extension")
+	}
+	if _, err := w.ExtendGapLimit(maxGapLimitExtension + 1); err == nil {
+		t.Fatalf("no error for too large extension")
+	}
+
+	const n = 100
+	gapLimit, err := w.ExtendGapLimit(n)
+	if err != nil {
+		t.Fatalf("ExtendGapLimit error: %v", err)
+	}
+	w.wg.Wait()
+	if gapLimit != wallet.DefaultGapLimit+n {
+		t.Fatalf("wrong gap limit. expected %d, got %d", wallet.DefaultGapLimit+n, gapLimit)
+	}
+	if dcrw.discoveredGap != gapLimit {
+		t.Fatalf("address discovery used gap limit %d, expected %d", dcrw.discoveredGap, gapLimit)
+	}
+	if gl, _ := w.GapLimit(); gl != gapLimit {
+		t.Fatalf("GapLimit returned %d, expected %d", gl, gapLimit)
+	}
+
+	// Rescan in progress error
+	w.rescan.Lock()
+	w.rescan.progress = &rescanProgress{}
+	w.rescan.Unlock()
+	if _, err := w.ExtendGapLimit(n); err == nil {
+		t.Fatalf("no error with rescan in progress")
+	}
+}
+
+func TestDerivationVerifier(t *testing.T) {
+	dataDir := t.TempDir()
+	seed := encode.RandomBytes(32)
+	if err := createSPVWallet([]byte("abc"), seed, dataDir, 0, 0, 0, tChainParams); err != nil {
+		t.Fatalf("createSPVWallet error: %v", err)
+	}
+	db, err := wallet.OpenDB(dbDriver, filepath.Join(dataDir, tChainParams.Name, "spv", walletDbName))
+	if err != nil {
+		t.Fatalf("OpenDB error: %v", err)
+	}
+	defer db.Close()
+	dcrw, err := wallet.Open(tCtx, newWalletConfig(db, tChainParams, 0))
+	if err != nil {
+		t.Fatalf("wallet.Open error: %v", err)
+	}
+	dcr := &ExchangeWallet{
+		ctx:         tCtx,
+		wallet:      &spvWallet{dcrWallet: &extendedWallet{dcrw}},
+		chainParams: tChainParams,
+	}
+
+	const n = 5
+	expected, err := dcr.SeedAddresses(seed, n)
+	if err != nil {
+		t.Fatalf("SeedAddresses error: %v", err)
+	}
+	reported, err := dcr.WalletAddresses(n)
+	if err != nil {
+		t.Fatalf("WalletAddresses error: %v", err)
+	}
+	if len(expected) != n || !reflect.DeepEqual(expected, reported) {
+		t.Fatalf("addresses don't match. expected %v, reported %v", expected, reported)
+	}
+
+	other, err := dcr.SeedAddresses(encode.RandomBytes(32), 1)
+	if err != nil {
+		t.Fatalf("SeedAddresses error: %v", err)
+	}
+	if other[0] == reported[0] {
+		t.Fatalf("different seed derived the same address")
+	}
+
+	// RPC wallets are not created from the seed.
+	rpcWallet, _, shutdown := tNewWallet()
+	defer shutdown()
+	if _, err := rpcWallet.SeedAddresses(seed, n); err == nil {
+		t.Fatalf("no error for RPC wallet")
+	}
+}
@@ -216,6 +216,14 @@ type ticketPager interface {
 	TicketPage(ctx context.Context, scanStart int32, n, skipN int) ([]*asset.Ticket, error)
 }
 
+// externalAddresser is satisfied by a Wallet that can derive the external
+// addresses of the default account from its key store.
+type externalAddresser interface {
+	// ExternalAddresses derives the first n external addresses of the default
+	// account, without recording them as returned.
+	ExternalAddresses(ctx context.Context, n uint32) ([]stdaddr.Address, error)
+}
+
 // TxOutput defines properties of a transaction output, including the
 // details of the block containing the tx, if mined.
 type TxOutput struct {
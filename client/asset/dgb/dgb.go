@@ -5,6 +5,7 @@ package dgb
 
 import (
 	"fmt"
+	"time"
 
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/asset/btc"
@@ -71,6 +72,7 @@ var (
 		Name:              "DigiByte",
 		SupportedVersions: []uint32{version},
 		UnitInfo:          dexdgb.UnitInfo,
+		BlockInterval:     15 * time.Second,
 		AvailableWallets: []*asset.WalletDefinition{{
 			Type:              walletTypeRPC,
 			Tab:               "External",
@@ -96,8 +98,8 @@ func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Ne
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // DigiByte.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// DigiByte and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Info returns basic information about the wallet and asset.
@@ -105,6 +107,18 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return WalletInfo
 }
 
+// explorerTxURLs are the block explorer transaction URL templates by network.
+var explorerTxURLs = map[dex.Network]string{
+	dex.Mainnet: "https://digiexplorer.info/tx/" + asset.ExplorerTxID,
+	dex.Testnet: "https://testnetexplorer.digibyteservers.io/tx/" + asset.ExplorerTxID,
+}
+
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return explorerTxURLs[net], ""
+}
+
 // MinLotSize calculates the minimum bond size for a given fee rate that avoids
 // dust outputs on the swap and refund txs, assuming the maxFeeRate doesn't
 // change.
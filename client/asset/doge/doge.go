@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"time"
 
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/asset/btc"
@@ -91,6 +92,7 @@ var (
 		Name:              "Dogecoin",
 		SupportedVersions: []uint32{version},
 		UnitInfo:          dexdoge.UnitInfo,
+		BlockInterval:     time.Minute,
 		AvailableWallets: []*asset.WalletDefinition{{
 			Type:              walletTypeRPC,
 			Tab:               "External",
@@ -116,8 +118,8 @@ func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Ne
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Dogecoin.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Dogecoin and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Info returns basic information about the wallet and asset.
@@ -125,6 +127,18 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return WalletInfo
 }
 
+// explorerTxURLs are the block explorer transaction URL templates by network.
+var explorerTxURLs = map[dex.Network]string{
+	dex.Mainnet: "https://dogeblocks.com/tx/" + asset.ExplorerTxID,
+	dex.Testnet: "https://blockexplorer.one/dogecoin/testnet/tx/" + asset.ExplorerTxID,
+}
+
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return explorerTxURLs[net], ""
+}
+
 // MinLotSize calculates the minimum bond size for a given fee rate that avoids
 // dust outputs on the swap and refund txs, assuming the maxFeeRate doesn't
 // change.
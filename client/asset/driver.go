@@ -37,6 +37,11 @@ type CreateWalletParams struct {
 	DataDir  string
 	Net      dex.Network
 	Logger   dex.Logger
+	// Imported indicates that the Seed was supplied by the user rather than
+	// derived from the application seed. The seed will have been validated
+	// with the driver's Importer.ValidateImport method. For some assets, the
+	// imported "seed" is a private key.
+	Imported bool
 }
 
 // Driver is the interface required of all exchange wallets.
@@ -128,6 +133,27 @@ func CreateWallet(assetID uint32, seedParams *CreateWalletParams) error {
 	})
 }
 
+// Importer is an optional interface for Creator drivers that can create a
+// seeded wallet from an existing seed or key supplied by the user.
+type Importer interface {
+	// ValidateImport checks that the seed or key is valid for creating a
+	// wallet of the specified type.
+	ValidateImport(walletType string, seed []byte) error
+}
+
+// ValidateImport checks that the seed or key can be imported into a new wallet
+// of the specified type. An error is returned if the asset's driver does not
+// support importing.
+func ValidateImport(assetID uint32, walletType string, seed []byte) error {
+	return withDriver(assetID, func(drv Driver) error {
+		importer, is := drv.(Importer)
+		if !is {
+			return fmt.Errorf("%s wallets do not support importing a seed", dex.BipIDSymbol(assetID))
+		}
+		return importer.ValidateImport(walletType, seed)
+	})
+}
+
 // OpenWallet sets up the asset, returning the exchange wallet.
 func OpenWallet(assetID uint32, cfg *WalletConfig, logger dex.Logger, net dex.Network) (w Wallet, err error) {
 	return w, withDriver(assetID, func(drv Driver) error {
@@ -231,6 +257,16 @@ func TokenInfo(assetID uint32) *Token {
 	return nt.Token
 }
 
+// FeeAsset returns the ID of the asset in which the transaction fees of the
+// specified asset are paid. For tokens, this is the parent asset, e.g. ETH for
+// an ERC20 token. For all other assets, it is the asset itself.
+func FeeAsset(assetID uint32) uint32 {
+	if token := TokenInfo(assetID); token != nil {
+		return token.ParentID
+	}
+	return assetID
+}
+
 // Info returns the WalletInfo for the specified asset, if supported. Info only
 // returns WalletInfo for base chain assets, not tokens.
 func Info(assetID uint32) (*WalletInfo, error) {
@@ -319,6 +355,67 @@ func MinimumLotSize(assetID uint32, maxFeeRate uint64) (minLotSize uint64, found
 	return m.MinLotSize(maxFeeRate), true
 }
 
+// ExplorerTxID and ExplorerAddr are the placeholders for a transaction ID and
+// an address in block explorer URL templates. ExplorerVout is the placeholder
+// for an output index in a transaction output URL template.
+const (
+	ExplorerTxID = "{txid}"
+	ExplorerAddr = "{addr}"
+	ExplorerVout = "{vout}"
+)
+
+// Explorer is a Driver that knows the block explorers for the asset's
+// networks.
+type Explorer interface {
+	// ExplorerURLs returns the block explorer URL templates for transactions
+	// and addresses on the network. A template is empty if there is no known
+	// explorer.
+	ExplorerURLs(net dex.Network) (txURL, addrURL string)
+}
+
+// ExplorerURLs returns the block explorer URL templates for transactions and
+// addresses of a registered asset on the network. Tokens use the explorer of
+// their parent chain.
+func ExplorerURLs(assetID uint32, net dex.Network) (txURL, addrURL string) {
+	driversMtx.RLock()
+	defer driversMtx.RUnlock()
+	if token, is := tokens[assetID]; is {
+		assetID = token.ParentID
+	}
+	explorer, is := drivers[assetID].(Explorer)
+	if !is {
+		return "", ""
+	}
+	return explorer.ExplorerURLs(net)
+}
+
+// OutputExplorer is an Explorer whose block explorers also have pages for
+// transaction outputs.
+type OutputExplorer interface {
+	Explorer
+	// ExplorerOutputURL returns the block explorer URL template for a
+	// transaction output on the network. The template is empty if there is no
+	// known explorer.
+	ExplorerOutputURL(net dex.Network) string
+}
+
+// ExplorerOutputURL returns the block explorer URL template for a transaction
+// output of a registered asset on the network, or an empty string if the
+// asset's explorer has no output pages. Tokens use the explorer of their
+// parent chain.
+func ExplorerOutputURL(assetID uint32, net dex.Network) string {
+	driversMtx.RLock()
+	defer driversMtx.RUnlock()
+	if token, is := tokens[assetID]; is {
+		assetID = token.ParentID
+	}
+	explorer, is := drivers[assetID].(OutputExplorer)
+	if !is {
+		return ""
+	}
+	return explorer.ExplorerOutputURL(net)
+}
+
 func FormatAtoms(assetID uint32, atoms uint64) string {
 	if ui, err := UnitInfo(assetID); err == nil {
 		return ui.FormatAtoms(atoms)
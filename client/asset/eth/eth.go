@@ -62,6 +62,7 @@ func registerToken(tokenID uint32, desc string) {
 		Type:        walletTypeToken,
 		Tab:         "Ethereum token",
 		Description: desc,
+		ConfigOpts:  []*asset.ConfigOption{GasStrategyOpt},
 	}, netAddrs)
 }
 
@@ -82,7 +83,8 @@ const (
 	walletTypeRPC   = "rpc"
 	walletTypeToken = "token"
 
-	providersKey = "providers"
+	providersKey    = "providers"
+	autoBumpFeesKey = "autobumpfees"
 
 	// confCheckTimeout is the amount of time allowed to check for
 	// confirmations. Testing on testnet has shown spikes up to 2.5
@@ -136,6 +138,17 @@ var (
 				"wallet.  Units: gwei / gas",
 			DefaultValue: defaultGasFeeLimit,
 		},
+		GasStrategyOpt,
+		{
+			Key:         autoBumpFeesKey,
+			DisplayName: "Auto-Bump Stuck Swaps",
+			Description: "Automatically replace swap and redeem transactions " +
+				"whose max fee rate has fallen below the network's base fee, " +
+				"instead of asking for confirmation. Refunds are always " +
+				"replaced automatically.",
+			IsBoolean:    true,
+			DefaultValue: false,
+		},
 	}
 	RPCOpts = []*asset.ConfigOption{
 		{
@@ -161,6 +174,7 @@ var (
 		// supported versions before a wallet is available.
 		SupportedVersions: []uint32{0},
 		UnitInfo:          dexeth.UnitInfo,
+		BlockInterval:     12 * time.Second,
 		AvailableWallets: []*asset.WalletDefinition{
 			// {
 			// 	Type:        walletTypeGeth,
@@ -250,7 +264,9 @@ func safeConfsBig(tip uint64, blockNum *big.Int) uint64 {
 
 // WalletConfig are wallet-level configuration settings.
 type WalletConfig struct {
-	GasFeeLimit uint64 `ini:"gasfeelimit"`
+	GasFeeLimit  uint64 `ini:"gasfeelimit"`
+	GasStrategy  string `ini:"gasstrategy"`
+	AutoBumpFees bool   `ini:"autobumpfees"`
 }
 
 // parseWalletConfig parses the settings map into a *WalletConfig.
@@ -328,6 +344,20 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return &wi
 }
 
+// explorerURLs are the block explorer transaction and address URL templates by
+// network.
+var explorerURLs = map[dex.Network][2]string{
+	dex.Mainnet: {"https://etherscan.io/tx/" + asset.ExplorerTxID, "https://etherscan.io/address/" + asset.ExplorerAddr},
+	dex.Testnet: {"https://sepolia.etherscan.io/tx/" + asset.ExplorerTxID, "https://sepolia.etherscan.io/address/" + asset.ExplorerAddr},
+}
+
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	urls := explorerURLs[net]
+	return urls[0], urls[1]
+}
+
 // Exists checks the existence of the wallet.
 func (d *Driver) Exists(walletType, dataDir string, settings map[string]string, net dex.Network) (bool, error) {
 	switch walletType {
@@ -343,6 +373,19 @@ func (d *Driver) Exists(walletType, dataDir string, settings map[string]string,
 	return len(ks.Wallets()) > 0, nil
 }
 
+// ValidateImport checks that the key is a valid secp256k1 private key. An
+// imported Ethereum wallet uses the key directly, rather than deriving a key
+// from a seed. ValidateImport satisfies asset.Importer.
+func (d *Driver) ValidateImport(walletType string, privKey []byte) error {
+	if walletType != walletTypeRPC {
+		return fmt.Errorf("only %q wallets can be imported, requested %q", walletTypeRPC, walletType)
+	}
+	if _, err := crypto.ToECDSA(privKey); err != nil {
+		return fmt.Errorf("invalid private key: %w", err)
+	}
+	return nil
+}
+
 func (d *Driver) Create(cfg *asset.CreateWalletParams) error {
 	comp, err := NetworkCompatibilityData(cfg.Net)
 	if err != nil {
@@ -379,6 +422,7 @@ type ethFetcher interface {
 	getTransaction(context.Context, common.Hash) (*types.Transaction, int64, error)
 	txOpts(ctx context.Context, val, maxGas uint64, maxFeeRate, tipCap, nonce *big.Int) (*bind.TransactOpts, error)
 	currentFees(ctx context.Context) (baseFees, tipCap *big.Int, err error)
+	feeHistory(ctx context.Context, blocks uint64, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
 	unlock(pw string) error
 	getConfirmedNonce(context.Context) (uint64, error)
 	transactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
@@ -410,6 +454,7 @@ var _ asset.AccountLocker = (*ETHWallet)(nil)
 var _ asset.AccountLocker = (*TokenWallet)(nil)
 var _ asset.TokenMaster = (*ETHWallet)(nil)
 var _ asset.WalletRestorer = (*ETHWallet)(nil)
+var _ asset.DerivationVerifier = (*ETHWallet)(nil)
 var _ asset.LiveReconfigurer = (*ETHWallet)(nil)
 var _ asset.LiveReconfigurer = (*TokenWallet)(nil)
 var _ asset.TxFeeEstimator = (*ETHWallet)(nil)
@@ -452,6 +497,9 @@ type baseWallet struct {
 	settings    map[string]string
 
 	gasFeeLimitV uint64 // atomic
+	// autoBumpFees is whether swap and redeem txs with a max fee rate below
+	// the base fee are replaced without asking the user.
+	autoBumpFees atomic.Bool
 
 	walletsMtx sync.RWMutex
 	wallets    map[uint32]*assetWallet
@@ -495,6 +543,10 @@ type assetWallet struct {
 	maxSwapGas   uint64
 	maxRedeemGas uint64
 
+	// gasStrategy is the configured gas strategy for swaps and redeems. nil
+	// if the network's suggested tip should be used.
+	gasStrategy atomic.Pointer[gasStrategy]
+
 	lockedFunds struct {
 		mtx                sync.RWMutex
 		initiateReserves   uint64
@@ -604,11 +656,20 @@ func CreateEVMWallet(chainID int64, createWalletParams *asset.CreateWalletParams
 
 	walletDir := getWalletDir(createWalletParams.DataDir, createWalletParams.Net)
 
-	privateKey, zero, err := privKeyFromSeed(createWalletParams.Seed)
-	if err != nil {
-		return err
+	var privateKey []byte
+	if createWalletParams.Imported {
+		// The imported "seed" is the private key.
+		privateKey = append([]byte(nil), createWalletParams.Seed...)
+		defer encode.ClearBytes(privateKey)
+	} else {
+		var zero func()
+		var err error
+		privateKey, zero, err = privKeyFromSeed(createWalletParams.Seed)
+		if err != nil {
+			return err
+		}
+		defer zero()
 	}
-	defer zero()
 
 	switch createWalletParams.Type {
 	// case walletTypeGeth:
@@ -775,6 +836,7 @@ func NewEVMWallet(cfg *EVMWalletConfig) (w *ETHWallet, err error) {
 		wallets:             make(map[uint32]*assetWallet),
 		multiBalanceAddress: cfg.MultiBalAddress,
 	}
+	eth.autoBumpFees.Store(wCfg.AutoBumpFees)
 
 	var maxSwapGas, maxRedeemGas uint64
 	for _, gases := range cfg.VersionedGases {
@@ -815,6 +877,9 @@ func NewEVMWallet(cfg *EVMWalletConfig) (w *ETHWallet, err error) {
 		pendingTxCheckBal:  new(big.Int),
 		wi:                 cfg.WalletInfo,
 	}
+	if err := aw.setGasStrategy(wCfg.GasStrategy); err != nil {
+		return nil, err
+	}
 
 	maxSwaps, maxRedeems := aw.maxSwapsAndRedeems()
 
@@ -1021,6 +1086,11 @@ func (w *ETHWallet) Reconfigure(ctx context.Context, cfg *asset.WalletConfig, cu
 		gasFeeLimit = defaultGasFeeLimit
 	}
 
+	gasStrategy, err := parseGasStrategy(walletCfg.GasStrategy)
+	if err != nil {
+		return false, err
+	}
+
 	// For now, we only are supporting multiRPCClient nodes. If we re-implement
 	// P2P nodes, we'll have to add protection to the node field to allow for
 	// reconfiguration of type.
@@ -1049,13 +1119,22 @@ func (w *ETHWallet) Reconfigure(ctx context.Context, cfg *asset.WalletConfig, cu
 	w.settingsMtx.Unlock()
 
 	atomic.StoreUint64(&w.baseWallet.gasFeeLimitV, gasFeeLimit)
+	w.autoBumpFees.Store(walletCfg.AutoBumpFees)
+	w.gasStrategy.Store(gasStrategy)
 
 	return false, nil
 }
 
-// Reconfigure attempts to reconfigure the wallet. The token wallet has
-// no configurations.
-func (w *TokenWallet) Reconfigure(context.Context, *asset.WalletConfig, string) (bool, error) {
+// Reconfigure attempts to reconfigure the wallet. The only token wallet
+// configuration is the gas strategy.
+func (w *TokenWallet) Reconfigure(_ context.Context, cfg *asset.WalletConfig, _ string) (bool, error) {
+	tokenCfg, err := parseTokenWalletConfig(cfg.Settings)
+	if err != nil {
+		return false, err
+	}
+	if err := w.setGasStrategy(tokenCfg.GasStrategy); err != nil {
+		return false, err
+	}
 	return false, nil
 }
 
@@ -1196,6 +1275,7 @@ type tokenWalletConfig struct {
 	// LimitAllowance disabled for now.
 	// See https://github.com/decred/dcrdex/pull/1394#discussion_r780479402.
 	// LimitAllowance bool `ini:"limitAllowance"`
+	GasStrategy string `ini:"gasstrategy"`
 }
 
 // parseTokenWalletConfig parses the settings map into a *tokenWalletConfig.
@@ -1282,9 +1362,13 @@ func (w *ETHWallet) OpenTokenWallet(tokenCfg *asset.TokenConfig) (asset.Wallet,
 			Name:              token.Name,
 			SupportedVersions: w.wi.SupportedVersions,
 			UnitInfo:          token.UnitInfo,
+			BlockInterval:     w.wi.BlockInterval,
 		},
 		pendingTxCheckBal: new(big.Int),
 	}
+	if err := aw.setGasStrategy(cfg.GasStrategy); err != nil {
+		return nil, err
+	}
 
 	w.baseWallet.walletsMtx.Lock()
 	w.baseWallet.wallets[tokenCfg.AssetID] = aw
@@ -1390,6 +1474,14 @@ func (w *assetWallet) unlockFunds(amt uint64, t fundReserveType) {
 	*reserve -= amt
 }
 
+// swapFeeReserves returns the amount locked for the fees of redemptions and
+// refunds.
+func (w *assetWallet) swapFeeReserves() uint64 {
+	w.lockedFunds.mtx.RLock()
+	defer w.lockedFunds.mtx.RUnlock()
+	return w.lockedFunds.redemptionReserves + w.lockedFunds.refundReserves
+}
+
 // amountLocked returns the total amount currently locked.
 func (w *assetWallet) amountLocked() uint64 {
 	w.lockedFunds.mtx.RLock()
@@ -1415,11 +1507,19 @@ func (w *assetWallet) balance() (*asset.Balance, error) {
 		available = w.atomize(bal.Current) - locked - w.atomize(bal.PendingOut)
 	}
 
-	return &asset.Balance{
+	balance := &asset.Balance{
 		Available: available,
 		Locked:    locked,
 		Immature:  w.atomize(bal.PendingIn),
-	}, nil
+		Other:     make(map[asset.BalanceCategory]asset.CustomBalance),
+	}
+	if reserves := w.swapFeeReserves(); reserves > 0 {
+		balance.Other[asset.BalanceCategorySwapFeeReserve] = asset.CustomBalance{
+			Amount: reserves,
+			Locked: true,
+		}
+	}
+	return balance, nil
 }
 
 // MaxOrder generates information about the maximum order size and associated
@@ -2203,7 +2303,7 @@ func (w *ETHWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, uint6
 	}
 
 	maxFeeRate := dexeth.GweiToWei(swaps.FeeRate)
-	_, tipRate, err := w.currentNetworkFees(w.ctx)
+	tipRate, err := w.strategicTipRate(w.ctx, maxFeeRate)
 	if err != nil {
 		return fail("Swap: failed to get network tip cap: %w", err)
 	}
@@ -2292,7 +2392,7 @@ func (w *TokenWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, uin
 	}
 
 	maxFeeRate := dexeth.GweiToWei(swaps.FeeRate)
-	_, tipRate, err := w.currentNetworkFees(w.ctx)
+	tipRate, err := w.strategicTipRate(w.ctx, maxFeeRate)
 	if err != nil {
 		return fail("Swap: failed to get network tip cap: %w", err)
 	}
@@ -2457,7 +2557,7 @@ func (w *assetWallet) Redeem(form *asset.RedeemForm, feeWallet *assetWallet, non
 	// If the base fee is higher than the FeeSuggestion we attempt to increase
 	// the gasFeeCap to 2*baseFee. If we don't have enough funds, we use the
 	// funds we have available.
-	baseFee, tipRate, err := w.currentNetworkFees(w.ctx)
+	baseFee, _, err := w.currentNetworkFees(w.ctx)
 	if err != nil {
 		return fail(fmt.Errorf("Error getting net fee state: %w", err))
 	}
@@ -2472,6 +2572,11 @@ func (w *assetWallet) Redeem(form *asset.RedeemForm, feeWallet *assetWallet, non
 		w.log.Warnf("base fee %d > server max fee rate %d. using %d as gas fee cap for redemption", baseFeeGwei, form.FeeSuggestion, gasFeeCap)
 	}
 
+	tipRate, err := w.strategicTipRate(w.ctx, dexeth.GweiToWei(gasFeeCap))
+	if err != nil {
+		return fail(fmt.Errorf("Redeem: failed to get network tip cap: %w", err))
+	}
+
 	tx, err := w.redeem(w.ctx, form.Redemptions, gasFeeCap, tipRate, gasLimit, contractVer)
 	if err != nil {
 		return fail(fmt.Errorf("Redeem: redeem error: %w", err))
@@ -2622,8 +2727,8 @@ func (w *TokenWallet) ApproveToken(assetVer uint32, onConfirm func()) (string, e
 		return "", fmt.Errorf("error getting eth balance: %w", err)
 	}
 	if ethBal.Available < approvalGas*feeRateGwei {
-		return "", fmt.Errorf("insufficient fee balance for approval. required: %d, available: %d",
-			approvalGas*feeRateGwei, ethBal.Available)
+		return "", fmt.Errorf("insufficient fee balance for approval. required: %d, available: %d%s",
+			approvalGas*feeRateGwei, ethBal.Available, reservesNote(w.parent))
 	}
 
 	tx, err := w.approveToken(w.ctx, unlimitedAllowance, approvalGas, maxFeeRate, tipRate, assetVer)
@@ -2672,8 +2777,8 @@ func (w *TokenWallet) UnapproveToken(assetVer uint32, onConfirm func()) (string,
 		return "", fmt.Errorf("error getting eth balance: %w", err)
 	}
 	if ethBal.Available < approvalGas*feeRateGwei {
-		return "", fmt.Errorf("insufficient eth balance for unapproval. required: %d, available: %d",
-			approvalGas*feeRateGwei, ethBal.Available)
+		return "", fmt.Errorf("insufficient eth balance for unapproval. required: %d, available: %d%s",
+			approvalGas*feeRateGwei, ethBal.Available, reservesNote(w.parent))
 	}
 
 	tx, err := w.approveToken(w.ctx, big.NewInt(0), approvalGas, maxFeeRate, tipRate, assetVer)
@@ -3219,12 +3324,23 @@ func (w *ETHWallet) canSend(value uint64, verifyBalance, isPreEstimate bool) (ma
 		}
 
 		if avail < value+maxFee {
-			return 0, nil, nil, fmt.Errorf("available funds %d gwei cannot cover value being sent: need %d gwei + %d gwei max fee", avail, value, maxFee)
+			return 0, nil, nil, fmt.Errorf("available funds %d gwei cannot cover value being sent: need %d gwei + %d gwei max fee%s",
+				avail, value, maxFee, reservesNote(w.assetWallet))
 		}
 	}
 	return
 }
 
+// reservesNote explains that funds are reserved for swap fees, if any are, for
+// errors about insufficient available funds.
+func reservesNote(w *assetWallet) string {
+	reserves := w.swapFeeReserves()
+	if reserves == 0 {
+		return ""
+	}
+	return fmt.Sprintf(". %d %s is reserved for the redemption and refund fees of active swaps", reserves, w.ui.AtomicUnit)
+}
+
 // canSend ensures that the wallet has enough to cover send value and returns
 // the fee rate and max fee required for the send tx.
 func (w *TokenWallet) canSend(value uint64, verifyBalance, isPreEstimate bool) (maxFee uint64, maxFeeRate, tipRate *big.Int, err error) {
@@ -3261,8 +3377,8 @@ func (w *TokenWallet) canSend(value uint64, verifyBalance, isPreEstimate bool) (
 		}
 
 		if ethBal.Available < maxFee {
-			return 0, nil, nil, fmt.Errorf("insufficient balance to cover token transfer fees. %d < %d",
-				ethBal.Available, maxFee)
+			return 0, nil, nil, fmt.Errorf("insufficient balance to cover token transfer fees. %d < %d%s",
+				ethBal.Available, maxFee, reservesNote(w.parent))
 		}
 	}
 	return
@@ -3337,6 +3453,34 @@ func (w *ETHWallet) RestorationInfo(seed []byte) ([]*asset.WalletRestoration, er
 	}, nil
 }
 
+// SeedAddresses derives the account address of a wallet created from the seed.
+// EVM wallets have a single address, so at most one address is returned.
+// Part of the asset.DerivationVerifier interface.
+func (w *ETHWallet) SeedAddresses(seed []byte, n int) ([]string, error) {
+	if n < 1 {
+		return nil, nil
+	}
+	privB, zero, err := privKeyFromSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	defer zero()
+	privateKey, err := crypto.ToECDSA(privB)
+	if err != nil {
+		return nil, err
+	}
+	return []string{crypto.PubkeyToAddress(privateKey.PublicKey).String()}, nil
+}
+
+// WalletAddresses returns the wallet's account address. Part of the
+// asset.DerivationVerifier interface.
+func (w *ETHWallet) WalletAddresses(n int) ([]string, error) {
+	if n < 1 {
+		return nil, nil
+	}
+	return []string{w.addr.String()}, nil
+}
+
 // SwapConfirmations gets the number of confirmations and the spend status
 // for the specified swap.
 func (w *assetWallet) SwapConfirmations(ctx context.Context, coinID dex.Bytes, contract dex.Bytes, _ time.Time) (confs uint32, spent bool, err error) {
@@ -4731,6 +4875,20 @@ func (w *baseWallet) checkPendingTxs() {
 			continue
 		}
 		if txCap.Cmp(baseRate) < 0 {
+			// Refunds are always kept above the base fee. Swaps and redeems
+			// are too if the user has opted in.
+			if pendingTx.Type == asset.Refund || (w.autoBumpFees.Load() &&
+				(pendingTx.Type == asset.Swap || pendingTx.Type == asset.Redeem)) {
+				newPendingTx, err := w.bumpPendingTxFees(i, pendingTx, tx)
+				if err == nil {
+					w.log.Infof("Replaced tx %s, with max fee rate %s below the base fee %s, with tx %s",
+						pendingTx.ID, txCap, baseRate, newPendingTx.ID)
+					w.emitTransactionNote(newPendingTx.WalletTransaction, true)
+					continue
+				}
+				w.log.Errorf("Error replacing tx %s with a max fee rate below the base fee: %v",
+					pendingTx.ID, err)
+			}
 			maxFees := new(big.Int).Add(tipRate, new(big.Int).Mul(baseRate, big.NewInt(2)))
 			maxFees.Mul(maxFees, new(big.Int).SetUint64(tx.Gas()))
 			req := newLowFeeNote(*pendingTx.WalletTransaction, dexeth.WeiToGweiCeil(maxFees))
@@ -4859,38 +5017,46 @@ func (w *assetWallet) userActionBumpFees(actionB []byte) error {
 			pendingTx.actionIgnored = time.Now()
 			return nil
 		}
+		_, err := w.bumpPendingTxFees(idx, pendingTx, tx)
+		return err
+	})
+}
 
-		nonce := new(big.Int).SetUint64(tx.Nonce())
-		maxFeeRate, tipCap, err := w.recommendedMaxFeeRate(w.ctx)
-		if err != nil {
-			return fmt.Errorf("error getting new fee rate: %w", err)
-		}
-		txOpts, err := w.node.txOpts(w.ctx, 0 /* set below */, tx.Gas(), maxFeeRate, tipCap, nonce)
-		if err != nil {
-			return fmt.Errorf("error preparing tx opts: %w", err)
-		}
-		txOpts.Value = tx.Value()
-		addr := tx.To()
-		if addr == nil {
-			return errors.New("pending tx has no recipient?")
-		}
+// bumpPendingTxFees replaces the pending tx at index idx of w.pendingTxs with
+// a tx with the same nonce, recipient, value and data, but with the currently
+// recommended fees.
+//
+// w.nonceMtx must be held.
+func (w *baseWallet) bumpPendingTxFees(idx int, pendingTx *extendedWalletTx, tx *types.Transaction) (*extendedWalletTx, error) {
+	nonce := new(big.Int).SetUint64(tx.Nonce())
+	maxFeeRate, tipCap, err := w.recommendedMaxFeeRate(w.ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting new fee rate: %w", err)
+	}
+	txOpts, err := w.node.txOpts(w.ctx, 0 /* set below */, tx.Gas(), maxFeeRate, tipCap, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing tx opts: %w", err)
+	}
+	txOpts.Value = tx.Value()
+	addr := tx.To()
+	if addr == nil {
+		return nil, errors.New("pending tx has no recipient?")
+	}
 
-		newTx, err := w.node.sendTransaction(w.ctx, txOpts, *addr, tx.Data())
-		if err != nil {
-			return fmt.Errorf("error sending bumped-fee transaction: %w", err)
-		}
+	newTx, err := w.node.sendTransaction(w.ctx, txOpts, *addr, tx.Data())
+	if err != nil {
+		return nil, fmt.Errorf("error sending bumped-fee transaction: %w", err)
+	}
 
-		newPendingTx := w.extendedTx(newTx, pendingTx.Type, pendingTx.Amount, pendingTx.Recipient)
+	newPendingTx := w.extendAndStoreTx(newTx, pendingTx.Type, pendingTx.Amount, pendingTx.TokenID, pendingTx.Recipient)
 
-		pendingTx.NonceReplacement = newPendingTx.ID
-		pendingTx.FeeReplacement = true
+	pendingTx.NonceReplacement = newPendingTx.ID
+	pendingTx.FeeReplacement = true
 
-		w.tryStoreDBTx(pendingTx)
-		w.tryStoreDBTx(newPendingTx)
+	w.tryStoreDBTx(pendingTx)
 
-		w.pendingTxs[idx] = newPendingTx
-		return nil
-	})
+	w.pendingTxs[idx] = newPendingTx
+	return newPendingTx, nil
 }
 
 // tryStoreDBTx attempts to store the DB tx and logs errors internally. This
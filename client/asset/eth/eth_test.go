@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"math/big"
 	"math/rand"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -29,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -130,6 +132,8 @@ type testNode struct {
 	tokenParent     *assetWallet // only set for tokens
 	txConfirmations map[common.Hash]uint32
 	txConfsErr      map[common.Hash]error
+	tipRewards      map[float64][]*big.Int // percentile -> reward by block
+	feeHistErr      error
 }
 
 func newBalance(current, in, out uint64) *Balance {
@@ -204,6 +208,21 @@ func (n *testNode) currentFees(ctx context.Context) (baseFees, tipCap *big.Int,
 	return n.baseFee, n.tip, n.netFeeStateErr
 }
 
+func (n *testNode) feeHistory(_ context.Context, blocks uint64, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	if n.feeHistErr != nil {
+		return nil, n.feeHistErr
+	}
+	h := &ethereum.FeeHistory{Reward: make([][]*big.Int, blocks)}
+	for _, pct := range rewardPercentiles {
+		for i, r := range n.tipRewards[pct] {
+			if i < int(blocks) {
+				h.Reward[i] = append(h.Reward[i], r)
+			}
+		}
+	}
+	return h, nil
+}
+
 func (n *testNode) shutdown() {}
 
 func (n *testNode) bestHeader(ctx context.Context) (*types.Header, error) {
@@ -454,6 +473,7 @@ func (c *tTokenContractor) estimateTransferGas(context.Context, *big.Int) (uint6
 
 type tTxDB struct {
 	storeTxCalled  bool
+	storeTxCounts  map[string]int
 	storeTxErr     error
 	removeTxCalled bool
 	removeTxErr    error
@@ -468,6 +488,9 @@ func (db *tTxDB) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 }
 func (db *tTxDB) storeTx(wt *extendedWalletTx) error {
 	db.storeTxCalled = true
+	if db.storeTxCounts != nil {
+		db.storeTxCounts[wt.ID]++
+	}
 	return db.storeTxErr
 }
 func (db *tTxDB) removeTx(_ /* id */ string) error {
@@ -837,6 +860,109 @@ func TestCheckPendingTxs(t *testing.T) {
 	}
 }
 
+func TestStuckBelowBaseFee(t *testing.T) {
+	_, eth, node, shutdown := tassetWallet(BipID)
+	defer shutdown()
+
+	emitChan := make(chan asset.WalletNotification, 128)
+	eth.emit = asset.NewWalletEmitter(emitChan, BipID, eth.log)
+	txDB := eth.txDB.(*tTxDB)
+
+	const feeCap = 50 // gwei
+	tip := uint64(12552)
+	mature := time.Now().Add(-time.Minute * 10)
+	replacementTx := node.newTransaction(0, dexeth.GweiToWei(1))
+
+	checkPending := func(txType asset.TransactionType, baseFee uint64) *extendedWalletTx {
+		t.Helper()
+		tx, _ := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			GasTipCap: dexeth.GweiToWei(2),
+			GasFeeCap: dexeth.GweiToWei(feeCap),
+			Gas:       50_000,
+			To:        &common.Address{0x01},
+			ChainID:   node.chainConfig().ChainID,
+		}), signer, node.privKey)
+		pendingTx := eth.extendedTx(tx, txType, 1, nil)
+		pendingTx.lastFeeCheck = mature
+		node.receipts[pendingTx.txHash] = &types.Receipt{}
+		node.receiptTxs[pendingTx.txHash] = tx
+		eth.confirmedNonceAt = pendingTx.Nonce
+		eth.pendingNonceAt = new(big.Int).Add(pendingTx.Nonce, big.NewInt(1))
+		eth.pendingTxs = []*extendedWalletTx{pendingTx}
+		// A new block with a new base fee.
+		tip++
+		eth.currentTip = &types.Header{Number: new(big.Int).SetUint64(tip)}
+		node.baseFee = dexeth.GweiToWei(baseFee)
+		node.sendTxTx = replacementTx
+		node.sentTxs = 0
+		txDB.storeTxCounts = make(map[string]int)
+		eth.checkPendingTxs()
+		return pendingTx
+	}
+
+	getAction := func() string {
+		for {
+			select {
+			case ni := <-emitChan:
+				if n, ok := ni.(*asset.ActionRequiredNote); ok {
+					return n.ActionID
+				}
+			default:
+				return ""
+			}
+		}
+	}
+
+	checkAction := func(tag, expAction string, expReplaced bool, pendingTx *extendedWalletTx) {
+		t.Helper()
+		if actionID := getAction(); actionID != expAction {
+			t.Fatalf("%s: expected action %q, got %q", tag, expAction, actionID)
+		}
+		replaced := eth.pendingTxs[0] != pendingTx
+		if replaced != expReplaced {
+			t.Fatalf("%s: expected replaced = %t, got %t", tag, expReplaced, replaced)
+		}
+		if replaced && (!pendingTx.FeeReplacement || pendingTx.NonceReplacement != eth.pendingTxs[0].ID) {
+			t.Fatalf("%s: replaced tx not marked as a fee replacement", tag)
+		}
+		if n := txDB.storeTxCounts[eth.pendingTxs[0].ID]; replaced && n != 1 {
+			t.Fatalf("%s: expected replacement tx to be stored once, got %d", tag, n)
+		}
+	}
+
+	// The base fee is below the tx's max fee rate.
+	pendingTx := checkPending(asset.Swap, feeCap/2)
+	checkAction("below fee cap", "", false, pendingTx)
+
+	// The base fee rises past the tx's max fee rate. The user is asked to
+	// bump the fees.
+	pendingTx = checkPending(asset.Swap, feeCap*2)
+	checkAction("swap above fee cap", actionTypeTooCheap, false, pendingTx)
+	pendingTx = checkPending(asset.Redeem, feeCap*2)
+	checkAction("redeem above fee cap", actionTypeTooCheap, false, pendingTx)
+
+	// Refunds are always bumped.
+	pendingTx = checkPending(asset.Refund, feeCap*2)
+	checkAction("refund above fee cap", "", true, pendingTx)
+
+	// With auto-bump enabled, swaps and redeems are bumped too, but other
+	// txs are not.
+	eth.autoBumpFees.Store(true)
+	defer eth.autoBumpFees.Store(false)
+	pendingTx = checkPending(asset.Swap, feeCap*2)
+	checkAction("auto-bump swap", "", true, pendingTx)
+	pendingTx = checkPending(asset.Redeem, feeCap*2)
+	checkAction("auto-bump redeem", "", true, pendingTx)
+	pendingTx = checkPending(asset.Send, feeCap*2)
+	checkAction("auto-bump send", actionTypeTooCheap, false, pendingTx)
+
+	// If the replacement fails, the user is asked instead.
+	node.sendTxErr = errors.New("test error")
+	pendingTx = checkPending(asset.Refund, feeCap*2)
+	node.sendTxErr = nil
+	checkAction("refund replacement error", actionTypeTooCheap, false, pendingTx)
+}
+
 func TestTakeAction(t *testing.T) {
 	_, eth, node, shutdown := tassetWallet(BipID)
 	defer shutdown()
@@ -3996,6 +4122,47 @@ func TestDriverExists(t *testing.T) {
 	}
 }
 
+func TestDriverImport(t *testing.T) {
+	drv := &Driver{}
+	privKey, _ := hex.DecodeString("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	expAddr := common.HexToAddress("0x2c7536E3605D9C16a7a3D7b1898e529396a65c23")
+
+	if err := drv.ValidateImport(walletTypeGeth, privKey); err == nil {
+		t.Fatalf("no error for wrong wallet type")
+	}
+	if err := drv.ValidateImport(walletTypeRPC, privKey[1:]); err == nil {
+		t.Fatalf("no error for short private key")
+	}
+	if err := drv.ValidateImport(walletTypeRPC, privKey); err != nil {
+		t.Fatalf("ValidateImport error: %v", err)
+	}
+
+	tmpDir := t.TempDir()
+	err := CreateEVMWallet(dexeth.ChainIDs[dex.Simnet], &asset.CreateWalletParams{
+		Type:     walletTypeRPC,
+		Seed:     privKey,
+		Pass:     encode.RandomBytes(32),
+		Settings: map[string]string{providersKey: "a.ipc"},
+		DataDir:  tmpDir,
+		Net:      dex.Simnet,
+		Logger:   tLogger,
+		Imported: true,
+	}, &testnetCompatibilityData, true)
+	if err != nil {
+		t.Fatalf("CreateEVMWallet error: %v", err)
+	}
+
+	keyStoreDir := filepath.Join(getWalletDir(tmpDir, dex.Simnet), "keystore")
+	ks := keystore.NewKeyStore(keyStoreDir, keystore.LightScryptN, keystore.LightScryptP)
+	accts := ks.Accounts()
+	if len(accts) != 1 {
+		t.Fatalf("expected 1 account, got %d", len(accts))
+	}
+	if accts[0].Address != expAddr {
+		t.Fatalf("wrong imported address. expected %s, got %s", expAddr, accts[0].Address)
+	}
+}
+
 func TestDriverDecodeCoinID(t *testing.T) {
 	drv := &Driver{}
 	addressStr := "0xB6De8BB5ed28E6bE6d671975cad20C03931bE981"
@@ -4483,6 +4650,112 @@ func TestReconfigure(t *testing.T) {
 	}
 }
 
+func TestGasStrategy(t *testing.T) {
+	w, eth, node, shutdown := tassetWallet(BipID)
+	defer shutdown()
+
+	gweis := func(vs ...uint64) []*big.Int {
+		rewards := make([]*big.Int, len(vs))
+		for i, v := range vs {
+			rewards[i] = dexeth.GweiToWei(v)
+		}
+		return rewards
+	}
+	// One outlier block per percentile, which the median ignores.
+	node.tipRewards = map[float64][]*big.Int{
+		10: gweis(3, 4, 2, 3, 40),
+		50: gweis(5, 6, 4, 5, 100),
+		90: gweis(12, 10, 11, 9, 200),
+	}
+	maxFeeRate := dexeth.GweiToWei(100)
+
+	tipFor := func(setting string) *big.Int {
+		t.Helper()
+		if err := eth.setGasStrategy(setting); err != nil {
+			t.Fatalf("error setting gas strategy %q: %v", setting, err)
+		}
+		tipRate, err := eth.strategicTipRate(context.Background(), maxFeeRate)
+		if err != nil {
+			t.Fatalf("error getting tip rate for gas strategy %q: %v", setting, err)
+		}
+		return tipRate
+	}
+
+	economical, standard, fast := tipFor("economical"), tipFor("Standard"), tipFor(" fast ")
+	if economical.Cmp(dexeth.GweiToWei(3)) != 0 || standard.Cmp(dexeth.GweiToWei(5)) != 0 ||
+		fast.Cmp(dexeth.GweiToWei(11)) != 0 {
+		t.Fatalf("wrong tip rates. economical = %s, standard = %s, fast = %s", economical, standard, fast)
+	}
+	if economical.Cmp(standard) >= 0 || standard.Cmp(fast) >= 0 {
+		t.Fatalf("tip rates out of order. economical = %s, standard = %s, fast = %s", economical, standard, fast)
+	}
+
+	// Explicit tip.
+	if tipRate := tipFor("7.5"); tipRate.Cmp(big.NewInt(7.5e9)) != 0 {
+		t.Fatalf("wrong explicit tip rate %s", tipRate)
+	}
+
+	// No strategy uses the network's suggested tip.
+	if tipRate := tipFor(""); tipRate.Cmp(node.tip) != 0 {
+		t.Fatalf("wrong suggested tip rate %s", tipRate)
+	}
+
+	// The tip is limited to the max fee rate.
+	maxFeeRate = dexeth.GweiToWei(8)
+	if tipRate := tipFor("fast"); tipRate.Cmp(maxFeeRate) != 0 {
+		t.Fatalf("tip rate %s not limited to max fee rate", tipRate)
+	}
+
+	// The minimum tip is enforced.
+	node.tipRewards[10] = gweis(1, 1, 1)
+	if tipRate := tipFor("economical"); tipRate.Cmp(dexeth.GweiToWei(dexeth.MinGasTipCap)) != 0 {
+		t.Fatalf("tip rate %s below minimum", tipRate)
+	}
+
+	// Fee history errors.
+	node.feeHistErr = errors.New("test error")
+	if _, err := eth.strategicTipRate(context.Background(), maxFeeRate); err == nil {
+		t.Fatal("no error for fee history error")
+	}
+	node.feeHistErr = nil
+	node.tipRewards = nil
+	if _, err := eth.strategicTipRate(context.Background(), maxFeeRate); err == nil {
+		t.Fatal("no error for empty fee history")
+	}
+
+	for _, bad := range []string{"turbo", "0", "-1", "NaN", "Inf"} {
+		if err := eth.setGasStrategy(bad); err == nil {
+			t.Fatalf("no error for invalid gas strategy %q", bad)
+		}
+	}
+
+	// The strategy is persisted with the wallet settings.
+	reconfigure := func(w asset.Wallet, setting string) error {
+		settings, _ := config.Mapify(&WalletConfig{GasStrategy: setting})
+		_, err := w.(asset.LiveReconfigurer).Reconfigure(context.Background(),
+			&asset.WalletConfig{Type: walletTypeRPC, Settings: settings}, "")
+		return err
+	}
+	if err := reconfigure(w, "economical"); err != nil {
+		t.Fatalf("reconfigure error: %v", err)
+	}
+	if s := eth.gasStrategy.Load(); s == nil || s.name != gasStrategyEconomical {
+		t.Fatalf("gas strategy not reconfigured")
+	}
+	if err := reconfigure(w, "turbo"); err == nil {
+		t.Fatal("no error reconfiguring with invalid gas strategy")
+	}
+
+	tw, tokenWallet, _, tokenShutdown := tassetWallet(usdcTokenID)
+	defer tokenShutdown()
+	if err := reconfigure(tw, "3"); err != nil {
+		t.Fatalf("token reconfigure error: %v", err)
+	}
+	if s := tokenWallet.gasStrategy.Load(); s == nil || s.fixed.Cmp(dexeth.GweiToWei(3)) != 0 {
+		t.Fatalf("token gas strategy not reconfigured")
+	}
+}
+
 func TestSend(t *testing.T) {
 	t.Run("eth", func(t *testing.T) { testSend(t, BipID) })
 	t.Run("token", func(t *testing.T) { testSend(t, usdcTokenID) })
@@ -4504,15 +4777,27 @@ func testSend(t *testing.T, assetID uint32) {
 
 	const val = 10e9
 	const testAddr = "dd93b447f7eBCA361805eBe056259853F3912E04"
+	// Fees are paid from, and swap fees are reserved in, the ETH wallet.
+	feeWallet := eth
+	if assetID != BipID {
+		feeWallet = node.tokenParent
+	}
+
 	tests := []struct {
 		name              string
 		sendAdj, feeAdj   uint64
+		reserves          uint64
 		balErr, sendTxErr error
 		addr              string
 		wantErr           bool
 	}{{
 		name: "ok",
 		addr: testAddr,
+	}, {
+		name:     "would spend swap fee reserves",
+		reserves: 1,
+		wantErr:  true,
+		addr:     testAddr,
 	}, {
 		name:    "balance error",
 		balErr:  errors.New("test error"),
@@ -4544,6 +4829,7 @@ func testSend(t *testing.T, assetID uint32) {
 		node.setBalanceError(eth, test.balErr)
 		node.sendTxErr = test.sendTxErr
 		node.tokenContractor.transferErr = test.sendTxErr
+		feeWallet.lockedFunds.redemptionReserves = test.reserves
 		if assetID == BipID {
 			node.bal = dexeth.GweiToWei(val + ethFees - test.sendAdj - test.feeAdj)
 		} else {
@@ -4555,6 +4841,9 @@ func testSend(t *testing.T, assetID uint32) {
 			if err == nil {
 				t.Fatalf("expected error for test %v", test.name)
 			}
+			if test.reserves > 0 && !strings.Contains(err.Error(), "reserved") {
+				t.Fatalf("error for test %v does not explain the reserves: %v", test.name, err)
+			}
 			continue
 		}
 		if err != nil {
@@ -4564,6 +4853,23 @@ func testSend(t *testing.T, assetID uint32) {
 			t.Fatal("coin is not the tx hash")
 		}
 	}
+
+	// The reserves are itemized in the ETH balance.
+	node.setBalanceError(eth, nil)
+	feeWallet.lockedFunds.redemptionReserves = 2
+	feeWallet.lockedFunds.refundReserves = 3
+	defer func() {
+		feeWallet.lockedFunds.redemptionReserves = 0
+		feeWallet.lockedFunds.refundReserves = 0
+	}()
+	bal, err := feeWallet.Balance()
+	if err != nil {
+		t.Fatalf("balance error: %v", err)
+	}
+	reserves, found := bal.Other[asset.BalanceCategorySwapFeeReserve]
+	if !found || reserves.Amount != 5 || !reserves.Locked {
+		t.Fatalf("wrong swap fee reserve balance: %+v", reserves)
+	}
 }
 
 func TestConfirmRedemption(t *testing.T) {
@@ -5327,3 +5633,54 @@ func parseRecoveryID(c asset.Coin) []byte {
 func randomHash() common.Hash {
 	return common.BytesToHash(encode.RandomBytes(20))
 }
+
+func TestDerivationVerifier(t *testing.T) {
+	w, eth, _, shutdown := tassetWallet(BipID)
+	defer shutdown()
+	verifier := w.(asset.DerivationVerifier)
+
+	seed := encode.RandomBytes(32)
+	privB, zero, err := privKeyFromSeed(seed)
+	if err != nil {
+		t.Fatalf("privKeyFromSeed error: %v", err)
+	}
+	privKey, err := crypto.ToECDSA(privB)
+	zero()
+	if err != nil {
+		t.Fatalf("ToECDSA error: %v", err)
+	}
+	eth.addr = crypto.PubkeyToAddress(privKey.PublicKey)
+
+	expected, err := verifier.SeedAddresses(seed, 5)
+	if err != nil {
+		t.Fatalf("SeedAddresses error: %v", err)
+	}
+	reported, err := verifier.WalletAddresses(len(expected))
+	if err != nil {
+		t.Fatalf("WalletAddresses error: %v", err)
+	}
+	if len(expected) != 1 || len(reported) != 1 || expected[0] != reported[0] {
+		t.Fatalf("addresses don't match. expected %v, reported %v", expected, reported)
+	}
+
+	other, err := verifier.SeedAddresses(encode.RandomBytes(32), 1)
+	if err != nil {
+		t.Fatalf("SeedAddresses error: %v", err)
+	}
+	if other[0] == reported[0] {
+		t.Fatalf("different seed derived the same address")
+	}
+}
+
+func TestExplorerURLs(t *testing.T) {
+	txURL, addrURL := (&Driver{}).ExplorerURLs(dex.Testnet)
+	if txURL != "https://sepolia.etherscan.io/tx/"+asset.ExplorerTxID {
+		t.Fatalf("wrong tx URL %q", txURL)
+	}
+	if addrURL != "https://sepolia.etherscan.io/address/"+asset.ExplorerAddr {
+		t.Fatalf("wrong address URL %q", addrURL)
+	}
+	if txURL, addrURL = (&Driver{}).ExplorerURLs(dex.Simnet); txURL != "" || addrURL != "" {
+		t.Fatalf("unexpected simnet URLs %q, %q", txURL, addrURL)
+	}
+}
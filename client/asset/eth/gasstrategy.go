@@ -0,0 +1,140 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"decred.org/dcrdex/client/asset"
+	dexeth "decred.org/dcrdex/dex/networks/eth"
+)
+
+const (
+	gasStrategyKey = "gasstrategy"
+
+	gasStrategyEconomical = "economical"
+	gasStrategyStandard   = "standard"
+	gasStrategyFast       = "fast"
+
+	// feeHistoryBlocks is the number of recent blocks from which the tip
+	// rewards are sampled.
+	feeHistoryBlocks = 20
+)
+
+// gasStrategyPercentiles are the percentiles of the tip rewards paid in
+// recent blocks that are targeted by the named gas strategies.
+var gasStrategyPercentiles = map[string]float64{
+	gasStrategyEconomical: 10,
+	gasStrategyStandard:   50,
+	gasStrategyFast:       90,
+}
+
+// GasStrategyOpt is the config option for selecting the gas price strategy for
+// swap and redeem transactions. Refunds always use the network's suggested
+// fees.
+var GasStrategyOpt = &asset.ConfigOption{
+	Key:         gasStrategyKey,
+	DisplayName: "Swap Gas Price Strategy",
+	Description: "The tip paid on swap and redeem transactions. One of " +
+		"economical, standard, or fast, which target the 10th, 50th, and " +
+		"90th percentile of the tips paid in recent blocks, or an explicit " +
+		"tip in gwei / gas. Leave empty to use the tip suggested by the " +
+		"RPC provider. The tip is always limited by the transaction's max " +
+		"fee rate.",
+}
+
+// gasStrategy determines the tip cap for swap and redeem transactions.
+type gasStrategy struct {
+	name string
+	// percentile is the targeted percentile of recent tip rewards. Only used
+	// when fixed is nil.
+	percentile float64
+	// fixed is an explicitly configured tip cap, in wei / gas.
+	fixed *big.Int
+}
+
+// parseGasStrategy parses the gasstrategy setting. A nil *gasStrategy is
+// returned for an empty setting.
+func parseGasStrategy(s string) (*gasStrategy, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return nil, nil
+	}
+	if pct, found := gasStrategyPercentiles[s]; found {
+		return &gasStrategy{name: s, percentile: pct}, nil
+	}
+	gwei, err := strconv.ParseFloat(s, 64)
+	if err != nil || !(gwei > 0) || math.IsInf(gwei, 1) {
+		return nil, fmt.Errorf("invalid gas strategy %q. expected %s, %s, %s, or a tip in gwei",
+			s, gasStrategyEconomical, gasStrategyStandard, gasStrategyFast)
+	}
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(dexeth.GweiFactor)).Int(nil)
+	return &gasStrategy{name: s, fixed: wei}, nil
+}
+
+// tipRate computes the tip cap from the fee history of recent blocks, or
+// returns the explicit tip cap.
+func (s *gasStrategy) tipRate(ctx context.Context, node ethFetcher) (*big.Int, error) {
+	if s.fixed != nil {
+		return new(big.Int).Set(s.fixed), nil
+	}
+	hist, err := node.feeHistory(ctx, feeHistoryBlocks, []float64{s.percentile})
+	if err != nil {
+		return nil, fmt.Errorf("error getting fee history: %w", err)
+	}
+	rewards := make([]*big.Int, 0, len(hist.Reward))
+	for _, r := range hist.Reward {
+		if len(r) > 0 && r[0] != nil {
+			rewards = append(rewards, r[0])
+		}
+	}
+	if len(rewards) == 0 {
+		return nil, errors.New("no tip rewards in fee history")
+	}
+	// Use the median across blocks so that one unusual block does not
+	// dominate.
+	sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+	tipRate := new(big.Int).Set(rewards[len(rewards)/2])
+	if minTip := dexeth.GweiToWei(dexeth.MinGasTipCap); tipRate.Cmp(minTip) < 0 {
+		tipRate.Set(minTip)
+	}
+	return tipRate, nil
+}
+
+// setGasStrategy parses and sets the wallet's gas strategy.
+func (w *assetWallet) setGasStrategy(setting string) error {
+	s, err := parseGasStrategy(setting)
+	if err != nil {
+		return err
+	}
+	w.gasStrategy.Store(s)
+	return nil
+}
+
+// strategicTipRate is the tip cap for swap and redeem transactions, as
+// determined by the wallet's gas strategy, limited to maxFeeRate. Without a
+// configured strategy, the network's suggested tip cap is used.
+func (w *assetWallet) strategicTipRate(ctx context.Context, maxFeeRate *big.Int) (*big.Int, error) {
+	s := w.gasStrategy.Load()
+	if s == nil {
+		_, tipRate, err := w.currentNetworkFees(ctx)
+		return tipRate, err
+	}
+	tipRate, err := s.tipRate(ctx, w.node)
+	if err != nil {
+		return nil, err
+	}
+	if tipRate.Cmp(maxFeeRate) > 0 {
+		w.log.Debugf("Limiting %s gas strategy tip %s to max fee rate %s", s.name, tipRate, maxFeeRate)
+		tipRate.Set(maxFeeRate)
+	}
+	return tipRate, nil
+}
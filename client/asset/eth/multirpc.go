@@ -1445,6 +1445,15 @@ func (m *multiRPCClient) currentFees(ctx context.Context) (baseFees, tipCap *big
 	})
 }
 
+// feeHistory gets the base fees and the tip rewards at the specified
+// percentiles for the most recent blocks.
+func (m *multiRPCClient) feeHistory(ctx context.Context, blocks uint64, rewardPercentiles []float64) (h *ethereum.FeeHistory, err error) {
+	return h, m.withAny(ctx, func(ctx context.Context, p *provider) error {
+		h, err = p.ec.FeeHistory(ctx, blocks, nil, rewardPercentiles)
+		return err
+	})
+}
+
 func (m *multiRPCClient) unlock(pw string) error {
 	return m.creds.ks.TimedUnlock(*m.creds.acct, pw, 0)
 }
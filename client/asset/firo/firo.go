@@ -69,6 +69,7 @@ var (
 		Name:              "Firo",
 		SupportedVersions: []uint32{version},
 		UnitInfo:          dexfiro.UnitInfo,
+		BlockInterval:     5 * time.Minute,
 		AvailableWallets: []*asset.WalletDefinition{
 			{
 				Type:              walletTypeRPC,
@@ -102,8 +103,8 @@ func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Ne
 // DecodeCoinID creates a human-readable representation of a coin ID
 // for Firo.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Firo and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Info returns basic information about the wallet and asset.
@@ -111,6 +112,18 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return WalletInfo
 }
 
+// explorerTxURLs are the block explorer transaction URL templates by network.
+var explorerTxURLs = map[dex.Network]string{
+	dex.Mainnet: "https://explorer.firo.org/tx/" + asset.ExplorerTxID,
+	dex.Testnet: "https://testexplorer.firo.org/tx/" + asset.ExplorerTxID,
+}
+
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return explorerTxURLs[net], ""
+}
+
 // MinLotSize calculates the minimum bond size for a given fee rate that avoids
 // dust outputs on the swap and refund txs, assuming the maxFeeRate doesn't
 // change.
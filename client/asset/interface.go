@@ -18,25 +18,26 @@ import (
 type WalletTrait uint64
 
 const (
-	WalletTraitRescanner      WalletTrait = 1 << iota // The Wallet is an asset.Rescanner.
-	WalletTraitNewAddresser                           // The Wallet can generate new addresses on demand with NewAddress.
-	WalletTraitLogFiler                               // The Wallet allows for downloading of a log file.
-	WalletTraitFeeRater                               // Wallet can provide a fee rate for non-critical transactions
-	WalletTraitAccelerator                            // This wallet can accelerate transactions using the CPFP technique
-	WalletTraitRecoverer                              // The wallet is an asset.Recoverer.
-	WalletTraitWithdrawer                             // The Wallet can withdraw a specific amount from an exchange wallet.
-	WalletTraitSweeper                                // The Wallet can sweep all the funds, leaving no change.
-	WalletTraitRestorer                               // The wallet is an asset.WalletRestorer
-	WalletTraitTxFeeEstimator                         // The wallet can estimate transaction fees.
-	WalletTraitPeerManager                            // The wallet can manage its peers.
-	WalletTraitAuthenticator                          // The wallet require authentication.
-	WalletTraitShielded                               // DEPRECATED. Left for ordering
-	WalletTraitTokenApprover                          // The wallet is a TokenApprover
-	WalletTraitAccountLocker                          // The wallet must have enough balance for redemptions before a trade.
-	WalletTraitTicketBuyer                            // The wallet can participate in decred staking.
-	WalletTraitHistorian                              // This wallet can return its transaction history
-	WalletTraitFundsMixer                             // The wallet can mix funds.
-	WalletTraitDynamicSwapper                         // The wallet has dynamic fees.
+	WalletTraitRescanner       WalletTrait = 1 << iota // The Wallet is an asset.Rescanner.
+	WalletTraitNewAddresser                            // The Wallet can generate new addresses on demand with NewAddress.
+	WalletTraitLogFiler                                // The Wallet allows for downloading of a log file.
+	WalletTraitFeeRater                                // Wallet can provide a fee rate for non-critical transactions
+	WalletTraitAccelerator                             // This wallet can accelerate transactions using the CPFP technique
+	WalletTraitRecoverer                               // The wallet is an asset.Recoverer.
+	WalletTraitWithdrawer                              // The Wallet can withdraw a specific amount from an exchange wallet.
+	WalletTraitSweeper                                 // The Wallet can sweep all the funds, leaving no change.
+	WalletTraitRestorer                                // The wallet is an asset.WalletRestorer
+	WalletTraitTxFeeEstimator                          // The wallet can estimate transaction fees.
+	WalletTraitPeerManager                             // The wallet can manage its peers.
+	WalletTraitAuthenticator                           // The wallet require authentication.
+	WalletTraitShielded                                // DEPRECATED. Left for ordering
+	WalletTraitTokenApprover                           // The wallet is a TokenApprover
+	WalletTraitAccountLocker                           // The wallet must have enough balance for redemptions before a trade.
+	WalletTraitTicketBuyer                             // The wallet can participate in decred staking.
+	WalletTraitHistorian                               // This wallet can return its transaction history
+	WalletTraitFundsMixer                              // The wallet can mix funds.
+	WalletTraitDynamicSwapper                          // The wallet has dynamic fees.
+	WalletTraitLegacyAddresser                         // The wallet can show its addresses in a legacy format.
 )
 
 // IsRescanner tests if the WalletTrait has the WalletTraitRescanner bit set.
@@ -150,6 +151,13 @@ func (wt WalletTrait) IsDynamicSwapper() bool {
 	return wt&WalletTraitDynamicSwapper != 0
 }
 
+// IsLegacyAddresser tests if the WalletTrait has the
+// WalletTraitLegacyAddresser bit set, which indicates the wallet implements
+// the LegacyAddresser interface.
+func (wt WalletTrait) IsLegacyAddresser() bool {
+	return wt&WalletTraitLegacyAddresser != 0
+}
+
 // DetermineWalletTraits returns the WalletTrait bitset for the provided Wallet.
 func DetermineWalletTraits(w Wallet) (t WalletTrait) {
 	if _, is := w.(Rescanner); is {
@@ -206,6 +214,9 @@ func DetermineWalletTraits(w Wallet) (t WalletTrait) {
 	if _, is := w.(DynamicSwapper); is {
 		t |= WalletTraitDynamicSwapper
 	}
+	if _, is := w.(LegacyAddresser); is {
+		t |= WalletTraitLegacyAddresser
+	}
 	return t
 }
 
@@ -254,6 +265,12 @@ const (
 	// that has not been approved.
 	ErrUnapprovedToken = dex.ErrorKind("token not approved")
 	ErrApprovalPending = dex.ErrorKind("approval pending")
+	// ErrWrongNetworkAddress is returned by AddressChecker.CheckAddress for
+	// an address that is valid for a different network of the asset.
+	ErrWrongNetworkAddress = dex.ErrorKind("address is for a different network")
+	// ErrMalformedAddress is returned by AddressChecker.CheckAddress for an
+	// address that is not valid for any network of the asset.
+	ErrMalformedAddress = dex.ErrorKind("malformed address")
 
 	// InternalNodeLoggerName is the name for a logger that is used to fine
 	// tune log levels for only loggers using this name.
@@ -335,6 +352,9 @@ type WalletInfo struct {
 	// that a common seed will be generated and wallets will generate the
 	// same address.
 	IsAccountBased bool
+	// BlockInterval is the target time between blocks of the asset's chain.
+	// For tokens, this is the parent chain's block interval.
+	BlockInterval time.Duration
 }
 
 // ConfigOption is a wallet configuration option.
@@ -534,10 +554,6 @@ type Wallet interface {
 	SwapConfirmations(ctx context.Context, coinID dex.Bytes, contract dex.Bytes, matchTime time.Time) (confs uint32, spent bool, err error)
 	// ValidateSecret checks that the secret hashes to the secret hash.
 	ValidateSecret(secret, secretHash []byte) bool
-	// SyncStatus is information about the blockchain sync status. It should
-	// only indicate synced when there are network peers and all blocks on the
-	// network have been processed by the wallet.
-	SyncStatus() (*SyncStatus, error)
 	// RegFeeConfirmations gets the confirmations for a registration fee
 	// payment. This method need not be supported by all assets. Those assets
 	// which do no support DEX registration fees will return an ErrUnsupported.
@@ -571,6 +587,16 @@ type Wallet interface {
 	MaxFundingFees(numTrades uint32, feeRate uint64, options map[string]string) uint64
 }
 
+// SyncStatuser is a wallet that must sync with the blockchain before it can be
+// used. Wallets that are always synced, e.g. light clients backed by a trusted
+// server, need not implement SyncStatuser, and are considered fully synced.
+type SyncStatuser interface {
+	// SyncStatus is information about the blockchain sync status. It should
+	// only indicate synced when there are network peers and all blocks on the
+	// network have been processed by the wallet.
+	SyncStatus() (*SyncStatus, error)
+}
+
 // Authenticator is a wallet implementation that require authentication.
 type Authenticator interface {
 	// Unlock unlocks the exchange wallet.
@@ -679,6 +705,26 @@ type Rescanner interface {
 	Rescan(ctx context.Context, bday /* unix time seconds */ uint64) error
 }
 
+// GapLimiter is a wallet implementation that discovers used addresses by
+// scanning up to a gap limit of unused addresses past the last used address.
+type GapLimiter interface {
+	// GapLimit returns the current gap limit.
+	GapLimit() (uint32, error)
+	// ExtendGapLimit raises the gap limit by n and begins a rescan so that
+	// funds received at addresses past the previous gap limit are found. The
+	// new gap limit is returned.
+	ExtendGapLimit(n uint32) (uint32, error)
+}
+
+// CoinLockLister is a wallet implementation that can list the coins that it
+// has locked, e.g. to fund orders, so that locks left behind by aborted orders
+// can be found and released with ReturnCoins.
+type CoinLockLister interface {
+	// LockedCoins returns the coins that are locked in the wallet. Only coins
+	// that are verified to be unspent are returned.
+	LockedCoins() (Coins, error)
+}
+
 // Recoverer is a wallet implementation with recover functionality.
 type Recoverer interface {
 	// GetRecoveryCfg returns information that will help the wallet get back to
@@ -708,6 +754,26 @@ type NewAddresser interface {
 	NewAddress() (string, error)
 }
 
+// LegacyAddresser is a wallet for an asset that has a legacy address format
+// in addition to its canonical format, e.g. the base58 encoding of a Bitcoin
+// Cash CashAddr address. The legacy format is only for display to users of
+// services that have not adopted the canonical format.
+type LegacyAddresser interface {
+	// LegacyAddress returns the legacy encoding of the canonically encoded
+	// address. The legacy address pays to the same output script. An error
+	// wrapping ErrUnsupported is returned if the wallet has no legacy format.
+	LegacyAddress(addr string) (string, error)
+}
+
+// AddressChecker is a wallet that can distinguish an address for a different
+// network of the asset from a malformed address.
+type AddressChecker interface {
+	// CheckAddress checks that the address is valid for the wallet's network.
+	// An invalid address results in an error wrapping ErrWrongNetworkAddress
+	// or ErrMalformedAddress.
+	CheckAddress(address string) error
+}
+
 // AddressReturner is a wallet that allows recycling of unused redemption or refund
 // addresses. Asset implementations should log any errors internally. The caller
 // is responsible for only returning unused addresses.
@@ -791,6 +857,19 @@ type WalletRestorer interface {
 	RestorationInfo(seed []byte) ([]*WalletRestoration, error)
 }
 
+// DerivationVerifier is a seeded wallet that can derive addresses from a seed
+// independently of its own key store, so that a restored wallet's key
+// derivation can be checked against the seed it should have been created from.
+type DerivationVerifier interface {
+	// SeedAddresses derives the first n external addresses of a wallet created
+	// from the seed. Fewer than n addresses are returned by wallets that only
+	// have fewer addresses, e.g. account-based assets.
+	SeedAddresses(seed []byte, n int) ([]string, error)
+	// WalletAddresses returns the first n external addresses from the
+	// wallet's key store, in derivation order.
+	WalletAddresses(n int) ([]string, error)
+}
+
 // EarlyAcceleration is returned from the PreAccelerate function to inform the
 // user that either their last acceleration or oldest swap transaction happened
 // very recently, and that they should double check that they really want to do
@@ -806,6 +885,50 @@ type EarlyAcceleration struct {
 	WasAccelerated bool `json:"wasAccelerated"`
 }
 
+// SwapReplacementFinder is implemented by wallets that can locate a
+// transaction that replaced one of their swap transactions, e.g. by RBF or by
+// being dropped and resent with the same inputs.
+type SwapReplacementFinder interface {
+	// FindSwapReplacement searches for a transaction that spends any of the
+	// inputs of the swap transaction identified by coinID and pays to the same
+	// contract. The coin ID of the replacement's contract output is returned.
+	// If no replacement is found, an error wrapping CoinNotFoundError is
+	// returned.
+	FindSwapReplacement(ctx context.Context, coinID, contract dex.Bytes) (dex.Bytes, error)
+}
+
+// DuplicateSwapFinder is implemented by wallets that can locate other
+// transactions paying to the same contract as one of their swaps, e.g. if the
+// swap was broadcast twice.
+type DuplicateSwapFinder interface {
+	// FindDuplicateSwaps returns the coin IDs of any contract outputs, other
+	// than the one identified by coinID, that pay to the same contract.
+	FindDuplicateSwaps(ctx context.Context, coinID, contract dex.Bytes) ([]dex.Bytes, error)
+}
+
+// SwapSimulator is implemented by wallets that can construct a swap
+// transaction without broadcasting it, for dry runs of the swap flow.
+type SwapSimulator interface {
+	// SimulateSwap constructs the transaction that Swap would broadcast for
+	// the swaps, but does not sign or broadcast it. No funds are spent, no
+	// addresses are used, and the Inputs are not unlocked. A SwapSimulator
+	// must not broadcast anything from FundOrder for an Order with DryRun
+	// set.
+	SimulateSwap(swaps *Swaps) (*SimulatedSwap, error)
+}
+
+// SimulatedSwap is a swap transaction that was constructed but not
+// broadcast.
+type SimulatedSwap struct {
+	// TxID is the ID of the unsigned transaction.
+	TxID string
+	// Tx is the serialized, unsigned transaction.
+	Tx dex.Bytes
+	// Contracts are the swap contracts, in the order of the Swaps.Contracts.
+	Contracts []dex.Bytes
+	Fees      uint64
+}
+
 // Accelerator is implemented by wallets which support acceleration of the
 // mining of swap transactions.
 type Accelerator interface {
@@ -1263,6 +1386,10 @@ const (
 	BalanceCategoryShielded = "Shielded"
 	BalanceCategoryUnmixed  = "Unmixed"
 	BalanceCategoryStaked   = "Staked"
+	// BalanceCategorySwapFeeReserve is the part of Balance.Locked reserved
+	// for the fees of anticipated redemptions and refunds, including those of
+	// token swaps that pay fees in this asset.
+	BalanceCategorySwapFeeReserve = "SwapFeeReserve"
 )
 
 // Coin is some amount of spendable asset. Coin provides the information needed
@@ -1427,6 +1554,9 @@ type Order struct {
 	RedeemVersion uint32
 	// RedeemAssetID is the asset ID of the "to" asset.
 	RedeemAssetID uint32
+	// DryRun is set when the order is funded for a SwapSimulator dry run, in
+	// which case nothing may be broadcast, e.g. a split transaction.
+	DryRun bool
 }
 
 // MultiOrderValue is one of the placements in a multi-order.
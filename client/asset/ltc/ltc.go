@@ -45,6 +45,12 @@ var (
 		Testnet: "19332",
 		Simnet:  "19443",
 	}
+	// explorerTxURLs are the block explorer transaction URL templates by
+	// network.
+	explorerTxURLs = map[dex.Network]string{
+		dex.Mainnet: "https://ltc.bitaps.com/" + asset.ExplorerTxID,
+		dex.Testnet: "https://sochain.com/tx/LTCTEST/" + asset.ExplorerTxID,
+	}
 	rpcWalletDefinition = &asset.WalletDefinition{
 		Type:              walletTypeRPC,
 		Tab:               "Litecoin Core (external)",
@@ -65,7 +71,7 @@ var (
 		Type:             walletTypeSPV,
 		Tab:              "Native",
 		Description:      "Use the built-in SPV wallet",
-		ConfigOpts:       btc.CommonConfigOpts("LTC", true),
+		ConfigOpts:       append(btc.CommonConfigOpts("LTC", true), btc.GapLimitOpt),
 		Seeded:           true,
 		MultiFundingOpts: btc.MultiFundingOpts,
 	}
@@ -74,6 +80,7 @@ var (
 		Name:              "Litecoin",
 		SupportedVersions: []uint32{version},
 		UnitInfo:          dexltc.UnitInfo,
+		BlockInterval:     150 * time.Second,
 		AvailableWallets: []*asset.WalletDefinition{
 			spvWalletDefinition,
 			rpcWalletDefinition,
@@ -100,8 +107,8 @@ func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Ne
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Litecoin.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Litecoin and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Info returns basic information about the wallet and asset.
@@ -109,6 +116,12 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return WalletInfo
 }
 
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return explorerTxURLs[net], ""
+}
+
 // MinLotSize calculates the minimum bond size for a given fee rate that avoids
 // dust outputs on the swap and refund txs, assuming the maxFeeRate doesn't
 // change.
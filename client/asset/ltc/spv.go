@@ -53,6 +53,9 @@ const (
 	defaultAcctNum         = 0
 	defaultAcctName        = "default"
 	dbTimeout              = 20 * time.Second
+	// defaultGapLimit is the default gap limit, which is the recovery window
+	// of ltcwallet's address discovery. Borrowed from ltcwallet directly.
+	defaultGapLimit = 250
 )
 
 var (
@@ -83,6 +86,9 @@ type ltcSPVWallet struct {
 	btcParams   *chaincfg.Params
 	log         dex.Logger
 
+	// gapLimit is the recovery window of the wallet loader.
+	gapLimit atomic.Uint32
+
 	// This section is populated in Start.
 	*wallet.Wallet
 	chainClient *chain.NeutrinoClient
@@ -112,6 +118,11 @@ func openSPVWallet(dir string, cfg *btc.WalletConfig, btcParams *chaincfg.Params
 		btcParams:   btcParams,
 		log:         log,
 	}
+	gapLimit := cfg.GapLimit
+	if gapLimit < defaultGapLimit {
+		gapLimit = defaultGapLimit
+	}
+	w.gapLimit.Store(gapLimit)
 	return w
 }
 
@@ -122,7 +133,7 @@ func createSPVWallet(privPass []byte, seed []byte, bday time.Time, walletDir str
 	}
 
 	// timeout and recoverWindow arguments borrowed from btcwallet directly.
-	loader := wallet.NewLoader(net, walletDir, true, dbTimeout, 250)
+	loader := wallet.NewLoader(net, walletDir, true, dbTimeout, defaultGapLimit)
 
 	pubPass := []byte(wallet.InsecurePubPassphrase)
 
@@ -190,7 +201,7 @@ func (w *ltcSPVWallet) Start() (btc.SPVService, error) {
 	}
 	// recoverWindow arguments borrowed from ltcwallet directly.
 
-	w.loader = wallet.NewLoader(w.walletParams(), w.dir, true, dbTimeout, 250)
+	w.loader = wallet.NewLoader(w.walletParams(), w.dir, true, dbTimeout, w.gapLimit.Load())
 
 	exists, err := w.loader.WalletExists()
 	if err != nil {
@@ -638,6 +649,97 @@ func (w *ltcSPVWallet) RescanAsync() error {
 	return nil
 }
 
+// GapLimit is the number of unused addresses past the last used address that
+// are scanned during address discovery.
+func (w *ltcSPVWallet) GapLimit() uint32 {
+	return w.gapLimit.Load()
+}
+
+// ExtendGapLimit raises the gap limit by n, derives n more addresses on each
+// branch of the default account, and begins a full rescan.
+func (w *ltcSPVWallet) ExtendGapLimit(n uint32) (uint32, error) {
+	props, err := w.Wallet.AccountProperties(ltcwaddrmgr.KeyScopeBIP0084WithBitcoinCoinID, defaultAcctNum)
+	if err != nil {
+		return 0, fmt.Errorf("error getting account properties: %w", err)
+	}
+	gapLimit := w.gapLimit.Load() + n
+	// The key counts are one past the last derived index.
+	extIdx, intIdx := props.ExternalKeyCount+n-1, props.InternalKeyCount+n-1
+	if err := extendAddresses(extIdx, intIdx, w.Wallet); err != nil {
+		return 0, fmt.Errorf("error deriving addresses: %w", err)
+	}
+	w.gapLimit.Store(gapLimit)
+	w.log.Infof("Derived addresses through external index %d and internal index %d. Gap limit is now %d.",
+		extIdx, intIdx, gapLimit)
+	return gapLimit, w.RescanAsync()
+}
+
+// UsedNearEdge reports whether any used address of the default account is
+// within margin of the last derived address of its branch.
+func (w *ltcSPVWallet) UsedNearEdge(margin uint32) (bool, error) {
+	scope := ltcwaddrmgr.KeyScopeBIP0084WithBitcoinCoinID
+	props, err := w.Wallet.AccountProperties(scope, defaultAcctNum)
+	if err != nil {
+		return false, fmt.Errorf("error getting account properties: %w", err)
+	}
+	scopedKeyManager, err := w.Manager.FetchScopedKeyManager(scope)
+	if err != nil {
+		return false, err
+	}
+	var nearEdge bool
+	err = walletdb.View(w.Database(), func(dbtx walletdb.ReadTx) error {
+		ns := dbtx.ReadBucket(waddrmgrNamespace)
+		return scopedKeyManager.ForEachAccountAddress(ns, defaultAcctNum, func(addr ltcwaddrmgr.ManagedAddress) error {
+			pkAddr, is := addr.(ltcwaddrmgr.ManagedPubKeyAddress)
+			if nearEdge || !is {
+				return nil
+			}
+			_, path, ok := pkAddr.DerivationInfo()
+			if !ok {
+				return nil
+			}
+			keyCount := props.ExternalKeyCount
+			if path.Branch == ltcwaddrmgr.InternalBranch {
+				keyCount = props.InternalKeyCount
+			}
+			nearEdge = path.Index+margin >= keyCount && addr.Used(ns)
+			return nil
+		})
+	})
+	return nearEdge, err
+}
+
+// ExternalAddresses derives the first n external addresses of the default
+// account from the account key in the wallet database.
+func (w *ltcSPVWallet) ExternalAddresses(n uint32) ([]btcutil.Address, error) {
+	scopedKeyManager, err := w.Manager.FetchScopedKeyManager(ltcwaddrmgr.KeyScopeBIP0084WithBitcoinCoinID)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]btcutil.Address, 0, n)
+	err = walletdb.View(w.Database(), func(dbtx walletdb.ReadTx) error {
+		ns := dbtx.ReadBucket(waddrmgrNamespace)
+		for i := uint32(0); i < n; i++ {
+			ltcAddr, err := scopedKeyManager.DeriveFromKeyPath(ns, ltcwaddrmgr.DerivationPath{
+				InternalAccount: defaultAcctNum,
+				Account:         defaultAcctNum,
+				Branch:          ltcwaddrmgr.ExternalBranch,
+				Index:           i,
+			})
+			if err != nil {
+				return err
+			}
+			addr, err := w.addrLTC2BTC(ltcAddr.Address())
+			if err != nil {
+				return err
+			}
+			addrs = append(addrs, addr)
+		}
+		return nil
+	})
+	return addrs, err
+}
+
 // ForceRescan forces a full rescan with active address discovery on wallet
 // restart by dropping the complete transaction history and setting the
 // "synced to" field to nil. See the btcwallet/cmd/dropwtxmgr app for more
@@ -2,8 +2,13 @@ package ltc
 
 import (
 	"testing"
+	"time"
 
+	"decred.org/dcrdex/dex/encode"
 	dexltc "decred.org/dcrdex/dex/networks/ltc"
+	ltcwaddrmgr "github.com/dcrlabs/ltcwallet/waddrmgr"
+	"github.com/dcrlabs/ltcwallet/wallet"
+	"github.com/dcrlabs/ltcwallet/walletdb"
 	ltcchaincfg "github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcd/ltcutil"
 )
@@ -58,3 +63,48 @@ func TestAddressTranslationRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+func TestExternalAddresses(t *testing.T) {
+	loader := wallet.NewLoader(&ltcchaincfg.MainNetParams, t.TempDir(), true, dbTimeout, defaultGapLimit)
+	ltcw, err := loader.CreateNewWallet([]byte(wallet.InsecurePubPassphrase), []byte("abc"), encode.RandomBytes(32), time.Now())
+	if err != nil {
+		t.Fatalf("CreateNewWallet error: %v", err)
+	}
+	defer loader.UnloadWallet()
+	w := &ltcSPVWallet{
+		chainParams: &ltcchaincfg.MainNetParams,
+		btcParams:   dexltc.MainNetParams,
+		Wallet:      ltcw,
+	}
+
+	addrs, err := w.ExternalAddresses(3)
+	if err != nil {
+		t.Fatalf("ExternalAddresses error: %v", err)
+	}
+	if len(addrs) != 3 {
+		t.Fatalf("expected 3 addresses, got %d", len(addrs))
+	}
+	// The first returned deposit address is the first external address.
+	scopedKeyManager, err := ltcw.Manager.FetchScopedKeyManager(ltcwaddrmgr.KeyScopeBIP0084WithBitcoinCoinID)
+	if err != nil {
+		t.Fatalf("FetchScopedKeyManager error: %v", err)
+	}
+	var depositAddr ltcutil.Address
+	err = walletdb.Update(ltcw.Database(), func(dbtx walletdb.ReadWriteTx) error {
+		managedAddrs, err := scopedKeyManager.NextExternalAddresses(dbtx.ReadWriteBucket(waddrmgrNamespace), defaultAcctNum, 1)
+		if err != nil {
+			return err
+		}
+		depositAddr = managedAddrs[0].Address()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("NextExternalAddresses error: %v", err)
+	}
+	if addrs[0].String() != depositAddr.String() {
+		t.Fatalf("wrong first external address %s, expected %s", addrs[0], depositAddr)
+	}
+	if addrs[1].String() == addrs[0].String() {
+		t.Fatalf("duplicate external addresses")
+	}
+}
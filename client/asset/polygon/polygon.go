@@ -8,6 +8,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"strconv"
+	"time"
 
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/asset/eth"
@@ -33,6 +34,7 @@ func registerToken(tokenID uint32, desc string, nets ...dex.Network) {
 		Type:        walletTypeToken,
 		Tab:         "Polygon token",
 		Description: desc,
+		ConfigOpts:  []*asset.ConfigOption{eth.GasStrategyOpt},
 	}, netAddrs)
 }
 
@@ -70,11 +72,13 @@ var (
 				"wallet.  Units: gwei / gas",
 			DefaultValue: defaultGasFeeLimit,
 		},
+		eth.GasStrategyOpt,
 	}
 	WalletInfo = asset.WalletInfo{
 		Name:              "Polygon",
 		SupportedVersions: []uint32{0},
 		UnitInfo:          dexpolygon.UnitInfo,
+		BlockInterval:     2 * time.Second,
 		AvailableWallets: []*asset.WalletDefinition{
 			{
 				Type:        walletTypeRPC,
@@ -164,6 +168,20 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return &wi
 }
 
+// explorerURLs are the block explorer transaction and address URL templates by
+// network.
+var explorerURLs = map[dex.Network][2]string{
+	dex.Mainnet: {"https://polygonscan.com/tx/" + asset.ExplorerTxID, "https://polygonscan.com/address/" + asset.ExplorerAddr},
+	dex.Testnet: {"https://amoy.polygonscan.com/tx/" + asset.ExplorerTxID, "https://amoy.polygonscan.com/address/" + asset.ExplorerAddr},
+}
+
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	urls := explorerURLs[net]
+	return urls[0], urls[1]
+}
+
 func (d *Driver) Exists(walletType, dataDir string, settings map[string]string, net dex.Network) (bool, error) {
 	if walletType != walletTypeRPC {
 		return false, fmt.Errorf("unknown wallet type %q", walletType)
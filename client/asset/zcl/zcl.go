@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"time"
 
 	"decred.org/dcrdex/client/asset"
 	"decred.org/dcrdex/client/asset/btc"
@@ -91,6 +92,7 @@ var (
 		Name:              "Zclassic",
 		SupportedVersions: []uint32{version},
 		UnitInfo:          dexzcl.UnitInfo,
+		BlockInterval:     150 * time.Second,
 		AvailableWallets: []*asset.WalletDefinition{{
 			Type:              walletTypeRPC,
 			Tab:               "External",
@@ -117,16 +119,8 @@ func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Ne
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Zcash.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Zcash shielded transactions don't have transparent outputs, so the coinID
-	// will just be the tx hash.
-	if len(coinID) == chainhash.HashSize {
-		var txHash chainhash.Hash
-		copy(txHash[:], coinID)
-		return txHash.String(), nil
-	}
-	// For transparent transactions, Zcash and Bitcoin have the same tx hash
-	// and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Info returns basic information about the wallet and asset.
@@ -134,6 +128,17 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return WalletInfo
 }
 
+// explorerTxURLs are the block explorer transaction URL templates by network.
+var explorerTxURLs = map[dex.Network]string{
+	dex.Mainnet: "https://explorer.zcl.zelcore.io/tx/" + asset.ExplorerTxID,
+}
+
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return explorerTxURLs[net], ""
+}
+
 // MinLotSize calculates the minimum bond size for a given fee rate that avoids
 // dust outputs on the swap and refund txs, assuming the maxFeeRate doesn't
 // change.
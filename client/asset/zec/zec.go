@@ -131,6 +131,7 @@ var (
 		Name:              "Zcash",
 		SupportedVersions: []uint32{version},
 		UnitInfo:          dexzec.UnitInfo,
+		BlockInterval:     75 * time.Second,
 		AvailableWallets: []*asset.WalletDefinition{{
 			Type:              walletTypeRPC,
 			Tab:               "External",
@@ -159,16 +160,8 @@ func (d *Driver) Open(cfg *asset.WalletConfig, logger dex.Logger, network dex.Ne
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Zcash.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Zcash shielded transactions don't have transparent outputs, so the coinID
-	// will just be the tx hash.
-	if len(coinID) == chainhash.HashSize {
-		var txHash chainhash.Hash
-		copy(txHash[:], coinID)
-		return txHash.String(), nil
-	}
-	// For transparent transactions, Zcash and Bitcoin have the same tx hash
-	// and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Info returns basic information about the wallet and asset.
@@ -176,6 +169,18 @@ func (d *Driver) Info() *asset.WalletInfo {
 	return WalletInfo
 }
 
+// explorerTxURLs are the block explorer transaction URL templates by network.
+var explorerTxURLs = map[dex.Network]string{
+	dex.Mainnet: "https://zcashblockexplorer.com/transactions/" + asset.ExplorerTxID,
+	dex.Testnet: "https://blockexplorer.one/zcash/testnet/tx/" + asset.ExplorerTxID,
+}
+
+// ExplorerURLs returns the block explorer URL templates for the network. Part
+// of the asset.Explorer interface.
+func (d *Driver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return explorerTxURLs[net], ""
+}
+
 // MinLotSize calculates the minimum bond size for a given fee rate that avoids
 // dust outputs on the swap and refund txs, assuming the maxFeeRate doesn't
 // change.
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/url"
@@ -31,11 +32,17 @@ const (
 	// The maximum time in seconds to write to a connection.
 	writeWait = time.Second * 3
 
-	// reconnectInterval is the initial and increment between reconnect tries.
-	reconnectInterval = 5 * time.Second
+	// DefaultReconnectInterval is the default initial wait between reconnect
+	// tries. The wait doubles after each failed attempt.
+	DefaultReconnectInterval = 5 * time.Second
 
-	// maxReconnectInterval is the maximum allowed reconnect interval.
-	maxReconnectInterval = time.Minute
+	// DefaultMaxReconnectInterval is the default maximum reconnect interval.
+	DefaultMaxReconnectInterval = time.Minute
+
+	// reconnectJitter is the maximum fraction of the reconnect interval that
+	// is randomly added to each wait, so that many clients dropped by the same
+	// server restart do not all reconnect in lockstep.
+	reconnectJitter = 0.25
 
 	// DefaultResponseTimeout is the default timeout for responses after a
 	// request is successfully sent.
@@ -144,6 +151,20 @@ type WsCfg struct {
 	// DisableAutoReconnect disables automatic reconnection.
 	DisableAutoReconnect bool
 
+	// ReconnectInterval is the initial wait between reconnect attempts. The
+	// wait doubles after each failed attempt, up to MaxReconnectInterval.
+	// Zero means DefaultReconnectInterval.
+	ReconnectInterval time.Duration
+
+	// MaxReconnectInterval is the maximum wait between reconnect attempts.
+	// Zero means DefaultMaxReconnectInterval.
+	MaxReconnectInterval time.Duration
+
+	// MaxMessageSize is the limit on the size of messages read from the
+	// server. The connection is dropped if a larger message is received. Zero
+	// means no limit.
+	MaxMessageSize int64
+
 	ConnectHeaders http.Header
 }
 
@@ -177,6 +198,9 @@ func NewWsConn(cfg *WsCfg) (WsConn, error) {
 	if cfg.PingWait < 0 {
 		return nil, fmt.Errorf("ping wait cannot be negative")
 	}
+	if cfg.ReconnectInterval < 0 || cfg.MaxReconnectInterval < 0 {
+		return nil, fmt.Errorf("reconnect intervals cannot be negative")
+	}
 
 	uri, err := url.Parse(cfg.URL)
 	if err != nil {
@@ -258,6 +282,10 @@ func (conn *wsConn) connect(ctx context.Context) error {
 		return err
 	}
 
+	if conn.cfg.MaxMessageSize > 0 {
+		ws.SetReadLimit(conn.cfg.MaxMessageSize)
+	}
+
 	ws.SetPingHandler(func(string) error {
 		now := time.Now()
 
@@ -434,10 +462,41 @@ func (conn *wsConn) read(ctx context.Context) {
 	}
 }
 
+// reconnectIntervals returns the configured initial and maximum reconnect
+// intervals, substituting defaults for unset values.
+func (conn *wsConn) reconnectIntervals() (initial, max time.Duration) {
+	initial, max = conn.cfg.ReconnectInterval, conn.cfg.MaxReconnectInterval
+	if initial == 0 {
+		initial = DefaultReconnectInterval
+	}
+	if max == 0 {
+		max = DefaultMaxReconnectInterval
+	}
+	if max < initial {
+		max = initial
+	}
+	return
+}
+
+// nextReconnectInterval doubles the reconnect interval, capped at max.
+func nextReconnectInterval(rcInt, max time.Duration) time.Duration {
+	rcInt *= 2
+	if rcInt > max {
+		return max
+	}
+	return rcInt
+}
+
+// withJitter adds a random delay of up to reconnectJitter * d to d.
+func withJitter(d time.Duration) time.Duration {
+	return d + time.Duration(rand.Float64()*reconnectJitter*float64(d))
+}
+
 // keepAlive maintains an active websocket connection by reconnecting when
 // the established connection is broken. This should be run as a goroutine.
 func (conn *wsConn) keepAlive(ctx context.Context) {
-	rcInt := reconnectInterval
+	initialInt, maxInt := conn.reconnectIntervals()
+	rcInt := initialInt
 	for {
 		select {
 		case <-conn.reconnectCh:
@@ -450,20 +509,18 @@ func (conn *wsConn) keepAlive(ctx context.Context) {
 			conn.log.Infof("Attempting to reconnect to %s...", conn.cfg.URL)
 			err := conn.connect(ctx)
 			if err != nil {
+				wait := withJitter(rcInt)
 				conn.log.Errorf("Reconnect failed. Scheduling reconnect to %s in %.1f seconds.",
-					conn.cfg.URL, rcInt.Seconds())
-				time.AfterFunc(rcInt, func() {
+					conn.cfg.URL, wait.Seconds())
+				time.AfterFunc(wait, func() {
 					conn.reconnectCh <- struct{}{}
 				})
-				// Increment the wait up to PingWait.
-				if rcInt < maxReconnectInterval {
-					rcInt += reconnectInterval
-				}
+				rcInt = nextReconnectInterval(rcInt, maxInt)
 				continue
 			}
 
 			conn.log.Info("Successfully reconnected.")
-			rcInt = reconnectInterval
+			rcInt = initialInt
 
 			// Synchronize after a reconnection.
 			if conn.cfg.ReconnectSync != nil {
@@ -486,3 +486,35 @@ func TestWsConn(t *testing.T) {
 		t.Error("read source should have been closed")
 	}
 }
+
+func TestReconnectBackoff(t *testing.T) {
+	conn := &wsConn{cfg: &WsCfg{}}
+	initial, max := conn.reconnectIntervals()
+	if initial != DefaultReconnectInterval || max != DefaultMaxReconnectInterval {
+		t.Fatalf("wrong default intervals %v, %v", initial, max)
+	}
+
+	conn.cfg.ReconnectInterval = time.Second
+	conn.cfg.MaxReconnectInterval = 10 * time.Second
+	initial, max = conn.reconnectIntervals()
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	rcInt := initial
+	for i, exp := range expected {
+		if rcInt != exp {
+			t.Fatalf("attempt %d: expected interval %v, got %v", i, exp, rcInt)
+		}
+		for j := 0; j < 100; j++ {
+			wait := withJitter(rcInt)
+			if wait < rcInt || wait > rcInt+time.Duration(reconnectJitter*float64(rcInt)) {
+				t.Fatalf("jittered wait %v out of range for interval %v", wait, rcInt)
+			}
+		}
+		rcInt = nextReconnectInterval(rcInt, max)
+	}
+
+	// A max below the initial interval is raised to the initial interval.
+	conn.cfg.MaxReconnectInterval = time.Millisecond
+	if _, max = conn.reconnectIntervals(); max != time.Second {
+		t.Fatalf("expected max to be raised to the initial interval, got %v", max)
+	}
+}
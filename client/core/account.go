@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"strings"
 
 	"decred.org/dcrdex/client/comms"
 	"decred.org/dcrdex/client/db"
@@ -14,6 +15,50 @@ import (
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 )
 
+// A DEX host may be qualified with the name of a trading identity, as in
+// "dex.example.com:7232#alt". Each identity of a host is a separate account,
+// with an account key derived from a different branch of the app seed, its own
+// connection, and its own bonds, so the server cannot link the orders placed
+// with one identity to those of another identity or of the unqualified
+// (primary) host. A user may, for example, register an identity for each
+// market they trade.
+//
+// The privacy is bought with bonds. Each identity must post and maintain bonds
+// for its own tier, so the bond amounts locked, and the transaction fees to
+// post and renew bonds, are multiplied by the number of identities. Bonds are
+// on-chain, so an observer that follows the funding of the bonds could still
+// link identities that are funded from the same wallet. Similarly, identities
+// connecting from the same IP address are linkable by the server, so a Tor
+// proxy with circuit isolation (the Config.TorIsolation option) should be used.
+// Reputation is not shared, so a new identity begins with no history.
+const (
+	identitySep       = "#"
+	maxIdentityLength = 32
+)
+
+// splitHostIdentity splits an identity-qualified host into the network address
+// and the trading identity name. The identity is empty for an unqualified host.
+func splitHostIdentity(host string) (addr, identity string) {
+	addr, identity, _ = strings.Cut(host, identitySep)
+	return addr, identity
+}
+
+// validIdentity checks that the trading identity name is not too long and is
+// composed of only ASCII letters, digits, dashes, and underscores.
+func validIdentity(identity string) bool {
+	if len(identity) > maxIdentityLength {
+		return false
+	}
+	for _, r := range identity {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // disconnectDEX unsubscribes from the dex's orderbooks, ends the connection
 // with the dex, and removes it from the connection map.
 func (c *Core) disconnectDEX(dc *dexConnection) {
@@ -359,6 +404,12 @@ func (c *Core) UpdateDEXHost(oldHost, newHost string, appPW []byte, certI any) (
 			oldHost, newHost)
 	}
 
+	// The identity determines the account key, so it cannot change.
+	_, oldIdentity := splitHostIdentity(oldDc.acct.host)
+	if _, newIdentity := splitHostIdentity(newDc.acct.host); newIdentity != oldIdentity {
+		return nil, fmt.Errorf("cannot change the trading identity of %s", oldHost)
+	}
+
 	c.disconnectDEX(oldDc)
 
 	if !oldDc.acct.isViewOnly() { // view-only dc should not discoverAcct
@@ -0,0 +1,96 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/db"
+)
+
+// balanceAlert is a minimum balance alert for an asset. low is whether the
+// available balance was below the threshold at the last balance update, so
+// that a notification is only sent when the threshold is crossed.
+type balanceAlert struct {
+	threshold uint64
+	low       bool
+}
+
+// SetBalanceAlert sets a minimum balance alert for the asset. When the
+// available balance of the asset's wallet falls below threshold, a warning
+// notification is sent, and when it recovers, a success notification is sent.
+// No further notifications are sent while the balance stays on the same side
+// of the threshold. A zero threshold removes the alert. Alerts are persisted.
+// If the wallet's current balance is already below the new threshold, the
+// warning is sent immediately.
+func (c *Core) SetBalanceAlert(assetID uint32, threshold uint64) error {
+	if _, err := asset.UnitInfo(assetID); err != nil {
+		return newError(assetSupportErr, "asset %d not supported: %w", assetID, err)
+	}
+	if err := c.db.SetBalanceAlert(assetID, threshold); err != nil {
+		return codedError(dbErr, err)
+	}
+
+	c.balanceAlertsMtx.Lock()
+	if threshold == 0 {
+		delete(c.balanceAlerts, assetID)
+	} else {
+		c.balanceAlerts[assetID] = &balanceAlert{threshold: threshold}
+	}
+	c.balanceAlertsMtx.Unlock()
+
+	if threshold == 0 {
+		return nil
+	}
+	if w, found := c.wallet(assetID); found {
+		w.mtx.RLock()
+		bal := w.balance
+		w.mtx.RUnlock()
+		if bal != nil {
+			c.checkBalanceAlert(w, bal)
+		}
+	}
+	return nil
+}
+
+// BalanceAlerts returns the minimum balance alert thresholds by asset ID.
+func (c *Core) BalanceAlerts() map[uint32]uint64 {
+	c.balanceAlertsMtx.Lock()
+	defer c.balanceAlertsMtx.Unlock()
+	alerts := make(map[uint32]uint64, len(c.balanceAlerts))
+	for assetID, alert := range c.balanceAlerts {
+		alerts[assetID] = alert.threshold
+	}
+	return alerts
+}
+
+// checkBalanceAlert sends a notification if the wallet's available balance
+// has crossed the asset's minimum balance alert threshold since the last
+// balance update.
+func (c *Core) checkBalanceAlert(w *xcWallet, bal *WalletBalance) {
+	if bal == nil || bal.Balance == nil {
+		return
+	}
+	c.balanceAlertsMtx.Lock()
+	alert, found := c.balanceAlerts[w.AssetID]
+	if !found {
+		c.balanceAlertsMtx.Unlock()
+		return
+	}
+	threshold := alert.threshold
+	low := bal.Available < threshold
+	crossed := low != alert.low
+	alert.low = low
+	c.balanceAlertsMtx.Unlock()
+	if !crossed {
+		return
+	}
+
+	ui := w.Info().UnitInfo
+	topic, severity := TopicBalanceRestored, db.Success
+	if low {
+		topic, severity = TopicLowBalance, db.WarningLevel
+	}
+	subject, details := c.formatDetails(topic, unbip(w.AssetID), ui.ConventionalString(bal.Available), ui.ConventionalString(threshold))
+	c.notify(newWalletConfigNote(topic, subject, details, severity, w.state()))
+}
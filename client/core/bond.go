@@ -37,6 +37,21 @@ func cutBond(bonds []*db.Bond, i int) []*db.Bond { // input slice modified
 	return bonds
 }
 
+// maintainedBonds are the confirmed bonds that count toward tier maintenance,
+// which excludes bonds relinquished by ReplaceBond. The authMtx must be held.
+func (a *dexAccount) maintainedBonds() []*db.Bond {
+	if len(a.relinquishedBonds) == 0 {
+		return a.bonds
+	}
+	bonds := make([]*db.Bond, 0, len(a.bonds))
+	for _, bond := range a.bonds {
+		if !a.relinquishedBonds[bondKey(bond.AssetID, bond.CoinID)] {
+			bonds = append(bonds, bond)
+		}
+	}
+	return bonds
+}
+
 func (c *Core) triggerBondRotation() {
 	select {
 	case c.rotate <- struct{}{}:
@@ -388,17 +403,19 @@ func (c *Core) bondStateOfDEX(dc *dexConnection, bondCfg *dexBondCfg) *dexAcctBo
 
 	filterExpiredBonds := func(bonds []*db.Bond) (liveBonds []*db.Bond) {
 		for _, bond := range bonds {
+			bondIDStr, key := coinIDString(bond.AssetID, bond.CoinID), bondKey(bond.AssetID, bond.CoinID)
 			if int64(bond.LockTime) <= bondCfg.lockTimeThresh {
 				// Often auth, reconnect, or a bondexpired notification will
 				// do this first, but we must also here for refunds when the
 				// DEX host is down or gone.
 				dc.acct.expiredBonds = append(dc.acct.expiredBonds, bond)
-				c.log.Infof("Newly expired bond found: %v (%s)", coinIDString(bond.AssetID, bond.CoinID), unbip(bond.AssetID))
+				delete(dc.acct.relinquishedBonds, key)
+				delete(dc.acct.bondReplacements, key) // a replacement that never confirmed
+				c.log.Infof("Newly expired bond found: %v (%s)", bondIDStr, unbip(bond.AssetID))
 			} else {
-				if int64(bond.LockTime) <= bondCfg.replaceThresh {
+				if int64(bond.LockTime) <= bondCfg.replaceThresh && !dc.acct.relinquishedBonds[key] {
 					weakBonds = append(weakBonds, bond) // but not yet expired (still live or pending)
-					c.log.Debugf("Soon to expire bond found: %v (%s)",
-						coinIDString(bond.AssetID, bond.CoinID), unbip(bond.AssetID))
+					c.log.Debugf("Soon to expire bond found: %v (%s)", bondIDStr, unbip(bond.AssetID))
 				}
 				liveBonds = append(liveBonds, bond)
 			}
@@ -414,7 +431,7 @@ func (c *Core) bondStateOfDEX(dc *dexConnection, bondCfg *dexBondCfg) *dexAcctBo
 	dc.acct.pendingBonds = filterExpiredBonds(dc.acct.pendingBonds) // possibly expired before confirmed
 	state.PendingStrength = sumBondStrengths(dc.acct.pendingBonds, bondCfg.bondAssets)
 	state.WeakStrength = sumBondStrengths(weakBonds, bondCfg.bondAssets)
-	state.LiveStrength = sumBondStrengths(dc.acct.bonds, bondCfg.bondAssets) // for max bonded check
+	state.LiveStrength = sumBondStrengths(dc.acct.maintainedBonds(), bondCfg.bondAssets) // for max bonded check
 	state.PendingBonds = dc.pendingBonds()
 	// Extract the expired bonds.
 	state.ExpiredBonds = make([]*db.Bond, len(dc.acct.expiredBonds))
@@ -673,7 +690,7 @@ func (c *Core) postRequiredBonds(
 		return
 	}
 
-	_, err = c.makeAndPostBond(dc, true, wallet, amt, c.feeSuggestionAny(wallet.AssetID), lockTime, bondAsset)
+	_, err = c.makeAndPostBond(dc, true, wallet, amt, c.feeSuggestionAny(wallet.AssetID), lockTime, bondAsset, nil)
 	if err != nil {
 		c.log.Errorf("Unable to post bond: %v", err)
 		return
@@ -1517,7 +1534,7 @@ func (c *Core) PostBond(form *PostBondForm) (*PostBondResult, error) {
 	}
 
 	// Make a bond transaction for the account ID generated from our public key.
-	bondCoin, err := c.makeAndPostBond(dc, acctExists, wallet, form.Bond, feeRate, lockTime, bondAsset)
+	bondCoin, err := c.makeAndPostBond(dc, acctExists, wallet, form.Bond, feeRate, lockTime, bondAsset, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1527,6 +1544,162 @@ func (c *Core) PostBond(form *PostBondForm) (*PostBondResult, error) {
 	return &PostBondResult{BondID: bondCoinStr, ReqConfirms: uint16(bondAsset.Confs)}, nil
 }
 
+// ReplaceBond posts a new bond of newAmount in the asset of the active bond
+// oldBondID, to replace the old bond before it expires without a gap in the
+// account's tier. The old bond continues to count toward the account's tier
+// until the new bond is confirmed and recognized by the server. Then the old
+// bond is relinquished, so that it no longer counts toward tier maintenance,
+// and it is refunded after its lock time like any other expired bond. Bonds
+// cannot be revoked, so the server counts the old bond until it expires. If
+// the new bond fails, the old bond remains in use. A replacement that is still
+// pending, e.g. one stuck unconfirmed, may be superseded by another, and the
+// first of them to be confirmed relinquishes the old bond.
+func (c *Core) ReplaceBond(appPW []byte, host, oldBondID string, newAmount uint64) (*PostBondResult, error) {
+	if newAmount == 0 {
+		return nil, newError(bondAmtErr, "zero bond amount not allowed")
+	}
+	crypter, err := c.encryptionKey(appPW)
+	if err != nil {
+		return nil, codedError(passwordErr, err)
+	}
+	defer crypter.Close()
+
+	dc, err := c.registeredDEX(host)
+	if err != nil {
+		return nil, err
+	}
+	if dc.acct.locked() {
+		return nil, newError(acctKeyErr, "acct locked %s (login first)", host)
+	}
+
+	var oldBond *db.Bond
+	dc.acct.authMtx.RLock()
+	for _, bond := range dc.acct.bonds {
+		if coinIDString(bond.AssetID, bond.CoinID) == oldBondID {
+			oldBond = bond
+			break
+		}
+	}
+	var replaced bool
+	var pendingReplacements []string
+	if oldBond != nil {
+		oldKey := bondKey(oldBond.AssetID, oldBond.CoinID)
+		replaced = dc.acct.relinquishedBonds[oldKey]
+		for _, bond := range dc.acct.pendingBonds {
+			if dc.acct.bondReplacements[bondKey(bond.AssetID, bond.CoinID)] == oldKey {
+				pendingReplacements = append(pendingReplacements, coinIDString(bond.AssetID, bond.CoinID))
+			}
+		}
+	}
+	dc.acct.authMtx.RUnlock()
+	if oldBond == nil {
+		return nil, fmt.Errorf("no active bond %s for %s", oldBondID, dc.acct.host)
+	}
+	if replaced {
+		return nil, fmt.Errorf("bond %s has already been replaced", oldBondID)
+	}
+
+	assetID := oldBond.AssetID
+	bondAsset, _ := dc.bondAsset(assetID)
+	if bondAsset == nil {
+		return nil, newError(assetSupportErr, "dex host %s does not support fidelity bonds in asset %q",
+			dc.acct.host, unbip(assetID))
+	}
+	if rem := newAmount % bondAsset.Amt; rem != 0 {
+		return nil, newError(bondAmtErr, "specified bond amount is not a multiple of the DEX-provided amount. %d %% %d = %d",
+			newAmount, bondAsset.Amt, rem)
+	}
+
+	wallet, err := c.connectedWallet(assetID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s wallet to post bond: %w", unbip(assetID), err)
+	}
+	if _, ok := wallet.Wallet.(asset.Bonder); !ok {
+		return nil, fmt.Errorf("wallet %v is not an asset.Bonder", unbip(assetID))
+	}
+	if err = wallet.checkPeersAndSyncStatus(); err != nil {
+		return nil, err
+	}
+	if !wallet.unlocked() {
+		if err = wallet.Unlock(crypter); err != nil {
+			return nil, newError(walletAuthErr, "failed to unlock %s wallet: %v", unbip(assetID), err)
+		}
+	}
+
+	// The replacement gets a full lifetime, rather than merging with an
+	// existing bond's lock time, which could be the old bond's.
+	lockDur := minBondLifetime(c.net, int64(dc.config().BondExpiry))
+	lockTime := time.Now().Add(lockDur).Truncate(time.Second)
+	feeRate := c.feeSuggestionAny(assetID, dc)
+	// The replacement is stored with the new bond, and registered before the
+	// new bond can be confirmed.
+	bondCoin, err := c.makeAndPostBond(dc, true, wallet, newAmount, feeRate, lockTime, bondAsset, oldBond.CoinID)
+	if err != nil {
+		return nil, err
+	}
+	c.updateBondReserves()
+
+	newBondID := coinIDString(assetID, bondCoin)
+
+	c.log.Infof("Posted bond %s to replace bond %s for %s.", newBondID, oldBondID, dc.acct.host)
+	if len(pendingReplacements) > 0 {
+		c.log.Warnf("Bond %s supersedes pending replacement bonds %v.", newBondID, pendingReplacements)
+	}
+	return &PostBondResult{BondID: newBondID, ReqConfirms: uint16(bondAsset.Confs)}, nil
+}
+
+// addBondReplacement records that the bond, posted by ReplaceBond, replaces the
+// bond identified by its Replaces coin ID. The authMtx must be held.
+func (a *dexAccount) addBondReplacement(bond *db.Bond) {
+	if a.bondReplacements == nil {
+		a.bondReplacements = make(map[string]string)
+	}
+	a.bondReplacements[bondKey(bond.AssetID, bond.CoinID)] = bondKey(bond.AssetID, bond.Replaces)
+}
+
+// restoreBondReplacements restores bondReplacements and relinquishedBonds from
+// the Replaces field of the loaded bonds. The authMtx must be held.
+func (a *dexAccount) restoreBondReplacements() {
+	a.bondReplacements, a.relinquishedBonds = nil, nil
+	for _, bond := range a.pendingBonds {
+		if len(bond.Replaces) > 0 {
+			a.addBondReplacement(bond)
+		}
+	}
+	for _, bond := range a.bonds {
+		if len(bond.Replaces) > 0 {
+			a.addBondReplacement(bond)
+			a.relinquishReplacedBond(bondKey(bond.AssetID, bond.CoinID))
+		}
+	}
+}
+
+// relinquishReplacedBond relinquishes the bond that was replaced by the newly
+// confirmed bond, if the bond was posted by ReplaceBond. Any other pending
+// replacements of the same bond are no longer replacements. The bonds are
+// identified by bondKey. The authMtx must be held.
+func (a *dexAccount) relinquishReplacedBond(newKey string) {
+	oldKey, found := a.bondReplacements[newKey]
+	if !found {
+		return
+	}
+	for k, replacedKey := range a.bondReplacements {
+		if replacedKey == oldKey {
+			delete(a.bondReplacements, k)
+		}
+	}
+	for _, bond := range a.bonds {
+		if bondKey(bond.AssetID, bond.CoinID) == oldKey {
+			if a.relinquishedBonds == nil {
+				a.relinquishedBonds = make(map[string]bool)
+			}
+			a.relinquishedBonds[oldKey] = true
+			return
+		}
+	}
+	// The old bond already expired.
+}
+
 // calculateMergingLockTime calculates a locktime for a new bond for the
 // specified account, with consideration for merging parallel bond tracks.
 // Tracks are merged by choosing the locktime of an existing bond if one exists
@@ -1566,8 +1739,11 @@ func (c *Core) calculateMergingLockTime(dc *dexConnection) (time.Time, error) {
 	return lockTime, nil
 }
 
+// makeAndPostBond creates, stores, and broadcasts a new bond. If replaces is
+// non-nil, it is the coin ID of the bond that the new bond is posted to replace,
+// which is relinquished once the new bond is confirmed.
 func (c *Core) makeAndPostBond(dc *dexConnection, acctExists bool, wallet *xcWallet, amt, feeRate uint64,
-	lockTime time.Time, bondAsset *msgjson.BondAsset) ([]byte, error) {
+	lockTime time.Time, bondAsset *msgjson.BondAsset, replaces []byte) ([]byte, error) {
 
 	bondKey, keyIndex, err := c.nextBondKey(bondAsset.ID)
 	if err != nil {
@@ -1575,6 +1751,11 @@ func (c *Core) makeAndPostBond(dc *dexConnection, acctExists bool, wallet *xcWal
 	}
 	defer bondKey.Zero()
 
+	// The bond's coins are locked until it is broadcast, and tracked as
+	// pending once stored.
+	fundingDone := c.startFunding(wallet.AssetID)
+	defer fundingDone()
+
 	acctID := dc.acct.ID()
 	bond, abandon, err := wallet.MakeBondTx(bondAsset.Version, amt, feeRate, lockTime, bondKey, acctID[:])
 	if err != nil {
@@ -1612,6 +1793,7 @@ func (c *Core) makeAndPostBond(dc *dexConnection, acctExists bool, wallet *xcWal
 		KeyIndex:   keyIndex,
 		RefundTx:   bond.RedeemTx,
 		Strength:   uint32(amt / bondAsset.Amt),
+		Replaces:   replaces,
 		// Confirmed and Refunded are false (new bond tx)
 	}
 
@@ -1644,6 +1826,9 @@ func (c *Core) makeAndPostBond(dc *dexConnection, acctExists bool, wallet *xcWal
 
 	dc.acct.authMtx.Lock()
 	dc.acct.pendingBonds = append(dc.acct.pendingBonds, dbBond)
+	if len(replaces) > 0 {
+		dc.acct.addBondReplacement(dbBond)
+	}
 	dc.acct.authMtx.Unlock()
 
 	if !acctExists { // *after* setting pendingBonds for rotateBonds accounting if targetTier>0
@@ -1699,6 +1884,7 @@ func (c *Core) bondConfirmed(dc *dexConnection, assetID uint32, coinID []byte, p
 			dc.acct.bonds = append(dc.acct.bonds, bond)
 			bond.Confirmed = true // not necessary, just for consistency with slice membership
 			foundPending = true
+			dc.acct.relinquishReplacedBond(bondKey(assetID, coinID))
 			break
 		}
 	}
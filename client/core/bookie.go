@@ -6,6 +6,8 @@ package core
 import (
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -440,11 +442,17 @@ func (dc *dexConnection) syncBook(base, quote uint32) (*orderbook.OrderBook, Boo
 }
 
 // subscribe subscribes to the given market's order book via the 'orderbook'
-// request. The response, which includes book's snapshot, is returned. Proper
+// request. The response, which includes book's snapshot, is returned. If the
+// snapshot is paginated, the rest of its orders are collected from the
+// 'orderbook_page' notifications that follow the response. Proper
 // synchronization is required by the caller to ensure that order feed messages
 // aren't processed before they are prepared to handle this subscription.
 func (dc *dexConnection) subscribe(baseID, quoteID uint32) (*msgjson.OrderBook, error) {
 	mkt := marketName(baseID, quoteID)
+	// The pages may be handled before the response, so start collecting them
+	// before the request.
+	pages := dc.awaitBookPages(mkt)
+	defer dc.stopBookPages(mkt, pages)
 	// Subscribe via the 'orderbook' request.
 	dc.log.Debugf("Subscribing to the %v order book for %v", mkt, dc.acct.host)
 	req, err := msgjson.NewRequest(dc.NextID(), msgjson.OrderBookRoute, &msgjson.OrderBookSubscription{
@@ -468,9 +476,97 @@ func (dc *dexConnection) subscribe(baseID, quoteID uint32) (*msgjson.OrderBook,
 	if err != nil {
 		return nil, err
 	}
+	if result.Pages > 1 {
+		if err := pages.collect(result, DefaultResponseTimeout); err != nil {
+			return nil, fmt.Errorf("error receiving %s orderbook: %w", mkt, err)
+		}
+	}
 	return result, nil
 }
 
+// snapshotPages collects the 'orderbook_page' notifications for a subscription
+// to a market's order book.
+type snapshotPages struct {
+	mtx   sync.Mutex
+	pages []*msgjson.OrderBookPage
+	added chan struct{}
+}
+
+// add stores the page and signals the collector.
+func (sp *snapshotPages) add(page *msgjson.OrderBookPage) {
+	sp.mtx.Lock()
+	sp.pages = append(sp.pages, page)
+	sp.mtx.Unlock()
+	select {
+	case sp.added <- struct{}{}:
+	default:
+	}
+}
+
+// collect waits for the remaining pages of the snapshot and appends their
+// orders to the snapshot's. Pages of other snapshots, e.g. from an earlier
+// subscription, are ignored.
+func (sp *snapshotPages) collect(snap *msgjson.OrderBook, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		sp.mtx.Lock()
+		pages := make([][]*msgjson.BookOrderNote, snap.Pages)
+		var n uint32
+		for _, page := range sp.pages {
+			if page.Seq != snap.Seq || page.Page == 0 || page.Page >= snap.Pages || pages[page.Page] != nil {
+				continue
+			}
+			pages[page.Page] = page.Orders
+			n++
+		}
+		sp.mtx.Unlock()
+		if n == snap.Pages-1 {
+			for _, ords := range pages[1:] {
+				snap.Orders = append(snap.Orders, ords...)
+			}
+			return nil
+		}
+		select {
+		case <-sp.added:
+		case <-timer.C:
+			return fmt.Errorf("timed out waiting for order book snapshot pages. received %d of %d", n+1, snap.Pages)
+		}
+	}
+}
+
+// awaitBookPages starts collecting the 'orderbook_page' notifications for the
+// market. stopBookPages must be called when the subscription is complete.
+func (dc *dexConnection) awaitBookPages(mkt string) *snapshotPages {
+	sp := &snapshotPages{added: make(chan struct{}, 1)}
+	dc.bookPagesMtx.Lock()
+	defer dc.bookPagesMtx.Unlock()
+	if dc.bookPages == nil {
+		dc.bookPages = make(map[string][]*snapshotPages)
+	}
+	dc.bookPages[mkt] = append(dc.bookPages[mkt], sp)
+	return sp
+}
+
+// stopBookPages stops collecting the 'orderbook_page' notifications for the
+// market.
+func (dc *dexConnection) stopBookPages(mkt string, sp *snapshotPages) {
+	dc.bookPagesMtx.Lock()
+	defer dc.bookPagesMtx.Unlock()
+	sps := dc.bookPages[mkt]
+	for i, s := range sps {
+		if s == sp {
+			sps = append(sps[:i], sps[i+1:]...)
+			break
+		}
+	}
+	if len(sps) == 0 {
+		delete(dc.bookPages, mkt)
+	} else {
+		dc.bookPages[mkt] = sps
+	}
+}
+
 // stopBook is the close callback passed to the bookie, and will be called when
 // there are no more subscribers and the close delay period has expired.
 func (dc *dexConnection) stopBook(base, quote uint32) {
@@ -575,14 +671,289 @@ func (c *Core) Book(dex string, base, quote uint32) (*OrderBook, error) {
 		}
 	}
 
-	buys, sells, epoch := book.OrderBook.Orders()
+	buys, sells, epoch, seq := book.OrderBook.OrdersSeq()
 	return &OrderBook{
 		Buys:  book.translateBookSide(buys),
 		Sells: book.translateBookSide(sells),
 		Epoch: book.translateBookSide(epoch),
+		Seq:   seq,
 	}, nil
 }
 
+// RecentTrades fetches up to n of the most recent trades on the market from the
+// server's public trade tape, newest first. If n is zero, all of the trades
+// retained by the server are returned. Trades carry no order or account
+// information, and matches at the same rate and taker side within an epoch are
+// combined.
+func (c *Core) RecentTrades(host string, base, quote uint32, n int) ([]*orderbook.MatchSummary, error) {
+	dc, connected, err := c.dex(host)
+	if err != nil {
+		return nil, err
+	}
+	if !connected {
+		return nil, fmt.Errorf("not connected to %s", dc.acct.host)
+	}
+	if dc.marketConfig(marketName(base, quote)) == nil {
+		return nil, fmt.Errorf("unknown market %s", marketName(base, quote))
+	}
+
+	var trades []*msgjson.PublicTrade
+	req := &msgjson.RecentTradesRequest{
+		BaseID:  base,
+		QuoteID: quote,
+		N:       n,
+	}
+	if err := sendRequest(dc.WsConn, msgjson.RecentTradesRoute, req, &trades, DefaultResponseTimeout); err != nil {
+		return nil, fmt.Errorf("error fetching recent trades: %w", err)
+	}
+
+	summaries := make([]*orderbook.MatchSummary, 0, len(trades))
+	for _, trade := range trades {
+		summaries = append(summaries, &orderbook.MatchSummary{
+			Rate:  trade.Rate,
+			Qty:   trade.Qty,
+			Stamp: trade.Stamp,
+			Sell:  trade.Sell,
+		})
+	}
+	return summaries, nil
+}
+
+// MarketDepth returns the aggregate depth of the synced order book for the
+// specified market, with booked order quantities grouped into price buckets
+// one rate step wide. See MarketDepthAt for other bucket sizes.
+func (c *Core) MarketDepth(host string, base, quote uint32) (*MarketDepth, error) {
+	return c.MarketDepthAt(host, base, quote, 0)
+}
+
+// MarketDepthAt returns the aggregate depth of the synced order book for the
+// specified market. Booked order quantities are grouped into price buckets of
+// width bucketSize, in message-rate units. The bucketSize is rounded up to a
+// multiple of the market's rate step, and is at least one rate step, so that a
+// depth chart can be rendered at different resolutions without the bucket
+// boundaries falling between valid rates. The bucket size used is reported in
+// the returned MarketDepth. The book must already be synced with SyncBook.
+// Epoch orders are not included.
+func (c *Core) MarketDepthAt(host string, base, quote uint32, bucketSize uint64) (*MarketDepth, error) {
+	dc, book, err := c.syncedBook(host, base, quote)
+	if err != nil {
+		return nil, err
+	}
+	mktConf := dc.marketConfig(marketName(base, quote))
+	if mktConf == nil {
+		return nil, fmt.Errorf("unknown market %s", marketName(base, quote))
+	}
+	buys, sells, _ := book.OrderBook.Orders()
+	return marketDepth(buys, sells, alignBucketSize(bucketSize, mktConf.RateStep)), nil
+}
+
+// alignBucketSize rounds the bucketSize up to a multiple of the rate step,
+// with a minimum of one rate step.
+func alignBucketSize(bucketSize, rateStep uint64) uint64 {
+	if rateStep == 0 {
+		rateStep = 1
+	}
+	if bucketSize <= rateStep {
+		return rateStep
+	}
+	if r := bucketSize % rateStep; r != 0 {
+		bucketSize += rateStep - r
+	}
+	return bucketSize
+}
+
+// MidGap returns the mid-gap rate of the synced order book for the specified
+// market, in message-rate units. If one side of the book is empty, the best
+// rate of the other side is returned. The book must already be synced with
+// SyncBook.
+func (c *Core) MidGap(host string, base, quote uint32) (uint64, error) {
+	_, book, err := c.syncedBook(host, base, quote)
+	if err != nil {
+		return 0, err
+	}
+	return book.MidGap()
+}
+
+// VWAP returns the volume-weighted average rate at which an order of the
+// specified base asset quantity would be filled by the synced order book for
+// the market, in message-rate units. A sell order is filled by the book's buy
+// orders, and a buy order by its sell orders. If the book does not have enough
+// depth to fill the quantity, an *InsufficientDepthError is returned. The book
+// must already be synced with SyncBook.
+func (c *Core) VWAP(host string, base, quote uint32, sell bool, qty uint64) (uint64, error) {
+	if qty == 0 {
+		return 0, newError(orderParamsErr, "zero quantity")
+	}
+	_, book, err := c.syncedBook(host, base, quote)
+	if err != nil {
+		return 0, err
+	}
+	fills, filled := book.BestFill(sell, qty)
+	avg, fillQty := vwap(fills)
+	if !filled {
+		return 0, &InsufficientDepthError{Qty: qty, Available: fillQty}
+	}
+	return avg, nil
+}
+
+// vwap calculates the volume-weighted average rate of the fills, and their
+// total quantity.
+func vwap(fills []*orderbook.Fill) (avg, qty uint64) {
+	weightedSum := new(big.Int)
+	for _, f := range fills {
+		weightedSum.Add(weightedSum, new(big.Int).Mul(
+			new(big.Int).SetUint64(f.Rate), new(big.Int).SetUint64(f.Quantity)))
+		qty += f.Quantity
+	}
+	if qty == 0 {
+		return 0, 0
+	}
+	return weightedSum.Div(weightedSum, new(big.Int).SetUint64(qty)).Uint64(), qty
+}
+
+// checkSlippage checks that the projected average fill rate of a market order
+// against the book does not deviate from the best rate by more than the form's
+// MaxSlippage percentage.
+func checkSlippage(book *bookie, form *TradeForm, lotSize uint64) error {
+	var fills []*orderbook.Fill
+	if form.Sell {
+		fills, _ = book.BestFill(true, form.Qty)
+	} else {
+		fills, _ = book.BestFillMarketBuy(form.Qty, lotSize)
+	}
+	if len(fills) == 0 {
+		return newError(orderParamsErr, "no book orders to match the market order")
+	}
+	best := fills[0].Rate
+	avg, _ := vwap(fills)
+	slippage := math.Abs(float64(avg)-float64(best)) / float64(best) * 100
+	if slippage > form.MaxSlippage {
+		return newError(orderParamsErr, "projected average fill rate %d deviates from the best rate %d by %.2f%%, "+
+			"which exceeds the maximum slippage of %.2f%%", avg, best, slippage, form.MaxSlippage)
+	}
+	return nil
+}
+
+// worstRateLimitForm converts a market order form with a WorstRate into an
+// immediate limit order form at that rate. The rate is rounded to the rate step
+// in the direction that keeps it acceptable. The quantity of a market buy,
+// which is in units of the quote asset, is converted to a whole number of lots
+// of the base asset at the worst rate.
+func worstRateLimitForm(form *TradeForm, mktConf *msgjson.Market) (*TradeForm, error) {
+	rate := form.WorstRate
+	qty := form.Qty
+	if form.Sell {
+		if r := rate % mktConf.RateStep; r != 0 {
+			rate += mktConf.RateStep - r
+		}
+	} else {
+		rate -= rate % mktConf.RateStep
+		if rate == 0 {
+			return nil, newError(orderParamsErr, "worst rate %d is less than the rate step %d",
+				form.WorstRate, mktConf.RateStep)
+		}
+		qty = calc.QuoteToBase(rate, qty)
+		qty -= qty % mktConf.LotSize
+	}
+	limitForm := *form
+	limitForm.IsLimit = true
+	limitForm.TifNow = true
+	limitForm.Rate = rate
+	limitForm.Qty = qty
+	return &limitForm, nil
+}
+
+// quoteQtyLimitForm converts a limit order form with a quantity in units of
+// the quote asset into a form with a quantity in the base asset. See
+// quoteToBaseLots.
+func quoteQtyLimitForm(form *TradeForm, mktConf *msgjson.Market) (*TradeForm, error) {
+	if !form.IsLimit {
+		return nil, newError(orderParamsErr, "quote asset quantities are only supported for limit orders")
+	}
+	if form.Rate == 0 {
+		return nil, newError(orderParamsErr, "zero-rate order not allowed")
+	}
+	qty := quoteToBaseLots(form.Qty, form.Rate, mktConf.LotSize)
+	if qty == 0 {
+		return nil, newError(orderParamsErr, "quote quantity %d is worth less than half a lot (lot size %d) at rate %d",
+			form.Qty, mktConf.LotSize, form.Rate)
+	}
+	baseForm := *form
+	baseForm.Qty = qty
+	baseForm.QtyInQuote = false
+	return &baseForm, nil
+}
+
+// quoteToBaseLots converts the quote asset quantity to the whole number of lots
+// of the base asset that is nearest in value at the rate. Half a lot or more
+// rounds up. The result is zero if the quote quantity is worth less than half
+// a lot.
+func quoteToBaseLots(quoteQty, rate, lotSize uint64) uint64 {
+	baseQty := calc.QuoteToBase(rate, quoteQty)
+	lots := (baseQty + lotSize/2) / lotSize
+	return lots * lotSize
+}
+
+// syncedBook returns the dexConnection and synced order book for the specified
+// market.
+func (c *Core) syncedBook(host string, base, quote uint32) (*dexConnection, *bookie, error) {
+	dc, _, err := c.dex(host)
+	if err != nil {
+		return nil, nil, err
+	}
+	mktID := marketName(base, quote)
+	book := dc.bookie(mktID)
+	if book == nil {
+		return nil, nil, fmt.Errorf("no synced order book for market %s", mktID)
+	}
+	return dc, book, nil
+}
+
+// marketDepth computes the bucketed depth for the sorted buy and sell sides of
+// an order book. Buy rates are rounded down and sell rates are rounded up to a
+// multiple of bucketSize, so that each bucket's cumulative quantity is
+// available at a rate at least as good as the bucket rate.
+func marketDepth(buys, sells []*orderbook.Order, bucketSize uint64) *MarketDepth {
+	bucketSide := func(ords []*orderbook.Order, roundUp bool) []*DepthBucket {
+		buckets := make([]*DepthBucket, 0)
+		var cum uint64
+		for _, o := range ords {
+			rate := o.Rate - o.Rate%bucketSize
+			if roundUp && rate != o.Rate {
+				rate += bucketSize
+			}
+			cum += o.Quantity
+			if n := len(buckets); n > 0 && buckets[n-1].Rate == rate {
+				buckets[n-1].Qty += o.Quantity
+				buckets[n-1].CumulativeQty = cum
+				continue
+			}
+			buckets = append(buckets, &DepthBucket{
+				Rate:          rate,
+				Qty:           o.Quantity,
+				CumulativeQty: cum,
+			})
+		}
+		return buckets
+	}
+
+	depth := &MarketDepth{
+		BucketSize: bucketSize,
+		Bids:       bucketSide(buys, false),
+		Asks:       bucketSide(sells, true),
+	}
+	if len(buys) > 0 {
+		depth.BestBid = buys[0].Rate
+	}
+	if len(sells) > 0 {
+		depth.BestAsk = sells[0].Rate
+	}
+	if depth.BestBid > 0 && depth.BestAsk > depth.BestBid {
+		depth.Spread = depth.BestAsk - depth.BestBid
+	}
+	return depth
+}
+
 // translateBookSide translates from []*orderbook.Order to []*MiniOrder.
 func (b *bookie) translateBookSide(ins []*orderbook.Order) (outs []*MiniOrder) {
 	for _, o := range ins {
@@ -599,6 +970,25 @@ func (b *bookie) translateBookSide(ins []*orderbook.Order) (outs []*MiniOrder) {
 	return
 }
 
+// handleOrderBookPageMsg is called when an orderbook_page notification is
+// received.
+func handleOrderBookPageMsg(_ *Core, dc *dexConnection, msg *msgjson.Message) error {
+	page := new(msgjson.OrderBookPage)
+	if err := msg.Unmarshal(page); err != nil {
+		return fmt.Errorf("orderbook page note unmarshal error: %w", err)
+	}
+	dc.bookPagesMtx.Lock()
+	defer dc.bookPagesMtx.Unlock()
+	sps := dc.bookPages[page.MarketID]
+	if len(sps) == 0 {
+		return fmt.Errorf("no %s order book subscription awaiting snapshot page %d", page.MarketID, page.Page)
+	}
+	for _, sp := range sps {
+		sp.add(page)
+	}
+	return nil
+}
+
 // handleBookOrderMsg is called when a book_order notification is received.
 func handleBookOrderMsg(_ *Core, dc *dexConnection, msg *msgjson.Message) error {
 	note := new(msgjson.BookOrderNote)
@@ -670,6 +1060,40 @@ func (dc *dexConnection) setMarketFinalEpoch(name string, finalEpoch uint64, per
 	mkt.Persist = &persist
 }
 
+// setMarketParams revises the LotSize and RateStep fields of the named market in
+// the stored ConfigResponse.
+func (dc *dexConnection) setMarketParams(name string, lotSize, rateStep uint64) bool {
+	dc.cfgMtx.Lock()
+	defer dc.cfgMtx.Unlock()
+	mkt := dc.findMarketConfig(name)
+	if mkt == nil {
+		return false
+	}
+	mkt.LotSize = lotSize
+	mkt.RateStep = rateStep
+	return true
+}
+
+// handleMarketConfigMsg is called when a market config notification is
+// received, which happens when the server changes a market's lot size and rate
+// step. The new parameters apply to orders submitted from now on.
+func handleMarketConfigMsg(_ *Core, dc *dexConnection, msg *msgjson.Message) error {
+	var mkt msgjson.Market
+	if err := msg.Unmarshal(&mkt); err != nil {
+		return fmt.Errorf("market config unmarshal error: %w", err)
+	}
+	if mkt.LotSize == 0 || mkt.RateStep == 0 {
+		return fmt.Errorf("invalid market config for %s: lot size %d, rate step %d",
+			mkt.Name, mkt.LotSize, mkt.RateStep)
+	}
+	if !dc.setMarketParams(mkt.Name, mkt.LotSize, mkt.RateStep) {
+		return fmt.Errorf("no market at %v found with ID %s", dc.acct.host, mkt.Name)
+	}
+	dc.log.Infof("Market %s at %v now has lot size %d and rate step %d",
+		mkt.Name, dc.acct.host, mkt.LotSize, mkt.RateStep)
+	return nil
+}
+
 // handleTradeSuspensionMsg is called when a trade suspension notification is
 // received. This message may come in advance of suspension, in which case it
 // has a SuspendTime set, or at the time of suspension if subscribed to the
@@ -693,8 +1117,10 @@ func handleTradeSuspensionMsg(c *Core, dc *dexConnection, msg *msgjson.Message)
 	// SuspendTime == 0 means suspending now.
 	if sp.SuspendTime != 0 {
 		// This is just a warning about a scheduled suspension.
-		suspendTime := time.UnixMilli(int64(sp.SuspendTime))
-		subject, detail := c.formatDetails(TopicMarketSuspendScheduled, sp.MarketID, dc.acct.host, suspendTime)
+		if err := sp.SuspendTime.Validate(); err != nil {
+			return fmt.Errorf("trade suspension for %s: %w", sp.MarketID, err)
+		}
+		subject, detail := c.formatDetails(TopicMarketSuspendScheduled, sp.MarketID, dc.acct.host, sp.SuspendTime.Time())
 		c.notify(newServerNotifyNote(TopicMarketSuspendScheduled, subject, detail, db.WarningLevel))
 		return nil
 	}
@@ -782,9 +1208,11 @@ func handleTradeResumptionMsg(c *Core, dc *dexConnection, msg *msgjson.Message)
 	// rs.ResumeTime == 0 means resume now.
 	if rs.ResumeTime != 0 {
 		// This is just a notice about a scheduled resumption.
+		if err := rs.ResumeTime.Validate(); err != nil {
+			return fmt.Errorf("trade resumption for %s: %w", rs.MarketID, err)
+		}
 		dc.setMarketStartEpoch(rs.MarketID, rs.StartEpoch, false) // set the start epoch, leaving any final/persist data
-		resTime := time.UnixMilli(int64(rs.ResumeTime))
-		subject, detail := c.formatDetails(TopicMarketResumeScheduled, rs.MarketID, dc.acct.host, resTime)
+		subject, detail := c.formatDetails(TopicMarketResumeScheduled, rs.MarketID, dc.acct.host, rs.ResumeTime.Time())
 		c.notify(newServerNotifyNote(TopicMarketResumeScheduled, subject, detail, db.WarningLevel))
 		return nil
 	}
@@ -886,6 +1314,30 @@ func (dc *dexConnection) refreshServerConfig() (*msgjson.ConfigResult, error) {
 	return cfg, nil
 }
 
+// clientFeatures are the optional protocol features that the client supports.
+var clientFeatures = []string{
+	msgjson.FeatureAnnouncements,
+	msgjson.FeatureBookPages,
+}
+
+// negotiateFeatures advertises the optional protocol features that the client
+// supports. The agreed features only apply to the current connection, so this
+// must be done again on reconnect. Servers that predate feature negotiation
+// don't know the route, and use all of their optional features.
+func (dc *dexConnection) negotiateFeatures() {
+	res := new(msgjson.FeaturesResult)
+	err := sendRequest(dc.WsConn, msgjson.FeaturesRoute, &msgjson.FeaturesRequest{Features: clientFeatures}, res, DefaultResponseTimeout)
+	if err != nil {
+		var msgErr *msgjson.Error
+		// Ignore old servers' errors.
+		if !errors.As(err, &msgErr) || msgErr.Code != msgjson.RPCUnknownRoute {
+			dc.log.Errorf("negotiateFeatures: unable to negotiate features: %v", err)
+		}
+		return
+	}
+	dc.log.Debugf("Server %v agreed to features %v", dc.acct.host, res.Features)
+}
+
 // subPriceFeed subscribes to the price_feed notification feed and primes the
 // initial prices.
 func (dc *dexConnection) subPriceFeed() {
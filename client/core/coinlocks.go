@@ -0,0 +1,170 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"sync"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/order"
+)
+
+// LockedCoin is a coin that is locked in a wallet. OrderID is the ID of the
+// order that the coin is funding, and is empty if the coin is not tied to any
+// order known to Core, i.e. the lock is orphaned.
+type LockedCoin struct {
+	ID      dex.Bytes `json:"id"`
+	Coin    string    `json:"coin"`
+	Value   uint64    `json:"value"`
+	OrderID dex.Bytes `json:"orderID,omitempty"`
+}
+
+// startFunding registers an order or bond that is being funded with coins
+// locked in the asset's wallet. The returned function must be called once the
+// coins are tracked, or have been returned. ReleaseOrphanedLocks is refused
+// while any funding is registered for the asset, and funding can't start while
+// ReleaseOrphanedLocks is running.
+func (c *Core) startFunding(assetID uint32) (done func()) {
+	c.fundingMtx.Lock()
+	if c.funding == nil {
+		c.funding = make(map[uint32]int)
+	}
+	c.funding[assetID]++
+	c.fundingMtx.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.fundingMtx.Lock()
+			if c.funding[assetID]--; c.funding[assetID] <= 0 {
+				delete(c.funding, assetID)
+			}
+			c.fundingMtx.Unlock()
+		})
+	}
+}
+
+// coinLockLister returns the wallet for the asset as an asset.CoinLockLister.
+func (c *Core) coinLockLister(assetID uint32) (*xcWallet, asset.CoinLockLister, error) {
+	wallet, found := c.wallet(assetID)
+	if !found {
+		return nil, nil, newError(missingWalletErr, "no %s wallet", unbip(assetID))
+	}
+	lister, is := wallet.Wallet.(asset.CoinLockLister)
+	if !is {
+		return nil, nil, newError(walletErr, "%s wallet cannot list locked coins", unbip(assetID))
+	}
+	if !wallet.connected() {
+		return nil, nil, errWalletNotConnected
+	}
+	return wallet, lister, nil
+}
+
+// coinOrders maps the IDs of the coins that the asset's trades are funded
+// with, including any locked change, to the ID of the order. Every trade
+// still tracked is included, active or not, so that a lock is only ever
+// considered orphaned if no trade could possibly need it.
+func (c *Core) coinOrders(assetID uint32) map[string]order.OrderID {
+	orders := make(map[string]order.OrderID)
+	for _, dc := range c.dexConnections() {
+		for _, tracker := range dc.trackedTrades() {
+			if tracker.fromAssetID != assetID {
+				continue
+			}
+			oid := tracker.ID()
+			tracker.mtx.RLock()
+			for _, coin := range tracker.coins {
+				orders[string(coin.ID())] = oid
+			}
+			if tracker.change != nil {
+				orders[string(tracker.change.ID())] = oid
+			}
+			tracker.mtx.RUnlock()
+		}
+	}
+	return orders
+}
+
+// LockedCoins lists the unspent coins that are locked in the asset's wallet,
+// along with the order that each coin is funding, if any.
+func (c *Core) LockedCoins(assetID uint32) ([]*LockedCoin, error) {
+	_, lister, err := c.coinLockLister(assetID)
+	if err != nil {
+		return nil, err
+	}
+	coins, err := lister.LockedCoins()
+	if err != nil {
+		return nil, newError(walletErr, "error listing %s locked coins: %w", unbip(assetID), err)
+	}
+	orders := c.coinOrders(assetID)
+	lockedCoins := make([]*LockedCoin, 0, len(coins))
+	for _, coin := range coins {
+		lc := &LockedCoin{
+			ID:    coin.ID(),
+			Coin:  coin.String(),
+			Value: coin.Value(),
+		}
+		if oid, found := orders[string(coin.ID())]; found {
+			lc.OrderID = oid[:]
+		}
+		lockedCoins = append(lockedCoins, lc)
+	}
+	return lockedCoins, nil
+}
+
+// ReleaseOrphanedLocks unlocks the coins in the asset's wallet that are
+// locked but not funding any order known to Core, e.g. locks left behind by
+// an order that failed after funding. The wallet only reports locked coins
+// that are verified to be unspent. Since bonds and orders that are still
+// being funded or submitted have locked coins that are not yet tracked, the
+// release is refused while any exist for the asset, and no new funding is
+// started until the release is done. The released coins are returned.
+func (c *Core) ReleaseOrphanedLocks(assetID uint32) ([]*LockedCoin, error) {
+	wallet, lister, err := c.coinLockLister(assetID)
+	if err != nil {
+		return nil, err
+	}
+	c.fundingMtx.Lock()
+	defer c.fundingMtx.Unlock()
+	if c.funding[assetID] > 0 {
+		return nil, newError(activeOrdersErr, "%s orders or bonds are being funded", unbip(assetID))
+	}
+	for _, dc := range c.dexConnections() {
+		dc.acct.authMtx.RLock()
+		for _, pb := range dc.pendingBonds() {
+			if pb.AssetID == assetID {
+				dc.acct.authMtx.RUnlock()
+				return nil, newError(activeOrdersErr, "pending %s bonds", unbip(assetID))
+			}
+		}
+		dc.acct.authMtx.RUnlock()
+	}
+
+	coins, err := lister.LockedCoins()
+	if err != nil {
+		return nil, newError(walletErr, "error listing %s locked coins: %w", unbip(assetID), err)
+	}
+	orders := c.coinOrders(assetID)
+	orphans := make(asset.Coins, 0, len(coins))
+	released := make([]*LockedCoin, 0, len(coins))
+	for _, coin := range coins {
+		if _, found := orders[string(coin.ID())]; found {
+			continue
+		}
+		orphans = append(orphans, coin)
+		released = append(released, &LockedCoin{
+			ID:    coin.ID(),
+			Coin:  coin.String(),
+			Value: coin.Value(),
+		})
+	}
+	if len(orphans) == 0 {
+		return released, nil
+	}
+	if err := wallet.ReturnCoins(orphans); err != nil {
+		return nil, newError(walletErr, "error releasing %s locked coins: %w", unbip(assetID), err)
+	}
+	c.log.Infof("Released %d orphaned %s coin locks", len(orphans), unbip(assetID))
+	return released, nil
+}
@@ -93,6 +93,10 @@ const (
 
 	// walletLockTimeout is the default timeout used when locking wallets.
 	walletLockTimeout = 5 * time.Second
+
+	// derivationCheckAddrs is the number of addresses compared by
+	// VerifyWalletDerivation.
+	derivationCheckAddrs = 5
 )
 
 var (
@@ -165,6 +169,11 @@ type dexConnection struct {
 	booksMtx sync.RWMutex
 	books    map[string]*bookie
 
+	// bookPages are the collectors of the pages of paginated order book
+	// snapshots for in-progress subscriptions, by market.
+	bookPagesMtx sync.Mutex
+	bookPages    map[string][]*snapshotPages
+
 	// tradeMtx is used to synchronize access to the trades map.
 	tradeMtx sync.RWMutex
 	// trades tracks outstanding orders issued by this client.
@@ -196,6 +205,12 @@ type dexConnection struct {
 	anomaliesCount uint32 // atomic
 	lastConnectMtx sync.RWMutex
 	lastConnect    time.Time
+
+	// announcements maps the IDs of the operator announcements that have
+	// been shown to their expiry, so that an announcement resent on reconnect
+	// is not shown again.
+	announcementsMtx sync.Mutex
+	announcements    map[uint64]uint64
 }
 
 // DefaultResponseTimeout is the default timeout for responses after a request is
@@ -339,6 +354,8 @@ func coreMarketFromMsgMarket(dc *dexConnection, msgMkt *msgjson.Market) *Market
 		QuoteSymbol:     quote.Symbol,
 		LotSize:         msgMkt.LotSize,
 		ParcelSize:      msgMkt.ParcelSize,
+		MinOrderLots:    msgMkt.MinOrderLots,
+		MaxOpenOrders:   msgMkt.MaxOpenOrders,
 		RateStep:        msgMkt.RateStep,
 		EpochLen:        msgMkt.EpochLen,
 		StartEpoch:      msgMkt.StartEpoch,
@@ -346,6 +363,8 @@ func coreMarketFromMsgMarket(dc *dexConnection, msgMkt *msgjson.Market) *Market
 		AtomToConv:      float64(bconv) / float64(qconv),
 		MinimumRate:     dc.minimumMarketRate(quote, msgMkt.LotSize),
 	}
+	mkt.MinOrderLifetime = msgMkt.MinOrderLifetime
+	mkt.MaxOrderLifetime = msgMkt.MaxOrderLifetime
 
 	trades, inFlight := dc.marketTrades(mkt.marketName())
 	mkt.InFlightOrders = inFlight
@@ -353,6 +372,7 @@ func coreMarketFromMsgMarket(dc *dexConnection, msgMkt *msgjson.Market) *Market
 	for _, trade := range trades {
 		mkt.Orders = append(mkt.Orders, trade.coreOrder())
 	}
+	mkt.StandingOrders = dc.standingOrders(mkt.marketName())
 
 	return mkt
 }
@@ -422,6 +442,32 @@ func (dc *dexConnection) marketTrades(mktID string) ([]*trackedTrade, []*InFligh
 	return trades, inFlight
 }
 
+// standingOrders counts the standing limit orders on the market that are in
+// the epoch queue or booked, including orders that are in flight. The server
+// limits the number of standing orders an account may have on a market.
+func (dc *dexConnection) standingOrders(mktID string) int {
+	trades, inFlight := dc.marketTrades(mktID)
+	var n int
+	for _, trade := range trades {
+		lo, ok := trade.Order.(*order.LimitOrder)
+		if !ok || lo.Force != order.StandingTiF {
+			continue
+		}
+		trade.mtx.RLock()
+		status := trade.metaData.Status
+		trade.mtx.RUnlock()
+		if status == order.OrderStatusEpoch || status == order.OrderStatusBooked {
+			n++
+		}
+	}
+	for _, ord := range inFlight {
+		if ord.Type == order.LimitOrderType && ord.TimeInForce == order.StandingTiF {
+			n++
+		}
+	}
+	return n
+}
+
 // pendingBonds returns the PendingBondState for all pending bonds. pendingBonds
 // should be called with the acct.authMtx locked.
 func (dc *dexConnection) pendingBonds() []*PendingBondState {
@@ -671,6 +717,15 @@ func (c *Core) tryCancelTrade(dc *dexConnection, tracker *trackedTrade) error {
 		return fmt.Errorf("order %v not cancellable in status %v", oid, status)
 	}
 
+	// The server will refuse to cancel an order younger than the market's
+	// minimum order lifetime.
+	if minLifetime := time.Duration(mktConf.MinOrderLifetime) * time.Millisecond; minLifetime > 0 {
+		if age := time.Since(tracker.Prefix().ServerTime); age < minLifetime {
+			return newError(orderParamsErr, "order %s cannot be canceled until it is %v old, the market's minimum order lifetime",
+				oid, minLifetime)
+		}
+	}
+
 	if tracker.cancel != nil {
 		// Existing cancel might be stale. Deleting it now allows this
 		// cancel attempt to proceed.
@@ -1337,6 +1392,26 @@ func (dc *dexConnection) bestBookFeeSuggestion(assetID uint32) uint64 {
 	return 0
 }
 
+// bestBookFeeRange attempts to find a recommended fee rate range for the
+// specified asset in any synced book.
+func (dc *dexConnection) bestBookFeeRange(assetID uint32) *msgjson.FeeRateRange {
+	dc.booksMtx.RLock()
+	defer dc.booksMtx.RUnlock()
+	for _, book := range dc.books {
+		var feeRange *msgjson.FeeRateRange
+		switch assetID {
+		case book.base:
+			feeRange = book.BaseFeeRange()
+		case book.quote:
+			feeRange = book.QuoteFeeRange()
+		}
+		if feeRange != nil {
+			return feeRange
+		}
+	}
+	return nil
+}
+
 type pokesCache struct {
 	sync.RWMutex
 	cache         []*db.Notification
@@ -1426,6 +1501,10 @@ type Config struct {
 	// on shutdown. This is useful if the consumer is using the BackupDB method,
 	// or simply creating manual backups of the DB file after shutdown.
 	NoAutoDBBackup bool // zero value is legacy behavior
+	// DBPruneAge is the age after which completed orders and matches are
+	// pruned from the DB. Orders and matches that are still active, e.g.
+	// awaiting a refund, are retained. Zero disables pruning.
+	DBPruneAge time.Duration
 	// UnlockCoinsOnLogin indicates that on wallet connect during login, or on
 	// creation of a new wallet, all coins with the wallet should be unlocked.
 	UnlockCoinsOnLogin bool
@@ -1433,6 +1512,41 @@ type Config struct {
 	// for running core in extension mode, which gives the caller options for
 	// e.g. limiting the ability to configure wallets.
 	ExtensionModeFile string
+	// ReconnectInterval is the initial wait between attempts to reestablish a
+	// lost connection to a DEX server. The wait doubles with each failed
+	// attempt, with random jitter, up to MaxReconnectInterval. Zero values use
+	// the comms package defaults.
+	ReconnectInterval    time.Duration
+	MaxReconnectInterval time.Duration
+	// MaxMessageSize is an optional limit on the size of messages from DEX
+	// servers. Zero means no limit.
+	MaxMessageSize int64
+	// NoteDelivery configures the delivery of notifications to an external
+	// sink, such as a webhook or email, for unattended operation. If nil,
+	// notifications are not delivered externally.
+	NoteDelivery *NoteDeliveryConfig
+	// Faucet configures requesting funds from a testnet faucet. The faucet is
+	// disabled on mainnet.
+	Faucet *FaucetConfig
+	// RedeemConfs are the numbers of confirmations to wait for on a
+	// counterparty's swap before redeeming it, by asset ID. A count that is
+	// not more than the server's required confirmations has no effect. Extra
+	// confirmations are abandoned if waiting for them would risk missing the
+	// server's broadcast timeout or the counterparty's refund.
+	RedeemConfs map[uint32]uint32
+	// PriceOracle configures the comparison of synced market prices with a
+	// reference price source. If nil, prices are not checked, and the price
+	// band guard uses the fiat exchange rates as its reference.
+	PriceOracle *PriceOracleConfig
+	// PriceBand is the maximum deviation, in percent, of a limit order's rate
+	// from the market's reference price. Zero disables the guard. See
+	// SetPriceBand.
+	PriceBand float64
+	// ExplorerURLs are block explorer transaction URL templates by asset ID
+	// that override the assets' default explorers. The transaction ID
+	// placeholder asset.ExplorerTxID in a template is replaced with the ID of
+	// the transaction. See SupportedAsset.ExplorerTxURL.
+	ExplorerURLs map[uint32]string
 }
 
 // locale is data associated with the currently selected language.
@@ -1486,12 +1600,21 @@ type Core struct {
 	tickSchedMtx sync.Mutex
 	tickSched    map[order.OrderID]*time.Timer
 
+	// ttlTimers are the timers that cancel standing limit orders with a TTL.
+	ttlTimersMtx sync.Mutex
+	ttlTimers    map[order.OrderID]*time.Timer
+
 	noteMtx   sync.RWMutex
 	noteChans map[uint64]chan Notification
 
 	sentCommitsMtx sync.Mutex
 	sentCommits    map[order.Commitment]chan struct{}
 
+	// fundingMtx guards funding, the number of orders and bonds per asset
+	// with locked coins that are not yet tracked. See startFunding.
+	fundingMtx sync.Mutex
+	funding    map[uint32]int
+
 	ratesMtx        sync.RWMutex
 	fiatRateSources map[string]*commonRateSource
 
@@ -1506,6 +1629,38 @@ type Core struct {
 
 	requestedActionMtx sync.RWMutex
 	requestedActions   map[string]*asset.ActionRequiredNote
+
+	depositRotatorsMtx sync.RWMutex
+	depositRotators    map[uint32]*depositAddressRotator
+
+	balanceAlertsMtx sync.Mutex
+	balanceAlerts    map[uint32]*balanceAlert
+
+	// orderSchedulesMtx serializes the updates of the stored order schedules.
+	orderSchedulesMtx sync.Mutex
+	// icebergsMtx guards icebergs and serializes the updates of the stored
+	// iceberg orders.
+	icebergsMtx sync.Mutex
+	// icebergs are the active iceberg orders, by ID. icebergs is nil until
+	// they are loaded from the DB.
+	icebergs map[string]*db.IcebergOrder
+
+	// noteDeliverer is nil if external notification delivery is not
+	// configured.
+	noteDeliverer *noteDeliverer
+	// faucet is nil if a faucet is not configured or on mainnet.
+	faucet *faucetClient
+	// priceOracle is nil if a price oracle check is not configured.
+	priceOracle *priceOracleChecker
+	// oracle is the source of reference prices for the price oracle check and
+	// the price band guard. It is the configured PriceOracle, or a
+	// fiatPriceOracle.
+	oracle PriceOracle
+
+	// autoRefund is set by SetAutoRefund.
+	autoRefund atomic.Bool
+	// priceBand is the float64 bits of the price band set by SetPriceBand.
+	priceBand atomic.Uint64
 }
 
 // New is the constructor for a new Core.
@@ -1515,6 +1670,7 @@ func New(cfg *Config) (*Core, error) {
 	}
 	dbOpts := bolt.Opts{
 		BackupOnShutdown: !cfg.NoAutoDBBackup,
+		PruneAge:         cfg.DBPruneAge,
 	}
 	boltDB, err := bolt.NewDB(cfg.DBPath, cfg.Logger.SubLogger("DB"), dbOpts)
 	if err != nil {
@@ -1613,6 +1769,30 @@ func New(cfg *Config) (*Core, error) {
 		}
 	}
 
+	var noteDeliverer *noteDeliverer
+	if cfg.NoteDelivery != nil {
+		noteDeliverer, err = newNoteDeliverer(cfg.NoteDelivery, cfg.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring notification delivery: %w", err)
+		}
+	}
+
+	var faucet *faucetClient
+	if cfg.Faucet != nil {
+		if cfg.Net == dex.Mainnet {
+			cfg.Logger.Warnf("Ignoring faucet configuration on mainnet")
+		} else if faucet, err = newFaucetClient(cfg.Faucet, cfg.Net); err != nil {
+			return nil, fmt.Errorf("error configuring faucet: %w", err)
+		}
+	}
+
+	var priceOracle *priceOracleChecker
+	if cfg.PriceOracle != nil {
+		if priceOracle, err = newPriceOracleChecker(cfg.PriceOracle); err != nil {
+			return nil, fmt.Errorf("error configuring price oracle: %w", err)
+		}
+	}
+
 	c := &Core{
 		cfg:           cfg,
 		credentials:   creds,
@@ -1628,6 +1808,7 @@ func New(cfg *Config) (*Core, error) {
 		blockWaiters:  make(map[string]*blockWaiter),
 		sentCommits:   make(map[order.Commitment]chan struct{}),
 		tickSched:     make(map[order.OrderID]*time.Timer),
+		ttlTimers:     make(map[order.OrderID]*time.Timer),
 		// Allowing to change the constructor makes testing a lot easier.
 		wsConstructor: comms.NewWsConn,
 		newCrypter:    encrypt.NewCrypter,
@@ -1644,6 +1825,34 @@ func New(cfg *Config) (*Core, error) {
 
 		notes:            make(chan asset.WalletNotification, 128),
 		requestedActions: make(map[string]*asset.ActionRequiredNote),
+		depositRotators:  make(map[uint32]*depositAddressRotator),
+		balanceAlerts:    make(map[uint32]*balanceAlert),
+		noteDeliverer:    noteDeliverer,
+		faucet:           faucet,
+		priceOracle:      priceOracle,
+	}
+
+	c.oracle = &fiatPriceOracle{c}
+	if cfg.PriceOracle != nil && cfg.PriceOracle.Oracle != nil {
+		c.oracle = cfg.PriceOracle.Oracle
+	}
+
+	if err := c.SetPriceBand(cfg.PriceBand); err != nil {
+		return nil, err
+	}
+
+	autoRefund, err := boltDB.AutoRefund()
+	if err != nil {
+		return nil, fmt.Errorf("error loading auto-refund setting: %w", err)
+	}
+	c.autoRefund.Store(autoRefund)
+
+	alerts, err := boltDB.BalanceAlerts()
+	if err != nil {
+		return nil, fmt.Errorf("error loading balance alerts: %w", err)
+	}
+	for assetID, threshold := range alerts {
+		c.balanceAlerts[assetID] = &balanceAlert{threshold: threshold}
 	}
 
 	c.intl.Store(&locale{
@@ -1669,6 +1878,9 @@ func (c *Core) Run(ctx context.Context) {
 		close(c.ready) // unblock <-Ready()
 		return
 	}
+	// Resume the deposit address policies before Ready, so that policy
+	// changes made once ready aren't overwritten by the stored policies.
+	c.loadDepositAddressPolicies()
 	close(c.ready)
 
 	// The DB starts first and stops last.
@@ -1686,6 +1898,22 @@ func (c *Core) Run(ctx context.Context) {
 		c.latencyQ.Run(ctx)
 	}()
 
+	if c.noteDeliverer != nil {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.noteDeliverer.run(ctx)
+		}()
+	}
+
+	if c.priceOracle != nil {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.watchPriceOracle(ctx)
+		}()
+	}
+
 	// Retrieve disabled fiat rate sources from database.
 	disabledSources, err := c.db.DisabledRateSources()
 	if err != nil {
@@ -1723,6 +1951,20 @@ fetchers:
 		}
 	}()
 
+	// Place the orders of the order schedules as they come due.
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.watchOrderSchedules(ctx)
+	}()
+
+	// Replenish the iceberg orders as their child orders are filled.
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.watchIcebergOrders(ctx)
+	}()
+
 	// Start bond supervisor.
 	c.wg.Add(1)
 	go func() {
@@ -1846,6 +2088,29 @@ func (c *Core) Language() string {
 	return c.locale().lang.String()
 }
 
+// SetAutoRefund enables or disables the automatic refund of swaps for which
+// the counterparty failed to act once the swap contract's lock time has
+// expired. Auto-refund is disabled until enabled, and the setting persists
+// through restarts. While disabled, expired swaps are left as is, and will be
+// refunded when auto-refund is enabled.
+func (c *Core) SetAutoRefund(enable bool) error {
+	if err := c.db.SetAutoRefund(enable); err != nil {
+		return fmt.Errorf("error storing auto-refund setting: %w", err)
+	}
+	c.autoRefund.Store(enable)
+	if enable {
+		c.log.Infof("Auto-refund of expired swaps enabled")
+	} else {
+		c.log.Infof("Auto-refund of expired swaps disabled")
+	}
+	return nil
+}
+
+// AutoRefund is whether expired swaps are refunded automatically.
+func (c *Core) AutoRefund() bool {
+	return c.autoRefund.Load()
+}
+
 // BackupDB makes a backup of the database at the specified location, optionally
 // overwriting any existing file and compacting the database.
 func (c *Core) BackupDB(dst string, overwrite, compact bool) error {
@@ -1854,9 +2119,24 @@ func (c *Core) BackupDB(dst string, overwrite, compact bool) error {
 
 const defaultDEXPort = "7232"
 
-// addrHost returns the host or url:port pair for an address.
+// addrHost returns the host or url:port pair for an address. The trading
+// identity of an identity-qualified address is kept, e.g. the host for
+// "https://thatonedex.com#alt" is "thatonedex.com:7232#alt".
 func addrHost(addr string) (string, error) {
-	addr = strings.TrimSpace(addr)
+	addr, identity := splitHostIdentity(strings.TrimSpace(addr))
+	if identity != "" && !validIdentity(identity) {
+		return "", fmt.Errorf("addrHost: invalid trading identity name %q", identity)
+	}
+	host, err := netAddrHost(addr)
+	if err != nil || identity == "" {
+		return host, err
+	}
+	return host + identitySep + identity, nil
+}
+
+// netAddrHost is addrHost for an address that is not qualified with a trading
+// identity.
+func netAddrHost(addr string) (string, error) {
 	const defaultHost = "localhost"
 	const missingPort = "missing port in address"
 	// Empty addresses are localhost.
@@ -1974,6 +2254,49 @@ func (c *Core) MarketConfig(host string, baseID, quoteID uint32) (*msgjson.Marke
 	return nil, fmt.Errorf("market (%d, %d) not found for host %s", baseID, quoteID, host)
 }
 
+// SignedConfig fetches the complete server configuration as a document signed
+// by the server's identity key. The signature is verified against the known
+// public key of the server before the document is returned, so it may be
+// exported and later checked with VerifySignedConfig.
+func (c *Core) SignedConfig(host string) (*msgjson.SignedConfig, *msgjson.ConfigResult, error) {
+	dc, connected, err := c.dex(host)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !connected {
+		return nil, nil, fmt.Errorf("not connected to %s", host)
+	}
+	if dc.acct.dexPubKey == nil {
+		return nil, nil, fmt.Errorf("unknown public key for %s", host)
+	}
+	sc := new(msgjson.SignedConfig)
+	if err := sendRequest(dc.WsConn, msgjson.SignedConfigRoute, nil, sc, DefaultResponseTimeout); err != nil {
+		return nil, nil, fmt.Errorf("error fetching signed config: %w", err)
+	}
+	cfg, err := VerifySignedConfig(sc, dc.acct.dexPubKey.SerializeCompressed())
+	if err != nil {
+		return nil, nil, fmt.Errorf("signed config from %s failed verification: %w", host, err)
+	}
+	return sc, cfg, nil
+}
+
+// VerifySignedConfig checks that the signed config document was signed by the
+// server with the serialized public key, and decodes the config. The public
+// key in the config must also match.
+func VerifySignedConfig(sc *msgjson.SignedConfig, pubKey []byte) (*msgjson.ConfigResult, error) {
+	if err := checkSigS256(sc.Serialize(), pubKey, sc.SigBytes()); err != nil {
+		return nil, err
+	}
+	cfg := new(msgjson.ConfigResult)
+	if err := json.Unmarshal(sc.Config, cfg); err != nil {
+		return nil, fmt.Errorf("error decoding config: %w", err)
+	}
+	if !bytes.Equal(cfg.DEXPubKey, pubKey) {
+		return nil, fmt.Errorf("config public key %x does not match %x", cfg.DEXPubKey, pubKey)
+	}
+	return cfg, nil
+}
+
 // dexConnections creates a slice of the *dexConnection in c.conns.
 func (c *Core) dexConnections() []*dexConnection {
 	c.connMtx.RLock()
@@ -1985,6 +2308,49 @@ func (c *Core) dexConnections() []*dexConnection {
 	return conns
 }
 
+// assetDisplay returns the display symbols and aliases that the DEX servers
+// have configured for the asset, keyed by host. Servers may configure
+// different display symbols for the same asset, so they are not merged. The
+// map is nil if no server configures either.
+func (c *Core) assetDisplay(assetID uint32) map[string]*AssetDisplay {
+	var display map[string]*AssetDisplay
+	for _, dc := range c.dexConnections() {
+		dc.assetsMtx.RLock()
+		a := dc.assets[assetID]
+		dc.assetsMtx.RUnlock()
+		if a == nil || (a.DisplaySymbol == "" && len(a.Aliases) == 0) {
+			continue
+		}
+		if display == nil {
+			display = make(map[string]*AssetDisplay, 1)
+		}
+		display[dc.acct.host] = &AssetDisplay{
+			Symbol:  a.DisplaySymbol,
+			Aliases: a.Aliases,
+		}
+	}
+	return display
+}
+
+// assetTicker is the asset's display symbol if every DEX server that
+// configures one agrees on it, otherwise its symbol.
+func (c *Core) assetTicker(assetID uint32) string {
+	var ticker string
+	for _, d := range c.assetDisplay(assetID) {
+		if d.Symbol == "" {
+			continue
+		}
+		if ticker != "" && ticker != d.Symbol {
+			return unbip(assetID)
+		}
+		ticker = d.Symbol
+	}
+	if ticker != "" {
+		return ticker
+	}
+	return unbip(assetID)
+}
+
 // wallet gets the wallet for the specified asset ID in a thread-safe way.
 func (c *Core) wallet(assetID uint32) (*xcWallet, bool) {
 	c.walletMtx.RLock()
@@ -2241,6 +2607,7 @@ func (c *Core) storeAndSendWalletBalance(wallet *xcWallet, walletBal *WalletBala
 		return fmt.Errorf("error updating %s balance in database: %w", unbip(wallet.AssetID), err)
 	}
 	c.notify(newBalanceNote(wallet.AssetID, walletBal))
+	c.checkBalanceAlert(wallet, walletBal)
 	return nil
 }
 
@@ -2250,7 +2617,8 @@ func (c *Core) storeAndSendWalletBalance(wallet *xcWallet, walletBal *WalletBala
 // Only applies to trades where the specified assetID is the fromAssetID.
 func (c *Core) lockedAmounts(assetID uint32) (contractLocked, orderLocked, bondLocked uint64) {
 	for _, dc := range c.dexConnections() {
-		bondLocked, _ = dc.bondTotal(assetID)
+		bonded, _ := dc.bondTotal(assetID)
+		bondLocked += bonded
 		for _, tracker := range dc.trackedTrades() {
 			if tracker.fromAssetID == assetID {
 				tracker.mtx.RLock()
@@ -2263,6 +2631,112 @@ func (c *Core) lockedAmounts(assetID uint32) (contractLocked, orderLocked, bondL
 	return
 }
 
+// BalanceBreakdown retrieves the wallet balance for the asset and breaks it
+// down into available, order-locked, swap-locked, bonded, immature, and other
+// locked amounts. The categories sum to the breakdown's Total.
+func (c *Core) BalanceBreakdown(assetID uint32) (*BalanceBreakdown, error) {
+	wallet, err := c.connectedWallet(assetID)
+	if err != nil {
+		return nil, fmt.Errorf("%d -> %s wallet error: %w", assetID, unbip(assetID), err)
+	}
+	bal, err := c.updateWalletBalance(wallet)
+	if err != nil {
+		return nil, err
+	}
+	return balanceBreakdown(assetID, bal), nil
+}
+
+// balanceBreakdown categorizes the WalletBalance. The order-locked amount is
+// a subset of the wallet's Locked balance, while the contract- and
+// bond-locked amounts are not included in the wallet balance at all. The
+// Locked balance is split between the order-locked and other locked amounts,
+// so the categories always sum to the wallet's Total.
+func balanceBreakdown(assetID uint32, bal *WalletBalance) *BalanceBreakdown {
+	// The wallet's Locked balance should always include order-locked funds,
+	// but some wallets don't report locks for unspent coins.
+	orderLocked := bal.OrderLocked
+	if orderLocked > bal.Locked {
+		orderLocked = bal.Locked
+	}
+	return &BalanceBreakdown{
+		AssetID:     assetID,
+		Available:   bal.Available,
+		OrderLocked: orderLocked,
+		SwapLocked:  bal.ContractLocked,
+		Bonded:      bal.BondLocked,
+		Immature:    bal.Immature,
+		OtherLocked: bal.Locked - orderLocked,
+		Total:       bal.Total(),
+	}
+}
+
+// Portfolio returns a consolidated view of the user's funds across all DEX
+// hosts, with per-host breakdowns of the funds committed to orders, swaps, and
+// bonds. Balances of disconnected wallets are the last known balances.
+func (c *Core) Portfolio() *Portfolio {
+	p := &Portfolio{
+		Assets: make(map[uint32]*BalanceBreakdown),
+		Hosts:  make(map[string]*HostPortfolio),
+	}
+	for _, w := range c.xcWallets() {
+		var bal *WalletBalance
+		if w.connected() {
+			var err error
+			bal, err = c.walletBalance(w)
+			if err != nil {
+				c.log.Errorf("Portfolio: error getting %s balance: %v", unbip(w.AssetID), err)
+			}
+		}
+		if bal == nil {
+			w.mtx.RLock()
+			bal = w.balance
+			w.mtx.RUnlock()
+		}
+		if bal == nil || bal.Balance == nil {
+			continue
+		}
+		p.Assets[w.AssetID] = balanceBreakdown(w.AssetID, bal)
+	}
+
+	for _, dc := range c.dexConnections() {
+		hp := &HostPortfolio{
+			Host:   dc.acct.host,
+			Assets: make(map[uint32]*HostAssetAmounts),
+		}
+		amts := func(assetID uint32) *HostAssetAmounts {
+			a, found := hp.Assets[assetID]
+			if !found {
+				a = new(HostAssetAmounts)
+				hp.Assets[assetID] = a
+			}
+			return a
+		}
+		for _, tracker := range dc.trackedTrades() {
+			if tracker.isActive() {
+				hp.ActiveOrders++
+				hp.ActiveMatches += len(tracker.activeMatches())
+			}
+			tracker.mtx.RLock()
+			swapLocked, orderLocked := tracker.unspentContractAmounts(), tracker.lockedAmount()
+			tracker.mtx.RUnlock()
+			if swapLocked+orderLocked > 0 {
+				a := amts(tracker.fromAssetID)
+				a.SwapLocked += swapLocked
+				a.OrderLocked += orderLocked
+			}
+		}
+		dc.acct.authMtx.RLock()
+		for _, bonds := range [][]*db.Bond{dc.acct.bonds, dc.acct.pendingBonds, dc.acct.expiredBonds} {
+			for _, b := range bonds {
+				amts(b.AssetID).Bonded += b.Amount
+			}
+		}
+		dc.acct.authMtx.RUnlock()
+		p.Hosts[hp.Host] = hp
+	}
+	return p
+}
+
 // updateBalances updates the balance for every key in the counter map.
 // Notifications are sent.
 func (c *Core) updateBalances(assets assetMap) {
@@ -2451,13 +2925,19 @@ func (c *Core) assetMap() map[uint32]*SupportedAsset {
 		if found {
 			wallet = w.state()
 		}
+		display := c.assetDisplay(assetID)
+		txURL, addrURL, outURL := c.explorerURLs(assetID)
 		assets[assetID] = &SupportedAsset{
-			ID:       assetID,
-			Symbol:   asset.Symbol,
-			Wallet:   wallet,
-			Info:     asset.Info,
-			Name:     asset.Info.Name,
-			UnitInfo: asset.Info.UnitInfo,
+			ID:              assetID,
+			Symbol:          asset.Symbol,
+			Wallet:          wallet,
+			Info:            asset.Info,
+			Name:            asset.Info.Name,
+			UnitInfo:        asset.Info.UnitInfo,
+			Display:         display,
+			ExplorerTxURL:   txURL,
+			ExplorerAddrURL: addrURL,
+			ExplorerOutURL:  outURL,
 		}
 		for tokenID, token := range asset.Tokens {
 			wallet = nil
@@ -2465,6 +2945,8 @@ func (c *Core) assetMap() map[uint32]*SupportedAsset {
 			if found {
 				wallet = w.state()
 			}
+			display := c.assetDisplay(tokenID)
+			txURL, addrURL, outURL := c.explorerURLs(tokenID)
 			assets[tokenID] = &SupportedAsset{
 				ID:                    tokenID,
 				Symbol:                dex.BipIDSymbol(tokenID),
@@ -2473,6 +2955,10 @@ func (c *Core) assetMap() map[uint32]*SupportedAsset {
 				Name:                  token.Name,
 				UnitInfo:              token.UnitInfo,
 				WalletCreationPending: c.walletCreationPending(tokenID),
+				Display:               display,
+				ExplorerTxURL:         txURL,
+				ExplorerAddrURL:       addrURL,
+				ExplorerOutURL:        outURL,
 			}
 		}
 	}
@@ -2485,15 +2971,21 @@ func (c *Core) asset(assetID uint32) *SupportedAsset {
 	if w != nil {
 		wallet = w.state()
 	}
+	display := c.assetDisplay(assetID)
+	txURL, addrURL, outURL := c.explorerURLs(assetID)
 	regAsset := asset.Asset(assetID)
 	if regAsset != nil {
 		return &SupportedAsset{
-			ID:       assetID,
-			Symbol:   regAsset.Symbol,
-			Wallet:   wallet,
-			Info:     regAsset.Info,
-			Name:     regAsset.Info.Name,
-			UnitInfo: regAsset.Info.UnitInfo,
+			ID:              assetID,
+			Symbol:          regAsset.Symbol,
+			Wallet:          wallet,
+			Info:            regAsset.Info,
+			Name:            regAsset.Info.Name,
+			UnitInfo:        regAsset.Info.UnitInfo,
+			Display:         display,
+			ExplorerTxURL:   txURL,
+			ExplorerAddrURL: addrURL,
+			ExplorerOutURL:  outURL,
 		}
 	}
 
@@ -2510,9 +3002,31 @@ func (c *Core) asset(assetID uint32) *SupportedAsset {
 		Name:                  token.Name,
 		UnitInfo:              token.UnitInfo,
 		WalletCreationPending: c.walletCreationPending(assetID),
+		Display:               display,
+		ExplorerTxURL:         txURL,
+		ExplorerAddrURL:       addrURL,
+		ExplorerOutURL:        outURL,
 	}
 }
 
+// explorerURLs returns the block explorer URL templates for the asset's
+// transactions, addresses, and transaction outputs on the current network. A
+// transaction URL template for the asset, or for a token's parent chain, in
+// Config.ExplorerURLs takes precedence over the asset's default explorer, and
+// the default explorer's output URL is then not used.
+func (c *Core) explorerURLs(assetID uint32) (txURL, addrURL, outURL string) {
+	txURL, addrURL = asset.ExplorerURLs(assetID, c.net)
+	if tmpl := c.cfg.ExplorerURLs[assetID]; tmpl != "" {
+		return tmpl, addrURL, ""
+	}
+	if token := asset.TokenInfo(assetID); token != nil {
+		if tmpl := c.cfg.ExplorerURLs[token.ParentID]; tmpl != "" {
+			return tmpl, addrURL, ""
+		}
+	}
+	return txURL, addrURL, asset.ExplorerOutputURL(assetID, c.net)
+}
+
 // User is a thread-safe getter for the User.
 func (c *Core) User() *User {
 	return &User{
@@ -2740,6 +3254,17 @@ func (c *Core) createWallet(crypter encrypt.Crypter, walletPW []byte, assetID ui
 		}
 	}
 
+	if len(form.ImportSeed) > 0 {
+		if !walletDef.Seeded {
+			return nil, newError(createWalletErr, "cannot import a seed into a %q-type wallet", walletDef.Type)
+		}
+		if err := asset.ValidateImport(assetID, walletDef.Type, form.ImportSeed); err != nil {
+			return nil, newError(createWalletErr, "invalid %s import seed: %w", unbip(assetID), err)
+		}
+		// Remember that the wallet cannot be restored from the app seed.
+		form.Config[importedSeedSetting] = "true"
+	}
+
 	if walletDef.Seeded {
 		if len(walletPW) > 0 {
 			return nil, errors.New("external password incompatible with seeded wallet")
@@ -2798,9 +3323,14 @@ func (c *Core) createTokenWallet(tokenID uint32, token *asset.Token, form *Walle
 	}, nil
 }
 
+// importedSeedSetting is a wallet setting inserted by Core to mark a seeded
+// wallet that was created from an imported seed.
+const importedSeedSetting = "importedseed"
+
 // createSeededWallet initializes a seeded wallet with an asset-specific seed
-// and password derived deterministically from the app seed. The password is
-// returned for encrypting and storing.
+// and password derived deterministically from the app seed. If the form has an
+// ImportSeed, it is used instead of the derived seed. The password is returned
+// for encrypting and storing.
 func (c *Core) createSeededWallet(assetID uint32, crypter encrypt.Crypter, form *WalletForm) ([]byte, error) {
 	seed, pw, err := c.assetSeedAndPass(assetID, crypter)
 	if err != nil {
@@ -2813,6 +3343,14 @@ func (c *Core) createSeededWallet(assetID uint32, crypter encrypt.Crypter, form
 		bday = uint64(creds.Birthday.Unix())
 	}
 
+	imported := len(form.ImportSeed) > 0
+	if imported {
+		encode.ClearBytes(seed)
+		seed = append([]byte(nil), form.ImportSeed...)
+		// The imported wallet may have history from before the app was created.
+		bday = 0
+	}
+
 	c.log.Infof("Initializing a %s wallet", unbip(assetID))
 	if err = asset.CreateWallet(assetID, &asset.CreateWalletParams{
 		Type:     form.Type,
@@ -2823,6 +3361,7 @@ func (c *Core) createSeededWallet(assetID uint32, crypter encrypt.Crypter, form
 		DataDir:  c.assetDataDirectory(assetID),
 		Net:      c.net,
 		Logger:   c.log.SubLogger(unbip(assetID)),
+		Imported: imported,
 	}); err != nil {
 		return nil, fmt.Errorf("Error creating wallet: %w", err)
 	}
@@ -2914,9 +3453,14 @@ func (c *Core) loadWallet(dbWallet *db.Wallet) (*xcWallet, error) {
 		peerCount:    -1, // no count yet
 		dbID:         dbWallet.ID(),
 		walletType:   dbWallet.Type,
+		importedSeed: dbWallet.Settings[importedSeedSetting] == "true",
+		redeemConfs:  c.cfg.RedeemConfs[assetID],
 		broadcasting: new(uint32),
 		disabled:     dbWallet.Disabled,
 		syncStatus:   &asset.SyncStatus{},
+		display: func() map[string]*AssetDisplay {
+			return c.assetDisplay(assetID)
+		},
 	}
 
 	token := asset.TokenInfo(assetID)
@@ -3140,6 +3684,32 @@ func (c *Core) walletCheckAndNotify(w *xcWallet) bool {
 	return ss.Synced
 }
 
+// WalletSyncStatus returns the blockchain sync progress of the wallet for the
+// specified asset. Wallets that are not asset.SyncStatusers are always synced.
+// Progress notifications are also emitted as WalletSyncNote while a connected
+// wallet is syncing.
+func (c *Core) WalletSyncStatus(assetID uint32) (*WalletSyncStatus, error) {
+	wallet, err := c.connectedWallet(assetID)
+	if err != nil {
+		return nil, err
+	}
+	ss, err := wallet.SyncStatus()
+	if err != nil {
+		return nil, fmt.Errorf("%s SyncStatus error: %w", unbip(assetID), err)
+	}
+	return walletSyncStatus(ss), nil
+}
+
+// walletSyncStatus converts the asset.SyncStatus to a WalletSyncStatus.
+func walletSyncStatus(ss *asset.SyncStatus) *WalletSyncStatus {
+	return &WalletSyncStatus{
+		Synced:       ss.Synced,
+		Height:       ss.Blocks,
+		TargetHeight: ss.TargetHeight,
+		Progress:     ss.BlockProgress() * 100,
+	}
+}
+
 // startWalletSyncMonitor repeatedly calls walletCheckAndNotify on a ticker
 // until it is synced. This launches the monitor goroutine, if not already
 // running, and immediately returns.
@@ -3275,6 +3845,9 @@ func (c *Core) RecoverWallet(assetID uint32, appPW []byte, force bool) error {
 	if !walletDef.Seeded {
 		return fmt.Errorf("can only recover a seeded wallet")
 	}
+	if oldWallet.importedSeed {
+		return fmt.Errorf("cannot recover a wallet created from an imported seed")
+	}
 
 	dbWallet, err := c.db.Wallet(oldWallet.dbID)
 	if err != nil {
@@ -3528,6 +4101,9 @@ func (c *Core) ReconfigureWallet(appPW, newWalletPW []byte, form *WalletForm) er
 	if walletDef.Seeded && newWalletPW != nil {
 		return newError(passwordErr, "cannot set a password on a built-in(seeded) wallet")
 	}
+	if len(form.ImportSeed) > 0 {
+		return newError(createWalletErr, "a seed can only be imported when creating a wallet")
+	}
 
 	oldWallet, found := c.wallet(assetID)
 	if !found {
@@ -3535,6 +4111,13 @@ func (c *Core) ReconfigureWallet(appPW, newWalletPW []byte, form *WalletForm) er
 			assetID, unbip(assetID))
 	}
 
+	if oldWallet.importedSeed && oldWallet.walletType == walletDef.Type {
+		if form.Config == nil {
+			form.Config = make(map[string]string)
+		}
+		form.Config[importedSeedSetting] = "true"
+	}
+
 	if oldWallet.isDisabled() { // disabled wallet cannot perform operation.
 		return fmt.Errorf(walletDisabledErrStr, strings.ToUpper(unbip(assetID)))
 	}
@@ -3570,6 +4153,7 @@ func (c *Core) ReconfigureWallet(appPW, newWalletPW []byte, form *WalletForm) er
 		}
 
 		c.notify(newBalanceNote(assetID, balances)) // redundant with wallet config note?
+		c.checkBalanceAlert(w, balances)
 		subject, details := c.formatDetails(TopicWalletConfigurationUpdated, unbip(assetID), w.address)
 		c.notify(newWalletConfigNote(TopicWalletConfigurationUpdated, subject, details, db.Success, w.state()))
 
@@ -3950,6 +4534,30 @@ func (c *Core) setWalletPassword(wallet *xcWallet, newPW []byte, crypter encrypt
 	return nil
 }
 
+// RequestFaucetFunds requests funds for the asset from the configured testnet
+// faucet, paid to a new deposit address from the asset's wallet. The faucet's
+// transaction ID is returned. The faucet is never available on mainnet, and
+// requests for each asset are rate-limited.
+func (c *Core) RequestFaucetFunds(assetID uint32) (string, error) {
+	if c.faucet == nil {
+		return "", newError(faucetErr, "no faucet configured for %s", c.net)
+	}
+	// Check the rate limit before generating an address that won't be used.
+	if err := c.faucet.reserve(assetID); err != nil {
+		return "", newError(faucetErr, "error requesting %s from faucet: %w", unbip(assetID), err)
+	}
+	addr, err := c.NewDepositAddress(assetID)
+	if err != nil {
+		return "", err
+	}
+	txID, err := c.faucet.request(c.ctx, assetID, addr)
+	if err != nil {
+		return "", newError(faucetErr, "error requesting %s from faucet: %w", unbip(assetID), err)
+	}
+	c.log.Infof("Faucet sent %s to %s in transaction %s", unbip(assetID), addr, txID)
+	return txID, nil
+}
+
 // NewDepositAddress retrieves a new deposit address from the specified asset's
 // wallet, saves it to the database, and emits a notification. If the wallet
 // does not support generating new addresses, the current address will be
@@ -3971,6 +4579,7 @@ func (c *Core) NewDepositAddress(assetID uint32) (string, error) {
 		if err = c.storeDepositAddress(w.dbID, addr); err != nil {
 			return "", err
 		}
+		c.trackDepositAddress(assetID, addr)
 		// Update wallet state in the User data struct and emit a WalletStateNote.
 		c.notify(newWalletStateNote(w.state()))
 	} else {
@@ -3980,6 +4589,36 @@ func (c *Core) NewDepositAddress(assetID uint32) (string, error) {
 	return addr, nil
 }
 
+// LegacyDepositAddress returns the legacy format of a deposit address of the
+// specified asset's wallet, for display to users of services that do not
+// accept the canonical format. Deposit addresses are always generated in the
+// canonical format. An error is returned if the asset has no legacy format.
+func (c *Core) LegacyDepositAddress(assetID uint32, addr string) (string, error) {
+	w, exists := c.wallet(assetID)
+	if !exists {
+		return "", newError(missingWalletErr, "no wallet found for %s", unbip(assetID))
+	}
+	la, is := w.Wallet.(asset.LegacyAddresser)
+	if !is {
+		return "", newError(walletErr, "%s has no legacy address format", unbip(assetID))
+	}
+	if !w.connected() {
+		return "", errWalletNotConnected
+	}
+	owns, err := w.OwnsDepositAddress(addr)
+	if err != nil {
+		return "", newError(walletErr, "error checking %s address: %w", unbip(assetID), err)
+	}
+	if !owns {
+		return "", newError(walletErr, "%s is not a %s deposit address of this wallet", addr, unbip(assetID))
+	}
+	legacyAddr, err := la.LegacyAddress(addr)
+	if err != nil {
+		return "", newError(walletErr, "error encoding legacy %s address: %w", unbip(assetID), err)
+	}
+	return legacyAddr, nil
+}
+
 // AutoWalletConfig attempts to load setting from a wallet package's
 // asset.WalletInfo.DefaultConfigPath. If settings are not found, an empty map
 // is returned.
@@ -5253,23 +5892,6 @@ func (c *Core) feeSuggestionAny(assetID uint32, preferredConns ...*dexConnection
 		}
 	}
 
-	// Helper function to determine if a server has an active market that pairs
-	// the requested asset.
-	hasActiveMarket := func(dc *dexConnection) bool {
-		dc.cfgMtx.RLock()
-		cfg := dc.cfg
-		dc.cfgMtx.RUnlock()
-		if cfg == nil {
-			return false
-		}
-		for _, mkt := range cfg.Markets {
-			if mkt.Base == assetID || mkt.Quote == assetID && mkt.Running() {
-				return true
-			}
-		}
-		return false
-	}
-
 	// Request a rate with fee_rate.
 	for _, dc := range conns {
 		// The server should have at least one active market with the asset,
@@ -5277,7 +5899,7 @@ func (c *Core) feeSuggestionAny(assetID uint32, preferredConns ...*dexConnection
 		// might be supported but not in active use, e.g. down for maintenance.
 		// The fee_rate endpoint will happily return a very old rate without
 		// indication.
-		if !hasActiveMarket(dc) {
+		if !dc.hasActiveMarket(assetID) {
 			continue
 		}
 
@@ -5289,6 +5911,47 @@ func (c *Core) feeSuggestionAny(assetID uint32, preferredConns ...*dexConnection
 	return 0
 }
 
+// hasActiveMarket checks if the server has an active market that pairs the
+// asset.
+func (dc *dexConnection) hasActiveMarket(assetID uint32) bool {
+	dc.cfgMtx.RLock()
+	cfg := dc.cfg
+	dc.cfgMtx.RUnlock()
+	if cfg == nil {
+		return false
+	}
+	for _, mkt := range cfg.Markets {
+		if mkt.Base == assetID || mkt.Quote == assetID && mkt.Running() {
+			return true
+		}
+	}
+	return false
+}
+
+// FeeRateHistory fetches up to n of the most recent fee rate estimates
+// recorded for the asset, oldest first, from the first connected server with
+// an active market for the asset. A server records a rate when it differs from
+// its previous estimate, so the history shows the trend of the rates that the
+// server reports. If n is zero, all of the rates retained by the server are
+// returned.
+func (c *Core) FeeRateHistory(assetID uint32, n int) ([]*msgjson.FeeRateRecord, error) {
+	for _, dc := range c.dexConnections() {
+		if dc.status() != comms.Connected || !dc.hasActiveMarket(assetID) {
+			continue
+		}
+		var rates []*msgjson.FeeRateRecord
+		req := &msgjson.FeeRateHistoryRequest{
+			AssetID: assetID,
+			N:       n,
+		}
+		if err := sendRequest(dc.WsConn, msgjson.FeeRateHistoryRoute, req, &rates, DefaultResponseTimeout); err != nil {
+			return nil, fmt.Errorf("error fetching %s fee rate history from %s: %w", unbip(assetID), dc.acct.host, err)
+		}
+		return rates, nil
+	}
+	return nil, fmt.Errorf("no connected server with an active %s market", unbip(assetID))
+}
+
 // feeSuggestion gets the best fee suggestion, first from a synced order book,
 // and if not synced, directly from the server.
 func (c *Core) feeSuggestion(dc *dexConnection, assetID uint32) (feeSuggestion uint64) {
@@ -5360,16 +6023,95 @@ func (c *Core) Send(pw []byte, assetID uint32, value uint64, address string, sub
 	return coin, nil
 }
 
-// ValidateAddress checks that the provided address is valid.
-func (c *Core) ValidateAddress(address string, assetID uint32) (bool, error) {
+// SendWithFeeLimit sends funds to an external address, refusing to send if the
+// estimated network fee exceeds maxFee. The address must be valid for the
+// asset's current network. If sendMax is true, amount is ignored and the
+// wallet's entire available balance is sent, with the network fee subtracted.
+// Funds locked by orders and swaps, and bond reserves, are not part of the
+// available balance, and are never sent. The sending transaction's ID is
+// returned.
+func (c *Core) SendWithFeeLimit(pw []byte, assetID uint32, address string, amount, maxFee uint64, sendMax bool) (string, error) {
+	status, err := c.ValidateAddress(assetID, address)
+	if err != nil {
+		return "", err
+	}
+	switch status {
+	case AddressWrongNetwork:
+		return "", newError(addressParseErr, "%s address %q is for a different network", c.assetTicker(assetID), address)
+	case AddressMalformed:
+		return "", newError(addressParseErr, "invalid %s address %q", c.assetTicker(assetID), address)
+	}
+
+	wallet, err := c.connectedWallet(assetID)
+	if err != nil {
+		return "", err
+	}
+	bal, err := wallet.Balance()
+	if err != nil {
+		return "", newError(walletBalanceErr, "error getting %s balance: %v", unbip(assetID), err)
+	}
+	ui := wallet.Info().UnitInfo
+	if sendMax {
+		amount = bal.Available
+	} else if amount > bal.Available {
+		return "", newError(walletBalanceErr, "cannot send %s %s with only %s available", ui.ConventionalString(amount),
+			unbip(assetID), ui.ConventionalString(bal.Available))
+	}
+	if amount == 0 {
+		return "", fmt.Errorf("no %s to send", unbip(assetID))
+	}
+
+	fee, _, err := c.EstimateSendTxFee(address, assetID, amount, sendMax, sendMax)
+	if err != nil {
+		return "", fmt.Errorf("error estimating %s network fee: %w", unbip(assetID), err)
+	}
+	if fee > maxFee {
+		feeUI := ui
+		if token := asset.TokenInfo(assetID); token != nil {
+			if parentInfo, err := asset.UnitInfo(token.ParentID); err == nil {
+				feeUI = parentInfo
+			}
+		}
+		return "", fmt.Errorf("estimated network fee %s %s exceeds the maximum fee %s", feeUI.ConventionalString(fee),
+			feeUI.Conventional.Unit, feeUI.ConventionalString(maxFee))
+	}
+
+	coin, err := c.Send(pw, assetID, amount, address, sendMax)
+	if err != nil {
+		return "", err
+	}
+	return coin.TxID(), nil
+}
+
+// ValidateAddress checks that the provided address is valid for the asset and
+// the current network. If the asset's wallet cannot recognize addresses for
+// the asset's other networks, any invalid address is reported as malformed.
+func (c *Core) ValidateAddress(assetID uint32, address string) (AddressStatus, error) {
 	if address == "" {
-		return false, nil
+		return AddressMalformed, nil
 	}
 	wallet, found := c.wallet(assetID)
 	if !found {
-		return false, newError(missingWalletErr, "no wallet found for %s", unbip(assetID))
+		return "", newError(missingWalletErr, "no wallet found for %s", c.assetTicker(assetID))
+	}
+	checker, is := wallet.Wallet.(asset.AddressChecker)
+	if !is {
+		if wallet.Wallet.ValidateAddress(address) {
+			return AddressValid, nil
+		}
+		return AddressMalformed, nil
+	}
+	err := checker.CheckAddress(address)
+	switch {
+	case err == nil:
+		return AddressValid, nil
+	case errors.Is(err, asset.ErrWrongNetworkAddress):
+		c.log.Debugf("%s address %q is for the wrong network: %v", c.assetTicker(assetID), address, err)
+		return AddressWrongNetwork, nil
+	case errors.Is(err, asset.ErrMalformedAddress):
+		return AddressMalformed, nil
 	}
-	return wallet.Wallet.ValidateAddress(address), nil
+	return "", fmt.Errorf("error checking %s address: %w", c.assetTicker(assetID), err)
 }
 
 // ApproveToken calls a wallet's ApproveToken method. It approves the version
@@ -5514,7 +6256,7 @@ func (c *Core) SingleLotFees(form *SingleLotFeesForm) (swapFees, redeemFees, ref
 		return 0, 0, 0, err
 	}
 	if !versCompat { // covers missing asset config, but that's unlikely since there is a market config
-		return 0, 0, 0, fmt.Errorf("client and server asset versions are incompatible for %v", form.Host)
+		return 0, 0, 0, assetVersionError(dc.acct.host, wallets, assetConfigs)
 	}
 
 	var swapFeeRate, redeemFeeRate uint64
@@ -5594,7 +6336,7 @@ func (c *Core) PreOrder(form *TradeForm) (*OrderEstimate, error) {
 		return nil, err
 	}
 	if !versCompat { // covers missing asset config, but that's unlikely since there is a market config
-		return nil, fmt.Errorf("client and server asset versions are incompatible for %v", form.Host)
+		return nil, assetVersionError(dc.acct.host, wallets, assetConfigs)
 	}
 
 	// So here's the thing. Our assets thus far don't require the wallet to be
@@ -5710,6 +6452,78 @@ func (c *Core) PreOrder(form *TradeForm) (*OrderEstimate, error) {
 	}, nil
 }
 
+// EstimateTradingCost estimates the full cost of a standing limit order on the
+// specified market, including the swap and redeem transaction fees, the
+// server's trading fees, and the fidelity bond requirement for trading.
+func (c *Core) EstimateTradingCost(host string, base, quote uint32, qty, rate uint64, sell bool) (*TradingCost, error) {
+	est, err := c.PreOrder(&TradeForm{
+		Host:    host,
+		IsLimit: true,
+		Sell:    sell,
+		Base:    base,
+		Quote:   quote,
+		Qty:     qty,
+		Rate:    rate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if est.Swap == nil || est.Swap.Estimate == nil || est.Redeem == nil || est.Redeem.Estimate == nil {
+		return nil, errors.New("incomplete order estimate")
+	}
+
+	swapAssetID, redeemAssetID := quote, base
+	if sell {
+		swapAssetID, redeemAssetID = base, quote
+	}
+	// The server charges no trading fees. Only the on-chain fees are paid.
+	var tradingFees uint64
+	cost := &TradingCost{
+		SwapAssetID:        swapAssetID,
+		SwapFeeAssetID:     asset.FeeAsset(swapAssetID),
+		SwapFees:           est.Swap.Estimate.RealisticWorstCase,
+		SwapFeesBestCase:   est.Swap.Estimate.RealisticBestCase,
+		RedeemAssetID:      redeemAssetID,
+		RedeemFeeAssetID:   asset.FeeAsset(redeemAssetID),
+		RedeemFees:         est.Redeem.Estimate.RealisticWorstCase,
+		RedeemFeesBestCase: est.Redeem.Estimate.RealisticBestCase,
+		TradingFees:        tradingFees,
+		TotalSwapAssetCost: est.Swap.Estimate.RealisticWorstCase + tradingFees,
+	}
+
+	dc, err := c.registeredDEX(host)
+	if err != nil {
+		return nil, err
+	}
+	cfg := dc.config()
+	if cfg == nil {
+		return nil, fmt.Errorf("no config for %s", host)
+	}
+	bond := &BondRequirement{
+		Amounts: make(map[string]uint64, len(cfg.BondAssets)),
+		Expiry:  cfg.BondExpiry,
+	}
+	var amts []string
+	for symbol, ba := range cfg.BondAssets {
+		bond.Amounts[symbol] = ba.Amt
+		amt := fmt.Sprintf("%d %s atoms", ba.Amt, unbip(ba.ID))
+		if ui, err := asset.UnitInfo(ba.ID); err == nil {
+			amt = fmt.Sprintf("%s %s", ui.ConventionalString(ba.Amt), ui.Conventional.Unit)
+		}
+		amts = append(amts, amt)
+	}
+	sort.Strings(amts)
+	dc.acct.authMtx.RLock()
+	bond.Tier = dc.acct.rep.EffectiveTier()
+	dc.acct.authMtx.RUnlock()
+	bond.Note = fmt.Sprintf("Trading requires a tier 1 account. Each tier requires a bond of %s, "+
+		"which is refunded after %s. Only the bond and refund transaction fees are spent. "+
+		"Your current tier is %d.", strings.Join(amts, " or "),
+		time.Duration(cfg.BondExpiry)*time.Second, bond.Tier)
+	cost.Bond = bond
+	return cost, nil
+}
+
 // MultiTrade is used to place multiple standing limit orders on the same
 // side of the same market simultaneously.
 func (c *Core) MultiTrade(pw []byte, form *MultiTradeForm) ([]*Order, error) {
@@ -5847,6 +6661,9 @@ type tradeRequest struct {
 	errCloser    *dex.ErrorCloser
 	tempID       uint64
 	commitSig    chan struct{}
+	// fundingDone ends the funding registered by startFunding, after the
+	// order's coins are either tracked or returned.
+	fundingDone func()
 }
 
 func (c *Core) prepareForTradeRequestPrep(pw []byte, base, quote uint32, host string, sell bool) (wallets *walletSet, assetConfig *assetSet, dc *dexConnection, mktConf *msgjson.Market, err error) {
@@ -5892,7 +6709,7 @@ func (c *Core) prepareForTradeRequestPrep(pw []byte, base, quote uint32, host st
 		return fail(err)
 	}
 	if !versCompat { // also covers missing asset config, but that's unlikely since there is a market config
-		return fail(fmt.Errorf("client and server asset versions are incompatible for %v", dc.acct.host))
+		return fail(assetVersionError(dc.acct.host, wallets, assetConfigs))
 	}
 
 	fromWallet, toWallet := wallets.fromWallet, wallets.toWallet
@@ -6071,6 +6888,11 @@ func (c *Core) createTradeRequest(wallets *walletSet, coins asset.Coins, redeemS
 	c.sentCommits[prefix.Commit] = commitSig
 	c.sentCommitsMtx.Unlock()
 
+	var cancelAfter uint64
+	if form.TTL > 0 {
+		cancelAfter = uint64(time.Now().Add(time.Duration(form.TTL) * time.Second).UnixMilli())
+	}
+
 	// Prepare order meta data.
 	dbOrder := &db.MetaOrder{
 		MetaData: &db.OrderMetaData{
@@ -6087,6 +6909,7 @@ func (c *Core) createTradeRequest(wallets *walletSet, coins asset.Coins, redeemS
 			RefundReserves:     refundReserves,
 			ChangeCoin:         changeID,
 			FundingFeesPaid:    fundingFees,
+			CancelAfter:        cancelAfter,
 		},
 		Order: ord,
 	}
@@ -6117,6 +6940,49 @@ func (c *Core) prepareTradeRequest(pw []byte, form *TradeForm) (*tradeRequest, e
 	fromWallet, toWallet := wallets.fromWallet, wallets.toWallet
 	mktID := marketName(form.Base, form.Quote)
 
+	if form.IsLimit && !form.OverridePriceBand {
+		err := c.checkPriceBand(dc, form.Base, form.Quote, form.Rate, assetConfigs.baseAsset.UnitInfo, assetConfigs.quoteAsset.UnitInfo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !form.IsLimit {
+		if form.MaxSlippage > 0 {
+			book := dc.bookie(mktID)
+			if book == nil {
+				return nil, newError(orderParamsErr, "slippage protection requires a synced order book")
+			}
+			if err := checkSlippage(book, form, mktConf.LotSize); err != nil {
+				return nil, err
+			}
+		}
+		if form.WorstRate > 0 {
+			if form, err = worstRateLimitForm(form, mktConf); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if form.QtyInQuote {
+		if form, err = quoteQtyLimitForm(form, mktConf); err != nil {
+			return nil, err
+		}
+	}
+
+	if form.TTL > 0 && (!form.IsLimit || form.TifNow) {
+		return nil, newError(orderParamsErr, "a TTL is only allowed for standing limit orders")
+	}
+	if minLifetime := time.Duration(mktConf.MinOrderLifetime) * time.Millisecond; form.TTL > 0 &&
+		time.Duration(form.TTL)*time.Second < minLifetime {
+		return nil, newError(orderParamsErr, "TTL of %d seconds is shorter than the market's minimum order lifetime of %v",
+			form.TTL, minLifetime)
+	}
+
+	if err := checkQuantization(mktConf, form.Qty, form.Rate, form.IsLimit, form.Sell); err != nil {
+		return nil, err
+	}
+
 	rate, qty := form.Rate, form.Qty
 	if form.IsLimit {
 		if rate == 0 {
@@ -6186,13 +7052,51 @@ func (c *Core) prepareTradeRequest(pw []byte, form *TradeForm) (*tradeRequest, e
 			qty, assetConfigs.baseAsset.Symbol, rate, mktConf.LotSize)
 	}
 
+	// The server will refuse orders smaller than the market's minimum order
+	// size. Market buys are checked by the server at the mid-gap rate.
+	if minLots := uint64(mktConf.MinOrderLots); lots < minLots && (form.Sell || form.IsLimit) {
+		return nil, newError(orderParamsErr, "order quantity of %d lots is below the market minimum of %d lots",
+			lots, minLots)
+	}
+
+	// The server will refuse standing orders beyond the market's limit.
+	if maxOrders := mktConf.MaxOpenOrders; maxOrders > 0 && !isImmediate {
+		if n := dc.standingOrders(mktID); n >= int(maxOrders) {
+			return nil, newError(orderParamsErr, "%d standing orders on market %s, the maximum is %d; cancel an order to place another",
+				n, mktID, maxOrders)
+		}
+	}
+
+	// The server will refuse orders that could create swap contracts that are
+	// too small to redeem at its last fee rate, which is the swap fee
+	// suggestion. Market buys are checked by the server at the mid-gap rate.
+	swapFeeSuggestion := c.feeSuggestion(dc, assetConfigs.fromAsset.ID)
+	if dustLimit := dustLimitAt(assetConfigs.fromAsset, swapFeeSuggestion); dustLimit > 0 && (form.Sell || form.IsLimit) {
+		swapLotSize := lotSize
+		if !form.Sell {
+			swapLotSize = calc.BaseToQuote(rate, lotSize)
+		}
+		if swapLotSize < dustLimit {
+			return nil, newError(orderParamsErr, "single lot swap value %d %s is below the server's dust limit of %d at fee rate %d",
+				swapLotSize, assetConfigs.fromAsset.Symbol, dustLimit, swapFeeSuggestion)
+		}
+	}
+
+	fundingDone := c.startFunding(assetConfigs.fromAsset.ID)
+	var prepared bool
+	defer func() {
+		if !prepared {
+			fundingDone()
+		}
+	}()
+
 	coins, redeemScripts, fundingFees, err := fromWallet.FundOrder(&asset.Order{
 		Version:       assetConfigs.fromAsset.Version,
 		Value:         fundQty,
 		MaxSwapCount:  lots,
 		MaxFeeRate:    assetConfigs.fromAsset.MaxFeeRate,
 		Immediate:     isImmediate,
-		FeeSuggestion: c.feeSuggestion(dc, assetConfigs.fromAsset.ID),
+		FeeSuggestion: swapFeeSuggestion,
 		Options:       form.Options,
 		RedeemVersion: assetConfigs.toAsset.Version,
 		RedeemAssetID: assetConfigs.toAsset.ID,
@@ -6227,6 +7131,8 @@ func (c *Core) prepareTradeRequest(pw []byte, form *TradeForm) (*tradeRequest, e
 	if err != nil {
 		return nil, err
 	}
+	tradeRequest.fundingDone = fundingDone
+	prepared = true
 
 	errCloser.Success()
 
@@ -6247,6 +7153,25 @@ func (c *Core) prepareMultiTradeRequests(pw []byte, form *MultiTradeForm) ([]*tr
 		if trade.Qty == 0 {
 			return nil, newError(orderParamsErr, "zero quantity is invalid")
 		}
+		if err := checkQuantization(mktConf, trade.Qty, trade.Rate, true, form.Sell); err != nil {
+			return nil, err
+		}
+		if !form.OverridePriceBand {
+			err := c.checkPriceBand(dc, form.Base, form.Quote, trade.Rate, assetConfigs.baseAsset.UnitInfo, assetConfigs.quoteAsset.UnitInfo)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The server will refuse standing orders beyond the market's limit. All
+	// of the placements are standing limit orders.
+	if maxOrders := mktConf.MaxOpenOrders; maxOrders > 0 {
+		mktID := marketName(form.Base, form.Quote)
+		if n := dc.standingOrders(mktID); n+len(form.Placements) > int(maxOrders) {
+			return nil, newError(orderParamsErr, "%d standing orders on market %s, the maximum is %d; cannot place %d more",
+				n, mktID, maxOrders, len(form.Placements))
+		}
 	}
 
 	redeemAddresses := make([]string, 0, len(form.Placements))
@@ -6276,6 +7201,9 @@ func (c *Core) prepareMultiTradeRequests(pw []byte, form *MultiTradeForm) ([]*tr
 		})
 	}
 
+	fundingDone := c.startFunding(assetConfigs.fromAsset.ID)
+	defer fundingDone()
+
 	allCoins, allRedeemScripts, fundingFees, err := fromWallet.FundMultiOrder(&asset.MultiOrder{
 		Version:       assetConfigs.fromAsset.Version,
 		Values:        orderValues,
@@ -6341,6 +7269,9 @@ func (c *Core) prepareMultiTradeRequests(pw []byte, form *MultiTradeForm) ([]*tr
 		tradeRequests = append(tradeRequests, req)
 	}
 
+	for _, req := range tradeRequests {
+		req.fundingDone = c.startFunding(assetConfigs.fromAsset.ID)
+	}
 	for _, errCloser := range errClosers {
 		errCloser.Success()
 	}
@@ -6353,6 +7284,7 @@ func (c *Core) prepareMultiTradeRequests(pw []byte, form *MultiTradeForm) ([]*tr
 func (c *Core) sendTradeRequest(tr *tradeRequest) (*Order, error) {
 	dc, dbOrder, wallets, form, route := tr.dc, tr.dbOrder, tr.wallets, tr.form, tr.route
 	mktID, msgOrder, preImg, recoveryCoin, coins := tr.mktID, tr.msgOrder, tr.preImg, tr.recoveryCoin, tr.coins
+	defer tr.fundingDone()
 	defer tr.errCloser.Done(c.log)
 	defer close(tr.commitSig) // signals on both success and failure
 
@@ -6409,6 +7341,8 @@ func (c *Core) sendTradeRequest(tr *tradeRequest) (*Order, error) {
 	dc.trades[tracker.ID()] = tracker
 	dc.tradeMtx.Unlock()
 
+	c.scheduleOrderTTL(dc, tracker)
+
 	// Send a low-priority notification.
 	corder := tracker.coreOrder()
 	if !form.IsLimit && !form.Sell {
@@ -6459,8 +7393,47 @@ func (w *walletSet) conventionalRate(msgRate uint64) float64 {
 }
 
 func (w *walletSet) trimmedConventionalRateString(r uint64) string {
-	s := strconv.FormatFloat(w.conventionalRate(r), 'f', 8, 64)
-	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	return dex.FormatMessageRate(r, w.baseWallet.Info().UnitInfo, w.quoteWallet.Info().UnitInfo)
+}
+
+// assetVersionError describes why our wallets are not compatible with the
+// server's asset versions, indicating whether the client or the server needs an
+// upgrade.
+func assetVersionError(host string, wallets *walletSet, assets *assetSet) error {
+	check := func(w *xcWallet, a *dex.Asset, assetID uint32) error {
+		if a == nil {
+			return newError(assetSupportErr, "%s asset configuration not available from %s", unbip(assetID), host)
+		}
+		if _, compat := w.compatibleVersion(a); compat {
+			return nil
+		}
+		serverVers := a.Versions
+		if len(serverVers) == 0 {
+			serverVers = []uint32{a.Version}
+		}
+		var clientMax uint32
+		for _, v := range w.supportedVersions {
+			if v > clientMax {
+				clientMax = v
+			}
+		}
+		for _, v := range serverVers {
+			if v < clientMax {
+				return newError(assetSupportErr, "%s only supports %s asset versions %v, "+
+					"but this client supports versions %v. The server must be upgraded.",
+					host, unbip(assetID), serverVers, w.supportedVersions)
+			}
+		}
+		return newError(assetSupportErr, "upgrade required: %s requires %s asset versions %v, "+
+			"but this client only supports versions %v", host, unbip(assetID), serverVers, w.supportedVersions)
+	}
+	if err := check(wallets.baseWallet, assets.baseAsset, wallets.baseWallet.AssetID); err != nil {
+		return err
+	}
+	if err := check(wallets.quoteWallet, assets.quoteAsset, wallets.quoteWallet.AssetID); err != nil {
+		return err
+	}
+	return newError(assetSupportErr, "client and server asset versions are incompatible for %v", host)
 }
 
 // walletSet constructs a walletSet and an assetSet for a certain DEX server and
@@ -6485,18 +7458,32 @@ func (c *Core) walletSet(dc *dexConnection, baseID, quoteID uint32, sell bool) (
 	quoteAsset := dc.assets[quoteID]
 	dc.assetsMtx.RUnlock()
 
+	// If the server accepts more than one version of an asset, use a copy of
+	// the asset configuration with the version compatible with our wallet.
+	negotiate := func(w *xcWallet, a *dex.Asset) (*dex.Asset, bool) {
+		ver, compat := w.compatibleVersion(a)
+		if !compat || ver == a.Version {
+			return a, compat
+		}
+		ac := *a
+		ac.Version = ver
+		return &ac, true
+	}
+
 	var versCompat bool
 	if baseAsset == nil {
 		c.log.Warnf("Base asset server configuration not available for %s (asset %s).",
 			dc.acct.host, unbip(baseID))
 	} else {
-		versCompat = baseWallet.supportsVer(baseAsset.Version)
+		baseAsset, versCompat = negotiate(baseWallet, baseAsset)
 	}
 	if quoteAsset == nil {
 		c.log.Warnf("Quote asset server configuration not available for %s (asset %s).",
 			dc.acct.host, unbip(quoteID))
 	} else {
-		versCompat = versCompat && quoteWallet.supportsVer(quoteAsset.Version)
+		var quoteCompat bool
+		quoteAsset, quoteCompat = negotiate(quoteWallet, quoteAsset)
+		versCompat = versCompat && quoteCompat
 	}
 
 	// We actually care less about base/quote, and more about from/to, which
@@ -6543,6 +7530,76 @@ func (c *Core) cancelOrder(oid order.OrderID) error {
 	return fmt.Errorf("Cancel: failed to find order %s", oid)
 }
 
+// orderTTLRetry is how long to wait before trying again to cancel an order
+// after its TTL, if the cancel order could not be submitted, e.g. because the
+// DEX is disconnected.
+var orderTTLRetry = time.Minute
+
+// scheduleOrderTTL schedules the cancellation of a standing limit order at its
+// CancelAfter time, if it has one. If the time has passed, the cancel is
+// attempted immediately.
+func (c *Core) scheduleOrderTTL(dc *dexConnection, tracker *trackedTrade) {
+	tracker.mtx.RLock()
+	cancelAfter := tracker.metaData.CancelAfter
+	status := tracker.metaData.Status
+	tracker.mtx.RUnlock()
+	if cancelAfter == 0 || status > order.OrderStatusBooked {
+		return
+	}
+	c.scheduleTTLCancel(dc, tracker, time.Until(time.UnixMilli(int64(cancelAfter))))
+}
+
+func (c *Core) scheduleTTLCancel(dc *dexConnection, tracker *trackedTrade, delay time.Duration) {
+	oid := tracker.ID()
+	c.ttlTimersMtx.Lock()
+	defer c.ttlTimersMtx.Unlock()
+	if _, found := c.ttlTimers[oid]; found {
+		return
+	}
+	if c.ttlTimers == nil {
+		c.ttlTimers = make(map[order.OrderID]*time.Timer)
+	}
+	c.ttlTimers[oid] = time.AfterFunc(delay, func() {
+		c.ttlTimersMtx.Lock()
+		delete(c.ttlTimers, oid)
+		c.ttlTimersMtx.Unlock()
+		if c.ctx.Err() != nil {
+			return
+		}
+		if retry := c.cancelExpiredOrder(dc, tracker); retry {
+			c.scheduleTTLCancel(dc, tracker, orderTTLRetry)
+		}
+	})
+}
+
+// cancelExpiredOrder cancels a standing limit order whose TTL has passed, if
+// it is still open. The order may be matched, or canceled by the user, just as
+// the TTL passes, so an order that is no longer open when the cancel is
+// attempted is not an error. The returned bool is true if the order is still
+// open and the cancel should be tried again later.
+func (c *Core) cancelExpiredOrder(dc *dexConnection, tracker *trackedTrade) (retry bool) {
+	stillOpen := func() bool {
+		tracker.mtx.RLock()
+		defer tracker.mtx.RUnlock()
+		status := tracker.metaData.Status
+		return (status == order.OrderStatusEpoch || status == order.OrderStatusBooked) && tracker.cancel == nil
+	}
+	if !stillOpen() {
+		return false
+	}
+	oid := tracker.ID()
+	if err := c.tryCancelTrade(dc, tracker); err != nil {
+		if !stillOpen() { // filled or canceled in the meantime
+			c.log.Debugf("Order %s closed as its TTL passed: %v", oid, err)
+			return false
+		}
+		c.log.Errorf("Error canceling order %s after its TTL: %v", oid, err)
+		return true
+	}
+	c.log.Infof("Canceled order %s after its TTL", oid)
+	return false
+}
+
 func assetBond(bond *db.Bond) *asset.Bond {
 	return &asset.Bond{
 		Version:    bond.Version,
@@ -7429,6 +8486,8 @@ func (c *Core) loadDBTrades(dc *dexConnection) error {
 		dc.trades[tracker.ID()] = tracker
 		dc.tradeMtx.Unlock()
 
+		c.scheduleOrderTTL(dc, tracker)
+
 		mktConf := dc.marketConfig(tracker.mktID)
 		if tracker.metaData.EpochDur == 0 { // upgraded with live orders... smart :/
 			if mktConf != nil { // may remain zero if market also vanished
@@ -7905,12 +8964,64 @@ func (c *Core) reReserveFunding(w *xcWallet) {
 	}
 }
 
+// checkRegisteredSymbols checks that the display symbols and aliases
+// configured by a server do not match, ignoring case, the symbol or ticker of
+// a different asset supported by the client. The server only knows about the
+// assets it lists, so this prevents it from displaying an asset as one that
+// the user knows by that name.
+func checkRegisteredSymbols(assets []*dex.Asset) error {
+	owners := make(map[string]map[uint32]bool)
+	addName := func(name string, assetID uint32) {
+		if name == "" {
+			return
+		}
+		name = strings.ToLower(name)
+		if owners[name] == nil {
+			owners[name] = make(map[uint32]bool, 1)
+		}
+		owners[name][assetID] = true
+	}
+	for assetID, ra := range asset.Assets() {
+		addName(ra.Symbol, assetID)
+		addName(ra.Info.UnitInfo.Conventional.Unit, assetID)
+		for tokenID, token := range ra.Tokens {
+			addName(dex.BipIDSymbol(tokenID), tokenID)
+			addName(token.UnitInfo.Conventional.Unit, tokenID)
+		}
+	}
+	for _, a := range assets {
+		names := a.Aliases
+		if a.DisplaySymbol != "" {
+			names = append([]string{a.DisplaySymbol}, names...)
+		}
+		for _, name := range names {
+			for assetID := range owners[strings.ToLower(name)] {
+				if assetID != a.ID {
+					return fmt.Errorf("display symbol or alias %q of %s is the symbol or ticker of %s",
+						name, a.Symbol, unbip(assetID))
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // generateDEXMaps creates the associated assets, market and epoch maps of the
 // DEXs from the provided configuration.
 func generateDEXMaps(host string, cfg *msgjson.ConfigResult) (map[uint32]*dex.Asset, map[string]uint64, error) {
 	assets := make(map[uint32]*dex.Asset, len(cfg.Assets))
+	assetList := make([]*dex.Asset, 0, len(cfg.Assets))
 	for _, asset := range cfg.Assets {
-		assets[asset.ID] = convertAssetInfo(asset)
+		a := convertAssetInfo(asset)
+		assets[asset.ID] = a
+		assetList = append(assetList, a)
+	}
+	// Ambiguous display symbols could be used to misrepresent an asset.
+	if err := dex.ValidateDisplaySymbols(assetList); err != nil {
+		return nil, nil, fmt.Errorf("%s reported ambiguous asset display symbols: %w", host, err)
+	}
+	if err := checkRegisteredSymbols(assetList); err != nil {
+		return nil, nil, fmt.Errorf("%s reported ambiguous asset display symbols: %w", host, err)
 	}
 	// Validate the markets so we don't have to check every time later.
 	for _, mkt := range cfg.Markets {
@@ -8068,7 +9179,8 @@ func (c *Core) newDEXConnection(acctInfo *db.AccountInfo, flag connectDEXFlag) (
 	if err != nil {
 		return nil, newError(addressParseErr, "error parsing address: %v", err)
 	}
-	wsURL, err := url.Parse("wss://" + host + "/ws")
+	netAddr, _ := splitHostIdentity(host)
+	wsURL, err := url.Parse("wss://" + netAddr + "/ws")
 	if err != nil {
 		return nil, newError(addressParseErr, "error parsing ws address from host %s: %w", host, err)
 	}
@@ -8101,6 +9213,10 @@ func (c *Core) newDEXConnection(acctInfo *db.AccountInfo, flag connectDEXFlag) (
 		PingWait: 20 * time.Second, // larger than server's pingPeriod (server/comms/server.go)
 		Cert:     acctInfo.Cert,
 		Logger:   c.log.SubLogger(wsURL.String()),
+
+		ReconnectInterval:    c.cfg.ReconnectInterval,
+		MaxReconnectInterval: c.cfg.MaxReconnectInterval,
+		MaxMessageSize:       c.cfg.MaxMessageSize,
 	}
 
 	isOnionHost := isOnionHost(wsURL.Host)
@@ -8193,6 +9309,8 @@ func (c *Core) startDexConnection(acctInfo *db.AccountInfo, dc *dexConnection) e
 			// requires the account keys.
 		}
 
+		dc.acct.restoreBondReplacements()
+
 		// Now in authDEX, we must reconcile the above categorized bonds
 		// according to ConnectResult.Bonds slice.
 	}
@@ -8222,6 +9340,8 @@ func (c *Core) startDexConnection(acctInfo *db.AccountInfo, dc *dexConnection) e
 	// Given bond config, sort through our db.Bond slice.
 	categorizeBonds(time.Now().Unix() + int64(cfg.BondExpiry))
 
+	dc.negotiateFeatures()
+
 	if listen {
 		c.log.Infof("Connected to DEX server at %s and listening for messages.", dc.acct.host)
 		go dc.subPriceFeed()
@@ -8254,6 +9374,8 @@ func (c *Core) handleReconnect(host string) {
 		return
 	}
 
+	dc.negotiateFeatures()
+
 	type market struct { // for book re-subscribe
 		name  string
 		base  uint32
@@ -8570,6 +9692,54 @@ func handleNotifyMsg(c *Core, dc *dexConnection, msg *msgjson.Message) error {
 	return nil
 }
 
+// handleAnnouncementMsg is called when an operator announcement is received.
+// The server sends active announcements on connect, so announcements that
+// were already shown are ignored.
+func handleAnnouncementMsg(c *Core, dc *dexConnection, msg *msgjson.Message) error {
+	var ann msgjson.Announcement
+	err := msg.Unmarshal(&ann)
+	if err != nil {
+		return fmt.Errorf("announcement unmarshal error: %w", err)
+	}
+	now := uint64(time.Now().UnixMilli())
+	if ann.Expiry <= now {
+		return nil
+	}
+
+	dc.announcementsMtx.Lock()
+	if dc.announcements == nil {
+		dc.announcements = make(map[uint64]uint64)
+	}
+	for id, expiry := range dc.announcements {
+		if expiry <= now {
+			delete(dc.announcements, id)
+		}
+	}
+	_, seen := dc.announcements[ann.ID]
+	dc.announcements[ann.ID] = ann.Expiry
+	dc.announcementsMtx.Unlock()
+	if seen {
+		return nil
+	}
+
+	var severity db.Severity
+	switch ann.Severity {
+	case msgjson.AnnouncementCritical:
+		severity = db.ErrorLevel
+	case msgjson.AnnouncementWarning:
+		severity = db.WarningLevel
+	default:
+		severity = db.Success
+	}
+	txt := ann.Message
+	if ann.Link != "" {
+		txt += " (" + ann.Link + ")"
+	}
+	subject, details := c.formatDetails(TopicDEXAnnouncement, dc.acct.host, txt)
+	c.notify(newServerNotifyNote(TopicDEXAnnouncement, subject, details, severity))
+	return nil
+}
+
 // handlePenaltyMsg is called when a Penalty notification is received.
 //
 // TODO: Consider other steps needed to take immediately after being banned.
@@ -8684,6 +9854,7 @@ var reqHandlers = map[string]routeHandler{
 var noteHandlers = map[string]routeHandler{
 	msgjson.MatchProofRoute:      handleMatchProofMsg,
 	msgjson.BookOrderRoute:       handleBookOrderMsg,
+	msgjson.OrderBookPageRoute:   handleOrderBookPageMsg,
 	msgjson.EpochOrderRoute:      handleEpochOrderMsg,
 	msgjson.UnbookOrderRoute:     handleUnbookOrderMsg,
 	msgjson.PriceUpdateRoute:     handlePriceUpdateNote,
@@ -8691,8 +9862,10 @@ var noteHandlers = map[string]routeHandler{
 	msgjson.EpochReportRoute:     handleEpochReportMsg,
 	msgjson.SuspensionRoute:      handleTradeSuspensionMsg,
 	msgjson.ResumptionRoute:      handleTradeResumptionMsg,
+	msgjson.MarketConfigRoute:    handleMarketConfigMsg,
 	msgjson.NotifyRoute:          handleNotifyMsg,
 	msgjson.PenaltyRoute:         handlePenaltyMsg,
+	msgjson.AnnouncementRoute:    handleAnnouncementMsg,
 	msgjson.NoMatchRoute:         handleNoMatchRoute,
 	msgjson.RevokeOrderRoute:     handleRevokeOrderMsg,
 	msgjson.RevokeMatchRoute:     handleRevokeMatchMsg,
@@ -9406,6 +10579,8 @@ func (c *Core) handleWalletNotification(ni asset.WalletNotification) {
 		c.requestedActionMtx.Unlock()
 	case *asset.ActionResolvedNote:
 		c.deleteRequestedAction(n.UniqueID)
+	case *asset.TransactionNote:
+		c.handleDepositNote(n)
 	}
 	c.notify(newWalletNote(ni))
 }
@@ -9451,6 +10626,19 @@ func (c *Core) tipChange(assetID uint32) {
 	c.updateBalances(assets)
 }
 
+// dustLimitAt is the asset's dust limit at the given fee rate. The server's
+// DustLimit is for the asset's MaxFeeRate, and dust limits are proportional to
+// the fee rate.
+func dustLimitAt(a *dex.Asset, feeRate uint64) uint64 {
+	if a.DustLimit == 0 || a.MaxFeeRate == 0 {
+		return a.DustLimit
+	}
+	if feeRate > a.MaxFeeRate {
+		feeRate = a.MaxFeeRate
+	}
+	return a.DustLimit / a.MaxFeeRate * feeRate
+}
+
 // convertAssetInfo converts from a *msgjson.Asset to the nearly identical
 // *dex.Asset.
 func convertAssetInfo(ai *msgjson.Asset) *dex.Asset {
@@ -9461,6 +10649,11 @@ func convertAssetInfo(ai *msgjson.Asset) *dex.Asset {
 		MaxFeeRate: ai.MaxFeeRate,
 		SwapConf:   uint32(ai.SwapConf),
 		UnitInfo:   ai.UnitInfo,
+		DustLimit:  ai.DustLimit,
+		Versions:   ai.Versions,
+
+		DisplaySymbol: ai.DisplaySymbol,
+		Aliases:       ai.Aliases,
 	}
 }
 
@@ -9650,6 +10843,7 @@ func validateOrderResponse(dc *dexConnection, result *msgjson.OrderResult, ord o
 // if certI is already a []byte, it is presumed to be the raw file contents, and
 // is returned unmodified.
 func parseCert(host string, certI any, net dex.Network) ([]byte, error) {
+	host, _ = splitHostIdentity(host) // identities of a host share the cert
 	switch c := certI.(type) {
 	case string:
 		if len(c) == 0 {
@@ -9703,6 +10897,9 @@ func (c *Core) WalletRestorationInfo(pw []byte, assetID uint32) ([]*asset.Wallet
 	if !found {
 		return nil, fmt.Errorf("no wallet configured for asset %d", assetID)
 	}
+	if wallet.importedSeed {
+		return nil, fmt.Errorf("wallet for asset %d was created from an imported seed", assetID)
+	}
 
 	restorer, ok := wallet.Wallet.(asset.WalletRestorer)
 	if !ok {
@@ -9717,6 +10914,62 @@ func (c *Core) WalletRestorationInfo(pw []byte, assetID uint32) ([]*asset.Wallet
 	return restorationInfo, nil
 }
 
+// VerifyWalletDerivation re-derives the first addresses of the asset's wallet
+// from the app seed and compares them against the addresses reported by the
+// wallet. A mismatch indicates that the wallet was not restored from the app
+// seed, or that it uses a different derivation path.
+func (c *Core) VerifyWalletDerivation(pw []byte, assetID uint32) (*WalletDerivation, error) {
+	wallet, found := c.wallet(assetID)
+	if !found {
+		return nil, fmt.Errorf("no wallet configured for asset %d", assetID)
+	}
+	if wallet.importedSeed {
+		return nil, fmt.Errorf("wallet for asset %d was created from an imported seed", assetID)
+	}
+	verifier, ok := wallet.Wallet.(asset.DerivationVerifier)
+	if !ok {
+		return nil, fmt.Errorf("wallet for asset %d doesn't support derivation verification", assetID)
+	}
+
+	crypter, err := c.encryptionKey(pw)
+	if err != nil {
+		return nil, fmt.Errorf("VerifyWalletDerivation password error: %w", err)
+	}
+	defer crypter.Close()
+
+	seed, _, err := c.assetSeedAndPass(assetID, crypter)
+	if err != nil {
+		return nil, fmt.Errorf("assetSeedAndPass error: %w", err)
+	}
+	defer encode.ClearBytes(seed)
+
+	expected, err := verifier.SeedAddresses(seed, derivationCheckAddrs)
+	if err != nil {
+		return nil, fmt.Errorf("error deriving %s addresses from seed: %w", unbip(assetID), err)
+	}
+	if len(expected) == 0 {
+		return nil, fmt.Errorf("no %s addresses derived from seed", unbip(assetID))
+	}
+	reported, err := verifier.WalletAddresses(len(expected))
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s wallet addresses: %w", unbip(assetID), err)
+	}
+
+	match := len(reported) == len(expected)
+	for i := 0; match && i < len(expected); i++ {
+		match = expected[i] == reported[i]
+	}
+	if !match {
+		c.log.Warnf("%s wallet addresses do not match those derived from the app seed", unbip(assetID))
+	}
+	return &WalletDerivation{
+		AssetID:  assetID,
+		Match:    match,
+		Expected: expected,
+		Reported: reported,
+	}, nil
+}
+
 // createFile creates a new file and will create the file directory if it does
 // not exist.
 func createFile(fileName string) (*os.File, error) {
@@ -10334,22 +11587,31 @@ func (c *Core) fiatConversions() map[uint32]float64 {
 
 	fiatRatesMap := make(map[uint32]float64, len(supportedAssets))
 	for assetID := range assetIDs {
-		var rateSum float64
-		var sources int
-		for _, source := range c.fiatSources() {
-			rateInfo := source.assetRate(assetID)
-			if rateInfo != nil && time.Since(rateInfo.lastUpdate) < fiatRateDataExpiry && rateInfo.rate > 0 {
-				sources++
-				rateSum += rateInfo.rate
-			}
-		}
-		if rateSum != 0 {
-			fiatRatesMap[assetID] = rateSum / float64(sources) // get average rate.
+		if rate := c.fiatRate(assetID); rate != 0 {
+			fiatRatesMap[assetID] = rate
 		}
 	}
 	return fiatRatesMap
 }
 
+// fiatRate is the average of the unexpired cached fiat rates for the asset, or
+// zero if there are none.
+func (c *Core) fiatRate(assetID uint32) float64 {
+	var rateSum float64
+	var sources int
+	for _, source := range c.fiatSources() {
+		rateInfo := source.assetRate(assetID)
+		if rateInfo != nil && time.Since(rateInfo.lastUpdate) < fiatRateDataExpiry && rateInfo.rate > 0 {
+			sources++
+			rateSum += rateInfo.rate
+		}
+	}
+	if rateSum == 0 {
+		return 0
+	}
+	return rateSum / float64(sources) // get average rate.
+}
+
 // ToggleRateSourceStatus toggles a fiat rate source status. If disable is true,
 // the fiat rate source is disabled, otherwise the rate source is enabled.
 func (c *Core) ToggleRateSourceStatus(source string, disable bool) error {
@@ -8,10 +8,13 @@ import (
 	crand "crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
 	"sort"
@@ -51,12 +54,16 @@ func init() {
 		tDriver: &tDriver{
 			decodedCoinID: tUTXOAssetB.Symbol + "-coin",
 			winfo:         tWalletInfo,
+			explorerTxURL: "https://utxo.explorer/tx/" + asset.ExplorerTxID,
+			explorerOut:   "https://utxo.explorer/tx/" + asset.ExplorerTxID + "/out/" + asset.ExplorerVout,
 		},
 	})
 	asset.Register(tACCTAsset.ID, &tCreator{
 		tDriver: &tDriver{
 			decodedCoinID: tACCTAsset.Symbol + "-coin",
 			winfo:         tWalletInfo,
+			explorerTxURL: "https://acct.explorer/tx/" + asset.ExplorerTxID,
+			explorerAddr:  "https://acct.explorer/address/" + asset.ExplorerAddr,
 		},
 	})
 	rand.Seed(time.Now().UnixNano())
@@ -383,6 +390,18 @@ type TDB struct {
 	deleteInactiveMatchesErr error
 	archivedMatches          int
 	updateAccountInfoErr     error
+	orderTemplates           map[string]*db.OrderTemplate
+	orderSchedules           map[string]*db.OrderSchedule
+	icebergOrders            map[string]*db.IcebergOrder
+	balanceAlerts            map[uint32]uint64
+	autoRefund               bool
+	setBalanceAlertErr       error
+	depositAddrsMtx          sync.Mutex
+	depositAddrs             map[uint32]*db.DepositAddressRecord
+
+	// walletsByID stores wallets by ID if non-nil, for tests with multiple
+	// wallets.
+	walletsByID map[string]*db.Wallet
 }
 
 func (tdb *TDB) Run(context.Context) {}
@@ -499,6 +518,9 @@ func (tdb *TDB) DEXOrdersWithActiveMatches(dex string) ([]order.OrderID, error)
 
 func (tdb *TDB) UpdateWallet(wallet *db.Wallet) error {
 	tdb.wallet = wallet
+	if tdb.walletsByID != nil {
+		tdb.walletsByID[string(wallet.ID())] = wallet
+	}
 	return tdb.updateWalletErr
 }
 
@@ -518,7 +540,14 @@ func (tdb *TDB) Wallets() ([]*db.Wallet, error) {
 	return nil, nil
 }
 
-func (tdb *TDB) Wallet([]byte) (*db.Wallet, error) {
+func (tdb *TDB) Wallet(wid []byte) (*db.Wallet, error) {
+	if tdb.walletsByID != nil {
+		w, found := tdb.walletsByID[string(wid)]
+		if !found {
+			return nil, fmt.Errorf("wallet not found")
+		}
+		return w, tdb.walletErr
+	}
 	return tdb.wallet, tdb.walletErr
 }
 
@@ -582,6 +611,149 @@ func (tdb *TDB) Language() (string, error) {
 	return "en-US", nil
 }
 
+func (tdb *TDB) SetAutoRefund(enable bool) error {
+	tdb.autoRefund = enable
+	return nil
+}
+
+func (tdb *TDB) AutoRefund() (bool, error) {
+	return tdb.autoRefund, nil
+}
+
+func (tdb *TDB) SaveOrderTemplate(tmpl *db.OrderTemplate) error {
+	if tdb.orderTemplates == nil {
+		tdb.orderTemplates = make(map[string]*db.OrderTemplate)
+	}
+	tdb.orderTemplates[tmpl.Name] = tmpl
+	return nil
+}
+
+func (tdb *TDB) OrderTemplates() ([]*db.OrderTemplate, error) {
+	tmpls := make([]*db.OrderTemplate, 0, len(tdb.orderTemplates))
+	for _, tmpl := range tdb.orderTemplates {
+		tmpls = append(tmpls, tmpl)
+	}
+	sort.Slice(tmpls, func(i, j int) bool { return tmpls[i].Name < tmpls[j].Name })
+	return tmpls, nil
+}
+
+func (tdb *TDB) SaveOrderSchedule(sched *db.OrderSchedule) error {
+	if tdb.orderSchedules == nil {
+		tdb.orderSchedules = make(map[string]*db.OrderSchedule)
+	}
+	s := *sched
+	tdb.orderSchedules[sched.ID] = &s
+	return nil
+}
+
+func (tdb *TDB) OrderSchedules() ([]*db.OrderSchedule, error) {
+	scheds := make([]*db.OrderSchedule, 0, len(tdb.orderSchedules))
+	for _, sched := range tdb.orderSchedules {
+		s := *sched
+		scheds = append(scheds, &s)
+	}
+	sort.Slice(scheds, func(i, j int) bool { return scheds[i].ID < scheds[j].ID })
+	return scheds, nil
+}
+
+func (tdb *TDB) DeleteOrderSchedule(id string) error {
+	if _, found := tdb.orderSchedules[id]; !found {
+		return db.ErrNoSchedule
+	}
+	delete(tdb.orderSchedules, id)
+	return nil
+}
+
+func (tdb *TDB) SaveIcebergOrder(ice *db.IcebergOrder) error {
+	if tdb.icebergOrders == nil {
+		tdb.icebergOrders = make(map[string]*db.IcebergOrder)
+	}
+	i := *ice
+	tdb.icebergOrders[ice.ID] = &i
+	return nil
+}
+
+func (tdb *TDB) IcebergOrders() ([]*db.IcebergOrder, error) {
+	ices := make([]*db.IcebergOrder, 0, len(tdb.icebergOrders))
+	for _, ice := range tdb.icebergOrders {
+		i := *ice
+		ices = append(ices, &i)
+	}
+	sort.Slice(ices, func(i, j int) bool { return ices[i].ID < ices[j].ID })
+	return ices, nil
+}
+
+func (tdb *TDB) DeleteIcebergOrder(id string) error {
+	if _, found := tdb.icebergOrders[id]; !found {
+		return db.ErrNoIceberg
+	}
+	delete(tdb.icebergOrders, id)
+	return nil
+}
+
+func (tdb *TDB) SetBalanceAlert(assetID uint32, threshold uint64) error {
+	if tdb.setBalanceAlertErr != nil {
+		return tdb.setBalanceAlertErr
+	}
+	if tdb.balanceAlerts == nil {
+		tdb.balanceAlerts = make(map[uint32]uint64)
+	}
+	if threshold == 0 {
+		delete(tdb.balanceAlerts, assetID)
+	} else {
+		tdb.balanceAlerts[assetID] = threshold
+	}
+	return nil
+}
+
+func (tdb *TDB) BalanceAlerts() (map[uint32]uint64, error) {
+	return tdb.balanceAlerts, nil
+}
+
+func (tdb *TDB) SaveDepositAddressRecord(rec *db.DepositAddressRecord) error {
+	b, _ := json.Marshal(rec) // deep copy
+	var r db.DepositAddressRecord
+	json.Unmarshal(b, &r)
+	tdb.depositAddrsMtx.Lock()
+	defer tdb.depositAddrsMtx.Unlock()
+	if tdb.depositAddrs == nil {
+		tdb.depositAddrs = make(map[uint32]*db.DepositAddressRecord)
+	}
+	tdb.depositAddrs[rec.AssetID] = &r
+	return nil
+}
+
+func (tdb *TDB) DepositAddressRecords() ([]*db.DepositAddressRecord, error) {
+	tdb.depositAddrsMtx.Lock()
+	defer tdb.depositAddrsMtx.Unlock()
+	recs := make([]*db.DepositAddressRecord, 0, len(tdb.depositAddrs))
+	for _, rec := range tdb.depositAddrs {
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+func (tdb *TDB) DeleteDepositAddressRecord(assetID uint32) error {
+	tdb.depositAddrsMtx.Lock()
+	defer tdb.depositAddrsMtx.Unlock()
+	delete(tdb.depositAddrs, assetID)
+	return nil
+}
+
+func (tdb *TDB) depositAddressRecord(assetID uint32) *db.DepositAddressRecord {
+	tdb.depositAddrsMtx.Lock()
+	defer tdb.depositAddrsMtx.Unlock()
+	return tdb.depositAddrs[assetID]
+}
+
+func (tdb *TDB) DeleteOrderTemplate(name string) error {
+	if _, found := tdb.orderTemplates[name]; !found {
+		return db.ErrNoTemplate
+	}
+	delete(tdb.orderTemplates, name)
+	return nil
+}
+
 type tCoin struct {
 	id []byte
 
@@ -593,7 +765,7 @@ func (c *tCoin) ID() dex.Bytes {
 }
 
 func (c *tCoin) TxID() string {
-	return ""
+	return hex.EncodeToString(c.id)
 }
 
 func (c *tCoin) String() string {
@@ -634,20 +806,24 @@ type TXCWallet struct {
 	swapSize            uint64
 	sendFeeSuggestion   uint64
 	sendCoin            *tCoin
+	withdrawValue       uint64
 	sendErr             error
 	addrErr             error
+	newAddrCount        atomic.Uint32
 	signCoinErr         error
 	lastSwaps           []*asset.Swaps
 	lastRedeems         []*asset.RedeemForm
 	swapReceipts        []asset.Receipt
 	swapCounter         int
 	swapErr             error
+	simulatedSwap       *asset.SimulatedSwap
 	auditInfo           *asset.AuditInfo
 	auditErr            error
 	auditChan           chan struct{}
 	refundCoin          dex.Bytes
 	refundErr           error
 	refundFeeSuggestion uint64
+	swapSpent           bool
 	redeemCoins         []dex.Bytes
 	redeemCounter       int
 	redeemFeeSuggestion uint64
@@ -656,6 +832,7 @@ type TXCWallet struct {
 	badSecret           bool
 	fundedVal           uint64
 	fundedSwaps         uint64
+	fundedDryRun        bool
 	connectErr          error
 	unlockErr           error
 	balErr              error
@@ -796,6 +973,7 @@ func (w *TXCWallet) ConfirmRedemption(coinID dex.Bytes, redemption *asset.Redemp
 func (w *TXCWallet) FundOrder(ord *asset.Order) (asset.Coins, []dex.Bytes, uint64, error) {
 	w.fundedVal = ord.Value
 	w.fundedSwaps = ord.MaxSwapCount
+	w.fundedDryRun = ord.DryRun
 	return w.fundingCoins, w.fundRedeemScripts, 0, w.fundingCoinErr
 }
 
@@ -849,6 +1027,14 @@ func (w *TXCWallet) Swap(swaps *asset.Swaps) ([]asset.Receipt, asset.Coin, uint6
 	return w.swapReceipts, w.changeCoin, tSwapFeesPaid, nil
 }
 
+func (w *TXCWallet) SimulateSwap(swaps *asset.Swaps) (*asset.SimulatedSwap, error) {
+	w.lastSwaps = append(w.lastSwaps, swaps)
+	if w.swapErr != nil {
+		return nil, w.swapErr
+	}
+	return w.simulatedSwap, nil
+}
+
 func (w *TXCWallet) Redeem(form *asset.RedeemForm) ([]dex.Bytes, asset.Coin, uint64, error) {
 	w.redeemFeeSuggestion = form.FeeSuggestion
 	defer func() {
@@ -903,7 +1089,10 @@ func (w *TXCWallet) RedemptionAddress() (string, error) {
 }
 
 func (w *TXCWallet) NewAddress() (string, error) {
-	return "", w.addrErr
+	if w.addrErr != nil {
+		return "", w.addrErr
+	}
+	return fmt.Sprintf("newaddr%d", w.newAddrCount.Add(1)), nil
 }
 
 func (w *TXCWallet) Unlock(pw []byte) error {
@@ -934,6 +1123,8 @@ func (w *TXCWallet) SendTransaction(rawTx []byte) ([]byte, error) {
 
 func (w *TXCWallet) Withdraw(address string, value, feeSuggestion uint64) (asset.Coin, error) {
 	w.sendFeeSuggestion = feeSuggestion
+	w.sendCoin.val = value - w.estFee
+	w.withdrawValue = value
 	return w.sendCoin, w.sendErr
 }
 
@@ -955,7 +1146,7 @@ func (w *TXCWallet) SyncStatus() (*asset.SyncStatus, error) {
 		return nil, err
 	}
 	blocks := uint64(math.Round(float64(progress) * 100))
-	return &asset.SyncStatus{Synced: synced, TargetHeight: blocks, Blocks: blocks}, nil
+	return &asset.SyncStatus{Synced: synced, TargetHeight: 100, Blocks: blocks}, nil
 }
 
 func (w *TXCWallet) setConfs(coinID dex.Bytes, confs uint32, err error) {
@@ -975,7 +1166,7 @@ func (w *TXCWallet) tConfirmations(_ context.Context, coinID dex.Bytes) (uint32,
 
 func (w *TXCWallet) SwapConfirmations(ctx context.Context, coinID dex.Bytes, contract dex.Bytes, matchTime time.Time) (uint32, bool, error) {
 	confs, err := w.tConfirmations(ctx, coinID)
-	return confs, false, err
+	return confs, w.swapSpent, err
 }
 
 func (w *TXCWallet) RegFeeConfirmations(ctx context.Context, coinID dex.Bytes) (uint32, error) {
@@ -1339,6 +1530,8 @@ func newTestRig() *testRig {
 			notes:            make(chan asset.WalletNotification, 128),
 			pokesCache:       newPokesCache(pokesCapacity),
 			requestedActions: make(map[string]*asset.ActionRequiredNote),
+			depositRotators:  make(map[uint32]*depositAddressRotator),
+			balanceAlerts:    make(map[uint32]*balanceAlert),
 		},
 		db:      tdb,
 		queue:   queue,
@@ -1348,6 +1541,7 @@ func newTestRig() *testRig {
 		crypter: crypter,
 	}
 
+	rig.core.oracle = &fiatPriceOracle{rig.core}
 	rig.core.intl.Store(&locale{
 		m:       originLocale,
 		printer: message.NewPrinter(language.AmericanEnglish),
@@ -1553,9587 +1747,14737 @@ func TestMarkets(t *testing.T) {
 	}
 }
 
-func TestBookFeed(t *testing.T) {
+func TestMarketDepth(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
 	tCore := rig.core
-	dc := rig.dc
 
-	checkAction := func(feed BookFeed, action string) {
+	// No synced book.
+	if _, err := tCore.MarketDepth(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID); err == nil {
+		t.Fatalf("no error for unsynced book")
+	}
+
+	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	err := book.Sync(&msgjson.OrderBook{
+		Seq:      4,
+		MarketID: tDcrBtcMktName,
+		Orders: []*msgjson.BookOrderNote{
+			tBookOrderNote(1, false, 5, 100),
+			tBookOrderNote(2, false, 3, 95),
+			tBookOrderNote(3, false, 2, 89),
+			tBookOrderNote(4, true, 4, 101),
+			tBookOrderNote(5, true, 6, 110),
+			tBookOrderNote(6, true, 1, 121),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Sync error: %v", err)
+	}
+	rig.dc.books[tDcrBtcMktName] = book
+
+	depth, err := tCore.MarketDepth(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if err != nil {
+		t.Fatalf("MarketDepth error: %v", err)
+	}
+	// Buckets are the market's rate step of 10.
+	if depth.BucketSize != dcrBtcRateStep {
+		t.Fatalf("wrong bucket size %d", depth.BucketSize)
+	}
+	if depth.BestBid != 100 || depth.BestAsk != 101 || depth.Spread != 1 {
+		t.Fatalf("wrong best bid, best ask, or spread: %d, %d, %d", depth.BestBid, depth.BestAsk, depth.Spread)
+	}
+	checkSide := func(side string, buckets []*DepthBucket, exp [][3]uint64) {
 		t.Helper()
-		select {
-		case u := <-feed.Next():
-			if u.Action != action {
-				t.Fatalf("expected action = %s, got %s", action, u.Action)
+		if len(buckets) != len(exp) {
+			t.Fatalf("expected %d %s buckets, got %d", len(exp), side, len(buckets))
+		}
+		for i, b := range buckets {
+			if b.Rate != exp[i][0] || b.Qty != exp[i][1] || b.CumulativeQty != exp[i][2] {
+				t.Fatalf("wrong %s bucket %d. expected %v, got %+v", side, i, exp[i], b)
 			}
-		default:
-			t.Fatalf("no %s received", action)
 		}
 	}
+	checkSide("bid", depth.Bids, [][3]uint64{{100, 5, 5}, {90, 3, 8}, {80, 2, 10}})
+	checkSide("ask", depth.Asks, [][3]uint64{{110, 10, 10}, {130, 1, 11}})
+}
 
-	// Ensure handleOrderBookMsg creates an order book as expected.
-	oid1 := ordertest.RandomOrderID()
-	bookMsg, err := msgjson.NewResponse(1, &msgjson.OrderBook{
-		Seq:      1,
+func TestMarketDepthAt(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	// No synced book.
+	if _, err := tCore.MarketDepthAt(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, 10); err == nil {
+		t.Fatalf("no error for unsynced book")
+	}
+
+	// Rates are multiples of the rate step of 10.
+	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	err := book.Sync(&msgjson.OrderBook{
+		Seq:      4,
 		MarketID: tDcrBtcMktName,
 		Orders: []*msgjson.BookOrderNote{
-			{
-				TradeNote: msgjson.TradeNote{
-					Side:     msgjson.BuyOrderNum,
-					Quantity: 10,
-					Rate:     2,
-				},
-				OrderNote: msgjson.OrderNote{
-					Seq:      1,
-					MarketID: tDcrBtcMktName,
-					OrderID:  oid1[:],
-				},
-			},
+			tBookOrderNote(1, false, 5, 1000),
+			tBookOrderNote(2, false, 3, 990),
+			tBookOrderNote(3, false, 2, 950),
+			tBookOrderNote(4, false, 7, 890),
+			tBookOrderNote(5, true, 4, 1010),
+			tBookOrderNote(6, true, 6, 1040),
+			tBookOrderNote(7, true, 1, 1100),
+			tBookOrderNote(8, true, 8, 1210),
 		},
-	}, nil)
+	})
 	if err != nil {
-		t.Fatalf("[NewResponse]: unexpected err: %v", err)
+		t.Fatalf("Sync error: %v", err)
 	}
+	rig.dc.books[tDcrBtcMktName] = book
 
-	oid2 := ordertest.RandomOrderID()
-	bookNote, _ := msgjson.NewNotification(msgjson.BookOrderRoute, &msgjson.BookOrderNote{
-		TradeNote: msgjson.TradeNote{
-			Side:     msgjson.BuyOrderNum,
-			Quantity: 10,
-			Rate:     2,
+	tests := []struct {
+		name       string
+		bucketSize uint64
+		expSize    uint64
+		bids, asks [][3]uint64
+	}{
+		{
+			name:       "zero is one rate step",
+			bucketSize: 0,
+			expSize:    10,
+			bids:       [][3]uint64{{1000, 5, 5}, {990, 3, 8}, {950, 2, 10}, {890, 7, 17}},
+			asks:       [][3]uint64{{1010, 4, 4}, {1040, 6, 10}, {1100, 1, 11}, {1210, 8, 19}},
 		},
-		OrderNote: msgjson.OrderNote{
-			Seq:      2,
-			MarketID: tDcrBtcMktName,
-			OrderID:  oid2[:],
+		{
+			name:       "smaller than rate step",
+			bucketSize: 3,
+			expSize:    10,
+			bids:       [][3]uint64{{1000, 5, 5}, {990, 3, 8}, {950, 2, 10}, {890, 7, 17}},
+			asks:       [][3]uint64{{1010, 4, 4}, {1040, 6, 10}, {1100, 1, 11}, {1210, 8, 19}},
 		},
-	})
+		{
+			name:       "multiple of rate step",
+			bucketSize: 50,
+			expSize:    50,
+			bids:       [][3]uint64{{1000, 5, 5}, {950, 5, 10}, {850, 7, 17}},
+			asks:       [][3]uint64{{1050, 10, 10}, {1100, 1, 11}, {1250, 8, 19}},
+		},
+		{
+			name:       "rounded up to rate step",
+			bucketSize: 95,
+			expSize:    100,
+			bids:       [][3]uint64{{1000, 5, 5}, {900, 5, 10}, {800, 7, 17}},
+			asks:       [][3]uint64{{1100, 11, 11}, {1300, 8, 19}},
+		},
+		{
+			name:       "whole book",
+			bucketSize: 1000,
+			expSize:    1000,
+			bids:       [][3]uint64{{1000, 5, 5}, {0, 12, 17}},
+			asks:       [][3]uint64{{2000, 19, 19}},
+		},
+	}
 
-	err = handleBookOrderMsg(tCore, dc, bookNote)
-	if err == nil {
-		t.Fatalf("no error for missing book")
+	for _, tt := range tests {
+		depth, err := tCore.MarketDepthAt(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, tt.bucketSize)
+		if err != nil {
+			t.Fatalf("%s: MarketDepthAt error: %v", tt.name, err)
+		}
+		if depth.BucketSize != tt.expSize {
+			t.Fatalf("%s: wrong bucket size. expected %d, got %d", tt.name, tt.expSize, depth.BucketSize)
+		}
+		if depth.BestBid != 1000 || depth.BestAsk != 1010 || depth.Spread != 10 {
+			t.Fatalf("%s: wrong best bid, best ask, or spread: %d, %d, %d", tt.name, depth.BestBid, depth.BestAsk, depth.Spread)
+		}
+		checkSide := func(side string, buckets []*DepthBucket, exp [][3]uint64) {
+			t.Helper()
+			if len(buckets) != len(exp) {
+				t.Fatalf("%s: expected %d %s buckets, got %d", tt.name, len(exp), side, len(buckets))
+			}
+			for i, b := range buckets {
+				if b.Rate != exp[i][0] || b.Qty != exp[i][1] || b.CumulativeQty != exp[i][2] {
+					t.Fatalf("%s: wrong %s bucket %d. expected %v, got %+v", tt.name, side, i, exp[i], b)
+				}
+			}
+		}
+		checkSide("bid", depth.Bids, tt.bids)
+		checkSide("ask", depth.Asks, tt.asks)
 	}
 
-	// Sync to unknown dex
-	_, _, err = tCore.SyncBook("unknown dex", tUTXOAssetA.ID, tUTXOAssetB.ID)
-	if err == nil {
-		t.Fatalf("no error for unknown dex")
+	// Unknown market.
+	if _, err := tCore.MarketDepthAt(tDexHost, tUTXOAssetA.ID, 12345, 10); err == nil {
+		t.Fatalf("no error for unknown market")
 	}
-	_, _, err = tCore.SyncBook(tDexHost, tUTXOAssetA.ID, 12345)
-	if err == nil {
-		t.Fatalf("no error for nonsense market")
+}
+
+func TestRecentTrades(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	tape := []*msgjson.PublicTrade{
+		{Rate: 3e7, Qty: 2e8, Sell: true, Stamp: 2000},
+		{Rate: 2e7, Qty: 1e8, Sell: false, Stamp: 1000},
+	}
+	var reqN int
+	queueTape := func() {
+		rig.ws.queueResponse(msgjson.RecentTradesRoute, func(msg *msgjson.Message, f msgFunc) error {
+			req := new(msgjson.RecentTradesRequest)
+			msg.Unmarshal(req)
+			if req.BaseID != tUTXOAssetA.ID || req.QuoteID != tUTXOAssetB.ID {
+				t.Errorf("wrong market requested: %d-%d", req.BaseID, req.QuoteID)
+			}
+			reqN = req.N
+			resp, _ := msgjson.NewResponse(msg.ID, tape, nil)
+			f(resp)
+			return nil
+		})
 	}
 
-	// Success
-	rig.ws.queueResponse(msgjson.OrderBookRoute, func(msg *msgjson.Message, f msgFunc) error {
-		f(bookMsg)
-		return nil
-	})
-	_, feed1, err := tCore.SyncBook(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	queueTape()
+	trades, err := tCore.RecentTrades(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, 2)
 	if err != nil {
-		t.Fatalf("SyncBook 1 error: %v", err)
+		t.Fatalf("RecentTrades error: %v", err)
 	}
-	_, feed2, err := tCore.SyncBook(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
-	if err != nil {
-		t.Fatalf("SyncBook 2 error: %v", err)
+	if reqN != 2 {
+		t.Fatalf("wrong number of trades requested: %d", reqN)
 	}
-
-	// Should be able to retrieve the book now.
-	book, err := tCore.Book(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
-	if err != nil {
-		t.Fatalf("Core.Book error: %v", err)
+	if len(trades) != len(tape) {
+		t.Fatalf("expected %d trades, got %d", len(tape), len(trades))
 	}
-	// Should have one buy order
-	if len(book.Buys) != 1 {
-		t.Fatalf("no buy orders found. expected 1")
+	for i, trade := range trades {
+		exp := tape[i]
+		if trade.Rate != exp.Rate || trade.Qty != exp.Qty || trade.Sell != exp.Sell || trade.Stamp != exp.Stamp {
+			t.Fatalf("wrong trade %d. expected %+v, got %+v", i, exp, trade)
+		}
 	}
 
-	// Both channels should have a full orderbook.
-	checkAction(feed1, FreshBookAction)
-	checkAction(feed2, FreshBookAction)
+	// Unknown market.
+	if _, err := tCore.RecentTrades(tDexHost, tUTXOAssetA.ID, 12345, 0); err == nil {
+		t.Fatalf("no error for unknown market")
+	}
 
-	err = handleBookOrderMsg(tCore, dc, bookNote)
-	if err != nil {
-		t.Fatalf("[handleBookOrderMsg]: unexpected err: %v", err)
+	// Unknown host.
+	if _, err := tCore.RecentTrades("unknown.tld:7232", tUTXOAssetA.ID, tUTXOAssetB.ID, 0); err == nil {
+		t.Fatalf("no error for unknown host")
 	}
 
-	// Both channels should have an update.
-	checkAction(feed1, BookOrderAction)
-	checkAction(feed2, BookOrderAction)
+	// Server error.
+	rig.ws.queueResponse(msgjson.RecentTradesRoute, func(msg *msgjson.Message, f msgFunc) error {
+		resp, _ := msgjson.NewResponse(msg.ID, nil, msgjson.NewError(msgjson.UnknownMarket, "unknown market"))
+		f(resp)
+		return nil
+	})
+	if _, err := tCore.RecentTrades(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, 0); err == nil {
+		t.Fatalf("no error for server error")
+	}
+}
 
-	// Close feed 1
-	feed1.Close()
+func TestFeeRateHistory(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-	oid3 := ordertest.RandomOrderID()
-	bookNote, _ = msgjson.NewNotification(msgjson.BookOrderRoute, &msgjson.BookOrderNote{
-		TradeNote: msgjson.TradeNote{
-			Side:     msgjson.SellOrderNum,
-			Quantity: 10,
-			Rate:     3,
-		},
-		OrderNote: msgjson.OrderNote{
-			Seq:      3,
-			MarketID: tDcrBtcMktName,
-			OrderID:  oid3[:],
-		},
+	hist := []*msgjson.FeeRateRecord{
+		{Rate: 10, Stamp: 1000},
+		{Rate: 20, Stamp: 2000},
+	}
+	var reqN int
+	rig.ws.queueResponse(msgjson.FeeRateHistoryRoute, func(msg *msgjson.Message, f msgFunc) error {
+		req := new(msgjson.FeeRateHistoryRequest)
+		msg.Unmarshal(req)
+		if req.AssetID != tUTXOAssetA.ID {
+			t.Errorf("wrong asset requested: %d", req.AssetID)
+		}
+		reqN = req.N
+		resp, _ := msgjson.NewResponse(msg.ID, hist, nil)
+		f(resp)
+		return nil
 	})
-	err = handleBookOrderMsg(tCore, dc, bookNote)
+	rates, err := tCore.FeeRateHistory(tUTXOAssetA.ID, 5)
 	if err != nil {
-		t.Fatalf("[handleBookOrderMsg]: unexpected err: %v", err)
+		t.Fatalf("FeeRateHistory error: %v", err)
 	}
-
-	// feed1 should have no update
-	select {
-	case <-feed1.Next():
-		t.Fatalf("update for feed 1 after Close")
-	default:
+	if reqN != 5 {
+		t.Fatalf("wrong number of rates requested: %d", reqN)
 	}
-	// feed2 should though
-	checkAction(feed2, BookOrderAction)
-
-	// Make sure the book has been updated.
-	book, _ = tCore.Book(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
-	if len(book.Buys) != 2 {
-		t.Fatalf("expected 2 buys, got %d", len(book.Buys))
+	if len(rates) != len(hist) {
+		t.Fatalf("expected %d rates, got %d", len(hist), len(rates))
 	}
-	if len(book.Sells) != 1 {
-		t.Fatalf("expected 1 sell, got %d", len(book.Sells))
+	for i, rec := range rates {
+		if *rec != *hist[i] {
+			t.Fatalf("wrong rate %d. expected %+v, got %+v", i, hist[i], rec)
+		}
 	}
 
-	// Update the remaining quantity of the just booked order.
-	var remaining uint64 = 5 * 1e8
-	bookNote, _ = msgjson.NewNotification(msgjson.BookOrderRoute, &msgjson.UpdateRemainingNote{
-		OrderNote: msgjson.OrderNote{
-			Seq:      4,
-			MarketID: tDcrBtcMktName,
-			OrderID:  oid3[:],
-		},
-		Remaining: remaining,
-	})
-	err = handleUpdateRemainingMsg(tCore, dc, bookNote)
-	if err != nil {
-		t.Fatalf("[handleBookOrderMsg]: unexpected err: %v", err)
+	// No server with a market for the asset.
+	if _, err := tCore.FeeRateHistory(12345, 0); err == nil {
+		t.Fatalf("no error for an asset without a market")
 	}
 
-	// feed2 should have an update
-	checkAction(feed2, UpdateRemainingAction)
-	book, _ = tCore.Book(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
-	firstSellQty := book.Sells[0].QtyAtomic
-	if firstSellQty != remaining {
-		t.Fatalf("expected remaining quantity of %d after update_remaining. got %d", remaining, firstSellQty)
+	// Not connected.
+	atomic.StoreUint32(&rig.dc.connectionStatus, uint32(comms.Disconnected))
+	if _, err := tCore.FeeRateHistory(tUTXOAssetA.ID, 0); err == nil {
+		t.Fatalf("no error when not connected")
 	}
+}
 
-	// Ensure handleUnbookOrderMsg removes a book order from an associated
-	// order book as expected.
-	unbookNote, _ := msgjson.NewNotification(msgjson.UnbookOrderRoute, &msgjson.UnbookOrderNote{
-		Seq:      5,
-		MarketID: tDcrBtcMktName,
-		OrderID:  oid1[:],
-	})
+func TestSignedConfig(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-	err = handleUnbookOrderMsg(tCore, dc, unbookNote)
-	if err != nil {
-		t.Fatalf("[handleUnbookOrderMsg]: unexpected err: %v", err)
+	cfgB, _ := json.Marshal(rig.dc.cfg)
+	newSignedConfig := func() *msgjson.SignedConfig {
+		sc := &msgjson.SignedConfig{Config: cfgB, Stamp: 1234}
+		sign(tDexPriv, sc)
+		return sc
 	}
-	// feed2 should have a notification.
-	checkAction(feed2, UnbookOrderAction)
-	book, _ = tCore.Book(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
-	if len(book.Buys) != 1 {
-		t.Fatalf("expected 1 buy after unbook_order, got %d", len(book.Buys))
-	}
-
-	// Test candles
-	queueCandles := func() {
-		rig.ws.queueResponse(msgjson.CandlesRoute, func(msg *msgjson.Message, f msgFunc) error {
-			resp, _ := msgjson.NewResponse(msg.ID, &msgjson.WireCandles{
-				StartStamps:  []uint64{1, 2},
-				EndStamps:    []uint64{3, 4},
-				MatchVolumes: []uint64{1, 2},
-				QuoteVolumes: []uint64{1, 2},
-				HighRates:    []uint64{3, 4},
-				LowRates:     []uint64{1, 2},
-				StartRates:   []uint64{1, 2},
-				EndRates:     []uint64{3, 4},
-			}, nil)
+	queueSignedConfig := func(sc *msgjson.SignedConfig) {
+		rig.ws.queueResponse(msgjson.SignedConfigRoute, func(msg *msgjson.Message, f msgFunc) error {
+			resp, _ := msgjson.NewResponse(msg.ID, sc, nil)
 			f(resp)
 			return nil
 		})
 	}
-	queueCandles()
 
-	if err := feed2.Candles("1h"); err != nil {
-		t.Fatalf("Candles error: %v", err)
+	queueSignedConfig(newSignedConfig())
+	sc, cfg, err := tCore.SignedConfig(tDexHost)
+	if err != nil {
+		t.Fatalf("SignedConfig error: %v", err)
 	}
-
-	checkAction(feed2, FreshCandlesAction)
-
-	// An epoch report should trigger two candle updates, one for each bin size.
-	epochReport, _ := msgjson.NewNotification(msgjson.EpochReportRoute, &msgjson.EpochReportNote{
-		MarketID:     tDcrBtcMktName,
-		Epoch:        1,
-		BaseFeeRate:  2,
-		QuoteFeeRate: 3,
-		Candle: msgjson.Candle{
-			StartStamp:  1,
-			EndStamp:    2,
-			MatchVolume: 3,
-			QuoteVolume: 3,
-			HighRate:    4,
-			LowRate:     1,
-			StartRate:   1,
-			EndRate:     2,
-		},
-	})
-
-	if err := handleEpochReportMsg(tCore, dc, epochReport); err != nil {
-		t.Fatalf("handleEpochReportMsg error: %v", err)
+	if len(cfg.Markets) != len(rig.dc.cfg.Markets) || len(cfg.Assets) != len(rig.dc.cfg.Assets) ||
+		cfg.Markets[0].LotSize != rig.dc.cfg.Markets[0].LotSize {
+		t.Fatalf("wrong config returned")
 	}
 
-	checkAction(feed2, EpochMatchSummary)
-
-	// We'll only receive 1 candle update, since we only synced one set of
-	// candles so far.
-	checkAction(feed2, CandleUpdateAction)
-	checkAction(feed2, EpochResolved)
-
-	// Now subscribe to the 24h candles too.
-	queueCandles()
-	if err := feed2.Candles("24h"); err != nil {
-		t.Fatalf("24h Candles error: %v", err)
+	// The exported document round-trips and verifies.
+	b, err := json.Marshal(sc)
+	if err != nil {
+		t.Fatalf("error encoding signed config: %v", err)
 	}
-	checkAction(feed2, FreshCandlesAction)
-
-	// This time, an epoch report should trigger two updates.
-	if err := handleEpochReportMsg(tCore, dc, epochReport); err != nil {
-		t.Fatalf("handleEpochReportMsg error: %v", err)
+	var reSC msgjson.SignedConfig
+	if err := json.Unmarshal(b, &reSC); err != nil {
+		t.Fatalf("error decoding signed config: %v", err)
+	}
+	pubKey := tDexKey.SerializeCompressed()
+	if _, err := VerifySignedConfig(&reSC, pubKey); err != nil {
+		t.Fatalf("exported config failed verification: %v", err)
 	}
-	checkAction(feed2, EpochMatchSummary)
-	checkAction(feed2, CandleUpdateAction)
-	checkAction(feed2, CandleUpdateAction)
-}
 
-type tDriver struct {
-	wallet        asset.Wallet
-	decodedCoinID string
-	winfo         *asset.WalletInfo
-}
+	// Tampering with the config fails verification.
+	tampered := newSignedConfig()
+	tampered.Config = bytes.Replace(tampered.Config, []byte(`"lotsize":`), []byte(`"lotsize":1`), 1)
+	if _, err := VerifySignedConfig(tampered, pubKey); err == nil {
+		t.Fatal("no error for tampered config")
+	}
+	queueSignedConfig(tampered)
+	if _, _, err := tCore.SignedConfig(tDexHost); err == nil {
+		t.Fatal("no error for tampered config from server")
+	}
 
-func (drv *tDriver) Open(cfg *asset.WalletConfig, logger dex.Logger, net dex.Network) (asset.Wallet, error) {
-	return drv.wallet, nil
-}
+	// Tampering with the stamp fails verification.
+	tampered = newSignedConfig()
+	tampered.Stamp++
+	if _, err := VerifySignedConfig(tampered, pubKey); err == nil {
+		t.Fatal("no error for tampered stamp")
+	}
 
-func (drv *tDriver) DecodeCoinID(coinID []byte) (string, error) {
-	return drv.decodedCoinID, nil
-}
+	// Signed by a different key.
+	otherPriv, _ := secp256k1.GeneratePrivateKey()
+	forged := newSignedConfig()
+	sign(otherPriv, forged)
+	queueSignedConfig(forged)
+	if _, _, err := tCore.SignedConfig(tDexHost); err == nil {
+		t.Fatal("no error for config signed by another key")
+	}
 
-func (drv *tDriver) Info() *asset.WalletInfo {
-	return drv.winfo
-}
+	// A config validly signed by a key that is not in the config.
+	if _, err := VerifySignedConfig(forged, otherPriv.PubKey().SerializeCompressed()); err == nil {
+		t.Fatal("no error for mismatched config public key")
+	}
 
-type tCreator struct {
-	*tDriver
-	doesntExist bool
-	existsErr   error
-	createErr   error
+	// Unknown host.
+	if _, _, err := tCore.SignedConfig("unknown.tld:7232"); err == nil {
+		t.Fatal("no error for unknown host")
+	}
 }
 
-func (ctr *tCreator) Exists(walletType, dataDir string, settings map[string]string, net dex.Network) (bool, error) {
-	return !ctr.doesntExist, ctr.existsErr
+type tPriceOracle struct {
+	price float64
+	err   error
 }
 
-func (ctr *tCreator) Create(*asset.CreateWalletParams) error {
-	return ctr.createErr
+func (o *tPriceOracle) Price(_ context.Context, _, _ uint32) (float64, error) {
+	return o.price, o.err
 }
 
-func TestCreateWallet(t *testing.T) {
+func TestPriceOracle(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
 	tCore := rig.core
 
-	// Create a new asset.
-	a := *tUTXOAssetA
-	tILT := &a
-	tILT.Symbol = "ilt"
-	tILT.ID, _ = dex.BipSymbolID(tILT.Symbol)
+	if _, err := newPriceOracleChecker(&PriceOracleConfig{Oracle: &tPriceOracle{}, Threshold: -1}); err == nil {
+		t.Fatalf("no error for negative threshold")
+	}
 
-	// Create registration form.
-	form := &WalletForm{
-		AssetID: tILT.ID,
-		Config: map[string]string{
-			"rpclisten": "localhost",
+	oracle := &tPriceOracle{price: 1}
+	var err error
+	tCore.priceOracle, err = newPriceOracleChecker(&PriceOracleConfig{Oracle: oracle, Threshold: 0.05})
+	if err != nil {
+		t.Fatalf("newPriceOracleChecker error: %v", err)
+	}
+	tCore.oracle = oracle
+
+	// A mid-gap of 1.0 in conventional units.
+	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	err = book.Sync(&msgjson.OrderBook{
+		Seq:      2,
+		MarketID: tDcrBtcMktName,
+		Orders: []*msgjson.BookOrderNote{
+			tBookOrderNote(1, false, 5, 0.99e8),
+			tBookOrderNote(2, true, 4, 1.01e8),
 		},
-		Type: "type",
+	})
+	if err != nil {
+		t.Fatalf("Sync error: %v", err)
 	}
+	rig.dc.books[tDcrBtcMktName] = book
 
-	ensureErr := func(tag string) {
+	feed := tCore.NotificationFeed()
+	defer feed.ReturnFeed()
+
+	checkNote := func(tag string, expNote bool) {
 		t.Helper()
-		err := tCore.CreateWallet(tPW, wPW, form)
-		if err == nil {
-			t.Fatalf("no %s error", tag)
+		tCore.checkOraclePrices(tCtx)
+		select {
+		case n := <-feed.C:
+			if !expNote {
+				t.Fatalf("%s: unexpected notification %s", tag, n.Topic())
+			}
+			if n.Topic() != TopicPriceDivergence {
+				t.Fatalf("%s: wrong topic %s", tag, n.Topic())
+			}
+			if n.Severity() != db.WarningLevel {
+				t.Fatalf("%s: wrong severity %v", tag, n.Severity())
+			}
+		default:
+			if expNote {
+				t.Fatalf("%s: no notification", tag)
+			}
 		}
 	}
 
-	// Try to add an existing wallet.
-	wallet, tWallet := newTWallet(tILT.ID)
-	tCore.wallets[tILT.ID] = wallet
-	ensureErr("existing wallet")
-	delete(tCore.wallets, tILT.ID)
-
-	// Failure to retrieve encryption key params.
-	creds := tCore.credentials
-	tCore.credentials = nil
-	ensureErr("db.Get")
-	tCore.credentials = creds
-
-	// Crypter error.
-	rig.crypter.(*tCrypter).encryptErr = tErr
-	ensureErr("Encrypt")
-	rig.crypter.(*tCrypter).encryptErr = nil
+	checkNote("aligned", false)
 
-	// Try an unknown wallet (not yet asset.Register'ed).
-	ensureErr("unregistered asset")
+	oracle.price = 1.04
+	checkNote("within threshold", false)
 
-	// Register the asset.
-	asset.Register(tILT.ID, &tDriver{
-		wallet:        wallet.Wallet,
-		decodedCoinID: "ilt-coin",
-		winfo:         tWalletInfo,
-	})
+	oracle.price = 1.2
+	checkNote("divergent", true)
+	checkNote("still divergent", false)
 
-	// Connection error.
-	tWallet.connectErr = tErr
-	ensureErr("Connect")
-	tWallet.connectErr = nil
+	oracle.err = errors.New("test error")
+	checkNote("oracle error", false)
+	oracle.err = nil
 
-	// Unlock error.
-	tWallet.unlockErr = tErr
-	ensureErr("Unlock")
-	tWallet.unlockErr = nil
+	oracle.price = 1
+	checkNote("realigned", false)
 
-	// Address error.
-	tWallet.addrErr = tErr
-	ensureErr("Address")
-	tWallet.addrErr = nil
+	oracle.price = 0.8
+	checkNote("divergent again", true)
 
-	// Balance error.
-	tWallet.balErr = tErr
-	ensureErr("Balance")
-	tWallet.balErr = nil
+	oracle.price = 0
+	checkNote("invalid reference price", false)
 
-	// Database error.
-	rig.db.updateWalletErr = tErr
-	ensureErr("db.UpdateWallet")
-	rig.db.updateWalletErr = nil
+	oracle.price = 1
+	checkNote("realigned again", false)
 
-	// Success
-	delete(tCore.wallets, tILT.ID)
-	err := tCore.CreateWallet(tPW, wPW, form)
-	if err != nil {
-		t.Fatalf("error when should be no error: %v", err)
-	}
+	// Without an oracle configured, the fiat rates are the reference.
+	tCore.oracle = &fiatPriceOracle{tCore}
+	checkNote("no fiat rates", false)
+	source := newCommonRateSource(nil)
+	source.fiatRates[tUTXOAssetA.ID] = &fiatRateInfo{rate: 30, lastUpdate: time.Now()}
+	source.fiatRates[tUTXOAssetB.ID] = &fiatRateInfo{rate: 20, lastUpdate: time.Now()}
+	tCore.fiatRateSources["test"] = source
+	checkNote("fiat rates divergent", true)
+	source.fiatRates[tUTXOAssetA.ID].rate = 20
+	checkNote("fiat rates aligned", false)
 }
 
-// TODO: TestGetDEXConfig
-/*
-func TestGetFee(t *testing.T) {
+func TestPriceBand(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
 	tCore := rig.core
-	cert := []byte{}
 
-	// DEX already registered
-	_, err := tCore.GetFee(tDexHost, cert)
-	if !errorHasCode(err, dupeDEXErr) {
-		t.Fatalf("wrong account exists error: %v", err)
+	for _, pct := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if err := tCore.SetPriceBand(pct); err == nil {
+			t.Fatalf("no error for invalid price band %f", pct)
+		}
 	}
 
-	// Lose the dexConnection
-	tCore.connMtx.Lock()
-	delete(tCore.conns, tDexHost)
-	tCore.connMtx.Unlock()
-
-	// connectDEX error
-	_, err = tCore.GetFee(tUnparseableHost, cert)
-	if !errorHasCode(err, addressParseErr) {
-		t.Fatalf("wrong connectDEX error: %v", err)
+	units := dex.UnitInfo{Conventional: dex.Denomination{ConversionFactor: 1e8}}
+	form := &TradeForm{
+		Host:    tDexHost,
+		IsLimit: true,
+		Base:    tUTXOAssetA.ID,
+		Quote:   tUTXOAssetB.ID,
+		Rate:    1.5e8,
+	}
+	check := func(tag string, expErr bool) {
+		t.Helper()
+		var err error
+		if form.IsLimit && !form.OverridePriceBand {
+			err = tCore.checkPriceBand(rig.dc, form.Base, form.Quote, form.Rate, units, units)
+		}
+		if expErr {
+			if err == nil {
+				t.Fatalf("%s: out-of-band order not rejected", tag)
+			}
+			if !errorHasCode(err, orderParamsErr) {
+				t.Fatalf("%s: wrong error: %v", tag, err)
+			}
+		} else if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tag, err)
+		}
 	}
 
-	// Queue a config response for success
-	rig.queueConfig()
+	// Disabled by default.
+	check("disabled", false)
 
-	// Success
-	_, err = tCore.GetFee(tDexHost, cert)
-	if err != nil {
-		t.Fatalf("GetFee error: %v", err)
+	if err := tCore.SetPriceBand(10); err != nil {
+		t.Fatalf("SetPriceBand error: %v", err)
+	}
+	if tCore.PriceBand() != 10 {
+		t.Fatalf("wrong price band %f", tCore.PriceBand())
 	}
-}
-*/
 
-func TestPostBond(t *testing.T) {
-	// This test takes a little longer because the key is decrypted every time
-	// Register is called.
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-	dc := rig.dc
-	clearConn := func() {
-		tCore.connMtx.Lock()
-		delete(tCore.conns, tDexHost)
-		tCore.connMtx.Unlock()
-	}
-	clearConn()
+	// No reference price.
+	check("no reference", false)
 
-	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = wallet
-	tWallet.bal = &asset.Balance{
-		Available: 4e9,
+	// A mid-gap of 1.0 in conventional units.
+	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	err := book.Sync(&msgjson.OrderBook{
+		Seq:      2,
+		MarketID: tDcrBtcMktName,
+		Orders: []*msgjson.BookOrderNote{
+			tBookOrderNote(1, false, 5, 0.99e8),
+			tBookOrderNote(2, true, 4, 1.01e8),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Sync error: %v", err)
 	}
+	rig.dc.books[tDcrBtcMktName] = book
 
-	// When registering, successfully retrieving *db.AccountInfo from the DB is
-	// an error (no dupes). Initial state is to return an error.
-	rig.db.acctErr = tErr
-
-	_ = tCore.Login(tPW)
+	check("far above", true)
+	form.Rate = 0.5e8
+	check("far below", true)
+	form.Rate = 1.09e8
+	check("in band", false)
+	form.Rate = 0.91e8
+	check("in band below", false)
+
+	// Overridden for a single order.
+	form.Rate = 2e8
+	form.OverridePriceBand = true
+	check("overridden", false)
+	form.OverridePriceBand = false
+
+	// Market orders are not checked.
+	form.IsLimit = false
+	check("market order", false)
+	form.IsLimit = true
 
-	// (*Core).Register does setupCryptoV2 to make the dc.acct.privKey etc., so
-	// we don't know the ClientPubKey here. It must be set in the request
-	// handler configured by queueRegister.
-	rig.ws.liveBondExpiry = uint64(time.Now().Add(time.Duration(pendingBuffer(dex.Simnet)) * 2 * time.Second).Unix())
-	postBondResult := &msgjson.PostBondResult{
-		AccountID:  rig.acct.id[:],
-		AssetID:    dcrBondAsset.ID,
-		Amount:     dcrBondAsset.Amt,
-		Expiry:     rig.ws.liveBondExpiry,
-		Reputation: &account.Reputation{BondedTier: 1},
+	// Without a book, the cached fiat rates are the reference.
+	delete(rig.dc.books, tDcrBtcMktName)
+	source := newCommonRateSource(nil)
+	source.fiatRates[tUTXOAssetA.ID] = &fiatRateInfo{rate: 42, lastUpdate: time.Now()}
+	source.fiatRates[tUTXOAssetB.ID] = &fiatRateInfo{rate: 20, lastUpdate: time.Now()}
+	tCore.fiatRateSources["test"] = source
+	check("fiat rates in band", false)
+	form.Rate = 1e8
+	check("fiat rates out of band", true)
+
+	// Expired fiat rates are not used.
+	source.fiatRates[tUTXOAssetA.ID].lastUpdate = time.Now().Add(-fiatRateDataExpiry)
+	check("expired fiat rates", false)
+
+	// A configured price oracle replaces the fiat rates.
+	oracle := &tPriceOracle{price: 1.5}
+	tCore.oracle = oracle
+	form.Rate = 1.5e8
+	check("oracle in band", false)
+	oracle.price = 1
+	check("oracle out of band", true)
+	oracle.err = errors.New("test error")
+	check("oracle error", false)
+}
+
+func tBookOrderNote(seq uint64, sell bool, qty, rate uint64) *msgjson.BookOrderNote {
+	oid := ordertest.RandomOrderID()
+	side := uint8(msgjson.BuyOrderNum)
+	if sell {
+		side = msgjson.SellOrderNum
+	}
+	return &msgjson.BookOrderNote{
+		TradeNote: msgjson.TradeNote{
+			Side:     side,
+			Quantity: qty,
+			Rate:     rate,
+		},
+		OrderNote: msgjson.OrderNote{
+			Seq:      seq,
+			MarketID: tDcrBtcMktName,
+			OrderID:  oid[:],
+		},
 	}
+}
 
-	var wg sync.WaitGroup
-	defer wg.Wait() // don't allow fail after TestRegister return
+func TestVWAP(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-	queueTipChange := func() {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			timeout := time.NewTimer(time.Second * 2)
-			defer timeout.Stop()
-			ticker := time.NewTicker(10 * time.Millisecond)
-			defer ticker.Stop()
-			for {
-				select {
-				case <-ticker.C:
-					tCore.waiterMtx.Lock()
-					waiterCount := len(tCore.blockWaiters)
-					tCore.waiterMtx.Unlock()
+	if _, err := tCore.MidGap(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID); err == nil {
+		t.Fatalf("no MidGap error for unsynced book")
+	}
+	if _, err := tCore.VWAP(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, true, 1); err == nil {
+		t.Fatalf("no VWAP error for unsynced book")
+	}
 
-					// Every tick, increase the bond tx confirmation count.
-					if waiterCount > 0 { // when verifyRegistrationFee adds a waiter, then we can trigger tip change
-						confs, found := tWallet.confs[dex.Bytes(tWallet.bondTxCoinID).String()]
-						if !found {
-							tWallet.setConfs(tWallet.bondTxCoinID, 0, nil)
-						} else {
-							tWallet.setConfs(tWallet.bondTxCoinID, confs+1, nil)
-						}
+	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	err := book.Sync(&msgjson.OrderBook{
+		Seq:      4,
+		MarketID: tDcrBtcMktName,
+		Orders: []*msgjson.BookOrderNote{
+			tBookOrderNote(1, false, 4e8, 100e6),
+			tBookOrderNote(2, false, 6e8, 90e6),
+			tBookOrderNote(3, true, 2e8, 110e6),
+			tBookOrderNote(4, true, 2e8, 130e6),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Sync error: %v", err)
+	}
+	rig.dc.books[tDcrBtcMktName] = book
 
-						tCore.tipChange(tUTXOAssetA.ID)
-						return
-					}
-				case <-timeout.C:
-					t.Errorf("failed to find waiter before timeout")
-					return
-				}
+	midGap, err := tCore.MidGap(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if err != nil {
+		t.Fatalf("MidGap error: %v", err)
+	}
+	if midGap != 105e6 {
+		t.Fatalf("wrong mid-gap %d", midGap)
+	}
+
+	tests := []struct {
+		name    string
+		sell    bool
+		qty     uint64
+		exp     uint64
+		expFail bool
+		avail   uint64
+	}{
+		{"partial fill of best buy", true, 2e8, 100e6, false, 0},
+		{"sell into two buys", true, 5e8, 98e6, false, 0},
+		{"buy all sells", false, 4e8, 120e6, false, 0},
+		{"insufficient buys", true, 11e8, 0, true, 10e8},
+		{"insufficient sells", false, 5e8, 0, true, 4e8},
+	}
+	for _, tt := range tests {
+		avg, err := tCore.VWAP(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, tt.sell, tt.qty)
+		if tt.expFail {
+			var depthErr *InsufficientDepthError
+			if !errors.As(err, &depthErr) {
+				t.Fatalf("%s: expected InsufficientDepthError, got %v", tt.name, err)
 			}
-		}()
+			if depthErr.Qty != tt.qty || depthErr.Available != tt.avail {
+				t.Fatalf("%s: wrong depth error %+v", tt.name, depthErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: VWAP error: %v", tt.name, err)
+		}
+		if avg != tt.exp {
+			t.Fatalf("%s: expected VWAP %d, got %d", tt.name, tt.exp, avg)
+		}
 	}
+}
 
-	accountNotFoundError := msgjson.NewError(msgjson.AccountNotFoundError, "test account not found error")
+func TestOrderTemplates(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-	queueConfigAndConnectUnknownAcct := func() {
-		rig.ws.submittedBond = nil
-		rig.queueConfig()
-		rig.queueConnect(accountNotFoundError, nil, nil) // for discoverAccount
-		rig.ws.queueResponse(msgjson.FeeRateRoute, func(msg *msgjson.Message, f msgFunc) error {
-			const feeRate = 50
-			resp, _ := msgjson.NewResponse(msg.ID, feeRate, nil)
-			f(resp)
-			return nil
-		})
+	tmpl := &db.OrderTemplate{
+		Name:       "sell10",
+		Host:       tDexHost,
+		Base:       tUTXOAssetA.ID,
+		Quote:      tUTXOAssetB.ID,
+		IsLimit:    true,
+		Sell:       true,
+		Qty:        10 * dcrBtcLotSize,
+		RateOffset: 0.1,
+	}
+	if err := tCore.SaveOrderTemplate(tmpl); err != nil {
+		t.Fatalf("SaveOrderTemplate error: %v", err)
+	}
+	for _, bad := range []*db.OrderTemplate{
+		{Host: tDexHost, Base: tUTXOAssetA.ID, Quote: tUTXOAssetB.ID, Qty: 1},
+		{Name: "x", Host: "unknown.host", Base: tUTXOAssetA.ID, Quote: tUTXOAssetB.ID, Qty: 1},
+		{Name: "x", Host: tDexHost, Base: tUTXOAssetB.ID, Quote: tUTXOAssetA.ID, Qty: 1},
+		{Name: "x", Host: tDexHost, Base: tUTXOAssetA.ID, Quote: tUTXOAssetB.ID},
+		{Name: "x", Host: tDexHost, Base: tUTXOAssetA.ID, Quote: tUTXOAssetB.ID, Qty: 1, RateOffset: 0.1},
+		{Name: "x", Host: tDexHost, Base: tUTXOAssetA.ID, Quote: tUTXOAssetB.ID, Qty: 1, IsLimit: true, RateOffset: -1},
+	} {
+		if err := tCore.SaveOrderTemplate(bad); err == nil {
+			t.Fatalf("no error saving bad template %+v", bad)
+		}
 	}
-
-	queuePostBondSequence := func() {
-		rig.queuePrevalidateBond()
-		rig.queuePostBond(postBondResult)
-		queueTipChange()
-		rig.queueConnect(nil, nil, nil)
+	if tmpls, _ := tCore.OrderTemplates(); len(tmpls) != 1 || tmpls[0].Name != tmpl.Name {
+		t.Fatalf("wrong templates %+v", tmpls)
 	}
 
-	queueResponses := func() {
-		queueConfigAndConnectUnknownAcct()
-		queuePostBondSequence()
+	// The rate is relative to the mid-gap rate, so the book must be synced.
+	if _, err := tCore.templateTradeForm(tmpl.Name, nil); err == nil {
+		t.Fatalf("no error for template rate without a synced book")
+	}
+	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	err := book.Sync(&msgjson.OrderBook{
+		Seq:      2,
+		MarketID: tDcrBtcMktName,
+		Orders: []*msgjson.BookOrderNote{
+			tBookOrderNote(1, false, 1e8, 100e6),
+			tBookOrderNote(2, true, 1e8, 110e6),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Sync error: %v", err)
 	}
+	rig.dc.books[tDcrBtcMktName] = book
 
-	form := &PostBondForm{
-		Addr:    tDexHost,
-		AppPass: tPW,
-		Asset:   &dcrBondAsset.ID,
-		Bond:    dcrBondAsset.Amt,
-		Cert:    []byte{0x1}, // not empty signals TLS, otherwise no TLS allowed hidden services
+	qty, offset, rate := 5*dcrBtcLotSize, -0.05, uint64(90e6)
+	tests := []struct {
+		name      string
+		overrides *TemplateOverrides
+		qty, rate uint64
+	}{
+		{"no overrides", nil, 10 * dcrBtcLotSize, 115_500_000},
+		{"qty", &TemplateOverrides{Qty: &qty}, qty, 115_500_000},
+		{"rate offset", &TemplateOverrides{RateOffset: &offset}, 10 * dcrBtcLotSize, 99_750_000},
+		{"rate", &TemplateOverrides{Rate: &rate, RateOffset: &offset}, 10 * dcrBtcLotSize, rate},
+	}
+	for _, tt := range tests {
+		form, err := tCore.templateTradeForm(tmpl.Name, tt.overrides)
+		if err != nil {
+			t.Fatalf("%s: templateTradeForm error: %v", tt.name, err)
+		}
+		if form.Qty != tt.qty || form.Rate != tt.rate || !form.Sell || !form.IsLimit {
+			t.Fatalf("%s: wrong trade form %+v", tt.name, form)
+		}
+	}
+	if _, err := tCore.templateTradeForm("unknown", nil); !errors.Is(err, db.ErrNoTemplate) {
+		t.Fatalf("wrong error for unknown template: %v", err)
 	}
 
-	// Suppress warnings about SendTransaction returning a mismatching ID.
-	tWallet.feeCoinSent = tWallet.bondTxCoinID
+	// Place an order from the template.
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
+	dcrWallet.Unlock(rig.crypter)
+	tDcrWallet.fundingCoins = asset.Coins{&tCoin{id: encode.RandomBytes(36), val: qty * 2}}
+	tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
+	btcWallet.Unlock(rig.crypter)
 
-	ch := tCore.NotificationFeed()
+	var sent *msgjson.LimitOrder
+	rig.ws.queueResponse(msgjson.LimitRoute, func(msg *msgjson.Message, f msgFunc) error {
+		sent = new(msgjson.LimitOrder)
+		if err := msg.Unmarshal(sent); err != nil {
+			t.Fatalf("unmarshal error: %v", err)
+		}
+		f(orderResponse(msg.ID, sent, convertMsgLimitOrder(sent), false, false, false))
+		return nil
+	})
+	corder, err := tCore.PlaceFromTemplate(tPW, tmpl.Name, &TemplateOverrides{Qty: &qty})
+	if err != nil {
+		t.Fatalf("PlaceFromTemplate error: %v", err)
+	}
+	if sent.Quantity != qty || sent.Rate != 115_500_000 || corder.Qty != qty || corder.Rate != 115_500_000 {
+		t.Fatalf("wrong order placed from template: %+v", sent)
+	}
 
-	var err error
-	run := func() {
-		// Register method will error if url is already in conns map.
-		clearConn()
+	if err := tCore.DeleteOrderTemplate(tmpl.Name); err != nil {
+		t.Fatalf("DeleteOrderTemplate error: %v", err)
+	}
+	if _, err := tCore.PlaceFromTemplate(tPW, tmpl.Name, nil); !errors.Is(err, db.ErrNoTemplate) {
+		t.Fatalf("wrong error placing from deleted template: %v", err)
+	}
+}
 
-		tWallet.setConfs(tWallet.bondTxCoinID, 0, nil)
-		// Skip finding bonds.
-		tWallet.findBondErr = errors.New("purposeful error")
-		_, err = tCore.PostBond(form)
+func TestOrderSchedules(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	err := book.Sync(&msgjson.OrderBook{
+		Seq:      2,
+		MarketID: tDcrBtcMktName,
+		Orders: []*msgjson.BookOrderNote{
+			tBookOrderNote(1, false, 1e8, 100e6),
+			tBookOrderNote(2, true, 1e8, 110e6),
+		},
+	})
+	if err != nil {
+		t.Fatalf("Sync error: %v", err)
 	}
+	rig.dc.books[tDcrBtcMktName] = book
 
-	getNotification := func(tag string) any {
-		t.Helper()
-		select {
-		case n := <-ch.C:
-			return n
-			// When it works, it should be virtually instant, but I have seen it fail
-			// at 1 millisecond.
-		case <-time.NewTimer(time.Second * 2).C:
-			t.Fatalf("timed out waiting for %s notification", tag)
-		}
-		return nil
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tDcrWallet.info.UnitInfo = tUTXOAssetA.UnitInfo
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
+	dcrWallet.Unlock(rig.crypter)
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
+	btcWallet.Unlock(rig.crypter)
+
+	qty := 10 * dcrBtcLotSize
+	tmpl := &db.OrderTemplate{
+		Name:       "dca",
+		Host:       tDexHost,
+		Base:       tUTXOAssetA.ID,
+		Quote:      tUTXOAssetB.ID,
+		IsLimit:    true,
+		Sell:       true,
+		Qty:        qty,
+		RateOffset: 0.1,
+	}
+	const interval = 24 * time.Hour
+	if _, err := tCore.CreateOrderSchedule(tmpl, time.Minute); !errorHasCode(err, orderParamsErr) {
+		t.Fatalf("wrong error for short interval: %v", err)
+	}
+	badTmpl := *tmpl
+	badTmpl.Qty = 0
+	if _, err := tCore.CreateOrderSchedule(&badTmpl, interval); !errorHasCode(err, orderParamsErr) {
+		t.Fatalf("wrong error for bad template: %v", err)
+	}
+	sched, err := tCore.CreateOrderSchedule(tmpl, interval)
+	if err != nil {
+		t.Fatalf("CreateOrderSchedule error: %v", err)
 	}
+	start := time.UnixMilli(int64(sched.Next))
 
-	// The feepayment note for mined fee payment txn notification to server, and
-	// the balance note from tip change are concurrent and thus come in no
-	// guaranteed order.
-	getBondAndBalanceNote := func() {
+	feed := tCore.NotificationFeed()
+	scheduleNotes := func() (topics []Topic) {
 		t.Helper()
-		var bondNote *BondPostNote
-		var balanceNotes uint8
-		// For a normal PostBond, there are three balance updates.
-		// 1) makeAndPostBond, 2) monitorBondConfs.trigger, and 3) tipChange.
-		for bondNote == nil || balanceNotes < 3 {
-			ntfn := getNotification("bond posted or balance")
-			switch note := ntfn.(type) {
-			case *BondPostNote:
-				if note.TopicID == TopicAccountRegistered {
-					bondNote = note
+		for {
+			select {
+			case note := <-feed.C:
+				if note.Type() == NoteTypeOrderSchedule {
+					topics = append(topics, note.Topic())
 				}
-			case *BalanceNote:
-				balanceNotes++
-			case *ReputationNote: // ignore
 			default:
-				t.Fatalf("wrong notification (%T). Expected FeePaymentNote or BalanceNote", ntfn)
+				return
 			}
 		}
 	}
 
-	queueResponses()
-	run()
-	if err != nil {
-		t.Fatalf("postbond error: %v", err)
-	}
+	var placed int
+	queueOrder := func() {
+		tDcrWallet.fundingCoins = asset.Coins{&tCoin{id: encode.RandomBytes(36), val: qty * 2}}
+		tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
+		rig.ws.queueResponse(msgjson.LimitRoute, func(msg *msgjson.Message, f msgFunc) error {
+			sent := new(msgjson.LimitOrder)
+			if err := msg.Unmarshal(sent); err != nil {
+				t.Fatalf("unmarshal error: %v", err)
+			}
+			placed++
+			// The schedules are not locked while the order is placed.
+			done := make(chan struct{})
+			go func() {
+				tCore.OrderSchedules()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Errorf("order schedules locked while placing an order")
+			}
+			f(orderResponse(msg.ID, sent, convertMsgLimitOrder(sent), false, false, false))
+			return nil
+		})
+	}
+	run := func(tag string, now time.Time, wantPlaced int, wantSkipped uint32, wantNext time.Time) {
+		t.Helper()
+		tCore.runOrderSchedules(now)
+		scheds, err := tCore.OrderSchedules()
+		if err != nil {
+			t.Fatalf("%s: OrderSchedules error: %v", tag, err)
+		}
+		if len(scheds) != 1 {
+			t.Fatalf("%s: expected 1 schedule, got %d", tag, len(scheds))
+		}
+		s := scheds[0]
+		if placed != wantPlaced || s.Placed != uint32(wantPlaced) {
+			t.Fatalf("%s: wanted %d orders placed, got %d (schedule says %d)", tag, wantPlaced, placed, s.Placed)
+		}
+		if s.Skipped != wantSkipped {
+			t.Fatalf("%s: wanted %d skipped, got %d", tag, wantSkipped, s.Skipped)
+		}
+		if next := time.UnixMilli(int64(s.Next)); !next.Equal(wantNext) {
+			t.Fatalf("%s: wanted next placement at %s, got %s", tag, wantNext, next)
+		}
+	}
 
-	// Should be two success notifications. One for fee paid on-chain, one for
-	// fee notification sent, each along with a balance note.
-	getBondAndBalanceNote()
+	// Not yet due.
+	tDcrWallet.bal = &asset.Balance{Available: qty * 3}
+	run("not due", start.Add(-time.Second), 0, 0, start)
 
-	// password error
-	rig.crypter.(*tCrypter).recryptErr = tErr
-	run()
-	if !errorHasCode(err, passwordErr) {
-		t.Fatalf("wrong password error: %v", err)
+	// The first order.
+	queueOrder()
+	run("first", start, 1, 0, start.Add(interval))
+	run("same interval", start.Add(interval/2), 1, 0, start.Add(interval))
+	if topics := scheduleNotes(); len(topics) != 0 {
+		t.Fatalf("unexpected schedule notes %v", topics)
 	}
-	rig.crypter.(*tCrypter).recryptErr = nil
 
-	// no host error
-	form.Addr = ""
-	run()
-	if !errorHasCode(err, emptyHostErr) {
-		t.Fatalf("wrong empty host error: %v", err)
+	// Insufficient balance skips the placement with a warning.
+	tDcrWallet.bal = &asset.Balance{Available: qty - 1}
+	run("low balance", start.Add(interval), 1, 1, start.Add(2*interval))
+	if topics := scheduleNotes(); len(topics) != 1 || topics[0] != TopicScheduledOrderSkipped {
+		t.Fatalf("expected a skipped note, got %v", topics)
 	}
-	form.Addr = tDexHost
 
-	// wallet not found
-	delete(tCore.wallets, tUTXOAssetA.ID)
-	run()
-	if !errorHasCode(err, missingWalletErr) {
-		t.Fatalf("wrong missing wallet error: %v", err)
+	// Paused schedules don't place orders.
+	tDcrWallet.bal = &asset.Balance{Available: qty * 3}
+	if err := tCore.PauseOrderSchedule(sched.ID, true); err != nil {
+		t.Fatalf("PauseOrderSchedule error: %v", err)
 	}
-	tCore.wallets[tUTXOAssetA.ID] = wallet
+	run("paused", start.Add(3*interval), 1, 1, start.Add(2*interval))
 
-	// Unlock wallet error
-	tWallet.unlockErr = tErr
-	tWallet.locked = true
-	run()
-	if !errorHasCode(err, walletAuthErr) {
-		t.Fatalf("wrong wallet auth error: %v", err)
+	// Resumed after missing two placements, only one order is placed.
+	if err := tCore.PauseOrderSchedule(sched.ID, false); err != nil {
+		t.Fatalf("PauseOrderSchedule error: %v", err)
 	}
-	tWallet.unlockErr = nil
-	tWallet.locked = false
+	queueOrder()
+	run("resumed", start.Add(3*interval+time.Minute), 2, 1, start.Add(4*interval))
 
-	// connectDEX error
-	form.Addr = tUnparseableHost
-	run()
-	if !errorHasCode(err, connectionErr) {
-		t.Fatalf("wrong connectDEX error: %v", err)
+	// Errors placing the order are skips.
+	tDcrWallet.fundingCoinErr = tErr
+	run("trade error", start.Add(4*interval), 2, 2, start.Add(5*interval))
+	if topics := scheduleNotes(); len(topics) != 1 || topics[0] != TopicScheduledOrderFailed {
+		t.Fatalf("expected a failed note, got %v", topics)
 	}
-	form.Addr = tDexHost
+	tDcrWallet.fundingCoinErr = nil
 
-	// fee asset not found, no cfg.Fee fallback
-	bondAssets := dc.cfg.BondAssets
-	dc.cfg.BondAssets = nil
-	queueConfigAndConnectUnknownAcct()
-	run()
-	if !errorHasCode(err, assetSupportErr) {
-		t.Fatalf("wrong error for missing asset: %v", err)
+	// Canceled schedules are deleted.
+	if err := tCore.CancelOrderSchedule(sched.ID); err != nil {
+		t.Fatalf("CancelOrderSchedule error: %v", err)
 	}
-	dc.cfg.BondAssets = bondAssets
-
-	// error creating signing key
-	rig.crypter.(*tCrypter).encryptErr = tErr
-	rig.queueConfig()
-	run()
-	if !errorHasCode(err, acctKeyErr) {
-		t.Fatalf("wrong account key error: %v", err)
+	if err := tCore.PauseOrderSchedule(sched.ID, true); !errors.Is(err, db.ErrNoSchedule) {
+		t.Fatalf("wrong error for canceled schedule: %v", err)
 	}
-	rig.crypter.(*tCrypter).encryptErr = nil
-
-	bal0 := tWallet.bal.Available
-	tWallet.bal.Available = 0
-	run()
-	if !errorHasCode(err, walletBalanceErr) {
-		t.Fatalf("expected low balance error, got: %v", err)
+	if scheds, _ := tCore.OrderSchedules(); len(scheds) != 0 {
+		t.Fatalf("schedule not deleted")
 	}
-	tWallet.bal.Available = bal0
+}
 
-	// signature error
-	queueConfigAndConnectUnknownAcct()
-	rig.ws.queueResponse(msgjson.PreValidateBondRoute, func(msg *msgjson.Message, f msgFunc) error {
-		preEval := new(msgjson.PreValidateBond)
-		msg.Unmarshal(preEval)
+func TestIcebergOrders(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-		preEvalResult := &msgjson.PreValidateBondResult{
-			Signature: msgjson.Signature{
-				Sig: []byte{0xb, 0xa, 0xd},
-			},
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
+	dcrWallet.Unlock(rig.crypter)
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
+	btcWallet.Unlock(rig.crypter)
+
+	const lots = 5
+	qty := lots * dcrBtcLotSize
+	displayQty := 2 * dcrBtcLotSize
+	form := &TradeForm{
+		Host:    tDexHost,
+		IsLimit: true,
+		Sell:    true,
+		Base:    tUTXOAssetA.ID,
+		Quote:   tUTXOAssetB.ID,
+		Qty:     qty,
+		Rate:    dcrBtcRateStep * 1000,
+	}
+
+	var placedQtys []uint64
+	queueOrder := func() {
+		tDcrWallet.fundingCoins = asset.Coins{&tCoin{id: encode.RandomBytes(36), val: qty * 2}}
+		tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
+		rig.ws.queueResponse(msgjson.LimitRoute, func(msg *msgjson.Message, f msgFunc) error {
+			sent := new(msgjson.LimitOrder)
+			if err := msg.Unmarshal(sent); err != nil {
+				t.Fatalf("unmarshal error: %v", err)
+			}
+			placedQtys = append(placedQtys, sent.Quantity)
+			f(orderResponse(msg.ID, sent, convertMsgLimitOrder(sent), false, false, false))
+			return nil
+		})
+	}
+	feed := tCore.NotificationFeed()
+	icebergNotes := func() (notes []*IcebergNote) {
+		t.Helper()
+		for {
+			select {
+			case note := <-feed.C:
+				if n, ok := note.(*IcebergNote); ok {
+					notes = append(notes, n)
+				}
+			default:
+				return
+			}
 		}
-		resp, _ := msgjson.NewResponse(msg.ID, preEvalResult, nil)
-		f(resp)
+	}
+	// checkDeleted checks that the finished iceberg order is no longer active
+	// or stored.
+	checkDeleted := func(id string) {
+		t.Helper()
+		ices, err := tCore.IcebergOrders()
+		if err != nil {
+			t.Fatalf("IcebergOrders error: %v", err)
+		}
+		for _, ice := range ices {
+			if ice.ID == id {
+				t.Fatalf("finished iceberg order %s is still active", id)
+			}
+		}
+		if _, found := rig.db.icebergOrders[id]; found {
+			t.Fatalf("finished iceberg order %s is still stored", id)
+		}
+	}
+	iceberg := func(id string) *db.IcebergOrder {
+		t.Helper()
+		ices, err := tCore.IcebergOrders()
+		if err != nil {
+			t.Fatalf("IcebergOrders error: %v", err)
+		}
+		for _, ice := range ices {
+			if ice.ID == id {
+				return ice
+			}
+		}
+		t.Fatalf("iceberg order %s not found", id)
 		return nil
-	})
-	run()
-	if !errorHasCode(err, signatureErr) {
-		t.Fatalf("wrong error for bad signature on prevalidate response: %v", err)
 	}
-
-	// Wrong bond size on form
-	goodAmt := form.Bond
-	form.Bond = goodAmt + 1
-	queueConfigAndConnectUnknownAcct()
-	run()
-	if !errorHasCode(err, bondAmtErr) {
-		t.Fatalf("wrong error for wrong fee in form: %v", err)
+	// fillChild sets the fill and status of the active child order.
+	fillChild := func(id string, filled uint64, status order.OrderStatus) {
+		t.Helper()
+		ice := iceberg(id)
+		var oid order.OrderID
+		copy(oid[:], ice.Child)
+		tracker := rig.dc.trades[oid]
+		if tracker == nil {
+			t.Fatalf("child order %s not found", oid)
+		}
+		tracker.mtx.Lock()
+		tracker.Trade().SetFill(filled)
+		tracker.metaData.Status = status
+		tracker.mtx.Unlock()
 	}
-	form.Bond = goodAmt
 
-	// MakeBondTx error
-	queueConfigAndConnectUnknownAcct()
-	tWallet.makeBondTxErr = tErr
-	run()
-	if !errorHasCode(err, bondPostErr) {
-		t.Fatalf("wrong error for bondPostErr: %v", err)
+	// Bad parameters.
+	badForm := *form
+	badForm.TifNow = true
+	if _, err := tCore.PlaceIcebergOrder(tPW, &badForm, displayQty); !errorHasCode(err, orderParamsErr) {
+		t.Fatalf("wrong error for immediate order: %v", err)
 	}
-	tWallet.makeBondTxErr = nil
-
-	// Make sure it's good again.
-	queueResponses()
-	run()
-	if err != nil {
-		t.Fatalf("error after regaining valid state: %v", err)
+	if _, err := tCore.PlaceIcebergOrder(tPW, form, qty); !errorHasCode(err, orderParamsErr) {
+		t.Fatalf("wrong error for display quantity = quantity: %v", err)
 	}
-	getBondAndBalanceNote()
-
-	// Test the account recovery path.
-	rig.queueConfig()
-	rig.queueConnect(nil, nil, nil) // account exists
-	run()
-	if err != nil {
-		t.Fatalf("Paid account error: %v", err)
+	if _, err := tCore.PlaceIcebergOrder(tPW, form, displayQty+1); !errorHasCode(err, orderParamsErr) {
+		t.Fatalf("wrong error for unquantized display quantity: %v", err)
 	}
 
-	// Account suspended should derive new HD credentials.
-	rig.queueConnect(nil, nil, nil, true) // first try exists but suspended
-	queueResponses()
-	run()
+	// The first child order is placed for the display quantity.
+	queueOrder()
+	ice, err := tCore.PlaceIcebergOrder(tPW, form, displayQty)
 	if err != nil {
-		t.Fatalf("Suspension recovery error: %v", err)
+		t.Fatalf("PlaceIcebergOrder error: %v", err)
+	}
+	if len(placedQtys) != 1 || placedQtys[0] != displayQty {
+		t.Fatalf("wrong first child order quantities %v", placedQtys)
 	}
-	getBondAndBalanceNote()
-}
 
-func TestCredentialsUpgrade(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-	rig.db.legacyKeyErr = nil
+	// Nothing happens until the child order is filled, even partially.
+	fillChild(ice.ID, dcrBtcLotSize, order.OrderStatusBooked)
+	tCore.checkIcebergOrders()
+	if len(placedQtys) != 1 || iceberg(ice.ID).Filled != 0 {
+		t.Fatalf("iceberg order replenished before the child order was filled")
+	}
 
-	clearUpgrade := func() {
-		rig.db.creds.EncInnerKey = nil
-		tCore.credentials.EncInnerKey = nil
+	// Each filled child order is replaced with a new one, the last for only
+	// the remaining quantity.
+	queueOrder()
+	fillChild(ice.ID, displayQty, order.OrderStatusExecuted)
+	tCore.checkIcebergOrders()
+	queueOrder()
+	fillChild(ice.ID, displayQty, order.OrderStatusExecuted)
+	tCore.checkIcebergOrders()
+	if want := []uint64{displayQty, displayQty, dcrBtcLotSize}; !reflect.DeepEqual(placedQtys, want) {
+		t.Fatalf("wrong child order quantities. wanted %v, got %v", want, placedQtys)
+	}
+	if ice = iceberg(ice.ID); ice.Filled != 2*displayQty || ice.Done || len(ice.Children) != 3 {
+		t.Fatalf("wrong iceberg order state %+v", ice)
 	}
 
-	clearUpgrade()
+	// The total filled never exceeds the quantity.
+	fillChild(ice.ID, dcrBtcLotSize, order.OrderStatusExecuted)
+	tCore.checkIcebergOrders()
+	tCore.checkIcebergOrders()
+	notes := icebergNotes()
+	if len(notes) != 1 || notes[0].Topic() != TopicIcebergCompleted {
+		t.Fatalf("expected a completed note, got %v", notes)
+	}
+	if ice = notes[0].Iceberg; ice.Filled != qty || !ice.Done || len(ice.Child) != 0 || len(placedQtys) != 3 {
+		t.Fatalf("wrong completed iceberg order state %+v", ice)
+	}
+	checkDeleted(ice.ID)
+	if err := tCore.CancelIcebergOrder(ice.ID); !errors.Is(err, db.ErrNoIceberg) {
+		t.Fatalf("wrong error canceling a completed iceberg order: %v", err)
+	}
 
-	// initial success
-	err := tCore.Login(tPW)
+	// A child order that is revoked by the server stops the iceberg order,
+	// with its partial fill accounted for.
+	queueOrder()
+	ice, err = tCore.PlaceIcebergOrder(tPW, form, displayQty)
 	if err != nil {
-		t.Fatalf("initial Login error: %v", err)
+		t.Fatalf("PlaceIcebergOrder error: %v", err)
 	}
+	fillChild(ice.ID, dcrBtcLotSize, order.OrderStatusRevoked)
+	tCore.checkIcebergOrders()
+	notes = icebergNotes()
+	if len(notes) != 1 || notes[0].Topic() != TopicIcebergStopped {
+		t.Fatalf("expected a stopped note, got %v", notes)
+	}
+	if ice = notes[0].Iceberg; ice.Filled != dcrBtcLotSize || !ice.Done || len(placedQtys) != 4 {
+		t.Fatalf("wrong stopped iceberg order state %+v", ice)
+	}
+	checkDeleted(ice.ID)
 
-	clearUpgrade()
-
-	// Recrypt error
-	rig.db.recryptErr = tErr
-	err = tCore.Login(tPW)
-	if err == nil {
-		t.Fatalf("no error for recryptErr")
+	// Canceling an iceberg order cancels the active child order, and no more
+	// child orders are placed.
+	queueOrder()
+	ice, err = tCore.PlaceIcebergOrder(tPW, form, displayQty)
+	if err != nil {
+		t.Fatalf("PlaceIcebergOrder error: %v", err)
+	}
+	fillChild(ice.ID, dcrBtcLotSize, order.OrderStatusBooked)
+	// A failed child cancel leaves the iceberg order active.
+	rig.queueCancel(msgjson.NewError(msgjson.RPCInternalError, "test error"))
+	if err := tCore.CancelIcebergOrder(ice.ID); err == nil {
+		t.Fatalf("no error for a failed child order cancel")
+	}
+	if iceberg(ice.ID).Canceled || rig.db.icebergOrders[ice.ID].Canceled {
+		t.Fatalf("iceberg order canceled after a failed child order cancel")
+	}
+	rig.queueCancel(nil)
+	if err := tCore.CancelIcebergOrder(ice.ID); err != nil {
+		t.Fatalf("CancelIcebergOrder error: %v", err)
+	}
+	if err := tCore.CancelIcebergOrder("nope"); !errors.Is(err, db.ErrNoIceberg) {
+		t.Fatalf("wrong error for unknown iceberg order: %v", err)
+	}
+	if ice = iceberg(ice.ID); !ice.Canceled || ice.Done {
+		t.Fatalf("wrong canceling iceberg order state %+v", ice)
+	}
+	if !rig.db.icebergOrders[ice.ID].Canceled {
+		t.Fatalf("iceberg order cancellation not stored")
+	}
+	fillChild(ice.ID, dcrBtcLotSize, order.OrderStatusCanceled)
+	tCore.checkIcebergOrders()
+	if len(placedQtys) != 5 {
+		t.Fatalf("child order placed for a canceled iceberg order")
+	}
+	checkDeleted(ice.ID)
+	if notes := icebergNotes(); len(notes) != 0 {
+		t.Fatalf("unexpected notes for a canceled iceberg order: %v", notes)
 	}
-	rig.db.recryptErr = nil
 
-	// final success
-	err = tCore.Login(tPW)
+	// A child order placed while the iceberg order is being canceled is
+	// recorded, and must be canceled.
+	queueOrder()
+	ice, err = tCore.PlaceIcebergOrder(tPW, form, displayQty)
 	if err != nil {
-		t.Fatalf("final Login error: %v", err)
+		t.Fatalf("PlaceIcebergOrder error: %v", err)
+	}
+	if _, err := tCore.markIcebergCanceled(ice.ID, true); err != nil {
+		t.Fatalf("markIcebergCanceled error: %v", err)
+	}
+	childID := encode.RandomBytes(32)
+	if !tCore.recordIcebergChild(ice.ID, &Order{ID: childID}, nil) {
+		t.Fatalf("child order of a canceled iceberg order not marked for cancellation")
+	}
+	if ice = iceberg(ice.ID); !bytes.Equal(ice.Child, childID) {
+		t.Fatalf("child order of a canceled iceberg order not recorded")
 	}
-}
 
-func unauth(a *dexAccount) {
-	a.authMtx.Lock()
-	a.isAuthed = false
-	a.authMtx.Unlock()
+	// A child order that cannot be placed stops the iceberg order.
+	if tCore.recordIcebergChild(ice.ID, nil, errors.New("test error")) {
+		t.Fatalf("failed child order marked for cancellation")
+	}
+	notes = icebergNotes()
+	if len(notes) != 1 || notes[0].Topic() != TopicIcebergFailed {
+		t.Fatalf("expected a failed note, got %v", notes)
+	}
+	checkDeleted(ice.ID)
+
+	// Finished iceberg orders stored by an earlier session are deleted when
+	// the iceberg orders are loaded.
+	rig.db.SaveIcebergOrder(&db.IcebergOrder{ID: "done", Done: true})
+	rig.db.SaveIcebergOrder(&db.IcebergOrder{ID: "active", Qty: qty, DisplayQty: displayQty})
+	tCore.icebergsMtx.Lock()
+	tCore.icebergs = nil
+	tCore.icebergsMtx.Unlock()
+	if ices, err := tCore.IcebergOrders(); err != nil || len(ices) != 1 || ices[0].ID != "active" {
+		t.Fatalf("wrong reloaded iceberg orders %+v, err = %v", ices, err)
+	}
+	if _, found := rig.db.icebergOrders["done"]; found {
+		t.Fatalf("finished iceberg order not deleted on load")
+	}
 }
 
-func TestLogin(t *testing.T) {
+func TestBookFeed(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
 	tCore := rig.core
-	rig.acct.rep = account.Reputation{BondedTier: 1}
+	dc := rig.dc
 
-	rig.queueConnect(nil, nil, nil)
-	err := tCore.Login(tPW)
-	if err != nil || !rig.acct.authed() {
-		t.Fatalf("initial Login error: %v", err)
+	checkAction := func(feed BookFeed, action string) {
+		t.Helper()
+		select {
+		case u := <-feed.Next():
+			if u.Action != action {
+				t.Fatalf("expected action = %s, got %s", action, u.Action)
+			}
+		default:
+			t.Fatalf("no %s received", action)
+		}
 	}
 
-	// No encryption key.
-	unauth(rig.acct)
-	creds := tCore.credentials
-	tCore.credentials = nil
-	err = tCore.Login(tPW)
-	if err == nil || rig.acct.authed() {
-		t.Fatalf("no error for missing app key")
+	// Ensure handleOrderBookMsg creates an order book as expected.
+	oid1 := ordertest.RandomOrderID()
+	bookMsg, err := msgjson.NewResponse(1, &msgjson.OrderBook{
+		Seq:      1,
+		MarketID: tDcrBtcMktName,
+		Orders: []*msgjson.BookOrderNote{
+			{
+				TradeNote: msgjson.TradeNote{
+					Side:     msgjson.BuyOrderNum,
+					Quantity: 10,
+					Rate:     2,
+				},
+				OrderNote: msgjson.OrderNote{
+					Seq:      1,
+					MarketID: tDcrBtcMktName,
+					OrderID:  oid1[:],
+				},
+			},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("[NewResponse]: unexpected err: %v", err)
 	}
-	tCore.credentials = creds
 
-	// Account not Paid. No error, and account should be unlocked.
-	rig.acct.rep = account.Reputation{BondedTier: 0}
-	rig.queueConnect(nil, nil, nil)
-	err = tCore.Login(tPW)
-	if err != nil || rig.acct.authed() {
-		t.Fatalf("error for unpaid account: %v", err)
+	oid2 := ordertest.RandomOrderID()
+	bookNote, _ := msgjson.NewNotification(msgjson.BookOrderRoute, &msgjson.BookOrderNote{
+		TradeNote: msgjson.TradeNote{
+			Side:     msgjson.BuyOrderNum,
+			Quantity: 10,
+			Rate:     2,
+		},
+		OrderNote: msgjson.OrderNote{
+			Seq:      2,
+			MarketID: tDcrBtcMktName,
+			OrderID:  oid2[:],
+		},
+	})
+
+	err = handleBookOrderMsg(tCore, dc, bookNote)
+	if err == nil {
+		t.Fatalf("no error for missing book")
 	}
-	if rig.acct.locked() {
-		t.Fatalf("unpaid account is locked")
+
+	// Sync to unknown dex
+	_, _, err = tCore.SyncBook("unknown dex", tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if err == nil {
+		t.Fatalf("no error for unknown dex")
+	}
+	_, _, err = tCore.SyncBook(tDexHost, tUTXOAssetA.ID, 12345)
+	if err == nil {
+		t.Fatalf("no error for nonsense market")
 	}
-	rig.acct.rep = account.Reputation{BondedTier: 1}
 
-	// 'connect' route error.
-	rig = newTestRig()
-	defer rig.shutdown()
-	tCore = rig.core
-	unauth(rig.acct)
-	rig.ws.queueResponse(msgjson.ConnectRoute, func(msg *msgjson.Message, f msgFunc) error {
-		resp, _ := msgjson.NewResponse(msg.ID, nil, msgjson.NewError(1, "test error"))
-		f(resp)
+	// Success
+	rig.ws.queueResponse(msgjson.OrderBookRoute, func(msg *msgjson.Message, f msgFunc) error {
+		f(bookMsg)
 		return nil
 	})
-	err = tCore.Login(tPW)
-	// Should be no error, but also not authed. Error is sent and logged
-	// as a notification.
-	if err != nil || rig.acct.authed() {
-		t.Fatalf("account authed after 'connect' error")
+	_, feed1, err := tCore.SyncBook(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if err != nil {
+		t.Fatalf("SyncBook 1 error: %v", err)
 	}
-
-	// Success with some matches in the response.
-	rig = newTestRig()
-	defer rig.shutdown()
-	dc := rig.dc
-	qty := 3 * dcrBtcLotSize
-	lo, dbOrder, preImg, addr := makeLimitOrder(dc, true, qty, dcrBtcRateStep*10)
-	lo.Force = order.StandingTiF
-	dbOrder.MetaData.Status = order.OrderStatusBooked // leave unfunded to have it canceled on auth/'connect'
-	oid := lo.ID()
-	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
-	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails) // nil means no funding coins
-	matchID := ordertest.RandomMatchID()
-	match := &matchTracker{
-		MetaMatch: db.MetaMatch{
-			UserMatch: &order.UserMatch{MatchID: matchID},
-			MetaData:  &db.MatchMetaData{},
-		},
+	_, feed2, err := tCore.SyncBook(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if err != nil {
+		t.Fatalf("SyncBook 2 error: %v", err)
 	}
-	tracker.matches[matchID] = match
-	knownMsgMatch := &msgjson.Match{OrderID: oid[:], MatchID: matchID[:]}
 
-	// Known trade, but missing match
-	missingID := ordertest.RandomMatchID()
-	missingMatch := &matchTracker{
-		MetaMatch: db.MetaMatch{
-			UserMatch: &order.UserMatch{MatchID: missingID},
-			MetaData:  &db.MatchMetaData{},
-		},
+	// Should be able to retrieve the book now.
+	book, err := tCore.Book(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if err != nil {
+		t.Fatalf("Core.Book error: %v", err)
+	}
+	// Should have one buy order
+	if len(book.Buys) != 1 {
+		t.Fatalf("no buy orders found. expected 1")
 	}
-	tracker.matches[missingID] = missingMatch
 
-	// extra match
-	extraID := ordertest.RandomMatchID()
-	matchTime := time.Now()
-	extraMsgMatch := &msgjson.Match{
-		OrderID:    oid[:],
-		MatchID:    extraID[:],
-		Side:       uint8(order.Taker),
-		Status:     uint8(order.MakerSwapCast),
-		ServerTime: uint64(matchTime.UnixMilli()),
+	// Both channels should have a full orderbook.
+	checkAction(feed1, FreshBookAction)
+	checkAction(feed2, FreshBookAction)
+
+	err = handleBookOrderMsg(tCore, dc, bookNote)
+	if err != nil {
+		t.Fatalf("[handleBookOrderMsg]: unexpected err: %v", err)
 	}
 
-	// The extra match is already at MakerSwapCast, and we're the taker, which
-	// will invoke match status conflict resolution and a contract audit.
-	_, auditInfo := tMsgAudit(oid, extraID, addr, qty, encode.RandomBytes(32))
-	auditInfo.Expiration = encode.DropMilliseconds(matchTime.Add(tracker.lockTimeMaker))
-	tBtcWallet.auditInfo = auditInfo
-	missedContract := encode.RandomBytes(50)
-	rig.ws.queueResponse(msgjson.MatchStatusRoute, func(msg *msgjson.Message, f msgFunc) error {
-		resp, _ := msgjson.NewResponse(msg.ID, []*msgjson.MatchStatusResult{{
-			MatchID:       extraID[:],
-			Status:        uint8(order.MakerSwapCast),
-			MakerContract: missedContract,
-			MakerSwap:     auditInfo.Coin.ID(),
-			Active:        true,
-			MakerTxData:   []byte{0x01},
-		}}, nil)
-		f(resp)
-		return nil
+	// Both channels should have an update.
+	checkAction(feed1, BookOrderAction)
+	checkAction(feed2, BookOrderAction)
+
+	// Close feed 1
+	feed1.Close()
+
+	oid3 := ordertest.RandomOrderID()
+	bookNote, _ = msgjson.NewNotification(msgjson.BookOrderRoute, &msgjson.BookOrderNote{
+		TradeNote: msgjson.TradeNote{
+			Side:     msgjson.SellOrderNum,
+			Quantity: 10,
+			Rate:     3,
+		},
+		OrderNote: msgjson.OrderNote{
+			Seq:      3,
+			MarketID: tDcrBtcMktName,
+			OrderID:  oid3[:],
+		},
 	})
+	err = handleBookOrderMsg(tCore, dc, bookNote)
+	if err != nil {
+		t.Fatalf("[handleBookOrderMsg]: unexpected err: %v", err)
+	}
 
-	dc.trades = map[order.OrderID]*trackedTrade{
-		oid: tracker,
+	// feed1 should have no update
+	select {
+	case <-feed1.Next():
+		t.Fatalf("update for feed 1 after Close")
+	default:
 	}
+	// feed2 should though
+	checkAction(feed2, BookOrderAction)
 
-	tCore = rig.core
-	rig.queueConnect(nil, []*msgjson.Match{knownMsgMatch /* missing missingMatch! */, extraMsgMatch}, nil)
-	rig.queueCancel(nil) // for the unfunded order that gets canceled in authDEX
-	// Login>authDEX will do 4 match DB updates for these two matches:
-	// missing -> revoke -> update match
-	// extra -> negotiate -> newTrackers -> update match
-	// matchConflicts (from extras) -> resolveMatchConflicts -> resolveConflictWithServerData
-	// 	-> update match after spawning auditContract
-	// 	-> update match in auditContract (second because of lock) ** the ASYNC one we have to wait for **
-	rig.db.updateMatchChan = make(chan order.MatchStatus, 4)
-	err = tCore.Login(tPW) // authDEX -> async contract audit for the extra match
-	if err != nil || !rig.acct.authed() {
-		t.Fatalf("final Login error: %v", err)
+	// Make sure the book has been updated.
+	book, _ = tCore.Book(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if len(book.Buys) != 2 {
+		t.Fatalf("expected 2 buys, got %d", len(book.Buys))
 	}
-	// Wait for expected db updates.
-	for i := 0; i < 4; i++ {
-		<-rig.db.updateMatchChan
+	if len(book.Sells) != 1 {
+		t.Fatalf("expected 1 sell, got %d", len(book.Sells))
 	}
 
-	// check t.metaData.LinkedOrder or for db.LinkOrder call, then db.UpdateOrder call
-	if tracker.metaData.LinkedOrder.IsZero() {
-		t.Errorf("cancel order not set")
+	// Update the remaining quantity of the just booked order.
+	var remaining uint64 = 5 * 1e8
+	bookNote, _ = msgjson.NewNotification(msgjson.BookOrderRoute, &msgjson.UpdateRemainingNote{
+		OrderNote: msgjson.OrderNote{
+			Seq:      4,
+			MarketID: tDcrBtcMktName,
+			OrderID:  oid3[:],
+		},
+		Remaining: remaining,
+	})
+	err = handleUpdateRemainingMsg(tCore, dc, bookNote)
+	if err != nil {
+		t.Fatalf("[handleBookOrderMsg]: unexpected err: %v", err)
 	}
-	if rig.db.linkedFromID != oid || rig.db.linkedToID.IsZero() {
-		t.Errorf("automatic cancel order not linked")
+
+	// feed2 should have an update
+	checkAction(feed2, UpdateRemainingAction)
+	book, _ = tCore.Book(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	firstSellQty := book.Sells[0].QtyAtomic
+	if firstSellQty != remaining {
+		t.Fatalf("expected remaining quantity of %d after update_remaining. got %d", remaining, firstSellQty)
 	}
 
-	if !tracker.matches[missingID].MetaData.Proof.SelfRevoked {
-		t.Errorf("SelfRevoked not true for missing match tracker")
+	// Ensure handleUnbookOrderMsg removes a book order from an associated
+	// order book as expected.
+	unbookNote, _ := msgjson.NewNotification(msgjson.UnbookOrderRoute, &msgjson.UnbookOrderNote{
+		Seq:      5,
+		MarketID: tDcrBtcMktName,
+		OrderID:  oid1[:],
+	})
+
+	err = handleUnbookOrderMsg(tCore, dc, unbookNote)
+	if err != nil {
+		t.Fatalf("[handleUnbookOrderMsg]: unexpected err: %v", err)
 	}
-	if tracker.matches[matchID].swapErr != nil {
-		t.Errorf("swapErr set for non-missing match tracker")
+	// feed2 should have a notification.
+	checkAction(feed2, UnbookOrderAction)
+	book, _ = tCore.Book(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if len(book.Buys) != 1 {
+		t.Fatalf("expected 1 buy after unbook_order, got %d", len(book.Buys))
 	}
-	if tracker.matches[matchID].MetaData.Proof.IsRevoked() {
-		t.Errorf("IsRevoked true for non-missing match tracker")
-	}
-	// Conflict resolution will have run negotiate on the extra match from the
-	// connect response, bringing our match count up to 3.
-	if len(tracker.matches) != 3 {
-		t.Errorf("Extra trade not accepted into matches")
+
+	// Test candles
+	queueCandles := func() {
+		rig.ws.queueResponse(msgjson.CandlesRoute, func(msg *msgjson.Message, f msgFunc) error {
+			resp, _ := msgjson.NewResponse(msg.ID, &msgjson.WireCandles{
+				StartStamps:  []uint64{1, 2},
+				EndStamps:    []uint64{3, 4},
+				MatchVolumes: []uint64{1, 2},
+				QuoteVolumes: []uint64{1, 2},
+				HighRates:    []uint64{3, 4},
+				LowRates:     []uint64{1, 2},
+				StartRates:   []uint64{1, 2},
+				EndRates:     []uint64{3, 4},
+			}, nil)
+			f(resp)
+			return nil
+		})
 	}
-	tracker.mtx.Lock()
-	defer tracker.mtx.Unlock()
-	match = tracker.matches[extraID]
-	if !bytes.Equal(match.MetaData.Proof.CounterContract, missedContract) {
-		t.Errorf("Missed maker contract not retrieved, %s, %s", match, hex.EncodeToString(match.MetaData.Proof.CounterContract))
+	queueCandles()
+
+	if err := feed2.Candles("1h"); err != nil {
+		t.Fatalf("Candles error: %v", err)
 	}
-}
 
-func TestAccountNotFoundError(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-	rig.acct.rep = account.Reputation{BondedTier: 1}
+	checkAction(feed2, FreshCandlesAction)
 
-	const expectedErrorMessage = "test account not found error"
-	accountNotFoundError := msgjson.NewError(msgjson.AccountNotFoundError, expectedErrorMessage)
-	rig.queueConnect(accountNotFoundError, nil, nil)
-	rig.queueConnect(accountNotFoundError, nil, nil)
+	// An epoch report should trigger two candle updates, one for each bin size.
+	epochReport, _ := msgjson.NewNotification(msgjson.EpochReportRoute, &msgjson.EpochReportNote{
+		MarketID:     tDcrBtcMktName,
+		Epoch:        1,
+		BaseFeeRate:  2,
+		QuoteFeeRate: 3,
+		Candle: msgjson.Candle{
+			StartStamp:  1,
+			EndStamp:    2,
+			MatchVolume: 3,
+			QuoteVolume: 3,
+			HighRate:    4,
+			LowRate:     1,
+			StartRate:   1,
+			EndRate:     2,
+		},
+	})
 
-	wallet, _ := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = wallet
-	rig.queueConnect(nil, nil, nil)
+	if err := handleEpochReportMsg(tCore, dc, epochReport); err != nil {
+		t.Fatalf("handleEpochReportMsg error: %v", err)
+	}
 
-	feed := tCore.NotificationFeed()
+	checkAction(feed2, EpochMatchSummary)
 
-	tCore.initializeDEXConnections(rig.crypter)
+	// We'll only receive 1 candle update, since we only synced one set of
+	// candles so far.
+	checkAction(feed2, CandleUpdateAction)
+	checkAction(feed2, EpochResolved)
 
-	// Make sure that the connections did not get authenticated
-	for _, dc := range tCore.dexConnections() {
-		if dc.acct.authed() {
-			t.Fatalf("dex connection should not have been authenticated")
-		}
+	// Now subscribe to the 24h candles too.
+	queueCandles()
+	if err := feed2.Candles("24h"); err != nil {
+		t.Fatalf("24h Candles error: %v", err)
 	}
+	checkAction(feed2, FreshCandlesAction)
 
-	// Make sure that an error notification was sent
-	for {
-		select {
-		case note := <-feed.C:
-			if note.Topic() == TopicDexAuthError && strings.Contains(note.Details(), expectedErrorMessage) {
-				return
-			}
-		case <-time.After(1 * time.Second):
-			t.Fatalf("error notification could not be found")
-		}
+	// This time, an epoch report should trigger two updates.
+	if err := handleEpochReportMsg(tCore, dc, epochReport); err != nil {
+		t.Fatalf("handleEpochReportMsg error: %v", err)
 	}
+	checkAction(feed2, EpochMatchSummary)
+	checkAction(feed2, CandleUpdateAction)
+	checkAction(feed2, CandleUpdateAction)
 }
 
-func TestInitializeDEXConnectionsSuccess(t *testing.T) {
+func TestBookFeedPages(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
 	tCore := rig.core
-	rig.acct.rep = account.Reputation{BondedTier: 1}
-	rig.queueConnect(nil, nil, nil)
+	dc := rig.dc
 
-	// Make sure that the connections got authenticated
-	tCore.initializeDEXConnections(rig.crypter)
-	for _, dc := range tCore.dexConnections() {
-		if !dc.acct.authed() {
-			t.Fatalf("dex connection was not authenticated")
+	bookNote := func() *msgjson.BookOrderNote {
+		oid := ordertest.RandomOrderID()
+		return &msgjson.BookOrderNote{
+			TradeNote: msgjson.TradeNote{
+				Side:     msgjson.BuyOrderNum,
+				Quantity: 10,
+				Rate:     2,
+			},
+			OrderNote: msgjson.OrderNote{OrderID: oid[:]},
 		}
 	}
-}
-
-func TestConnectDEX(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-
-	ai := &db.AccountInfo{
-		Host: "somedex.com",
+	pageNote := func(seq uint64, page uint32) *msgjson.Message {
+		note, _ := msgjson.NewNotification(msgjson.OrderBookPageRoute, &msgjson.OrderBookPage{
+			MarketID: tDcrBtcMktName,
+			Seq:      seq,
+			Page:     page,
+			Orders:   []*msgjson.BookOrderNote{bookNote(), bookNote()},
+		})
+		return note
 	}
 
-	_, err := tCore.connectDEX(ai)
-	if err == nil {
-		t.Fatalf("expected error for no TLS plain internet DEX host")
+	// No subscription awaiting the page.
+	if err := handleOrderBookPageMsg(tCore, dc, pageNote(1, 1)); err == nil {
+		t.Fatalf("no error for unexpected page")
 	}
 
-	ai.Host = "somedex13254214214.onion" // not a valid onion host in case we decide to validate them
-	// No onion proxy set => error
-	_, err = tCore.connectDEX(ai)
-	if err == nil {
-		t.Fatalf("expected error with no onion proxy set")
-	}
+	const numPages = 3
+	bookMsg, _ := msgjson.NewResponse(1, &msgjson.OrderBook{
+		Seq:      5,
+		MarketID: tDcrBtcMktName,
+		Orders:   []*msgjson.BookOrderNote{bookNote(), bookNote()},
+		Pages:    numPages,
+	}, nil)
 
-	rig.queueConfig()
-	tCore.cfg.Onion = "127.0.0.1:9050"
-	dc, err := tCore.connectDEX(ai)
+	// The pages may be handled before the response. A page of a stale
+	// snapshot is ignored.
+	rig.ws.queueResponse(msgjson.OrderBookRoute, func(msg *msgjson.Message, f msgFunc) error {
+		for _, note := range []*msgjson.Message{pageNote(5, 2), pageNote(4, 1), pageNote(5, 1)} {
+			if err := handleOrderBookPageMsg(tCore, dc, note); err != nil {
+				t.Fatalf("handleOrderBookPageMsg error: %v", err)
+			}
+		}
+		f(bookMsg)
+		return nil
+	})
+	_, feed, err := tCore.SyncBook(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
 	if err != nil {
-		t.Fatalf("error connecting to onion host with an onion proxy configured: %v", err)
+		t.Fatalf("SyncBook error: %v", err)
 	}
-	dc.connMaster.Disconnect()
-
-	rig.queueConfig()
-	ai.Host = "somedex.com"
-	ai.Cert = []byte{0x1}
-	dc, err = tCore.connectDEX(ai)
+	feed.Close()
+	book, err := tCore.Book(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
 	if err != nil {
-		t.Fatalf("initial connectDEX error: %v", err)
-	}
-	dc.connMaster.Disconnect()
-
-	// Bad URL.
-	ai.Host = tUnparseableHost // Illegal ASCII control character
-	_, err = tCore.connectDEX(ai)
-	if err == nil {
-		t.Fatalf("no error for bad URL")
+		t.Fatalf("Core.Book error: %v", err)
 	}
-	ai.Host = "someotherdex.org"
-
-	// Constructor error.
-	ogConstructor := tCore.wsConstructor
-	tCore.wsConstructor = func(*comms.WsCfg) (comms.WsConn, error) {
-		return nil, tErr
+	if len(book.Buys) != 2*numPages {
+		t.Fatalf("wanted %d buy orders, got %d", 2*numPages, len(book.Buys))
 	}
-	_, err = tCore.connectDEX(ai)
-	if err == nil {
-		t.Fatalf("no error for WsConn constructor error")
+	dc.bookPagesMtx.Lock()
+	awaiting := len(dc.bookPages)
+	dc.bookPagesMtx.Unlock()
+	if awaiting != 0 {
+		t.Fatalf("still collecting pages after subscribing")
 	}
-	tCore.wsConstructor = ogConstructor
 
-	// WsConn.Connect error.
-	rig.ws.connectErr = tErr
-	_, err = tCore.connectDEX(ai)
-	if err == nil {
-		t.Fatalf("no error for WsConn.Connect error")
+	// A missing page times out.
+	sp := dc.awaitBookPages(tDcrBtcMktName)
+	defer dc.stopBookPages(tDcrBtcMktName, sp)
+	if err := handleOrderBookPageMsg(tCore, dc, pageNote(6, 2)); err != nil {
+		t.Fatalf("handleOrderBookPageMsg error: %v", err)
 	}
-
-	rig.ws.connectErr = nil
-
-	// 'config' route error.
-	rig.ws.queueResponse(msgjson.ConfigRoute, func(msg *msgjson.Message, f msgFunc) error {
-		resp, _ := msgjson.NewResponse(msg.ID, nil, msgjson.NewError(1, "test error"))
-		f(resp)
-		return nil
-	})
-	_, err = tCore.connectDEX(ai)
-	if err == nil {
-		t.Fatalf("no error for 'config' route error")
+	snap := &msgjson.OrderBook{Seq: 6, MarketID: tDcrBtcMktName, Pages: numPages}
+	if err := sp.collect(snap, 50*time.Millisecond); err == nil {
+		t.Fatalf("no error for missing page")
 	}
+}
 
-	// Success again.
-	rig.queueConfig()
-	dc, err = tCore.connectDEX(ai)
-	if err != nil {
-		t.Fatalf("final connectDEX error: %v", err)
-	}
-	dc.connMaster.Disconnect()
+type tDriver struct {
+	wallet        asset.Wallet
+	decodedCoinID string
+	winfo         *asset.WalletInfo
+	explorerTxURL string
+	explorerAddr  string
+	explorerOut   string
+}
 
-	// TODO: test temporary, ensure listen isn't running, somehow
+func (drv *tDriver) Open(cfg *asset.WalletConfig, logger dex.Logger, net dex.Network) (asset.Wallet, error) {
+	return drv.wallet, nil
 }
 
-func TestInitializeClient(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
+func (drv *tDriver) DecodeCoinID(coinID []byte) (string, error) {
+	return drv.decodedCoinID, nil
+}
 
-	clearCreds := func() {
-		tCore.credentials = nil
-		rig.db.creds = nil
-	}
+func (drv *tDriver) Info() *asset.WalletInfo {
+	return drv.winfo
+}
 
-	clearCreds()
+func (drv *tDriver) ExplorerURLs(net dex.Network) (txURL, addrURL string) {
+	return drv.explorerTxURL, drv.explorerAddr
+}
 
-	_, err := tCore.InitializeClient(tPW, nil)
-	if err != nil {
-		t.Fatalf("InitializeClient error: %v", err)
-	}
+func (drv *tDriver) ExplorerOutputURL(net dex.Network) string {
+	return drv.explorerOut
+}
 
-	clearCreds()
+type tCreator struct {
+	*tDriver
+	doesntExist  bool
+	existsErr    error
+	createErr    error
+	createParams *asset.CreateWalletParams
+	importErr    error
+}
 
-	// Empty password.
-	emptyPass := []byte("")
-	_, err = tCore.InitializeClient(emptyPass, nil)
-	if err == nil {
-		t.Fatalf("no error for empty password")
-	}
+func (ctr *tCreator) Exists(walletType, dataDir string, settings map[string]string, net dex.Network) (bool, error) {
+	return !ctr.doesntExist, ctr.existsErr
+}
 
-	// Store error. Use a non-empty password to pass empty password check.
-	rig.db.setCredsErr = tErr
-	_, err = tCore.InitializeClient(tPW, nil)
-	if err == nil {
-		t.Fatalf("no error for StoreEncryptedKey error")
-	}
-	rig.db.setCredsErr = nil
+func (ctr *tCreator) Create(params *asset.CreateWalletParams) error {
+	ctr.createParams = params
+	return ctr.createErr
+}
 
-	// Success again
-	_, err = tCore.InitializeClient(tPW, nil)
-	if err != nil {
-		t.Fatalf("final InitializeClient error: %v", err)
-	}
+func (ctr *tCreator) ValidateImport(walletType string, seed []byte) error {
+	return ctr.importErr
 }
 
-func TestSend(t *testing.T) {
+func TestCreateWallet(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
 	tCore := rig.core
-	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = wallet
-	tWallet.sendCoin = &tCoin{id: encode.RandomBytes(36)}
-	address := "addr"
 
-	// Successful
-	coin, err := tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
-	if err != nil {
-		t.Fatalf("Send error: %v", err)
-	}
-	if coin.Value() != 1e8 {
-		t.Fatalf("Expected sent value to be %v, got %v", 1e8, coin.Value())
-	}
+	// Create a new asset.
+	a := *tUTXOAssetA
+	tILT := &a
+	tILT.Symbol = "ilt"
+	tILT.ID, _ = dex.BipSymbolID(tILT.Symbol)
 
-	// 0 value
-	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 0, address, false)
-	if err == nil {
-		t.Fatalf("no error for zero value send")
+	// Create registration form.
+	form := &WalletForm{
+		AssetID: tILT.ID,
+		Config: map[string]string{
+			"rpclisten": "localhost",
+		},
+		Type: "type",
 	}
 
-	// no wallet
-	_, err = tCore.Send(tPW, 12345, 1e8, address, false)
-	if err == nil {
-		t.Fatalf("no error for unknown wallet")
+	ensureErr := func(tag string) {
+		t.Helper()
+		err := tCore.CreateWallet(tPW, wPW, form)
+		if err == nil {
+			t.Fatalf("no %s error", tag)
+		}
 	}
 
-	// connect error
-	wallet.hookedUp = false
+	// Try to add an existing wallet.
+	wallet, tWallet := newTWallet(tILT.ID)
+	tCore.wallets[tILT.ID] = wallet
+	ensureErr("existing wallet")
+	delete(tCore.wallets, tILT.ID)
+
+	// Failure to retrieve encryption key params.
+	creds := tCore.credentials
+	tCore.credentials = nil
+	ensureErr("db.Get")
+	tCore.credentials = creds
+
+	// Crypter error.
+	rig.crypter.(*tCrypter).encryptErr = tErr
+	ensureErr("Encrypt")
+	rig.crypter.(*tCrypter).encryptErr = nil
+
+	// Try an unknown wallet (not yet asset.Register'ed).
+	ensureErr("unregistered asset")
+
+	// Register the asset.
+	asset.Register(tILT.ID, &tDriver{
+		wallet:        wallet.Wallet,
+		decodedCoinID: "ilt-coin",
+		winfo:         tWalletInfo,
+	})
+
+	// Connection error.
 	tWallet.connectErr = tErr
-	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
-	if err == nil {
-		t.Fatalf("no error for wallet connect error")
-	}
+	ensureErr("Connect")
 	tWallet.connectErr = nil
 
-	// Send error
-	tWallet.sendErr = tErr
-	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
-	if err == nil {
-		t.Fatalf("no error for wallet send error")
-	}
-	tWallet.sendErr = nil
+	// Unlock error.
+	tWallet.unlockErr = tErr
+	ensureErr("Unlock")
+	tWallet.unlockErr = nil
 
-	// Check the coin.
-	tWallet.sendCoin = &tCoin{id: []byte{'a'}}
-	coin, err = tCore.Send(tPW, tUTXOAssetA.ID, 3e8, address, false)
+	// Address error.
+	tWallet.addrErr = tErr
+	ensureErr("Address")
+	tWallet.addrErr = nil
+
+	// Balance error.
+	tWallet.balErr = tErr
+	ensureErr("Balance")
+	tWallet.balErr = nil
+
+	// Database error.
+	rig.db.updateWalletErr = tErr
+	ensureErr("db.UpdateWallet")
+	rig.db.updateWalletErr = nil
+
+	// Success
+	delete(tCore.wallets, tILT.ID)
+	err := tCore.CreateWallet(tPW, wPW, form)
 	if err != nil {
-		t.Fatalf("coin check error: %v", err)
+		t.Fatalf("error when should be no error: %v", err)
 	}
-	coinID := coin.ID()
-	if len(coinID) != 1 || coinID[0] != 'a' {
-		t.Fatalf("coin ID not propagated")
+}
+
+func TestCreateWalletImport(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	assetID, _ := dex.BipSymbolID("ltc")
+	wallet, _ := newTWallet(assetID)
+	walletDef := &asset.WalletDefinition{
+		Type:   "type",
+		Seeded: true,
 	}
-	if coin.Value() != 3e8 {
-		t.Fatalf("Expected sent value to be %v, got %v", 3e8, coin.Value())
+	winfo := *tWalletInfo
+	winfo.AvailableWallets = []*asset.WalletDefinition{walletDef}
+	assetDriver := &tCreator{
+		tDriver: &tDriver{
+			wallet: wallet.Wallet,
+			winfo:  &winfo,
+		},
+		doesntExist: true,
 	}
+	asset.Register(assetID, assetDriver)
 
-	// So far, the fee suggestion should have always been zero.
-	if tWallet.sendFeeSuggestion != 0 {
-		t.Fatalf("unexpected non-zero fee rate when no books or responses prepared")
+	importSeed := encode.RandomBytes(32)
+	form := &WalletForm{
+		AssetID:    assetID,
+		Type:       "type",
+		ImportSeed: importSeed,
 	}
 
-	const feeRate = 54321
+	// Invalid seed.
+	assetDriver.importErr = tErr
+	err := tCore.CreateWallet(tPW, nil, form)
+	if !errorHasCode(err, createWalletErr) {
+		t.Fatalf("wrong error for invalid import seed: %v", err)
+	}
+	assetDriver.importErr = nil
 
-	feeRater := &TFeeRater{
-		TXCWallet: tWallet,
-		feeRate:   feeRate,
+	// Import into a non-seeded wallet.
+	walletDef.Seeded = false
+	err = tCore.CreateWallet(tPW, wPW, form)
+	if !errorHasCode(err, createWalletErr) {
+		t.Fatalf("wrong error for import into non-seeded wallet: %v", err)
 	}
+	walletDef.Seeded = true
 
-	wallet.Wallet = feeRater
+	// Success.
+	form.Config = nil
+	if err = tCore.CreateWallet(tPW, nil, form); err != nil {
+		t.Fatalf("CreateWallet error: %v", err)
+	}
+	params := assetDriver.createParams
+	if params == nil || !params.Imported || !bytes.Equal(params.Seed, importSeed) || params.Birthday != 0 {
+		t.Fatalf("wallet not created with the imported seed: %+v", params)
+	}
+	if rig.db.wallet.Settings[importedSeedSetting] != "true" {
+		t.Fatalf("imported seed setting not stored")
+	}
+	xcWallet, found := tCore.wallet(assetID)
+	if !found || !xcWallet.importedSeed {
+		t.Fatalf("wallet not marked as imported")
+	}
 
-	coin, err = tCore.Send(tPW, tUTXOAssetA.ID, 2e8, address, false)
-	if err != nil {
-		t.Fatalf("FeeRater Withdraw/send error: %v", err)
+	// An imported wallet cannot be restored from the app seed.
+	if _, err = tCore.WalletRestorationInfo(tPW, assetID); err == nil {
+		t.Fatalf("no error for restoration info of an imported wallet")
 	}
-	if coin.Value() != 2e8 {
-		t.Fatalf("Expected sent value to be %v, got %v", 2e8, coin.Value())
+	// A seed cannot be imported when reconfiguring.
+	if err = tCore.ReconfigureWallet(tPW, nil, form); err == nil {
+		t.Fatalf("no error for importing a seed on reconfiguration")
 	}
+}
 
-	if tWallet.sendFeeSuggestion != feeRate {
-		t.Fatalf("unexpected fee rate from FeeRater. wanted %d, got %d", feeRate, tWallet.sendFeeSuggestion)
+func TestWalletSettingsExportImport(t *testing.T) {
+	// Register two assets with a secret setting and a required setting.
+	walletDef := &asset.WalletDefinition{
+		Type: "rpc",
+		ConfigOpts: []*asset.ConfigOption{
+			{Key: "rpcuser"},
+			{Key: "rpcpassword", NoEcho: true},
+			{Key: "rpcbind", Required: true},
+		},
+	}
+	winfo := *tWalletInfo
+	winfo.AvailableWallets = []*asset.WalletDefinition{walletDef}
+	var assetIDs []uint32
+	for _, symbol := range []string{"doge", "bch"} {
+		assetID, _ := dex.BipSymbolID(symbol)
+		wallet, _ := newTWallet(assetID)
+		asset.Register(assetID, &tDriver{
+			wallet: wallet.Wallet,
+			winfo:  &winfo,
+		})
+		assetIDs = append(assetIDs, assetID)
 	}
+	sort.Slice(assetIDs, func(i, j int) bool { return assetIDs[i] < assetIDs[j] })
 
-	// wallet is not synced
-	wallet.syncStatus.Synced = false
-	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
-	if err == nil {
-		t.Fatalf("Expected error for a non-synchronized wallet")
+	newRig := func() *testRig {
+		rig := newTestRig()
+		rig.core.wallets = make(map[uint32]*xcWallet)
+		rig.db.walletsByID = make(map[string]*db.Wallet)
+		return rig
+	}
+	settings := func(assetID uint32) map[string]string {
+		return map[string]string{
+			"rpcuser":     "user" + strconv.Itoa(int(assetID)),
+			"rpcpassword": "pass" + strconv.Itoa(int(assetID)),
+			"rpcbind":     "127.0.0.1:" + strconv.Itoa(int(assetID)),
+		}
 	}
-}
 
-func trade(t *testing.T, async bool) {
-	rig := newTestRig()
+	rig := newRig()
 	defer rig.shutdown()
 	tCore := rig.core
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	dcrWallet.hookedUp = false
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
-	dcrWallet.Unlock(rig.crypter)
-	_ = dcrWallet.Connect() // connector will panic on Wait, and sync status goroutines will exit if disconnected
-	defer dcrWallet.Disconnect()
-	syncTickerPeriod = 10 * time.Millisecond
+	for _, assetID := range assetIDs {
+		form := &WalletForm{AssetID: assetID, Type: "rpc", Config: settings(assetID)}
+		if err := tCore.CreateWallet(tPW, wPW, form); err != nil {
+			t.Fatalf("CreateWallet error: %v", err)
+		}
+	}
 
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
-	btcWallet.Unlock(rig.crypter)
+	// Bad password.
+	rig.crypter.(*tCrypter).recryptErr = tErr
+	_, err := tCore.ExportWalletSettings(tPW, nil)
+	if !errorHasCode(err, passwordErr) {
+		t.Fatalf("wrong error for bad password: %v", err)
+	}
+	rig.crypter.(*tCrypter).recryptErr = nil
 
-	ethWallet, tEthWallet := newTAccountLocker(tACCTAsset.ID)
-	tCore.wallets[tACCTAsset.ID] = ethWallet
-	ethWallet.address = "18d65fb8d60c1199bb1ad381be47aa692b482605"
-	ethWallet.Unlock(rig.crypter)
+	// Unknown wallet.
+	unknownID := uint32(12345)
+	if _, err = tCore.ExportWalletSettings(tPW, &unknownID); !errorHasCode(err, missingWalletErr) {
+		t.Fatalf("wrong error for unknown wallet: %v", err)
+	}
 
-	var lots uint64 = 10
-	qty := dcrBtcLotSize * lots
-	rate := dcrBtcRateStep * 1000
+	exp, err := tCore.ExportWalletSettings(tPW, nil)
+	if err != nil {
+		t.Fatalf("ExportWalletSettings error: %v", err)
+	}
+	if len(exp.Wallets) != len(assetIDs) {
+		t.Fatalf("expected %d exported wallets, got %d", len(assetIDs), len(exp.Wallets))
+	}
+	for i, ew := range exp.Wallets {
+		if ew.AssetID != assetIDs[i] || ew.Type != "rpc" {
+			t.Fatalf("wrong exported wallet %+v", ew)
+		}
+		if _, found := ew.Settings["rpcpassword"]; found {
+			t.Fatalf("secret setting exported in plain text")
+		}
+		if ew.Settings["rpcbind"] != settings(ew.AssetID)["rpcbind"] || len(ew.Secrets) == 0 {
+			t.Fatalf("wrong exported settings %+v", ew)
+		}
+	}
 
-	form := &TradeForm{
-		Host:    tDexHost,
-		IsLimit: true,
-		Sell:    true,
-		Base:    tUTXOAssetA.ID,
-		Quote:   tUTXOAssetB.ID,
-		Qty:     qty,
-		Rate:    rate,
-		TifNow:  false,
+	// The export is JSON-encoded for transfer.
+	b, err := json.Marshal(exp)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	exp = new(WalletSettingsExport)
+	if err = json.Unmarshal(b, exp); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
 	}
 
-	dcrCoin := &tCoin{
-		id:  encode.RandomBytes(36),
-		val: qty * 2,
+	// Import into a new client.
+	rig2 := newRig()
+	defer rig2.shutdown()
+	tCore2 := rig2.core
+
+	// Bad export password.
+	rig2.crypter.(*tCrypter).decryptErr = tErr
+	if err = tCore2.ImportWalletSettings(tPW, nil, exp, false); !errorHasCode(err, passwordErr) {
+		t.Fatalf("wrong error for bad export password: %v", err)
 	}
-	tDcrWallet.fundingCoins = asset.Coins{dcrCoin}
-	tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
+	rig2.crypter.(*tCrypter).decryptErr = nil
 
-	btcVal := calc.BaseToQuote(rate, qty*2)
-	btcCoin := &tCoin{
-		id:  encode.RandomBytes(36),
-		val: btcVal,
+	// Settings that don't match the schema.
+	bind := exp.Wallets[1].Settings["rpcbind"]
+	delete(exp.Wallets[1].Settings, "rpcbind")
+	if err = tCore2.ImportWalletSettings(tPW, nil, exp, false); !errorHasCode(err, createWalletErr) {
+		t.Fatalf("wrong error for missing required setting: %v", err)
+	}
+	exp.Wallets[1].Settings["rpcbind"] = bind
+	if len(tCore2.wallets) != 0 {
+		t.Fatalf("wallets created from invalid settings")
 	}
-	tBtcWallet.fundingCoins = asset.Coins{btcCoin}
-	tBtcWallet.fundRedeemScripts = []dex.Bytes{nil}
 
-	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
-	rig.dc.books[tDcrBtcMktName] = book
+	// Unknown settings, e.g. from an older wallet version, are ignored.
+	exp.Wallets[0].Settings["unknown"] = "setting"
 
-	msgOrderNote := &msgjson.BookOrderNote{
-		OrderNote: msgjson.OrderNote{
-			OrderID: encode.RandomBytes(32),
-		},
-		TradeNote: msgjson.TradeNote{
-			Side:     msgjson.SellOrderNum,
-			Quantity: dcrBtcLotSize,
-			Time:     uint64(time.Now().Unix()),
-			Rate:     rate,
-		},
+	if err = tCore2.ImportWalletSettings(tPW, nil, exp, false); err != nil {
+		t.Fatalf("ImportWalletSettings error: %v", err)
+	}
+	for _, assetID := range assetIDs {
+		w, found := tCore2.wallet(assetID)
+		if !found {
+			t.Fatalf("%s wallet not imported", unbip(assetID))
+		}
+		if !bytes.Equal(w.encPW(), wPW) { // tCrypter doesn't encrypt
+			t.Fatalf("wrong imported wallet password")
+		}
+		got, err := tCore2.WalletSettings(assetID)
+		if err != nil {
+			t.Fatalf("WalletSettings error: %v", err)
+		}
+		if !reflect.DeepEqual(got, settings(assetID)) {
+			t.Fatalf("wrong imported settings %v, expected %v", got, settings(assetID))
+		}
 	}
 
-	err := book.Sync(&msgjson.OrderBook{
-		MarketID: tDcrBtcMktName,
-		Seq:      1,
-		Epoch:    1,
-		Orders:   []*msgjson.BookOrderNote{msgOrderNote},
-	})
+	// Existing wallets are not overwritten without confirmation.
+	exp, err = tCore.ExportWalletSettings(tPW, &assetIDs[0])
 	if err != nil {
-		t.Fatalf("order book sync error: %v", err)
+		t.Fatalf("ExportWalletSettings error: %v", err)
 	}
-
-	badSig := false
-	noID := false
-	badID := false
-	handleLimit := func(msg *msgjson.Message, f msgFunc) error {
-		t.Helper()
-		// Need to stamp and sign the message with the server's key.
-		msgOrder := new(msgjson.LimitOrder)
-		err := msg.Unmarshal(msgOrder)
-		if err != nil {
-			t.Fatalf("unmarshal error: %v", err)
-		}
-		lo := convertMsgLimitOrder(msgOrder)
-		f(orderResponse(msg.ID, msgOrder, lo, badSig, noID, badID))
-		return nil
+	if len(exp.Wallets) != 1 || exp.Wallets[0].AssetID != assetIDs[0] {
+		t.Fatalf("wrong single wallet export %+v", exp.Wallets)
 	}
-
-	handleMarket := func(msg *msgjson.Message, f msgFunc) error {
-		t.Helper()
-		// Need to stamp and sign the message with the server's key.
-		msgOrder := new(msgjson.MarketOrder)
-		err := msg.Unmarshal(msgOrder)
-		if err != nil {
-			t.Fatalf("unmarshal error: %v", err)
-		}
-		mo := convertMsgMarketOrder(msgOrder)
-		f(orderResponse(msg.ID, msgOrder, mo, badSig, noID, badID))
-		return nil
+	exp.Wallets[0].Settings["rpcuser"] = "newuser"
+	if err = tCore2.ImportWalletSettings(tPW, nil, exp, false); !errorHasCode(err, createWalletErr) {
+		t.Fatalf("wrong error for existing wallet: %v", err)
 	}
-
-	ch := tCore.NotificationFeed() // detect when sync goroutine completes
-	waitForOrderNotification := func() (*Order, uint64, error) {
-		var corder *Order
-		var tempID uint64
-	wait:
-		for {
-			select {
-			case note := <-ch.C:
-				if note.Type() == NoteTypeOrder {
-					n, ok := note.(*OrderNote)
-					if !ok {
-						t.Fatalf("Expected OrderNote type, got %T", note)
-					}
-					if note.Topic() == TopicAsyncOrderSubmitted {
-						tempID = n.TemporaryID
-					} else if tempID == n.TemporaryID && note.Topic() == TopicAsyncOrderFailure {
-						return nil, tempID, fmt.Errorf("%v", note.Details())
-					} else {
-						corder = n.Order
-						break wait
-					}
-				}
-			case <-time.After(1 * time.Second):
-				t.Fatal("Failed to receive queued order note")
-			}
-		}
-		return corder, tempID, nil
+	if got, _ := tCore2.WalletSettings(assetIDs[0]); got["rpcuser"] == "newuser" {
+		t.Fatalf("existing wallet overwritten without confirmation")
 	}
+	if err = tCore2.ImportWalletSettings(tPW, nil, exp, true); err != nil {
+		t.Fatalf("ImportWalletSettings error with overwrite: %v", err)
+	}
+	if got, _ := tCore2.WalletSettings(assetIDs[0]); got["rpcuser"] != "newuser" {
+		t.Fatalf("existing wallet not overwritten with confirmation")
+	}
+}
 
-	trade := func() (*Order, error) {
-		if !async {
-			return tCore.Trade(tPW, form)
-		}
-
-		inFlight, err := tCore.TradeAsync(tPW, form)
-		if err != nil {
-			return nil, err
-		}
-
-		corder, tempID, err := waitForOrderNotification()
-		if err != nil {
-			return nil, err
-		}
-
-		if inFlight.TemporaryID != tempID {
-			t.Fatalf("received wrong in-flight order, expected %d got %d", inFlight.TemporaryID, tempID)
-		}
+// TODO: TestGetDEXConfig
+/*
+func TestGetFee(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	cert := []byte{}
 
-		return corder, nil
+	// DEX already registered
+	_, err := tCore.GetFee(tDexHost, cert)
+	if !errorHasCode(err, dupeDEXErr) {
+		t.Fatalf("wrong account exists error: %v", err)
 	}
 
-	ensureOrderErr := func(tag string, waitForErr bool) {
-		t.Helper()
-		var err error
-		if async {
-			_, err = tCore.TradeAsync(tPW, form)
-		} else {
-			_, err = tCore.Trade(tPW, form)
-		}
-		if !waitForErr && err == nil {
-			t.Fatalf("%s: no error", tag)
-		}
+	// Lose the dexConnection
+	tCore.connMtx.Lock()
+	delete(tCore.conns, tDexHost)
+	tCore.connMtx.Unlock()
 
-		if waitForErr {
-			_, _, err := waitForOrderNotification()
-			if err == nil {
-				t.Fatalf("%s: no error for queued order", tag)
-			}
-		}
+	// connectDEX error
+	_, err = tCore.GetFee(tUnparseableHost, cert)
+	if !errorHasCode(err, addressParseErr) {
+		t.Fatalf("wrong connectDEX error: %v", err)
 	}
 
-	ensureErr := func(tag string) {
-		t.Helper()
-		ensureOrderErr(tag, false)
-	}
+	// Queue a config response for success
+	rig.queueConfig()
 
-	// Initial success
-	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
-	corder, err := trade()
+	// Success
+	_, err = tCore.GetFee(tDexHost, cert)
 	if err != nil {
-		t.Fatalf("limit order error: %v", err)
+		t.Fatalf("GetFee error: %v", err)
 	}
-	t.Logf("Order with ID(%s) has been placed successfully!", corder.ID.String())
+}
+*/
 
-	// Check that the Fund request for a limit sell came through and that
-	// value was not adjusted internally with BaseToQuote.
-	if tDcrWallet.fundedVal != qty {
-		t.Fatalf("limit sell expected funded value %d, got %d", qty, tDcrWallet.fundedVal)
-	}
-	tDcrWallet.fundedVal = 0
-	if tDcrWallet.fundedSwaps != lots {
-		t.Fatalf("limit sell expected %d max swaps, got %d", lots, tDcrWallet.fundedSwaps)
+func TestPostBond(t *testing.T) {
+	// This test takes a little longer because the key is decrypted every time
+	// Register is called.
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+	clearConn := func() {
+		tCore.connMtx.Lock()
+		delete(tCore.conns, tDexHost)
+		tCore.connMtx.Unlock()
 	}
-	tDcrWallet.fundedSwaps = 0
+	clearConn()
 
-	// Should not be able to close wallet now, since there are orders.
-	if tCore.CloseWallet(tUTXOAssetA.ID) == nil {
-		t.Fatalf("no error for closing DCR wallet with active orders")
-	}
-	if tCore.CloseWallet(tUTXOAssetB.ID) == nil {
-		t.Fatalf("no error for closing BTC wallet with active orders")
+	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
+	tWallet.bal = &asset.Balance{
+		Available: 4e9,
 	}
 
-	// Should not be able to disable wallet, since there are active orders.
-	if tCore.ToggleWalletStatus(tUTXOAssetA.ID, true) == nil {
-		t.Fatalf("no error for disabling DCR wallet with active orders")
-	}
-	if tCore.ToggleWalletStatus(tUTXOAssetB.ID, true) == nil {
-		t.Fatalf("no error for disabling BTC wallet with active orders")
-	}
+	// When registering, successfully retrieving *db.AccountInfo from the DB is
+	// an error (no dupes). Initial state is to return an error.
+	rig.db.acctErr = tErr
 
-	// We want to set peerCount to 0 (from 1), but we'll do this the hard way to
-	// ensure the peerChange handler works as intended.
-	// dcrWallet.mtx.Lock()
-	// dcrWallet.peerCount = 0
-	// dcrWallet.mtx.Unlock()
-	tCore.peerChange(dcrWallet, 0, nil)
-	_, err = tCore.Trade(tPW, form)
-	if err == nil {
-		t.Fatalf("no error for no peers")
-	}
-	tCore.peerChange(dcrWallet, 1, nil)
+	_ = tCore.Login(tPW)
 
-	// Dex not found
-	form.Host = "someotherdex.org"
-	_, err = tCore.Trade(tPW, form)
-	if err == nil {
-		t.Fatalf("no error for unknown dex")
+	// (*Core).Register does setupCryptoV2 to make the dc.acct.privKey etc., so
+	// we don't know the ClientPubKey here. It must be set in the request
+	// handler configured by queueRegister.
+	rig.ws.liveBondExpiry = uint64(time.Now().Add(time.Duration(pendingBuffer(dex.Simnet)) * 2 * time.Second).Unix())
+	postBondResult := &msgjson.PostBondResult{
+		AccountID:  rig.acct.id[:],
+		AssetID:    dcrBondAsset.ID,
+		Amount:     dcrBondAsset.Amt,
+		Expiry:     rig.ws.liveBondExpiry,
+		Reputation: &account.Reputation{BondedTier: 1},
 	}
-	form.Host = tDexHost
 
-	// Account locked = probably not logged in
-	rig.dc.acct.lock()
-	_, err = tCore.Trade(tPW, form)
-	if err == nil {
-		t.Fatalf("no error for disconnected dex")
-	}
-	rig.dc.acct.unlock(rig.crypter)
+	var wg sync.WaitGroup
+	defer wg.Wait() // don't allow fail after TestRegister return
 
-	// DEX not connected
-	atomic.StoreUint32(&rig.dc.connectionStatus, uint32(comms.Disconnected))
-	_, err = tCore.Trade(tPW, form)
-	if err == nil {
-		t.Fatalf("no error for disconnected dex")
-	}
-	atomic.StoreUint32(&rig.dc.connectionStatus, uint32(comms.Connected))
+	queueTipChange := func() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timeout := time.NewTimer(time.Second * 2)
+			defer timeout.Stop()
+			ticker := time.NewTicker(10 * time.Millisecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					tCore.waiterMtx.Lock()
+					waiterCount := len(tCore.blockWaiters)
+					tCore.waiterMtx.Unlock()
 
-	setWalletSyncStatus := func(w *xcWallet, status bool) {
-		w.mtx.Lock()
-		w.syncStatus.Synced = status
-		w.mtx.Unlock()
+					// Every tick, increase the bond tx confirmation count.
+					if waiterCount > 0 { // when verifyRegistrationFee adds a waiter, then we can trigger tip change
+						confs, found := tWallet.confs[dex.Bytes(tWallet.bondTxCoinID).String()]
+						if !found {
+							tWallet.setConfs(tWallet.bondTxCoinID, 0, nil)
+						} else {
+							tWallet.setConfs(tWallet.bondTxCoinID, confs+1, nil)
+						}
+
+						tCore.tipChange(tUTXOAssetA.ID)
+						return
+					}
+				case <-timeout.C:
+					t.Errorf("failed to find waiter before timeout")
+					return
+				}
+			}
+		}()
 	}
 
-	// No base asset
-	form.Base = 12345
-	ensureErr("bad base asset")
-	form.Base = tUTXOAssetA.ID
+	accountNotFoundError := msgjson.NewError(msgjson.AccountNotFoundError, "test account not found error")
 
-	// No quote asset
-	form.Quote = 12345
-	ensureErr("bad quote asset")
-	form.Quote = tUTXOAssetB.ID
-
-	// Limit order zero rate
-	form.Rate = 0
-	ensureErr("zero rate limit")
-	form.Rate = rate
-
-	// No from wallet
-	tCore.walletMtx.Lock()
-	delete(tCore.wallets, tUTXOAssetA.ID)
-	tCore.walletMtx.Unlock()
-	ensureErr("no dcr wallet")
-	tCore.walletMtx.Lock()
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	tCore.walletMtx.Unlock()
-
-	// No to wallet
-	tCore.walletMtx.Lock()
-	delete(tCore.wallets, tUTXOAssetB.ID)
-	tCore.walletMtx.Unlock()
-	ensureErr("no btc wallet")
-	tCore.walletMtx.Lock()
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	tCore.walletMtx.Unlock()
-
-	// Address error
-	tBtcWallet.addrErr = tErr
-	ensureErr("address error")
-	tBtcWallet.addrErr = nil
-
-	// Not enough funds
-	tDcrWallet.fundingCoinErr = tErr
-	ensureErr("funds error")
-	tDcrWallet.fundingCoinErr = nil
-
-	// Lot size violation
-	ogQty := form.Qty
-	form.Qty += dcrBtcLotSize / 2
-	ensureErr("bad size")
-	form.Qty = ogQty
+	queueConfigAndConnectUnknownAcct := func() {
+		rig.ws.submittedBond = nil
+		rig.queueConfig()
+		rig.queueConnect(accountNotFoundError, nil, nil) // for discoverAccount
+		rig.ws.queueResponse(msgjson.FeeRateRoute, func(msg *msgjson.Message, f msgFunc) error {
+			const feeRate = 50
+			resp, _ := msgjson.NewResponse(msg.ID, feeRate, nil)
+			f(resp)
+			return nil
+		})
+	}
 
-	// Coin signature error
-	tDcrWallet.signCoinErr = tErr
-	ensureErr("signature error")
-	tDcrWallet.signCoinErr = nil
+	queuePostBondSequence := func() {
+		rig.queuePrevalidateBond()
+		rig.queuePostBond(postBondResult)
+		queueTipChange()
+		rig.queueConnect(nil, nil, nil)
+	}
 
-	// Sync-in-progress error
-	setWalletSyncStatus(dcrWallet, false)
-	ensureErr("base not synced")
-	setWalletSyncStatus(dcrWallet, true)
+	queueResponses := func() {
+		queueConfigAndConnectUnknownAcct()
+		queuePostBondSequence()
+	}
 
-	setWalletSyncStatus(btcWallet, false)
-	ensureErr("quote not synced")
-	setWalletSyncStatus(btcWallet, true)
+	form := &PostBondForm{
+		Addr:    tDexHost,
+		AppPass: tPW,
+		Asset:   &dcrBondAsset.ID,
+		Bond:    dcrBondAsset.Amt,
+		Cert:    []byte{0x1}, // not empty signals TLS, otherwise no TLS allowed hidden services
+	}
 
-	// LimitRoute error
-	rig.ws.reqErr = tErr
-	ensureOrderErr("Request error", async)
-	rig.ws.reqErr = nil
+	// Suppress warnings about SendTransaction returning a mismatching ID.
+	tWallet.feeCoinSent = tWallet.bondTxCoinID
 
-	// The rest need a queued handler
+	ch := tCore.NotificationFeed()
 
-	// Bad signature
-	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
-	badSig = true
-	ensureOrderErr("bad server sig", async)
-	badSig = false
+	var err error
+	run := func() {
+		// Register method will error if url is already in conns map.
+		clearConn()
 
-	// No order ID in response
-	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
-	noID = true
-	ensureOrderErr("no ID", async)
-	noID = false
+		tWallet.setConfs(tWallet.bondTxCoinID, 0, nil)
+		// Skip finding bonds.
+		tWallet.findBondErr = errors.New("purposeful error")
+		_, err = tCore.PostBond(form)
+	}
 
-	// Wrong order ID in response
-	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
-	badID = true
-	ensureOrderErr("no ID", async)
-	badID = false
+	getNotification := func(tag string) any {
+		t.Helper()
+		select {
+		case n := <-ch.C:
+			return n
+			// When it works, it should be virtually instant, but I have seen it fail
+			// at 1 millisecond.
+		case <-time.NewTimer(time.Second * 2).C:
+			t.Fatalf("timed out waiting for %s notification", tag)
+		}
+		return nil
+	}
 
-	// Storage failure
-	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
-	rig.db.updateOrderErr = tErr
-	ensureOrderErr("db failure", async)
-	rig.db.updateOrderErr = nil
+	// The feepayment note for mined fee payment txn notification to server, and
+	// the balance note from tip change are concurrent and thus come in no
+	// guaranteed order.
+	getBondAndBalanceNote := func() {
+		t.Helper()
+		var bondNote *BondPostNote
+		var balanceNotes uint8
+		// For a normal PostBond, there are three balance updates.
+		// 1) makeAndPostBond, 2) monitorBondConfs.trigger, and 3) tipChange.
+		for bondNote == nil || balanceNotes < 3 {
+			ntfn := getNotification("bond posted or balance")
+			switch note := ntfn.(type) {
+			case *BondPostNote:
+				if note.TopicID == TopicAccountRegistered {
+					bondNote = note
+				}
+			case *BalanceNote:
+				balanceNotes++
+			case *ReputationNote: // ignore
+			default:
+				t.Fatalf("wrong notification (%T). Expected FeePaymentNote or BalanceNote", ntfn)
+			}
+		}
+	}
 
-	// Success when buying.
-	form.Sell = false
-	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
-	corder, err = trade()
+	queueResponses()
+	run()
 	if err != nil {
-		t.Fatalf("limit order error: %v", err)
+		t.Fatalf("postbond error: %v", err)
 	}
-	t.Logf("Order with ID(%s) has been placed successfully!", corder.ID.String())
 
-	// Check that the Fund request for a limit buy came through to the BTC wallet
-	// and that the value was adjusted internally with BaseToQuote.
-	expQty := calc.BaseToQuote(rate, qty)
-	if tBtcWallet.fundedVal != expQty {
-		t.Fatalf("limit buy expected funded value %d, got %d", expQty, tBtcWallet.fundedVal)
-	}
-	tBtcWallet.fundedVal = 0
-	// The number of lots should still be the same as for a sell order.
-	if tBtcWallet.fundedSwaps != lots {
-		t.Fatalf("limit buy expected %d max swaps, got %d", lots, tBtcWallet.fundedSwaps)
+	// Should be two success notifications. One for fee paid on-chain, one for
+	// fee notification sent, each along with a balance note.
+	getBondAndBalanceNote()
+
+	// password error
+	rig.crypter.(*tCrypter).recryptErr = tErr
+	run()
+	if !errorHasCode(err, passwordErr) {
+		t.Fatalf("wrong password error: %v", err)
 	}
-	tBtcWallet.fundedSwaps = 0
+	rig.crypter.(*tCrypter).recryptErr = nil
 
-	// Successful market buy order
-	form.IsLimit = false
-	form.Qty = calc.BaseToQuote(rate, qty)
-	rig.ws.queueResponse(msgjson.MarketRoute, handleMarket)
-	corder, err = trade()
-	if err != nil {
-		t.Fatalf("market order error: %v", err)
+	// no host error
+	form.Addr = ""
+	run()
+	if !errorHasCode(err, emptyHostErr) {
+		t.Fatalf("wrong empty host error: %v", err)
 	}
-	t.Logf("Order with ID(%s) has been placed successfully!", corder.ID.String())
+	form.Addr = tDexHost
 
-	// The funded qty for a market buy should not be adjusted.
-	if tBtcWallet.fundedVal != form.Qty {
-		t.Fatalf("market buy expected funded value %d, got %d", qty, tBtcWallet.fundedVal)
+	// wallet not found
+	delete(tCore.wallets, tUTXOAssetA.ID)
+	run()
+	if !errorHasCode(err, missingWalletErr) {
+		t.Fatalf("wrong missing wallet error: %v", err)
 	}
-	tBtcWallet.fundedVal = 0
-	if tBtcWallet.fundedSwaps != lots {
-		t.Fatalf("market buy expected %d max swaps, got %d", lots, tBtcWallet.fundedSwaps)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
+
+	// Unlock wallet error
+	tWallet.unlockErr = tErr
+	tWallet.locked = true
+	run()
+	if !errorHasCode(err, walletAuthErr) {
+		t.Fatalf("wrong wallet auth error: %v", err)
 	}
-	tBtcWallet.fundedSwaps = 0
+	tWallet.unlockErr = nil
+	tWallet.locked = false
 
-	// Successful market sell order.
-	form.Sell = true
-	form.Qty = qty
-	rig.ws.queueResponse(msgjson.MarketRoute, handleMarket)
-	corder, err = trade()
-	if err != nil {
-		t.Fatalf("market order error: %v", err)
+	// connectDEX error
+	form.Addr = tUnparseableHost
+	run()
+	if !errorHasCode(err, connectionErr) {
+		t.Fatalf("wrong connectDEX error: %v", err)
 	}
-	t.Logf("Order with ID(%s) has been placed successfully!", corder.ID.String())
+	form.Addr = tDexHost
 
-	// The funded qty for a market sell order should not be adjusted.
-	if tDcrWallet.fundedVal != qty {
-		t.Fatalf("market sell expected funded value %d, got %d", qty, tDcrWallet.fundedVal)
+	// fee asset not found, no cfg.Fee fallback
+	bondAssets := dc.cfg.BondAssets
+	dc.cfg.BondAssets = nil
+	queueConfigAndConnectUnknownAcct()
+	run()
+	if !errorHasCode(err, assetSupportErr) {
+		t.Fatalf("wrong error for missing asset: %v", err)
 	}
-	if tDcrWallet.fundedSwaps != lots {
-		t.Fatalf("market sell expected %d max swaps, got %d", lots, tDcrWallet.fundedSwaps)
+	dc.cfg.BondAssets = bondAssets
+
+	// error creating signing key
+	rig.crypter.(*tCrypter).encryptErr = tErr
+	rig.queueConfig()
+	run()
+	if !errorHasCode(err, acctKeyErr) {
+		t.Fatalf("wrong account key error: %v", err)
 	}
+	rig.crypter.(*tCrypter).encryptErr = nil
 
-	// Selling to an account-based quote asset.
-	const reserveN = 50
-	form.Base = tUTXOAssetB.ID
-	form.Quote = tACCTAsset.ID
-	rig.ws.queueResponse(msgjson.MarketRoute, handleMarket)
-	tEthWallet.fundingMtx.Lock()
-	tEthWallet.reserveNRedemptions = reserveN
-	tEthWallet.fundingMtx.Unlock()
-	tEthWallet.sigs = []dex.Bytes{{}}
-	tEthWallet.pubKeys = []dex.Bytes{{}}
-	corder, err = trade()
-	if err != nil {
-		t.Fatalf("account-redeemed order error: %v", err)
+	bal0 := tWallet.bal.Available
+	tWallet.bal.Available = 0
+	run()
+	if !errorHasCode(err, walletBalanceErr) {
+		t.Fatalf("expected low balance error, got: %v", err)
 	}
-	t.Logf("Order with ID(%s) has been placed successfully!", corder.ID.String())
+	tWallet.bal.Available = bal0
 
-	// redeem sig error
-	tEthWallet.signCoinErr = tErr
-	ensureErr("redeem sig error")
-	tEthWallet.signCoinErr = nil
+	// signature error
+	queueConfigAndConnectUnknownAcct()
+	rig.ws.queueResponse(msgjson.PreValidateBondRoute, func(msg *msgjson.Message, f msgFunc) error {
+		preEval := new(msgjson.PreValidateBond)
+		msg.Unmarshal(preEval)
 
-	// missing sig
-	tEthWallet.sigs = []dex.Bytes{}
-	ensureErr("no redeem sig is result")
-	tEthWallet.sigs = []dex.Bytes{{}}
+		preEvalResult := &msgjson.PreValidateBondResult{
+			Signature: msgjson.Signature{
+				Sig: []byte{0xb, 0xa, 0xd},
+			},
+		}
+		resp, _ := msgjson.NewResponse(msg.ID, preEvalResult, nil)
+		f(resp)
+		return nil
+	})
+	run()
+	if !errorHasCode(err, signatureErr) {
+		t.Fatalf("wrong error for bad signature on prevalidate response: %v", err)
+	}
 
-	// ReserveN error
-	tEthWallet.reserveNRedemptionsErr = tErr
-	ensureErr("reserveN error")
-	tEthWallet.reserveNRedemptionsErr = nil
+	// Wrong bond size on form
+	goodAmt := form.Bond
+	form.Bond = goodAmt + 1
+	queueConfigAndConnectUnknownAcct()
+	run()
+	if !errorHasCode(err, bondAmtErr) {
+		t.Fatalf("wrong error for wrong fee in form: %v", err)
+	}
+	form.Bond = goodAmt
 
-	// Funds returned for later error.
-	tEthWallet.fundingMtx.Lock()
-	tEthWallet.redemptionUnlocked = 0
-	tEthWallet.fundingMtx.Unlock()
-	rig.db.updateOrderErr = tErr
-	rig.ws.queueResponse(msgjson.MarketRoute, handleMarket)
-	ensureOrderErr("db error after redeem funds checked out", async)
-	rig.db.updateOrderErr = nil
-	tEthWallet.fundingMtx.Lock()
-	defer tEthWallet.fundingMtx.Unlock()
-	if tEthWallet.redemptionUnlocked != reserveN {
-		t.Fatalf("redeem funds not returned")
+	// MakeBondTx error
+	queueConfigAndConnectUnknownAcct()
+	tWallet.makeBondTxErr = tErr
+	run()
+	if !errorHasCode(err, bondPostErr) {
+		t.Fatalf("wrong error for bondPostErr: %v", err)
 	}
-}
+	tWallet.makeBondTxErr = nil
 
-func TestTrade(t *testing.T) {
-	trade(t, false)
-}
+	// Make sure it's good again.
+	queueResponses()
+	run()
+	if err != nil {
+		t.Fatalf("error after regaining valid state: %v", err)
+	}
+	getBondAndBalanceNote()
 
-func TestTradeAsync(t *testing.T) {
-	trade(t, true)
-}
+	// Test the account recovery path.
+	rig.queueConfig()
+	rig.queueConnect(nil, nil, nil) // account exists
+	run()
+	if err != nil {
+		t.Fatalf("Paid account error: %v", err)
+	}
 
-func TestRefundReserves(t *testing.T) {
-	const reserves = 100_000
+	// Account suspended should derive new HD credentials.
+	rig.queueConnect(nil, nil, nil, true) // first try exists but suspended
+	queueResponses()
+	run()
+	if err != nil {
+		t.Fatalf("Suspension recovery error: %v", err)
+	}
+	getBondAndBalanceNote()
+}
 
+func TestCredentialsUpgrade(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	dc := rig.dc
 	tCore := rig.core
+	rig.db.legacyKeyErr = nil
 
-	btcWallet, _ := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = btcWallet
-	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
-	btcWallet.Unlock(rig.crypter)
-
-	ethWallet, tEthWallet := newTAccountLocker(tACCTAsset.ID)
-	tCore.wallets[tACCTAsset.ID] = ethWallet
-	ethWallet.address = "18d65fb8d60c1199bb1ad381be47aa692b482605"
-	ethWallet.Unlock(rig.crypter)
-
-	lotSize := dcrBtcLotSize
-	qty := lotSize * 10
-	rate := dcrBtcRateStep * 100
+	clearUpgrade := func() {
+		rig.db.creds.EncInnerKey = nil
+		tCore.credentials.EncInnerKey = nil
+	}
 
-	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, rate)
-	lo.BaseAsset = tUTXOAssetB.ID
-	lo.QuoteAsset = tACCTAsset.ID
-	lo.Force = order.StandingTiF
-	loid := lo.ID()
+	clearUpgrade()
 
-	walletSet, _, _, err := tCore.walletSet(dc, tACCTAsset.ID, tUTXOAssetA.ID, true)
+	// initial success
+	err := tCore.Login(tPW)
 	if err != nil {
-		t.Fatalf("walletSet error: %v", err)
+		t.Fatalf("initial Login error: %v", err)
 	}
 
-	dbOrder.MetaData.RefundReserves = reserves
+	clearUpgrade()
 
-	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
-	dc.trades[loid] = tracker
-	preImgC := newPreimage()
-	co := &order.CancelOrder{
-		P: order.Prefix{
-			AccountID:  dc.acct.ID(),
-			BaseAsset:  tACCTAsset.ID,
-			QuoteAsset: tUTXOAssetA.ID,
-			OrderType:  order.MarketOrderType,
-			ClientTime: time.Now(),
-			ServerTime: time.Now().Add(time.Millisecond),
-			Commit:     preImgC.Commit(),
-		},
+	// Recrypt error
+	rig.db.recryptErr = tErr
+	err = tCore.Login(tPW)
+	if err == nil {
+		t.Fatalf("no error for recryptErr")
 	}
+	rig.db.recryptErr = nil
 
-	msgCancelMatch := &msgjson.Match{
-		OrderID:  loid[:],
-		MatchID:  encode.RandomBytes(32),
-		Quantity: qty / 3,
-		// empty Address signals cancel order match
+	// final success
+	err = tCore.Login(tPW)
+	if err != nil {
+		t.Fatalf("final Login error: %v", err)
 	}
-	sign(tDexPriv, msgCancelMatch)
+}
 
-	matchQty := qty * 2 / 3
-	matchReserves := applyFraction(2, 3, reserves)
-	msgMatch := &msgjson.Match{
-		OrderID:  loid[:],
-		MatchID:  encode.RandomBytes(32),
-		Quantity: matchQty,
-		Rate:     rate,
-		Address:  "somenonemptyaddress",
-	}
-	sign(tDexPriv, msgMatch)
+func unauth(a *dexAccount) {
+	a.authMtx.Lock()
+	a.isAuthed = false
+	a.authMtx.Unlock()
+}
 
-	test := func(tag string, expUnlock uint64, f func()) {
-		t.Helper()
-		tEthWallet.refundUnlocked = 0
-		tracker.refundLocked = reserves
-		tracker.metaData.Status = order.OrderStatusEpoch
-		f()
-		if tEthWallet.refundUnlocked != expUnlock {
-			t.Fatalf("%s: expected %d to be unlocked. saw %d", tag, expUnlock, tEthWallet.refundUnlocked)
-		}
+func TestLogin(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	rig.acct.rep = account.Reputation{BondedTier: 1}
+
+	rig.queueConnect(nil, nil, nil)
+	err := tCore.Login(tPW)
+	if err != nil || !rig.acct.authed() {
+		t.Fatalf("initial Login error: %v", err)
 	}
 
-	test("revoke_order in epoch", reserves, func() {
-		tracker.revoke()
-	})
+	// No encryption key.
+	unauth(rig.acct)
+	creds := tCore.credentials
+	tCore.credentials = nil
+	err = tCore.Login(tPW)
+	if err == nil || rig.acct.authed() {
+		t.Fatalf("no error for missing app key")
+	}
+	tCore.credentials = creds
 
-	test("revoke_order in booked, partial fill", reserves/2, func() {
-		// Revoke in booked with partial fill.
-		tracker.Trade().SetFill(qty / 2)
-		tracker.metaData.Status = order.OrderStatusBooked
-		tracker.revoke()
-	})
+	// Account not Paid. No error, and account should be unlocked.
+	rig.acct.rep = account.Reputation{BondedTier: 0}
+	rig.queueConnect(nil, nil, nil)
+	err = tCore.Login(tPW)
+	if err != nil || rig.acct.authed() {
+		t.Fatalf("error for unpaid account: %v", err)
+	}
+	if rig.acct.locked() {
+		t.Fatalf("unpaid account is locked")
+	}
+	rig.acct.rep = account.Reputation{BondedTier: 1}
 
-	test("canceled, partially filled", reserves/3, func() {
-		tracker.cancel = &trackedCancel{CancelOrder: *co}
-		msgCancel, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch, msgCancelMatch})
-		if err := handleMatchRoute(tCore, rig.dc, msgCancel); err != nil {
-			t.Fatalf("handleMatchRoute error: %v", err)
-		}
-	})
-
-	tracker.cancel = nil
-
-	lo.Force = order.ImmediateTiF
-	loid = lo.ID()
-	msgMatch.OrderID = loid[:]
-	sign(tDexPriv, msgMatch)
-
-	test("partial immediate TiF limit order", reserves/3, func() {
-		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
-		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
-			t.Fatalf("handleMatchRoute error: %v", err)
-		}
+	// 'connect' route error.
+	rig = newTestRig()
+	defer rig.shutdown()
+	tCore = rig.core
+	unauth(rig.acct)
+	rig.ws.queueResponse(msgjson.ConnectRoute, func(msg *msgjson.Message, f msgFunc) error {
+		resp, _ := msgjson.NewResponse(msg.ID, nil, msgjson.NewError(1, "test error"))
+		f(resp)
+		return nil
 	})
+	err = tCore.Login(tPW)
+	// Should be no error, but also not authed. Error is sent and logged
+	// as a notification.
+	if err != nil || rig.acct.authed() {
+		t.Fatalf("account authed after 'connect' error")
+	}
 
+	// Success with some matches in the response.
+	rig = newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	qty := 3 * dcrBtcLotSize
+	lo, dbOrder, preImg, addr := makeLimitOrder(dc, true, qty, dcrBtcRateStep*10)
 	lo.Force = order.StandingTiF
-	loid = lo.ID()
-	msgMatch.OrderID = loid[:]
-	sign(tDexPriv, msgMatch)
-
-	addMatch := func(side order.MatchSide, status order.MatchStatus, qty uint64) order.MatchID {
-		t.Helper()
-		msgMatch.Side = uint8(side)
-		m := *msgMatch
-		var mid order.MatchID
-		copy(mid[:], encode.RandomBytes(32))
-		m.MatchID = mid[:]
-		m.Quantity = qty
-		sign(tDexPriv, &m)
-		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{&m})
-		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
-			t.Fatalf("handleMatchRoute error: %v", err)
-		}
-		mt, ok := tracker.matches[mid]
-		if !ok {
-			t.Fatalf("match not found")
-		}
-		mt.Status = status
-		if status >= order.TakerSwapCast {
-			mt.counterSwap = &asset.AuditInfo{}
-		}
-		return mid
+	dbOrder.MetaData.Status = order.OrderStatusBooked // leave unfunded to have it canceled on auth/'connect'
+	oid := lo.ID()
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails) // nil means no funding coins
+	matchID := ordertest.RandomMatchID()
+	match := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{MatchID: matchID},
+			MetaData:  &db.MatchMetaData{},
+		},
 	}
+	tracker.matches[matchID] = match
+	knownMsgMatch := &msgjson.Match{OrderID: oid[:], MatchID: matchID[:]}
 
-	resetMatches := func() {
-		tracker.matches = make(map[order.MatchID]*matchTracker)
+	// Known trade, but missing match
+	missingID := ordertest.RandomMatchID()
+	missingMatch := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{MatchID: missingID},
+			MetaData:  &db.MatchMetaData{},
+		},
 	}
+	tracker.matches[missingID] = missingMatch
 
-	test("redemption received", reserves/10, func() {
-		mid := addMatch(order.Taker, order.TakerSwapCast, lotSize)
-		redemption := &msgjson.Redemption{
-			Redeem: msgjson.Redeem{
-				OrderID: loid[:],
-				MatchID: mid[:],
-				CoinID:  encode.RandomBytes(36),
-			},
-		}
-		tracker.processRedemption(1, redemption)
-	})
-
-	// Market sell order
-	mo := &order.MarketOrder{
-		P: lo.P,
-		T: *lo.Trade(),
+	// extra match
+	extraID := ordertest.RandomMatchID()
+	matchTime := time.Now()
+	extraMsgMatch := &msgjson.Match{
+		OrderID:    oid[:],
+		MatchID:    extraID[:],
+		Side:       uint8(order.Taker),
+		Status:     uint8(order.MakerSwapCast),
+		ServerTime: uint64(matchTime.UnixMilli()),
 	}
-	mo.Prefix().OrderType = order.MarketOrderType
-	moid := mo.ID()
-	dbOrder.Order = mo
-	msgMatch.OrderID = moid[:]
-	sign(tDexPriv, msgMatch)
-
-	tracker = newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
-	dc.trades = map[order.OrderID]*trackedTrade{moid: tracker}
-
-	test("nomatch", reserves, func() {
-		tracker.nomatch(moid)
-	})
 
-	test("partial market sell match", reserves/3, func() {
-		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
-		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
-			t.Fatalf("handleMatchRoute error: %v", err)
-		}
+	// The extra match is already at MakerSwapCast, and we're the taker, which
+	// will invoke match status conflict resolution and a contract audit.
+	_, auditInfo := tMsgAudit(oid, extraID, addr, qty, encode.RandomBytes(32))
+	auditInfo.Expiration = encode.DropMilliseconds(matchTime.Add(tracker.lockTimeMaker))
+	tBtcWallet.auditInfo = auditInfo
+	missedContract := encode.RandomBytes(50)
+	rig.ws.queueResponse(msgjson.MatchStatusRoute, func(msg *msgjson.Message, f msgFunc) error {
+		resp, _ := msgjson.NewResponse(msg.ID, []*msgjson.MatchStatusResult{{
+			MatchID:       extraID[:],
+			Status:        uint8(order.MakerSwapCast),
+			MakerContract: missedContract,
+			MakerSwap:     auditInfo.Coin.ID(),
+			Active:        true,
+			MakerTxData:   []byte{0x01},
+		}}, nil)
+		f(resp)
+		return nil
 	})
 
-	resetMatches()
-
-	testRevokeMatch := func(side order.MatchSide, status order.MatchStatus, expReserves uint64) {
-		t.Helper()
-		resetMatches()
-		matchID := addMatch(side, status, matchQty)
-		desc := fmt.Sprintf("match revoke - %s in %s", side, status)
-		test(desc, expReserves, func() {
-			tracker.revokeMatch(matchID, true)
-		})
+	dc.trades = map[order.OrderID]*trackedTrade{
+		oid: tracker,
 	}
 
-	testRevokeMatch(order.Maker, order.NewlyMatched, matchReserves)
-
-	testRevokeMatch(order.Taker, order.NewlyMatched, matchReserves)
-
-	testRevokeMatch(order.Taker, order.MakerSwapCast, matchReserves)
-
-	// But Maker in MakerSwapCast shouldn't return reserves, because they will
-	// need to do a refund
-	testRevokeMatch(order.Maker, order.MakerSwapCast, 0)
-
-	// Similarly Taker in TakerSwapCast shouldn't return anything, since
-	// they will need to do a refund.
-	testRevokeMatch(order.Taker, order.TakerSwapCast, 0)
-
-	resetMatches()
-
-	// Market buy order
-	mo.BaseAsset, mo.QuoteAsset = mo.QuoteAsset, mo.BaseAsset
-	mo.Sell = false
-	tracker.wallets, _, _, _ = tCore.walletSet(dc, tUTXOAssetA.ID, tACCTAsset.ID, false)
-
-	test("redemption received, market buy", reserves, func() {
-		mid := addMatch(order.Taker, order.TakerSwapCast, lotSize)
-		redemption := &msgjson.Redemption{
-			Redeem: msgjson.Redeem{
-				OrderID: loid[:],
-				MatchID: mid[:],
-				CoinID:  encode.RandomBytes(36),
-			},
-		}
-		tracker.processRedemption(1, redemption)
-	})
+	tCore = rig.core
+	rig.queueConnect(nil, []*msgjson.Match{knownMsgMatch /* missing missingMatch! */, extraMsgMatch}, nil)
+	rig.queueCancel(nil) // for the unfunded order that gets canceled in authDEX
+	// Login>authDEX will do 4 match DB updates for these two matches:
+	// missing -> revoke -> update match
+	// extra -> negotiate -> newTrackers -> update match
+	// matchConflicts (from extras) -> resolveMatchConflicts -> resolveConflictWithServerData
+	// 	-> update match after spawning auditContract
+	// 	-> update match in auditContract (second because of lock) ** the ASYNC one we have to wait for **
+	rig.db.updateMatchChan = make(chan order.MatchStatus, 4)
+	err = tCore.Login(tPW) // authDEX -> async contract audit for the extra match
+	if err != nil || !rig.acct.authed() {
+		t.Fatalf("final Login error: %v", err)
+	}
+	// Wait for expected db updates.
+	for i := 0; i < 4; i++ {
+		<-rig.db.updateMatchChan
+	}
 
-	resetMatches()
-	mids := []order.MatchID{
-		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
-		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
-		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
+	// check t.metaData.LinkedOrder or for db.LinkOrder call, then db.UpdateOrder call
+	if tracker.metaData.LinkedOrder.IsZero() {
+		t.Errorf("cancel order not set")
+	}
+	if rig.db.linkedFromID != oid || rig.db.linkedToID.IsZero() {
+		t.Errorf("automatic cancel order not linked")
 	}
 
-	tracker.refundLocked = reserves
-	tEthWallet.refundUnlocked = 0
-	for _, mid := range mids {
-		// Third match should catch the market buy order dust filter.
-		if err := tracker.revokeMatch(mid, true); err != nil {
-			t.Fatalf("revokeMatch error: %v", err)
-		}
+	if !tracker.matches[missingID].MetaData.Proof.SelfRevoked {
+		t.Errorf("SelfRevoked not true for missing match tracker")
 	}
-	if tracker.refundLocked != 0 {
-		t.Fatalf("redemptionLocked (1/3) * 3 != 1: %d still reserved of %d", tracker.refundLocked, reserves)
+	if tracker.matches[matchID].swapErr != nil {
+		t.Errorf("swapErr set for non-missing match tracker")
 	}
-	if tEthWallet.refundUnlocked != reserves {
-		t.Fatalf("redemptionUnlocked (1/3) * 3 != 1: %d returned of %d", tEthWallet.refundUnlocked, reserves)
+	if tracker.matches[matchID].MetaData.Proof.IsRevoked() {
+		t.Errorf("IsRevoked true for non-missing match tracker")
+	}
+	// Conflict resolution will have run negotiate on the extra match from the
+	// connect response, bringing our match count up to 3.
+	if len(tracker.matches) != 3 {
+		t.Errorf("Extra trade not accepted into matches")
+	}
+	tracker.mtx.Lock()
+	defer tracker.mtx.Unlock()
+	match = tracker.matches[extraID]
+	if !bytes.Equal(match.MetaData.Proof.CounterContract, missedContract) {
+		t.Errorf("Missed maker contract not retrieved, %s, %s", match, hex.EncodeToString(match.MetaData.Proof.CounterContract))
 	}
 }
 
-func TestRedemptionReserves(t *testing.T) {
-	const reserves = 100_000
-
+func TestAccountNotFoundError(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	dc := rig.dc
 	tCore := rig.core
+	rig.acct.rep = account.Reputation{BondedTier: 1}
 
-	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
-	btcWallet.Unlock(rig.crypter)
+	const expectedErrorMessage = "test account not found error"
+	accountNotFoundError := msgjson.NewError(msgjson.AccountNotFoundError, expectedErrorMessage)
+	rig.queueConnect(accountNotFoundError, nil, nil)
+	rig.queueConnect(accountNotFoundError, nil, nil)
 
-	ethWallet, tEthWallet := newTAccountLocker(tACCTAsset.ID)
-	tCore.wallets[tACCTAsset.ID] = ethWallet
-	ethWallet.address = "18d65fb8d60c1199bb1ad381be47aa692b482605"
-	ethWallet.Unlock(rig.crypter)
+	wallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
+	rig.queueConnect(nil, nil, nil)
 
-	lotSize := dcrBtcLotSize
-	qty := lotSize * 10
-	rate := dcrBtcRateStep * 100
+	feed := tCore.NotificationFeed()
 
-	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, rate)
-	lo.BaseAsset = tUTXOAssetB.ID
-	lo.QuoteAsset = tACCTAsset.ID
-	lo.Force = order.StandingTiF
-	loid := lo.ID()
+	tCore.initializeDEXConnections(rig.crypter)
 
-	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetB.ID, tACCTAsset.ID, true)
-	if err != nil {
-		t.Fatalf("walletSet error: %v", err)
+	// Make sure that the connections did not get authenticated
+	for _, dc := range tCore.dexConnections() {
+		if dc.acct.authed() {
+			t.Fatalf("dex connection should not have been authenticated")
+		}
 	}
 
-	dbOrder.MetaData.RedemptionReserves = reserves
-
-	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
-	dc.trades[loid] = tracker
-	preImgC := newPreimage()
-	co := &order.CancelOrder{
-		P: order.Prefix{
-			AccountID:  dc.acct.ID(),
-			BaseAsset:  tACCTAsset.ID,
-			QuoteAsset: tUTXOAssetB.ID,
-			OrderType:  order.MarketOrderType,
-			ClientTime: time.Now(),
-			ServerTime: time.Now().Add(time.Millisecond),
-			Commit:     preImgC.Commit(),
-		},
+	// Make sure that an error notification was sent
+	for {
+		select {
+		case note := <-feed.C:
+			if note.Topic() == TopicDexAuthError && strings.Contains(note.Details(), expectedErrorMessage) {
+				return
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatalf("error notification could not be found")
+		}
 	}
+}
 
-	msgCancelMatch := &msgjson.Match{
-		OrderID:  loid[:],
-		MatchID:  encode.RandomBytes(32),
-		Quantity: qty / 3,
-		// empty Address signals cancel order match
-	}
-	sign(tDexPriv, msgCancelMatch)
+func TestHandleReconnect(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+	rig.acct.rep = account.Reputation{BondedTier: 1}
 
-	matchQty := qty * 2 / 3
-	matchReserves := applyFraction(2, 3, reserves)
-	msgMatch := &msgjson.Match{
-		OrderID:  loid[:],
-		MatchID:  encode.RandomBytes(32),
-		Quantity: matchQty,
-		Rate:     rate,
-		Address:  "somenonemptyaddress",
+	oid := ordertest.RandomOrderID()
+	var bookSubs uint32
+	queueBook := func() {
+		rig.ws.queueResponse(msgjson.OrderBookRoute, func(msg *msgjson.Message, f msgFunc) error {
+			atomic.AddUint32(&bookSubs, 1)
+			resp, _ := msgjson.NewResponse(msg.ID, &msgjson.OrderBook{
+				Seq:      1,
+				MarketID: tDcrBtcMktName,
+				Orders: []*msgjson.BookOrderNote{{
+					TradeNote: msgjson.TradeNote{
+						Side:     msgjson.BuyOrderNum,
+						Quantity: 10,
+						Rate:     2,
+					},
+					OrderNote: msgjson.OrderNote{
+						Seq:      1,
+						MarketID: tDcrBtcMktName,
+						OrderID:  oid[:],
+					},
+				}},
+			}, nil)
+			f(resp)
+			return nil
+		})
 	}
-	sign(tDexPriv, msgMatch)
+	queueBook()
+	_, bookFeed, err := tCore.SyncBook(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if err != nil {
+		t.Fatalf("SyncBook error: %v", err)
+	}
+	<-bookFeed.Next() // initial FreshBookAction
 
-	test := func(tag string, expUnlock uint64, f func()) {
+	atomic.StoreUint32(&dc.reportingConnects, 1)
+	dc.notify = tCore.notify
+	noteFeed := tCore.NotificationFeed()
+	waitForTopic := func(topic Topic) {
 		t.Helper()
-		tEthWallet.redemptionUnlocked = 0
-		tracker.redemptionLocked = reserves
-		tracker.metaData.Status = order.OrderStatusEpoch
-		f()
-		if tEthWallet.redemptionUnlocked != expUnlock {
-			t.Fatalf("%s: expected %d to be unlocked. saw %d", tag, expUnlock, tEthWallet.redemptionUnlocked)
+		for {
+			select {
+			case note := <-noteFeed.C:
+				if note.Topic() == topic {
+					return
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("no %s notification", topic)
+			}
 		}
 	}
 
-	test("revoke_order in epoch", reserves, func() {
-		tracker.revoke()
-	})
+	// The server drops the connection.
+	tCore.handleConnectEvent(dc, comms.Disconnected)
+	waitForTopic(TopicDEXDisconnected)
+	if dc.status() != comms.Disconnected {
+		t.Fatalf("expected disconnected status, got %v", dc.status())
+	}
 
-	test("revoke_order in booked, partial fill", reserves/2, func() {
-		// Revoke in booked with partial fill.
-		tracker.Trade().SetFill(qty / 2)
-		tracker.metaData.Status = order.OrderStatusBooked
-		tracker.revoke()
+	// The connection is reestablished. Core should refresh the server config,
+	// re-authenticate, and resubscribe to the book.
+	tCore.handleConnectEvent(dc, comms.Connected)
+	waitForTopic(TopicDEXConnected)
+	rig.queueConfig()
+	var connects uint32
+	rig.ws.queueResponse(msgjson.ConnectRoute, func(msg *msgjson.Message, f msgFunc) error {
+		atomic.AddUint32(&connects, 1)
+		connect := new(msgjson.Connect)
+		msg.Unmarshal(connect)
+		sign(tDexPriv, connect)
+		resp, _ := msgjson.NewResponse(msg.ID, &msgjson.ConnectResult{
+			Sig:        connect.Sig,
+			Reputation: &account.Reputation{BondedTier: 1},
+		}, nil)
+		f(resp)
+		return nil
 	})
+	queueBook()
+	tCore.handleReconnect(tDexHost)
 
-	test("canceled, partially filled", reserves/3, func() {
-		tracker.cancel = &trackedCancel{CancelOrder: *co}
-		msgCancel, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch, msgCancelMatch})
-		if err := handleMatchRoute(tCore, rig.dc, msgCancel); err != nil {
-			t.Fatalf("handleMatchRoute error: %v", err)
+	if atomic.LoadUint32(&connects) != 1 {
+		t.Fatalf("expected 1 connect request, got %d", connects)
+	}
+	if !dc.acct.authed() {
+		t.Fatalf("not authenticated after reconnect")
+	}
+	if atomic.LoadUint32(&bookSubs) != 2 {
+		t.Fatalf("expected book resubscription, got %d subscriptions", bookSubs)
+	}
+	select {
+	case u := <-bookFeed.Next():
+		if u.Action != FreshBookAction {
+			t.Fatalf("expected %s, got %s", FreshBookAction, u.Action)
 		}
-	})
-
-	tracker.cancel = nil
+	default:
+		t.Fatalf("no fresh book after reconnect")
+	}
+}
 
-	lo.Force = order.ImmediateTiF
-	loid = lo.ID()
-	msgMatch.OrderID = loid[:]
-	sign(tDexPriv, msgMatch)
+func TestInitializeDEXConnectionsSuccess(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	rig.acct.rep = account.Reputation{BondedTier: 1}
+	rig.queueConnect(nil, nil, nil)
 
-	test("partially filled immediate TiF limit order", reserves/3, func() {
-		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
-		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
-			t.Fatalf("handleMatchRoute error: %v", err)
+	// Make sure that the connections got authenticated
+	tCore.initializeDEXConnections(rig.crypter)
+	for _, dc := range tCore.dexConnections() {
+		if !dc.acct.authed() {
+			t.Fatalf("dex connection was not authenticated")
 		}
-	})
-
-	mo := &order.MarketOrder{
-		P: lo.P,
-		T: *lo.Trade(),
 	}
-	mo.Prefix().OrderType = order.MarketOrderType
-	moid := mo.ID()
-	dbOrder.Order = mo
-	msgMatch.OrderID = moid[:]
-	sign(tDexPriv, msgMatch)
-
-	tracker = newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
-	dc.trades = map[order.OrderID]*trackedTrade{moid: tracker}
-
-	test("nomatch", reserves, func() {
-		tracker.nomatch(moid)
-	})
+}
 
-	test("partial market sell match", reserves/3, func() {
-		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
-		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
-			t.Fatalf("handleMatchRoute error: %v", err)
-		}
-	})
+func TestConnectDEX(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-	addMatch := func(side order.MatchSide, status order.MatchStatus, qty uint64) order.MatchID {
-		msgMatch.Side = uint8(side)
-		m := *msgMatch
-		var mid order.MatchID
-		copy(mid[:], encode.RandomBytes(32))
-		m.MatchID = mid[:]
-		m.Quantity = qty
-		sign(tDexPriv, &m)
-		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{&m})
-		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
-			t.Fatalf("handleMatchRoute error: %v", err)
-		}
-		mt, ok := tracker.matches[mid]
-		if !ok {
-			t.Fatalf("match not found")
-		}
-		mt.Status = status
-		return mid
+	ai := &db.AccountInfo{
+		Host: "somedex.com",
 	}
 
-	resetMatches := func() {
-		tracker.matches = make(map[order.MatchID]*matchTracker)
+	_, err := tCore.connectDEX(ai)
+	if err == nil {
+		t.Fatalf("expected error for no TLS plain internet DEX host")
 	}
 
-	testRevokeMatch := func(side order.MatchSide, status order.MatchStatus, expReserves uint64) {
-		t.Helper()
-		resetMatches()
-		matchID := addMatch(side, status, matchQty)
-		desc := fmt.Sprintf("match revoke - %s in %s", side, status)
-		test(desc, expReserves, func() {
-			tracker.revokeMatch(matchID, true)
-		})
+	ai.Host = "somedex13254214214.onion" // not a valid onion host in case we decide to validate them
+	// No onion proxy set => error
+	_, err = tCore.connectDEX(ai)
+	if err == nil {
+		t.Fatalf("expected error with no onion proxy set")
 	}
 
-	testRevokeMatch(order.Maker, order.NewlyMatched, matchReserves)
+	rig.queueConfig()
+	tCore.cfg.Onion = "127.0.0.1:9050"
+	dc, err := tCore.connectDEX(ai)
+	if err != nil {
+		t.Fatalf("error connecting to onion host with an onion proxy configured: %v", err)
+	}
+	dc.connMaster.Disconnect()
 
-	testRevokeMatch(order.Taker, order.NewlyMatched, matchReserves)
+	rig.queueConfig()
+	ai.Host = "somedex.com"
+	ai.Cert = []byte{0x1}
+	dc, err = tCore.connectDEX(ai)
+	if err != nil {
+		t.Fatalf("initial connectDEX error: %v", err)
+	}
+	dc.connMaster.Disconnect()
 
-	testRevokeMatch(order.Taker, order.MakerSwapCast, matchReserves)
+	// Bad URL.
+	ai.Host = tUnparseableHost // Illegal ASCII control character
+	_, err = tCore.connectDEX(ai)
+	if err == nil {
+		t.Fatalf("no error for bad URL")
+	}
+	ai.Host = "someotherdex.org"
 
-	// But Maker in MakerSwapCast shouldn't return reserves, since the trade
-	// will proceed to redeem.
-	testRevokeMatch(order.Maker, order.MakerSwapCast, 0)
+	// Constructor error.
+	ogConstructor := tCore.wsConstructor
+	tCore.wsConstructor = func(*comms.WsCfg) (comms.WsConn, error) {
+		return nil, tErr
+	}
+	_, err = tCore.connectDEX(ai)
+	if err == nil {
+		t.Fatalf("no error for WsConn constructor error")
+	}
+	tCore.wsConstructor = ogConstructor
 
-	// Similarly Taker in TakerSwapCast shouldn't return anything, since we will
-	// be watching for a redemption.
-	testRevokeMatch(order.Taker, order.TakerSwapCast, 0)
+	// WsConn.Connect error.
+	rig.ws.connectErr = tErr
+	_, err = tCore.connectDEX(ai)
+	if err == nil {
+		t.Fatalf("no error for WsConn.Connect error")
+	}
 
-	// Market buy order with dust handling.
-	mo.BaseAsset, mo.QuoteAsset = mo.QuoteAsset, mo.BaseAsset
-	mo.Sell = false
-	tracker.wallets, _, _, _ = tCore.walletSet(dc, tACCTAsset.ID, tUTXOAssetB.ID, false)
+	rig.ws.connectErr = nil
 
-	resetMatches()
-	mids := []order.MatchID{
-		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
-		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
-		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
+	// 'config' route error.
+	rig.ws.queueResponse(msgjson.ConfigRoute, func(msg *msgjson.Message, f msgFunc) error {
+		resp, _ := msgjson.NewResponse(msg.ID, nil, msgjson.NewError(1, "test error"))
+		f(resp)
+		return nil
+	})
+	_, err = tCore.connectDEX(ai)
+	if err == nil {
+		t.Fatalf("no error for 'config' route error")
 	}
 
-	tracker.redemptionLocked = reserves
-	tEthWallet.redemptionUnlocked = 0
-	for _, mid := range mids {
-		// Third match should catch the market buy order dust filter.
-		if err := tracker.revokeMatch(mid, true); err != nil {
-			t.Fatalf("revokeMatch error: %v", err)
-		}
-	}
-	if tracker.redemptionLocked != 0 {
-		t.Fatalf("redemptionLocked (1/3) * 3 != 1: %d still reserved of %d", tracker.redemptionLocked, reserves)
-	}
-	if tEthWallet.redemptionUnlocked != reserves {
-		t.Fatalf("redemptionUnlocked (1/3) * 3 != 1: %d returned of %d", tEthWallet.redemptionUnlocked, reserves)
+	// Success again.
+	rig.queueConfig()
+	dc, err = tCore.connectDEX(ai)
+	if err != nil {
+		t.Fatalf("final connectDEX error: %v", err)
 	}
+	dc.connMaster.Disconnect()
+
+	// TODO: test temporary, ensure listen isn't running, somehow
 }
 
-func TestCancel(t *testing.T) {
+func TestInitializeClient(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	dc := rig.dc
-	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, 0, 0)
-	lo.Force = order.StandingTiF
-	oid := lo.ID()
-	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, nil, nil, rig.core.notify, rig.core.formatDetails)
-	dc.trades[oid] = tracker
+	tCore := rig.core
 
-	rig.queueCancel(nil)
-	err := rig.core.Cancel(oid[:])
+	clearCreds := func() {
+		tCore.credentials = nil
+		rig.db.creds = nil
+	}
+
+	clearCreds()
+
+	_, err := tCore.InitializeClient(tPW, nil)
 	if err != nil {
-		t.Fatalf("cancel error: %v", err)
+		t.Fatalf("InitializeClient error: %v", err)
 	}
-	if tracker.cancel == nil {
-		t.Fatalf("cancel order not found")
+
+	clearCreds()
+
+	// Empty password.
+	emptyPass := []byte("")
+	_, err = tCore.InitializeClient(emptyPass, nil)
+	if err == nil {
+		t.Fatalf("no error for empty password")
 	}
 
-	ensureErr := func(tag string) {
-		t.Helper()
-		err := rig.core.Cancel(oid[:])
-		if err == nil {
-			t.Fatalf("%s: no error", tag)
-		}
+	// Store error. Use a non-empty password to pass empty password check.
+	rig.db.setCredsErr = tErr
+	_, err = tCore.InitializeClient(tPW, nil)
+	if err == nil {
+		t.Fatalf("no error for StoreEncryptedKey error")
 	}
+	rig.db.setCredsErr = nil
 
-	// Should get an error for existing cancel order.
-	ensureErr("second cancel")
+	// Success again
+	_, err = tCore.InitializeClient(tPW, nil)
+	if err != nil {
+		t.Fatalf("final InitializeClient error: %v", err)
+	}
+}
 
-	// remove the cancel order so we can check its nilness on error.
-	tracker.cancel = nil
+func TestSend(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
+	tWallet.sendCoin = &tCoin{id: encode.RandomBytes(36)}
+	address := "addr"
 
-	ensureNilCancel := func(tag string) {
-		if tracker.cancel != nil {
-			t.Fatalf("%s: cancel order found", tag)
-		}
+	// Successful
+	coin, err := tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
+	if err != nil {
+		t.Fatalf("Send error: %v", err)
+	}
+	if coin.Value() != 1e8 {
+		t.Fatalf("Expected sent value to be %v, got %v", 1e8, coin.Value())
 	}
 
-	// Bad order ID
-	ogID := oid
-	oid = order.OrderID{0x01, 0x02}
-	ensureErr("bad id")
-	ensureNilCancel("bad id")
-	oid = ogID
+	// 0 value
+	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 0, address, false)
+	if err == nil {
+		t.Fatalf("no error for zero value send")
+	}
 
-	// Order not found
-	delete(dc.trades, oid)
-	ensureErr("no order")
-	ensureNilCancel("no order")
-	dc.trades[oid] = tracker
+	// no wallet
+	_, err = tCore.Send(tPW, 12345, 1e8, address, false)
+	if err == nil {
+		t.Fatalf("no error for unknown wallet")
+	}
+
+	// connect error
+	wallet.hookedUp = false
+	tWallet.connectErr = tErr
+	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
+	if err == nil {
+		t.Fatalf("no error for wallet connect error")
+	}
+	tWallet.connectErr = nil
 
 	// Send error
-	rig.ws.reqErr = tErr
-	ensureErr("Request error")
-	ensureNilCancel("Request error")
-	rig.ws.reqErr = nil
-}
+	tWallet.sendErr = tErr
+	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
+	if err == nil {
+		t.Fatalf("no error for wallet send error")
+	}
+	tWallet.sendErr = nil
 
-func TestHandlePreimageRequest(t *testing.T) {
-	t.Run("basic checks", func(t *testing.T) {
-		rig := newTestRig()
-		defer rig.shutdown()
-		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
-		oid := ord.ID()
-		preImg := newPreimage()
+	// Check the coin.
+	tWallet.sendCoin = &tCoin{id: []byte{'a'}}
+	coin, err = tCore.Send(tPW, tUTXOAssetA.ID, 3e8, address, false)
+	if err != nil {
+		t.Fatalf("coin check error: %v", err)
+	}
+	coinID := coin.ID()
+	if len(coinID) != 1 || coinID[0] != 'a' {
+		t.Fatalf("coin ID not propagated")
+	}
+	if coin.Value() != 3e8 {
+		t.Fatalf("Expected sent value to be %v, got %v", 3e8, coin.Value())
+	}
 
-		// It is no longer OK for server to omit the commitment.
-		payload := &msgjson.PreimageRequest{
-			OrderID: oid[:],
-			// No commitment in this request.
-		}
-		reqNoCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
-		// mkt := dc.marketConfig(tDcrBtcMktName)
+	// So far, the fee suggestion should have always been zero.
+	if tWallet.sendFeeSuggestion != 0 {
+		t.Fatalf("unexpected non-zero fee rate when no books or responses prepared")
+	}
 
-		tracker := &trackedTrade{
-			Order:    ord,
-			preImg:   preImg,
-			mktID:    tDcrBtcMktName,
-			db:       rig.db,
-			dc:       rig.dc,
-			metaData: &db.OrderMetaData{},
-		}
+	const feeRate = 54321
 
-		// resetCsum resets csum for further preimage request since multiple
-		// testing scenarios use the same tracker object.
-		resetCsum := func(tracker *trackedTrade) {
-			tracker.mtx.Lock()
-			tracker.csum = nil
-			tracker.mtx.Unlock()
-		}
+	feeRater := &TFeeRater{
+		TXCWallet: tWallet,
+		feeRate:   feeRate,
+	}
 
-		rig.dc.trades[oid] = tracker
-		err := handlePreimageRequest(rig.core, rig.dc, reqNoCommit)
-		if err == nil {
-			t.Fatalf("handlePreimageRequest succeeded with no commitment in the request")
-		}
-		resetCsum(tracker)
+	wallet.Wallet = feeRater
 
-		// Test the new path with rig.core.sentCommits.
-		readyCommitment := func(commit order.Commitment) chan struct{} {
-			commitSig := make(chan struct{}) // close after fake order submission is "done"
-			rig.core.sentCommitsMtx.Lock()
-			rig.core.sentCommits[commit] = commitSig
-			rig.core.sentCommitsMtx.Unlock()
-			return commitSig
-		}
+	coin, err = tCore.Send(tPW, tUTXOAssetA.ID, 2e8, address, false)
+	if err != nil {
+		t.Fatalf("FeeRater Withdraw/send error: %v", err)
+	}
+	if coin.Value() != 2e8 {
+		t.Fatalf("Expected sent value to be %v, got %v", 2e8, coin.Value())
+	}
 
-		commit := preImg.Commit()
-		commitSig := readyCommitment(commit)
-		payload = &msgjson.PreimageRequest{
-			OrderID:    oid[:],
-			Commitment: commit[:],
-		}
-		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
+	if tWallet.sendFeeSuggestion != feeRate {
+		t.Fatalf("unexpected fee rate from FeeRater. wanted %d, got %d", feeRate, tWallet.sendFeeSuggestion)
+	}
 
-		notes := rig.core.NotificationFeed()
+	// wallet is not synced
+	wallet.syncStatus.Synced = false
+	_, err = tCore.Send(tPW, tUTXOAssetA.ID, 1e8, address, false)
+	if err == nil {
+		t.Fatalf("Expected error for a non-synchronized wallet")
+	}
+}
 
-		rig.dc.trades[oid] = tracker
-		err = handlePreimageRequest(rig.core, rig.dc, reqCommit)
-		if err != nil {
-			t.Fatalf("handlePreimageRequest error: %v", err)
-		}
-		resetCsum(tracker)
+func TestSendWithFeeLimit(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
+	tWallet.sendCoin = &tCoin{id: encode.RandomBytes(36)}
+	tWallet.validAddr = true
+	tWallet.bal = &asset.Balance{
+		Available:    5e8,
+		Locked:       2e8,
+		BondReserves: 1e8,
+	}
+	tWallet.estFee = 1e4
+	const address = "addr"
+	const maxFee = 2e4
 
-		// It has gone async now, waiting for commitSig.
-		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
-		close(commitSig) // pretend like the order submission just finished
+	// Normal send
+	txID, err := tCore.SendWithFeeLimit(tPW, tUTXOAssetA.ID, address, 1e8, maxFee, false)
+	if err != nil {
+		t.Fatalf("SendWithFeeLimit error: %v", err)
+	}
+	if txID != tWallet.sendCoin.TxID() {
+		t.Fatalf("wrong tx ID %s", txID)
+	}
+	if tWallet.sendCoin.val != 1e8 {
+		t.Fatalf("wrong amount sent %d", tWallet.sendCoin.val)
+	}
 
-		select {
-		case note := <-notes.C:
-			if note.Topic() != TopicPreimageSent {
-				t.Fatalf("note subject is %v, not %v", note.Topic(), TopicPreimageSent)
-			}
-		case <-time.After(time.Second):
-			t.Fatal("no order note from preimage request handling")
-		}
+	// Send max withdraws the available balance only, leaving locked funds and
+	// bond reserves.
+	if _, err = tCore.SendWithFeeLimit(tPW, tUTXOAssetA.ID, address, 0, maxFee, true); err != nil {
+		t.Fatalf("SendWithFeeLimit send max error: %v", err)
+	}
+	if tWallet.withdrawValue != 5e8 {
+		t.Fatalf("send max withdrew %d, expected %d", tWallet.withdrawValue, uint64(5e8))
+	}
+	if tWallet.sendCoin.val != 5e8-1e4 {
+		t.Fatalf("send max sent %d, expected %d", tWallet.sendCoin.val, uint64(5e8-1e4))
+	}
 
-		// negative paths
-		ensureErr := func(tag string, req *msgjson.Message, errPrefix string) {
-			t.Helper()
-			commitSig := readyCommitment(commit)
-			close(commitSig) // ready before preimage request
-			err := handlePreimageRequest(rig.core, rig.dc, req)
-			if err == nil {
-				t.Fatalf("%s: no error", tag)
-			}
-			if !strings.HasPrefix(err.Error(), errPrefix) {
-				t.Fatalf("expected error starting with %q, got %q", errPrefix, err)
-			}
-			resetCsum(tracker)
-		}
+	// Fee exceeds the limit
+	tWallet.sendCoin.val = 0
+	tWallet.estFee = maxFee + 1
+	if _, err = tCore.SendWithFeeLimit(tPW, tUTXOAssetA.ID, address, 1e8, maxFee, false); err == nil {
+		t.Fatalf("no error for fee exceeding limit")
+	}
+	if tWallet.sendCoin.val != 0 {
+		t.Fatalf("sent despite fee exceeding limit")
+	}
+	tWallet.estFee = 1e4
 
-		// unknown commitment in request
-		payloadBad := &msgjson.PreimageRequest{
-			OrderID:    oid[:],
-			Commitment: encode.RandomBytes(order.CommitmentSize), // junk, but correct length
-		}
-		reqCommitBad, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payloadBad)
-		ensureErr("unknown commitment", reqCommitBad, "received preimage request for unknown commitment")
-	})
-	t.Run("csum for order", func(t *testing.T) {
-		rig := newTestRig()
-		defer rig.shutdown()
-		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
-		oid := ord.ID()
-		preImg := newPreimage()
-		// mkt := dc.marketConfig(tDcrBtcMktName)
+	// More than the available balance
+	if _, err = tCore.SendWithFeeLimit(tPW, tUTXOAssetA.ID, address, 6e8, maxFee, false); err == nil {
+		t.Fatalf("no error for sending more than the available balance")
+	}
 
-		tracker := &trackedTrade{
-			Order:    ord,
-			preImg:   preImg,
-			mktID:    tDcrBtcMktName,
-			db:       rig.db,
-			dc:       rig.dc,
-			metaData: &db.OrderMetaData{},
-		}
+	// Fee estimation error
+	tWallet.estFeeErr = tErr
+	if _, err = tCore.SendWithFeeLimit(tPW, tUTXOAssetA.ID, address, 1e8, maxFee, false); err == nil {
+		t.Fatalf("no error for fee estimation error")
+	}
+	tWallet.estFeeErr = nil
 
-		// Test the new path with rig.core.sentCommits.
-		readyCommitment := func(commit order.Commitment) chan struct{} {
-			commitSig := make(chan struct{}) // close after fake order submission is "done"
-			rig.core.sentCommitsMtx.Lock()
-			rig.core.sentCommits[commit] = commitSig
-			rig.core.sentCommitsMtx.Unlock()
-			return commitSig
-		}
+	// Invalid address
+	tWallet.validAddr = false
+	if _, err = tCore.SendWithFeeLimit(tPW, tUTXOAssetA.ID, address, 1e8, maxFee, false); err == nil {
+		t.Fatalf("no error for invalid address")
+	}
+}
 
-		commit := preImg.Commit()
-		commitCSum := dex.Bytes{2, 3, 5, 7, 11, 13}
-		commitSig := readyCommitment(commit)
-		payload := &msgjson.PreimageRequest{
-			OrderID:        oid[:],
-			Commitment:     commit[:],
-			CommitChecksum: commitCSum,
-		}
-		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
+func trade(t *testing.T, async bool) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	dcrWallet.hookedUp = false
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
+	dcrWallet.Unlock(rig.crypter)
+	_ = dcrWallet.Connect() // connector will panic on Wait, and sync status goroutines will exit if disconnected
+	defer dcrWallet.Disconnect()
+	syncTickerPeriod = 10 * time.Millisecond
 
-		notes := rig.core.NotificationFeed()
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
+	btcWallet.Unlock(rig.crypter)
 
-		rig.dc.trades[oid] = tracker
-		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
-		if err != nil {
-			t.Fatalf("handlePreimageRequest error: %v", err)
-		}
+	ethWallet, tEthWallet := newTAccountLocker(tACCTAsset.ID)
+	tCore.wallets[tACCTAsset.ID] = ethWallet
+	ethWallet.address = "18d65fb8d60c1199bb1ad381be47aa692b482605"
+	ethWallet.Unlock(rig.crypter)
 
-		// It has gone async now, waiting for commitSig.
-		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
-		close(commitSig) // pretend like the order submission just finished
+	var lots uint64 = 10
+	qty := dcrBtcLotSize * lots
+	rate := dcrBtcRateStep * 1000
 
-		select {
-		case note := <-notes.C:
-			if note.Topic() != TopicPreimageSent {
-				t.Fatalf("note subject is %v, not %v", note.Topic(), TopicPreimageSent)
-			}
-		case <-time.After(time.Second):
-			t.Fatal("no order note from preimage request handling")
-		}
+	form := &TradeForm{
+		Host:    tDexHost,
+		IsLimit: true,
+		Sell:    true,
+		Base:    tUTXOAssetA.ID,
+		Quote:   tUTXOAssetB.ID,
+		Qty:     qty,
+		Rate:    rate,
+		TifNow:  false,
+	}
 
-		tracker.mtx.RLock()
-		if !bytes.Equal(commitCSum, tracker.csum) {
-			t.Fatalf(
-				"handlePreimageRequest must initialize tracker csum, exp: %s, got: %s",
-				commitCSum,
-				tracker.csum,
-			)
-		}
-		tracker.mtx.RUnlock()
-	})
-	t.Run("more than one preimage request for order (different csums)", func(t *testing.T) {
-		rig := newTestRig()
-		defer rig.shutdown()
-		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
-		oid := ord.ID()
-		preImg := newPreimage()
-		// mkt := dc.marketConfig(tDcrBtcMktName)
-		firstCSum := dex.Bytes{2, 3, 5, 7, 11, 13}
+	dcrCoin := &tCoin{
+		id:  encode.RandomBytes(36),
+		val: qty * 2,
+	}
+	tDcrWallet.fundingCoins = asset.Coins{dcrCoin}
+	tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
 
-		tracker := &trackedTrade{
-			Order:  ord,
-			preImg: preImg,
-			mktID:  tDcrBtcMktName,
-			db:     rig.db,
-			dc:     rig.dc,
-			// Simulate first preimage request by initializing csum here.
-			csum:     firstCSum,
-			metaData: &db.OrderMetaData{},
-		}
+	btcVal := calc.BaseToQuote(rate, qty*2)
+	btcCoin := &tCoin{
+		id:  encode.RandomBytes(36),
+		val: btcVal,
+	}
+	tBtcWallet.fundingCoins = asset.Coins{btcCoin}
+	tBtcWallet.fundRedeemScripts = []dex.Bytes{nil}
 
-		// Test the new path with rig.core.sentCommits.
-		readyCommitment := func(commit order.Commitment) chan struct{} {
-			commitSig := make(chan struct{}) // close after fake order submission is "done"
-			rig.core.sentCommitsMtx.Lock()
-			rig.core.sentCommits[commit] = commitSig
-			rig.core.sentCommitsMtx.Unlock()
-			return commitSig
-		}
+	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	rig.dc.books[tDcrBtcMktName] = book
 
-		commit := preImg.Commit()
-		commitSig := readyCommitment(commit)
-		secondCSum := dex.Bytes{2, 3, 5, 7, 11, 14}
-		payload := &msgjson.PreimageRequest{
-			OrderID:        oid[:],
-			Commitment:     commit[:],
-			CommitChecksum: secondCSum,
-		}
-		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
+	msgOrderNote := &msgjson.BookOrderNote{
+		OrderNote: msgjson.OrderNote{
+			OrderID: encode.RandomBytes(32),
+		},
+		TradeNote: msgjson.TradeNote{
+			Side:     msgjson.SellOrderNum,
+			Quantity: dcrBtcLotSize,
+			Time:     uint64(time.Now().Unix()),
+			Rate:     rate,
+		},
+	}
 
-		// Prepare to have processPreimageRequest respond with a payload with
-		// the Error field set.
-		rig.ws.sendMsgErrChan = make(chan *msgjson.Error, 1)
-		defer func() { rig.ws.sendMsgErrChan = nil }()
+	err := book.Sync(&msgjson.OrderBook{
+		MarketID: tDcrBtcMktName,
+		Seq:      1,
+		Epoch:    1,
+		Orders:   []*msgjson.BookOrderNote{msgOrderNote},
+	})
+	if err != nil {
+		t.Fatalf("order book sync error: %v", err)
+	}
 
-		rig.dc.trades[oid] = tracker
-		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
+	badSig := false
+	noID := false
+	badID := false
+	handleLimit := func(msg *msgjson.Message, f msgFunc) error {
+		t.Helper()
+		// Need to stamp and sign the message with the server's key.
+		msgOrder := new(msgjson.LimitOrder)
+		err := msg.Unmarshal(msgOrder)
 		if err != nil {
-			t.Fatalf("handlePreimageRequest error: %v", err)
+			t.Fatalf("unmarshal error: %v", err)
 		}
+		lo := convertMsgLimitOrder(msgOrder)
+		f(orderResponse(msg.ID, msgOrder, lo, badSig, noID, badID))
+		return nil
+	}
 
-		// It has gone async now, waiting for commitSig.
-		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
-		close(commitSig) // pretend like the order submission just finished
-
-		select {
-		case msgErr := <-rig.ws.sendMsgErrChan:
-			if msgErr.Code != msgjson.InvalidRequestError {
-				t.Fatalf("expected error code %d got %d", msgjson.InvalidRequestError, msgErr.Code)
-			}
-		case <-time.After(time.Second):
-			t.Fatal("no msgjson.Error sent from preimage request handling")
+	handleMarket := func(msg *msgjson.Message, f msgFunc) error {
+		t.Helper()
+		// Need to stamp and sign the message with the server's key.
+		msgOrder := new(msgjson.MarketOrder)
+		err := msg.Unmarshal(msgOrder)
+		if err != nil {
+			t.Fatalf("unmarshal error: %v", err)
 		}
+		mo := convertMsgMarketOrder(msgOrder)
+		f(orderResponse(msg.ID, msgOrder, mo, badSig, noID, badID))
+		return nil
+	}
 
-		tracker.mtx.RLock()
-		if !bytes.Equal(firstCSum, tracker.csum) {
-			t.Fatalf(
-				"[handlePreimageRequest] csum was changed, exp: %s, got: %s",
-				firstCSum,
-				tracker.csum,
-			)
-		}
-		tracker.mtx.RUnlock()
-	})
-	t.Run("more than one preimage request for order (same csum)", func(t *testing.T) {
-		rig := newTestRig()
-		defer rig.shutdown()
-		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
-		oid := ord.ID()
-		preImg := newPreimage()
-		// mkt := dc.marketConfig(tDcrBtcMktName)
-		csum := dex.Bytes{2, 3, 5, 7, 11, 13}
-
-		tracker := &trackedTrade{
-			Order:  ord,
-			preImg: preImg,
-			mktID:  tDcrBtcMktName,
-			db:     rig.db,
-			dc:     rig.dc,
-			// Simulate first preimage request by initializing csum here.
-			csum:     csum,
-			metaData: &db.OrderMetaData{},
+	ch := tCore.NotificationFeed() // detect when sync goroutine completes
+	waitForOrderNotification := func() (*Order, uint64, error) {
+		var corder *Order
+		var tempID uint64
+	wait:
+		for {
+			select {
+			case note := <-ch.C:
+				if note.Type() == NoteTypeOrder {
+					n, ok := note.(*OrderNote)
+					if !ok {
+						t.Fatalf("Expected OrderNote type, got %T", note)
+					}
+					if note.Topic() == TopicAsyncOrderSubmitted {
+						tempID = n.TemporaryID
+					} else if tempID == n.TemporaryID && note.Topic() == TopicAsyncOrderFailure {
+						return nil, tempID, fmt.Errorf("%v", note.Details())
+					} else {
+						corder = n.Order
+						break wait
+					}
+				}
+			case <-time.After(1 * time.Second):
+				t.Fatal("Failed to receive queued order note")
+			}
 		}
+		return corder, tempID, nil
+	}
 
-		// Test the new path with rig.core.sentCommits.
-		readyCommitment := func(commit order.Commitment) chan struct{} {
-			commitSig := make(chan struct{}) // close after fake order submission is "done"
-			rig.core.sentCommitsMtx.Lock()
-			rig.core.sentCommits[commit] = commitSig
-			rig.core.sentCommitsMtx.Unlock()
-			return commitSig
+	trade := func() (*Order, error) {
+		if !async {
+			return tCore.Trade(tPW, form)
 		}
 
-		commit := preImg.Commit()
-		commitSig := readyCommitment(commit)
-		payload := &msgjson.PreimageRequest{
-			OrderID:        oid[:],
-			Commitment:     commit[:],
-			CommitChecksum: csum,
+		inFlight, err := tCore.TradeAsync(tPW, form)
+		if err != nil {
+			return nil, err
 		}
-		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
-
-		notes := rig.core.NotificationFeed()
 
-		rig.dc.trades[oid] = tracker
-		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
+		corder, tempID, err := waitForOrderNotification()
 		if err != nil {
-			t.Fatalf("handlePreimageRequest error: %v", err)
+			return nil, err
 		}
 
-		// It has gone async now, waiting for commitSig.
-		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
-		close(commitSig) // pretend like the order submission just finished
-
-		select {
-		case note := <-notes.C:
-			if note.Topic() != TopicPreimageSent {
-				t.Fatalf("note subject is %v, not %v", note.Topic(), TopicPreimageSent)
-			}
-		case <-time.After(time.Second):
-			t.Fatal("no order note from preimage request handling")
+		if inFlight.TemporaryID != tempID {
+			t.Fatalf("received wrong in-flight order, expected %d got %d", inFlight.TemporaryID, tempID)
 		}
 
-		tracker.mtx.RLock()
-		if !bytes.Equal(csum, tracker.csum) {
-			t.Fatalf(
-				"[handlePreimageRequest] csum was changed, exp: %s, got: %s",
-				csum,
-				tracker.csum,
-			)
-		}
-		tracker.mtx.RUnlock()
-	})
-	t.Run("csum for cancel order", func(t *testing.T) {
-		rig := newTestRig()
-		defer rig.shutdown()
-		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
-		preImg := newPreimage()
-		mkt := rig.dc.marketConfig(tDcrBtcMktName)
+		return corder, nil
+	}
 
-		tracker := &trackedTrade{
-			Order:    ord,
-			preImg:   preImg,
-			mktID:    tDcrBtcMktName,
-			db:       rig.db,
-			dc:       rig.dc,
-			metaData: &db.OrderMetaData{},
-			cancel: &trackedCancel{
-				CancelOrder: order.CancelOrder{
-					P: order.Prefix{
-						AccountID:  rig.dc.acct.ID(),
-						BaseAsset:  tUTXOAssetA.ID,
-						QuoteAsset: tUTXOAssetB.ID,
-						OrderType:  order.MarketOrderType,
-						ClientTime: time.Now(),
-						ServerTime: time.Now().Add(time.Millisecond),
-						Commit:     preImg.Commit(),
-					},
-				},
-				epochLen: mkt.EpochLen,
-			},
+	ensureOrderErr := func(tag string, waitForErr bool) {
+		t.Helper()
+		var err error
+		if async {
+			_, err = tCore.TradeAsync(tPW, form)
+		} else {
+			_, err = tCore.Trade(tPW, form)
 		}
-		oid := tracker.cancel.ID()
-
-		// Test the new path with rig.core.sentCommits.
-		readyCommitment := func(commit order.Commitment) chan struct{} {
-			commitSig := make(chan struct{}) // close after fake order submission is "done"
-			rig.core.sentCommitsMtx.Lock()
-			rig.core.sentCommits[commit] = commitSig
-			rig.core.sentCommitsMtx.Unlock()
-			return commitSig
+		if !waitForErr && err == nil {
+			t.Fatalf("%s: no error", tag)
 		}
 
-		commit := preImg.Commit()
-		commitCSum := dex.Bytes{2, 3, 5, 7, 11, 13}
-		commitSig := readyCommitment(commit)
-		payload := &msgjson.PreimageRequest{
-			OrderID:        oid[:],
-			Commitment:     commit[:],
-			CommitChecksum: commitCSum,
+		if waitForErr {
+			_, _, err := waitForOrderNotification()
+			if err == nil {
+				t.Fatalf("%s: no error for queued order", tag)
+			}
 		}
-		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
-
-		notes := rig.core.NotificationFeed()
+	}
 
-		rig.dc.trades[order.OrderID{}] = tracker
-		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
-		if err != nil {
-			t.Fatalf("handlePreimageRequest error: %v", err)
-		}
+	ensureErr := func(tag string) {
+		t.Helper()
+		ensureOrderErr(tag, false)
+	}
 
-		// It has gone async now, waiting for commitSig.
-		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
-		close(commitSig) // pretend like the order submission just finished
+	// Initial success
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	corder, err := trade()
+	if err != nil {
+		t.Fatalf("limit order error: %v", err)
+	}
+	t.Logf("Order with ID(%s) has been placed successfully!", corder.ID.String())
 
-		select {
-		case note := <-notes.C:
-			if note.Topic() != TopicCancelPreimageSent {
-				t.Fatalf("note subject is %v, not %v", note.Topic(), TopicCancelPreimageSent)
-			}
-		case <-time.After(time.Second):
-			t.Fatal("no order note from preimage request handling")
-		}
+	// Check that the Fund request for a limit sell came through and that
+	// value was not adjusted internally with BaseToQuote.
+	if tDcrWallet.fundedVal != qty {
+		t.Fatalf("limit sell expected funded value %d, got %d", qty, tDcrWallet.fundedVal)
+	}
+	tDcrWallet.fundedVal = 0
+	if tDcrWallet.fundedSwaps != lots {
+		t.Fatalf("limit sell expected %d max swaps, got %d", lots, tDcrWallet.fundedSwaps)
+	}
+	tDcrWallet.fundedSwaps = 0
 
-		tracker.mtx.RLock()
-		if !bytes.Equal(commitCSum, tracker.cancel.csum) {
-			t.Fatalf(
-				"handlePreimageRequest must initialize tracker cancel csum, exp: %s, got: %s",
-				commitCSum,
-				tracker.cancel.csum,
-			)
-		}
-		tracker.mtx.RUnlock()
-	})
-	t.Run("more than one preimage request for cancel order (different csums)", func(t *testing.T) {
-		rig := newTestRig()
-		defer rig.shutdown()
-		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
-		preImg := newPreimage()
-		mkt := rig.dc.marketConfig(tDcrBtcMktName)
-		firstCSum := dex.Bytes{2, 3, 5, 7, 11, 13}
+	// Should not be able to close wallet now, since there are orders.
+	if tCore.CloseWallet(tUTXOAssetA.ID) == nil {
+		t.Fatalf("no error for closing DCR wallet with active orders")
+	}
+	if tCore.CloseWallet(tUTXOAssetB.ID) == nil {
+		t.Fatalf("no error for closing BTC wallet with active orders")
+	}
 
-		tracker := &trackedTrade{
-			Order:    ord,
-			preImg:   preImg,
-			mktID:    tDcrBtcMktName,
-			db:       rig.db,
-			dc:       rig.dc,
-			metaData: &db.OrderMetaData{},
-			cancel: &trackedCancel{
-				// Simulate first preimage request by initializing csum here.
-				csum: firstCSum,
-				CancelOrder: order.CancelOrder{
-					P: order.Prefix{
-						AccountID:  rig.dc.acct.ID(),
-						BaseAsset:  tUTXOAssetA.ID,
-						QuoteAsset: tUTXOAssetB.ID,
-						OrderType:  order.MarketOrderType,
-						ClientTime: time.Now(),
-						ServerTime: time.Now().Add(time.Millisecond),
-						Commit:     preImg.Commit(),
-					},
-				},
-				epochLen: mkt.EpochLen,
-			},
-		}
-		oid := tracker.cancel.ID()
+	// Should not be able to disable wallet, since there are active orders.
+	if tCore.ToggleWalletStatus(tUTXOAssetA.ID, true) == nil {
+		t.Fatalf("no error for disabling DCR wallet with active orders")
+	}
+	if tCore.ToggleWalletStatus(tUTXOAssetB.ID, true) == nil {
+		t.Fatalf("no error for disabling BTC wallet with active orders")
+	}
 
-		// Test the new path with rig.core.sentCommits.
-		readyCommitment := func(commit order.Commitment) chan struct{} {
-			commitSig := make(chan struct{}) // close after fake order submission is "done"
-			rig.core.sentCommitsMtx.Lock()
-			rig.core.sentCommits[commit] = commitSig
-			rig.core.sentCommitsMtx.Unlock()
-			return commitSig
-		}
-
-		commit := preImg.Commit()
-		secondCSum := dex.Bytes{2, 3, 5, 7, 11, 14}
-		commitSig := readyCommitment(commit)
-		payload := &msgjson.PreimageRequest{
-			OrderID:        oid[:],
-			Commitment:     commit[:],
-			CommitChecksum: secondCSum,
-		}
-		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
+	// We want to set peerCount to 0 (from 1), but we'll do this the hard way to
+	// ensure the peerChange handler works as intended.
+	// dcrWallet.mtx.Lock()
+	// dcrWallet.peerCount = 0
+	// dcrWallet.mtx.Unlock()
+	tCore.peerChange(dcrWallet, 0, nil)
+	_, err = tCore.Trade(tPW, form)
+	if err == nil {
+		t.Fatalf("no error for no peers")
+	}
+	tCore.peerChange(dcrWallet, 1, nil)
 
-		// Prepare to have processPreimageRequest respond with a payload with
-		// the Error field set.
-		rig.ws.sendMsgErrChan = make(chan *msgjson.Error, 1)
-		defer func() { rig.ws.sendMsgErrChan = nil }()
+	// Dex not found
+	form.Host = "someotherdex.org"
+	_, err = tCore.Trade(tPW, form)
+	if err == nil {
+		t.Fatalf("no error for unknown dex")
+	}
+	form.Host = tDexHost
 
-		rig.dc.trades[order.OrderID{}] = tracker
-		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
-		if err != nil {
-			t.Fatalf("handlePreimageRequest error: %v", err)
-		}
+	// Account locked = probably not logged in
+	rig.dc.acct.lock()
+	_, err = tCore.Trade(tPW, form)
+	if err == nil {
+		t.Fatalf("no error for disconnected dex")
+	}
+	rig.dc.acct.unlock(rig.crypter)
 
-		// It has gone async now, waiting for commitSig.
-		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
-		close(commitSig) // pretend like the order submission just finished
+	// DEX not connected
+	atomic.StoreUint32(&rig.dc.connectionStatus, uint32(comms.Disconnected))
+	_, err = tCore.Trade(tPW, form)
+	if err == nil {
+		t.Fatalf("no error for disconnected dex")
+	}
+	atomic.StoreUint32(&rig.dc.connectionStatus, uint32(comms.Connected))
 
-		select {
-		case msgErr := <-rig.ws.sendMsgErrChan:
-			if msgErr.Code != msgjson.InvalidRequestError {
-				t.Fatalf("expected error code %d got %d", msgjson.InvalidRequestError, msgErr.Code)
-			}
-		case <-time.After(time.Second):
-			t.Fatal("no msgjson.Error sent from preimage request handling")
-		}
-		tracker.mtx.RLock()
-		if !bytes.Equal(firstCSum, tracker.cancel.csum) {
-			t.Fatalf(
-				"[handlePreimageRequest] cancel csum was changed, exp: %s, got: %s",
-				firstCSum,
-				tracker.cancel.csum,
-			)
-		}
-		tracker.mtx.RUnlock()
-	})
-	t.Run("more than one preimage request for cancel order (same csum)", func(t *testing.T) {
-		rig := newTestRig()
-		defer rig.shutdown()
-		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
-		preImg := newPreimage()
-		mkt := rig.dc.marketConfig(tDcrBtcMktName)
-		csum := dex.Bytes{2, 3, 5, 7, 11, 13}
+	setWalletSyncStatus := func(w *xcWallet, status bool) {
+		w.mtx.Lock()
+		w.syncStatus.Synced = status
+		w.mtx.Unlock()
+	}
 
-		tracker := &trackedTrade{
-			Order:    ord,
-			preImg:   preImg,
-			mktID:    tDcrBtcMktName,
-			db:       rig.db,
-			dc:       rig.dc,
-			metaData: &db.OrderMetaData{},
-			cancel: &trackedCancel{
-				// Simulate first preimage request by initializing csum here.
-				csum: csum,
-				CancelOrder: order.CancelOrder{
-					P: order.Prefix{
-						AccountID:  rig.dc.acct.ID(),
-						BaseAsset:  tUTXOAssetA.ID,
-						QuoteAsset: tUTXOAssetB.ID,
-						OrderType:  order.MarketOrderType,
-						ClientTime: time.Now(),
-						ServerTime: time.Now().Add(time.Millisecond),
-						Commit:     preImg.Commit(),
-					},
-				},
-				epochLen: mkt.EpochLen,
-			},
-		}
-		oid := tracker.cancel.ID()
+	// No base asset
+	form.Base = 12345
+	ensureErr("bad base asset")
+	form.Base = tUTXOAssetA.ID
 
-		// Test the new path with rig.core.sentCommits.
-		readyCommitment := func(commit order.Commitment) chan struct{} {
-			commitSig := make(chan struct{}) // close after fake order submission is "done"
-			rig.core.sentCommitsMtx.Lock()
-			rig.core.sentCommits[commit] = commitSig
-			rig.core.sentCommitsMtx.Unlock()
-			return commitSig
-		}
+	// No quote asset
+	form.Quote = 12345
+	ensureErr("bad quote asset")
+	form.Quote = tUTXOAssetB.ID
 
-		commit := preImg.Commit()
-		commitSig := readyCommitment(commit)
-		payload := &msgjson.PreimageRequest{
-			OrderID:        oid[:],
-			Commitment:     commit[:],
-			CommitChecksum: csum,
-		}
-		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
+	// Limit order zero rate
+	form.Rate = 0
+	ensureErr("zero rate limit")
+	form.Rate = rate
 
-		notes := rig.core.NotificationFeed()
+	// Limit order outside of the price band around the mid-gap, which is the
+	// rate of the single booked sell order.
+	tCore.SetPriceBand(10)
+	form.Rate = rate * 2
+	ensureErr("out of price band")
+	form.Rate = rate
+	tCore.SetPriceBand(0)
 
-		rig.dc.trades[order.OrderID{}] = tracker
-		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
-		if err != nil {
-			t.Fatalf("handlePreimageRequest error: %v", err)
-		}
+	// No from wallet
+	tCore.walletMtx.Lock()
+	delete(tCore.wallets, tUTXOAssetA.ID)
+	tCore.walletMtx.Unlock()
+	ensureErr("no dcr wallet")
+	tCore.walletMtx.Lock()
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	tCore.walletMtx.Unlock()
 
-		// It has gone async now, waiting for commitSig.
-		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
-		close(commitSig) // pretend like the order submission just finished
+	// No to wallet
+	tCore.walletMtx.Lock()
+	delete(tCore.wallets, tUTXOAssetB.ID)
+	tCore.walletMtx.Unlock()
+	ensureErr("no btc wallet")
+	tCore.walletMtx.Lock()
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	tCore.walletMtx.Unlock()
 
-		select {
-		case note := <-notes.C:
-			if note.Topic() != TopicCancelPreimageSent {
-				t.Fatalf("note subject is %v, not %v", note.Topic(), TopicCancelPreimageSent)
-			}
-		case <-time.After(time.Second):
-			t.Fatal("no order note from preimage request handling")
-		}
+	// Address error
+	tBtcWallet.addrErr = tErr
+	ensureErr("address error")
+	tBtcWallet.addrErr = nil
 
-		tracker.mtx.RLock()
-		if !bytes.Equal(csum, tracker.cancel.csum) {
-			t.Fatalf(
-				"[handlePreimageRequest] cancel csum was changed, exp: %s, got: %s",
-				csum,
-				tracker.cancel.csum,
-			)
-		}
-		tracker.mtx.RUnlock()
-	})
-}
+	// Not enough funds
+	tDcrWallet.fundingCoinErr = tErr
+	ensureErr("funds error")
+	tDcrWallet.fundingCoinErr = nil
 
-func TestHandleRevokeOrderMsg(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	dc := rig.dc
-	tCore := rig.core
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
-	dcrWallet.Unlock(rig.crypter)
+	// Lot size violation
+	ogQty := form.Qty
+	form.Qty += dcrBtcLotSize / 2
+	ensureErr("bad size")
+	form.Qty = ogQty
 
-	fundCoinDcrID := encode.RandomBytes(36)
-	fundCoinDcr := &tCoin{id: fundCoinDcrID}
+	// Rate step violation
+	form.Rate = rate + dcrBtcRateStep/2
+	ensureErr("bad rate step")
+	form.Rate = rate
 
-	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
-	btcWallet.Unlock(rig.crypter)
+	// Below the market's minimum order size
+	mktConf := rig.dc.marketConfig(tDcrBtcMktName)
+	mktConf.MinOrderLots = uint32(lots + 1)
+	ensureErr("below min lots")
+	mktConf.MinOrderLots = 0
+
+	// The dust limit advertised for the MaxFeeRate is checked at the server's
+	// current fee rate.
+	const serverFeeRate = 4
+	queueServerFeeRate := func() {
+		rig.ws.mtx.Lock()
+		delete(rig.ws.handlers, msgjson.FeeRateRoute)
+		rig.ws.mtx.Unlock()
+		for i := 0; i < 3; i++ {
+			rig.ws.queueResponse(msgjson.FeeRateRoute, func(msg *msgjson.Message, f msgFunc) error {
+				var assetID uint32
+				msg.Unmarshal(&assetID)
+				if assetID != tUTXOAssetA.ID {
+					return tErr
+				}
+				resp, _ := msgjson.NewResponse(msg.ID, serverFeeRate, nil)
+				f(resp)
+				return nil
+			})
+		}
+	}
+	dcrAsset := rig.dc.assetConfig(tUTXOAssetA.ID)
+	dcrAsset.DustLimit = (dcrBtcLotSize/serverFeeRate + 1) * dcrAsset.MaxFeeRate
+	queueServerFeeRate()
+	// The limit response is for the next order, since the order is not sent.
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	ensureErr("below dust limit")
+	// Below the limit at the MaxFeeRate, but not at the current fee rate.
+	dcrAsset.DustLimit = dcrBtcLotSize / serverFeeRate * dcrAsset.MaxFeeRate
+	queueServerFeeRate()
+	if _, err := trade(); err != nil {
+		t.Fatalf("error for order above the dust limit at the current fee rate: %v", err)
+	}
+	dcrAsset.DustLimit = 0
+	rig.ws.mtx.Lock()
+	delete(rig.ws.handlers, msgjson.FeeRateRoute)
+	rig.ws.mtx.Unlock()
+
+	// At the market's limit on standing orders
+	walletSet, _, _, _ := tCore.walletSet(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	booked := makeTradeTracker(rig, walletSet, order.StandingTiF, order.OrderStatusBooked)
+	rig.dc.tradeMtx.Lock()
+	rig.dc.trades[booked.ID()] = booked
+	rig.dc.tradeMtx.Unlock()
+	standing := rig.dc.standingOrders(tDcrBtcMktName)
+	mktConf.MaxOpenOrders = uint32(standing)
+	ensureErr("at max open orders")
+	if mkt := rig.dc.coreMarket(tDcrBtcMktName); mkt.StandingOrders != standing {
+		t.Fatalf("expected %d standing orders, got %d", standing, mkt.StandingOrders)
+	}
+	// Canceling the order frees capacity.
+	booked.mtx.Lock()
+	booked.metaData.Status = order.OrderStatusCanceled
+	booked.mtx.Unlock()
+	if n := rig.dc.standingOrders(tDcrBtcMktName); n != standing-1 {
+		t.Fatalf("expected %d standing orders after cancel, got %d", standing-1, n)
+	}
+	rig.dc.tradeMtx.Lock()
+	delete(rig.dc.trades, booked.ID())
+	rig.dc.tradeMtx.Unlock()
+	mktConf.MaxOpenOrders = 0
 
-	// fundCoinBID := encode.RandomBytes(36)
-	// fundCoinB := &tCoin{id: fundCoinBID}
+	// Coin signature error
+	tDcrWallet.signCoinErr = tErr
+	ensureErr("signature error")
+	tDcrWallet.signCoinErr = nil
 
-	qty := 2 * dcrBtcLotSize
-	rate := dcrBtcRateStep * 10
-	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, rate) // sell DCR
-	lo.Coins = []order.CoinID{fundCoinDcrID}
-	dbOrder.MetaData.Status = order.OrderStatusBooked
-	oid := lo.ID()
+	// Sync-in-progress error
+	setWalletSyncStatus(dcrWallet, false)
+	ensureErr("base not synced")
+	setWalletSyncStatus(dcrWallet, true)
 
-	tDcrWallet.fundingCoins = asset.Coins{fundCoinDcr}
+	setWalletSyncStatus(btcWallet, false)
+	ensureErr("quote not synced")
+	setWalletSyncStatus(btcWallet, true)
 
-	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
-	if err != nil {
-		t.Fatalf("walletSet error: %v", err)
-	}
+	// LimitRoute error
+	rig.ws.reqErr = tErr
+	ensureOrderErr("Request error", async)
+	rig.ws.reqErr = nil
 
-	// Not in dc.trades yet.
+	// The rest need a queued handler
 
-	// Send a request for the unknown order.
-	payload := &msgjson.RevokeOrder{
-		OrderID: oid[:],
+	// Bad signature
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	badSig = true
+	ensureOrderErr("bad server sig", async)
+	badSig = false
+
+	// No order ID in response
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	noID = true
+	ensureOrderErr("no ID", async)
+	noID = false
+
+	// Wrong order ID in response
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	badID = true
+	ensureOrderErr("no ID", async)
+	badID = false
+
+	// Storage failure
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	rig.db.updateOrderErr = tErr
+	ensureOrderErr("db failure", async)
+	rig.db.updateOrderErr = nil
+
+	// Success when buying.
+	form.Sell = false
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	corder, err = trade()
+	if err != nil {
+		t.Fatalf("limit order error: %v", err)
 	}
-	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.RevokeOrderRoute, payload)
+	t.Logf("Order with ID(%s) has been placed successfully!", corder.ID.String())
 
-	// Ensure revoking a non-existent order generates an error.
-	err = handleRevokeOrderMsg(rig.core, rig.dc, req)
-	if err == nil {
-		t.Fatal("[handleRevokeOrderMsg] expected a non-existent order")
+	// Check that the Fund request for a limit buy came through to the BTC wallet
+	// and that the value was adjusted internally with BaseToQuote.
+	expQty := calc.BaseToQuote(rate, qty)
+	if tBtcWallet.fundedVal != expQty {
+		t.Fatalf("limit buy expected funded value %d, got %d", expQty, tBtcWallet.fundedVal)
+	}
+	tBtcWallet.fundedVal = 0
+	// The number of lots should still be the same as for a sell order.
+	if tBtcWallet.fundedSwaps != lots {
+		t.Fatalf("limit buy expected %d max swaps, got %d", lots, tBtcWallet.fundedSwaps)
 	}
+	tBtcWallet.fundedSwaps = 0
 
-	// Now store the order in dc.trades, with a linked cancel order.
-	tracker := newTrackedTrade(dbOrder, preImg, dc,
-		rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, tDcrWallet.fundingCoins, rig.core.notify,
-		rig.core.formatDetails)
-	preImgC := newPreimage()
-	co := &order.CancelOrder{
-		P: order.Prefix{
-			ServerTime: time.Now(),
-			Commit:     preImgC.Commit(),
-		},
+	// Successful market buy order
+	form.IsLimit = false
+	form.Qty = calc.BaseToQuote(rate, qty)
+	rig.ws.queueResponse(msgjson.MarketRoute, handleMarket)
+	corder, err = trade()
+	if err != nil {
+		t.Fatalf("market order error: %v", err)
 	}
-	tracker.cancel = &trackedCancel{CancelOrder: *co}
-	coid := co.ID()
-	rig.dc.trades[oid] = tracker
+	t.Logf("Order with ID(%s) has been placed successfully!", corder.ID.String())
 
-	orderNotes, feedDone := orderNoteFeed(tCore)
-	defer feedDone()
+	// The funded qty for a market buy should not be adjusted.
+	if tBtcWallet.fundedVal != form.Qty {
+		t.Fatalf("market buy expected funded value %d, got %d", qty, tBtcWallet.fundedVal)
+	}
+	tBtcWallet.fundedVal = 0
+	if tBtcWallet.fundedSwaps != lots {
+		t.Fatalf("market buy expected %d max swaps, got %d", lots, tBtcWallet.fundedSwaps)
+	}
+	tBtcWallet.fundedSwaps = 0
 
-	// Revoke the cancel order, not the targeted order.
-	payloadC := &msgjson.RevokeOrder{
-		OrderID: coid[:],
+	// Market buy within the allowed slippage. The book has a single sell
+	// order, so the projected fill rate is the best rate.
+	form.MaxSlippage = 1
+	rig.ws.queueResponse(msgjson.MarketRoute, handleMarket)
+	if _, err = trade(); err != nil {
+		t.Fatalf("market order within slippage error: %v", err)
 	}
-	reqC, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.RevokeOrderRoute, payloadC)
-	err = handleRevokeOrderMsg(rig.core, rig.dc, reqC)
+	tBtcWallet.fundedVal = 0
+	tBtcWallet.fundedSwaps = 0
+
+	// A more expensive sell order pushes the projected fill rate beyond the
+	// allowed slippage.
+	expensiveOID := encode.RandomBytes(32)
+	err = book.Book(&msgjson.BookOrderNote{
+		OrderNote: msgjson.OrderNote{
+			Seq:      2,
+			MarketID: tDcrBtcMktName,
+			OrderID:  expensiveOID,
+		},
+		TradeNote: msgjson.TradeNote{
+			Side:     msgjson.SellOrderNum,
+			Quantity: qty,
+			Time:     uint64(time.Now().Unix()),
+			Rate:     rate * 2,
+		},
+	})
 	if err != nil {
-		t.Fatalf("handleRevokeOrderMsg error: %v", err)
+		t.Fatalf("error booking order: %v", err)
+	}
+	ensureErr("slippage exceeded")
+	form.MaxSlippage = 0
+	err = book.Unbook(&msgjson.UnbookOrderNote{
+		Seq:      3,
+		MarketID: tDcrBtcMktName,
+		OrderID:  expensiveOID,
+	})
+	if err != nil {
+		t.Fatalf("error unbooking order: %v", err)
 	}
 
-	verifyRevokeNotification(orderNotes, TopicFailedCancel, t)
+	// Successful market sell order.
+	form.Sell = true
+	form.Qty = qty
+	rig.ws.queueResponse(msgjson.MarketRoute, handleMarket)
+	corder, err = trade()
+	if err != nil {
+		t.Fatalf("market order error: %v", err)
+	}
+	t.Logf("Order with ID(%s) has been placed successfully!", corder.ID.String())
 
-	if tracker.metaData.Status == order.OrderStatusRevoked {
-		t.Errorf("Incorrectly revoked the targeted order instead of clearing the cancel order!")
+	// The funded qty for a market sell order should not be adjusted.
+	if tDcrWallet.fundedVal != qty {
+		t.Fatalf("market sell expected funded value %d, got %d", qty, tDcrWallet.fundedVal)
 	}
-	if tracker.cancel != nil {
-		t.Fatalf("Did not clear the cancel order")
+	if tDcrWallet.fundedSwaps != lots {
+		t.Fatalf("market sell expected %d max swaps, got %d", lots, tDcrWallet.fundedSwaps)
 	}
 
-	// Now revoke the actual trade order.
-	err = handleRevokeOrderMsg(rig.core, rig.dc, req)
+	// A market sell with a worst acceptable rate is placed as an immediate
+	// limit order, with the rate rounded up to the rate step.
+	form.WorstRate = rate - dcrBtcRateStep/2
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	corder, err = trade()
 	if err != nil {
-		t.Fatalf("handleRevokeOrderMsg error: %v", err)
+		t.Fatalf("worst rate order error: %v", err)
 	}
+	if corder.Type != order.LimitOrderType || corder.TimeInForce != order.ImmediateTiF || corder.Rate != rate {
+		t.Fatalf("worst rate order not an immediate limit order at rate %d. type = %s, tif = %s, rate = %d",
+			rate, corder.Type, corder.TimeInForce, corder.Rate)
+	}
+	form.WorstRate = 0
 
-	verifyRevokeNotification(orderNotes, TopicOrderRevoked, t)
+	// A limit buy with the quantity in the quote asset is placed for the
+	// nearest whole number of lots.
+	form.IsLimit = true
+	form.Sell = false
+	form.Rate = rate
+	form.QtyInQuote = true
+	form.Qty = calc.BaseToQuote(rate, qty+dcrBtcLotSize/3)
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	corder, err = trade()
+	if err != nil {
+		t.Fatalf("quote quantity order error: %v", err)
+	}
+	if corder.Qty != qty {
+		t.Fatalf("quote quantity order placed for %d, expected %d", corder.Qty, qty)
+	}
+	if !form.QtyInQuote || form.Qty == qty {
+		t.Fatalf("trade form modified")
+	}
+	// Less than half a lot is an error.
+	form.Qty = calc.BaseToQuote(rate, dcrBtcLotSize/3)
+	ensureErr("quote quantity less than half a lot")
+	form.QtyInQuote = false
+	form.Qty = qty
 
-	if tracker.metaData.Status != order.OrderStatusRevoked {
-		t.Errorf("expected order status %v, got %v", order.OrderStatusRevoked, tracker.metaData.Status)
+	// A standing limit order with a TTL records when to cancel it.
+	form.TTL = 60
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+	corder, err = trade()
+	if err != nil {
+		t.Fatalf("order with TTL error: %v", err)
+	}
+	ttlTracker, _, _ := rig.dc.findOrder(order.OrderID(corder.ID))
+	if ttlTracker == nil {
+		t.Fatalf("order with TTL not found")
+	}
+	ttlTracker.mtx.RLock()
+	cancelAfter := ttlTracker.metaData.CancelAfter
+	ttlTracker.mtx.RUnlock()
+	if wantAfter := uint64(time.Now().Add(59 * time.Second).UnixMilli()); cancelAfter < wantAfter {
+		t.Fatalf("wrong cancel time %d for order with TTL", cancelAfter)
+	}
+	// Only standing limit orders can have a TTL.
+	form.TifNow = true
+	ensureErr("TTL for immediate limit order")
+	form.TifNow = false
+	form.IsLimit = false
+	form.Sell = true
+	ensureErr("TTL for market order")
+	form.TTL = 0
+
+	// Selling to an account-based quote asset.
+	const reserveN = 50
+	form.Base = tUTXOAssetB.ID
+	form.Quote = tACCTAsset.ID
+	rig.ws.queueResponse(msgjson.MarketRoute, handleMarket)
+	tEthWallet.fundingMtx.Lock()
+	tEthWallet.reserveNRedemptions = reserveN
+	tEthWallet.fundingMtx.Unlock()
+	tEthWallet.sigs = []dex.Bytes{{}}
+	tEthWallet.pubKeys = []dex.Bytes{{}}
+	corder, err = trade()
+	if err != nil {
+		t.Fatalf("account-redeemed order error: %v", err)
+	}
+	t.Logf("Order with ID(%s) has been placed successfully!", corder.ID.String())
+
+	// redeem sig error
+	tEthWallet.signCoinErr = tErr
+	ensureErr("redeem sig error")
+	tEthWallet.signCoinErr = nil
+
+	// missing sig
+	tEthWallet.sigs = []dex.Bytes{}
+	ensureErr("no redeem sig is result")
+	tEthWallet.sigs = []dex.Bytes{{}}
+
+	// ReserveN error
+	tEthWallet.reserveNRedemptionsErr = tErr
+	ensureErr("reserveN error")
+	tEthWallet.reserveNRedemptionsErr = nil
+
+	// Funds returned for later error.
+	tEthWallet.fundingMtx.Lock()
+	tEthWallet.redemptionUnlocked = 0
+	tEthWallet.fundingMtx.Unlock()
+	rig.db.updateOrderErr = tErr
+	rig.ws.queueResponse(msgjson.MarketRoute, handleMarket)
+	ensureOrderErr("db error after redeem funds checked out", async)
+	rig.db.updateOrderErr = nil
+	tEthWallet.fundingMtx.Lock()
+	defer tEthWallet.fundingMtx.Unlock()
+	if tEthWallet.redemptionUnlocked != reserveN {
+		t.Fatalf("redeem funds not returned")
+	}
+
+	// Every funding ended, successful or not.
+	tCore.fundingMtx.Lock()
+	defer tCore.fundingMtx.Unlock()
+	if len(tCore.funding) != 0 {
+		t.Fatalf("funding not ended: %v", tCore.funding)
 	}
 }
 
-func TestHandleRevokeMatchMsg(t *testing.T) {
+func TestQuoteToBaseLots(t *testing.T) {
+	const lotSize = 1e8
+	tests := []struct {
+		name                    string
+		quoteQty, rate, lotSize uint64
+		wantQty                 uint64
+	}{{
+		name:     "exact lots at rate 1",
+		quoteQty: 5e8,
+		rate:     calc.RateEncodingFactor,
+		lotSize:  lotSize,
+		wantQty:  5e8,
+	}, {
+		name:     "rounds down below half a lot",
+		quoteQty: 5.49e8,
+		rate:     calc.RateEncodingFactor,
+		lotSize:  lotSize,
+		wantQty:  5e8,
+	}, {
+		name:     "rounds up at half a lot",
+		quoteQty: 5.5e8,
+		rate:     calc.RateEncodingFactor,
+		lotSize:  lotSize,
+		wantQty:  6e8,
+	}, {
+		name:     "low rate buys more lots",
+		quoteQty: 1e8, // 100 lots at 0.01 quote per base
+		rate:     calc.RateEncodingFactor / 100,
+		lotSize:  lotSize,
+		wantQty:  100e8,
+	}, {
+		name:     "high rate",
+		quoteQty: 249e8, // 2.49 lots at 100 quote per base
+		rate:     calc.RateEncodingFactor * 100,
+		lotSize:  lotSize,
+		wantQty:  2e8,
+	}, {
+		name:     "small lot size",
+		quoteQty: 1234,
+		rate:     calc.RateEncodingFactor / 2, // 2468 base
+		lotSize:  1000,
+		wantQty:  2000,
+	}, {
+		name:     "less than half a lot rounds to zero",
+		quoteQty: 0.49e8,
+		rate:     calc.RateEncodingFactor,
+		lotSize:  lotSize,
+		wantQty:  0,
+	}, {
+		name:    "zero quote quantity",
+		rate:    calc.RateEncodingFactor,
+		lotSize: lotSize,
+		wantQty: 0,
+	}}
+	for _, tt := range tests {
+		if qty := quoteToBaseLots(tt.quoteQty, tt.rate, tt.lotSize); qty != tt.wantQty {
+			t.Fatalf("%s: wanted %d, got %d", tt.name, tt.wantQty, qty)
+		}
+	}
+}
+
+func TestTrade(t *testing.T) {
+	trade(t, false)
+}
+
+func TestTradeAsync(t *testing.T) {
+	trade(t, true)
+}
+
+func TestMultiTradeMaxOpenOrders(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	dc := rig.dc
 	tCore := rig.core
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
 	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
 	dcrWallet.Unlock(rig.crypter)
-
-	fundCoinDcrID := encode.RandomBytes(36)
-	fundCoinDcr := &tCoin{id: fundCoinDcrID}
-
 	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
 	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
 	btcWallet.Unlock(rig.crypter)
 
-	// fundCoinBID := encode.RandomBytes(36)
-	// fundCoinB := &tCoin{id: fundCoinBID}
+	walletSet, _, _, _ := tCore.walletSet(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	booked := makeTradeTracker(rig, walletSet, order.StandingTiF, order.OrderStatusBooked)
+	rig.dc.tradeMtx.Lock()
+	rig.dc.trades[booked.ID()] = booked
+	rig.dc.tradeMtx.Unlock()
+	standing := rig.dc.standingOrders(tDcrBtcMktName)
 
-	matchSize := 4 * dcrBtcLotSize
-	cancelledQty := dcrBtcLotSize
-	qty := 2*matchSize + cancelledQty
-	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, dcrBtcRateStep)
-	lo.Coins = []order.CoinID{fundCoinDcrID}
-	dbOrder.MetaData.Status = order.OrderStatusBooked
-	oid := lo.ID()
+	form := &MultiTradeForm{
+		Host:  tDexHost,
+		Sell:  true,
+		Base:  tUTXOAssetA.ID,
+		Quote: tUTXOAssetB.ID,
+		Placements: []*QtyRate{
+			{Qty: dcrBtcLotSize, Rate: dcrBtcRateStep * 1000},
+			{Qty: dcrBtcLotSize, Rate: dcrBtcRateStep * 1001},
+		},
+		OverridePriceBand: true,
+	}
 
-	tDcrWallet.fundingCoins = asset.Coins{fundCoinDcr}
+	mktConf := rig.dc.marketConfig(tDcrBtcMktName)
+	defer func() { mktConf.MaxOpenOrders = 0 }()
 
-	mid := ordertest.RandomMatchID()
-	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
-	if err != nil {
-		t.Fatalf("walletSet error: %v", err)
+	// Room for only one of the placements.
+	mktConf.MaxOpenOrders = uint32(standing + 1)
+	if _, err := tCore.prepareMultiTradeRequests(tPW, form); err == nil || !strings.Contains(err.Error(), "the maximum is") {
+		t.Fatalf("expected max open orders error, got %v", err)
 	}
 
-	tracker := newTrackedTrade(dbOrder, preImg, dc,
-		rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, tDcrWallet.fundingCoins, rig.core.notify,
-		rig.core.formatDetails)
-
-	match := &matchTracker{
-		MetaMatch: db.MetaMatch{
-			UserMatch: &order.UserMatch{MatchID: mid},
-			MetaData:  &db.MatchMetaData{},
-		},
-	}
-	tracker.matches[mid] = match
-
-	payload := &msgjson.RevokeMatch{
-		OrderID: oid[:],
-		MatchID: mid[:],
-	}
-	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.RevokeMatchRoute, payload)
-
-	// Ensure revoking a non-existent order generates an error.
-	err = handleRevokeMatchMsg(rig.core, rig.dc, req)
-	if err == nil {
-		t.Fatal("[handleRevokeMatchMsg] expected a non-existent order")
-	}
-
-	rig.dc.trades[oid] = tracker
-
-	// Success
-	err = handleRevokeMatchMsg(rig.core, rig.dc, req)
-	if err != nil {
-		t.Fatalf("handleRevokeMatchMsg error: %v", err)
+	// Room for all of the placements.
+	mktConf.MaxOpenOrders = uint32(standing + len(form.Placements))
+	if _, err := tCore.prepareMultiTradeRequests(tPW, form); err != nil {
+		t.Fatalf("prepareMultiTradeRequests error: %v", err)
 	}
 }
 
-func TestTradeTracking(t *testing.T) {
+func TestRefundReserves(t *testing.T) {
+	const reserves = 100_000
+
 	rig := newTestRig()
 	defer rig.shutdown()
 	dc := rig.dc
 	tCore := rig.core
-	tCore.loggedIn = true
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
-	dcrWallet.Unlock(rig.crypter)
 
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = btcWallet
 	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
 	btcWallet.Unlock(rig.crypter)
 
-	tBtcWallet.confirmRedemptionErr = errors.New("")
-	tDcrWallet.confirmRedemptionErr = errors.New("")
+	ethWallet, tEthWallet := newTAccountLocker(tACCTAsset.ID)
+	tCore.wallets[tACCTAsset.ID] = ethWallet
+	ethWallet.address = "18d65fb8d60c1199bb1ad381be47aa692b482605"
+	ethWallet.Unlock(rig.crypter)
 
-	matchSize := 4 * dcrBtcLotSize
-	cancelledQty := dcrBtcLotSize
-	qty := 2*matchSize + cancelledQty
-	rate := dcrBtcRateStep * 10
-	lo, dbOrder, preImgL, addr := makeLimitOrder(dc, true, qty, dcrBtcRateStep)
+	lotSize := dcrBtcLotSize
+	qty := lotSize * 10
+	rate := dcrBtcRateStep * 100
+
+	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, rate)
+	lo.BaseAsset = tUTXOAssetB.ID
+	lo.QuoteAsset = tACCTAsset.ID
 	lo.Force = order.StandingTiF
-	// fundCoinDcrID := encode.RandomBytes(36)
-	// lo.Coins = []order.CoinID{fundCoinDcrID}
 	loid := lo.ID()
 
-	//fundCoinDcr := &tCoin{id: fundCoinDcrID}
-	//tDcrWallet.fundingCoins = asset.Coins{fundCoinDcr}
-
-	mid := ordertest.RandomMatchID()
-	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	walletSet, _, _, err := tCore.walletSet(dc, tACCTAsset.ID, tUTXOAssetA.ID, true)
 	if err != nil {
 		t.Fatalf("walletSet error: %v", err)
 	}
-	mkt := dc.marketConfig(tDcrBtcMktName)
-	fundCoinDcrID := encode.RandomBytes(36)
-	fundingCoins := asset.Coins{&tCoin{id: fundCoinDcrID}}
-	tracker := newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, fundingCoins, rig.core.notify, rig.core.formatDetails)
-	rig.dc.trades[tracker.ID()] = tracker
-	var match *matchTracker
-	checkStatus := func(tag string, wantStatus order.MatchStatus) {
+
+	dbOrder.MetaData.RefundReserves = reserves
+
+	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+	dc.trades[loid] = tracker
+	preImgC := newPreimage()
+	co := &order.CancelOrder{
+		P: order.Prefix{
+			AccountID:  dc.acct.ID(),
+			BaseAsset:  tACCTAsset.ID,
+			QuoteAsset: tUTXOAssetA.ID,
+			OrderType:  order.MarketOrderType,
+			ClientTime: time.Now(),
+			ServerTime: time.Now().Add(time.Millisecond),
+			Commit:     preImgC.Commit(),
+		},
+	}
+
+	msgCancelMatch := &msgjson.Match{
+		OrderID:  loid[:],
+		MatchID:  encode.RandomBytes(32),
+		Quantity: qty / 3,
+		// empty Address signals cancel order match
+	}
+	sign(tDexPriv, msgCancelMatch)
+
+	matchQty := qty * 2 / 3
+	matchReserves := applyFraction(2, 3, reserves)
+	msgMatch := &msgjson.Match{
+		OrderID:  loid[:],
+		MatchID:  encode.RandomBytes(32),
+		Quantity: matchQty,
+		Rate:     rate,
+		Address:  "somenonemptyaddress",
+	}
+	sign(tDexPriv, msgMatch)
+
+	test := func(tag string, expUnlock uint64, f func()) {
 		t.Helper()
-		if match.Status != wantStatus {
-			t.Fatalf("%s: wrong status wanted %v, got %v", tag,
-				wantStatus, match.Status)
+		tEthWallet.refundUnlocked = 0
+		tracker.refundLocked = reserves
+		tracker.metaData.Status = order.OrderStatusEpoch
+		f()
+		if tEthWallet.refundUnlocked != expUnlock {
+			t.Fatalf("%s: expected %d to be unlocked. saw %d", tag, expUnlock, tEthWallet.refundUnlocked)
 		}
 	}
 
-	// create new notification feed to catch swap-related errors from goroutines
-	notes := tCore.NotificationFeed()
-	drainNotes := func() {
-		for {
-			select {
-			case <-notes.C:
-			default:
-				return
-			}
+	test("revoke_order in epoch", reserves, func() {
+		tracker.revoke()
+	})
+
+	test("revoke_order in booked, partial fill", reserves/2, func() {
+		// Revoke in booked with partial fill.
+		tracker.Trade().SetFill(qty / 2)
+		tracker.metaData.Status = order.OrderStatusBooked
+		tracker.revoke()
+	})
+
+	test("canceled, partially filled", reserves/3, func() {
+		tracker.cancel = &trackedCancel{CancelOrder: *co}
+		msgCancel, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch, msgCancelMatch})
+		if err := handleMatchRoute(tCore, rig.dc, msgCancel); err != nil {
+			t.Fatalf("handleMatchRoute error: %v", err)
 		}
-	}
+	})
 
-	lastSwapErrorNote := func() Notification {
-		for {
-			select {
-			case note := <-notes.C:
-				if note.Severity() == db.ErrorLevel && (note.Topic() == TopicSwapSendError ||
-					note.Topic() == TopicInitError || note.Topic() == TopicReportRedeemError) {
+	tracker.cancel = nil
 
-					return note
-				}
-			default:
-				return nil
-			}
+	lo.Force = order.ImmediateTiF
+	loid = lo.ID()
+	msgMatch.OrderID = loid[:]
+	sign(tDexPriv, msgMatch)
+
+	test("partial immediate TiF limit order", reserves/3, func() {
+		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
+		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
+			t.Fatalf("handleMatchRoute error: %v", err)
 		}
-	}
+	})
 
-	type swapRelatedAction struct {
-		name                 string
-		fn                   func() error
-		expectError          bool
-		expectMatchDBUpdates int
-		expectSwapErrorNote  bool
-	}
-	testSwapRelatedAction := func(action swapRelatedAction) {
+	lo.Force = order.StandingTiF
+	loid = lo.ID()
+	msgMatch.OrderID = loid[:]
+	sign(tDexPriv, msgMatch)
+
+	addMatch := func(side order.MatchSide, status order.MatchStatus, qty uint64) order.MatchID {
 		t.Helper()
-		drainNotes() // clear previous (swap error) notes before exec'ing swap-related action
-		if action.expectMatchDBUpdates > 0 {
-			rig.db.updateMatchChan = make(chan order.MatchStatus, action.expectMatchDBUpdates)
-		}
-		// Try the action and confirm the behaviour is as expected.
-		err := action.fn()
-		if action.expectError && err == nil {
-			t.Fatalf("%s: expected error but got nil", action.name)
-		} else if !action.expectError && err != nil {
-			t.Fatalf("%s: unexpected error: %v", action.name, err)
+		msgMatch.Side = uint8(side)
+		m := *msgMatch
+		var mid order.MatchID
+		copy(mid[:], encode.RandomBytes(32))
+		m.MatchID = mid[:]
+		m.Quantity = qty
+		sign(tDexPriv, &m)
+		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{&m})
+		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
+			t.Fatalf("handleMatchRoute error: %v", err)
 		}
-		// Check that we received the expected number of match db updates.
-		for i := 0; i < action.expectMatchDBUpdates; i++ {
-			<-rig.db.updateMatchChan
+		mt, ok := tracker.matches[mid]
+		if !ok {
+			t.Fatalf("match not found")
 		}
-		rig.db.updateMatchChan = nil
-		// Check that we received a swap error note (if expected), and that
-		// no error note was received, if not expected.
-		time.Sleep(100 * time.Millisecond) // wait briefly as swap error notes may be sent from a goroutine
-		swapErrNote := lastSwapErrorNote()
-		if action.expectSwapErrorNote && swapErrNote == nil {
-			t.Fatalf("%s: expected swap error note but got nil", action.name)
-		} else if !action.expectSwapErrorNote && swapErrNote != nil {
-			t.Fatalf("%s: unexpected swap error note: %s", action.name, swapErrNote.Details())
+		mt.Status = status
+		if status >= order.TakerSwapCast {
+			mt.counterSwap = &asset.AuditInfo{}
 		}
+		return mid
 	}
 
-	// MAKER MATCH
-	matchTime := time.Now()
-	msgMatch := &msgjson.Match{
-		OrderID:    loid[:],
-		MatchID:    mid[:],
-		Quantity:   matchSize,
-		Rate:       rate,
-		Address:    "counterparty-address",
-		Side:       uint8(order.Maker),
-		ServerTime: uint64(matchTime.UnixMilli()),
+	resetMatches := func() {
+		tracker.matches = make(map[order.MatchID]*matchTracker)
 	}
-	counterSwapID := encode.RandomBytes(36)
-	tDcrWallet.swapReceipts = []asset.Receipt{&tReceipt{coin: &tCoin{id: counterSwapID}}}
-	sign(tDexPriv, msgMatch)
 
-	// Make sure that a fee rate higher than our recorded MaxFeeRate results in
-	// an error.
-	msgMatch.FeeRateBase = tMaxFeeRate + 1
-	sign(tDexPriv, msgMatch)
-	msg, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
-	err = handleMatchRoute(tCore, rig.dc, msg)
-	if err == nil || !strings.Contains(err.Error(), "is > MaxFeeRate") {
-		t.Fatalf("no error for fee rate > MaxFeeRate %t", lo.Trade().Sell)
-	}
+	test("redemption received", reserves/10, func() {
+		mid := addMatch(order.Taker, order.TakerSwapCast, lotSize)
+		redemption := &msgjson.Redemption{
+			Redeem: msgjson.Redeem{
+				OrderID: loid[:],
+				MatchID: mid[:],
+				CoinID:  encode.RandomBytes(36),
+			},
+		}
+		tracker.processRedemption(1, redemption)
+	})
 
-	// Restore fee rate.
-	msgMatch.FeeRateBase = tMaxFeeRate
+	// Market sell order
+	mo := &order.MarketOrder{
+		P: lo.P,
+		T: *lo.Trade(),
+	}
+	mo.Prefix().OrderType = order.MarketOrderType
+	moid := mo.ID()
+	dbOrder.Order = mo
+	msgMatch.OrderID = moid[:]
 	sign(tDexPriv, msgMatch)
-	msg, _ = msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
 
-	// Handle new match as maker with a queued invalid DEX init ack.
-	// handleMatchRoute should have no errors but trigger a match db update (status = NewlyMatched).
-	// Maker's swap should be bcasted, triggering another match db update (NewlyMatched->MakerSwapCast).
-	// sendInitAsync should fail because of invalid ack and produce a swap error note.
-	testSwapRelatedAction(swapRelatedAction{
-		name: "handleMatchRoute",
-		fn: func() error {
-			// queue an invalid DEX init ack
-			rig.ws.queueResponse(msgjson.InitRoute, invalidAcker)
-			return handleMatchRoute(tCore, rig.dc, msg)
-		},
-		expectError:          false,
-		expectMatchDBUpdates: 2,
-		expectSwapErrorNote:  true,
+	tracker = newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+	dc.trades = map[order.OrderID]*trackedTrade{moid: tracker}
+
+	test("nomatch", reserves, func() {
+		tracker.nomatch(moid)
 	})
 
-	var found bool
-	match, found = tracker.matches[mid]
-	if !found {
-		t.Fatalf("match not found")
-	}
+	test("partial market sell match", reserves/3, func() {
+		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
+		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
+			t.Fatalf("handleMatchRoute error: %v", err)
+		}
+	})
 
-	// We're the maker, so the init transaction should be broadcast.
-	checkStatus("maker swapped", order.MakerSwapCast)
-	proof, auth := &match.MetaData.Proof, &match.MetaData.Proof.Auth
-	if len(auth.MatchSig) == 0 {
-		t.Fatalf("no match sig recorded")
+	resetMatches()
+
+	testRevokeMatch := func(side order.MatchSide, status order.MatchStatus, expReserves uint64) {
+		t.Helper()
+		resetMatches()
+		matchID := addMatch(side, status, matchQty)
+		desc := fmt.Sprintf("match revoke - %s in %s", side, status)
+		test(desc, expReserves, func() {
+			tracker.revokeMatch(matchID, true)
+		})
 	}
-	if !bytes.Equal(proof.MakerSwap, counterSwapID) {
-		t.Fatalf("receipt ID not recorded")
+
+	testRevokeMatch(order.Maker, order.NewlyMatched, matchReserves)
+
+	testRevokeMatch(order.Taker, order.NewlyMatched, matchReserves)
+
+	testRevokeMatch(order.Taker, order.MakerSwapCast, matchReserves)
+
+	// But Maker in MakerSwapCast shouldn't return reserves, because they will
+	// need to do a refund
+	testRevokeMatch(order.Maker, order.MakerSwapCast, 0)
+
+	// Similarly Taker in TakerSwapCast shouldn't return anything, since
+	// they will need to do a refund.
+	testRevokeMatch(order.Taker, order.TakerSwapCast, 0)
+
+	resetMatches()
+
+	// Market buy order
+	mo.BaseAsset, mo.QuoteAsset = mo.QuoteAsset, mo.BaseAsset
+	mo.Sell = false
+	tracker.wallets, _, _, _ = tCore.walletSet(dc, tUTXOAssetA.ID, tACCTAsset.ID, false)
+
+	test("redemption received, market buy", reserves, func() {
+		mid := addMatch(order.Taker, order.TakerSwapCast, lotSize)
+		redemption := &msgjson.Redemption{
+			Redeem: msgjson.Redeem{
+				OrderID: loid[:],
+				MatchID: mid[:],
+				CoinID:  encode.RandomBytes(36),
+			},
+		}
+		tracker.processRedemption(1, redemption)
+	})
+
+	resetMatches()
+	mids := []order.MatchID{
+		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
+		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
+		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
 	}
-	if len(proof.Secret) == 0 {
-		t.Fatalf("secret not set")
+
+	tracker.refundLocked = reserves
+	tEthWallet.refundUnlocked = 0
+	for _, mid := range mids {
+		// Third match should catch the market buy order dust filter.
+		if err := tracker.revokeMatch(mid, true); err != nil {
+			t.Fatalf("revokeMatch error: %v", err)
+		}
 	}
-	if len(proof.SecretHash) == 0 {
-		t.Fatalf("secret hash not set")
+	if tracker.refundLocked != 0 {
+		t.Fatalf("redemptionLocked (1/3) * 3 != 1: %d still reserved of %d", tracker.refundLocked, reserves)
 	}
-	// auth.InitSig should be unset because our init request received
-	// an invalid ack
-	if len(auth.InitSig) != 0 {
-		t.Fatalf("init sig recorded for invalid init ack")
+	if tEthWallet.refundUnlocked != reserves {
+		t.Fatalf("redemptionUnlocked (1/3) * 3 != 1: %d returned of %d", tEthWallet.refundUnlocked, reserves)
 	}
+}
 
-	// requeue an invalid DEX init ack and resend pending init request
-	testSwapRelatedAction(swapRelatedAction{
-		name: "resend pending init (invalid ack)",
-		fn: func() error {
-			rig.ws.queueResponse(msgjson.InitRoute, invalidAcker)
-			tCore.resendPendingRequests(tracker)
-			return nil
-		},
-		expectError:          false,
-		expectMatchDBUpdates: 0,    // no db update for invalid init ack
-		expectSwapErrorNote:  true, // expect swap error note for invalid init ack
-	})
-	// auth.InitSig should remain unset because our resent init request
-	// received an invalid ack still
-	if len(auth.InitSig) != 0 {
-		t.Fatalf("init sig recorded for second invalid init ack")
-	}
+func TestRedemptionReserves(t *testing.T) {
+	const reserves = 100_000
 
-	// queue a valid DEX init ack and re-send pending init request
-	// a valid ack should produce a db update otherwise it's an error
-	testSwapRelatedAction(swapRelatedAction{
-		name: "resend pending init (valid ack)",
-		fn: func() error {
-			rig.ws.queueResponse(msgjson.InitRoute, initAcker)
-			tCore.resendPendingRequests(tracker)
-			return nil
-		},
-		expectError:          false,
-		expectMatchDBUpdates: 1,     // expect db update for valid init ack
-		expectSwapErrorNote:  false, // no swap error note for valid init ack
-	})
-	// auth.InitSig should now be set because our init request received
-	// a valid ack
-	if len(auth.InitSig) == 0 {
-		t.Fatalf("init sig not recorded for valid init ack")
-	}
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
 
-	// Send the counter-party's init info.
-	auditQty := calc.BaseToQuote(rate, matchSize)
-	audit, auditInfo := tMsgAudit(loid, mid, addr, auditQty, proof.SecretHash)
-	auditInfo.Expiration = encode.DropMilliseconds(matchTime.Add(tracker.lockTimeTaker))
-	tBtcWallet.auditInfo = auditInfo
-	msg, _ = msgjson.NewRequest(1, msgjson.AuditRoute, audit)
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
+	btcWallet.Unlock(rig.crypter)
 
-	// Check audit errors.
-	tBtcWallet.auditErr = tErr
-	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
-	if err == nil {
-		t.Fatalf("no maker error for AuditContract error")
-	}
+	ethWallet, tEthWallet := newTAccountLocker(tACCTAsset.ID)
+	tCore.wallets[tACCTAsset.ID] = ethWallet
+	ethWallet.address = "18d65fb8d60c1199bb1ad381be47aa692b482605"
+	ethWallet.Unlock(rig.crypter)
 
-	// Check expiration error.
-	match.MetaData.Proof.SelfRevoked = true // keeps trying unless revoked
-	tBtcWallet.auditErr = asset.CoinNotFoundError
-	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
-	if err == nil {
-		t.Fatalf("no maker error for AuditContract expiration")
-	}
-	var expErr ExpirationErr
-	if !errors.As(err, &expErr) {
-		t.Fatalf("wrong error type. expecting ExpirationTimeout, got %T: %v", err, err)
-	}
-	tBtcWallet.auditErr = nil
-	match.MetaData.Proof.SelfRevoked = false
+	lotSize := dcrBtcLotSize
+	qty := lotSize * 10
+	rate := dcrBtcRateStep * 100
 
-	auditInfo.Coin.(*tCoin).val = auditQty - 1
-	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
-	if err == nil {
-		t.Fatalf("no maker error for low value")
+	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, rate)
+	lo.BaseAsset = tUTXOAssetB.ID
+	lo.QuoteAsset = tACCTAsset.ID
+	lo.Force = order.StandingTiF
+	loid := lo.ID()
+
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetB.ID, tACCTAsset.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
 	}
-	auditInfo.Coin.(*tCoin).val = auditQty
 
-	auditInfo.SecretHash = []byte{0x01}
-	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
-	if err == nil {
-		t.Fatalf("no maker error for wrong secret hash")
+	dbOrder.MetaData.RedemptionReserves = reserves
+
+	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+	dc.trades[loid] = tracker
+	preImgC := newPreimage()
+	co := &order.CancelOrder{
+		P: order.Prefix{
+			AccountID:  dc.acct.ID(),
+			BaseAsset:  tACCTAsset.ID,
+			QuoteAsset: tUTXOAssetB.ID,
+			OrderType:  order.MarketOrderType,
+			ClientTime: time.Now(),
+			ServerTime: time.Now().Add(time.Millisecond),
+			Commit:     preImgC.Commit(),
+		},
 	}
-	auditInfo.SecretHash = proof.SecretHash
 
-	auditInfo.Recipient = "wrong address"
-	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
-	if err == nil {
-		t.Fatalf("no maker error for wrong address")
+	msgCancelMatch := &msgjson.Match{
+		OrderID:  loid[:],
+		MatchID:  encode.RandomBytes(32),
+		Quantity: qty / 3,
+		// empty Address signals cancel order match
 	}
-	auditInfo.Recipient = addr
+	sign(tDexPriv, msgCancelMatch)
 
-	auditInfo.Expiration = matchTime.Add(tracker.lockTimeTaker - time.Hour)
-	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
-	if err == nil {
-		t.Fatalf("no maker error for early lock time")
+	matchQty := qty * 2 / 3
+	matchReserves := applyFraction(2, 3, reserves)
+	msgMatch := &msgjson.Match{
+		OrderID:  loid[:],
+		MatchID:  encode.RandomBytes(32),
+		Quantity: matchQty,
+		Rate:     rate,
+		Address:  "somenonemptyaddress",
 	}
-	auditInfo.Expiration = matchTime.Add(tracker.lockTimeTaker)
+	sign(tDexPriv, msgMatch)
 
-	// success, full handleAuditRoute>processAuditMsg>auditContract
-	rig.db.updateMatchChan = make(chan order.MatchStatus, 1)
-	err = handleAuditRoute(tCore, rig.dc, msg)
-	if err != nil {
-		t.Fatalf("audit error: %v", err)
-	}
-	// let the async auditContract run
-	newMatchStatus := <-rig.db.updateMatchChan
-	if newMatchStatus != order.TakerSwapCast {
-		t.Fatalf("wrong match status. wanted %v, got %v", order.TakerSwapCast, newMatchStatus)
+	test := func(tag string, expUnlock uint64, f func()) {
+		t.Helper()
+		tEthWallet.redemptionUnlocked = 0
+		tracker.redemptionLocked = reserves
+		tracker.metaData.Status = order.OrderStatusEpoch
+		f()
+		if tEthWallet.redemptionUnlocked != expUnlock {
+			t.Fatalf("%s: expected %d to be unlocked. saw %d", tag, expUnlock, tEthWallet.redemptionUnlocked)
+		}
 	}
-	if match.counterSwap == nil {
-		t.Fatalf("counter-swap not set")
+
+	test("revoke_order in epoch", reserves, func() {
+		tracker.revoke()
+	})
+
+	test("revoke_order in booked, partial fill", reserves/2, func() {
+		// Revoke in booked with partial fill.
+		tracker.Trade().SetFill(qty / 2)
+		tracker.metaData.Status = order.OrderStatusBooked
+		tracker.revoke()
+	})
+
+	test("canceled, partially filled", reserves/3, func() {
+		tracker.cancel = &trackedCancel{CancelOrder: *co}
+		msgCancel, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch, msgCancelMatch})
+		if err := handleMatchRoute(tCore, rig.dc, msgCancel); err != nil {
+			t.Fatalf("handleMatchRoute error: %v", err)
+		}
+	})
+
+	tracker.cancel = nil
+
+	lo.Force = order.ImmediateTiF
+	loid = lo.ID()
+	msgMatch.OrderID = loid[:]
+	sign(tDexPriv, msgMatch)
+
+	test("partially filled immediate TiF limit order", reserves/3, func() {
+		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
+		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
+			t.Fatalf("handleMatchRoute error: %v", err)
+		}
+	})
+
+	mo := &order.MarketOrder{
+		P: lo.P,
+		T: *lo.Trade(),
 	}
-	if !bytes.Equal(proof.CounterContract, audit.Contract) {
-		t.Fatalf("counter-script not recorded")
+	mo.Prefix().OrderType = order.MarketOrderType
+	moid := mo.ID()
+	dbOrder.Order = mo
+	msgMatch.OrderID = moid[:]
+	sign(tDexPriv, msgMatch)
+
+	tracker = newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+	dc.trades = map[order.OrderID]*trackedTrade{moid: tracker}
+
+	test("nomatch", reserves, func() {
+		tracker.nomatch(moid)
+	})
+
+	test("partial market sell match", reserves/3, func() {
+		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
+		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
+			t.Fatalf("handleMatchRoute error: %v", err)
+		}
+	})
+
+	addMatch := func(side order.MatchSide, status order.MatchStatus, qty uint64) order.MatchID {
+		msgMatch.Side = uint8(side)
+		m := *msgMatch
+		var mid order.MatchID
+		copy(mid[:], encode.RandomBytes(32))
+		m.MatchID = mid[:]
+		m.Quantity = qty
+		sign(tDexPriv, &m)
+		matchReq, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{&m})
+		if err := handleMatchRoute(tCore, rig.dc, matchReq); err != nil {
+			t.Fatalf("handleMatchRoute error: %v", err)
+		}
+		mt, ok := tracker.matches[mid]
+		if !ok {
+			t.Fatalf("match not found")
+		}
+		mt.Status = status
+		return mid
 	}
-	if !bytes.Equal(proof.TakerSwap, audit.CoinID) {
-		t.Fatalf("taker contract ID not set")
+
+	resetMatches := func() {
+		tracker.matches = make(map[order.MatchID]*matchTracker)
 	}
-	<-rig.db.updateMatchChan // AuditSig is set in a second match data update
-	if !bytes.Equal(auth.AuditSig, audit.Sig) {
-		t.Fatalf("audit sig not set")
+
+	testRevokeMatch := func(side order.MatchSide, status order.MatchStatus, expReserves uint64) {
+		t.Helper()
+		resetMatches()
+		matchID := addMatch(side, status, matchQty)
+		desc := fmt.Sprintf("match revoke - %s in %s", side, status)
+		test(desc, expReserves, func() {
+			tracker.revokeMatch(matchID, true)
+		})
 	}
-	if auth.AuditStamp != audit.Time {
-		t.Fatalf("audit time not set")
+
+	testRevokeMatch(order.Maker, order.NewlyMatched, matchReserves)
+
+	testRevokeMatch(order.Taker, order.NewlyMatched, matchReserves)
+
+	testRevokeMatch(order.Taker, order.MakerSwapCast, matchReserves)
+
+	// But Maker in MakerSwapCast shouldn't return reserves, since the trade
+	// will proceed to redeem.
+	testRevokeMatch(order.Maker, order.MakerSwapCast, 0)
+
+	// Similarly Taker in TakerSwapCast shouldn't return anything, since we will
+	// be watching for a redemption.
+	testRevokeMatch(order.Taker, order.TakerSwapCast, 0)
+
+	// Market buy order with dust handling.
+	mo.BaseAsset, mo.QuoteAsset = mo.QuoteAsset, mo.BaseAsset
+	mo.Sell = false
+	tracker.wallets, _, _, _ = tCore.walletSet(dc, tACCTAsset.ID, tUTXOAssetB.ID, false)
+
+	resetMatches()
+	mids := []order.MatchID{
+		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
+		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
+		addMatch(order.Maker, order.NewlyMatched, lotSize*2),
 	}
 
-	// Confirming the counter-swap triggers a redemption.
-	tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
-	redeemCoin := encode.RandomBytes(36)
-	//<-tBtcWallet.redeemErrChan
-	tBtcWallet.redeemCoins = []dex.Bytes{redeemCoin}
-	rig.ws.queueResponse(msgjson.RedeemRoute, redeemAcker)
-	tCore.tickAsset(dc, tUTXOAssetB.ID)
-	// TakerSwapCast -> MakerRedeemed after broadcast, before redeem request
-	newMatchStatus = <-rig.db.updateMatchChan
-	if newMatchStatus != order.MakerRedeemed {
-		t.Fatalf("wrong match status. wanted %v, got %v", order.MakerRedeemed, newMatchStatus)
+	tracker.redemptionLocked = reserves
+	tEthWallet.redemptionUnlocked = 0
+	for _, mid := range mids {
+		// Third match should catch the market buy order dust filter.
+		if err := tracker.revokeMatch(mid, true); err != nil {
+			t.Fatalf("revokeMatch error: %v", err)
+		}
 	}
-	// MakerRedeem -> MatchComplete after redeem request
-	newMatchStatus = <-rig.db.updateMatchChan
-	if newMatchStatus != order.MatchComplete {
-		t.Fatalf("wrong match status. wanted %v, got %v", order.MatchComplete, newMatchStatus)
+	if tracker.redemptionLocked != 0 {
+		t.Fatalf("redemptionLocked (1/3) * 3 != 1: %d still reserved of %d", tracker.redemptionLocked, reserves)
 	}
-	if !bytes.Equal(proof.MakerRedeem, redeemCoin) {
-		t.Fatalf("redeem coin ID not logged")
+	if tEthWallet.redemptionUnlocked != reserves {
+		t.Fatalf("redemptionUnlocked (1/3) * 3 != 1: %d returned of %d", tEthWallet.redemptionUnlocked, reserves)
 	}
-	// No redemption request received as maker. Only taker gets a redemption
-	// request following maker's redeem.
+}
 
-	// Check that fees were incremented appropriately.
-	if tracker.metaData.SwapFeesPaid != tSwapFeesPaid {
-		t.Fatalf("wrong fees recorded for swap. expected %d, got %d", tSwapFeesPaid, tracker.metaData.SwapFeesPaid)
-	}
-	// Check that fees were incremented appropriately.
-	if tracker.metaData.RedemptionFeesPaid != tRedemptionFeesPaid {
-		t.Fatalf("wrong fees recorded for redemption. expected %d, got %d", tRedemptionFeesPaid, tracker.metaData.SwapFeesPaid)
-	}
-	rig.db.updateMatchChan = nil
+func TestCancel(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, 0, 0)
+	lo.Force = order.StandingTiF
+	oid := lo.ID()
+	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, nil, nil, rig.core.notify, rig.core.formatDetails)
+	dc.trades[oid] = tracker
 
-	// TAKER MATCH
-	//
-	mid = ordertest.RandomMatchID()
-	msgMatch = &msgjson.Match{
-		OrderID:     loid[:],
-		MatchID:     mid[:],
-		Quantity:    matchSize,
-		Rate:        rate,
-		Address:     "counterparty-address",
-		Side:        uint8(order.Taker),
-		ServerTime:  uint64(matchTime.UnixMilli()),
-		FeeRateBase: tMaxFeeRate,
-	}
-	sign(tDexPriv, msgMatch)
-	msg, _ = msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
-	rig.db.updateMatchChan = make(chan order.MatchStatus, 1)
-	err = handleMatchRoute(tCore, rig.dc, msg)
+	rig.queueCancel(nil)
+	err := rig.core.Cancel(oid[:])
 	if err != nil {
-		t.Fatalf("match messages error: %v", err)
-	}
-	match, found = tracker.matches[mid]
-	if !found {
-		t.Fatalf("match not found")
-	}
-	newMatchStatus = <-rig.db.updateMatchChan
-	if newMatchStatus != order.NewlyMatched {
-		t.Fatalf("wrong match status. wanted %v, got %v", order.NewlyMatched, newMatchStatus)
-	}
-	proof, auth = &match.MetaData.Proof, &match.MetaData.Proof.Auth
-	if len(auth.MatchSig) == 0 {
-		t.Fatalf("no match sig recorded")
-	}
-	// Secret should not be set yet.
-	if len(proof.Secret) != 0 {
-		t.Fatalf("secret set for taker")
+		t.Fatalf("cancel error: %v", err)
 	}
-	if len(proof.SecretHash) != 0 {
-		t.Fatalf("secret hash set for taker")
+	if tracker.cancel == nil {
+		t.Fatalf("cancel order not found")
 	}
 
-	// Now send through the audit request for the maker's init.
-	audit, auditInfo = tMsgAudit(loid, mid, addr, matchSize, nil)
-	tBtcWallet.auditInfo = auditInfo
-	// early lock time
-	auditInfo.Expiration = matchTime.Add(tracker.lockTimeMaker - time.Hour)
-	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
-	if err == nil {
-		t.Fatalf("no taker error for early lock time")
+	ensureErr := func(tag string) {
+		t.Helper()
+		err := rig.core.Cancel(oid[:])
+		if err == nil {
+			t.Fatalf("%s: no error", tag)
+		}
 	}
 
-	// success, full handleAuditRoute>processAuditMsg>auditContract
-	auditInfo.Expiration = encode.DropMilliseconds(matchTime.Add(tracker.lockTimeMaker))
-	msg, _ = msgjson.NewRequest(1, msgjson.AuditRoute, audit)
-	err = handleAuditRoute(tCore, rig.dc, msg)
-	if err != nil {
-		t.Fatalf("taker's match message error: %v", err)
-	}
-	// let the async auditContract run, updating match status
-	newMatchStatus = <-rig.db.updateMatchChan
-	if newMatchStatus != order.MakerSwapCast {
-		t.Fatalf("wrong match status. wanted %v, got %v", order.MakerSwapCast, newMatchStatus)
-	}
-	if len(proof.SecretHash) == 0 {
-		t.Fatalf("secret hash not set for taker")
-	}
-	if !bytes.Equal(proof.MakerSwap, audit.CoinID) {
-		t.Fatalf("maker redeem coin not set")
-	}
-	<-rig.db.updateMatchChan // AuditSig is set in a second match data update
-	if !bytes.Equal(auth.AuditSig, audit.Sig) {
-		t.Fatalf("audit sig not set for taker")
-	}
-	if auth.AuditStamp != audit.Time {
-		t.Fatalf("audit time not set for taker")
-	}
-	// The swap should not be sent, since the auditInfo coin doesn't have the
-	// requisite confirmations.
-	if len(proof.TakerSwap) != 0 {
-		t.Fatalf("swap broadcast before confirmations")
-	}
-	// confirming maker's swap should trigger taker's swap bcast
-	tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
-	swapID := encode.RandomBytes(36)
-	tDcrWallet.swapReceipts = []asset.Receipt{&tReceipt{coin: &tCoin{id: swapID}}}
-	rig.ws.queueResponse(msgjson.InitRoute, initAcker)
-	tCore.tickAsset(dc, tUTXOAssetB.ID)
-	newMatchStatus = <-rig.db.updateMatchChan // MakerSwapCast->TakerSwapCast (after taker's swap bcast)
-	if newMatchStatus != order.TakerSwapCast {
-		t.Fatalf("wrong match status. wanted %v, got %v", order.TakerSwapCast, newMatchStatus)
-	}
-	if len(proof.TakerSwap) == 0 {
-		t.Fatalf("swap not broadcast with confirmations")
-	}
-	<-rig.db.updateMatchChan // init ack sig is set in a second match data update
-	if len(auth.InitSig) == 0 {
-		t.Fatalf("init ack sig not set for taker")
-	}
+	// Should get an error for existing cancel order.
+	ensureErr("second cancel")
 
-	// Receive the maker's redemption.
-	redemptionCoin := encode.RandomBytes(36)
-	redemption := &msgjson.Redemption{
-		Redeem: msgjson.Redeem{
-			OrderID: loid[:],
-			MatchID: mid[:],
-			CoinID:  redemptionCoin,
-		},
-	}
-	sign(tDexPriv, redemption)
-	redeemCoin = encode.RandomBytes(36)
-	tBtcWallet.redeemCoins = []dex.Bytes{redeemCoin}
-	msg, _ = msgjson.NewRequest(1, msgjson.RedemptionRoute, redemption)
+	// remove the cancel order so we can check its nilness on error.
+	tracker.cancel = nil
 
-	tBtcWallet.badSecret = true
-	err = handleRedemptionRoute(tCore, rig.dc, msg)
-	if err == nil {
-		t.Fatalf("no error for wrong secret")
-	}
-	newMatchStatus = <-rig.db.updateMatchChan  // wrong secret still updates match
-	if newMatchStatus != order.TakerSwapCast { // but status is same
-		t.Fatalf("wrong match status. wanted %v, got %v", order.TakerSwapCast, newMatchStatus)
+	ensureNilCancel := func(tag string) {
+		if tracker.cancel != nil {
+			t.Fatalf("%s: cancel order found", tag)
+		}
 	}
-	tBtcWallet.badSecret = false
 
-	tBtcWallet.redeemErrChan = make(chan error, 1)
-	rig.ws.queueResponse(msgjson.RedeemRoute, redeemAcker)
-	err = handleRedemptionRoute(tCore, rig.dc, msg)
-	if err != nil {
-		t.Fatalf("redemption message error: %v", err)
-	}
-	err = <-tBtcWallet.redeemErrChan
-	if err != nil {
-		t.Fatalf("should have worked, got: %v", err)
-	}
-	// For taker, there's one status update to MakerRedeemed prior to bcasting taker's redemption
-	newMatchStatus = <-rig.db.updateMatchChan
-	if newMatchStatus != order.MakerRedeemed {
-		t.Fatalf("wrong match status. wanted %v, got %v", order.MakerRedeemed, newMatchStatus)
-	}
-	// and another status update to MatchComplete when taker's redemption is bcast
-	newMatchStatus = <-rig.db.updateMatchChan
-	if newMatchStatus != order.MatchComplete {
-		t.Fatalf("wrong match status. wanted %v, got %v", order.MatchComplete, newMatchStatus)
-	}
-	if !bytes.Equal(proof.MakerRedeem, redemptionCoin) {
-		t.Fatalf("redemption coin ID not logged")
-	}
-	if len(proof.TakerRedeem) == 0 {
-		t.Fatalf("taker redemption not sent")
-	}
-	// Then a match update to set the redeem ack sig when the 'redeem' request back
-	// to the server succeeds.
-	<-rig.db.updateMatchChan
-	if len(auth.RedeemSig) == 0 {
-		t.Fatalf("redeem ack sig not set for taker")
-	}
-	rig.db.updateMatchChan = nil
-	tBtcWallet.redeemErrChan = nil
+	// Bad order ID
+	ogID := oid
+	oid = order.OrderID{0x01, 0x02}
+	ensureErr("bad id")
+	ensureNilCancel("bad id")
+	oid = ogID
 
-	// CANCEL ORDER MATCH
-	//
-	tDcrWallet.returnedCoins = nil
-	copy(mid[:], encode.RandomBytes(32))
-	preImgC := newPreimage()
-	co := &order.CancelOrder{
-		P: order.Prefix{
-			AccountID:  dc.acct.ID(),
-			BaseAsset:  tUTXOAssetA.ID,
-			QuoteAsset: tUTXOAssetB.ID,
-			OrderType:  order.MarketOrderType,
-			ClientTime: time.Now(),
-			ServerTime: time.Now().Add(time.Millisecond),
-			Commit:     preImgC.Commit(),
-		},
-	}
-	tracker.cancel = &trackedCancel{CancelOrder: *co, epochLen: mkt.EpochLen}
-	coid := co.ID()
-	m1 := &msgjson.Match{
-		OrderID:  loid[:],
-		MatchID:  mid[:],
-		Quantity: cancelledQty,
-		Rate:     rate,
-		Address:  "",
+	// Order not found
+	delete(dc.trades, oid)
+	ensureErr("no order")
+	ensureNilCancel("no order")
+	dc.trades[oid] = tracker
+
+	// Order younger than the market's min order lifetime.
+	mktConf := dc.marketConfig(tracker.mktID)
+	mktConf.MinOrderLifetime = uint64(time.Hour.Milliseconds())
+	ensureErr("min lifetime")
+	ensureNilCancel("min lifetime")
+	mktConf.MinOrderLifetime = 0
+
+	// Send error
+	rig.ws.reqErr = tErr
+	ensureErr("Request error")
+	ensureNilCancel("Request error")
+	rig.ws.reqErr = nil
+}
+
+func TestOrderTTL(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+
+	defer func(d time.Duration) { orderTTLRetry = d }(orderTTLRetry)
+	orderTTLRetry = 10 * time.Millisecond
+
+	newTracker := func() *trackedTrade {
+		lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, 0, 0)
+		lo.Force = order.StandingTiF
+		dbOrder.MetaData.CancelAfter = uint64(time.Now().Add(20 * time.Millisecond).UnixMilli())
+		tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+			rig.db, rig.queue, nil, nil, rig.core.notify, rig.core.formatDetails)
+		dc.tradeMtx.Lock()
+		dc.trades[lo.ID()] = tracker
+		dc.tradeMtx.Unlock()
+		return tracker
 	}
-	m2 := &msgjson.Match{
-		OrderID:  coid[:],
-		MatchID:  mid[:],
-		Quantity: cancelledQty,
-		Address:  "testaddr",
+	canceled := func(tracker *trackedTrade) bool {
+		tracker.mtx.RLock()
+		defer tracker.mtx.RUnlock()
+		return tracker.cancel != nil
 	}
-	sign(tDexPriv, m1)
-	sign(tDexPriv, m2)
-	msg, _ = msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{m1, m2})
-	err = handleMatchRoute(tCore, rig.dc, msg)
-	if err != nil {
-		t.Fatalf("handleMatchRoute error (cancel with swaps): %v", err)
+	waitFor := func(f func() bool) bool {
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+			if f() {
+				return true
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return false
 	}
-	if tracker.cancel.matches.maker == nil {
-		t.Fatalf("cancelMatches.maker not set")
+	scheduled := func(tracker *trackedTrade) bool {
+		rig.core.ttlTimersMtx.Lock()
+		defer rig.core.ttlTimersMtx.Unlock()
+		return rig.core.ttlTimers[tracker.ID()] != nil
 	}
-	if tracker.Trade().Filled() != qty {
-		t.Fatalf("fill not set. %d != %d", tracker.Trade().Filled(), qty)
+
+	// An unfilled order is canceled at its TTL.
+	tracker := newTracker()
+	rig.queueCancel(nil)
+	rig.core.scheduleOrderTTL(dc, tracker)
+	if !scheduled(tracker) {
+		t.Fatalf("TTL cancel not scheduled")
 	}
-	if tracker.cancel.matches.taker == nil {
-		t.Fatalf("cancelMatches.taker not set")
+	if canceled(tracker) {
+		t.Fatalf("order canceled before TTL")
 	}
-	// Since there are no unswapped orders, the change coin should be returned.
-	if len(tDcrWallet.returnedCoins) != 1 || !bytes.Equal(tDcrWallet.returnedCoins[0].ID(), tDcrWallet.changeCoin.id) {
-		t.Fatalf("change coin not returned")
+	if !waitFor(func() bool { return canceled(tracker) }) {
+		t.Fatalf("unfilled order not canceled at TTL")
 	}
 
-	resetMatches := func() {
-		tracker.matches = make(map[order.MatchID]*matchTracker)
-		tracker.change = nil
-		tracker.metaData.ChangeCoin = nil
-		tracker.coinsLocked = true
+	// A cancel that cannot be submitted is tried again. With no cancel
+	// response queued, the request errors.
+	tracker = newTracker()
+	rig.core.scheduleOrderTTL(dc, tracker)
+	time.Sleep(40 * time.Millisecond)
+	if canceled(tracker) {
+		t.Fatalf("order canceled with request error")
+	}
+	rig.queueCancel(nil)
+	if !waitFor(func() bool { return canceled(tracker) }) {
+		t.Fatalf("order not canceled after retry")
 	}
 
-	// If there is no change coin and no matches, the funding coin should be
-	// returned instead.
-	resetMatches()
-	// The change coins would also have been added to the coins map, so delete
-	// that too.
-	delete(tracker.coins, tDcrWallet.changeCoin.String())
-	err = handleMatchRoute(tCore, rig.dc, msg)
-	if err != nil {
-		t.Fatalf("handleMatchRoute error (cancel without swaps): %v", err)
+	// An order filled before its TTL is not canceled.
+	tracker = newTracker()
+	rig.core.scheduleOrderTTL(dc, tracker)
+	tracker.mtx.Lock()
+	tracker.metaData.Status = order.OrderStatusExecuted
+	tracker.mtx.Unlock()
+	time.Sleep(40 * time.Millisecond)
+	if scheduled(tracker) {
+		t.Fatalf("TTL timer not removed")
 	}
-	if len(tDcrWallet.returnedCoins) != 1 || !bytes.Equal(tDcrWallet.returnedCoins[0].ID(), fundCoinDcrID) {
-		t.Fatalf("change coin not returned (cancel without swaps)")
+	if canceled(tracker) {
+		t.Fatalf("filled order canceled at TTL")
 	}
 
-	// If the order is an immediate order, the asset.Swaps.LockChange should be
-	// false regardless of whether the order is filled.
-	resetMatches()
-	tracker.cancel = nil
-	rig.ws.queueResponse(msgjson.InitRoute, initAcker)
-	msgMatch.Side = uint8(order.Maker)
-	sign(tDexPriv, msgMatch) // Side is not in the serialization but whatever
-	msg, _ = msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
-
-	tracker.metaData.Status = order.OrderStatusEpoch
-	lo.Force = order.ImmediateTiF
-	err = handleMatchRoute(tCore, rig.dc, msg)
-	if err != nil {
-		t.Fatalf("handleMatchRoute error (immediate partial fill): %v", err)
+	// A filled order that is loaded from the DB is not scheduled.
+	tracker = newTracker()
+	tracker.metaData.Status = order.OrderStatusExecuted
+	rig.core.scheduleOrderTTL(dc, tracker)
+	if scheduled(tracker) {
+		t.Fatalf("TTL cancel scheduled for filled order")
 	}
-	lastSwaps := tDcrWallet.lastSwaps[len(tDcrWallet.lastSwaps)-1]
-	if lastSwaps.LockChange != false {
-		t.Fatalf("change locked for executed non-standing order (immediate partial fill)")
+
+	// An order without a TTL is not scheduled.
+	tracker = newTracker()
+	tracker.metaData.CancelAfter = 0
+	rig.core.scheduleOrderTTL(dc, tracker)
+	if scheduled(tracker) {
+		t.Fatalf("TTL cancel scheduled for order without a TTL")
 	}
 }
 
-func TestReconcileTrades(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	dc := rig.dc
-
-	mkt := dc.marketConfig(tDcrBtcMktName)
-	rig.core.wallets[mkt.Base], _ = newTWallet(mkt.Base)
-	rig.core.wallets[mkt.Quote], _ = newTWallet(mkt.Quote)
-	walletSet, _, _, err := rig.core.walletSet(dc, mkt.Base, mkt.Quote, true)
-	if err != nil {
-		t.Fatalf("walletSet error: %v", err)
-	}
+func TestHandlePreimageRequest(t *testing.T) {
+	t.Run("basic checks", func(t *testing.T) {
+		rig := newTestRig()
+		defer rig.shutdown()
+		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
+		oid := ord.ID()
+		preImg := newPreimage()
 
-	type orderSet struct {
-		epoch               *trackedTrade
-		booked              *trackedTrade // standing limit orders only
-		bookedPendingCancel *trackedTrade // standing limit orders only
-		executed            *trackedTrade
-	}
-	makeOrderSet := func(force order.TimeInForce) *orderSet {
-		orders := &orderSet{
-			epoch:    makeTradeTracker(rig, walletSet, force, order.OrderStatusEpoch),
-			executed: makeTradeTracker(rig, walletSet, force, order.OrderStatusExecuted),
+		// It is no longer OK for server to omit the commitment.
+		payload := &msgjson.PreimageRequest{
+			OrderID: oid[:],
+			// No commitment in this request.
 		}
-		if force == order.StandingTiF {
-			orders.booked = makeTradeTracker(rig, walletSet, force, order.OrderStatusBooked)
-			orders.bookedPendingCancel = makeTradeTracker(rig, walletSet, force, order.OrderStatusBooked)
-			orders.bookedPendingCancel.cancel = &trackedCancel{
-				CancelOrder: order.CancelOrder{
-					P: order.Prefix{
-						ServerTime: time.Now().UTC().Add(-16 * time.Minute),
-					},
-				},
-				epochLen: mkt.EpochLen,
-			}
+		reqNoCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
+		// mkt := dc.marketConfig(tDcrBtcMktName)
+
+		tracker := &trackedTrade{
+			Order:    ord,
+			preImg:   preImg,
+			mktID:    tDcrBtcMktName,
+			db:       rig.db,
+			dc:       rig.dc,
+			metaData: &db.OrderMetaData{},
 		}
-		return orders
-	}
 
-	standingOrders := makeOrderSet(order.StandingTiF)
-	immediateOrders := makeOrderSet(order.ImmediateTiF)
+		// resetCsum resets csum for further preimage request since multiple
+		// testing scenarios use the same tracker object.
+		resetCsum := func(tracker *trackedTrade) {
+			tracker.mtx.Lock()
+			tracker.csum = nil
+			tracker.mtx.Unlock()
+		}
 
-	tests := []struct {
-		name                string
-		clientOrders        []*trackedTrade        // orders known to the client
-		serverOrders        []*msgjson.OrderStatus // orders considered active by the server
-		orderStatusRes      []*msgjson.OrderStatus // server's response to order_status requests
-		expectOrderStatuses map[order.OrderID]order.OrderStatus
-	}{
-		{
-			name:         "server orders unknown to client",
-			clientOrders: []*trackedTrade{},
-			serverOrders: []*msgjson.OrderStatus{
-				{
-					ID:     ordertest.RandomOrderID().Bytes(),
-					Status: uint16(order.OrderStatusBooked),
-				},
-			},
-			expectOrderStatuses: map[order.OrderID]order.OrderStatus{},
-		},
-		{
-			name: "different server-client statuses",
-			clientOrders: []*trackedTrade{
-				standingOrders.epoch,
-				standingOrders.booked,
-				standingOrders.bookedPendingCancel,
-				immediateOrders.epoch,
-				immediateOrders.executed,
-			},
-			serverOrders: []*msgjson.OrderStatus{
-				{
-					ID:     standingOrders.epoch.ID().Bytes(),
-					Status: uint16(order.OrderStatusBooked), // now booked
-				},
-				{
-					ID:     standingOrders.booked.ID().Bytes(),
-					Status: uint16(order.OrderStatusEpoch), // invald! booked orders cannot return to epoch!
-				},
-				{
-					ID:     standingOrders.bookedPendingCancel.ID().Bytes(),
-					Status: uint16(order.OrderStatusBooked), // still booked, cancel order should be deleted
-				},
-				{
-					ID:     immediateOrders.epoch.ID().Bytes(),
-					Status: uint16(order.OrderStatusBooked), // invalid, immediate orders cannot be booked!
-				},
-				{
-					ID:     immediateOrders.executed.ID().Bytes(),
-					Status: uint16(order.OrderStatusBooked), // invalid, inactive orders should not be returned by DEX!
-				},
-			},
-			expectOrderStatuses: map[order.OrderID]order.OrderStatus{
-				standingOrders.epoch.ID():               order.OrderStatusBooked,   // epoch => booked
-				standingOrders.booked.ID():              order.OrderStatusBooked,   // should not change, cannot return to epoch
-				standingOrders.bookedPendingCancel.ID(): order.OrderStatusBooked,   // no status change
-				immediateOrders.epoch.ID():              order.OrderStatusEpoch,    // should not change, cannot be booked
-				immediateOrders.executed.ID():           order.OrderStatusExecuted, // should not change, inactive cannot become active
-			},
-		},
-		{
-			name: "active becomes inactive",
-			clientOrders: []*trackedTrade{
-				standingOrders.epoch,
-				standingOrders.booked,
-				standingOrders.bookedPendingCancel,
-				standingOrders.executed,
-				immediateOrders.epoch,
-				immediateOrders.executed,
-			},
-			serverOrders: []*msgjson.OrderStatus{}, // no active order reported by server
-			orderStatusRes: []*msgjson.OrderStatus{
-				{
-					ID:     standingOrders.epoch.ID().Bytes(),
-					Status: uint16(order.OrderStatusRevoked),
-				},
-				{
-					ID:     standingOrders.booked.ID().Bytes(),
-					Status: uint16(order.OrderStatusRevoked),
-				},
-				{
-					ID:     standingOrders.bookedPendingCancel.ID().Bytes(),
-					Status: uint16(order.OrderStatusCanceled),
-				},
-				{
-					ID:     immediateOrders.epoch.ID().Bytes(),
-					Status: uint16(order.OrderStatusExecuted),
-				},
-			},
-			expectOrderStatuses: map[order.OrderID]order.OrderStatus{
-				standingOrders.epoch.ID():               order.OrderStatusRevoked,  // preimage missed = revoked
-				standingOrders.booked.ID():              order.OrderStatusRevoked,  // booked, not canceled = assume revoked (may actually be executed)
-				standingOrders.bookedPendingCancel.ID(): order.OrderStatusCanceled, // booked pending canceled = assume canceled (may actually be revoked or executed)
-				standingOrders.executed.ID():            order.OrderStatusExecuted, // should not change
-				immediateOrders.epoch.ID():              order.OrderStatusExecuted, // preimage sent, not canceled = executed
-				immediateOrders.executed.ID():           order.OrderStatusExecuted, // should not change
-			},
-		},
-	}
+		rig.dc.trades[oid] = tracker
+		err := handlePreimageRequest(rig.core, rig.dc, reqNoCommit)
+		if err == nil {
+			t.Fatalf("handlePreimageRequest succeeded with no commitment in the request")
+		}
+		resetCsum(tracker)
 
-	for _, tt := range tests {
-		// Track client orders in dc.trades.
-		dc.tradeMtx.Lock()
-		var pendingCancel *trackedTrade
-		dc.trades = make(map[order.OrderID]*trackedTrade)
-		for _, tracker := range tt.clientOrders {
-			dc.trades[tracker.ID()] = tracker
-			if tracker.cancel != nil {
-				pendingCancel = tracker
-			}
+		// Test the new path with rig.core.sentCommits.
+		readyCommitment := func(commit order.Commitment) chan struct{} {
+			commitSig := make(chan struct{}) // close after fake order submission is "done"
+			rig.core.sentCommitsMtx.Lock()
+			rig.core.sentCommits[commit] = commitSig
+			rig.core.sentCommitsMtx.Unlock()
+			return commitSig
 		}
-		dc.tradeMtx.Unlock()
 
-		// Queue order_status response if required for reconciliation.
-		if len(tt.orderStatusRes) > 0 {
-			rig.ws.queueResponse(msgjson.OrderStatusRoute, func(msg *msgjson.Message, f msgFunc) error {
-				resp, _ := msgjson.NewResponse(msg.ID, tt.orderStatusRes, nil)
-				f(resp)
-				return nil
-			})
+		commit := preImg.Commit()
+		commitSig := readyCommitment(commit)
+		payload = &msgjson.PreimageRequest{
+			OrderID:    oid[:],
+			Commitment: commit[:],
 		}
+		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
 
-		// Reconcile tracked orders with server orders.
-		dc.reconcileTrades(tt.serverOrders)
+		notes := rig.core.NotificationFeed()
 
-		dc.tradeMtx.RLock()
-		if len(dc.trades) != len(tt.expectOrderStatuses) {
-			t.Fatalf("%s: post-reconcileTrades order count mismatch. expected %d, got %d",
-				tt.name, len(tt.expectOrderStatuses), len(dc.trades))
+		rig.dc.trades[oid] = tracker
+		err = handlePreimageRequest(rig.core, rig.dc, reqCommit)
+		if err != nil {
+			t.Fatalf("handlePreimageRequest error: %v", err)
 		}
-		for oid, tracker := range dc.trades {
-			expectedStatus, expected := tt.expectOrderStatuses[oid]
-			if !expected {
-				t.Fatalf("%s: unexpected order %v tracked by client", tt.name, oid)
-			}
-			tracker.mtx.RLock()
-			if tracker.metaData.Status != expectedStatus {
-				t.Fatalf("%s: client reported wrong order status %v, expected %v",
-					tt.name, tracker.metaData.Status, expectedStatus)
+		resetCsum(tracker)
+
+		// It has gone async now, waiting for commitSig.
+		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
+		close(commitSig) // pretend like the order submission just finished
+
+		select {
+		case note := <-notes.C:
+			if note.Topic() != TopicPreimageSent {
+				t.Fatalf("note subject is %v, not %v", note.Topic(), TopicPreimageSent)
 			}
-			tracker.mtx.RUnlock()
+		case <-time.After(time.Second):
+			t.Fatal("no order note from preimage request handling")
 		}
-		dc.tradeMtx.RUnlock()
 
-		// Check if a previously canceled order existed; if the order is still
-		// active (Epoch/Booked status) and the cancel order is deleted, having
-		// been there for over 15 minutes since the cancel order's epoch ended.
-		if pendingCancel != nil {
-			pendingCancel.mtx.RLock()
-			status, stillHasCancelOrder := pendingCancel.metaData.Status, pendingCancel.cancel != nil
-			pendingCancel.mtx.RUnlock()
-			if status == order.OrderStatusBooked {
-				if stillHasCancelOrder {
-					t.Fatalf("%s: expected stale cancel order to be deleted for now-booked order", tt.name)
-				}
-				// Cancel order deleted. Canceling the order again should succeed.
-				rig.queueCancel(nil)
-				err = rig.core.Cancel(pendingCancel.ID().Bytes())
-				if err != nil {
-					t.Fatalf("cancel order error after deleting previous stale cancel: %v", err)
-				}
+		// negative paths
+		ensureErr := func(tag string, req *msgjson.Message, errPrefix string) {
+			t.Helper()
+			commitSig := readyCommitment(commit)
+			close(commitSig) // ready before preimage request
+			err := handlePreimageRequest(rig.core, rig.dc, req)
+			if err == nil {
+				t.Fatalf("%s: no error", tag)
+			}
+			if !strings.HasPrefix(err.Error(), errPrefix) {
+				t.Fatalf("expected error starting with %q, got %q", errPrefix, err)
 			}
+			resetCsum(tracker)
 		}
-	}
-}
-
-func makeTradeTracker(rig *testRig, walletSet *walletSet, force order.TimeInForce, status order.OrderStatus) *trackedTrade {
-	qty := 4 * dcrBtcLotSize
-	lo, dbOrder, preImg, _ := makeLimitOrder(rig.dc, true, qty, dcrBtcRateStep)
-	lo.Force = force
-	dbOrder.MetaData.Status = status
 
-	return newTrackedTrade(dbOrder, preImg, rig.dc,
-		rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, nil, rig.core.notify,
-		rig.core.formatDetails)
-}
+		// unknown commitment in request
+		payloadBad := &msgjson.PreimageRequest{
+			OrderID:    oid[:],
+			Commitment: encode.RandomBytes(order.CommitmentSize), // junk, but correct length
+		}
+		reqCommitBad, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payloadBad)
+		ensureErr("unknown commitment", reqCommitBad, "received preimage request for unknown commitment")
+	})
+	t.Run("csum for order", func(t *testing.T) {
+		rig := newTestRig()
+		defer rig.shutdown()
+		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
+		oid := ord.ID()
+		preImg := newPreimage()
+		// mkt := dc.marketConfig(tDcrBtcMktName)
 
-func TestRefunds(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
+		tracker := &trackedTrade{
+			Order:    ord,
+			preImg:   preImg,
+			mktID:    tDcrBtcMktName,
+			db:       rig.db,
+			dc:       rig.dc,
+			metaData: &db.OrderMetaData{},
+		}
 
-	dc := rig.dc
-	tCore := rig.core
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	btcWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
-	btcWallet.Unlock(rig.crypter)
+		// Test the new path with rig.core.sentCommits.
+		readyCommitment := func(commit order.Commitment) chan struct{} {
+			commitSig := make(chan struct{}) // close after fake order submission is "done"
+			rig.core.sentCommitsMtx.Lock()
+			rig.core.sentCommits[commit] = commitSig
+			rig.core.sentCommitsMtx.Unlock()
+			return commitSig
+		}
 
-	ethWallet, tEthWallet := newTAccountLocker(tACCTAsset.ID)
-	tCore.wallets[tACCTAsset.ID] = ethWallet
-	ethWallet.address = "18d65fb8d60c1199bb1ad381be47aa692b482605"
-	ethWallet.Unlock(rig.crypter)
+		commit := preImg.Commit()
+		commitCSum := dex.Bytes{2, 3, 5, 7, 11, 13}
+		commitSig := readyCommitment(commit)
+		payload := &msgjson.PreimageRequest{
+			OrderID:        oid[:],
+			Commitment:     commit[:],
+			CommitChecksum: commitCSum,
+		}
+		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
 
-	checkStatus := func(tag string, match *matchTracker, wantStatus order.MatchStatus) {
-		t.Helper()
-		if match.Status != wantStatus {
-			t.Fatalf("%s: wrong status wanted %v, got %v", tag, wantStatus, match.Status)
+		notes := rig.core.NotificationFeed()
+
+		rig.dc.trades[oid] = tracker
+		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
+		if err != nil {
+			t.Fatalf("handlePreimageRequest error: %v", err)
 		}
-	}
-	checkRefund := func(tracker *trackedTrade, match *matchTracker, expectAmt uint64) {
-		t.Helper()
-		// Confirm that the status is SwapCast.
-		if match.Side == order.Maker {
-			checkStatus("maker swapped", match, order.MakerSwapCast)
-		} else {
-			checkStatus("taker swapped", match, order.TakerSwapCast)
+
+		// It has gone async now, waiting for commitSig.
+		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
+		close(commitSig) // pretend like the order submission just finished
+
+		select {
+		case note := <-notes.C:
+			if note.Topic() != TopicPreimageSent {
+				t.Fatalf("note subject is %v, not %v", note.Topic(), TopicPreimageSent)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no order note from preimage request handling")
 		}
-		// Confirm isRefundable = true.
-		if !tracker.isRefundable(tCore.ctx, match) {
-			t.Fatalf("%s's swap not refundable", match.Side)
+
+		tracker.mtx.RLock()
+		if !bytes.Equal(commitCSum, tracker.csum) {
+			t.Fatalf(
+				"handlePreimageRequest must initialize tracker csum, exp: %s, got: %s",
+				commitCSum,
+				tracker.csum,
+			)
 		}
-		// Check refund.
-		amtRefunded, err := rig.core.refundMatches(tracker, []*matchTracker{match})
-		if err != nil {
-			t.Fatalf("unexpected refund error %v", err)
+		tracker.mtx.RUnlock()
+	})
+	t.Run("more than one preimage request for order (different csums)", func(t *testing.T) {
+		rig := newTestRig()
+		defer rig.shutdown()
+		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
+		oid := ord.ID()
+		preImg := newPreimage()
+		// mkt := dc.marketConfig(tDcrBtcMktName)
+		firstCSum := dex.Bytes{2, 3, 5, 7, 11, 13}
+
+		tracker := &trackedTrade{
+			Order:  ord,
+			preImg: preImg,
+			mktID:  tDcrBtcMktName,
+			db:     rig.db,
+			dc:     rig.dc,
+			// Simulate first preimage request by initializing csum here.
+			csum:     firstCSum,
+			metaData: &db.OrderMetaData{},
 		}
-		// Check refunded amount.
-		if amtRefunded != expectAmt {
-			t.Fatalf("expected %d refund amount, got %d", expectAmt, amtRefunded)
+
+		// Test the new path with rig.core.sentCommits.
+		readyCommitment := func(commit order.Commitment) chan struct{} {
+			commitSig := make(chan struct{}) // close after fake order submission is "done"
+			rig.core.sentCommitsMtx.Lock()
+			rig.core.sentCommits[commit] = commitSig
+			rig.core.sentCommitsMtx.Unlock()
+			return commitSig
 		}
-		// Confirm isRefundable = false.
-		if tracker.isRefundable(tCore.ctx, match) {
-			t.Fatalf("%s's swap refundable after being refunded", match.Side)
+
+		commit := preImg.Commit()
+		commitSig := readyCommitment(commit)
+		secondCSum := dex.Bytes{2, 3, 5, 7, 11, 14}
+		payload := &msgjson.PreimageRequest{
+			OrderID:        oid[:],
+			Commitment:     commit[:],
+			CommitChecksum: secondCSum,
 		}
-		// Expect refund re-attempt to not refund any coin.
-		amtRefunded, err = rig.core.refundMatches(tracker, []*matchTracker{match})
+		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
+
+		// Prepare to have processPreimageRequest respond with a payload with
+		// the Error field set.
+		rig.ws.sendMsgErrChan = make(chan *msgjson.Error, 1)
+		defer func() { rig.ws.sendMsgErrChan = nil }()
+
+		rig.dc.trades[oid] = tracker
+		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
 		if err != nil {
-			t.Fatalf("unexpected refund error %v", err)
-		}
-		if amtRefunded != 0 {
-			t.Fatalf("expected 0 refund amount, got %d", amtRefunded)
-		}
-		// Confirm that the status is unchanged.
-		if match.Side == order.Maker {
-			checkStatus("maker swapped", match, order.MakerSwapCast)
-		} else {
-			checkStatus("taker swapped", match, order.TakerSwapCast)
+			t.Fatalf("handlePreimageRequest error: %v", err)
 		}
 
-		if _, is := tracker.accountRefunder(); is {
-			if tEthWallet.refundFeeSuggestion != tMaxFeeRate {
-				t.Fatalf("refund suggestion for account asset %v != server max fee rate %v",
-					tEthWallet.refundFeeSuggestion, tACCTAsset.MaxFeeRate)
+		// It has gone async now, waiting for commitSig.
+		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
+		close(commitSig) // pretend like the order submission just finished
+
+		select {
+		case msgErr := <-rig.ws.sendMsgErrChan:
+			if msgErr.Code != msgjson.InvalidRequestError {
+				t.Fatalf("expected error code %d got %d", msgjson.InvalidRequestError, msgErr.Code)
 			}
+		case <-time.After(time.Second):
+			t.Fatal("no msgjson.Error sent from preimage request handling")
 		}
-	}
 
-	matchSize := 4 * dcrBtcLotSize
-	qty := 3 * matchSize
-	rate := dcrBtcRateStep * 10
-	lo, dbOrder, preImgL, addr := makeLimitOrder(dc, false, qty, dcrBtcRateStep)
-	loid := lo.ID()
-	mid := ordertest.RandomMatchID()
-	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetB.ID, tACCTAsset.ID, false)
-	if err != nil {
-		t.Fatalf("walletSet error: %v", err)
-	}
-	fundCoinsETH := asset.Coins{&tCoin{id: encode.RandomBytes(36)}}
-	tEthWallet.fundingCoins = fundCoinsETH
-	tEthWallet.fundRedeemScripts = []dex.Bytes{nil}
-	tracker := newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, fundCoinsETH, rig.core.notify, rig.core.formatDetails)
-	rig.dc.trades[tracker.ID()] = tracker
+		tracker.mtx.RLock()
+		if !bytes.Equal(firstCSum, tracker.csum) {
+			t.Fatalf(
+				"[handlePreimageRequest] csum was changed, exp: %s, got: %s",
+				firstCSum,
+				tracker.csum,
+			)
+		}
+		tracker.mtx.RUnlock()
+	})
+	t.Run("more than one preimage request for order (same csum)", func(t *testing.T) {
+		rig := newTestRig()
+		defer rig.shutdown()
+		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
+		oid := ord.ID()
+		preImg := newPreimage()
+		// mkt := dc.marketConfig(tDcrBtcMktName)
+		csum := dex.Bytes{2, 3, 5, 7, 11, 13}
 
-	// MAKER REFUND, INVALID TAKER COUNTERSWAP
-	//
+		tracker := &trackedTrade{
+			Order:  ord,
+			preImg: preImg,
+			mktID:  tDcrBtcMktName,
+			db:     rig.db,
+			dc:     rig.dc,
+			// Simulate first preimage request by initializing csum here.
+			csum:     csum,
+			metaData: &db.OrderMetaData{},
+		}
 
-	matchTime := time.Now().Truncate(time.Millisecond).UTC()
-	msgMatch := &msgjson.Match{
-		OrderID:      loid[:],
-		MatchID:      mid[:],
-		Quantity:     matchSize,
-		Rate:         rate,
-		Address:      "counterparty-address",
-		Side:         uint8(order.Maker),
-		ServerTime:   uint64(matchTime.UnixMilli()),
-		FeeRateQuote: tMaxFeeRate,
-	}
-	swapID := encode.RandomBytes(36)
-	contract := encode.RandomBytes(36)
-	tEthWallet.swapReceipts = []asset.Receipt{&tReceipt{coin: &tCoin{id: swapID}, contract: contract}}
-	sign(tDexPriv, msgMatch)
-	msg, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
-	rig.ws.queueResponse(msgjson.InitRoute, initAcker)
-	err = handleMatchRoute(tCore, rig.dc, msg)
-	if err != nil {
-		t.Fatalf("match messages error: %v", err)
-	}
-	match, found := tracker.matches[mid]
-	if !found {
-		t.Fatalf("match not found")
-	}
+		// Test the new path with rig.core.sentCommits.
+		readyCommitment := func(commit order.Commitment) chan struct{} {
+			commitSig := make(chan struct{}) // close after fake order submission is "done"
+			rig.core.sentCommitsMtx.Lock()
+			rig.core.sentCommits[commit] = commitSig
+			rig.core.sentCommitsMtx.Unlock()
+			return commitSig
+		}
 
-	// We're the maker, so the init transaction should be broadcast.
-	checkStatus("maker swapped", match, order.MakerSwapCast)
-	proof := &match.MetaData.Proof
-	if !bytes.Equal(proof.ContractData, contract) {
-		t.Fatalf("invalid contract recorded for Maker swap")
-	}
+		commit := preImg.Commit()
+		commitSig := readyCommitment(commit)
+		payload := &msgjson.PreimageRequest{
+			OrderID:        oid[:],
+			Commitment:     commit[:],
+			CommitChecksum: csum,
+		}
+		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
 
-	// Send the counter-party's init info.
-	audit, auditInfo := tMsgAudit(loid, mid, addr, matchSize, proof.SecretHash)
-	tBtcWallet.auditInfo = auditInfo
-	auditInfo.Expiration = encode.DropMilliseconds(matchTime.Add(tracker.lockTimeMaker).UTC())
+		notes := rig.core.NotificationFeed()
 
-	// Check audit errors.
-	tBtcWallet.auditErr = tErr
-	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
-	if err == nil {
-		t.Fatalf("no maker error for AuditContract error")
-	}
+		rig.dc.trades[oid] = tracker
+		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
+		if err != nil {
+			t.Fatalf("handlePreimageRequest error: %v", err)
+		}
 
-	// Attempt refund.
-	tEthWallet.refundCoin = encode.RandomBytes(36)
-	tEthWallet.refundErr = nil
-	tBtcWallet.refundCoin = nil
-	tBtcWallet.refundErr = fmt.Errorf("unexpected call to btcWallet.Refund")
-	matchSizeQuoteUnits := calc.BaseToQuote(rate, matchSize)
-	// Make the contract appear expired
-	tEthWallet.contractExpired = true
-	tEthWallet.contractLockTime = time.Now()
-	checkRefund(tracker, match, matchSizeQuoteUnits)
-	tEthWallet.contractExpired = false
-	tEthWallet.contractLockTime = time.Now().Add(time.Minute)
+		// It has gone async now, waiting for commitSig.
+		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
+		close(commitSig) // pretend like the order submission just finished
 
-	// TAKER REFUND, NO MAKER REDEEM
-	//
-	// Reset funding coins in the trackedTrade, wipe change coin.
-	matchTime = time.Now().Truncate(time.Millisecond).UTC()
-	tracker.mtx.Lock()
-	tracker.coins = mapifyCoins(fundCoinsETH)
-	tracker.coinsLocked = true
-	tracker.changeLocked = false
-	tracker.change = nil
-	tracker.metaData.ChangeCoin = nil
-	tracker.mtx.Unlock()
-	mid = ordertest.RandomMatchID()
-	msgMatch = &msgjson.Match{
-		OrderID:      loid[:],
-		MatchID:      mid[:],
-		Quantity:     matchSize,
-		Rate:         rate,
-		Address:      "counterparty-address",
-		Side:         uint8(order.Taker),
-		ServerTime:   uint64(matchTime.UnixMilli()),
-		FeeRateQuote: tMaxFeeRate,
-	}
-	sign(tDexPriv, msgMatch)
-	msg, _ = msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
-	err = handleMatchRoute(tCore, rig.dc, msg)
-	if err != nil {
-		t.Fatalf("match messages error: %v", err)
-	}
-	match, found = tracker.matches[mid]
-	if !found {
-		t.Fatalf("match not found")
-	}
-	checkStatus("taker matched", match, order.NewlyMatched)
-	// Send through the audit request for the maker's init.
-	rig.db.updateMatchChan = make(chan order.MatchStatus, 1)
-	audit, auditInfo = tMsgAudit(loid, mid, addr, matchSize, nil)
-	tBtcWallet.auditInfo = auditInfo
-	auditInfo.Expiration = encode.DropMilliseconds(matchTime.Add(tracker.lockTimeMaker))
-	tBtcWallet.auditErr = nil
-	msg, _ = msgjson.NewRequest(1, msgjson.AuditRoute, audit)
-	err = handleAuditRoute(tCore, rig.dc, msg)
-	if err != nil {
-		t.Fatalf("taker's match message error: %v", err)
-	}
-	// let the async auditContract run, updating match status
-	newMatchStatus := <-rig.db.updateMatchChan
-	if newMatchStatus != order.MakerSwapCast {
-		t.Fatalf("wrong match status. wanted %v, got %v", order.MakerSwapCast, newMatchStatus)
-	}
-	<-rig.db.updateMatchChan // AuditSig set in second update to match data
-	tracker.mtx.RLock()
-	if !bytes.Equal(match.MetaData.Proof.Auth.AuditSig, audit.Sig) {
-		t.Fatalf("audit sig not set for taker")
-	}
-	tracker.mtx.RUnlock()
-	// maker's swap confirmation should trigger taker's swap bcast
-	tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
-	counterSwapID := encode.RandomBytes(36)
-	counterScript := encode.RandomBytes(36)
-	tEthWallet.swapReceipts = []asset.Receipt{&tReceipt{coin: &tCoin{id: counterSwapID}, contract: counterScript}}
-	rig.ws.queueResponse(msgjson.InitRoute, initAcker)
-	tCore.tickAsset(dc, tUTXOAssetB.ID)
-	newMatchStatus = <-rig.db.updateMatchChan // MakerSwapCast->TakerSwapCast (after taker's swap bcast)
-	if newMatchStatus != order.TakerSwapCast {
-		t.Fatalf("wrong match status. wanted %v, got %v", order.TakerSwapCast, newMatchStatus)
-	}
-	tracker.mtx.RLock()
-	if !bytes.Equal(match.MetaData.Proof.ContractData, counterScript) {
-		t.Fatalf("invalid contract recorded for Taker swap")
-	}
-	tracker.mtx.RUnlock()
-	// still takerswapcast, but with initsig
-	newMatchStatus = <-rig.db.updateMatchChan
-	if newMatchStatus != order.TakerSwapCast {
-		t.Fatalf("wrong match status wanted %v, got %v", order.TakerSwapCast, newMatchStatus)
-	}
-	tracker.mtx.RLock()
-	auth := &match.MetaData.Proof.Auth
-	if len(auth.InitSig) == 0 {
-		t.Fatalf("init sig not recorded for valid init ack")
-	}
-	tracker.mtx.RUnlock()
+		select {
+		case note := <-notes.C:
+			if note.Topic() != TopicPreimageSent {
+				t.Fatalf("note subject is %v, not %v", note.Topic(), TopicPreimageSent)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no order note from preimage request handling")
+		}
 
-	// Attempt refund.
-	rig.db.updateMatchChan = nil
-	tEthWallet.contractExpired = true
-	tEthWallet.contractLockTime = time.Now()
-	checkRefund(tracker, match, matchSizeQuoteUnits)
-	tEthWallet.contractExpired = false
-	tEthWallet.contractLockTime = time.Now().Add(time.Minute)
-}
+		tracker.mtx.RLock()
+		if !bytes.Equal(csum, tracker.csum) {
+			t.Fatalf(
+				"[handlePreimageRequest] csum was changed, exp: %s, got: %s",
+				csum,
+				tracker.csum,
+			)
+		}
+		tracker.mtx.RUnlock()
+	})
+	t.Run("csum for cancel order", func(t *testing.T) {
+		rig := newTestRig()
+		defer rig.shutdown()
+		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
+		preImg := newPreimage()
+		mkt := rig.dc.marketConfig(tDcrBtcMktName)
 
-func TestNotifications(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
+		tracker := &trackedTrade{
+			Order:    ord,
+			preImg:   preImg,
+			mktID:    tDcrBtcMktName,
+			db:       rig.db,
+			dc:       rig.dc,
+			metaData: &db.OrderMetaData{},
+			cancel: &trackedCancel{
+				CancelOrder: order.CancelOrder{
+					P: order.Prefix{
+						AccountID:  rig.dc.acct.ID(),
+						BaseAsset:  tUTXOAssetA.ID,
+						QuoteAsset: tUTXOAssetB.ID,
+						OrderType:  order.MarketOrderType,
+						ClientTime: time.Now(),
+						ServerTime: time.Now().Add(time.Millisecond),
+						Commit:     preImg.Commit(),
+					},
+				},
+				epochLen: mkt.EpochLen,
+			},
+		}
+		oid := tracker.cancel.ID()
 
-	// Insert a notification into the database.
-	typedNote := newOrderNote("123", "abc", "def", 100, nil)
+		// Test the new path with rig.core.sentCommits.
+		readyCommitment := func(commit order.Commitment) chan struct{} {
+			commitSig := make(chan struct{}) // close after fake order submission is "done"
+			rig.core.sentCommitsMtx.Lock()
+			rig.core.sentCommits[commit] = commitSig
+			rig.core.sentCommitsMtx.Unlock()
+			return commitSig
+		}
 
-	tCore := rig.core
-	ch := tCore.NotificationFeed()
-	tCore.notify(typedNote)
-	select {
-	case n := <-ch.C:
-		dbtest.MustCompareNotifications(t, n.DBNote(), &typedNote.Notification)
-	case <-time.After(time.Second):
-		t.Fatalf("no notification received over the notification channel")
-	}
-}
+		commit := preImg.Commit()
+		commitCSum := dex.Bytes{2, 3, 5, 7, 11, 13}
+		commitSig := readyCommitment(commit)
+		payload := &msgjson.PreimageRequest{
+			OrderID:        oid[:],
+			Commitment:     commit[:],
+			CommitChecksum: commitCSum,
+		}
+		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
 
-func generateMatch(rig *testRig, baseID, quoteID uint32) (uint64, *order.LimitOrder, *db.MetaOrder, *db.MetaMatch, *tCoin) {
-	const redemptionReserves = 50
-	const refundReserves = 75
+		notes := rig.core.NotificationFeed()
 
-	qty := dcrBtcLotSize * 5
-	rate := dcrBtcRateStep * 5
-	lo := &order.LimitOrder{
-		P: order.Prefix{
-			OrderType:  order.LimitOrderType,
-			BaseAsset:  baseID,
-			QuoteAsset: quoteID,
-			ClientTime: time.Now(),
-			ServerTime: time.Now(),
-			Commit:     ordertest.RandomCommitment(),
-		},
-		T: order.Trade{
-			Quantity: qty,
-			Sell:     true,
-		},
-		Rate:  rate,
-		Force: order.StandingTiF, // we're calling it booked in OrderMetaData
-	}
+		rig.dc.trades[order.OrderID{}] = tracker
+		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
+		if err != nil {
+			t.Fatalf("handlePreimageRequest error: %v", err)
+		}
 
-	changeCoinID := encode.RandomBytes(36)
-	changeCoin := &tCoin{id: changeCoinID}
+		// It has gone async now, waiting for commitSig.
+		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
+		close(commitSig) // pretend like the order submission just finished
 
-	dbOrder := &db.MetaOrder{
-		MetaData: &db.OrderMetaData{
-			Status:             order.OrderStatusBooked,
-			Host:               tDexHost,
-			Proof:              db.OrderProof{},
-			ChangeCoin:         changeCoinID,
-			RedemptionReserves: redemptionReserves,
-			RefundReserves:     refundReserves,
-		},
-		Order: lo,
-	}
+		select {
+		case note := <-notes.C:
+			if note.Topic() != TopicCancelPreimageSent {
+				t.Fatalf("note subject is %v, not %v", note.Topic(), TopicCancelPreimageSent)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no order note from preimage request handling")
+		}
 
-	oid := lo.ID()
-	mid := ordertest.RandomMatchID()
-	addr := ordertest.RandomAddress()
-	matchQty := qty - dcrBtcLotSize
-	match := &db.MetaMatch{
-		MetaData: &db.MatchMetaData{
-			Proof: db.MatchProof{
-				CounterContract: encode.RandomBytes(50),
-				SecretHash:      encode.RandomBytes(32),
-				MakerSwap:       encode.RandomBytes(32),
-				Auth: db.MatchAuth{
-					MatchSig: encode.RandomBytes(32),
-					InitSig:  encode.RandomBytes(32), // otherwise MatchComplete will be seen as a cancel order match (inactive)
+		tracker.mtx.RLock()
+		if !bytes.Equal(commitCSum, tracker.cancel.csum) {
+			t.Fatalf(
+				"handlePreimageRequest must initialize tracker cancel csum, exp: %s, got: %s",
+				commitCSum,
+				tracker.cancel.csum,
+			)
+		}
+		tracker.mtx.RUnlock()
+	})
+	t.Run("more than one preimage request for cancel order (different csums)", func(t *testing.T) {
+		rig := newTestRig()
+		defer rig.shutdown()
+		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
+		preImg := newPreimage()
+		mkt := rig.dc.marketConfig(tDcrBtcMktName)
+		firstCSum := dex.Bytes{2, 3, 5, 7, 11, 13}
+
+		tracker := &trackedTrade{
+			Order:    ord,
+			preImg:   preImg,
+			mktID:    tDcrBtcMktName,
+			db:       rig.db,
+			dc:       rig.dc,
+			metaData: &db.OrderMetaData{},
+			cancel: &trackedCancel{
+				// Simulate first preimage request by initializing csum here.
+				csum: firstCSum,
+				CancelOrder: order.CancelOrder{
+					P: order.Prefix{
+						AccountID:  rig.dc.acct.ID(),
+						BaseAsset:  tUTXOAssetA.ID,
+						QuoteAsset: tUTXOAssetB.ID,
+						OrderType:  order.MarketOrderType,
+						ClientTime: time.Now(),
+						ServerTime: time.Now().Add(time.Millisecond),
+						Commit:     preImg.Commit(),
+					},
 				},
+				epochLen: mkt.EpochLen,
 			},
-			DEX:   tDexHost,
-			Base:  baseID,
-			Quote: quoteID,
-		},
-		UserMatch: &order.UserMatch{
-			OrderID:  oid,
-			MatchID:  mid,
-			Quantity: matchQty,
-			Rate:     rate,
-			Address:  addr,
-			Status:   order.MakerSwapCast,
-			Side:     order.Taker,
-		},
-	}
-
-	// Need to return an order from db.ActiveDEXOrders
-	rig.db.activeDEXOrders = []*db.MetaOrder{dbOrder}
-	rig.db.orderOrders[oid] = dbOrder
-
-	rig.db.activeMatchOIDs = []order.OrderID{oid}
-	rig.db.matchesForOID = []*db.MetaMatch{match}
-
-	return qty, lo, dbOrder, match, changeCoin
-}
-
-var (
-	activeStatuses   = []order.OrderStatus{order.OrderStatusEpoch, order.OrderStatusBooked}
-	inactiveStatuses = []order.OrderStatus{order.OrderStatusExecuted, order.OrderStatusCanceled, order.OrderStatusRevoked}
-
-	reservationTests = []struct {
-		name          string
-		sell          bool
-		side          []order.MatchSide
-		orderStatuses []order.OrderStatus
-		matchStatuses []order.MatchStatus
-		expectedCoins int
-	}{
-		// With an active order, the change coin should always be loaded.
-		{
-			name:          "active-order, sell",
-			sell:          true,
-			side:          []order.MatchSide{order.Taker, order.Maker},
-			orderStatuses: activeStatuses,
-			matchStatuses: []order.MatchStatus{order.NewlyMatched, order.MakerSwapCast,
-				order.TakerSwapCast, order.MakerRedeemed, order.MatchComplete},
-			expectedCoins: 1,
-		},
-		{
-			name:          "active-order, buy",
-			sell:          false,
-			side:          []order.MatchSide{order.Taker, order.Maker},
-			orderStatuses: activeStatuses,
-			matchStatuses: []order.MatchStatus{order.NewlyMatched, order.MakerSwapCast,
-				order.TakerSwapCast, order.MakerRedeemed, order.MatchComplete},
-			expectedCoins: 1,
-		},
-		// With an inactive order, as taker, if match is >= TakerSwapCast, there
-		// will be no funding coin fetched.
-		{
-			name:          "inactive taker > MakerSwapCast, sell",
-			sell:          true,
-			side:          []order.MatchSide{order.Taker},
-			orderStatuses: inactiveStatuses,
-			matchStatuses: []order.MatchStatus{order.TakerSwapCast, order.MakerRedeemed,
-				order.MatchComplete},
-			expectedCoins: 0,
-		},
-		{
-			name:          "inactive taker > MakerSwapCast, buy",
-			sell:          false,
-			side:          []order.MatchSide{order.Taker},
-			orderStatuses: inactiveStatuses,
-			matchStatuses: []order.MatchStatus{order.TakerSwapCast, order.MakerRedeemed,
-				order.MatchComplete},
-			expectedCoins: 0,
-		},
-		// But there will be for NewlyMatched && MakerSwapCast
-		{
-			name:          "inactive taker < TakerSwapCast, sell",
-			sell:          true,
-			side:          []order.MatchSide{order.Taker},
-			orderStatuses: inactiveStatuses,
-			matchStatuses: []order.MatchStatus{order.NewlyMatched, order.MakerSwapCast},
-			expectedCoins: 1,
-		},
-		{
-			name:          "inactive taker < TakerSwapCast, buy",
-			sell:          false,
-			side:          []order.MatchSide{order.Taker},
-			orderStatuses: inactiveStatuses,
-			matchStatuses: []order.MatchStatus{order.NewlyMatched, order.MakerSwapCast},
-			expectedCoins: 1,
-		},
-		// For a maker with an inactive order, only NewlyMatched would
-		// necessitate fetching of coins.
-		{
-			name:          "inactive maker NewlyMatched, sell",
-			sell:          true,
-			side:          []order.MatchSide{order.Maker},
-			orderStatuses: inactiveStatuses,
-			matchStatuses: []order.MatchStatus{order.NewlyMatched},
-			expectedCoins: 1,
-		},
-		{
-			name:          "inactive maker NewlyMatched, buy",
-			sell:          false,
-			side:          []order.MatchSide{order.Maker},
-			orderStatuses: inactiveStatuses,
-			matchStatuses: []order.MatchStatus{order.NewlyMatched},
-			expectedCoins: 1,
-		},
-		{
-			name:          "inactive maker > NewlyMatched, sell",
-			sell:          true,
-			side:          []order.MatchSide{order.Maker},
-			orderStatuses: inactiveStatuses,
-			matchStatuses: []order.MatchStatus{order.MakerSwapCast, order.TakerSwapCast,
-				order.MakerRedeemed, order.MatchComplete},
-			expectedCoins: 0,
-		},
-		{
-			name:          "inactive maker > NewlyMatched, buy",
-			sell:          false,
-			side:          []order.MatchSide{order.Maker},
-			orderStatuses: inactiveStatuses,
-			matchStatuses: []order.MatchStatus{order.MakerSwapCast, order.TakerSwapCast,
-				order.MakerRedeemed, order.MatchComplete},
-			expectedCoins: 0,
-		},
-	}
-)
-
-// auth sets the account as authenticated at the provided tier.
-func auth(a *dexAccount) {
-	a.authMtx.Lock()
-	a.isAuthed = true
-	a.rep = account.Reputation{BondedTier: 1}
-	a.authMtx.Unlock()
-}
-
-func TestResolveActiveTrades(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-
-	auth(rig.acct) // Short path through initializeDEXConnections
-
-	utxoAsset /* base */, acctAsset /* quote */ := tUTXOAssetB, tACCTAsset
-
-	btcWallet, tBtcWallet := newTWallet(utxoAsset.ID)
-	tCore.wallets[utxoAsset.ID] = btcWallet
-
-	ethWallet, tEthWallet := newTAccountLocker(acctAsset.ID)
-	tCore.wallets[acctAsset.ID] = ethWallet
-
-	// Create an order
-	qty, lo, dbOrder, match, changeCoin := generateMatch(rig, utxoAsset.ID, acctAsset.ID)
-	redemptionReserves, refundReserves := dbOrder.MetaData.RedemptionReserves, dbOrder.MetaData.RefundReserves
-
-	tBtcWallet.fundingCoins = asset.Coins{changeCoin}
-	tEthWallet.fundingCoins = asset.Coins{changeCoin}
+		}
+		oid := tracker.cancel.ID()
 
-	// reset
-	reset := func() {
-		rig.acct.lock()
-		btcWallet.Lock(time.Second)
-		ethWallet.Lock(time.Second)
-		tEthWallet.reservedRedemption = 0
-		tEthWallet.reservedRefund = 0
+		// Test the new path with rig.core.sentCommits.
+		readyCommitment := func(commit order.Commitment) chan struct{} {
+			commitSig := make(chan struct{}) // close after fake order submission is "done"
+			rig.core.sentCommitsMtx.Lock()
+			rig.core.sentCommits[commit] = commitSig
+			rig.core.sentCommitsMtx.Unlock()
+			return commitSig
+		}
 
-		rig.dc.trades = make(map[order.OrderID]*trackedTrade)
-	}
+		commit := preImg.Commit()
+		secondCSum := dex.Bytes{2, 3, 5, 7, 11, 14}
+		commitSig := readyCommitment(commit)
+		payload := &msgjson.PreimageRequest{
+			OrderID:        oid[:],
+			Commitment:     commit[:],
+			CommitChecksum: secondCSum,
+		}
+		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
 
-	// Ensure the order is good, and reset the state.
-	runTest := func(tag string, expAddedToTradesMap, expReadyToTick, expBTCUnlocked, expETHUnlocked bool, expCoinsLoaded int) {
-		t.Helper()
-		defer reset()
+		// Prepare to have processPreimageRequest respond with a payload with
+		// the Error field set.
+		rig.ws.sendMsgErrChan = make(chan *msgjson.Error, 1)
+		defer func() { rig.ws.sendMsgErrChan = nil }()
 
-		description := fmt.Sprintf("%s: side = %s, order status = %s, match status = %s",
-			tag, match.Side, dbOrder.MetaData.Status, match.Status)
-		tCore.loginMtx.Lock()
-		tCore.loggedIn = false
-		tCore.loginMtx.Unlock()
-		err := tCore.Login(tPW)
+		rig.dc.trades[order.OrderID{}] = tracker
+		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
 		if err != nil {
-			t.Fatalf("%s: login error: %v", description, err)
+			t.Fatalf("handlePreimageRequest error: %v", err)
 		}
 
-		trade, found := rig.dc.trades[lo.ID()]
-		if expAddedToTradesMap != found {
-			t.Fatalf("%s: expected added to trades map = %v, but got %v. len(trades) = %d", description, expAddedToTradesMap, found, len(rig.dc.trades))
-		}
-		if !expAddedToTradesMap {
-			return
-		}
+		// It has gone async now, waiting for commitSig.
+		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
+		close(commitSig) // pretend like the order submission just finished
 
-		if expBTCUnlocked != btcWallet.unlocked() {
-			t.Fatalf("%s: btc wallet unlocked = %v but got %v", description, expBTCUnlocked, btcWallet.unlocked())
+		select {
+		case msgErr := <-rig.ws.sendMsgErrChan:
+			if msgErr.Code != msgjson.InvalidRequestError {
+				t.Fatalf("expected error code %d got %d", msgjson.InvalidRequestError, msgErr.Code)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no msgjson.Error sent from preimage request handling")
 		}
-
-		if expETHUnlocked != ethWallet.unlocked() {
-			t.Fatalf("%s: eth wallet unlocked = %v but got %v", description, expETHUnlocked, ethWallet.unlocked())
+		tracker.mtx.RLock()
+		if !bytes.Equal(firstCSum, tracker.cancel.csum) {
+			t.Fatalf(
+				"[handlePreimageRequest] cancel csum was changed, exp: %s, got: %s",
+				firstCSum,
+				tracker.cancel.csum,
+			)
 		}
+		tracker.mtx.RUnlock()
+	})
+	t.Run("more than one preimage request for cancel order (same csum)", func(t *testing.T) {
+		rig := newTestRig()
+		defer rig.shutdown()
+		ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
+		preImg := newPreimage()
+		mkt := rig.dc.marketConfig(tDcrBtcMktName)
+		csum := dex.Bytes{2, 3, 5, 7, 11, 13}
 
-		_, found = trade.matches[match.MatchID]
-		if !found {
-			t.Fatalf("%s: trade with expected order id not found. len(matches) = %d", description, len(trade.matches))
+		tracker := &trackedTrade{
+			Order:    ord,
+			preImg:   preImg,
+			mktID:    tDcrBtcMktName,
+			db:       rig.db,
+			dc:       rig.dc,
+			metaData: &db.OrderMetaData{},
+			cancel: &trackedCancel{
+				// Simulate first preimage request by initializing csum here.
+				csum: csum,
+				CancelOrder: order.CancelOrder{
+					P: order.Prefix{
+						AccountID:  rig.dc.acct.ID(),
+						BaseAsset:  tUTXOAssetA.ID,
+						QuoteAsset: tUTXOAssetB.ID,
+						OrderType:  order.MarketOrderType,
+						ClientTime: time.Now(),
+						ServerTime: time.Now().Add(time.Millisecond),
+						Commit:     preImg.Commit(),
+					},
+				},
+				epochLen: mkt.EpochLen,
+			},
 		}
+		oid := tracker.cancel.ID()
 
-		if len(trade.coins) != expCoinsLoaded {
-			t.Fatalf("%s: expected %d coin loaded, got %d", description, expCoinsLoaded, len(trade.coins))
+		// Test the new path with rig.core.sentCommits.
+		readyCommitment := func(commit order.Commitment) chan struct{} {
+			commitSig := make(chan struct{}) // close after fake order submission is "done"
+			rig.core.sentCommitsMtx.Lock()
+			rig.core.sentCommits[commit] = commitSig
+			rig.core.sentCommitsMtx.Unlock()
+			return commitSig
 		}
 
-		if found && expReadyToTick != trade.readyToTick {
-			t.Fatalf("%s: expected ready to tick = %v, but got %v", description, expReadyToTick, trade.readyToTick)
-		}
-		if !expReadyToTick {
-			return
+		commit := preImg.Commit()
+		commitSig := readyCommitment(commit)
+		payload := &msgjson.PreimageRequest{
+			OrderID:        oid[:],
+			Commitment:     commit[:],
+			CommitChecksum: csum,
 		}
+		reqCommit, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
 
-		if lo.T.Sell && ((match.Side == order.Taker && match.Status < order.MatchComplete) ||
-			(match.Side == order.Taker && match.Status < order.MakerRedeemed)) {
-			var reReserveQty uint64 = redemptionReserves
-			if dbOrder.MetaData.Status > order.OrderStatusBooked {
-				reReserveQty = applyFraction(match.Quantity, qty, redemptionReserves)
-			}
+		notes := rig.core.NotificationFeed()
 
-			if tEthWallet.reservedRedemption != reReserveQty {
-				t.Fatalf("%s: redemption funds not reserved, %d != %d", description, tEthWallet.reservedRedemption, reReserveQty)
-			}
+		rig.dc.trades[order.OrderID{}] = tracker
+		err := handlePreimageRequest(rig.core, rig.dc, reqCommit)
+		if err != nil {
+			t.Fatalf("handlePreimageRequest error: %v", err)
 		}
 
-		if !lo.T.Sell && match.Status < order.MakerRedeemed {
-			var reRefundQty uint64 = refundReserves
-			if dbOrder.MetaData.Status > order.OrderStatusBooked {
-				reRefundQty = applyFraction(match.Quantity, qty, refundReserves)
-			}
+		// It has gone async now, waiting for commitSig.
+		// i.e. "Received preimage request for %v with no corresponding order submission response! Waiting..."
+		close(commitSig) // pretend like the order submission just finished
 
-			if tEthWallet.reservedRefund != reRefundQty {
-				t.Fatalf("%s: refund funds not reserved, %d != %d", description, tEthWallet.reservedRefund, reRefundQty)
+		select {
+		case note := <-notes.C:
+			if note.Topic() != TopicCancelPreimageSent {
+				t.Fatalf("note subject is %v, not %v", note.Topic(), TopicCancelPreimageSent)
 			}
+		case <-time.After(time.Second):
+			t.Fatal("no order note from preimage request handling")
 		}
 
-	}
-
-	runTest("initial", true, true, true, true, 1)
-
-	// No base wallet. Trade will not be in the map.
-	delete(tCore.wallets, utxoAsset.ID)
-	runTest("no base wallet", false, false, false, false, 0)
-	tCore.wallets[utxoAsset.ID] = btcWallet
-
-	// Base wallet unlock errors. Trade will be in map, but it will not be
-	// ready to tick.
-	tBtcWallet.unlockErr = tErr
-	tBtcWallet.locked = true
-	runTest("base unlock", true, false, false, false, 0)
-	tBtcWallet.unlockErr = nil
-	tBtcWallet.locked = false
-
-	// No quote wallet. Trade will not be in the map.
-	delete(tCore.wallets, acctAsset.ID)
-	runTest("missing quote", false, false, false, false, 0)
-	tCore.wallets[acctAsset.ID] = ethWallet
-
-	// Quote wallet unlock errors. Trade will be in map, but it will not be
-	// ready to tick.
-	tEthWallet.unlockErr = tErr
-	tEthWallet.locked = true
-	runTest("quote unlock", true, false, true, false, 0)
-	tEthWallet.unlockErr = nil
-	tEthWallet.locked = false
-
-	// Funding coin error still puts it in the trades map, and sets ready to tick,
-	// just with no coins locked.
-	tBtcWallet.fundingCoinErr = tErr
-	runTest("funding coin", true, true, true, true, 0)
-	tBtcWallet.fundingCoinErr = nil
-
-	// No matches
-	rig.db.activeMatchOIDSErr = tErr
-	runTest("matches error", false, false, false, false, 0)
-	rig.db.activeMatchOIDSErr = nil
-
-	for _, tt := range reservationTests {
-		lo.T.Sell = tt.sell
-		if tt.sell {
-			dbOrder.MetaData.RefundReserves = 0
-			dbOrder.MetaData.RedemptionReserves = redemptionReserves
-		} else {
-			dbOrder.MetaData.RefundReserves = refundReserves
-			dbOrder.MetaData.RedemptionReserves = 0
-		}
-		for _, side := range tt.side {
-			match.Side = side
-			for _, orderStatus := range tt.orderStatuses {
-				dbOrder.MetaData.Status = orderStatus
-				for _, matchStatus := range tt.matchStatuses {
-					match.Status = matchStatus
-					runTest(tt.name, true, true, true, true, tt.expectedCoins)
-				}
-			}
+		tracker.mtx.RLock()
+		if !bytes.Equal(csum, tracker.cancel.csum) {
+			t.Fatalf(
+				"[handlePreimageRequest] cancel csum was changed, exp: %s, got: %s",
+				csum,
+				tracker.cancel.csum,
+			)
 		}
-	}
+		tracker.mtx.RUnlock()
+	})
 }
 
-func TestReReserveFunding(t *testing.T) {
+func TestHandleRevokeOrderMsg(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
+	dc := rig.dc
 	tCore := rig.core
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
+	dcrWallet.Unlock(rig.crypter)
 
-	auth(rig.acct) // Short path through initializeDEXConnections
+	fundCoinDcrID := encode.RandomBytes(36)
+	fundCoinDcr := &tCoin{id: fundCoinDcrID}
 
-	utxoAsset /* base */, acctAsset /* quote */ := tUTXOAssetB, tACCTAsset
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
+	btcWallet.Unlock(rig.crypter)
 
-	btcWallet, tBtcWallet := newTWallet(utxoAsset.ID)
-	tCore.wallets[utxoAsset.ID] = btcWallet
+	// fundCoinBID := encode.RandomBytes(36)
+	// fundCoinB := &tCoin{id: fundCoinBID}
 
-	ethWallet, tEthWallet := newTAccountLocker(acctAsset.ID)
-	tCore.wallets[acctAsset.ID] = ethWallet
+	qty := 2 * dcrBtcLotSize
+	rate := dcrBtcRateStep * 10
+	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, rate) // sell DCR
+	lo.Coins = []order.CoinID{fundCoinDcrID}
+	dbOrder.MetaData.Status = order.OrderStatusBooked
+	oid := lo.ID()
 
-	// Create an order
-	qty, lo, dbOrder, match, changeCoin := generateMatch(rig, utxoAsset.ID, acctAsset.ID)
-	redemptionReserves, refundReserves := dbOrder.MetaData.RedemptionReserves, dbOrder.MetaData.RefundReserves
+	tDcrWallet.fundingCoins = asset.Coins{fundCoinDcr}
 
-	tBtcWallet.fundingCoins = asset.Coins{changeCoin}
-	tEthWallet.fundingCoins = asset.Coins{changeCoin}
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
+	}
 
-	oid := lo.ID()
+	// Not in dc.trades yet.
 
-	tracker := &trackedTrade{
-		Order:    lo,
-		dc:       rig.dc,
-		metaData: dbOrder.MetaData,
-		matches: map[order.MatchID]*matchTracker{
-			match.MatchID: {
-				MetaMatch: db.MetaMatch{
-					MetaData: &db.MatchMetaData{},
-					UserMatch: &order.UserMatch{
-						OrderID:  lo.ID(),
-						MatchID:  match.MatchID,
-						Status:   order.NewlyMatched,
-						Side:     order.Maker,
-						Quantity: match.Quantity,
-					},
-				},
-				prefix: lo.Prefix(),
-				trade:  lo.Trade(),
-				// counterConfirms: -1,
-			},
-		},
-		coins:              map[string]asset.Coin{"changecoinid": changeCoin},
-		redemptionReserves: redemptionReserves,
-		refundReserves:     refundReserves,
+	// Send a request for the unknown order.
+	payload := &msgjson.RevokeOrder{
+		OrderID: oid[:],
 	}
+	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.RevokeOrderRoute, payload)
 
-	rig.dc.trades = map[order.OrderID]*trackedTrade{
-		oid: tracker,
+	// Ensure revoking a non-existent order generates an error.
+	err = handleRevokeOrderMsg(rig.core, rig.dc, req)
+	if err == nil {
+		t.Fatal("[handleRevokeOrderMsg] expected a non-existent order")
 	}
 
-	// reset
-	reset := func() {
-		tEthWallet.reservedRedemption = 0
-		tEthWallet.reservedRefund = 0
-		tracker.redemptionLocked = 0
-		tracker.refundLocked = 0
+	// Now store the order in dc.trades, with a linked cancel order.
+	tracker := newTrackedTrade(dbOrder, preImg, dc,
+		rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, tDcrWallet.fundingCoins, rig.core.notify,
+		rig.core.formatDetails)
+	preImgC := newPreimage()
+	co := &order.CancelOrder{
+		P: order.Prefix{
+			ServerTime: time.Now(),
+			Commit:     preImgC.Commit(),
+		},
 	}
+	tracker.cancel = &trackedCancel{CancelOrder: *co}
+	coid := co.ID()
+	rig.dc.trades[oid] = tracker
 
-	run := func(tag string) {
-		t.Helper()
-		description := fmt.Sprintf("%s: side = %s, order status = %s, match status = %s",
-			tag, match.Side, dbOrder.MetaData.Status, match.Status)
-
-		tCore.reReserveFunding(btcWallet)
-		tCore.reReserveFunding(ethWallet)
-
-		if lo.T.Sell && ((match.Side == order.Taker && match.Status < order.MatchComplete) ||
-			(match.Side == order.Taker && match.Status < order.MakerRedeemed)) {
-			var reReserveQty uint64 = redemptionReserves
-			if dbOrder.MetaData.Status > order.OrderStatusBooked {
-				reReserveQty = applyFraction(match.Quantity, qty, redemptionReserves)
-			}
+	orderNotes, feedDone := orderNoteFeed(tCore)
+	defer feedDone()
 
-			if tEthWallet.reservedRedemption != reReserveQty {
-				t.Fatalf("%s: redemption funds not reserved, %d != %d", description, tEthWallet.reservedRedemption, reReserveQty)
-			}
-		}
+	// Revoke the cancel order, not the targeted order.
+	payloadC := &msgjson.RevokeOrder{
+		OrderID: coid[:],
+	}
+	reqC, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.RevokeOrderRoute, payloadC)
+	err = handleRevokeOrderMsg(rig.core, rig.dc, reqC)
+	if err != nil {
+		t.Fatalf("handleRevokeOrderMsg error: %v", err)
+	}
 
-		if !lo.T.Sell && match.Status < order.MakerRedeemed {
-			var reRefundQty uint64 = refundReserves
-			if dbOrder.MetaData.Status > order.OrderStatusBooked {
-				reRefundQty = applyFraction(match.Quantity, qty, refundReserves)
-			}
+	verifyRevokeNotification(orderNotes, TopicFailedCancel, t)
 
-			if tEthWallet.reservedRefund != reRefundQty {
-				t.Fatalf("%s: refund funds not reserved, %d != %d", description, tEthWallet.reservedRefund, reRefundQty)
-			}
-		}
+	if tracker.metaData.Status == order.OrderStatusRevoked {
+		t.Errorf("Incorrectly revoked the targeted order instead of clearing the cancel order!")
+	}
+	if tracker.cancel != nil {
+		t.Fatalf("Did not clear the cancel order")
+	}
 
-		reset()
+	// Now revoke the actual trade order.
+	err = handleRevokeOrderMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("handleRevokeOrderMsg error: %v", err)
 	}
 
-	for _, tt := range reservationTests {
+	verifyRevokeNotification(orderNotes, TopicOrderRevoked, t)
 
-		lo.T.Sell = tt.sell
-		tracker.wallets, _, _, _ = tCore.walletSet(rig.dc, utxoAsset.ID, acctAsset.ID, tt.sell)
-		if tt.sell {
-			dbOrder.MetaData.RefundReserves = 0
-			dbOrder.MetaData.RedemptionReserves = redemptionReserves
-		} else {
-			dbOrder.MetaData.RefundReserves = refundReserves
-			dbOrder.MetaData.RedemptionReserves = 0
-		}
-		for _, side := range tt.side {
-			match.Side = side
-			for _, orderStatus := range tt.orderStatuses {
-				dbOrder.MetaData.Status = orderStatus
-				for _, matchStatus := range tt.matchStatuses {
-					match.Status = matchStatus
-					run(tt.name)
-				}
-			}
-		}
+	if tracker.metaData.Status != order.OrderStatusRevoked {
+		t.Errorf("expected order status %v, got %v", order.OrderStatusRevoked, tracker.metaData.Status)
 	}
-
 }
 
-func TestCompareServerMatches(t *testing.T) {
+func TestHandleRevokeMatchMsg(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	preImg := newPreimage()
 	dc := rig.dc
+	tCore := rig.core
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
+	dcrWallet.Unlock(rig.crypter)
 
-	notes := make(map[string][]Notification)
-	notify := func(note Notification) {
-		notes[note.Type()] = append(notes[note.Type()], note)
-	}
+	fundCoinDcrID := encode.RandomBytes(36)
+	fundCoinDcr := &tCoin{id: fundCoinDcrID}
 
-	lo := &order.LimitOrder{
-		P: order.Prefix{
-			// 	OrderType:  order.LimitOrderType,
-			// 	BaseAsset:  tUTXOAssetA.ID,
-			// 	QuoteAsset: tUTXOAssetB.ID,
-			// 	ClientTime: time.Now(),
-			ServerTime: time.Now(),
-			// 	Commit:     preImg.Commit(),
-		},
-	}
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
+	btcWallet.Unlock(rig.crypter)
+
+	// fundCoinBID := encode.RandomBytes(36)
+	// fundCoinB := &tCoin{id: fundCoinBID}
+
+	matchSize := 4 * dcrBtcLotSize
+	cancelledQty := dcrBtcLotSize
+	qty := 2*matchSize + cancelledQty
+	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, dcrBtcRateStep)
+	lo.Coins = []order.CoinID{fundCoinDcrID}
+	dbOrder.MetaData.Status = order.OrderStatusBooked
 	oid := lo.ID()
-	dbOrder := &db.MetaOrder{
-		MetaData: &db.OrderMetaData{},
-		Order:    lo,
-	}
-	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, nil, nil, rig.core.notify, rig.core.formatDetails)
 
-	// Known trade, and known match
-	knownID := ordertest.RandomMatchID()
-	knownMatch := &matchTracker{
-		MetaMatch: db.MetaMatch{
-			UserMatch: &order.UserMatch{MatchID: knownID},
-			MetaData:  &db.MatchMetaData{},
-		},
-		counterConfirms: -1,
-	}
-	tracker.matches[knownID] = knownMatch
-	knownMsgMatch := &msgjson.Match{OrderID: oid[:], MatchID: knownID[:]}
+	tDcrWallet.fundingCoins = asset.Coins{fundCoinDcr}
 
-	// Known trade, but missing match
-	missingID := ordertest.RandomMatchID()
-	missingMatch := &matchTracker{
-		MetaMatch: db.MetaMatch{
-			UserMatch: &order.UserMatch{MatchID: missingID},
-			MetaData:  &db.MatchMetaData{},
-		},
+	mid := ordertest.RandomMatchID()
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
 	}
-	tracker.matches[missingID] = missingMatch
 
-	// extra match
-	extraID := ordertest.RandomMatchID()
-	extraMsgMatch := &msgjson.Match{OrderID: oid[:], MatchID: extraID[:]}
+	tracker := newTrackedTrade(dbOrder, preImg, dc,
+		rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, tDcrWallet.fundingCoins, rig.core.notify,
+		rig.core.formatDetails)
 
-	// Entirely missing order
-	loMissing, dbOrderMissing, preImgMissing, _ := makeLimitOrder(dc, true, 3*dcrBtcLotSize, dcrBtcRateStep*10)
-	trackerMissing := newTrackedTrade(dbOrderMissing, preImgMissing, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, nil, nil, notify, rig.core.formatDetails)
-	oidMissing := loMissing.ID()
-	// an active match for the missing trade
-	matchIDMissing := ordertest.RandomMatchID()
-	missingTradeMatch := &matchTracker{
+	match := &matchTracker{
 		MetaMatch: db.MetaMatch{
-			UserMatch: &order.UserMatch{MatchID: matchIDMissing},
+			UserMatch: &order.UserMatch{MatchID: mid},
 			MetaData:  &db.MatchMetaData{},
 		},
-		counterConfirms: -1,
-	}
-	trackerMissing.matches[knownID] = missingTradeMatch
-	// an inactive match for the missing trade
-	matchIDMissingInactive := ordertest.RandomMatchID()
-	missingTradeMatchInactive := &matchTracker{
-		MetaMatch: db.MetaMatch{
-			UserMatch: &order.UserMatch{
-				MatchID: matchIDMissingInactive,
-				Status:  order.MatchComplete,
-			},
-			MetaData: &db.MatchMetaData{
-				Proof: db.MatchProof{
-					Auth: db.MatchAuth{
-						RedeemSig: []byte{1, 2, 3}, // won't be considered complete with out it
-					},
-				},
-			},
-		},
-		counterConfirms: 1,
 	}
-	trackerMissing.matches[matchIDMissingInactive] = missingTradeMatchInactive
+	tracker.matches[mid] = match
 
-	srvMatches := map[order.OrderID]*serverMatches{
-		oid: {
-			tracker:    tracker,
-			msgMatches: []*msgjson.Match{knownMsgMatch, extraMsgMatch},
-		},
-		// oidMissing not included (missing!)
+	payload := &msgjson.RevokeMatch{
+		OrderID: oid[:],
+		MatchID: mid[:],
 	}
+	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.RevokeMatchRoute, payload)
 
-	dc.trades = map[order.OrderID]*trackedTrade{
-		oid:        tracker,
-		oidMissing: trackerMissing,
+	// Ensure revoking a non-existent order generates an error.
+	err = handleRevokeMatchMsg(rig.core, rig.dc, req)
+	if err == nil {
+		t.Fatal("[handleRevokeMatchMsg] expected a non-existent order")
 	}
 
-	exceptions, _ := dc.compareServerMatches(srvMatches)
-	if len(exceptions) != 2 {
-		t.Fatalf("exceptions did not include both trades, just %d", len(exceptions))
-	}
+	rig.dc.trades[oid] = tracker
 
-	exc, ok := exceptions[oid]
-	if !ok {
-		t.Fatalf("exceptions did not include trade %v", oid)
-	}
-	if exc.trade.ID() != oid {
-		t.Fatalf("wrong trade ID, got %v, want %v", exc.trade.ID(), oid)
-	}
-	if len(exc.missing) != 1 {
-		t.Fatalf("found %d missing matches for trade %v, expected 1", len(exc.missing), oid)
-	}
-	if exc.missing[0].MatchID != missingID {
-		t.Fatalf("wrong missing match, got %v, expected %v", exc.missing[0].MatchID, missingID)
-	}
-	if len(exc.extra) != 1 {
-		t.Fatalf("found %d extra matches for trade %v, expected 1", len(exc.extra), oid)
-	}
-	if !bytes.Equal(exc.extra[0].MatchID, extraID[:]) {
-		t.Fatalf("wrong extra match, got %v, expected %v", exc.extra[0].MatchID, extraID)
+	// Success
+	err = handleRevokeMatchMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("handleRevokeMatchMsg error: %v", err)
 	}
+}
 
-	exc, ok = exceptions[oidMissing]
-	if !ok {
-		t.Fatalf("exceptions did not include trade %v", oidMissing)
-	}
-	if exc.trade.ID() != oidMissing {
-		t.Fatalf("wrong trade ID, got %v, want %v", exc.trade.ID(), oidMissing)
-	}
-	if len(exc.missing) != 1 { // no matchIDMissingInactive
-		t.Fatalf("found %d missing matches for trade %v, expected 1", len(exc.missing), oid)
-	}
-	if exc.missing[0].MatchID != matchIDMissing {
-		t.Fatalf("wrong missing match, got %v, expected %v", exc.missing[0].MatchID, matchIDMissing)
-	}
-	if len(exc.extra) != 0 {
-		t.Fatalf("found %d extra matches for trade %v, expected 0", len(exc.extra), oid)
-	}
-}
+func TestTradeTracking(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
+	tCore.loggedIn = true
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
+	dcrWallet.Unlock(rig.crypter)
 
-func convertMsgLimitOrder(msgOrder *msgjson.LimitOrder) *order.LimitOrder {
-	tif := order.ImmediateTiF
-	if msgOrder.TiF == msgjson.StandingOrderNum {
-		tif = order.StandingTiF
-	}
-	return &order.LimitOrder{
-		P:     convertMsgPrefix(&msgOrder.Prefix, order.LimitOrderType),
-		T:     convertMsgTrade(&msgOrder.Trade),
-		Rate:  msgOrder.Rate,
-		Force: tif,
-	}
-}
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.address = "12DXGkvxFjuq5btXYkwWfBZaz1rVwFgini"
+	btcWallet.Unlock(rig.crypter)
 
-func convertMsgMarketOrder(msgOrder *msgjson.MarketOrder) *order.MarketOrder {
-	return &order.MarketOrder{
-		P: convertMsgPrefix(&msgOrder.Prefix, order.MarketOrderType),
-		T: convertMsgTrade(&msgOrder.Trade),
-	}
-}
+	tBtcWallet.confirmRedemptionErr = errors.New("")
+	tDcrWallet.confirmRedemptionErr = errors.New("")
 
-func convertMsgCancelOrder(msgOrder *msgjson.CancelOrder) *order.CancelOrder {
-	var oid order.OrderID
-	copy(oid[:], msgOrder.TargetID)
-	return &order.CancelOrder{
-		P:             convertMsgPrefix(&msgOrder.Prefix, order.CancelOrderType),
-		TargetOrderID: oid,
-	}
-}
+	matchSize := 4 * dcrBtcLotSize
+	cancelledQty := dcrBtcLotSize
+	qty := 2*matchSize + cancelledQty
+	rate := dcrBtcRateStep * 10
+	lo, dbOrder, preImgL, addr := makeLimitOrder(dc, true, qty, dcrBtcRateStep)
+	lo.Force = order.StandingTiF
+	// fundCoinDcrID := encode.RandomBytes(36)
+	// lo.Coins = []order.CoinID{fundCoinDcrID}
+	loid := lo.ID()
 
-func convertMsgPrefix(msgPrefix *msgjson.Prefix, oType order.OrderType) order.Prefix {
-	var commit order.Commitment
-	copy(commit[:], msgPrefix.Commit)
-	var acctID account.AccountID
-	copy(acctID[:], msgPrefix.AccountID)
-	return order.Prefix{
-		AccountID:  acctID,
-		BaseAsset:  msgPrefix.Base,
-		QuoteAsset: msgPrefix.Quote,
-		OrderType:  oType,
-		ClientTime: time.UnixMilli(int64(msgPrefix.ClientTime)),
-		//ServerTime set in epoch queue processing pipeline.
-		Commit: commit,
-	}
-}
+	//fundCoinDcr := &tCoin{id: fundCoinDcrID}
+	//tDcrWallet.fundingCoins = asset.Coins{fundCoinDcr}
 
-func convertMsgTrade(msgTrade *msgjson.Trade) order.Trade {
-	coins := make([]order.CoinID, 0, len(msgTrade.Coins))
-	for _, coin := range msgTrade.Coins {
-		var b []byte = coin.ID
-		coins = append(coins, b)
-	}
-	sell := true
-	if msgTrade.Side == msgjson.BuyOrderNum {
-		sell = false
+	mid := ordertest.RandomMatchID()
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
 	}
-	return order.Trade{
-		Coins:    coins,
-		Sell:     sell,
-		Quantity: msgTrade.Quantity,
-		Address:  msgTrade.Address,
+	mkt := dc.marketConfig(tDcrBtcMktName)
+	fundCoinDcrID := encode.RandomBytes(36)
+	fundingCoins := asset.Coins{&tCoin{id: fundCoinDcrID}}
+	tracker := newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, fundingCoins, rig.core.notify, rig.core.formatDetails)
+	rig.dc.trades[tracker.ID()] = tracker
+	var match *matchTracker
+	checkStatus := func(tag string, wantStatus order.MatchStatus) {
+		t.Helper()
+		if match.Status != wantStatus {
+			t.Fatalf("%s: wrong status wanted %v, got %v", tag,
+				wantStatus, match.Status)
+		}
 	}
-}
 
-func orderResponse(msgID uint64, msgPrefix msgjson.Stampable, ord order.Order, badSig, noID, badID bool) *msgjson.Message {
-	orderTime := time.Now()
-	timeStamp := uint64(orderTime.UnixMilli())
-	msgPrefix.Stamp(timeStamp)
-	sign(tDexPriv, msgPrefix)
-	if badSig {
-		msgPrefix.SetSig(encode.RandomBytes(5))
-	}
-	ord.SetTime(orderTime)
-	oid := ord.ID()
-	oidB := oid[:]
-	if noID {
-		oidB = nil
-	} else if badID {
-		oidB = encode.RandomBytes(32)
+	// create new notification feed to catch swap-related errors from goroutines
+	notes := tCore.NotificationFeed()
+	drainNotes := func() {
+		for {
+			select {
+			case <-notes.C:
+			default:
+				return
+			}
+		}
 	}
-	resp, _ := msgjson.NewResponse(msgID, &msgjson.OrderResult{
-		Sig:        msgPrefix.SigBytes(),
-		OrderID:    oidB,
-		ServerTime: timeStamp,
-	}, nil)
-	return resp
-}
 
-func tMsgAudit(oid order.OrderID, mid order.MatchID, recipient string, val uint64, secretHash []byte) (*msgjson.Audit, *asset.AuditInfo) {
-	auditID := encode.RandomBytes(36)
-	auditContract := encode.RandomBytes(75)
-	if secretHash == nil {
-		secretHash = encode.RandomBytes(32)
+	lastSwapErrorNote := func() Notification {
+		for {
+			select {
+			case note := <-notes.C:
+				if note.Severity() == db.ErrorLevel && (note.Topic() == TopicSwapSendError ||
+					note.Topic() == TopicInitError || note.Topic() == TopicReportRedeemError) {
+
+					return note
+				}
+			default:
+				return nil
+			}
+		}
 	}
-	auditStamp := uint64(time.Now().UnixMilli())
-	audit := &msgjson.Audit{
-		OrderID:  oid[:],
-		MatchID:  mid[:],
-		Time:     auditStamp,
-		CoinID:   auditID,
-		Contract: auditContract,
+
+	type swapRelatedAction struct {
+		name                 string
+		fn                   func() error
+		expectError          bool
+		expectMatchDBUpdates int
+		expectSwapErrorNote  bool
 	}
-	sign(tDexPriv, audit)
-	auditCoin := &tCoin{id: auditID, val: val}
-	auditInfo := &asset.AuditInfo{
-		Recipient:  recipient,
-		Coin:       auditCoin,
-		Contract:   auditContract,
-		SecretHash: secretHash,
+	testSwapRelatedAction := func(action swapRelatedAction) {
+		t.Helper()
+		drainNotes() // clear previous (swap error) notes before exec'ing swap-related action
+		if action.expectMatchDBUpdates > 0 {
+			rig.db.updateMatchChan = make(chan order.MatchStatus, action.expectMatchDBUpdates)
+		}
+		// Try the action and confirm the behaviour is as expected.
+		err := action.fn()
+		if action.expectError && err == nil {
+			t.Fatalf("%s: expected error but got nil", action.name)
+		} else if !action.expectError && err != nil {
+			t.Fatalf("%s: unexpected error: %v", action.name, err)
+		}
+		// Check that we received the expected number of match db updates.
+		for i := 0; i < action.expectMatchDBUpdates; i++ {
+			<-rig.db.updateMatchChan
+		}
+		rig.db.updateMatchChan = nil
+		// Check that we received a swap error note (if expected), and that
+		// no error note was received, if not expected.
+		time.Sleep(100 * time.Millisecond) // wait briefly as swap error notes may be sent from a goroutine
+		swapErrNote := lastSwapErrorNote()
+		if action.expectSwapErrorNote && swapErrNote == nil {
+			t.Fatalf("%s: expected swap error note but got nil", action.name)
+		} else if !action.expectSwapErrorNote && swapErrNote != nil {
+			t.Fatalf("%s: unexpected swap error note: %s", action.name, swapErrNote.Details())
+		}
 	}
-	return audit, auditInfo
-}
 
-func TestHandleEpochOrderMsg(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
-	oid := ord.ID()
-	payload := &msgjson.EpochOrderNote{
-		BookOrderNote: msgjson.BookOrderNote{
-			OrderNote: msgjson.OrderNote{
-				MarketID: tDcrBtcMktName,
-				OrderID:  oid.Bytes(),
-			},
-			TradeNote: msgjson.TradeNote{
-				Side:     msgjson.BuyOrderNum,
-				Rate:     4,
-				Quantity: 10,
-			},
-		},
-		Epoch: 1,
+	// MAKER MATCH
+	matchTime := time.Now()
+	msgMatch := &msgjson.Match{
+		OrderID:    loid[:],
+		MatchID:    mid[:],
+		Quantity:   matchSize,
+		Rate:       rate,
+		Address:    "counterparty-address",
+		Side:       uint8(order.Maker),
+		ServerTime: uint64(matchTime.UnixMilli()),
 	}
+	counterSwapID := encode.RandomBytes(36)
+	tDcrWallet.swapReceipts = []asset.Receipt{&tReceipt{coin: &tCoin{id: counterSwapID}}}
+	sign(tDexPriv, msgMatch)
 
-	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.EpochOrderRoute, payload)
-
-	// Ensure handling an epoch order associated with a non-existent orderbook
-	// generates an error.
-	err := handleEpochOrderMsg(rig.core, rig.dc, req)
-	if err == nil {
-		t.Fatal("[handleEpochOrderMsg] expected a non-existent orderbook error")
+	// Make sure that a fee rate higher than our recorded MaxFeeRate results in
+	// an error.
+	msgMatch.FeeRateBase = tMaxFeeRate + 1
+	sign(tDexPriv, msgMatch)
+	msg, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
+	err = handleMatchRoute(tCore, rig.dc, msg)
+	if err == nil || !strings.Contains(err.Error(), "is > MaxFeeRate") {
+		t.Fatalf("no error for fee rate > MaxFeeRate %t", lo.Trade().Sell)
 	}
 
-	rig.dc.books[tDcrBtcMktName] = newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	// Restore fee rate.
+	msgMatch.FeeRateBase = tMaxFeeRate
+	sign(tDexPriv, msgMatch)
+	msg, _ = msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
 
-	err = handleEpochOrderMsg(rig.core, rig.dc, req)
-	if err != nil {
-		t.Fatalf("[handleEpochOrderMsg] unexpected error: %v", err)
-	}
-}
+	// Handle new match as maker with a queued invalid DEX init ack.
+	// handleMatchRoute should have no errors but trigger a match db update (status = NewlyMatched).
+	// Maker's swap should be bcasted, triggering another match db update (NewlyMatched->MakerSwapCast).
+	// sendInitAsync should fail because of invalid ack and produce a swap error note.
+	testSwapRelatedAction(swapRelatedAction{
+		name: "handleMatchRoute",
+		fn: func() error {
+			// queue an invalid DEX init ack
+			rig.ws.queueResponse(msgjson.InitRoute, invalidAcker)
+			return handleMatchRoute(tCore, rig.dc, msg)
+		},
+		expectError:          false,
+		expectMatchDBUpdates: 2,
+		expectSwapErrorNote:  true,
+	})
 
-func makeMatchProof(preimages []order.Preimage, commitments []order.Commitment) (msgjson.Bytes, msgjson.Bytes, error) {
-	if len(preimages) != len(commitments) {
-		return nil, nil, fmt.Errorf("expected equal number of preimages and commitments")
+	var found bool
+	match, found = tracker.matches[mid]
+	if !found {
+		t.Fatalf("match not found")
 	}
 
-	sbuff := make([]byte, 0, len(preimages)*order.PreimageSize)
-	cbuff := make([]byte, 0, len(commitments)*order.CommitmentSize)
-	for i := 0; i < len(preimages); i++ {
-		sbuff = append(sbuff, preimages[i][:]...)
-		cbuff = append(cbuff, commitments[i][:]...)
+	// We're the maker, so the init transaction should be broadcast.
+	checkStatus("maker swapped", order.MakerSwapCast)
+	proof, auth := &match.MetaData.Proof, &match.MetaData.Proof.Auth
+	if len(auth.MatchSig) == 0 {
+		t.Fatalf("no match sig recorded")
 	}
-	seed := blake256.Sum256(sbuff)
-	csum := blake256.Sum256(cbuff)
-	return seed[:], csum[:], nil
-}
-
-func TestHandleMatchProofMsg(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	pimg := newPreimage()
-	cmt := pimg.Commit()
-
-	seed, csum, err := makeMatchProof([]order.Preimage{pimg}, []order.Commitment{cmt})
-	if err != nil {
-		t.Fatalf("[makeMatchProof] unexpected error: %v", err)
+	if !bytes.Equal(proof.MakerSwap, counterSwapID) {
+		t.Fatalf("receipt ID not recorded")
+	}
+	if len(proof.Secret) == 0 {
+		t.Fatalf("secret not set")
+	}
+	if len(proof.SecretHash) == 0 {
+		t.Fatalf("secret hash not set")
+	}
+	// auth.InitSig should be unset because our init request received
+	// an invalid ack
+	if len(auth.InitSig) != 0 {
+		t.Fatalf("init sig recorded for invalid init ack")
 	}
 
-	payload := &msgjson.MatchProofNote{
-		MarketID:  tDcrBtcMktName,
-		Epoch:     1,
-		Preimages: []dex.Bytes{pimg[:]},
-		CSum:      csum[:],
-		Seed:      seed[:],
+	// requeue an invalid DEX init ack and resend pending init request
+	testSwapRelatedAction(swapRelatedAction{
+		name: "resend pending init (invalid ack)",
+		fn: func() error {
+			rig.ws.queueResponse(msgjson.InitRoute, invalidAcker)
+			tCore.resendPendingRequests(tracker)
+			return nil
+		},
+		expectError:          false,
+		expectMatchDBUpdates: 0,    // no db update for invalid init ack
+		expectSwapErrorNote:  true, // expect swap error note for invalid init ack
+	})
+	// auth.InitSig should remain unset because our resent init request
+	// received an invalid ack still
+	if len(auth.InitSig) != 0 {
+		t.Fatalf("init sig recorded for second invalid init ack")
 	}
 
-	eo := &msgjson.EpochOrderNote{
-		BookOrderNote: msgjson.BookOrderNote{
-			OrderNote: msgjson.OrderNote{
-				MarketID: tDcrBtcMktName,
-				OrderID:  encode.RandomBytes(order.OrderIDSize),
-			},
+	// queue a valid DEX init ack and re-send pending init request
+	// a valid ack should produce a db update otherwise it's an error
+	testSwapRelatedAction(swapRelatedAction{
+		name: "resend pending init (valid ack)",
+		fn: func() error {
+			rig.ws.queueResponse(msgjson.InitRoute, initAcker)
+			tCore.resendPendingRequests(tracker)
+			return nil
 		},
-		Epoch:  1,
-		Commit: cmt[:],
+		expectError:          false,
+		expectMatchDBUpdates: 1,     // expect db update for valid init ack
+		expectSwapErrorNote:  false, // no swap error note for valid init ack
+	})
+	// auth.InitSig should now be set because our init request received
+	// a valid ack
+	if len(auth.InitSig) == 0 {
+		t.Fatalf("init sig not recorded for valid init ack")
 	}
 
-	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.MatchProofRoute, payload)
+	// Send the counter-party's init info.
+	auditQty := calc.BaseToQuote(rate, matchSize)
+	audit, auditInfo := tMsgAudit(loid, mid, addr, auditQty, proof.SecretHash)
+	auditInfo.Expiration = encode.DropMilliseconds(matchTime.Add(tracker.lockTimeTaker))
+	tBtcWallet.auditInfo = auditInfo
+	msg, _ = msgjson.NewRequest(1, msgjson.AuditRoute, audit)
 
-	// Ensure match proof validation generates an error for a non-existent orderbook.
-	err = handleMatchProofMsg(rig.core, rig.dc, req)
+	// Check audit errors.
+	tBtcWallet.auditErr = tErr
+	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
 	if err == nil {
-		t.Fatal("[handleMatchProofMsg] expected a non-existent orderbook error")
+		t.Fatalf("no maker error for AuditContract error")
 	}
 
-	rig.dc.books[tDcrBtcMktName] = newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
-
-	err = rig.dc.books[tDcrBtcMktName].Enqueue(eo)
-	if err != nil {
-		t.Fatalf("[Enqueue] unexpected error: %v", err)
+	// Check expiration error.
+	match.MetaData.Proof.SelfRevoked = true // keeps trying unless revoked
+	tBtcWallet.auditErr = asset.CoinNotFoundError
+	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
+	if err == nil {
+		t.Fatalf("no maker error for AuditContract expiration")
 	}
-
-	err = handleMatchProofMsg(rig.core, rig.dc, req)
-	if err != nil {
-		t.Fatalf("[handleMatchProofMsg] unexpected error: %v", err)
+	var expErr ExpirationErr
+	if !errors.As(err, &expErr) {
+		t.Fatalf("wrong error type. expecting ExpirationTimeout, got %T: %v", err, err)
 	}
-}
+	tBtcWallet.auditErr = nil
+	match.MetaData.Proof.SelfRevoked = false
 
-func Test_marketTrades(t *testing.T) {
-	mktID := "dcr_btc"
-	dc := &dexConnection{
-		trades: make(map[order.OrderID]*trackedTrade),
+	auditInfo.Coin.(*tCoin).val = auditQty - 1
+	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
+	if err == nil {
+		t.Fatalf("no maker error for low value")
 	}
+	auditInfo.Coin.(*tCoin).val = auditQty
 
-	preImg := newPreimage()
-	activeOrd := &order.LimitOrder{P: order.Prefix{
-		ServerTime: time.Now(),
-		Commit:     preImg.Commit(),
-	}}
-	activeTracker := &trackedTrade{
-		Order:  activeOrd,
-		preImg: preImg,
-		mktID:  mktID,
-		dc:     dc,
-		metaData: &db.OrderMetaData{
-			Status: order.OrderStatusBooked,
-		},
-		matches: make(map[order.MatchID]*matchTracker),
+	auditInfo.SecretHash = []byte{0x01}
+	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
+	if err == nil {
+		t.Fatalf("no maker error for wrong secret hash")
 	}
+	auditInfo.SecretHash = proof.SecretHash
 
-	dc.trades[activeTracker.ID()] = activeTracker
-
-	preImg = newPreimage() // different oid
-	inactiveOrd := &order.LimitOrder{P: order.Prefix{
-		ServerTime: time.Now(),
-		Commit:     preImg.Commit(),
-	}}
-	inactiveTracker := &trackedTrade{
-		Order:  inactiveOrd,
-		preImg: preImg,
-		mktID:  mktID,
-		dc:     dc,
-		metaData: &db.OrderMetaData{
-			Status: order.OrderStatusExecuted,
-		},
-		matches: make(map[order.MatchID]*matchTracker), // no matches
+	auditInfo.Recipient = "wrong address"
+	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
+	if err == nil {
+		t.Fatalf("no maker error for wrong address")
 	}
+	auditInfo.Recipient = addr
 
-	dc.trades[inactiveTracker.ID()] = inactiveTracker
+	auditInfo.Expiration = matchTime.Add(tracker.lockTimeTaker - time.Hour)
+	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
+	if err == nil {
+		t.Fatalf("no maker error for early lock time")
+	}
+	auditInfo.Expiration = matchTime.Add(tracker.lockTimeTaker)
 
-	trades, _ := dc.marketTrades(mktID)
-	if len(trades) != 1 {
-		t.Fatalf("Expected only one trade from marketTrades, found %v", len(trades))
+	// success, full handleAuditRoute>processAuditMsg>auditContract
+	rig.db.updateMatchChan = make(chan order.MatchStatus, 1)
+	err = handleAuditRoute(tCore, rig.dc, msg)
+	if err != nil {
+		t.Fatalf("audit error: %v", err)
 	}
-	if trades[0].ID() != activeOrd.ID() {
-		t.Errorf("Expected active order ID %v, got %v", activeOrd.ID(), trades[0].ID())
+	// let the async auditContract run
+	newMatchStatus := <-rig.db.updateMatchChan
+	if newMatchStatus != order.TakerSwapCast {
+		t.Fatalf("wrong match status. wanted %v, got %v", order.TakerSwapCast, newMatchStatus)
 	}
-}
-
-func TestLogout(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-
-	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-
-	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-
-	ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
-	tracker := &trackedTrade{
-		Order:  ord,
-		preImg: newPreimage(),
-		dc:     rig.dc,
-		metaData: &db.OrderMetaData{
-			Status: order.OrderStatusBooked,
-		},
-		matches: make(map[order.MatchID]*matchTracker),
+	if match.counterSwap == nil {
+		t.Fatalf("counter-swap not set")
 	}
-	rig.dc.trades[ord.ID()] = tracker
-
-	ensureErr := func(tag string) {
-		t.Helper()
-
-		tCore.loginMtx.Lock()
-		tCore.loggedIn = true
-		tCore.loginMtx.Unlock()
-
-		err := tCore.Logout()
-		if err == nil {
-			t.Fatalf("%s: no error", tag)
-		}
+	if !bytes.Equal(proof.CounterContract, audit.Contract) {
+		t.Fatalf("counter-script not recorded")
+	}
+	if !bytes.Equal(proof.TakerSwap, audit.CoinID) {
+		t.Fatalf("taker contract ID not set")
+	}
+	<-rig.db.updateMatchChan // AuditSig is set in a second match data update
+	if !bytes.Equal(auth.AuditSig, audit.Sig) {
+		t.Fatalf("audit sig not set")
+	}
+	if auth.AuditStamp != audit.Time {
+		t.Fatalf("audit time not set")
 	}
 
-	// Active orders error.
-	ensureErr("active orders")
-
-	tracker.metaData = &db.OrderMetaData{
-		Status: order.OrderStatusExecuted,
+	// Confirming the counter-swap triggers a redemption.
+	tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
+	redeemCoin := encode.RandomBytes(36)
+	//<-tBtcWallet.redeemErrChan
+	tBtcWallet.redeemCoins = []dex.Bytes{redeemCoin}
+	rig.ws.queueResponse(msgjson.RedeemRoute, redeemAcker)
+	tCore.tickAsset(dc, tUTXOAssetB.ID)
+	// TakerSwapCast -> MakerRedeemed after broadcast, before redeem request
+	newMatchStatus = <-rig.db.updateMatchChan
+	if newMatchStatus != order.MakerRedeemed {
+		t.Fatalf("wrong match status. wanted %v, got %v", order.MakerRedeemed, newMatchStatus)
 	}
-	mid := ordertest.RandomMatchID()
-	tracker.matches[mid] = &matchTracker{
-		MetaMatch: db.MetaMatch{
-			MetaData: &db.MatchMetaData{},
-			UserMatch: &order.UserMatch{
-				OrderID: ord.ID(),
-				MatchID: mid,
-				Status:  order.NewlyMatched,
-				Side:    order.Maker,
-			},
-		},
-		prefix:          ord.Prefix(),
-		trade:           ord.Trade(),
-		counterConfirms: -1,
+	// MakerRedeem -> MatchComplete after redeem request
+	newMatchStatus = <-rig.db.updateMatchChan
+	if newMatchStatus != order.MatchComplete {
+		t.Fatalf("wrong match status. wanted %v, got %v", order.MatchComplete, newMatchStatus)
 	}
-	// Active orders with matches error.
-	ensureErr("active orders matches")
-	rig.dc.trades = nil
-}
+	if !bytes.Equal(proof.MakerRedeem, redeemCoin) {
+		t.Fatalf("redeem coin ID not logged")
+	}
+	// No redemption request received as maker. Only taker gets a redemption
+	// request following maker's redeem.
 
-func TestSetEpoch(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	dc := rig.dc
-	dc.books[tDcrBtcMktName] = newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	// Check that fees were incremented appropriately.
+	if tracker.metaData.SwapFeesPaid != tSwapFeesPaid {
+		t.Fatalf("wrong fees recorded for swap. expected %d, got %d", tSwapFeesPaid, tracker.metaData.SwapFeesPaid)
+	}
+	// Check that fees were incremented appropriately.
+	if tracker.metaData.RedemptionFeesPaid != tRedemptionFeesPaid {
+		t.Fatalf("wrong fees recorded for redemption. expected %d, got %d", tRedemptionFeesPaid, tracker.metaData.SwapFeesPaid)
+	}
+	rig.db.updateMatchChan = nil
 
-	mktEpoch := func() uint64 {
-		dc.epochMtx.RLock()
-		defer dc.epochMtx.RUnlock()
-		return dc.epoch[tDcrBtcMktName]
+	// TAKER MATCH
+	//
+	mid = ordertest.RandomMatchID()
+	msgMatch = &msgjson.Match{
+		OrderID:     loid[:],
+		MatchID:     mid[:],
+		Quantity:    matchSize,
+		Rate:        rate,
+		Address:     "counterparty-address",
+		Side:        uint8(order.Taker),
+		ServerTime:  uint64(matchTime.UnixMilli()),
+		FeeRateBase: tMaxFeeRate,
+	}
+	sign(tDexPriv, msgMatch)
+	msg, _ = msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
+	rig.db.updateMatchChan = make(chan order.MatchStatus, 1)
+	err = handleMatchRoute(tCore, rig.dc, msg)
+	if err != nil {
+		t.Fatalf("match messages error: %v", err)
+	}
+	match, found = tracker.matches[mid]
+	if !found {
+		t.Fatalf("match not found")
+	}
+	newMatchStatus = <-rig.db.updateMatchChan
+	if newMatchStatus != order.NewlyMatched {
+		t.Fatalf("wrong match status. wanted %v, got %v", order.NewlyMatched, newMatchStatus)
+	}
+	proof, auth = &match.MetaData.Proof, &match.MetaData.Proof.Auth
+	if len(auth.MatchSig) == 0 {
+		t.Fatalf("no match sig recorded")
+	}
+	// Secret should not be set yet.
+	if len(proof.Secret) != 0 {
+		t.Fatalf("secret set for taker")
+	}
+	if len(proof.SecretHash) != 0 {
+		t.Fatalf("secret hash set for taker")
 	}
 
-	payload := &msgjson.MatchProofNote{
-		MarketID: tDcrBtcMktName,
-		Epoch:    1,
+	// Now send through the audit request for the maker's init.
+	audit, auditInfo = tMsgAudit(loid, mid, addr, matchSize, nil)
+	tBtcWallet.auditInfo = auditInfo
+	// early lock time
+	auditInfo.Expiration = matchTime.Add(tracker.lockTimeMaker - time.Hour)
+	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
+	if err == nil {
+		t.Fatalf("no taker error for early lock time")
 	}
-	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.MatchProofRoute, payload)
-	err := handleMatchProofMsg(rig.core, rig.dc, req)
+
+	// success, full handleAuditRoute>processAuditMsg>auditContract
+	auditInfo.Expiration = encode.DropMilliseconds(matchTime.Add(tracker.lockTimeMaker))
+	msg, _ = msgjson.NewRequest(1, msgjson.AuditRoute, audit)
+	err = handleAuditRoute(tCore, rig.dc, msg)
 	if err != nil {
-		t.Fatalf("error advancing epoch: %v", err)
+		t.Fatalf("taker's match message error: %v", err)
 	}
-	if mktEpoch() != 2 {
-		t.Fatalf("expected epoch 2, got %d", mktEpoch())
+	// let the async auditContract run, updating match status
+	newMatchStatus = <-rig.db.updateMatchChan
+	if newMatchStatus != order.MakerSwapCast {
+		t.Fatalf("wrong match status. wanted %v, got %v", order.MakerSwapCast, newMatchStatus)
+	}
+	if len(proof.SecretHash) == 0 {
+		t.Fatalf("secret hash not set for taker")
+	}
+	if !bytes.Equal(proof.MakerSwap, audit.CoinID) {
+		t.Fatalf("maker redeem coin not set")
+	}
+	<-rig.db.updateMatchChan // AuditSig is set in a second match data update
+	if !bytes.Equal(auth.AuditSig, audit.Sig) {
+		t.Fatalf("audit sig not set for taker")
+	}
+	if auth.AuditStamp != audit.Time {
+		t.Fatalf("audit time not set for taker")
+	}
+	// The swap should not be sent, since the auditInfo coin doesn't have the
+	// requisite confirmations.
+	if len(proof.TakerSwap) != 0 {
+		t.Fatalf("swap broadcast before confirmations")
+	}
+	// confirming maker's swap should trigger taker's swap bcast
+	tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
+	swapID := encode.RandomBytes(36)
+	tDcrWallet.swapReceipts = []asset.Receipt{&tReceipt{coin: &tCoin{id: swapID}}}
+	rig.ws.queueResponse(msgjson.InitRoute, initAcker)
+	tCore.tickAsset(dc, tUTXOAssetB.ID)
+	newMatchStatus = <-rig.db.updateMatchChan // MakerSwapCast->TakerSwapCast (after taker's swap bcast)
+	if newMatchStatus != order.TakerSwapCast {
+		t.Fatalf("wrong match status. wanted %v, got %v", order.TakerSwapCast, newMatchStatus)
+	}
+	if len(proof.TakerSwap) == 0 {
+		t.Fatalf("swap not broadcast with confirmations")
+	}
+	<-rig.db.updateMatchChan // init ack sig is set in a second match data update
+	if len(auth.InitSig) == 0 {
+		t.Fatalf("init ack sig not set for taker")
 	}
 
-	payload.Epoch = 0
-	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.MatchProofRoute, payload)
-	err = handleMatchProofMsg(rig.core, rig.dc, req)
+	// Receive the maker's redemption.
+	redemptionCoin := encode.RandomBytes(36)
+	redemption := &msgjson.Redemption{
+		Redeem: msgjson.Redeem{
+			OrderID: loid[:],
+			MatchID: mid[:],
+			CoinID:  redemptionCoin,
+		},
+	}
+	sign(tDexPriv, redemption)
+	redeemCoin = encode.RandomBytes(36)
+	tBtcWallet.redeemCoins = []dex.Bytes{redeemCoin}
+	msg, _ = msgjson.NewRequest(1, msgjson.RedemptionRoute, redemption)
+
+	tBtcWallet.badSecret = true
+	err = handleRedemptionRoute(tCore, rig.dc, msg)
+	if err == nil {
+		t.Fatalf("no error for wrong secret")
+	}
+	newMatchStatus = <-rig.db.updateMatchChan  // wrong secret still updates match
+	if newMatchStatus != order.TakerSwapCast { // but status is same
+		t.Fatalf("wrong match status. wanted %v, got %v", order.TakerSwapCast, newMatchStatus)
+	}
+	tBtcWallet.badSecret = false
+
+	tBtcWallet.redeemErrChan = make(chan error, 1)
+	rig.ws.queueResponse(msgjson.RedeemRoute, redeemAcker)
+	err = handleRedemptionRoute(tCore, rig.dc, msg)
 	if err != nil {
-		t.Fatalf("error handling match proof: %v", err)
+		t.Fatalf("redemption message error: %v", err)
 	}
-	if mktEpoch() != 2 {
-		t.Fatalf("epoch changed, expected epoch 2, got %d", mktEpoch())
+	err = <-tBtcWallet.redeemErrChan
+	if err != nil {
+		t.Fatalf("should have worked, got: %v", err)
 	}
-}
+	// For taker, there's one status update to MakerRedeemed prior to bcasting taker's redemption
+	newMatchStatus = <-rig.db.updateMatchChan
+	if newMatchStatus != order.MakerRedeemed {
+		t.Fatalf("wrong match status. wanted %v, got %v", order.MakerRedeemed, newMatchStatus)
+	}
+	// and another status update to MatchComplete when taker's redemption is bcast
+	newMatchStatus = <-rig.db.updateMatchChan
+	if newMatchStatus != order.MatchComplete {
+		t.Fatalf("wrong match status. wanted %v, got %v", order.MatchComplete, newMatchStatus)
+	}
+	if !bytes.Equal(proof.MakerRedeem, redemptionCoin) {
+		t.Fatalf("redemption coin ID not logged")
+	}
+	if len(proof.TakerRedeem) == 0 {
+		t.Fatalf("taker redemption not sent")
+	}
+	// Then a match update to set the redeem ack sig when the 'redeem' request back
+	// to the server succeeds.
+	<-rig.db.updateMatchChan
+	if len(auth.RedeemSig) == 0 {
+		t.Fatalf("redeem ack sig not set for taker")
+	}
+	rig.db.updateMatchChan = nil
+	tBtcWallet.redeemErrChan = nil
 
-func makeLimitOrder(dc *dexConnection, sell bool, qty, rate uint64) (*order.LimitOrder, *db.MetaOrder, order.Preimage, string) {
-	preImg := newPreimage()
-	addr := ordertest.RandomAddress()
-	lo := &order.LimitOrder{
+	// CANCEL ORDER MATCH
+	//
+	tDcrWallet.returnedCoins = nil
+	copy(mid[:], encode.RandomBytes(32))
+	preImgC := newPreimage()
+	co := &order.CancelOrder{
 		P: order.Prefix{
 			AccountID:  dc.acct.ID(),
 			BaseAsset:  tUTXOAssetA.ID,
 			QuoteAsset: tUTXOAssetB.ID,
-			OrderType:  order.LimitOrderType,
+			OrderType:  order.MarketOrderType,
 			ClientTime: time.Now(),
 			ServerTime: time.Now().Add(time.Millisecond),
-			Commit:     preImg.Commit(),
-		},
-		T: order.Trade{
-			// Coins needed?
-			Sell:     sell,
-			Quantity: qty,
-			Address:  addr,
+			Commit:     preImgC.Commit(),
 		},
-		Rate:  rate,
-		Force: order.ImmediateTiF,
 	}
-	fromAsset, toAsset := tUTXOAssetB, tUTXOAssetA
-	if sell {
-		fromAsset, toAsset = tUTXOAssetA, tUTXOAssetB
+	tracker.cancel = &trackedCancel{CancelOrder: *co, epochLen: mkt.EpochLen}
+	coid := co.ID()
+	m1 := &msgjson.Match{
+		OrderID:  loid[:],
+		MatchID:  mid[:],
+		Quantity: cancelledQty,
+		Rate:     rate,
+		Address:  "",
 	}
-	dbOrder := &db.MetaOrder{
-		MetaData: &db.OrderMetaData{
-			Status: order.OrderStatusEpoch,
-			Host:   dc.acct.host,
-			Proof: db.OrderProof{
-				Preimage: preImg[:],
-			},
-			MaxFeeRate:   tMaxFeeRate,
-			EpochDur:     dc.marketEpochDuration(tDcrBtcMktName),
-			FromSwapConf: fromAsset.SwapConf,
-			ToSwapConf:   toAsset.SwapConf,
-		},
-		Order: lo,
+	m2 := &msgjson.Match{
+		OrderID:  coid[:],
+		MatchID:  mid[:],
+		Quantity: cancelledQty,
+		Address:  "testaddr",
 	}
-	return lo, dbOrder, preImg, addr
-}
-
-func TestAddrHost(t *testing.T) {
-	tests := []struct {
-		name, addr, want string
-		wantErr          bool
-	}{{
-		name: "scheme, host, and port",
-		addr: "https://localhost:5758",
-		want: "localhost:5758",
-	}, {
-		name: "scheme, ipv6 host, and port",
-		addr: "https://[::1]:5758",
-		want: "[::1]:5758",
-	}, {
-		name: "host and port",
-		addr: "localhost:5758",
-		want: "localhost:5758",
-	}, {
-		name: "just port",
-		addr: ":5758",
-		want: "localhost:5758",
-	}, {
-		name: "ip host and port",
-		addr: "127.0.0.1:5758",
-		want: "127.0.0.1:5758",
-	}, {
-		name: "just host",
-		addr: "thatonedex.com",
-		want: "thatonedex.com:7232",
-	}, {
-		name: "scheme and host",
-		addr: "https://thatonedex.com",
-		want: "thatonedex.com:7232",
-	}, {
-		name: "scheme, host, and path",
-		addr: "https://thatonedex.com/any/path",
-		want: "thatonedex.com:7232",
-	}, {
-		name: "ipv6 host",
-		addr: "[1:2::]",
-		want: "[1:2::]:7232",
-	}, {
-		name: "ipv6 host and port",
-		addr: "[1:2::]:5758",
-		want: "[1:2::]:5758",
-	}, {
-		name: "empty address",
-		want: "localhost:7232",
-	}, {
-		name:    "invalid host",
-		addr:    "https://\n:1234",
-		wantErr: true,
-	}, {
-		name:    "invalid port",
-		addr:    ":asdf",
-		wantErr: true,
-	}}
-	for _, test := range tests {
-		res, err := addrHost(test.addr)
-		if res != test.want {
-			t.Fatalf("wanted %s but got %s for test '%s'", test.want, res, test.name)
-		}
-		if test.wantErr {
-			if err == nil {
-				t.Fatalf("wanted error for test %s, but got none", test.name)
-			}
-			continue
-		} else if err != nil {
-			t.Fatalf("addrHost error for test %s: %v", test.name, err)
-		}
-		// Parsing results a second time should produce the same results.
-		res, _ = addrHost(res)
-		if res != test.want {
-			t.Fatalf("wanted %s but got %s for test '%s'", test.want, res, test.name)
-		}
+	sign(tDexPriv, m1)
+	sign(tDexPriv, m2)
+	msg, _ = msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{m1, m2})
+	err = handleMatchRoute(tCore, rig.dc, msg)
+	if err != nil {
+		t.Fatalf("handleMatchRoute error (cancel with swaps): %v", err)
+	}
+	if tracker.cancel.matches.maker == nil {
+		t.Fatalf("cancelMatches.maker not set")
+	}
+	if tracker.Trade().Filled() != qty {
+		t.Fatalf("fill not set. %d != %d", tracker.Trade().Filled(), qty)
+	}
+	if tracker.cancel.matches.taker == nil {
+		t.Fatalf("cancelMatches.taker not set")
+	}
+	// Since there are no unswapped orders, the change coin should be returned.
+	if len(tDcrWallet.returnedCoins) != 1 || !bytes.Equal(tDcrWallet.returnedCoins[0].ID(), tDcrWallet.changeCoin.id) {
+		t.Fatalf("change coin not returned")
 	}
-}
-
-func TestAssetBalance(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
 
-	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = wallet
-	bal := &asset.Balance{
-		Available: 4e7,
-		Immature:  6e7,
-		Locked:    2e8,
+	resetMatches := func() {
+		tracker.matches = make(map[order.MatchID]*matchTracker)
+		tracker.change = nil
+		tracker.metaData.ChangeCoin = nil
+		tracker.coinsLocked = true
 	}
-	tWallet.bal = bal
-	walletBal, err := tCore.AssetBalance(tUTXOAssetA.ID)
+
+	// If there is no change coin and no matches, the funding coin should be
+	// returned instead.
+	resetMatches()
+	// The change coins would also have been added to the coins map, so delete
+	// that too.
+	delete(tracker.coins, tDcrWallet.changeCoin.String())
+	err = handleMatchRoute(tCore, rig.dc, msg)
 	if err != nil {
-		t.Fatalf("error retrieving asset balance: %v", err)
+		t.Fatalf("handleMatchRoute error (cancel without swaps): %v", err)
 	}
-	dbtest.MustCompareAssetBalances(t, "zero-conf", bal, &walletBal.Balance.Balance)
-	if walletBal.ContractLocked != 0 {
-		t.Fatalf("contractlocked balance %d > expected value 0", walletBal.ContractLocked)
+	if len(tDcrWallet.returnedCoins) != 1 || !bytes.Equal(tDcrWallet.returnedCoins[0].ID(), fundCoinDcrID) {
+		t.Fatalf("change coin not returned (cancel without swaps)")
 	}
-}
 
-func TestAssetCounter(t *testing.T) {
-	assets := make(assetMap)
-	assets.count(1)
-	if len(assets) != 1 {
-		t.Fatalf("count not added")
-	}
+	// If the order is an immediate order, the asset.Swaps.LockChange should be
+	// false regardless of whether the order is filled.
+	resetMatches()
+	tracker.cancel = nil
+	rig.ws.queueResponse(msgjson.InitRoute, initAcker)
+	msgMatch.Side = uint8(order.Maker)
+	sign(tDexPriv, msgMatch) // Side is not in the serialization but whatever
+	msg, _ = msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
 
-	newCounts := assetMap{
-		1: struct{}{},
-		2: struct{}{},
+	tracker.metaData.Status = order.OrderStatusEpoch
+	lo.Force = order.ImmediateTiF
+	err = handleMatchRoute(tCore, rig.dc, msg)
+	if err != nil {
+		t.Fatalf("handleMatchRoute error (immediate partial fill): %v", err)
 	}
-	assets.merge(newCounts)
-	if len(assets) != 2 {
-		t.Fatalf("counts not absorbed properly")
+	lastSwaps := tDcrWallet.lastSwaps[len(tDcrWallet.lastSwaps)-1]
+	if lastSwaps.LockChange != false {
+		t.Fatalf("change locked for executed non-standing order (immediate partial fill)")
 	}
 }
 
-func TestHandleTradeSuspensionMsg(t *testing.T) {
+func TestReconcileTrades(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-
-	tCore := rig.core
 	dc := rig.dc
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	dcrWallet.Unlock(rig.crypter)
 
-	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	btcWallet.Unlock(rig.crypter)
-
-	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
-
-	rig.dc.books[tDcrBtcMktName] = newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
-
-	addTracker := func(coins asset.Coins) *trackedTrade {
-		lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, 0, 0)
-		oid := lo.ID()
-		tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-			rig.db, rig.queue, walletSet, coins, rig.core.notify, rig.core.formatDetails)
-		dc.trades[oid] = tracker
-		return tracker
-	}
-
-	// Make a trade that has a single funding coin, no change coin, and no
-	// active matches.
-	fundCoinDcrID := encode.RandomBytes(36)
-	freshTracker := addTracker(asset.Coins{&tCoin{id: fundCoinDcrID}})
-	freshTracker.metaData.Status = order.OrderStatusBooked // suspend with purge only purges book orders since epoch orders are always processed first
-
-	// Ensure a non-existent market cannot be suspended.
-	payload := &msgjson.TradeSuspension{
-		MarketID: "dcr_dcr",
+	mkt := dc.marketConfig(tDcrBtcMktName)
+	rig.core.wallets[mkt.Base], _ = newTWallet(mkt.Base)
+	rig.core.wallets[mkt.Quote], _ = newTWallet(mkt.Quote)
+	walletSet, _, _, err := rig.core.walletSet(dc, mkt.Base, mkt.Quote, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
 	}
 
-	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.SuspensionRoute, payload)
-	err := handleTradeSuspensionMsg(rig.core, rig.dc, req)
-	if err == nil {
-		t.Fatal("[handleTradeSuspensionMsg] expected a market ID not found error")
+	type orderSet struct {
+		epoch               *trackedTrade
+		booked              *trackedTrade // standing limit orders only
+		bookedPendingCancel *trackedTrade // standing limit orders only
+		executed            *trackedTrade
 	}
-
-	newPayload := func() *msgjson.TradeSuspension {
-		return &msgjson.TradeSuspension{
-			MarketID:    tDcrBtcMktName,
-			FinalEpoch:  100,
-			SuspendTime: uint64(time.Now().Add(time.Millisecond * 20).UnixMilli()),
-			Persist:     false, // Make sure the coins are returned.
+	makeOrderSet := func(force order.TimeInForce) *orderSet {
+		orders := &orderSet{
+			epoch:    makeTradeTracker(rig, walletSet, force, order.OrderStatusEpoch),
+			executed: makeTradeTracker(rig, walletSet, force, order.OrderStatusExecuted),
+		}
+		if force == order.StandingTiF {
+			orders.booked = makeTradeTracker(rig, walletSet, force, order.OrderStatusBooked)
+			orders.bookedPendingCancel = makeTradeTracker(rig, walletSet, force, order.OrderStatusBooked)
+			orders.bookedPendingCancel.cancel = &trackedCancel{
+				CancelOrder: order.CancelOrder{
+					P: order.Prefix{
+						ServerTime: time.Now().UTC().Add(-16 * time.Minute),
+					},
+				},
+				epochLen: mkt.EpochLen,
+			}
 		}
+		return orders
 	}
 
-	// Suspend a running market.
-	rig.dc.cfgMtx.Lock()
-	mktConf := rig.dc.findMarketConfig(tDcrBtcMktName)
-	mktConf.StartEpoch = 12
-	rig.dc.cfgMtx.Unlock()
-
-	payload = newPayload()
-	payload.SuspendTime = 0 // now
-
-	orderNotes, feedDone := orderNoteFeed(tCore)
-	defer feedDone()
+	standingOrders := makeOrderSet(order.StandingTiF)
+	immediateOrders := makeOrderSet(order.ImmediateTiF)
 
-	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.SuspensionRoute, payload)
-	err = handleTradeSuspensionMsg(rig.core, rig.dc, req)
-	if err != nil {
-		t.Fatalf("[handleTradeSuspensionMsg] unexpected error: %v", err)
+	tests := []struct {
+		name                string
+		clientOrders        []*trackedTrade        // orders known to the client
+		serverOrders        []*msgjson.OrderStatus // orders considered active by the server
+		orderStatusRes      []*msgjson.OrderStatus // server's response to order_status requests
+		expectOrderStatuses map[order.OrderID]order.OrderStatus
+	}{
+		{
+			name:         "server orders unknown to client",
+			clientOrders: []*trackedTrade{},
+			serverOrders: []*msgjson.OrderStatus{
+				{
+					ID:     ordertest.RandomOrderID().Bytes(),
+					Status: uint16(order.OrderStatusBooked),
+				},
+			},
+			expectOrderStatuses: map[order.OrderID]order.OrderStatus{},
+		},
+		{
+			name: "different server-client statuses",
+			clientOrders: []*trackedTrade{
+				standingOrders.epoch,
+				standingOrders.booked,
+				standingOrders.bookedPendingCancel,
+				immediateOrders.epoch,
+				immediateOrders.executed,
+			},
+			serverOrders: []*msgjson.OrderStatus{
+				{
+					ID:     standingOrders.epoch.ID().Bytes(),
+					Status: uint16(order.OrderStatusBooked), // now booked
+				},
+				{
+					ID:     standingOrders.booked.ID().Bytes(),
+					Status: uint16(order.OrderStatusEpoch), // invald! booked orders cannot return to epoch!
+				},
+				{
+					ID:     standingOrders.bookedPendingCancel.ID().Bytes(),
+					Status: uint16(order.OrderStatusBooked), // still booked, cancel order should be deleted
+				},
+				{
+					ID:     immediateOrders.epoch.ID().Bytes(),
+					Status: uint16(order.OrderStatusBooked), // invalid, immediate orders cannot be booked!
+				},
+				{
+					ID:     immediateOrders.executed.ID().Bytes(),
+					Status: uint16(order.OrderStatusBooked), // invalid, inactive orders should not be returned by DEX!
+				},
+			},
+			expectOrderStatuses: map[order.OrderID]order.OrderStatus{
+				standingOrders.epoch.ID():               order.OrderStatusBooked,   // epoch => booked
+				standingOrders.booked.ID():              order.OrderStatusBooked,   // should not change, cannot return to epoch
+				standingOrders.bookedPendingCancel.ID(): order.OrderStatusBooked,   // no status change
+				immediateOrders.epoch.ID():              order.OrderStatusEpoch,    // should not change, cannot be booked
+				immediateOrders.executed.ID():           order.OrderStatusExecuted, // should not change, inactive cannot become active
+			},
+		},
+		{
+			name: "active becomes inactive",
+			clientOrders: []*trackedTrade{
+				standingOrders.epoch,
+				standingOrders.booked,
+				standingOrders.bookedPendingCancel,
+				standingOrders.executed,
+				immediateOrders.epoch,
+				immediateOrders.executed,
+			},
+			serverOrders: []*msgjson.OrderStatus{}, // no active order reported by server
+			orderStatusRes: []*msgjson.OrderStatus{
+				{
+					ID:     standingOrders.epoch.ID().Bytes(),
+					Status: uint16(order.OrderStatusRevoked),
+				},
+				{
+					ID:     standingOrders.booked.ID().Bytes(),
+					Status: uint16(order.OrderStatusRevoked),
+				},
+				{
+					ID:     standingOrders.bookedPendingCancel.ID().Bytes(),
+					Status: uint16(order.OrderStatusCanceled),
+				},
+				{
+					ID:     immediateOrders.epoch.ID().Bytes(),
+					Status: uint16(order.OrderStatusExecuted),
+				},
+			},
+			expectOrderStatuses: map[order.OrderID]order.OrderStatus{
+				standingOrders.epoch.ID():               order.OrderStatusRevoked,  // preimage missed = revoked
+				standingOrders.booked.ID():              order.OrderStatusRevoked,  // booked, not canceled = assume revoked (may actually be executed)
+				standingOrders.bookedPendingCancel.ID(): order.OrderStatusCanceled, // booked pending canceled = assume canceled (may actually be revoked or executed)
+				standingOrders.executed.ID():            order.OrderStatusExecuted, // should not change
+				immediateOrders.epoch.ID():              order.OrderStatusExecuted, // preimage sent, not canceled = executed
+				immediateOrders.executed.ID():           order.OrderStatusExecuted, // should not change
+			},
+		},
 	}
 
-	verifyRevokeNotification(orderNotes, TopicOrderAutoRevoked, t)
+	for _, tt := range tests {
+		// Track client orders in dc.trades.
+		dc.tradeMtx.Lock()
+		var pendingCancel *trackedTrade
+		dc.trades = make(map[order.OrderID]*trackedTrade)
+		for _, tracker := range tt.clientOrders {
+			dc.trades[tracker.ID()] = tracker
+			if tracker.cancel != nil {
+				pendingCancel = tracker
+			}
+		}
+		dc.tradeMtx.Unlock()
 
-	// Check that the funding coin was returned. Use the tradeMtx for
-	// synchronization.
-	dc.tradeMtx.Lock()
-	if len(tDcrWallet.returnedCoins) != 1 || !bytes.Equal(tDcrWallet.returnedCoins[0].ID(), fundCoinDcrID) {
-		t.Fatalf("funding coin not returned")
-	}
-	dc.tradeMtx.Unlock()
+		// Queue order_status response if required for reconciliation.
+		if len(tt.orderStatusRes) > 0 {
+			rig.ws.queueResponse(msgjson.OrderStatusRoute, func(msg *msgjson.Message, f msgFunc) error {
+				resp, _ := msgjson.NewResponse(msg.ID, tt.orderStatusRes, nil)
+				f(resp)
+				return nil
+			})
+		}
 
-	// Make sure the change coin is returned for a trade with a change coin.
-	delete(dc.trades, freshTracker.ID())
-	swappedTracker := addTracker(nil)
-	changeCoinID := encode.RandomBytes(36)
-	swappedTracker.change = &tCoin{id: changeCoinID}
-	swappedTracker.changeLocked = true
-	swappedTracker.metaData.Status = order.OrderStatusBooked
-	rig.dc.cfgMtx.Lock()
-	mktConf.StartEpoch = 12 // make it appear running again first
-	mktConf.FinalEpoch = 0
-	mktConf.Persist = nil
-	rig.dc.cfgMtx.Unlock()
-	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.SuspensionRoute, payload)
-	err = handleTradeSuspensionMsg(rig.core, rig.dc, req)
-	if err != nil {
-		t.Fatalf("[handleTradeSuspensionMsg] unexpected error: %v", err)
-	}
-	// Check that the funding coin was returned.
-	dc.tradeMtx.Lock()
-	if len(tDcrWallet.returnedCoins) != 1 || !bytes.Equal(tDcrWallet.returnedCoins[0].ID(), changeCoinID) {
-		t.Fatalf("change coin not returned")
-	}
-	tDcrWallet.returnedCoins = nil
-	dc.tradeMtx.Unlock()
+		// Reconcile tracked orders with server orders.
+		dc.reconcileTrades(tt.serverOrders)
 
-	// Make sure the coin isn't returned if there are unswapped matches.
-	mid := ordertest.RandomMatchID()
-	match := &matchTracker{
-		MetaMatch: db.MetaMatch{
-			UserMatch: &order.UserMatch{MatchID: mid}, // Default status = NewlyMatched
-			MetaData:  &db.MatchMetaData{},
-		},
-		counterConfirms: -1,
-	}
-	swappedTracker.matches[mid] = match
-	rig.dc.cfgMtx.Lock()
-	mktConf.StartEpoch = 12 // make it appear running again first
-	rig.dc.cfgMtx.Unlock()
-	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.SuspensionRoute, payload)
-	err = handleTradeSuspensionMsg(rig.core, rig.dc, req)
-	if err != nil {
-		t.Fatalf("[handleTradeSuspensionMsg] unexpected error: %v", err)
-	}
-	dc.tradeMtx.Lock()
-	if tDcrWallet.returnedCoins != nil {
-		t.Fatalf("change coin returned with active matches")
-	}
-	dc.tradeMtx.Unlock()
+		dc.tradeMtx.RLock()
+		if len(dc.trades) != len(tt.expectOrderStatuses) {
+			t.Fatalf("%s: post-reconcileTrades order count mismatch. expected %d, got %d",
+				tt.name, len(tt.expectOrderStatuses), len(dc.trades))
+		}
+		for oid, tracker := range dc.trades {
+			expectedStatus, expected := tt.expectOrderStatuses[oid]
+			if !expected {
+				t.Fatalf("%s: unexpected order %v tracked by client", tt.name, oid)
+			}
+			tracker.mtx.RLock()
+			if tracker.metaData.Status != expectedStatus {
+				t.Fatalf("%s: client reported wrong order status %v, expected %v",
+					tt.name, tracker.metaData.Status, expectedStatus)
+			}
+			tracker.mtx.RUnlock()
+		}
+		dc.tradeMtx.RUnlock()
 
-	// Ensure trades for a suspended market generate an error.
-	form := &TradeForm{
-		Host:    tDexHost,
-		IsLimit: true,
-		Sell:    true,
-		Base:    tUTXOAssetA.ID,
-		Quote:   tUTXOAssetB.ID,
-		Qty:     dcrBtcLotSize * 10,
-		Rate:    dcrBtcRateStep * 1000,
-		TifNow:  false,
+		// Check if a previously canceled order existed; if the order is still
+		// active (Epoch/Booked status) and the cancel order is deleted, having
+		// been there for over 15 minutes since the cancel order's epoch ended.
+		if pendingCancel != nil {
+			pendingCancel.mtx.RLock()
+			status, stillHasCancelOrder := pendingCancel.metaData.Status, pendingCancel.cancel != nil
+			pendingCancel.mtx.RUnlock()
+			if status == order.OrderStatusBooked {
+				if stillHasCancelOrder {
+					t.Fatalf("%s: expected stale cancel order to be deleted for now-booked order", tt.name)
+				}
+				// Cancel order deleted. Canceling the order again should succeed.
+				rig.queueCancel(nil)
+				err = rig.core.Cancel(pendingCancel.ID().Bytes())
+				if err != nil {
+					t.Fatalf("cancel order error after deleting previous stale cancel: %v", err)
+				}
+			}
+		}
 	}
+}
 
-	_, err = rig.core.Trade(tPW, form)
-	if err == nil {
-		t.Fatalf("expected a suspension market error")
-	}
+func makeTradeTracker(rig *testRig, walletSet *walletSet, force order.TimeInForce, status order.OrderStatus) *trackedTrade {
+	qty := 4 * dcrBtcLotSize
+	lo, dbOrder, preImg, _ := makeLimitOrder(rig.dc, true, qty, dcrBtcRateStep)
+	lo.Force = force
+	dbOrder.MetaData.Status = status
+
+	return newTrackedTrade(dbOrder, preImg, rig.dc,
+		rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify,
+		rig.core.formatDetails)
 }
 
-func orderNoteFeed(tCore *Core) (orderNotes chan *OrderNote, done func()) {
-	orderNotes = make(chan *OrderNote, 16)
-
-	ntfnFeed := tCore.NotificationFeed()
-	feedDone := make(chan struct{})
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		for {
-			select {
-			case n := <-ntfnFeed.C:
-				if ordNote, ok := n.(*OrderNote); ok {
-					orderNotes <- ordNote
-				}
-			case <-tCtx.Done():
-				return
-			case <-feedDone:
-				return
-			}
-		}
-	}()
-
-	done = func() {
-		close(feedDone) // close first on return
-		wg.Wait()
-	}
-	return orderNotes, done
-}
-
-func verifyRevokeNotification(ch chan *OrderNote, expectedTopic Topic, t *testing.T) {
-	t.Helper()
-	select {
-	case actualOrderNote := <-ch:
-		if expectedTopic != actualOrderNote.TopicID {
-			t.Fatalf("SubjectText mismatch. %s != %s", actualOrderNote.TopicID,
-				expectedTopic)
-		}
-		return
-	case <-tCtx.Done():
-		return
-	case <-time.After(time.Second):
-		t.Fatal("timed out waiting for OrderNote notification")
-		return
-	}
-}
-
-func TestHandleTradeResumptionMsg(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
+func TestRefunds(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
 
+	dc := rig.dc
 	tCore := rig.core
-	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	dcrWallet.Unlock(rig.crypter)
-
-	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
 	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.address = "DsVmA7aqqWeKWy461hXjytbZbgCqbB8g2dq"
 	btcWallet.Unlock(rig.crypter)
 
-	epochLen := rig.dc.marketConfig(tDcrBtcMktName).EpochLen
+	ethWallet, tEthWallet := newTAccountLocker(tACCTAsset.ID)
+	tCore.wallets[tACCTAsset.ID] = ethWallet
+	ethWallet.address = "18d65fb8d60c1199bb1ad381be47aa692b482605"
+	ethWallet.Unlock(rig.crypter)
 
-	handleLimit := func(msg *msgjson.Message, f msgFunc) error {
-		// Need to stamp and sign the message with the server's key.
-		msgOrder := new(msgjson.LimitOrder)
-		err := msg.Unmarshal(msgOrder)
-		if err != nil {
-			t.Fatalf("unmarshal error: %v", err)
+	checkStatus := func(tag string, match *matchTracker, wantStatus order.MatchStatus) {
+		t.Helper()
+		if match.Status != wantStatus {
+			t.Fatalf("%s: wrong status wanted %v, got %v", tag, wantStatus, match.Status)
 		}
-		lo := convertMsgLimitOrder(msgOrder)
-		f(orderResponse(msg.ID, msgOrder, lo, false, false, false))
-		return nil
 	}
+	checkRefund := func(tracker *trackedTrade, match *matchTracker, expectAmt uint64) {
+		t.Helper()
+		// Confirm that the status is SwapCast.
+		if match.Side == order.Maker {
+			checkStatus("maker swapped", match, order.MakerSwapCast)
+		} else {
+			checkStatus("taker swapped", match, order.TakerSwapCast)
+		}
+		// Confirm isRefundable = true.
+		if !tracker.isRefundable(tCore.ctx, match) {
+			t.Fatalf("%s's swap not refundable", match.Side)
+		}
+		// Check refund.
+		amtRefunded, err := rig.core.refundMatches(tracker, []*matchTracker{match})
+		if err != nil {
+			t.Fatalf("unexpected refund error %v", err)
+		}
+		// Check refunded amount.
+		if amtRefunded != expectAmt {
+			t.Fatalf("expected %d refund amount, got %d", expectAmt, amtRefunded)
+		}
+		// Confirm isRefundable = false.
+		if tracker.isRefundable(tCore.ctx, match) {
+			t.Fatalf("%s's swap refundable after being refunded", match.Side)
+		}
+		// Expect refund re-attempt to not refund any coin.
+		amtRefunded, err = rig.core.refundMatches(tracker, []*matchTracker{match})
+		if err != nil {
+			t.Fatalf("unexpected refund error %v", err)
+		}
+		if amtRefunded != 0 {
+			t.Fatalf("expected 0 refund amount, got %d", amtRefunded)
+		}
+		// Confirm that the status is unchanged.
+		if match.Side == order.Maker {
+			checkStatus("maker swapped", match, order.MakerSwapCast)
+		} else {
+			checkStatus("taker swapped", match, order.TakerSwapCast)
+		}
 
-	tradeForm := &TradeForm{
-		Host:    tDexHost,
-		IsLimit: true,
-		Sell:    true,
-		Base:    tUTXOAssetA.ID,
-		Quote:   tUTXOAssetB.ID,
-		Qty:     dcrBtcLotSize * 10,
-		Rate:    dcrBtcRateStep * 1000,
-		TifNow:  false,
+		if _, is := tracker.accountRefunder(); is {
+			if tEthWallet.refundFeeSuggestion != tMaxFeeRate {
+				t.Fatalf("refund suggestion for account asset %v != server max fee rate %v",
+					tEthWallet.refundFeeSuggestion, tACCTAsset.MaxFeeRate)
+			}
+		}
 	}
 
-	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
-
-	// Ensure a non-existent market cannot be suspended.
-	payload := &msgjson.TradeResumption{
-		MarketID: "dcr_dcr",
+	matchSize := 4 * dcrBtcLotSize
+	qty := 3 * matchSize
+	rate := dcrBtcRateStep * 10
+	lo, dbOrder, preImgL, addr := makeLimitOrder(dc, false, qty, dcrBtcRateStep)
+	loid := lo.ID()
+	mid := ordertest.RandomMatchID()
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetB.ID, tACCTAsset.ID, false)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
 	}
+	fundCoinsETH := asset.Coins{&tCoin{id: encode.RandomBytes(36)}}
+	tEthWallet.fundingCoins = fundCoinsETH
+	tEthWallet.fundRedeemScripts = []dex.Bytes{nil}
+	tracker := newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, fundCoinsETH, rig.core.notify, rig.core.formatDetails)
+	rig.dc.trades[tracker.ID()] = tracker
 
-	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.ResumptionRoute, payload)
-	err := handleTradeResumptionMsg(rig.core, rig.dc, req)
-	if err == nil {
-		t.Fatal("[handleTradeResumptionMsg] expected a market ID not found error")
-	}
+	// MAKER REFUND, INVALID TAKER COUNTERSWAP
+	//
 
-	var resumeTime uint64
-	newPayload := func() *msgjson.TradeResumption {
-		return &msgjson.TradeResumption{
-			MarketID:   tDcrBtcMktName,
-			ResumeTime: resumeTime, // set the time to test the scheduling notification case, zero it for immediate resume
-			StartEpoch: resumeTime / epochLen,
-		}
+	matchTime := time.Now().Truncate(time.Millisecond).UTC()
+	msgMatch := &msgjson.Match{
+		OrderID:      loid[:],
+		MatchID:      mid[:],
+		Quantity:     matchSize,
+		Rate:         rate,
+		Address:      "counterparty-address",
+		Side:         uint8(order.Maker),
+		ServerTime:   uint64(matchTime.UnixMilli()),
+		FeeRateQuote: tMaxFeeRate,
 	}
-
-	// Notify of scheduled resume.
-	rig.dc.cfgMtx.Lock()
-	mktConf := rig.dc.findMarketConfig(tDcrBtcMktName)
-	mktConf.StartEpoch = 12
-	mktConf.FinalEpoch = mktConf.StartEpoch + 1 // long since closed
-	rig.dc.cfgMtx.Unlock()
-
-	resumeTime = uint64(time.Now().Add(time.Hour).UnixMilli())
-	payload = newPayload()
-	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.ResumptionRoute, payload)
-	err = handleTradeResumptionMsg(rig.core, rig.dc, req)
+	swapID := encode.RandomBytes(36)
+	contract := encode.RandomBytes(36)
+	tEthWallet.swapReceipts = []asset.Receipt{&tReceipt{coin: &tCoin{id: swapID}, contract: contract}}
+	sign(tDexPriv, msgMatch)
+	msg, _ := msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
+	rig.ws.queueResponse(msgjson.InitRoute, initAcker)
+	err = handleMatchRoute(tCore, rig.dc, msg)
 	if err != nil {
-		t.Fatalf("[handleTradeResumptionMsg] unexpected error: %v", err)
+		t.Fatalf("match messages error: %v", err)
 	}
-
-	// Should be suspended still, no trades
-	_, err = rig.core.Trade(tPW, tradeForm)
-	if err == nil {
-		t.Fatal("trade was accepted for suspended market")
+	match, found := tracker.matches[mid]
+	if !found {
+		t.Fatalf("match not found")
 	}
 
-	// Resume the market immediately.
-	resumeTime = uint64(time.Now().UnixMilli())
-	payload = newPayload()
-	payload.ResumeTime = 0 // resume now, not scheduled
-	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.ResumptionRoute, payload)
-	err = handleTradeResumptionMsg(rig.core, rig.dc, req)
-	if err != nil {
-		t.Fatalf("[handleTradeResumptionMsg] unexpected error: %v", err)
+	// We're the maker, so the init transaction should be broadcast.
+	checkStatus("maker swapped", match, order.MakerSwapCast)
+	proof := &match.MetaData.Proof
+	if !bytes.Equal(proof.ContractData, contract) {
+		t.Fatalf("invalid contract recorded for Maker swap")
 	}
 
-	// Ensure trades for a resumed market are processed without error.
-	_, err = rig.core.Trade(tPW, tradeForm)
-	if err != nil {
-		t.Fatalf("unexpected trade error %v", err)
+	// Send the counter-party's init info.
+	audit, auditInfo := tMsgAudit(loid, mid, addr, matchSize, proof.SecretHash)
+	tBtcWallet.auditInfo = auditInfo
+	auditInfo.Expiration = encode.DropMilliseconds(matchTime.Add(tracker.lockTimeMaker).UTC())
+
+	// Check audit errors.
+	tBtcWallet.auditErr = tErr
+	err = tracker.auditContract(match, audit.CoinID, audit.Contract, nil)
+	if err == nil {
+		t.Fatalf("no maker error for AuditContract error")
 	}
-}
 
-func TestHandleNomatch(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-	dc := rig.dc
+	// Attempt refund.
+	tEthWallet.refundCoin = encode.RandomBytes(36)
+	tEthWallet.refundErr = nil
+	tBtcWallet.refundCoin = nil
+	tBtcWallet.refundErr = fmt.Errorf("unexpected call to btcWallet.Refund")
+	matchSizeQuoteUnits := calc.BaseToQuote(rate, matchSize)
+	// Make the contract appear expired
+	tEthWallet.contractExpired = true
+	tEthWallet.contractLockTime = time.Now()
 
-	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	// Auto-refund is disabled by default, so tick should not refund.
+	tracker.readyToTick = true
+	if tCore.AutoRefund() {
+		t.Fatalf("auto-refund enabled by default")
+	}
+	if _, err := tCore.tick(tracker); err != nil {
+		t.Fatalf("tick error: %v", err)
+	}
+	if len(proof.RefundCoin) != 0 {
+		t.Fatalf("refunded with auto-refund disabled")
+	}
+	if err := tCore.SetAutoRefund(true); err != nil {
+		t.Fatalf("SetAutoRefund error: %v", err)
+	}
+	if !rig.db.autoRefund {
+		t.Fatalf("auto-refund setting not stored")
+	}
 
-	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	// The counterparty spent our swap at the last second. No refund.
+	tEthWallet.swapSpent = true
+	amtRefunded, err := tCore.refundMatches(tracker, []*matchTracker{match})
 	if err != nil {
-		t.Fatalf("walletSet error: %v", err)
+		t.Fatalf("refundMatches error for spent swap: %v", err)
+	}
+	if amtRefunded != 0 || len(proof.RefundCoin) != 0 {
+		t.Fatalf("refunded a spent swap")
+	}
+	if match.refundErr == nil {
+		t.Fatalf("refundErr not set for spent swap")
 	}
+	tEthWallet.swapSpent = false
+	match.refundErr = nil
 
-	fundingCoins := asset.Coins{&tCoin{}}
+	checkRefund(tracker, match, matchSizeQuoteUnits)
+	tEthWallet.contractExpired = false
+	tEthWallet.contractLockTime = time.Now().Add(time.Minute)
 
-	// Four types of order to check
+	// TAKER REFUND, NO MAKER REDEEM
+	//
+	// Reset funding coins in the trackedTrade, wipe change coin.
+	matchTime = time.Now().Truncate(time.Millisecond).UTC()
+	tracker.mtx.Lock()
+	tracker.coins = mapifyCoins(fundCoinsETH)
+	tracker.coinsLocked = true
+	tracker.changeLocked = false
+	tracker.change = nil
+	tracker.metaData.ChangeCoin = nil
+	tracker.mtx.Unlock()
+	mid = ordertest.RandomMatchID()
+	msgMatch = &msgjson.Match{
+		OrderID:      loid[:],
+		MatchID:      mid[:],
+		Quantity:     matchSize,
+		Rate:         rate,
+		Address:      "counterparty-address",
+		Side:         uint8(order.Taker),
+		ServerTime:   uint64(matchTime.UnixMilli()),
+		FeeRateQuote: tMaxFeeRate,
+	}
+	sign(tDexPriv, msgMatch)
+	msg, _ = msgjson.NewRequest(1, msgjson.MatchRoute, []*msgjson.Match{msgMatch})
+	err = handleMatchRoute(tCore, rig.dc, msg)
+	if err != nil {
+		t.Fatalf("match messages error: %v", err)
+	}
+	match, found = tracker.matches[mid]
+	if !found {
+		t.Fatalf("match not found")
+	}
+	checkStatus("taker matched", match, order.NewlyMatched)
+	// Send through the audit request for the maker's init.
+	rig.db.updateMatchChan = make(chan order.MatchStatus, 1)
+	audit, auditInfo = tMsgAudit(loid, mid, addr, matchSize, nil)
+	tBtcWallet.auditInfo = auditInfo
+	auditInfo.Expiration = encode.DropMilliseconds(matchTime.Add(tracker.lockTimeMaker))
+	tBtcWallet.auditErr = nil
+	msg, _ = msgjson.NewRequest(1, msgjson.AuditRoute, audit)
+	err = handleAuditRoute(tCore, rig.dc, msg)
+	if err != nil {
+		t.Fatalf("taker's match message error: %v", err)
+	}
+	// let the async auditContract run, updating match status
+	newMatchStatus := <-rig.db.updateMatchChan
+	if newMatchStatus != order.MakerSwapCast {
+		t.Fatalf("wrong match status. wanted %v, got %v", order.MakerSwapCast, newMatchStatus)
+	}
+	<-rig.db.updateMatchChan // AuditSig set in second update to match data
+	tracker.mtx.RLock()
+	if !bytes.Equal(match.MetaData.Proof.Auth.AuditSig, audit.Sig) {
+		t.Fatalf("audit sig not set for taker")
+	}
+	tracker.mtx.RUnlock()
+	// maker's swap confirmation should trigger taker's swap bcast
+	tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
+	counterSwapID := encode.RandomBytes(36)
+	counterScript := encode.RandomBytes(36)
+	tEthWallet.swapReceipts = []asset.Receipt{&tReceipt{coin: &tCoin{id: counterSwapID}, contract: counterScript}}
+	rig.ws.queueResponse(msgjson.InitRoute, initAcker)
+	tCore.tickAsset(dc, tUTXOAssetB.ID)
+	newMatchStatus = <-rig.db.updateMatchChan // MakerSwapCast->TakerSwapCast (after taker's swap bcast)
+	if newMatchStatus != order.TakerSwapCast {
+		t.Fatalf("wrong match status. wanted %v, got %v", order.TakerSwapCast, newMatchStatus)
+	}
+	tracker.mtx.RLock()
+	if !bytes.Equal(match.MetaData.Proof.ContractData, counterScript) {
+		t.Fatalf("invalid contract recorded for Taker swap")
+	}
+	tracker.mtx.RUnlock()
+	// still takerswapcast, but with initsig
+	newMatchStatus = <-rig.db.updateMatchChan
+	if newMatchStatus != order.TakerSwapCast {
+		t.Fatalf("wrong match status wanted %v, got %v", order.TakerSwapCast, newMatchStatus)
+	}
+	tracker.mtx.RLock()
+	auth := &match.MetaData.Proof.Auth
+	if len(auth.InitSig) == 0 {
+		t.Fatalf("init sig not recorded for valid init ack")
+	}
+	tracker.mtx.RUnlock()
 
-	// 1. Immediate limit order
-	loImmediate, dbOrder, preImgL, _ := makeLimitOrder(dc, true, dcrBtcLotSize*100, dcrBtcRateStep)
-	loImmediate.Force = order.ImmediateTiF
-	immediateOID := loImmediate.ID()
-	immediateTracker := newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, fundingCoins, rig.core.notify, rig.core.formatDetails)
-	dc.trades[immediateOID] = immediateTracker
+	// Attempt refund.
+	rig.db.updateMatchChan = nil
+	tEthWallet.contractExpired = true
+	tEthWallet.contractLockTime = time.Now()
+	checkRefund(tracker, match, matchSizeQuoteUnits)
+	tEthWallet.contractExpired = false
+	tEthWallet.contractLockTime = time.Now().Add(time.Minute)
+}
 
-	// 2. Standing limit order
-	loStanding, dbOrder, preImgL, _ := makeLimitOrder(dc, true, dcrBtcLotSize*100, dcrBtcRateStep)
-	loStanding.Force = order.StandingTiF
-	standingOID := loStanding.ID()
-	standingTracker := newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, fundingCoins, rig.core.notify, rig.core.formatDetails)
-	dc.trades[standingOID] = standingTracker
+func TestCounterpartyInaction(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
 
-	// 3. Cancel order.
-	cancelOrder := &order.CancelOrder{
-		P: order.Prefix{
-			ServerTime: time.Now(),
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.Unlock(rig.crypter)
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
+	}
+	_, dbOrder, preImg, _ := makeLimitOrder(dc, true, 4*dcrBtcLotSize, dcrBtcRateStep)
+	tracker := newTrackedTrade(dbOrder, preImg, dc, tCore.lockTimeTaker, tCore.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, tCore.notify, tCore.formatDetails)
+
+	// The time that our swap reaches the required confirmations is recorded
+	// when checking its status.
+	swapCoinID := encode.RandomBytes(36)
+	match := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{
+				MatchID: ordertest.RandomMatchID(),
+				Side:    order.Maker,
+				Status:  order.MakerSwapCast,
+			},
+			MetaData: &db.MatchMetaData{
+				Proof: db.MatchProof{MakerSwap: swapCoinID},
+			},
 		},
 	}
-	cancelOID := cancelOrder.ID()
-	standingTracker.cancel = &trackedCancel{
-		CancelOrder: *cancelOrder,
+	swapConf := tracker.metaData.FromSwapConf
+	tDcrWallet.setConfs(swapCoinID, swapConf-1, nil)
+	tracker.isSwappable(tCtx, match)
+	if atomic.LoadInt64(&match.swapConfStamp) != 0 {
+		t.Fatalf("swap confirmed time recorded with too few confirmations")
 	}
-
-	// 4. Market order.
-	loWillBeMarket, dbOrder, preImgL, _ := makeLimitOrder(dc, true, dcrBtcLotSize*100, dcrBtcRateStep)
-	mktOrder := &order.MarketOrder{
-		P: loWillBeMarket.P,
-		T: *loWillBeMarket.Trade().Copy(),
+	tDcrWallet.setConfs(swapCoinID, swapConf, nil)
+	tracker.isSwappable(tCtx, match)
+	if atomic.LoadInt64(&match.swapConfStamp) == 0 {
+		t.Fatalf("swap confirmed time not recorded")
 	}
-	dbOrder.Order = mktOrder
-	marketOID := mktOrder.ID()
-	marketTracker := newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, fundingCoins, rig.core.notify, rig.core.formatDetails)
-	dc.trades[marketOID] = marketTracker
 
-	runNomatch := func(tag string, oid order.OrderID) {
-		tracker, _, _ := dc.findOrder(oid)
-		if tracker == nil {
-			t.Fatalf("%s: order ID not found", tag)
-		}
-		payload := &msgjson.NoMatch{OrderID: oid[:]}
-		req, _ := msgjson.NewRequest(dc.NextID(), msgjson.NoMatchRoute, payload)
-		err := handleNoMatchRoute(tCore, dc, req)
-		if err != nil {
-			t.Fatalf("handleNoMatchRoute error: %v", err)
+	feed := tCore.NotificationFeed()
+	checkNote := func(tag string, expTopic Topic) {
+		t.Helper()
+		for {
+			select {
+			case note := <-feed.C:
+				if note.Type() != NoteTypeMatch {
+					continue
+				}
+				if expTopic == "" {
+					t.Fatalf("%s: unexpected %s notification", tag, note.Topic())
+				}
+				if note.Topic() != expTopic {
+					t.Fatalf("%s: wrong topic. wanted %s, got %s", tag, expTopic, note.Topic())
+				}
+				return
+			case <-time.After(50 * time.Millisecond):
+				if expTopic != "" {
+					t.Fatalf("%s: no %s notification", tag, expTopic)
+				}
+				return
+			}
 		}
 	}
 
-	checkTradeStatus := func(tag string, oid order.OrderID, expStatus order.OrderStatus) {
-		tracker, _, _ := dc.findOrder(oid)
-		if tracker.metaData.Status != expStatus {
-			t.Fatalf("%s: wrong status. expected %s, got %s", tag, expStatus, tracker.metaData.Status)
-		}
-		if rig.db.lastStatusID != oid {
-			t.Fatalf("%s: order status not stored", tag)
-		}
-		if rig.db.lastStatus != expStatus {
-			t.Fatalf("%s: wrong order status stored. expected %s, got %s", tag, expStatus, rig.db.lastStatus)
-		}
-		if expStatus == order.OrderStatusExecuted {
-			if tBtcWallet.returnedAddr != tracker.Trade().Address {
-				t.Fatalf("%s: redemption address not returned", tag)
-			}
+	bTimeout := tracker.broadcastTimeout()
+	newMatch := func(side order.MatchSide, status order.MatchStatus, sinceConf time.Duration) *matchTracker {
+		now := time.Now()
+		return &matchTracker{
+			swapConfStamp: now.Add(-sinceConf).UnixMilli(),
+			MetaMatch: db.MetaMatch{
+				UserMatch: &order.UserMatch{
+					MatchID: ordertest.RandomMatchID(),
+					Side:    side,
+					Status:  status,
+				},
+				MetaData: &db.MatchMetaData{
+					Proof: db.MatchProof{
+						Auth: db.MatchAuth{
+							MatchStamp: uint64(now.Add(-sinceConf - 2*time.Second).UnixMilli()),
+							InitStamp:  uint64(now.Add(-sinceConf - time.Second).UnixMilli()),
+						},
+					},
+				},
+			},
 		}
 	}
 
-	runNomatch("cancel", cancelOID)
-	if rig.db.lastStatusID != cancelOID || rig.db.lastStatus != order.OrderStatusExecuted {
-		t.Fatalf("cancel status not updated")
-	}
-	if rig.db.linkedFromID != standingOID || !rig.db.linkedToID.IsZero() {
-		t.Fatalf("missed cancel not unlinked. wanted trade ID %s, got %s. wanted zeroed linked ID, got %s",
-			standingOID, rig.db.linkedFromID, rig.db.linkedToID)
-	}
+	// Maker waiting on the taker's swap, but within the broadcast timeout.
+	match = newMatch(order.Maker, order.MakerSwapCast, bTimeout/2)
+	tracker.checkCounterpartyInaction(match)
+	checkNote("maker within timeout", "")
 
-	runNomatch("standing limit", standingOID)
-	checkTradeStatus("standing limit", standingOID, order.OrderStatusBooked)
+	// The server doesn't wait on the taker until our swap has the required
+	// confirmations, however long ago it was sent.
+	match = newMatch(order.Maker, order.MakerSwapCast, bTimeout*2)
+	match.swapConfStamp = 0
+	tracker.checkCounterpartyInaction(match)
+	checkNote("maker swap unconfirmed", "")
 
-	runNomatch("immediate", immediateOID)
-	checkTradeStatus("immediate", immediateOID, order.OrderStatusExecuted)
+	// Maker waiting on the taker's swap past the broadcast timeout.
+	match = newMatch(order.Maker, order.MakerSwapCast, bTimeout*2)
+	tracker.checkCounterpartyInaction(match)
+	checkNote("maker stalled", TopicCounterSwapDelayed)
+	// Only notified once.
+	tracker.checkCounterpartyInaction(match)
+	checkNote("maker stalled repeat", "")
 
-	runNomatch("market", marketOID)
-	checkTradeStatus("market", marketOID, order.OrderStatusExecuted)
+	// Taker waiting on the maker's redeem past the broadcast timeout.
+	match = newMatch(order.Taker, order.TakerSwapCast, bTimeout*2)
+	tracker.checkCounterpartyInaction(match)
+	checkNote("taker stalled", TopicCounterRedeemDelayed)
 
-	// Unknown order should error.
-	oid := ordertest.RandomOrderID()
-	payload := &msgjson.NoMatch{OrderID: oid[:]}
-	req, _ := msgjson.NewRequest(dc.NextID(), msgjson.NoMatchRoute, payload)
-	err = handleNoMatchRoute(tCore, dc, req)
-	if !errorHasCode(err, unknownOrderErr) {
-		t.Fatalf("wrong error for unknown order ID: %v", err)
-	}
+	// Taker hasn't sent anything yet.
+	match = newMatch(order.Taker, order.NewlyMatched, bTimeout*2)
+	tracker.checkCounterpartyInaction(match)
+	checkNote("taker newly matched", "")
+
+	// Revoked matches are handled by the server's revocation.
+	match = newMatch(order.Maker, order.MakerSwapCast, bTimeout*2)
+	match.MetaData.Proof.ServerRevoked = true
+	tracker.checkCounterpartyInaction(match)
+	checkNote("revoked", "")
 }
 
-func TestWalletSettings(t *testing.T) {
+type TSwapReplacementFinder struct {
+	*TXCWallet
+	replacement    dex.Bytes
+	replacementErr error
+	searches       int
+}
+
+func (w *TSwapReplacementFinder) FindSwapReplacement(ctx context.Context, coinID, contract dex.Bytes) (dex.Bytes, error) {
+	w.searches++
+	return w.replacement, w.replacementErr
+}
+
+func TestSwapReplacement(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
 	tCore := rig.core
-	rig.db.wallet = &db.Wallet{
-		Settings: map[string]string{
-			"abc": "123",
-		},
-	}
-	var assetID uint32 = 54321
-
-	// wallet not found
-	_, err := tCore.WalletSettings(assetID)
-	if !errorHasCode(err, missingWalletErr) {
-		t.Fatalf("wrong error for missing wallet: %v", err)
-	}
-
-	tCore.wallets[assetID] = &xcWallet{}
-
-	// db error
-	rig.db.walletErr = tErr
-	_, err = tCore.WalletSettings(assetID)
-	if !errorHasCode(err, dbErr) {
-		t.Fatalf("wrong error when expected db error: %v", err)
-	}
-	rig.db.walletErr = nil
+	dc := rig.dc
 
-	// success
-	returnedSettings, err := tCore.WalletSettings(assetID)
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	finder := &TSwapReplacementFinder{TXCWallet: tDcrWallet}
+	dcrWallet.Wallet = finder
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
 	if err != nil {
-		t.Fatalf("WalletSettings error: %v", err)
+		t.Fatalf("walletSet error: %v", err)
 	}
+	_, dbOrder, preImg, _ := makeLimitOrder(dc, true, 4*dcrBtcLotSize, dcrBtcRateStep)
+	tracker := newTrackedTrade(dbOrder, preImg, dc, tCore.lockTimeTaker, tCore.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, tCore.notify, tCore.formatDetails)
+	tracker.readyToTick = true
 
-	if len(returnedSettings) != 1 || returnedSettings["abc"] != "123" {
-		t.Fatalf("returned wallet settings are not correct: %v", returnedSettings)
+	feed := tCore.NotificationFeed()
+	checkNote := func(tag string, expTopic Topic) {
+		t.Helper()
+		for {
+			select {
+			case note := <-feed.C:
+				if note.Type() != NoteTypeMatch || note.Topic() == TopicConfirms {
+					continue
+				}
+				if expTopic == "" {
+					t.Fatalf("%s: unexpected %s notification", tag, note.Topic())
+				}
+				if note.Topic() != expTopic {
+					t.Fatalf("%s: wrong topic. wanted %s, got %s", tag, expTopic, note.Topic())
+				}
+				return
+			case <-time.After(50 * time.Millisecond):
+				if expTopic != "" {
+					t.Fatalf("%s: no %s notification", tag, expTopic)
+				}
+				return
+			}
+		}
 	}
-}
-
-func TestChangeAppPass(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	// Use the smarter crypter.
-	smartCrypter := newTCrypterSmart()
-	rig.crypter = smartCrypter
-	rig.core.newCrypter = func([]byte) encrypt.Crypter { return newTCrypterSmart() }
-	rig.core.reCrypter = func([]byte, []byte) (encrypt.Crypter, error) { return rig.crypter, smartCrypter.recryptErr }
-
-	tCore := rig.core
-	newTPW := []byte("apppass")
 
-	// App Password error
-	rig.crypter.(*tCrypterSmart).recryptErr = tErr
-	err := tCore.ChangeAppPass(tPW, newTPW)
-	if !errorHasCode(err, authErr) {
-		t.Fatalf("wrong error for password error: %v", err)
+	// A maker match waiting for the taker's swap.
+	now := time.Now()
+	swapCoinID := encode.RandomBytes(36)
+	match := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{
+				MatchID: ordertest.RandomMatchID(),
+				Side:    order.Maker,
+				Status:  order.MakerSwapCast,
+				Address: "counterparty-address",
+			},
+			MetaData: &db.MatchMetaData{
+				Proof: db.MatchProof{
+					Auth: db.MatchAuth{
+						MatchStamp: uint64(now.UnixMilli()),
+						InitStamp:  uint64(now.UnixMilli()),
+					},
+					MakerSwap:    swapCoinID,
+					ContractData: encode.RandomBytes(50),
+				},
+			},
+		},
 	}
-	rig.crypter.(*tCrypterSmart).recryptErr = nil
-
-	oldCreds := tCore.credentials
+	tracker.matches[match.MatchID] = match
 
-	rig.db.creds = nil
-	err = tCore.ChangeAppPass(tPW, newTPW)
-	if err != nil {
-		t.Fatal(err)
-	}
+	tick := func() {
+		t.Helper()
+		if _, err := tCore.tick(tracker); err != nil {
+			t.Fatalf("tick error: %v", err)
+		}
+	}
+
+	// The swap is found. No search.
+	tDcrWallet.setConfs(swapCoinID, 1, nil)
+	tick()
+	if finder.searches != 0 {
+		t.Fatalf("searched for a replacement of a found swap")
+	}
+
+	// The swap is replaced, but the replacement is not found yet.
+	tDcrWallet.setConfs(swapCoinID, 0, asset.CoinNotFoundError)
+	finder.replacementErr = asset.CoinNotFoundError
+	tick()
+	if finder.searches != 1 {
+		t.Fatalf("no search for a replacement of a missing swap")
+	}
+	checkNote("replacement not found", "")
+
+	// The replacement is found, and tracking follows it.
+	replacementCoinID := encode.RandomBytes(36)
+	finder.replacement, finder.replacementErr = replacementCoinID, nil
+	tick()
+	checkNote("replaced", TopicSwapReplaced)
+	if !bytes.Equal(match.MetaData.Proof.MakerSwap, replacementCoinID) {
+		t.Fatalf("match not re-bound to the replacement")
+	}
+	tDcrWallet.setConfs(replacementCoinID, 2, nil)
+	finder.searches = 0
+	tick()
+	if finder.searches != 0 {
+		t.Fatalf("searched for a replacement of the replacement")
+	}
+	if swapConfs, _ := match.confirms(); swapConfs != 2 {
+		t.Fatalf("replacement confirmations not tracked. got %d", swapConfs)
+	}
+
+	// The replacement disappears and no replacement is found before the
+	// timeout.
+	tDcrWallet.setConfs(replacementCoinID, 0, asset.CoinNotFoundError)
+	finder.replacement, finder.replacementErr = nil, asset.CoinNotFoundError
+	tick()
+	checkNote("within timeout", "")
+	match.swapMissingMtx.Lock()
+	match.swapMissingTime = time.Now().Add(-swapReplacementTimeout - time.Second)
+	match.swapMissingMtx.Unlock()
+	tick()
+	checkNote("timeout", TopicSwapLost)
+	// Only alerted once.
+	tick()
+	checkNote("timeout repeat", "")
+}
+
+type TDuplicateSwapFinder struct {
+	*TXCWallet
+	dups     []dex.Bytes
+	spent    map[string]bool
+	searches int
+}
 
-	if bytes.Equal(oldCreds.OuterKeyParams, tCore.credentials.OuterKeyParams) {
-		t.Fatalf("credentials not updated in Core")
-	}
+func (w *TDuplicateSwapFinder) FindDuplicateSwaps(ctx context.Context, coinID, contract dex.Bytes) ([]dex.Bytes, error) {
+	w.searches++
+	return w.dups, nil
+}
 
-	if rig.db.creds == nil || !bytes.Equal(tCore.credentials.OuterKeyParams, rig.db.creds.OuterKeyParams) {
-		t.Fatalf("credentials not updated in DB")
-	}
+func (w *TDuplicateSwapFinder) SwapConfirmations(ctx context.Context, coinID dex.Bytes, contract dex.Bytes, matchTime time.Time) (uint32, bool, error) {
+	confs, err := w.tConfirmations(ctx, coinID)
+	return confs, w.spent[coinID.String()], err
 }
 
-func TestResetAppPass(t *testing.T) {
+func TestDuplicateSwaps(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	crypter := newTCrypterSmart()
-	rig.crypter = crypter
-	rig.core.newCrypter = func([]byte) encrypt.Crypter { return crypter }
-	rig.core.reCrypter = func([]byte, []byte) (encrypt.Crypter, error) { return rig.crypter, crypter.recryptErr }
-
-	rig.core.credentials = nil
-	rig.core.InitializeClient(tPW, nil)
-
 	tCore := rig.core
-	seed, err := tCore.ExportSeed(tPW)
+	dc := rig.dc
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	finder := &TDuplicateSwapFinder{TXCWallet: tDcrWallet, spent: make(map[string]bool)}
+	dcrWallet.Wallet = finder
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
 	if err != nil {
-		t.Fatalf("seed export failed: %v", err)
+		t.Fatalf("walletSet error: %v", err)
 	}
+	_, dbOrder, preImg, _ := makeLimitOrder(dc, true, 4*dcrBtcLotSize, dcrBtcRateStep)
+	tracker := newTrackedTrade(dbOrder, preImg, dc, tCore.lockTimeTaker, tCore.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, tCore.notify, tCore.formatDetails)
+	tracker.readyToTick = true
 
-	// Invalid seed error
-	invalidSeed := seed[:24]
-	err = tCore.ResetAppPass(tPW, invalidSeed)
-	if !strings.Contains(err.Error(), "unabled to decode provided seed") {
-		t.Fatalf("wrong error for invalid seed length: %v", err)
+	feed := tCore.NotificationFeed()
+	checkNote := func(tag string, expTopic Topic) {
+		t.Helper()
+		for {
+			select {
+			case note := <-feed.C:
+				if note.Type() != NoteTypeMatch || note.Topic() == TopicConfirms {
+					continue
+				}
+				if expTopic == "" {
+					t.Fatalf("%s: unexpected %s notification", tag, note.Topic())
+				}
+				if note.Topic() != expTopic {
+					t.Fatalf("%s: wrong topic. wanted %s, got %s", tag, expTopic, note.Topic())
+				}
+				return
+			case <-time.After(50 * time.Millisecond):
+				if expTopic != "" {
+					t.Fatalf("%s: no %s notification", tag, expTopic)
+				}
+				return
+			}
+		}
 	}
 
-	// Want incorrect seed error.
-	rig.crypter.(*tCrypterSmart).recryptErr = tErr
-	// tCrypter is used to encode the orginal seed but we don't need it here, so
-	// we need to add 8 bytes to commplete the expected seed lenght(64).
-	err = tCore.ResetAppPass(tPW, seed+"blah")
-	if !strings.Contains(err.Error(), "unabled to decode provided seed") {
-		t.Fatalf("wrong error for incorrect seed: %v", err)
+	tick := func() {
+		t.Helper()
+		if _, err := tCore.tick(tracker); err != nil {
+			t.Fatalf("tick error: %v", err)
+		}
 	}
 
-	// ok, no crypter error.
-	rig.crypter.(*tCrypterSmart).recryptErr = nil
-	err = tCore.ResetAppPass(tPW, seed)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	// A maker match that has been redeemed by both parties, but our swap
+	// was broadcast twice.
+	now := time.Now()
+	newMatch := func(swapCoinID order.CoinID) *matchTracker {
+		match := &matchTracker{
+			MetaMatch: db.MetaMatch{
+				UserMatch: &order.UserMatch{
+					MatchID: ordertest.RandomMatchID(),
+					Side:    order.Maker,
+					Status:  order.MatchComplete,
+					Address: "counterparty-address",
+				},
+				MetaData: &db.MatchMetaData{
+					Proof: db.MatchProof{
+						Auth: db.MatchAuth{
+							MatchStamp: uint64(now.UnixMilli()),
+							InitStamp:  uint64(now.UnixMilli()),
+						},
+						MakerSwap:    swapCoinID,
+						MakerRedeem:  encode.RandomBytes(36),
+						TakerSwap:    encode.RandomBytes(36),
+						ContractData: encode.RandomBytes(50),
+						Secret:       encode.RandomBytes(32),
+					},
+				},
+			},
+		}
+		tracker.matches[match.MatchID] = match
+		// Our redemption is not yet confirmed.
+		tBtcWallet.confirmRedemptionResult = &asset.ConfirmRedemptionStatus{
+			Req:    1,
+			CoinID: dex.Bytes(match.MetaData.Proof.MakerRedeem),
+		}
+		return match
 	}
-}
+	swapCoinID := encode.RandomBytes(36)
+	match := newMatch(swapCoinID)
+	proof := &match.MetaData.Proof
+	dupCoinID := encode.RandomBytes(36)
+	finder.dups = []dex.Bytes{dex.Bytes(swapCoinID), dupCoinID}
+	tDcrWallet.contractLockTime = now.Add(time.Hour)
 
-func TestReconfigureWallet(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-	rig.db.wallet = &db.Wallet{
-		Settings: map[string]string{
-			"abc": "123",
-		},
+	// The duplicate is detected before the locktime expires.
+	tick()
+	if finder.searches != 1 {
+		t.Fatalf("expected 1 search, got %d", finder.searches)
 	}
-	const assetID uint32 = 54321
-	xyzWallet, tXyzWallet := newTWallet(assetID)
-	newSettings := map[string]string{
-		"def": "456",
+	checkNote("detected", TopicDuplicateSwaps)
+	if len(proof.DuplicateSwaps) != 1 || !bytes.Equal(proof.DuplicateSwaps[0], dupCoinID) {
+		t.Fatalf("duplicate not recorded. got %v", proof.DuplicateSwaps)
 	}
-
-	form := &WalletForm{
-		AssetID: assetID,
-		Config:  newSettings,
-		Type:    "type",
+	if !tracker.matchIsActive(match) {
+		t.Fatalf("match with duplicate swaps is not active")
 	}
-	xyzWallet.walletType = "type"
-
-	// App Password error
-	rig.crypter.(*tCrypter).recryptErr = tErr
-	err := tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, authErr) {
-		t.Fatalf("wrong error for password error: %v", err)
+	if m := matchFromMetaMatch(tracker.Order, &match.MetaMatch); len(m.DuplicateSwaps) != 1 {
+		t.Fatalf("duplicate not reported in core Match")
 	}
-	rig.crypter.(*tCrypter).recryptErr = nil
 
-	// Missing wallet error
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, assetSupportErr) {
-		t.Fatalf("wrong error for missing wallet definition: %v", err)
+	// No new search within the interval, and no refund before the locktime.
+	tick()
+	if finder.searches != 1 {
+		t.Fatalf("searched again within the interval")
+	}
+	checkNote("not expired", "")
+	if len(proof.DuplicateSwaps) != 1 {
+		t.Fatalf("duplicate resolved before the locktime")
 	}
 
-	walletDef := &asset.WalletDefinition{
-		Type:   "type",
-		Seeded: true,
+	// A confirmed match with a duplicate is still active.
+	match.Status = order.MatchConfirmed
+	if !tracker.matchIsActive(match) {
+		t.Fatalf("confirmed match with duplicate swaps is not active")
 	}
-	winfo := *tWalletInfo
-	winfo.AvailableWallets = []*asset.WalletDefinition{walletDef}
 
-	assetDriver := &tCreator{
-		tDriver: &tDriver{
-			wallet: xyzWallet.Wallet,
-			winfo:  &winfo,
-		},
+	// After the locktime, the unspent duplicate is refunded.
+	tDcrWallet.contractExpired = true
+	tDcrWallet.refundCoin = encode.RandomBytes(36)
+	tDcrWallet.refundErr = nil
+	tick()
+	checkNote("refunded", TopicDuplicateSwapRefunded)
+	if len(proof.DuplicateSwaps) != 0 {
+		t.Fatalf("refunded duplicate still recorded")
+	}
+	if !bytes.Equal(proof.MakerSwap, swapCoinID) {
+		t.Fatalf("swap coin changed")
+	}
+	if tracker.matchIsActive(match) {
+		t.Fatalf("resolved match still active")
+	}
+	delete(tracker.matches, match.MatchID)
+
+	// The counterparty redeemed a duplicate instead of the swap we reported.
+	// The match follows the duplicate before the locktime expires, and the
+	// reported swap is refunded as a duplicate once it does.
+	tDcrWallet.contractExpired = false
+	swapCoinID = encode.RandomBytes(36)
+	match = newMatch(swapCoinID)
+	proof = &match.MetaData.Proof
+	dupCoinID = encode.RandomBytes(36)
+	proof.DuplicateSwaps = []order.CoinID{order.CoinID(dupCoinID)}
+	finder.dups = nil
+	finder.spent[dex.Bytes(dupCoinID).String()] = true
+	tick()
+	checkNote("switched", TopicDuplicateSwapUsed)
+	if !bytes.Equal(proof.MakerSwap, dupCoinID) {
+		t.Fatalf("match not re-bound to the redeemed duplicate")
+	}
+	if len(proof.DuplicateSwaps) != 1 || !bytes.Equal(proof.DuplicateSwaps[0], swapCoinID) {
+		t.Fatalf("reported swap not recorded as a duplicate. got %v", proof.DuplicateSwaps)
+	}
+	tick()
+	checkNote("original not expired", "")
+	if len(proof.DuplicateSwaps) != 1 {
+		t.Fatalf("reported swap resolved before the locktime")
 	}
-	asset.Register(assetID, assetDriver)
-	if err = xyzWallet.Connect(); err != nil {
-		t.Fatal(err)
+	tDcrWallet.contractExpired = true
+	tick()
+	checkNote("original refunded", TopicDuplicateSwapRefunded)
+	if len(proof.DuplicateSwaps) != 0 {
+		t.Fatalf("refunded swap still recorded")
 	}
-	defer xyzWallet.Disconnect()
+}
 
-	// Missing wallet error
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, missingWalletErr) {
-		t.Fatalf("wrong error for missing wallet: %v", err)
-	}
+func TestNotifications(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
 
-	tCore.wallets[assetID] = xyzWallet
+	// Insert a notification into the database.
+	typedNote := newOrderNote("123", "abc", "def", 100, nil)
 
-	// Errors for seeded wallets.
-	walletDef.Seeded = true
-	// Exists error
-	assetDriver.existsErr = tErr
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, existenceCheckErr) {
-		t.Fatalf("wrong error when expecting existence check error: %v", err)
-	}
-	assetDriver.existsErr = nil
-	// Create error
-	assetDriver.doesntExist = true
-	assetDriver.createErr = tErr
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, createWalletErr) {
-		t.Fatalf("wrong error when expecting wallet creation error error: %v", err)
+	tCore := rig.core
+	ch := tCore.NotificationFeed()
+	tCore.notify(typedNote)
+	select {
+	case n := <-ch.C:
+		dbtest.MustCompareNotifications(t, n.DBNote(), &typedNote.Notification)
+	case <-time.After(time.Second):
+		t.Fatalf("no notification received over the notification channel")
 	}
-	assetDriver.createErr = nil
-	walletDef.Seeded = false
+}
 
-	// Connect error
-	tXyzWallet.connectErr = tErr
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, connectWalletErr) {
-		t.Fatalf("wrong error when expecting connection error: %v", err)
-	}
-	tXyzWallet.connectErr = nil
+func generateMatch(rig *testRig, baseID, quoteID uint32) (uint64, *order.LimitOrder, *db.MetaOrder, *db.MetaMatch, *tCoin) {
+	const redemptionReserves = 50
+	const refundReserves = 75
 
-	// Unlock error
-	tXyzWallet.Unlock(wPW)
-	tXyzWallet.unlockErr = tErr
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, walletAuthErr) {
-		t.Fatalf("wrong error when expecting auth error: %v", err)
+	qty := dcrBtcLotSize * 5
+	rate := dcrBtcRateStep * 5
+	lo := &order.LimitOrder{
+		P: order.Prefix{
+			OrderType:  order.LimitOrderType,
+			BaseAsset:  baseID,
+			QuoteAsset: quoteID,
+			ClientTime: time.Now(),
+			ServerTime: time.Now(),
+			Commit:     ordertest.RandomCommitment(),
+		},
+		T: order.Trade{
+			Quantity: qty,
+			Sell:     true,
+		},
+		Rate:  rate,
+		Force: order.StandingTiF, // we're calling it booked in OrderMetaData
 	}
-	tXyzWallet.unlockErr = nil
 
-	// For the last success, make sure that we also clear any related
-	// tickGovernors.
-	abcWallet, _ := newTWallet(tUTXOAssetA.ID) // for to/baseWallet
-	matchID := ordertest.RandomMatchID()
-	match := &matchTracker{
-		suspectSwap:  true,
-		tickGovernor: time.NewTimer(time.Hour),
-		MetaMatch: db.MetaMatch{
-			MetaData: &db.MatchMetaData{
-				Proof: db.MatchProof{
-					ContractData: dex.Bytes{0},
-				},
-			},
-			UserMatch: &order.UserMatch{
-				MatchID: matchID,
-			},
+	changeCoinID := encode.RandomBytes(36)
+	changeCoin := &tCoin{id: changeCoinID}
+
+	dbOrder := &db.MetaOrder{
+		MetaData: &db.OrderMetaData{
+			Status:             order.OrderStatusBooked,
+			Host:               tDexHost,
+			Proof:              db.OrderProof{},
+			ChangeCoin:         changeCoinID,
+			RedemptionReserves: redemptionReserves,
+			RefundReserves:     refundReserves,
 		},
+		Order: lo,
 	}
-	tCore.conns[tDexHost].tradeMtx.Lock()
-	tCore.conns[tDexHost].trades[order.OrderID{}] = &trackedTrade{
-		Order: &order.LimitOrder{
-			P: order.Prefix{
-				BaseAsset:  assetID,
-				ServerTime: time.Now(),
+
+	oid := lo.ID()
+	mid := ordertest.RandomMatchID()
+	addr := ordertest.RandomAddress()
+	matchQty := qty - dcrBtcLotSize
+	match := &db.MetaMatch{
+		MetaData: &db.MatchMetaData{
+			Proof: db.MatchProof{
+				CounterContract: encode.RandomBytes(50),
+				SecretHash:      encode.RandomBytes(32),
+				MakerSwap:       encode.RandomBytes(32),
+				Auth: db.MatchAuth{
+					MatchSig: encode.RandomBytes(32),
+					InitSig:  encode.RandomBytes(32), // otherwise MatchComplete will be seen as a cancel order match (inactive)
+				},
 			},
+			DEX:   tDexHost,
+			Base:  baseID,
+			Quote: quoteID,
 		},
-		wallets: &walletSet{
-			fromWallet:  xyzWallet,
-			quoteWallet: xyzWallet, // sell=false
-			toWallet:    abcWallet,
-			baseWallet:  abcWallet,
-		},
-		matches: map[order.MatchID]*matchTracker{
-			{}: match,
+		UserMatch: &order.UserMatch{
+			OrderID:  oid,
+			MatchID:  mid,
+			Quantity: matchQty,
+			Rate:     rate,
+			Address:  addr,
+			Status:   order.MakerSwapCast,
+			Side:     order.Taker,
 		},
-		metaData:    &db.OrderMetaData{},
-		dc:          rig.dc,
-		readyToTick: true, // prevent resume path
 	}
-	tCore.conns[tDexHost].tradeMtx.Unlock()
 
-	// Error checking if wallet owns address.
-	tXyzWallet.ownsAddressErr = tErr
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, walletErr) {
-		t.Fatalf("wrong error when expecting ownsAddress wallet error: %v", err)
-	}
-	tXyzWallet.ownsAddressErr = nil
+	// Need to return an order from db.ActiveDEXOrders
+	rig.db.activeDEXOrders = []*db.MetaOrder{dbOrder}
+	rig.db.orderOrders[oid] = dbOrder
 
-	// Wallet doesn't own address.
-	tXyzWallet.ownsAddress = false
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, walletErr) {
-		t.Fatalf("wrong error when expecting not owned wallet error: %v", err)
-	}
+	rig.db.activeMatchOIDs = []order.OrderID{oid}
+	rig.db.matchesForOID = []*db.MetaMatch{match}
 
-	// Leave the ownsAddress false, but swap out a LiveReconfigurer and ensure
-	// the restart = false path passes.
-	liveReconfigurer := &TLiveReconfigurer{TXCWallet: tXyzWallet}
-	xyzWallet.Wallet = liveReconfigurer
-	if err = tCore.ReconfigureWallet(tPW, nil, form); err != nil {
-		t.Fatalf("ReconfigureWallet error for short path: %v", err)
-	}
+	return qty, lo, dbOrder, match, changeCoin
+}
 
-	// But restart = true should still fail for live orders.
-	liveReconfigurer.restart = true
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, walletErr) {
-		t.Fatalf("wrong error when expecting not owned wallet error: %v", err)
-	}
-	liveReconfigurer.restart = false
+var (
+	activeStatuses   = []order.OrderStatus{order.OrderStatusEpoch, order.OrderStatusBooked}
+	inactiveStatuses = []order.OrderStatus{order.OrderStatusExecuted, order.OrderStatusCanceled, order.OrderStatusRevoked}
 
-	// OwnsAddress error
-	liveReconfigurer.ownsAddressErr = tErr
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, walletErr) {
-		t.Fatalf("wrong error when expecting ownsAddress wallet error without restart: %v", err)
+	reservationTests = []struct {
+		name          string
+		sell          bool
+		side          []order.MatchSide
+		orderStatuses []order.OrderStatus
+		matchStatuses []order.MatchStatus
+		expectedCoins int
+	}{
+		// With an active order, the change coin should always be loaded.
+		{
+			name:          "active-order, sell",
+			sell:          true,
+			side:          []order.MatchSide{order.Taker, order.Maker},
+			orderStatuses: activeStatuses,
+			matchStatuses: []order.MatchStatus{order.NewlyMatched, order.MakerSwapCast,
+				order.TakerSwapCast, order.MakerRedeemed, order.MatchComplete},
+			expectedCoins: 1,
+		},
+		{
+			name:          "active-order, buy",
+			sell:          false,
+			side:          []order.MatchSide{order.Taker, order.Maker},
+			orderStatuses: activeStatuses,
+			matchStatuses: []order.MatchStatus{order.NewlyMatched, order.MakerSwapCast,
+				order.TakerSwapCast, order.MakerRedeemed, order.MatchComplete},
+			expectedCoins: 1,
+		},
+		// With an inactive order, as taker, if match is >= TakerSwapCast, there
+		// will be no funding coin fetched.
+		{
+			name:          "inactive taker > MakerSwapCast, sell",
+			sell:          true,
+			side:          []order.MatchSide{order.Taker},
+			orderStatuses: inactiveStatuses,
+			matchStatuses: []order.MatchStatus{order.TakerSwapCast, order.MakerRedeemed,
+				order.MatchComplete},
+			expectedCoins: 0,
+		},
+		{
+			name:          "inactive taker > MakerSwapCast, buy",
+			sell:          false,
+			side:          []order.MatchSide{order.Taker},
+			orderStatuses: inactiveStatuses,
+			matchStatuses: []order.MatchStatus{order.TakerSwapCast, order.MakerRedeemed,
+				order.MatchComplete},
+			expectedCoins: 0,
+		},
+		// But there will be for NewlyMatched && MakerSwapCast
+		{
+			name:          "inactive taker < TakerSwapCast, sell",
+			sell:          true,
+			side:          []order.MatchSide{order.Taker},
+			orderStatuses: inactiveStatuses,
+			matchStatuses: []order.MatchStatus{order.NewlyMatched, order.MakerSwapCast},
+			expectedCoins: 1,
+		},
+		{
+			name:          "inactive taker < TakerSwapCast, buy",
+			sell:          false,
+			side:          []order.MatchSide{order.Taker},
+			orderStatuses: inactiveStatuses,
+			matchStatuses: []order.MatchStatus{order.NewlyMatched, order.MakerSwapCast},
+			expectedCoins: 1,
+		},
+		// For a maker with an inactive order, only NewlyMatched would
+		// necessitate fetching of coins.
+		{
+			name:          "inactive maker NewlyMatched, sell",
+			sell:          true,
+			side:          []order.MatchSide{order.Maker},
+			orderStatuses: inactiveStatuses,
+			matchStatuses: []order.MatchStatus{order.NewlyMatched},
+			expectedCoins: 1,
+		},
+		{
+			name:          "inactive maker NewlyMatched, buy",
+			sell:          false,
+			side:          []order.MatchSide{order.Maker},
+			orderStatuses: inactiveStatuses,
+			matchStatuses: []order.MatchStatus{order.NewlyMatched},
+			expectedCoins: 1,
+		},
+		{
+			name:          "inactive maker > NewlyMatched, sell",
+			sell:          true,
+			side:          []order.MatchSide{order.Maker},
+			orderStatuses: inactiveStatuses,
+			matchStatuses: []order.MatchStatus{order.MakerSwapCast, order.TakerSwapCast,
+				order.MakerRedeemed, order.MatchComplete},
+			expectedCoins: 0,
+		},
+		{
+			name:          "inactive maker > NewlyMatched, buy",
+			sell:          false,
+			side:          []order.MatchSide{order.Maker},
+			orderStatuses: inactiveStatuses,
+			matchStatuses: []order.MatchStatus{order.MakerSwapCast, order.TakerSwapCast,
+				order.MakerRedeemed, order.MatchComplete},
+			expectedCoins: 0,
+		},
 	}
-	liveReconfigurer.ownsAddressErr = nil
+)
 
-	// Refresh address error
-	liveReconfigurer.addrErr = tErr
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, newAddrErr) {
-		t.Fatalf("wrong error when expecting address refresh error without restart: %v", err)
-	}
-	liveReconfigurer.addrErr = nil
+// auth sets the account as authenticated at the provided tier.
+func auth(a *dexAccount) {
+	a.authMtx.Lock()
+	a.isAuthed = true
+	a.rep = account.Reputation{BondedTier: 1}
+	a.authMtx.Unlock()
+}
 
-	// Password error for non-seeded wallet with password.
-	// from above: walletDef.Seeded = false
-	liveReconfigurer.unlockErr = tErr
-	err = tCore.ReconfigureWallet(tPW, append(tPW, 5), form)
-	if !errorHasCode(err, walletAuthErr) {
-		t.Fatalf("wrong error when expecting new password error without restart: %v", err)
-	}
-	liveReconfigurer.unlockErr = nil
+func TestResolveActiveTrades(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-	// DB error for restartless path.
-	rig.db.updateWalletErr = tErr
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if !errorHasCode(err, dbErr) {
-		t.Fatalf("wrong error when db update error without restart: %v", err)
-	}
-	rig.db.updateWalletErr = nil
+	auth(rig.acct) // Short path through initializeDEXConnections
 
-	// End LiveReconfigurer tests.
-	xyzWallet.Wallet = tXyzWallet
-	tXyzWallet.ownsAddress = true
+	utxoAsset /* base */, acctAsset /* quote */ := tUTXOAssetB, tACCTAsset
 
-	// Success updating settings.
-	err = tCore.ReconfigureWallet(tPW, nil, form)
-	if err != nil {
-		t.Fatalf("ReconfigureWallet error: %v", err)
-	}
+	btcWallet, tBtcWallet := newTWallet(utxoAsset.ID)
+	tCore.wallets[utxoAsset.ID] = btcWallet
 
-	settings := rig.db.wallet.Settings
-	if len(settings) != 1 || settings["def"] != "456" {
-		t.Fatalf("settings not stored")
-	}
+	ethWallet, tEthWallet := newTAccountLocker(acctAsset.ID)
+	tCore.wallets[acctAsset.ID] = ethWallet
 
-	if match.tickGovernor != nil {
-		t.Fatalf("tickGovernor not removed")
-	}
-
-	// Success updating wallet PW.
-	newWalletPW := []byte("password")
-	err = tCore.ReconfigureWallet(tPW, newWalletPW, form)
-	if err != nil {
-		t.Fatalf("ReconfigureWallet error: %v", err)
-	}
+	// Create an order
+	qty, lo, dbOrder, match, changeCoin := generateMatch(rig, utxoAsset.ID, acctAsset.ID)
+	redemptionReserves, refundReserves := dbOrder.MetaData.RedemptionReserves, dbOrder.MetaData.RefundReserves
 
-	// Check that the xcWallet was updated.
-	xyzWallet = tCore.wallets[assetID]
-	decNewPW, _ := rig.crypter.Decrypt(xyzWallet.encPW())
-	if !bytes.Equal(decNewPW, newWalletPW) {
-		t.Fatalf("xcWallet encPW field not updated want: %x got: %x",
-			newWalletPW, decNewPW)
-	}
-}
+	tBtcWallet.fundingCoins = asset.Coins{changeCoin}
+	tEthWallet.fundingCoins = asset.Coins{changeCoin}
 
-func TestSetWalletPassword(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-	rig.db.wallet = &db.Wallet{
-		EncryptedPW: []byte("abc"),
-	}
-	newPW := []byte("def")
-	var assetID uint32 = 54321
+	// reset
+	reset := func() {
+		rig.acct.lock()
+		btcWallet.Lock(time.Second)
+		ethWallet.Lock(time.Second)
+		tEthWallet.reservedRedemption = 0
+		tEthWallet.reservedRefund = 0
 
-	// Nil password error
-	err := tCore.SetWalletPassword(tPW, assetID, nil)
-	if !errorHasCode(err, passwordErr) {
-		t.Fatalf("wrong error for nil password error: %v", err)
+		rig.dc.trades = make(map[order.OrderID]*trackedTrade)
 	}
 
-	// Auth error
-	rig.crypter.(*tCrypter).recryptErr = tErr
-	err = tCore.SetWalletPassword(tPW, assetID, newPW)
-	if !errorHasCode(err, authErr) {
-		t.Fatalf("wrong error for auth error: %v", err)
-	}
-	rig.crypter.(*tCrypter).recryptErr = nil
+	// Ensure the order is good, and reset the state.
+	runTest := func(tag string, expAddedToTradesMap, expReadyToTick, expBTCUnlocked, expETHUnlocked bool, expCoinsLoaded int) {
+		t.Helper()
+		defer reset()
 
-	// Missing wallet error
-	err = tCore.SetWalletPassword(tPW, assetID, newPW)
-	if !errorHasCode(err, missingWalletErr) {
-		t.Fatalf("wrong error for missing wallet: %v", err)
-	}
+		description := fmt.Sprintf("%s: side = %s, order status = %s, match status = %s",
+			tag, match.Side, dbOrder.MetaData.Status, match.Status)
+		tCore.loginMtx.Lock()
+		tCore.loggedIn = false
+		tCore.loginMtx.Unlock()
+		err := tCore.Login(tPW)
+		if err != nil {
+			t.Fatalf("%s: login error: %v", description, err)
+		}
 
-	xyzWallet, tXyzWallet := newTWallet(assetID)
-	tCore.wallets[assetID] = xyzWallet
+		trade, found := rig.dc.trades[lo.ID()]
+		if expAddedToTradesMap != found {
+			t.Fatalf("%s: expected added to trades map = %v, but got %v. len(trades) = %d", description, expAddedToTradesMap, found, len(rig.dc.trades))
+		}
+		if !expAddedToTradesMap {
+			return
+		}
 
-	// Connection error
-	xyzWallet.hookedUp = false
-	tXyzWallet.connectErr = tErr
-	err = tCore.SetWalletPassword(tPW, assetID, newPW)
-	if !errorHasCode(err, connectionErr) {
-		t.Fatalf("wrong error for connection error: %v", err)
-	}
-	xyzWallet.hookedUp = true
-	tXyzWallet.connectErr = nil
+		if expBTCUnlocked != btcWallet.unlocked() {
+			t.Fatalf("%s: btc wallet unlocked = %v but got %v", description, expBTCUnlocked, btcWallet.unlocked())
+		}
 
-	// Unlock error
-	tXyzWallet.unlockErr = tErr
-	err = tCore.SetWalletPassword(tPW, assetID, newPW)
-	if !errorHasCode(err, authErr) {
-		t.Fatalf("wrong error for auth error: %v", err)
-	}
-	tXyzWallet.unlockErr = nil
+		if expETHUnlocked != ethWallet.unlocked() {
+			t.Fatalf("%s: eth wallet unlocked = %v but got %v", description, expETHUnlocked, ethWallet.unlocked())
+		}
 
-	// SetWalletPassword db error
-	rig.db.setWalletPwErr = tErr
-	err = tCore.SetWalletPassword(tPW, assetID, newPW)
-	if !errorHasCode(err, dbErr) {
-		t.Fatalf("wrong error for missing wallet: %v", err)
-	}
-	rig.db.setWalletPwErr = nil
+		_, found = trade.matches[match.MatchID]
+		if !found {
+			t.Fatalf("%s: trade with expected order id not found. len(matches) = %d", description, len(trade.matches))
+		}
 
-	// Success
-	err = tCore.SetWalletPassword(tPW, assetID, newPW)
-	if err != nil {
-		t.Fatalf("SetWalletPassword error: %v", err)
-	}
+		if len(trade.coins) != expCoinsLoaded {
+			t.Fatalf("%s: expected %d coin loaded, got %d", description, expCoinsLoaded, len(trade.coins))
+		}
 
-	// Check that the xcWallet was updated.
-	decNewPW, _ := rig.crypter.Decrypt(xyzWallet.encPW())
-	if !bytes.Equal(decNewPW, newPW) {
-		t.Fatalf("xcWallet encPW field not updated")
-	}
-}
+		if found && expReadyToTick != trade.readyToTick {
+			t.Fatalf("%s: expected ready to tick = %v, but got %v", description, expReadyToTick, trade.readyToTick)
+		}
+		if !expReadyToTick {
+			return
+		}
 
-func TestHandlePenaltyMsg(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-	dc := rig.dc
-	penalty := &msgjson.Penalty{
-		Rule:    account.Rule(1),
-		Time:    uint64(1598929305),
-		Details: "You may no longer trade. Leave your client running to finish pending trades.",
-	}
-	diffKey, _ := secp256k1.GeneratePrivateKey()
-	noMatch, err := msgjson.NewNotification(msgjson.NoMatchRoute, "fake")
-	if err != nil {
-		t.Fatal(err)
-	}
-	tests := []struct {
-		name    string
-		key     *secp256k1.PrivateKey
-		payload any
-		wantErr bool
-	}{{
-		name:    "ok",
-		key:     tDexPriv,
-		payload: penalty,
-	}, {
-		name:    "bad note",
-		key:     tDexPriv,
-		payload: noMatch,
-		wantErr: true,
-	}, {
-		name:    "wrong sig",
-		key:     diffKey,
-		payload: penalty,
-		wantErr: true,
-	}}
-	for _, test := range tests {
-		var err error
-		var note *msgjson.Message
-		switch v := test.payload.(type) {
-		case *msgjson.Penalty:
-			penaltyNote := &msgjson.PenaltyNote{
-				Penalty: v,
+		if lo.T.Sell && ((match.Side == order.Taker && match.Status < order.MatchComplete) ||
+			(match.Side == order.Taker && match.Status < order.MakerRedeemed)) {
+			var reReserveQty uint64 = redemptionReserves
+			if dbOrder.MetaData.Status > order.OrderStatusBooked {
+				reReserveQty = applyFraction(match.Quantity, qty, redemptionReserves)
 			}
-			sign(test.key, penaltyNote)
-			note, err = msgjson.NewNotification(msgjson.PenaltyRoute, penaltyNote)
-			if err != nil {
-				t.Fatalf("error creating penalty notification: %v", err)
+
+			if tEthWallet.reservedRedemption != reReserveQty {
+				t.Fatalf("%s: redemption funds not reserved, %d != %d", description, tEthWallet.reservedRedemption, reReserveQty)
 			}
-		case *msgjson.Message:
-			note = v
-		default:
-			t.Fatalf("unknown payload type: %T", v)
 		}
 
-		err = handlePenaltyMsg(tCore, dc, note)
-		if test.wantErr {
-			if err == nil {
-				t.Fatalf("expected error for test %s", test.name)
+		if !lo.T.Sell && match.Status < order.MakerRedeemed {
+			var reRefundQty uint64 = refundReserves
+			if dbOrder.MetaData.Status > order.OrderStatusBooked {
+				reRefundQty = applyFraction(match.Quantity, qty, refundReserves)
+			}
+
+			if tEthWallet.reservedRefund != reRefundQty {
+				t.Fatalf("%s: refund funds not reserved, %d != %d", description, tEthWallet.reservedRefund, reRefundQty)
 			}
-			continue
-		}
-		if err != nil {
-			t.Fatalf("%s: unexpected error: %v", test.name, err)
 		}
-	}
-}
 
-func TestPreimageSync(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	dcrWallet.Unlock(rig.crypter)
+	}
 
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	btcWallet.Unlock(rig.crypter)
+	runTest("initial", true, true, true, true, 1)
 
-	var lots uint64 = 10
-	qty := dcrBtcLotSize * lots
-	rate := dcrBtcRateStep * 1000
+	// No base wallet. Trade will not be in the map.
+	delete(tCore.wallets, utxoAsset.ID)
+	runTest("no base wallet", false, false, false, false, 0)
+	tCore.wallets[utxoAsset.ID] = btcWallet
 
-	form := &TradeForm{
-		Host:    tDexHost,
-		IsLimit: true,
-		Sell:    true,
-		Base:    tUTXOAssetA.ID,
-		Quote:   tUTXOAssetB.ID,
-		Qty:     qty,
-		Rate:    rate,
-		TifNow:  false,
-	}
+	// Base wallet unlock errors. Trade will be in map, but it will not be
+	// ready to tick.
+	tBtcWallet.unlockErr = tErr
+	tBtcWallet.locked = true
+	runTest("base unlock", true, false, false, false, 0)
+	tBtcWallet.unlockErr = nil
+	tBtcWallet.locked = false
 
-	dcrCoin := &tCoin{
-		id:  encode.RandomBytes(36),
-		val: qty * 2,
-	}
-	tDcrWallet.fundingCoins = asset.Coins{dcrCoin}
-	tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
+	// No quote wallet. Trade will not be in the map.
+	delete(tCore.wallets, acctAsset.ID)
+	runTest("missing quote", false, false, false, false, 0)
+	tCore.wallets[acctAsset.ID] = ethWallet
 
-	btcVal := calc.BaseToQuote(rate, qty*2)
-	btcCoin := &tCoin{
-		id:  encode.RandomBytes(36),
-		val: btcVal,
-	}
-	tBtcWallet.fundingCoins = asset.Coins{btcCoin}
-	tBtcWallet.fundRedeemScripts = []dex.Bytes{nil}
+	// Quote wallet unlock errors. Trade will be in map, but it will not be
+	// ready to tick.
+	tEthWallet.unlockErr = tErr
+	tEthWallet.locked = true
+	runTest("quote unlock", true, false, true, false, 0)
+	tEthWallet.unlockErr = nil
+	tEthWallet.locked = false
 
-	limitRouteProcessing := make(chan order.OrderID)
-	var commit order.Commitment
+	// Funding coin error still puts it in the trades map, and sets ready to tick,
+	// just with no coins locked.
+	tBtcWallet.fundingCoinErr = tErr
+	runTest("funding coin", true, true, true, true, 0)
+	tBtcWallet.fundingCoinErr = nil
 
-	rig.ws.queueResponse(msgjson.LimitRoute, func(msg *msgjson.Message, f msgFunc) error {
-		t.Helper()
-		// Need to stamp and sign the message with the server's key.
-		msgOrder := new(msgjson.LimitOrder)
-		err := msg.Unmarshal(msgOrder)
-		if err != nil {
-			return fmt.Errorf("unmarshal error: %w", err)
+	// No matches
+	rig.db.activeMatchOIDSErr = tErr
+	runTest("matches error", false, false, false, false, 0)
+	rig.db.activeMatchOIDSErr = nil
+
+	for _, tt := range reservationTests {
+		lo.T.Sell = tt.sell
+		if tt.sell {
+			dbOrder.MetaData.RefundReserves = 0
+			dbOrder.MetaData.RedemptionReserves = redemptionReserves
+		} else {
+			dbOrder.MetaData.RefundReserves = refundReserves
+			dbOrder.MetaData.RedemptionReserves = 0
 		}
-		lo := convertMsgLimitOrder(msgOrder)
-		resp := orderResponse(msg.ID, msgOrder, lo, false, false, false)
-		limitRouteProcessing <- lo.ID()
-		commit = lo.Commit // accessed below only after errChan receive indicating Trade done
-		f(resp)            // e.g. the UnmarshalJSON in sendRequest
-		return nil
-	})
+		for _, side := range tt.side {
+			match.Side = side
+			for _, orderStatus := range tt.orderStatuses {
+				dbOrder.MetaData.Status = orderStatus
+				for _, matchStatus := range tt.matchStatuses {
+					match.Status = matchStatus
+					runTest(tt.name, true, true, true, true, tt.expectedCoins)
+				}
+			}
+		}
+	}
+}
 
-	errChan := make(chan error, 1)
-	// Run the trade in a goroutine.
-	go func() {
-		_, err := tCore.Trade(tPW, form)
-		errChan <- err
-	}()
+func TestReReserveFunding(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-	// Wait for the limit route to start processing. Then we have 100 ms to call
-	// handlePreimageRequest to catch the early-preimage case.
-	var oid order.OrderID
-	select {
-	case oid = <-limitRouteProcessing:
-	case <-time.After(time.Second):
-		t.Fatalf("limit route never hit")
+	auth(rig.acct) // Short path through initializeDEXConnections
+
+	utxoAsset /* base */, acctAsset /* quote */ := tUTXOAssetB, tACCTAsset
+
+	btcWallet, tBtcWallet := newTWallet(utxoAsset.ID)
+	tCore.wallets[utxoAsset.ID] = btcWallet
+
+	ethWallet, tEthWallet := newTAccountLocker(acctAsset.ID)
+	tCore.wallets[acctAsset.ID] = ethWallet
+
+	// Create an order
+	qty, lo, dbOrder, match, changeCoin := generateMatch(rig, utxoAsset.ID, acctAsset.ID)
+	redemptionReserves, refundReserves := dbOrder.MetaData.RedemptionReserves, dbOrder.MetaData.RefundReserves
+
+	tBtcWallet.fundingCoins = asset.Coins{changeCoin}
+	tEthWallet.fundingCoins = asset.Coins{changeCoin}
+
+	oid := lo.ID()
+
+	tracker := &trackedTrade{
+		Order:    lo,
+		dc:       rig.dc,
+		metaData: dbOrder.MetaData,
+		matches: map[order.MatchID]*matchTracker{
+			match.MatchID: {
+				MetaMatch: db.MetaMatch{
+					MetaData: &db.MatchMetaData{},
+					UserMatch: &order.UserMatch{
+						OrderID:  lo.ID(),
+						MatchID:  match.MatchID,
+						Status:   order.NewlyMatched,
+						Side:     order.Maker,
+						Quantity: match.Quantity,
+					},
+				},
+				prefix: lo.Prefix(),
+				trade:  lo.Trade(),
+				// counterConfirms: -1,
+			},
+		},
+		coins:              map[string]asset.Coin{"changecoinid": changeCoin},
+		redemptionReserves: redemptionReserves,
+		refundReserves:     refundReserves,
 	}
 
-	err := <-errChan
-	if err != nil {
-		t.Fatalf("trade error: %v", err)
+	rig.dc.trades = map[order.OrderID]*trackedTrade{
+		oid: tracker,
 	}
 
-	// So ideally, we're calling handlePreimageRequest about 100 ms before we
-	// even have an order id back from the server. This shouldn't result in an
-	// error.
-	payload := &msgjson.PreimageRequest{
-		OrderID:    oid[:],
-		Commitment: commit[:],
+	// reset
+	reset := func() {
+		tEthWallet.reservedRedemption = 0
+		tEthWallet.reservedRefund = 0
+		tracker.redemptionLocked = 0
+		tracker.refundLocked = 0
 	}
-	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
-	err = handlePreimageRequest(rig.core, rig.dc, req)
-	if err != nil {
-		t.Fatalf("early preimage request error: %v", err)
+
+	run := func(tag string) {
+		t.Helper()
+		description := fmt.Sprintf("%s: side = %s, order status = %s, match status = %s",
+			tag, match.Side, dbOrder.MetaData.Status, match.Status)
+
+		tCore.reReserveFunding(btcWallet)
+		tCore.reReserveFunding(ethWallet)
+
+		if lo.T.Sell && ((match.Side == order.Taker && match.Status < order.MatchComplete) ||
+			(match.Side == order.Taker && match.Status < order.MakerRedeemed)) {
+			var reReserveQty uint64 = redemptionReserves
+			if dbOrder.MetaData.Status > order.OrderStatusBooked {
+				reReserveQty = applyFraction(match.Quantity, qty, redemptionReserves)
+			}
+
+			if tEthWallet.reservedRedemption != reReserveQty {
+				t.Fatalf("%s: redemption funds not reserved, %d != %d", description, tEthWallet.reservedRedemption, reReserveQty)
+			}
+		}
+
+		if !lo.T.Sell && match.Status < order.MakerRedeemed {
+			var reRefundQty uint64 = refundReserves
+			if dbOrder.MetaData.Status > order.OrderStatusBooked {
+				reRefundQty = applyFraction(match.Quantity, qty, refundReserves)
+			}
+
+			if tEthWallet.reservedRefund != reRefundQty {
+				t.Fatalf("%s: refund funds not reserved, %d != %d", description, tEthWallet.reservedRefund, reRefundQty)
+			}
+		}
+
+		reset()
+	}
+
+	for _, tt := range reservationTests {
+
+		lo.T.Sell = tt.sell
+		tracker.wallets, _, _, _ = tCore.walletSet(rig.dc, utxoAsset.ID, acctAsset.ID, tt.sell)
+		if tt.sell {
+			dbOrder.MetaData.RefundReserves = 0
+			dbOrder.MetaData.RedemptionReserves = redemptionReserves
+		} else {
+			dbOrder.MetaData.RefundReserves = refundReserves
+			dbOrder.MetaData.RedemptionReserves = 0
+		}
+		for _, side := range tt.side {
+			match.Side = side
+			for _, orderStatus := range tt.orderStatuses {
+				dbOrder.MetaData.Status = orderStatus
+				for _, matchStatus := range tt.matchStatuses {
+					match.Status = matchStatus
+					run(tt.name)
+				}
+			}
+		}
 	}
+
 }
 
-func TestAccelerateOrder(t *testing.T) {
+func TestCompareServerMatches(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	tCore := rig.core
+	preImg := newPreimage()
 	dc := rig.dc
 
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	tDcrWallet.swapSize = tSwapSizeA
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tBtcWallet.swapSize = tSwapSizeB
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-
-	buyWalletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, false)
-	sellWalletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, false)
+	notes := make(map[string][]Notification)
+	notify := func(note Notification) {
+		notes[note.Type()] = append(notes[note.Type()], note)
+	}
 
-	var newBaseFeeRate uint64 = 55
-	var newQuoteFeeRate uint64 = 65
-	feeRateSource := func(msg *msgjson.Message, f msgFunc) error {
-		var resp *msgjson.Message
-		if string(msg.Payload) == "42" {
-			resp, _ = msgjson.NewResponse(msg.ID, newBaseFeeRate, nil)
-		} else {
-			resp, _ = msgjson.NewResponse(msg.ID, newQuoteFeeRate, nil)
-		}
-		f(resp)
-		return nil
+	lo := &order.LimitOrder{
+		P: order.Prefix{
+			// 	OrderType:  order.LimitOrderType,
+			// 	BaseAsset:  tUTXOAssetA.ID,
+			// 	QuoteAsset: tUTXOAssetB.ID,
+			// 	ClientTime: time.Now(),
+			ServerTime: time.Now(),
+			// 	Commit:     preImg.Commit(),
+		},
 	}
+	oid := lo.ID()
+	dbOrder := &db.MetaOrder{
+		MetaData: &db.OrderMetaData{},
+		Order:    lo,
+	}
+	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, nil, nil, rig.core.notify, rig.core.formatDetails)
 
-	type testMatch struct {
-		status   order.MatchStatus
-		quantity uint64
-		rate     uint64
-		side     order.MatchSide
+	// Known trade, and known match
+	knownID := ordertest.RandomMatchID()
+	knownMatch := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{MatchID: knownID},
+			MetaData:  &db.MatchMetaData{},
+		},
+		counterConfirms: -1,
 	}
+	tracker.matches[knownID] = knownMatch
+	knownMsgMatch := &msgjson.Match{OrderID: oid[:], MatchID: knownID[:]}
 
-	tests := []struct {
-		name                       string
-		orderQuantity              uint64
-		orderFilled                uint64
-		orderStatus                order.OrderStatus
-		rate                       uint64
-		sell                       bool
-		previousAccelerations      []order.CoinID
-		matches                    []testMatch
-		expectRequiredForRemaining uint64
-		expectError                bool
-		orderIDIncorrectLength     bool
-		nonActiveOrderID           bool
-		accelerateOrderError       bool
-		nilChangeCoin              bool
-		nilNewChangeCoin           bool
-	}{
-		{
-			name:                       "ok",
-			orderQuantity:              3 * dcrBtcLotSize,
-			orderFilled:                dcrBtcLotSize,
-			previousAccelerations:      []order.CoinID{encode.RandomBytes(32)},
-			orderStatus:                order.OrderStatusExecuted,
-			rate:                       dcrBtcRateStep * 10,
-			expectRequiredForRemaining: 2*tMaxFeeRate*tSwapSizeB + calc.BaseToQuote(dcrBtcRateStep*10, 2*dcrBtcLotSize),
-			matches: []testMatch{
-				{
-					side:     order.Maker,
-					status:   order.TakerSwapCast,
-					quantity: dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-			},
-		},
-		{
-			name:                       "ok - unswapped match, buy",
-			orderQuantity:              8 * dcrBtcLotSize,
-			orderFilled:                5 * dcrBtcLotSize,
-			orderStatus:                order.OrderStatusExecuted,
-			previousAccelerations:      []order.CoinID{encode.RandomBytes(32), encode.RandomBytes(32)},
-			rate:                       dcrBtcRateStep * 10,
-			expectRequiredForRemaining: 4*tMaxFeeRate*tSwapSizeB + calc.BaseToQuote(dcrBtcRateStep*10, 5*dcrBtcLotSize),
-			matches: []testMatch{
-				{
-					side:     order.Maker,
-					status:   order.TakerSwapCast,
-					quantity: dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-				{
-					side:     order.Taker,
-					status:   order.TakerSwapCast,
-					quantity: 2 * dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-				{
-					side:     order.Taker,
-					status:   order.MakerSwapCast,
-					quantity: 2 * dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-			},
-		},
-		{
-			name:                       "ok - unswapped match, sell",
-			sell:                       true,
-			previousAccelerations:      []order.CoinID{encode.RandomBytes(32), encode.RandomBytes(32)},
-			orderQuantity:              8 * dcrBtcLotSize,
-			orderFilled:                5 * dcrBtcLotSize,
-			orderStatus:                order.OrderStatusExecuted,
-			rate:                       dcrBtcRateStep * 10,
-			expectRequiredForRemaining: 4*tMaxFeeRate*tSwapSizeB + 5*dcrBtcLotSize,
-			matches: []testMatch{
-				{
-					side:     order.Maker,
-					status:   order.TakerSwapCast,
-					quantity: dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-				{
-					side:     order.Taker,
-					status:   order.TakerSwapCast,
-					quantity: 2 * dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-				{
-					side:     order.Taker,
-					status:   order.MakerSwapCast,
-					quantity: 2 * dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-			},
-		},
-		{
-			name: "10 previous accelerations",
-			sell: true,
-			previousAccelerations: []order.CoinID{encode.RandomBytes(32), encode.RandomBytes(32),
-				encode.RandomBytes(32), encode.RandomBytes(32),
-				encode.RandomBytes(32), encode.RandomBytes(32),
-				encode.RandomBytes(32), encode.RandomBytes(32),
-				encode.RandomBytes(32), encode.RandomBytes(32)},
-			orderQuantity: 8 * dcrBtcLotSize,
-			orderFilled:   5 * dcrBtcLotSize,
-			orderStatus:   order.OrderStatusExecuted,
-			rate:          dcrBtcRateStep * 10,
-			matches: []testMatch{
-				{
-					side:     order.Maker,
-					status:   order.TakerSwapCast,
-					quantity: dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-			},
-			expectError: true,
-		},
-		{
-			name:          "no matches",
-			orderQuantity: 3 * dcrBtcLotSize,
-			orderFilled:   dcrBtcLotSize,
-			orderStatus:   order.OrderStatusExecuted,
-			rate:          dcrBtcRateStep * 10,
-			matches:       []testMatch{},
-			expectError:   true,
-		},
-		{
-			name:          "no swap coins",
-			orderQuantity: 3 * dcrBtcLotSize,
-			orderFilled:   dcrBtcLotSize,
-			orderStatus:   order.OrderStatusExecuted,
-			rate:          dcrBtcRateStep * 10,
-			matches: []testMatch{{
-				side:     order.Taker,
-				status:   order.MakerSwapCast,
-				quantity: 2 * dcrBtcLotSize,
-				rate:     dcrBtcRateStep * 10,
-			}},
-			expectError: true,
-		},
-		{
-			name:          "incorrect length order id",
-			orderQuantity: 3 * dcrBtcLotSize,
-			orderFilled:   dcrBtcLotSize,
-			orderStatus:   order.OrderStatusExecuted,
-			rate:          dcrBtcRateStep * 10,
-			matches: []testMatch{
-				{
-					side:     order.Maker,
-					status:   order.TakerSwapCast,
-					quantity: dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-			},
-			orderIDIncorrectLength: true,
-			expectError:            true,
-		},
-		{
-			name:          "incorrect length order id",
-			orderQuantity: 3 * dcrBtcLotSize,
-			orderFilled:   dcrBtcLotSize,
-			orderStatus:   order.OrderStatusExecuted,
-			rate:          dcrBtcRateStep * 10,
-			matches: []testMatch{
-				{
-					side:     order.Maker,
-					status:   order.TakerSwapCast,
-					quantity: dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-			},
-			nonActiveOrderID: true,
-			expectError:      true,
+	// Known trade, but missing match
+	missingID := ordertest.RandomMatchID()
+	missingMatch := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{MatchID: missingID},
+			MetaData:  &db.MatchMetaData{},
 		},
-		{
-			name:          "accelerate order err",
-			orderQuantity: 3 * dcrBtcLotSize,
-			orderFilled:   dcrBtcLotSize,
-			orderStatus:   order.OrderStatusExecuted,
-			rate:          dcrBtcRateStep * 10,
-			matches: []testMatch{
-				{
-					side:     order.Maker,
-					status:   order.TakerSwapCast,
-					quantity: dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
-			},
-			accelerateOrderError: true,
-			expectError:          true,
+	}
+	tracker.matches[missingID] = missingMatch
+
+	// extra match
+	extraID := ordertest.RandomMatchID()
+	extraMsgMatch := &msgjson.Match{OrderID: oid[:], MatchID: extraID[:]}
+
+	// Entirely missing order
+	loMissing, dbOrderMissing, preImgMissing, _ := makeLimitOrder(dc, true, 3*dcrBtcLotSize, dcrBtcRateStep*10)
+	trackerMissing := newTrackedTrade(dbOrderMissing, preImgMissing, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, nil, nil, notify, rig.core.formatDetails)
+	oidMissing := loMissing.ID()
+	// an active match for the missing trade
+	matchIDMissing := ordertest.RandomMatchID()
+	missingTradeMatch := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{MatchID: matchIDMissing},
+			MetaData:  &db.MatchMetaData{},
 		},
-		{
-			name:          "nil change coin",
-			orderQuantity: 3 * dcrBtcLotSize,
-			orderFilled:   dcrBtcLotSize,
-			orderStatus:   order.OrderStatusExecuted,
-			rate:          dcrBtcRateStep * 10,
-			matches: []testMatch{
-				{
-					side:     order.Maker,
-					status:   order.TakerSwapCast,
-					quantity: dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
-				},
+		counterConfirms: -1,
+	}
+	trackerMissing.matches[knownID] = missingTradeMatch
+	// an inactive match for the missing trade
+	matchIDMissingInactive := ordertest.RandomMatchID()
+	missingTradeMatchInactive := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{
+				MatchID: matchIDMissingInactive,
+				Status:  order.MatchComplete,
 			},
-			nilChangeCoin: true,
-			expectError:   true,
-		},
-		{
-			name:                       "nil new change coin",
-			orderQuantity:              3 * dcrBtcLotSize,
-			orderFilled:                dcrBtcLotSize,
-			orderStatus:                order.OrderStatusExecuted,
-			rate:                       dcrBtcRateStep * 10,
-			expectRequiredForRemaining: 2*tMaxFeeRate*tSwapSizeB + calc.BaseToQuote(dcrBtcRateStep*10, 2*dcrBtcLotSize),
-			matches: []testMatch{
-				{
-					side:     order.Maker,
-					status:   order.TakerSwapCast,
-					quantity: dcrBtcLotSize,
-					rate:     dcrBtcRateStep * 10,
+			MetaData: &db.MatchMetaData{
+				Proof: db.MatchProof{
+					Auth: db.MatchAuth{
+						RedeemSig: []byte{1, 2, 3}, // won't be considered complete with out it
+					},
 				},
 			},
-			nilNewChangeCoin: true,
 		},
+		counterConfirms: 1,
 	}
+	trackerMissing.matches[matchIDMissingInactive] = missingTradeMatchInactive
 
-	for _, test := range tests {
-		tBtcWallet.accelerateOrderErr = nil
-		lo, dbOrder, preImg, addr := makeLimitOrder(dc, test.sell, test.orderQuantity, test.rate)
-		dbOrder.MetaData.Status = test.orderStatus // so there is no order_status request for this
-		oid := lo.ID()
-		var walletSet *walletSet
-		if test.sell {
-			walletSet = sellWalletSet
-		} else {
-			walletSet = buyWalletSet
-		}
-		trade := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-			rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
-		dc.trades[trade.ID()] = trade
-		trade.Trade().AddFill(test.orderFilled)
-
-		trade.metaData.ChangeCoin = encode.RandomBytes(32)
-		originalChangeCoin := trade.metaData.ChangeCoin
-		trade.metaData.AccelerationCoins = test.previousAccelerations
-		newChangeCoinID := dex.Bytes(encode.RandomBytes(32))
-		if test.nilNewChangeCoin {
-			tBtcWallet.newChangeCoinID = nil
-		} else {
-			tBtcWallet.newChangeCoinID = &newChangeCoinID
-		}
-		tBtcWallet.newAccelerationTxID = hex.EncodeToString(encode.RandomBytes(32))
-		trade.matches = make(map[order.MatchID]*matchTracker)
-		expectedSwapCoins := make([]order.CoinID, 0, len(test.matches))
-		for _, testMatch := range test.matches {
-			matchID := ordertest.RandomMatchID()
-			match := &matchTracker{
-				MetaMatch: db.MetaMatch{
-					MetaData: &db.MatchMetaData{
-						Proof: db.MatchProof{
-							MakerSwap: encode.RandomBytes(32),
-							TakerSwap: encode.RandomBytes(32),
-						},
-					},
-					UserMatch: &order.UserMatch{
-						MatchID:  matchID,
-						Address:  addr,
-						Side:     testMatch.side,
-						Status:   testMatch.status,
-						Quantity: testMatch.quantity,
-						Rate:     testMatch.rate,
-					},
-				},
-			}
-			if testMatch.side == order.Maker && testMatch.status >= order.MakerSwapCast {
-				expectedSwapCoins = append(expectedSwapCoins, match.MetaData.Proof.MakerSwap)
-			}
-			if testMatch.side == order.Taker && testMatch.status >= order.TakerSwapCast {
-				expectedSwapCoins = append(expectedSwapCoins, match.MetaData.Proof.TakerSwap)
-			}
-			trade.matches[matchID] = match
-		}
-		orderIDBytes := oid.Bytes()
-		if test.orderIDIncorrectLength {
-			orderIDBytes = encode.RandomBytes(31)
-		}
-		if test.nonActiveOrderID {
-			orderIDBytes = encode.RandomBytes(32)
-		}
-		if test.accelerateOrderError {
-			tBtcWallet.accelerateOrderErr = errors.New("")
-		}
-		if test.nilChangeCoin {
-			trade.metaData.ChangeCoin = nil
-		}
-
-		checkCommonCallValues := func() {
-			t.Helper()
-			swapCoins := tBtcWallet.accelerationParams.swapCoins
-			if len(swapCoins) != len(expectedSwapCoins) {
-				t.Fatalf("expected %d swap coins but got %d", len(expectedSwapCoins), len(swapCoins))
-			}
+	srvMatches := map[order.OrderID]*serverMatches{
+		oid: {
+			tracker:    tracker,
+			msgMatches: []*msgjson.Match{knownMsgMatch, extraMsgMatch},
+		},
+		// oidMissing not included (missing!)
+	}
 
-			sort.Slice(swapCoins, func(i, j int) bool { return bytes.Compare(swapCoins[i], swapCoins[j]) > 0 })
-			sort.Slice(expectedSwapCoins, func(i, j int) bool { return bytes.Compare(expectedSwapCoins[i], expectedSwapCoins[j]) > 0 })
+	dc.trades = map[order.OrderID]*trackedTrade{
+		oid:        tracker,
+		oidMissing: trackerMissing,
+	}
 
-			for i := range swapCoins {
-				if !bytes.Equal(swapCoins[i], expectedSwapCoins[i]) {
-					t.Fatalf("expected swap coins not the same as actual")
-				}
-			}
+	exceptions, _ := dc.compareServerMatches(srvMatches)
+	if len(exceptions) != 2 {
+		t.Fatalf("exceptions did not include both trades, just %d", len(exceptions))
+	}
 
-			changeCoin := tBtcWallet.accelerationParams.changeCoin
-			if !bytes.Equal(changeCoin, originalChangeCoin) {
-				t.Fatalf("change coin not same as expected %x - %x", changeCoin, trade.metaData.ChangeCoin)
-			}
+	exc, ok := exceptions[oid]
+	if !ok {
+		t.Fatalf("exceptions did not include trade %v", oid)
+	}
+	if exc.trade.ID() != oid {
+		t.Fatalf("wrong trade ID, got %v, want %v", exc.trade.ID(), oid)
+	}
+	if len(exc.missing) != 1 {
+		t.Fatalf("found %d missing matches for trade %v, expected 1", len(exc.missing), oid)
+	}
+	if exc.missing[0].MatchID != missingID {
+		t.Fatalf("wrong missing match, got %v, expected %v", exc.missing[0].MatchID, missingID)
+	}
+	if len(exc.extra) != 1 {
+		t.Fatalf("found %d extra matches for trade %v, expected 1", len(exc.extra), oid)
+	}
+	if !bytes.Equal(exc.extra[0].MatchID, extraID[:]) {
+		t.Fatalf("wrong extra match, got %v, expected %v", exc.extra[0].MatchID, extraID)
+	}
 
-			accelerationCoins := tBtcWallet.accelerationParams.accelerationCoins
-			if len(accelerationCoins) != len(test.previousAccelerations) {
-				t.Fatalf("expected 1 acceleration tx but got %v", len(accelerationCoins))
-			}
-			for i := range accelerationCoins {
-				if !bytes.Equal(accelerationCoins[i], test.previousAccelerations[i]) {
-					t.Fatalf("expected acceleration coin not the same as actual")
-				}
-			}
-		}
+	exc, ok = exceptions[oidMissing]
+	if !ok {
+		t.Fatalf("exceptions did not include trade %v", oidMissing)
+	}
+	if exc.trade.ID() != oidMissing {
+		t.Fatalf("wrong trade ID, got %v, want %v", exc.trade.ID(), oidMissing)
+	}
+	if len(exc.missing) != 1 { // no matchIDMissingInactive
+		t.Fatalf("found %d missing matches for trade %v, expected 1", len(exc.missing), oid)
+	}
+	if exc.missing[0].MatchID != matchIDMissing {
+		t.Fatalf("wrong missing match, got %v, expected %v", exc.missing[0].MatchID, matchIDMissing)
+	}
+	if len(exc.extra) != 0 {
+		t.Fatalf("found %d extra matches for trade %v, expected 0", len(exc.extra), oid)
+	}
+}
 
-		checkRequiredForRemainingSwaps := func() {
-			t.Helper()
-			if tBtcWallet.accelerationParams.requiredForRemainingSwaps != test.expectRequiredForRemaining {
-				t.Fatalf("expected requiredForRemainingSwaps %d, but got %d", test.expectRequiredForRemaining,
-					tBtcWallet.accelerationParams.requiredForRemainingSwaps)
-			}
-		}
+func convertMsgLimitOrder(msgOrder *msgjson.LimitOrder) *order.LimitOrder {
+	tif := order.ImmediateTiF
+	if msgOrder.TiF == msgjson.StandingOrderNum {
+		tif = order.StandingTiF
+	}
+	return &order.LimitOrder{
+		P:     convertMsgPrefix(&msgOrder.Prefix, order.LimitOrderType),
+		T:     convertMsgTrade(&msgOrder.Trade),
+		Rate:  msgOrder.Rate,
+		Force: tif,
+	}
+}
 
-		testAccelerateOrder := func() {
-			newFeeRate := rand.Uint64()
-			txID, err := tCore.AccelerateOrder(tPW, orderIDBytes, newFeeRate)
-			if test.expectError {
-				if err == nil {
-					t.Fatalf("expected error, but did not get")
-				}
-				return
-			}
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
-			}
+func convertMsgMarketOrder(msgOrder *msgjson.MarketOrder) *order.MarketOrder {
+	return &order.MarketOrder{
+		P: convertMsgPrefix(&msgOrder.Prefix, order.MarketOrderType),
+		T: convertMsgTrade(&msgOrder.Trade),
+	}
+}
 
-			checkCommonCallValues()
-			checkRequiredForRemainingSwaps()
+func convertMsgCancelOrder(msgOrder *msgjson.CancelOrder) *order.CancelOrder {
+	var oid order.OrderID
+	copy(oid[:], msgOrder.TargetID)
+	return &order.CancelOrder{
+		P:             convertMsgPrefix(&msgOrder.Prefix, order.CancelOrderType),
+		TargetOrderID: oid,
+	}
+}
 
-			if test.nilNewChangeCoin {
-				if tBtcWallet.newChangeCoinID != nil {
-					t.Fatalf("expected coin on order to be nil, but got %x", tBtcWallet.newChangeCoinID)
-				}
-			} else {
-				if !bytes.Equal(trade.metaData.ChangeCoin, *tBtcWallet.newChangeCoinID) {
-					t.Fatalf("change coin on trade was not updated to return value from AccelerateOrder")
-				}
-				if !bytes.Equal(trade.metaData.AccelerationCoins[len(trade.metaData.AccelerationCoins)-1], *tBtcWallet.newChangeCoinID) {
-					t.Fatalf("new acceleration transaction id was not added to the trade")
-				}
+func convertMsgPrefix(msgPrefix *msgjson.Prefix, oType order.OrderType) order.Prefix {
+	var commit order.Commitment
+	copy(commit[:], msgPrefix.Commit)
+	var acctID account.AccountID
+	copy(acctID[:], msgPrefix.AccountID)
+	return order.Prefix{
+		AccountID:  acctID,
+		BaseAsset:  msgPrefix.Base,
+		QuoteAsset: msgPrefix.Quote,
+		OrderType:  oType,
+		ClientTime: time.UnixMilli(int64(msgPrefix.ClientTime)),
+		//ServerTime set in epoch queue processing pipeline.
+		Commit: commit,
+	}
+}
 
-				var inCoinsList bool
-				for _, coin := range trade.coins {
-					if bytes.Equal(coin.ID(), *tBtcWallet.newChangeCoinID) {
-						inCoinsList = true
-					}
-				}
-				if !inCoinsList {
-					t.Fatalf("new change coin must be added to the trade.coins slice")
-				}
-			}
-			if txID != tBtcWallet.newAccelerationTxID {
-				t.Fatalf("new acceleration transaction id was not returned from AccelerateOrder")
-			}
-			if newFeeRate != tBtcWallet.accelerationParams.newFeeRate {
-				t.Fatalf("%s: expected new fee rate %d, but got %d", test.name,
-					newFeeRate, tBtcWallet.accelerationParams.newFeeRate)
-			}
-		}
+func convertMsgTrade(msgTrade *msgjson.Trade) order.Trade {
+	coins := make([]order.CoinID, 0, len(msgTrade.Coins))
+	for _, coin := range msgTrade.Coins {
+		var b []byte = coin.ID
+		coins = append(coins, b)
+	}
+	sell := true
+	if msgTrade.Side == msgjson.BuyOrderNum {
+		sell = false
+	}
+	return order.Trade{
+		Coins:    coins,
+		Sell:     sell,
+		Quantity: msgTrade.Quantity,
+		Address:  msgTrade.Address,
+	}
+}
 
-		testPreAccelerate := func() {
-			rig.ws.queueResponse(msgjson.FeeRateRoute, feeRateSource)
-			tBtcWallet.preAccelerateSwapRate = rand.Uint64()
-			tBtcWallet.preAccelerateSuggestedRange = asset.XYRange{
-				Start: asset.XYRangePoint{
-					Label: "startLabel",
-					X:     rand.Float64(),
-					Y:     rand.Float64(),
-				},
-				End: asset.XYRangePoint{
-					Label: "endLabel",
-					X:     rand.Float64(),
-					Y:     rand.Float64(),
-				},
-				XUnit: "x",
-				YUnit: "y",
-			}
+func orderResponse(msgID uint64, msgPrefix msgjson.Stampable, ord order.Order, badSig, noID, badID bool) *msgjson.Message {
+	orderTime := time.Now()
+	timeStamp := uint64(orderTime.UnixMilli())
+	msgPrefix.Stamp(timeStamp)
+	sign(tDexPriv, msgPrefix)
+	if badSig {
+		msgPrefix.SetSig(encode.RandomBytes(5))
+	}
+	ord.SetTime(orderTime)
+	oid := ord.ID()
+	oidB := oid[:]
+	if noID {
+		oidB = nil
+	} else if badID {
+		oidB = encode.RandomBytes(32)
+	}
+	resp, _ := msgjson.NewResponse(msgID, &msgjson.OrderResult{
+		Sig:        msgPrefix.SigBytes(),
+		OrderID:    oidB,
+		ServerTime: timeStamp,
+	}, nil)
+	return resp
+}
 
-			preAccelerate, err := tCore.PreAccelerateOrder(orderIDBytes)
-			if test.expectError {
-				if err == nil {
-					t.Fatalf("expected error, but did not get")
-				}
-				return
-			}
-			if err != nil {
-				t.Fatalf("%s: unexpected error: %v", test.name, err)
-			}
+func tMsgAudit(oid order.OrderID, mid order.MatchID, recipient string, val uint64, secretHash []byte) (*msgjson.Audit, *asset.AuditInfo) {
+	auditID := encode.RandomBytes(36)
+	auditContract := encode.RandomBytes(75)
+	if secretHash == nil {
+		secretHash = encode.RandomBytes(32)
+	}
+	auditStamp := uint64(time.Now().UnixMilli())
+	audit := &msgjson.Audit{
+		OrderID:  oid[:],
+		MatchID:  mid[:],
+		Time:     auditStamp,
+		CoinID:   auditID,
+		Contract: auditContract,
+	}
+	sign(tDexPriv, audit)
+	auditCoin := &tCoin{id: auditID, val: val}
+	auditInfo := &asset.AuditInfo{
+		Recipient:  recipient,
+		Coin:       auditCoin,
+		Contract:   auditContract,
+		SecretHash: secretHash,
+	}
+	return audit, auditInfo
+}
 
-			checkCommonCallValues()
-			checkRequiredForRemainingSwaps()
+func TestHandleEpochOrderMsg(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
+	oid := ord.ID()
+	payload := &msgjson.EpochOrderNote{
+		BookOrderNote: msgjson.BookOrderNote{
+			OrderNote: msgjson.OrderNote{
+				MarketID: tDcrBtcMktName,
+				OrderID:  oid.Bytes(),
+			},
+			TradeNote: msgjson.TradeNote{
+				Side:     msgjson.BuyOrderNum,
+				Rate:     4,
+				Quantity: 10,
+			},
+		},
+		Epoch: 1,
+	}
 
-			if !test.sell && preAccelerate.SuggestedRate != newQuoteFeeRate {
-				t.Fatalf("%s: expected fee suggestion to be %d, but got %d",
-					test.name, newQuoteFeeRate, preAccelerate.SuggestedRate)
-			}
-			if test.sell && preAccelerate.SuggestedRate != newBaseFeeRate {
-				t.Fatalf("%s: expected fee suggestion to be %d, but got %d",
-					test.name, newBaseFeeRate, preAccelerate.SuggestedRate)
-			}
-			if preAccelerate.SwapRate != tBtcWallet.preAccelerateSwapRate {
-				t.Fatalf("%s: expected pre accelerate swap rate %d, but got %d",
-					test.name, tBtcWallet.preAccelerateSwapRate, preAccelerate.SwapRate)
-			}
-			if !reflect.DeepEqual(preAccelerate.SuggestedRange,
-				tBtcWallet.preAccelerateSuggestedRange) {
-				t.Fatalf("%s: PreAccelerate suggested range not same as expected",
-					test.name)
-			}
-		}
+	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.EpochOrderRoute, payload)
 
-		testMaxAcceleration := func() {
-			t.Helper()
-			tBtcWallet.accelerationEstimate = rand.Uint64()
-			newFeeRate := rand.Uint64()
-			estimate, err := tCore.AccelerationEstimate(orderIDBytes, newFeeRate)
-			if test.expectError {
-				if err == nil {
-					t.Fatalf("expected error, but did not get")
-				}
-				return
-			}
-			if err != nil {
-				t.Fatalf("%s: unexpected error: %v", test.name, err)
-			}
+	// Ensure handling an epoch order associated with a non-existent orderbook
+	// generates an error.
+	err := handleEpochOrderMsg(rig.core, rig.dc, req)
+	if err == nil {
+		t.Fatal("[handleEpochOrderMsg] expected a non-existent orderbook error")
+	}
 
-			checkCommonCallValues()
-			checkRequiredForRemainingSwaps()
+	rig.dc.books[tDcrBtcMktName] = newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
 
-			if newFeeRate != tBtcWallet.accelerationParams.newFeeRate {
-				t.Fatalf("%s: expected new fee rate %d, but got %d", test.name,
-					newFeeRate, tBtcWallet.accelerationParams.newFeeRate)
-			}
-			if estimate != tBtcWallet.accelerationEstimate {
-				t.Fatalf("%s: expected acceleration estimate %d, but got %d",
-					test.name, tBtcWallet.accelerationEstimate, estimate)
-			}
-		}
+	err = handleEpochOrderMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("[handleEpochOrderMsg] unexpected error: %v", err)
+	}
+}
 
-		testPreAccelerate()
-		testMaxAcceleration()
-		testAccelerateOrder()
+func makeMatchProof(preimages []order.Preimage, commitments []order.Commitment) (msgjson.Bytes, msgjson.Bytes, error) {
+	if len(preimages) != len(commitments) {
+		return nil, nil, fmt.Errorf("expected equal number of preimages and commitments")
+	}
+
+	sbuff := make([]byte, 0, len(preimages)*order.PreimageSize)
+	cbuff := make([]byte, 0, len(commitments)*order.CommitmentSize)
+	for i := 0; i < len(preimages); i++ {
+		sbuff = append(sbuff, preimages[i][:]...)
+		cbuff = append(cbuff, commitments[i][:]...)
 	}
+	seed := blake256.Sum256(sbuff)
+	csum := blake256.Sum256(cbuff)
+	return seed[:], csum[:], nil
 }
 
-func TestMatchStatusResolution(t *testing.T) {
+func TestHandleMatchProofMsg(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	tCore := rig.core
-	dc := rig.dc
-
-	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	pimg := newPreimage()
+	cmt := pimg.Commit()
 
-	qty := 3 * dcrBtcLotSize
-	secret := encode.RandomBytes(32)
-	secretHash := sha256.Sum256(secret)
+	seed, csum, err := makeMatchProof([]order.Preimage{pimg}, []order.Commitment{cmt})
+	if err != nil {
+		t.Fatalf("[makeMatchProof] unexpected error: %v", err)
+	}
 
-	lo, dbOrder, preImg, addr := makeLimitOrder(dc, true, qty, dcrBtcRateStep*10)
-	dbOrder.MetaData.Status = order.OrderStatusExecuted // so there is no order_status request for this
-	oid := lo.ID()
-	trade := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+	payload := &msgjson.MatchProofNote{
+		MarketID:  tDcrBtcMktName,
+		Epoch:     1,
+		Preimages: []dex.Bytes{pimg[:]},
+		CSum:      csum[:],
+		Seed:      seed[:],
+	}
 
-	dc.trades[trade.ID()] = trade
-	matchID := ordertest.RandomMatchID()
-	matchTime := time.Now()
-	match := &matchTracker{
-		MetaMatch: db.MetaMatch{
-			MetaData: &db.MatchMetaData{},
-			UserMatch: &order.UserMatch{
-				MatchID: matchID,
-				Address: addr,
+	eo := &msgjson.EpochOrderNote{
+		BookOrderNote: msgjson.BookOrderNote{
+			OrderNote: msgjson.OrderNote{
+				MarketID: tDcrBtcMktName,
+				OrderID:  encode.RandomBytes(order.OrderIDSize),
 			},
 		},
+		Epoch:  1,
+		Commit: cmt[:],
 	}
-	trade.matches[matchID] = match
 
-	// oid order.OrderID, mid order.MatchID, recipient string, val uint64, secretHash []byte
-	_, auditInfo := tMsgAudit(oid, matchID, addr, qty, secretHash[:])
-	tBtcWallet.auditInfo = auditInfo
+	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.MatchProofRoute, payload)
 
-	connectMatches := func(status order.MatchStatus) []*msgjson.Match {
-		return []*msgjson.Match{
-			{
-				OrderID: oid[:],
-				MatchID: matchID[:],
-				Status:  uint8(status),
-				Side:    uint8(match.Side),
-			},
-		}
+	// Ensure match proof validation generates an error for a non-existent orderbook.
+	err = handleMatchProofMsg(rig.core, rig.dc, req)
+	if err == nil {
+		t.Fatal("[handleMatchProofMsg] expected a non-existent orderbook error")
+	}
+
+	rig.dc.books[tDcrBtcMktName] = newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
 
+	err = rig.dc.books[tDcrBtcMktName].Enqueue(eo)
+	if err != nil {
+		t.Fatalf("[Enqueue] unexpected error: %v", err)
 	}
 
-	tBytes := encode.RandomBytes(2)
-	tCoinID := encode.RandomBytes(36)
-	tTxData := encode.RandomBytes(1)
+	err = handleMatchProofMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("[handleMatchProofMsg] unexpected error: %v", err)
+	}
+}
 
-	setAuthSigs := func(status order.MatchStatus) {
-		isMaker := match.Side == order.Maker
-		match.MetaData.Proof.Auth = db.MatchAuth{}
-		auth := &match.MetaData.Proof.Auth
-		auth.MatchStamp = uint64(matchTime.UnixMilli())
-		if status >= order.MakerSwapCast {
-			if isMaker {
-				auth.InitSig = tBytes
-			} else {
-				auth.AuditSig = tBytes
-			}
-		}
-		if status >= order.TakerSwapCast {
-			if isMaker {
-				auth.AuditSig = tBytes
-			} else {
-				auth.InitSig = tBytes
-			}
-		}
-		if status >= order.MakerRedeemed {
-			if isMaker {
-				auth.RedeemSig = tBytes
-			} else {
-				auth.RedemptionSig = tBytes
-			}
-		}
-		if status >= order.MatchComplete {
-			if isMaker {
-				auth.RedemptionSig = tBytes
-			} else {
-				auth.RedeemSig = tBytes
-			}
-		}
+func Test_marketTrades(t *testing.T) {
+	mktID := "dcr_btc"
+	dc := &dexConnection{
+		trades: make(map[order.OrderID]*trackedTrade),
 	}
 
-	// Call setProof before setAuthSigs
-	setProof := func(status order.MatchStatus) {
-		isMaker := match.Side == order.Maker
-		match.Status = status
-		match.MetaData.Proof = db.MatchProof{}
-		proof := &match.MetaData.Proof
+	preImg := newPreimage()
+	activeOrd := &order.LimitOrder{P: order.Prefix{
+		ServerTime: time.Now(),
+		Commit:     preImg.Commit(),
+	}}
+	activeTracker := &trackedTrade{
+		Order:  activeOrd,
+		preImg: preImg,
+		mktID:  mktID,
+		dc:     dc,
+		metaData: &db.OrderMetaData{
+			Status: order.OrderStatusBooked,
+		},
+		matches: make(map[order.MatchID]*matchTracker),
+	}
 
-		if isMaker {
-			auditInfo.Expiration = matchTime.Add(trade.lockTimeTaker)
-		} else {
-			auditInfo.Expiration = matchTime.Add(trade.lockTimeMaker)
-		}
+	dc.trades[activeTracker.ID()] = activeTracker
 
-		if status >= order.MakerSwapCast {
-			proof.MakerSwap = tCoinID
-			proof.SecretHash = secretHash[:]
-			if isMaker {
-				proof.ContractData = tBytes
-				proof.Secret = secret
-			} else {
-				proof.CounterContract = tBytes
-			}
-		}
-		if status >= order.TakerSwapCast {
-			proof.TakerSwap = tCoinID
-			if isMaker {
-				proof.CounterContract = tBytes
-			} else {
-				proof.ContractData = tBytes
-			}
-		}
-		if status >= order.MakerRedeemed {
-			proof.MakerRedeem = tCoinID
-			if !isMaker {
-				proof.Secret = secret
-			}
-		}
-		if status >= order.MatchComplete {
-			proof.TakerRedeem = tCoinID
-		}
+	preImg = newPreimage() // different oid
+	inactiveOrd := &order.LimitOrder{P: order.Prefix{
+		ServerTime: time.Now(),
+		Commit:     preImg.Commit(),
+	}}
+	inactiveTracker := &trackedTrade{
+		Order:  inactiveOrd,
+		preImg: preImg,
+		mktID:  mktID,
+		dc:     dc,
+		metaData: &db.OrderMetaData{
+			Status: order.OrderStatusExecuted,
+		},
+		matches: make(map[order.MatchID]*matchTracker), // no matches
 	}
 
-	setLocalMatchStatus := func(proofStatus, authStatus order.MatchStatus) {
-		setProof(proofStatus)
-		setAuthSigs(authStatus)
-	}
+	dc.trades[inactiveTracker.ID()] = inactiveTracker
 
-	var tMatchResults *msgjson.MatchStatusResult
-	setMatchResults := func(status order.MatchStatus) *msgjson.MatchStatusResult {
-		tMatchResults = &msgjson.MatchStatusResult{
-			MatchID: matchID[:],
-			Status:  uint8(status),
-			Active:  status != order.MatchComplete,
-		}
-		if status >= order.MakerSwapCast {
-			tMatchResults.MakerContract = tBytes
-			tMatchResults.MakerSwap = tCoinID
-		}
-		if status == order.MakerSwapCast || status == order.TakerSwapCast {
-			tMatchResults.TakerTxData = tTxData
-		}
-		if status >= order.TakerSwapCast {
-			tMatchResults.TakerContract = tBytes
-			tMatchResults.TakerSwap = tCoinID
-		}
-		if status >= order.MakerRedeemed {
-			tMatchResults.MakerRedeem = tCoinID
-			tMatchResults.Secret = secret
-		}
-		if status >= order.MatchComplete {
-			tMatchResults.TakerRedeem = tCoinID
-		}
-		return tMatchResults
+	trades, _ := dc.marketTrades(mktID)
+	if len(trades) != 1 {
+		t.Fatalf("Expected only one trade from marketTrades, found %v", len(trades))
 	}
-
-	type test struct {
-		ours, servers      order.MatchStatus
-		side               order.MatchSide
-		tweaker            func()
-		countStatusUpdates int
+	if trades[0].ID() != activeOrd.ID() {
+		t.Errorf("Expected active order ID %v, got %v", activeOrd.ID(), trades[0].ID())
 	}
+}
 
-	testName := func(tt *test) string {
-		return fmt.Sprintf("%s / %s (%s)", tt.ours, tt.servers, tt.side)
-	}
+func TestLogout(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-	runTest := func(tt *test) order.MatchStatus {
-		match.Side = tt.side
-		setLocalMatchStatus(tt.ours, tt.servers)
-		setMatchResults(tt.servers)
-		if tt.tweaker != nil {
-			tt.tweaker()
-		}
-		rig.queueConnect(nil, connectMatches(tt.servers), nil)
-		rig.ws.queueResponse(msgjson.MatchStatusRoute, func(msg *msgjson.Message, f msgFunc) error {
-			resp, _ := msgjson.NewResponse(msg.ID, []*msgjson.MatchStatusResult{tMatchResults}, nil)
-			f(resp)
-			return nil
-		})
-		if tt.countStatusUpdates > 0 {
-			rig.db.updateMatchChan = make(chan order.MatchStatus, tt.countStatusUpdates)
-		}
-		if err := tCore.authDEX(dc); err != nil {
-			t.Fatalf("unexpected authDEX error: %v", err)
-		}
-		for i := 0; i < tt.countStatusUpdates; i++ {
-			<-rig.db.updateMatchChan
-		}
-		rig.db.updateMatchChan = nil
-		trade.mtx.Lock()
-		newStatus := match.Status
-		trade.mtx.Unlock()
-		return newStatus
-	}
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
 
-	// forwardResolvers are recoverable status combos where the server is ahead
-	// of us.
-	forwardResolvers := []*test{
-		{
-			ours:               order.NewlyMatched,
-			servers:            order.MakerSwapCast,
-			side:               order.Taker,
-			countStatusUpdates: 2,
-		},
-		{
-			ours:               order.MakerSwapCast,
-			servers:            order.TakerSwapCast,
-			side:               order.Maker,
-			countStatusUpdates: 2,
-		},
-		{
-			ours:    order.TakerSwapCast,
-			servers: order.MakerRedeemed,
-			side:    order.Taker,
-		},
-		{
-			ours:    order.MakerRedeemed,
-			servers: order.MatchComplete,
-			side:    order.Maker,
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+
+	ord := &order.LimitOrder{P: order.Prefix{ServerTime: time.Now()}}
+	tracker := &trackedTrade{
+		Order:  ord,
+		preImg: newPreimage(),
+		dc:     rig.dc,
+		metaData: &db.OrderMetaData{
+			Status: order.OrderStatusBooked,
 		},
+		matches: make(map[order.MatchID]*matchTracker),
 	}
+	rig.dc.trades[ord.ID()] = tracker
 
-	// Check that all of the forwardResolvers update the match status.
-	for _, tt := range forwardResolvers {
-		newStatus := runTest(tt)
-		if newStatus == tt.ours {
-			t.Fatalf("(%s) status not updated for forward resolution path", testName(tt))
-		}
-		if match.MetaData.Proof.SelfRevoked {
-			t.Fatalf("(%s) match self-revoked during forward resolution", testName(tt))
+	ensureErr := func(tag string) {
+		t.Helper()
+
+		tCore.loginMtx.Lock()
+		tCore.loggedIn = true
+		tCore.loginMtx.Unlock()
+
+		err := tCore.Logout()
+		if err == nil {
+			t.Fatalf("%s: no error", tag)
 		}
 	}
 
-	// backwardsResolvers are recoverable status mismatches where we are ahead
-	// of the server but can be resolved by deferring to resendPendingRequests.
-	backWardsResolvers := []*test{
-		{
-			ours:    order.MakerSwapCast,
-			servers: order.NewlyMatched,
-			side:    order.Maker,
-		},
-		{
-			ours:    order.TakerSwapCast,
-			servers: order.MakerSwapCast,
-			side:    order.Taker,
-		},
-		{
-			ours:    order.MakerRedeemed,
-			servers: order.TakerSwapCast,
-			side:    order.Maker,
-		},
-		{
-			ours:    order.MatchComplete,
-			servers: order.MakerRedeemed,
-			side:    order.Taker,
-		},
-	}
+	// Active orders error.
+	ensureErr("active orders")
 
-	// Backwards resolvers won't update the match status, but also won't revoke
-	// the match.
-	for _, tt := range backWardsResolvers {
-		newStatus := runTest(tt)
-		if newStatus != tt.ours {
-			t.Fatalf("(%s) status changed for backwards resolution path", testName(tt))
-		}
-		if match.MetaData.Proof.SelfRevoked {
-			t.Fatalf("(%s) match self-revoked during backwards resolution", testName(tt))
-		}
-	}
-
-	// nonsense are status combos that make no sense, so should always result
-	// in a self-revocation.
-	nonsense := []*test{
-		{ // Server has our info before us
-			ours:    order.NewlyMatched,
-			servers: order.MakerSwapCast,
-			side:    order.Maker,
-		},
-		{ // Two steps apart
-			ours:    order.NewlyMatched,
-			servers: order.TakerSwapCast,
-			side:    order.Maker,
-		},
-		{ // Server didn't send contract
-			ours:    order.NewlyMatched,
-			servers: order.MakerSwapCast,
-			side:    order.Taker,
-			tweaker: func() {
-				tMatchResults.MakerContract = nil
-			},
-		},
-		{ // Server didn't send coin ID.
-			ours:    order.NewlyMatched,
-			servers: order.MakerSwapCast,
-			side:    order.Taker,
-			tweaker: func() {
-				tMatchResults.MakerSwap = nil
-			},
-		},
-		{ // Audit failed.
-			ours:    order.NewlyMatched,
-			servers: order.MakerSwapCast,
-			side:    order.Taker,
-			tweaker: func() {
-				auditInfo.Expiration = matchTime
-			},
-			countStatusUpdates: 2, // async auditContract -> revoke and db update
-		},
-		{ // Server has our info before us
-			ours:    order.MakerSwapCast,
-			servers: order.TakerSwapCast,
-			side:    order.Taker,
-		},
-		{ // Server has our info before us
-			ours:    order.MakerSwapCast,
-			servers: order.TakerSwapCast,
-			side:    order.Taker,
-		},
-		{ // Server didn't send contract
-			ours:    order.MakerSwapCast,
-			servers: order.TakerSwapCast,
-			side:    order.Maker,
-			tweaker: func() {
-				tMatchResults.TakerContract = nil
-			},
-		},
-		{ // Server didn't send coin ID.
-			ours:    order.MakerSwapCast,
-			servers: order.TakerSwapCast,
-			side:    order.Maker,
-			tweaker: func() {
-				tMatchResults.TakerSwap = nil
-			},
-		},
-		{ // Audit failed.
-			ours:    order.MakerSwapCast,
-			servers: order.TakerSwapCast,
-			side:    order.Maker,
-			tweaker: func() {
-				auditInfo.Expiration = matchTime
-			},
-			countStatusUpdates: 2, // async auditContract -> revoke and db update
-		},
-		{ // Taker has counter-party info the server doesn't.
-			ours:    order.MakerSwapCast,
-			servers: order.NewlyMatched,
-			side:    order.Taker,
-		},
-		{ // Maker has a server ack, but they say they don't have the data.
-			ours:    order.MakerSwapCast,
-			servers: order.NewlyMatched,
-			side:    order.Maker,
-			tweaker: func() {
-				match.MetaData.Proof.Auth.InitSig = tBytes
-			},
-		},
-		{ // Maker has counter-party info the server doesn't.
-			ours:    order.TakerSwapCast,
-			servers: order.MakerSwapCast,
-			side:    order.Maker,
-		},
-		{ // Taker has a server ack, but they say they don't have the data.
-			ours:    order.TakerSwapCast,
-			servers: order.MakerSwapCast,
-			side:    order.Taker,
-			tweaker: func() {
-				match.MetaData.Proof.Auth.InitSig = tBytes
-			},
-		},
-		{ // Server has redeem info before us.
-			ours:    order.TakerSwapCast,
-			servers: order.MakerRedeemed,
-			side:    order.Maker,
-		},
-		{ // Server didn't provide redemption coin ID.
-			ours:    order.TakerSwapCast,
-			servers: order.MakerRedeemed,
-			side:    order.Taker,
-			tweaker: func() {
-				tMatchResults.MakerRedeem = nil
-			},
-		},
-		{ // Server didn't provide secret.
-			ours:    order.TakerSwapCast,
-			servers: order.MakerRedeemed,
-			side:    order.Taker,
-			tweaker: func() {
-				tMatchResults.Secret = nil
-			},
-		},
-		{ // Server has our redemption data before us.
-			ours:    order.MakerRedeemed,
-			servers: order.MatchComplete,
-			side:    order.Taker,
-		},
-		{ // We have data before the server.
-			ours:    order.MakerRedeemed,
-			servers: order.TakerSwapCast,
-			side:    order.Taker,
-		},
-		{ // We have a server ack, but they say they don't have the data.
-			ours:    order.MakerRedeemed,
-			servers: order.TakerSwapCast,
-			side:    order.Maker,
-			tweaker: func() {
-				match.MetaData.Proof.Auth.RedeemSig = tBytes
-			},
-		},
-		{ // We have data before the server.
-			ours:    order.MatchComplete,
-			servers: order.MakerSwapCast,
-			side:    order.Maker,
-		},
-		{ // We have a server ack, but they say they don't have the data.
-			ours:    order.MatchComplete,
-			servers: order.MakerSwapCast,
-			side:    order.Taker,
-			tweaker: func() {
-				match.MetaData.Proof.Auth.RedeemSig = tBytes
-			},
-		},
-	}
-
-	for _, tt := range nonsense {
-		runTest(tt)
-		if !match.MetaData.Proof.SelfRevoked {
-			t.Fatalf("(%s) match not self-revoked during nonsense resolution", testName(tt))
-		}
+	tracker.metaData = &db.OrderMetaData{
+		Status: order.OrderStatusExecuted,
 	}
-
-	// Run two matches for the same order.
-	match2ID := ordertest.RandomMatchID()
-	match2 := &matchTracker{
+	mid := ordertest.RandomMatchID()
+	tracker.matches[mid] = &matchTracker{
 		MetaMatch: db.MetaMatch{
 			MetaData: &db.MatchMetaData{},
 			UserMatch: &order.UserMatch{
-				MatchID: match2ID,
-				Address: addr,
+				OrderID: ord.ID(),
+				MatchID: mid,
+				Status:  order.NewlyMatched,
+				Side:    order.Maker,
 			},
 		},
+		prefix:          ord.Prefix(),
+		trade:           ord.Trade(),
+		counterConfirms: -1,
 	}
-	trade.matches[match2ID] = match2
-	setAuthSigs(order.NewlyMatched)
-	setProof(order.NewlyMatched)
-	match2.Side = order.Taker
-	match2.MetaData.Proof = match.MetaData.Proof
+	// Active orders with matches error.
+	ensureErr("active orders matches")
+	rig.dc.trades = nil
+}
 
-	srvMatches := connectMatches(order.MakerSwapCast)
-	srvMatches = append(srvMatches, &msgjson.Match{OrderID: oid[:],
-		MatchID: match2ID[:],
-		Status:  uint8(order.MakerSwapCast),
-		Side:    uint8(order.Taker),
-	})
+func TestSetEpoch(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	dc.books[tDcrBtcMktName] = newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
 
-	res1 := setMatchResults(order.MakerSwapCast)
-	res2 := setMatchResults(order.MakerSwapCast)
-	res2.MatchID = match2ID[:]
+	mktEpoch := func() uint64 {
+		dc.epochMtx.RLock()
+		defer dc.epochMtx.RUnlock()
+		return dc.epoch[tDcrBtcMktName]
+	}
 
-	rig.queueConnect(nil, srvMatches, nil)
-	rig.ws.queueResponse(msgjson.MatchStatusRoute, func(msg *msgjson.Message, f msgFunc) error {
-		resp, _ := msgjson.NewResponse(msg.ID, []*msgjson.MatchStatusResult{res1, res2}, nil)
-		f(resp)
-		return nil
-	})
-	// 2 matches resolved via contract audit: 2 synchronous updates, 2 async
-	rig.db.updateMatchChan = make(chan order.MatchStatus, 4)
-	tCore.authDEX(dc)
-	for i := 0; i < 4; i++ {
-		<-rig.db.updateMatchChan
+	payload := &msgjson.MatchProofNote{
+		MarketID: tDcrBtcMktName,
+		Epoch:    1,
 	}
-	trade.mtx.Lock()
-	newStatus1 := match.Status
-	newStatus2 := match2.Status
-	trade.mtx.Unlock()
-	if newStatus1 != order.MakerSwapCast {
-		t.Fatalf("wrong status for match 1: %s", newStatus1)
+	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.MatchProofRoute, payload)
+	err := handleMatchProofMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("error advancing epoch: %v", err)
 	}
-	if newStatus2 != order.MakerSwapCast {
-		t.Fatalf("wrong status for match 2: %s", newStatus2)
+	if mktEpoch() != 2 {
+		t.Fatalf("expected epoch 2, got %d", mktEpoch())
+	}
+
+	payload.Epoch = 0
+	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.MatchProofRoute, payload)
+	err = handleMatchProofMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("error handling match proof: %v", err)
+	}
+	if mktEpoch() != 2 {
+		t.Fatalf("epoch changed, expected epoch 2, got %d", mktEpoch())
 	}
 }
 
-func TestConfirmRedemption(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	dc := rig.dc
+func makeLimitOrder(dc *dexConnection, sell bool, qty, rate uint64) (*order.LimitOrder, *db.MetaOrder, order.Preimage, string) {
+	preImg := newPreimage()
+	addr := ordertest.RandomAddress()
+	lo := &order.LimitOrder{
+		P: order.Prefix{
+			AccountID:  dc.acct.ID(),
+			BaseAsset:  tUTXOAssetA.ID,
+			QuoteAsset: tUTXOAssetB.ID,
+			OrderType:  order.LimitOrderType,
+			ClientTime: time.Now(),
+			ServerTime: time.Now().Add(time.Millisecond),
+			Commit:     preImg.Commit(),
+		},
+		T: order.Trade{
+			// Coins needed?
+			Sell:     sell,
+			Quantity: qty,
+			Address:  addr,
+		},
+		Rate:  rate,
+		Force: order.ImmediateTiF,
+	}
+	fromAsset, toAsset := tUTXOAssetB, tUTXOAssetA
+	if sell {
+		fromAsset, toAsset = tUTXOAssetA, tUTXOAssetB
+	}
+	dbOrder := &db.MetaOrder{
+		MetaData: &db.OrderMetaData{
+			Status: order.OrderStatusEpoch,
+			Host:   dc.acct.host,
+			Proof: db.OrderProof{
+				Preimage: preImg[:],
+			},
+			MaxFeeRate:   tMaxFeeRate,
+			EpochDur:     dc.marketEpochDuration(tDcrBtcMktName),
+			FromSwapConf: fromAsset.SwapConf,
+			ToSwapConf:   toAsset.SwapConf,
+		},
+		Order: lo,
+	}
+	return lo, dbOrder, preImg, addr
+}
+
+func TestAddrHost(t *testing.T) {
+	tests := []struct {
+		name, addr, want string
+		wantErr          bool
+	}{{
+		name: "scheme, host, and port",
+		addr: "https://localhost:5758",
+		want: "localhost:5758",
+	}, {
+		name: "scheme, ipv6 host, and port",
+		addr: "https://[::1]:5758",
+		want: "[::1]:5758",
+	}, {
+		name: "host and port",
+		addr: "localhost:5758",
+		want: "localhost:5758",
+	}, {
+		name: "just port",
+		addr: ":5758",
+		want: "localhost:5758",
+	}, {
+		name: "ip host and port",
+		addr: "127.0.0.1:5758",
+		want: "127.0.0.1:5758",
+	}, {
+		name: "just host",
+		addr: "thatonedex.com",
+		want: "thatonedex.com:7232",
+	}, {
+		name: "scheme and host",
+		addr: "https://thatonedex.com",
+		want: "thatonedex.com:7232",
+	}, {
+		name: "scheme, host, and path",
+		addr: "https://thatonedex.com/any/path",
+		want: "thatonedex.com:7232",
+	}, {
+		name: "ipv6 host",
+		addr: "[1:2::]",
+		want: "[1:2::]:7232",
+	}, {
+		name: "ipv6 host and port",
+		addr: "[1:2::]:5758",
+		want: "[1:2::]:5758",
+	}, {
+		name: "empty address",
+		want: "localhost:7232",
+	}, {
+		name:    "invalid host",
+		addr:    "https://\n:1234",
+		wantErr: true,
+	}, {
+		name:    "invalid port",
+		addr:    ":asdf",
+		wantErr: true,
+	}, {
+		name: "host and identity",
+		addr: "thatonedex.com#alt",
+		want: "thatonedex.com:7232#alt",
+	}, {
+		name: "scheme, host, port, and identity",
+		addr: "https://thatonedex.com:5758#dcr_btc-1",
+		want: "thatonedex.com:5758#dcr_btc-1",
+	}, {
+		name:    "invalid identity",
+		addr:    "thatonedex.com#a/b",
+		wantErr: true,
+	}}
+	for _, test := range tests {
+		res, err := addrHost(test.addr)
+		if res != test.want {
+			t.Fatalf("wanted %s but got %s for test '%s'", test.want, res, test.name)
+		}
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("wanted error for test %s, but got none", test.name)
+			}
+			continue
+		} else if err != nil {
+			t.Fatalf("addrHost error for test %s: %v", test.name, err)
+		}
+		// Parsing results a second time should produce the same results.
+		res, _ = addrHost(res)
+		if res != test.want {
+			t.Fatalf("wanted %s but got %s for test '%s'", test.want, res, test.name)
+		}
+	}
+}
+
+func TestTradingIdentities(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
 	tCore := rig.core
+	// tCrypter decrypts in place, and setupCryptoV2 clears the decrypted seed,
+	// so each derivation needs its own copy.
+	seed := bytes.Clone(tCore.creds().EncSeed)
+	setupCrypto := func(acct *dexAccount) error {
+		creds := *tCore.creds()
+		creds.EncSeed = bytes.Clone(seed)
+		return acct.setupCryptoV2(&creds, rig.crypter, 0)
+	}
 
 	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
 	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	dcrWallet.Unlock(rig.crypter)
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
 	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
-
-	lo, dbOrder, preImg, addr := makeLimitOrder(dc, true, 0, 0)
-	oid := lo.ID()
-	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
-	dc.trades[oid] = tracker
-
-	tBytes := encode.RandomBytes(2)
-	tCoinID := encode.RandomBytes(36)
-	tUpdatedCoinID := encode.RandomBytes(36)
-	secret := encode.RandomBytes(32)
-	secretHash := sha256.Sum256(secret)
-
-	var match *matchTracker
+	btcWallet.Unlock(rig.crypter)
 
-	tBtcWallet.redeemCoins = []dex.Bytes{tUpdatedCoinID}
+	qty := dcrBtcLotSize * 2
+	rate := dcrBtcRateStep * 1000
 
-	ourContract := encode.RandomBytes(90)
-	setupMatch := func(status order.MatchStatus, side order.MatchSide) {
-		matchID := ordertest.RandomMatchID()
-		_, auditInfo := tMsgAudit(oid, matchID, addr, 0, secretHash[:])
-		matchTime := time.Now()
-		match = &matchTracker{
-			counterSwap: auditInfo,
-			MetaMatch: db.MetaMatch{
-				MetaData: &db.MatchMetaData{},
-				UserMatch: &order.UserMatch{
-					MatchID: matchID,
-					Address: addr,
-					Side:    side,
-					Status:  status,
-				},
-			},
+	// The primary account and two identities of the same host each get their
+	// own account key, connection, and orders.
+	hosts := []string{tDexHost, tDexHost + "#alt", tDexHost + "#dcr_btc"}
+	hostIDs := make(map[account.AccountID]string, len(hosts))
+	for _, host := range hosts {
+		acct := &dexAccount{
+			host:              host,
+			dexPubKey:         tDexKey,
+			pendingBondsConfs: make(map[string]uint32),
+			rep:               account.Reputation{BondedTier: 1},
 		}
-		tracker.matches = map[order.MatchID]*matchTracker{matchID: match}
-
-		isMaker := match.Side == order.Maker
-		proof := &match.MetaData.Proof
-		proof.Auth.InitSig = []byte{1, 2, 3, 4}
-		// Assume our redeem was accepted, if we sent one.
-		if isMaker {
-			auditInfo.Expiration = matchTime.Add(tracker.lockTimeTaker)
-			if status >= order.MakerRedeemed {
-				match.MetaData.Proof.Auth.RedeemSig = []byte{0}
-			}
-		} else {
-			auditInfo.Expiration = matchTime.Add(tracker.lockTimeMaker)
-			if status >= order.MatchComplete {
-				match.MetaData.Proof.Auth.RedeemSig = []byte{0}
-			}
+		if err := setupCrypto(acct); err != nil {
+			t.Fatalf("%s: setupCryptoV2 error: %v", host, err)
 		}
+		acctID := acct.ID()
+		if otherHost, found := hostIDs[acctID]; found {
+			t.Fatalf("%s and %s have the same account ID %s", host, otherHost, acctID)
+		}
+		hostIDs[acctID] = host
 
-		if status >= order.MakerSwapCast {
-			proof.MakerSwap = tCoinID
-			proof.SecretHash = secretHash[:]
-			if isMaker {
-				proof.ContractData = ourContract
-				proof.Secret = secret
-			} else {
-				proof.CounterContract = tBytes
-			}
+		// The key is deterministic.
+		again := &dexAccount{host: host, dexPubKey: tDexKey}
+		if err := setupCrypto(again); err != nil {
+			t.Fatalf("%s: setupCryptoV2 error: %v", host, err)
 		}
-		if status >= order.TakerSwapCast {
-			proof.TakerSwap = tCoinID
-			if isMaker {
-				proof.CounterContract = tBytes
-			} else {
-				proof.ContractData = ourContract
-			}
+		if again.ID() != acctID {
+			t.Fatalf("%s: account key not deterministic", host)
 		}
-		if status >= order.MakerRedeemed {
-			proof.MakerRedeem = tCoinID
-			if !isMaker {
-				proof.Secret = secret
+
+		dc, ws, _ := testDexConnection(tCore.ctx, rig.crypter.(*tCrypter))
+		dc.acct = acct
+		tCore.addDexConnection(dc)
+
+		var orderAcctID account.AccountID
+		ws.queueResponse(msgjson.LimitRoute, func(msg *msgjson.Message, f msgFunc) error {
+			msgOrder := new(msgjson.LimitOrder)
+			if err := msg.Unmarshal(msgOrder); err != nil {
+				t.Fatalf("unmarshal error: %v", err)
 			}
+			lo := convertMsgLimitOrder(msgOrder)
+			orderAcctID = lo.AccountID
+			f(orderResponse(msg.ID, msgOrder, lo, false, false, false))
+			return nil
+		})
+
+		tDcrWallet.fundingCoins = asset.Coins{&tCoin{id: encode.RandomBytes(36), val: qty * 2}}
+		tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
+		_, err := tCore.Trade(tPW, &TradeForm{
+			Host:    host,
+			IsLimit: true,
+			Sell:    true,
+			Base:    tUTXOAssetA.ID,
+			Quote:   tUTXOAssetB.ID,
+			Qty:     qty,
+			Rate:    rate,
+		})
+		if err != nil {
+			t.Fatalf("%s: Trade error: %v", host, err)
 		}
-		if status >= order.MatchComplete {
-			proof.TakerRedeem = tCoinID
+		if orderAcctID != acctID {
+			t.Fatalf("%s: order placed with account %s, expected %s", host, orderAcctID, acctID)
 		}
 	}
+}
 
-	type note struct {
-		severity db.Severity
-		topic    db.Topic
+func TestAssetBalance(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
+	bal := &asset.Balance{
+		Available: 4e7,
+		Immature:  6e7,
+		Locked:    2e8,
+	}
+	tWallet.bal = bal
+	walletBal, err := tCore.AssetBalance(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("error retrieving asset balance: %v", err)
+	}
+	dbtest.MustCompareAssetBalances(t, "zero-conf", bal, &walletBal.Balance.Balance)
+	if walletBal.ContractLocked != 0 {
+		t.Fatalf("contractlocked balance %d > expected value 0", walletBal.ContractLocked)
 	}
+}
 
-	tests := []struct {
-		name                    string
-		matchStatus             order.MatchStatus
-		matchSide               order.MatchSide
-		expectedNotifications   []*note
-		confirmRedemptionResult *asset.ConfirmRedemptionStatus
-		confirmRedemptionErr    error
+func TestBalanceBreakdown(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
 
-		expectConfirmRedemptionCalled bool
-		expectedStatus                order.MatchStatus
-		expectTicksDelayed            bool
-	}{
-		{
-			name:        "maker, makerRedeemed, confirmedRedemption",
-			matchStatus: order.MakerRedeemed,
-			matchSide:   order.Maker,
-			expectedNotifications: []*note{
-				{
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+
+	const fundingAmt = 5e8
+	tDcrWallet.bal = &asset.Balance{
+		Available:    4e8,
+		Immature:     6e7,
+		Locked:       fundingAmt + 3e6, // 3e6 bond reserves
+		BondReserves: 3e6,
+	}
+
+	// An order with locked funding coins.
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
+	}
+	qty := 4 * dcrBtcLotSize
+	_, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, dcrBtcRateStep)
+	fundingCoins := asset.Coins{&tCoin{id: encode.RandomBytes(36), val: fundingAmt}}
+	tracker := newTrackedTrade(dbOrder, preImg, dc, tCore.lockTimeTaker, tCore.lockTimeMaker,
+		rig.db, rig.queue, walletSet, fundingCoins, tCore.notify, tCore.formatDetails)
+	dc.trades[tracker.ID()] = tracker
+
+	// An in-flight swap.
+	swapQty := 2 * dcrBtcLotSize
+	mid := ordertest.RandomMatchID()
+	tracker.matches[mid] = &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{
+				MatchID:  mid,
+				Quantity: swapQty,
+				Rate:     dcrBtcRateStep,
+				Side:     order.Maker,
+				Status:   order.MakerSwapCast,
+			},
+			MetaData: &db.MatchMetaData{},
+		},
+	}
+
+	// A bond.
+	const bondAmt = 1e8
+	dc.acct.authMtx.Lock()
+	dc.acct.bonds = []*db.Bond{{AssetID: tUTXOAssetA.ID, Amount: bondAmt}}
+	dc.acct.authMtx.Unlock()
+
+	bd, err := tCore.BalanceBreakdown(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("BalanceBreakdown error: %v", err)
+	}
+	bal := tDcrWallet.bal
+	if bd.Available != bal.Available || bd.Immature != bal.Immature {
+		t.Fatalf("wrong available/immature. wanted %d/%d, got %d/%d",
+			bal.Available, bal.Immature, bd.Available, bd.Immature)
+	}
+	if bd.OrderLocked != fundingAmt {
+		t.Fatalf("wrong order locked amount. wanted %d, got %d", uint64(fundingAmt), bd.OrderLocked)
+	}
+	if bd.SwapLocked != swapQty {
+		t.Fatalf("wrong swap locked amount. wanted %d, got %d", uint64(swapQty), bd.SwapLocked)
+	}
+	if bd.Bonded != bondAmt {
+		t.Fatalf("wrong bonded amount. wanted %d, got %d", uint64(bondAmt), bd.Bonded)
+	}
+	if bd.OtherLocked != bal.BondReserves {
+		t.Fatalf("wrong other locked amount. wanted %d, got %d", bal.BondReserves, bd.OtherLocked)
+	}
+	// The breakdown sums to the wallet's total, including the funds that
+	// have left the wallet for swap contracts and bonds.
+	checkTotal := func() {
+		t.Helper()
+		dcrWallet.mtx.RLock()
+		walletTotal := dcrWallet.balance.Total()
+		dcrWallet.mtx.RUnlock()
+		if bd.Total != walletTotal {
+			t.Fatalf("breakdown total %d != wallet total %d", bd.Total, walletTotal)
+		}
+		if sum := bd.Available + bd.OrderLocked + bd.SwapLocked + bd.Bonded + bd.Immature + bd.OtherLocked; sum != bd.Total {
+			t.Fatalf("categories sum %d != total %d", sum, bd.Total)
+		}
+	}
+	checkTotal()
+	if walletTotal := bal.Available + bal.Immature + bal.Locked + swapQty + bondAmt; bd.Total != walletTotal {
+		t.Fatalf("breakdown total %d != wallet total %d", bd.Total, walletTotal)
+	}
+
+	// A wallet that doesn't report locks for the order's funding coins.
+	tDcrWallet.bal = &asset.Balance{
+		Available: 4e8,
+		Locked:    1e6,
+	}
+	bd, err = tCore.BalanceBreakdown(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("BalanceBreakdown error: %v", err)
+	}
+	if bd.OrderLocked != 1e6 || bd.OtherLocked != 0 {
+		t.Fatalf("wrong locked amounts for under-reported locks. wanted 1000000/0, got %d/%d",
+			bd.OrderLocked, bd.OtherLocked)
+	}
+	checkTotal()
+
+	// No wallet.
+	if _, err := tCore.BalanceBreakdown(tACCTAsset.ID); err == nil {
+		t.Fatalf("no error for missing wallet")
+	}
+}
+
+func TestPortfolio(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc1 := rig.dc
+
+	dc2, _, acct2 := testDexConnection(tCore.ctx, rig.crypter.(*tCrypter))
+	acct2.host = "someotherhost.com"
+	tCore.conns[acct2.host] = dc2
+
+	// Both hosts share the same DCR and BTC wallets.
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+
+	const dcrFunding1, dcrFunding2 = 3e8, 2e8
+	tDcrWallet.bal = &asset.Balance{
+		Available: 1e9,
+		Locked:    dcrFunding1 + dcrFunding2,
+	}
+	tBtcWallet.bal = &asset.Balance{Available: 7e7}
+
+	addSellOrder := func(dc *dexConnection, funding uint64) *trackedTrade {
+		t.Helper()
+		walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+		if err != nil {
+			t.Fatalf("walletSet error: %v", err)
+		}
+		_, dbOrder, preImg, _ := makeLimitOrder(dc, true, 4*dcrBtcLotSize, dcrBtcRateStep)
+		dbOrder.MetaData.Status = order.OrderStatusBooked
+		fundingCoins := asset.Coins{&tCoin{id: encode.RandomBytes(36), val: funding}}
+		tracker := newTrackedTrade(dbOrder, preImg, dc, tCore.lockTimeTaker, tCore.lockTimeMaker,
+			rig.db, rig.queue, walletSet, fundingCoins, tCore.notify, tCore.formatDetails)
+		dc.trades[tracker.ID()] = tracker
+		return tracker
+	}
+	addSellOrder(dc1, dcrFunding1)
+	tracker2 := addSellOrder(dc2, dcrFunding2)
+
+	swapQty := dcrBtcLotSize
+	mid := ordertest.RandomMatchID()
+	tracker2.matches[mid] = &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{
+				MatchID:  mid,
+				Quantity: swapQty,
+				Rate:     dcrBtcRateStep,
+				Side:     order.Maker,
+				Status:   order.MakerSwapCast,
+			},
+			MetaData: &db.MatchMetaData{},
+		},
+	}
+
+	const bond1, bond2, expiredBond1 = 1e8, 2e8, 5e7
+	dc1.acct.bonds = []*db.Bond{{AssetID: tUTXOAssetA.ID, Amount: bond1}}
+	dc1.acct.expiredBonds = []*db.Bond{{AssetID: tUTXOAssetA.ID, Amount: expiredBond1}}
+	dc2.acct.pendingBonds = []*db.Bond{{AssetID: tUTXOAssetA.ID, Amount: bond2}}
+
+	p := tCore.Portfolio()
+
+	if len(p.Hosts) != 2 {
+		t.Fatalf("expected 2 hosts, got %d", len(p.Hosts))
+	}
+	hp1, hp2 := p.Hosts[dc1.acct.host], p.Hosts[acct2.host]
+	if hp1 == nil || hp2 == nil {
+		t.Fatalf("missing host portfolio")
+	}
+	if hp1.ActiveOrders != 1 || hp1.ActiveMatches != 0 {
+		t.Fatalf("wrong host 1 counts. orders = %d, matches = %d", hp1.ActiveOrders, hp1.ActiveMatches)
+	}
+	if hp2.ActiveOrders != 1 || hp2.ActiveMatches != 1 {
+		t.Fatalf("wrong host 2 counts. orders = %d, matches = %d", hp2.ActiveOrders, hp2.ActiveMatches)
+	}
+	checkHostAmts := func(hp *HostPortfolio, orderLocked, swapLocked, bonded uint64) {
+		t.Helper()
+		a := hp.Assets[tUTXOAssetA.ID]
+		if a == nil {
+			t.Fatalf("no %s amounts for %s", tUTXOAssetA.Symbol, hp.Host)
+		}
+		if a.OrderLocked != orderLocked || a.SwapLocked != swapLocked || a.Bonded != bonded {
+			t.Fatalf("wrong amounts for %s. wanted %d/%d/%d, got %d/%d/%d", hp.Host,
+				orderLocked, swapLocked, bonded, a.OrderLocked, a.SwapLocked, a.Bonded)
+		}
+	}
+	checkHostAmts(hp1, dcrFunding1, 0, bond1+expiredBond1)
+	checkHostAmts(hp2, dcrFunding2, swapQty, bond2)
+
+	// The shared wallets are counted once, and the asset amounts are derived
+	// from the wallet balances.
+	if len(p.Assets) != 2 {
+		t.Fatalf("expected 2 assets, got %d", len(p.Assets))
+	}
+	dcr := p.Assets[tUTXOAssetA.ID]
+	if dcr.Available != tDcrWallet.bal.Available {
+		t.Fatalf("wrong available DCR. wanted %d, got %d", tDcrWallet.bal.Available, dcr.Available)
+	}
+	if dcr.OrderLocked != dcrFunding1+dcrFunding2 {
+		t.Fatalf("wrong order-locked DCR. wanted %d, got %d", uint64(dcrFunding1+dcrFunding2), dcr.OrderLocked)
+	}
+	if dcr.SwapLocked != swapQty {
+		t.Fatalf("wrong swap-locked DCR. wanted %d, got %d", swapQty, dcr.SwapLocked)
+	}
+	const bonded = bond1 + bond2 + expiredBond1
+	if dcr.Bonded != bonded {
+		t.Fatalf("wrong bonded DCR. wanted %d, got %d", uint64(bonded), dcr.Bonded)
+	}
+	expTotal := tDcrWallet.bal.Available + tDcrWallet.bal.Locked + swapQty + bonded
+	if dcr.Total != expTotal {
+		t.Fatalf("wrong DCR total. wanted %d, got %d", expTotal, dcr.Total)
+	}
+	if btc := p.Assets[tUTXOAssetB.ID]; btc.Total != tBtcWallet.bal.Available {
+		t.Fatalf("wrong BTC total. wanted %d, got %d", tBtcWallet.bal.Available, btc.Total)
+	}
+
+	// A wallet that does not report locks for the order funding coins caps the
+	// asset's order-locked amount, but the hosts' attributions are unchanged,
+	// so they no longer sum to the asset amount.
+	tDcrWallet.bal = &asset.Balance{Available: 1e9}
+	p = tCore.Portfolio()
+	if dcr = p.Assets[tUTXOAssetA.ID]; dcr.OrderLocked != 0 {
+		t.Fatalf("order-locked DCR not capped by the wallet's locked balance. got %d", dcr.OrderLocked)
+	}
+	checkHostAmts(p.Hosts[dc1.acct.host], dcrFunding1, 0, bond1+expiredBond1)
+	checkHostAmts(p.Hosts[acct2.host], dcrFunding2, swapQty, bond2)
+}
+
+func TestAssetCounter(t *testing.T) {
+	assets := make(assetMap)
+	assets.count(1)
+	if len(assets) != 1 {
+		t.Fatalf("count not added")
+	}
+
+	newCounts := assetMap{
+		1: struct{}{},
+		2: struct{}{},
+	}
+	assets.merge(newCounts)
+	if len(assets) != 2 {
+		t.Fatalf("counts not absorbed properly")
+	}
+}
+
+func TestHandleTradeSuspensionMsg(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+
+	tCore := rig.core
+	dc := rig.dc
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.Unlock(rig.crypter)
+
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.Unlock(rig.crypter)
+
+	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+
+	rig.dc.books[tDcrBtcMktName] = newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+
+	addTracker := func(coins asset.Coins) *trackedTrade {
+		lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, 0, 0)
+		oid := lo.ID()
+		tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+			rig.db, rig.queue, walletSet, coins, rig.core.notify, rig.core.formatDetails)
+		dc.trades[oid] = tracker
+		return tracker
+	}
+
+	// Make a trade that has a single funding coin, no change coin, and no
+	// active matches.
+	fundCoinDcrID := encode.RandomBytes(36)
+	freshTracker := addTracker(asset.Coins{&tCoin{id: fundCoinDcrID}})
+	freshTracker.metaData.Status = order.OrderStatusBooked // suspend with purge only purges book orders since epoch orders are always processed first
+
+	// Ensure a non-existent market cannot be suspended.
+	payload := &msgjson.TradeSuspension{
+		MarketID: "dcr_dcr",
+	}
+
+	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.SuspensionRoute, payload)
+	err := handleTradeSuspensionMsg(rig.core, rig.dc, req)
+	if err == nil {
+		t.Fatal("[handleTradeSuspensionMsg] expected a market ID not found error")
+	}
+
+	newPayload := func() *msgjson.TradeSuspension {
+		return &msgjson.TradeSuspension{
+			MarketID:    tDcrBtcMktName,
+			FinalEpoch:  100,
+			SuspendTime: dex.NewStamp(time.Now().Add(time.Millisecond * 20)),
+			Persist:     false, // Make sure the coins are returned.
+		}
+	}
+
+	// Suspend a running market.
+	rig.dc.cfgMtx.Lock()
+	mktConf := rig.dc.findMarketConfig(tDcrBtcMktName)
+	mktConf.StartEpoch = 12
+	rig.dc.cfgMtx.Unlock()
+
+	payload = newPayload()
+	payload.SuspendTime = 0 // now
+
+	orderNotes, feedDone := orderNoteFeed(tCore)
+	defer feedDone()
+
+	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.SuspensionRoute, payload)
+	err = handleTradeSuspensionMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("[handleTradeSuspensionMsg] unexpected error: %v", err)
+	}
+
+	verifyRevokeNotification(orderNotes, TopicOrderAutoRevoked, t)
+
+	// Check that the funding coin was returned. Use the tradeMtx for
+	// synchronization.
+	dc.tradeMtx.Lock()
+	if len(tDcrWallet.returnedCoins) != 1 || !bytes.Equal(tDcrWallet.returnedCoins[0].ID(), fundCoinDcrID) {
+		t.Fatalf("funding coin not returned")
+	}
+	dc.tradeMtx.Unlock()
+
+	// Make sure the change coin is returned for a trade with a change coin.
+	delete(dc.trades, freshTracker.ID())
+	swappedTracker := addTracker(nil)
+	changeCoinID := encode.RandomBytes(36)
+	swappedTracker.change = &tCoin{id: changeCoinID}
+	swappedTracker.changeLocked = true
+	swappedTracker.metaData.Status = order.OrderStatusBooked
+	rig.dc.cfgMtx.Lock()
+	mktConf.StartEpoch = 12 // make it appear running again first
+	mktConf.FinalEpoch = 0
+	mktConf.Persist = nil
+	rig.dc.cfgMtx.Unlock()
+	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.SuspensionRoute, payload)
+	err = handleTradeSuspensionMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("[handleTradeSuspensionMsg] unexpected error: %v", err)
+	}
+	// Check that the funding coin was returned.
+	dc.tradeMtx.Lock()
+	if len(tDcrWallet.returnedCoins) != 1 || !bytes.Equal(tDcrWallet.returnedCoins[0].ID(), changeCoinID) {
+		t.Fatalf("change coin not returned")
+	}
+	tDcrWallet.returnedCoins = nil
+	dc.tradeMtx.Unlock()
+
+	// Make sure the coin isn't returned if there are unswapped matches.
+	mid := ordertest.RandomMatchID()
+	match := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			UserMatch: &order.UserMatch{MatchID: mid}, // Default status = NewlyMatched
+			MetaData:  &db.MatchMetaData{},
+		},
+		counterConfirms: -1,
+	}
+	swappedTracker.matches[mid] = match
+	rig.dc.cfgMtx.Lock()
+	mktConf.StartEpoch = 12 // make it appear running again first
+	rig.dc.cfgMtx.Unlock()
+	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.SuspensionRoute, payload)
+	err = handleTradeSuspensionMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("[handleTradeSuspensionMsg] unexpected error: %v", err)
+	}
+	dc.tradeMtx.Lock()
+	if tDcrWallet.returnedCoins != nil {
+		t.Fatalf("change coin returned with active matches")
+	}
+	dc.tradeMtx.Unlock()
+
+	// Ensure trades for a suspended market generate an error.
+	form := &TradeForm{
+		Host:    tDexHost,
+		IsLimit: true,
+		Sell:    true,
+		Base:    tUTXOAssetA.ID,
+		Quote:   tUTXOAssetB.ID,
+		Qty:     dcrBtcLotSize * 10,
+		Rate:    dcrBtcRateStep * 1000,
+		TifNow:  false,
+	}
+
+	_, err = rig.core.Trade(tPW, form)
+	if err == nil {
+		t.Fatalf("expected a suspension market error")
+	}
+}
+
+func orderNoteFeed(tCore *Core) (orderNotes chan *OrderNote, done func()) {
+	orderNotes = make(chan *OrderNote, 16)
+
+	ntfnFeed := tCore.NotificationFeed()
+	feedDone := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case n := <-ntfnFeed.C:
+				if ordNote, ok := n.(*OrderNote); ok {
+					orderNotes <- ordNote
+				}
+			case <-tCtx.Done():
+				return
+			case <-feedDone:
+				return
+			}
+		}
+	}()
+
+	done = func() {
+		close(feedDone) // close first on return
+		wg.Wait()
+	}
+	return orderNotes, done
+}
+
+func verifyRevokeNotification(ch chan *OrderNote, expectedTopic Topic, t *testing.T) {
+	t.Helper()
+	select {
+	case actualOrderNote := <-ch:
+		if expectedTopic != actualOrderNote.TopicID {
+			t.Fatalf("SubjectText mismatch. %s != %s", actualOrderNote.TopicID,
+				expectedTopic)
+		}
+		return
+	case <-tCtx.Done():
+		return
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for OrderNote notification")
+		return
+	}
+}
+
+func TestHandleMarketConfigMsg(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+
+	handle := func(mkt *msgjson.Market) error {
+		t.Helper()
+		note, _ := msgjson.NewNotification(msgjson.MarketConfigRoute, mkt)
+		return handleMarketConfigMsg(rig.core, rig.dc, note)
+	}
+
+	mktCfg := *rig.dc.marketConfig(tDcrBtcMktName)
+	mktCfg.LotSize, mktCfg.RateStep = dcrBtcLotSize*2, dcrBtcRateStep*10
+	if err := handle(&mktCfg); err != nil {
+		t.Fatalf("handleMarketConfigMsg error: %v", err)
+	}
+	mkt := rig.dc.marketConfig(tDcrBtcMktName)
+	if mkt.LotSize != dcrBtcLotSize*2 || mkt.RateStep != dcrBtcRateStep*10 {
+		t.Fatalf("market parameters not updated, lot size %d, rate step %d", mkt.LotSize, mkt.RateStep)
+	}
+
+	unknown := mktCfg
+	unknown.Name = "dcr_dcr"
+	if err := handle(&unknown); err == nil {
+		t.Fatalf("no error for unknown market")
+	}
+	zeroLot := mktCfg
+	zeroLot.LotSize = 0
+	if err := handle(&zeroLot); err == nil {
+		t.Fatalf("no error for zero lot size")
+	}
+}
+
+func TestHandleTradeResumptionMsg(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+
+	tCore := rig.core
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.Unlock(rig.crypter)
+
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.Unlock(rig.crypter)
+
+	epochLen := rig.dc.marketConfig(tDcrBtcMktName).EpochLen
+
+	handleLimit := func(msg *msgjson.Message, f msgFunc) error {
+		// Need to stamp and sign the message with the server's key.
+		msgOrder := new(msgjson.LimitOrder)
+		err := msg.Unmarshal(msgOrder)
+		if err != nil {
+			t.Fatalf("unmarshal error: %v", err)
+		}
+		lo := convertMsgLimitOrder(msgOrder)
+		f(orderResponse(msg.ID, msgOrder, lo, false, false, false))
+		return nil
+	}
+
+	tradeForm := &TradeForm{
+		Host:    tDexHost,
+		IsLimit: true,
+		Sell:    true,
+		Base:    tUTXOAssetA.ID,
+		Quote:   tUTXOAssetB.ID,
+		Qty:     dcrBtcLotSize * 10,
+		Rate:    dcrBtcRateStep * 1000,
+		TifNow:  false,
+	}
+
+	rig.ws.queueResponse(msgjson.LimitRoute, handleLimit)
+
+	// Ensure a non-existent market cannot be suspended.
+	payload := &msgjson.TradeResumption{
+		MarketID: "dcr_dcr",
+	}
+
+	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.ResumptionRoute, payload)
+	err := handleTradeResumptionMsg(rig.core, rig.dc, req)
+	if err == nil {
+		t.Fatal("[handleTradeResumptionMsg] expected a market ID not found error")
+	}
+
+	var resumeTime dex.Stamp
+	newPayload := func() *msgjson.TradeResumption {
+		return &msgjson.TradeResumption{
+			MarketID:   tDcrBtcMktName,
+			ResumeTime: resumeTime, // set the time to test the scheduling notification case, zero it for immediate resume
+			StartEpoch: uint64(resumeTime) / epochLen,
+		}
+	}
+
+	// Notify of scheduled resume.
+	rig.dc.cfgMtx.Lock()
+	mktConf := rig.dc.findMarketConfig(tDcrBtcMktName)
+	mktConf.StartEpoch = 12
+	mktConf.FinalEpoch = mktConf.StartEpoch + 1 // long since closed
+	rig.dc.cfgMtx.Unlock()
+
+	resumeTime = dex.NewStamp(time.Now().Add(time.Hour))
+	payload = newPayload()
+	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.ResumptionRoute, payload)
+	err = handleTradeResumptionMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("[handleTradeResumptionMsg] unexpected error: %v", err)
+	}
+
+	// Should be suspended still, no trades
+	_, err = rig.core.Trade(tPW, tradeForm)
+	if err == nil {
+		t.Fatal("trade was accepted for suspended market")
+	}
+
+	// A resume time in seconds is rejected.
+	payload = newPayload()
+	payload.ResumeTime = dex.Stamp(time.Now().Add(time.Hour).Unix())
+	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.ResumptionRoute, payload)
+	err = handleTradeResumptionMsg(rig.core, rig.dc, req)
+	if !errors.Is(err, dex.ErrInvalidStamp) {
+		t.Fatalf("[handleTradeResumptionMsg] expected ErrInvalidStamp, got %v", err)
+	}
+
+	// Resume the market immediately.
+	resumeTime = dex.StampNow()
+	payload = newPayload()
+	payload.ResumeTime = 0 // resume now, not scheduled
+	req, _ = msgjson.NewRequest(rig.dc.NextID(), msgjson.ResumptionRoute, payload)
+	err = handleTradeResumptionMsg(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("[handleTradeResumptionMsg] unexpected error: %v", err)
+	}
+
+	// Ensure trades for a resumed market are processed without error.
+	_, err = rig.core.Trade(tPW, tradeForm)
+	if err != nil {
+		t.Fatalf("unexpected trade error %v", err)
+	}
+}
+
+func TestHandleNomatch(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
+	}
+
+	fundingCoins := asset.Coins{&tCoin{}}
+
+	// Four types of order to check
+
+	// 1. Immediate limit order
+	loImmediate, dbOrder, preImgL, _ := makeLimitOrder(dc, true, dcrBtcLotSize*100, dcrBtcRateStep)
+	loImmediate.Force = order.ImmediateTiF
+	immediateOID := loImmediate.ID()
+	immediateTracker := newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, fundingCoins, rig.core.notify, rig.core.formatDetails)
+	dc.trades[immediateOID] = immediateTracker
+
+	// 2. Standing limit order
+	loStanding, dbOrder, preImgL, _ := makeLimitOrder(dc, true, dcrBtcLotSize*100, dcrBtcRateStep)
+	loStanding.Force = order.StandingTiF
+	standingOID := loStanding.ID()
+	standingTracker := newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, fundingCoins, rig.core.notify, rig.core.formatDetails)
+	dc.trades[standingOID] = standingTracker
+
+	// 3. Cancel order.
+	cancelOrder := &order.CancelOrder{
+		P: order.Prefix{
+			ServerTime: time.Now(),
+		},
+	}
+	cancelOID := cancelOrder.ID()
+	standingTracker.cancel = &trackedCancel{
+		CancelOrder: *cancelOrder,
+	}
+
+	// 4. Market order.
+	loWillBeMarket, dbOrder, preImgL, _ := makeLimitOrder(dc, true, dcrBtcLotSize*100, dcrBtcRateStep)
+	mktOrder := &order.MarketOrder{
+		P: loWillBeMarket.P,
+		T: *loWillBeMarket.Trade().Copy(),
+	}
+	dbOrder.Order = mktOrder
+	marketOID := mktOrder.ID()
+	marketTracker := newTrackedTrade(dbOrder, preImgL, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, fundingCoins, rig.core.notify, rig.core.formatDetails)
+	dc.trades[marketOID] = marketTracker
+
+	runNomatch := func(tag string, oid order.OrderID) {
+		tracker, _, _ := dc.findOrder(oid)
+		if tracker == nil {
+			t.Fatalf("%s: order ID not found", tag)
+		}
+		payload := &msgjson.NoMatch{OrderID: oid[:]}
+		req, _ := msgjson.NewRequest(dc.NextID(), msgjson.NoMatchRoute, payload)
+		err := handleNoMatchRoute(tCore, dc, req)
+		if err != nil {
+			t.Fatalf("handleNoMatchRoute error: %v", err)
+		}
+	}
+
+	checkTradeStatus := func(tag string, oid order.OrderID, expStatus order.OrderStatus) {
+		tracker, _, _ := dc.findOrder(oid)
+		if tracker.metaData.Status != expStatus {
+			t.Fatalf("%s: wrong status. expected %s, got %s", tag, expStatus, tracker.metaData.Status)
+		}
+		if rig.db.lastStatusID != oid {
+			t.Fatalf("%s: order status not stored", tag)
+		}
+		if rig.db.lastStatus != expStatus {
+			t.Fatalf("%s: wrong order status stored. expected %s, got %s", tag, expStatus, rig.db.lastStatus)
+		}
+		if expStatus == order.OrderStatusExecuted {
+			if tBtcWallet.returnedAddr != tracker.Trade().Address {
+				t.Fatalf("%s: redemption address not returned", tag)
+			}
+		}
+	}
+
+	runNomatch("cancel", cancelOID)
+	if rig.db.lastStatusID != cancelOID || rig.db.lastStatus != order.OrderStatusExecuted {
+		t.Fatalf("cancel status not updated")
+	}
+	if rig.db.linkedFromID != standingOID || !rig.db.linkedToID.IsZero() {
+		t.Fatalf("missed cancel not unlinked. wanted trade ID %s, got %s. wanted zeroed linked ID, got %s",
+			standingOID, rig.db.linkedFromID, rig.db.linkedToID)
+	}
+
+	runNomatch("standing limit", standingOID)
+	checkTradeStatus("standing limit", standingOID, order.OrderStatusBooked)
+
+	runNomatch("immediate", immediateOID)
+	checkTradeStatus("immediate", immediateOID, order.OrderStatusExecuted)
+
+	runNomatch("market", marketOID)
+	checkTradeStatus("market", marketOID, order.OrderStatusExecuted)
+
+	// Unknown order should error.
+	oid := ordertest.RandomOrderID()
+	payload := &msgjson.NoMatch{OrderID: oid[:]}
+	req, _ := msgjson.NewRequest(dc.NextID(), msgjson.NoMatchRoute, payload)
+	err = handleNoMatchRoute(tCore, dc, req)
+	if !errorHasCode(err, unknownOrderErr) {
+		t.Fatalf("wrong error for unknown order ID: %v", err)
+	}
+}
+
+func TestWalletSettings(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	rig.db.wallet = &db.Wallet{
+		Settings: map[string]string{
+			"abc": "123",
+		},
+	}
+	var assetID uint32 = 54321
+
+	// wallet not found
+	_, err := tCore.WalletSettings(assetID)
+	if !errorHasCode(err, missingWalletErr) {
+		t.Fatalf("wrong error for missing wallet: %v", err)
+	}
+
+	tCore.wallets[assetID] = &xcWallet{}
+
+	// db error
+	rig.db.walletErr = tErr
+	_, err = tCore.WalletSettings(assetID)
+	if !errorHasCode(err, dbErr) {
+		t.Fatalf("wrong error when expected db error: %v", err)
+	}
+	rig.db.walletErr = nil
+
+	// success
+	returnedSettings, err := tCore.WalletSettings(assetID)
+	if err != nil {
+		t.Fatalf("WalletSettings error: %v", err)
+	}
+
+	if len(returnedSettings) != 1 || returnedSettings["abc"] != "123" {
+		t.Fatalf("returned wallet settings are not correct: %v", returnedSettings)
+	}
+}
+
+func TestChangeAppPass(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	// Use the smarter crypter.
+	smartCrypter := newTCrypterSmart()
+	rig.crypter = smartCrypter
+	rig.core.newCrypter = func([]byte) encrypt.Crypter { return newTCrypterSmart() }
+	rig.core.reCrypter = func([]byte, []byte) (encrypt.Crypter, error) { return rig.crypter, smartCrypter.recryptErr }
+
+	tCore := rig.core
+	newTPW := []byte("apppass")
+
+	// App Password error
+	rig.crypter.(*tCrypterSmart).recryptErr = tErr
+	err := tCore.ChangeAppPass(tPW, newTPW)
+	if !errorHasCode(err, authErr) {
+		t.Fatalf("wrong error for password error: %v", err)
+	}
+	rig.crypter.(*tCrypterSmart).recryptErr = nil
+
+	oldCreds := tCore.credentials
+
+	rig.db.creds = nil
+	err = tCore.ChangeAppPass(tPW, newTPW)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(oldCreds.OuterKeyParams, tCore.credentials.OuterKeyParams) {
+		t.Fatalf("credentials not updated in Core")
+	}
+
+	if rig.db.creds == nil || !bytes.Equal(tCore.credentials.OuterKeyParams, rig.db.creds.OuterKeyParams) {
+		t.Fatalf("credentials not updated in DB")
+	}
+}
+
+func TestResetAppPass(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	crypter := newTCrypterSmart()
+	rig.crypter = crypter
+	rig.core.newCrypter = func([]byte) encrypt.Crypter { return crypter }
+	rig.core.reCrypter = func([]byte, []byte) (encrypt.Crypter, error) { return rig.crypter, crypter.recryptErr }
+
+	rig.core.credentials = nil
+	rig.core.InitializeClient(tPW, nil)
+
+	tCore := rig.core
+	seed, err := tCore.ExportSeed(tPW)
+	if err != nil {
+		t.Fatalf("seed export failed: %v", err)
+	}
+
+	// Invalid seed error
+	invalidSeed := seed[:24]
+	err = tCore.ResetAppPass(tPW, invalidSeed)
+	if !strings.Contains(err.Error(), "unabled to decode provided seed") {
+		t.Fatalf("wrong error for invalid seed length: %v", err)
+	}
+
+	// Want incorrect seed error.
+	rig.crypter.(*tCrypterSmart).recryptErr = tErr
+	// tCrypter is used to encode the orginal seed but we don't need it here, so
+	// we need to add 8 bytes to commplete the expected seed lenght(64).
+	err = tCore.ResetAppPass(tPW, seed+"blah")
+	if !strings.Contains(err.Error(), "unabled to decode provided seed") {
+		t.Fatalf("wrong error for incorrect seed: %v", err)
+	}
+
+	// ok, no crypter error.
+	rig.crypter.(*tCrypterSmart).recryptErr = nil
+	err = tCore.ResetAppPass(tPW, seed)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReconfigureWallet(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	rig.db.wallet = &db.Wallet{
+		Settings: map[string]string{
+			"abc": "123",
+		},
+	}
+	const assetID uint32 = 54321
+	xyzWallet, tXyzWallet := newTWallet(assetID)
+	newSettings := map[string]string{
+		"def": "456",
+	}
+
+	form := &WalletForm{
+		AssetID: assetID,
+		Config:  newSettings,
+		Type:    "type",
+	}
+	xyzWallet.walletType = "type"
+
+	// App Password error
+	rig.crypter.(*tCrypter).recryptErr = tErr
+	err := tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, authErr) {
+		t.Fatalf("wrong error for password error: %v", err)
+	}
+	rig.crypter.(*tCrypter).recryptErr = nil
+
+	// Missing wallet error
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, assetSupportErr) {
+		t.Fatalf("wrong error for missing wallet definition: %v", err)
+	}
+
+	walletDef := &asset.WalletDefinition{
+		Type:   "type",
+		Seeded: true,
+	}
+	winfo := *tWalletInfo
+	winfo.AvailableWallets = []*asset.WalletDefinition{walletDef}
+
+	assetDriver := &tCreator{
+		tDriver: &tDriver{
+			wallet: xyzWallet.Wallet,
+			winfo:  &winfo,
+		},
+	}
+	asset.Register(assetID, assetDriver)
+	if err = xyzWallet.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer xyzWallet.Disconnect()
+
+	// Missing wallet error
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, missingWalletErr) {
+		t.Fatalf("wrong error for missing wallet: %v", err)
+	}
+
+	tCore.wallets[assetID] = xyzWallet
+
+	// Errors for seeded wallets.
+	walletDef.Seeded = true
+	// Exists error
+	assetDriver.existsErr = tErr
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, existenceCheckErr) {
+		t.Fatalf("wrong error when expecting existence check error: %v", err)
+	}
+	assetDriver.existsErr = nil
+	// Create error
+	assetDriver.doesntExist = true
+	assetDriver.createErr = tErr
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, createWalletErr) {
+		t.Fatalf("wrong error when expecting wallet creation error error: %v", err)
+	}
+	assetDriver.createErr = nil
+	walletDef.Seeded = false
+
+	// Connect error
+	tXyzWallet.connectErr = tErr
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, connectWalletErr) {
+		t.Fatalf("wrong error when expecting connection error: %v", err)
+	}
+	tXyzWallet.connectErr = nil
+
+	// Unlock error
+	tXyzWallet.Unlock(wPW)
+	tXyzWallet.unlockErr = tErr
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, walletAuthErr) {
+		t.Fatalf("wrong error when expecting auth error: %v", err)
+	}
+	tXyzWallet.unlockErr = nil
+
+	// For the last success, make sure that we also clear any related
+	// tickGovernors.
+	abcWallet, _ := newTWallet(tUTXOAssetA.ID) // for to/baseWallet
+	matchID := ordertest.RandomMatchID()
+	match := &matchTracker{
+		suspectSwap:  true,
+		tickGovernor: time.NewTimer(time.Hour),
+		MetaMatch: db.MetaMatch{
+			MetaData: &db.MatchMetaData{
+				Proof: db.MatchProof{
+					ContractData: dex.Bytes{0},
+				},
+			},
+			UserMatch: &order.UserMatch{
+				MatchID: matchID,
+			},
+		},
+	}
+	tCore.conns[tDexHost].tradeMtx.Lock()
+	tCore.conns[tDexHost].trades[order.OrderID{}] = &trackedTrade{
+		Order: &order.LimitOrder{
+			P: order.Prefix{
+				BaseAsset:  assetID,
+				ServerTime: time.Now(),
+			},
+		},
+		wallets: &walletSet{
+			fromWallet:  xyzWallet,
+			quoteWallet: xyzWallet, // sell=false
+			toWallet:    abcWallet,
+			baseWallet:  abcWallet,
+		},
+		matches: map[order.MatchID]*matchTracker{
+			{}: match,
+		},
+		metaData:    &db.OrderMetaData{},
+		dc:          rig.dc,
+		readyToTick: true, // prevent resume path
+	}
+	tCore.conns[tDexHost].tradeMtx.Unlock()
+
+	// Error checking if wallet owns address.
+	tXyzWallet.ownsAddressErr = tErr
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, walletErr) {
+		t.Fatalf("wrong error when expecting ownsAddress wallet error: %v", err)
+	}
+	tXyzWallet.ownsAddressErr = nil
+
+	// Wallet doesn't own address.
+	tXyzWallet.ownsAddress = false
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, walletErr) {
+		t.Fatalf("wrong error when expecting not owned wallet error: %v", err)
+	}
+
+	// Leave the ownsAddress false, but swap out a LiveReconfigurer and ensure
+	// the restart = false path passes.
+	liveReconfigurer := &TLiveReconfigurer{TXCWallet: tXyzWallet}
+	xyzWallet.Wallet = liveReconfigurer
+	if err = tCore.ReconfigureWallet(tPW, nil, form); err != nil {
+		t.Fatalf("ReconfigureWallet error for short path: %v", err)
+	}
+
+	// But restart = true should still fail for live orders.
+	liveReconfigurer.restart = true
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, walletErr) {
+		t.Fatalf("wrong error when expecting not owned wallet error: %v", err)
+	}
+	liveReconfigurer.restart = false
+
+	// OwnsAddress error
+	liveReconfigurer.ownsAddressErr = tErr
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, walletErr) {
+		t.Fatalf("wrong error when expecting ownsAddress wallet error without restart: %v", err)
+	}
+	liveReconfigurer.ownsAddressErr = nil
+
+	// Refresh address error
+	liveReconfigurer.addrErr = tErr
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, newAddrErr) {
+		t.Fatalf("wrong error when expecting address refresh error without restart: %v", err)
+	}
+	liveReconfigurer.addrErr = nil
+
+	// Password error for non-seeded wallet with password.
+	// from above: walletDef.Seeded = false
+	liveReconfigurer.unlockErr = tErr
+	err = tCore.ReconfigureWallet(tPW, append(tPW, 5), form)
+	if !errorHasCode(err, walletAuthErr) {
+		t.Fatalf("wrong error when expecting new password error without restart: %v", err)
+	}
+	liveReconfigurer.unlockErr = nil
+
+	// DB error for restartless path.
+	rig.db.updateWalletErr = tErr
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if !errorHasCode(err, dbErr) {
+		t.Fatalf("wrong error when db update error without restart: %v", err)
+	}
+	rig.db.updateWalletErr = nil
+
+	// End LiveReconfigurer tests.
+	xyzWallet.Wallet = tXyzWallet
+	tXyzWallet.ownsAddress = true
+
+	// Success updating settings.
+	err = tCore.ReconfigureWallet(tPW, nil, form)
+	if err != nil {
+		t.Fatalf("ReconfigureWallet error: %v", err)
+	}
+
+	settings := rig.db.wallet.Settings
+	if len(settings) != 1 || settings["def"] != "456" {
+		t.Fatalf("settings not stored")
+	}
+
+	if match.tickGovernor != nil {
+		t.Fatalf("tickGovernor not removed")
+	}
+
+	// Success updating wallet PW.
+	newWalletPW := []byte("password")
+	err = tCore.ReconfigureWallet(tPW, newWalletPW, form)
+	if err != nil {
+		t.Fatalf("ReconfigureWallet error: %v", err)
+	}
+
+	// Check that the xcWallet was updated.
+	xyzWallet = tCore.wallets[assetID]
+	decNewPW, _ := rig.crypter.Decrypt(xyzWallet.encPW())
+	if !bytes.Equal(decNewPW, newWalletPW) {
+		t.Fatalf("xcWallet encPW field not updated want: %x got: %x",
+			newWalletPW, decNewPW)
+	}
+}
+
+func TestSetWalletPassword(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	rig.db.wallet = &db.Wallet{
+		EncryptedPW: []byte("abc"),
+	}
+	newPW := []byte("def")
+	var assetID uint32 = 54321
+
+	// Nil password error
+	err := tCore.SetWalletPassword(tPW, assetID, nil)
+	if !errorHasCode(err, passwordErr) {
+		t.Fatalf("wrong error for nil password error: %v", err)
+	}
+
+	// Auth error
+	rig.crypter.(*tCrypter).recryptErr = tErr
+	err = tCore.SetWalletPassword(tPW, assetID, newPW)
+	if !errorHasCode(err, authErr) {
+		t.Fatalf("wrong error for auth error: %v", err)
+	}
+	rig.crypter.(*tCrypter).recryptErr = nil
+
+	// Missing wallet error
+	err = tCore.SetWalletPassword(tPW, assetID, newPW)
+	if !errorHasCode(err, missingWalletErr) {
+		t.Fatalf("wrong error for missing wallet: %v", err)
+	}
+
+	xyzWallet, tXyzWallet := newTWallet(assetID)
+	tCore.wallets[assetID] = xyzWallet
+
+	// Connection error
+	xyzWallet.hookedUp = false
+	tXyzWallet.connectErr = tErr
+	err = tCore.SetWalletPassword(tPW, assetID, newPW)
+	if !errorHasCode(err, connectionErr) {
+		t.Fatalf("wrong error for connection error: %v", err)
+	}
+	xyzWallet.hookedUp = true
+	tXyzWallet.connectErr = nil
+
+	// Unlock error
+	tXyzWallet.unlockErr = tErr
+	err = tCore.SetWalletPassword(tPW, assetID, newPW)
+	if !errorHasCode(err, authErr) {
+		t.Fatalf("wrong error for auth error: %v", err)
+	}
+	tXyzWallet.unlockErr = nil
+
+	// SetWalletPassword db error
+	rig.db.setWalletPwErr = tErr
+	err = tCore.SetWalletPassword(tPW, assetID, newPW)
+	if !errorHasCode(err, dbErr) {
+		t.Fatalf("wrong error for missing wallet: %v", err)
+	}
+	rig.db.setWalletPwErr = nil
+
+	// Success
+	err = tCore.SetWalletPassword(tPW, assetID, newPW)
+	if err != nil {
+		t.Fatalf("SetWalletPassword error: %v", err)
+	}
+
+	// Check that the xcWallet was updated.
+	decNewPW, _ := rig.crypter.Decrypt(xyzWallet.encPW())
+	if !bytes.Equal(decNewPW, newPW) {
+		t.Fatalf("xcWallet encPW field not updated")
+	}
+}
+
+func TestHandlePenaltyMsg(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+	penalty := &msgjson.Penalty{
+		Rule:    account.Rule(1),
+		Time:    uint64(1598929305),
+		Details: "You may no longer trade. Leave your client running to finish pending trades.",
+	}
+	diffKey, _ := secp256k1.GeneratePrivateKey()
+	noMatch, err := msgjson.NewNotification(msgjson.NoMatchRoute, "fake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		key     *secp256k1.PrivateKey
+		payload any
+		wantErr bool
+	}{{
+		name:    "ok",
+		key:     tDexPriv,
+		payload: penalty,
+	}, {
+		name:    "bad note",
+		key:     tDexPriv,
+		payload: noMatch,
+		wantErr: true,
+	}, {
+		name:    "wrong sig",
+		key:     diffKey,
+		payload: penalty,
+		wantErr: true,
+	}}
+	for _, test := range tests {
+		var err error
+		var note *msgjson.Message
+		switch v := test.payload.(type) {
+		case *msgjson.Penalty:
+			penaltyNote := &msgjson.PenaltyNote{
+				Penalty: v,
+			}
+			sign(test.key, penaltyNote)
+			note, err = msgjson.NewNotification(msgjson.PenaltyRoute, penaltyNote)
+			if err != nil {
+				t.Fatalf("error creating penalty notification: %v", err)
+			}
+		case *msgjson.Message:
+			note = v
+		default:
+			t.Fatalf("unknown payload type: %T", v)
+		}
+
+		err = handlePenaltyMsg(tCore, dc, note)
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("expected error for test %s", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+	}
+}
+
+func TestHandleAnnouncementMsg(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+	feed := tCore.NotificationFeed()
+	defer feed.ReturnFeed()
+
+	expiry := uint64(time.Now().Add(time.Hour).UnixMilli())
+	announce := func(ann *msgjson.Announcement) {
+		t.Helper()
+		note, err := msgjson.NewNotification(msgjson.AnnouncementRoute, ann)
+		if err != nil {
+			t.Fatalf("error creating announcement notification: %v", err)
+		}
+		if err := handleAnnouncementMsg(tCore, dc, note); err != nil {
+			t.Fatalf("handleAnnouncementMsg error: %v", err)
+		}
+	}
+	checkNote := func(tag string, wantSeverity db.Severity) {
+		t.Helper()
+		select {
+		case n := <-feed.C:
+			if n.Topic() != TopicDEXAnnouncement {
+				t.Fatalf("%s: wrong topic %s", tag, n.Topic())
+			}
+			if n.Severity() != wantSeverity {
+				t.Fatalf("%s: wrong severity %v, wanted %v", tag, n.Severity(), wantSeverity)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: no notification", tag)
+		}
+	}
+	checkNoNote := func(tag string) {
+		t.Helper()
+		select {
+		case n := <-feed.C:
+			t.Fatalf("%s: unexpected notification %s", tag, n.Topic())
+		default:
+		}
+	}
+
+	ann := &msgjson.Announcement{
+		ID:       1,
+		Severity: msgjson.AnnouncementWarning,
+		Message:  "maintenance at noon",
+		Link:     "https://example.com",
+		Expiry:   expiry,
+	}
+	announce(ann)
+	checkNote("first", db.WarningLevel)
+
+	// Resent on reconnect.
+	announce(ann)
+	checkNoNote("repeat")
+
+	// Expired.
+	announce(&msgjson.Announcement{ID: 2, Severity: msgjson.AnnouncementInfo, Message: "old news", Expiry: 1})
+	checkNoNote("expired")
+
+	announce(&msgjson.Announcement{ID: 3, Severity: msgjson.AnnouncementCritical, Message: "halting", Expiry: expiry})
+	checkNote("critical", db.ErrorLevel)
+
+	bad, _ := msgjson.NewNotification(msgjson.AnnouncementRoute, "not an announcement")
+	if err := handleAnnouncementMsg(tCore, dc, bad); err == nil {
+		t.Fatalf("no error for bad announcement payload")
+	}
+}
+
+func TestPreimageSync(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.Unlock(rig.crypter)
+
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.Unlock(rig.crypter)
+
+	var lots uint64 = 10
+	qty := dcrBtcLotSize * lots
+	rate := dcrBtcRateStep * 1000
+
+	form := &TradeForm{
+		Host:    tDexHost,
+		IsLimit: true,
+		Sell:    true,
+		Base:    tUTXOAssetA.ID,
+		Quote:   tUTXOAssetB.ID,
+		Qty:     qty,
+		Rate:    rate,
+		TifNow:  false,
+	}
+
+	dcrCoin := &tCoin{
+		id:  encode.RandomBytes(36),
+		val: qty * 2,
+	}
+	tDcrWallet.fundingCoins = asset.Coins{dcrCoin}
+	tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
+
+	btcVal := calc.BaseToQuote(rate, qty*2)
+	btcCoin := &tCoin{
+		id:  encode.RandomBytes(36),
+		val: btcVal,
+	}
+	tBtcWallet.fundingCoins = asset.Coins{btcCoin}
+	tBtcWallet.fundRedeemScripts = []dex.Bytes{nil}
+
+	limitRouteProcessing := make(chan order.OrderID)
+	var commit order.Commitment
+
+	rig.ws.queueResponse(msgjson.LimitRoute, func(msg *msgjson.Message, f msgFunc) error {
+		t.Helper()
+		// Need to stamp and sign the message with the server's key.
+		msgOrder := new(msgjson.LimitOrder)
+		err := msg.Unmarshal(msgOrder)
+		if err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		lo := convertMsgLimitOrder(msgOrder)
+		resp := orderResponse(msg.ID, msgOrder, lo, false, false, false)
+		limitRouteProcessing <- lo.ID()
+		commit = lo.Commit // accessed below only after errChan receive indicating Trade done
+		f(resp)            // e.g. the UnmarshalJSON in sendRequest
+		return nil
+	})
+
+	errChan := make(chan error, 1)
+	// Run the trade in a goroutine.
+	go func() {
+		_, err := tCore.Trade(tPW, form)
+		errChan <- err
+	}()
+
+	// Wait for the limit route to start processing. Then we have 100 ms to call
+	// handlePreimageRequest to catch the early-preimage case.
+	var oid order.OrderID
+	select {
+	case oid = <-limitRouteProcessing:
+	case <-time.After(time.Second):
+		t.Fatalf("limit route never hit")
+	}
+
+	err := <-errChan
+	if err != nil {
+		t.Fatalf("trade error: %v", err)
+	}
+
+	// So ideally, we're calling handlePreimageRequest about 100 ms before we
+	// even have an order id back from the server. This shouldn't result in an
+	// error.
+	payload := &msgjson.PreimageRequest{
+		OrderID:    oid[:],
+		Commitment: commit[:],
+	}
+	req, _ := msgjson.NewRequest(rig.dc.NextID(), msgjson.PreimageRoute, payload)
+	err = handlePreimageRequest(rig.core, rig.dc, req)
+	if err != nil {
+		t.Fatalf("early preimage request error: %v", err)
+	}
+}
+
+func TestAccelerateOrder(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tDcrWallet.swapSize = tSwapSizeA
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tBtcWallet.swapSize = tSwapSizeB
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+
+	buyWalletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, false)
+	sellWalletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, false)
+
+	var newBaseFeeRate uint64 = 55
+	var newQuoteFeeRate uint64 = 65
+	feeRateSource := func(msg *msgjson.Message, f msgFunc) error {
+		var resp *msgjson.Message
+		if string(msg.Payload) == "42" {
+			resp, _ = msgjson.NewResponse(msg.ID, newBaseFeeRate, nil)
+		} else {
+			resp, _ = msgjson.NewResponse(msg.ID, newQuoteFeeRate, nil)
+		}
+		f(resp)
+		return nil
+	}
+
+	type testMatch struct {
+		status   order.MatchStatus
+		quantity uint64
+		rate     uint64
+		side     order.MatchSide
+	}
+
+	tests := []struct {
+		name                       string
+		orderQuantity              uint64
+		orderFilled                uint64
+		orderStatus                order.OrderStatus
+		rate                       uint64
+		sell                       bool
+		previousAccelerations      []order.CoinID
+		matches                    []testMatch
+		expectRequiredForRemaining uint64
+		expectError                bool
+		orderIDIncorrectLength     bool
+		nonActiveOrderID           bool
+		accelerateOrderError       bool
+		nilChangeCoin              bool
+		nilNewChangeCoin           bool
+	}{
+		{
+			name:                       "ok",
+			orderQuantity:              3 * dcrBtcLotSize,
+			orderFilled:                dcrBtcLotSize,
+			previousAccelerations:      []order.CoinID{encode.RandomBytes(32)},
+			orderStatus:                order.OrderStatusExecuted,
+			rate:                       dcrBtcRateStep * 10,
+			expectRequiredForRemaining: 2*tMaxFeeRate*tSwapSizeB + calc.BaseToQuote(dcrBtcRateStep*10, 2*dcrBtcLotSize),
+			matches: []testMatch{
+				{
+					side:     order.Maker,
+					status:   order.TakerSwapCast,
+					quantity: dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+			},
+		},
+		{
+			name:                       "ok - unswapped match, buy",
+			orderQuantity:              8 * dcrBtcLotSize,
+			orderFilled:                5 * dcrBtcLotSize,
+			orderStatus:                order.OrderStatusExecuted,
+			previousAccelerations:      []order.CoinID{encode.RandomBytes(32), encode.RandomBytes(32)},
+			rate:                       dcrBtcRateStep * 10,
+			expectRequiredForRemaining: 4*tMaxFeeRate*tSwapSizeB + calc.BaseToQuote(dcrBtcRateStep*10, 5*dcrBtcLotSize),
+			matches: []testMatch{
+				{
+					side:     order.Maker,
+					status:   order.TakerSwapCast,
+					quantity: dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+				{
+					side:     order.Taker,
+					status:   order.TakerSwapCast,
+					quantity: 2 * dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+				{
+					side:     order.Taker,
+					status:   order.MakerSwapCast,
+					quantity: 2 * dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+			},
+		},
+		{
+			name:                       "ok - unswapped match, sell",
+			sell:                       true,
+			previousAccelerations:      []order.CoinID{encode.RandomBytes(32), encode.RandomBytes(32)},
+			orderQuantity:              8 * dcrBtcLotSize,
+			orderFilled:                5 * dcrBtcLotSize,
+			orderStatus:                order.OrderStatusExecuted,
+			rate:                       dcrBtcRateStep * 10,
+			expectRequiredForRemaining: 4*tMaxFeeRate*tSwapSizeB + 5*dcrBtcLotSize,
+			matches: []testMatch{
+				{
+					side:     order.Maker,
+					status:   order.TakerSwapCast,
+					quantity: dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+				{
+					side:     order.Taker,
+					status:   order.TakerSwapCast,
+					quantity: 2 * dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+				{
+					side:     order.Taker,
+					status:   order.MakerSwapCast,
+					quantity: 2 * dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+			},
+		},
+		{
+			name: "10 previous accelerations",
+			sell: true,
+			previousAccelerations: []order.CoinID{encode.RandomBytes(32), encode.RandomBytes(32),
+				encode.RandomBytes(32), encode.RandomBytes(32),
+				encode.RandomBytes(32), encode.RandomBytes(32),
+				encode.RandomBytes(32), encode.RandomBytes(32),
+				encode.RandomBytes(32), encode.RandomBytes(32)},
+			orderQuantity: 8 * dcrBtcLotSize,
+			orderFilled:   5 * dcrBtcLotSize,
+			orderStatus:   order.OrderStatusExecuted,
+			rate:          dcrBtcRateStep * 10,
+			matches: []testMatch{
+				{
+					side:     order.Maker,
+					status:   order.TakerSwapCast,
+					quantity: dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+			},
+			expectError: true,
+		},
+		{
+			name:          "no matches",
+			orderQuantity: 3 * dcrBtcLotSize,
+			orderFilled:   dcrBtcLotSize,
+			orderStatus:   order.OrderStatusExecuted,
+			rate:          dcrBtcRateStep * 10,
+			matches:       []testMatch{},
+			expectError:   true,
+		},
+		{
+			name:          "no swap coins",
+			orderQuantity: 3 * dcrBtcLotSize,
+			orderFilled:   dcrBtcLotSize,
+			orderStatus:   order.OrderStatusExecuted,
+			rate:          dcrBtcRateStep * 10,
+			matches: []testMatch{{
+				side:     order.Taker,
+				status:   order.MakerSwapCast,
+				quantity: 2 * dcrBtcLotSize,
+				rate:     dcrBtcRateStep * 10,
+			}},
+			expectError: true,
+		},
+		{
+			name:          "incorrect length order id",
+			orderQuantity: 3 * dcrBtcLotSize,
+			orderFilled:   dcrBtcLotSize,
+			orderStatus:   order.OrderStatusExecuted,
+			rate:          dcrBtcRateStep * 10,
+			matches: []testMatch{
+				{
+					side:     order.Maker,
+					status:   order.TakerSwapCast,
+					quantity: dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+			},
+			orderIDIncorrectLength: true,
+			expectError:            true,
+		},
+		{
+			name:          "incorrect length order id",
+			orderQuantity: 3 * dcrBtcLotSize,
+			orderFilled:   dcrBtcLotSize,
+			orderStatus:   order.OrderStatusExecuted,
+			rate:          dcrBtcRateStep * 10,
+			matches: []testMatch{
+				{
+					side:     order.Maker,
+					status:   order.TakerSwapCast,
+					quantity: dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+			},
+			nonActiveOrderID: true,
+			expectError:      true,
+		},
+		{
+			name:          "accelerate order err",
+			orderQuantity: 3 * dcrBtcLotSize,
+			orderFilled:   dcrBtcLotSize,
+			orderStatus:   order.OrderStatusExecuted,
+			rate:          dcrBtcRateStep * 10,
+			matches: []testMatch{
+				{
+					side:     order.Maker,
+					status:   order.TakerSwapCast,
+					quantity: dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+			},
+			accelerateOrderError: true,
+			expectError:          true,
+		},
+		{
+			name:          "nil change coin",
+			orderQuantity: 3 * dcrBtcLotSize,
+			orderFilled:   dcrBtcLotSize,
+			orderStatus:   order.OrderStatusExecuted,
+			rate:          dcrBtcRateStep * 10,
+			matches: []testMatch{
+				{
+					side:     order.Maker,
+					status:   order.TakerSwapCast,
+					quantity: dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+			},
+			nilChangeCoin: true,
+			expectError:   true,
+		},
+		{
+			name:                       "nil new change coin",
+			orderQuantity:              3 * dcrBtcLotSize,
+			orderFilled:                dcrBtcLotSize,
+			orderStatus:                order.OrderStatusExecuted,
+			rate:                       dcrBtcRateStep * 10,
+			expectRequiredForRemaining: 2*tMaxFeeRate*tSwapSizeB + calc.BaseToQuote(dcrBtcRateStep*10, 2*dcrBtcLotSize),
+			matches: []testMatch{
+				{
+					side:     order.Maker,
+					status:   order.TakerSwapCast,
+					quantity: dcrBtcLotSize,
+					rate:     dcrBtcRateStep * 10,
+				},
+			},
+			nilNewChangeCoin: true,
+		},
+	}
+
+	for _, test := range tests {
+		tBtcWallet.accelerateOrderErr = nil
+		lo, dbOrder, preImg, addr := makeLimitOrder(dc, test.sell, test.orderQuantity, test.rate)
+		dbOrder.MetaData.Status = test.orderStatus // so there is no order_status request for this
+		oid := lo.ID()
+		var walletSet *walletSet
+		if test.sell {
+			walletSet = sellWalletSet
+		} else {
+			walletSet = buyWalletSet
+		}
+		trade := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+			rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+		dc.trades[trade.ID()] = trade
+		trade.Trade().AddFill(test.orderFilled)
+
+		trade.metaData.ChangeCoin = encode.RandomBytes(32)
+		originalChangeCoin := trade.metaData.ChangeCoin
+		trade.metaData.AccelerationCoins = test.previousAccelerations
+		newChangeCoinID := dex.Bytes(encode.RandomBytes(32))
+		if test.nilNewChangeCoin {
+			tBtcWallet.newChangeCoinID = nil
+		} else {
+			tBtcWallet.newChangeCoinID = &newChangeCoinID
+		}
+		tBtcWallet.newAccelerationTxID = hex.EncodeToString(encode.RandomBytes(32))
+		trade.matches = make(map[order.MatchID]*matchTracker)
+		expectedSwapCoins := make([]order.CoinID, 0, len(test.matches))
+		for _, testMatch := range test.matches {
+			matchID := ordertest.RandomMatchID()
+			match := &matchTracker{
+				MetaMatch: db.MetaMatch{
+					MetaData: &db.MatchMetaData{
+						Proof: db.MatchProof{
+							MakerSwap: encode.RandomBytes(32),
+							TakerSwap: encode.RandomBytes(32),
+						},
+					},
+					UserMatch: &order.UserMatch{
+						MatchID:  matchID,
+						Address:  addr,
+						Side:     testMatch.side,
+						Status:   testMatch.status,
+						Quantity: testMatch.quantity,
+						Rate:     testMatch.rate,
+					},
+				},
+			}
+			if testMatch.side == order.Maker && testMatch.status >= order.MakerSwapCast {
+				expectedSwapCoins = append(expectedSwapCoins, match.MetaData.Proof.MakerSwap)
+			}
+			if testMatch.side == order.Taker && testMatch.status >= order.TakerSwapCast {
+				expectedSwapCoins = append(expectedSwapCoins, match.MetaData.Proof.TakerSwap)
+			}
+			trade.matches[matchID] = match
+		}
+		orderIDBytes := oid.Bytes()
+		if test.orderIDIncorrectLength {
+			orderIDBytes = encode.RandomBytes(31)
+		}
+		if test.nonActiveOrderID {
+			orderIDBytes = encode.RandomBytes(32)
+		}
+		if test.accelerateOrderError {
+			tBtcWallet.accelerateOrderErr = errors.New("")
+		}
+		if test.nilChangeCoin {
+			trade.metaData.ChangeCoin = nil
+		}
+
+		checkCommonCallValues := func() {
+			t.Helper()
+			swapCoins := tBtcWallet.accelerationParams.swapCoins
+			if len(swapCoins) != len(expectedSwapCoins) {
+				t.Fatalf("expected %d swap coins but got %d", len(expectedSwapCoins), len(swapCoins))
+			}
+
+			sort.Slice(swapCoins, func(i, j int) bool { return bytes.Compare(swapCoins[i], swapCoins[j]) > 0 })
+			sort.Slice(expectedSwapCoins, func(i, j int) bool { return bytes.Compare(expectedSwapCoins[i], expectedSwapCoins[j]) > 0 })
+
+			for i := range swapCoins {
+				if !bytes.Equal(swapCoins[i], expectedSwapCoins[i]) {
+					t.Fatalf("expected swap coins not the same as actual")
+				}
+			}
+
+			changeCoin := tBtcWallet.accelerationParams.changeCoin
+			if !bytes.Equal(changeCoin, originalChangeCoin) {
+				t.Fatalf("change coin not same as expected %x - %x", changeCoin, trade.metaData.ChangeCoin)
+			}
+
+			accelerationCoins := tBtcWallet.accelerationParams.accelerationCoins
+			if len(accelerationCoins) != len(test.previousAccelerations) {
+				t.Fatalf("expected 1 acceleration tx but got %v", len(accelerationCoins))
+			}
+			for i := range accelerationCoins {
+				if !bytes.Equal(accelerationCoins[i], test.previousAccelerations[i]) {
+					t.Fatalf("expected acceleration coin not the same as actual")
+				}
+			}
+		}
+
+		checkRequiredForRemainingSwaps := func() {
+			t.Helper()
+			if tBtcWallet.accelerationParams.requiredForRemainingSwaps != test.expectRequiredForRemaining {
+				t.Fatalf("expected requiredForRemainingSwaps %d, but got %d", test.expectRequiredForRemaining,
+					tBtcWallet.accelerationParams.requiredForRemainingSwaps)
+			}
+		}
+
+		testAccelerateOrder := func() {
+			newFeeRate := rand.Uint64()
+			txID, err := tCore.AccelerateOrder(tPW, orderIDBytes, newFeeRate)
+			if test.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but did not get")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			checkCommonCallValues()
+			checkRequiredForRemainingSwaps()
+
+			if test.nilNewChangeCoin {
+				if tBtcWallet.newChangeCoinID != nil {
+					t.Fatalf("expected coin on order to be nil, but got %x", tBtcWallet.newChangeCoinID)
+				}
+			} else {
+				if !bytes.Equal(trade.metaData.ChangeCoin, *tBtcWallet.newChangeCoinID) {
+					t.Fatalf("change coin on trade was not updated to return value from AccelerateOrder")
+				}
+				if !bytes.Equal(trade.metaData.AccelerationCoins[len(trade.metaData.AccelerationCoins)-1], *tBtcWallet.newChangeCoinID) {
+					t.Fatalf("new acceleration transaction id was not added to the trade")
+				}
+
+				var inCoinsList bool
+				for _, coin := range trade.coins {
+					if bytes.Equal(coin.ID(), *tBtcWallet.newChangeCoinID) {
+						inCoinsList = true
+					}
+				}
+				if !inCoinsList {
+					t.Fatalf("new change coin must be added to the trade.coins slice")
+				}
+			}
+			if txID != tBtcWallet.newAccelerationTxID {
+				t.Fatalf("new acceleration transaction id was not returned from AccelerateOrder")
+			}
+			if newFeeRate != tBtcWallet.accelerationParams.newFeeRate {
+				t.Fatalf("%s: expected new fee rate %d, but got %d", test.name,
+					newFeeRate, tBtcWallet.accelerationParams.newFeeRate)
+			}
+		}
+
+		testPreAccelerate := func() {
+			rig.ws.queueResponse(msgjson.FeeRateRoute, feeRateSource)
+			tBtcWallet.preAccelerateSwapRate = rand.Uint64()
+			tBtcWallet.preAccelerateSuggestedRange = asset.XYRange{
+				Start: asset.XYRangePoint{
+					Label: "startLabel",
+					X:     rand.Float64(),
+					Y:     rand.Float64(),
+				},
+				End: asset.XYRangePoint{
+					Label: "endLabel",
+					X:     rand.Float64(),
+					Y:     rand.Float64(),
+				},
+				XUnit: "x",
+				YUnit: "y",
+			}
+
+			preAccelerate, err := tCore.PreAccelerateOrder(orderIDBytes)
+			if test.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but did not get")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", test.name, err)
+			}
+
+			checkCommonCallValues()
+			checkRequiredForRemainingSwaps()
+
+			if !test.sell && preAccelerate.SuggestedRate != newQuoteFeeRate {
+				t.Fatalf("%s: expected fee suggestion to be %d, but got %d",
+					test.name, newQuoteFeeRate, preAccelerate.SuggestedRate)
+			}
+			if test.sell && preAccelerate.SuggestedRate != newBaseFeeRate {
+				t.Fatalf("%s: expected fee suggestion to be %d, but got %d",
+					test.name, newBaseFeeRate, preAccelerate.SuggestedRate)
+			}
+			if preAccelerate.SwapRate != tBtcWallet.preAccelerateSwapRate {
+				t.Fatalf("%s: expected pre accelerate swap rate %d, but got %d",
+					test.name, tBtcWallet.preAccelerateSwapRate, preAccelerate.SwapRate)
+			}
+			if !reflect.DeepEqual(preAccelerate.SuggestedRange,
+				tBtcWallet.preAccelerateSuggestedRange) {
+				t.Fatalf("%s: PreAccelerate suggested range not same as expected",
+					test.name)
+			}
+		}
+
+		testMaxAcceleration := func() {
+			t.Helper()
+			tBtcWallet.accelerationEstimate = rand.Uint64()
+			newFeeRate := rand.Uint64()
+			estimate, err := tCore.AccelerationEstimate(orderIDBytes, newFeeRate)
+			if test.expectError {
+				if err == nil {
+					t.Fatalf("expected error, but did not get")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", test.name, err)
+			}
+
+			checkCommonCallValues()
+			checkRequiredForRemainingSwaps()
+
+			if newFeeRate != tBtcWallet.accelerationParams.newFeeRate {
+				t.Fatalf("%s: expected new fee rate %d, but got %d", test.name,
+					newFeeRate, tBtcWallet.accelerationParams.newFeeRate)
+			}
+			if estimate != tBtcWallet.accelerationEstimate {
+				t.Fatalf("%s: expected acceleration estimate %d, but got %d",
+					test.name, tBtcWallet.accelerationEstimate, estimate)
+			}
+		}
+
+		testPreAccelerate()
+		testMaxAcceleration()
+		testAccelerateOrder()
+	}
+}
+
+func TestMatchStatusResolution(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+
+	qty := 3 * dcrBtcLotSize
+	secret := encode.RandomBytes(32)
+	secretHash := sha256.Sum256(secret)
+
+	lo, dbOrder, preImg, addr := makeLimitOrder(dc, true, qty, dcrBtcRateStep*10)
+	dbOrder.MetaData.Status = order.OrderStatusExecuted // so there is no order_status request for this
+	oid := lo.ID()
+	trade := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+
+	dc.trades[trade.ID()] = trade
+	matchID := ordertest.RandomMatchID()
+	matchTime := time.Now()
+	match := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			MetaData: &db.MatchMetaData{},
+			UserMatch: &order.UserMatch{
+				MatchID: matchID,
+				Address: addr,
+			},
+		},
+	}
+	trade.matches[matchID] = match
+
+	// oid order.OrderID, mid order.MatchID, recipient string, val uint64, secretHash []byte
+	_, auditInfo := tMsgAudit(oid, matchID, addr, qty, secretHash[:])
+	tBtcWallet.auditInfo = auditInfo
+
+	connectMatches := func(status order.MatchStatus) []*msgjson.Match {
+		return []*msgjson.Match{
+			{
+				OrderID: oid[:],
+				MatchID: matchID[:],
+				Status:  uint8(status),
+				Side:    uint8(match.Side),
+			},
+		}
+
+	}
+
+	tBytes := encode.RandomBytes(2)
+	tCoinID := encode.RandomBytes(36)
+	tTxData := encode.RandomBytes(1)
+
+	setAuthSigs := func(status order.MatchStatus) {
+		isMaker := match.Side == order.Maker
+		match.MetaData.Proof.Auth = db.MatchAuth{}
+		auth := &match.MetaData.Proof.Auth
+		auth.MatchStamp = uint64(matchTime.UnixMilli())
+		if status >= order.MakerSwapCast {
+			if isMaker {
+				auth.InitSig = tBytes
+			} else {
+				auth.AuditSig = tBytes
+			}
+		}
+		if status >= order.TakerSwapCast {
+			if isMaker {
+				auth.AuditSig = tBytes
+			} else {
+				auth.InitSig = tBytes
+			}
+		}
+		if status >= order.MakerRedeemed {
+			if isMaker {
+				auth.RedeemSig = tBytes
+			} else {
+				auth.RedemptionSig = tBytes
+			}
+		}
+		if status >= order.MatchComplete {
+			if isMaker {
+				auth.RedemptionSig = tBytes
+			} else {
+				auth.RedeemSig = tBytes
+			}
+		}
+	}
+
+	// Call setProof before setAuthSigs
+	setProof := func(status order.MatchStatus) {
+		isMaker := match.Side == order.Maker
+		match.Status = status
+		match.MetaData.Proof = db.MatchProof{}
+		proof := &match.MetaData.Proof
+
+		if isMaker {
+			auditInfo.Expiration = matchTime.Add(trade.lockTimeTaker)
+		} else {
+			auditInfo.Expiration = matchTime.Add(trade.lockTimeMaker)
+		}
+
+		if status >= order.MakerSwapCast {
+			proof.MakerSwap = tCoinID
+			proof.SecretHash = secretHash[:]
+			if isMaker {
+				proof.ContractData = tBytes
+				proof.Secret = secret
+			} else {
+				proof.CounterContract = tBytes
+			}
+		}
+		if status >= order.TakerSwapCast {
+			proof.TakerSwap = tCoinID
+			if isMaker {
+				proof.CounterContract = tBytes
+			} else {
+				proof.ContractData = tBytes
+			}
+		}
+		if status >= order.MakerRedeemed {
+			proof.MakerRedeem = tCoinID
+			if !isMaker {
+				proof.Secret = secret
+			}
+		}
+		if status >= order.MatchComplete {
+			proof.TakerRedeem = tCoinID
+		}
+	}
+
+	setLocalMatchStatus := func(proofStatus, authStatus order.MatchStatus) {
+		setProof(proofStatus)
+		setAuthSigs(authStatus)
+	}
+
+	var tMatchResults *msgjson.MatchStatusResult
+	setMatchResults := func(status order.MatchStatus) *msgjson.MatchStatusResult {
+		tMatchResults = &msgjson.MatchStatusResult{
+			MatchID: matchID[:],
+			Status:  uint8(status),
+			Active:  status != order.MatchComplete,
+		}
+		if status >= order.MakerSwapCast {
+			tMatchResults.MakerContract = tBytes
+			tMatchResults.MakerSwap = tCoinID
+		}
+		if status == order.MakerSwapCast || status == order.TakerSwapCast {
+			tMatchResults.TakerTxData = tTxData
+		}
+		if status >= order.TakerSwapCast {
+			tMatchResults.TakerContract = tBytes
+			tMatchResults.TakerSwap = tCoinID
+		}
+		if status >= order.MakerRedeemed {
+			tMatchResults.MakerRedeem = tCoinID
+			tMatchResults.Secret = secret
+		}
+		if status >= order.MatchComplete {
+			tMatchResults.TakerRedeem = tCoinID
+		}
+		return tMatchResults
+	}
+
+	type test struct {
+		ours, servers      order.MatchStatus
+		side               order.MatchSide
+		tweaker            func()
+		countStatusUpdates int
+	}
+
+	testName := func(tt *test) string {
+		return fmt.Sprintf("%s / %s (%s)", tt.ours, tt.servers, tt.side)
+	}
+
+	runTest := func(tt *test) order.MatchStatus {
+		match.Side = tt.side
+		setLocalMatchStatus(tt.ours, tt.servers)
+		setMatchResults(tt.servers)
+		if tt.tweaker != nil {
+			tt.tweaker()
+		}
+		rig.queueConnect(nil, connectMatches(tt.servers), nil)
+		rig.ws.queueResponse(msgjson.MatchStatusRoute, func(msg *msgjson.Message, f msgFunc) error {
+			resp, _ := msgjson.NewResponse(msg.ID, []*msgjson.MatchStatusResult{tMatchResults}, nil)
+			f(resp)
+			return nil
+		})
+		if tt.countStatusUpdates > 0 {
+			rig.db.updateMatchChan = make(chan order.MatchStatus, tt.countStatusUpdates)
+		}
+		if err := tCore.authDEX(dc); err != nil {
+			t.Fatalf("unexpected authDEX error: %v", err)
+		}
+		for i := 0; i < tt.countStatusUpdates; i++ {
+			<-rig.db.updateMatchChan
+		}
+		rig.db.updateMatchChan = nil
+		trade.mtx.Lock()
+		newStatus := match.Status
+		trade.mtx.Unlock()
+		return newStatus
+	}
+
+	// forwardResolvers are recoverable status combos where the server is ahead
+	// of us.
+	forwardResolvers := []*test{
+		{
+			ours:               order.NewlyMatched,
+			servers:            order.MakerSwapCast,
+			side:               order.Taker,
+			countStatusUpdates: 2,
+		},
+		{
+			ours:               order.MakerSwapCast,
+			servers:            order.TakerSwapCast,
+			side:               order.Maker,
+			countStatusUpdates: 2,
+		},
+		{
+			ours:    order.TakerSwapCast,
+			servers: order.MakerRedeemed,
+			side:    order.Taker,
+		},
+		{
+			ours:    order.MakerRedeemed,
+			servers: order.MatchComplete,
+			side:    order.Maker,
+		},
+	}
+
+	// Check that all of the forwardResolvers update the match status.
+	for _, tt := range forwardResolvers {
+		newStatus := runTest(tt)
+		if newStatus == tt.ours {
+			t.Fatalf("(%s) status not updated for forward resolution path", testName(tt))
+		}
+		if match.MetaData.Proof.SelfRevoked {
+			t.Fatalf("(%s) match self-revoked during forward resolution", testName(tt))
+		}
+	}
+
+	// backwardsResolvers are recoverable status mismatches where we are ahead
+	// of the server but can be resolved by deferring to resendPendingRequests.
+	backWardsResolvers := []*test{
+		{
+			ours:    order.MakerSwapCast,
+			servers: order.NewlyMatched,
+			side:    order.Maker,
+		},
+		{
+			ours:    order.TakerSwapCast,
+			servers: order.MakerSwapCast,
+			side:    order.Taker,
+		},
+		{
+			ours:    order.MakerRedeemed,
+			servers: order.TakerSwapCast,
+			side:    order.Maker,
+		},
+		{
+			ours:    order.MatchComplete,
+			servers: order.MakerRedeemed,
+			side:    order.Taker,
+		},
+	}
+
+	// Backwards resolvers won't update the match status, but also won't revoke
+	// the match.
+	for _, tt := range backWardsResolvers {
+		newStatus := runTest(tt)
+		if newStatus != tt.ours {
+			t.Fatalf("(%s) status changed for backwards resolution path", testName(tt))
+		}
+		if match.MetaData.Proof.SelfRevoked {
+			t.Fatalf("(%s) match self-revoked during backwards resolution", testName(tt))
+		}
+	}
+
+	// nonsense are status combos that make no sense, so should always result
+	// in a self-revocation.
+	nonsense := []*test{
+		{ // Server has our info before us
+			ours:    order.NewlyMatched,
+			servers: order.MakerSwapCast,
+			side:    order.Maker,
+		},
+		{ // Two steps apart
+			ours:    order.NewlyMatched,
+			servers: order.TakerSwapCast,
+			side:    order.Maker,
+		},
+		{ // Server didn't send contract
+			ours:    order.NewlyMatched,
+			servers: order.MakerSwapCast,
+			side:    order.Taker,
+			tweaker: func() {
+				tMatchResults.MakerContract = nil
+			},
+		},
+		{ // Server didn't send coin ID.
+			ours:    order.NewlyMatched,
+			servers: order.MakerSwapCast,
+			side:    order.Taker,
+			tweaker: func() {
+				tMatchResults.MakerSwap = nil
+			},
+		},
+		{ // Audit failed.
+			ours:    order.NewlyMatched,
+			servers: order.MakerSwapCast,
+			side:    order.Taker,
+			tweaker: func() {
+				auditInfo.Expiration = matchTime
+			},
+			countStatusUpdates: 2, // async auditContract -> revoke and db update
+		},
+		{ // Server has our info before us
+			ours:    order.MakerSwapCast,
+			servers: order.TakerSwapCast,
+			side:    order.Taker,
+		},
+		{ // Server has our info before us
+			ours:    order.MakerSwapCast,
+			servers: order.TakerSwapCast,
+			side:    order.Taker,
+		},
+		{ // Server didn't send contract
+			ours:    order.MakerSwapCast,
+			servers: order.TakerSwapCast,
+			side:    order.Maker,
+			tweaker: func() {
+				tMatchResults.TakerContract = nil
+			},
+		},
+		{ // Server didn't send coin ID.
+			ours:    order.MakerSwapCast,
+			servers: order.TakerSwapCast,
+			side:    order.Maker,
+			tweaker: func() {
+				tMatchResults.TakerSwap = nil
+			},
+		},
+		{ // Audit failed.
+			ours:    order.MakerSwapCast,
+			servers: order.TakerSwapCast,
+			side:    order.Maker,
+			tweaker: func() {
+				auditInfo.Expiration = matchTime
+			},
+			countStatusUpdates: 2, // async auditContract -> revoke and db update
+		},
+		{ // Taker has counter-party info the server doesn't.
+			ours:    order.MakerSwapCast,
+			servers: order.NewlyMatched,
+			side:    order.Taker,
+		},
+		{ // Maker has a server ack, but they say they don't have the data.
+			ours:    order.MakerSwapCast,
+			servers: order.NewlyMatched,
+			side:    order.Maker,
+			tweaker: func() {
+				match.MetaData.Proof.Auth.InitSig = tBytes
+			},
+		},
+		{ // Maker has counter-party info the server doesn't.
+			ours:    order.TakerSwapCast,
+			servers: order.MakerSwapCast,
+			side:    order.Maker,
+		},
+		{ // Taker has a server ack, but they say they don't have the data.
+			ours:    order.TakerSwapCast,
+			servers: order.MakerSwapCast,
+			side:    order.Taker,
+			tweaker: func() {
+				match.MetaData.Proof.Auth.InitSig = tBytes
+			},
+		},
+		{ // Server has redeem info before us.
+			ours:    order.TakerSwapCast,
+			servers: order.MakerRedeemed,
+			side:    order.Maker,
+		},
+		{ // Server didn't provide redemption coin ID.
+			ours:    order.TakerSwapCast,
+			servers: order.MakerRedeemed,
+			side:    order.Taker,
+			tweaker: func() {
+				tMatchResults.MakerRedeem = nil
+			},
+		},
+		{ // Server didn't provide secret.
+			ours:    order.TakerSwapCast,
+			servers: order.MakerRedeemed,
+			side:    order.Taker,
+			tweaker: func() {
+				tMatchResults.Secret = nil
+			},
+		},
+		{ // Server has our redemption data before us.
+			ours:    order.MakerRedeemed,
+			servers: order.MatchComplete,
+			side:    order.Taker,
+		},
+		{ // We have data before the server.
+			ours:    order.MakerRedeemed,
+			servers: order.TakerSwapCast,
+			side:    order.Taker,
+		},
+		{ // We have a server ack, but they say they don't have the data.
+			ours:    order.MakerRedeemed,
+			servers: order.TakerSwapCast,
+			side:    order.Maker,
+			tweaker: func() {
+				match.MetaData.Proof.Auth.RedeemSig = tBytes
+			},
+		},
+		{ // We have data before the server.
+			ours:    order.MatchComplete,
+			servers: order.MakerSwapCast,
+			side:    order.Maker,
+		},
+		{ // We have a server ack, but they say they don't have the data.
+			ours:    order.MatchComplete,
+			servers: order.MakerSwapCast,
+			side:    order.Taker,
+			tweaker: func() {
+				match.MetaData.Proof.Auth.RedeemSig = tBytes
+			},
+		},
+	}
+
+	for _, tt := range nonsense {
+		runTest(tt)
+		if !match.MetaData.Proof.SelfRevoked {
+			t.Fatalf("(%s) match not self-revoked during nonsense resolution", testName(tt))
+		}
+	}
+
+	// Run two matches for the same order.
+	match2ID := ordertest.RandomMatchID()
+	match2 := &matchTracker{
+		MetaMatch: db.MetaMatch{
+			MetaData: &db.MatchMetaData{},
+			UserMatch: &order.UserMatch{
+				MatchID: match2ID,
+				Address: addr,
+			},
+		},
+	}
+	trade.matches[match2ID] = match2
+	setAuthSigs(order.NewlyMatched)
+	setProof(order.NewlyMatched)
+	match2.Side = order.Taker
+	match2.MetaData.Proof = match.MetaData.Proof
+
+	srvMatches := connectMatches(order.MakerSwapCast)
+	srvMatches = append(srvMatches, &msgjson.Match{OrderID: oid[:],
+		MatchID: match2ID[:],
+		Status:  uint8(order.MakerSwapCast),
+		Side:    uint8(order.Taker),
+	})
+
+	res1 := setMatchResults(order.MakerSwapCast)
+	res2 := setMatchResults(order.MakerSwapCast)
+	res2.MatchID = match2ID[:]
+
+	rig.queueConnect(nil, srvMatches, nil)
+	rig.ws.queueResponse(msgjson.MatchStatusRoute, func(msg *msgjson.Message, f msgFunc) error {
+		resp, _ := msgjson.NewResponse(msg.ID, []*msgjson.MatchStatusResult{res1, res2}, nil)
+		f(resp)
+		return nil
+	})
+	// 2 matches resolved via contract audit: 2 synchronous updates, 2 async
+	rig.db.updateMatchChan = make(chan order.MatchStatus, 4)
+	tCore.authDEX(dc)
+	for i := 0; i < 4; i++ {
+		<-rig.db.updateMatchChan
+	}
+	trade.mtx.Lock()
+	newStatus1 := match.Status
+	newStatus2 := match2.Status
+	trade.mtx.Unlock()
+	if newStatus1 != order.MakerSwapCast {
+		t.Fatalf("wrong status for match 1: %s", newStatus1)
+	}
+	if newStatus2 != order.MakerSwapCast {
+		t.Fatalf("wrong status for match 2: %s", newStatus2)
+	}
+}
+
+func TestConfirmRedemption(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+
+	lo, dbOrder, preImg, addr := makeLimitOrder(dc, true, 0, 0)
+	oid := lo.ID()
+	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+	dc.trades[oid] = tracker
+
+	tBytes := encode.RandomBytes(2)
+	tCoinID := encode.RandomBytes(36)
+	tUpdatedCoinID := encode.RandomBytes(36)
+	secret := encode.RandomBytes(32)
+	secretHash := sha256.Sum256(secret)
+
+	var match *matchTracker
+
+	tBtcWallet.redeemCoins = []dex.Bytes{tUpdatedCoinID}
+
+	ourContract := encode.RandomBytes(90)
+	setupMatch := func(status order.MatchStatus, side order.MatchSide) {
+		matchID := ordertest.RandomMatchID()
+		_, auditInfo := tMsgAudit(oid, matchID, addr, 0, secretHash[:])
+		matchTime := time.Now()
+		match = &matchTracker{
+			counterSwap: auditInfo,
+			MetaMatch: db.MetaMatch{
+				MetaData: &db.MatchMetaData{},
+				UserMatch: &order.UserMatch{
+					MatchID: matchID,
+					Address: addr,
+					Side:    side,
+					Status:  status,
+				},
+			},
+		}
+		tracker.matches = map[order.MatchID]*matchTracker{matchID: match}
+
+		isMaker := match.Side == order.Maker
+		proof := &match.MetaData.Proof
+		proof.Auth.InitSig = []byte{1, 2, 3, 4}
+		// Assume our redeem was accepted, if we sent one.
+		if isMaker {
+			auditInfo.Expiration = matchTime.Add(tracker.lockTimeTaker)
+			if status >= order.MakerRedeemed {
+				match.MetaData.Proof.Auth.RedeemSig = []byte{0}
+			}
+		} else {
+			auditInfo.Expiration = matchTime.Add(tracker.lockTimeMaker)
+			if status >= order.MatchComplete {
+				match.MetaData.Proof.Auth.RedeemSig = []byte{0}
+			}
+		}
+
+		if status >= order.MakerSwapCast {
+			proof.MakerSwap = tCoinID
+			proof.SecretHash = secretHash[:]
+			if isMaker {
+				proof.ContractData = ourContract
+				proof.Secret = secret
+			} else {
+				proof.CounterContract = tBytes
+			}
+		}
+		if status >= order.TakerSwapCast {
+			proof.TakerSwap = tCoinID
+			if isMaker {
+				proof.CounterContract = tBytes
+			} else {
+				proof.ContractData = ourContract
+			}
+		}
+		if status >= order.MakerRedeemed {
+			proof.MakerRedeem = tCoinID
+			if !isMaker {
+				proof.Secret = secret
+			}
+		}
+		if status >= order.MatchComplete {
+			proof.TakerRedeem = tCoinID
+		}
+	}
+
+	type note struct {
+		severity db.Severity
+		topic    db.Topic
+	}
+
+	tests := []struct {
+		name                    string
+		matchStatus             order.MatchStatus
+		matchSide               order.MatchSide
+		expectedNotifications   []*note
+		confirmRedemptionResult *asset.ConfirmRedemptionStatus
+		confirmRedemptionErr    error
+
+		expectConfirmRedemptionCalled bool
+		expectedStatus                order.MatchStatus
+		expectTicksDelayed            bool
+	}{
+		{
+			name:        "maker, makerRedeemed, confirmedRedemption",
+			matchStatus: order.MakerRedeemed,
+			matchSide:   order.Maker,
+			expectedNotifications: []*note{
+				{
+					severity: db.Success,
+					topic:    TopicRedemptionConfirmed,
+				},
+			},
+			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
+				Confs:  10,
+				Req:    10,
+				CoinID: tCoinID,
+			},
+			expectConfirmRedemptionCalled: true,
+			expectedStatus:                order.MatchConfirmed,
+		},
+		{
+			name:        "maker, makerRedeemed, confirmedRedemption, more confs than required",
+			matchStatus: order.MakerRedeemed,
+			matchSide:   order.Maker,
+			expectedNotifications: []*note{
+				{
+					severity: db.Success,
+					topic:    TopicRedemptionConfirmed,
+				},
+			},
+			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
+				Confs:  15,
+				Req:    10,
+				CoinID: tCoinID,
+			},
+			expectConfirmRedemptionCalled: true,
+			expectedStatus:                order.MatchConfirmed,
+		},
+		{
+			name:        "taker, matchComplete, confirmedRedemption",
+			matchStatus: order.MatchComplete,
+			matchSide:   order.Taker,
+			expectedNotifications: []*note{
+				{
+					severity: db.Success,
+					topic:    TopicRedemptionConfirmed,
+				},
+			},
+			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
+				Confs:  10,
+				Req:    10,
+				CoinID: tCoinID,
+			},
+			expectConfirmRedemptionCalled: true,
+			expectedStatus:                order.MatchConfirmed,
+		},
+		{
+			name:        "maker, makerRedeemed, incomplete",
+			matchStatus: order.MakerRedeemed,
+			matchSide:   order.Maker,
+			expectedNotifications: []*note{
+				{
+					severity: db.Data,
+					topic:    TopicConfirms,
+				},
+			},
+			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
+				Confs:  5,
+				Req:    10,
+				CoinID: tCoinID,
+			},
+			expectConfirmRedemptionCalled: true,
+			expectedStatus:                order.MakerRedeemed,
+		},
+		{
+			name:        "maker, makerRedeemed, replacedTx",
+			matchStatus: order.MakerRedeemed,
+			matchSide:   order.Maker,
+			expectedNotifications: []*note{
+				{
+					severity: db.WarningLevel,
+					topic:    TopicRedemptionResubmitted,
+				},
+				{
+					severity: db.Data,
+					topic:    TopicConfirms,
+				},
+			},
+			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
+				Confs:  0,
+				Req:    10,
+				CoinID: tUpdatedCoinID,
+			},
+			expectConfirmRedemptionCalled: true,
+			expectedStatus:                order.MakerRedeemed,
+		},
+		{
+			name:        "taker, matchComplete, replacedTx",
+			matchStatus: order.MatchComplete,
+			matchSide:   order.Taker,
+			expectedNotifications: []*note{
+				{
+					severity: db.WarningLevel,
+					topic:    TopicRedemptionResubmitted,
+				},
+				{
+					severity: db.Data,
+					topic:    TopicConfirms,
+				},
+			},
+			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
+				Confs:  0,
+				Req:    10,
+				CoinID: tUpdatedCoinID,
+			},
+			expectConfirmRedemptionCalled: true,
+			expectedStatus:                order.MatchComplete,
+		},
+		{
+			// This case could happen if the dex was shut down right after
+			// a resubmission.
+			name:        "taker, matchComplete, replacedTx and already confirmed",
+			matchStatus: order.MatchComplete,
+			matchSide:   order.Taker,
+			expectedNotifications: []*note{
+				{
+					severity: db.WarningLevel,
+					topic:    TopicRedemptionResubmitted,
+				},
+				{
 					severity: db.Success,
 					topic:    TopicRedemptionConfirmed,
 				},
 			},
-			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
-				Confs:  10,
-				Req:    10,
-				CoinID: tCoinID,
+			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
+				Confs:  10,
+				Req:    10,
+				CoinID: tUpdatedCoinID,
+			},
+			expectConfirmRedemptionCalled: true,
+			expectedStatus:                order.MatchConfirmed,
+		},
+		{
+			name:                          "maker, makerRedeemed, error",
+			matchStatus:                   order.MakerRedeemed,
+			matchSide:                     order.Maker,
+			confirmRedemptionErr:          errors.New("err"),
+			expectedStatus:                order.MakerRedeemed,
+			expectTicksDelayed:            true,
+			expectConfirmRedemptionCalled: true,
+		},
+		{
+			name:                 "maker, makerRedeemed, swap refunded error",
+			matchStatus:          order.MakerRedeemed,
+			matchSide:            order.Maker,
+			confirmRedemptionErr: asset.ErrSwapRefunded,
+			expectedStatus:       order.MatchConfirmed,
+			expectedNotifications: []*note{
+				{
+					severity: db.ErrorLevel,
+					topic:    TopicSwapRefunded,
+				},
+			},
+			expectConfirmRedemptionCalled: true,
+		},
+		{
+			name:                 "taker, takerRedeemed, redemption tx rejected error",
+			matchStatus:          order.MatchComplete,
+			matchSide:            order.Taker,
+			confirmRedemptionErr: asset.ErrTxRejected,
+			expectedStatus:       order.MatchComplete,
+			expectedNotifications: []*note{
+				{
+					severity: db.Data,
+					topic:    TopicRedeemRejected,
+				},
+			},
+			expectConfirmRedemptionCalled: true,
+		},
+		{
+			name:                          "maker, makerRedeemed, redemption tx lost",
+			matchStatus:                   order.MakerRedeemed,
+			matchSide:                     order.Maker,
+			confirmRedemptionErr:          asset.ErrTxLost,
+			expectedStatus:                order.TakerSwapCast,
+			expectConfirmRedemptionCalled: true,
+		},
+		{
+			name:                          "taker, takerRedeemed, redemption tx lost",
+			matchStatus:                   order.MatchComplete,
+			matchSide:                     order.Taker,
+			confirmRedemptionErr:          asset.ErrTxLost,
+			expectedStatus:                order.MakerRedeemed,
+			expectConfirmRedemptionCalled: true,
+		},
+		{
+			name:                          "maker, matchConfirmed",
+			matchStatus:                   order.MatchConfirmed,
+			matchSide:                     order.Maker,
+			expectedStatus:                order.MatchConfirmed,
+			expectedNotifications:         []*note{},
+			expectConfirmRedemptionCalled: false,
+		},
+		{
+			name:                          "maker, TakerSwapCast",
+			matchStatus:                   order.TakerSwapCast,
+			matchSide:                     order.Maker,
+			expectedStatus:                order.TakerSwapCast,
+			expectedNotifications:         []*note{},
+			expectConfirmRedemptionCalled: false,
+		},
+		{
+			name:                          "taker, TakerSwapCast",
+			matchStatus:                   order.TakerSwapCast,
+			matchSide:                     order.Taker,
+			expectedStatus:                order.TakerSwapCast,
+			expectedNotifications:         []*note{},
+			expectConfirmRedemptionCalled: false,
+		},
+	}
+
+	notificationFeed := tCore.NotificationFeed()
+
+	for _, test := range tests {
+		tracker.mtx.Lock()
+		setupMatch(test.matchStatus, test.matchSide)
+		tracker.mtx.Unlock()
+
+		tBtcWallet.confirmRedemptionResult = test.confirmRedemptionResult
+		tBtcWallet.confirmRedemptionErr = test.confirmRedemptionErr
+		tBtcWallet.confirmRedemptionCalled = false
+
+		tCore.tickAsset(dc, tUTXOAssetB.ID)
+
+		if tBtcWallet.confirmRedemptionCalled != test.expectConfirmRedemptionCalled {
+			t.Fatalf("%s: expected confirm redemption to be called=%v but got=%v",
+				test.name, test.expectConfirmRedemptionCalled, tBtcWallet.confirmRedemptionCalled)
+		}
+
+		for _, expectedNotification := range test.expectedNotifications {
+			var n Notification
+		out:
+			for {
+				select {
+				case n = <-notificationFeed.C:
+					if n.Topic() == expectedNotification.topic {
+						break out
+					}
+				case <-time.After(60 * time.Second):
+					t.Fatalf("%s: did not receive expected notification", test.name)
+				}
+			}
+
+			if n.Severity() != expectedNotification.severity {
+				t.Fatalf("%s: expected severity %v, got %v",
+					test.name, expectedNotification.severity, n.Severity())
+			}
+		}
+
+		tracker.mtx.RLock()
+		if test.confirmRedemptionResult != nil {
+			var redeemCoin order.CoinID
+			if test.matchSide == order.Maker {
+				redeemCoin = match.MetaData.Proof.MakerRedeem
+			} else {
+				redeemCoin = match.MetaData.Proof.TakerRedeem
+			}
+			if !bytes.Equal(redeemCoin, test.confirmRedemptionResult.CoinID) {
+				t.Fatalf("%s: expected coin %v != actual %v", test.name, test.confirmRedemptionResult.CoinID, redeemCoin)
+			}
+			if test.confirmRedemptionResult.Confs >= test.confirmRedemptionResult.Req {
+				if len(tDcrWallet.returnedContracts) != 1 || !bytes.Equal(ourContract, tDcrWallet.returnedContracts[0]) {
+					t.Fatalf("%s: refund address not returned", test.name)
+				}
+			}
+		}
+
+		ticksDelayed := match.tickGovernor != nil
+		if ticksDelayed != test.expectTicksDelayed {
+			t.Fatalf("%s: expected ticks delayed %v but got %v", test.name, test.expectTicksDelayed, ticksDelayed)
+		}
+
+		if match.Status != test.expectedStatus {
+			t.Fatalf("%s: expected status %v but got %v", test.name, test.expectedStatus, match.Status)
+		}
+		tracker.mtx.RUnlock()
+	}
+}
+
+func TestRedeemConfs(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
+
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.Unlock(rig.crypter)
+	extraConfs := tUTXOAssetB.SwapConf + 3
+	btcWallet.redeemConfs = extraConfs
+	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+
+	lo, dbOrder, preImg, addr := makeLimitOrder(dc, true, 0, 0)
+	oid := lo.ID()
+	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+	dc.trades[oid] = tracker
+
+	newMatch := func(matchTime time.Time) (*matchTracker, *asset.AuditInfo) {
+		matchID := ordertest.RandomMatchID()
+		_, auditInfo := tMsgAudit(oid, matchID, addr, 0, encode.RandomBytes(32))
+		auditInfo.Expiration = matchTime.Add(tracker.lockTimeTaker)
+		tBtcWallet.contractLockTime = auditInfo.Expiration
+		match := &matchTracker{
+			counterSwap: auditInfo,
+			MetaMatch: db.MetaMatch{
+				MetaData: &db.MatchMetaData{Stamp: uint64(matchTime.UnixMilli())},
+				UserMatch: &order.UserMatch{
+					MatchID: matchID,
+					Address: addr,
+					Side:    order.Maker,
+					Status:  order.TakerSwapCast,
+				},
+			},
+		}
+		return match, auditInfo
+	}
+	checkRedeemable := func(tag string, match *matchTracker, exp bool) {
+		t.Helper()
+		tracker.mtx.RLock()
+		ready, revoke := tracker.isRedeemable(tCtx, match)
+		tracker.mtx.RUnlock()
+		if ready != exp || revoke {
+			t.Fatalf("%s: expected redeemable = %t, got %t (revoke = %t)", tag, exp, ready, revoke)
+		}
+	}
+
+	// Early in the swap, redemption waits for the extra confirmations.
+	match, auditInfo := newMatch(time.Now())
+	tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
+	checkRedeemable("server confs", match, false)
+	tBtcWallet.setConfs(auditInfo.Coin.ID(), extraConfs, nil)
+	checkRedeemable("extra confs", match, true)
+
+	// Once waiting would risk the counterparty's refund, the server's
+	// requirement is used.
+	match, auditInfo = newMatch(time.Now().Add(-tracker.lockTimeTaker * 3 / 5))
+	tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
+	checkRedeemable("deadline fallback", match, true)
+	if atomic.LoadUint32(&match.redeemConfsAbandoned) != 1 {
+		t.Fatalf("extra confirmations not flagged abandoned")
+	}
+
+	// The maker's redeem is due within the broadcast timeout of the taker's
+	// swap reaching the server's required confirmations, so the extra wait
+	// ends half way through it, regardless of the lock time.
+	match, auditInfo = newMatch(time.Now())
+	tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
+	checkRedeemable("broadcast timeout wait", match, false)
+	confStamp := atomic.LoadInt64(&match.counterSwapConfStamp)
+	if confStamp == 0 {
+		t.Fatalf("server confirmations time not recorded")
+	}
+	bTimeout := tracker.broadcastTimeout()
+	atomic.StoreInt64(&match.counterSwapConfStamp, confStamp-(bTimeout/2).Milliseconds())
+	checkRedeemable("broadcast timeout fallback", match, true)
+	if atomic.LoadUint32(&match.redeemConfsAbandoned) != 1 {
+		t.Fatalf("extra confirmations not abandoned before the broadcast timeout")
+	}
+
+	// Fewer confirmations than the server requires are ignored.
+	btcWallet.redeemConfs = 1
+	match, auditInfo = newMatch(time.Now())
+	tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf-1, nil)
+	checkRedeemable("below server confs", match, false)
+}
+
+func TestSwapFeeRate(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	feeRater := &TFeeRater{TXCWallet: tDcrWallet}
+	dcrWallet.Wallet = feeRater
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
+	}
+	_, dbOrder, preImg, _ := makeLimitOrder(dc, true, 0, 0)
+	dbOrder.MetaData.MaxFeeRate = 100
+	tracker := newTrackedTrade(dbOrder, preImg, dc, tCore.lockTimeTaker, tCore.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, tCore.notify, tCore.formatDetails)
+
+	// The ranges are reported with the book snapshot and updated with each
+	// epoch report.
+	book := newBookie(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	err = book.Sync(&msgjson.OrderBook{
+		MarketID:     tDcrBtcMktName,
+		BaseFeeRange: &msgjson.FeeRateRange{MinToConfirm: 1, Economical: 2, Priority: 3},
+		Orders:       []*msgjson.BookOrderNote{},
+	})
+	if err != nil {
+		t.Fatalf("Sync error: %v", err)
+	}
+	if feeRange := book.BaseFeeRange(); feeRange == nil || feeRange.Economical != 2 {
+		t.Fatalf("wrong fee range from snapshot %+v", feeRange)
+	}
+	dc.booksMtx.Lock()
+	dc.books[tDcrBtcMktName] = book
+	dc.booksMtx.Unlock()
+
+	for _, tt := range []struct {
+		name       string
+		prescribed uint64
+		local      uint64
+		feeRange   *msgjson.FeeRateRange
+		exp        uint64
+	}{
+		{"prescribed", 20, 10, nil, 20},
+		{"local estimate", 20, 30, nil, 30},
+		{"local estimate capped at max", 20, 300, nil, 100},
+		{"raised to economical", 20, 10, &msgjson.FeeRateRange{MinToConfirm: 5, Economical: 40, Priority: 60}, 40},
+		{"capped at priority", 20, 80, &msgjson.FeeRateRange{MinToConfirm: 5, Economical: 40, Priority: 60}, 60},
+		{"within range", 20, 50, &msgjson.FeeRateRange{MinToConfirm: 5, Economical: 40, Priority: 60}, 50},
+		{"prescribed above range", 70, 50, &msgjson.FeeRateRange{MinToConfirm: 5, Economical: 40, Priority: 60}, 70},
+		{"economical capped at max", 20, 10, &msgjson.FeeRateRange{MinToConfirm: 5, Economical: 150, Priority: 200}, 100},
+	} {
+		book.LogEpochReport(&msgjson.EpochReportNote{MarketID: tDcrBtcMktName, BaseFeeRange: tt.feeRange})
+		feeRater.feeRate = tt.local
+		if feeRate := tracker.swapFeeRate(tt.prescribed); feeRate != tt.exp {
+			t.Fatalf("%s: wanted fee rate %d, got %d", tt.name, tt.exp, feeRate)
+		}
+	}
+}
+
+func TestSimulateTrade(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.Unlock(rig.crypter)
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	btcWallet.Unlock(rig.crypter)
+
+	qty := dcrBtcLotSize * 10
+	form := &TradeForm{
+		Host:    tDexHost,
+		IsLimit: true,
+		Sell:    true,
+		Base:    tUTXOAssetA.ID,
+		Quote:   tUTXOAssetB.ID,
+		Qty:     qty,
+		Rate:    dcrBtcRateStep * 1000,
+	}
+	dcrCoin := &tCoin{id: encode.RandomBytes(36), val: qty * 2}
+	tDcrWallet.fundingCoins = asset.Coins{dcrCoin}
+	tDcrWallet.fundRedeemScripts = []dex.Bytes{nil}
+	tDcrWallet.simulatedSwap = &asset.SimulatedSwap{
+		TxID:      "swaptx",
+		Tx:        encode.RandomBytes(100),
+		Contracts: []dex.Bytes{encode.RandomBytes(50)},
+		Fees:      500,
+	}
+
+	sim, err := tCore.SimulateTrade(tPW, form)
+	if err != nil {
+		t.Fatalf("SimulateTrade error: %v", err)
+	}
+	if !sim.Simulated || sim.SwapTxID != "swaptx" || !bytes.Equal(sim.Contract, tDcrWallet.simulatedSwap.Contracts[0]) ||
+		sim.SwapFees != 500 || len(sim.FundingCoins) != 1 {
+		t.Fatalf("wrong simulated trade %+v", sim)
+	}
+	if !tDcrWallet.fundedDryRun {
+		t.Fatalf("order not funded as a dry run")
+	}
+	if len(tDcrWallet.lastSwaps) != 1 || tDcrWallet.lastSwaps[0].Contracts[0].Value != qty {
+		t.Fatalf("wrong simulated swaps")
+	}
+	// Nothing is broadcast, the coins are returned, and no trade is tracked.
+	if tDcrWallet.swapCounter != 0 {
+		t.Fatalf("swap broadcast during dry run")
+	}
+	if len(tDcrWallet.returnedCoins) != 1 || !bytes.Equal(tDcrWallet.returnedCoins[0].ID(), dcrCoin.id) {
+		t.Fatalf("funding coins not returned")
+	}
+	if len(rig.dc.trackedTrades()) != 0 {
+		t.Fatalf("dry run trade was tracked")
+	}
+
+	// Simulation errors are returned, and the coins are still returned.
+	tDcrWallet.returnedCoins = nil
+	tDcrWallet.swapErr = tErr
+	if _, err = tCore.SimulateTrade(tPW, form); err == nil {
+		t.Fatalf("no error for simulation error")
+	}
+	if len(tDcrWallet.returnedCoins) != 1 {
+		t.Fatalf("funding coins not returned after simulation error")
+	}
+}
+
+func TestMaxSwapsRedeemsInTx(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+
+	tDcrWallet.info.MaxSwapsInTx = 4
+	tBtcWallet.info.MaxRedeemsInTx = 4
+
+	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, 0, 0)
+	oid := lo.ID()
+	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+	dc.trades[oid] = tracker
+
+	newMatch := func(side order.MatchSide, status order.MatchStatus) *matchTracker {
+		return &matchTracker{
+			prefix: lo.Prefix(),
+			trade:  lo.Trade(),
+			MetaMatch: db.MetaMatch{
+				MetaData: &db.MatchMetaData{
+					Proof: db.MatchProof{
+						Auth: db.MatchAuth{
+							MatchStamp: uint64(time.Now().UnixMilli()),
+							AuditStamp: uint64(time.Now().UnixMilli()),
+						},
+					},
+				},
+				UserMatch: &order.UserMatch{
+					MatchID:     ordertest.RandomMatchID(),
+					Side:        side,
+					Address:     ordertest.RandomAddress(),
+					Status:      status,
+					FeeRateSwap: tMaxFeeRate,
+				},
+			},
+		}
+	}
+
+	swapabbleMatches := func(num int) map[order.MatchID]*matchTracker {
+		matches := make(map[order.MatchID]*matchTracker, num)
+		for i := 0; i < num; i++ {
+			m := newMatch(order.Maker, order.NewlyMatched)
+			matches[m.MatchID] = m
+		}
+		return matches
+	}
+
+	redeemableMatches := func(num int) map[order.MatchID]*matchTracker {
+		matches := make(map[order.MatchID]*matchTracker, num)
+		for i := 0; i < num; i++ {
+			m := newMatch(order.Taker, order.MakerRedeemed)
+			matches[m.MatchID] = m
+			rig.ws.queueResponse(msgjson.RedeemRoute, redeemAcker)
+		}
+		return matches
+	}
+
+	checkNumSwaps := func(expected []int, wallet *TXCWallet) {
+		t.Helper()
+		for i := range expected {
+			if expected[i] != len(wallet.lastSwaps[i].Contracts) {
+				t.Fatalf("expected %d swaps but got %d", expected[i], len(wallet.lastSwaps[i].Contracts))
+			}
+		}
+	}
+
+	checkNumRedeems := func(expected []int, wallet *TXCWallet) {
+		t.Helper()
+		for i := range expected {
+			if expected[i] != len(wallet.lastRedeems[i].Redemptions) {
+				t.Fatalf("expected %d swaps but got %d", expected[i], len(wallet.lastRedeems[i].Redemptions))
+			}
+		}
+	}
+
+	populateRedeemCoins := func(num int, wallet *TXCWallet) {
+		wallet.redeemCoins = make([]dex.Bytes, num)
+		for i := 0; i < num; i++ {
+			wallet.redeemCoins = append(wallet.redeemCoins, encode.RandomBytes(32))
+		}
+	}
+
+	// Test Swaps
+	expected := []int{4, 4, 4, 4, 4, 2}
+	tracker.matches = swapabbleMatches(22)
+	tCore.tick(tracker)
+	checkNumSwaps(expected, tDcrWallet)
+
+	tDcrWallet.lastSwaps = make([]*asset.Swaps, 0)
+	expected = []int{3}
+	tracker.matches = swapabbleMatches(3)
+	tCore.tick(tracker)
+	checkNumSwaps(expected, tDcrWallet)
+
+	tDcrWallet.lastSwaps = make([]*asset.Swaps, 0)
+	expected = []int{4}
+	tracker.matches = swapabbleMatches(4)
+	tCore.tick(tracker)
+	checkNumSwaps(expected, tDcrWallet)
+
+	// Test Redeems
+	expected = []int{4, 4, 4, 4, 4, 2}
+	tracker.matches = redeemableMatches(22)
+	populateRedeemCoins(22, tBtcWallet)
+	tCore.tick(tracker)
+	checkNumRedeems(expected, tBtcWallet)
+
+	tBtcWallet.lastRedeems = make([]*asset.RedeemForm, 0)
+	expected = []int{3}
+	tracker.matches = redeemableMatches(3)
+	populateRedeemCoins(3, tBtcWallet)
+	tCore.tick(tracker)
+	checkNumRedeems(expected, tBtcWallet)
+
+	tBtcWallet.lastRedeems = make([]*asset.RedeemForm, 0)
+	expected = []int{4}
+	tracker.matches = redeemableMatches(4)
+	populateRedeemCoins(4, tBtcWallet)
+	tCore.tick(tracker)
+	checkNumRedeems(expected, tBtcWallet)
+}
+
+func TestSuspectTrades(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+
+	lo, dbOrder, preImg, addr := makeLimitOrder(dc, true, 0, 0)
+	oid := lo.ID()
+	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
+	dc.trades[oid] = tracker
+
+	newMatch := func(side order.MatchSide, status order.MatchStatus) *matchTracker {
+		return &matchTracker{
+			prefix: lo.Prefix(),
+			trade:  lo.Trade(),
+			MetaMatch: db.MetaMatch{
+				MetaData: &db.MatchMetaData{
+					Proof: db.MatchProof{
+						Auth: db.MatchAuth{
+							MatchStamp: uint64(time.Now().UnixMilli()),
+							AuditStamp: uint64(time.Now().UnixMilli()),
+						},
+					},
+				},
+				UserMatch: &order.UserMatch{
+					MatchID:     ordertest.RandomMatchID(),
+					Side:        side,
+					Address:     ordertest.RandomAddress(),
+					Status:      status,
+					FeeRateSwap: tMaxFeeRate,
+				},
+			},
+		}
+	}
+
+	var swappableMatch1, swappableMatch2 *matchTracker
+	setSwaps := func() {
+		swappableMatch1 = newMatch(order.Maker, order.NewlyMatched)
+		swappableMatch2 = newMatch(order.Taker, order.MakerSwapCast)
+
+		// Set counterswaps for both swaps.
+		// Set valid wallet auditInfo for swappableMatch2, taker will repeat audit before swapping.
+		auditQty := calc.BaseToQuote(swappableMatch2.Rate, swappableMatch2.Quantity)
+		_, auditInfo := tMsgAudit(oid, swappableMatch2.MatchID, addr, auditQty, encode.RandomBytes(32))
+		auditInfo.Expiration = encode.DropMilliseconds(swappableMatch2.matchTime().Add(tracker.lockTimeMaker))
+		tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetA.SwapConf, nil)
+		tBtcWallet.auditInfo = auditInfo
+		swappableMatch2.counterSwap = auditInfo
+
+		_, auditInfo = tMsgAudit(oid, swappableMatch1.MatchID, ordertest.RandomAddress(), 1, encode.RandomBytes(32))
+		tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetA.SwapConf, nil)
+		swappableMatch1.counterSwap = auditInfo
+
+		tDcrWallet.swapCounter = 0
+		tracker.matches = map[order.MatchID]*matchTracker{
+			swappableMatch1.MatchID: swappableMatch1,
+			swappableMatch2.MatchID: swappableMatch2,
+		}
+	}
+	setSwaps()
+
+	// Initial success
+	_, err := tCore.tick(tracker)
+	if err != nil {
+		t.Fatalf("swap tick error: %v", err)
+	}
+
+	setSwaps()
+	tDcrWallet.swapErr = tErr
+	_, err = tCore.tick(tracker)
+	if err == nil || !strings.Contains(err.Error(), "error sending dcr swap transaction") {
+		t.Fatalf("swap error not propagated, err = %v", err)
+	}
+	if tDcrWallet.swapCounter != 1 {
+		t.Fatalf("never swapped")
+	}
+
+	// Both matches should be marked as suspect and have tickGovernors in place.
+	tracker.mtx.Lock()
+	for i, m := range []*matchTracker{swappableMatch1, swappableMatch2} {
+		if !m.suspectSwap {
+			t.Fatalf("swappable match %d not suspect after failed swap", i+1)
+		}
+		if m.tickGovernor == nil {
+			t.Fatalf("swappable match %d has no tick meterer set", i+1)
+		}
+	}
+	tracker.mtx.Unlock()
+
+	// Ticking right away again should do nothing.
+	tDcrWallet.swapErr = nil
+	_, err = tCore.tick(tracker)
+	if err != nil {
+		t.Fatalf("tick error during metered swap tick: %v", err)
+	}
+	if tDcrWallet.swapCounter != 1 {
+		t.Fatalf("swapped during metered tick")
+	}
+
+	// But once the tickGovernors expire, we should succeed with two separate
+	// requests.
+	tracker.mtx.Lock()
+	swappableMatch1.tickGovernor = nil
+	swappableMatch2.tickGovernor = nil
+	tracker.mtx.Unlock()
+	_, err = tCore.tick(tracker)
+	if err != nil {
+		t.Fatalf("tick error while swapping suspect matches: %v", err)
+	}
+	if tDcrWallet.swapCounter != 3 {
+		t.Fatalf("suspect swap matches not run or not run separately. expected 2 new calls to Swap, got %d", tDcrWallet.swapCounter-1)
+	}
+
+	var redeemableMatch1, redeemableMatch2 *matchTracker
+	setRedeems := func() {
+		redeemableMatch1 = newMatch(order.Maker, order.TakerSwapCast)
+		redeemableMatch2 = newMatch(order.Taker, order.MakerRedeemed)
+
+		// Set valid wallet auditInfo for redeemableMatch1, maker will repeat audit before redeeming.
+		auditQty := calc.BaseToQuote(redeemableMatch1.Rate, redeemableMatch1.Quantity)
+		_, auditInfo := tMsgAudit(oid, redeemableMatch1.MatchID, addr, auditQty, encode.RandomBytes(32))
+		auditInfo.Expiration = encode.DropMilliseconds(redeemableMatch1.matchTime().Add(tracker.lockTimeTaker))
+		tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
+		tBtcWallet.auditInfo = auditInfo
+		redeemableMatch1.counterSwap = auditInfo
+		redeemableMatch1.MetaData.Proof.SecretHash = auditInfo.SecretHash
+
+		tBtcWallet.redeemCounter = 0
+		tracker.matches = map[order.MatchID]*matchTracker{
+			redeemableMatch1.MatchID: redeemableMatch1,
+			redeemableMatch2.MatchID: redeemableMatch2,
+		}
+		rig.ws.queueResponse(msgjson.RedeemRoute, redeemAcker)
+		rig.ws.queueResponse(msgjson.RedeemRoute, redeemAcker)
+	}
+	setRedeems()
+
+	// Initial success
+	tBtcWallet.redeemCoins = []dex.Bytes{encode.RandomBytes(36), encode.RandomBytes(36)}
+	_, err = tCore.tick(tracker)
+	if err != nil {
+		t.Fatalf("redeem tick error: %v", err)
+	}
+	if tBtcWallet.redeemCounter != 1 {
+		t.Fatalf("never redeemed")
+	}
+
+	setRedeems()
+	tBtcWallet.redeemErr = tErr
+	_, err = tCore.tick(tracker)
+	if err == nil || !strings.Contains(err.Error(), "error sending redeem transaction") {
+		t.Fatalf("redeem error not propagated. err = %v", err)
+	}
+	if tBtcWallet.redeemCounter != 1 {
+		t.Fatalf("never redeemed")
+	}
+
+	// Both matches should be marked as suspect and have tickGovernors in place.
+	tracker.mtx.Lock()
+	for i, m := range []*matchTracker{redeemableMatch1, redeemableMatch2} {
+		if !m.suspectRedeem {
+			t.Fatalf("redeemable match %d not suspect after failed swap", i+1)
+		}
+		if m.tickGovernor == nil {
+			t.Fatalf("redeemable match %d has no tick meterer set", i+1)
+		}
+	}
+	tracker.mtx.Unlock()
+
+	// Ticking right away again should do nothing.
+	tBtcWallet.redeemErr = nil
+	_, err = tCore.tick(tracker)
+	if err != nil {
+		t.Fatalf("tick error during metered redeem tick: %v", err)
+	}
+	if tBtcWallet.redeemCounter != 1 {
+		t.Fatalf("redeemed during metered tick %d", tBtcWallet.redeemCounter)
+	}
+
+	// But once the tickGovernors expire, we should succeed with two separate
+	// requests.
+	tracker.mtx.Lock()
+	redeemableMatch1.tickGovernor = nil
+	redeemableMatch2.tickGovernor = nil
+	tracker.mtx.Unlock()
+	_, err = tCore.tick(tracker)
+	if err != nil {
+		t.Fatalf("tick error while redeeming suspect matches: %v", err)
+	}
+	if tBtcWallet.redeemCounter != 3 {
+		t.Fatalf("suspect redeem matches not run or not run separately. expected 2 new calls to Redeem, got %d", tBtcWallet.redeemCounter-1)
+	}
+}
+
+func TestWalletSyncing(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	noteFeed := tCore.NotificationFeed()
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	dcrWallet.syncStatus.Synced = false
+	dcrWallet.syncStatus.Blocks = 0
+	dcrWallet.hookedUp = false
+	// Connect with tCore.connectWallet below.
+
+	tStart := time.Now()
+	testDuration := 100 * time.Millisecond
+	syncTickerPeriod = 10 * time.Millisecond
+
+	tDcrWallet.syncStatus = func() (bool, float32, error) {
+		progress := float32(float64(time.Since(tStart)) / float64(testDuration))
+		if progress >= 1 {
+			return true, 1, nil
+		}
+		return false, progress, nil
+	}
+
+	_, err := tCore.connectWallet(dcrWallet)
+	if err != nil {
+		t.Fatalf("connectWallet error: %v", err)
+	}
+
+	timeout := time.NewTimer(time.Second)
+	defer timeout.Stop()
+	var syncNotes int
+	var lastProgress float32
+out:
+	for {
+		select {
+		case note := <-noteFeed.C:
+			n, ok := note.(*WalletSyncNote)
+			if !ok {
+				continue
+			}
+			syncNotes++
+			if n.Status.Progress < lastProgress {
+				t.Fatalf("progress went backwards from %.2f to %.2f", lastProgress, n.Status.Progress)
+			}
+			lastProgress = n.Status.Progress
+			if n.Status.Synced {
+				break out
+			}
+		case <-timeout.C:
+			t.Fatalf("timed out waiting for synced wallet note. Received %d sync notes", syncNotes)
+		}
+	}
+	// By the time we've got 10th note it should signal that the wallet has been
+	// synced (due to how we've set up testDuration and syncTickerPeriod values).
+	if syncNotes > 10 {
+		t.Fatalf("expected 10 sync notes at most, got %d", syncNotes)
+	}
+	if lastProgress != 100 {
+		t.Fatalf("expected 100%% progress when synced, got %.2f", lastProgress)
+	}
+}
+
+func TestWalletSyncStatus(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	if _, err := tCore.WalletSyncStatus(tUTXOAssetA.ID); err == nil {
+		t.Fatalf("no error for missing wallet")
+	}
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+
+	for _, progress := range []float32{0, 0.25, 0.5} {
+		tDcrWallet.syncStatus = func() (bool, float32, error) { return false, progress, nil }
+		status, err := tCore.WalletSyncStatus(tUTXOAssetA.ID)
+		if err != nil {
+			t.Fatalf("WalletSyncStatus error: %v", err)
+		}
+		expHeight := uint64(progress * 100)
+		if status.Synced || status.Height != expHeight || status.TargetHeight != 100 || status.Progress != progress*100 {
+			t.Fatalf("wrong status at %.2f progress: %+v", progress, status)
+		}
+	}
+
+	tDcrWallet.syncStatus = func() (bool, float32, error) { return true, 1, nil }
+	status, err := tCore.WalletSyncStatus(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("WalletSyncStatus error: %v", err)
+	}
+	if !status.Synced || status.Progress != 100 {
+		t.Fatalf("wrong synced status: %+v", status)
+	}
+
+	tDcrWallet.syncStatus = func() (bool, float32, error) { return false, 0, tErr }
+	if _, err = tCore.WalletSyncStatus(tUTXOAssetA.ID); err == nil {
+		t.Fatalf("no error for SyncStatus error")
+	}
+
+	// A wallet that is not an asset.SyncStatuser is always synced.
+	dcrWallet.Wallet = &tNoSyncStatusWallet{tDcrWallet}
+	status, err = tCore.WalletSyncStatus(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("WalletSyncStatus error for always-synced wallet: %v", err)
+	}
+	if !status.Synced || status.Progress != 100 {
+		t.Fatalf("wrong status for always-synced wallet: %+v", status)
+	}
+}
+
+// tNoSyncStatusWallet is a wallet that does not implement asset.SyncStatuser.
+type tNoSyncStatusWallet struct {
+	asset.Wallet
+}
+
+// tNoNewAddressWallet is a wallet that does not implement asset.NewAddresser.
+type tNoNewAddressWallet struct {
+	asset.Wallet
+}
+
+func TestDepositAddressPolicy(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	assetID := tUTXOAssetA.ID
+
+	if err := tCore.SetDepositAddressPolicy(assetID, &DepositAddressPolicy{OnDeposit: true}); err == nil {
+		t.Fatalf("no error for missing wallet")
+	}
+
+	dcrWallet, tDcrWallet := newTWallet(assetID)
+	tCore.wallets[assetID] = dcrWallet
+	dcrWallet.address = "initaddr"
+
+	currentAddr := func() string {
+		dcrWallet.mtx.RLock()
+		defer dcrWallet.mtx.RUnlock()
+		return dcrWallet.address
+	}
+
+	// The wallet's address is updated before the rotator tracks it, so wait
+	// for both.
+	tracked := func(addr string) bool {
+		r := tCore.depositRotator(assetID)
+		if r == nil {
+			return false
+		}
+		for _, a := range r.addresses() {
+			if a.Address == addr {
+				return true
+			}
+		}
+		return false
+	}
+	waitForAddr := func(addr string) {
+		t.Helper()
+		for i := 0; i < 100 && (currentAddr() != addr || !tracked(addr)); i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if currentAddr() != addr || !tracked(addr) {
+			t.Fatalf("deposit address not rotated to %s. current address = %s", addr, currentAddr())
+		}
+	}
+
+	var txCount int
+	depositTo := func(addr string, amt uint64) {
+		txCount++
+		note := &asset.TransactionNote{
+			Transaction: &asset.WalletTransaction{
+				Type:      asset.Receive,
+				ID:        fmt.Sprintf("tx%d", txCount),
+				Amount:    amt,
+				Recipient: &addr,
 			},
-			expectConfirmRedemptionCalled: true,
-			expectedStatus:                order.MatchConfirmed,
+			New: true,
+		}
+		note.AssetID = assetID
+		tCore.handleWalletNotification(note)
+	}
+
+	// Rotate on deposit.
+	if err := tCore.SetDepositAddressPolicy(assetID, &DepositAddressPolicy{OnDeposit: true}); err != nil {
+		t.Fatalf("SetDepositAddressPolicy error: %v", err)
+	}
+	depositTo("initaddr", 5e8)
+	waitForAddr("newaddr1")
+	depositTo("newaddr1", 2e8)
+	waitForAddr("newaddr2")
+	// A second deposit to an old address is attributed to it, but does not
+	// rotate the current address.
+	depositTo("initaddr", 1e8)
+	time.Sleep(50 * time.Millisecond)
+	if addr := currentAddr(); addr != "newaddr2" {
+		t.Fatalf("address rotated for deposit to old address. current address = %s", addr)
+	}
+
+	addrs, err := tCore.DepositAddresses(assetID)
+	if err != nil {
+		t.Fatalf("DepositAddresses error: %v", err)
+	}
+	expAddrs := []struct {
+		addr     string
+		received uint64
+		deposits int
+	}{{"initaddr", 6e8, 2}, {"newaddr1", 2e8, 1}, {"newaddr2", 0, 0}}
+	checkAddrs := func(addrs []*DepositAddress) {
+		t.Helper()
+		if len(addrs) != len(expAddrs) {
+			t.Fatalf("expected %d tracked addresses, got %d", len(expAddrs), len(addrs))
+		}
+		for i, exp := range expAddrs {
+			a := addrs[i]
+			if a.Address != exp.addr || a.Received != exp.received || a.Deposits != exp.deposits {
+				t.Fatalf("wrong deposit address record %d. wanted %+v, got %+v", i, exp, a)
+			}
+		}
+	}
+	checkAddrs(addrs)
+
+	// The policy and the tracked addresses are stored.
+	rec := rig.db.depositAddressRecord(assetID)
+	if rec == nil {
+		t.Fatalf("deposit address policy not stored")
+	}
+	if !rec.OnDeposit || rec.Interval != 0 || len(rec.Credited) != 3 {
+		t.Fatalf("wrong stored deposit address policy %+v", rec)
+	}
+	checkAddrs(rec.Addresses)
+
+	// The stored policy is resumed after a restart, and deposits that were
+	// already credited are not credited again.
+	tCore.depositRotatorsMtx.Lock()
+	tCore.depositRotators[assetID].stop()
+	delete(tCore.depositRotators, assetID)
+	tCore.depositRotatorsMtx.Unlock()
+	tCore.loadDepositAddressPolicies()
+	addrs, _ = tCore.DepositAddresses(assetID)
+	checkAddrs(addrs)
+	txCount--
+	depositTo("newaddr1", 2e8) // same tx ID
+	depositTo("newaddr2", 3e8)
+	waitForAddr("newaddr3")
+	expAddrs[2].received, expAddrs[2].deposits = 3e8, 1
+	expAddrs = append(expAddrs, struct {
+		addr     string
+		received uint64
+		deposits int
+	}{"newaddr3", 0, 0})
+	addrs, _ = tCore.DepositAddresses(assetID)
+	checkAddrs(addrs)
+
+	// Scheduled rotation.
+	if err := tCore.SetDepositAddressPolicy(assetID, &DepositAddressPolicy{Interval: 10 * time.Millisecond}); err != nil {
+		t.Fatalf("SetDepositAddressPolicy error: %v", err)
+	}
+	for i := 0; i < 100 && tDcrWallet.newAddrCount.Load() < 5; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := tDcrWallet.newAddrCount.Load(); n < 5 {
+		t.Fatalf("expected at least 2 scheduled rotations, got %d", n-3)
+	}
+
+	// Disabling the policy stops rotation.
+	if err := tCore.SetDepositAddressPolicy(assetID, nil); err != nil {
+		t.Fatalf("SetDepositAddressPolicy error: %v", err)
+	}
+	rotated := tDcrWallet.newAddrCount.Load()
+	time.Sleep(50 * time.Millisecond)
+	if tDcrWallet.newAddrCount.Load() != rotated {
+		t.Fatalf("address rotated after policy disabled")
+	}
+	if addrs, _ = tCore.DepositAddresses(assetID); len(addrs) != 0 {
+		t.Fatalf("addresses tracked after policy disabled")
+	}
+	if rig.db.depositAddressRecord(assetID) != nil {
+		t.Fatalf("deposit address policy still stored after policy disabled")
+	}
+
+	// Wallets that don't generate new addresses are not rotated.
+	dcrWallet.mtx.Lock()
+	dcrWallet.Wallet = &tNoNewAddressWallet{tDcrWallet}
+	dcrWallet.mtx.Unlock()
+	if err := tCore.SetDepositAddressPolicy(assetID, &DepositAddressPolicy{Interval: time.Millisecond, OnDeposit: true}); err != nil {
+		t.Fatalf("SetDepositAddressPolicy error for non-NewAddresser: %v", err)
+	}
+	if tCore.depositRotator(assetID) != nil {
+		t.Fatalf("policy applied for wallet without new addresses")
+	}
+}
+
+func TestDustLimitAt(t *testing.T) {
+	a := &dex.Asset{MaxFeeRate: 10, DustLimit: 5000}
+	for _, tt := range []struct {
+		feeRate, exp uint64
+	}{{0, 0}, {1, 500}, {4, 2000}, {10, 5000}, {20, 5000}} {
+		if limit := dustLimitAt(a, tt.feeRate); limit != tt.exp {
+			t.Fatalf("fee rate %d: wanted dust limit %d, got %d", tt.feeRate, tt.exp, limit)
+		}
+	}
+	// No limit.
+	if limit := dustLimitAt(&dex.Asset{MaxFeeRate: 10}, 5); limit != 0 {
+		t.Fatalf("wanted no dust limit, got %d", limit)
+	}
+}
+
+func TestParseCert(t *testing.T) {
+	byteCert := []byte{0x0a, 0x0b}
+	cert, err := parseCert("anyhost", []byte{0x0a, 0x0b}, dex.Mainnet)
+	if err != nil {
+		t.Fatalf("byte cert error: %v", err)
+	}
+	if !bytes.Equal(cert, byteCert) {
+		t.Fatalf("byte cert note returned unmodified. expected %x, got %x", byteCert, cert)
+	}
+	byteCert = []byte{0x05, 0x06}
+	certFile, _ := os.CreateTemp("", "dumbcert")
+	defer os.Remove(certFile.Name())
+	certFile.Write(byteCert)
+	certFile.Close()
+	cert, err = parseCert("anyhost", certFile.Name(), dex.Mainnet)
+	if err != nil {
+		t.Fatalf("file cert error: %v", err)
+	}
+	if !bytes.Equal(cert, byteCert) {
+		t.Fatalf("byte cert note returned unmodified. expected %x, got %x", byteCert, cert)
+	}
+	_, err = parseCert("bison.exchange:17232", []byte(nil), dex.Testnet)
+	if err != nil {
+		t.Fatalf("CertStore cert error: %v", err)
+	}
+}
+
+func TestPreOrder(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+
+	var rate uint64 = 1e8
+	quoteConvertedLotSize := calc.BaseToQuote(rate, dcrBtcLotSize)
+
+	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	dc.books[tDcrBtcMktName] = book
+
+	sellNote := &msgjson.BookOrderNote{
+		OrderNote: msgjson.OrderNote{
+			OrderID: encode.RandomBytes(32),
 		},
-		{
-			name:        "maker, makerRedeemed, confirmedRedemption, more confs than required",
-			matchStatus: order.MakerRedeemed,
-			matchSide:   order.Maker,
-			expectedNotifications: []*note{
-				{
-					severity: db.Success,
-					topic:    TopicRedemptionConfirmed,
-				},
-			},
-			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
-				Confs:  15,
-				Req:    10,
-				CoinID: tCoinID,
-			},
-			expectConfirmRedemptionCalled: true,
-			expectedStatus:                order.MatchConfirmed,
+		TradeNote: msgjson.TradeNote{
+			Side:     msgjson.SellOrderNum,
+			Quantity: quoteConvertedLotSize * 10,
+			Time:     uint64(time.Now().Unix()),
+			Rate:     rate,
 		},
-		{
-			name:        "taker, matchComplete, confirmedRedemption",
-			matchStatus: order.MatchComplete,
-			matchSide:   order.Taker,
-			expectedNotifications: []*note{
-				{
-					severity: db.Success,
-					topic:    TopicRedemptionConfirmed,
-				},
-			},
-			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
-				Confs:  10,
-				Req:    10,
-				CoinID: tCoinID,
-			},
-			expectConfirmRedemptionCalled: true,
-			expectedStatus:                order.MatchConfirmed,
+	}
+
+	buyNote := *sellNote
+	buyNote.TradeNote.Quantity = dcrBtcLotSize * 10
+	buyNote.TradeNote.Side = msgjson.BuyOrderNum
+
+	var baseFeeRate uint64 = 5
+	var quoteFeeRate uint64 = 10
+
+	err := book.Sync(&msgjson.OrderBook{
+		MarketID:     tDcrBtcMktName,
+		Seq:          1,
+		Epoch:        1,
+		Orders:       []*msgjson.BookOrderNote{sellNote, &buyNote},
+		BaseFeeRate:  baseFeeRate,
+		QuoteFeeRate: quoteFeeRate,
+	})
+	if err != nil {
+		t.Fatalf("Sync error: %v", err)
+	}
+
+	preSwap := &asset.PreSwap{
+		Estimate: &asset.SwapEstimate{
+			MaxFees:            1001,
+			Lots:               5,
+			RealisticBestCase:  15,
+			RealisticWorstCase: 20,
 		},
-		{
-			name:        "maker, makerRedeemed, incomplete",
-			matchStatus: order.MakerRedeemed,
-			matchSide:   order.Maker,
-			expectedNotifications: []*note{
-				{
-					severity: db.Data,
-					topic:    TopicConfirms,
-				},
-			},
-			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
-				Confs:  5,
-				Req:    10,
-				CoinID: tCoinID,
-			},
-			expectConfirmRedemptionCalled: true,
-			expectedStatus:                order.MakerRedeemed,
+	}
+
+	tBtcWallet.preSwap = preSwap
+
+	preRedeem := &asset.PreRedeem{
+		Estimate: &asset.RedeemEstimate{
+			RealisticBestCase:  15,
+			RealisticWorstCase: 20,
+		},
+	}
+
+	tDcrWallet.preRedeem = preRedeem
+
+	form := &TradeForm{
+		Host: tDexHost,
+		Sell: false,
+		// IsLimit: true,
+		Base:  tUTXOAssetA.ID,
+		Quote: tUTXOAssetB.ID,
+		Qty:   quoteConvertedLotSize * 5,
+		Rate:  rate,
+	}
+	preOrder, err := tCore.PreOrder(form)
+	if err != nil {
+		t.Fatalf("PreOrder market buy error: %v", err)
+	}
+
+	compUint64 := func(tag string, a, b uint64) {
+		t.Helper()
+		if a != b {
+			t.Fatalf("%s: %d != %d", tag, a, b)
+		}
+	}
+
+	est1, est2 := preSwap.Estimate, preOrder.Swap.Estimate
+	compUint64("MaxFees", est1.MaxFees, est2.MaxFees)
+	compUint64("RealisticWorstCase", est1.RealisticWorstCase, est2.RealisticWorstCase)
+	compUint64("RealisticBestCase", est1.RealisticBestCase, est2.RealisticBestCase)
+	// This is a buy order, so the from asset is the quote asset.
+	compUint64("PreOrder.FeeSuggestion.quote", quoteFeeRate, tBtcWallet.preSwapForm.FeeSuggestion)
+	compUint64("PreOrder.FeeSuggestion.base", baseFeeRate, tDcrWallet.preRedeemForm.FeeSuggestion)
+
+	// Missing book is an error
+	delete(dc.books, tDcrBtcMktName)
+	_, err = tCore.PreOrder(form)
+	if err == nil {
+		t.Fatalf("no error for market order with missing book")
+	}
+	dc.books[tDcrBtcMktName] = book
+
+	// Exercise the market sell path too.
+	form.Sell = true
+	_, err = tCore.PreOrder(form)
+	if err != nil {
+		t.Fatalf("PreOrder market sell error: %v", err)
+	}
+
+	// Market orders have to have a market to make estimates.
+	book.Unbook(&msgjson.UnbookOrderNote{
+		MarketID: tDcrBtcMktName,
+		OrderID:  sellNote.OrderID,
+	})
+	book.Unbook(&msgjson.UnbookOrderNote{
+		MarketID: tDcrBtcMktName,
+		OrderID:  buyNote.OrderID,
+	})
+	_, err = tCore.PreOrder(form)
+	if err == nil {
+		t.Fatalf("no error for market order with empty market")
+	}
+
+	// Limit orders have no such restriction.
+	form.IsLimit = true
+	_, err = tCore.PreOrder(form)
+	if err != nil {
+		t.Fatalf("PreOrder limit sell error: %v", err)
+	}
+
+	var newBaseFeeRate uint64 = 55
+	var newQuoteFeeRate uint64 = 65
+	feeRateSource := func(msg *msgjson.Message, f msgFunc) error {
+		var resp *msgjson.Message
+		if string(msg.Payload) == "42" {
+			resp, _ = msgjson.NewResponse(msg.ID, newBaseFeeRate, nil)
+		} else {
+			resp, _ = msgjson.NewResponse(msg.ID, newQuoteFeeRate, nil)
+		}
+		f(resp)
+		return nil
+	}
+
+	// Removing the book should cause us to
+	delete(dc.books, tDcrBtcMktName)
+	rig.ws.queueResponse(msgjson.FeeRateRoute, feeRateSource)
+	rig.ws.queueResponse(msgjson.FeeRateRoute, feeRateSource)
+
+	_, err = tCore.PreOrder(form)
+	if err != nil {
+		t.Fatalf("PreOrder limit sell error #2: %v", err)
+	}
+	// sell order now, so from asset is base asset
+	compUint64("PreOrder.FeeSuggestion quote asset from server", newQuoteFeeRate, tBtcWallet.preRedeemForm.FeeSuggestion)
+	compUint64("PreOrder.FeeSuggestion base asset from server", newBaseFeeRate, tDcrWallet.preSwapForm.FeeSuggestion)
+	dc.books[tDcrBtcMktName] = book
+
+	// no DEX
+	delete(tCore.conns, dc.acct.host)
+	_, err = tCore.PreOrder(form)
+	if err == nil {
+		t.Fatalf("no error for unknown DEX")
+	}
+	tCore.conns[dc.acct.host] = dc
+
+	// no wallet
+	delete(tCore.wallets, tUTXOAssetA.ID)
+	_, err = tCore.PreOrder(form)
+	if err == nil {
+		t.Fatalf("no error for missing wallet")
+	}
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+
+	// base wallet not connected
+	dcrWallet.hookedUp = false
+	tDcrWallet.connectErr = tErr
+	_, err = tCore.PreOrder(form)
+	if err == nil {
+		t.Fatalf("no error for unconnected base wallet")
+	}
+	dcrWallet.hookedUp = true
+	tDcrWallet.connectErr = nil
+
+	// quote wallet not connected
+	btcWallet.hookedUp = false
+	tBtcWallet.connectErr = tErr
+	_, err = tCore.PreOrder(form)
+	if err == nil {
+		t.Fatalf("no error for unconnected quote wallet")
+	}
+	btcWallet.hookedUp = true
+	tBtcWallet.connectErr = nil
+
+	// success again
+	_, err = tCore.PreOrder(form)
+	if err != nil {
+		t.Fatalf("PreOrder error after fixing everything: %v", err)
+	}
+}
+
+func TestEstimateTradingCost(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	dc := rig.dc
+
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+
+	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
+	dc.books[tDcrBtcMktName] = book
+	if err := book.Sync(&msgjson.OrderBook{
+		MarketID:     tDcrBtcMktName,
+		Seq:          1,
+		Epoch:        1,
+		BaseFeeRate:  5,
+		QuoteFeeRate: 10,
+	}); err != nil {
+		t.Fatalf("Sync error: %v", err)
+	}
+
+	tDcrWallet.preSwap = &asset.PreSwap{
+		Estimate: &asset.SwapEstimate{
+			RealisticBestCase:  1500,
+			RealisticWorstCase: 4000,
 		},
-		{
-			name:        "maker, makerRedeemed, replacedTx",
-			matchStatus: order.MakerRedeemed,
-			matchSide:   order.Maker,
-			expectedNotifications: []*note{
-				{
-					severity: db.WarningLevel,
-					topic:    TopicRedemptionResubmitted,
-				},
-				{
-					severity: db.Data,
-					topic:    TopicConfirms,
-				},
-			},
-			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
-				Confs:  0,
-				Req:    10,
-				CoinID: tUpdatedCoinID,
-			},
-			expectConfirmRedemptionCalled: true,
-			expectedStatus:                order.MakerRedeemed,
+	}
+	tBtcWallet.preRedeem = &asset.PreRedeem{
+		Estimate: &asset.RedeemEstimate{
+			RealisticBestCase:  300,
+			RealisticWorstCase: 900,
 		},
-		{
-			name:        "taker, matchComplete, replacedTx",
-			matchStatus: order.MatchComplete,
-			matchSide:   order.Taker,
-			expectedNotifications: []*note{
-				{
-					severity: db.WarningLevel,
-					topic:    TopicRedemptionResubmitted,
-				},
-				{
-					severity: db.Data,
-					topic:    TopicConfirms,
-				},
+	}
+	dc.acct.authMtx.Lock()
+	dc.acct.rep = account.Reputation{BondedTier: 2, Penalties: 1}
+	dc.acct.authMtx.Unlock()
+
+	cost, err := tCore.EstimateTradingCost(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, 5*dcrBtcLotSize, 1e8, true)
+	if err != nil {
+		t.Fatalf("EstimateTradingCost error: %v", err)
+	}
+	if cost.SwapAssetID != tUTXOAssetA.ID || cost.RedeemAssetID != tUTXOAssetB.ID {
+		t.Fatalf("wrong assets for sell order. swap = %d, redeem = %d", cost.SwapAssetID, cost.RedeemAssetID)
+	}
+	if cost.SwapFeeAssetID != tUTXOAssetA.ID || cost.RedeemFeeAssetID != tUTXOAssetB.ID {
+		t.Fatalf("wrong fee assets for sell order. swap = %d, redeem = %d", cost.SwapFeeAssetID, cost.RedeemFeeAssetID)
+	}
+	if cost.SwapFees != 4000 || cost.SwapFeesBestCase != 1500 || cost.RedeemFees != 900 || cost.RedeemFeesBestCase != 300 {
+		t.Fatalf("wrong on-chain fees: %+v", cost)
+	}
+	if cost.TradingFees != 0 || cost.TotalSwapAssetCost != 4000 {
+		t.Fatalf("wrong aggregate swap asset cost %d", cost.TotalSwapAssetCost)
+	}
+	if cost.Bond == nil || cost.Bond.Amounts["dcr"] != dcrBondAsset.Amt || cost.Bond.Expiry != dc.cfg.BondExpiry {
+		t.Fatalf("wrong bond requirement %+v", cost.Bond)
+	}
+	if cost.Bond.Tier != 1 {
+		t.Fatalf("wrong tier %d", cost.Bond.Tier)
+	}
+	if cost.Bond.Note == "" {
+		t.Fatalf("no bond note")
+	}
+	if tDcrWallet.preSwapForm.Lots != 5 || tDcrWallet.preSwapForm.Immediate {
+		t.Fatalf("wrong swap estimate form %+v", tDcrWallet.preSwapForm)
+	}
+
+	// Buy order swaps the quote asset.
+	tBtcWallet.preSwap = tDcrWallet.preSwap
+	tDcrWallet.preRedeem = tBtcWallet.preRedeem
+	cost, err = tCore.EstimateTradingCost(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID, 5*dcrBtcLotSize, 1e8, false)
+	if err != nil {
+		t.Fatalf("EstimateTradingCost buy error: %v", err)
+	}
+	if cost.SwapAssetID != tUTXOAssetB.ID || cost.RedeemAssetID != tUTXOAssetA.ID {
+		t.Fatalf("wrong assets for buy order. swap = %d, redeem = %d", cost.SwapAssetID, cost.RedeemAssetID)
+	}
+
+	// Unknown host.
+	if _, err = tCore.EstimateTradingCost("unknown.host", tUTXOAssetA.ID, tUTXOAssetB.ID, 5*dcrBtcLotSize, 1e8, true); err == nil {
+		t.Fatalf("no error for unknown host")
+	}
+}
+
+func TestOrderFeeAssets(t *testing.T) {
+	const tokenID = 60001
+	asset.RegisterToken(tokenID, &dex.Token{
+		ParentID: tACCTAsset.ID,
+		Name:     "Test Token",
+		UnitInfo: dex.UnitInfo{Conventional: dex.Denomination{ConversionFactor: 1e6}},
+	}, &asset.WalletDefinition{}, nil)
+
+	// A token market's fees are reported in the parent asset, not the token.
+	for _, sell := range []bool{true, false} {
+		lo := &order.LimitOrder{
+			P: order.Prefix{
+				BaseAsset:  tokenID,
+				QuoteAsset: tUTXOAssetB.ID,
+				OrderType:  order.LimitOrderType,
 			},
-			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
-				Confs:  0,
-				Req:    10,
-				CoinID: tUpdatedCoinID,
+			T: order.Trade{
+				Sell:     sell,
+				Quantity: 1e6,
 			},
-			expectConfirmRedemptionCalled: true,
-			expectedStatus:                order.MatchComplete,
-		},
+			Rate: 1e8,
+		}
+		corder := coreOrderFromTrade(lo, &db.OrderMetaData{
+			SwapFeesPaid:       100,
+			RedemptionFeesPaid: 200,
+		})
+		fees := corder.FeesPaid
+		if fees.Swap != 100 || fees.Redemption != 200 {
+			t.Fatalf("sell = %t: wrong fees paid %+v", sell, fees)
+		}
+		expSwapFeeAsset, expRedeemFeeAsset := tUTXOAssetB.ID, tACCTAsset.ID
+		if sell {
+			expSwapFeeAsset, expRedeemFeeAsset = tACCTAsset.ID, tUTXOAssetB.ID
+		}
+		if fees.SwapFeeAsset != expSwapFeeAsset || fees.RedemptionFeeAsset != expRedeemFeeAsset {
+			t.Fatalf("sell = %t: wrong fee assets. swap = %d, redemption = %d",
+				sell, fees.SwapFeeAsset, fees.RedemptionFeeAsset)
+		}
+	}
+
+	if feeAsset := asset.FeeAsset(tUTXOAssetA.ID); feeAsset != tUTXOAssetA.ID {
+		t.Fatalf("wrong fee asset %d for a base chain asset", feeAsset)
+	}
+}
+
+func TestRefreshServerConfig(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+
+	// Add an API version to supportedAPIVers to use in tests.
+	const newAPIVer = ^uint16(0) - 1
+	supportedAPIVers = append(supportedAPIVers, int32(newAPIVer))
+
+	queueConfig := func(err *msgjson.Error, apiVer uint16) {
+		rig.ws.queueResponse(msgjson.ConfigRoute, func(msg *msgjson.Message, f msgFunc) error {
+			cfg := *rig.dc.cfg
+			cfg.APIVersion = apiVer
+			resp, _ := msgjson.NewResponse(msg.ID, cfg, err)
+			f(resp)
+			return nil
+		})
+	}
+	tests := []struct {
+		name       string
+		configErr  *msgjson.Error
+		gotAPIVer  uint16
+		marketBase uint32
+		wantErr    bool
+	}{{
+		name:       "ok",
+		marketBase: tUTXOAssetA.ID,
+		gotAPIVer:  newAPIVer,
+	}, {
+		name:      "unable to fetch config",
+		configErr: new(msgjson.Error),
+		wantErr:   true,
+	}, {
+		name:       "api not in wanted versions",
+		gotAPIVer:  ^uint16(0),
+		marketBase: tUTXOAssetA.ID,
+		wantErr:    true,
+	}, {
+		name:       "generate maps failure",
+		marketBase: ^uint32(0),
+		gotAPIVer:  newAPIVer,
+		wantErr:    true,
+	}}
+
+	for _, test := range tests {
+		rig.dc.cfg.Markets[0].Base = test.marketBase
+		queueConfig(test.configErr, test.gotAPIVer)
+		_, err := rig.dc.refreshServerConfig()
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("expected error for test %q", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error for test %q: %v", test.name, err)
+		}
+	}
+}
+
+func TestCredentialHandling(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	clearCreds := func() {
+		tCore.credentials = nil
+		rig.db.creds = nil
+	}
+
+	clearCreds()
+	tCore.newCrypter = encrypt.NewCrypter
+	tCore.reCrypter = encrypt.Deserialize
+
+	_, err := tCore.InitializeClient(tPW, nil)
+	if err != nil {
+		t.Fatalf("InitializeClient error: %v", err)
+	}
+
+	// Since the actual encrypt package crypter is now used instead of the dummy
+	// tCrypter, the acct.encKey should be updated to reflect acct.privKey.
+	// Although the test does not rely on this, we should keep the dexAccount
+	// self-consistent and avoid confusing messages in the test log.
+	err = rig.resetAcctEncKey(tPW)
+	if err != nil {
+		t.Fatalf("InitializeClient error: %v", err)
+	}
+
+	tCore.Logout()
+
+	err = tCore.Login(tPW)
+	if err != nil {
+		t.Fatalf("Login error: %v", err)
+	}
+	// NOTE: a warning note is expected. "Wallet connection warning - Incomplete
+	// registration detected for somedex.tld:7232, but failed to connect to the
+	// Decred wallet"
+}
+
+func TestCoreAssetSeedAndPass(t *testing.T) {
+	// This test ensures the derived wallet seed and password are deterministic
+	// and depend on both asset ID and app seed.
+
+	// NOTE: the blake256 hash of an empty slice is:
+	// []byte{0x71, 0x6f, 0x6e, 0x86, 0x3f, 0x74, 0x4b, 0x9a, 0xc2, 0x2c, 0x97, 0xec, 0x7b, 0x76, 0xea, 0x5f,
+	//        0x59, 0x8, 0xbc, 0x5b, 0x2f, 0x67, 0xc6, 0x15, 0x10, 0xbf, 0xc4, 0x75, 0x13, 0x84, 0xea, 0x7a}
+	// The above was very briefly the password for all seeded wallets, not released.
+
+	tests := []struct {
+		name     string
+		appSeed  []byte
+		assetID  uint32
+		wantSeed []byte
+		wantPass []byte
+	}{
 		{
-			// This case could happen if the dex was shut down right after
-			// a resubmission.
-			name:        "taker, matchComplete, replacedTx and already confirmed",
-			matchStatus: order.MatchComplete,
-			matchSide:   order.Taker,
-			expectedNotifications: []*note{
-				{
-					severity: db.WarningLevel,
-					topic:    TopicRedemptionResubmitted,
-				},
-				{
-					severity: db.Success,
-					topic:    TopicRedemptionConfirmed,
-				},
-			},
-			confirmRedemptionResult: &asset.ConfirmRedemptionStatus{
-				Confs:  10,
-				Req:    10,
-				CoinID: tUpdatedCoinID,
-			},
-			expectConfirmRedemptionCalled: true,
-			expectedStatus:                order.MatchConfirmed,
+			name:    "base",
+			appSeed: []byte{1, 2, 3},
+			assetID: 2,
+			wantSeed: []byte{
+				0xac, 0x61, 0xb1, 0xbc, 0x77, 0xd0, 0xa6, 0xd5, 0xd2, 0xb5, 0xc9, 0x77, 0x91, 0xd6, 0x4a, 0xaf,
+				0x4a, 0xa3, 0x47, 0xb7, 0xb, 0x85, 0xe, 0x82, 0x1c, 0x79, 0xab, 0xc0, 0x86, 0x50, 0xee, 0xda},
+			wantPass: []byte{
+				0xd8, 0xf0, 0x27, 0x4d, 0xbc, 0x56, 0xb0, 0x74, 0x1e, 0x20, 0x3b, 0x98, 0xe9, 0xaa, 0x5c, 0xba,
+				0x13, 0xfd, 0x60, 0x3b, 0x83, 0x76, 0x2e, 0x4b, 0x5d, 0x6d, 0x19, 0x57, 0x89, 0xe2, 0x8b, 0xc7},
 		},
 		{
-			name:                          "maker, makerRedeemed, error",
-			matchStatus:                   order.MakerRedeemed,
-			matchSide:                     order.Maker,
-			confirmRedemptionErr:          errors.New("err"),
-			expectedStatus:                order.MakerRedeemed,
-			expectTicksDelayed:            true,
-			expectConfirmRedemptionCalled: true,
+			name:    "change app seed",
+			appSeed: []byte{2, 2, 3},
+			assetID: 2,
+			wantSeed: []byte{
+				0xf, 0xc9, 0xf, 0xa8, 0xb3, 0xe9, 0x31, 0x2a, 0xba, 0xf1, 0xda, 0x70, 0x41, 0x81, 0x49, 0xed,
+				0xad, 0x47, 0x9, 0xcd, 0xe2, 0x17, 0x14, 0xd, 0x63, 0x49, 0x8a, 0xd8, 0xff, 0x1f, 0x3e, 0x8b},
+			wantPass: []byte{
+				0x78, 0x21, 0x72, 0x59, 0xbe, 0x39, 0xea, 0x54, 0x10, 0x46, 0x7d, 0x7e, 0xa, 0x95, 0xc4, 0xa0,
+				0xd8, 0x73, 0xce, 0x1, 0xb2, 0x49, 0x98, 0x6c, 0x68, 0xc5, 0x69, 0x69, 0xa7, 0x13, 0xc1, 0xce},
 		},
 		{
-			name:                 "maker, makerRedeemed, swap refunded error",
-			matchStatus:          order.MakerRedeemed,
-			matchSide:            order.Maker,
-			confirmRedemptionErr: asset.ErrSwapRefunded,
-			expectedStatus:       order.MatchConfirmed,
-			expectedNotifications: []*note{
-				{
-					severity: db.ErrorLevel,
-					topic:    TopicSwapRefunded,
-				},
-			},
-			expectConfirmRedemptionCalled: true,
+			name:    "change asset ID",
+			appSeed: []byte{1, 2, 3},
+			assetID: 0,
+			wantSeed: []byte{
+				0xe1, 0xad, 0x62, 0xe4, 0x60, 0xfd, 0x75, 0x91, 0x3d, 0x41, 0x2e, 0x8e, 0xc5, 0x72, 0xd4, 0xa2,
+				0x39, 0x2d, 0x32, 0x86, 0xf0, 0x6b, 0xf7, 0xdf, 0x48, 0xcc, 0x57, 0xb1, 0x4b, 0x7b, 0xc6, 0xce},
+			wantPass: []byte{
+				0x52, 0xba, 0x59, 0x21, 0xd3, 0xc5, 0x6b, 0x2, 0x2c, 0x12, 0xc1, 0x98, 0xdc, 0x84, 0xed, 0x68,
+				0x6, 0x35, 0xa6, 0x25, 0xd0, 0xc4, 0x49, 0x5a, 0x13, 0xc3, 0x12, 0xfb, 0xeb, 0xb3, 0x61, 0x88},
 		},
-		{
-			name:                 "taker, takerRedeemed, redemption tx rejected error",
-			matchStatus:          order.MatchComplete,
-			matchSide:            order.Taker,
-			confirmRedemptionErr: asset.ErrTxRejected,
-			expectedStatus:       order.MatchComplete,
-			expectedNotifications: []*note{
-				{
-					severity: db.Data,
-					topic:    TopicRedeemRejected,
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed, pass := AssetSeedAndPass(tt.assetID, tt.appSeed)
+			if !bytes.Equal(pass, tt.wantPass) {
+				t.Errorf("pass not as expected, got %#v", pass)
+			}
+			if !bytes.Equal(seed, tt.wantSeed) {
+				t.Errorf("seed not as expected, got %#v", seed)
+			}
+		})
+	}
+}
+
+var randU32 = func() uint32 { return uint32(rand.Int31()) }
+
+func randOrderForMarket(base, quote uint32) order.Order {
+	switch rand.Intn(3) {
+	case 0:
+		o, _ := ordertest.RandomCancelOrder()
+		o.BaseAsset = base
+		o.QuoteAsset = quote
+		return o
+	case 1:
+		o, _ := ordertest.RandomMarketOrder()
+		o.BaseAsset = base
+		o.QuoteAsset = quote
+		return o
+	default:
+		o, _ := ordertest.RandomLimitOrder()
+		o.BaseAsset = base
+		o.QuoteAsset = quote
+		return o
+	}
+}
+
+func randBytes(n int) []byte {
+	b := make([]byte, n)
+	rand.Read(b)
+	return b
+}
+
+func TestDeleteOrderFn(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	randomOdrs := func() []*db.MetaOrder {
+		acct1 := dbtest.RandomAccountInfo()
+		acct2 := dbtest.RandomAccountInfo()
+		base1, quote1 := tUTXOAssetA.ID, tUTXOAssetB.ID
+		base2, quote2 := tACCTAsset.ID, tUTXOAssetA.ID
+		n := rand.Intn(9) + 1
+		orders := make([]*db.MetaOrder, n)
+		for i := 0; i < n; i++ {
+			acct := acct1
+			base, quote := base1, quote1
+			if i%2 == 1 {
+				acct = acct2
+				base, quote = base2, quote2
+			}
+			ord := randOrderForMarket(base, quote)
+			orders[i] = &db.MetaOrder{
+				MetaData: &db.OrderMetaData{
+					Status:             order.OrderStatus(rand.Intn(5) + 1),
+					Host:               acct.Host,
+					Proof:              db.OrderProof{DEXSig: randBytes(73)},
+					SwapFeesPaid:       rand.Uint64(),
+					RedemptionFeesPaid: rand.Uint64(),
+					MaxFeeRate:         rand.Uint64(),
 				},
-			},
-			expectConfirmRedemptionCalled: true,
-		},
-		{
-			name:                          "maker, makerRedeemed, redemption tx lost",
-			matchStatus:                   order.MakerRedeemed,
-			matchSide:                     order.Maker,
-			confirmRedemptionErr:          asset.ErrTxLost,
-			expectedStatus:                order.TakerSwapCast,
-			expectConfirmRedemptionCalled: true,
-		},
-		{
-			name:                          "taker, takerRedeemed, redemption tx lost",
-			matchStatus:                   order.MatchComplete,
-			matchSide:                     order.Taker,
-			confirmRedemptionErr:          asset.ErrTxLost,
-			expectedStatus:                order.MakerRedeemed,
-			expectConfirmRedemptionCalled: true,
-		},
-		{
-			name:                          "maker, matchConfirmed",
-			matchStatus:                   order.MatchConfirmed,
-			matchSide:                     order.Maker,
-			expectedStatus:                order.MatchConfirmed,
-			expectedNotifications:         []*note{},
-			expectConfirmRedemptionCalled: false,
-		},
-		{
-			name:                          "maker, TakerSwapCast",
-			matchStatus:                   order.TakerSwapCast,
-			matchSide:                     order.Maker,
-			expectedStatus:                order.TakerSwapCast,
-			expectedNotifications:         []*note{},
-			expectConfirmRedemptionCalled: false,
-		},
-		{
-			name:                          "taker, TakerSwapCast",
-			matchStatus:                   order.TakerSwapCast,
-			matchSide:                     order.Taker,
-			expectedStatus:                order.TakerSwapCast,
-			expectedNotifications:         []*note{},
-			expectConfirmRedemptionCalled: false,
-		},
+				Order: ord,
+			}
+		}
+		return orders
 	}
 
-	notificationFeed := tCore.NotificationFeed()
-
-	for _, test := range tests {
-		tracker.mtx.Lock()
-		setupMatch(test.matchStatus, test.matchSide)
-		tracker.mtx.Unlock()
-
-		tBtcWallet.confirmRedemptionResult = test.confirmRedemptionResult
-		tBtcWallet.confirmRedemptionErr = test.confirmRedemptionErr
-		tBtcWallet.confirmRedemptionCalled = false
-
-		tCore.tickAsset(dc, tUTXOAssetB.ID)
+	ordersFile, err := os.CreateTemp("", "delete_archives_test_orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ordersFileName := ordersFile.Name()
+	ordersFile.Close()
+	os.Remove(ordersFileName)
 
-		if tBtcWallet.confirmRedemptionCalled != test.expectConfirmRedemptionCalled {
-			t.Fatalf("%s: expected confirm redemption to be called=%v but got=%v",
-				test.name, test.expectConfirmRedemptionCalled, tBtcWallet.confirmRedemptionCalled)
-		}
+	tests := []struct {
+		name, ordersFileStr string
+		wantErr             bool
+	}{{
+		name:          "ok orders and file save",
+		ordersFileStr: ordersFileName,
+	}, {
+		name:          "bad file (already closed)",
+		ordersFileStr: ordersFileName,
+		wantErr:       true,
+	}}
 
-		for _, expectedNotification := range test.expectedNotifications {
-			var n Notification
-		out:
-			for {
-				select {
-				case n = <-notificationFeed.C:
-					if n.Topic() == expectedNotification.topic {
-						break out
-					}
-				case <-time.After(60 * time.Second):
-					t.Fatalf("%s: did not receive expected notification", test.name)
-				}
+	for _, test := range tests {
+		perOrdFn, cleanupFn, err := tCore.deleteOrderFn(test.ordersFileStr)
+		if test.wantErr {
+			if err != nil {
+				continue
 			}
-
-			if n.Severity() != expectedNotification.severity {
-				t.Fatalf("%s: expected severity %v, got %v",
-					test.name, expectedNotification.severity, n.Severity())
+			t.Fatalf("%q: expected error", test.name)
+		}
+		if err != nil {
+			t.Fatalf("%q: unexpected failure: %v", test.name, err)
+		}
+		for _, o := range randomOdrs() {
+			err = perOrdFn(o)
+			if err != nil {
+				t.Fatalf("%q: unexpected failure: %v", test.name, err)
 			}
 		}
+		cleanupFn()
+	}
 
-		tracker.mtx.RLock()
-		if test.confirmRedemptionResult != nil {
-			var redeemCoin order.CoinID
-			if test.matchSide == order.Maker {
-				redeemCoin = match.MetaData.Proof.MakerRedeem
+	b, err := os.ReadFile(ordersFileName)
+	if err != nil {
+		t.Fatalf("unable to read file: %s", ordersFileName)
+	}
+	fmt.Println(string(b))
+	os.Remove(ordersFileName)
+}
+
+func TestDeleteMatchFn(t *testing.T) {
+	randomMtchs := func() []*db.MetaMatch {
+		base, quote := tUTXOAssetA.ID, tUTXOAssetB.ID
+		acct := dbtest.RandomAccountInfo()
+		n := rand.Intn(9) + 1
+		metaMatches := make([]*db.MetaMatch, 0, n)
+		for i := 0; i < n; i++ {
+			m := &db.MetaMatch{
+				MetaData: &db.MatchMetaData{
+					Proof: *dbtest.RandomMatchProof(0.5),
+					DEX:   acct.Host,
+					Base:  base,
+					Quote: quote,
+					Stamp: rand.Uint64(),
+				},
+				UserMatch: ordertest.RandomUserMatch(),
+			}
+			if i%2 == 1 {
+				m.Status = order.MatchStatus(rand.Intn(4))
 			} else {
-				redeemCoin = match.MetaData.Proof.TakerRedeem
+				m.Status = order.MatchComplete              // inactive
+				m.MetaData.Proof.Auth.RedeemSig = []byte{0} // redeemSig required for MatchComplete to be considered inactive
 			}
-			if !bytes.Equal(redeemCoin, test.confirmRedemptionResult.CoinID) {
-				t.Fatalf("%s: expected coin %v != actual %v", test.name, test.confirmRedemptionResult.CoinID, redeemCoin)
+			metaMatches = append(metaMatches, m)
+		}
+		return metaMatches
+	}
+
+	matchesFile, err := os.CreateTemp("", "delete_archives_test_matches")
+	if err != nil {
+		t.Fatal(err)
+	}
+	matchesFileName := matchesFile.Name()
+	matchesFile.Close()
+	os.Remove(matchesFileName)
+
+	tests := []struct {
+		name, matchesFileStr string
+		wantErr              bool
+	}{{
+		name:           "ok matches and file save",
+		matchesFileStr: matchesFileName,
+	}, {
+		name:           "bad file (already closed)",
+		matchesFileStr: matchesFileName,
+		wantErr:        true,
+	}}
+
+	for _, test := range tests {
+		perMatchFn, cleanupFn, err := deleteMatchFn(test.matchesFileStr)
+		if test.wantErr {
+			if err != nil {
+				continue
 			}
-			if test.confirmRedemptionResult.Confs >= test.confirmRedemptionResult.Req {
-				if len(tDcrWallet.returnedContracts) != 1 || !bytes.Equal(ourContract, tDcrWallet.returnedContracts[0]) {
-					t.Fatalf("%s: refund address not returned", test.name)
-				}
+			t.Fatalf("%q: expected error", test.name)
+		}
+		if err != nil {
+			t.Fatalf("%q: unexpected failure: %v", test.name, err)
+		}
+		for _, m := range randomMtchs() {
+			err = perMatchFn(m, true)
+			if err != nil {
+				t.Fatalf("%q: unexpected failure: %v", test.name, err)
 			}
 		}
+		cleanupFn()
+	}
 
-		ticksDelayed := match.tickGovernor != nil
-		if ticksDelayed != test.expectTicksDelayed {
-			t.Fatalf("%s: expected ticks delayed %v but got %v", test.name, test.expectTicksDelayed, ticksDelayed)
+	b, err := os.ReadFile(matchesFileName)
+	if err != nil {
+		t.Fatalf("unable to read file: %s", matchesFileName)
+	}
+	fmt.Println(string(b))
+	os.Remove(matchesFileName)
+}
+
+func TestDeleteArchivedRecords(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	tdb := tCore.db.(*TDB)
+
+	tempFile := func(suffix string) (path string) {
+		matchesFile, err := os.CreateTemp("", suffix+"delete_archives_test_matches")
+		if err != nil {
+			t.Fatal(err)
+		}
+		matchesFileName := matchesFile.Name()
+		matchesFile.Close()
+		os.Remove(matchesFileName)
+		return matchesFileName
+	}
+
+	tests := []struct {
+		name                                              string
+		olderThan                                         *time.Time
+		matchesFileStr, ordersFileStr                     string
+		archivedMatches, archivedOrders                   int
+		deleteInactiveOrdersErr, deleteInactiveMatchesErr error
+		wantErr                                           bool
+	}{{
+		name:            "ok no order or file save",
+		archivedMatches: 12,
+		archivedOrders:  24,
+	}, {
+		name:            "ok orders and file save",
+		ordersFileStr:   tempFile("abc"),
+		matchesFileStr:  tempFile("123"),
+		archivedMatches: 34,
+		archivedOrders:  67,
+	}, {
+		name:                    "orders save error",
+		ordersFileStr:           tempFile("abc"),
+		deleteInactiveOrdersErr: errors.New(""),
+		wantErr:                 true,
+	}, {
+		name:                     "matches save error",
+		matchesFileStr:           tempFile("123"),
+		deleteInactiveMatchesErr: errors.New(""),
+		wantErr:                  true,
+	}}
+
+	for _, test := range tests {
+		tdb.archivedMatches = test.archivedMatches
+		tdb.archivedOrders = test.archivedOrders
+		tdb.deleteInactiveOrdersErr = test.deleteInactiveOrdersErr
+		tdb.deleteInactiveMatchesErr = test.deleteInactiveMatchesErr
+		nRecordsDeleted, err := tCore.DeleteArchivedRecords(test.olderThan, test.matchesFileStr, test.ordersFileStr)
+		if test.wantErr {
+			if err != nil {
+				continue
+			}
+			t.Fatalf("%q: expected error", test.name)
+		}
+		if err != nil {
+			t.Fatalf("%q: unexpected failure: %v", test.name, err)
+		}
+		expectedRecords := test.archivedMatches + test.archivedOrders
+		if nRecordsDeleted != expectedRecords {
+			t.Fatalf("%s: Expected %d deleted records, got %d", test.name, expectedRecords, nRecordsDeleted)
 		}
+	}
+}
+
+func TestLCM(t *testing.T) {
+	tests := []struct {
+		name                                  string
+		a, b, wantDenom, wantMultA, wantMultB uint64
+	}{{
+		name:      "ok 5 and 10",
+		a:         5,
+		b:         10,
+		wantDenom: 10,
+		wantMultA: 2,
+		wantMultB: 1,
+	}, {
+		name:      "ok 3 and 7",
+		a:         3,
+		b:         7,
+		wantDenom: 21,
+		wantMultA: 7,
+		wantMultB: 3,
+	}, {
+		name:      "ok 6 and 34",
+		a:         34,
+		b:         6,
+		wantDenom: 102,
+		wantMultA: 3,
+		wantMultB: 17,
+	}}
 
-		if match.Status != test.expectedStatus {
-			t.Fatalf("%s: expected status %v but got %v", test.name, test.expectedStatus, match.Status)
+	for _, test := range tests {
+		denom, multA, multB := lcm(test.a, test.b)
+		if denom != test.wantDenom || multA != test.wantMultA || multB != test.wantMultB {
+			t.Fatalf("%q: expected %d %d %d but got %d %d %d", test.name,
+				test.wantDenom, test.wantMultA, test.wantMultB, denom, multA, multB)
 		}
-		tracker.mtx.RUnlock()
 	}
 }
 
-func TestMaxSwapsRedeemsInTx(t *testing.T) {
+func TestToggleRateSourceStatus(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	dc := rig.dc
 	tCore := rig.core
 
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
-
-	tDcrWallet.info.MaxSwapsInTx = 4
-	tBtcWallet.info.MaxRedeemsInTx = 4
-
-	lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, 0, 0)
-	oid := lo.ID()
-	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
-	dc.trades[oid] = tracker
+	tests := []struct {
+		name, source  string
+		wantErr, init bool
+	}{{
+		name:    "Invalid rate source",
+		source:  "binance",
+		wantErr: true,
+	}, {
+		name:    "ok valid source",
+		source:  messari,
+		wantErr: false,
+	}, {
+		name:    "ok already disabled/not initialized || enabled",
+		source:  messari,
+		wantErr: false,
+	}}
 
-	newMatch := func(side order.MatchSide, status order.MatchStatus) *matchTracker {
-		return &matchTracker{
-			prefix: lo.Prefix(),
-			trade:  lo.Trade(),
-			MetaMatch: db.MetaMatch{
-				MetaData: &db.MatchMetaData{
-					Proof: db.MatchProof{
-						Auth: db.MatchAuth{
-							MatchStamp: uint64(time.Now().UnixMilli()),
-							AuditStamp: uint64(time.Now().UnixMilli()),
-						},
-					},
-				},
-				UserMatch: &order.UserMatch{
-					MatchID:     ordertest.RandomMatchID(),
-					Side:        side,
-					Address:     ordertest.RandomAddress(),
-					Status:      status,
-					FeeRateSwap: tMaxFeeRate,
-				},
-			},
+	// Test disabling fiat rate source.
+	for _, test := range tests {
+		err := tCore.ToggleRateSourceStatus(test.source, true)
+		if test.wantErr != (err != nil) {
+			t.Fatalf("%s: wantErr = %t, err = %v", test.name, test.wantErr, err)
 		}
 	}
 
-	swapabbleMatches := func(num int) map[order.MatchID]*matchTracker {
-		matches := make(map[order.MatchID]*matchTracker, num)
-		for i := 0; i < num; i++ {
-			m := newMatch(order.Maker, order.NewlyMatched)
-			matches[m.MatchID] = m
+	// Test enabling fiat rate source.
+	for _, test := range tests {
+		if test.init {
+			tCore.fiatRateSources[test.source] = newCommonRateSource(tFetcher)
 		}
-		return matches
-	}
-
-	redeemableMatches := func(num int) map[order.MatchID]*matchTracker {
-		matches := make(map[order.MatchID]*matchTracker, num)
-		for i := 0; i < num; i++ {
-			m := newMatch(order.Taker, order.MakerRedeemed)
-			matches[m.MatchID] = m
-			rig.ws.queueResponse(msgjson.RedeemRoute, redeemAcker)
+		err := tCore.ToggleRateSourceStatus(test.source, false)
+		if test.wantErr != (err != nil) {
+			t.Fatalf("%s: wantErr = %t, err = %v", test.name, test.wantErr, err)
 		}
-		return matches
 	}
+}
 
-	checkNumSwaps := func(expected []int, wallet *TXCWallet) {
-		t.Helper()
-		for i := range expected {
-			if expected[i] != len(wallet.lastSwaps[i].Contracts) {
-				t.Fatalf("expected %d swaps but got %d", expected[i], len(wallet.lastSwaps[i].Contracts))
-			}
-		}
+func TestFiatRateSources(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+	supportedFetchers := len(fiatRateFetchers)
+	rateSources := tCore.FiatRateSources()
+	if len(rateSources) != supportedFetchers {
+		t.Fatalf("Expected %d number of fiat rate source/fetchers", supportedFetchers)
 	}
+}
 
-	checkNumRedeems := func(expected []int, wallet *TXCWallet) {
-		t.Helper()
-		for i := range expected {
-			if expected[i] != len(wallet.lastRedeems[i].Redemptions) {
-				t.Fatalf("expected %d swaps but got %d", expected[i], len(wallet.lastRedeems[i].Redemptions))
-			}
-		}
+func TestFiatConversions(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	// No fiat rate source initialized
+	fiatRates := tCore.fiatConversions()
+	if len(fiatRates) != 0 {
+		t.Fatal("Unexpected asset rate values.")
 	}
 
-	populateRedeemCoins := func(num int, wallet *TXCWallet) {
-		wallet.redeemCoins = make([]dex.Bytes, num)
-		for i := 0; i < num; i++ {
-			wallet.redeemCoins = append(wallet.redeemCoins, encode.RandomBytes(32))
-		}
+	// Initialize fiat rate sources.
+	for token := range fiatRateFetchers {
+		tCore.fiatRateSources[token] = newCommonRateSource(tFetcher)
 	}
 
-	// Test Swaps
-	expected := []int{4, 4, 4, 4, 4, 2}
-	tracker.matches = swapabbleMatches(22)
-	tCore.tick(tracker)
-	checkNumSwaps(expected, tDcrWallet)
+	// Fetch fiat rates.
+	tCore.wg.Add(1)
+	go func() {
+		defer tCore.wg.Done()
+		tCore.refreshFiatRates(tCtx)
+	}()
+	tCore.wg.Wait()
 
-	tDcrWallet.lastSwaps = make([]*asset.Swaps, 0)
-	expected = []int{3}
-	tracker.matches = swapabbleMatches(3)
-	tCore.tick(tracker)
-	checkNumSwaps(expected, tDcrWallet)
+	// Expects assets fiat rate values.
+	fiatRates = tCore.fiatConversions()
+	if len(fiatRates) != 2 {
+		t.Fatal("Expected assets fiat rate for two assets")
+	}
 
-	tDcrWallet.lastSwaps = make([]*asset.Swaps, 0)
-	expected = []int{4}
-	tracker.matches = swapabbleMatches(4)
-	tCore.tick(tracker)
-	checkNumSwaps(expected, tDcrWallet)
+	// fiat rates for assets can expire, and fiat rate fetchers can be
+	// removed if expired.
+	for token, source := range tCore.fiatRateSources {
+		source.fiatRates[tUTXOAssetA.ID].lastUpdate = time.Now().Add(-time.Minute)
+		source.fiatRates[tUTXOAssetB.ID].lastUpdate = time.Now().Add(-time.Minute)
+		if source.isExpired(55 * time.Second) {
+			delete(tCore.fiatRateSources, token)
+		}
+	}
 
-	// Test Redeems
-	expected = []int{4, 4, 4, 4, 4, 2}
-	tracker.matches = redeemableMatches(22)
-	populateRedeemCoins(22, tBtcWallet)
-	tCore.tick(tracker)
-	checkNumRedeems(expected, tBtcWallet)
+	fiatRates = tCore.fiatConversions()
+	if len(fiatRates) != 0 {
+		t.Fatal("Unexpected assets fiat rate values, expected to ignore expired fiat rates.")
+	}
 
-	tBtcWallet.lastRedeems = make([]*asset.RedeemForm, 0)
-	expected = []int{3}
-	tracker.matches = redeemableMatches(3)
-	populateRedeemCoins(3, tBtcWallet)
-	tCore.tick(tracker)
-	checkNumRedeems(expected, tBtcWallet)
+	if len(tCore.fiatRateSources) != 0 {
+		t.Fatal("Expected fiat conversion to be disabled, all rate source data has expired.")
+	}
+}
 
-	tBtcWallet.lastRedeems = make([]*asset.RedeemForm, 0)
-	expected = []int{4}
-	tracker.matches = redeemableMatches(4)
-	populateRedeemCoins(4, tBtcWallet)
-	tCore.tick(tracker)
-	checkNumRedeems(expected, tBtcWallet)
+type TAddressChecker struct {
+	*TXCWallet
+	checkAddrErr error
 }
 
-func TestSuspectTrades(t *testing.T) {
+func (w *TAddressChecker) CheckAddress(address string) error {
+	return w.checkAddrErr
+}
+
+func TestValidateAddress(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	dc := rig.dc
 	tCore := rig.core
 
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
-	walletSet, _, _, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
 
-	lo, dbOrder, preImg, addr := makeLimitOrder(dc, true, 0, 0)
-	oid := lo.ID()
-	tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
-		rig.db, rig.queue, walletSet, nil, rig.core.notify, rig.core.formatDetails)
-	dc.trades[oid] = tracker
+	// A wallet that can only report valid or invalid.
+	tests := []struct {
+		name              string
+		addr              string
+		validAddr         bool
+		wantStatus        AddressStatus
+		wantMissingWallet bool
+	}{{
+		name:       "valid address",
+		addr:       "randomvalidaddress",
+		validAddr:  true,
+		wantStatus: AddressValid,
+	}, {
+		name:       "invalid address",
+		addr:       "randominvalidaddress",
+		wantStatus: AddressMalformed,
+	}, {
+		name:       "empty address",
+		addr:       "",
+		validAddr:  true,
+		wantStatus: AddressMalformed,
+	}, {
+		name:              "wallet not found",
+		addr:              "randomaddr",
+		wantMissingWallet: true,
+	}}
+	for _, test := range tests {
+		tWallet.validAddr = test.validAddr
+		assetID := tUTXOAssetA.ID
+		if test.wantMissingWallet {
+			assetID = tUTXOAssetB.ID
+		}
+		status, err := tCore.ValidateAddress(assetID, test.addr)
+		if test.wantMissingWallet {
+			if err == nil {
+				t.Fatalf("%s: expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if status != test.wantStatus {
+			t.Fatalf("%s: got status %q, expected %q", test.name, status, test.wantStatus)
+		}
+	}
 
-	newMatch := func(side order.MatchSide, status order.MatchStatus) *matchTracker {
-		return &matchTracker{
-			prefix: lo.Prefix(),
-			trade:  lo.Trade(),
-			MetaMatch: db.MetaMatch{
-				MetaData: &db.MatchMetaData{
-					Proof: db.MatchProof{
-						Auth: db.MatchAuth{
-							MatchStamp: uint64(time.Now().UnixMilli()),
-							AuditStamp: uint64(time.Now().UnixMilli()),
-						},
-					},
-				},
-				UserMatch: &order.UserMatch{
-					MatchID:     ordertest.RandomMatchID(),
-					Side:        side,
-					Address:     ordertest.RandomAddress(),
-					Status:      status,
-					FeeRateSwap: tMaxFeeRate,
-				},
-			},
+	// A wallet that distinguishes wrong-network from malformed addresses.
+	checker := &TAddressChecker{TXCWallet: tWallet}
+	wallet.Wallet = checker
+	checkerTests := []struct {
+		name         string
+		checkAddrErr error
+		wantStatus   AddressStatus
+		wantErr      bool
+	}{{
+		name:       "valid address",
+		wantStatus: AddressValid,
+	}, {
+		name:         "wrong network",
+		checkAddrErr: dex.NewError(asset.ErrWrongNetworkAddress, "testnet address"),
+		wantStatus:   AddressWrongNetwork,
+	}, {
+		name:         "malformed",
+		checkAddrErr: dex.NewError(asset.ErrMalformedAddress, "bad checksum"),
+		wantStatus:   AddressMalformed,
+	}, {
+		name:         "other error",
+		checkAddrErr: tErr,
+		wantErr:      true,
+	}}
+	for _, test := range checkerTests {
+		checker.checkAddrErr = test.checkAddrErr
+		status, err := tCore.ValidateAddress(tUTXOAssetA.ID, "addr")
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("%s: expected error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if status != test.wantStatus {
+			t.Fatalf("%s: got status %q, expected %q", test.name, status, test.wantStatus)
 		}
 	}
+}
 
-	var swappableMatch1, swappableMatch2 *matchTracker
-	setSwaps := func() {
-		swappableMatch1 = newMatch(order.Maker, order.NewlyMatched)
-		swappableMatch2 = newMatch(order.Taker, order.MakerSwapCast)
-
-		// Set counterswaps for both swaps.
-		// Set valid wallet auditInfo for swappableMatch2, taker will repeat audit before swapping.
-		auditQty := calc.BaseToQuote(swappableMatch2.Rate, swappableMatch2.Quantity)
-		_, auditInfo := tMsgAudit(oid, swappableMatch2.MatchID, addr, auditQty, encode.RandomBytes(32))
-		auditInfo.Expiration = encode.DropMilliseconds(swappableMatch2.matchTime().Add(tracker.lockTimeMaker))
-		tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetA.SwapConf, nil)
-		tBtcWallet.auditInfo = auditInfo
-		swappableMatch2.counterSwap = auditInfo
+func TestExplorerURLs(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-		_, auditInfo = tMsgAudit(oid, swappableMatch1.MatchID, ordertest.RandomAddress(), 1, encode.RandomBytes(32))
-		tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetA.SwapConf, nil)
-		swappableMatch1.counterSwap = auditInfo
+	const tokenID = 60002
+	asset.RegisterToken(tokenID, &dex.Token{
+		ParentID: tACCTAsset.ID,
+		Name:     "Test Token",
+		UnitInfo: dex.UnitInfo{Conventional: dex.Denomination{ConversionFactor: 1e6}},
+	}, &asset.WalletDefinition{}, nil)
 
-		tDcrWallet.swapCounter = 0
-		tracker.matches = map[order.MatchID]*matchTracker{
-			swappableMatch1.MatchID: swappableMatch1,
-			swappableMatch2.MatchID: swappableMatch2,
+	check := func(assetID uint32, wantTx, wantAddr string) {
+		t.Helper()
+		a := tCore.asset(assetID)
+		if a.ExplorerTxURL != wantTx || a.ExplorerAddrURL != wantAddr {
+			t.Fatalf("%s: wrong explorer URLs %q, %q. expected %q, %q", unbip(assetID),
+				a.ExplorerTxURL, a.ExplorerAddrURL, wantTx, wantAddr)
+		}
+		if a = tCore.assetMap()[assetID]; a.ExplorerTxURL != wantTx || a.ExplorerAddrURL != wantAddr {
+			t.Fatalf("%s: wrong explorer URLs in asset map %q, %q", unbip(assetID), a.ExplorerTxURL, a.ExplorerAddrURL)
 		}
 	}
-	setSwaps()
-
-	// Initial success
-	_, err := tCore.tick(tracker)
-	if err != nil {
-		t.Fatalf("swap tick error: %v", err)
+	checkOut := func(assetID uint32, wantOut string) {
+		t.Helper()
+		if outURL := tCore.asset(assetID).ExplorerOutURL; outURL != wantOut {
+			t.Fatalf("%s: wrong explorer output URL %q. expected %q", unbip(assetID), outURL, wantOut)
+		}
+		if outURL := tCore.assetMap()[assetID].ExplorerOutURL; outURL != wantOut {
+			t.Fatalf("%s: wrong explorer output URL in asset map %q", unbip(assetID), outURL)
+		}
 	}
 
-	setSwaps()
-	tDcrWallet.swapErr = tErr
-	_, err = tCore.tick(tracker)
-	if err == nil || !strings.Contains(err.Error(), "error sending dcr swap transaction") {
-		t.Fatalf("swap error not propagated, err = %v", err)
-	}
-	if tDcrWallet.swapCounter != 1 {
-		t.Fatalf("never swapped")
+	// Driver defaults. Tokens use their parent chain's explorer.
+	const acctTx, acctAddr = "https://acct.explorer/tx/{txid}", "https://acct.explorer/address/{addr}"
+	const utxoTx, utxoOut = "https://utxo.explorer/tx/{txid}", "https://utxo.explorer/tx/{txid}/out/{vout}"
+	check(tUTXOAssetA.ID, "", "")
+	check(tUTXOAssetB.ID, utxoTx, "")
+	checkOut(tUTXOAssetB.ID, utxoOut)
+	checkOut(tACCTAsset.ID, "")
+	check(tACCTAsset.ID, acctTx, acctAddr)
+	check(tokenID, acctTx, acctAddr)
+
+	// A configured template overrides the transaction URL, and a parent
+	// chain's template applies to its tokens.
+	tCore.cfg.ExplorerURLs = map[uint32]string{
+		tUTXOAssetA.ID: "https://my.explorer/tx/{txid}?net=main",
+		tACCTAsset.ID:  "https://other.explorer/{txid}",
 	}
+	check(tUTXOAssetA.ID, "https://my.explorer/tx/{txid}?net=main", "")
+	check(tACCTAsset.ID, "https://other.explorer/{txid}", acctAddr)
+	check(tokenID, "https://other.explorer/{txid}", acctAddr)
 
-	// Both matches should be marked as suspect and have tickGovernors in place.
-	tracker.mtx.Lock()
-	for i, m := range []*matchTracker{swappableMatch1, swappableMatch2} {
-		if !m.suspectSwap {
-			t.Fatalf("swappable match %d not suspect after failed swap", i+1)
+	// The default explorer's output pages are not used with a configured
+	// template.
+	tCore.cfg.ExplorerURLs[tUTXOAssetB.ID] = "https://my.explorer/tx/{txid}"
+	checkOut(tUTXOAssetB.ID, "")
+
+	tCore.cfg.ExplorerURLs[tokenID] = "https://token.explorer/{txid}"
+	check(tokenID, "https://token.explorer/{txid}", acctAddr)
+}
+
+func TestEstimateSendTxFee(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	tests := []struct {
+		name              string
+		asset             uint32
+		estFee            uint64
+		value             uint64
+		subtract          bool
+		wantMissingWallet bool
+		wantErr           bool
+	}{{
+		name:     "ok",
+		asset:    tUTXOAssetA.ID,
+		subtract: true,
+		estFee:   1e8,
+		value:    1e8,
+	}, {
+		name:     "zero amount",
+		asset:    tACCTAsset.ID,
+		subtract: true,
+		wantErr:  true,
+	}, {
+		name:     "subtract true and not withdrawer",
+		asset:    tACCTAsset.ID,
+		subtract: true,
+		wantErr:  true,
+		value:    1e8,
+	}, {
+		name:              "wallet not found",
+		asset:             tUTXOAssetA.ID,
+		wantErr:           true,
+		wantMissingWallet: true,
+		value:             1e8,
+	}}
+
+	for _, test := range tests {
+		wallet, tWallet := newTWallet(test.asset)
+		tCore.wallets[test.asset] = wallet
+		if test.wantMissingWallet {
+			delete(tCore.wallets, test.asset)
 		}
-		if m.tickGovernor == nil {
-			t.Fatalf("swappable match %d has no tick meterer set", i+1)
+
+		tWallet.estFee = test.estFee
+
+		tWallet.estFeeErr = nil
+		if test.wantErr {
+			tWallet.estFeeErr = tErr
+		}
+		estimate, _, err := tCore.EstimateSendTxFee("addr", test.asset, test.value, test.subtract, false)
+		if test.wantErr {
+			if err != nil {
+				continue
+			}
+			t.Fatalf("%s: expected error", test.name)
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
 		}
-	}
-	tracker.mtx.Unlock()
 
-	// Ticking right away again should do nothing.
-	tDcrWallet.swapErr = nil
-	_, err = tCore.tick(tracker)
-	if err != nil {
-		t.Fatalf("tick error during metered swap tick: %v", err)
-	}
-	if tDcrWallet.swapCounter != 1 {
-		t.Fatalf("swapped during metered tick")
+		if estimate != test.estFee {
+			t.Fatalf("%s: expected fee %v, got %v", test.name, test.estFee, estimate)
+		}
+		if !test.wantErr && err != nil {
+			t.Fatalf("%s: unexpected error", test.name)
+		}
 	}
+}
 
-	// But once the tickGovernors expire, we should succeed with two separate
-	// requests.
-	tracker.mtx.Lock()
-	swappableMatch1.tickGovernor = nil
-	swappableMatch2.tickGovernor = nil
-	tracker.mtx.Unlock()
-	_, err = tCore.tick(tracker)
-	if err != nil {
-		t.Fatalf("tick error while swapping suspect matches: %v", err)
-	}
-	if tDcrWallet.swapCounter != 3 {
-		t.Fatalf("suspect swap matches not run or not run separately. expected 2 new calls to Swap, got %d", tDcrWallet.swapCounter-1)
-	}
+type TDynamicSwapper struct {
+	*TXCWallet
+	tfpPaid         uint64
+	tfpSecretHashes [][]byte
+	tfpErr          error
+}
 
-	var redeemableMatch1, redeemableMatch2 *matchTracker
-	setRedeems := func() {
-		redeemableMatch1 = newMatch(order.Maker, order.TakerSwapCast)
-		redeemableMatch2 = newMatch(order.Taker, order.MakerRedeemed)
+func (dtfc *TDynamicSwapper) DynamicSwapFeesPaid(ctx context.Context, coinID, contractData dex.Bytes) (uint64, [][]byte, error) {
+	return dtfc.tfpPaid, dtfc.tfpSecretHashes, dtfc.tfpErr
+}
+func (dtfc *TDynamicSwapper) DynamicRedemptionFeesPaid(ctx context.Context, coinID, contractData dex.Bytes) (uint64, [][]byte, error) {
+	return dtfc.tfpPaid, dtfc.tfpSecretHashes, dtfc.tfpErr
+}
 
-		// Set valid wallet auditInfo for redeemableMatch1, maker will repeat audit before redeeming.
-		auditQty := calc.BaseToQuote(redeemableMatch1.Rate, redeemableMatch1.Quantity)
-		_, auditInfo := tMsgAudit(oid, redeemableMatch1.MatchID, addr, auditQty, encode.RandomBytes(32))
-		auditInfo.Expiration = encode.DropMilliseconds(redeemableMatch1.matchTime().Add(tracker.lockTimeTaker))
-		tBtcWallet.setConfs(auditInfo.Coin.ID(), tUTXOAssetB.SwapConf, nil)
-		tBtcWallet.auditInfo = auditInfo
-		redeemableMatch1.counterSwap = auditInfo
-		redeemableMatch1.MetaData.Proof.SecretHash = auditInfo.SecretHash
+var _ asset.DynamicSwapper = (*TDynamicSwapper)(nil)
 
-		tBtcWallet.redeemCounter = 0
-		tracker.matches = map[order.MatchID]*matchTracker{
-			redeemableMatch1.MatchID: redeemableMatch1,
-			redeemableMatch2.MatchID: redeemableMatch2,
+func TestUpdateFeesPaid(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tests := []struct {
+		name                   string
+		paid                   uint64
+		init, swapWallets      bool
+		tfpErr, updateOrderErr error
+	}{{
+		name: "ok init",
+		paid: 1,
+		init: true,
+	}, {
+		name:        "ok redeem",
+		paid:        1,
+		swapWallets: true,
+	}, {
+		name: "not dynamic",
+	}, {
+		name:   "TransactionFeesPaid error other than coin not found",
+		init:   true,
+		tfpErr: errors.New("other error"),
+	}}
+	for _, test := range tests {
+		acctWallet, tWallet := newTWallet(tACCTAsset.ID)
+		dynamicFeeChecker := &TDynamicSwapper{TXCWallet: tWallet}
+		acctWallet.Wallet = dynamicFeeChecker
+		tWallet.confs["00"] = 10
+
+		utxoWallet, _ := newTWallet(tUTXOAssetA.ID)
+
+		wallets := &walletSet{
+			fromWallet:  acctWallet,
+			toWallet:    utxoWallet,
+			baseWallet:  acctWallet,
+			quoteWallet: utxoWallet,
+		}
+		if test.swapWallets {
+			wallets.fromWallet, wallets.toWallet = wallets.toWallet, wallets.fromWallet
+			wallets.baseWallet, wallets.quoteWallet = wallets.quoteWallet, wallets.baseWallet
+		}
+
+		dc := &dexConnection{
+			acct: tNewAccount(&tCrypter{}),
+			log:  tLogger,
+		}
+		lo, _, _, _ := makeLimitOrder(dc, true, 0, 0)
+		tracker := &trackedTrade{
+			wallets:  wallets,
+			dc:       dc,
+			metaData: new(db.OrderMetaData),
+			db:       new(TDB),
+			Order:    lo,
+			notify:   func(Notification) {},
+		}
+		tracker.SetTime(time.Now())
+		dynamicFeeChecker.tfpPaid = 1
+		dynamicFeeChecker.tfpErr = test.tfpErr
+		dynamicFeeChecker.tfpSecretHashes = [][]byte{{0}}
+		matchID := ordertest.RandomMatchID()
+		match := &matchTracker{
+			MetaMatch: db.MetaMatch{
+				UserMatch: &order.UserMatch{MatchID: matchID},
+				MetaData: &db.MatchMetaData{
+					Proof: db.MatchProof{
+						TakerSwap:   []byte{0},
+						MakerSwap:   []byte{0},
+						MakerRedeem: []byte{0},
+						TakerRedeem: []byte{0},
+						SecretHash:  []byte{0},
+					},
+				},
+			},
 		}
-		rig.ws.queueResponse(msgjson.RedeemRoute, redeemAcker)
-		rig.ws.queueResponse(msgjson.RedeemRoute, redeemAcker)
-	}
-	setRedeems()
-
-	// Initial success
-	tBtcWallet.redeemCoins = []dex.Bytes{encode.RandomBytes(36), encode.RandomBytes(36)}
-	_, err = tCore.tick(tracker)
-	if err != nil {
-		t.Fatalf("redeem tick error: %v", err)
-	}
-	if tBtcWallet.redeemCounter != 1 {
-		t.Fatalf("never redeemed")
-	}
-
-	setRedeems()
-	tBtcWallet.redeemErr = tErr
-	_, err = tCore.tick(tracker)
-	if err == nil || !strings.Contains(err.Error(), "error sending redeem transaction") {
-		t.Fatalf("redeem error not propagated. err = %v", err)
-	}
-	if tBtcWallet.redeemCounter != 1 {
-		t.Fatalf("never redeemed")
-	}
-
-	// Both matches should be marked as suspect and have tickGovernors in place.
-	tracker.mtx.Lock()
-	for i, m := range []*matchTracker{redeemableMatch1, redeemableMatch2} {
-		if !m.suspectRedeem {
-			t.Fatalf("redeemable match %d not suspect after failed swap", i+1)
+		tracker.updateDynamicSwapOrRedemptionFeesPaid(ctx, match, test.init)
+		got := tracker.metaData.SwapFeesPaid
+		if !test.init {
+			got = tracker.metaData.RedemptionFeesPaid
 		}
-		if m.tickGovernor == nil {
-			t.Fatalf("redeemable match %d has no tick meterer set", i+1)
+		if got != test.paid {
+			t.Fatalf("%s: want %d but got %d fees paid", test.name, test.paid, got)
 		}
 	}
-	tracker.mtx.Unlock()
-
-	// Ticking right away again should do nothing.
-	tBtcWallet.redeemErr = nil
-	_, err = tCore.tick(tracker)
-	if err != nil {
-		t.Fatalf("tick error during metered redeem tick: %v", err)
-	}
-	if tBtcWallet.redeemCounter != 1 {
-		t.Fatalf("redeemed during metered tick %d", tBtcWallet.redeemCounter)
-	}
-
-	// But once the tickGovernors expire, we should succeed with two separate
-	// requests.
-	tracker.mtx.Lock()
-	redeemableMatch1.tickGovernor = nil
-	redeemableMatch2.tickGovernor = nil
-	tracker.mtx.Unlock()
-	_, err = tCore.tick(tracker)
-	if err != nil {
-		t.Fatalf("tick error while redeeming suspect matches: %v", err)
-	}
-	if tBtcWallet.redeemCounter != 3 {
-		t.Fatalf("suspect redeem matches not run or not run separately. expected 2 new calls to Redeem, got %d", tBtcWallet.redeemCounter-1)
-	}
 }
 
-func TestWalletSyncing(t *testing.T) {
+func TestUpdateBondOptions(t *testing.T) {
+	const feeRate = 50
+
 	rig := newTestRig()
 	defer rig.shutdown()
-	tCore := rig.core
+	acct := rig.dc.acct
+	acct.isAuthed = true
 
-	noteFeed := tCore.NotificationFeed()
 	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	dcrWallet.syncStatus.Synced = false
-	dcrWallet.syncStatus.Blocks = 0
-	dcrWallet.hookedUp = false
-	// Connect with tCore.connectWallet below.
+	dcrWallet.Wallet = &TFeeRater{tDcrWallet, feeRate}
+	rig.core.wallets[tUTXOAssetA.ID] = dcrWallet
+	bondFeeBuffer := tDcrWallet.BondsFeeBuffer(feeRate)
 
-	tStart := time.Now()
-	testDuration := 100 * time.Millisecond
-	syncTickerPeriod = 10 * time.Millisecond
+	bondAsset := dcrBondAsset
+	var wrongBondAssetID uint32 = 0
+	var targetTier uint64 = 1
+	var targetTierZero uint64 = 0
+	defaultMaxBondedAmt := maxBondedMult * bondAsset.Amt * targetTier
+	tooLowMaxBonded := defaultMaxBondedAmt - 1
+	// Double because we will reserve for the bond that's about to be posted
+	// in rotateBonds too.
+	singlyBondedReserves := bondAsset.Amt*targetTier*2 + bondFeeBuffer
 
-	tDcrWallet.syncStatus = func() (bool, float32, error) {
-		progress := float32(float64(time.Since(tStart)) / float64(testDuration))
-		if progress >= 1 {
-			return true, 1, nil
-		}
-		return false, progress, nil
+	type acctState struct {
+		targetTier   uint64
+		maxBondedAmt uint64
 	}
 
-	_, err := tCore.connectWallet(dcrWallet)
-	if err != nil {
-		t.Fatalf("connectWallet error: %v", err)
-	}
+	for _, tt := range []struct {
+		name        string
+		bal         uint64
+		form        BondOptionsForm
+		before      acctState
+		after       acctState
+		expReserves uint64
+		addOtherDC  bool
+		wantErr     bool
+	}{
+		{
+			name: "set target tier to 1",
+			bal:  singlyBondedReserves,
+			form: BondOptionsForm{
+				Host:        acct.host,
+				TargetTier:  &targetTier,
+				BondAssetID: &bondAsset.ID,
+			},
+			after: acctState{
+				targetTier:   1,
+				maxBondedAmt: defaultMaxBondedAmt,
+			},
+			expReserves: singlyBondedReserves,
+		},
+		{
+			name: "low balance",
+			bal:  singlyBondedReserves - 1,
+			form: BondOptionsForm{
+				Host:        acct.host,
+				TargetTier:  &targetTier,
+				BondAssetID: &bondAsset.ID,
+			},
+			wantErr: true,
+		},
+		{
+			name: "max-bonded too low",
+			bal:  singlyBondedReserves,
+			form: BondOptionsForm{
+				Host:         acct.host,
+				TargetTier:   &targetTier,
+				BondAssetID:  &bondAsset.ID,
+				MaxBondedAmt: &tooLowMaxBonded,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported bond asset",
+			form: BondOptionsForm{
+				Host:        acct.host,
+				TargetTier:  &targetTier,
+				BondAssetID: &wrongBondAssetID,
+			},
+			wantErr: true,
+		},
+		{
+			name: "lower target tier with zero balance OK",
+			bal:  0,
+			form: BondOptionsForm{
+				Host:        acct.host,
+				TargetTier:  &targetTierZero,
+				BondAssetID: &bondAsset.ID,
+			},
+			before: acctState{
+				targetTier:   1,
+				maxBondedAmt: defaultMaxBondedAmt,
+			},
+			after:       acctState{},
+			expReserves: 0,
+		},
+		{
+			name: "lower target tier to zero with other exchanges still keeps reserves",
+			bal:  0,
+			form: BondOptionsForm{
+				Host:        acct.host,
+				TargetTier:  &targetTierZero,
+				BondAssetID: &bondAsset.ID,
+			},
+			before: acctState{
+				targetTier:   1,
+				maxBondedAmt: defaultMaxBondedAmt,
+			},
+			addOtherDC:  true,
+			after:       acctState{},
+			expReserves: bondFeeBuffer,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after := tt.before, tt.after
+			acct.targetTier = before.targetTier
+			acct.maxBondedAmt = before.maxBondedAmt
+			tDcrWallet.bal = &asset.Balance{Available: tt.bal}
 
-	timeout := time.NewTimer(time.Second)
-	defer timeout.Stop()
-	var progressNotes int
-out:
-	for {
-		select {
-		case note := <-noteFeed.C:
-			syncNote, ok := note.(*WalletSyncNote)
-			if !ok {
-				continue
+			if tt.addOtherDC {
+				dc, _, acct := testDexConnection(rig.core.ctx, rig.crypter.(*tCrypter))
+				acct.host = "someotherhost.com"
+				rig.core.conns[acct.host] = dc
+				defer delete(rig.core.conns, acct.host)
+				acct.bondAsset = bondAsset.ID
+				acct.targetTier = 1
 			}
-			progressNotes++
-			if syncNote.SyncStatus.Synced {
-				break out
+
+			if err := rig.core.UpdateBondOptions(&tt.form); err != nil {
+				if tt.wantErr {
+					return
+				}
+				t.Fatalf("UpdateBondOptions error: %v", err)
+			}
+			if tt.wantErr {
+				t.Fatalf("No error when one was expected")
 			}
-		case <-timeout.C:
-			t.Fatalf("timed out waiting for synced wallet note. Received %d progress notes", progressNotes)
-		}
-	}
-	// By the time we've got 10th note it should signal that the wallet has been
-	// synced (due to how we've set up testDuration and syncTickerPeriod values).
-	if progressNotes > 10 {
-		t.Fatalf("expected 10 progress notes at most, got %d", progressNotes)
-	}
-}
 
-func TestParseCert(t *testing.T) {
-	byteCert := []byte{0x0a, 0x0b}
-	cert, err := parseCert("anyhost", []byte{0x0a, 0x0b}, dex.Mainnet)
-	if err != nil {
-		t.Fatalf("byte cert error: %v", err)
-	}
-	if !bytes.Equal(cert, byteCert) {
-		t.Fatalf("byte cert note returned unmodified. expected %x, got %x", byteCert, cert)
-	}
-	byteCert = []byte{0x05, 0x06}
-	certFile, _ := os.CreateTemp("", "dumbcert")
-	defer os.Remove(certFile.Name())
-	certFile.Write(byteCert)
-	certFile.Close()
-	cert, err = parseCert("anyhost", certFile.Name(), dex.Mainnet)
-	if err != nil {
-		t.Fatalf("file cert error: %v", err)
-	}
-	if !bytes.Equal(cert, byteCert) {
-		t.Fatalf("byte cert note returned unmodified. expected %x, got %x", byteCert, cert)
-	}
-	_, err = parseCert("bison.exchange:17232", []byte(nil), dex.Testnet)
-	if err != nil {
-		t.Fatalf("CertStore cert error: %v", err)
+			if acct.targetTier != after.targetTier {
+				t.Fatalf("Wrong targetTier. %d != %d", acct.targetTier, after.targetTier)
+			}
+			if acct.maxBondedAmt != after.maxBondedAmt {
+				t.Fatalf("Wrong maxBondedAmt. %d != %d", acct.maxBondedAmt, after.maxBondedAmt)
+			}
+			if tDcrWallet.reserves.Load() != tt.expReserves {
+				t.Fatalf("Wrong reserves. %d != %d", tDcrWallet.reserves.Load(), tt.expReserves)
+			}
+		})
 	}
 }
 
-func TestPreOrder(t *testing.T) {
+func TestRotateBonds(t *testing.T) {
+	const feeRate = 50
+
 	rig := newTestRig()
 	defer rig.shutdown()
-	tCore := rig.core
-	dc := rig.dc
+	rig.core.Login(tPW)
+
+	acct := rig.dc.acct
+	acct.isAuthed = true
 
-	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
-	tCore.wallets[tUTXOAssetB.ID] = btcWallet
 	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.Wallet = &TFeeRater{tDcrWallet, feeRate}
+	rig.core.wallets[tUTXOAssetA.ID] = dcrWallet
+	bondAsset := dcrBondAsset
+	bondFeeBuffer := tDcrWallet.BondsFeeBuffer(feeRate)
+	maxBondedPerTier := maxBondedMult * bondAsset.Amt
 
-	var rate uint64 = 1e8
-	quoteConvertedLotSize := calc.BaseToQuote(rate, dcrBtcLotSize)
+	now := uint64(time.Now().Unix())
+	bondExpiry := rig.dc.config().BondExpiry
+	// bondDuration := minBondLifetime(rig.core.net, bondExpiry)
+	locktimeThresh := now + bondExpiry
+	pBuffer := uint64(pendingBuffer(rig.core.net))
+	mergeableLocktimeThresh := locktimeThresh + bondExpiry/4 + pBuffer
+	// unexpired := locktimeThresh + 1
+	locktimeExpired := locktimeThresh - 1
+	locktimeRefundable := now - 1
+	weakTimeThresh := locktimeThresh + pBuffer
 
-	book := newBookie(rig.dc, tUTXOAssetA.ID, tUTXOAssetB.ID, nil, tLogger)
-	dc.books[tDcrBtcMktName] = book
+	run := func(wantPending, wantExpired int, expectedReserves uint64) {
+		ctx, cancel := context.WithTimeout(rig.core.ctx, time.Second)
+		rig.core.rotateBonds(ctx)
+		cancel()
 
-	sellNote := &msgjson.BookOrderNote{
-		OrderNote: msgjson.OrderNote{
-			OrderID: encode.RandomBytes(32),
-		},
-		TradeNote: msgjson.TradeNote{
-			Side:     msgjson.SellOrderNum,
-			Quantity: quoteConvertedLotSize * 10,
-			Time:     uint64(time.Now().Unix()),
-			Rate:     rate,
-		},
+		t.Helper()
+		if len(acct.pendingBonds) != wantPending {
+			t.Fatalf("wanted %d pending bonds, got %d", wantPending, len(acct.pendingBonds))
+		}
+		if len(acct.expiredBonds) != wantExpired {
+			t.Fatalf("wanted %d expired bonds, got %d", wantExpired, len(acct.expiredBonds))
+		}
+		if tDcrWallet.reserves.Load() != expectedReserves {
+			t.Fatalf("wrong reserves. expected %d, got %d", expectedReserves, tDcrWallet.reserves.Load())
+		}
 	}
 
-	buyNote := *sellNote
-	buyNote.TradeNote.Quantity = dcrBtcLotSize * 10
-	buyNote.TradeNote.Side = msgjson.BuyOrderNum
+	// No bonds, target tier 1. Should create a new bond and add it to pending.
+	var targetTier uint64 = 1
+	acct.targetTier = targetTier
+	acct.maxBondedAmt = maxBondedPerTier * targetTier
+	acct.bondAsset = bondAsset.ID
+	tDcrWallet.bal = &asset.Balance{Available: bondAsset.Amt*targetTier + bondFeeBuffer}
+	rig.queuePrevalidateBond()
+	run(1, 0, bondAsset.Amt+bondFeeBuffer)
 
-	var baseFeeRate uint64 = 5
-	var quoteFeeRate uint64 = 10
+	// Post and then expire the bond. This first bond should move to expired and we
+	// should create another bond.
+	acct.bonds, acct.pendingBonds = acct.pendingBonds, nil
+	acct.bonds[0].LockTime = locktimeExpired
+	rig.queuePrevalidateBond()
+	// The newly expired bond will be refunded in time to fund our next round,
+	// so we only need fees reserved.
+	run(1, 1, bondFeeBuffer)
 
-	err := book.Sync(&msgjson.OrderBook{
-		MarketID:     tDcrBtcMktName,
-		Seq:          1,
-		Epoch:        1,
-		Orders:       []*msgjson.BookOrderNote{sellNote, &buyNote},
-		BaseFeeRate:  baseFeeRate,
-		QuoteFeeRate: quoteFeeRate,
-	})
-	if err != nil {
-		t.Fatalf("Sync error: %v", err)
+	// If the live bond is closer to expiration, the expired bond won't be
+	// ready in time, so we'll need more reserves.
+	acct.bonds, acct.pendingBonds = acct.pendingBonds, nil
+	acct.bonds[0].LockTime = weakTimeThresh + 1
+	run(0, 1, bondAsset.Amt+bondFeeBuffer)
+
+	// Make the live bond weak. Should get a pending bond. Only fees reserves,
+	// because we still have an expired bond.
+	acct.bonds[0].LockTime = weakTimeThresh - 1
+	rig.queuePrevalidateBond()
+	run(1, 1, bondFeeBuffer)
+
+	// Refund the expired bond
+	acct.expiredBonds[0].LockTime = locktimeRefundable
+	tDcrWallet.contractExpired = true
+	tDcrWallet.refundBondCoin = &tCoin{}
+	run(1, 0, bondAsset.Amt+bondFeeBuffer)
+
+	acct.targetTier = 2
+	acct.bonds = nil
+	rig.queuePrevalidateBond()
+	run(2, 0, bondAsset.Amt*2+bondFeeBuffer)
+
+	// Check that a new bond will be scheduled for merge with an existing bond
+	// if the locktime is not too soon.
+	acct.bonds = append(acct.bonds, acct.pendingBonds[0])
+	acct.pendingBonds = nil
+	acct.bonds[0].LockTime = mergeableLocktimeThresh + 5
+	rig.queuePrevalidateBond()
+	run(1, 0, 2*bondAsset.Amt+bondFeeBuffer)
+	mergingBond := acct.pendingBonds[0]
+	if mergingBond.LockTime != acct.bonds[0].LockTime {
+		t.Fatalf("Mergeable bond was not merged")
 	}
 
-	preSwap := &asset.PreSwap{
-		Estimate: &asset.SwapEstimate{
-			MaxFees:            1001,
-			Lots:               5,
-			RealisticBestCase:  15,
-			RealisticWorstCase: 20,
-		},
+	// Same thing, but without the merge, just to check our threshold calc.
+	acct.pendingBonds = nil
+	acct.bonds[0].LockTime = mergeableLocktimeThresh - 1
+	rig.queuePrevalidateBond()
+	run(1, 0, 2*bondAsset.Amt+bondFeeBuffer)
+	unmergingBond := acct.pendingBonds[0]
+	if unmergingBond.LockTime == acct.bonds[0].LockTime {
+		t.Fatalf("Unmergeable bond was scheduled for merged")
 	}
+}
 
-	tBtcWallet.preSwap = preSwap
+func TestReplaceBond(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	rig.core.Login(tPW)
 
-	preRedeem := &asset.PreRedeem{
-		Estimate: &asset.RedeemEstimate{
-			RealisticBestCase:  15,
-			RealisticWorstCase: 20,
-		},
-	}
+	acct := rig.dc.acct
+	acct.isAuthed = true
 
-	tDcrWallet.preRedeem = preRedeem
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	rig.core.wallets[tUTXOAssetA.ID] = dcrWallet
+	dcrWallet.Unlock(rig.crypter)
+	bondAsset := dcrBondAsset
 
-	form := &TradeForm{
-		Host: tDexHost,
-		Sell: false,
-		// IsLimit: true,
-		Base:  tUTXOAssetA.ID,
-		Quote: tUTXOAssetB.ID,
-		Qty:   quoteConvertedLotSize * 5,
-		Rate:  rate,
-	}
-	preOrder, err := tCore.PreOrder(form)
-	if err != nil {
-		t.Fatalf("PreOrder market buy error: %v", err)
-	}
+	bondCfg := rig.core.dexBondConfig(rig.dc, time.Now().Unix())
+	oldBond := &db.Bond{
+		AssetID:   bondAsset.ID,
+		CoinID:    encode.RandomBytes(36),
+		Amount:    bondAsset.Amt,
+		Strength:  1,
+		LockTime:  uint64(bondCfg.replaceThresh) - 1, // weak
+		Confirmed: true,
+	}
+	oldBondID := coinIDString(oldBond.AssetID, oldBond.CoinID)
+	acct.bonds = []*db.Bond{oldBond}
+	acct.targetTier = 1
+	acct.bondAsset = bondAsset.ID
 
-	compUint64 := func(tag string, a, b uint64) {
+	checkState := func(tag string, liveStrength, weakStrength int64) {
 		t.Helper()
-		if a != b {
-			t.Fatalf("%s: %d != %d", tag, a, b)
+		state := rig.core.bondStateOfDEX(rig.dc, bondCfg)
+		if state.LiveStrength != liveStrength || state.WeakStrength != weakStrength {
+			t.Fatalf("%s: wanted live strength %d and weak strength %d, got %d and %d",
+				tag, liveStrength, weakStrength, state.LiveStrength, state.WeakStrength)
 		}
 	}
 
-	est1, est2 := preSwap.Estimate, preOrder.Swap.Estimate
-	compUint64("MaxFees", est1.MaxFees, est2.MaxFees)
-	compUint64("RealisticWorstCase", est1.RealisticWorstCase, est2.RealisticWorstCase)
-	compUint64("RealisticBestCase", est1.RealisticBestCase, est2.RealisticBestCase)
-	// This is a buy order, so the from asset is the quote asset.
-	compUint64("PreOrder.FeeSuggestion.quote", quoteFeeRate, tBtcWallet.preSwapForm.FeeSuggestion)
-	compUint64("PreOrder.FeeSuggestion.base", baseFeeRate, tDcrWallet.preRedeemForm.FeeSuggestion)
-
-	// Missing book is an error
-	delete(dc.books, tDcrBtcMktName)
-	_, err = tCore.PreOrder(form)
-	if err == nil {
-		t.Fatalf("no error for market order with missing book")
+	// Unknown bond.
+	if _, err := rig.core.ReplaceBond(tPW, tDexHost, "abc", bondAsset.Amt); err == nil {
+		t.Fatalf("no error for unknown bond")
 	}
-	dc.books[tDcrBtcMktName] = book
 
-	// Exercise the market sell path too.
-	form.Sell = true
-	_, err = tCore.PreOrder(form)
-	if err != nil {
-		t.Fatalf("PreOrder market sell error: %v", err)
+	// A failed new bond leaves the old bond in use.
+	tDcrWallet.makeBondTxErr = tErr
+	if _, err := rig.core.ReplaceBond(tPW, tDexHost, oldBondID, bondAsset.Amt); err == nil {
+		t.Fatalf("no error for failed new bond")
 	}
-
-	// Market orders have to have a market to make estimates.
-	book.Unbook(&msgjson.UnbookOrderNote{
-		MarketID: tDcrBtcMktName,
-		OrderID:  sellNote.OrderID,
-	})
-	book.Unbook(&msgjson.UnbookOrderNote{
-		MarketID: tDcrBtcMktName,
-		OrderID:  buyNote.OrderID,
-	})
-	_, err = tCore.PreOrder(form)
-	if err == nil {
-		t.Fatalf("no error for market order with empty market")
+	tDcrWallet.makeBondTxErr = nil
+	if len(acct.pendingBonds) != 0 || len(acct.bondReplacements) != 0 {
+		t.Fatalf("failed new bond recorded")
 	}
+	checkState("failed", 1, 1)
 
-	// Limit orders have no such restriction.
-	form.IsLimit = true
-	_, err = tCore.PreOrder(form)
+	// The old bond still counts while the new bond is pending.
+	tDcrWallet.bondTxCoinID = encode.RandomBytes(36)
+	rig.queuePrevalidateBond()
+	res, err := rig.core.ReplaceBond(tPW, tDexHost, oldBondID, bondAsset.Amt)
 	if err != nil {
-		t.Fatalf("PreOrder limit sell error: %v", err)
+		t.Fatalf("ReplaceBond error: %v", err)
 	}
-
-	var newBaseFeeRate uint64 = 55
-	var newQuoteFeeRate uint64 = 65
-	feeRateSource := func(msg *msgjson.Message, f msgFunc) error {
-		var resp *msgjson.Message
-		if string(msg.Payload) == "42" {
-			resp, _ = msgjson.NewResponse(msg.ID, newBaseFeeRate, nil)
-		} else {
-			resp, _ = msgjson.NewResponse(msg.ID, newQuoteFeeRate, nil)
-		}
-		f(resp)
-		return nil
+	if len(acct.pendingBonds) != 1 {
+		t.Fatalf("new bond not pending")
 	}
-
-	// Removing the book should cause us to
-	delete(dc.books, tDcrBtcMktName)
-	rig.ws.queueResponse(msgjson.FeeRateRoute, feeRateSource)
-	rig.ws.queueResponse(msgjson.FeeRateRoute, feeRateSource)
-
-	_, err = tCore.PreOrder(form)
-	if err != nil {
-		t.Fatalf("PreOrder limit sell error #2: %v", err)
+	newBond := acct.pendingBonds[0]
+	if newBond.LockTime <= oldBond.LockTime {
+		t.Fatalf("replacement bond does not outlast the old bond")
 	}
-	// sell order now, so from asset is base asset
-	compUint64("PreOrder.FeeSuggestion quote asset from server", newQuoteFeeRate, tBtcWallet.preRedeemForm.FeeSuggestion)
-	compUint64("PreOrder.FeeSuggestion base asset from server", newBaseFeeRate, tDcrWallet.preSwapForm.FeeSuggestion)
-	dc.books[tDcrBtcMktName] = book
+	checkState("pending", 1, 1)
 
-	// no DEX
-	delete(tCore.conns, dc.acct.host)
-	_, err = tCore.PreOrder(form)
-	if err == nil {
-		t.Fatalf("no error for unknown DEX")
+	// A pending replacement can be superseded.
+	tDcrWallet.bondTxCoinID = encode.RandomBytes(36)
+	rig.queuePrevalidateBond()
+	if _, err := rig.core.ReplaceBond(tPW, tDexHost, oldBondID, bondAsset.Amt); err != nil {
+		t.Fatalf("ReplaceBond error for superseding replacement: %v", err)
 	}
-	tCore.conns[dc.acct.host] = dc
+	if len(acct.pendingBonds) != 2 || len(acct.bondReplacements) != 2 {
+		t.Fatalf("superseding replacement not recorded")
+	}
+	checkState("superseded", 1, 1)
 
-	// no wallet
-	delete(tCore.wallets, tUTXOAssetA.ID)
-	_, err = tCore.PreOrder(form)
-	if err == nil {
-		t.Fatalf("no error for missing wallet")
+	// Once the new bond is confirmed, the old bond is relinquished, and the
+	// other replacement is no longer a replacement.
+	pbr := &msgjson.PostBondResult{Reputation: &account.Reputation{BondedTier: 2}}
+	if err := rig.core.bondConfirmed(rig.dc, newBond.AssetID, newBond.CoinID, pbr); err != nil {
+		t.Fatalf("bondConfirmed error: %v", err)
+	}
+	if res.BondID != coinIDString(newBond.AssetID, newBond.CoinID) {
+		t.Fatalf("wrong bond ID %s", res.BondID)
+	}
+	if !acct.relinquishedBonds[bondKey(oldBond.AssetID, oldBond.CoinID)] || len(acct.bondReplacements) != 0 {
+		t.Fatalf("old bond not relinquished")
+	}
+	checkState("confirmed", 1, 0)
+	// The old bond is not replaced by rotation.
+	if state := rig.core.bondStateOfDEX(rig.dc, bondCfg); state.mustPost != 0 {
+		t.Fatalf("rotation would post %d bonds", state.mustPost)
+	}
+	// Can't replace it again.
+	if _, err := rig.core.ReplaceBond(tPW, tDexHost, oldBondID, bondAsset.Amt); err == nil {
+		t.Fatalf("no error for replacing a relinquished bond")
 	}
-	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
 
-	// base wallet not connected
-	dcrWallet.hookedUp = false
-	tDcrWallet.connectErr = tErr
-	_, err = tCore.PreOrder(form)
-	if err == nil {
-		t.Fatalf("no error for unconnected base wallet")
+	// The replacement is stored with the new bond, and the replacement state
+	// is restored when the bonds are loaded.
+	if !bytes.Equal(newBond.Replaces, oldBond.CoinID) {
+		t.Fatalf("replaced bond not recorded with the new bond")
 	}
-	dcrWallet.hookedUp = true
-	tDcrWallet.connectErr = nil
+	oldKey, newKey := bondKey(oldBond.AssetID, oldBond.CoinID), bondKey(newBond.AssetID, newBond.CoinID)
+	acct.authMtx.Lock()
+	acct.bondReplacements, acct.relinquishedBonds = nil, nil
+	acct.restoreBondReplacements()
+	acct.authMtx.Unlock()
+	if !acct.relinquishedBonds[oldKey] || len(acct.bondReplacements) != 0 {
+		t.Fatalf("old bond not relinquished after reload")
+	}
+	checkState("reloaded", 1, 0)
 
-	// quote wallet not connected
-	btcWallet.hookedUp = false
-	tBtcWallet.connectErr = tErr
-	_, err = tCore.PreOrder(form)
-	if err == nil {
-		t.Fatalf("no error for unconnected quote wallet")
+	// A pending replacement is restored too.
+	acct.authMtx.Lock()
+	acct.bonds, acct.pendingBonds = []*db.Bond{oldBond}, []*db.Bond{newBond}
+	acct.restoreBondReplacements()
+	acct.authMtx.Unlock()
+	if acct.bondReplacements[newKey] != oldKey || len(acct.relinquishedBonds) != 0 {
+		t.Fatalf("pending replacement not restored")
 	}
-	btcWallet.hookedUp = true
-	tBtcWallet.connectErr = nil
 
-	// success again
-	_, err = tCore.PreOrder(form)
-	if err != nil {
-		t.Fatalf("PreOrder error after fixing everything: %v", err)
+	// A replacement that expires before it is confirmed is forgotten.
+	acct.authMtx.Lock()
+	newBond.LockTime = uint64(bondCfg.lockTimeThresh)
+	acct.authMtx.Unlock()
+	rig.core.bondStateOfDEX(rig.dc, bondCfg)
+	if len(acct.bondReplacements) != 0 {
+		t.Fatalf("expired replacement not forgotten")
 	}
 }
 
-func TestRefreshServerConfig(t *testing.T) {
+func TestFindBondKeyIdx(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
+	rig.core.Login(tPW)
 
-	// Add an API version to supportedAPIVers to use in tests.
-	const newAPIVer = ^uint16(0) - 1
-	supportedAPIVers = append(supportedAPIVers, int32(newAPIVer))
-
-	queueConfig := func(err *msgjson.Error, apiVer uint16) {
-		rig.ws.queueResponse(msgjson.ConfigRoute, func(msg *msgjson.Message, f msgFunc) error {
-			cfg := *rig.dc.cfg
-			cfg.APIVersion = apiVer
-			resp, _ := msgjson.NewResponse(msg.ID, cfg, err)
-			f(resp)
-			return nil
-		})
+	pkhEqualFnFn := func(find bool) func(bondKey *secp256k1.PrivateKey) bool {
+		return func(bondKey *secp256k1.PrivateKey) bool {
+			return find
+		}
 	}
 	tests := []struct {
 		name       string
-		configErr  *msgjson.Error
-		gotAPIVer  uint16
-		marketBase uint32
+		pkhEqualFn func(bondKey *secp256k1.PrivateKey) bool
 		wantErr    bool
 	}{{
 		name:       "ok",
-		marketBase: tUTXOAssetA.ID,
-		gotAPIVer:  newAPIVer,
-	}, {
-		name:      "unable to fetch config",
-		configErr: new(msgjson.Error),
-		wantErr:   true,
+		pkhEqualFn: pkhEqualFnFn(true),
 	}, {
-		name:       "api not in wanted versions",
-		gotAPIVer:  ^uint16(0),
-		marketBase: tUTXOAssetA.ID,
+		name:       "cant find",
+		pkhEqualFn: pkhEqualFnFn(false),
 		wantErr:    true,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := rig.core.findBondKeyIdx(test.pkhEqualFn, 0)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestFindBond(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	rig.core.wallets[tUTXOAssetA.ID] = dcrWallet
+	rig.core.Login(tPW)
+
+	bd := &asset.BondDetails{
+		Bond: &asset.Bond{
+			Amount:  tFee,
+			AssetID: tUTXOAssetA.ID,
+		},
+		LockTime: time.Now(),
+		CheckPrivKey: func(bondKey *secp256k1.PrivateKey) bool {
+			return true
+		},
+	}
+	msgBond := &msgjson.Bond{
+		Version: 0,
+		AssetID: tUTXOAssetA.ID,
+	}
+
+	tests := []struct {
+		name        string
+		findBond    *asset.BondDetails
+		findBondErr error
+		wantStr     uint32
+	}{{
+		name:     "ok",
+		findBond: bd,
+		wantStr:  1,
 	}, {
-		name:       "generate maps failure",
-		marketBase: ^uint32(0),
-		gotAPIVer:  newAPIVer,
-		wantErr:    true,
+		name:        "find bond error",
+		findBondErr: errors.New("some error"),
 	}}
 
 	for _, test := range tests {
-		rig.dc.cfg.Markets[0].Base = test.marketBase
-		queueConfig(test.configErr, test.gotAPIVer)
-		_, err := rig.dc.refreshServerConfig()
-		if test.wantErr {
-			if err == nil {
-				t.Fatalf("expected error for test %q", test.name)
+		t.Run(test.name, func(t *testing.T) {
+			tDcrWallet.findBond = test.findBond
+			tDcrWallet.findBondErr = test.findBondErr
+			str, _ := rig.core.findBond(rig.dc, msgBond)
+			if str != test.wantStr {
+				t.Fatalf("wanted str %d but got %d", test.wantStr, str)
 			}
-			continue
-		}
-		if err != nil {
-			t.Fatalf("unexpected error for test %q: %v", test.name, err)
-		}
+		})
 	}
 }
 
-func TestCredentialHandling(t *testing.T) {
+func TestNetworkFeeRate(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	tCore := rig.core
 
-	clearCreds := func() {
-		tCore.credentials = nil
-		rig.db.creds = nil
+	assetID := tUTXOAssetA.ID
+	wallet, tWallet := newTWallet(assetID)
+	rig.core.wallets[assetID] = wallet
+
+	const feeRaterRate = 50
+	dumbWallet := wallet.Wallet
+	wallet.Wallet = &TFeeRater{
+		TXCWallet: tWallet,
+		feeRate:   feeRaterRate,
 	}
+	if r := rig.core.NetworkFeeRate(assetID); r != feeRaterRate {
+		t.Fatalf("FeeRater not working. %d != %d", r, feeRaterRate)
+	}
+	wallet.Wallet = dumbWallet
 
-	clearCreds()
-	tCore.newCrypter = encrypt.NewCrypter
-	tCore.reCrypter = encrypt.Deserialize
+	const bookFeedFeeRate = 60
+	book := newBookie(rig.dc, assetID, tUTXOAssetB.ID, nil, tLogger)
+	rig.dc.books[tDcrBtcMktName] = book
+	book.logEpochReport(&msgjson.EpochReportNote{BaseFeeRate: bookFeedFeeRate})
+	if r := rig.core.NetworkFeeRate(assetID); r != bookFeedFeeRate {
+		t.Fatalf("Book feed fee rate not working. %d != %d", r, bookFeedFeeRate)
+	}
+	delete(rig.dc.books, tDcrBtcMktName)
 
-	_, err := tCore.InitializeClient(tPW, nil)
-	if err != nil {
-		t.Fatalf("InitializeClient error: %v", err)
+	const serverFeeRate = 70
+	rig.ws.queueResponse(msgjson.FeeRateRoute, func(msg *msgjson.Message, f msgFunc) error {
+		resp, _ := msgjson.NewResponse(msg.ID, serverFeeRate, nil)
+		f(resp)
+		return nil
+	})
+	if r := rig.core.NetworkFeeRate(assetID); r != serverFeeRate {
+		t.Fatalf("Server fee rate not working. %d != %d", r, serverFeeRate)
+	}
+}
+
+func TestPokesCacheInit(t *testing.T) {
+	tPokes := []*db.Notification{
+		{DetailText: "poke 1"},
+		{DetailText: "poke 2"},
+		{DetailText: "poke 3"},
+		{DetailText: "poke 4"},
+		{DetailText: "poke 5"},
+	}
+	{
+		pokesCapacity := 6
+		c := newPokesCache(pokesCapacity)
+		c.init(tPokes)
+
+		// Check if the cache is initialized correctly
+		if len(c.cache) != 5 {
+			t.Errorf("Expected cache length %d, got %d", len(tPokes), len(c.cache))
+		}
+
+		if c.cursor != 5 {
+			t.Errorf("Expected cursor %d, got %d", len(tPokes)%pokesCapacity, c.cursor)
+		}
+
+		// Check if the cache contains the correct pokes
+		for i, poke := range tPokes {
+			if c.cache[i] != poke {
+				t.Errorf("Expected poke %v at index %d, got %v", poke, i, c.cache[i])
+			}
+		}
+	}
+	{
+		pokesCapacity := 4
+		c := newPokesCache(pokesCapacity)
+		c.init(tPokes)
+
+		// Check if the cache is initialized correctly
+		if len(c.cache) != 1 {
+			t.Errorf("Expected cache length %d, got %d", 1, len(c.cache))
+		}
+
+		if c.cursor != 1 {
+			t.Errorf("Expected cursor %d, got %d", 1, c.cursor)
+		}
+
+		// Check if the cache contains the correct pokes
+		for i, poke := range tPokes[:len(tPokes)-pokesCapacity] {
+			if c.cache[i] != poke {
+				t.Errorf("Expected poke %v at index %d, got %v", poke, i, c.cache[i])
+			}
+		}
+	}
+}
+
+func TestPokesAdd(t *testing.T) {
+	tPokes := []*db.Notification{
+		{DetailText: "poke 1"},
+		{DetailText: "poke 2"},
+		{DetailText: "poke 3"},
+		{DetailText: "poke 4"},
+		{DetailText: "poke 5"},
+	}
+	tNewPoke := &db.Notification{
+		DetailText: "poke 6",
+	}
+	{
+		pokesCapacity := 6
+		c := newPokesCache(pokesCapacity)
+		c.init(tPokes)
+		c.add(tNewPoke)
+
+		// Check if the cache is updated correctly
+		if len(c.cache) != 6 {
+			t.Errorf("Expected cache length %d, got %d", len(tPokes), len(c.cache))
+		}
+
+		if c.cursor != 0 {
+			t.Errorf("Expected cursor %d, got %d", 0, c.cursor)
+		}
+
+		// Check if the cache contains the correct pokes
+		tAllPokes := append(tPokes, tNewPoke)
+		for i, poke := range tAllPokes {
+			if c.cache[i] != poke {
+				t.Errorf("Expected poke %v at index %d, got %v", poke, i, c.cache[i])
+			}
+		}
 	}
+	{
+		pokesCapacity := 5
+		c := newPokesCache(pokesCapacity)
+		c.init(tPokes)
+		c.add(tNewPoke)
 
-	// Since the actual encrypt package crypter is now used instead of the dummy
-	// tCrypter, the acct.encKey should be updated to reflect acct.privKey.
-	// Although the test does not rely on this, we should keep the dexAccount
-	// self-consistent and avoid confusing messages in the test log.
-	err = rig.resetAcctEncKey(tPW)
-	if err != nil {
-		t.Fatalf("InitializeClient error: %v", err)
-	}
+		// Check if the cache is updated correctly
+		if len(c.cache) != pokesCapacity {
+			t.Errorf("Expected cache length %d, got %d", pokesCapacity, len(c.cache))
+		}
 
-	tCore.Logout()
+		if c.cursor != 1 {
+			t.Errorf("Expected cursor %d, got %d", 1, c.cursor)
+		}
 
-	err = tCore.Login(tPW)
-	if err != nil {
-		t.Fatalf("Login error: %v", err)
+		// Check if the cache contains the correct pokes
+		tAllPokes := make([]*db.Notification, 0)
+		tAllPokes = append(tAllPokes, tNewPoke)
+		tAllPokes = append(tAllPokes, tPokes[1:]...)
+		for i, poke := range tAllPokes {
+			if c.cache[i] != poke {
+				t.Errorf("Expected poke %v at index %d, got %v", poke, i, c.cache[i])
+			}
+		}
 	}
-	// NOTE: a warning note is expected. "Wallet connection warning - Incomplete
-	// registration detected for somedex.tld:7232, but failed to connect to the
-	// Decred wallet"
 }
 
-func TestCoreAssetSeedAndPass(t *testing.T) {
-	// This test ensures the derived wallet seed and password are deterministic
-	// and depend on both asset ID and app seed.
+func TestPokesCachePokes(t *testing.T) {
+	tPokes := []*db.Notification{
+		{TimeStamp: 1, DetailText: "poke 1"},
+		{TimeStamp: 2, DetailText: "poke 2"},
+		{TimeStamp: 3, DetailText: "poke 3"},
+		{TimeStamp: 4, DetailText: "poke 4"},
+		{TimeStamp: 5, DetailText: "poke 5"},
+	}
+	{
+		pokesCapacity := 6
+		c := newPokesCache(pokesCapacity)
+		c.init(tPokes)
+		pokes := c.pokes()
 
-	// NOTE: the blake256 hash of an empty slice is:
-	// []byte{0x71, 0x6f, 0x6e, 0x86, 0x3f, 0x74, 0x4b, 0x9a, 0xc2, 0x2c, 0x97, 0xec, 0x7b, 0x76, 0xea, 0x5f,
-	//        0x59, 0x8, 0xbc, 0x5b, 0x2f, 0x67, 0xc6, 0x15, 0x10, 0xbf, 0xc4, 0x75, 0x13, 0x84, 0xea, 0x7a}
-	// The above was very briefly the password for all seeded wallets, not released.
+		// Check if the result length is correct
+		if len(pokes) != len(tPokes) {
+			t.Errorf("Expected pokes length %d, got %d", len(tPokes), len(pokes))
+		}
 
-	tests := []struct {
-		name     string
-		appSeed  []byte
-		assetID  uint32
-		wantSeed []byte
-		wantPass []byte
-	}{
-		{
-			name:    "base",
-			appSeed: []byte{1, 2, 3},
-			assetID: 2,
-			wantSeed: []byte{
-				0xac, 0x61, 0xb1, 0xbc, 0x77, 0xd0, 0xa6, 0xd5, 0xd2, 0xb5, 0xc9, 0x77, 0x91, 0xd6, 0x4a, 0xaf,
-				0x4a, 0xa3, 0x47, 0xb7, 0xb, 0x85, 0xe, 0x82, 0x1c, 0x79, 0xab, 0xc0, 0x86, 0x50, 0xee, 0xda},
-			wantPass: []byte{
-				0xd8, 0xf0, 0x27, 0x4d, 0xbc, 0x56, 0xb0, 0x74, 0x1e, 0x20, 0x3b, 0x98, 0xe9, 0xaa, 0x5c, 0xba,
-				0x13, 0xfd, 0x60, 0x3b, 0x83, 0x76, 0x2e, 0x4b, 0x5d, 0x6d, 0x19, 0x57, 0x89, 0xe2, 0x8b, 0xc7},
-		},
-		{
-			name:    "change app seed",
-			appSeed: []byte{2, 2, 3},
-			assetID: 2,
-			wantSeed: []byte{
-				0xf, 0xc9, 0xf, 0xa8, 0xb3, 0xe9, 0x31, 0x2a, 0xba, 0xf1, 0xda, 0x70, 0x41, 0x81, 0x49, 0xed,
-				0xad, 0x47, 0x9, 0xcd, 0xe2, 0x17, 0x14, 0xd, 0x63, 0x49, 0x8a, 0xd8, 0xff, 0x1f, 0x3e, 0x8b},
-			wantPass: []byte{
-				0x78, 0x21, 0x72, 0x59, 0xbe, 0x39, 0xea, 0x54, 0x10, 0x46, 0x7d, 0x7e, 0xa, 0x95, 0xc4, 0xa0,
-				0xd8, 0x73, 0xce, 0x1, 0xb2, 0x49, 0x98, 0x6c, 0x68, 0xc5, 0x69, 0x69, 0xa7, 0x13, 0xc1, 0xce},
-		},
-		{
-			name:    "change asset ID",
-			appSeed: []byte{1, 2, 3},
-			assetID: 0,
-			wantSeed: []byte{
-				0xe1, 0xad, 0x62, 0xe4, 0x60, 0xfd, 0x75, 0x91, 0x3d, 0x41, 0x2e, 0x8e, 0xc5, 0x72, 0xd4, 0xa2,
-				0x39, 0x2d, 0x32, 0x86, 0xf0, 0x6b, 0xf7, 0xdf, 0x48, 0xcc, 0x57, 0xb1, 0x4b, 0x7b, 0xc6, 0xce},
-			wantPass: []byte{
-				0x52, 0xba, 0x59, 0x21, 0xd3, 0xc5, 0x6b, 0x2, 0x2c, 0x12, 0xc1, 0x98, 0xdc, 0x84, 0xed, 0x68,
-				0x6, 0x35, 0xa6, 0x25, 0xd0, 0xc4, 0x49, 0x5a, 0x13, 0xc3, 0x12, 0xfb, 0xeb, 0xb3, 0x61, 0x88},
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			seed, pass := AssetSeedAndPass(tt.assetID, tt.appSeed)
-			if !bytes.Equal(pass, tt.wantPass) {
-				t.Errorf("pass not as expected, got %#v", pass)
-			}
-			if !bytes.Equal(seed, tt.wantSeed) {
-				t.Errorf("seed not as expected, got %#v", seed)
+		// Check if the result contains the correct pokes
+		for i, poke := range tPokes {
+			if pokes[i] != poke {
+				t.Errorf("Expected poke %v at index %d, got %v", poke, i, pokes[i])
 			}
-		})
+		}
 	}
-}
+	{
+		pokesCapacity := 5
+		tNewPoke := &db.Notification{
+			TimeStamp:  6,
+			DetailText: "poke 6",
+		}
+		c := newPokesCache(pokesCapacity)
+		c.init(tPokes)
+		c.add(tNewPoke)
+		pokes := c.pokes()
 
-var randU32 = func() uint32 { return uint32(rand.Int31()) }
+		// Check if the result length is correct
+		if len(pokes) != pokesCapacity {
+			t.Errorf("Expected cache length %d, got %d", 1, len(pokes))
+		}
 
-func randOrderForMarket(base, quote uint32) order.Order {
-	switch rand.Intn(3) {
-	case 0:
-		o, _ := ordertest.RandomCancelOrder()
-		o.BaseAsset = base
-		o.QuoteAsset = quote
-		return o
-	case 1:
-		o, _ := ordertest.RandomMarketOrder()
-		o.BaseAsset = base
-		o.QuoteAsset = quote
-		return o
-	default:
-		o, _ := ordertest.RandomLimitOrder()
-		o.BaseAsset = base
-		o.QuoteAsset = quote
-		return o
+		tAllPokes := append(tPokes[1:], tNewPoke)
+		// Check if the result contains the correct pokes
+		for i, poke := range tAllPokes {
+			if pokes[i] != poke {
+				t.Errorf("Expected poke %v at index %d, got %v", poke, i, pokes[i])
+			}
+		}
 	}
 }
 
-func randBytes(n int) []byte {
-	b := make([]byte, n)
-	rand.Read(b)
-	return b
-}
-
-func TestDeleteOrderFn(t *testing.T) {
+func TestTakeAction(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	tCore := rig.core
 
-	randomOdrs := func() []*db.MetaOrder {
-		acct1 := dbtest.RandomAccountInfo()
-		acct2 := dbtest.RandomAccountInfo()
-		base1, quote1 := tUTXOAssetA.ID, tUTXOAssetB.ID
-		base2, quote2 := tACCTAsset.ID, tUTXOAssetA.ID
-		n := rand.Intn(9) + 1
-		orders := make([]*db.MetaOrder, n)
-		for i := 0; i < n; i++ {
-			acct := acct1
-			base, quote := base1, quote1
-			if i%2 == 1 {
-				acct = acct2
-				base, quote = base2, quote2
-			}
-			ord := randOrderForMarket(base, quote)
-			orders[i] = &db.MetaOrder{
-				MetaData: &db.OrderMetaData{
-					Status:             order.OrderStatus(rand.Intn(5) + 1),
-					Host:               acct.Host,
-					Proof:              db.OrderProof{DEXSig: randBytes(73)},
-					SwapFeesPaid:       rand.Uint64(),
-					RedemptionFeesPaid: rand.Uint64(),
-					MaxFeeRate:         rand.Uint64(),
+	coinID := encode.RandomBytes(32)
+	uniqueID := dex.Bytes(coinID).String()
+
+	newMatch := func() *matchTracker {
+		var matchID order.MatchID
+		copy(matchID[:], encode.RandomBytes(32))
+		return &matchTracker{
+			MetaMatch: db.MetaMatch{
+				UserMatch: &order.UserMatch{
+					Status:  order.MatchComplete,
+					MatchID: matchID,
+					Side:    order.Taker,
 				},
-				Order: ord,
-			}
+				MetaData: &db.MatchMetaData{},
+			},
 		}
-		return orders
 	}
+	rightMatch := newMatch()
+	rightMatch.MetaData.Proof.TakerRedeem = coinID
+	rightMatch.redemptionRejected = true
 
-	ordersFile, err := os.CreateTemp("", "delete_archives_test_orders")
-	if err != nil {
-		t.Fatal(err)
-	}
-	ordersFileName := ordersFile.Name()
-	ordersFile.Close()
-	os.Remove(ordersFileName)
+	wrongMatch := newMatch()
+	wrongMatch.MetaData.Proof.TakerRedeem = encode.RandomBytes(31)
 
-	tests := []struct {
-		name, ordersFileStr string
-		wantErr             bool
-	}{{
-		name:          "ok orders and file save",
-		ordersFileStr: ordersFileName,
-	}, {
-		name:          "bad file (already closed)",
-		ordersFileStr: ordersFileName,
-		wantErr:       true,
-	}}
+	makerMatch := newMatch()
+	makerMatch.Status = order.MakerRedeemed
+	makerMatch.MetaData.Proof.MakerRedeem = coinID
+	makerMatch.Side = order.Maker
 
-	for _, test := range tests {
-		perOrdFn, cleanupFn, err := tCore.deleteOrderFn(test.ordersFileStr)
-		if test.wantErr {
-			if err != nil {
-				continue
-			}
-			t.Fatalf("%q: expected error", test.name)
-		}
-		if err != nil {
-			t.Fatalf("%q: unexpected failure: %v", test.name, err)
-		}
-		for _, o := range randomOdrs() {
-			err = perOrdFn(o)
-			if err != nil {
-				t.Fatalf("%q: unexpected failure: %v", test.name, err)
-			}
-		}
-		cleanupFn()
+	tracker := &trackedTrade{
+		matches: map[order.MatchID]*matchTracker{
+			rightMatch.MatchID: rightMatch,
+			wrongMatch.MatchID: wrongMatch,
+			makerMatch.MatchID: makerMatch,
+		},
 	}
 
-	b, err := os.ReadFile(ordersFileName)
-	if err != nil {
-		t.Fatalf("unable to read file: %s", ordersFileName)
-	}
-	fmt.Println(string(b))
-	os.Remove(ordersFileName)
-}
+	var oid order.OrderID
+	copy(oid[:], encode.RandomBytes(32))
 
-func TestDeleteMatchFn(t *testing.T) {
-	randomMtchs := func() []*db.MetaMatch {
-		base, quote := tUTXOAssetA.ID, tUTXOAssetB.ID
-		acct := dbtest.RandomAccountInfo()
-		n := rand.Intn(9) + 1
-		metaMatches := make([]*db.MetaMatch, 0, n)
-		for i := 0; i < n; i++ {
-			m := &db.MetaMatch{
-				MetaData: &db.MatchMetaData{
-					Proof: *dbtest.RandomMatchProof(0.5),
-					DEX:   acct.Host,
-					Base:  base,
-					Quote: quote,
-					Stamp: rand.Uint64(),
-				},
-				UserMatch: ordertest.RandomUserMatch(),
-			}
-			if i%2 == 1 {
-				m.Status = order.MatchStatus(rand.Intn(4))
-			} else {
-				m.Status = order.MatchComplete              // inactive
-				m.MetaData.Proof.Auth.RedeemSig = []byte{0} // redeemSig required for MatchComplete to be considered inactive
-			}
-			metaMatches = append(metaMatches, m)
-		}
-		return metaMatches
+	rig.dc.trades[oid] = tracker
+
+	requestData := []byte(fmt.Sprintf(`{"orderID":"abcd","coinID":"%s","retry":true}`, dex.Bytes(coinID)))
+
+	err := rig.core.TakeAction(0, ActionIDRedeemRejected, requestData)
+	if err == nil {
+		t.Fatalf("expected error for wrong order ID but got nothing")
 	}
 
-	matchesFile, err := os.CreateTemp("", "delete_archives_test_matches")
+	rig.core.requestedActions[uniqueID] = nil
+	requestData = []byte(fmt.Sprintf(`{"orderID":"%s","coinID":"%s","retry":false}`, oid, dex.Bytes(coinID)))
+
+	err = rig.core.TakeAction(0, ActionIDRedeemRejected, requestData)
 	if err != nil {
-		t.Fatal(err)
+		t.Fatalf("error for retry=false: %v", err)
+	}
+	if len(rig.core.requestedActions) != 0 {
+		t.Fatal("requested action not removed")
 	}
-	matchesFileName := matchesFile.Name()
-	matchesFile.Close()
-	os.Remove(matchesFileName)
 
-	tests := []struct {
-		name, matchesFileStr string
-		wantErr              bool
-	}{{
-		name:           "ok matches and file save",
-		matchesFileStr: matchesFileName,
-	}, {
-		name:           "bad file (already closed)",
-		matchesFileStr: matchesFileName,
-		wantErr:        true,
-	}}
+	requestData = []byte(fmt.Sprintf(`{"orderID":"%s","coinID":"%s","retry":true}`, oid, dex.Bytes(coinID)))
+	err = rig.core.TakeAction(0, ActionIDRedeemRejected, requestData)
+	if err != nil {
+		t.Fatalf("error for taker retry=true: %v", err)
+	}
 
-	for _, test := range tests {
-		perMatchFn, cleanupFn, err := deleteMatchFn(test.matchesFileStr)
-		if test.wantErr {
-			if err != nil {
-				continue
-			}
-			t.Fatalf("%q: expected error", test.name)
-		}
-		if err != nil {
-			t.Fatalf("%q: unexpected failure: %v", test.name, err)
-		}
-		for _, m := range randomMtchs() {
-			err = perMatchFn(m, true)
-			if err != nil {
-				t.Fatalf("%q: unexpected failure: %v", test.name, err)
-			}
-		}
-		cleanupFn()
+	if len(rightMatch.MetaData.Proof.TakerRedeem) != 0 {
+		t.Fatalf("taker redemption not cleared")
+	}
+	if len(wrongMatch.MetaData.Proof.TakerRedeem) == 0 {
+		t.Fatalf("wrong taker redemption cleared")
 	}
 
-	b, err := os.ReadFile(matchesFileName)
+	makerMatch.redemptionRejected = true
+	err = rig.core.TakeAction(0, ActionIDRedeemRejected, requestData)
 	if err != nil {
-		t.Fatalf("unable to read file: %s", matchesFileName)
+		t.Fatalf("error for maker retry=true: %v", err)
 	}
-	fmt.Println(string(b))
-	os.Remove(matchesFileName)
+	if len(makerMatch.MetaData.Proof.MakerRedeem) != 0 {
+		t.Fatalf("maker redemption not cleared")
+	}
+
 }
 
-func TestDeleteArchivedRecords(t *testing.T) {
+func TestNoteDelivery(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
 	tCore := rig.core
-	tdb := tCore.db.(*TDB)
 
-	tempFile := func(suffix string) (path string) {
-		matchesFile, err := os.CreateTemp("", suffix+"delete_archives_test_matches")
-		if err != nil {
-			t.Fatal(err)
+	var mtx sync.Mutex
+	var delivered []*DeliveredNote
+	var failures int // number of requests to fail before succeeding
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
 		}
-		matchesFileName := matchesFile.Name()
-		matchesFile.Close()
-		os.Remove(matchesFileName)
-		return matchesFileName
-	}
-
-	tests := []struct {
-		name                                              string
-		olderThan                                         *time.Time
-		matchesFileStr, ordersFileStr                     string
-		archivedMatches, archivedOrders                   int
-		deleteInactiveOrdersErr, deleteInactiveMatchesErr error
-		wantErr                                           bool
-	}{{
-		name:            "ok no order or file save",
-		archivedMatches: 12,
-		archivedOrders:  24,
-	}, {
-		name:            "ok orders and file save",
-		ordersFileStr:   tempFile("abc"),
-		matchesFileStr:  tempFile("123"),
-		archivedMatches: 34,
-		archivedOrders:  67,
-	}, {
-		name:                    "orders save error",
-		ordersFileStr:           tempFile("abc"),
-		deleteInactiveOrdersErr: errors.New(""),
-		wantErr:                 true,
-	}, {
-		name:                     "matches save error",
-		matchesFileStr:           tempFile("123"),
-		deleteInactiveMatchesErr: errors.New(""),
-		wantErr:                  true,
-	}}
+		note := new(DeliveredNote)
+		if err := json.NewDecoder(r.Body).Decode(note); err != nil {
+			t.Errorf("error decoding delivered note: %v", err)
+		}
+		delivered = append(delivered, note)
+	}))
+	defer srv.Close()
 
-	for _, test := range tests {
-		tdb.archivedMatches = test.archivedMatches
-		tdb.archivedOrders = test.archivedOrders
-		tdb.deleteInactiveOrdersErr = test.deleteInactiveOrdersErr
-		tdb.deleteInactiveMatchesErr = test.deleteInactiveMatchesErr
-		nRecordsDeleted, err := tCore.DeleteArchivedRecords(test.olderThan, test.matchesFileStr, test.ordersFileStr)
-		if test.wantErr {
-			if err != nil {
-				continue
+	waitForDelivered := func(n int) []*DeliveredNote {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); ; time.Sleep(5 * time.Millisecond) {
+			mtx.Lock()
+			count := len(delivered)
+			mtx.Unlock()
+			if count >= n {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %d delivered notes, got %d", n, count)
 			}
-			t.Fatalf("%q: expected error", test.name)
 		}
+		time.Sleep(20 * time.Millisecond) // no more
+		mtx.Lock()
+		defer mtx.Unlock()
+		notes := delivered
+		delivered = nil
+		return notes
+	}
+
+	startDeliverer := func(cfg *NoteDeliveryConfig) func() {
+		t.Helper()
+		cfg.WebhookURL = srv.URL
+		d, err := newNoteDeliverer(cfg, tLogger)
 		if err != nil {
-			t.Fatalf("%q: unexpected failure: %v", test.name, err)
+			t.Fatalf("newNoteDeliverer error: %v", err)
 		}
-		expectedRecords := test.archivedMatches + test.archivedOrders
-		if nRecordsDeleted != expectedRecords {
-			t.Fatalf("%s: Expected %d deleted records, got %d", test.name, expectedRecords, nRecordsDeleted)
+		tCore.noteDeliverer = d
+		ctx, cancel := context.WithCancel(rig.core.ctx)
+		done := make(chan struct{})
+		go func() {
+			d.run(ctx)
+			close(done)
+		}()
+		return func() {
+			cancel()
+			<-done
+			tCore.noteDeliverer = nil
 		}
 	}
-}
-
-func TestLCM(t *testing.T) {
-	tests := []struct {
-		name                                  string
-		a, b, wantDenom, wantMultA, wantMultB uint64
-	}{{
-		name:      "ok 5 and 10",
-		a:         5,
-		b:         10,
-		wantDenom: 10,
-		wantMultA: 2,
-		wantMultB: 1,
-	}, {
-		name:      "ok 3 and 7",
-		a:         3,
-		b:         7,
-		wantDenom: 21,
-		wantMultA: 7,
-		wantMultB: 3,
-	}, {
-		name:      "ok 6 and 34",
-		a:         34,
-		b:         6,
-		wantDenom: 102,
-		wantMultA: 3,
-		wantMultB: 17,
-	}}
 
-	for _, test := range tests {
-		denom, multA, multB := lcm(test.a, test.b)
-		if denom != test.wantDenom || multA != test.wantMultA || multB != test.wantMultB {
-			t.Fatalf("%q: expected %d %d %d but got %d %d %d", test.name,
-				test.wantDenom, test.wantMultA, test.wantMultB, denom, multA, multB)
+	notifyAll := func() {
+		for _, severity := range []db.Severity{db.Data, db.Poke, db.Success, db.WarningLevel, db.ErrorLevel} {
+			tCore.notify(newSecurityNote(TopicSeedNeedsSaving, severity.String(), "details", severity))
 		}
 	}
-}
-
-func TestToggleRateSourceStatus(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
 
-	tests := []struct {
-		name, source  string
-		wantErr, init bool
-	}{{
-		name:    "Invalid rate source",
-		source:  "binance",
-		wantErr: true,
-	}, {
-		name:    "ok valid source",
-		source:  messari,
-		wantErr: false,
-	}, {
-		name:    "ok already disabled/not initialized || enabled",
-		source:  messari,
-		wantErr: false,
-	}}
+	// Default minimum severity is warning.
+	stop := startDeliverer(&NoteDeliveryConfig{})
+	notifyAll()
+	notes := waitForDelivered(2)
+	if len(notes) != 2 || notes[0].Severity != "warning" || notes[1].Severity != "error" {
+		t.Fatalf("wrong delivered notes %+v", notes)
+	}
+	if notes[0].Type != NoteTypeSecurity || notes[0].Topic != TopicSeedNeedsSaving ||
+		notes[0].Subject != "warning" || notes[0].Details != "details" || notes[0].Stamp == 0 {
+		t.Fatalf("wrong delivered note %+v", notes[0])
+	}
+	stop()
 
-	// Test disabling fiat rate source.
-	for _, test := range tests {
-		err := tCore.ToggleRateSourceStatus(test.source, true)
-		if test.wantErr != (err != nil) {
-			t.Fatalf("%s: wantErr = %t, err = %v", test.name, test.wantErr, err)
+	// Lower the minimum severity and redact details.
+	stop = startDeliverer(&NoteDeliveryConfig{MinSeverity: db.Success, Redact: true})
+	notifyAll()
+	notes = waitForDelivered(3)
+	if len(notes) != 3 || notes[0].Severity != "success" {
+		t.Fatalf("wrong delivered notes %+v", notes)
+	}
+	for _, note := range notes {
+		if note.Details != "" {
+			t.Fatalf("details not redacted")
 		}
 	}
+	stop()
 
-	// Test enabling fiat rate source.
-	for _, test := range tests {
-		if test.init {
-			tCore.fiatRateSources[test.source] = newCommonRateSource(tFetcher)
-		}
-		err := tCore.ToggleRateSourceStatus(test.source, false)
-		if test.wantErr != (err != nil) {
-			t.Fatalf("%s: wantErr = %t, err = %v", test.name, test.wantErr, err)
+	// Failed deliveries are retried.
+	stop = startDeliverer(&NoteDeliveryConfig{MinSeverity: db.ErrorLevel, RetryDelay: time.Millisecond})
+	mtx.Lock()
+	failures = 2
+	mtx.Unlock()
+	notifyAll()
+	if notes = waitForDelivered(1); len(notes) != 1 {
+		t.Fatalf("expected 1 delivered note after retries, got %d", len(notes))
+	}
+
+	// And dropped after MaxAttempts.
+	mtx.Lock()
+	failures = defaultNoteDeliveryMaxAttempts
+	mtx.Unlock()
+	notifyAll()
+	time.Sleep(50 * time.Millisecond)
+	mtx.Lock()
+	if len(delivered) != 0 || failures != 0 {
+		t.Fatalf("expected note to be dropped after %d attempts", defaultNoteDeliveryMaxAttempts)
+	}
+	mtx.Unlock()
+	stop()
+
+	// Notifications are dropped while the queue is full, without blocking.
+	d, err := newNoteDeliverer(&NoteDeliveryConfig{WebhookURL: srv.URL, QueueSize: 1}, tLogger)
+	if err != nil {
+		t.Fatalf("newNoteDeliverer error: %v", err)
+	}
+	tCore.noteDeliverer = d
+	notifyAll()
+	tCore.noteDeliverer = nil
+	if len(d.queue) != 1 {
+		t.Fatalf("expected 1 queued note, got %d", len(d.queue))
+	}
+
+	// Configuration errors.
+	for _, cfg := range []*NoteDeliveryConfig{
+		{},
+		{WebhookURL: "ftp://example.com"},
+		{WebhookURL: srv.URL, SMTP: &SMTPConfig{Host: "localhost:25", From: "a@b.c", To: []string{"d@e.f"}}},
+		{SMTP: &SMTPConfig{Host: "localhost", From: "a@b.c", To: []string{"d@e.f"}}},
+		{SMTP: &SMTPConfig{Host: "localhost:25", From: "a@b.c"}},
+		{SMTP: &SMTPConfig{Host: "localhost:25", From: "a@b.c\r\nBcc: x@y.z", To: []string{"d@e.f"}}},
+	} {
+		if _, err := newNoteDeliverer(cfg, tLogger); err == nil {
+			t.Fatalf("no error for bad config %+v", cfg)
 		}
 	}
 }
 
-func TestFiatRateSources(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
-	supportedFetchers := len(fiatRateFetchers)
-	rateSources := tCore.FiatRateSources()
-	if len(rateSources) != supportedFetchers {
-		t.Fatalf("Expected %d number of fiat rate source/fetchers", supportedFetchers)
+func TestSMTPMessageHeaders(t *testing.T) {
+	sink, err := newSMTPSink(&SMTPConfig{Host: "localhost:25", From: "a@b.c", To: []string{"d@e.f"}})
+	if err != nil {
+		t.Fatalf("newSMTPSink error: %v", err)
+	}
+	msg := sink.message(&DeliveredNote{
+		Severity: "warning",
+		Subject:  "Order canceled\r\nBcc: x@y.z",
+		Stamp:    uint64(time.Now().UnixMilli()),
+	})
+	headers, _, found := strings.Cut(msg, "\r\n\r\n")
+	if !found {
+		t.Fatalf("no header terminator in message %q", msg)
+	}
+	for _, line := range strings.Split(headers, "\r\n") {
+		if strings.HasPrefix(line, "Bcc:") {
+			t.Fatalf("injected header in message %q", msg)
+		}
 	}
 }
 
-func TestFiatConversions(t *testing.T) {
+func TestRequestFaucetFunds(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
 	tCore := rig.core
+	dcrWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
 
-	// No fiat rate source initialized
-	fiatRates := tCore.fiatConversions()
-	if len(fiatRates) != 0 {
-		t.Fatal("Unexpected asset rate values.")
+	// No faucet configured.
+	if _, err := tCore.RequestFaucetFunds(tUTXOAssetA.ID); err == nil {
+		t.Fatalf("no error without a faucet")
 	}
 
-	// Initialize fiat rate sources.
-	for token := range fiatRateFetchers {
-		tCore.fiatRateSources[token] = newCommonRateSource(tFetcher)
+	var mtx sync.Mutex
+	var requests []*faucetRequest
+	var status int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		req := new(faucetRequest)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			t.Errorf("error decoding faucet request: %v", err)
+		}
+		requests = append(requests, req)
+		if status != 0 {
+			w.WriteHeader(status)
+			return
+		}
+		json.NewEncoder(w).Encode(&faucetResponse{TxID: "txid-" + req.Asset})
+	}))
+	defer srv.Close()
+	numRequests := func() int {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return len(requests)
 	}
 
-	// Fetch fiat rates.
-	tCore.wg.Add(1)
-	go func() {
-		defer tCore.wg.Done()
-		tCore.refreshFiatRates(tCtx)
-	}()
-	tCore.wg.Wait()
-
-	// Expects assets fiat rate values.
-	fiatRates = tCore.fiatConversions()
-	if len(fiatRates) != 2 {
-		t.Fatal("Expected assets fiat rate for two assets")
+	// Never on mainnet.
+	if _, err := newFaucetClient(&FaucetConfig{URL: srv.URL}, dex.Mainnet); err == nil {
+		t.Fatalf("no error for mainnet faucet")
 	}
-
-	// fiat rates for assets can expire, and fiat rate fetchers can be
-	// removed if expired.
-	for token, source := range tCore.fiatRateSources {
-		source.fiatRates[tUTXOAssetA.ID].lastUpdate = time.Now().Add(-time.Minute)
-		source.fiatRates[tUTXOAssetB.ID].lastUpdate = time.Now().Add(-time.Minute)
-		if source.isExpired(55 * time.Second) {
-			delete(tCore.fiatRateSources, token)
-		}
+	if _, err := newFaucetClient(&FaucetConfig{URL: "ftp://faucet"}, dex.Testnet); err == nil {
+		t.Fatalf("no error for bad faucet URL scheme")
 	}
 
-	fiatRates = tCore.fiatConversions()
-	if len(fiatRates) != 0 {
-		t.Fatal("Unexpected assets fiat rate values, expected to ignore expired fiat rates.")
+	faucet, err := newFaucetClient(&FaucetConfig{URL: srv.URL}, dex.Testnet)
+	if err != nil {
+		t.Fatalf("newFaucetClient error: %v", err)
 	}
+	tCore.faucet = faucet
 
-	if len(tCore.fiatRateSources) != 0 {
-		t.Fatal("Expected fiat conversion to be disabled, all rate source data has expired.")
+	// Success
+	txID, err := tCore.RequestFaucetFunds(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("RequestFaucetFunds error: %v", err)
+	}
+	if txID != "txid-dcr" {
+		t.Fatalf("wrong txid %q", txID)
+	}
+	if numRequests() != 1 || requests[0].Asset != "dcr" || requests[0].Address == "" {
+		t.Fatalf("wrong faucet request %+v", requests)
 	}
-}
 
-func TestValidateAddress(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	tCore := rig.core
+	// Another request for the same asset is rate-limited without contacting
+	// the faucet or generating a new address.
+	tDcrWallet := dcrWallet.Wallet.(*TXCWallet)
+	addrCount := tDcrWallet.newAddrCount.Load()
+	if _, err = tCore.RequestFaucetFunds(tUTXOAssetA.ID); !errors.Is(err, errFaucetRateLimited) {
+		t.Fatalf("expected rate limit error, got %v", err)
+	}
+	if numRequests() != 1 {
+		t.Fatalf("rate-limited request sent to the faucet")
+	}
+	if tDcrWallet.newAddrCount.Load() != addrCount {
+		t.Fatalf("new address generated for a rate-limited request")
+	}
 
-	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
-	tCore.wallets[tUTXOAssetA.ID] = wallet
+	// Other assets are limited separately.
+	if _, err = tCore.RequestFaucetFunds(tUTXOAssetB.ID); err != nil {
+		t.Fatalf("RequestFaucetFunds error for second asset: %v", err)
+	}
 
-	tests := []struct {
-		name              string
-		addr              string
-		wantValidAddr     bool
-		wantMissingWallet bool
-		wantErr           bool
-	}{{
-		name:          "valid address",
-		addr:          "randomvalidaddress",
-		wantValidAddr: true,
-	}, {
-		name: "invalid address",
-		addr: "",
-	}, {
-		name:              "wallet not found",
-		addr:              "randomaddr",
-		wantMissingWallet: true,
-		wantErr:           true,
-	}}
-	for _, test := range tests {
-		tWallet.validAddr = test.wantValidAddr
-		if test.wantMissingWallet {
-			tCore.wallets = make(map[uint32]*xcWallet)
-		}
-		valid, err := tCore.ValidateAddress(test.addr, tUTXOAssetA.ID)
-		if test.wantErr {
-			if err != nil {
-				continue
-			}
-			t.Fatalf("%s: expected error", test.name)
-		}
-		if test.wantValidAddr != valid {
-			t.Fatalf("Got wrong response for address validation, got %v expected %v", valid, test.wantValidAddr)
-		}
+	// The faucet's own rate limit.
+	faucet.mtx.Lock()
+	faucet.lastRequest[tUTXOAssetA.ID] = time.Now().Add(-faucet.interval)
+	faucet.mtx.Unlock()
+	mtx.Lock()
+	status = http.StatusTooManyRequests
+	mtx.Unlock()
+	if _, err = tCore.RequestFaucetFunds(tUTXOAssetA.ID); !errors.Is(err, errFaucetRateLimited) {
+		t.Fatalf("expected rate limit error for faucet 429, got %v", err)
+	}
+	if numRequests() != 3 {
+		t.Fatalf("expected 3 faucet requests, got %d", numRequests())
 	}
 }
 
-func TestEstimateSendTxFee(t *testing.T) {
+func TestWalletSetVersions(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
+	dc := rig.dc
 	tCore := rig.core
 
-	tests := []struct {
-		name              string
-		asset             uint32
-		estFee            uint64
-		value             uint64
-		subtract          bool
-		wantMissingWallet bool
-		wantErr           bool
-	}{{
-		name:     "ok",
-		asset:    tUTXOAssetA.ID,
-		subtract: true,
-		estFee:   1e8,
-		value:    1e8,
-	}, {
-		name:     "zero amount",
-		asset:    tACCTAsset.ID,
-		subtract: true,
-		wantErr:  true,
-	}, {
-		name:     "subtract true and not withdrawer",
-		asset:    tACCTAsset.ID,
-		subtract: true,
-		wantErr:  true,
-		value:    1e8,
-	}, {
-		name:              "wallet not found",
-		asset:             tUTXOAssetA.ID,
-		wantErr:           true,
-		wantMissingWallet: true,
-		value:             1e8,
-	}}
+	baseWallet, _ := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = baseWallet
+	quoteWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = quoteWallet
+	baseWallet.supportedVersions = []uint32{0}
 
-	for _, test := range tests {
-		wallet, tWallet := newTWallet(test.asset)
-		tCore.wallets[test.asset] = wallet
-		if test.wantMissingWallet {
-			delete(tCore.wallets, test.asset)
-		}
+	setServerVersions := func(ver uint32, vers ...uint32) {
+		a := *tUTXOAssetA
+		a.Version, a.Versions = ver, vers
+		dc.assetsMtx.Lock()
+		dc.assets[a.ID] = &a
+		dc.assetsMtx.Unlock()
+	}
+	defer func() {
+		dc.assetsMtx.Lock()
+		dc.assets[tUTXOAssetA.ID] = tUTXOAssetA
+		dc.assetsMtx.Unlock()
+	}()
 
-		tWallet.estFee = test.estFee
+	// The server prefers a newer version, but still accepts ours.
+	setServerVersions(1, 0, 1)
+	_, assets, compat, err := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if err != nil {
+		t.Fatalf("walletSet error: %v", err)
+	}
+	if !compat {
+		t.Fatalf("compatible versions not negotiated")
+	}
+	if assets.baseAsset.Version != 0 || assets.fromAsset.Version != 0 {
+		t.Fatalf("wrong negotiated version %d", assets.baseAsset.Version)
+	}
+	if dc.assets[tUTXOAssetA.ID].Version != 1 {
+		t.Fatalf("server asset configuration modified")
+	}
 
-		tWallet.estFeeErr = nil
-		if test.wantErr {
-			tWallet.estFeeErr = tErr
-		}
-		estimate, _, err := tCore.EstimateSendTxFee("addr", test.asset, test.value, test.subtract, false)
-		if test.wantErr {
-			if err != nil {
-				continue
-			}
-			t.Fatalf("%s: expected error", test.name)
-		}
-		if err != nil {
-			t.Fatalf("%s: unexpected error: %v", test.name, err)
-		}
+	// The server only accepts a newer version.
+	setServerVersions(1)
+	wallets, assets, compat, _ := tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if compat {
+		t.Fatalf("incompatible versions reported as compatible")
+	}
+	err = assetVersionError(dc.acct.host, wallets, assets)
+	if err == nil || !strings.Contains(err.Error(), "upgrade required") {
+		t.Fatalf("expected client upgrade error, got %v", err)
+	}
 
-		if estimate != test.estFee {
-			t.Fatalf("%s: expected fee %v, got %v", test.name, test.estFee, estimate)
-		}
-		if !test.wantErr && err != nil {
-			t.Fatalf("%s: unexpected error", test.name)
-		}
+	// The server only accepts an older version.
+	baseWallet.supportedVersions = []uint32{2}
+	wallets, assets, compat, _ = tCore.walletSet(dc, tUTXOAssetA.ID, tUTXOAssetB.ID, true)
+	if compat {
+		t.Fatalf("incompatible versions reported as compatible")
+	}
+	err = assetVersionError(dc.acct.host, wallets, assets)
+	if err == nil || !strings.Contains(err.Error(), "server must be upgraded") {
+		t.Fatalf("expected server upgrade error, got %v", err)
 	}
 }
 
-type TDynamicSwapper struct {
+type TDerivationVerifier struct {
 	*TXCWallet
-	tfpPaid         uint64
-	tfpSecretHashes [][]byte
-	tfpErr          error
+	// offset shifts the wallet's derivation relative to the seed's.
+	offset    int
+	seedErr   error
+	walletErr error
+	seed      []byte
 }
 
-func (dtfc *TDynamicSwapper) DynamicSwapFeesPaid(ctx context.Context, coinID, contractData dex.Bytes) (uint64, [][]byte, error) {
-	return dtfc.tfpPaid, dtfc.tfpSecretHashes, dtfc.tfpErr
-}
-func (dtfc *TDynamicSwapper) DynamicRedemptionFeesPaid(ctx context.Context, coinID, contractData dex.Bytes) (uint64, [][]byte, error) {
-	return dtfc.tfpPaid, dtfc.tfpSecretHashes, dtfc.tfpErr
+func tDerivedAddresses(seed []byte, start, n int) []string {
+	addrs := make([]string, n)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("%x/%d", seed[:4], start+i)
+	}
+	return addrs
 }
 
-var _ asset.DynamicSwapper = (*TDynamicSwapper)(nil)
-
-func TestUpdateFeesPaid(t *testing.T) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	tests := []struct {
-		name                   string
-		paid                   uint64
-		init, swapWallets      bool
-		tfpErr, updateOrderErr error
-	}{{
-		name: "ok init",
-		paid: 1,
-		init: true,
-	}, {
-		name:        "ok redeem",
-		paid:        1,
-		swapWallets: true,
-	}, {
-		name: "not dynamic",
-	}, {
-		name:   "TransactionFeesPaid error other than coin not found",
-		init:   true,
-		tfpErr: errors.New("other error"),
-	}}
-	for _, test := range tests {
-		acctWallet, tWallet := newTWallet(tACCTAsset.ID)
-		dynamicFeeChecker := &TDynamicSwapper{TXCWallet: tWallet}
-		acctWallet.Wallet = dynamicFeeChecker
-		tWallet.confs["00"] = 10
-
-		utxoWallet, _ := newTWallet(tUTXOAssetA.ID)
-
-		wallets := &walletSet{
-			fromWallet:  acctWallet,
-			toWallet:    utxoWallet,
-			baseWallet:  acctWallet,
-			quoteWallet: utxoWallet,
-		}
-		if test.swapWallets {
-			wallets.fromWallet, wallets.toWallet = wallets.toWallet, wallets.fromWallet
-			wallets.baseWallet, wallets.quoteWallet = wallets.quoteWallet, wallets.baseWallet
-		}
-
-		dc := &dexConnection{
-			acct: tNewAccount(&tCrypter{}),
-			log:  tLogger,
-		}
-		lo, _, _, _ := makeLimitOrder(dc, true, 0, 0)
-		tracker := &trackedTrade{
-			wallets:  wallets,
-			dc:       dc,
-			metaData: new(db.OrderMetaData),
-			db:       new(TDB),
-			Order:    lo,
-			notify:   func(Notification) {},
-		}
-		tracker.SetTime(time.Now())
-		dynamicFeeChecker.tfpPaid = 1
-		dynamicFeeChecker.tfpErr = test.tfpErr
-		dynamicFeeChecker.tfpSecretHashes = [][]byte{{0}}
-		matchID := ordertest.RandomMatchID()
-		match := &matchTracker{
-			MetaMatch: db.MetaMatch{
-				UserMatch: &order.UserMatch{MatchID: matchID},
-				MetaData: &db.MatchMetaData{
-					Proof: db.MatchProof{
-						TakerSwap:   []byte{0},
-						MakerSwap:   []byte{0},
-						MakerRedeem: []byte{0},
-						TakerRedeem: []byte{0},
-						SecretHash:  []byte{0},
-					},
-				},
-			},
-		}
-		tracker.updateDynamicSwapOrRedemptionFeesPaid(ctx, match, test.init)
-		got := tracker.metaData.SwapFeesPaid
-		if !test.init {
-			got = tracker.metaData.RedemptionFeesPaid
-		}
-		if got != test.paid {
-			t.Fatalf("%s: want %d but got %d fees paid", test.name, test.paid, got)
-		}
-	}
+func (w *TDerivationVerifier) SeedAddresses(seed []byte, n int) ([]string, error) {
+	return tDerivedAddresses(seed, 0, n), w.seedErr
 }
 
-func TestUpdateBondOptions(t *testing.T) {
-	const feeRate = 50
+func (w *TDerivationVerifier) WalletAddresses(n int) ([]string, error) {
+	return tDerivedAddresses(w.seed, w.offset, n), w.walletErr
+}
 
+func TestVerifyWalletDerivation(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	acct := rig.dc.acct
-	acct.isAuthed = true
+	tCore := rig.core
 
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	dcrWallet.Wallet = &TFeeRater{tDcrWallet, feeRate}
-	rig.core.wallets[tUTXOAssetA.ID] = dcrWallet
-	bondFeeBuffer := tDcrWallet.BondsFeeBuffer(feeRate)
+	const assetID = 42
+	xcWallet, tWallet := newTWallet(assetID)
+	tCore.wallets[assetID] = xcWallet
 
-	bondAsset := dcrBondAsset
-	var wrongBondAssetID uint32 = 0
-	var targetTier uint64 = 1
-	var targetTierZero uint64 = 0
-	defaultMaxBondedAmt := maxBondedMult * bondAsset.Amt * targetTier
-	tooLowMaxBonded := defaultMaxBondedAmt - 1
-	// Double because we will reserve for the bond that's about to be posted
-	// in rotateBonds too.
-	singlyBondedReserves := bondAsset.Amt*targetTier*2 + bondFeeBuffer
+	// Not a DerivationVerifier.
+	if _, err := tCore.VerifyWalletDerivation(tPW, assetID); err == nil {
+		t.Fatalf("no error for unsupported wallet")
+	}
 
-	type acctState struct {
-		targetTier   uint64
-		maxBondedAmt uint64
+	seed, _, err := tCore.assetSeedAndPass(assetID, rig.crypter)
+	if err != nil {
+		t.Fatalf("assetSeedAndPass error: %v", err)
 	}
+	verifier := &TDerivationVerifier{TXCWallet: tWallet, seed: seed}
+	xcWallet.Wallet = verifier
 
-	for _, tt := range []struct {
-		name        string
-		bal         uint64
-		form        BondOptionsForm
-		before      acctState
-		after       acctState
-		expReserves uint64
-		addOtherDC  bool
-		wantErr     bool
-	}{
-		{
-			name: "set target tier to 1",
-			bal:  singlyBondedReserves,
-			form: BondOptionsForm{
-				Host:        acct.host,
-				TargetTier:  &targetTier,
-				BondAssetID: &bondAsset.ID,
-			},
-			after: acctState{
-				targetTier:   1,
-				maxBondedAmt: defaultMaxBondedAmt,
-			},
-			expReserves: singlyBondedReserves,
-		},
-		{
-			name: "low balance",
-			bal:  singlyBondedReserves - 1,
-			form: BondOptionsForm{
-				Host:        acct.host,
-				TargetTier:  &targetTier,
-				BondAssetID: &bondAsset.ID,
-			},
-			wantErr: true,
-		},
-		{
-			name: "max-bonded too low",
-			bal:  singlyBondedReserves,
-			form: BondOptionsForm{
-				Host:         acct.host,
-				TargetTier:   &targetTier,
-				BondAssetID:  &bondAsset.ID,
-				MaxBondedAmt: &tooLowMaxBonded,
-			},
-			wantErr: true,
-		},
-		{
-			name: "unsupported bond asset",
-			form: BondOptionsForm{
-				Host:        acct.host,
-				TargetTier:  &targetTier,
-				BondAssetID: &wrongBondAssetID,
-			},
-			wantErr: true,
-		},
-		{
-			name: "lower target tier with zero balance OK",
-			bal:  0,
-			form: BondOptionsForm{
-				Host:        acct.host,
-				TargetTier:  &targetTierZero,
-				BondAssetID: &bondAsset.ID,
-			},
-			before: acctState{
-				targetTier:   1,
-				maxBondedAmt: defaultMaxBondedAmt,
-			},
-			after:       acctState{},
-			expReserves: 0,
-		},
-		{
-			name: "lower target tier to zero with other exchanges still keeps reserves",
-			bal:  0,
-			form: BondOptionsForm{
-				Host:        acct.host,
-				TargetTier:  &targetTierZero,
-				BondAssetID: &bondAsset.ID,
-			},
-			before: acctState{
-				targetTier:   1,
-				maxBondedAmt: defaultMaxBondedAmt,
-			},
-			addOtherDC:  true,
-			after:       acctState{},
-			expReserves: bondFeeBuffer,
-		},
-	} {
-		t.Run(tt.name, func(t *testing.T) {
-			before, after := tt.before, tt.after
-			acct.targetTier = before.targetTier
-			acct.maxBondedAmt = before.maxBondedAmt
-			tDcrWallet.bal = &asset.Balance{Available: tt.bal}
+	// Correctly derived.
+	res, err := tCore.VerifyWalletDerivation(tPW, assetID)
+	if err != nil {
+		t.Fatalf("VerifyWalletDerivation error: %v", err)
+	}
+	if !res.Match || len(res.Expected) != derivationCheckAddrs || len(res.Reported) != derivationCheckAddrs {
+		t.Fatalf("wrong result for correctly derived wallet: %+v", res)
+	}
 
-			if tt.addOtherDC {
-				dc, _, acct := testDexConnection(rig.core.ctx, rig.crypter.(*tCrypter))
-				acct.host = "someotherhost.com"
-				rig.core.conns[acct.host] = dc
-				defer delete(rig.core.conns, acct.host)
-				acct.bondAsset = bondAsset.ID
-				acct.targetTier = 1
-			}
+	// Restored from a different seed.
+	verifier.seed = encode.RandomBytes(32)
+	if res, err = tCore.VerifyWalletDerivation(tPW, assetID); err != nil {
+		t.Fatalf("VerifyWalletDerivation error: %v", err)
+	}
+	if res.Match {
+		t.Fatalf("wallet from a different seed reported as a match")
+	}
 
-			if err := rig.core.UpdateBondOptions(&tt.form); err != nil {
-				if tt.wantErr {
-					return
-				}
-				t.Fatalf("UpdateBondOptions error: %v", err)
-			}
-			if tt.wantErr {
-				t.Fatalf("No error when one was expected")
-			}
+	// Same seed, different derivation path.
+	verifier.seed = seed
+	verifier.offset = 1
+	if res, err = tCore.VerifyWalletDerivation(tPW, assetID); err != nil {
+		t.Fatalf("VerifyWalletDerivation error: %v", err)
+	}
+	if res.Match {
+		t.Fatalf("wallet with a different derivation path reported as a match")
+	}
+	verifier.offset = 0
 
-			if acct.targetTier != after.targetTier {
-				t.Fatalf("Wrong targetTier. %d != %d", acct.targetTier, after.targetTier)
-			}
-			if acct.maxBondedAmt != after.maxBondedAmt {
-				t.Fatalf("Wrong maxBondedAmt. %d != %d", acct.maxBondedAmt, after.maxBondedAmt)
-			}
-			if tDcrWallet.reserves.Load() != tt.expReserves {
-				t.Fatalf("Wrong reserves. %d != %d", tDcrWallet.reserves.Load(), tt.expReserves)
-			}
-		})
+	// Errors.
+	verifier.seedErr = tErr
+	if _, err = tCore.VerifyWalletDerivation(tPW, assetID); err == nil {
+		t.Fatalf("no error for seed derivation error")
+	}
+	verifier.seedErr = nil
+	verifier.walletErr = tErr
+	if _, err = tCore.VerifyWalletDerivation(tPW, assetID); err == nil {
+		t.Fatalf("no error for wallet addresses error")
+	}
+	verifier.walletErr = nil
+	xcWallet.importedSeed = true
+	if _, err = tCore.VerifyWalletDerivation(tPW, assetID); err == nil {
+		t.Fatalf("no error for imported wallet")
+	}
+	xcWallet.importedSeed = false
+	if _, err = tCore.VerifyWalletDerivation(tPW, 12345); err == nil {
+		t.Fatalf("no error for unknown wallet")
 	}
 }
 
-func TestRotateBonds(t *testing.T) {
-	const feeRate = 50
-
+func TestMarketQuantization(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	rig.core.Login(tPW)
+	tCore := rig.core
 
-	acct := rig.dc.acct
-	acct.isAuthed = true
+	for _, a := range []*dex.Asset{tUTXOAssetA, tUTXOAssetB} {
+		a := *a
+		a.UnitInfo = dex.UnitInfo{Conventional: dex.Denomination{ConversionFactor: 1e8}}
+		rig.dc.assets[a.ID] = &a
+	}
+
+	q, err := tCore.MarketQuantization(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID)
+	if err != nil {
+		t.Fatalf("MarketQuantization error: %v", err)
+	}
+	// A lot size of 1e7 is 0.1 DCR, and a rate step of 10 is 1e-7 BTC/DCR.
+	if q.LotSize != dcrBtcLotSize || q.RateStep != dcrBtcRateStep || q.QtyPrecision != 1 || q.RatePrecision != 7 {
+		t.Fatalf("wrong quantization %+v", q)
+	}
+
+	if _, err = tCore.MarketQuantization(tDexHost, tUTXOAssetA.ID, 12345); !errorHasCode(err, marketErr) {
+		t.Fatalf("wrong error for unknown market: %v", err)
+	}
+	if _, err = tCore.MarketQuantization("unknown.host", tUTXOAssetA.ID, tUTXOAssetB.ID); err == nil {
+		t.Fatalf("no error for unknown host")
+	}
+
+	mktConf := rig.dc.marketConfig(tDcrBtcMktName)
+	qty, rate := 5*dcrBtcLotSize, 100*dcrBtcRateStep
+	tests := []struct {
+		name          string
+		qty, rate     uint64
+		isLimit, sell bool
+		wantErr       string
+	}{
+		{"limit ok", qty, rate, true, false, ""},
+		{"limit partial lot", qty + 1, rate, true, false, "not a multiple of the market's lot size"},
+		{"limit off rate step", qty, rate + 1, true, true, "not a multiple of the market's rate step"},
+		{"market sell partial lot", qty + 1, 0, false, true, "not a multiple of the market's lot size"},
+		{"market buy in quote units", qty + 1, 0, false, false, ""},
+	}
+	for _, tt := range tests {
+		err := checkQuantization(mktConf, tt.qty, tt.rate, tt.isLimit, tt.sell)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if !errorHasCode(err, orderParamsErr) || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Fatalf("%s: wrong error: %v", tt.name, err)
+		}
+	}
+}
+
+func TestReduceOrder(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	dc := rig.dc
+	tCore := rig.core
 
 	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	dcrWallet.Wallet = &TFeeRater{tDcrWallet, feeRate}
-	rig.core.wallets[tUTXOAssetA.ID] = dcrWallet
-	bondAsset := dcrBondAsset
-	bondFeeBuffer := tDcrWallet.BondsFeeBuffer(feeRate)
-	maxBondedPerTier := maxBondedMult * bondAsset.Amt
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	btcWallet, _ := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
 
-	now := uint64(time.Now().Unix())
-	bondExpiry := rig.dc.config().BondExpiry
-	// bondDuration := minBondLifetime(rig.core.net, bondExpiry)
-	locktimeThresh := now + bondExpiry
-	pBuffer := uint64(pendingBuffer(rig.core.net))
-	mergeableLocktimeThresh := locktimeThresh + bondExpiry/4 + pBuffer
-	// unexpired := locktimeThresh + 1
-	locktimeExpired := locktimeThresh - 1
-	locktimeRefundable := now - 1
-	weakTimeThresh := locktimeThresh + pBuffer
+	defer func(d time.Duration, n uint64) {
+		reduceOrderPollInterval, reduceOrderWaitEpochs = d, n
+	}(reduceOrderPollInterval, reduceOrderWaitEpochs)
+	reduceOrderPollInterval = 5 * time.Millisecond
 
-	run := func(wantPending, wantExpired int, expectedReserves uint64) {
-		ctx, cancel := context.WithTimeout(rig.core.ctx, time.Second)
-		rig.core.rotateBonds(ctx)
-		cancel()
+	const lots = 10
+	qty := lots * dcrBtcLotSize
+	rate := dcrBtcRateStep * 1000
 
-		t.Helper()
-		if len(acct.pendingBonds) != wantPending {
-			t.Fatalf("wanted %d pending bonds, got %d", wantPending, len(acct.pendingBonds))
+	newTracker := func(force order.TimeInForce) *trackedTrade {
+		lo, dbOrder, preImg, _ := makeLimitOrder(dc, true, qty, rate)
+		lo.Force = force
+		dbOrder.MetaData.Status = order.OrderStatusBooked
+		dbOrder.MetaData.Options = map[string]string{"opt": "val"}
+		tracker := newTrackedTrade(dbOrder, preImg, dc, rig.core.lockTimeTaker, rig.core.lockTimeMaker,
+			rig.db, rig.queue, nil, nil, rig.core.notify, rig.core.formatDetails)
+		dc.tradeMtx.Lock()
+		dc.trades[lo.ID()] = tracker
+		dc.tradeMtx.Unlock()
+		return tracker
+	}
+
+	// whenCanceled runs f with the tracker locked once the cancel order is
+	// placed.
+	whenCanceled := func(tracker *trackedTrade, f func()) {
+		go func() {
+			for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+				tracker.mtx.Lock()
+				if tracker.cancel != nil {
+					f()
+					tracker.mtx.Unlock()
+					return
+				}
+				tracker.mtx.Unlock()
+			}
+		}()
+	}
+	cancelMatched := func(tracker *trackedTrade, filled uint64) func() {
+		return func() {
+			tracker.Trade().SetFill(qty)
+			tracker.cancel.matches.maker = &msgjson.Match{Quantity: qty - filled}
+			tracker.metaData.Status = order.OrderStatusCanceled
 		}
-		if len(acct.expiredBonds) != wantExpired {
-			t.Fatalf("wanted %d expired bonds, got %d", wantExpired, len(acct.expiredBonds))
+	}
+
+	ensureErr := func(tag string, tracker *trackedTrade, reduceTo uint64, expCode int) {
+		t.Helper()
+		_, err := tCore.reduceOrder(dc, tracker, reduceTo)
+		if err == nil {
+			t.Fatalf("%s: no error", tag)
 		}
-		if tDcrWallet.reserves.Load() != expectedReserves {
-			t.Fatalf("wrong reserves. expected %d, got %d", expectedReserves, tDcrWallet.reserves.Load())
+		if expCode >= 0 && !errorHasCode(err, expCode) {
+			t.Fatalf("%s: wrong error %v", tag, err)
 		}
 	}
 
-	// No bonds, target tier 1. Should create a new bond and add it to pending.
-	var targetTier uint64 = 1
-	acct.targetTier = targetTier
-	acct.maxBondedAmt = maxBondedPerTier * targetTier
-	acct.bondAsset = bondAsset.ID
-	tDcrWallet.bal = &asset.Balance{Available: bondAsset.Amt*targetTier + bondFeeBuffer}
-	rig.queuePrevalidateBond()
-	run(1, 0, bondAsset.Amt+bondFeeBuffer)
+	// Only standing limit orders can be reduced.
+	ensureErr("immediate", newTracker(order.ImmediateTiF), 5*dcrBtcLotSize, orderParamsErr)
+	tracker := newTracker(order.StandingTiF)
+	ensureErr("not lot multiple", tracker, 5*dcrBtcLotSize+1, orderParamsErr)
+	ensureErr("zero", tracker, 0, orderParamsErr)
+	ensureErr("not reduced", tracker, qty, orderParamsErr)
+	if tracker.cancel != nil {
+		t.Fatalf("order canceled with invalid quantity")
+	}
 
-	// Post and then expire the bond. This first bond should move to expired and we
-	// should create another bond.
-	acct.bonds, acct.pendingBonds = acct.pendingBonds, nil
-	acct.bonds[0].LockTime = locktimeExpired
-	rig.queuePrevalidateBond()
-	// The newly expired bond will be refunded in time to fund our next round,
-	// so we only need fees reserved.
-	run(1, 1, bondFeeBuffer)
+	// Reducing an unfilled order.
+	reduceTo := 4 * dcrBtcLotSize
+	rig.queueCancel(nil)
+	whenCanceled(tracker, cancelMatched(tracker, 0))
+	form, err := tCore.reduceOrder(dc, tracker, reduceTo)
+	if err != nil {
+		t.Fatalf("reduceOrder error: %v", err)
+	}
+	if form.Qty != reduceTo || form.Rate != rate || !form.Sell || !form.IsLimit || form.TifNow ||
+		form.Base != tUTXOAssetA.ID || form.Quote != tUTXOAssetB.ID || form.Host != tDexHost ||
+		form.Options["opt"] != "val" || !form.OverridePriceBand {
+		t.Fatalf("wrong replacement order %+v", form)
+	}
+
+	// Reducing an order that is partially filled before the cancel matches
+	// leaves the requested remainder.
+	tracker = newTracker(order.StandingTiF)
+	rig.queueCancel(nil)
+	whenCanceled(tracker, cancelMatched(tracker, 3*dcrBtcLotSize))
+	form, err = tCore.reduceOrder(dc, tracker, reduceTo)
+	if err != nil {
+		t.Fatalf("reduceOrder error: %v", err)
+	}
+	if form.Qty != reduceTo {
+		t.Fatalf("wrong replacement quantity %d, expected %d", form.Qty, reduceTo)
+	}
 
-	// If the live bond is closer to expiration, the expired bond won't be
-	// ready in time, so we'll need more reserves.
-	acct.bonds, acct.pendingBonds = acct.pendingBonds, nil
-	acct.bonds[0].LockTime = weakTimeThresh + 1
-	run(0, 1, bondAsset.Amt+bondFeeBuffer)
+	// Filled past the requested quantity, only the canceled remainder is
+	// replaced.
+	tracker = newTracker(order.StandingTiF)
+	rig.queueCancel(nil)
+	whenCanceled(tracker, cancelMatched(tracker, 8*dcrBtcLotSize))
+	form, err = tCore.reduceOrder(dc, tracker, reduceTo)
+	if err != nil {
+		t.Fatalf("reduceOrder error: %v", err)
+	}
+	if form.Qty != 2*dcrBtcLotSize {
+		t.Fatalf("wrong replacement quantity %d, expected %d", form.Qty, 2*dcrBtcLotSize)
+	}
 
-	// Make the live bond weak. Should get a pending bond. Only fees reserves,
-	// because we still have an expired bond.
-	acct.bonds[0].LockTime = weakTimeThresh - 1
-	rig.queuePrevalidateBond()
-	run(1, 1, bondFeeBuffer)
+	// Completely filled before the cancel matches.
+	tracker = newTracker(order.StandingTiF)
+	rig.queueCancel(nil)
+	whenCanceled(tracker, func() {
+		tracker.Trade().SetFill(qty)
+		tracker.metaData.Status = order.OrderStatusExecuted
+	})
+	ensureErr("filled", tracker, reduceTo, -1)
 
-	// Refund the expired bond
-	acct.expiredBonds[0].LockTime = locktimeRefundable
-	tDcrWallet.contractExpired = true
-	tDcrWallet.refundBondCoin = &tCoin{}
-	run(1, 0, bondAsset.Amt+bondFeeBuffer)
+	// The cancel order does not match.
+	tracker = newTracker(order.StandingTiF)
+	rig.queueCancel(nil)
+	whenCanceled(tracker, func() { tracker.cancel = nil })
+	ensureErr("nomatch", tracker, reduceTo, -1)
 
-	acct.targetTier = 2
-	acct.bonds = nil
-	rig.queuePrevalidateBond()
-	run(2, 0, bondAsset.Amt*2+bondFeeBuffer)
+	// The replacement is canceled when the original would have been.
+	tracker = newTracker(order.StandingTiF)
+	cancelAfter := time.Now().Add(time.Minute)
+	tracker.metaData.CancelAfter = uint64(cancelAfter.UnixMilli())
+	rig.queueCancel(nil)
+	whenCanceled(tracker, cancelMatched(tracker, 0))
+	form, err = tCore.reduceOrder(dc, tracker, reduceTo)
+	if err != nil {
+		t.Fatalf("reduceOrder error: %v", err)
+	}
+	if form.TTL < 59 || form.TTL > 60 {
+		t.Fatalf("wrong replacement TTL %d, expected 60", form.TTL)
+	}
 
-	// Check that a new bond will be scheduled for merge with an existing bond
-	// if the locktime is not too soon.
-	acct.bonds = append(acct.bonds, acct.pendingBonds[0])
-	acct.pendingBonds = nil
-	acct.bonds[0].LockTime = mergeableLocktimeThresh + 5
-	rig.queuePrevalidateBond()
-	run(1, 0, 2*bondAsset.Amt+bondFeeBuffer)
-	mergingBond := acct.pendingBonds[0]
-	if mergingBond.LockTime != acct.bonds[0].LockTime {
-		t.Fatalf("Mergeable bond was not merged")
+	// An order that has reached its time to live is not reduced.
+	tracker = newTracker(order.StandingTiF)
+	tracker.metaData.CancelAfter = uint64(time.Now().Add(-time.Second).UnixMilli())
+	ensureErr("expired", tracker, reduceTo, orderParamsErr)
+	if tracker.cancel != nil {
+		t.Fatalf("expired order canceled")
 	}
 
-	// Same thing, but without the merge, just to check our threshold calc.
-	acct.pendingBonds = nil
-	acct.bonds[0].LockTime = mergeableLocktimeThresh - 1
-	rig.queuePrevalidateBond()
-	run(1, 0, 2*bondAsset.Amt+bondFeeBuffer)
-	unmergingBond := acct.pendingBonds[0]
-	if unmergingBond.LockTime == acct.bonds[0].LockTime {
-		t.Fatalf("Unmergeable bond was scheduled for merged")
+	// The replacement cannot be placed after the original is canceled.
+	feed := tCore.NotificationFeed()
+	defer feed.ReturnFeed()
+	tracker = newTracker(order.StandingTiF)
+	oid := tracker.ID()
+	tDcrWallet.fundingCoinErr = tErr
+	rig.queueCancel(nil)
+	whenCanceled(tracker, cancelMatched(tracker, 0))
+	if _, err := tCore.ReduceOrder(tPW, oid[:], reduceTo); err == nil {
+		t.Fatalf("no error for failed replacement")
+	}
+	tDcrWallet.fundingCoinErr = nil
+	var reduceNote *OrderNote
+	for reduceNote == nil {
+		select {
+		case note := <-feed.C:
+			if n, ok := note.(*OrderNote); ok && n.Topic() == TopicOrderReduceFailed {
+				reduceNote = n
+			}
+		default:
+			t.Fatalf("no reduce failed notification")
+		}
+	}
+	if reduceNote.Severity() != db.ErrorLevel || !bytes.Equal(reduceNote.Order.ID, oid[:]) {
+		t.Fatalf("wrong reduce failed notification %+v", reduceNote)
+	}
+
+	// The cancel order does not match in time.
+	reduceOrderWaitEpochs = 0
+	tracker = newTracker(order.StandingTiF)
+	rig.queueCancel(nil)
+	ensureErr("timeout", tracker, reduceTo, -1)
+
+	// ReduceOrder checks the password and finds the order.
+	tracker = newTracker(order.StandingTiF)
+	oid = tracker.ID()
+	rig.crypter.(*tCrypter).recryptErr = tErr
+	if _, err := tCore.ReduceOrder(tPW, oid[:], reduceTo); !errorHasCode(err, passwordErr) {
+		t.Fatalf("expected password error, got %v", err)
+	}
+	rig.crypter.(*tCrypter).recryptErr = nil
+	if _, err := tCore.ReduceOrder(tPW, encode.RandomBytes(32), reduceTo); !errorHasCode(err, unknownOrderErr) {
+		t.Fatalf("expected unknown order error, got %v", err)
 	}
 }
 
-func TestFindBondKeyIdx(t *testing.T) {
+func TestBalanceAlert(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
-	rig.core.Login(tPW)
+	tCore := rig.core
 
-	pkhEqualFnFn := func(find bool) func(bondKey *secp256k1.PrivateKey) bool {
-		return func(bondKey *secp256k1.PrivateKey) bool {
-			return find
-		}
-	}
-	tests := []struct {
-		name       string
-		pkhEqualFn func(bondKey *secp256k1.PrivateKey) bool
-		wantErr    bool
-	}{{
-		name:       "ok",
-		pkhEqualFn: pkhEqualFnFn(true),
-	}, {
-		name:       "cant find",
-		pkhEqualFn: pkhEqualFnFn(false),
-		wantErr:    true,
-	}}
+	assetID := tUTXOAssetA.ID
+	wallet, tWallet := newTWallet(assetID)
+	tWallet.info.UnitInfo = tUTXOAssetA.UnitInfo
+	tCore.wallets[assetID] = wallet
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			_, err := rig.core.findBondKeyIdx(test.pkhEqualFn, 0)
-			if test.wantErr {
-				if err == nil {
-					t.Fatal("expected error")
+	feed := tCore.NotificationFeed()
+	alertNotes := func() (topics []Topic) {
+		t.Helper()
+		for {
+			select {
+			case note := <-feed.C:
+				if note.Topic() == TopicLowBalance || note.Topic() == TopicBalanceRestored {
+					topics = append(topics, note.Topic())
 				}
+			default:
 				return
 			}
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
-			}
-		})
+		}
 	}
-}
-
-func TestFindBond(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
-	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
-	rig.core.wallets[tUTXOAssetA.ID] = dcrWallet
-	rig.core.Login(tPW)
-
-	bd := &asset.BondDetails{
-		Bond: &asset.Bond{
-			Amount:  tFee,
-			AssetID: tUTXOAssetA.ID,
-		},
-		LockTime: time.Now(),
-		CheckPrivKey: func(bondKey *secp256k1.PrivateKey) bool {
-			return true
-		},
+	updateBalance := func(avail uint64) []Topic {
+		t.Helper()
+		tWallet.bal = &asset.Balance{Available: avail}
+		if _, err := tCore.updateWalletBalance(wallet); err != nil {
+			t.Fatalf("updateWalletBalance error: %v", err)
+		}
+		return alertNotes()
 	}
-	msgBond := &msgjson.Bond{
-		Version: 0,
-		AssetID: tUTXOAssetA.ID,
+
+	// Unsupported asset.
+	if err := tCore.SetBalanceAlert(987654, 1e8); !errorHasCode(err, assetSupportErr) {
+		t.Fatalf("wrong error for unsupported asset: %v", err)
 	}
 
-	tests := []struct {
-		name        string
-		findBond    *asset.BondDetails
-		findBondErr error
-		wantStr     uint32
-	}{{
-		name:     "ok",
-		findBond: bd,
-		wantStr:  1,
-	}, {
-		name:        "find bond error",
-		findBondErr: errors.New("some error"),
-	}}
+	// DB error.
+	rig.db.setBalanceAlertErr = tErr
+	if err := tCore.SetBalanceAlert(assetID, 1e8); !errorHasCode(err, dbErr) {
+		t.Fatalf("wrong error for db error: %v", err)
+	}
+	rig.db.setBalanceAlertErr = nil
 
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			tDcrWallet.findBond = test.findBond
-			tDcrWallet.findBondErr = test.findBondErr
-			str, _ := rig.core.findBond(rig.dc, msgBond)
-			if str != test.wantStr {
-				t.Fatalf("wanted str %d but got %d", test.wantStr, str)
-			}
-		})
+	// No alert set.
+	if topics := updateBalance(1); len(topics) != 0 {
+		t.Fatalf("unexpected alert notes with no alert set: %v", topics)
 	}
-}
 
-func TestNetworkFeeRate(t *testing.T) {
-	rig := newTestRig()
-	defer rig.shutdown()
+	// Setting an alert above the current balance warns immediately.
+	if err := tCore.SetBalanceAlert(assetID, 1e8); err != nil {
+		t.Fatalf("SetBalanceAlert error: %v", err)
+	}
+	if rig.db.balanceAlerts[assetID] != 1e8 {
+		t.Fatalf("balance alert not stored")
+	}
+	if alerts := tCore.BalanceAlerts(); alerts[assetID] != 1e8 {
+		t.Fatalf("wrong balance alerts %v", alerts)
+	}
+	if topics := alertNotes(); len(topics) != 1 || topics[0] != TopicLowBalance {
+		t.Fatalf("expected a low balance note, got %v", topics)
+	}
 
-	assetID := tUTXOAssetA.ID
-	wallet, tWallet := newTWallet(assetID)
-	rig.core.wallets[assetID] = wallet
+	// No repeated warning while the balance stays low.
+	if topics := updateBalance(5e7); len(topics) != 0 {
+		t.Fatalf("repeated alert notes while low: %v", topics)
+	}
 
-	const feeRaterRate = 50
-	dumbWallet := wallet.Wallet
-	wallet.Wallet = &TFeeRater{
-		TXCWallet: tWallet,
-		feeRate:   feeRaterRate,
+	// Crossing above.
+	if topics := updateBalance(1e8); len(topics) != 1 || topics[0] != TopicBalanceRestored {
+		t.Fatalf("expected a balance restored note, got %v", topics)
 	}
-	if r := rig.core.NetworkFeeRate(assetID); r != feeRaterRate {
-		t.Fatalf("FeeRater not working. %d != %d", r, feeRaterRate)
+	if topics := updateBalance(2e8); len(topics) != 0 {
+		t.Fatalf("repeated alert notes while restored: %v", topics)
 	}
-	wallet.Wallet = dumbWallet
 
-	const bookFeedFeeRate = 60
-	book := newBookie(rig.dc, assetID, tUTXOAssetB.ID, nil, tLogger)
-	rig.dc.books[tDcrBtcMktName] = book
-	book.logEpochReport(&msgjson.EpochReportNote{BaseFeeRate: bookFeedFeeRate})
-	if r := rig.core.NetworkFeeRate(assetID); r != bookFeedFeeRate {
-		t.Fatalf("Book feed fee rate not working. %d != %d", r, bookFeedFeeRate)
+	// Crossing below.
+	if topics := updateBalance(1e8 - 1); len(topics) != 1 || topics[0] != TopicLowBalance {
+		t.Fatalf("expected a low balance note, got %v", topics)
 	}
-	delete(rig.dc.books, tDcrBtcMktName)
 
-	const serverFeeRate = 70
-	rig.ws.queueResponse(msgjson.FeeRateRoute, func(msg *msgjson.Message, f msgFunc) error {
-		resp, _ := msgjson.NewResponse(msg.ID, serverFeeRate, nil)
-		f(resp)
-		return nil
-	})
-	if r := rig.core.NetworkFeeRate(assetID); r != serverFeeRate {
-		t.Fatalf("Server fee rate not working. %d != %d", r, serverFeeRate)
+	// Removing the alert.
+	if err := tCore.SetBalanceAlert(assetID, 0); err != nil {
+		t.Fatalf("SetBalanceAlert error: %v", err)
+	}
+	if _, found := rig.db.balanceAlerts[assetID]; found {
+		t.Fatalf("balance alert not deleted")
+	}
+	if topics := updateBalance(2e8); len(topics) != 0 {
+		t.Fatalf("alert notes after removing the alert: %v", topics)
+	}
+	if topics := updateBalance(1); len(topics) != 0 {
+		t.Fatalf("alert notes after removing the alert: %v", topics)
 	}
 }
 
-func TestPokesCacheInit(t *testing.T) {
-	tPokes := []*db.Notification{
-		{DetailText: "poke 1"},
-		{DetailText: "poke 2"},
-		{DetailText: "poke 3"},
-		{DetailText: "poke 4"},
-		{DetailText: "poke 5"},
+type TGapLimiter struct {
+	*TXCWallet
+	gapLimit  uint32
+	extendErr error
+}
+
+func (w *TGapLimiter) GapLimit() (uint32, error) {
+	return w.gapLimit, nil
+}
+
+func (w *TGapLimiter) ExtendGapLimit(n uint32) (uint32, error) {
+	if w.extendErr != nil {
+		return 0, w.extendErr
 	}
-	{
-		pokesCapacity := 6
-		c := newPokesCache(pokesCapacity)
-		c.init(tPokes)
+	w.gapLimit += n
+	return w.gapLimit, nil
+}
 
-		// Check if the cache is initialized correctly
-		if len(c.cache) != 5 {
-			t.Errorf("Expected cache length %d, got %d", len(tPokes), len(c.cache))
-		}
+func TestExtendGapLimit(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-		if c.cursor != 5 {
-			t.Errorf("Expected cursor %d, got %d", len(tPokes)%pokesCapacity, c.cursor)
-		}
+	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
 
-		// Check if the cache contains the correct pokes
-		for i, poke := range tPokes {
-			if c.cache[i] != poke {
-				t.Errorf("Expected poke %v at index %d, got %v", poke, i, c.cache[i])
-			}
-		}
+	// The wallet does not have a gap limit.
+	if _, err := tCore.GapLimit(tUTXOAssetA.ID); err == nil {
+		t.Fatalf("no error for a wallet without a gap limit")
+	}
+	if _, err := tCore.ExtendGapLimit(tUTXOAssetA.ID, 10); err == nil {
+		t.Fatalf("no error extending the gap limit of a wallet without one")
 	}
-	{
-		pokesCapacity := 4
-		c := newPokesCache(pokesCapacity)
-		c.init(tPokes)
 
-		// Check if the cache is initialized correctly
-		if len(c.cache) != 1 {
-			t.Errorf("Expected cache length %d, got %d", 1, len(c.cache))
-		}
+	gapLimiter := &TGapLimiter{TXCWallet: tWallet, gapLimit: 250}
+	wallet.Wallet = gapLimiter
+	rig.db.wallet = &db.Wallet{AssetID: tUTXOAssetA.ID}
 
-		if c.cursor != 1 {
-			t.Errorf("Expected cursor %d, got %d", 1, c.cursor)
-		}
+	if _, err := tCore.GapLimit(tUTXOAssetB.ID); err == nil {
+		t.Fatalf("no error for missing wallet")
+	}
+	gapLimit, err := tCore.GapLimit(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("GapLimit error: %v", err)
+	}
+	if gapLimit != 250 {
+		t.Fatalf("wrong gap limit. wanted 250, got %d", gapLimit)
+	}
 
-		// Check if the cache contains the correct pokes
-		for i, poke := range tPokes[:len(tPokes)-pokesCapacity] {
-			if c.cache[i] != poke {
-				t.Errorf("Expected poke %v at index %d, got %v", poke, i, c.cache[i])
-			}
-		}
+	gapLimiter.extendErr = tErr
+	if _, err := tCore.ExtendGapLimit(tUTXOAssetA.ID, 10); err == nil {
+		t.Fatalf("no error for wallet error")
+	}
+	gapLimiter.extendErr = nil
+
+	// Not allowed with active orders.
+	rig.dc.trades[order.OrderID{}] = &trackedTrade{
+		Order:    &order.LimitOrder{P: order.Prefix{BaseAsset: tUTXOAssetA.ID}},
+		wallets:  &walletSet{fromWallet: wallet, toWallet: wallet},
+		metaData: &db.OrderMetaData{Status: order.OrderStatusBooked},
+	}
+	if _, err := tCore.ExtendGapLimit(tUTXOAssetA.ID, 10); !errorHasCode(err, activeOrdersErr) {
+		t.Fatalf("wrong error with active orders: %v", err)
+	}
+	delete(rig.dc.trades, order.OrderID{})
+
+	gapLimit, err = tCore.ExtendGapLimit(tUTXOAssetA.ID, 10)
+	if err != nil {
+		t.Fatalf("ExtendGapLimit error: %v", err)
+	}
+	if gapLimit != 260 {
+		t.Fatalf("wrong extended gap limit. wanted 260, got %d", gapLimit)
+	}
+	if setting := rig.db.wallet.Settings[gapLimitSetting]; setting != "260" {
+		t.Fatalf("gap limit not stored. setting = %q", setting)
 	}
 }
 
-func TestPokesAdd(t *testing.T) {
-	tPokes := []*db.Notification{
-		{DetailText: "poke 1"},
-		{DetailText: "poke 2"},
-		{DetailText: "poke 3"},
-		{DetailText: "poke 4"},
-		{DetailText: "poke 5"},
+func TestGenerateDEXMapsDisplaySymbols(t *testing.T) {
+	unitInfo := func(unit string) dex.UnitInfo {
+		return dex.UnitInfo{Conventional: dex.Denomination{Unit: unit, ConversionFactor: 1e6}}
 	}
-	tNewPoke := &db.Notification{
-		DetailText: "poke 6",
+	cfg := &msgjson.ConfigResult{
+		Assets: []*msgjson.Asset{
+			{Symbol: "usdc.eth", ID: 60001, UnitInfo: unitInfo("USDC")},
+			{Symbol: "usdc.polygon", ID: 966001, UnitInfo: unitInfo("USDC"), DisplaySymbol: "USDC.p", Aliases: []string{"pUSDC"}},
+		},
+	}
+	// The display symbols are encoded in the config response.
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("error encoding config: %v", err)
+	}
+	cfg = new(msgjson.ConfigResult)
+	if err := json.Unmarshal(b, cfg); err != nil {
+		t.Fatalf("error decoding config: %v", err)
 	}
-	{
-		pokesCapacity := 6
-		c := newPokesCache(pokesCapacity)
-		c.init(tPokes)
-		c.add(tNewPoke)
 
-		// Check if the cache is updated correctly
-		if len(c.cache) != 6 {
-			t.Errorf("Expected cache length %d, got %d", len(tPokes), len(c.cache))
-		}
+	assets, _, err := generateDEXMaps(tDexHost, cfg)
+	if err != nil {
+		t.Fatalf("generateDEXMaps error: %v", err)
+	}
+	usdcP := assets[966001]
+	if usdcP.DisplayTicker() != "USDC.p" || !reflect.DeepEqual(usdcP.Aliases, []string{"pUSDC"}) {
+		t.Fatalf("wrong display symbol %q or aliases %v", usdcP.DisplaySymbol, usdcP.Aliases)
+	}
+	if ticker := assets[60001].DisplayTicker(); ticker != "USDC" {
+		t.Fatalf("wrong default display ticker %q", ticker)
+	}
 
-		if c.cursor != 0 {
-			t.Errorf("Expected cursor %d, got %d", 0, c.cursor)
-		}
+	// A server that displays one asset with the ticker of another is rejected.
+	cfg.Assets[1].DisplaySymbol = "usdc"
+	if _, _, err := generateDEXMaps(tDexHost, cfg); err == nil {
+		t.Fatalf("no error for ambiguous display symbol")
+	}
 
-		// Check if the cache contains the correct pokes
-		tAllPokes := append(tPokes, tNewPoke)
-		for i, poke := range tAllPokes {
-			if c.cache[i] != poke {
-				t.Errorf("Expected poke %v at index %d, got %v", poke, i, c.cache[i])
-			}
-		}
+	// So is one that displays an asset with the symbol of an asset that the
+	// client supports but the server does not list.
+	cfg.Assets = cfg.Assets[:1]
+	cfg.Assets[0].DisplaySymbol = "BTC"
+	if _, _, err := generateDEXMaps(tDexHost, cfg); err == nil {
+		t.Fatalf("no error for display symbol of a registered asset")
 	}
-	{
-		pokesCapacity := 5
-		c := newPokesCache(pokesCapacity)
-		c.init(tPokes)
-		c.add(tNewPoke)
+	cfg.Assets[0].DisplaySymbol = ""
+	cfg.Assets[0].Aliases = []string{tACCTAsset.Symbol}
+	if _, _, err := generateDEXMaps(tDexHost, cfg); err == nil {
+		t.Fatalf("no error for alias of a registered asset")
+	}
+}
 
-		// Check if the cache is updated correctly
-		if len(c.cache) != pokesCapacity {
-			t.Errorf("Expected cache length %d, got %d", pokesCapacity, len(c.cache))
-		}
+func TestAssetDisplaySymbols(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
 
-		if c.cursor != 1 {
-			t.Errorf("Expected cursor %d, got %d", 1, c.cursor)
-		}
+	assetID := tUTXOAssetA.ID
+	setDisplay := func(dc *dexConnection, displaySymbol string, aliases ...string) {
+		a := *tUTXOAssetA
+		a.DisplaySymbol = displaySymbol
+		a.Aliases = aliases
+		dc.assetsMtx.Lock()
+		dc.assets[assetID] = &a
+		dc.assetsMtx.Unlock()
+	}
+	setDisplay(rig.dc, "DCR.x", "xDCR")
 
-		// Check if the cache contains the correct pokes
-		tAllPokes := make([]*db.Notification, 0)
-		tAllPokes = append(tAllPokes, tNewPoke)
-		tAllPokes = append(tAllPokes, tPokes[1:]...)
-		for i, poke := range tAllPokes {
-			if c.cache[i] != poke {
-				t.Errorf("Expected poke %v at index %d, got %v", poke, i, c.cache[i])
-			}
-		}
+	wantDisplay := map[string]*AssetDisplay{tDexHost: {Symbol: "DCR.x", Aliases: []string{"xDCR"}}}
+	if display := tCore.SupportedAssets()[assetID].Display; !reflect.DeepEqual(display, wantDisplay) {
+		t.Fatalf("wrong display %+v", display)
+	}
+	if display := tCore.asset(assetID).Display; !reflect.DeepEqual(display, wantDisplay) {
+		t.Fatalf("wrong display %+v for single asset", display)
+	}
+	// Assets without a configured display symbol have none.
+	if display := tCore.SupportedAssets()[tUTXOAssetB.ID].Display; display != nil {
+		t.Fatalf("unexpected display %+v", display)
 	}
-}
 
-func TestPokesCachePokes(t *testing.T) {
-	tPokes := []*db.Notification{
-		{TimeStamp: 1, DetailText: "poke 1"},
-		{TimeStamp: 2, DetailText: "poke 2"},
-		{TimeStamp: 3, DetailText: "poke 3"},
-		{TimeStamp: 4, DetailText: "poke 4"},
-		{TimeStamp: 5, DetailText: "poke 5"},
+	// The wallet state reports the display symbols.
+	wallet, tWallet := newTWallet(assetID)
+	wallet.display = func() map[string]*AssetDisplay {
+		return tCore.assetDisplay(assetID)
+	}
+	tCore.wallets[assetID] = wallet
+	if display := wallet.state().Display; !reflect.DeepEqual(display, wantDisplay) {
+		t.Fatalf("wrong wallet state display %+v", display)
 	}
-	{
-		pokesCapacity := 6
-		c := newPokesCache(pokesCapacity)
-		c.init(tPokes)
-		pokes := c.pokes()
 
-		// Check if the result length is correct
-		if len(pokes) != len(tPokes) {
-			t.Errorf("Expected pokes length %d, got %d", len(tPokes), len(pokes))
-		}
+	// Address validation errors name the asset by its display symbol.
+	tWallet.validAddr = false
+	_, err := tCore.SendWithFeeLimit(tPW, assetID, "addr", 1e8, 1e5, false)
+	if err == nil || !strings.Contains(err.Error(), "DCR.x") {
+		t.Fatalf("wrong error for invalid address: %v", err)
+	}
 
-		// Check if the result contains the correct pokes
-		for i, poke := range tPokes {
-			if pokes[i] != poke {
-				t.Errorf("Expected poke %v at index %d, got %v", poke, i, pokes[i])
-			}
-		}
+	// Another server may display the asset differently. Each server's display
+	// symbol is reported under its host, and neither is used for messages
+	// outside of a server's context.
+	dc2, _, acct2 := testDexConnection(tCore.ctx, rig.crypter.(*tCrypter))
+	acct2.host = "someotherhost.com"
+	setDisplay(dc2, "DCR.y")
+	tCore.connMtx.Lock()
+	tCore.conns[acct2.host] = dc2
+	tCore.connMtx.Unlock()
+	wantDisplay[acct2.host] = &AssetDisplay{Symbol: "DCR.y"}
+	if display := tCore.SupportedAssets()[assetID].Display; !reflect.DeepEqual(display, wantDisplay) {
+		t.Fatalf("wrong display %+v for two hosts", display)
 	}
-	{
-		pokesCapacity := 5
-		tNewPoke := &db.Notification{
-			TimeStamp:  6,
-			DetailText: "poke 6",
-		}
-		c := newPokesCache(pokesCapacity)
-		c.init(tPokes)
-		c.add(tNewPoke)
-		pokes := c.pokes()
+	_, err = tCore.SendWithFeeLimit(tPW, assetID, "addr", 1e8, 1e5, false)
+	if err == nil || strings.Contains(err.Error(), "DCR.") || !strings.Contains(err.Error(), unbip(assetID)) {
+		t.Fatalf("wrong error for invalid address with conflicting display symbols: %v", err)
+	}
+}
 
-		// Check if the result length is correct
-		if len(pokes) != pokesCapacity {
-			t.Errorf("Expected cache length %d, got %d", 1, len(pokes))
-		}
+type TCoinLockLister struct {
+	*TXCWallet
+	locked    asset.Coins
+	listedErr error
+}
 
-		tAllPokes := append(tPokes[1:], tNewPoke)
-		// Check if the result contains the correct pokes
-		for i, poke := range tAllPokes {
-			if pokes[i] != poke {
-				t.Errorf("Expected poke %v at index %d, got %v", poke, i, pokes[i])
-			}
-		}
-	}
+func (w *TCoinLockLister) LockedCoins() (asset.Coins, error) {
+	return w.locked, w.listedErr
 }
 
-func TestTakeAction(t *testing.T) {
+func TestReleaseOrphanedLocks(t *testing.T) {
 	rig := newTestRig()
 	defer rig.shutdown()
+	tCore := rig.core
 
-	coinID := encode.RandomBytes(32)
-	uniqueID := dex.Bytes(coinID).String()
+	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
 
-	newMatch := func() *matchTracker {
-		var matchID order.MatchID
-		copy(matchID[:], encode.RandomBytes(32))
-		return &matchTracker{
-			MetaMatch: db.MetaMatch{
-				UserMatch: &order.UserMatch{
-					Status:  order.MatchComplete,
-					MatchID: matchID,
-					Side:    order.Taker,
-				},
-				MetaData: &db.MatchMetaData{},
-			},
-		}
+	// The wallet cannot list locked coins.
+	if _, err := tCore.LockedCoins(tUTXOAssetA.ID); err == nil {
+		t.Fatalf("no error for a wallet that cannot list locked coins")
+	}
+	if _, err := tCore.ReleaseOrphanedLocks(tUTXOAssetA.ID); err == nil {
+		t.Fatalf("no error releasing locks of a wallet that cannot list them")
 	}
-	rightMatch := newMatch()
-	rightMatch.MetaData.Proof.TakerRedeem = coinID
-	rightMatch.redemptionRejected = true
 
-	wrongMatch := newMatch()
-	wrongMatch.MetaData.Proof.TakerRedeem = encode.RandomBytes(31)
+	orphan := &tCoin{id: encode.RandomBytes(36), val: 1e8}
+	funding := &tCoin{id: encode.RandomBytes(36), val: 2e8}
+	lister := &TCoinLockLister{TXCWallet: tWallet, locked: asset.Coins{orphan, funding}}
+	wallet.Wallet = lister
 
-	makerMatch := newMatch()
-	makerMatch.Status = order.MakerRedeemed
-	makerMatch.MetaData.Proof.MakerRedeem = coinID
-	makerMatch.Side = order.Maker
+	// A booked order is funded with one of the locked coins.
+	lo, _, preImg, _ := makeLimitOrder(rig.dc, true, 0, 0)
+	oid := lo.ID()
+	rig.dc.trades[oid] = &trackedTrade{
+		Order:       lo,
+		preImg:      preImg,
+		mktID:       tDcrBtcMktName,
+		db:          rig.db,
+		dc:          rig.dc,
+		fromAssetID: tUTXOAssetA.ID,
+		coins:       map[string]asset.Coin{funding.String(): funding},
+		metaData:    &db.OrderMetaData{Status: order.OrderStatusBooked},
+	}
 
-	tracker := &trackedTrade{
-		matches: map[order.MatchID]*matchTracker{
-			rightMatch.MatchID: rightMatch,
-			wrongMatch.MatchID: wrongMatch,
-			makerMatch.MatchID: makerMatch,
-		},
+	lockedCoins, err := tCore.LockedCoins(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("LockedCoins error: %v", err)
+	}
+	if len(lockedCoins) != 2 {
+		t.Fatalf("expected 2 locked coins, got %d", len(lockedCoins))
+	}
+	for _, lc := range lockedCoins {
+		switch {
+		case bytes.Equal(lc.ID, orphan.id):
+			if len(lc.OrderID) != 0 {
+				t.Fatalf("orphaned coin tied to order %s", lc.OrderID)
+			}
+		case bytes.Equal(lc.ID, funding.id):
+			if !bytes.Equal(lc.OrderID, oid[:]) {
+				t.Fatalf("funding coin tied to wrong order %s", lc.OrderID)
+			}
+		default:
+			t.Fatalf("unknown locked coin %s", lc.Coin)
+		}
 	}
 
-	var oid order.OrderID
-	copy(oid[:], encode.RandomBytes(32))
+	// Nothing is released while an order is being funded or submitted.
+	fundingDone := tCore.startFunding(tUTXOAssetA.ID)
+	if _, err := tCore.ReleaseOrphanedLocks(tUTXOAssetA.ID); err == nil {
+		t.Fatalf("no error releasing locks with an order being funded")
+	}
+	fundingDone()
+	fundingDone() // only ends the funding once
+	if tWallet.returnedCoins != nil {
+		t.Fatalf("coins returned with an order being funded")
+	}
+	if n := tCore.funding[tUTXOAssetA.ID]; n != 0 {
+		t.Fatalf("funding count %d after funding done", n)
+	}
 
-	rig.dc.trades[oid] = tracker
+	// Wallet error.
+	lister.listedErr = tErr
+	if _, err := tCore.ReleaseOrphanedLocks(tUTXOAssetA.ID); err == nil {
+		t.Fatalf("no error for wallet error")
+	}
+	lister.listedErr = nil
 
-	requestData := []byte(fmt.Sprintf(`{"orderID":"abcd","coinID":"%s","retry":true}`, dex.Bytes(coinID)))
+	// Only the orphaned coin is released.
+	released, err := tCore.ReleaseOrphanedLocks(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("ReleaseOrphanedLocks error: %v", err)
+	}
+	if len(released) != 1 || !bytes.Equal(released[0].ID, orphan.id) {
+		t.Fatalf("wrong coins released: %+v", released)
+	}
+	if len(tWallet.returnedCoins) != 1 || !bytes.Equal(tWallet.returnedCoins[0].ID(), orphan.id) {
+		t.Fatalf("wrong coins returned to the wallet: %v", tWallet.returnedCoins)
+	}
 
-	err := rig.core.TakeAction(0, ActionIDRedeemRejected, requestData)
-	if err == nil {
-		t.Fatalf("expected error for wrong order ID but got nothing")
+	// Nothing is released if every lock backs an order.
+	tWallet.returnedCoins = nil
+	lister.locked = asset.Coins{funding}
+	released, err = tCore.ReleaseOrphanedLocks(tUTXOAssetA.ID)
+	if err != nil {
+		t.Fatalf("ReleaseOrphanedLocks error with no orphans: %v", err)
 	}
+	if len(released) != 0 || tWallet.returnedCoins != nil {
+		t.Fatalf("coins released with no orphans")
+	}
+}
 
-	rig.core.requestedActions[uniqueID] = nil
-	requestData = []byte(fmt.Sprintf(`{"orderID":"%s","coinID":"%s","retry":false}`, oid, dex.Bytes(coinID)))
+type TLegacyAddresser struct {
+	*TXCWallet
+	legacyAddr string
+}
 
-	err = rig.core.TakeAction(0, ActionIDRedeemRejected, requestData)
-	if err != nil {
-		t.Fatalf("error for retry=false: %v", err)
+func (w *TLegacyAddresser) LegacyAddress(addr string) (string, error) {
+	return w.legacyAddr, nil
+}
+
+func TestLegacyDepositAddress(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	wallet, tWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = wallet
+
+	// The wallet has no legacy address format.
+	if _, err := tCore.LegacyDepositAddress(tUTXOAssetA.ID, "addr"); err == nil {
+		t.Fatalf("no error for a wallet without a legacy address format")
 	}
-	if len(rig.core.requestedActions) != 0 {
-		t.Fatal("requested action not removed")
+
+	wallet.Wallet = &TLegacyAddresser{TXCWallet: tWallet, legacyAddr: "legacyaddr"}
+
+	if _, err := tCore.LegacyDepositAddress(tUTXOAssetB.ID, "addr"); err == nil {
+		t.Fatalf("no error for missing wallet")
 	}
 
-	requestData = []byte(fmt.Sprintf(`{"orderID":"%s","coinID":"%s","retry":true}`, oid, dex.Bytes(coinID)))
-	err = rig.core.TakeAction(0, ActionIDRedeemRejected, requestData)
+	legacyAddr, err := tCore.LegacyDepositAddress(tUTXOAssetA.ID, "addr")
 	if err != nil {
-		t.Fatalf("error for taker retry=true: %v", err)
+		t.Fatalf("LegacyDepositAddress error: %v", err)
+	}
+	if legacyAddr != "legacyaddr" {
+		t.Fatalf("wrong legacy address %s", legacyAddr)
 	}
 
-	if len(rightMatch.MetaData.Proof.TakerRedeem) != 0 {
-		t.Fatalf("taker redemption not cleared")
+	// Only addresses of the wallet can be converted.
+	tWallet.ownsAddress = false
+	if _, err := tCore.LegacyDepositAddress(tUTXOAssetA.ID, "addr"); err == nil {
+		t.Fatalf("no error for an address not owned by the wallet")
 	}
-	if len(wrongMatch.MetaData.Proof.TakerRedeem) == 0 {
-		t.Fatalf("wrong taker redemption cleared")
+	tWallet.ownsAddress = true
+	tWallet.ownsAddressErr = tErr
+	if _, err := tCore.LegacyDepositAddress(tUTXOAssetA.ID, "addr"); err == nil {
+		t.Fatalf("no error for wallet error")
 	}
+}
 
-	makerMatch.redemptionRejected = true
-	err = rig.core.TakeAction(0, ActionIDRedeemRejected, requestData)
-	if err != nil {
-		t.Fatalf("error for maker retry=true: %v", err)
+func TestNegotiateFeatures(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+
+	var req msgjson.FeaturesRequest
+	rig.ws.queueResponse(msgjson.FeaturesRoute, func(msg *msgjson.Message, f msgFunc) error {
+		if err := msg.Unmarshal(&req); err != nil {
+			t.Fatalf("error decoding features request: %v", err)
+		}
+		resp, _ := msgjson.NewResponse(msg.ID, &msgjson.FeaturesResult{Features: req.Features[:1]}, nil)
+		f(resp)
+		return nil
+	})
+	rig.dc.negotiateFeatures()
+	if len(req.Features) != len(clientFeatures) {
+		t.Fatalf("wrong features advertised: %v", req.Features)
 	}
-	if len(makerMatch.MetaData.Proof.MakerRedeem) != 0 {
-		t.Fatalf("maker redemption not cleared")
+	for i, feature := range clientFeatures {
+		if req.Features[i] != feature {
+			t.Fatalf("wrong feature advertised: %s != %s", req.Features[i], feature)
+		}
+	}
+
+	// A server that predates feature negotiation does not know the route.
+	rig.ws.queueResponse(msgjson.FeaturesRoute, func(msg *msgjson.Message, f msgFunc) error {
+		resp, _ := msgjson.NewResponse(msg.ID, nil, msgjson.NewError(msgjson.RPCUnknownRoute, "unknown route"))
+		f(resp)
+		return nil
+	})
+	rig.dc.negotiateFeatures()
+}
+
+func TestEstimateSettlementTime(t *testing.T) {
+	rig := newTestRig()
+	defer rig.shutdown()
+	tCore := rig.core
+
+	dcrWallet, tDcrWallet := newTWallet(tUTXOAssetA.ID)
+	tCore.wallets[tUTXOAssetA.ID] = dcrWallet
+	tDcrWallet.info.BlockInterval = 5 * time.Minute
+	btcWallet, tBtcWallet := newTWallet(tUTXOAssetB.ID)
+	tCore.wallets[tUTXOAssetB.ID] = btcWallet
+	tBtcWallet.info.BlockInterval = 10 * time.Minute
+	ethWallet, tEthWallet := newTWallet(tACCTAsset.ID)
+	tCore.wallets[tACCTAsset.ID] = ethWallet
+	tEthWallet.info.BlockInterval = 12 * time.Second
+	tEthWallet.info.IsAccountBased = true
+	feeRater := &TFeeRater{TXCWallet: tEthWallet, feeRate: tACCTAsset.MaxFeeRate}
+	ethWallet.Wallet = feeRater
+
+	btcCfg := *tUTXOAssetB
+	btcCfg.SwapConf = 3
+	rig.dc.assetsMtx.Lock()
+	rig.dc.assets[tUTXOAssetB.ID] = &btcCfg
+	rig.dc.assetsMtx.Unlock()
+
+	check := func(tag string, base, quote uint32, minMS, expMS, maxMS uint64) {
+		t.Helper()
+		est, err := tCore.EstimateSettlementTime(tDexHost, base, quote)
+		if err != nil {
+			t.Fatalf("%s: EstimateSettlementTime error: %v", tag, err)
+		}
+		if est.MinMS != minMS || est.ExpectedMS != expMS || est.MaxMS != maxMS {
+			t.Fatalf("%s: wanted %d < %d < %d, got %d < %d < %d", tag,
+				minMS, expMS, maxMS, est.MinMS, est.ExpectedMS, est.MaxMS)
+		}
 	}
 
+	// dcr needs 2 blocks of 5 minutes and btc needs 4 blocks of 10 minutes.
+	// The variance is 2 * 300^2 + 4 * 600^2 s^2, for a deviation of 1272.8 s.
+	check("dcr_btc", tUTXOAssetA.ID, tUTXOAssetB.ID, 454_416, 3_000_000, 5_545_584)
+
+	// eth blocks are regular, and only add to the expected time.
+	check("btc_eth", tUTXOAssetB.ID, tACCTAsset.ID, 24_000, 2_424_000, 4_824_000)
+
+	// With the fee rate at twice the max fee rate, the base fee needs 6 blocks
+	// to drop below the max fee rate.
+	feeRater.feeRate = tACCTAsset.MaxFeeRate * 2
+	check("btc_eth high fees", tUTXOAssetB.ID, tACCTAsset.ID, 96_000, 2_496_000, 4_896_000)
+
+	// Unknown market.
+	if _, err := tCore.EstimateSettlementTime(tDexHost, tUTXOAssetA.ID, tACCTAsset.ID); err == nil {
+		t.Fatalf("no error for unknown market")
+	}
+	// Unknown host.
+	if _, err := tCore.EstimateSettlementTime("unknown.dex", tUTXOAssetA.ID, tUTXOAssetB.ID); err == nil {
+		t.Fatalf("no error for unknown host")
+	}
+	// Unknown block interval.
+	tDcrWallet.info.BlockInterval = 0
+	if _, err := tCore.EstimateSettlementTime(tDexHost, tUTXOAssetA.ID, tUTXOAssetB.ID); err == nil {
+		t.Fatalf("no error for unknown block interval")
+	}
 }
@@ -0,0 +1,340 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/client/db"
+)
+
+// DepositAddressPolicy specifies when a wallet's deposit address should be
+// replaced with a fresh one.
+type DepositAddressPolicy = db.DepositAddressPolicy
+
+// DepositAddress is a deposit address issued while a DepositAddressPolicy is
+// in effect, and the amount received to it.
+type DepositAddress = db.DepositAddress
+
+// depositAddressRotator applies a DepositAddressPolicy for a single wallet,
+// and tracks the addresses it has issued.
+type depositAddressRotator struct {
+	assetID uint32
+	policy  DepositAddressPolicy
+	cancel  context.CancelFunc
+
+	mtx     sync.Mutex
+	stopped bool
+	addrs   []*DepositAddress
+	txs     map[string]bool
+}
+
+func newDepositAddressRotator(assetID uint32, policy *DepositAddressPolicy, currentAddr string) *depositAddressRotator {
+	r := &depositAddressRotator{
+		assetID: assetID,
+		policy:  *policy,
+		txs:     make(map[string]bool),
+	}
+	if currentAddr != "" {
+		r.track(currentAddr)
+	}
+	return r
+}
+
+// depositAddressRotatorFromRecord restores a depositAddressRotator from its
+// stored record.
+func depositAddressRotatorFromRecord(rec *db.DepositAddressRecord) *depositAddressRotator {
+	r := &depositAddressRotator{
+		assetID: rec.AssetID,
+		policy:  rec.DepositAddressPolicy,
+		addrs:   rec.Addresses,
+		txs:     make(map[string]bool, len(rec.Credited)),
+	}
+	for _, txID := range rec.Credited {
+		r.txs[txID] = true
+	}
+	return r
+}
+
+// track records a newly issued deposit address. track returns true if the
+// address was not already tracked.
+func (r *depositAddressRotator) track(addr string) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for _, a := range r.addrs {
+		if a.Address == addr {
+			return false
+		}
+	}
+	r.addrs = append(r.addrs, &DepositAddress{
+		Address: addr,
+		Stamp:   uint64(time.Now().UnixMilli()),
+	})
+	return true
+}
+
+// credit attributes a received amount to the deposit address, if the address
+// is tracked and the transaction has not already been credited. credit
+// returns true if the deposit was attributed to a tracked address.
+func (r *depositAddressRotator) credit(addr, txID string, amt uint64) bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.txs[txID] {
+		return false
+	}
+	for _, a := range r.addrs {
+		if a.Address == addr {
+			r.txs[txID] = true
+			a.Received += amt
+			a.Deposits++
+			return true
+		}
+	}
+	return false
+}
+
+// addresses returns copies of the tracked deposit addresses, oldest first.
+func (r *depositAddressRotator) addresses() []*DepositAddress {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	addrs := make([]*DepositAddress, 0, len(r.addrs))
+	for _, a := range r.addrs {
+		a := *a
+		addrs = append(addrs, &a)
+	}
+	return addrs
+}
+
+// store saves the policy and the tracked addresses to the DB. Nothing is
+// stored once the rotator is stopped, so that a replaced or disabled policy is
+// not written back.
+func (r *depositAddressRotator) store(dexDB db.DB) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.stopped {
+		return nil
+	}
+	rec := &db.DepositAddressRecord{
+		AssetID:              r.assetID,
+		DepositAddressPolicy: r.policy,
+		Addresses:            r.addrs,
+		Credited:             make([]string, 0, len(r.txs)),
+	}
+	for txID := range r.txs {
+		rec.Credited = append(rec.Credited, txID)
+	}
+	sort.Strings(rec.Credited)
+	return dexDB.SaveDepositAddressRecord(rec)
+}
+
+// stop stops scheduled rotation, and prevents any further storage of the
+// rotator.
+func (r *depositAddressRotator) stop() {
+	r.mtx.Lock()
+	r.stopped = true
+	r.mtx.Unlock()
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+// SetDepositAddressPolicy sets the deposit address rotation policy for the
+// wallet. A fresh deposit address is requested at the policy's Interval, if
+// non-zero, and after each detected deposit to the current deposit address if
+// OnDeposit is set. A nil or empty policy disables rotation. Addresses issued
+// while a policy is in effect are tracked so that deposits are attributed to
+// the address they were received at. See DepositAddresses. The policy and the
+// tracked addresses are stored, and the policy is resumed when Core is
+// restarted. For wallets that do not generate new addresses, the policy is a
+// no-op.
+func (c *Core) SetDepositAddressPolicy(assetID uint32, policy *DepositAddressPolicy) error {
+	w, exists := c.wallet(assetID)
+	if !exists {
+		return newError(missingWalletErr, "no wallet found for %s", unbip(assetID))
+	}
+	if policy != nil && policy.Interval < 0 {
+		return newError(walletErr, "negative deposit address rotation interval")
+	}
+
+	c.depositRotatorsMtx.Lock()
+	defer c.depositRotatorsMtx.Unlock()
+	if r := c.depositRotators[assetID]; r != nil {
+		r.stop()
+		delete(c.depositRotators, assetID)
+	}
+
+	if policy == nil || (policy.Interval == 0 && !policy.OnDeposit) {
+		if err := c.db.DeleteDepositAddressRecord(assetID); err != nil {
+			return fmt.Errorf("error deleting stored deposit address policy: %w", err)
+		}
+		c.log.Infof("Deposit address rotation disabled for %s wallet", unbip(assetID))
+		return nil
+	}
+
+	if _, ok := w.Wallet.(asset.NewAddresser); !ok {
+		c.log.Warnf("%s wallet does not generate new addresses. Deposit address policy will not be applied.",
+			unbip(assetID))
+		return nil
+	}
+
+	w.mtx.RLock()
+	currentAddr := w.address
+	w.mtx.RUnlock()
+
+	r := newDepositAddressRotator(assetID, policy, currentAddr)
+	if err := r.store(c.db); err != nil {
+		return fmt.Errorf("error storing deposit address policy: %w", err)
+	}
+	c.startDepositRotator(r)
+
+	c.log.Infof("Deposit address policy set for %s wallet: interval = %s, on deposit = %t",
+		unbip(assetID), policy.Interval, policy.OnDeposit)
+	return nil
+}
+
+// startDepositRotator registers the depositAddressRotator and starts scheduled
+// rotation if the policy has an interval. The depositRotatorsMtx MUST be held.
+func (c *Core) startDepositRotator(r *depositAddressRotator) {
+	ctx, cancel := context.WithCancel(c.ctx)
+	r.cancel = cancel
+	c.depositRotators[r.assetID] = r
+
+	if r.policy.Interval > 0 {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			ticker := time.NewTicker(r.policy.Interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					c.rotateDepositAddress(r.assetID)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// loadDepositAddressPolicies resumes the stored deposit address policies.
+func (c *Core) loadDepositAddressPolicies() {
+	recs, err := c.db.DepositAddressRecords()
+	if err != nil {
+		c.log.Errorf("Error loading deposit address policies: %v", err)
+		return
+	}
+	c.depositRotatorsMtx.Lock()
+	defer c.depositRotatorsMtx.Unlock()
+	for _, rec := range recs {
+		w, exists := c.wallet(rec.AssetID)
+		if !exists {
+			c.log.Warnf("No %s wallet for stored deposit address policy", unbip(rec.AssetID))
+			continue
+		}
+		if _, ok := w.Wallet.(asset.NewAddresser); !ok {
+			c.log.Warnf("%s wallet does not generate new addresses. Deposit address policy will not be applied.",
+				unbip(rec.AssetID))
+			continue
+		}
+		r := depositAddressRotatorFromRecord(rec)
+		w.mtx.RLock()
+		currentAddr := w.address
+		w.mtx.RUnlock()
+		if currentAddr != "" && r.track(currentAddr) {
+			if err := r.store(c.db); err != nil {
+				c.log.Errorf("Error storing %s deposit addresses: %v", unbip(rec.AssetID), err)
+			}
+		}
+		c.startDepositRotator(r)
+		c.log.Infof("Resumed deposit address policy for %s wallet: interval = %s, on deposit = %t",
+			unbip(rec.AssetID), r.policy.Interval, r.policy.OnDeposit)
+	}
+}
+
+// DepositAddresses returns the deposit addresses issued for the wallet while
+// the current deposit address policy has been in effect, along with the
+// amounts received at each.
+func (c *Core) DepositAddresses(assetID uint32) ([]*DepositAddress, error) {
+	if _, exists := c.wallet(assetID); !exists {
+		return nil, newError(missingWalletErr, "no wallet found for %s", unbip(assetID))
+	}
+	r := c.depositRotator(assetID)
+	if r == nil {
+		return nil, nil
+	}
+	return r.addresses(), nil
+}
+
+// depositRotator returns the active depositAddressRotator for the asset, or nil
+// if no deposit address policy is set.
+func (c *Core) depositRotator(assetID uint32) *depositAddressRotator {
+	c.depositRotatorsMtx.RLock()
+	defer c.depositRotatorsMtx.RUnlock()
+	return c.depositRotators[assetID]
+}
+
+// trackDepositAddress records a newly issued deposit address if a deposit
+// address policy is active for the asset.
+func (c *Core) trackDepositAddress(assetID uint32, addr string) {
+	if r := c.depositRotator(assetID); r != nil && r.track(addr) {
+		if err := r.store(c.db); err != nil {
+			c.log.Errorf("Error storing %s deposit addresses: %v", unbip(assetID), err)
+		}
+	}
+}
+
+// rotateDepositAddress requests a fresh deposit address from the wallet.
+func (c *Core) rotateDepositAddress(assetID uint32) {
+	addr, err := c.NewDepositAddress(assetID)
+	if err != nil {
+		c.log.Errorf("Error rotating %s deposit address: %v", unbip(assetID), err)
+		return
+	}
+	c.log.Debugf("Rotated %s deposit address to %s", unbip(assetID), addr)
+}
+
+// handleDepositNote attributes a new incoming transaction to the tracked
+// deposit address it was received at, and rotates the deposit address if the
+// policy requires it.
+func (c *Core) handleDepositNote(n *asset.TransactionNote) {
+	tx := n.Transaction
+	if !n.New || tx == nil || tx.Type != asset.Receive || tx.Recipient == nil {
+		return
+	}
+	r := c.depositRotator(n.AssetID)
+	if r == nil {
+		return
+	}
+	addr := *tx.Recipient
+	if !r.credit(addr, tx.ID, tx.Amount) {
+		return
+	}
+	if err := r.store(c.db); err != nil {
+		c.log.Errorf("Error storing %s deposit addresses: %v", unbip(n.AssetID), err)
+	}
+	if !r.policy.OnDeposit {
+		return
+	}
+	w, exists := c.wallet(n.AssetID)
+	if !exists {
+		return
+	}
+	w.mtx.RLock()
+	current := w.address == addr
+	w.mtx.RUnlock()
+	if !current {
+		return
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.rotateDepositAddress(n.AssetID)
+	}()
+}
@@ -52,6 +52,7 @@ const (
 	bondTimeErr
 	bondAssetErr
 	bondPostErr // TODO
+	faucetErr
 )
 
 // Error is an error code and a wrapped error.
@@ -97,6 +98,21 @@ func errorHasCode(err error, code int) bool {
 	return errors.As(err, &e) && e.code == code
 }
 
+// InsufficientDepthError is returned when the order book does not have enough
+// depth to fill a requested quantity.
+type InsufficientDepthError struct {
+	// Qty is the requested quantity.
+	Qty uint64
+	// Available is the total quantity available on the relevant side of the
+	// book.
+	Available uint64
+}
+
+// Error returns the error string. Satisfies the error interface.
+func (e *InsufficientDepthError) Error() string {
+	return fmt.Sprintf("insufficient book depth to fill quantity %d. %d available", e.Qty, e.Available)
+}
+
 // UnwrapErr returns the result of calling the Unwrap method on err,
 // until it returns a non-wrapped error.
 func UnwrapErr(err error) error {
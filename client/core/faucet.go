@@ -0,0 +1,133 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/dexnet"
+)
+
+const (
+	defaultFaucetRequestInterval = time.Hour
+	faucetRequestTimeout         = 30 * time.Second
+)
+
+// errFaucetRateLimited is returned by faucetClient.request when funds for the
+// asset were requested too recently, or the faucet rejected the request as
+// too frequent.
+var errFaucetRateLimited = errors.New("faucet request rate limited")
+
+// FaucetConfig is the configuration for requesting testnet funds from a
+// faucet. The faucet is disabled on mainnet.
+type FaucetConfig struct {
+	// URL is the faucet endpoint. Requests are POSTed as JSON with the asset
+	// symbol and deposit address, and the faucet responds with the ID of the
+	// transaction paying the address.
+	URL string
+	// RequestInterval is the minimum time between requests for the same asset.
+	// The default is one hour.
+	RequestInterval time.Duration
+}
+
+type faucetRequest struct {
+	Asset   string `json:"asset"`
+	Address string `json:"address"`
+}
+
+type faucetResponse struct {
+	TxID string `json:"txid"`
+}
+
+// faucetClient requests funds from a faucet, limiting the request rate for
+// each asset.
+type faucetClient struct {
+	url      string
+	interval time.Duration
+
+	mtx         sync.Mutex
+	lastRequest map[uint32]time.Time
+}
+
+func newFaucetClient(cfg *FaucetConfig, net dex.Network) (*faucetClient, error) {
+	if net == dex.Mainnet {
+		return nil, errors.New("faucet is not available on mainnet")
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid faucet URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid faucet URL scheme %q", u.Scheme)
+	}
+	interval := cfg.RequestInterval
+	if interval <= 0 {
+		interval = defaultFaucetRequestInterval
+	}
+	return &faucetClient{
+		url:         cfg.URL,
+		interval:    interval,
+		lastRequest: make(map[uint32]time.Time),
+	}, nil
+}
+
+// reserve records a request for the asset, unless the last request for the
+// asset was made within the request interval, in which case an error wrapping
+// errFaucetRateLimited is returned. reserve must be called before request.
+func (f *faucetClient) reserve(assetID uint32) error {
+	if dex.BipIDSymbol(assetID) == "" {
+		return fmt.Errorf("unknown asset %d", assetID)
+	}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if last, found := f.lastRequest[assetID]; found {
+		if wait := f.interval - time.Since(last); wait > 0 {
+			return fmt.Errorf("%w: try again in %s", errFaucetRateLimited, wait.Round(time.Second))
+		}
+	}
+	// Count failed requests too, so a misbehaving faucet isn't hammered.
+	f.lastRequest[assetID] = time.Now()
+	return nil
+}
+
+// request requests funds for the asset to be sent to the address, returning the
+// faucet's transaction ID. The request must first be reserved. Requests rejected
+// by the faucet with a 429 status return an error wrapping
+// errFaucetRateLimited.
+func (f *faucetClient) request(ctx context.Context, assetID uint32, addr string) (string, error) {
+	symbol := dex.BipIDSymbol(assetID)
+	b, err := json.Marshal(&faucetRequest{Asset: symbol, Address: addr})
+	if err != nil {
+		return "", fmt.Errorf("error encoding faucet request: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, faucetRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(b))
+	if err != nil {
+		return "", fmt.Errorf("error constructing faucet request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp faucetResponse
+	var code int
+	err = dexnet.Do(req, &resp, dexnet.WithSizeLimit(1<<14), dexnet.WithStatusFunc(func(c int) { code = c }))
+	if code == http.StatusTooManyRequests {
+		return "", fmt.Errorf("%w: rejected by faucet", errFaucetRateLimited)
+	}
+	if err != nil {
+		return "", fmt.Errorf("faucet request error: %w", err)
+	}
+	if resp.TxID == "" {
+		return "", errors.New("faucet response did not include a transaction ID")
+	}
+	return resp.TxID, nil
+}
@@ -0,0 +1,77 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"strconv"
+
+	"decred.org/dcrdex/client/asset"
+)
+
+// gapLimitSetting is the wallet setting for the gap limit of a wallet's
+// address discovery.
+const gapLimitSetting = "gaplimit"
+
+// gapLimiter returns the wallet for the asset as an asset.GapLimiter.
+func (c *Core) gapLimiter(assetID uint32) (*xcWallet, asset.GapLimiter, error) {
+	wallet, found := c.wallet(assetID)
+	if !found {
+		return nil, nil, newError(missingWalletErr, "no %s wallet", unbip(assetID))
+	}
+	gl, is := wallet.Wallet.(asset.GapLimiter)
+	if !is {
+		return nil, nil, newError(walletErr, "%s wallet does not have a gap limit", unbip(assetID))
+	}
+	return wallet, gl, nil
+}
+
+// GapLimit returns the gap limit of the address discovery of the asset's
+// wallet, which is the number of unused addresses past the last used address
+// that are scanned for funds.
+func (c *Core) GapLimit(assetID uint32) (uint32, error) {
+	_, gl, err := c.gapLimiter(assetID)
+	if err != nil {
+		return 0, err
+	}
+	return gl.GapLimit()
+}
+
+// ExtendGapLimit raises the gap limit of the asset's wallet by n and begins a
+// rescan, so that funds received at addresses past the previous gap limit,
+// e.g. by another wallet restored from the same seed, are found. The new gap
+// limit is stored in the wallet's settings. Like a rescan, this is not allowed
+// while the wallet has active orders.
+func (c *Core) ExtendGapLimit(assetID, n uint32) (uint32, error) {
+	wallet, gl, err := c.gapLimiter(assetID)
+	if err != nil {
+		return 0, err
+	}
+	if !wallet.connected() {
+		return 0, errWalletNotConnected
+	}
+	if c.walletIsActive(assetID) {
+		return 0, newError(activeOrdersErr, "active orders or registration fee payments for %v", unbip(assetID))
+	}
+	gapLimit, err := gl.ExtendGapLimit(n)
+	if err != nil {
+		return 0, newError(walletErr, "error extending %s gap limit: %w", unbip(assetID), err)
+	}
+
+	dbWallet, err := c.db.Wallet(wallet.dbID)
+	if err != nil {
+		return 0, codedError(dbErr, err)
+	}
+	if dbWallet.Settings == nil {
+		dbWallet.Settings = make(map[string]string, 1)
+	}
+	dbWallet.Settings[gapLimitSetting] = strconv.FormatUint(uint64(gapLimit), 10)
+	if err := c.db.UpdateWallet(dbWallet); err != nil {
+		return 0, newError(dbErr, "error saving %s gap limit: %w", unbip(assetID), err)
+	}
+
+	if !c.walletCheckAndNotify(wallet) {
+		c.startWalletSyncMonitor(wallet)
+	}
+	return gapLimit, nil
+}
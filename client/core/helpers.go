@@ -405,8 +405,7 @@ func (ord *OrderReader) FundingCoinIDs() []string {
 // formatRate formats the specified rate as a conventional rate with trailing
 // zeros trimmed.
 func (ord *OrderReader) formatRate(msgRate uint64) string {
-	r := calc.ConventionalRate(msgRate, ord.BaseUnitInfo, ord.QuoteUnitInfo)
-	return trimTrailingZeros(strconv.FormatFloat(r, 'f', 8, 64))
+	return dex.FormatMessageRate(msgRate, ord.BaseUnitInfo, ord.QuoteUnitInfo)
 }
 
 // formatQty formats the quantity as a conventional string and trims trailing
@@ -0,0 +1,356 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
+	"decred.org/dcrdex/dex/order"
+)
+
+// icebergCheckInterval is how often the child orders of the iceberg orders
+// are checked, and replenished if they were filled.
+const icebergCheckInterval = 5 * time.Second
+
+// PlaceIcebergOrder places a standing limit order for the form's Qty of which
+// only displayQty is on the book at a time. The order is managed by the client
+// as a series of child limit orders for up to displayQty. Only one child order
+// is active at a time, and when it is filled, the next child order is placed
+// for the lesser of displayQty and the unfilled quantity. The first child
+// order is placed immediately. The following child orders are placed without
+// the app password, so the wallets must remain unlocked. Iceberg orders are
+// persisted.
+func (c *Core) PlaceIcebergOrder(pw []byte, form *TradeForm, displayQty uint64) (*db.IcebergOrder, error) {
+	if !form.IsLimit || form.TifNow {
+		return nil, newError(orderParamsErr, "iceberg orders must be standing limit orders")
+	}
+	if form.QtyInQuote || form.TTL > 0 {
+		return nil, newError(orderParamsErr, "quote asset quantities and TTLs are not supported for iceberg orders")
+	}
+	if displayQty == 0 || displayQty >= form.Qty {
+		return nil, newError(orderParamsErr, "display quantity %d must be positive and less than the order quantity %d",
+			displayQty, form.Qty)
+	}
+	dc, _, err := c.dex(form.Host)
+	if err != nil {
+		return nil, err
+	}
+	mktConf := dc.marketConfig(marketName(form.Base, form.Quote))
+	if mktConf == nil {
+		return nil, newError(marketErr, "unknown market %s", marketName(form.Base, form.Quote))
+	}
+	if err := checkQuantization(mktConf, form.Qty, form.Rate, true, form.Sell); err != nil {
+		return nil, err
+	}
+	if err := checkQuantization(mktConf, displayQty, form.Rate, true, form.Sell); err != nil {
+		return nil, err
+	}
+
+	ice := &db.IcebergOrder{
+		ID:         hex.EncodeToString(encode.RandomBytes(8)),
+		Host:       dc.acct.host,
+		Base:       form.Base,
+		Quote:      form.Quote,
+		Sell:       form.Sell,
+		Rate:       form.Rate,
+		Qty:        form.Qty,
+		DisplayQty: displayQty,
+		Options:    form.Options,
+	}
+
+	// Load the iceberg orders first, so that a DB error doesn't leave an
+	// untracked child order on the book.
+	c.icebergsMtx.Lock()
+	_, err = c.activeIcebergs()
+	c.icebergsMtx.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	ord, err := c.placeIcebergChild(pw, ice)
+	if err != nil {
+		return nil, err
+	}
+	ice.Child = ord.ID
+	ice.Children = append(ice.Children, ord.ID)
+
+	c.icebergsMtx.Lock()
+	defer c.icebergsMtx.Unlock()
+	icebergs, err := c.activeIcebergs()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.db.SaveIcebergOrder(ice); err != nil {
+		return nil, codedError(dbErr, err)
+	}
+	icebergs[ice.ID] = ice
+	i := *ice
+	return &i, nil
+}
+
+// IcebergOrders returns the active iceberg orders, sorted by ID. Iceberg
+// orders are deleted once they are done.
+func (c *Core) IcebergOrders() ([]*db.IcebergOrder, error) {
+	c.icebergsMtx.Lock()
+	defer c.icebergsMtx.Unlock()
+	icebergs, err := c.activeIcebergs()
+	if err != nil {
+		return nil, err
+	}
+	ices := make([]*db.IcebergOrder, 0, len(icebergs))
+	for _, ice := range icebergs {
+		i := *ice
+		ices = append(ices, &i)
+	}
+	sort.Slice(ices, func(i, j int) bool { return ices[i].ID < ices[j].ID })
+	return ices, nil
+}
+
+// activeIcebergs returns the active iceberg orders, loading them from the DB
+// the first time. Stored iceberg orders that are already done are deleted.
+// The icebergsMtx must be held.
+func (c *Core) activeIcebergs() (map[string]*db.IcebergOrder, error) {
+	if c.icebergs != nil {
+		return c.icebergs, nil
+	}
+	ices, err := c.db.IcebergOrders()
+	if err != nil {
+		return nil, codedError(dbErr, err)
+	}
+	icebergs := make(map[string]*db.IcebergOrder, len(ices))
+	for _, ice := range ices {
+		if !ice.Done {
+			icebergs[ice.ID] = ice
+			continue
+		}
+		if err := c.db.DeleteIcebergOrder(ice.ID); err != nil {
+			c.log.Errorf("Error deleting finished iceberg order %s: %v", ice.ID, err)
+		}
+	}
+	c.icebergs = icebergs
+	return icebergs, nil
+}
+
+// CancelIcebergOrder cancels the iceberg order with the ID. The active child
+// order is canceled, and no more child orders are placed. The quantity filled
+// by the active child order before it is canceled is still accounted for.
+func (c *Core) CancelIcebergOrder(id string) error {
+	// The iceberg order is marked canceled before the child order is canceled,
+	// so that no new child order is placed in the meantime.
+	childID, err := c.markIcebergCanceled(id, true)
+	if err != nil {
+		return err
+	}
+	if len(childID) == 0 {
+		return nil
+	}
+	child, err := c.Order(childID)
+	if err == nil && child.Status <= order.OrderStatusBooked {
+		err = c.Cancel(childID)
+	}
+	if err != nil {
+		if _, unmarkErr := c.markIcebergCanceled(id, false); unmarkErr != nil {
+			c.log.Errorf("Error restoring iceberg order %s after a failed cancel: %v", id, unmarkErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// markIcebergCanceled sets or clears the Canceled flag of the iceberg order
+// with the ID, and returns the ID of its active child order, if there is one.
+func (c *Core) markIcebergCanceled(id string, canceled bool) (dex.Bytes, error) {
+	c.icebergsMtx.Lock()
+	defer c.icebergsMtx.Unlock()
+	icebergs, err := c.activeIcebergs()
+	if err != nil {
+		return nil, err
+	}
+	ice := icebergs[id]
+	if ice == nil {
+		return nil, fmt.Errorf("%w: %q", db.ErrNoIceberg, id)
+	}
+	if canceled && ice.Canceled {
+		return nil, fmt.Errorf("iceberg order %s is already finished", id)
+	}
+	ice.Canceled = canceled
+	if err := c.db.SaveIcebergOrder(ice); err != nil {
+		ice.Canceled = !canceled
+		return nil, codedError(dbErr, err)
+	}
+	return ice.Child, nil
+}
+
+// placeIcebergChild places the next child order of the iceberg order, for the
+// lesser of the display quantity and the unfilled quantity. The icebergsMtx
+// must not be held, since Trade communicates with the server.
+func (c *Core) placeIcebergChild(pw []byte, ice *db.IcebergOrder) (*Order, error) {
+	qty := ice.Qty - ice.Filled
+	if qty > ice.DisplayQty {
+		qty = ice.DisplayQty
+	}
+	return c.Trade(pw, &TradeForm{
+		Host:    ice.Host,
+		IsLimit: true,
+		Sell:    ice.Sell,
+		Base:    ice.Base,
+		Quote:   ice.Quote,
+		Qty:     qty,
+		Rate:    ice.Rate,
+		Options: ice.Options,
+	})
+}
+
+// watchIcebergOrders replenishes the iceberg orders as their child orders are
+// filled.
+func (c *Core) watchIcebergOrders(ctx context.Context) {
+	tick := time.NewTicker(icebergCheckInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			c.checkIcebergOrders()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkIcebergOrders updates the active iceberg orders. Iceberg orders that
+// are done are deleted. The next child orders are placed without the
+// icebergsMtx held.
+func (c *Core) checkIcebergOrders() {
+	for _, ice := range c.updateIcebergOrders() {
+		ord, err := c.placeIcebergChild(nil, ice)
+		if c.recordIcebergChild(ice.ID, ord, err) {
+			// The iceberg order was canceled while the child order was being
+			// placed.
+			if err := c.Cancel(ord.ID); err != nil {
+				c.log.Errorf("Error canceling child order %s of canceled iceberg order %s: %v", ord.ID, ice.ID, err)
+			}
+		}
+	}
+}
+
+// updateIcebergOrders updates the active iceberg orders, and returns copies of
+// the iceberg orders that need a new child order.
+func (c *Core) updateIcebergOrders() []*db.IcebergOrder {
+	c.icebergsMtx.Lock()
+	defer c.icebergsMtx.Unlock()
+	icebergs, err := c.activeIcebergs()
+	if err != nil {
+		c.log.Errorf("Error loading iceberg orders: %v", err)
+		return nil
+	}
+	var replenish []*db.IcebergOrder
+	for id, ice := range icebergs {
+		changed, needsChild := c.updateIcebergOrder(ice)
+		if needsChild {
+			i := *ice
+			replenish = append(replenish, &i)
+		}
+		if !changed {
+			continue
+		}
+		if ice.Done {
+			delete(icebergs, id)
+			if err := c.db.DeleteIcebergOrder(id); err != nil {
+				c.log.Errorf("Error deleting iceberg order %s: %v", id, err)
+			}
+			continue
+		}
+		if err := c.db.SaveIcebergOrder(ice); err != nil {
+			c.log.Errorf("Error storing iceberg order %s: %v", id, err)
+		}
+	}
+	return replenish
+}
+
+// updateIcebergOrder adds the quantity filled by the iceberg order's child
+// order to the iceberg order's Filled once the child order is no longer
+// active. If the iceberg order was canceled or is filled, it is done.
+// Otherwise, needsChild is true and the next child order should be placed. If
+// the child order was canceled or revoked by the server, the iceberg order is
+// stopped. updateIcebergOrder reports whether the iceberg order was changed.
+// The icebergsMtx must be held.
+func (c *Core) updateIcebergOrder(ice *db.IcebergOrder) (changed, needsChild bool) {
+	if len(ice.Child) > 0 {
+		child, err := c.Order(ice.Child)
+		if err != nil {
+			c.log.Errorf("Error loading child order %s of iceberg order %s: %v", ice.Child, ice.ID, err)
+			return false, false
+		}
+		if child.Status <= order.OrderStatusBooked {
+			return false, false
+		}
+		ice.Filled += child.Filled
+		ice.Child = nil
+		if child.Status != order.OrderStatusExecuted && !ice.Canceled {
+			ice.Done = true
+			c.log.Warnf("Stopping iceberg order %s. Child order %s was %s", ice.ID, child.ID, child.Status)
+			c.notifyIceberg(ice, TopicIcebergStopped, db.WarningLevel, child.ID, child.Status)
+			return true, false
+		}
+		changed = true
+	}
+
+	switch {
+	case ice.Canceled:
+		ice.Done = true
+		return true, false
+	case ice.Filled >= ice.Qty:
+		ice.Done = true
+		c.notifyIceberg(ice, TopicIcebergCompleted, db.Success)
+		return true, false
+	}
+	return changed, true
+}
+
+// recordIcebergChild records the result of placing the next child order of
+// the iceberg order with the ID. If the child order could not be placed, the
+// iceberg order is stopped. recordIcebergChild reports whether the iceberg
+// order was canceled or finished while the child order was being placed, in
+// which case the child order should be canceled.
+func (c *Core) recordIcebergChild(id string, ord *Order, placeErr error) (cancelChild bool) {
+	c.icebergsMtx.Lock()
+	defer c.icebergsMtx.Unlock()
+	icebergs, err := c.activeIcebergs()
+	if err != nil {
+		c.log.Errorf("Error loading iceberg orders: %v", err)
+		return placeErr == nil
+	}
+	ice := icebergs[id]
+	if ice == nil {
+		return placeErr == nil
+	}
+	if placeErr != nil {
+		ice.Done = true
+		c.log.Errorf("Error placing the next child order of iceberg order %s: %v", id, placeErr)
+		c.notifyIceberg(ice, TopicIcebergFailed, db.ErrorLevel, placeErr)
+		delete(icebergs, id)
+		if err := c.db.DeleteIcebergOrder(id); err != nil {
+			c.log.Errorf("Error deleting iceberg order %s: %v", id, err)
+		}
+		return false
+	}
+	ice.Child = ord.ID
+	ice.Children = append(ice.Children, ord.ID)
+	if err := c.db.SaveIcebergOrder(ice); err != nil {
+		c.log.Errorf("Error storing iceberg order %s: %v", id, err)
+	}
+	return ice.Canceled
+}
+
+// notifyIceberg sends an IcebergNote with a copy of the iceberg order.
+func (c *Core) notifyIceberg(ice *db.IcebergOrder, topic Topic, severity db.Severity, args ...any) {
+	subject, details := c.formatDetails(topic, append([]any{ice.ID, marketName(ice.Base, ice.Quote)}, args...)...)
+	i := *ice
+	c.notify(newIcebergNote(topic, subject, details, severity, &i))
+}
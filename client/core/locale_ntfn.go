@@ -65,6 +65,34 @@ var originLocale = map[Topic]*translation{
 		subject:  intl.Translation{T: "Wallet connectivity restored"},
 		template: intl.Translation{T: "%v wallet has reestablished connectivity.", Notes: "args: [asset name]"},
 	},
+	TopicLowBalance: {
+		subject:  intl.Translation{T: "Low balance"},
+		template: intl.Translation{T: "Available %s balance %s is below the alert threshold %s", Notes: "args: [ticker, balance, threshold]"},
+	},
+	TopicBalanceRestored: {
+		subject:  intl.Translation{T: "Balance restored"},
+		template: intl.Translation{T: "Available %s balance %s is no longer below the alert threshold %s", Notes: "args: [ticker, balance, threshold]"},
+	},
+	TopicScheduledOrderSkipped: {
+		subject:  intl.Translation{T: "Scheduled order skipped"},
+		template: intl.Translation{T: "Skipped the %s order of schedule %q. Available %s balance %s is less than the %s required", Notes: "args: [market, schedule name, ticker, balance, required]"},
+	},
+	TopicScheduledOrderFailed: {
+		subject:  intl.Translation{T: "Scheduled order failed"},
+		template: intl.Translation{T: "Error placing the %s order of schedule %q: %v", Notes: "args: [market, schedule name, error]"},
+	},
+	TopicIcebergCompleted: {
+		subject:  intl.Translation{T: "Iceberg order complete"},
+		template: intl.Translation{T: "Iceberg order %s on %s is filled", Notes: "args: [iceberg order ID, market]"},
+	},
+	TopicIcebergStopped: {
+		subject:  intl.Translation{T: "Iceberg order stopped"},
+		template: intl.Translation{T: "Iceberg order %s on %s was stopped because child order %s was %s", Notes: "args: [iceberg order ID, market, child order ID, child order status]"},
+	},
+	TopicIcebergFailed: {
+		subject:  intl.Translation{T: "Iceberg order failed"},
+		template: intl.Translation{T: "Error placing the next child order of iceberg order %s on %s: %v", Notes: "args: [iceberg order ID, market, error]"},
+	},
 	TopicSendError: {
 		subject:  intl.Translation{T: "Send error"},
 		template: intl.Translation{Version: 1, T: "Error encountered while sending %s: %v", Notes: "args: [ticker, error]"},
@@ -205,6 +233,10 @@ var originLocale = map[Topic]*translation{
 		subject:  intl.Translation{T: "Match resolution error"},
 		template: intl.Translation{T: "%d matches reported by %s were not found for %s.", Notes: "args: [count, host, token]"},
 	},
+	TopicOrderReduceFailed: {
+		subject:  intl.Translation{T: "Order reduce failed"},
+		template: intl.Translation{T: "Order %s was canceled to reduce it, but the replacement order for %s %s could not be placed: %v", Notes: "args: [token, qty, ticker, error]"},
+	},
 	TopicFailedCancel: {
 		subject: intl.Translation{T: "Failed cancel"},
 		template: intl.Translation{
@@ -290,6 +322,14 @@ var originLocale = map[Topic]*translation{
 		subject:  intl.Translation{T: "Message from DEX"},
 		template: intl.Translation{T: "%s: %s", Notes: "args: [host, msg]"},
 	},
+	TopicDEXAnnouncement: {
+		subject:  intl.Translation{T: "Announcement from DEX"},
+		template: intl.Translation{T: "%s: %s", Notes: "args: [host, announcement]"},
+	},
+	TopicPriceDivergence: {
+		subject:  intl.Translation{T: "Market price divergence"},
+		template: intl.Translation{T: "The %s %s market price %.8f differs from the reference price %.8f by %.1f%%", Notes: "args: [host, market, price, reference price, percent difference]"},
+	},
 	TopicQueuedCreationFailed: {
 		subject:  intl.Translation{T: "Failed to create token wallet"},
 		template: intl.Translation{T: "After creating %s wallet, failed to create the %s wallet", Notes: "args: [parentSymbol, tokenSymbol]"},
@@ -306,6 +346,34 @@ var originLocale = map[Topic]*translation{
 		subject:  intl.Translation{T: "Redemption Confirmed"},
 		template: intl.Translation{T: "Your redemption for match %s in order %s was confirmed"},
 	},
+	TopicCounterSwapDelayed: {
+		subject:  intl.Translation{T: "Counterparty swap delayed"},
+		template: intl.Translation{T: "The counterparty has not sent their swap for match %s in order %s within the server's broadcast timeout. If they do not, your swap can be refunded after %s.", Notes: "args: [match token, order token, refund time]"},
+	},
+	TopicCounterRedeemDelayed: {
+		subject:  intl.Translation{T: "Counterparty redemption delayed"},
+		template: intl.Translation{T: "The counterparty has not redeemed your swap for match %s in order %s within the server's broadcast timeout. If they do not, your swap can be refunded after %s.", Notes: "args: [match token, order token, refund time]"},
+	},
+	TopicSwapReplaced: {
+		subject:  intl.Translation{T: "Swap replaced"},
+		template: intl.Translation{T: "Your swap for match %s in order %s was replaced by transaction %s, which is now being tracked.", Notes: "args: [match token, order token, coin ID]"},
+	},
+	TopicSwapLost: {
+		subject:  intl.Translation{T: "Swap transaction missing"},
+		template: intl.Translation{T: "Your swap %s for match %s in order %s can no longer be found by your %s wallet, and no replacement transaction was found. Check your wallet for a conflicting transaction.", Notes: "args: [coin ID, match token, order token, asset symbol]"},
+	},
+	TopicDuplicateSwaps: {
+		subject:  intl.Translation{T: "Duplicate swap detected"},
+		template: intl.Translation{T: "Your %s wallet sent more than one swap for match %s in order %s. The duplicate swap %s will be refunded after %s. The match needs attention until then.", Notes: "args: [asset symbol, match token, order token, coin ID, refund time]"},
+	},
+	TopicDuplicateSwapUsed: {
+		subject:  intl.Translation{T: "Duplicate swap redeemed"},
+		template: intl.Translation{T: "The counterparty redeemed the duplicate swap %s for match %s in order %s. The original swap %s will be refunded instead.", Notes: "args: [coin ID, match token, order token, original coin ID]"},
+	},
+	TopicDuplicateSwapRefunded: {
+		subject:  intl.Translation{T: "Duplicate swap refunded"},
+		template: intl.Translation{T: "The duplicate swap %s for match %s in order %s was refunded in transaction %s.", Notes: "args: [coin ID, match token, order token, refund coin ID]"},
+	},
 	TopicWalletTypeDeprecated: {
 		subject:  intl.Translation{T: "Wallet Disabled"},
 		template: intl.Translation{T: "Your %s wallet type is no longer supported. Create a new wallet."},
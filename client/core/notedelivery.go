@@ -0,0 +1,306 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+)
+
+const (
+	defaultNoteDeliveryQueueSize   = 64
+	defaultNoteDeliveryMaxAttempts = 3
+	defaultNoteDeliveryRetryDelay  = 5 * time.Second
+	noteDeliveryTimeout            = 20 * time.Second
+)
+
+// DeliveredNote is the notification data dispatched to a NoteSink.
+type DeliveredNote struct {
+	Type     string `json:"type"`
+	Topic    Topic  `json:"topic"`
+	Subject  string `json:"subject"`
+	Details  string `json:"details,omitempty"`
+	Severity string `json:"severity"`
+	Stamp    uint64 `json:"stamp"`
+}
+
+// NoteSink is an external destination for notifications.
+type NoteSink interface {
+	// Deliver sends the notification to the sink. Deliver should return when
+	// the context is canceled.
+	Deliver(ctx context.Context, note *DeliveredNote) error
+}
+
+// SMTPConfig is the configuration for delivering notifications by email.
+type SMTPConfig struct {
+	// Host is the host:port of the SMTP server.
+	Host string
+	// Username and Password are used for PLAIN authentication if Username is
+	// set.
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// NoteDeliveryConfig is the configuration for delivering notifications to an
+// external sink. Exactly one of Sink, WebhookURL, or SMTP must be set.
+type NoteDeliveryConfig struct {
+	// Sink is a custom NoteSink.
+	Sink NoteSink
+	// WebhookURL is a URL to which notifications are POSTed as JSON.
+	WebhookURL string
+	// SMTP configures delivery by email.
+	SMTP *SMTPConfig
+	// MinSeverity is the lowest severity of notification that will be
+	// delivered. Notifications with severity below Poke are never delivered.
+	// The default is db.WarningLevel.
+	MinSeverity db.Severity
+	// Redact omits the notification details, which may include amounts,
+	// addresses, and order and match IDs, from delivered notifications.
+	Redact bool
+	// QueueSize is the number of notifications that may be waiting for
+	// delivery. Notifications are dropped while the queue is full. The default
+	// is 64.
+	QueueSize int
+	// MaxAttempts is the number of times delivery of a notification is
+	// attempted before it is dropped. The default is 3.
+	MaxAttempts int
+	// RetryDelay is the wait after the first failed delivery attempt. The wait
+	// doubles with each failed attempt. The default is 5 seconds.
+	RetryDelay time.Duration
+}
+
+// noteDeliverer dispatches notifications to a NoteSink asynchronously, so that
+// a slow or unavailable sink never blocks Core.
+type noteDeliverer struct {
+	sink        NoteSink
+	minSeverity db.Severity
+	redact      bool
+	maxAttempts int
+	retryDelay  time.Duration
+	queue       chan *DeliveredNote
+	log         dex.Logger
+}
+
+func newNoteDeliverer(cfg *NoteDeliveryConfig, log dex.Logger) (*noteDeliverer, error) {
+	var sinks []NoteSink
+	if cfg.Sink != nil {
+		sinks = append(sinks, cfg.Sink)
+	}
+	if cfg.WebhookURL != "" {
+		sink, err := newWebhookSink(cfg.WebhookURL)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if cfg.SMTP != nil {
+		sink, err := newSMTPSink(cfg.SMTP)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) != 1 {
+		return nil, fmt.Errorf("exactly one notification sink must be configured, got %d", len(sinks))
+	}
+
+	d := &noteDeliverer{
+		sink:        sinks[0],
+		minSeverity: cfg.MinSeverity,
+		redact:      cfg.Redact,
+		maxAttempts: cfg.MaxAttempts,
+		retryDelay:  cfg.RetryDelay,
+		log:         log,
+	}
+	if d.minSeverity < db.Poke {
+		d.minSeverity = db.WarningLevel
+	}
+	if d.maxAttempts <= 0 {
+		d.maxAttempts = defaultNoteDeliveryMaxAttempts
+	}
+	if d.retryDelay <= 0 {
+		d.retryDelay = defaultNoteDeliveryRetryDelay
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = defaultNoteDeliveryQueueSize
+	}
+	d.queue = make(chan *DeliveredNote, queueSize)
+	return d, nil
+}
+
+// enqueue queues the notification for delivery if its severity is high enough.
+// The notification is dropped if the queue is full.
+func (d *noteDeliverer) enqueue(n Notification) {
+	if n.Severity() < d.minSeverity {
+		return
+	}
+	note := &DeliveredNote{
+		Type:     n.Type(),
+		Topic:    n.Topic(),
+		Subject:  n.Subject(),
+		Severity: n.Severity().String(),
+		Stamp:    n.Time(),
+	}
+	if !d.redact {
+		note.Details = n.Details()
+	}
+	select {
+	case d.queue <- note:
+	default:
+		d.log.Warnf("Notification delivery queue full. Dropping %q notification %q", note.Type, note.Subject)
+	}
+}
+
+// run delivers queued notifications until the context is canceled.
+func (d *noteDeliverer) run(ctx context.Context) {
+	for {
+		select {
+		case note := <-d.queue:
+			d.deliver(ctx, note)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// deliver attempts to deliver the notification up to maxAttempts times.
+func (d *noteDeliverer) deliver(ctx context.Context, note *DeliveredNote) {
+	delay := d.retryDelay
+	for attempt := 1; ; attempt++ {
+		err := d.sink.Deliver(ctx, note)
+		if err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if attempt >= d.maxAttempts {
+			d.log.Errorf("Dropping %q notification %q after %d failed delivery attempts: %v",
+				note.Type, note.Subject, attempt, err)
+			return
+		}
+		d.log.Warnf("Error delivering %q notification %q (attempt %d), retrying in %s: %v",
+			note.Type, note.Subject, attempt, delay, err)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		delay *= 2
+	}
+}
+
+// webhookSink POSTs notifications as JSON to a URL.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookSink(rawURL string) (*webhookSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webhook URL scheme %q", u.Scheme)
+	}
+	return &webhookSink{
+		url:    rawURL,
+		client: &http.Client{Timeout: noteDeliveryTimeout},
+	}, nil
+}
+
+// Deliver POSTs the notification to the webhook URL. Any non-2xx response is
+// an error.
+func (s *webhookSink) Deliver(ctx context.Context, note *DeliveredNote) error {
+	b, err := json.Marshal(note)
+	if err != nil {
+		return fmt.Errorf("error encoding notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+// smtpSink emails notifications.
+type smtpSink struct {
+	cfg  *SMTPConfig
+	host string
+}
+
+func newSMTPSink(cfg *SMTPConfig) (*smtpSink, error) {
+	host, _, err := net.SplitHostPort(cfg.Host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP host %q: %w", cfg.Host, err)
+	}
+	if cfg.From == "" || len(cfg.To) == 0 {
+		return nil, errors.New("SMTP notification delivery requires from and to addresses")
+	}
+	for _, addr := range append([]string{cfg.From}, cfg.To...) {
+		if strings.ContainsAny(addr, "\r\n") {
+			return nil, fmt.Errorf("invalid email address %q", addr)
+		}
+	}
+	return &smtpSink{cfg: cfg, host: host}, nil
+}
+
+// headerReplacer removes line breaks from email header values, which could
+// otherwise be used to inject headers.
+var headerReplacer = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ")
+
+// message formats the notification as an email message.
+func (s *smtpSink) message(note *DeliveredNote) string {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [Bison Wallet %s] %s\r\n", note.Severity, headerReplacer.Replace(note.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.UnixMilli(int64(note.Stamp)).Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(note.Subject + "\r\n")
+	if note.Details != "" {
+		msg.WriteString("\r\n" + note.Details + "\r\n")
+	}
+	return msg.String()
+}
+
+// Deliver emails the notification to the configured recipients. The context
+// is not honored once the SMTP session has begun.
+func (s *smtpSink) Deliver(ctx context.Context, note *DeliveredNote) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.host)
+	}
+	return smtp.SendMail(s.cfg.Host, auth, s.cfg.From, s.cfg.To, []byte(s.message(note)))
+}
@@ -43,6 +43,8 @@ const (
 	NoteTypeWalletNote     = "walletnote"
 	NoteTypeReputation     = "reputation"
 	NoteTypeActionRequired = "actionrequired"
+	NoteTypeOrderSchedule  = "orderschedule"
+	NoteTypeIceberg        = "iceberg"
 )
 
 var noteChanCounter uint64
@@ -90,6 +92,10 @@ func (c *Core) notify(n Notification) {
 
 	c.logNote(n)
 
+	if c.noteDeliverer != nil {
+		c.noteDeliverer.enqueue(n)
+	}
+
 	c.noteMtx.RLock()
 	for _, ch := range c.noteChans {
 		select {
@@ -404,6 +410,7 @@ const (
 	TopicAsyncOrderFailure    Topic = "AsyncOrderFailure"
 	TopicAsyncOrderSubmitted  Topic = "AsyncOrderSubmitted"
 	TopicOrderQuantityTooHigh Topic = "OrderQuantityTooHigh"
+	TopicOrderReduceFailed    Topic = "OrderReduceFailed"
 )
 
 func newOrderNote(topic Topic, subject, details string, severity db.Severity, corder *Order) *OrderNote {
@@ -437,6 +444,13 @@ const (
 	TopicRedemptionResubmitted Topic = "RedemptionResubmitted"
 	TopicSwapRefunded          Topic = "SwapRefunded"
 	TopicRedemptionConfirmed   Topic = "RedemptionConfirmed"
+	TopicCounterSwapDelayed    Topic = "CounterSwapDelayed"
+	TopicCounterRedeemDelayed  Topic = "CounterRedeemDelayed"
+	TopicSwapReplaced          Topic = "SwapReplaced"
+	TopicSwapLost              Topic = "SwapLost"
+	TopicDuplicateSwaps        Topic = "DuplicateSwaps"
+	TopicDuplicateSwapUsed     Topic = "DuplicateSwapUsed"
+	TopicDuplicateSwapRefunded Topic = "DuplicateSwapRefunded"
 )
 
 func newMatchNote(topic Topic, subject, details string, severity db.Severity, t *trackedTrade, match *matchTracker) *MatchNote {
@@ -600,6 +614,8 @@ const (
 	TopicWalletTypeDeprecated       Topic = "WalletTypeDeprecated"
 	TopicWalletPeersUpdate          Topic = "WalletPeersUpdate"
 	TopicBondWalletNotConnected     Topic = "BondWalletNotConnected"
+	TopicLowBalance                 Topic = "LowBalance"
+	TopicBalanceRestored            Topic = "BalanceRestored"
 )
 
 func newWalletConfigNote(topic Topic, subject, details string, severity db.Severity, walletState *WalletState) *WalletConfigNote {
@@ -609,6 +625,43 @@ func newWalletConfigNote(topic Topic, subject, details string, severity db.Sever
 	}
 }
 
+// OrderScheduleNote is a notification regarding a recurring order schedule.
+type OrderScheduleNote struct {
+	db.Notification
+	Schedule *db.OrderSchedule `json:"schedule"`
+}
+
+const (
+	TopicScheduledOrderSkipped Topic = "ScheduledOrderSkipped"
+	TopicScheduledOrderFailed  Topic = "ScheduledOrderFailed"
+)
+
+func newOrderScheduleNote(topic Topic, subject, details string, severity db.Severity, sched *db.OrderSchedule) *OrderScheduleNote {
+	return &OrderScheduleNote{
+		Notification: db.NewNotification(NoteTypeOrderSchedule, topic, subject, details, severity),
+		Schedule:     sched,
+	}
+}
+
+// IcebergNote is a notification regarding an iceberg order.
+type IcebergNote struct {
+	db.Notification
+	Iceberg *db.IcebergOrder `json:"iceberg"`
+}
+
+const (
+	TopicIcebergCompleted Topic = "IcebergCompleted"
+	TopicIcebergStopped   Topic = "IcebergStopped"
+	TopicIcebergFailed    Topic = "IcebergFailed"
+)
+
+func newIcebergNote(topic Topic, subject, details string, severity db.Severity, ice *db.IcebergOrder) *IcebergNote {
+	return &IcebergNote{
+		Notification: db.NewNotification(NoteTypeIceberg, topic, subject, details, severity),
+		Iceberg:      ice,
+	}
+}
+
 // WalletStateNote is a notification regarding a change in wallet state,
 // including: creation, locking, unlocking, connect, disabling and enabling. This
 // is intended to be a Data Severity notification.
@@ -631,12 +684,15 @@ func newWalletStateNote(walletState *WalletState) *WalletStateNote {
 	}
 }
 
-// WalletSyncNote is a notification of the wallet sync status.
+// WalletSyncNote is a notification of the wallet sync status. It is emitted
+// periodically while the wallet is syncing, and once more when the sync
+// completes.
 type WalletSyncNote struct {
 	db.Notification
 	AssetID      uint32            `json:"assetID"`
 	SyncStatus   *asset.SyncStatus `json:"syncStatus"`
 	SyncProgress float32           `json:"syncProgress"`
+	Status       *WalletSyncStatus `json:"status"`
 }
 
 const TopicWalletSync = "WalletSync"
@@ -647,6 +703,7 @@ func newWalletSyncNote(assetID uint32, ss *asset.SyncStatus) *WalletSyncNote {
 		AssetID:      assetID,
 		SyncStatus:   ss,
 		SyncProgress: ss.BlockProgress(),
+		Status:       walletSyncStatus(ss),
 	}
 }
 
@@ -663,6 +720,8 @@ const (
 	TopicMarketResumed            Topic = "MarketResumed"
 	TopicPenalized                Topic = "Penalized"
 	TopicDEXNotification          Topic = "DEXNotification"
+	TopicDEXAnnouncement          Topic = "DEXAnnouncement"
+	TopicPriceDivergence          Topic = "PriceDivergence"
 )
 
 func newServerNotifyNote(topic Topic, subject, details string, severity db.Severity) *ServerNotifyNote {
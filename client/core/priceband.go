@@ -0,0 +1,83 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+)
+
+// SetPriceBand sets the maximum deviation, in percent, of a limit order's rate
+// from the market's reference price. Limit orders outside of the band are
+// rejected unless the form's OverridePriceBand is set. The reference price is
+// the mid-gap of the synced order book, or the price oracle's reference price,
+// by default the ratio of the cached fiat rates, if the book is not synced or
+// one side is empty. Zero disables the guard.
+func (c *Core) SetPriceBand(pct float64) error {
+	if pct < 0 || math.IsNaN(pct) || math.IsInf(pct, 0) {
+		return fmt.Errorf("invalid price band %f", pct)
+	}
+	c.priceBand.Store(math.Float64bits(pct))
+	if pct == 0 {
+		c.log.Infof("Limit order price band disabled")
+	} else {
+		c.log.Infof("Limit orders must be within %.2f%% of the reference price", pct)
+	}
+	return nil
+}
+
+// PriceBand is the maximum deviation, in percent, of a limit order's rate from
+// the market's reference price. Zero means the guard is disabled.
+func (c *Core) PriceBand() float64 {
+	return math.Float64frombits(c.priceBand.Load())
+}
+
+// referenceRate is the message-rate against which limit orders are checked by
+// the price band guard. This is the synced book's mid-gap, or the price
+// oracle's reference price. False is returned if there is no reference price.
+func (c *Core) referenceRate(dc *dexConnection, base, quote uint32, baseUnits, quoteUnits dex.UnitInfo) (uint64, bool) {
+	if book := dc.bookie(marketName(base, quote)); book != nil {
+		if midGap, err := book.MidGap(); err == nil && midGap > 0 {
+			return midGap, true
+		}
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, oracleRequestTimeout)
+	defer cancel()
+	price, err := c.oracle.Price(ctx, base, quote)
+	if err != nil || price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		c.log.Debugf("No oracle reference price for %s: price = %f, err = %v",
+			marketName(base, quote), price, err)
+		return 0, false
+	}
+	return calc.MessageRate(price, baseUnits, quoteUnits), true
+}
+
+// checkPriceBand rejects a limit order rate that deviates from the reference
+// price by more than the configured price band. Rates are not checked if the
+// guard is disabled or if there is no reference price. The caller is
+// responsible for skipping market orders and orders that override the band.
+func (c *Core) checkPriceBand(dc *dexConnection, base, quote uint32, rate uint64, baseUnits, quoteUnits dex.UnitInfo) error {
+	band := c.PriceBand()
+	if band == 0 {
+		return nil
+	}
+	ref, found := c.referenceRate(dc, base, quote, baseUnits, quoteUnits)
+	if !found {
+		c.log.Warnf("Not checking the price band of a %s limit order. No reference price.",
+			marketName(base, quote))
+		return nil
+	}
+	deviation := math.Abs(float64(rate)-float64(ref)) / float64(ref) * 100
+	if deviation > band {
+		return newError(orderParamsErr, "rate %s deviates from the reference price %s by %.2f%%, "+
+			"which exceeds the price band of %.2f%%. override the price band to place the order anyway",
+			dex.FormatMessageRate(rate, baseUnits, quoteUnits),
+			dex.FormatMessageRate(ref, baseUnits, quoteUnits), deviation, band)
+	}
+	return nil
+}
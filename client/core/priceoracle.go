@@ -0,0 +1,170 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex/calc"
+)
+
+const (
+	defaultOracleThreshold = 0.1
+	defaultOracleInterval  = 5 * time.Minute
+	oracleRequestTimeout   = 30 * time.Second
+)
+
+// PriceOracle is an external source of reference prices.
+type PriceOracle interface {
+	// Price returns the reference price of the base asset in conventional
+	// units of the quote asset per conventional unit of the base asset.
+	Price(ctx context.Context, baseID, quoteID uint32) (float64, error)
+}
+
+// fiatPriceOracle is the default PriceOracle. Reference prices are the ratio
+// of the base and quote assets' cached fiat exchange rates.
+type fiatPriceOracle struct {
+	c *Core
+}
+
+var _ PriceOracle = (*fiatPriceOracle)(nil)
+
+// Price returns the ratio of the cached fiat exchange rates of the base and
+// quote assets. An error is returned if either rate is not known.
+func (o *fiatPriceOracle) Price(_ context.Context, baseID, quoteID uint32) (float64, error) {
+	baseFiat, quoteFiat := o.c.fiatRate(baseID), o.c.fiatRate(quoteID)
+	if baseFiat == 0 || quoteFiat == 0 {
+		return 0, fmt.Errorf("no fiat rates for %s-%s", unbip(baseID), unbip(quoteID))
+	}
+	return baseFiat / quoteFiat, nil
+}
+
+// PriceOracleConfig is the configuration for comparing the mid-gap prices of
+// synced markets with the prices from a PriceOracle. A warning notification is
+// emitted when a market's price diverges from the reference price by more than
+// Threshold.
+type PriceOracleConfig struct {
+	// Oracle is the source of reference prices. It is also used by the price
+	// band guard when an order's market is not synced. If nil, the reference
+	// prices are the ratios of the cached fiat exchange rates.
+	Oracle PriceOracle
+	// Threshold is the largest tolerated divergence of a market's mid-gap
+	// price from the reference price, as a fraction of the reference price.
+	// The default is 0.1.
+	Threshold float64
+	// Interval is the time between checks. The default is 5 minutes.
+	Interval time.Duration
+}
+
+// priceOracleChecker compares market prices with an oracle's reference prices,
+// tracking the markets that have been flagged so that a divergence is only
+// reported once, until the market's price realigns.
+type priceOracleChecker struct {
+	threshold float64
+	interval  time.Duration
+
+	mtx     sync.Mutex
+	flagged map[string]bool // host + market name
+}
+
+func newPriceOracleChecker(cfg *PriceOracleConfig) (*priceOracleChecker, error) {
+	if cfg.Threshold < 0 || math.IsNaN(cfg.Threshold) {
+		return nil, fmt.Errorf("invalid price oracle threshold %f", cfg.Threshold)
+	}
+	threshold := cfg.Threshold
+	if threshold == 0 {
+		threshold = defaultOracleThreshold
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultOracleInterval
+	}
+	return &priceOracleChecker{
+		threshold: threshold,
+		interval:  interval,
+		flagged:   make(map[string]bool),
+	}, nil
+}
+
+// divergence is the relative difference of the price from the reference price.
+func divergence(price, ref float64) float64 {
+	return math.Abs(price-ref) / ref
+}
+
+// setFlagged records whether the market is diverged, returning true if the
+// market was not already flagged.
+func (p *priceOracleChecker) setFlagged(mktKey string, diverged bool) bool {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	wasFlagged := p.flagged[mktKey]
+	if diverged {
+		p.flagged[mktKey] = true
+	} else {
+		delete(p.flagged, mktKey)
+	}
+	return diverged && !wasFlagged
+}
+
+// watchPriceOracle checks the prices of the synced markets every interval
+// until the context is canceled.
+func (c *Core) watchPriceOracle(ctx context.Context) {
+	tick := time.NewTicker(c.priceOracle.interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			c.checkOraclePrices(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkOraclePrices compares the mid-gap price of each synced market with the
+// oracle's reference price, and emits a warning notification for any market
+// that has newly diverged beyond the threshold.
+func (c *Core) checkOraclePrices(ctx context.Context) {
+	p := c.priceOracle
+	for _, dc := range c.dexConnections() {
+		dc.booksMtx.RLock()
+		books := make(map[string]*bookie, len(dc.books))
+		for mkt, book := range dc.books {
+			books[mkt] = book
+		}
+		dc.booksMtx.RUnlock()
+
+		for mkt, book := range books {
+			midGap, err := book.MidGap()
+			if err != nil {
+				continue // empty side
+			}
+			price := calc.ConventionalRate(midGap, book.baseUnits, book.quoteUnits)
+			reqCtx, cancel := context.WithTimeout(ctx, oracleRequestTimeout)
+			ref, err := c.oracle.Price(reqCtx, book.base, book.quote)
+			cancel()
+			if err != nil {
+				c.log.Debugf("No reference price for %s: %v", mkt, err)
+				continue
+			}
+			if ref <= 0 || math.IsNaN(ref) || math.IsInf(ref, 0) {
+				c.log.Warnf("Invalid reference price %f for %s", ref, mkt)
+				continue
+			}
+			div := divergence(price, ref)
+			if !p.setFlagged(dc.acct.host+"|"+mkt, div > p.threshold) {
+				continue
+			}
+			c.log.Warnf("%s market %s price %f diverges %.1f%% from the reference price %f",
+				dc.acct.host, mkt, price, div*100, ref)
+			subject, details := c.formatDetails(TopicPriceDivergence, dc.acct.host, mkt,
+				price, ref, div*100)
+			c.notify(newServerNotifyNote(TopicPriceDivergence, subject, details, db.WarningLevel))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"fmt"
+	"math/big"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/msgjson"
+)
+
+// MarketQuantization returns the quantization rules for orders on the market,
+// as described by the server's market configuration. Order quantities must be
+// a multiple of the lot size, except for market buys, which are denominated in
+// the quote asset. Limit order rates must be a multiple of the rate step.
+func (c *Core) MarketQuantization(host string, base, quote uint32) (*MarketQuantization, error) {
+	dc, _, err := c.dex(host)
+	if err != nil {
+		return nil, err
+	}
+	mktConf := dc.marketConfig(marketName(base, quote))
+	if mktConf == nil {
+		return nil, newError(marketErr, "unknown market %s", marketName(base, quote))
+	}
+	baseAsset, quoteAsset := dc.assetConfig(base), dc.assetConfig(quote)
+	if baseAsset == nil || quoteAsset == nil {
+		return nil, fmt.Errorf("no asset config for market %s", mktConf.Name)
+	}
+	baseFactor := baseAsset.UnitInfo.Conventional.ConversionFactor
+	quoteFactor := quoteAsset.UnitInfo.Conventional.ConversionFactor
+
+	// A conventional rate is the message rate * baseFactor / (quoteFactor *
+	// RateEncodingFactor).
+	rateStep := new(big.Int).Mul(new(big.Int).SetUint64(mktConf.RateStep), new(big.Int).SetUint64(baseFactor))
+	rateFactor := new(big.Int).Mul(new(big.Int).SetUint64(quoteFactor), big.NewInt(calc.RateEncodingFactor))
+
+	return &MarketQuantization{
+		LotSize:       mktConf.LotSize,
+		RateStep:      mktConf.RateStep,
+		MinOrderLots:  mktConf.MinOrderLots,
+		QtyPrecision:  dex.DecimalPlaces(new(big.Int).SetUint64(mktConf.LotSize), new(big.Int).SetUint64(baseFactor)),
+		RatePrecision: dex.DecimalPlaces(rateStep, rateFactor),
+	}, nil
+}
+
+// checkQuantization checks that an order's quantity and rate respect the
+// market's lot size and rate step, so that orders that the server would
+// reject are caught before they are funded. Market buy quantities are in the
+// quote asset and are not lot-quantized.
+func checkQuantization(mktConf *msgjson.Market, qty, rate uint64, isLimit, sell bool) error {
+	if (isLimit || sell) && mktConf.LotSize > 0 && qty%mktConf.LotSize != 0 {
+		return newError(orderParamsErr, "quantity %d is not a multiple of the market's lot size %d",
+			qty, mktConf.LotSize)
+	}
+	if isLimit && mktConf.RateStep > 0 && rate%mktConf.RateStep != 0 {
+		return newError(orderParamsErr, "rate %d is not a multiple of the market's rate step %d",
+			rate, mktConf.RateStep)
+	}
+	return nil
+}
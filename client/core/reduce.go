@@ -0,0 +1,195 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/order"
+)
+
+var (
+	// reduceOrderPollInterval is how often the status of an order being
+	// reduced is checked while waiting for its cancel order to match.
+	reduceOrderPollInterval = 250 * time.Millisecond
+	// reduceOrderWaitEpochs is the number of epochs to wait for the cancel
+	// order of an order being reduced to match before giving up.
+	reduceOrderWaitEpochs uint64 = 3
+)
+
+// ReduceOrder reduces the quantity of a standing limit order to qty. There is
+// no protocol support for amending a booked order, so the order is canceled
+// and a new standing limit order for the reduced quantity is placed at the same
+// rate. The new order does NOT keep the original order's time priority on the
+// book, and is placed behind any other orders at the same rate.
+//
+// The replacement is placed as soon as the cancel order is matched to minimize
+// the time that neither order is booked. If the original order is partially
+// filled before the cancel order is matched, the replacement is for the lesser
+// of qty and the unfilled remainder that was canceled, so the total that may
+// be filled never exceeds what was requested. ReduceOrder blocks until the
+// replacement is placed, or returns an error if the cancel order does not
+// match within a few epochs, in which case the original order may still be
+// booked. If the original order has a time to live, the replacement is canceled
+// at the same time. If the replacement cannot be placed after the original is
+// canceled, an error notification with the original order is sent. The
+// replacement order is returned.
+func (c *Core) ReduceOrder(pw []byte, oidB dex.Bytes, qty uint64) (*Order, error) {
+	oid, err := order.IDFromBytes(oidB)
+	if err != nil {
+		return nil, err
+	}
+	// Check the password before canceling, so that the replacement is not
+	// refused after the original order is gone.
+	crypter, err := c.encryptionKey(pw)
+	if err != nil {
+		return nil, codedError(passwordErr, err)
+	}
+	crypter.Close()
+
+	var dc *dexConnection
+	var tracker *trackedTrade
+	for _, conn := range c.dexConnections() {
+		if tracker, _, _ = conn.findOrder(oid); tracker != nil {
+			dc = conn
+			break
+		}
+	}
+	if tracker == nil {
+		return nil, newError(unknownOrderErr, "order %s not found", oid)
+	}
+
+	form, err := c.reduceOrder(dc, tracker, qty)
+	if err != nil {
+		return nil, err
+	}
+	corder, err := c.Trade(pw, form)
+	if err != nil {
+		c.notifyReduceFailed(dc, tracker, form, err)
+		return nil, err
+	}
+	return corder, nil
+}
+
+// notifyReduceFailed sends an error notification with the original order when
+// its replacement could not be placed after it was canceled.
+func (c *Core) notifyReduceFailed(dc *dexConnection, tracker *trackedTrade, form *TradeForm, err error) {
+	c.log.Errorf("Order %s was canceled to reduce it, but the replacement order could not be placed: %v",
+		tracker.ID(), err)
+	qty, unit := strconv.FormatUint(form.Qty, 10), dex.BipIDSymbol(form.Base)
+	if wallets, _, _, wErr := c.walletSet(dc, form.Base, form.Quote, form.Sell); wErr == nil {
+		ui := wallets.baseWallet.Info().UnitInfo
+		qty, unit = ui.ConventionalString(form.Qty), ui.Conventional.Unit
+	}
+	subject, details := c.formatDetails(TopicOrderReduceFailed, makeOrderToken(tracker.token()), qty, unit, err)
+	c.notify(newOrderNote(TopicOrderReduceFailed, subject, details, db.ErrorLevel, tracker.coreOrder()))
+}
+
+// reduceOrder cancels the standing limit order and waits for the cancel order
+// to match, returning the TradeForm for the replacement order.
+func (c *Core) reduceOrder(dc *dexConnection, tracker *trackedTrade, qty uint64) (*TradeForm, error) {
+	oid := tracker.ID()
+	lo, ok := tracker.Order.(*order.LimitOrder)
+	if !ok || lo.Force != order.StandingTiF {
+		return nil, newError(orderParamsErr, "cannot reduce %s order %s that is not a standing limit order", tracker.Type(), oid)
+	}
+	mktConf := dc.marketConfig(tracker.mktID)
+	if mktConf == nil {
+		return nil, newError(marketErr, "unknown market %q", tracker.mktID)
+	}
+	if qty == 0 || qty%mktConf.LotSize != 0 {
+		return nil, newError(orderParamsErr, "quantity %d is not a non-zero multiple of the market's lot size %d", qty, mktConf.LotSize)
+	}
+	if remaining := tracker.Trade().Remaining(); qty >= remaining {
+		return nil, newError(orderParamsErr, "quantity %d is not less than the order's unfilled remainder %d", qty, remaining)
+	}
+	// Make sure the replacement order can be funded by the same wallets.
+	if _, _, _, err := c.walletSet(dc, lo.BaseAsset, lo.QuoteAsset, lo.Sell); err != nil {
+		return nil, err
+	}
+
+	tracker.mtx.RLock()
+	options := make(map[string]string, len(tracker.metaData.Options))
+	for k, v := range tracker.metaData.Options {
+		options[k] = v
+	}
+	cancelAfter := tracker.metaData.CancelAfter
+	tracker.mtx.RUnlock()
+	if cancelAfter != 0 && time.Now().UnixMilli() >= int64(cancelAfter) {
+		return nil, newError(orderParamsErr, "order %s has reached its time to live", oid)
+	}
+
+	if err := c.tryCancelTrade(dc, tracker); err != nil {
+		return nil, err
+	}
+
+	canceled, err := c.waitForCancelMatch(tracker)
+	if err != nil {
+		return nil, err
+	}
+	if canceled < qty {
+		c.log.Infof("Order %s was filled to %d remaining before it was canceled. Replacement order quantity reduced from %d.",
+			oid, canceled, qty)
+		qty = canceled
+	}
+
+	// The replacement is canceled when the original would have been.
+	var ttl uint64
+	if cancelAfter != 0 {
+		remaining := time.Until(time.UnixMilli(int64(cancelAfter)))
+		ttl = uint64(math.Max(math.Ceil(remaining.Seconds()), 1))
+	}
+
+	// The replacement is at the rate of the order that was already booked, so
+	// it must not be refused by the price band after the original is gone.
+	return &TradeForm{
+		Host:              dc.acct.host,
+		IsLimit:           true,
+		Sell:              lo.Sell,
+		Base:              lo.BaseAsset,
+		Quote:             lo.QuoteAsset,
+		Qty:               qty,
+		Rate:              lo.Rate,
+		TTL:               ttl,
+		Options:           options,
+		OverridePriceBand: true,
+	}, nil
+}
+
+// waitForCancelMatch waits for the cancel order targeting the trade to match,
+// and returns the quantity that was canceled.
+func (c *Core) waitForCancelMatch(tracker *trackedTrade) (uint64, error) {
+	oid := tracker.ID()
+	deadline := time.Now().Add(time.Duration(reduceOrderWaitEpochs*tracker.epochLen()) * time.Millisecond)
+	ticker := time.NewTicker(reduceOrderPollInterval)
+	defer ticker.Stop()
+	for {
+		tracker.mtx.RLock()
+		status, cancel := tracker.metaData.Status, tracker.cancel
+		tracker.mtx.RUnlock()
+		switch {
+		case status == order.OrderStatusCanceled && cancel != nil && cancel.matches.maker != nil:
+			return cancel.matches.maker.Quantity, nil
+		case status == order.OrderStatusExecuted:
+			return 0, fmt.Errorf("order %s was filled before it could be canceled", oid)
+		case status == order.OrderStatusRevoked:
+			return 0, fmt.Errorf("order %s was revoked before it could be canceled", oid)
+		case cancel == nil:
+			return 0, fmt.Errorf("cancel order targeting order %s did not match. The order was not reduced", oid)
+		}
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("timed out waiting for the cancel order targeting order %s to match", oid)
+		}
+		select {
+		case <-ticker.C:
+		case <-c.ctx.Done():
+			return 0, c.ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"decred.org/dcrdex/client/db"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/encode"
+)
+
+const (
+	// minScheduleInterval is the shortest allowed interval between the orders
+	// of an order schedule.
+	minScheduleInterval = time.Hour
+	// scheduleCheckInterval is how often the order schedules are checked for
+	// due placements.
+	scheduleCheckInterval = time.Minute
+)
+
+// CreateOrderSchedule creates a schedule that places an order with the
+// parameters of the template every interval, e.g. for dollar-cost averaging.
+// The template's Name is the schedule's label. The first order is placed at the
+// next check of the schedules. Before each placement, the balance of the wallet
+// that funds the order is checked, and if it is insufficient, the placement is
+// skipped and a warning notification is sent. Orders are placed without the
+// app password, so the wallets must be unlocked. Schedules are persisted.
+func (c *Core) CreateOrderSchedule(tmpl *db.OrderTemplate, interval time.Duration) (*db.OrderSchedule, error) {
+	if interval < minScheduleInterval {
+		return nil, newError(orderParamsErr, "schedule interval %s is less than the minimum of %s", interval, minScheduleInterval)
+	}
+	host, err := c.validateOrderTemplate(tmpl)
+	if err != nil {
+		return nil, newError(orderParamsErr, "invalid order schedule: %w", err)
+	}
+	sched := &db.OrderSchedule{
+		ID:            hex.EncodeToString(encode.RandomBytes(8)),
+		OrderTemplate: *tmpl,
+		Interval:      interval,
+		Next:          uint64(time.Now().UnixMilli()),
+	}
+	sched.Host = host
+
+	c.orderSchedulesMtx.Lock()
+	defer c.orderSchedulesMtx.Unlock()
+	if err := c.db.SaveOrderSchedule(sched); err != nil {
+		return nil, codedError(dbErr, err)
+	}
+	return sched, nil
+}
+
+// OrderSchedules returns the stored order schedules.
+func (c *Core) OrderSchedules() ([]*db.OrderSchedule, error) {
+	c.orderSchedulesMtx.Lock()
+	defer c.orderSchedulesMtx.Unlock()
+	return c.db.OrderSchedules()
+}
+
+// PauseOrderSchedule pauses or resumes the order schedule with the ID. If a
+// placement was missed while the schedule was paused, an order is placed at the
+// next check after the schedule is resumed.
+func (c *Core) PauseOrderSchedule(id string, pause bool) error {
+	c.orderSchedulesMtx.Lock()
+	defer c.orderSchedulesMtx.Unlock()
+	sched, err := c.orderSchedule(id)
+	if err != nil {
+		return err
+	}
+	sched.Paused = pause
+	if err := c.db.SaveOrderSchedule(sched); err != nil {
+		return codedError(dbErr, err)
+	}
+	return nil
+}
+
+// CancelOrderSchedule deletes the order schedule with the ID. Orders that were
+// already placed are not canceled.
+func (c *Core) CancelOrderSchedule(id string) error {
+	c.orderSchedulesMtx.Lock()
+	defer c.orderSchedulesMtx.Unlock()
+	return c.db.DeleteOrderSchedule(id)
+}
+
+// orderSchedule retrieves the order schedule with the ID. The
+// orderSchedulesMtx must be held.
+func (c *Core) orderSchedule(id string) (*db.OrderSchedule, error) {
+	scheds, err := c.db.OrderSchedules()
+	if err != nil {
+		return nil, codedError(dbErr, err)
+	}
+	for _, sched := range scheds {
+		if sched.ID == id {
+			return sched, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", db.ErrNoSchedule, id)
+}
+
+// watchOrderSchedules places the orders of the order schedules as they come
+// due.
+func (c *Core) watchOrderSchedules(ctx context.Context) {
+	tick := time.NewTicker(scheduleCheckInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case now := <-tick.C:
+			c.runOrderSchedules(now)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runOrderSchedules places the orders of the schedules that are due at the
+// specified time. A schedule's next placement is advanced to its first interval
+// after now, so only one order is placed for placements that were missed while
+// the client was not running. The orders are placed without the
+// orderSchedulesMtx held.
+func (c *Core) runOrderSchedules(now time.Time) {
+	for _, sched := range c.dueOrderSchedules(now) {
+		placed := c.placeScheduledOrder(sched)
+		c.recordScheduledPlacement(sched.ID, placed)
+	}
+}
+
+// dueOrderSchedules returns the schedules that are due at the specified time,
+// after advancing their next placement to the first interval after now.
+func (c *Core) dueOrderSchedules(now time.Time) []*db.OrderSchedule {
+	c.orderSchedulesMtx.Lock()
+	defer c.orderSchedulesMtx.Unlock()
+	scheds, err := c.db.OrderSchedules()
+	if err != nil {
+		c.log.Errorf("Error loading order schedules: %v", err)
+		return nil
+	}
+	var due []*db.OrderSchedule
+	for _, sched := range scheds {
+		next := time.UnixMilli(int64(sched.Next))
+		if sched.Paused || now.Before(next) {
+			continue
+		}
+		for !next.After(now) {
+			next = next.Add(sched.Interval)
+		}
+		sched.Next = uint64(next.UnixMilli())
+		if err := c.db.SaveOrderSchedule(sched); err != nil {
+			c.log.Errorf("Error storing order schedule %s: %v", sched.ID, err)
+			continue
+		}
+		due = append(due, sched)
+	}
+	return due
+}
+
+// recordScheduledPlacement counts a placed or skipped order for the schedule
+// with the ID. The schedule may have been canceled while the order was being
+// placed, in which case there is nothing to record.
+func (c *Core) recordScheduledPlacement(id string, placed bool) {
+	c.orderSchedulesMtx.Lock()
+	defer c.orderSchedulesMtx.Unlock()
+	sched, err := c.orderSchedule(id)
+	if err != nil {
+		if !errors.Is(err, db.ErrNoSchedule) {
+			c.log.Errorf("Error loading order schedule %s: %v", id, err)
+		}
+		return
+	}
+	if placed {
+		sched.Placed++
+	} else {
+		sched.Skipped++
+	}
+	if err := c.db.SaveOrderSchedule(sched); err != nil {
+		c.log.Errorf("Error storing order schedule %s: %v", sched.ID, err)
+	}
+}
+
+// placeScheduledOrder places the order of the schedule if the balance of the
+// wallet that funds it is sufficient, and reports whether the order was placed.
+// The required balance does not include fees.
+func (c *Core) placeScheduledOrder(sched *db.OrderSchedule) bool {
+	mktID := marketName(sched.Base, sched.Quote)
+	fail := func(err error) bool {
+		c.log.Errorf("Error placing the %s order of schedule %s (%q): %v", mktID, sched.ID, sched.Name, err)
+		subject, details := c.formatDetails(TopicScheduledOrderFailed, mktID, sched.Name, err)
+		s := *sched
+		c.notify(newOrderScheduleNote(TopicScheduledOrderFailed, subject, details, db.ErrorLevel, &s))
+		return false
+	}
+
+	form, err := c.tradeFormFromTemplate(&sched.OrderTemplate, nil)
+	if err != nil {
+		return fail(err)
+	}
+	fromID, required := form.Base, form.Qty
+	if !form.Sell {
+		fromID = form.Quote
+		if form.IsLimit {
+			required = calc.BaseToQuote(form.Rate, form.Qty)
+		}
+	}
+	w, found := c.wallet(fromID)
+	if !found {
+		return fail(newError(missingWalletErr, "no %s wallet", unbip(fromID)))
+	}
+	bal, err := c.updateWalletBalance(w)
+	if err != nil {
+		return fail(fmt.Errorf("error getting %s balance: %w", unbip(fromID), err))
+	}
+	if bal.Available < required {
+		ui := w.Info().UnitInfo
+		c.log.Warnf("Skipping the %s order of schedule %s (%q). Available %s balance %d < %d required",
+			mktID, sched.ID, sched.Name, unbip(fromID), bal.Available, required)
+		subject, details := c.formatDetails(TopicScheduledOrderSkipped, mktID, sched.Name, unbip(fromID),
+			ui.ConventionalString(bal.Available), ui.ConventionalString(required))
+		s := *sched
+		c.notify(newOrderScheduleNote(TopicScheduledOrderSkipped, subject, details, db.WarningLevel, &s))
+		return false
+	}
+	if _, err := c.Trade(nil, form); err != nil {
+		return fail(err)
+	}
+	return true
+}
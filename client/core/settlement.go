@@ -0,0 +1,109 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"math"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+)
+
+// SettlementEstimate is the estimated time for a swap to settle once matched,
+// in milliseconds. The range is where the settlement time is expected to fall,
+// given the random arrival of blocks.
+type SettlementEstimate struct {
+	MinMS      uint64 `json:"minMS"`
+	ExpectedMS uint64 `json:"expectedMS"`
+	MaxMS      uint64 `json:"maxMS"`
+}
+
+// baseFeeDecay is the max fractional decrease of an EIP-1559 base fee in one
+// block.
+const baseFeeDecay = 1.0 / 8
+
+// chainInfo returns the WalletInfo describing the asset's chain, which for a
+// token is the parent asset's. A wallet's Info is preferred over the driver's.
+func (c *Core) chainInfo(assetID uint32) (*asset.WalletInfo, error) {
+	if token := asset.TokenInfo(assetID); token != nil {
+		return asset.Info(token.ParentID)
+	}
+	if w, found := c.wallet(assetID); found {
+		return w.Info(), nil
+	}
+	return asset.Info(assetID)
+}
+
+// feeInclusionBlocks estimates the number of blocks that an account-based
+// asset's swap transaction waits to be mined if the network's current fee rate
+// is higher than the max fee rate of the swap, since the base fee can drop by
+// no more than baseFeeDecay per block. Without a connected wallet to report the
+// current fee rate, no wait is assumed.
+func (c *Core) feeInclusionBlocks(assetID uint32, maxFeeRate uint64) float64 {
+	w, found := c.wallet(assetID)
+	if !found || !w.connected() || maxFeeRate == 0 {
+		return 0
+	}
+	rater, is := w.Wallet.(asset.FeeRater)
+	if !is {
+		return 0
+	}
+	feeRate := rater.FeeRate()
+	if feeRate <= maxFeeRate {
+		return 0
+	}
+	return math.Ceil(math.Log(float64(feeRate)/float64(maxFeeRate)) / -math.Log(1-baseFeeDecay))
+}
+
+// EstimateSettlementTime estimates how long a swap on the market will take to
+// settle once matched, from the typical block interval of each asset's chain
+// and the swap confirmations required by the server. Each side of the swap
+// must reach the required confirmations, and then its redemption must be
+// mined. Block times of proof-of-work chains are exponentially distributed,
+// and the range is two standard deviations either side of the expected time.
+// Account-based chains have regular block times, but a swap may wait for the
+// base fee to drop below the swap's max fee rate.
+func (c *Core) EstimateSettlementTime(host string, base, quote uint32) (*SettlementEstimate, error) {
+	dc, _, err := c.dex(host)
+	if err != nil {
+		return nil, err
+	}
+	mktID := marketName(base, quote)
+	if dc.marketConfig(mktID) == nil {
+		return nil, newError(marketErr, "unknown market %s at %s", mktID, dc.acct.host)
+	}
+
+	var expected, variance float64 // seconds, seconds^2
+	for _, assetID := range []uint32{base, quote} {
+		assetCfg := dc.assetConfig(assetID)
+		if assetCfg == nil {
+			return nil, newError(assetSupportErr, "no %s asset config at %s", unbip(assetID), dc.acct.host)
+		}
+		info, err := c.chainInfo(assetID)
+		if err != nil {
+			return nil, newError(assetSupportErr, "unsupported asset %s: %w", unbip(assetID), err)
+		}
+		if info.BlockInterval <= 0 {
+			return nil, newError(assetSupportErr, "unknown block interval for %s", unbip(assetID))
+		}
+		interval := info.BlockInterval.Seconds()
+		blocks := float64(assetCfg.SwapConf) + 1
+		if info.IsAccountBased {
+			blocks += c.feeInclusionBlocks(assetID, assetCfg.MaxFeeRate)
+		} else {
+			variance += blocks * interval * interval
+		}
+		expected += blocks * interval
+	}
+
+	dev := 2 * math.Sqrt(variance)
+	toMS := func(secs float64) uint64 {
+		return uint64(math.Round(secs * float64(time.Second/time.Millisecond)))
+	}
+	return &SettlementEstimate{
+		MinMS:      toMS(math.Max(expected-dev, 0)),
+		ExpectedMS: toMS(expected),
+		MaxMS:      toMS(expected + dev),
+	}, nil
+}
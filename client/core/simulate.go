@@ -0,0 +1,126 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/encode"
+)
+
+// SimulateTrade does a dry run of the trade described by the form. The order is
+// funded and the swap transaction for a single match of the full quantity is
+// constructed, but nothing is broadcast, the order is not submitted, and the
+// funding coins are returned to the wallet before SimulateTrade returns. The
+// wallet funding the order must implement asset.SwapSimulator. Wallet options
+// are not applied, and the order is funded without a split transaction.
+func (c *Core) SimulateTrade(pw []byte, form *TradeForm) (*SimulatedTrade, error) {
+	wallets, assetConfigs, dc, mktConf, err := c.prepareForTradeRequestPrep(pw, form.Base, form.Quote, form.Host, form.Sell)
+	if err != nil {
+		return nil, err
+	}
+	fromWallet, fromAsset := wallets.fromWallet, assetConfigs.fromAsset
+	simulator, is := fromWallet.Wallet.(asset.SwapSimulator)
+	if !is {
+		return nil, newError(walletErr, "%s wallet does not support dry runs", unbip(fromWallet.AssetID))
+	}
+
+	if form.QtyInQuote {
+		if form, err = quoteQtyLimitForm(form, mktConf); err != nil {
+			return nil, err
+		}
+	}
+
+	rate, qty := form.Rate, form.Qty
+	if form.IsLimit && rate == 0 {
+		return nil, newError(orderParamsErr, "zero-rate order not allowed")
+	}
+	lots := qty / mktConf.LotSize
+	fundQty := qty
+	if !form.Sell {
+		if form.IsLimit {
+			fundQty = calc.BaseToQuote(rate, qty)
+		} else {
+			lots = 1 // market buy quantity is in the quote asset
+		}
+	}
+	if lots == 0 || fundQty == 0 {
+		return nil, newError(orderParamsErr, "order quantity < 1 lot. qty = %d %s, rate = %d, lot size = %d",
+			qty, assetConfigs.baseAsset.Symbol, rate, mktConf.LotSize)
+	}
+
+	feeRate := c.feeSuggestion(dc, fromAsset.ID)
+	if feeRate == 0 {
+		feeRate = fromAsset.MaxFeeRate
+	}
+
+	coins, _, fundingFees, err := fromWallet.FundOrder(&asset.Order{
+		Version:       fromAsset.Version,
+		Value:         fundQty,
+		MaxSwapCount:  lots,
+		MaxFeeRate:    fromAsset.MaxFeeRate,
+		Immediate:     !form.IsLimit || form.TifNow,
+		FeeSuggestion: feeRate,
+		RedeemVersion: assetConfigs.toAsset.Version,
+		RedeemAssetID: assetConfigs.toAsset.ID,
+		DryRun:        true,
+	})
+	if err != nil {
+		return nil, codedError(walletErr, fmt.Errorf("FundOrder error for %s, funding quantity %d (%d lots): %w",
+			fromAsset.Symbol, fundQty, lots, err))
+	}
+	defer func() {
+		if err := fromWallet.ReturnCoins(coins); err != nil {
+			c.log.Errorf("Unable to return %s funding coins after dry run: %v", unbip(fromWallet.AssetID), err)
+		}
+	}()
+
+	// The user's own address stands in for the counterparty's.
+	counterAddr, err := fromWallet.RedemptionAddress()
+	if err != nil {
+		return nil, codedError(walletErr, fmt.Errorf("%s RedemptionAddress error: %w", fromAsset.Symbol, err))
+	}
+	if returner, is := fromWallet.Wallet.(asset.AddressReturner); is {
+		defer returner.ReturnRedemptionAddress(counterAddr)
+	}
+	secretHash := sha256.Sum256(encode.RandomBytes(32))
+
+	sim, err := simulator.SimulateSwap(&asset.Swaps{
+		Version: fromAsset.Version,
+		Inputs:  coins,
+		Contracts: []*asset.Contract{{
+			Address:    counterAddr,
+			Value:      fundQty,
+			SecretHash: secretHash[:],
+			LockTime:   uint64(time.Now().Add(c.lockTimeMaker).Unix()),
+		}},
+		FeeRate: feeRate,
+	})
+	if err != nil {
+		return nil, codedError(walletErr, fmt.Errorf("error simulating %s swap: %w", fromAsset.Symbol, err))
+	}
+
+	fundingCoins := make([]string, 0, len(coins))
+	for _, coin := range coins {
+		fundingCoins = append(fundingCoins, coin.String())
+	}
+	var contract []byte
+	if len(sim.Contracts) > 0 {
+		contract = sim.Contracts[0]
+	}
+	return &SimulatedTrade{
+		Simulated:    true,
+		FundingCoins: fundingCoins,
+		FundingFees:  fundingFees,
+		SwapFeeRate:  feeRate,
+		SwapTxID:     sim.TxID,
+		SwapTx:       sim.Tx,
+		Contract:     contract,
+		SwapFees:     sim.Fees,
+	}, nil
+}
@@ -0,0 +1,152 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"decred.org/dcrdex/client/db"
+)
+
+// SaveOrderTemplate validates and stores an order template, replacing any
+// template with the same name.
+func (c *Core) SaveOrderTemplate(tmpl *db.OrderTemplate) error {
+	if tmpl.Name == "" {
+		return errors.New("order template has no name")
+	}
+	host, err := c.validateOrderTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+	t := *tmpl
+	t.Host = host
+	return c.db.SaveOrderTemplate(&t)
+}
+
+// validateOrderTemplate checks the template's order parameters, and returns the
+// normalized host of its DEX.
+func (c *Core) validateOrderTemplate(tmpl *db.OrderTemplate) (string, error) {
+	dc, _, err := c.dex(tmpl.Host)
+	if err != nil {
+		return "", err
+	}
+	mktID := marketName(tmpl.Base, tmpl.Quote)
+	if dc.marketConfig(mktID) == nil {
+		return "", fmt.Errorf("unknown market %s at %s", mktID, dc.acct.host)
+	}
+	if tmpl.Qty == 0 {
+		return "", errors.New("zero quantity not allowed")
+	}
+	if math.IsNaN(tmpl.RateOffset) || math.IsInf(tmpl.RateOffset, 0) {
+		return "", fmt.Errorf("invalid rate offset %f", tmpl.RateOffset)
+	}
+	if tmpl.IsLimit {
+		if tmpl.RateOffset <= -1 {
+			return "", fmt.Errorf("rate offset %f would not leave a positive rate", tmpl.RateOffset)
+		}
+	} else if tmpl.RateOffset != 0 {
+		return "", errors.New("a rate offset cannot be used with a market order")
+	}
+	return dc.acct.host, nil
+}
+
+// OrderTemplates returns the stored order templates, sorted by name.
+func (c *Core) OrderTemplates() ([]*db.OrderTemplate, error) {
+	return c.db.OrderTemplates()
+}
+
+// DeleteOrderTemplate deletes the named order template.
+func (c *Core) DeleteOrderTemplate(name string) error {
+	return c.db.DeleteOrderTemplate(name)
+}
+
+// PlaceFromTemplate places an order with the parameters of the named order
+// template, changed by any overrides. The rate of a limit order is the mid-gap
+// rate offset by the template's rate offset, rounded to the market's rate
+// step, so the market's order book must be synced with SyncBook unless an
+// override rate is given.
+func (c *Core) PlaceFromTemplate(pw []byte, name string, overrides *TemplateOverrides) (*Order, error) {
+	form, err := c.templateTradeForm(name, overrides)
+	if err != nil {
+		return nil, err
+	}
+	return c.Trade(pw, form)
+}
+
+// templateTradeForm materializes the TradeForm for an order from the named
+// order template.
+func (c *Core) templateTradeForm(name string, overrides *TemplateOverrides) (*TradeForm, error) {
+	tmpls, err := c.db.OrderTemplates()
+	if err != nil {
+		return nil, fmt.Errorf("error loading order templates: %w", err)
+	}
+	var tmpl *db.OrderTemplate
+	for _, t := range tmpls {
+		if t.Name == name {
+			tmpl = t
+			break
+		}
+	}
+	if tmpl == nil {
+		return nil, fmt.Errorf("%w: %q", db.ErrNoTemplate, name)
+	}
+	return c.tradeFormFromTemplate(tmpl, overrides)
+}
+
+// tradeFormFromTemplate materializes the TradeForm for an order from the order
+// template.
+func (c *Core) tradeFormFromTemplate(tmpl *db.OrderTemplate, overrides *TemplateOverrides) (*TradeForm, error) {
+	if overrides == nil {
+		overrides = new(TemplateOverrides)
+	}
+
+	form := &TradeForm{
+		Host:    tmpl.Host,
+		IsLimit: tmpl.IsLimit,
+		Sell:    tmpl.Sell,
+		Base:    tmpl.Base,
+		Quote:   tmpl.Quote,
+		Qty:     tmpl.Qty,
+		TifNow:  tmpl.TifNow,
+		Options: tmpl.Options,
+	}
+	if overrides.Qty != nil {
+		form.Qty = *overrides.Qty
+	}
+	if !tmpl.IsLimit {
+		if overrides.Rate != nil || overrides.RateOffset != nil {
+			return nil, errors.New("cannot set a rate for a market order")
+		}
+		return form, nil
+	}
+	if overrides.Rate != nil {
+		form.Rate = *overrides.Rate
+		return form, nil
+	}
+
+	offset := tmpl.RateOffset
+	if overrides.RateOffset != nil {
+		offset = *overrides.RateOffset
+	}
+	dc, book, err := c.syncedBook(tmpl.Host, tmpl.Base, tmpl.Quote)
+	if err != nil {
+		return nil, err
+	}
+	midGap, err := book.MidGap()
+	if err != nil {
+		return nil, fmt.Errorf("error getting mid-gap rate for template rate: %w", err)
+	}
+	mkt := dc.marketConfig(marketName(tmpl.Base, tmpl.Quote))
+	if mkt == nil {
+		return nil, fmt.Errorf("unknown market %s", marketName(tmpl.Base, tmpl.Quote))
+	}
+	steps := math.Round(float64(midGap) * (1 + offset) / float64(mkt.RateStep))
+	if steps < 1 {
+		return nil, fmt.Errorf("rate offset %f from mid-gap rate %d is less than the rate step", offset, midGap)
+	}
+	form.Rate = uint64(steps) * mkt.RateStep
+	return form, nil
+}
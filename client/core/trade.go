@@ -46,6 +46,16 @@ type matchTracker struct {
 	// setSwapConfirms, and setCounterConfirms.
 	counterConfirms int64 // atomic
 	swapConfirms    int64 // atomic
+	// counterSwapConfStamp is the unix millisecond time that the counterparty's
+	// swap was first seen with the server's required confirmations, or zero.
+	// It bounds the extra wait for the user's redemption confirmations. See
+	// redeemConfs.
+	counterSwapConfStamp int64 // atomic
+	// swapConfStamp is the unix millisecond time that our own swap was first
+	// seen with the server's required confirmations, or zero. The server
+	// starts waiting on the counterparty at this point. See
+	// checkCounterpartyInaction.
+	swapConfStamp int64 // atomic
 
 	// sendingInitAsync indicates if this match's init request is being sent to
 	// the server and awaiting a response. No attempts will be made to send
@@ -55,6 +65,14 @@ type matchTracker struct {
 	// to the server and awaiting a response. No attempts will be made to send
 	// another redeem request for this match while one is already active.
 	sendingRedeemAsync uint32 // atomic
+	// counterInactionNoted is one more than the match status for which the
+	// user was last notified of counterparty inaction, or zero if they have
+	// not been notified. See checkCounterpartyInaction.
+	counterInactionNoted uint32 // atomic
+	// redeemConfsAbandoned is set to 1 when the user's extra redemption
+	// confirmations are abandoned to protect the refund deadline, so that it
+	// is logged only once. See redeemConfs.
+	redeemConfsAbandoned uint32 // atomic
 
 	// The first group of fields below should be accessed with the parent
 	// trackedTrade's mutex locked, excluding the atomic fields.
@@ -106,6 +124,12 @@ type matchTracker struct {
 	// request. Additional requests will just error and they don't really care
 	// if we redeem as taker anyway.
 	matchCompleteSent bool
+	// lastDuplicateCheck is when the wallet was last searched for duplicates
+	// of our swap. See duplicateCheckDue.
+	lastDuplicateCheck time.Time
+	// duplicateSwitched is set when the match is re-bound to a duplicate swap
+	// that the counterparty acted on, so that it happens at most once.
+	duplicateSwitched bool
 
 	// The fields below need to be modified without the parent trackedTrade's
 	// mutex being write locked, so they have dedicated mutexes.
@@ -113,6 +137,13 @@ type matchTracker struct {
 	swapSpentTimeMtx sync.Mutex
 	swapSpentTime    time.Time
 
+	// swapMissingTime is when our own swap transaction was first found to be
+	// missing by the wallet, e.g. because it was replaced. swapLostNoted is set
+	// when the user is alerted that no replacement was found.
+	swapMissingMtx  sync.Mutex
+	swapMissingTime time.Time
+	swapLostNoted   bool
+
 	// lastExpireDur is the most recently logged time until expiry of the
 	// party's own contract. This may be negative if expiry has passed, but it
 	// is not yet refundable due to other consensus rules. This is used only by
@@ -160,6 +191,35 @@ func (m *matchTracker) swapSpent() {
 	m.swapSpentTime = time.Now()
 }
 
+// swapMissing records the first time that our own swap transaction was found
+// to be missing.
+func (m *matchTracker) swapMissing() {
+	m.swapMissingMtx.Lock()
+	defer m.swapMissingMtx.Unlock()
+	if m.swapMissingTime.IsZero() {
+		m.swapMissingTime = time.Now()
+	}
+}
+
+// swapFound clears the missing swap record.
+func (m *matchTracker) swapFound() {
+	m.swapMissingMtx.Lock()
+	defer m.swapMissingMtx.Unlock()
+	m.swapMissingTime = time.Time{}
+	m.swapLostNoted = false
+}
+
+// swapMissingAgo is how long our own swap transaction has been missing, or
+// zero if it is not missing.
+func (m *matchTracker) swapMissingAgo() time.Duration {
+	m.swapMissingMtx.Lock()
+	defer m.swapMissingMtx.Unlock()
+	if m.swapMissingTime.IsZero() {
+		return 0
+	}
+	return time.Since(m.swapMissingTime)
+}
+
 // setExpireDur records the last known duration until expiry if the difference
 // from the previous recorded duration is at least the provided log interval
 // threshold. The return indicates if it was updated (and should be logged by
@@ -200,6 +260,12 @@ func (m *matchTracker) setSwapConfirms(mine int64) {
 	atomic.StoreInt64(&m.swapConfirms, mine)
 }
 
+// swapConfirmed records the first time that our own swap was seen with the
+// server's required confirmations.
+func (m *matchTracker) swapConfirmed() {
+	atomic.CompareAndSwapInt64(&m.swapConfStamp, 0, time.Now().UnixMilli())
+}
+
 func (m *matchTracker) setCounterConfirms(theirs int64) (was int64) {
 	return atomic.SwapInt64(&m.counterConfirms, theirs)
 }
@@ -248,6 +314,15 @@ const (
 	// self-governed trade. We are less patient if the server is down or
 	// lacking the market or asset configs involved.
 	spentAgoThreshSelfGoverned = time.Minute
+
+	// swapReplacementTimeout is how long to search for a replacement of our own
+	// swap transaction after the wallet stops finding it before alerting the
+	// user.
+	swapReplacementTimeout = 20 * time.Minute
+
+	// duplicateSwapCheckInterval is how often the wallet is searched for
+	// duplicates of our own swap while the match is in progress.
+	duplicateSwapCheckInterval = 5 * time.Minute
 )
 
 // trackedTrade is an order (issued by this client), its matches, and its cancel
@@ -1148,6 +1223,11 @@ func (t *trackedTrade) counterPartyConfirms(ctx context.Context, match *matchTra
 			coin, t.wallets.toWallet.Symbol, match, t.UID(), err))
 	}
 
+	// As maker, this is a check before redeeming.
+	if match.Side == order.Maker {
+		needed = t.redeemConfs(match, needed, have, lockTime)
+	}
+
 	// Log the pending swap status at new heights only.
 	was := match.setCounterConfirms(int64(have))
 	if changed = was != int64(have); changed {
@@ -1157,6 +1237,49 @@ func (t *trackedTrade) counterPartyConfirms(ctx context.Context, match *matchTra
 	return
 }
 
+// redeemConfs is the number of confirmations to wait for on the counterparty's
+// swap before redeeming it. The user's RedeemConfs for the asset is used if it
+// is more than the server's requirement, but only until one of two deadlines:
+//
+//  1. Half of the broadcast timeout after the swap was first seen with the
+//     server's required confirmations. The server expects the maker's redeem
+//     within the broadcast timeout of the taker's swap reaching SwapConf, so
+//     the other half is left for our observation lag, the tick interval, and
+//     the redeem request itself.
+//  2. Half of the time from the match to the counterparty's lock time. Waiting
+//     longer could leave too little time to redeem before the counterparty can
+//     refund.
+//
+// After either deadline, only the server's requirement is used.
+func (t *trackedTrade) redeemConfs(match *matchTracker, serverConfs, have uint32, lockTime time.Time) uint32 {
+	userConfs := t.wallets.toWallet.redeemConfs
+	if userConfs <= serverConfs {
+		return serverConfs
+	}
+	now := time.Now()
+	if have >= serverConfs {
+		atomic.CompareAndSwapInt64(&match.counterSwapConfStamp, 0, now.UnixMilli())
+	}
+	matchTime := time.UnixMilli(int64(match.MetaData.Stamp))
+	deadline := matchTime.Add(lockTime.Sub(matchTime) / 2)
+	if confStamp := atomic.LoadInt64(&match.counterSwapConfStamp); confStamp > 0 {
+		bcastDeadline := time.UnixMilli(confStamp).Add(t.broadcastTimeout() / 2)
+		if bcastDeadline.Before(deadline) {
+			deadline = bcastDeadline
+		}
+	}
+	if now.Before(deadline) {
+		return userConfs
+	}
+	if atomic.CompareAndSwapUint32(&match.redeemConfsAbandoned, 0, 1) {
+		t.dc.log.Warnf("Not waiting for %d confirmations on the counterparty's %s swap for match %s, "+
+			"since the redeem is due by the server's broadcast timeout or the counterparty could "+
+			"refund at %v. Redeeming with %d confirmations.",
+			userConfs, t.wallets.toWallet.Symbol, match, lockTime, serverConfs)
+	}
+	return serverConfs
+}
+
 // deleteCancelOrder will clear any associated trackedCancel, and set the status
 // of the cancel order as revoked in the DB so that it will not be loaded with
 // other active orders on startup. The trackedTrade's OrderMetaData.LinkedOrder
@@ -1410,10 +1533,14 @@ func (t *trackedTrade) isSwappable(ctx context.Context, match *matchTracker) (re
 			// is expected for newly made swaps involving contracts.
 			t.dc.log.Errorf("isSwappable: error getting confirmation for our own swap transaction: %v", err)
 		}
+		t.noteSwapMissing(match, err)
 		if spent { // This should NEVER happen for maker in MakerSwapCast unless revoked and refunded!
 			t.dc.log.Errorf("Our (maker) swap for match %s is being reported as spent before taker's swap was broadcast!", match)
 		}
 		match.setSwapConfirms(int64(confs))
+		if confs >= t.metaData.FromSwapConf {
+			match.swapConfirmed()
+		}
 		t.notify(newMatchNote(TopicConfirms, "", "", db.Data, t, match))
 		return false, false
 	}
@@ -1645,6 +1772,7 @@ func (t *trackedTrade) isRedeemable(ctx context.Context, match *matchTracker) (r
 			// is expected for newly made swaps involving contracts.
 			t.dc.log.Errorf("isRedeemable: error getting confirmation for our own swap transaction: %v", err)
 		}
+		t.noteSwapMissing(match, err)
 		if spent {
 			t.dc.log.Debugf("Our (taker) swap for match %s is being reported as spent, "+
 				"but we have not seen the counter-party's redemption yet. This could just"+
@@ -1654,6 +1782,9 @@ func (t *trackedTrade) isRedeemable(ctx context.Context, match *matchTracker) (r
 			match.swapSpent()
 		}
 		match.setSwapConfirms(int64(confs))
+		if confs >= t.metaData.FromSwapConf {
+			match.swapConfirmed()
+		}
 		t.notify(newMatchNote(TopicConfirms, "", "", db.Data, t, match))
 		return false, false
 
@@ -1745,6 +1876,146 @@ func (t *trackedTrade) isRefundable(ctx context.Context, match *matchTracker) bo
 	return false
 }
 
+// checkCounterpartyInaction sends a notification if the counterparty has not
+// taken the swap step that we are waiting on within the server's broadcast
+// timeout of our own swap reaching the required confirmations, which is when
+// the server starts waiting on the counterparty. The server is expected to
+// revoke the match at this point, so there is no point notifying any sooner.
+// The user is notified only once for each step. This should be called with the
+// mtx >= RLocked.
+func (t *trackedTrade) checkCounterpartyInaction(match *matchTracker) {
+	bTimeout := t.broadcastTimeout()
+	if bTimeout == 0 || match.MetaData.Proof.IsRevoked() {
+		return
+	}
+	// Taker in NewlyMatched also awaits the maker's swap, but none of the
+	// taker's funds are in a contract yet, so there is nothing at risk.
+	var topic Topic
+	lockTime := match.matchTime()
+	switch {
+	case match.Side == order.Maker && match.Status == order.MakerSwapCast:
+		topic = TopicCounterSwapDelayed
+		lockTime = lockTime.Add(t.lockTimeMaker)
+	case match.Side == order.Taker && match.Status == order.TakerSwapCast:
+		topic = TopicCounterRedeemDelayed
+		lockTime = lockTime.Add(t.lockTimeTaker)
+	default:
+		return
+	}
+	// The server starts the counterparty's inaction timer when our swap
+	// reaches the required confirmations, which we cannot see any sooner than
+	// the server does.
+	confStamp := atomic.LoadInt64(&match.swapConfStamp)
+	if confStamp == 0 || time.Since(time.UnixMilli(confStamp)) < bTimeout {
+		return
+	}
+	noted := uint32(match.Status) + 1
+	if atomic.SwapUint32(&match.counterInactionNoted, noted) == noted {
+		return
+	}
+	t.dc.log.Warnf("Counterparty inaction for match %s, order %s (%s, status %v). Refundable after %v.",
+		match, t.ID(), match.Side, match.Status, lockTime)
+	subject, details := t.formatDetails(topic, match.token(), makeOrderToken(t.token()),
+		lockTime.Local().Format(time.RFC1123))
+	t.notify(newMatchNote(topic, subject, details, db.WarningLevel, t, match))
+}
+
+// noteSwapMissing records whether our own swap transaction was found, given the
+// error from the wallet's swapConfirmations.
+func (t *trackedTrade) noteSwapMissing(match *matchTracker, swapConfsErr error) {
+	switch {
+	case swapConfsErr == nil:
+		match.swapFound()
+	case errors.Is(swapConfsErr, asset.CoinNotFoundError):
+		match.swapMissing()
+	}
+}
+
+// findSwapReplacement attempts to locate a transaction that replaced our own
+// swap transaction, which the wallet can no longer find, e.g. because it was
+// replaced by RBF or dropped and resent. If a replacement paying to the same
+// contract is found, the match is re-bound to the replacement. If no
+// replacement is found within swapReplacementTimeout, the user is alerted.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (t *trackedTrade) findSwapReplacement(ctx context.Context, match *matchTracker) {
+	proof := &match.MetaData.Proof
+	swapCoinID := &proof.MakerSwap
+	if match.Side == order.Taker {
+		swapCoinID = &proof.TakerSwap
+	}
+	fromWallet := t.wallets.fromWallet
+	oldCoinStr := coinIDString(fromWallet.AssetID, *swapCoinID)
+
+	if finder, is := fromWallet.Wallet.(asset.SwapReplacementFinder); is {
+		newCoinID, err := finder.FindSwapReplacement(ctx, dex.Bytes(*swapCoinID), proof.ContractData)
+		if err == nil {
+			newCoinStr := coinIDString(fromWallet.AssetID, newCoinID)
+			t.dc.log.Infof("Our swap %s (%s) for match %s, order %s was replaced by %s",
+				oldCoinStr, fromWallet.Symbol, match, t.ID(), newCoinStr)
+			*swapCoinID = order.CoinID(newCoinID)
+			match.swapFound()
+			match.setSwapConfirms(0)
+			atomic.StoreInt64(&match.swapConfStamp, 0)
+			if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+				t.dc.log.Errorf("Error updating match %s with replacement swap: %v", match, err)
+			}
+			subject, details := t.formatDetails(TopicSwapReplaced, match.token(), makeOrderToken(t.token()), newCoinStr)
+			t.notify(newMatchNote(TopicSwapReplaced, subject, details, db.WarningLevel, t, match))
+			return
+		}
+		if !errors.Is(err, asset.CoinNotFoundError) {
+			t.dc.log.Errorf("Error searching for a replacement of our swap %s (%s) for match %s: %v",
+				oldCoinStr, fromWallet.Symbol, match, err)
+		}
+	}
+
+	if match.swapMissingAgo() < swapReplacementTimeout {
+		return
+	}
+	match.swapMissingMtx.Lock()
+	noted := match.swapLostNoted
+	match.swapLostNoted = true
+	match.swapMissingMtx.Unlock()
+	if noted {
+		return
+	}
+	t.dc.log.Errorf("Our swap %s (%s) for match %s, order %s is missing, and no replacement was found",
+		oldCoinStr, fromWallet.Symbol, match, t.ID())
+	subject, details := t.formatDetails(TopicSwapLost, oldCoinStr, match.token(), makeOrderToken(t.token()), fromWallet.Symbol)
+	t.notify(newMatchNote(TopicSwapLost, subject, details, db.ErrorLevel, t, match))
+}
+
+// duplicateCheckDue is true if the wallet can search for duplicates of our own
+// swap, e.g. from a double broadcast, and it is time to search again. Searches
+// stop once the match is confirmed or our swap is refunded.
+//
+// This method accesses match fields and MUST be called with the trackedTrade
+// mutex lock held for reads.
+func (t *trackedTrade) duplicateCheckDue(match *matchTracker) bool {
+	if _, is := t.wallets.fromWallet.Wallet.(asset.DuplicateSwapFinder); !is {
+		return false
+	}
+	proof := &match.MetaData.Proof
+	swapCoinID := proof.MakerSwap
+	if match.Side == order.Taker {
+		swapCoinID = proof.TakerSwap
+	}
+	return len(swapCoinID) > 0 && len(proof.RefundCoin) == 0 &&
+		match.Status < order.MatchConfirmed &&
+		time.Since(match.lastDuplicateCheck) >= duplicateSwapCheckInterval
+}
+
+// shouldCheckDuplicateSwaps is true if there are known duplicates of our swap
+// to resolve, or if it is time to search for duplicates.
+//
+// This method accesses match fields and MUST be called with the trackedTrade
+// mutex lock held for reads.
+func (t *trackedTrade) shouldCheckDuplicateSwaps(match *matchTracker) bool {
+	return len(match.MetaData.Proof.DuplicateSwaps) > 0 || t.duplicateCheckDue(match)
+}
+
 // shouldBeginFindRedemption will be true if we are the Taker on this match,
 // we've broadcasted a swap, our swap has gotten the required confs, we've not
 // refunded our swap, and either the match was revoked (without receiving a
@@ -1843,11 +2114,15 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 	tLock = time.Since(tStart)
 
 	var swaps, redeems, refunds, revokes, searches, redemptionConfirms,
-		dynamicSwapFeeConfirms, dynamicRedemptionFeeConfirms []*matchTracker
+		dynamicSwapFeeConfirms, dynamicRedemptionFeeConfirms, swapReplacements,
+		duplicateSwaps []*matchTracker
 	var sent, quoteSent, received, quoteReceived uint64
 
 	checkMatch := func(match *matchTracker) error { // only errors on context.DeadlineExceeded or context.Canceled
 		side := match.Side
+		if t.shouldCheckDuplicateSwaps(match) {
+			duplicateSwaps = append(duplicateSwaps, match)
+		}
 		if match.Status == order.MatchConfirmed {
 			return nil
 		}
@@ -1861,6 +2136,8 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 		// Inform shouldBeginFindRedemption without modifying the MatchProof.
 		revoked := match.MetaData.Proof.IsRevoked()
 
+		t.checkCounterpartyInaction(match)
+
 		// The trackedTrade mutex is locked, so we must not hang forever. Give
 		// this a generous timeout because it may be necessary to retrieve full
 		// blocks, and catch timeout/shutdown after each check. Individual
@@ -1908,11 +2185,20 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 			dynamicRedemptionFeeConfirms = append(dynamicRedemptionFeeConfirms, match)
 		}
 
+		if match.swapMissingAgo() > 0 {
+			swapReplacements = append(swapReplacements, match)
+		}
+
 		// Check refundability before checking if to start finding redemption.
 		// Ensures that redemption search is not started if locktime has expired.
 		// If we've already started redemption search for this match, the search
 		// will be aborted if/when auto-refund succeeds.
 		if t.isRefundable(ctx, match) { // does not matter if revoked
+			if !c.autoRefund.Load() {
+				c.log.Debugf("Not refunding refundable match %s for order %v (%v). Auto-refund is disabled.",
+					match, t.ID(), side)
+				return nil
+			}
 			c.log.Debugf("Refundable match %s for order %v (%v)", match, t.ID(), side)
 			refunds = append(refunds, match)
 			return nil
@@ -2005,7 +2291,8 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 
 	if !rmCancel && len(swaps) == 0 && len(refunds) == 0 && len(redeems) == 0 &&
 		len(revokes) == 0 && len(searches) == 0 && len(redemptionConfirms) == 0 &&
-		len(dynamicSwapFeeConfirms) == 0 && len(dynamicRedemptionFeeConfirms) == 0 {
+		len(dynamicSwapFeeConfirms) == 0 && len(dynamicRedemptionFeeConfirms) == 0 &&
+		len(swapReplacements) == 0 && len(duplicateSwaps) == 0 {
 		return assets, nil // nothing to do, don't acquire the write-lock
 	}
 
@@ -2032,6 +2319,31 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 		// handleRevokeMatchMsg or resolveMatchConflicts (on reconnect).
 	}
 
+	// Resolve duplicate swaps before refunding, since a match that is re-bound
+	// to a duplicate redeemed by the counterparty must not refund the swap it
+	// was re-bound from. That swap is refunded as a duplicate instead.
+	if len(duplicateSwaps) > 0 {
+		assets.count(t.wallets.fromWallet.AssetID)
+		var switched map[*matchTracker]bool
+		for _, match := range duplicateSwaps {
+			if c.checkDuplicateSwaps(t, match) {
+				if switched == nil {
+					switched = make(map[*matchTracker]bool)
+				}
+				switched[match] = true
+			}
+		}
+		if len(switched) > 0 {
+			keep := refunds[:0]
+			for _, match := range refunds {
+				if !switched[match] {
+					keep = append(keep, match)
+				}
+			}
+			refunds = keep
+		}
+	}
+
 	if len(swaps) > 0 {
 		didUnlock, err := t.wallets.fromWallet.refreshUnlock()
 		if err != nil { // Just log it and try anyway.
@@ -2113,6 +2425,10 @@ func (c *Core) tick(t *trackedTrade) (assetMap, error) {
 		}
 	}
 
+	for _, match := range swapReplacements {
+		t.findSwapReplacement(c.ctx, match)
+	}
+
 	if len(redemptionConfirms) > 0 {
 		c.confirmRedemptions(t, redemptionConfirms)
 	}
@@ -2292,6 +2608,40 @@ func (c *Core) swapMatches(t *trackedTrade, matches []*matchTracker) error {
 	return errs.ifAny()
 }
 
+// swapFeeRate is the fee rate to use for swaps with the server's prescribed
+// fee rate. A higher rate is used if a local estimate is higher, but not higher
+// than the funded (max) rate. If the server reports a recommended fee rate
+// range for the asset, the local estimate is kept within it: at least the
+// economical rate, so that the swap confirms well within the broadcast
+// timeout, and at most the priority rate.
+func (t *trackedTrade) swapFeeRate(prescribed uint64) uint64 {
+	if prescribed >= t.metaData.MaxFeeRate {
+		return prescribed
+	}
+	fromWallet := t.wallets.fromWallet
+	freshRate := fromWallet.feeRate()
+	if freshRate == 0 { // either not a FeeRater, or FeeRate failed
+		freshRate = t.dc.bestBookFeeSuggestion(fromWallet.AssetID)
+	}
+	if feeRange := t.dc.bestBookFeeRange(fromWallet.AssetID); feeRange != nil {
+		if freshRate < feeRange.Economical {
+			freshRate = feeRange.Economical
+		}
+		if feeRange.Priority > 0 && freshRate > feeRange.Priority {
+			freshRate = feeRange.Priority
+		}
+	}
+	if freshRate > t.metaData.MaxFeeRate {
+		freshRate = t.metaData.MaxFeeRate
+	}
+	if prescribed < freshRate {
+		t.dc.log.Infof("Prescribed %v fee rate %v looks low, using %v",
+			fromWallet.Symbol, prescribed, freshRate)
+		return freshRate
+	}
+	return prescribed
+}
+
 // swapMatchGroup will send a transaction with swap outputs for the specified
 // matches.
 //
@@ -2373,22 +2723,7 @@ func (c *Core) swapMatchGroup(t *trackedTrade, matches []*matchTracker, errs *er
 		return
 	}
 
-	// Use a higher swap fee rate if a local estimate is higher than the
-	// prescribed rate, but not higher than the funded (max) rate.
-	if highestFeeRate < t.metaData.MaxFeeRate {
-		freshRate := fromWallet.feeRate()
-		if freshRate == 0 { // either not a FeeRater, or FeeRate failed
-			freshRate = t.dc.bestBookFeeSuggestion(fromWallet.AssetID)
-		}
-		if freshRate > t.metaData.MaxFeeRate {
-			freshRate = t.metaData.MaxFeeRate
-		}
-		if highestFeeRate < freshRate {
-			c.log.Infof("Prescribed %v fee rate %v looks low, using %v",
-				fromWallet.Symbol, highestFeeRate, freshRate)
-			highestFeeRate = freshRate
-		}
-	}
+	highestFeeRate = t.swapFeeRate(highestFeeRate)
 
 	// Ensure swap is not sent with a zero fee rate.
 	if highestFeeRate == 0 {
@@ -3203,6 +3538,21 @@ func (c *Core) refundMatches(t *trackedTrade, matches []*matchTracker) (uint64,
 		}
 
 		swapCoinString := coinIDString(assetID, swapCoinID)
+
+		// The counterparty may have acted at the last moment. Make sure our
+		// swap is still unspent before broadcasting the refund. If the check
+		// itself fails, let Refund sort it out.
+		_, spent, err := refundWallet.SwapConfirmations(c.ctx, swapCoinID, contractToRefund, match.matchTime())
+		if err == nil && spent {
+			c.log.Infof("Not refunding %s contract %s for match %s. The swap has already been spent.",
+				symbol, swapCoinString, match)
+			match.refundErr = fmt.Errorf("swap %s already spent", swapCoinString)
+			if match.Side == order.Taker {
+				t.findMakersRedemption(c.ctx, match)
+			}
+			continue
+		}
+
 		c.log.Infof("Refunding %s contract %s for match %s (%s)",
 			symbol, swapCoinString, match, matchFailureReason)
 
@@ -3270,6 +3620,151 @@ func (c *Core) refundMatches(t *trackedTrade, matches []*matchTracker) (uint64,
 	return refundedQty, errs.ifAny()
 }
 
+// checkDuplicateSwaps searches the wallet for duplicates of our own swap,
+// e.g. from a double broadcast, and resolves any known duplicates. If the
+// counterparty redeemed a duplicate instead of the swap reported to the server,
+// the match is re-bound to the duplicate, and the reported swap is treated as
+// a duplicate in its place. This is checked on every tick, regardless of our
+// contract's locktime, so that the match follows the redeemed duplicate before
+// the reported swap becomes refundable. Unspent duplicates are refunded once
+// the locktime has expired. The match remains active until all duplicates are
+// resolved. checkDuplicateSwaps returns true if the match was re-bound to a
+// duplicate.
+//
+// This method modifies match fields and MUST be called with the trackedTrade
+// mutex lock held for writes.
+func (c *Core) checkDuplicateSwaps(t *trackedTrade, match *matchTracker) (switched bool) {
+	proof := &match.MetaData.Proof
+	swapCoinID := &proof.MakerSwap
+	if match.Side == order.Taker {
+		swapCoinID = &proof.TakerSwap
+	}
+	wallet := t.wallets.fromWallet
+	symbol, assetID := wallet.Symbol, wallet.AssetID
+
+	var newDups []order.CoinID
+	if t.duplicateCheckDue(match) {
+		match.lastDuplicateCheck = time.Now()
+		finder := wallet.Wallet.(asset.DuplicateSwapFinder)
+		coinIDs, err := finder.FindDuplicateSwaps(c.ctx, dex.Bytes(*swapCoinID), proof.ContractData)
+		if err != nil {
+			c.log.Errorf("Error searching for duplicates of our swap %s (%s) for match %s: %v",
+				coinIDString(assetID, *swapCoinID), symbol, match, err)
+		}
+	next:
+		for _, coinID := range coinIDs {
+			if bytes.Equal(coinID, *swapCoinID) {
+				continue
+			}
+			for _, dup := range proof.DuplicateSwaps {
+				if bytes.Equal(coinID, dup) {
+					continue next
+				}
+			}
+			newDups = append(newDups, order.CoinID(coinID))
+		}
+		if len(newDups) > 0 {
+			proof.DuplicateSwaps = append(proof.DuplicateSwaps, newDups...)
+			if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+				c.log.Errorf("Error storing duplicate swaps for match %s: %v", match, err)
+			}
+		}
+	}
+	if len(proof.DuplicateSwaps) == 0 {
+		return false
+	}
+
+	expired, lockTime, err := wallet.ContractLockTimeExpired(c.ctx, proof.ContractData)
+	if err != nil {
+		// Still check for a redeemed duplicate, but don't refund.
+		c.log.Errorf("Error checking locktime of our %s contract for match %s: %v", symbol, match, err)
+		expired = false
+	}
+	for _, dup := range newDups {
+		dupStr := coinIDString(assetID, dup)
+		c.log.Warnf("Duplicate swap %s (%s) found for match %s, order %s", dupStr, symbol, match, t.ID())
+		subject, details := t.formatDetails(TopicDuplicateSwaps, symbol, match.token(),
+			makeOrderToken(t.token()), dupStr, lockTime.Local().Format(time.RFC1123))
+		t.notify(newMatchNote(TopicDuplicateSwaps, subject, details, db.WarningLevel, t, match))
+	}
+
+	var feeRate uint64
+	if expired {
+		if _, err := wallet.refreshUnlock(); err != nil { // Just log it and try anyway.
+			c.log.Errorf("refreshUnlock error refunding duplicate %s swaps: %v", symbol, err)
+		}
+		if _, is := t.accountRefunder(); is {
+			feeRate = t.metaData.MaxFeeRate
+		}
+		if feeRate == 0 {
+			feeRate = c.feeSuggestionAny(assetID)
+		}
+	}
+
+	remaining := make([]order.CoinID, 0, len(proof.DuplicateSwaps))
+	for _, dup := range proof.DuplicateSwaps {
+		dupStr := coinIDString(assetID, dup)
+		_, spent, err := wallet.SwapConfirmations(c.ctx, dex.Bytes(dup), proof.ContractData, match.matchTime())
+		if err != nil && !errors.Is(err, asset.CoinNotFoundError) {
+			c.log.Errorf("Error checking duplicate swap %s (%s) for match %s: %v", dupStr, symbol, match, err)
+			remaining = append(remaining, dup)
+			continue
+		}
+		if spent {
+			// The counterparty may have redeemed the duplicate rather than the
+			// swap we reported. If our reported swap is unspent, track the
+			// duplicate instead, and resolve the reported swap as a duplicate.
+			_, primarySpent, err := wallet.SwapConfirmations(c.ctx, dex.Bytes(*swapCoinID), proof.ContractData, match.matchTime())
+			if err == nil && !primarySpent && !match.duplicateSwitched {
+				oldStr := coinIDString(assetID, *swapCoinID)
+				c.log.Warnf("Counterparty redeemed duplicate swap %s (%s) for match %s. Tracking it instead of %s.",
+					dupStr, symbol, match, oldStr)
+				remaining = append(remaining, *swapCoinID)
+				*swapCoinID = dup
+				match.duplicateSwitched = true
+				switched = true
+				subject, details := t.formatDetails(TopicDuplicateSwapUsed, dupStr, match.token(),
+					makeOrderToken(t.token()), oldStr)
+				t.notify(newMatchNote(TopicDuplicateSwapUsed, subject, details, db.WarningLevel, t, match))
+				continue
+			}
+			c.log.Infof("Duplicate swap %s (%s) for match %s is already spent", dupStr, symbol, match)
+			continue
+		}
+		if !expired {
+			remaining = append(remaining, dup)
+			continue
+		}
+		if errors.Is(err, asset.CoinNotFoundError) {
+			c.log.Infof("Duplicate swap %s (%s) for match %s not found. Nothing to refund.", dupStr, symbol, match)
+			continue
+		}
+		refundCoin, err := wallet.Refund(dex.Bytes(dup), proof.ContractData, feeRate)
+		if err != nil {
+			if errors.Is(err, asset.CoinNotFoundError) {
+				c.log.Infof("Duplicate swap %s (%s) for match %s already spent", dupStr, symbol, match)
+				continue
+			}
+			c.log.Errorf("Error refunding duplicate swap %s (%s) for match %s: %v", dupStr, symbol, match, err)
+			remaining = append(remaining, dup)
+			continue
+		}
+		refundStr := coinIDString(assetID, refundCoin)
+		c.log.Infof("Refunded duplicate swap %s (%s) for match %s in %s", dupStr, symbol, match, refundStr)
+		subject, details := t.formatDetails(TopicDuplicateSwapRefunded, dupStr, match.token(),
+			makeOrderToken(t.token()), refundStr)
+		t.notify(newMatchNote(TopicDuplicateSwapRefunded, subject, details, db.Success, t, match))
+	}
+	if len(remaining) == 0 {
+		remaining = nil
+	}
+	proof.DuplicateSwaps = remaining
+	if err := t.db.UpdateMatch(&match.MetaMatch); err != nil {
+		c.log.Errorf("Error updating duplicate swaps for match %s: %v", match, err)
+	}
+	return switched
+}
+
 // processAuditMsg processes the audit request from the server. A non-nil error
 // is only returned if the match referenced by the Audit message is not known.
 func (t *trackedTrade) processAuditMsg(msgID uint64, audit *msgjson.Audit) error {
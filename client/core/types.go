@@ -4,6 +4,7 @@
 package core
 
 import (
+	"crypto/sha256"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
@@ -94,6 +95,19 @@ type WalletForm struct {
 	// wallet is fully synced, sending NoteTypeCreateWallet notifications to
 	// update with progress.
 	ParentForm *WalletForm
+	// ImportSeed is an optional existing seed, or for some assets a private
+	// key, from which to create a new seeded wallet instead of a seed derived
+	// from the application seed. Wallets created from an imported seed cannot
+	// be restored from the application seed.
+	ImportSeed []byte
+}
+
+// WalletSyncStatus is the blockchain sync progress of a wallet.
+type WalletSyncStatus struct {
+	Synced       bool    `json:"synced"`
+	Height       uint64  `json:"height"`
+	TargetHeight uint64  `json:"targetHeight"`
+	Progress     float32 `json:"progress"` // percent
 }
 
 // WalletBalance is an exchange wallet's balance which includes various locked
@@ -118,6 +132,93 @@ type WalletBalance struct {
 	BondLocked uint64 `json:"bondlocked"`
 }
 
+// Total is the wallet's total balance, including the amounts locked in swap
+// contracts and bonds. This is the total balance shown for the wallet in the
+// UI.
+func (b *WalletBalance) Total() uint64 {
+	return b.Available + b.Immature + b.Locked + b.ContractLocked + b.BondLocked
+}
+
+// BalanceBreakdown is a categorized accounting of a wallet's funds. The
+// categories are non-overlapping, and they sum to Total.
+type BalanceBreakdown struct {
+	AssetID uint32 `json:"assetID"`
+	// Available is the balance that is available for trading immediately.
+	Available uint64 `json:"available"`
+	// OrderLocked is the amount reserved by orders for swaps that have not
+	// yet been broadcast.
+	OrderLocked uint64 `json:"orderLocked"`
+	// SwapLocked is the amount locked in unredeemed and unrefunded swap
+	// contracts.
+	SwapLocked uint64 `json:"swapLocked"`
+	// Bonded is the amount locked in fidelity bonds.
+	Bonded uint64 `json:"bonded"`
+	// Immature is the amount that will become available after some
+	// confirmations.
+	Immature uint64 `json:"immature"`
+	// OtherLocked is any amount locked by the wallet that is not attributable
+	// to orders, e.g. bond reserves or wallet-specific locks.
+	OtherLocked uint64 `json:"otherLocked"`
+	// Total is the wallet's total balance, which is the sum of all of the
+	// above.
+	Total uint64 `json:"total"`
+}
+
+// HostAssetAmounts are the amounts of an asset committed to a single DEX host.
+type HostAssetAmounts struct {
+	// OrderLocked is the amount reserved by the host's orders for swaps that
+	// have not yet been broadcast.
+	OrderLocked uint64 `json:"orderLocked"`
+	// SwapLocked is the amount locked in the host's unredeemed and unrefunded
+	// swap contracts.
+	SwapLocked uint64 `json:"swapLocked"`
+	// Bonded is the amount locked in fidelity bonds with the host, including
+	// expired bonds that have not been refunded.
+	Bonded uint64 `json:"bonded"`
+}
+
+// HostPortfolio is the portion of a Portfolio attributable to one DEX host.
+type HostPortfolio struct {
+	Host          string                       `json:"host"`
+	ActiveOrders  int                          `json:"activeOrders"`
+	ActiveMatches int                          `json:"activeMatches"`
+	Assets        map[uint32]*HostAssetAmounts `json:"assets"`
+}
+
+// Portfolio is a consolidated view of the user's funds across all DEX hosts.
+// Wallets are shared by all hosts, so each wallet's balance is counted only
+// once in Assets. Hosts are each host's attribution of funds to its own orders,
+// swaps, and bonds. The Assets breakdowns are derived from the wallet balances
+// instead, which may be stale for disconnected wallets and cap the order-locked
+// amount at the wallet's locked balance, so the per-host amounts need not sum
+// to the amounts in Assets.
+type Portfolio struct {
+	Assets map[uint32]*BalanceBreakdown `json:"assets"`
+	Hosts  map[string]*HostPortfolio    `json:"hosts"`
+}
+
+// WalletSettingsExport is a portable export of the configuration of one or
+// more wallets, used to set up the same wallets on another client. Secret
+// settings and wallet passwords are encrypted with a key derived from the
+// exporting client's app password, with parameters KeyParams.
+type WalletSettingsExport struct {
+	Version   uint32            `json:"version"`
+	KeyParams dex.Bytes         `json:"keyParams"`
+	Wallets   []*ExportedWallet `json:"wallets"`
+}
+
+// ExportedWallet is the exported configuration of a single wallet. Settings
+// are the wallet's non-secret settings. Secrets is the encrypted JSON-encoded
+// secret settings and, for wallets that are not seeded by Core, the wallet
+// password.
+type ExportedWallet struct {
+	AssetID  uint32            `json:"assetID"`
+	Symbol   string            `json:"symbol"`
+	Type     string            `json:"type"`
+	Settings map[string]string `json:"settings"`
+	Secrets  dex.Bytes         `json:"secrets,omitempty"`
+}
+
 // WalletState is the current status of an exchange wallet.
 type WalletState struct {
 	Symbol       string                          `json:"symbol"`
@@ -138,6 +239,16 @@ type WalletState struct {
 	Disabled     bool                            `json:"disabled"`
 	Approved     map[uint32]asset.ApprovalStatus `json:"approved"`
 	FeeState     *FeeState                       `json:"feeState"`
+	// Display is the display symbol and aliases configured for the asset by
+	// the DEX servers, keyed by host. See SupportedAsset.
+	Display map[string]*AssetDisplay `json:"display,omitempty"`
+}
+
+// AssetDisplay is the display symbol and alternative names that a DEX server
+// configures for an asset. See dex.Asset.
+type AssetDisplay struct {
+	Symbol  string   `json:"symbol,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
 }
 
 // FeeState is information about the current network transaction fees and
@@ -190,6 +301,21 @@ type User struct {
 	Actions            []*asset.ActionRequiredNote `json:"actions,omitempty"`
 }
 
+// AddressStatus is the result of validating an address for an asset.
+type AddressStatus string
+
+const (
+	// AddressValid indicates that the address is valid for the asset and the
+	// current network.
+	AddressValid AddressStatus = "valid"
+	// AddressWrongNetwork indicates that the address is valid for a different
+	// network of the asset.
+	AddressWrongNetwork AddressStatus = "wrongnetwork"
+	// AddressMalformed indicates that the address is not a valid address for
+	// the asset.
+	AddressMalformed AddressStatus = "malformed"
+)
+
 // SupportedAsset is data about an asset and possibly the wallet associated
 // with it.
 type SupportedAsset struct {
@@ -206,6 +332,19 @@ type SupportedAsset struct {
 	// WalletCreationPending will be true if this wallet's parent wallet is
 	// being synced before this wallet is created.
 	WalletCreationPending bool `json:"walletCreationPending"`
+	// Display is the display symbol and aliases configured for the asset by
+	// the DEX servers, keyed by host. Servers may display the same asset
+	// differently, so a display symbol only applies in the context of its
+	// server.
+	Display map[string]*AssetDisplay `json:"display,omitempty"`
+	// ExplorerTxURL and ExplorerAddrURL are block explorer URL templates for
+	// the asset's transactions and addresses on the current network, if
+	// known. See asset.ExplorerTxID and asset.ExplorerAddr.
+	ExplorerTxURL   string `json:"explorerTxURL,omitempty"`
+	ExplorerAddrURL string `json:"explorerAddrURL,omitempty"`
+	// ExplorerOutURL is the block explorer URL template for a transaction
+	// output, if the explorer has output pages. See asset.ExplorerVout.
+	ExplorerOutURL string `json:"explorerOutURL,omitempty"`
 }
 
 // BondOptionsForm is used from the settings page to change the auto-bond
@@ -258,6 +397,10 @@ type Match struct {
 	Refund        *Coin             `json:"refund,omitempty"`
 	Stamp         uint64            `json:"stamp"` // Server's time stamp - we have no local time recorded
 	IsCancel      bool              `json:"isCancel"`
+	// DuplicateSwaps are other outputs paying to the same contract as our
+	// swap, e.g. from a double broadcast. The match needs attention until
+	// they are resolved.
+	DuplicateSwaps []*Coin `json:"duplicateSwaps,omitempty"`
 }
 
 // Coin encodes both the coin ID and the asset-dependent string representation
@@ -380,6 +523,9 @@ func matchFromMetaMatchWithConfs(ord order.Order, metaMatch *db.MetaMatch, swapC
 		CounterRedeem: counterRedeem,
 		Refund:        refund,
 	}
+	for _, coinID := range proof.DuplicateSwaps {
+		match.DuplicateSwaps = append(match.DuplicateSwaps, NewCoin(fromID, coinID))
+	}
 
 	return match
 }
@@ -433,13 +579,28 @@ type InFlightOrder struct {
 	TemporaryID uint64 `json:"tempID"`
 }
 
-// FeeBreakdown is categorized fee information.
+// FeeBreakdown is categorized fee information. The swap, funding, and refund
+// fees are in atomic units of SwapFeeAsset, and the redemption fees are in
+// atomic units of RedemptionFeeAsset. The fee assets differ from the traded
+// assets for tokens, whose fees are paid in the parent asset.
 type FeeBreakdown struct {
 	Swap       uint64 `json:"swap"`
 	Redemption uint64 `json:"redemption"`
 	Funding    uint64 `json:"funding"` // split fees
 	// TODO: Refund is not yet being populated.
-	Refund uint64 `json:"refund"`
+	Refund             uint64 `json:"refund"`
+	SwapFeeAsset       uint32 `json:"swapFeeAsset"`
+	RedemptionFeeAsset uint32 `json:"redemptionFeeAsset"`
+}
+
+// orderFeeAssets returns the assets in which the swap and redemption fees of
+// an order on the specified market are paid.
+func orderFeeAssets(baseID, quoteID uint32, sell bool) (swapFeeAsset, redemptionFeeAsset uint32) {
+	fromID, toID := quoteID, baseID
+	if sell {
+		fromID, toID = baseID, quoteID
+	}
+	return asset.FeeAsset(fromID), asset.FeeAsset(toID)
 }
 
 // coreOrderFromTrade constructs an *Order from the supplied limit or market
@@ -473,6 +634,8 @@ func coreOrderFromTrade(ord order.Order, metaData *db.OrderMetaData) *Order {
 		}
 	}
 
+	swapFeeAsset, redemptionFeeAsset := orderFeeAssets(baseID, quoteID, trade.Sell)
+
 	var cancelling, canceled bool
 	if !metaData.LinkedOrder.IsZero() {
 		if metaData.Status == order.OrderStatusCanceled {
@@ -524,9 +687,11 @@ func coreOrderFromTrade(ord order.Order, metaData *db.OrderMetaData) *Order {
 		Canceled:    canceled,
 		Cancelling:  cancelling,
 		FeesPaid: &FeeBreakdown{
-			Swap:       metaData.SwapFeesPaid,
-			Redemption: metaData.RedemptionFeesPaid,
-			Funding:    metaData.FundingFeesPaid,
+			Swap:               metaData.SwapFeesPaid,
+			Redemption:         metaData.RedemptionFeesPaid,
+			Funding:            metaData.FundingFeesPaid,
+			SwapFeeAsset:       swapFeeAsset,
+			RedemptionFeeAsset: redemptionFeeAsset,
 		},
 		FundingCoins:      fundingCoins,
 		AccelerationCoins: accelerationCoins,
@@ -544,6 +709,9 @@ type Market struct {
 	QuoteSymbol     string        `json:"quotesymbol"`
 	LotSize         uint64        `json:"lotsize"`
 	ParcelSize      uint32        `json:"parcelsize"`
+	MinOrderLots    uint32        `json:"minorderlots,omitempty"`
+	MaxOpenOrders   uint32        `json:"maxopenorders,omitempty"` // max standing orders, 0 for no limit
+	StandingOrders  int           `json:"standingorders"`          // the account's standing orders
 	RateStep        uint64        `json:"ratestep"`
 	EpochLen        uint64        `json:"epochlen"`
 	StartEpoch      uint64        `json:"startepoch"`
@@ -561,6 +729,12 @@ type Market struct {
 	// MinimumRate is the minimum rate allowed for the market, which is the
 	// minimum rate at which 1 lot converts to something greater than dust.
 	MinimumRate uint64 `json:"minimumRate"`
+	// MinOrderLifetime is how long, in milliseconds, a standing order must be
+	// on the market before it may be canceled. Zero means no minimum.
+	MinOrderLifetime uint64 `json:"minOrderLifetime,omitempty"`
+	// MaxOrderLifetime is how long, in milliseconds, a standing order may be
+	// on the market before it is revoked by the server. Zero means no maximum.
+	MaxOrderLifetime uint64 `json:"maxOrderLifetime,omitempty"`
 }
 
 // BaseContractLocked is the amount of base asset locked in un-redeemed
@@ -742,6 +916,35 @@ type RemainderUpdate struct {
 	QtyAtomic uint64  `json:"qtyAtomic"`
 }
 
+// MarketQuantization describes the quantization of a market's orders.
+type MarketQuantization struct {
+	// LotSize is the lot size, in atomic units of the base asset.
+	LotSize uint64 `json:"lotSize"`
+	// RateStep is the rate step, in message-rate units.
+	RateStep uint64 `json:"rateStep"`
+	// MinOrderLots is the smallest order, in lots, that the server accepts.
+	MinOrderLots uint32 `json:"minOrderLots"`
+	// QtyPrecision is the number of decimal places needed to express any
+	// valid quantity in conventional base asset units.
+	QtyPrecision int `json:"qtyPrecision"`
+	// RatePrecision is the number of decimal places needed to express any
+	// valid conventional rate.
+	RatePrecision int `json:"ratePrecision"`
+}
+
+// WalletDerivation is the result of checking that a wallet's addresses match
+// those derived from the app seed.
+type WalletDerivation struct {
+	AssetID uint32 `json:"assetID"`
+	// Match is true if the wallet reported the same addresses, in the same
+	// order, as were derived from the app seed.
+	Match bool `json:"match"`
+	// Expected are the addresses derived from the app seed.
+	Expected []string `json:"expected"`
+	// Reported are the addresses reported by the wallet.
+	Reported []string `json:"reported"`
+}
+
 // OrderBook represents an order book, which are sorted buys and sells, and
 // unsorted epoch orders.
 type OrderBook struct {
@@ -750,6 +953,33 @@ type OrderBook struct {
 	Epoch []*MiniOrder `json:"epoch"`
 	// RecentMatches is a cache of up to 100 recent matches for a market.
 	RecentMatches []*orderbook.MatchSummary `json:"recentMatches"`
+	// Seq is the sequence number of the last update applied to the book. It
+	// is only set by Book.
+	Seq uint64 `json:"seq,omitempty"`
+}
+
+// DepthBucket is the booked quantity in a price bucket of a MarketDepth.
+type DepthBucket struct {
+	// Rate is the bucket's rate, in message-rate units. Bid rates are rounded
+	// down and ask rates rounded up to a multiple of the bucket size.
+	Rate uint64 `json:"rate"`
+	// Qty is the booked quantity in the bucket, in units of the base asset.
+	Qty uint64 `json:"qty"`
+	// CumulativeQty is the booked quantity in this bucket and all buckets
+	// with better rates.
+	CumulativeQty uint64 `json:"cumulativeQty"`
+}
+
+// MarketDepth is an aggregate view of an order book's liquidity. Bids and Asks
+// are sorted best rate first. BestBid, BestAsk, and Spread are zero if they
+// cannot be determined from the book.
+type MarketDepth struct {
+	BucketSize uint64         `json:"bucketSize"`
+	BestBid    uint64         `json:"bestBid"`
+	BestAsk    uint64         `json:"bestAsk"`
+	Spread     uint64         `json:"spread"`
+	Bids       []*DepthBucket `json:"bids"`
+	Asks       []*DepthBucket `json:"asks"`
 }
 
 // MarketOrderBook is used as the BookUpdate's Payload with the FreshBookAction.
@@ -826,6 +1056,16 @@ type dexAccount struct {
 	maxBondedAmt      uint64
 	penaltyComps      uint16 // max penalties to compensate for
 	bondAsset         uint32 // asset used for bond maintenance/rotation
+	// bondReplacements maps bonds posted by ReplaceBond to the bonds that
+	// they replace, until the new bonds are confirmed. Keys and values are
+	// from bondKey. The replacement is stored with the new bond as
+	// db.Bond.Replaces, from which bondReplacements and relinquishedBonds
+	// are restored when the account is loaded.
+	bondReplacements map[string]string
+	// relinquishedBonds are bonds, by bondKey, that were replaced by a
+	// confirmed bond. They are still active with the server until they
+	// expire, but no longer count toward tier maintenance.
+	relinquishedBonds map[string]bool
 }
 
 // newDEXAccount is a constructor for a new *dexAccount.
@@ -884,7 +1124,7 @@ func (a *dexAccount) setupCryptoV2(creds *db.PrimaryCredentials, crypter encrypt
 	// but it works.
 
 	// Prepare the chain of child indices.
-	kids := make([]uint32, 0, 11) // 1 x purpose, 1 x version (incl. oddness), 8 x 4-byte uint32s, 1 x acct key index.
+	kids := make([]uint32, 0, 12) // 1 x purpose, 1 x version (incl. oddness), 8 x 4-byte uint32s, [1 x identity], 1 x acct key index.
 	// Hardened "purpose" key.
 	kids = append(kids, hdKeyPurposeAccts)
 	// Second child is the the format/oddness byte.
@@ -894,6 +1134,12 @@ func (a *dexAccount) setupCryptoV2(creds *db.PrimaryCredentials, crypter encrypt
 	for i := 0; i < 8; i++ {
 		kids = append(kids, binary.LittleEndian.Uint32(byteSeq[i*4:i*4+4]))
 	}
+	// A trading identity gets its own branch, so that its accounts are not
+	// linkable to those of the primary identity.
+	if _, identity := splitHostIdentity(a.host); identity != "" {
+		identityHash := sha256.Sum256([]byte(identity))
+		kids = append(kids, binary.LittleEndian.Uint32(identityHash[:4]))
+	}
 	// Last child is the account key index.
 	kids = append(kids, keyIndex)
 
@@ -1030,6 +1276,55 @@ type TradeForm struct {
 	Rate    uint64            `json:"rate"`
 	TifNow  bool              `json:"tifnow"`
 	Options map[string]string `json:"options"`
+	// MaxSlippage is the maximum allowed deviation, in percent, of the
+	// projected average fill rate of a market order from the best rate on the
+	// synced order book. Zero disables the check.
+	MaxSlippage float64 `json:"maxSlippage,omitempty"`
+	// WorstRate is the worst acceptable fill rate for a market order. If set,
+	// the order is submitted as an immediate limit order at this rate, so any
+	// quantity that cannot be matched at the rate or better is canceled.
+	WorstRate uint64 `json:"worstRate,omitempty"`
+	// QtyInQuote indicates that the Qty of a limit order is an amount of the
+	// quote asset. The order is placed for the whole number of lots of the
+	// base asset nearest in value to that amount at the Rate, so the order may
+	// be worth a little more or less than Qty. The base asset quantity of the
+	// placed order is reported in the returned Order.
+	QtyInQuote bool `json:"qtyInQuote,omitempty"`
+	// TTL is the time to live, in seconds, of a standing limit order. If the
+	// order is still open after the TTL, the client cancels it. Zero means
+	// the order stands until it is filled or canceled.
+	TTL uint64 `json:"ttl,omitempty"`
+	// OverridePriceBand allows a limit order with a rate outside of the
+	// configured price band. See Core.SetPriceBand.
+	OverridePriceBand bool `json:"overridePriceBand,omitempty"`
+}
+
+// TemplateOverrides are changes to an order template's parameters for a
+// single order placed with PlaceFromTemplate. Nil fields are not changed.
+type TemplateOverrides struct {
+	Qty        *uint64  `json:"qty,omitempty"`
+	RateOffset *float64 `json:"rateOffset,omitempty"`
+	// Rate is a limit order rate to use instead of the rate offset from the
+	// mid-gap rate.
+	Rate *uint64 `json:"rate,omitempty"`
+}
+
+// SimulatedTrade is the result of a dry run of a trade with SimulateTrade. The
+// swap transaction was constructed for a single match of the full order
+// quantity, with the user's own address standing in for the counterparty's,
+// but it was not signed or broadcast, and no order was submitted or saved.
+type SimulatedTrade struct {
+	// Simulated is always true, marking the transactions as never broadcast.
+	Simulated    bool     `json:"simulated"`
+	FundingCoins []string `json:"fundingCoins"`
+	// FundingFees are the fees for any transaction needed to fund the order,
+	// which was also not broadcast.
+	FundingFees uint64    `json:"fundingFees"`
+	SwapFeeRate uint64    `json:"swapFeeRate"`
+	SwapTxID    string    `json:"swapTxID"`
+	SwapTx      dex.Bytes `json:"swapTx"`
+	Contract    dex.Bytes `json:"contract"`
+	SwapFees    uint64    `json:"swapFees"`
 }
 
 // QtyRate specifies the quantity and rate of an order placement.
@@ -1051,6 +1346,9 @@ type MultiTradeForm struct {
 	// MaxLock is the maximum amount of the "from" asset that the wallet
 	// should lock for the trade.
 	MaxLock uint64 `json:"maxLock"`
+	// OverridePriceBand allows placements with rates outside of the
+	// configured price band. See Core.SetPriceBand.
+	OverridePriceBand bool `json:"overridePriceBand,omitempty"`
 }
 
 // SingleLotFeesForm is used to determine the fees for a single lot trade.
@@ -1155,6 +1453,54 @@ type OrderEstimate struct {
 	Redeem *asset.PreRedeem `json:"redeem"`
 }
 
+// TradingCost is an estimate of the full cost of a standing limit order,
+// including on-chain transaction fees, server trading fees, and the fidelity
+// bond requirement for trading.
+type TradingCost struct {
+	// SwapAssetID is the asset of the swap transactions. SwapFees and
+	// SwapFeesBestCase are the realistic worst-case and best-case swap
+	// transaction fees, in atomic units of SwapFeeAssetID, which is the parent
+	// asset for tokens.
+	SwapAssetID      uint32 `json:"swapAssetID"`
+	SwapFeeAssetID   uint32 `json:"swapFeeAssetID"`
+	SwapFees         uint64 `json:"swapFees"`
+	SwapFeesBestCase uint64 `json:"swapFeesBestCase"`
+	// RedeemAssetID is the asset of the redeem transactions. RedeemFees and
+	// RedeemFeesBestCase are the realistic worst-case and best-case redeem
+	// transaction fees, in atomic units of RedeemFeeAssetID, which is the
+	// parent asset for tokens.
+	RedeemAssetID      uint32 `json:"redeemAssetID"`
+	RedeemFeeAssetID   uint32 `json:"redeemFeeAssetID"`
+	RedeemFees         uint64 `json:"redeemFees"`
+	RedeemFeesBestCase uint64 `json:"redeemFeesBestCase"`
+	// TradingFees are the fees charged by the server for the order, in atomic
+	// units of the swap fee asset. The server's market config does not specify
+	// any trading fees, so this is zero.
+	TradingFees uint64 `json:"tradingFees"`
+	// TotalSwapAssetCost is the sum of the worst-case swap fees and the
+	// trading fees, in atomic units of the swap fee asset.
+	TotalSwapAssetCost uint64 `json:"totalSwapAssetCost"`
+	// Bond is the server's fidelity bond requirement.
+	Bond *BondRequirement `json:"bond"`
+}
+
+// BondRequirement describes the fidelity bond required to trade on a server.
+// Bonds are time-locked and refunded after they expire, so the cost of a bond
+// is the fees of the bond and refund transactions and the opportunity cost of
+// the locked funds, not the bond amount.
+type BondRequirement struct {
+	// Amounts are the bond amounts for a single tier, in atomic units, by bond
+	// asset symbol.
+	Amounts map[string]uint64 `json:"amounts"`
+	// Expiry is how long, in seconds, a bond counts toward the account's tier.
+	Expiry uint64 `json:"expiry"`
+	// Tier is the account's effective tier. Trading requires a tier of at
+	// least 1.
+	Tier int64 `json:"tier"`
+	// Note is a human-readable summary of the requirement.
+	Note string `json:"note"`
+}
+
 // PreAccelerate gives information that the user can use to decide on
 // how much to accelerate stuck swap transactions in an order.
 type PreAccelerate struct {
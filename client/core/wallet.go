@@ -51,10 +51,15 @@ type xcWallet struct {
 	supportedVersions []uint32
 	dbID              []byte
 	walletType        string
+	importedSeed      bool // seeded wallet not derived from the app seed
 	traits            asset.WalletTrait
+	redeemConfs       uint32 // confirmations on counterparty swaps before redeeming
 	parent            *xcWallet
 	feeState          atomic.Value // *FeeState
 	connectMtx        sync.Mutex
+	// display returns the display symbols and aliases configured for the
+	// asset by the DEX servers, keyed by host. May be nil.
+	display func() map[string]*AssetDisplay
 
 	mtx        sync.RWMutex
 	encPass    []byte // empty means wallet not password protected
@@ -99,6 +104,24 @@ func (w *xcWallet) supportsVer(ver uint32) bool {
 	return false
 }
 
+// compatibleVersion returns the version of the server's asset to use with this
+// wallet. The server's preferred version is used if the wallet supports it.
+// Otherwise, the newest of the other versions accepted by the server that the
+// wallet supports is used. The bool is false if there is no such version.
+func (w *xcWallet) compatibleVersion(a *dex.Asset) (uint32, bool) {
+	if w.supportsVer(a.Version) {
+		return a.Version, true
+	}
+	var ver uint32
+	var found bool
+	for _, v := range a.Versions {
+		if w.supportsVer(v) && (!found || v > ver) {
+			ver, found = v, true
+		}
+	}
+	return ver, found
+}
+
 // Unlock unlocks the wallet backend and caches the decrypted wallet password so
 // the wallet may be unlocked without user interaction using refreshUnlock.
 func (w *xcWallet) Unlock(crypter encrypt.Crypter) error {
@@ -315,6 +338,10 @@ func (w *xcWallet) state() *WalletState {
 		w.parent.mtx.RUnlock()
 	}
 
+	if w.display != nil {
+		state.Display = w.display()
+	}
+
 	return state
 }
 
@@ -374,6 +401,16 @@ func (w *xcWallet) connected() bool {
 	return w.hookedUp
 }
 
+// SyncStatus is the blockchain sync status of the wallet. Wallets that are not
+// asset.SyncStatusers are always synced.
+func (w *xcWallet) SyncStatus() (*asset.SyncStatus, error) {
+	ssr, is := w.Wallet.(asset.SyncStatuser)
+	if !is {
+		return &asset.SyncStatus{Synced: true}, nil
+	}
+	return ssr.SyncStatus()
+}
+
 // checkPeersAndSyncStatus checks that the wallet is synced, and has peers
 // otherwise we might double spend if the wallet keys were used elsewhere. This
 // should be checked before attempting to send funds but does not replace any
@@ -0,0 +1,264 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"decred.org/dcrdex/client/asset"
+	"decred.org/dcrdex/dex"
+)
+
+// walletSettingsExportVersion is the version of the WalletSettingsExport
+// format.
+const walletSettingsExportVersion = 0
+
+// exportedSecrets is the plaintext of ExportedWallet.Secrets.
+type exportedSecrets struct {
+	Settings map[string]string `json:"settings,omitempty"`
+	Password dex.Bytes         `json:"password,omitempty"`
+}
+
+// ExportWalletSettings exports the configuration of the wallet for the
+// specified asset, or of all wallets if assetID is nil, so that the wallets
+// can be set up on another client with ImportWalletSettings. Seeds are never
+// exported. Seeded wallets that are imported on another client are created
+// from that client's app seed, so wallets that were created from an imported
+// seed are not exported. Secret settings and wallet passwords are encrypted
+// under the app password.
+func (c *Core) ExportWalletSettings(appPW []byte, assetID *uint32) (*WalletSettingsExport, error) {
+	crypter, err := c.encryptionKey(appPW)
+	if err != nil {
+		return nil, codedError(passwordErr, err)
+	}
+	defer crypter.Close()
+
+	var wallets []*xcWallet
+	if assetID != nil {
+		wallet, found := c.wallet(*assetID)
+		if !found {
+			return nil, newError(missingWalletErr, "%d -> %s wallet not found", *assetID, unbip(*assetID))
+		}
+		wallets = []*xcWallet{wallet}
+	} else {
+		wallets = c.xcWallets()
+		sort.Slice(wallets, func(i, j int) bool { return wallets[i].AssetID < wallets[j].AssetID })
+	}
+
+	// The secrets are encrypted with a new key derived from the app password
+	// rather than the app's encryption key, which is not portable.
+	exportCrypter := c.newCrypter(appPW)
+	defer exportCrypter.Close()
+
+	exp := &WalletSettingsExport{
+		Version:   walletSettingsExportVersion,
+		KeyParams: exportCrypter.Serialize(),
+		Wallets:   make([]*ExportedWallet, 0, len(wallets)),
+	}
+	for _, wallet := range wallets {
+		symbol := unbip(wallet.AssetID)
+		dbWallet, err := c.db.Wallet(wallet.dbID)
+		if err != nil {
+			return nil, codedError(dbErr, err)
+		}
+		if dbWallet.Settings[importedSeedSetting] == "true" {
+			if assetID != nil {
+				return nil, fmt.Errorf("cannot export the %s wallet, which was created from an imported seed", symbol)
+			}
+			c.log.Infof("Not exporting the %s wallet, which was created from an imported seed", symbol)
+			continue
+		}
+		walletDef, err := asset.WalletDef(wallet.AssetID, wallet.walletType)
+		if err != nil {
+			return nil, newError(assetSupportErr, "asset.WalletDef error: %w", err)
+		}
+		noEcho := make(map[string]bool)
+		for _, opt := range walletDef.ConfigOpts {
+			if opt.NoEcho {
+				noEcho[strings.ToLower(opt.Key)] = true
+			}
+		}
+
+		ew := &ExportedWallet{
+			AssetID:  wallet.AssetID,
+			Symbol:   symbol,
+			Type:     walletDef.Type,
+			Settings: make(map[string]string, len(dbWallet.Settings)),
+		}
+		secrets := new(exportedSecrets)
+		for k, v := range dbWallet.Settings {
+			if noEcho[k] {
+				if secrets.Settings == nil {
+					secrets.Settings = make(map[string]string)
+				}
+				secrets.Settings[k] = v
+				continue
+			}
+			ew.Settings[k] = v
+		}
+		// The password of a seeded wallet is derived from the app seed.
+		if encPW := wallet.encPW(); !walletDef.Seeded && len(encPW) > 0 {
+			secrets.Password, err = crypter.Decrypt(encPW)
+			if err != nil {
+				return nil, newError(encryptionErr, "error decrypting %s wallet password: %w", symbol, err)
+			}
+		}
+		if len(secrets.Settings) > 0 || len(secrets.Password) > 0 {
+			b, err := json.Marshal(secrets)
+			if err != nil {
+				return nil, fmt.Errorf("error encoding %s wallet secrets: %w", symbol, err)
+			}
+			ew.Secrets, err = exportCrypter.Encrypt(b)
+			if err != nil {
+				return nil, newError(encryptionErr, "error encrypting %s wallet secrets: %w", symbol, err)
+			}
+		}
+		exp.Wallets = append(exp.Wallets, ew)
+	}
+	return exp, nil
+}
+
+// ImportWalletSettings creates the wallets in an export from
+// ExportWalletSettings. exportPW is the app password of the client that
+// exported the settings, and is only required if it differs from appPW. The
+// settings of every wallet are validated against the asset's wallet
+// definition before any wallet is created. If a wallet for any of the assets
+// already exists, no wallets are created unless overwrite is true, in which
+// case the existing wallets are reconfigured with the imported settings.
+func (c *Core) ImportWalletSettings(appPW, exportPW []byte, exp *WalletSettingsExport, overwrite bool) error {
+	crypter, err := c.encryptionKey(appPW)
+	if err != nil {
+		return codedError(passwordErr, err)
+	}
+	crypter.Close()
+
+	if exp == nil {
+		return newError(decodeErr, "no wallet settings")
+	}
+	if exp.Version != walletSettingsExportVersion {
+		return newError(decodeErr, "unknown wallet settings version %d", exp.Version)
+	}
+	if len(exportPW) == 0 {
+		exportPW = appPW
+	}
+	exportCrypter, err := c.reCrypter(exportPW, exp.KeyParams)
+	if err != nil {
+		return newError(passwordErr, "error deriving the export key: %w", err)
+	}
+	defer exportCrypter.Close()
+
+	type walletImport struct {
+		form     *WalletForm
+		walletPW []byte
+		exists   bool
+	}
+	imports := make([]*walletImport, 0, len(exp.Wallets))
+	seen := make(map[uint32]bool, len(exp.Wallets))
+	var existing []string
+	for _, ew := range exp.Wallets {
+		symbol := unbip(ew.AssetID)
+		if seen[ew.AssetID] {
+			return newError(createWalletErr, "duplicate %s wallet settings", symbol)
+		}
+		seen[ew.AssetID] = true
+
+		settings := make(map[string]string, len(ew.Settings))
+		for k, v := range ew.Settings {
+			settings[k] = v
+		}
+		secrets := new(exportedSecrets)
+		if len(ew.Secrets) > 0 {
+			b, err := exportCrypter.Decrypt(ew.Secrets)
+			if err != nil {
+				return newError(passwordErr, "error decrypting %s wallet secrets: %w", symbol, err)
+			}
+			if err := json.Unmarshal(b, secrets); err != nil {
+				return newError(decodeErr, "error decoding %s wallet secrets: %w", symbol, err)
+			}
+			for k, v := range secrets.Settings {
+				settings[k] = v
+			}
+		}
+
+		walletDef, err := asset.WalletDef(ew.AssetID, ew.Type)
+		if err != nil {
+			return newError(assetSupportErr, "asset.WalletDef error: %w", err)
+		}
+		dropped, err := validateWalletSettings(walletDef, settings)
+		if err != nil {
+			return newError(createWalletErr, "invalid %s wallet settings: %w", symbol, err)
+		}
+		if len(dropped) > 0 {
+			c.log.Warnf("Ignoring unknown %s wallet settings %s", symbol, strings.Join(dropped, ", "))
+		}
+		if walletDef.Seeded && len(secrets.Password) > 0 {
+			return newError(createWalletErr, "%s wallet settings include a password for a seeded wallet", symbol)
+		}
+
+		_, exists := c.wallet(ew.AssetID)
+		if exists {
+			existing = append(existing, symbol)
+		}
+		imports = append(imports, &walletImport{
+			form: &WalletForm{
+				AssetID: ew.AssetID,
+				Config:  settings,
+				Type:    walletDef.Type,
+			},
+			walletPW: secrets.Password,
+			exists:   exists,
+		})
+	}
+	if len(existing) > 0 && !overwrite {
+		return newError(createWalletErr, "wallets already exist for %s. Confirm to overwrite their settings",
+			strings.Join(existing, ", "))
+	}
+
+	// Token wallets require their parent wallet, so create those last.
+	sort.SliceStable(imports, func(i, j int) bool {
+		return asset.TokenInfo(imports[i].form.AssetID) == nil && asset.TokenInfo(imports[j].form.AssetID) != nil
+	})
+	for _, wi := range imports {
+		symbol := unbip(wi.form.AssetID)
+		if wi.exists {
+			if err := c.ReconfigureWallet(appPW, wi.walletPW, wi.form); err != nil {
+				return fmt.Errorf("error reconfiguring %s wallet: %w", symbol, err)
+			}
+			c.log.Infof("Reconfigured %s wallet with imported settings", symbol)
+			continue
+		}
+		if err := c.CreateWallet(appPW, wi.walletPW, wi.form); err != nil {
+			return fmt.Errorf("error creating %s wallet: %w", symbol, err)
+		}
+		c.log.Infof("Created %s wallet from imported settings", symbol)
+	}
+	return nil
+}
+
+// validateWalletSettings checks that all required settings are present.
+// Settings that are not defined by the wallet definition, e.g. settings that
+// were stored by an older version of the wallet, are deleted from settings and
+// returned, sorted, so that the remaining settings can still be used.
+func validateWalletSettings(walletDef *asset.WalletDefinition, settings map[string]string) (dropped []string, err error) {
+	opts := make(map[string]*asset.ConfigOption, len(walletDef.ConfigOpts))
+	for _, opt := range walletDef.ConfigOpts {
+		opts[strings.ToLower(opt.Key)] = opt
+	}
+	for k := range settings {
+		if opts[k] == nil {
+			dropped = append(dropped, k)
+			delete(settings, k)
+		}
+	}
+	sort.Strings(dropped)
+	for k, opt := range opts {
+		if opt.Required && settings[k] == "" {
+			return nil, fmt.Errorf("missing required setting %q", k)
+		}
+	}
+	return dropped, nil
+}
@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"decred.org/dcrdex/client/db"
@@ -69,6 +70,11 @@ var (
 	notesBucket            = []byte("notes")
 	pokesBucket            = []byte("pokes")
 	credentialsBucket      = []byte("credentials")
+	orderTemplatesBucket   = []byte("orderTemplates")
+	balanceAlertsBucket    = []byte("balanceAlerts")
+	orderSchedulesBucket   = []byte("orderSchedules")
+	icebergOrdersBucket    = []byte("icebergOrders")
+	depositAddrsBucket     = []byte("depositAddresses")
 
 	// value keys
 	versionKey            = []byte("version")
@@ -86,6 +92,7 @@ var (
 	bondKey               = []byte("bond")
 	confirmedKey          = []byte("confirmed")
 	refundedKey           = []byte("refunded")
+	replacesKey           = []byte("replaces")
 	lockTimeKey           = []byte("lockTime")
 	dexKey                = []byte("dex")
 	updateTimeKey         = []byte("utime")
@@ -121,10 +128,12 @@ var (
 	optionsKey            = []byte("options")
 	redemptionReservesKey = []byte("redemptionReservesKey")
 	refundReservesKey     = []byte("refundReservesKey")
+	cancelAfterKey        = []byte("cancelAfter")
 	disabledRateSourceKey = []byte("disabledRateSources")
 	walletDisabledKey     = []byte("walletDisabled")
 	programKey            = []byte("program")
 	langKey               = []byte("lang")
+	autoRefundKey         = []byte("autoRefund")
 
 	// values
 	byteTrue   = encode.ByteTrue
@@ -138,8 +147,18 @@ var (
 // Opts is a set of options for the DB.
 type Opts struct {
 	BackupOnShutdown bool // default is true
+	// PruneAge is the retention window for completed orders and matches. If
+	// non-zero, Run prunes the inactive orders and matches that are older
+	// than PruneAge at startup and then every PruneInterval. See Prune.
+	PruneAge time.Duration
+	// PruneInterval is how often the database is pruned. The default is
+	// defaultPruneInterval.
+	PruneInterval time.Duration
 }
 
+// defaultPruneInterval is the default Opts.PruneInterval.
+const defaultPruneInterval = 24 * time.Hour
+
 var defaultOpts = Opts{
 	BackupOnShutdown: true,
 }
@@ -183,7 +202,8 @@ func NewDB(dbPath string, logger dex.Logger, opts ...Opts) (dexdb.DB, error) {
 		activeOrdersBucket, archivedOrdersBucket,
 		activeMatchesBucket, archivedMatchesBucket,
 		walletsBucket, notesBucket, credentialsBucket,
-		botProgramsBucket, pokesBucket,
+		botProgramsBucket, pokesBucket, orderTemplatesBucket, balanceAlertsBucket,
+		orderSchedulesBucket, icebergOrdersBucket, depositAddrsBucket,
 	}); err != nil {
 		return nil, err
 	}
@@ -232,9 +252,20 @@ func (db *BoltDB) fileSize(path string) int64 {
 	return stat.Size()
 }
 
-// Run waits for context cancellation and closes the database.
+// Run waits for context cancellation and closes the database. If pruning is
+// enabled, the database is pruned until the context is canceled.
 func (db *BoltDB) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	if db.opts.PruneAge > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			db.pruneLoop(ctx)
+		}()
+	}
+
 	<-ctx.Done() // wait for shutdown to backup and compact
+	wg.Wait()    // an interrupted prune rolls back its current batch
 
 	// Create a backup in the backups folder.
 	if db.opts.BackupOnShutdown {
@@ -566,6 +597,7 @@ func loadAccountInfo(acct *bbolt.Bucket, log dex.Logger) (*db.AccountInfo, error
 		}
 		dbBond.Confirmed = bEqual(bond.Get(confirmedKey), byteTrue)
 		dbBond.Refunded = bEqual(bond.Get(refundedKey), byteTrue)
+		dbBond.Replaces = getCopy(bond, replacesKey)
 		acctInfo.Bonds = append(acctInfo.Bonds, dbBond)
 	}
 
@@ -816,6 +848,13 @@ func (db *BoltDB) storeBond(bondBkt *bbolt.Bucket, bond *db.Bond) error {
 		return fmt.Errorf("refundedKey put error: %w", err)
 	}
 
+	if len(bond.Replaces) > 0 {
+		err = bondBkt.Put(replacesKey, bond.Replaces)
+		if err != nil {
+			return fmt.Errorf("replacesKey put error: %w", err)
+		}
+	}
+
 	err = bondBkt.Put(lockTimeKey, uint64Bytes(bond.LockTime)) // also in bond encoding
 	if err != nil {
 		return fmt.Errorf("lockTimeKey put error: %w", err)
@@ -1292,6 +1331,11 @@ func decodeOrderBucket(oid []byte, oBkt *bbolt.Bucket) (*dexdb.MetaOrder, error)
 		fundingFeesPaid = intCoder.Uint64(fundingFeesB)
 	}
 
+	var cancelAfter uint64
+	if cancelAfterB := oBkt.Get(cancelAfterKey); len(cancelAfterB) == 8 {
+		cancelAfter = intCoder.Uint64(cancelAfterB)
+	}
+
 	return &dexdb.MetaOrder{
 		MetaData: &dexdb.OrderMetaData{
 			Proof:              *proof,
@@ -1313,6 +1357,7 @@ func decodeOrderBucket(oid []byte, oBkt *bbolt.Bucket) (*dexdb.MetaOrder, error)
 			RefundReserves:     refundReserves,
 			AccelerationCoins:  accelerationCoinIDs,
 			FundingFeesPaid:    fundingFeesPaid,
+			CancelAfter:        cancelAfter,
 		},
 		Order: ord,
 	}, nil
@@ -1401,6 +1446,7 @@ func updateOrderMetaData(bkt *bbolt.Bucket, md *dexdb.OrderMetaData) error {
 		put(refundReservesKey, uint64Bytes(md.RefundReserves)).
 		put(accelerationsKey, accelerationsB).
 		put(fundingFeesKey, uint64Bytes(md.FundingFeesPaid)).
+		put(cancelAfterKey, uint64Bytes(md.CancelAfter)).
 		err()
 }
 
@@ -1940,6 +1986,187 @@ func (db *BoltDB) LoadPokes() (pokes []*dexdb.Notification, _ error) {
 	})
 }
 
+// SaveOrderTemplate stores an order template, replacing any stored template
+// with the same name.
+func (db *BoltDB) SaveOrderTemplate(tmpl *dexdb.OrderTemplate) error {
+	if tmpl.Name == "" {
+		return errors.New("order template has no name")
+	}
+	b, err := json.Marshal(tmpl)
+	if err != nil {
+		return fmt.Errorf("JSON marshal error: %w", err)
+	}
+	return db.withBucket(orderTemplatesBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		return bkt.Put([]byte(tmpl.Name), b)
+	})
+}
+
+// OrderTemplates retrieves all stored order templates, sorted by name.
+func (db *BoltDB) OrderTemplates() (tmpls []*dexdb.OrderTemplate, _ error) {
+	return tmpls, db.withBucket(orderTemplatesBucket, db.View, func(bkt *bbolt.Bucket) error {
+		// Keys are iterated in byte-sorted order.
+		return bkt.ForEach(func(k, v []byte) error {
+			tmpl := new(dexdb.OrderTemplate)
+			if err := json.Unmarshal(v, tmpl); err != nil {
+				return fmt.Errorf("error decoding order template %q: %w", string(k), err)
+			}
+			tmpls = append(tmpls, tmpl)
+			return nil
+		})
+	})
+}
+
+// DeleteOrderTemplate deletes the named order template. dexdb.ErrNoTemplate
+// is returned if there is no template with the name.
+func (db *BoltDB) DeleteOrderTemplate(name string) error {
+	return db.withBucket(orderTemplatesBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		if bkt.Get([]byte(name)) == nil {
+			return dexdb.ErrNoTemplate
+		}
+		return bkt.Delete([]byte(name))
+	})
+}
+
+// SaveOrderSchedule stores an order schedule, replacing any stored schedule
+// with the same ID.
+func (db *BoltDB) SaveOrderSchedule(sched *dexdb.OrderSchedule) error {
+	if sched.ID == "" {
+		return errors.New("order schedule has no ID")
+	}
+	b, err := json.Marshal(sched)
+	if err != nil {
+		return fmt.Errorf("JSON marshal error: %w", err)
+	}
+	return db.withBucket(orderSchedulesBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		return bkt.Put([]byte(sched.ID), b)
+	})
+}
+
+// OrderSchedules retrieves all stored order schedules, sorted by ID.
+func (db *BoltDB) OrderSchedules() (scheds []*dexdb.OrderSchedule, _ error) {
+	return scheds, db.withBucket(orderSchedulesBucket, db.View, func(bkt *bbolt.Bucket) error {
+		return bkt.ForEach(func(k, v []byte) error {
+			sched := new(dexdb.OrderSchedule)
+			if err := json.Unmarshal(v, sched); err != nil {
+				return fmt.Errorf("error decoding order schedule %q: %w", string(k), err)
+			}
+			scheds = append(scheds, sched)
+			return nil
+		})
+	})
+}
+
+// DeleteOrderSchedule deletes the order schedule with the ID.
+// dexdb.ErrNoSchedule is returned if there is no schedule with the ID.
+func (db *BoltDB) DeleteOrderSchedule(id string) error {
+	return db.withBucket(orderSchedulesBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		if bkt.Get([]byte(id)) == nil {
+			return dexdb.ErrNoSchedule
+		}
+		return bkt.Delete([]byte(id))
+	})
+}
+
+// SaveIcebergOrder stores an iceberg order, replacing any stored iceberg order
+// with the same ID.
+func (db *BoltDB) SaveIcebergOrder(ice *dexdb.IcebergOrder) error {
+	if ice.ID == "" {
+		return errors.New("iceberg order has no ID")
+	}
+	b, err := json.Marshal(ice)
+	if err != nil {
+		return fmt.Errorf("JSON marshal error: %w", err)
+	}
+	return db.withBucket(icebergOrdersBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		return bkt.Put([]byte(ice.ID), b)
+	})
+}
+
+// IcebergOrders retrieves all stored iceberg orders, sorted by ID.
+func (db *BoltDB) IcebergOrders() (ices []*dexdb.IcebergOrder, _ error) {
+	return ices, db.withBucket(icebergOrdersBucket, db.View, func(bkt *bbolt.Bucket) error {
+		return bkt.ForEach(func(k, v []byte) error {
+			ice := new(dexdb.IcebergOrder)
+			if err := json.Unmarshal(v, ice); err != nil {
+				return fmt.Errorf("error decoding iceberg order %q: %w", string(k), err)
+			}
+			ices = append(ices, ice)
+			return nil
+		})
+	})
+}
+
+// DeleteIcebergOrder deletes the iceberg order with the ID.
+// dexdb.ErrNoIceberg is returned if there is no iceberg order with the ID.
+func (db *BoltDB) DeleteIcebergOrder(id string) error {
+	return db.withBucket(icebergOrdersBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		if bkt.Get([]byte(id)) == nil {
+			return dexdb.ErrNoIceberg
+		}
+		return bkt.Delete([]byte(id))
+	})
+}
+
+// SetBalanceAlert stores the minimum balance alert threshold for the asset. A
+// zero threshold deletes the alert.
+func (db *BoltDB) SetBalanceAlert(assetID uint32, threshold uint64) error {
+	return db.withBucket(balanceAlertsBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		k := uint32Bytes(assetID)
+		if threshold == 0 {
+			return bkt.Delete(k)
+		}
+		return bkt.Put(k, uint64Bytes(threshold))
+	})
+}
+
+// BalanceAlerts retrieves the minimum balance alert thresholds by asset ID.
+func (db *BoltDB) BalanceAlerts() (map[uint32]uint64, error) {
+	alerts := make(map[uint32]uint64)
+	return alerts, db.withBucket(balanceAlertsBucket, db.View, func(bkt *bbolt.Bucket) error {
+		return bkt.ForEach(func(k, v []byte) error {
+			if len(k) != 4 || len(v) != 8 {
+				return fmt.Errorf("invalid balance alert entry %x: %x", k, v)
+			}
+			alerts[intCoder.Uint32(k)] = intCoder.Uint64(v)
+			return nil
+		})
+	})
+}
+
+// SaveDepositAddressRecord stores the deposit address policy and tracked
+// deposit addresses for the asset, replacing any stored record.
+func (db *BoltDB) SaveDepositAddressRecord(rec *dexdb.DepositAddressRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("JSON marshal error: %w", err)
+	}
+	return db.withBucket(depositAddrsBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		return bkt.Put(uint32Bytes(rec.AssetID), b)
+	})
+}
+
+// DepositAddressRecords retrieves all stored deposit address records.
+func (db *BoltDB) DepositAddressRecords() (recs []*dexdb.DepositAddressRecord, _ error) {
+	return recs, db.withBucket(depositAddrsBucket, db.View, func(bkt *bbolt.Bucket) error {
+		return bkt.ForEach(func(k, v []byte) error {
+			rec := new(dexdb.DepositAddressRecord)
+			if err := json.Unmarshal(v, rec); err != nil {
+				return fmt.Errorf("error decoding deposit address record %x: %w", k, err)
+			}
+			recs = append(recs, rec)
+			return nil
+		})
+	})
+}
+
+// DeleteDepositAddressRecord deletes the deposit address record for the asset,
+// if there is one.
+func (db *BoltDB) DeleteDepositAddressRecord(assetID uint32) error {
+	return db.withBucket(depositAddrsBucket, db.Update, func(bkt *bbolt.Bucket) error {
+		return bkt.Delete(uint32Bytes(assetID))
+	})
+}
+
 // newest buckets gets the nested buckets with the hightest timestamp from the
 // specified master buckets. The nested bucket should have an encoded uint64 at
 // the timeKey. An optional filter function can be used to reject buckets.
@@ -2266,6 +2493,57 @@ func (db *BoltDB) DeleteInactiveOrders(ctx context.Context, olderThan *time.Time
 	return nDeletedOrders, nil
 }
 
+// Prune deletes the inactive matches and orders that were last updated before
+// olderThan. Active matches, including those awaiting a refund, are never
+// deleted, nor are the orders that are active or that have active matches, or
+// the matches of active orders. Pruning is done in batches, and stops when the
+// context is canceled.
+func (db *BoltDB) Prune(ctx context.Context, olderThan time.Time) (nMatches, nOrders int, err error) {
+	// Matches are deleted first, so that an interrupted prune does not leave
+	// archived matches without their orders.
+	nMatches, err = db.DeleteInactiveMatches(ctx, &olderThan, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	nOrders, err = db.DeleteInactiveOrders(ctx, &olderThan, nil)
+	if err != nil {
+		return nMatches, 0, err
+	}
+	return nMatches, nOrders, nil
+}
+
+// pruneLoop prunes the records that are older than the PruneAge now and every
+// PruneInterval until the context is canceled.
+func (db *BoltDB) pruneLoop(ctx context.Context) {
+	interval := db.opts.PruneInterval
+	if interval <= 0 {
+		interval = defaultPruneInterval
+	}
+	prune := func() {
+		nMatches, nOrders, err := db.Prune(ctx, time.Now().Add(-db.opts.PruneAge))
+		switch {
+		case ctx.Err() != nil:
+			db.log.Infof("Database pruning interrupted by shutdown")
+		case err != nil:
+			db.log.Errorf("Error pruning database: %v", err)
+		case nMatches+nOrders > 0:
+			db.log.Infof("Pruned %d matches and %d orders older than %v from the database",
+				nMatches, nOrders, db.opts.PruneAge)
+		}
+	}
+	prune()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			prune()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // orderSide Returns wether the order was for buying or selling the asset.
 func orderSide(tx *bbolt.Tx, oid order.OrderID) (sell bool, err error) {
 	oidB := oid[:]
@@ -2462,6 +2740,33 @@ func (db *BoltDB) Language() (lang string, _ error) {
 	})
 }
 
+// SetAutoRefund stores whether expired swaps are refunded automatically.
+func (db *BoltDB) SetAutoRefund(enable bool) error {
+	return db.Update(func(dbTx *bbolt.Tx) error {
+		bkt := dbTx.Bucket(appBucket)
+		if bkt == nil {
+			return fmt.Errorf("app bucket not found")
+		}
+		v := byteFalse
+		if enable {
+			v = byteTrue
+		}
+		return bkt.Put(autoRefundKey, v)
+	})
+}
+
+// AutoRefund retrieves the setting stored with SetAutoRefund. If no setting
+// has been stored, auto-refund is disabled.
+func (db *BoltDB) AutoRefund() (enabled bool, _ error) {
+	return enabled, db.View(func(dbTx *bbolt.Tx) error {
+		bkt := dbTx.Bucket(appBucket)
+		if bkt != nil {
+			enabled = bytes.Equal(bkt.Get(autoRefundKey), byteTrue)
+		}
+		return nil
+	})
+}
+
 // timeNow is the current unix timestamp in milliseconds.
 func timeNow() uint64 {
 	return uint64(time.Now().UnixMilli())
@@ -8,6 +8,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"decred.org/dcrdex/client/db"
 	dbtest "decred.org/dcrdex/client/db/test"
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/dex/order"
 	ordertest "decred.org/dcrdex/dex/order/test"
 	"go.etcd.io/bbolt"
@@ -266,6 +268,49 @@ func TestAccounts(t *testing.T) {
 	acct.DEXPubKey = dexKey
 }
 
+func TestBondReplaces(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	acct := dbtest.RandomAccountInfo()
+	if err := boltdb.CreateAccount(acct); err != nil {
+		t.Fatalf("CreateAccount error: %v", err)
+	}
+	oldBond := &db.Bond{AssetID: 42, CoinID: encode.RandomBytes(36), Amount: 1e8, LockTime: 1000}
+	newBond := &db.Bond{AssetID: 42, CoinID: encode.RandomBytes(36), Amount: 1e8, LockTime: 2000,
+		Replaces: oldBond.CoinID}
+	for _, bond := range []*db.Bond{oldBond, newBond} {
+		if err := boltdb.AddBond(acct.Host, bond); err != nil {
+			t.Fatalf("AddBond error: %v", err)
+		}
+	}
+	if err := boltdb.ConfirmBond(acct.Host, 42, newBond.CoinID); err != nil {
+		t.Fatalf("ConfirmBond error: %v", err)
+	}
+
+	reAI, err := boltdb.Account(acct.Host)
+	if err != nil {
+		t.Fatalf("Account error: %v", err)
+	}
+	if len(reAI.Bonds) != 2 {
+		t.Fatalf("expected 2 bonds, got %d", len(reAI.Bonds))
+	}
+	for _, bond := range reAI.Bonds {
+		switch {
+		case bytes.Equal(bond.CoinID, oldBond.CoinID):
+			if bond.Replaces != nil {
+				t.Fatalf("old bond has a replaced bond %s", bond.Replaces)
+			}
+		case bytes.Equal(bond.CoinID, newBond.CoinID):
+			if !bond.Confirmed || !bytes.Equal(bond.Replaces, oldBond.CoinID) {
+				t.Fatalf("wrong replacement bond. confirmed = %t, replaces %s", bond.Confirmed, bond.Replaces)
+			}
+		default:
+			t.Fatalf("unknown bond %s", bond.CoinID)
+		}
+	}
+}
+
 func TestDisableAccount(t *testing.T) {
 	boltdb, shutdown := newTestDB(t)
 	defer shutdown()
@@ -457,6 +502,7 @@ func TestOrders(t *testing.T) {
 				SwapFeesPaid:       rand.Uint64(),
 				RedemptionFeesPaid: rand.Uint64(),
 				MaxFeeRate:         rand.Uint64(),
+				CancelAfter:        rand.Uint64(),
 			},
 			Order: ord,
 		}
@@ -497,6 +543,9 @@ func TestOrders(t *testing.T) {
 	if firstOrd.MetaData.MaxFeeRate != mord.MetaData.MaxFeeRate {
 		t.Fatalf("wrong MaxFeeRate. wanted %d, got %d", firstOrd.MetaData.MaxFeeRate, mord.MetaData.MaxFeeRate)
 	}
+	if firstOrd.MetaData.CancelAfter != mord.MetaData.CancelAfter {
+		t.Fatalf("wrong CancelAfter. wanted %d, got %d", firstOrd.MetaData.CancelAfter, mord.MetaData.CancelAfter)
+	}
 
 	// Check the active orders.
 	activeOrders, err := boltdb.ActiveOrders()
@@ -1494,6 +1543,153 @@ func TestDeleteInactiveOrders(t *testing.T) {
 	}
 }
 
+func TestPrune(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "db.db")
+	dbi, err := NewDB(dbPath, tLogger)
+	if err != nil {
+		t.Fatalf("error creating dB: %v", err)
+	}
+	boltdb := dbi.(*BoltDB)
+
+	acct := dbtest.RandomAccountInfo()
+	if err := boltdb.CreateAccount(acct); err != nil {
+		t.Fatalf("CreateAccount error: %v", err)
+	}
+	base, quote := randU32(), randU32()
+
+	newOrder := func(status order.OrderStatus) order.OrderID {
+		t.Helper()
+		ord := randOrderForMarket(base, quote)
+		err := boltdb.UpdateOrder(&db.MetaOrder{
+			MetaData: &db.OrderMetaData{
+				Status: status,
+				Host:   acct.Host,
+				Proof:  db.OrderProof{DEXSig: randBytes(73)},
+			},
+			Order: ord,
+		})
+		if err != nil {
+			t.Fatalf("error inserting order: %v", err)
+		}
+		return ord.ID()
+	}
+	newMatch := func(oid order.OrderID, status order.MatchStatus, stamp time.Time) order.MatchID {
+		t.Helper()
+		m := &db.MetaMatch{
+			MetaData: &db.MatchMetaData{
+				Proof: db.MatchProof{MakerSwap: randBytes(36)},
+				DEX:   acct.Host,
+				Base:  base,
+				Quote: quote,
+				Stamp: uint64(stamp.UnixMilli()),
+			},
+			UserMatch: ordertest.RandomUserMatch(),
+		}
+		m.OrderID, m.Status, m.Side = oid, status, order.Maker
+		if err := boltdb.UpdateMatch(m); err != nil {
+			t.Fatalf("error inserting match: %v", err)
+		}
+		return m.MatchID
+	}
+
+	oldDone := newOrder(order.OrderStatusExecuted)
+	oldBooked := newOrder(order.OrderStatusBooked)
+	// An executed order with a match that is awaiting a refund.
+	oldRefunding := newOrder(order.OrderStatusExecuted)
+	olderThan := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	newDone := newOrder(order.OrderStatusExecuted)
+
+	oldStamp, newStamp := olderThan.Add(-time.Hour), olderThan.Add(time.Hour)
+	oldComplete := newMatch(oldDone, order.MatchConfirmed, oldStamp)
+	oldOfBooked := newMatch(oldBooked, order.MatchConfirmed, oldStamp)
+	refunding := newMatch(oldRefunding, order.MakerSwapCast, oldStamp)
+	newComplete := newMatch(newDone, order.MatchConfirmed, newStamp)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nMatches, nOrders, err := boltdb.Prune(ctx, olderThan)
+	if err != nil {
+		t.Fatalf("Prune error: %v", err)
+	}
+	if nMatches != 1 || nOrders != 1 {
+		t.Fatalf("expected 1 match and 1 order pruned, got %d and %d", nMatches, nOrders)
+	}
+
+	if _, err := boltdb.Order(oldDone); err == nil {
+		t.Fatalf("old completed order not pruned")
+	}
+	for _, oid := range []order.OrderID{oldBooked, oldRefunding, newDone} {
+		if _, err := boltdb.Order(oid); err != nil {
+			t.Fatalf("order %v was pruned: %v", oid, err)
+		}
+	}
+	remaining := make(map[order.MatchID]bool)
+	if err := boltdb.View(func(tx *bbolt.Tx) error {
+		for _, bkt := range [][]byte{activeMatchesBucket, archivedMatchesBucket} {
+			mb := tx.Bucket(bkt)
+			if err := mb.ForEach(func(k, _ []byte) error {
+				m, err := loadMatchBucket(mb.Bucket(k), false)
+				if err != nil {
+					return err
+				}
+				remaining[m.MatchID] = true
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("error loading matches: %v", err)
+	}
+	if remaining[oldComplete] {
+		t.Fatalf("old completed match not pruned")
+	}
+	for _, mid := range []order.MatchID{oldOfBooked, refunding, newComplete} {
+		if !remaining[mid] {
+			t.Fatalf("match %v was pruned", mid)
+		}
+	}
+
+	cancel()
+	if _, _, err := boltdb.Prune(ctx, time.Now()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled from an interrupted prune, got %v", err)
+	}
+
+	// Reopen the DB with pruning enabled. Everything inactive is pruned at
+	// startup.
+	boltdb.Close()
+	dbi, err = NewDB(dbPath, tLogger, Opts{PruneAge: time.Nanosecond})
+	if err != nil {
+		t.Fatalf("error opening dB: %v", err)
+	}
+	boltdb = dbi.(*BoltDB)
+	ctx, cancel = context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		boltdb.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	for i := 0; ; i++ {
+		if _, err := boltdb.Order(newDone); err != nil {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("order not pruned at startup")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := boltdb.Order(oldBooked); err != nil {
+		t.Fatalf("active order pruned at startup: %v", err)
+	}
+}
+
 func TestOrderSide(t *testing.T) {
 	boltdb, shutdown := newTestDB(t)
 	defer shutdown()
@@ -1599,3 +1795,294 @@ func TestPokes(t *testing.T) {
 		t.Fatal("Result from second LoadPokes wasn't empty")
 	}
 }
+
+func TestOrderTemplates(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	tmplA := &db.OrderTemplate{
+		Name:       "a",
+		Host:       "somedex.com",
+		Base:       42,
+		Quote:      0,
+		IsLimit:    true,
+		Sell:       true,
+		Qty:        1e8,
+		RateOffset: 0.01,
+		Options:    map[string]string{"swapfeebump": "1.5"},
+	}
+	tmplB := &db.OrderTemplate{Name: "b", Host: "somedex.com", Qty: 2e8}
+	for _, tmpl := range []*db.OrderTemplate{tmplB, tmplA} {
+		if err := boltdb.SaveOrderTemplate(tmpl); err != nil {
+			t.Fatalf("SaveOrderTemplate error: %v", err)
+		}
+	}
+	if err := boltdb.SaveOrderTemplate(&db.OrderTemplate{}); err == nil {
+		t.Fatalf("no error saving a template without a name")
+	}
+
+	tmpls, err := boltdb.OrderTemplates()
+	if err != nil {
+		t.Fatalf("OrderTemplates error: %v", err)
+	}
+	if len(tmpls) != 2 || !reflect.DeepEqual(tmpls[0], tmplA) || !reflect.DeepEqual(tmpls[1], tmplB) {
+		t.Fatalf("wrong templates loaded: %+v", tmpls)
+	}
+
+	// Saving with the same name replaces the template.
+	tmplB.Qty = 3e8
+	if err := boltdb.SaveOrderTemplate(tmplB); err != nil {
+		t.Fatalf("SaveOrderTemplate error: %v", err)
+	}
+	if tmpls, _ = boltdb.OrderTemplates(); len(tmpls) != 2 || tmpls[1].Qty != 3e8 {
+		t.Fatalf("template not replaced: %+v", tmpls)
+	}
+
+	if err := boltdb.DeleteOrderTemplate("a"); err != nil {
+		t.Fatalf("DeleteOrderTemplate error: %v", err)
+	}
+	if err := boltdb.DeleteOrderTemplate("a"); !errors.Is(err, db.ErrNoTemplate) {
+		t.Fatalf("wrong error deleting a missing template: %v", err)
+	}
+	if tmpls, _ = boltdb.OrderTemplates(); len(tmpls) != 1 || tmpls[0].Name != "b" {
+		t.Fatalf("wrong templates after delete: %+v", tmpls)
+	}
+}
+
+func TestOrderSchedules(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	schedA := &db.OrderSchedule{
+		ID: "a",
+		OrderTemplate: db.OrderTemplate{
+			Name:  "weekly",
+			Host:  "somedex.com",
+			Base:  42,
+			Quote: 0,
+			Qty:   1e8,
+		},
+		Interval: 7 * 24 * time.Hour,
+		Next:     1700000000000,
+		Placed:   3,
+		Skipped:  1,
+	}
+	schedB := &db.OrderSchedule{ID: "b", Interval: time.Hour, Paused: true}
+	for _, sched := range []*db.OrderSchedule{schedB, schedA} {
+		if err := boltdb.SaveOrderSchedule(sched); err != nil {
+			t.Fatalf("SaveOrderSchedule error: %v", err)
+		}
+	}
+	if err := boltdb.SaveOrderSchedule(&db.OrderSchedule{}); err == nil {
+		t.Fatalf("no error saving a schedule without an ID")
+	}
+
+	scheds, err := boltdb.OrderSchedules()
+	if err != nil {
+		t.Fatalf("OrderSchedules error: %v", err)
+	}
+	if len(scheds) != 2 || !reflect.DeepEqual(scheds[0], schedA) || !reflect.DeepEqual(scheds[1], schedB) {
+		t.Fatalf("wrong schedules loaded: %+v", scheds)
+	}
+
+	// Saving with the same ID replaces the schedule.
+	schedA.Placed++
+	if err := boltdb.SaveOrderSchedule(schedA); err != nil {
+		t.Fatalf("SaveOrderSchedule error: %v", err)
+	}
+	if scheds, _ = boltdb.OrderSchedules(); len(scheds) != 2 || scheds[0].Placed != 4 {
+		t.Fatalf("schedule not replaced: %+v", scheds)
+	}
+
+	if err := boltdb.DeleteOrderSchedule("a"); err != nil {
+		t.Fatalf("DeleteOrderSchedule error: %v", err)
+	}
+	if err := boltdb.DeleteOrderSchedule("a"); !errors.Is(err, db.ErrNoSchedule) {
+		t.Fatalf("wrong error deleting a missing schedule: %v", err)
+	}
+	if scheds, _ = boltdb.OrderSchedules(); len(scheds) != 1 || scheds[0].ID != "b" {
+		t.Fatalf("wrong schedules after delete: %+v", scheds)
+	}
+}
+
+func TestIcebergOrders(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	child := dex.Bytes(randBytes(order.OrderIDSize))
+	iceA := &db.IcebergOrder{
+		ID:         "a",
+		Host:       "somedex.com",
+		Base:       42,
+		Quote:      0,
+		Sell:       true,
+		Rate:       1e6,
+		Qty:        10e8,
+		DisplayQty: 1e8,
+		Filled:     2e8,
+		Child:      child,
+		Children:   []dex.Bytes{randBytes(order.OrderIDSize), randBytes(order.OrderIDSize), child},
+	}
+	iceB := &db.IcebergOrder{ID: "b", Qty: 5e8, Filled: 5e8, Done: true}
+	for _, ice := range []*db.IcebergOrder{iceB, iceA} {
+		if err := boltdb.SaveIcebergOrder(ice); err != nil {
+			t.Fatalf("SaveIcebergOrder error: %v", err)
+		}
+	}
+	if err := boltdb.SaveIcebergOrder(&db.IcebergOrder{}); err == nil {
+		t.Fatalf("no error saving an iceberg order without an ID")
+	}
+
+	ices, err := boltdb.IcebergOrders()
+	if err != nil {
+		t.Fatalf("IcebergOrders error: %v", err)
+	}
+	if len(ices) != 2 || !reflect.DeepEqual(ices[0], iceA) || !reflect.DeepEqual(ices[1], iceB) {
+		t.Fatalf("wrong iceberg orders loaded: %+v", ices)
+	}
+
+	// Saving with the same ID replaces the iceberg order.
+	iceA.Canceled = true
+	if err := boltdb.SaveIcebergOrder(iceA); err != nil {
+		t.Fatalf("SaveIcebergOrder error: %v", err)
+	}
+	if ices, _ = boltdb.IcebergOrders(); len(ices) != 2 || !ices[0].Canceled {
+		t.Fatalf("iceberg order not replaced: %+v", ices)
+	}
+
+	if err := boltdb.DeleteIcebergOrder("b"); err != nil {
+		t.Fatalf("DeleteIcebergOrder error: %v", err)
+	}
+	if err := boltdb.DeleteIcebergOrder("b"); !errors.Is(err, db.ErrNoIceberg) {
+		t.Fatalf("wrong error deleting a missing iceberg order: %v", err)
+	}
+	if ices, _ = boltdb.IcebergOrders(); len(ices) != 1 || ices[0].ID != "a" {
+		t.Fatalf("wrong iceberg orders after deletion: %+v", ices)
+	}
+}
+
+func TestBalanceAlerts(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	alerts, err := boltdb.BalanceAlerts()
+	if err != nil {
+		t.Fatalf("BalanceAlerts error: %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("expected no alerts, got %v", alerts)
+	}
+
+	if err := boltdb.SetBalanceAlert(42, 1e8); err != nil {
+		t.Fatalf("SetBalanceAlert error: %v", err)
+	}
+	if err := boltdb.SetBalanceAlert(0, 5e5); err != nil {
+		t.Fatalf("SetBalanceAlert error: %v", err)
+	}
+	// Replace one.
+	if err := boltdb.SetBalanceAlert(42, 2e8); err != nil {
+		t.Fatalf("SetBalanceAlert error: %v", err)
+	}
+	alerts, err = boltdb.BalanceAlerts()
+	if err != nil {
+		t.Fatalf("BalanceAlerts error: %v", err)
+	}
+	if !reflect.DeepEqual(alerts, map[uint32]uint64{42: 2e8, 0: 5e5}) {
+		t.Fatalf("wrong alerts %v", alerts)
+	}
+
+	// A zero threshold deletes the alert.
+	if err := boltdb.SetBalanceAlert(42, 0); err != nil {
+		t.Fatalf("SetBalanceAlert error: %v", err)
+	}
+	if alerts, _ = boltdb.BalanceAlerts(); !reflect.DeepEqual(alerts, map[uint32]uint64{0: 5e5}) {
+		t.Fatalf("wrong alerts after delete %v", alerts)
+	}
+}
+
+func TestAutoRefund(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	// Disabled until enabled.
+	if enabled, err := boltdb.AutoRefund(); err != nil || enabled {
+		t.Fatalf("expected auto-refund disabled by default, got %t, %v", enabled, err)
+	}
+	if err := boltdb.SetAutoRefund(true); err != nil {
+		t.Fatalf("SetAutoRefund error: %v", err)
+	}
+	if enabled, err := boltdb.AutoRefund(); err != nil || !enabled {
+		t.Fatalf("expected auto-refund enabled, got %t, %v", enabled, err)
+	}
+	if err := boltdb.SetAutoRefund(false); err != nil {
+		t.Fatalf("SetAutoRefund error: %v", err)
+	}
+	if enabled, _ := boltdb.AutoRefund(); enabled {
+		t.Fatalf("expected auto-refund disabled")
+	}
+}
+
+func TestDepositAddressRecords(t *testing.T) {
+	boltdb, shutdown := newTestDB(t)
+	defer shutdown()
+
+	recs, err := boltdb.DepositAddressRecords()
+	if err != nil {
+		t.Fatalf("DepositAddressRecords error: %v", err)
+	}
+	if len(recs) != 0 {
+		t.Fatalf("expected no records, got %d", len(recs))
+	}
+
+	recA := &db.DepositAddressRecord{
+		AssetID: 42,
+		DepositAddressPolicy: db.DepositAddressPolicy{
+			Interval:  time.Hour,
+			OnDeposit: true,
+		},
+		Addresses: []*db.DepositAddress{
+			{Address: "addr1", Stamp: 1000, Received: 5e7, Deposits: 1},
+			{Address: "addr2", Stamp: 2000},
+		},
+		Credited: []string{"tx1"},
+	}
+	recB := &db.DepositAddressRecord{
+		AssetID:              0,
+		DepositAddressPolicy: db.DepositAddressPolicy{OnDeposit: true},
+		Addresses:            []*db.DepositAddress{{Address: "addr3", Stamp: 3000}},
+	}
+	for _, rec := range []*db.DepositAddressRecord{recA, recB} {
+		if err := boltdb.SaveDepositAddressRecord(rec); err != nil {
+			t.Fatalf("SaveDepositAddressRecord error: %v", err)
+		}
+	}
+	recs, err = boltdb.DepositAddressRecords()
+	if err != nil {
+		t.Fatalf("DepositAddressRecords error: %v", err)
+	}
+	// Keyed by asset ID.
+	if len(recs) != 2 || !reflect.DeepEqual(recs[0], recB) || !reflect.DeepEqual(recs[1], recA) {
+		t.Fatalf("wrong records %+v", recs)
+	}
+
+	// Saving for the same asset replaces the record.
+	recA.Addresses[1].Received = 1e8
+	recA.Credited = append(recA.Credited, "tx2")
+	if err := boltdb.SaveDepositAddressRecord(recA); err != nil {
+		t.Fatalf("SaveDepositAddressRecord error: %v", err)
+	}
+	if recs, _ = boltdb.DepositAddressRecords(); len(recs) != 2 || !reflect.DeepEqual(recs[1], recA) {
+		t.Fatalf("record not replaced: %+v", recs)
+	}
+
+	if err := boltdb.DeleteDepositAddressRecord(0); err != nil {
+		t.Fatalf("DeleteDepositAddressRecord error: %v", err)
+	}
+	// Deleting a missing record is not an error.
+	if err := boltdb.DeleteDepositAddressRecord(0); err != nil {
+		t.Fatalf("DeleteDepositAddressRecord error for missing record: %v", err)
+	}
+	if recs, _ = boltdb.DepositAddressRecords(); len(recs) != 1 || recs[0].AssetID != 42 {
+		t.Fatalf("wrong records after delete %+v", recs)
+	}
+}
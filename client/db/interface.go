@@ -154,4 +154,47 @@ type DB interface {
 	SetLanguage(lang string) error
 	// Language gets the language stored with SetLanguage.
 	Language() (string, error)
+	// SetAutoRefund stores whether expired swaps are refunded automatically.
+	SetAutoRefund(enable bool) error
+	// AutoRefund gets the setting stored with SetAutoRefund. Auto-refund is
+	// disabled if it has not been stored.
+	AutoRefund() (bool, error)
+	// SaveOrderTemplate stores an order template, replacing any stored
+	// template with the same name.
+	SaveOrderTemplate(*OrderTemplate) error
+	// OrderTemplates retrieves all stored order templates, sorted by name.
+	OrderTemplates() ([]*OrderTemplate, error)
+	// DeleteOrderTemplate deletes the named order template. ErrNoTemplate is
+	// returned if there is no template with the name.
+	DeleteOrderTemplate(name string) error
+	// SaveOrderSchedule stores an order schedule, replacing any stored
+	// schedule with the same ID.
+	SaveOrderSchedule(*OrderSchedule) error
+	// OrderSchedules retrieves all stored order schedules, sorted by ID.
+	OrderSchedules() ([]*OrderSchedule, error)
+	// DeleteOrderSchedule deletes the order schedule with the ID.
+	// ErrNoSchedule is returned if there is no schedule with the ID.
+	DeleteOrderSchedule(id string) error
+	// SaveIcebergOrder stores an iceberg order, replacing any stored iceberg
+	// order with the same ID.
+	SaveIcebergOrder(*IcebergOrder) error
+	// IcebergOrders retrieves all stored iceberg orders, sorted by ID.
+	IcebergOrders() ([]*IcebergOrder, error)
+	// DeleteIcebergOrder deletes the iceberg order with the ID. ErrNoIceberg
+	// is returned if there is no iceberg order with the ID.
+	DeleteIcebergOrder(id string) error
+	// SetBalanceAlert stores the minimum balance alert threshold for the
+	// asset. A zero threshold deletes the alert.
+	SetBalanceAlert(assetID uint32, threshold uint64) error
+	// BalanceAlerts retrieves the minimum balance alert thresholds by asset
+	// ID.
+	BalanceAlerts() (map[uint32]uint64, error)
+	// SaveDepositAddressRecord stores the deposit address policy and tracked
+	// deposit addresses for the asset, replacing any stored record.
+	SaveDepositAddressRecord(*DepositAddressRecord) error
+	// DepositAddressRecords retrieves all stored deposit address records.
+	DepositAddressRecords() ([]*DepositAddressRecord, error)
+	// DeleteDepositAddressRecord deletes the deposit address record for the
+	// asset, if there is one.
+	DeleteDepositAddressRecord(assetID uint32) error
 }
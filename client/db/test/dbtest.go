@@ -256,6 +256,14 @@ func MustCompareMatchProof(t testKiller, m1, m2 *db.MatchProof) {
 	if !bytes.Equal(m1.TakerRedeem, m2.TakerRedeem) {
 		t.Fatalf("TakerRedeem mismatch. %x != %x", m1.TakerRedeem, m2.TakerRedeem)
 	}
+	if len(m1.DuplicateSwaps) != len(m2.DuplicateSwaps) {
+		t.Fatalf("DuplicateSwaps length mismatch. %d != %d", len(m1.DuplicateSwaps), len(m2.DuplicateSwaps))
+	}
+	for i := range m1.DuplicateSwaps {
+		if !bytes.Equal(m1.DuplicateSwaps[i], m2.DuplicateSwaps[i]) {
+			t.Fatalf("DuplicateSwaps mismatch. %x != %x", m1.DuplicateSwaps[i], m2.DuplicateSwaps[i])
+		}
+	}
 	MustCompareMatchAuth(t, &m1.Auth, &m2.Auth)
 }
 
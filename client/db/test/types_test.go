@@ -35,7 +35,15 @@ func TestMatchProof(t *testing.T) {
 	proofs := make([]*db.MatchProof, 0, spins)
 	// Generate proofs with an average of 20% sparsity. Empty fields should not
 	// affect accurate encoding/decoding.
-	nTimes(spins, func(int) { proofs = append(proofs, RandomMatchProof(0.4)) })
+	nTimes(spins, func(i int) {
+		proof := RandomMatchProof(0.4)
+		// DuplicateSwaps keep the match active, so RandomMatchProof leaves
+		// them empty.
+		for j := 0; j < i%3; j++ {
+			proof.DuplicateSwaps = append(proof.DuplicateSwaps, randBytes(36))
+		}
+		proofs = append(proofs, proof)
+	})
 	tStart := time.Now()
 	nTimes(spins, func(i int) {
 		proof := proofs[i]
@@ -45,6 +45,9 @@ const (
 	ErrNoCredentials = dex.ErrorKind("no credentials have been stored")
 	ErrAcctNotFound  = dex.ErrorKind("account not found")
 	ErrNoSeedGenTime = dex.ErrorKind("seed generation time has not been stored")
+	ErrNoTemplate    = dex.ErrorKind("order template not found")
+	ErrNoSchedule    = dex.ErrorKind("order schedule not found")
+	ErrNoIceberg     = dex.ErrorKind("iceberg order not found")
 )
 
 // String satisfies fmt.Stringer for Severity.
@@ -111,6 +114,9 @@ type Bond struct {
 
 	Confirmed bool `json:"confirmed"` // if reached required confs according to server, not in serialization
 	Refunded  bool `json:"refunded"`  // not in serialization
+	// Replaces is the coin ID of the bond, of the same asset, that this bond
+	// was posted to replace, if any. Not in serialization.
+	Replaces dex.Bytes `json:"replaces,omitempty"`
 
 	Strength uint32 `json:"strength"`
 }
@@ -478,6 +484,10 @@ type OrderMetaData struct {
 	// AccelerationCoins keeps track of all the change coins generated from doing
 	// accelerations on this order.
 	AccelerationCoins []order.CoinID
+	// CancelAfter is the time, in unix milliseconds, after which a standing
+	// limit order that is still open is canceled by the client. Zero means
+	// the order has no TTL.
+	CancelAfter uint64
 }
 
 // MetaMatch is a match and its metadata.
@@ -498,6 +508,11 @@ func (m *MetaMatch) MatchOrderUniqueID() []byte {
 // never active, (2) the match is refunded, or (3) it is revoked and this side
 // of the match requires no further action like refund or auto-redeem.
 func MatchIsActive(match *order.UserMatch, proof *MatchProof) bool {
+	// Duplicate swaps must be refunded, regardless of the match status.
+	if len(proof.DuplicateSwaps) > 0 {
+		return true
+	}
+
 	// MatchComplete only means inactive if: (a) cancel order match or (b) the
 	// redeem request was accepted for trade orders. A cancel order match starts
 	// complete and has no InitSig as there is no swap negotiation.
@@ -623,6 +638,10 @@ type MatchProof struct {
 	// RedemptionFeeConfirmed indicate the fees for this match have been
 	// confirmed and the value added to the trade.
 	RedemptionFeeConfirmed bool
+	// DuplicateSwaps are contract coins for this match's swap other than the
+	// one reported to the server, e.g. if the swap was broadcast twice. They
+	// are removed as they are refunded.
+	DuplicateSwaps []order.CoinID
 }
 
 func boolByte(b bool) []byte {
@@ -634,8 +653,8 @@ func boolByte(b bool) []byte {
 
 // MatchProofVer is the current serialization version of a MatchProof.
 const (
-	MatchProofVer    = 3
-	matchProofPushes = 24
+	MatchProofVer    = 4
+	matchProofPushes = 25
 )
 
 // Encode encodes the MatchProof to a versioned blob.
@@ -665,7 +684,37 @@ func (p *MatchProof) Encode() []byte {
 		AddData(boolByte(p.SelfRevoked)).
 		AddData(p.CounterTxData).
 		AddData(boolByte(p.SwapFeeConfirmed)).
-		AddData(boolByte(p.RedemptionFeeConfirmed))
+		AddData(boolByte(p.RedemptionFeeConfirmed)).
+		AddData(encodeCoinIDs(p.DuplicateSwaps))
+}
+
+// encodeCoinIDs encodes the coin IDs as a single blob, or nil if there are
+// none.
+func encodeCoinIDs(coinIDs []order.CoinID) []byte {
+	if len(coinIDs) == 0 {
+		return nil
+	}
+	b := encode.BuildyBytes{0}
+	for _, coinID := range coinIDs {
+		b = b.AddData(coinID)
+	}
+	return b
+}
+
+// decodeCoinIDs decodes the coin IDs encoded with encodeCoinIDs.
+func decodeCoinIDs(b []byte) ([]order.CoinID, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+	_, pushes, err := encode.DecodeBlob(b)
+	if err != nil {
+		return nil, err
+	}
+	coinIDs := make([]order.CoinID, 0, len(pushes))
+	for _, p := range pushes {
+		coinIDs = append(coinIDs, p)
+	}
+	return coinIDs, nil
 }
 
 // DecodeMatchProof decodes the versioned blob to a *MatchProof.
@@ -675,7 +724,10 @@ func DecodeMatchProof(b []byte) (*MatchProof, uint8, error) {
 		return nil, 0, err
 	}
 	switch ver {
-	case 3: // MatchProofVer
+	case 4: // MatchProofVer
+		proof, err := decodeMatchProof_v4(pushes)
+		return proof, ver, err
+	case 3:
 		proof, err := decodeMatchProof_v3(pushes)
 		return proof, ver, err
 	case 2:
@@ -709,10 +761,20 @@ func decodeMatchProof_v2(pushes [][]byte) (*MatchProof, error) {
 }
 
 func decodeMatchProof_v3(pushes [][]byte) (*MatchProof, error) {
+	// Add the empty MatchProof DuplicateSwaps.
+	pushes = append(pushes, nil)
+	return decodeMatchProof_v4(pushes)
+}
+
+func decodeMatchProof_v4(pushes [][]byte) (*MatchProof, error) {
 	if len(pushes) != matchProofPushes {
 		return nil, fmt.Errorf("DecodeMatchProof: expected %d pushes, got %d",
 			matchProofPushes, len(pushes))
 	}
+	duplicateSwaps, err := decodeCoinIDs(pushes[24])
+	if err != nil {
+		return nil, fmt.Errorf("DecodeMatchProof: error decoding duplicate swaps: %w", err)
+	}
 	return &MatchProof{
 		ContractData:    pushes[0],
 		CounterContract: pushes[1],
@@ -740,6 +802,7 @@ func decodeMatchProof_v3(pushes [][]byte) (*MatchProof, error) {
 		SelfRevoked:            bytes.Equal(pushes[20], encode.ByteTrue),
 		SwapFeeConfirmed:       bytes.Equal(pushes[21], encode.ByteTrue),
 		RedemptionFeeConfirmed: bytes.Equal(pushes[22], encode.ByteTrue),
+		DuplicateSwaps:         duplicateSwaps,
 	}, nil
 }
 
@@ -1193,6 +1256,103 @@ func (n *Notification) Encode() []byte {
 		AddData([]byte(n.TopicID))
 }
 
+// OrderTemplate is a named set of order parameters that can be used to place
+// the same order repeatedly.
+type OrderTemplate struct {
+	Name    string `json:"name"`
+	Host    string `json:"host"`
+	Base    uint32 `json:"base"`
+	Quote   uint32 `json:"quote"`
+	IsLimit bool   `json:"isLimit"`
+	Sell    bool   `json:"sell"`
+	Qty     uint64 `json:"qty"`
+	// RateOffset is the fractional offset of a limit order's rate from the
+	// mid-gap rate at the time the order is placed, e.g. -0.01 for a rate 1%
+	// below the mid-gap rate.
+	RateOffset float64           `json:"rateOffset"`
+	TifNow     bool              `json:"tifnow"`
+	Options    map[string]string `json:"options,omitempty"`
+}
+
+// OrderSchedule places an order with the parameters of its OrderTemplate at a
+// fixed interval, e.g. for dollar-cost averaging. The template's Name is the
+// schedule's label.
+type OrderSchedule struct {
+	ID string `json:"id"`
+	OrderTemplate
+	Interval time.Duration `json:"interval"`
+	// Next is the time of the next placement, in unix milliseconds.
+	Next   uint64 `json:"next"`
+	Paused bool   `json:"paused"`
+	// Placed is the number of orders placed.
+	Placed uint32 `json:"placed"`
+	// Skipped is the number of placements that were skipped because of an
+	// insufficient balance or an error.
+	Skipped uint32 `json:"skipped"`
+}
+
+// IcebergOrder is a standing limit order of which only up to DisplayQty is on
+// the book at a time. The protocol has no native iceberg orders, so the order
+// is placed as a series of child limit orders. Only one child order is active
+// at a time, and the next one is placed when it is filled, for the lesser of
+// DisplayQty and the quantity that remains unfilled, so the total filled never
+// exceeds Qty.
+type IcebergOrder struct {
+	ID         string            `json:"id"`
+	Host       string            `json:"host"`
+	Base       uint32            `json:"base"`
+	Quote      uint32            `json:"quote"`
+	Sell       bool              `json:"sell"`
+	Rate       uint64            `json:"rate"`
+	Qty        uint64            `json:"qty"`
+	DisplayQty uint64            `json:"displayQty"`
+	Options    map[string]string `json:"options,omitempty"`
+	// Filled is the quantity filled by the child orders that are no longer
+	// active.
+	Filled uint64 `json:"filled"`
+	// Child is the ID of the active child order, if there is one.
+	Child dex.Bytes `json:"child,omitempty"`
+	// Children are the IDs of all of the child orders.
+	Children []dex.Bytes `json:"children"`
+	// Canceled is whether the user canceled the iceberg order.
+	Canceled bool `json:"canceled"`
+	// Done is whether the iceberg order is finished, i.e. no more child
+	// orders will be placed.
+	Done bool `json:"done"`
+}
+
+// DepositAddressPolicy specifies when a wallet's deposit address should be
+// replaced with a fresh one.
+type DepositAddressPolicy struct {
+	// Interval is the period at which a fresh deposit address is requested.
+	// Zero disables scheduled rotation.
+	Interval time.Duration `json:"interval"`
+	// OnDeposit requests a fresh deposit address after each incoming deposit
+	// to the current deposit address is detected.
+	OnDeposit bool `json:"onDeposit"`
+}
+
+// DepositAddress is a deposit address issued while a DepositAddressPolicy is
+// in effect, and the amount received to it.
+type DepositAddress struct {
+	Address string `json:"address"`
+	// Stamp is the time the address was issued, in milliseconds.
+	Stamp    uint64 `json:"stamp"`
+	Received uint64 `json:"received"`
+	Deposits int    `json:"deposits"`
+}
+
+// DepositAddressRecord is a wallet's DepositAddressPolicy and the deposit
+// addresses issued while it has been in effect.
+type DepositAddressRecord struct {
+	AssetID uint32 `json:"assetID"`
+	DepositAddressPolicy
+	Addresses []*DepositAddress `json:"addresses"`
+	// Credited are the IDs of the transactions that have been credited to
+	// the addresses.
+	Credited []string `json:"credited"`
+}
+
 type OrderFilterMarket struct {
 	Base  uint32
 	Quote uint32
@@ -366,12 +366,7 @@ func parseMarket(host string, mkt *core.Market) (*market, error) {
 }
 
 func (m *market) fmtRate(msgRate uint64) string {
-	r := calc.ConventionalRate(msgRate, m.bui, m.qui)
-	s := strconv.FormatFloat(r, 'f', 8, 64)
-	if strings.Contains(s, ".") {
-		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
-	}
-	return s
+	return dex.FormatMessageRate(msgRate, m.bui, m.qui)
 }
 func (m *market) fmtBase(atoms uint64) string {
 	return m.bui.FormatAtoms(atoms)
@@ -82,6 +82,11 @@ type OrderBook struct {
 	seq      uint64
 	marketID string
 
+	// updateMtx is locked for writing while a sequenced update is applied, and
+	// for reading by OrdersSeq, so that the orders and the sequence number of
+	// a snapshot are consistent.
+	updateMtx sync.RWMutex
+
 	noteQueueMtx sync.Mutex
 	noteQueue    []*cachedOrderNote
 
@@ -102,6 +107,14 @@ type OrderBook struct {
 
 	matchSummaryMtx sync.Mutex
 	matchesSummary  []*MatchSummary
+
+	// feeRanges are the last reported recommended fee rate ranges, which are
+	// nil if the server does not estimate them for the asset.
+	feeRangesMtx sync.RWMutex
+	feeRanges    struct {
+		base  *msgjson.FeeRateRange
+		quote *msgjson.FeeRateRange
+	}
 }
 
 // NewOrderBook creates a new order book.
@@ -127,6 +140,28 @@ func (ob *OrderBook) QuoteFeeRate() uint64 {
 	return atomic.LoadUint64(&ob.feeRates.quote)
 }
 
+// BaseFeeRange is the last reported base asset fee rate range, or nil if the
+// server does not report one.
+func (ob *OrderBook) BaseFeeRange() *msgjson.FeeRateRange {
+	ob.feeRangesMtx.RLock()
+	defer ob.feeRangesMtx.RUnlock()
+	return ob.feeRanges.base
+}
+
+// QuoteFeeRange is the last reported quote asset fee rate range, or nil if the
+// server does not report one.
+func (ob *OrderBook) QuoteFeeRange() *msgjson.FeeRateRange {
+	ob.feeRangesMtx.RLock()
+	defer ob.feeRangesMtx.RUnlock()
+	return ob.feeRanges.quote
+}
+
+func (ob *OrderBook) setFeeRanges(base, quote *msgjson.FeeRateRange) {
+	ob.feeRangesMtx.Lock()
+	ob.feeRanges.base, ob.feeRanges.quote = base, quote
+	ob.feeRangesMtx.Unlock()
+}
+
 // setSynced sets the synced state of the order book.
 func (ob *OrderBook) setSynced(value bool) {
 	ob.syncedMtx.Lock()
@@ -154,6 +189,20 @@ func (ob *OrderBook) setSeq(seq uint64) {
 	}
 }
 
+// OrdersSeq is like Orders, but also returns the sequence number of the most
+// recent book update applied. The sequence number changes whenever the book
+// changes, so it may be used for change detection. No update is applied
+// between reading the orders and the sequence number.
+func (ob *OrderBook) OrdersSeq() (buys, sells, epoch []*Order, seq uint64) {
+	ob.updateMtx.RLock()
+	defer ob.updateMtx.RUnlock()
+	buys, sells, epoch = ob.Orders()
+	ob.seqMtx.Lock()
+	seq = ob.seq
+	ob.seqMtx.Unlock()
+	return buys, sells, epoch, seq
+}
+
 // cacheOrderNote caches an order note.
 func (ob *OrderBook) cacheOrderNote(route string, entry any) error {
 	note := new(cachedOrderNote)
@@ -237,6 +286,9 @@ func (ob *OrderBook) Sync(snapshot *msgjson.OrderBook) error {
 // snapshot. This resets the sequence.
 // TODO: eliminate this and half of the mutexes!
 func (ob *OrderBook) Reset(snapshot *msgjson.OrderBook) error {
+	ob.updateMtx.Lock()
+	defer ob.updateMtx.Unlock()
+
 	// Don't use setSeq here, since this message is the seed and is not expected
 	// to be 1 more than the current seq value.
 	ob.seqMtx.Lock()
@@ -245,6 +297,7 @@ func (ob *OrderBook) Reset(snapshot *msgjson.OrderBook) error {
 
 	atomic.StoreUint64(&ob.feeRates.base, snapshot.BaseFeeRate)
 	atomic.StoreUint64(&ob.feeRates.quote, snapshot.QuoteFeeRate)
+	ob.setFeeRanges(snapshot.BaseFeeRange, snapshot.QuoteFeeRange)
 
 	ob.marketID = snapshot.MarketID
 
@@ -376,6 +429,8 @@ func (ob *OrderBook) book(note *msgjson.BookOrderNote, cached bool) error {
 
 // Book adds a new order to the order book.
 func (ob *OrderBook) Book(note *msgjson.BookOrderNote) error {
+	ob.updateMtx.Lock()
+	defer ob.updateMtx.Unlock()
 	return ob.book(note, false)
 }
 
@@ -420,6 +475,8 @@ func (ob *OrderBook) updateRemaining(note *msgjson.UpdateRemainingNote, cached b
 
 // UpdateRemaining updates the remaining quantity of a booked order.
 func (ob *OrderBook) UpdateRemaining(note *msgjson.UpdateRemainingNote) error {
+	ob.updateMtx.Lock()
+	defer ob.updateMtx.Unlock()
 	return ob.updateRemaining(note, false)
 }
 
@@ -429,6 +486,7 @@ func (ob *OrderBook) LogEpochReport(note *msgjson.EpochReportNote) error {
 	// TODO: update future candlestick charts.
 	atomic.StoreUint64(&ob.feeRates.base, note.BaseFeeRate)
 	atomic.StoreUint64(&ob.feeRates.quote, note.QuoteFeeRate)
+	ob.setFeeRanges(note.BaseFeeRange, note.QuoteFeeRange)
 	return nil
 }
 
@@ -473,6 +531,8 @@ func (ob *OrderBook) unbook(note *msgjson.UnbookOrderNote, cached bool) error {
 
 // Unbook removes an order from the order book.
 func (ob *OrderBook) Unbook(note *msgjson.UnbookOrderNote) error {
+	ob.updateMtx.Lock()
+	defer ob.updateMtx.Unlock()
 	return ob.unbook(note, false)
 }
 
@@ -573,6 +633,8 @@ func (ob *OrderBook) Orders() ([]*Order, []*Order, []*Order) {
 
 // Enqueue appends the provided order note to the corresponding epoch's queue.
 func (ob *OrderBook) Enqueue(note *msgjson.EpochOrderNote) error {
+	ob.updateMtx.Lock()
+	defer ob.updateMtx.Unlock()
 	ob.setSeq(note.Seq)
 	idx := note.Epoch
 	ob.epochMtx.Lock()
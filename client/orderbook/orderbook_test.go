@@ -424,6 +424,39 @@ func TestOrderBookBook(t *testing.T) {
 	}
 }
 
+func TestOrderBookOrdersSeq(t *testing.T) {
+	const n = 200
+	ob := makeOrderBook(0, "ob", nil, nil, true)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := uint64(1); i <= n; i++ {
+			var oid order.OrderID
+			oid[0], oid[1] = byte(i), byte(i>>8)
+			if err := ob.Book(makeBookOrderNote(i, "ob", oid, msgjson.BuyOrderNum, 1, i, i)); err != nil {
+				t.Errorf("Book error: %v", err)
+				return
+			}
+		}
+	}()
+	// Each update books one order, so the sequence number always matches the
+	// number of orders in the snapshot.
+	for {
+		buys, _, _, seq := ob.OrdersSeq()
+		if uint64(len(buys)) != seq {
+			t.Fatalf("snapshot with %d orders has sequence number %d", len(buys), seq)
+		}
+		select {
+		case <-done:
+			if _, _, _, seq = ob.OrdersSeq(); seq != n {
+				t.Fatalf("wrong final sequence number %d", seq)
+			}
+			return
+		default:
+		}
+	}
+}
+
 func TestOrderBookUpdateRemaining(t *testing.T) {
 	var qty uint64 = 10
 	var remaining uint64 = 5
@@ -64,6 +64,8 @@ const (
 	txHistoryRoute             = "txhistory"
 	walletTxRoute              = "wallettx"
 	withdrawBchSpvRoute        = "withdrawbchspv"
+	setPriceBandRoute          = "setpriceband"
+	setAutoRefundRoute         = "setautorefund"
 )
 
 const (
@@ -76,6 +78,8 @@ const (
 	walletStatusStr   = "%s wallet has been %s"
 	setVotePrefsStr   = "vote preferences set"
 	setVSPStr         = "vsp set to %s"
+	setPriceBandStr   = "price band set to %.2f%%"
+	setAutoRefundStr  = "auto-refund %s"
 )
 
 // createResponse creates a msgjson response payload.
@@ -141,6 +145,8 @@ var routes = map[string]func(s *RPCServer, params *RawParams) *msgjson.ResponseP
 	txHistoryRoute:             handleTxHistory,
 	walletTxRoute:              handleWalletTx,
 	withdrawBchSpvRoute:        handleWithdrawBchSpv,
+	setPriceBandRoute:          handleSetPriceBand,
+	setAutoRefundRoute:         handleSetAutoRefund,
 }
 
 // handleHelp handles requests for help. Returns general help for all commands
@@ -981,6 +987,38 @@ func handleSetVSP(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
 	return createResponse(setVSPRoute, fmt.Sprintf(setVSPStr, form.addr), nil)
 }
 
+func handleSetPriceBand(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	pct, err := parseSetPriceBandArgs(params)
+	if err != nil {
+		return usage(setPriceBandRoute, err)
+	}
+
+	if err := s.core.SetPriceBand(pct); err != nil {
+		resErr := msgjson.NewError(msgjson.RPCSetPriceBandError, "unable to set price band: %v", err)
+		return createResponse(setPriceBandRoute, nil, resErr)
+	}
+
+	return createResponse(setPriceBandRoute, fmt.Sprintf(setPriceBandStr, pct), nil)
+}
+
+func handleSetAutoRefund(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
+	enable, err := parseSetAutoRefundArgs(params)
+	if err != nil {
+		return usage(setAutoRefundRoute, err)
+	}
+
+	if err := s.core.SetAutoRefund(enable); err != nil {
+		resErr := msgjson.NewError(msgjson.RPCSetAutoRefundError, "unable to set auto-refund: %v", err)
+		return createResponse(setAutoRefundRoute, nil, resErr)
+	}
+
+	status := "disabled"
+	if enable {
+		status = "enabled"
+	}
+	return createResponse(setAutoRefundRoute, fmt.Sprintf(setAutoRefundStr, status), nil)
+}
+
 func handlePurchaseTickets(s *RPCServer, params *RawParams) *msgjson.ResponsePayload {
 	form, err := parsePurchaseTicketsArgs(params)
 	if err != nil {
@@ -1419,7 +1457,7 @@ var helpMsgs = map[string]helpMsg{
 	},
 	tradeRoute: {
 		pwArgsShort: `"appPass"`,
-		argsShort:   `"host" isLimit sell base quote qty rate immediate`,
+		argsShort:   `"host" isLimit sell base quote qty rate immediate options (overridePriceBand)`,
 		cmdSummary:  `Make an order to buy or sell an asset.`,
 		pwArgsLong: `Password Args:
     appPass (string): The Bison Wallet password.`,
@@ -1434,7 +1472,9 @@ var helpMsgs = map[string]helpMsg{
       156000 satoshi/DCR for the DCR(base)_BTC(quote).
     immediate (bool): Require immediate match. Do not book the order.
     options (string): A JSON-encoded string->string mapping of additional
-       trade options.`,
+       trade options.
+    overridePriceBand (bool): Optional. Place a limit order even if its rate is
+      outside of the configured price band. default: false`,
 		returns: `Returns:
     obj: The order details.
     {
@@ -1792,6 +1832,26 @@ an spv wallet and enables options to view and set the vsp.
   addr (string): The vsp's url.`,
 		returns: `Returns:
   string: The message "` + fmt.Sprintf(setVSPStr, "[vsp url]") + `"`,
+	},
+	setPriceBandRoute: {
+		argsShort: `pct`,
+		cmdSummary: `Set the maximum percentage that a limit order's rate may deviate from the
+    market's reference price. Orders outside of the band are rejected unless the
+    trade overrides the band.`,
+		argsLong: `Args:
+  pct (float): The price band in percent. 0 disables the check.`,
+		returns: `Returns:
+  string: The message "` + fmt.Sprintf(setPriceBandStr, 5.0) + `"`,
+	},
+	setAutoRefundRoute: {
+		argsShort: `enable`,
+		cmdSummary: `Enable or disable the automatic refund of swaps for which the counterparty
+    failed to act once the swap contract's lock time has expired. Auto-refund
+    is disabled until enabled, and the setting persists through restarts.`,
+		argsLong: `Args:
+  enable (bool): Whether to refund expired swaps automatically.`,
+		returns: `Returns:
+  string: The message "` + fmt.Sprintf(setAutoRefundStr, "enabled") + `"`,
 	},
 	purchaseTicketsRoute: {
 		pwArgsShort: `"appPass"`,
@@ -1276,6 +1276,83 @@ func TestHandleSetVSP(t *testing.T) {
 	}
 }
 
+func TestHandleSetPriceBand(t *testing.T) {
+	params := &RawParams{Args: []string{"5"}}
+	tests := []struct {
+		name            string
+		params          *RawParams
+		setPriceBandErr error
+		wantErrCode     int
+	}{{
+		name:        "ok",
+		params:      params,
+		wantErrCode: -1,
+	}, {
+		name:            "core.SetPriceBand error",
+		params:          params,
+		setPriceBandErr: errors.New("error"),
+		wantErrCode:     msgjson.RPCSetPriceBandError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{setPriceBandErr: test.setPriceBandErr}
+		r := &RPCServer{core: tc}
+		payload := handleSetPriceBand(r, test.params)
+		res := ""
+		if err := verifyResponse(payload, &res, test.wantErrCode); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+	}
+}
+
+func TestHandleSetAutoRefund(t *testing.T) {
+	tests := []struct {
+		name             string
+		params           *RawParams
+		setAutoRefundErr error
+		wantRes          string
+		wantErrCode      int
+	}{{
+		name:        "enable",
+		params:      &RawParams{Args: []string{"true"}},
+		wantRes:     "auto-refund enabled",
+		wantErrCode: -1,
+	}, {
+		name:        "disable",
+		params:      &RawParams{Args: []string{"false"}},
+		wantRes:     "auto-refund disabled",
+		wantErrCode: -1,
+	}, {
+		name:             "core.SetAutoRefund error",
+		params:           &RawParams{Args: []string{"true"}},
+		setAutoRefundErr: errors.New("error"),
+		wantErrCode:      msgjson.RPCSetAutoRefundError,
+	}, {
+		name:        "not a bool",
+		params:      &RawParams{Args: []string{"maybe"}},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}, {
+		name:        "bad params",
+		params:      &RawParams{},
+		wantErrCode: msgjson.RPCArgumentsError,
+	}}
+	for _, test := range tests {
+		tc := &TCore{setAutoRefundErr: test.setAutoRefundErr}
+		r := &RPCServer{core: tc}
+		payload := handleSetAutoRefund(r, test.params)
+		res := ""
+		if err := verifyResponse(payload, &res, test.wantErrCode); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if res != test.wantRes {
+			t.Fatalf("%s: wanted %q, got %q", test.name, test.wantRes, res)
+		}
+	}
+}
+
 func TestPurchaseTickets(t *testing.T) {
 	pw := encode.PassBytes("password123")
 	params := &RawParams{
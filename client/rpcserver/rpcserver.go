@@ -10,13 +10,16 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,6 +29,7 @@ import (
 	"decred.org/dcrdex/client/mm"
 	"decred.org/dcrdex/client/websocket"
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/dexnet"
 	"decred.org/dcrdex/dex/msgjson"
 	"github.com/decred/dcrd/certgen"
 	"github.com/go-chi/chi/v5"
@@ -83,6 +87,7 @@ type clientCore interface {
 	AddWalletPeer(assetID uint32, host string) error
 	RemoveWalletPeer(assetID uint32, host string) error
 	Notifications(int) (notes, pokes []*db.Notification, _ error)
+	NotificationFeed() *core.NoteFeed
 	MultiTrade(pw []byte, form *core.MultiTradeForm) ([]*core.Order, error)
 	TxHistory(assetID uint32, n int, refID *string, past bool) ([]*asset.WalletTransaction, error)
 	WalletTransaction(assetID uint32, txID string) (*asset.WalletTransaction, error)
@@ -90,6 +95,8 @@ type clientCore interface {
 	// These are core's ticket buying interface.
 	StakeStatus(assetID uint32) (*asset.TicketStakingStatus, error)
 	SetVSP(assetID uint32, addr string) error
+	SetPriceBand(pct float64) error
+	SetAutoRefund(enable bool) error
 	PurchaseTickets(assetID uint32, pw []byte, n int) error
 	SetVotingPreferences(assetID uint32, choices, tSpendPolicy, treasuryPolicy map[string]string) error
 	GenerateBCHRecoveryTransaction(appPW []byte, recipient string) ([]byte, error)
@@ -187,6 +194,98 @@ func (s *RPCServer) handleJSON(w http.ResponseWriter, r *http.Request) {
 	s.parseHTTPRequest(w, req)
 }
 
+// noteFilter selects the notifications sent by handleNotificationStream.
+type noteFilter struct {
+	types       map[string]bool // empty means all types
+	minSeverity db.Severity
+}
+
+// parseNoteFilter parses the "types" and "severity" query parameters of a
+// notifications stream request. types is a comma-separated list of
+// notification types, e.g. "order,match". severity is the minimum severity,
+// e.g. "warning".
+func parseNoteFilter(q url.Values) (*noteFilter, error) {
+	f := &noteFilter{types: make(map[string]bool)}
+	if types := q.Get("types"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				f.types[t] = true
+			}
+		}
+	}
+	if sev := q.Get("severity"); sev != "" {
+		found := false
+		for s := db.Ignorable; s <= db.ErrorLevel; s++ {
+			if s.String() == sev {
+				f.minSeverity, found = s, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown severity %q", sev)
+		}
+	}
+	return f, nil
+}
+
+func (f *noteFilter) match(n core.Notification) bool {
+	if len(f.types) > 0 && !f.types[n.Type()] {
+		return false
+	}
+	return n.Severity() >= f.minSeverity
+}
+
+// handleNotificationStream streams core notifications to the client as
+// newline-delimited JSON until the client disconnects or the server shuts
+// down. See parseNoteFilter for the supported query parameters.
+func (s *RPCServer) handleNotificationStream(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	filter, err := parseNoteFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The stream outlives the server's write timeout.
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Errorf("Unable to clear write deadline for notification stream: %v", err)
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	feed := s.core.NotificationFeed()
+	defer feed.ReturnFeed()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	log.Debugf("Notification stream started for %s", r.RemoteAddr)
+	defer log.Debugf("Notification stream ended for %s", r.RemoteAddr)
+
+	enc := json.NewEncoder(w) // Encode terminates each value with a newline
+	for {
+		select {
+		case n := <-feed.C:
+			if !filter.match(n) {
+				continue
+			}
+			if err := enc.Encode(n); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // Config holds variables needed to create a new RPC Server.
 type Config struct {
 	Core                        clientCore
@@ -194,6 +293,16 @@ type Config struct {
 	Addr, User, Pass, Cert, Key string
 	BWVersion                   *SemVersion
 	CertHosts                   []string
+	// TLSMinVersion is the minimum TLS version accepted by the server, either
+	// "1.2" or "1.3". Default is "1.2".
+	TLSMinVersion string
+	// TLSCipherSuites optionally restricts the TLS 1.2 cipher suites accepted
+	// by the server, using the crypto/tls suite names. Insecure suites are not
+	// allowed. TLS 1.3 suites are not configurable.
+	TLSCipherSuites []string
+	// ClientCA is an optional path to a PEM file of CA certificates. If set,
+	// clients must present a certificate signed by one of these CAs.
+	ClientCA string
 }
 
 // SetLogger sets the logger for the RPCServer package.
@@ -201,6 +310,30 @@ func SetLogger(logger dex.Logger) {
 	log = logger
 }
 
+// newTLSConfig creates the server's TLS configuration, rejecting insecure or
+// nonsensical settings.
+func newTLSConfig(cfg *Config, keypair tls.Certificate) (*tls.Config, error) {
+	tlsConfig, err := dexnet.TLSConfig(keypair, cfg.TLSMinVersion, cfg.TLSCipherSuites)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ClientCA != "" {
+		caPEM, err := os.ReadFile(cfg.ClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("error reading client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in client CA file %s", cfg.ClientCA)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return tlsConfig, nil
+}
+
 // New is the constructor for an RPCServer.
 func New(cfg *Config) (*RPCServer, error) {
 
@@ -226,9 +359,9 @@ func New(cfg *Config) (*RPCServer, error) {
 	}
 
 	// Prepare the TLS configuration.
-	tlsConfig := &tls.Config{
-		Certificates: []tls.Certificate{keypair},
-		MinVersion:   tls.VersionTLS12,
+	tlsConfig, err := newTLSConfig(cfg, keypair)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create an HTTP router.
@@ -298,6 +431,9 @@ func (s *RPCServer) Connect(ctx context.Context) (*sync.WaitGroup, error) {
 	s.mux.Get("/ws", func(w http.ResponseWriter, r *http.Request) {
 		s.wsServer.HandleConnect(ctx, w, r)
 	})
+	s.mux.Get("/notifications", func(w http.ResponseWriter, r *http.Request) {
+		s.handleNotificationStream(ctx, w, r)
+	})
 
 	s.wg.Add(1)
 	go func() {
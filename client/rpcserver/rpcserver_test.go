@@ -6,9 +6,11 @@
 package rpcserver
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -35,6 +37,7 @@ var (
 )
 
 type TCore struct {
+	noteFeed                 chan core.Notification
 	dexExchange              *core.Exchange
 	getDEXConfigErr          error
 	balanceErr               error
@@ -67,6 +70,8 @@ type TCore struct {
 	archivedRecords          int
 	deleteArchivedRecordsErr error
 	setVSPErr                error
+	setPriceBandErr          error
+	setAutoRefundErr         error
 	purchaseTicketsErr       error
 	stakeStatus              *asset.TicketStakingStatus
 	stakeStatusErr           error
@@ -174,6 +179,9 @@ func (c *TCore) AddWalletPeer(assetID uint32, address string) error {
 func (c *TCore) RemoveWalletPeer(assetID uint32, address string) error {
 	return nil
 }
+func (c *TCore) NotificationFeed() *core.NoteFeed {
+	return &core.NoteFeed{C: c.noteFeed}
+}
 func (c *TCore) Notifications(n int) (notes, pokes []*db.Notification, _ error) {
 	return nil, nil, nil
 }
@@ -183,6 +191,12 @@ func (c *TCore) MultiTrade(appPass []byte, form *core.MultiTradeForm) ([]*core.O
 func (c *TCore) SetVSP(assetID uint32, addr string) error {
 	return c.setVSPErr
 }
+func (c *TCore) SetPriceBand(pct float64) error {
+	return c.setPriceBandErr
+}
+func (c *TCore) SetAutoRefund(enable bool) error {
+	return c.setAutoRefundErr
+}
 func (c *TCore) PurchaseTickets(assetID uint32, pw []byte, n int) error {
 	return c.purchaseTicketsErr
 }
@@ -295,6 +309,215 @@ func TestConnectBindError(t *testing.T) {
 	}
 }
 
+func TestTLSConfig(t *testing.T) {
+	newServer := func(modCfg func(*Config)) (*RPCServer, error) {
+		t.Helper()
+		tempDir := t.TempDir()
+		cfg := &Config{
+			Core: &TCore{},
+			Addr: "127.0.0.1:0",
+			Pass: "abc",
+			Cert: tempDir + "/cert.cert",
+			Key:  tempDir + "/key.key",
+		}
+		modCfg(cfg)
+		return New(cfg)
+	}
+
+	badCfgs := []struct {
+		name   string
+		modCfg func(*Config)
+	}{{
+		name:   "insecure version",
+		modCfg: func(cfg *Config) { cfg.TLSMinVersion = "1.1" },
+	}, {
+		name:   "unknown version",
+		modCfg: func(cfg *Config) { cfg.TLSMinVersion = "2" },
+	}, {
+		name:   "insecure cipher suite",
+		modCfg: func(cfg *Config) { cfg.TLSCipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"} },
+	}, {
+		name:   "unknown cipher suite",
+		modCfg: func(cfg *Config) { cfg.TLSCipherSuites = []string{"TLS_NOPE"} },
+	}, {
+		name:   "TLS 1.3 cipher suite",
+		modCfg: func(cfg *Config) { cfg.TLSCipherSuites = []string{"TLS_AES_128_GCM_SHA256"} },
+	}, {
+		name: "cipher suites with TLS 1.3",
+		modCfg: func(cfg *Config) {
+			cfg.TLSMinVersion = "1.3"
+			cfg.TLSCipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"}
+		},
+	}, {
+		name:   "missing client CA file",
+		modCfg: func(cfg *Config) { cfg.ClientCA = cfg.Cert + ".nope" },
+	}}
+	for _, tt := range badCfgs {
+		if _, err := newServer(tt.modCfg); err == nil {
+			t.Fatalf("%s: no error", tt.name)
+		}
+	}
+
+	dial := func(s *RPCServer, tlsCfg *tls.Config) error {
+		t.Helper()
+		tlsCfg.InsecureSkipVerify = true
+		conn, err := tls.Dial("tcp", s.addr, tlsCfg)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return conn.Handshake()
+	}
+	start := func(s *RPCServer) func() {
+		t.Helper()
+		ctx, cancel := context.WithCancel(tCtx)
+		cm := dex.NewConnectionMaster(s)
+		if err := cm.Connect(ctx); err != nil {
+			cancel()
+			t.Fatalf("error starting RPCServer: %v", err)
+		}
+		return func() {
+			cancel()
+			cm.Disconnect()
+		}
+	}
+
+	// Minimum TLS 1.3.
+	s, err := newServer(func(cfg *Config) { cfg.TLSMinVersion = "1.3" })
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	stop := start(s)
+	if err := dial(s, &tls.Config{MaxVersion: tls.VersionTLS12}); err == nil {
+		t.Fatalf("TLS 1.2 connection not refused")
+	}
+	if err := dial(s, &tls.Config{MinVersion: tls.VersionTLS13}); err != nil {
+		t.Fatalf("TLS 1.3 connection error: %v", err)
+	}
+	stop()
+
+	// Restricted TLS 1.2 cipher suites.
+	const allowedSuite = tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384
+	s, err = newServer(func(cfg *Config) {
+		cfg.TLSCipherSuites = []string{tls.CipherSuiteName(allowedSuite)}
+	})
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	stop = start(s)
+	if err := dial(s, &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305},
+	}); err == nil {
+		t.Fatalf("connection with disallowed cipher suite not refused")
+	}
+	if err := dial(s, &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{allowedSuite},
+	}); err != nil {
+		t.Fatalf("connection with allowed cipher suite error: %v", err)
+	}
+	stop()
+
+	// Client certificate required.
+	s, err = newServer(func(cfg *Config) {
+		// Any valid PEM file will do. Use a generated cert.
+		caFile := t.TempDir() + "/ca.cert"
+		if err := genCertPair(caFile, caFile+".key", nil); err != nil {
+			t.Fatalf("genCertPair error: %v", err)
+		}
+		cfg.ClientCA = caFile
+	})
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	stop = start(s)
+	if err := dial(s, &tls.Config{MaxVersion: tls.VersionTLS12}); err == nil {
+		t.Fatalf("connection without client certificate not refused")
+	}
+	stop()
+}
+
+func TestNotificationStream(t *testing.T) {
+	s, shutdown := newTServer(t, true, "user", "pass")
+	defer shutdown()
+	noteFeed := make(chan core.Notification, 16)
+	s.core.(*TCore).noteFeed = noteFeed
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	get := func(query string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, "https://"+s.addr+"/notifications"+query, nil)
+		req.SetBasicAuth("user", "pass")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		return resp
+	}
+
+	// Bad severity.
+	resp := get("?severity=dire")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status %d for bad severity, got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	resp = get("?types=order,match&severity=warning")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("wrong status %d", resp.StatusCode)
+	}
+
+	newNote := func(noteType string, severity db.Severity) core.Notification {
+		n := db.NewNotification(noteType, "topic", "subject", "details", severity)
+		return &n
+	}
+	noteFeed <- newNote(core.NoteTypeBalance, db.ErrorLevel) // wrong type
+	noteFeed <- newNote(core.NoteTypeOrder, db.Success)      // low severity
+	noteFeed <- newNote(core.NoteTypeOrder, db.WarningLevel)
+	noteFeed <- newNote(core.NoteTypeConnEvent, db.ErrorLevel) // wrong type
+	noteFeed <- newNote(core.NoteTypeMatch, db.ErrorLevel)
+
+	lines := make(chan []byte)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+		close(lines)
+	}()
+	for _, exp := range []struct {
+		noteType string
+		severity db.Severity
+	}{
+		{core.NoteTypeOrder, db.WarningLevel},
+		{core.NoteTypeMatch, db.ErrorLevel},
+	} {
+		var line []byte
+		select {
+		case line = <-lines:
+		case <-time.After(time.Second):
+			t.Fatalf("no %s notification streamed", exp.noteType)
+		}
+		var n db.Notification
+		if err := json.Unmarshal(line, &n); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", string(line), err)
+		}
+		if n.Type() != exp.noteType || n.Severity() != exp.severity {
+			t.Fatalf("wrong notification streamed. wanted %s/%s, got %s/%s",
+				exp.noteType, exp.severity, n.Type(), n.Severity())
+		}
+	}
+	select {
+	case line := <-lines:
+		t.Fatalf("unexpected notification streamed: %s", string(line))
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
 type tResponseWriter struct {
 	b    []byte
 	code int
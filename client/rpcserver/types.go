@@ -557,7 +557,7 @@ func parsePostBondArgs(params *RawParams) (*core.PostBondForm, error) {
 }
 
 func parseTradeArgs(params *RawParams) (*tradeForm, error) {
-	if err := checkNArgs(params, []int{1}, []int{9}); err != nil {
+	if err := checkNArgs(params, []int{1}, []int{9, 10}); err != nil {
 		return nil, err
 	}
 	isLimit, err := checkBoolArg(params.Args[1], "isLimit")
@@ -592,6 +592,13 @@ func parseTradeArgs(params *RawParams) (*tradeForm, error) {
 	if err != nil {
 		return nil, err
 	}
+	var overrideBand bool
+	if len(params.Args) > 9 {
+		overrideBand, err = checkBoolArg(params.Args[9], "overridePriceBand")
+		if err != nil {
+			return nil, err
+		}
+	}
 	req := &tradeForm{
 		appPass: params.PWArgs[0],
 		srvForm: &core.TradeForm{
@@ -604,6 +611,8 @@ func parseTradeArgs(params *RawParams) (*tradeForm, error) {
 			Rate:    rate,
 			TifNow:  tifnow,
 			Options: options,
+
+			OverridePriceBand: overrideBand,
 		},
 	}
 	return req, nil
@@ -1030,6 +1039,24 @@ func parseSetVSPArgs(params *RawParams) (*setVSPForm, error) {
 	}, nil
 }
 
+func parseSetPriceBandArgs(params *RawParams) (float64, error) {
+	if err := checkNArgs(params, []int{0}, []int{1}); err != nil {
+		return 0, err
+	}
+	pct, err := strconv.ParseFloat(params.Args[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: cannot parse pct: %v", errArgs, err)
+	}
+	return pct, nil
+}
+
+func parseSetAutoRefundArgs(params *RawParams) (bool, error) {
+	if err := checkNArgs(params, []int{0}, []int{1}); err != nil {
+		return false, err
+	}
+	return checkBoolArg(params.Args[0], "enable")
+}
+
 func parsePurchaseTicketsArgs(params *RawParams) (*purchaseTicketsForm, error) {
 	if err := checkNArgs(params, []int{1}, []int{2}); err != nil {
 		return nil, err
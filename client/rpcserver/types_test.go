@@ -393,6 +393,19 @@ func TestTradeArgs(t *testing.T) {
 		name:    "options not map[string]string",
 		params:  paramsWith(8, "blue"),
 		wantErr: errArgs,
+	}, {
+		name: "override price band",
+		params: &RawParams{
+			PWArgs: goodParams.PWArgs,
+			Args:   append(append([]string{}, goodParams.Args...), "true"),
+		},
+	}, {
+		name: "override price band not bool",
+		params: &RawParams{
+			PWArgs: goodParams.PWArgs,
+			Args:   append(append([]string{}, goodParams.Args...), "blue"),
+		},
+		wantErr: errArgs,
 	}}
 	for _, test := range tests {
 		reg, err := parseTradeArgs(test.params)
@@ -442,6 +455,9 @@ func TestTradeArgs(t *testing.T) {
 		if wantOptions != test.params.Args[8] {
 			t.Fatalf("Options doesn't match")
 		}
+		if len(test.params.Args) > 9 && fmt.Sprint(reg.srvForm.OverridePriceBand) != test.params.Args[9] {
+			t.Fatalf("OverridePriceBand doesn't match")
+		}
 	}
 }
 
@@ -790,6 +806,45 @@ func TestParseSetVSPArgs(t *testing.T) {
 	}
 }
 
+func TestParseSetPriceBandArgs(t *testing.T) {
+	paramsWithArgs := func(args ...string) *RawParams {
+		return &RawParams{Args: args}
+	}
+	tests := []struct {
+		name    string
+		params  *RawParams
+		wantPct float64
+		wantErr error
+	}{{
+		name:    "ok",
+		params:  paramsWithArgs("2.5"),
+		wantPct: 2.5,
+	}, {
+		name:    "pct not a number",
+		params:  paramsWithArgs("abc"),
+		wantErr: errArgs,
+	}, {
+		name:    "no args",
+		params:  paramsWithArgs(),
+		wantErr: errArgs,
+	}}
+	for _, test := range tests {
+		pct, err := parseSetPriceBandArgs(test.params)
+		if test.wantErr != nil {
+			if errors.Is(err, test.wantErr) {
+				continue
+			}
+			t.Fatalf("%q: expected error", test.name)
+		}
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", test.name, err)
+		}
+		if pct != test.wantPct {
+			t.Fatalf("%q: wanted pct %f, got %f", test.name, test.wantPct, pct)
+		}
+	}
+}
+
 func TestPurchaseTicketsArgs(t *testing.T) {
 	pw := encode.PassBytes("password123")
 	pwArgs := []encode.PassBytes{pw}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"decred.org/dcrdex/client/asset"
@@ -88,15 +89,17 @@ func (s *WebServer) apiValidateAddress(w http.ResponseWriter, r *http.Request) {
 		s.writeAPIError(w, errors.New("missing asset ID"))
 		return
 	}
-	valid, err := s.core.ValidateAddress(form.Addr, *form.AssetID)
+	status, err := s.core.ValidateAddress(*form.AssetID, form.Addr)
 	if err != nil {
 		s.writeAPIError(w, err)
 		return
 	}
 	resp := struct {
-		OK bool `json:"ok"`
+		OK     bool               `json:"ok"`
+		Status core.AddressStatus `json:"status"`
 	}{
-		OK: valid,
+		OK:     status == core.AddressValid,
+		Status: status,
 	}
 	writeJSON(w, resp)
 }
@@ -597,6 +600,37 @@ func (s *WebServer) apiNewDepositAddress(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// apiLegacyDepositAddress gets the legacy format of a wallet's deposit
+// address.
+func (s *WebServer) apiLegacyDepositAddress(w http.ResponseWriter, r *http.Request) {
+	form := &struct {
+		AssetID *uint32 `json:"assetID"`
+		Address string  `json:"address"`
+	}{}
+	if !readPost(w, r, form) {
+		return
+	}
+	if form.AssetID == nil {
+		s.writeAPIError(w, errors.New("missing asset ID"))
+		return
+	}
+	assetID := *form.AssetID
+
+	addr, err := s.core.LegacyDepositAddress(assetID, form.Address)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("error getting legacy %s address: %w", unbip(assetID), err))
+		return
+	}
+
+	writeJSON(w, &struct {
+		OK      bool   `json:"ok"`
+		Address string `json:"address"`
+	}{
+		OK:      true,
+		Address: addr,
+	})
+}
+
 // apiConnectWallet is the handler for the '/connectwallet' API request.
 // Connects to a specified wallet, but does not unlock it.
 func (s *WebServer) apiConnectWallet(w http.ResponseWriter, r *http.Request) {
@@ -1687,6 +1721,142 @@ func (s *WebServer) apiRunLogs(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+const (
+	// defaultBookPageSize is the number of price levels per side in a page
+	// of the '/book' response when the pageSize parameter is not provided.
+	defaultBookPageSize = 50
+	// maxBookPageSize is the largest pageSize accepted by '/book'.
+	maxBookPageSize = 500
+)
+
+// apiBook is the handler for the '/book' API request. The synced order book
+// for the market is aggregated into price levels and served in depth-ordered
+// pages. The query parameters are:
+//
+//	host, base, quote: the market (required)
+//	depth: the maximum number of price levels per side (default all)
+//	page: the zero-based page index (default 0)
+//	pageSize: the number of price levels per side per page
+//
+// The book's sequence number is returned as the ETag, and a request with a
+// matching If-None-Match header receives a 304 Not Modified response.
+func (s *WebServer) apiBook(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	host := q.Get("host")
+	if host == "" {
+		s.writeAPIError(w, errors.New("no host specified"))
+		return
+	}
+	base, err := strconv.ParseUint(q.Get("base"), 10, 32)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("invalid base asset ID: %w", err))
+		return
+	}
+	quote, err := strconv.ParseUint(q.Get("quote"), 10, 32)
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("invalid quote asset ID: %w", err))
+		return
+	}
+	intParam := func(name string, def, min int) (int, error) {
+		v := q.Get(name)
+		if v == "" {
+			return def, nil
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil || i < min {
+			return 0, fmt.Errorf("invalid %s %q", name, v)
+		}
+		return i, nil
+	}
+	depth, err := intParam("depth", 0, 0)
+	if err != nil {
+		s.writeAPIError(w, err)
+		return
+	}
+	page, err := intParam("page", 0, 0)
+	if err != nil {
+		s.writeAPIError(w, err)
+		return
+	}
+	pageSize, err := intParam("pageSize", defaultBookPageSize, 1)
+	if err != nil {
+		s.writeAPIError(w, err)
+		return
+	}
+	if pageSize > maxBookPageSize {
+		pageSize = maxBookPageSize
+	}
+
+	book, err := s.core.Book(host, uint32(base), uint32(quote))
+	if err != nil {
+		s.writeAPIError(w, fmt.Errorf("error getting order book: %w", err))
+		return
+	}
+
+	etag := fmt.Sprintf(`"%d"`, book.Seq)
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	buys, sells := bookLevels(book.Buys, depth), bookLevels(book.Sells, depth)
+	pages := (max(len(buys), len(sells)) + pageSize - 1) / pageSize
+	writeJSON(w, &struct {
+		OK   bool      `json:"ok"`
+		Book *bookPage `json:"book"`
+	}{
+		OK: true,
+		Book: &bookPage{
+			Host:     host,
+			Base:     uint32(base),
+			Quote:    uint32(quote),
+			Seq:      book.Seq,
+			Page:     page,
+			PageSize: pageSize,
+			Pages:    max(pages, 1),
+			Buys:     pageBookLevels(buys, page, pageSize),
+			Sells:    pageBookLevels(sells, page, pageSize),
+		},
+	})
+}
+
+// bookLevels aggregates the orders of one side of the book, which must be
+// sorted best first, into at most depth price levels. A depth of zero returns
+// all levels.
+func bookLevels(ords []*core.MiniOrder, depth int) []*bookLevel {
+	levels := make([]*bookLevel, 0)
+	for _, ord := range ords {
+		if n := len(levels); n > 0 && levels[n-1].MsgRate == ord.MsgRate {
+			lvl := levels[n-1]
+			lvl.QtyAtomic += ord.QtyAtomic
+			lvl.Qty += ord.Qty
+			lvl.Orders++
+			continue
+		}
+		if depth > 0 && len(levels) == depth {
+			break
+		}
+		levels = append(levels, &bookLevel{
+			MsgRate:   ord.MsgRate,
+			Rate:      ord.Rate,
+			QtyAtomic: ord.QtyAtomic,
+			Qty:       ord.Qty,
+			Orders:    1,
+		})
+	}
+	return levels
+}
+
+// pageBookLevels returns the levels on the zero-based page.
+func pageBookLevels(levels []*bookLevel, page, pageSize int) []*bookLevel {
+	if page >= len(levels) || page*pageSize >= len(levels) {
+		return []*bookLevel{}
+	}
+	start := page * pageSize
+	return levels[start:min(start+pageSize, len(levels))]
+}
+
 func (s *WebServer) apiCEXBook(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Host    string `json:"host"`
@@ -1749,6 +1919,37 @@ func (s *WebServer) apiSetVSP(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, simpleAck())
 }
 
+// apiSetPriceBand sets the maximum deviation, in percent, of a limit order's
+// rate from the market's reference price.
+func (s *WebServer) apiSetPriceBand(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Pct float64 `json:"pct"`
+	}
+	if !readPost(w, r, &req) {
+		return
+	}
+	if err := s.core.SetPriceBand(req.Pct); err != nil {
+		s.writeAPIError(w, fmt.Errorf("error setting price band to %.2f%%: %w", req.Pct, err))
+		return
+	}
+	writeJSON(w, simpleAck())
+}
+
+// apiSetAutoRefund enables or disables the automatic refund of expired swaps.
+func (s *WebServer) apiSetAutoRefund(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Enable bool `json:"enable"`
+	}
+	if !readPost(w, r, &req) {
+		return
+	}
+	if err := s.core.SetAutoRefund(req.Enable); err != nil {
+		s.writeAPIError(w, fmt.Errorf("error setting auto-refund: %w", err))
+		return
+	}
+	writeJSON(w, simpleAck())
+}
+
 func (s *WebServer) apiPurchaseTickets(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		AssetID uint32           `json:"assetID"`
@@ -260,6 +260,7 @@ func (s *WebServer) handleSettings(w http.ResponseWriter, r *http.Request) {
 		FiatCurrency    string
 		Exchanges       map[string]*core.Exchange
 		IsInitialized   bool
+		AutoRefund      bool
 	}{
 		CommonArguments: *common,
 		KnownExchanges:  s.knownUnregisteredExchanges(xcs),
@@ -267,6 +268,7 @@ func (s *WebServer) handleSettings(w http.ResponseWriter, r *http.Request) {
 		FiatRateSources: s.core.FiatRateSources(),
 		Exchanges:       xcs,
 		IsInitialized:   s.core.IsInitialized(),
+		AutoRefund:      s.core.AutoRefund(),
 	}
 	s.sendTemplate(w, "settings", data)
 }
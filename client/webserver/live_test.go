@@ -613,8 +613,11 @@ func (c *TCore) UpdateBondOptions(form *core.BondOptionsForm) error {
 func (c *TCore) BondsFeeBuffer(assetID uint32) (uint64, error) {
 	return 222, nil
 }
-func (c *TCore) ValidateAddress(address string, assetID uint32) (bool, error) {
-	return len(address) > 10, nil
+func (c *TCore) ValidateAddress(assetID uint32, address string) (core.AddressStatus, error) {
+	if len(address) > 10 {
+		return core.AddressValid, nil
+	}
+	return core.AddressMalformed, nil
 }
 func (c *TCore) EstimateSendTxFee(addr string, assetID uint32, value uint64, subtract, maxWithdraw bool) (fee uint64, isValidAddress bool, err error) {
 	return uint64(float64(value) * 0.01), len(addr) > 10, nil
@@ -1140,6 +1143,26 @@ func nextToken() string {
 	return strconv.Itoa(int(atomic.AddUint32(&tokenCounter, 1)))
 }
 
+func (c *TCore) Book(dexAddr string, base, quote uint32) (*core.OrderBook, error) {
+	c.orderMtx.Lock()
+	buys := make([]*core.MiniOrder, 0, len(c.buys))
+	for _, ord := range c.buys {
+		buys = append(buys, ord)
+	}
+	sells := make([]*core.MiniOrder, 0, len(c.sells))
+	for _, ord := range c.sells {
+		sells = append(sells, ord)
+	}
+	c.orderMtx.Unlock()
+	sort.Slice(buys, func(i, j int) bool { return buys[i].Rate > buys[j].Rate })
+	sort.Slice(sells, func(i, j int) bool { return sells[i].Rate < sells[j].Rate })
+	return &core.OrderBook{
+		Buys:  buys,
+		Sells: sells,
+		Seq:   uint64(atomic.LoadUint32(&tokenCounter)),
+	}, nil
+}
+
 // Book randomizes an order book.
 func (c *TCore) book(dexAddr, mktID string) *core.OrderBook {
 	midGap, maxQty := getMarketStats(mktID)
@@ -1657,6 +1680,10 @@ func (c *TCore) NewDepositAddress(assetID uint32) (string, error) {
 	return ordertest.RandomAddress(), nil
 }
 
+func (c *TCore) LegacyDepositAddress(assetID uint32, addr string) (string, error) {
+	return ordertest.RandomAddress(), nil
+}
+
 func (c *TCore) SetWalletPassword(appPW []byte, assetID uint32, newPW []byte) error { return nil }
 
 func (c *TCore) User() *core.User {
@@ -1967,6 +1994,18 @@ func (c *TCore) SetVSP(assetID uint32, addr string) error {
 	return nil
 }
 
+func (c *TCore) SetPriceBand(pct float64) error {
+	return nil
+}
+
+func (c *TCore) SetAutoRefund(enable bool) error {
+	return nil
+}
+
+func (c *TCore) AutoRefund() bool {
+	return false
+}
+
 func (c *TCore) PurchaseTickets(assetID uint32, pw []byte, n int) error {
 	return nil
 }
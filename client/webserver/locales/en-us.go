@@ -159,6 +159,8 @@ var EnUS = map[string]*intl.Translation{
 	"reg_ssl_needed":            {T: "Looks like we don't have an SSL certificate for this DEX. Add the server's certificate to continue."},
 	"Dark Mode":                 {T: "Dark Mode"},
 	"Show pop-up notifications": {T: "Show pop-up notifications"},
+	"auto_refund":               {T: "Refund expired swaps automatically"},
+	"auto_refund_msg":           {T: "When the counterparty fails to act on a swap before its lock time expires, broadcast the refund transaction automatically. While disabled, expired swaps are refunded once this is enabled."},
 	"Account ID":                {T: "Account ID"},
 	"Export Account":            {T: "Export Account"},
 	"simultaneous_servers_msg":  {Version: 1, T: "<span class=brand></span> supports simultaneous use of any number of DEX servers."},
@@ -174,6 +176,7 @@ var EnUS = map[string]*intl.Translation{
 	"Receive":                   {T: "Receive"},
 	"Lock":                      {T: "Lock"},
 	"New Address":               {T: "New Address"},
+	"show_legacy_address":       {T: "Show legacy address format"},
 	"New Deposit Address":       {T: "New Deposit Address"}, // Unused
 	"Address":                   {T: "Address"},
 	"Amount":                    {T: "Amount"},
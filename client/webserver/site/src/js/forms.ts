@@ -31,7 +31,7 @@ import {
   PrepaidBondID
 } from './registry'
 import { XYRangeHandler } from './opts'
-import { CoinExplorers } from './coinexplorers'
+import { coinExplorer } from './coinexplorers'
 import { MM, setCexElements } from './mmutil'
 
 interface ConfigOptionInput extends HTMLInputElement {
@@ -1849,6 +1849,7 @@ export class LoginForm {
 }
 
 const traitNewAddresser = 1 << 1
+const traitLegacyAddresser = 1 << 19
 
 /*
  * DepositAddress displays a deposit address, a QR code, and a button to
@@ -1858,6 +1859,8 @@ export class DepositAddress {
   form: PageElement
   page: Record<string, PageElement>
   assetID: number
+  // address is the deposit address in the canonical format.
+  address: string
 
   constructor (form: PageElement) {
     this.form = form
@@ -1865,6 +1868,7 @@ export class DepositAddress {
     Doc.cleanTemplates(page.unifiedReceiverTmpl)
     Doc.bind(page.newDepAddrBttn, 'click', async () => { this.newDepositAddress() })
     Doc.bind(page.copyAddressBtn, 'click', () => { this.copyAddress() })
+    Doc.bind(page.showLegacyAddr, 'change', async () => { this.showLegacyAddress() })
   }
 
   /* Display a deposit address. */
@@ -1883,11 +1887,14 @@ export class DepositAddress {
       Doc.show(page.depositTokenMsgBox)
     }
     Doc.setVis((wallet.traits & traitNewAddresser) !== 0, page.newDepAddrBttnBox)
+    Doc.setVis((wallet.traits & traitLegacyAddresser) !== 0, page.legacyAddrBox)
     this.setAddress(wallet.address)
   }
 
   setAddress (addr: string) {
     const page = this.page
+    this.address = addr
+    page.showLegacyAddr.checked = false
     Doc.hide(page.unifiedReceivers)
     if (addr.startsWith('unified:')) {
       const receivers = JSON.parse(addr.substring('unified:'.length)) as Record<string, string>
@@ -1918,6 +1925,33 @@ export class DepositAddress {
     page.qrcode.src = `/generateqrcode?address=${addr}`
   }
 
+  /*
+   * Show the deposit address in the asset's legacy format, for services that
+   * do not accept the canonical format, or switch back to the canonical
+   * format.
+   */
+  async showLegacyAddress () {
+    const { page, assetID, form } = this
+    Doc.hide(page.depositErr)
+    if (!page.showLegacyAddr.checked) {
+      this.setCentralAddress(this.address)
+      return
+    }
+    const loaded = app().loading(form)
+    const res = await postJSON('/api/legacydepositaddress', {
+      assetID: assetID,
+      address: this.address
+    })
+    loaded()
+    if (!app().checkResponse(res)) {
+      page.depositErr.textContent = res.msg
+      Doc.show(page.depositErr)
+      page.showLegacyAddr.checked = false
+      return
+    }
+    this.setCentralAddress(res.address)
+  }
+
   /* Fetch a new address from the wallet. */
   async newDepositAddress () {
     const { page, assetID, form } = this
@@ -2128,10 +2162,8 @@ export class TokenApprovalForm {
       return
     }
     page.txid.innerText = res.txID
-    const assetExplorer = CoinExplorers[tokenAsset.id]
-    if (assetExplorer && assetExplorer[app().user.net]) {
-      page.txid.href = assetExplorer[app().user.net](res.txID)
-    }
+    const assetExplorer = coinExplorer(tokenAsset.id)
+    if (assetExplorer) page.txid.href = assetExplorer(res.txID)
     Doc.hide(page.submissionElements, page.balanceBox, page.addressBox)
     Doc.show(page.txMsg)
     if (success) success()
@@ -21,7 +21,7 @@ import BasePage from './basepage'
 import { setMarketElements, liveBotStatus } from './mmutil'
 import * as intl from './locales'
 import * as wallets from './wallets'
-import { CoinExplorers } from './coinexplorers'
+import { coinExplorer } from './coinexplorers'
 
 interface LogsPageParams {
   host: string
@@ -30,8 +30,6 @@ interface LogsPageParams {
   startTime: number
 }
 
-let net = 0
-
 const logsBatchSize = 50
 
 interface logFilters {
@@ -79,7 +77,6 @@ export default class MarketMakerLogsPage extends BasePage {
   constructor (main: HTMLElement, params: LogsPageParams) {
     super()
     const page = this.page = Doc.idDescendants(main)
-    net = app().user.net
     Doc.cleanTemplates(page.eventTableRowTmpl, page.dexOrderTxRowTmpl, page.performanceTableRowTmpl)
     Doc.bind(this.page.backButton, 'click', () => { app().loadPage(this.liveBot ? 'mm' : 'mmarchives') })
     Doc.bind(this.page.filterButton, 'click', () => { this.applyFilters() })
@@ -425,10 +422,8 @@ export default class MarketMakerLogsPage extends BasePage {
         console.error('unexpected tx type in dex order event', tx.type)
         continue
       }
-      const assetExplorer = CoinExplorers[asset.id]
-      if (assetExplorer && assetExplorer[net]) {
-        tmpl.explorerLink.href = assetExplorer[net](tx.id)
-      }
+      const assetExplorer = coinExplorer(asset.id)
+      if (assetExplorer) tmpl.explorerLink.href = assetExplorer(tx.id)
       tmpl.amt.textContent = `${Doc.formatCoinValue(tx.amount, asset.unitInfo)} ${asset.unitInfo.conventional.unit.toLowerCase()}`
       tmpl.fees.textContent = `${Doc.formatCoinValue(tx.fees, asset.unitInfo)} ${asset.unitInfo.conventional.unit.toLowerCase()}`
       page.dexOrderTxsTableBody.appendChild(row)
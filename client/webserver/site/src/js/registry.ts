@@ -205,6 +205,10 @@ export interface SupportedAsset {
   token?: Token
   unitInfo: UnitInfo
   walletCreationPending: boolean
+  display?: Record<string, AssetDisplay>
+  explorerTxURL?: string
+  explorerAddrURL?: string
+  explorerOutURL?: string
 }
 
 export interface Token {
@@ -257,6 +261,12 @@ export interface WalletState {
   syncStatus: SyncStatus
   approved: Record<number, ApprovalStatus>
   feeState?: FeeState
+  display?: Record<string, AssetDisplay>
+}
+
+export interface AssetDisplay {
+  symbol?: string
+  aliases?: string[]
 }
 
 export interface WalletInfo {
@@ -409,6 +419,14 @@ export interface WalletSyncNote extends CoreNote {
   assetID: number
   syncStatus: SyncStatus
   syncProgress: number
+  status: WalletSyncStatus
+}
+
+export interface WalletSyncStatus {
+  synced: boolean
+  height: number
+  targetHeight: number
+  progress: number
 }
 
 export type WalletStateNote = WalletConfigNote
@@ -81,6 +81,13 @@ export default class SettingsPage extends BasePage {
       })
     })
 
+    Doc.bind(page.autoRefund, 'change', async () => {
+      const res = await postJSON('/api/setautorefund', { enable: page.autoRefund.checked || false })
+      if (!app().checkResponse(res)) {
+        page.autoRefund.checked = !page.autoRefund.checked
+      }
+    })
+
     // Asset selection
     this.regAssetForm = new forms.FeeAssetSelectionForm(page.regAssetForm, async (assetID: number, tier: number) => {
       if (assetID === PrepaidBondID) {
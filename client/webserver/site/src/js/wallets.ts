@@ -43,7 +43,7 @@ import {
   WalletTransaction,
   FeeState
 } from './registry'
-import { CoinExplorers } from './coinexplorers'
+import { coinExplorer } from './coinexplorers'
 
 interface DecredTicketTipUpdate {
   ticketPrice: number
@@ -212,8 +212,6 @@ interface reconfigSettings {
   elevateProviders?: boolean
 }
 
-let net = 0
-
 export default class WalletsPage extends BasePage {
   body: HTMLElement
   data?: WalletsPageData
@@ -250,7 +248,6 @@ export default class WalletsPage extends BasePage {
     this.data = data
     const page = this.page = Doc.idDescendants(body)
     this.stampers = []
-    net = app().user.net
 
     const setStamp = () => {
       for (const span of this.stampers) {
@@ -605,10 +602,8 @@ export default class WalletsPage extends BasePage {
       return
     }
 
-    const assetExplorer = CoinExplorers[this.selectedAssetID]
-    if (assetExplorer && assetExplorer[net]) {
-      page.unapproveTokenTxID.href = assetExplorer[net](res.txID)
-    }
+    const assetExplorer = coinExplorer(this.selectedAssetID)
+    if (assetExplorer) page.unapproveTokenTxID.href = assetExplorer(res.txID)
     page.unapproveTokenTxID.textContent = res.txID
     Doc.hide(page.unapproveTokenSubmissionElements, page.unapproveTokenErr)
     Doc.show(page.unapproveTokenTxMsg)
@@ -1286,7 +1281,7 @@ export default class WalletsPage extends BasePage {
   displayTicketPage (pageNumber: number, pageOfTickets: Ticket[]) {
     const { page, selectedAssetID: assetID } = this
     const ui = app().unitInfo(assetID)
-    const coinLink = CoinExplorers[assetID][app().user.net]
+    const coinLink = coinExplorer(assetID)
     Doc.empty(page.ticketHistoryRows)
     page.ticketHistoryPage.textContent = String(pageNumber)
     for (const { tx, status } of pageOfTickets) {
@@ -1299,7 +1294,7 @@ export default class WalletsPage extends BasePage {
       tmpl.status.textContent = intl.prep(ticketStatusTranslationKeys[status])
       tmpl.hashStart.textContent = tx.hash.slice(0, 6)
       tmpl.hashEnd.textContent = tx.hash.slice(-6)
-      tmpl.detailsLinkUrl.setAttribute('href', coinLink(tx.hash))
+      if (coinLink) tmpl.detailsLinkUrl.setAttribute('href', coinLink(tx.hash))
     }
   }
 
@@ -1359,7 +1354,7 @@ export default class WalletsPage extends BasePage {
     const { page, stakeStatus, selectedAssetID: assetID } = this
     const ui = app().unitInfo(assetID)
     Doc.hide(page.votingFormErr)
-    const coinLink = CoinExplorers[assetID][app().user.net]
+    const coinLink = coinExplorer(assetID)
     const upperCase = (s: string) => s.charAt(0).toUpperCase() + s.slice(1)
 
     const setVotes = async (req: any) => {
@@ -1424,7 +1419,7 @@ export default class WalletsPage extends BasePage {
       if (tspend.value > 0) tmpl.value.textContent = Doc.formatFourSigFigs(tspend.value / ui.conventional.conversionFactor)
       else Doc.hide(tmpl.value)
       tmpl.hash.textContent = tspend.hash
-      tmpl.explorerLink.setAttribute('href', coinLink(tspend.hash))
+      if (coinLink) tmpl.explorerLink.setAttribute('href', coinLink(tspend.hash))
     }
 
     const setTKeyPolicy = async (key: string, policy: string) => {
@@ -1500,7 +1495,7 @@ export default class WalletsPage extends BasePage {
     const totalLocked = bal.locked + bal.contractlocked + bal.bondlocked
     const totalBalance = bal.available + totalLocked + bal.immature
     page.balance.textContent = Doc.formatCoinValue(totalBalance, ui)
-    page.balanceUnit.textContent = ui.conventional.unit
+    page.balanceUnit.textContent = displayTicker(asset)
     const rate = app().fiatRatesMap[assetID]
     if (rate) {
       Doc.show(page.fiatBalanceBox)
@@ -1551,6 +1546,7 @@ export default class WalletsPage extends BasePage {
     if (bal.bondlocked > 0) addSubBalance(intl.prep(intl.ID_BONDED), bal.bondlocked, intl.prep(intl.ID_LOCKED_BOND_BAL_MSG))
     if (bal.bondReserves > 0) addSubBalance(intl.prep(intl.ID_BOND_RESERVES), bal.bondReserves, intl.prep(intl.ID_BOND_RESERVES_MSG))
     if (bal?.other?.Staked !== undefined) addSubBalance('Staked', bal.other.Staked.amt)
+    if (bal?.other?.SwapFeeReserve !== undefined) addSubBalance('Swap Fee Reserves', bal.other.SwapFeeReserve.amt)
     setRowClasses()
 
     if (bal.immature) addPrimaryBalance(intl.prep(intl.ID_IMMATURE_TITLE), bal.immature, intl.prep(intl.ID_IMMATURE_BAL_MSG))
@@ -1721,10 +1717,8 @@ export default class WalletsPage extends BasePage {
     const page = this.page
 
     // Block explorer
-    const assetExplorer = CoinExplorers[this.selectedAssetID]
-    if (assetExplorer && assetExplorer[net]) {
-      page.txViewBlockExplorer.href = assetExplorer[net](tx.id)
-    }
+    const assetExplorer = coinExplorer(this.selectedAssetID)
+    if (assetExplorer) page.txViewBlockExplorer.href = assetExplorer(tx.id)
 
     // Tx type
     let txType = txTypeString(tx.type)
@@ -2143,7 +2137,8 @@ export default class WalletsPage extends BasePage {
   async showSendForm (assetID: number) {
     const page = this.page
     const box = page.sendForm
-    const { wallet, unitInfo: ui, symbol, token } = app().assets[assetID]
+    const asset = app().assets[assetID]
+    const { wallet, unitInfo: ui, symbol, token } = asset
     Doc.hide(page.toggleSubtract)
     page.subtractCheckBox.checked = false
 
@@ -2160,7 +2155,9 @@ export default class WalletsPage extends BasePage {
     Doc.showFiatValue(page.sendValue, 0, xcRate, ui)
     page.walletBal.textContent = Doc.formatFullPrecision(wallet.balance.available, ui)
     page.sendLogo.src = Doc.logoPath(symbol)
-    page.sendName.textContent = ui.conventional.unit
+    // The server-configured display symbol distinguishes tokens that share a
+    // ticker across chains.
+    page.sendName.textContent = displayTicker(asset)
     if (token) {
       const parentAsset = app().assets[token.parentID]
       page.sendTokenParentLogo.src = Doc.logoPath(parentAsset.symbol)
@@ -2518,3 +2515,20 @@ function trimStringWithEllipsis (str: string, maxLen: number): string {
   if (str.length <= maxLen) return str
   return `${str.substring(0, maxLen / 2)}...${str.substring(str.length - maxLen / 2)}`
 }
+
+/*
+ * displayTicker is the asset's server-configured display symbol if every
+ * server that configures one agrees on it, otherwise its conventional unit.
+ * Display symbols are per-server, and the wallets page is not specific to
+ * any server.
+ */
+function displayTicker (asset: SupportedAsset): string {
+  const unit = asset.unitInfo.conventional.unit
+  let ticker = ''
+  for (const { symbol } of Object.values(asset.display || {})) {
+    if (!symbol) continue
+    if (ticker && ticker !== symbol) return unit
+    ticker = symbol
+  }
+  return ticker || unit
+}
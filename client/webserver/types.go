@@ -143,3 +143,28 @@ type deleteRecordsForm struct {
 	SaveOrdersToFile  bool  `json:"saveOrdersToFile"`
 	SaveMatchesToFile bool  `json:"saveMatchesToFile"`
 }
+
+// bookLevel is the aggregate of all booked orders at a single rate.
+type bookLevel struct {
+	MsgRate   uint64  `json:"msgRate"`
+	Rate      float64 `json:"rate"`
+	QtyAtomic uint64  `json:"qtyAtomic"`
+	Qty       float64 `json:"qty"`
+	Orders    int     `json:"orders"`
+}
+
+// bookPage is a single page of a depth-ordered order book snapshot. Both sides
+// are ordered best rate first, and page N of each side holds levels
+// [N*PageSize, (N+1)*PageSize). Seq identifies the state of the book the page
+// was taken from, and is also returned as the response's ETag.
+type bookPage struct {
+	Host     string       `json:"host"`
+	Base     uint32       `json:"base"`
+	Quote    uint32       `json:"quote"`
+	Seq      uint64       `json:"seq"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"pageSize"`
+	Pages    int          `json:"pages"`
+	Buys     []*bookLevel `json:"buys"`
+	Sells    []*bookLevel `json:"sells"`
+}
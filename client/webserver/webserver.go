@@ -22,6 +22,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -36,6 +37,7 @@ import (
 	"decred.org/dcrdex/client/webserver/locales"
 	"decred.org/dcrdex/client/websocket"
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/dexnet"
 	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/dex/encrypt"
 	"github.com/decred/dcrd/certgen"
@@ -115,9 +117,11 @@ type clientCore interface {
 	ChangeAppPass([]byte, []byte) error
 	ResetAppPass(newPass []byte, seed string) error
 	NewDepositAddress(assetID uint32) (string, error)
+	LegacyDepositAddress(assetID uint32, addr string) (string, error)
 	AutoWalletConfig(assetID uint32, walletType string) (map[string]string, error)
 	User() *core.User
 	GetDEXConfig(dexAddr string, certI any) (*core.Exchange, error)
+	Book(dex string, base, quote uint32) (*core.OrderBook, error)
 	AddDEX(appPW []byte, dexAddr string, certI any) error
 	DiscoverAccount(dexAddr string, pass []byte, certI any) (*core.Exchange, bool, error)
 	SupportedAssets() map[uint32]*core.SupportedAsset
@@ -148,7 +152,7 @@ type clientCore interface {
 	ToggleRateSourceStatus(src string, disable bool) error
 	FiatRateSources() map[string]bool
 	EstimateSendTxFee(address string, assetID uint32, value uint64, subtract, maxWithdraw bool) (fee uint64, isValidAddress bool, err error)
-	ValidateAddress(address string, assetID uint32) (bool, error)
+	ValidateAddress(assetID uint32, address string) (core.AddressStatus, error)
 	DeleteArchivedRecordsWithBackup(olderThan *time.Time, saveMatchesToFile, saveOrdersToFile bool) (string, int, error)
 	WalletPeers(assetID uint32) ([]*asset.WalletPeer, error)
 	AddWalletPeer(assetID uint32, addr string) error
@@ -159,6 +163,9 @@ type clientCore interface {
 	ApproveTokenFee(assetID uint32, version uint32, approval bool) (uint64, error)
 	StakeStatus(assetID uint32) (*asset.TicketStakingStatus, error)
 	SetVSP(assetID uint32, addr string) error
+	SetPriceBand(pct float64) error
+	SetAutoRefund(enable bool) error
+	AutoRefund() bool
 	PurchaseTickets(assetID uint32, pw []byte, n int) error
 	SetVotingPreferences(assetID uint32, choices, tSpendPolicy, treasuryPolicy map[string]string) error
 	ListVSPs(assetID uint32) ([]*asset.VotingServiceProvider, error)
@@ -240,6 +247,101 @@ type Config struct {
 	// and execution of html templates on each request.
 	NoEmbed  bool
 	HttpProf bool
+	// TLSMinVersion is the minimum TLS version accepted by the HTTPS server,
+	// either "1.2" or "1.3". Default is "1.2".
+	TLSMinVersion string
+	// TLSCipherSuites optionally restricts the TLS 1.2 cipher suites accepted
+	// by the HTTPS server, using the crypto/tls suite names. Insecure suites
+	// are not allowed. TLS 1.3 suites are not configurable.
+	TLSCipherSuites []string
+	// ALPN is the list of application protocols offered in the TLS handshake,
+	// in order of preference. Only "h2" and "http/1.1" are supported. Default
+	// is both, which enables HTTP/2.
+	ALPN []string
+	// SNICerts are additional certificates for multi-host deployments. A
+	// client that requests one of their hosts via SNI is served the matching
+	// certificate. All other clients are served the CertFile certificate.
+	SNICerts []*SNICert
+}
+
+// SNICert is a certificate that is selected by the host name requested by the
+// client via SNI. Unlike CertFile and KeyFile, these files are not generated.
+type SNICert struct {
+	// Host is the server name, e.g. dex.example.com. A leading wildcard
+	// label, as in *.example.com, matches any single label.
+	Host     string
+	CertFile string
+	KeyFile  string
+}
+
+const (
+	http2Proto  = "h2"
+	http11Proto = "http/1.1"
+)
+
+// newTLSConfig creates the HTTPS server's TLS configuration, rejecting
+// insecure or conflicting settings.
+func newTLSConfig(cfg *Config, keyPair tls.Certificate) (*tls.Config, error) {
+	tlsConfig, err := dexnet.TLSConfig(keyPair, cfg.TLSMinVersion, cfg.TLSCipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.ServerName = cfg.Addr
+	tlsConfig.NextProtos = []string{http2Proto, http11Proto}
+
+	if len(cfg.ALPN) > 0 {
+		tlsConfig.NextProtos = make([]string, 0, len(cfg.ALPN))
+		for _, proto := range cfg.ALPN {
+			if proto != http2Proto && proto != http11Proto {
+				return nil, fmt.Errorf("unsupported ALPN protocol %q", proto)
+			}
+			if slices.Contains(tlsConfig.NextProtos, proto) {
+				return nil, fmt.Errorf("duplicate ALPN protocol %q", proto)
+			}
+			tlsConfig.NextProtos = append(tlsConfig.NextProtos, proto)
+		}
+	}
+
+	// RFC 7540 section 9.2.2 requires one of these suites for HTTP/2 over TLS
+	// 1.2, and net/http will refuse to serve without it.
+	if len(tlsConfig.CipherSuites) > 0 && slices.Contains(tlsConfig.NextProtos, http2Proto) &&
+		!slices.Contains(tlsConfig.CipherSuites, tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256) &&
+		!slices.Contains(tlsConfig.CipherSuites, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256) {
+		return nil, errors.New("HTTP/2 requires the TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256 " +
+			"or TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 cipher suite")
+	}
+
+	if len(cfg.SNICerts) > 0 {
+		sniCerts := make(map[string]*tls.Certificate, len(cfg.SNICerts))
+		for _, sc := range cfg.SNICerts {
+			host := strings.ToLower(sc.Host)
+			if host == "" {
+				return nil, errors.New("no host specified for SNI certificate")
+			}
+			if _, found := sniCerts[host]; found {
+				return nil, fmt.Errorf("duplicate SNI certificate host %s", host)
+			}
+			cert, err := tls.LoadX509KeyPair(sc.CertFile, sc.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("error loading SNI certificate for %s: %w", host, err)
+			}
+			sniCerts[host] = &cert
+		}
+		tlsConfig.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			name := strings.ToLower(hello.ServerName)
+			if cert, found := sniCerts[name]; found {
+				return cert, nil
+			}
+			if _, domain, found := strings.Cut(name, "."); found {
+				if cert, found := sniCerts["*."+domain]; found {
+					return cert, nil
+				}
+			}
+			return nil, nil // use Certificates
+		}
+	}
+
+	return tlsConfig, nil
 }
 
 type valStamp struct {
@@ -357,13 +459,16 @@ func New(cfg *Config) (*WebServer, error) {
 			"or override the warning about a self-signed certificate. "+
 			"Delete both files to regenerate them on next startup.",
 			cfg.CertFile, cfg.KeyFile)
-		httpServer.TLSConfig = &tls.Config{
-			ServerName:   cfg.Addr,
-			Certificates: []tls.Certificate{keyPair},
-			MinVersion:   tls.VersionTLS12,
+		httpServer.TLSConfig, err = newTLSConfig(cfg, keyPair)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(httpServer.TLSConfig.NextProtos, http2Proto) {
+			// Disable HTTP/2.
+			httpServer.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler), 0)
 		}
-		// Uncomment to disable HTTP/2:
-		// httpServer.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler), 0)
+	} else if cfg.TLSMinVersion != "" || len(cfg.TLSCipherSuites) > 0 || len(cfg.ALPN) > 0 || len(cfg.SNICerts) > 0 {
+		return nil, errors.New("TLS options are set, but HTTPS is not enabled")
 	}
 
 	lang := cfg.Core.Language()
@@ -506,6 +611,7 @@ func New(cfg *Config) (*WebServer, error) {
 		r.Group(func(apiAuth chi.Router) {
 			apiAuth.Use(s.rejectUnauthed)
 			apiAuth.Get("/notes", s.apiNotes)
+			apiAuth.Get("/book", s.apiBook)
 			apiAuth.Post("/defaultwalletcfg", s.apiDefaultWalletCfg)
 			apiAuth.Post("/postbond", s.apiPostBond)
 			apiAuth.Post("/updatebondoptions", s.apiUpdateBondOptions)
@@ -513,6 +619,7 @@ func New(cfg *Config) (*WebServer, error) {
 			apiAuth.Post("/newwallet", s.apiNewWallet)
 			apiAuth.Post("/openwallet", s.apiOpenWallet)
 			apiAuth.Post("/depositaddress", s.apiNewDepositAddress)
+			apiAuth.Post("/legacydepositaddress", s.apiLegacyDepositAddress)
 			apiAuth.Post("/closewallet", s.apiCloseWallet)
 			apiAuth.Post("/connectwallet", s.apiConnectWallet)
 			apiAuth.Post("/rescanwallet", s.apiRescanWallet)
@@ -559,6 +666,8 @@ func New(cfg *Config) (*WebServer, error) {
 
 			apiAuth.Post("/stakestatus", s.apiStakeStatus)
 			apiAuth.Post("/setvsp", s.apiSetVSP)
+			apiAuth.Post("/setpriceband", s.apiSetPriceBand)
+			apiAuth.Post("/setautorefund", s.apiSetAutoRefund)
 			apiAuth.Post("/purchasetickets", s.apiPurchaseTickets)
 			apiAuth.Post("/setvotes", s.apiSetVotingPreferences)
 			apiAuth.Post("/listvsps", s.apiListVSPs)
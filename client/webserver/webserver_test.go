@@ -5,6 +5,7 @@ package webserver
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -13,7 +14,9 @@ import (
 	"math/rand"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -90,6 +93,8 @@ type TCore struct {
 	tradeErr         error
 	notes            []*db.Notification
 	notesErr         error
+	book             *core.OrderBook
+	bookErr          error
 }
 
 func (c *TCore) Network() dex.Network                         { return dex.Mainnet }
@@ -136,6 +141,9 @@ func (c *TCore) SyncBook(dex string, base, quote uint32) (*orderbook.OrderBook,
 	return nil, c.syncFeed, c.syncErr
 }
 func (c *TCore) Book(dex string, base, quote uint32) (*core.OrderBook, error) {
+	if c.book != nil || c.bookErr != nil {
+		return c.book, c.bookErr
+	}
 	return &core.OrderBook{}, nil
 }
 func (c *TCore) AssetBalance(assetID uint32) (*core.WalletBalance, error) { return nil, c.balanceErr }
@@ -173,6 +181,7 @@ func (c *TCore) ChangeAppPass(appPW, newAppPW []byte) error
 func (c *TCore) ResetAppPass(newAppPW []byte, seed string) error                    { return nil }
 func (c *TCore) SetWalletPassword(appPW []byte, assetID uint32, newPW []byte) error { return nil }
 func (c *TCore) NewDepositAddress(assetID uint32) (string, error)                   { return "", nil }
+func (c *TCore) LegacyDepositAddress(assetID uint32, addr string) (string, error)   { return "", nil }
 func (c *TCore) AutoWalletConfig(assetID uint32, walletType string) (map[string]string, error) {
 	return nil, nil
 }
@@ -183,8 +192,11 @@ func (c *TCore) SupportedAssets() map[uint32]*core.SupportedAsset {
 func (c *TCore) Send(pw []byte, assetID uint32, value uint64, address string, subtract bool) (asset.Coin, error) {
 	return &tCoin{id: []byte{0xde, 0xc7, 0xed}}, c.sendErr
 }
-func (c *TCore) ValidateAddress(address string, assetID uint32) (bool, error) {
-	return c.validAddr, nil
+func (c *TCore) ValidateAddress(assetID uint32, address string) (core.AddressStatus, error) {
+	if c.validAddr {
+		return core.AddressValid, nil
+	}
+	return core.AddressMalformed, nil
 }
 func (c *TCore) EstimateSendTxFee(addr string, assetID uint32, value uint64, subtract, maxWithdraw bool) (fee uint64, isValidAddress bool, err error) {
 	return c.estFee, true, c.estFeeErr
@@ -307,6 +319,18 @@ func (c *TCore) SetVSP(assetID uint32, addr string) error {
 	return nil
 }
 
+func (c *TCore) SetPriceBand(pct float64) error {
+	return nil
+}
+
+func (c *TCore) SetAutoRefund(enable bool) error {
+	return nil
+}
+
+func (c *TCore) AutoRefund() bool {
+	return false
+}
+
 func (c *TCore) PurchaseTickets(assetID uint32, appPW []byte, n int) error {
 	return nil
 }
@@ -485,6 +509,183 @@ func TestNew_siteError(t *testing.T) {
 	}
 }
 
+func TestTLSConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	certFile, keyFile := filepath.Join(tempDir, "web.cert"), filepath.Join(tempDir, "web.key")
+	altCertFile, altKeyFile := filepath.Join(tempDir, "alt.cert"), filepath.Join(tempDir, "alt.key")
+	log = tLogger // set by New, but genCertPair is used first
+	if err := genCertPair(altCertFile, altKeyFile, []string{"alt.example.com"}); err != nil {
+		t.Fatalf("genCertPair error: %v", err)
+	}
+	altCert, err := tls.LoadX509KeyPair(altCertFile, altKeyFile)
+	if err != nil {
+		t.Fatalf("error loading alt cert: %v", err)
+	}
+
+	newServer := func(modCfg func(*Config)) (*WebServer, error) {
+		t.Helper()
+		cfg := &Config{
+			Core:     &TCore{},
+			Addr:     "127.0.0.1:0",
+			Logger:   tLogger,
+			CertFile: certFile,
+			KeyFile:  keyFile,
+		}
+		modCfg(cfg)
+		return New(cfg)
+	}
+
+	badCfgs := []struct {
+		name   string
+		modCfg func(*Config)
+	}{{
+		name:   "insecure version",
+		modCfg: func(cfg *Config) { cfg.TLSMinVersion = "1.1" },
+	}, {
+		name:   "unknown version",
+		modCfg: func(cfg *Config) { cfg.TLSMinVersion = "2" },
+	}, {
+		name:   "insecure cipher suite",
+		modCfg: func(cfg *Config) { cfg.TLSCipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"} },
+	}, {
+		name:   "unknown cipher suite",
+		modCfg: func(cfg *Config) { cfg.TLSCipherSuites = []string{"TLS_NOPE"} },
+	}, {
+		name: "cipher suites with TLS 1.3",
+		modCfg: func(cfg *Config) {
+			cfg.TLSMinVersion = "1.3"
+			cfg.TLSCipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}
+		},
+	}, {
+		name:   "HTTP/2 without required cipher suite",
+		modCfg: func(cfg *Config) { cfg.TLSCipherSuites = []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"} },
+	}, {
+		name:   "unknown ALPN protocol",
+		modCfg: func(cfg *Config) { cfg.ALPN = []string{"h3"} },
+	}, {
+		name:   "duplicate ALPN protocol",
+		modCfg: func(cfg *Config) { cfg.ALPN = []string{"h2", "h2"} },
+	}, {
+		name:   "missing SNI cert",
+		modCfg: func(cfg *Config) { cfg.SNICerts = []*SNICert{{"a.com", certFile + ".nope", keyFile}} },
+	}, {
+		name: "duplicate SNI host",
+		modCfg: func(cfg *Config) {
+			cfg.SNICerts = []*SNICert{{"a.com", altCertFile, altKeyFile}, {"A.com", altCertFile, altKeyFile}}
+		},
+	}, {
+		name: "TLS options without HTTPS",
+		modCfg: func(cfg *Config) {
+			cfg.CertFile, cfg.KeyFile = "", ""
+			cfg.TLSMinVersion = "1.3"
+		},
+	}}
+	for _, tt := range badCfgs {
+		if _, err := newServer(tt.modCfg); err == nil {
+			t.Fatalf("%s: no error", tt.name)
+		}
+	}
+
+	start := func(s *WebServer) func() {
+		t.Helper()
+		ctx, cancel := context.WithCancel(tCtx)
+		cm := dex.NewConnectionMaster(s)
+		if err := cm.Connect(ctx); err != nil {
+			cancel()
+			t.Fatalf("error starting WebServer: %v", err)
+		}
+		return func() {
+			cancel()
+			cm.Disconnect()
+		}
+	}
+	dial := func(s *WebServer, tlsCfg *tls.Config) (*tls.Conn, error) {
+		t.Helper()
+		tlsCfg.InsecureSkipVerify = true
+		conn, err := tls.Dial("tcp", s.addr, tlsCfg)
+		if err != nil {
+			return nil, err
+		}
+		return conn, conn.Handshake()
+	}
+	protoMajor := func(s *WebServer) int {
+		t.Helper()
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			ForceAttemptHTTP2: true,
+		}}
+		defer client.CloseIdleConnections()
+		resp, err := client.Get("https://" + s.addr + "/")
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		resp.Body.Close()
+		return resp.ProtoMajor
+	}
+
+	// The default baseline negotiates HTTP/2.
+	s, err := newServer(func(*Config) {})
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	stop := start(s)
+	if v := protoMajor(s); v != 2 {
+		t.Fatalf("expected HTTP/2, got HTTP/%d", v)
+	}
+	stop()
+
+	// HTTP/2 can be disabled.
+	s, err = newServer(func(cfg *Config) { cfg.ALPN = []string{"http/1.1"} })
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	stop = start(s)
+	if v := protoMajor(s); v != 1 {
+		t.Fatalf("expected HTTP/1.1, got HTTP/%d", v)
+	}
+	stop()
+
+	// Minimum TLS 1.3 refuses TLS 1.2.
+	s, err = newServer(func(cfg *Config) { cfg.TLSMinVersion = "1.3" })
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	stop = start(s)
+	if _, err := dial(s, &tls.Config{MaxVersion: tls.VersionTLS12}); err == nil {
+		t.Fatalf("TLS 1.2 connection not refused")
+	}
+	conn, err := dial(s, &tls.Config{MinVersion: tls.VersionTLS13})
+	if err != nil {
+		t.Fatalf("TLS 1.3 connection error: %v", err)
+	}
+	conn.Close()
+	stop()
+
+	// SNI certificate selection.
+	s, err = newServer(func(cfg *Config) {
+		cfg.SNICerts = []*SNICert{{"*.alt.example.com", altCertFile, altKeyFile}}
+	})
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	stop = start(s)
+	defer stop()
+	for _, tt := range []struct {
+		serverName string
+		alt        bool
+	}{{"www.alt.example.com", true}, {"other.example.com", false}, {"", false}} {
+		conn, err := dial(s, &tls.Config{ServerName: tt.serverName})
+		if err != nil {
+			t.Fatalf("%q: connection error: %v", tt.serverName, err)
+		}
+		isAlt := bytes.Equal(conn.ConnectionState().PeerCertificates[0].Raw, altCert.Certificate[0])
+		conn.Close()
+		if isAlt != tt.alt {
+			t.Fatalf("%q: wrong certificate served. alt = %t", tt.serverName, isAlt)
+		}
+	}
+}
+
 func TestConnectStart(t *testing.T) {
 	_, _, shutdown := newTServer(t, true)
 	defer shutdown()
@@ -902,11 +1103,11 @@ func TestAPIValidateAddress(t *testing.T) {
 		AssetID: &testID,
 	}
 
-	want := `{"ok":true}`
+	want := `{"ok":true,"status":"valid"}`
 	tCore.validAddr = true
 	ensureResponse(t, s.apiValidateAddress, want, reader, writer, body, nil)
 
-	want = `{"ok":false}`
+	want = `{"ok":false,"status":"malformed"}`
 	tCore.validAddr = false
 	ensureResponse(t, s.apiValidateAddress, want, reader, writer, body, nil)
 }
@@ -1076,3 +1277,129 @@ func Test_prepareAddr(t *testing.T) {
 		}
 	}
 }
+
+func TestAPIBook(t *testing.T) {
+	s, tCore, shutdown := newTServer(t, false)
+	defer shutdown()
+
+	// 7 buy levels, with two orders at the best rate, and 4 sell levels.
+	miniOrd := func(sell bool, rate uint64) *core.MiniOrder {
+		return &core.MiniOrder{Sell: sell, MsgRate: rate, QtyAtomic: 1e8, Qty: 1}
+	}
+	book := &core.OrderBook{Seq: 5}
+	book.Buys = append(book.Buys, miniOrd(false, 100))
+	for rate := uint64(100); rate > 93; rate-- {
+		book.Buys = append(book.Buys, miniOrd(false, rate))
+	}
+	for rate := uint64(101); rate < 105; rate++ {
+		book.Sells = append(book.Sells, miniOrd(true, rate))
+	}
+	tCore.book = book
+
+	type bookResp struct {
+		OK   bool      `json:"ok"`
+		Msg  string    `json:"msg"`
+		Book *bookPage `json:"book"`
+	}
+	get := func(query, etag string) (*httptest.ResponseRecorder, *bookResp) {
+		t.Helper()
+		r := httptest.NewRequest(http.MethodGet, "/book?host=abc.com&base=42&quote=0"+query, nil)
+		if etag != "" {
+			r.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		s.apiBook(w, r)
+		if w.Code == http.StatusNotModified {
+			return w, nil
+		}
+		resp := new(bookResp)
+		if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+			t.Fatalf("error decoding response: %v", err)
+		}
+		return w, resp
+	}
+
+	// Walk the pages and make sure every level is seen exactly once, in
+	// order.
+	var buyRates, sellRates []uint64
+	for page := 0; ; page++ {
+		_, resp := get(fmt.Sprintf("&pageSize=3&page=%d", page), "")
+		if !resp.OK {
+			t.Fatalf("error response: %s", resp.Msg)
+		}
+		if resp.Book.Pages != 3 {
+			t.Fatalf("expected 3 pages, got %d", resp.Book.Pages)
+		}
+		if page == resp.Book.Pages {
+			if len(resp.Book.Buys) != 0 || len(resp.Book.Sells) != 0 {
+				t.Fatalf("levels returned beyond the last page")
+			}
+			break
+		}
+		for _, lvl := range resp.Book.Buys {
+			buyRates = append(buyRates, lvl.MsgRate)
+		}
+		for _, lvl := range resp.Book.Sells {
+			sellRates = append(sellRates, lvl.MsgRate)
+		}
+		if page == 0 && (resp.Book.Buys[0].Orders != 2 || resp.Book.Buys[0].QtyAtomic != 2e8) {
+			t.Fatalf("best buy level not aggregated: %+v", resp.Book.Buys[0])
+		}
+	}
+	checkRates := func(rates []uint64, first uint64, n int, sell bool) {
+		t.Helper()
+		if len(rates) != n {
+			t.Fatalf("expected %d levels, got %d", n, len(rates))
+		}
+		for i, rate := range rates {
+			exp := first - uint64(i)
+			if sell {
+				exp = first + uint64(i)
+			}
+			if rate != exp {
+				t.Fatalf("level %d: expected rate %d, got %d", i, exp, rate)
+			}
+		}
+	}
+	checkRates(buyRates, 100, 7, false)
+	checkRates(sellRates, 101, 4, true)
+
+	// Depth limits the levels per side.
+	_, resp := get("&depth=2", "")
+	if len(resp.Book.Buys) != 2 || len(resp.Book.Sells) != 2 || resp.Book.Pages != 1 {
+		t.Fatalf("depth not respected. %d buys, %d sells, %d pages",
+			len(resp.Book.Buys), len(resp.Book.Sells), resp.Book.Pages)
+	}
+
+	// The ETag is the sequence, and an unchanged book is not resent.
+	w, resp := get("", "")
+	etag := w.Header().Get("ETag")
+	if etag != `"5"` || resp.Book.Seq != 5 {
+		t.Fatalf("wrong ETag %s / seq %d", etag, resp.Book.Seq)
+	}
+	if w, _ = get("", etag); w.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for an unchanged book, got %d", w.Code)
+	}
+
+	// An update changes the sequence.
+	book.Seq++
+	book.Sells = book.Sells[1:]
+	w, resp = get("", etag)
+	if w.Code != http.StatusOK || resp.Book.Seq != 6 || w.Header().Get("ETag") == etag {
+		t.Fatalf("updated book not detected")
+	}
+	if len(resp.Book.Sells) != 3 {
+		t.Fatalf("expected 3 sell levels after update, got %d", len(resp.Book.Sells))
+	}
+
+	// Bad parameters.
+	for _, query := range []string{"&depth=-1", "&page=x", "&pageSize=0"} {
+		if _, resp = get(query, ""); resp.OK {
+			t.Fatalf("no error for query %q", query)
+		}
+	}
+	tCore.bookErr = tErr
+	if _, resp = get("", ""); resp.OK {
+		t.Fatalf("no error for Book error")
+	}
+}
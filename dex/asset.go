@@ -137,6 +137,69 @@ type Asset struct {
 	MaxFeeRate uint64   `json:"maxFeeRate"`
 	SwapConf   uint32   `json:"swapConf"`
 	UnitInfo   UnitInfo `json:"unitInfo"`
+	// DustLimit is the smallest swap contract value that the server will
+	// accept at MaxFeeRate, since smaller contracts can't be redeemed
+	// economically. The limit is proportional to the fee rate.
+	DustLimit uint64 `json:"dustLimit,omitempty"`
+	// Versions are all of the versions the server accepts, if it accepts
+	// versions other than Version.
+	Versions []uint32 `json:"versions,omitempty"`
+	// DisplaySymbol is the operator-configured symbol with which the asset
+	// is displayed, e.g. to distinguish tokens that share a ticker across
+	// chains. If empty, the conventional unit is used.
+	DisplaySymbol string `json:"displaySymbol,omitempty"`
+	// Aliases are additional operator-configured names for the asset, e.g.
+	// the name of a wrapped variant.
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// DisplayTicker is the symbol with which the asset is displayed, which is the
+// DisplaySymbol if set, and the conventional unit otherwise.
+func (a *Asset) DisplayTicker() string {
+	if a.DisplaySymbol != "" {
+		return a.DisplaySymbol
+	}
+	return a.UnitInfo.Conventional.Unit
+}
+
+// ValidateDisplaySymbols checks that the configured display symbols and
+// aliases of the assets are unambiguous. A display symbol or alias may not
+// match, ignoring case, the display ticker, alias, or symbol of any other
+// asset. Assets without a configured display symbol may share a ticker.
+func ValidateDisplaySymbols(assets []*Asset) error {
+	owners := make(map[string]map[uint32]bool)
+	addName := func(name string, assetID uint32) {
+		name = strings.ToLower(name)
+		if owners[name] == nil {
+			owners[name] = make(map[uint32]bool, 1)
+		}
+		owners[name][assetID] = true
+	}
+	for _, a := range assets {
+		addName(a.DisplayTicker(), a.ID)
+		addName(a.Symbol, a.ID)
+		for _, alias := range a.Aliases {
+			addName(alias, a.ID)
+		}
+	}
+	for _, a := range assets {
+		names := a.Aliases
+		if a.DisplaySymbol != "" {
+			names = append([]string{a.DisplaySymbol}, names...)
+		}
+		for _, name := range names {
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("empty display symbol or alias for %s", a.Symbol)
+			}
+			for assetID := range owners[strings.ToLower(name)] {
+				if assetID != a.ID {
+					return fmt.Errorf("display symbol or alias %q of %s is ambiguous with asset %d",
+						name, a.Symbol, assetID)
+				}
+			}
+		}
+	}
+	return nil
 }
 
 // Denomination is a unit and its conversion factor.
@@ -72,3 +72,95 @@ func Test_IntDivUp(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDisplaySymbols(t *testing.T) {
+	asset := func(id uint32, symbol, unit, display string, aliases ...string) *Asset {
+		return &Asset{
+			ID:            id,
+			Symbol:        symbol,
+			UnitInfo:      UnitInfo{Conventional: Denomination{Unit: unit}},
+			DisplaySymbol: display,
+			Aliases:       aliases,
+		}
+	}
+	const btcID, ethID, usdcEthID, usdcPolygonID = 0, 60, 60001, 966001
+
+	tests := []struct {
+		name    string
+		assets  []*Asset
+		wantErr bool
+	}{
+		{
+			name: "shared tickers without display symbols",
+			assets: []*Asset{
+				asset(usdcEthID, "usdc.eth", "USDC", ""),
+				asset(usdcPolygonID, "usdc.polygon", "USDC", ""),
+			},
+		},
+		{
+			name: "distinguished by display symbol",
+			assets: []*Asset{
+				asset(usdcEthID, "usdc.eth", "USDC", ""),
+				asset(usdcPolygonID, "usdc.polygon", "USDC", "USDC.p", "pUSDC"),
+			},
+		},
+		{
+			name: "own ticker as alias",
+			assets: []*Asset{
+				asset(ethID, "eth", "ETH", "", "eth", "Ether"),
+			},
+		},
+		{
+			name: "display symbol matches another ticker",
+			assets: []*Asset{
+				asset(btcID, "btc", "BTC", ""),
+				asset(ethID, "eth", "ETH", "btc"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "alias matches another display symbol",
+			assets: []*Asset{
+				asset(usdcEthID, "usdc.eth", "USDC", "USDC.e"),
+				asset(usdcPolygonID, "usdc.polygon", "USDC", "USDC.p", "usdc.E"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "alias matches another symbol",
+			assets: []*Asset{
+				asset(usdcEthID, "usdc.eth", "USDC", ""),
+				asset(usdcPolygonID, "usdc.polygon", "USDC", "USDC.p", "USDC.ETH"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "shared alias",
+			assets: []*Asset{
+				asset(btcID, "btc", "BTC", "", "bitcoin"),
+				asset(ethID, "eth", "ETH", "", "Bitcoin"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty alias",
+			assets: []*Asset{
+				asset(btcID, "btc", "BTC", "", " "),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		err := ValidateDisplaySymbols(tt.assets)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("%s: wanted error = %t, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+
+	if ticker := asset(usdcPolygonID, "usdc.polygon", "USDC", "USDC.p").DisplayTicker(); ticker != "USDC.p" {
+		t.Fatalf("wrong display ticker %q", ticker)
+	}
+	if ticker := asset(usdcEthID, "usdc.eth", "USDC", "").DisplayTicker(); ticker != "USDC" {
+		t.Fatalf("wrong default display ticker %q", ticker)
+	}
+}
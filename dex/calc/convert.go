@@ -11,7 +11,7 @@ import (
 
 // RateEncodingFactor is used when encoding an exchange rate as an integer.
 // https://github.com/decred/dcrdex/blob/master/spec/comm.mediawiki#Rate_Encoding
-const RateEncodingFactor = 1e8
+const RateEncodingFactor = dex.RateEncodingFactor
 
 var (
 	bigRateConversionFactor = big.NewInt(RateEncodingFactor)
@@ -0,0 +1,151 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dex
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+const (
+	// ErrUnknownCoinIDAsset is returned by DecodeCoinID when no decoder is
+	// registered for the asset.
+	ErrUnknownCoinIDAsset = ErrorKind("no coin ID decoder registered for asset")
+	// ErrInvalidCoinID is returned by DecodeCoinID when the coin ID cannot be
+	// decoded.
+	ErrInvalidCoinID = ErrorKind("invalid coin ID")
+)
+
+// CoinIDKind is the kind of thing identified by a coin ID.
+type CoinIDKind uint8
+
+const (
+	// CoinIDOutpoint is a transaction output, identified by the transaction
+	// hash and output index, as used by UTXO-based assets.
+	CoinIDOutpoint CoinIDKind = iota + 1
+	// CoinIDTx is a transaction, identified by its hash, as used by
+	// account-based assets.
+	CoinIDTx
+	// CoinIDAccount is an account address.
+	CoinIDAccount
+)
+
+// String returns a description of the CoinIDKind.
+func (k CoinIDKind) String() string {
+	switch k {
+	case CoinIDOutpoint:
+		return "outpoint"
+	case CoinIDTx:
+		return "tx"
+	case CoinIDAccount:
+		return "account"
+	}
+	return "unknown"
+}
+
+// DecodedCoinID is the typed components of a decoded coin ID.
+type DecodedCoinID struct {
+	Kind CoinIDKind
+	// TxHash is the transaction hash as it is conventionally displayed for
+	// the asset. TxHash is set for the CoinIDOutpoint and CoinIDTx kinds.
+	TxHash string
+	// Vout is the output index for the CoinIDOutpoint kind.
+	Vout uint32
+	// Address is the account address for the CoinIDAccount kind.
+	Address string
+}
+
+// String is a human-readable representation of the coin ID.
+func (c *DecodedCoinID) String() string {
+	switch c.Kind {
+	case CoinIDOutpoint:
+		return fmt.Sprintf("%s:%d", c.TxHash, c.Vout)
+	case CoinIDAccount:
+		return c.Address
+	}
+	return c.TxHash
+}
+
+// CoinIDDecoder decodes the coin IDs of an asset.
+type CoinIDDecoder func(coinID []byte) (*DecodedCoinID, error)
+
+var (
+	coinIDDecodersMtx sync.RWMutex
+	coinIDDecoders    = make(map[uint32]CoinIDDecoder)
+)
+
+// RegisterCoinIDDecoder registers the coin ID decoder for an asset. Asset
+// packages should register their decoder in an init function. The decoder
+// should return an error for malformed coin IDs.
+func RegisterCoinIDDecoder(assetID uint32, decoder CoinIDDecoder) {
+	coinIDDecodersMtx.Lock()
+	defer coinIDDecodersMtx.Unlock()
+	if _, exists := coinIDDecoders[assetID]; exists {
+		panic(fmt.Sprintf("coin ID decoder already registered for asset %d", assetID))
+	}
+	coinIDDecoders[assetID] = decoder
+}
+
+// DecodeCoinID decodes the coin ID with the decoder registered for the asset,
+// returning a human-readable representation of the coin ID and its typed
+// components. Errors wrap ErrUnknownCoinIDAsset or ErrInvalidCoinID.
+func DecodeCoinID(assetID uint32, coinID []byte) (string, *DecodedCoinID, error) {
+	coinIDDecodersMtx.RLock()
+	decoder, found := coinIDDecoders[assetID]
+	coinIDDecodersMtx.RUnlock()
+	if !found {
+		return "", nil, NewError(ErrUnknownCoinIDAsset, assetIDString(assetID))
+	}
+	decoded, err := decoder(coinID)
+	if err != nil {
+		return "", nil, NewError(ErrInvalidCoinID, fmt.Sprintf("%s coin ID %x: %v", assetIDString(assetID), coinID, err))
+	}
+	return decoded.String(), decoded, nil
+}
+
+// DecodeOutpointCoinID decodes a coin ID that is the 32-byte transaction hash
+// followed by the big-endian 4-byte output index, as used by Bitcoin, Decred,
+// and their clones. The transaction hash is displayed byte-reversed, as is
+// conventional for these assets.
+func DecodeOutpointCoinID(coinID []byte) (*DecodedCoinID, error) {
+	const txHashSize = 32
+	if len(coinID) != txHashSize+4 {
+		return nil, fmt.Errorf("coin ID wrong length. expected %d, got %d", txHashSize+4, len(coinID))
+	}
+	return &DecodedCoinID{
+		Kind:   CoinIDOutpoint,
+		TxHash: reversedHex(coinID[:txHashSize]),
+		Vout:   binary.BigEndian.Uint32(coinID[txHashSize:]),
+	}, nil
+}
+
+// DecodeReversedTxCoinID decodes a coin ID that is a 32-byte transaction hash,
+// displayed byte-reversed.
+func DecodeReversedTxCoinID(coinID []byte) (*DecodedCoinID, error) {
+	const txHashSize = 32
+	if len(coinID) != txHashSize {
+		return nil, fmt.Errorf("coin ID wrong length. expected %d, got %d", txHashSize, len(coinID))
+	}
+	return &DecodedCoinID{
+		Kind:   CoinIDTx,
+		TxHash: reversedHex(coinID),
+	}, nil
+}
+
+func reversedHex(b []byte) string {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return hex.EncodeToString(r)
+}
+
+func assetIDString(assetID uint32) string {
+	if symbol := BipIDSymbol(assetID); symbol != "" {
+		return symbol
+	}
+	return fmt.Sprintf("asset %d", assetID)
+}
@@ -0,0 +1,54 @@
+package dex
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestDecodeCoinID(t *testing.T) {
+	const assetID = 0 // btc
+	RegisterCoinIDDecoder(assetID, DecodeOutpointCoinID)
+	defer func() {
+		coinIDDecodersMtx.Lock()
+		delete(coinIDDecoders, assetID)
+		coinIDDecodersMtx.Unlock()
+	}()
+
+	// The first output of the Bitcoin genesis block coinbase transaction.
+	const txid = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	coinID, _ := hex.DecodeString("3ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a" + "00000000")
+	coinID[35] = 1 // vout 1
+
+	s, decoded, err := DecodeCoinID(assetID, coinID)
+	if err != nil {
+		t.Fatalf("DecodeCoinID error: %v", err)
+	}
+	if s != txid+":1" {
+		t.Fatalf("wrong coin ID string %q", s)
+	}
+	if decoded.Kind != CoinIDOutpoint || decoded.TxHash != txid || decoded.Vout != 1 || decoded.Address != "" {
+		t.Fatalf("wrong decoded coin ID %+v", decoded)
+	}
+
+	// Malformed coin IDs.
+	for _, badID := range [][]byte{nil, coinID[:32], append(coinID, 0)} {
+		if _, _, err = DecodeCoinID(assetID, badID); !errors.Is(err, ErrInvalidCoinID) {
+			t.Fatalf("expected ErrInvalidCoinID for %x, got %v", badID, err)
+		}
+	}
+
+	// Unknown asset.
+	if _, _, err = DecodeCoinID(1e9, coinID); !errors.Is(err, ErrUnknownCoinIDAsset) {
+		t.Fatalf("expected ErrUnknownCoinIDAsset, got %v", err)
+	}
+
+	// A transaction hash coin ID.
+	decoded, err = DecodeReversedTxCoinID(coinID[:32])
+	if err != nil {
+		t.Fatalf("DecodeReversedTxCoinID error: %v", err)
+	}
+	if decoded.Kind != CoinIDTx || decoded.String() != txid {
+		t.Fatalf("wrong decoded tx coin ID %+v", decoded)
+	}
+}
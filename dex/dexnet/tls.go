@@ -0,0 +1,63 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dexnet
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// TLSConfig creates a server TLS configuration with the certificate, a minimum
+// TLS version of minVersion, and the named cipher suites. minVersion may be
+// "1.2" or "1.3", and defaults to 1.2 if empty. If no cipher suites are named,
+// Go's defaults are used. Insecure versions and cipher suites are rejected, as
+// are cipher suites with a minimum version of 1.3, for which they cannot be
+// configured.
+func TLSConfig(keyPair tls.Certificate, minVersion string, cipherSuites []string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{keyPair},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	switch minVersion {
+	case "", "1.2":
+	case "1.3":
+		tlsConfig.MinVersion = tls.VersionTLS13
+	case "1.0", "1.1":
+		return nil, fmt.Errorf("TLS version %s is insecure, the minimum is 1.2", minVersion)
+	default:
+		return nil, fmt.Errorf("unknown TLS version %q", minVersion)
+	}
+
+	if len(cipherSuites) == 0 {
+		return tlsConfig, nil
+	}
+	if tlsConfig.MinVersion == tls.VersionTLS13 {
+		return nil, errors.New("TLS cipher suites cannot be configured with a minimum TLS version of 1.3")
+	}
+	suites := make(map[string]*tls.CipherSuite)
+	for _, cs := range tls.CipherSuites() {
+		suites[cs.Name] = cs
+	}
+	insecureSuites := make(map[string]bool)
+	for _, cs := range tls.InsecureCipherSuites() {
+		insecureSuites[cs.Name] = true
+	}
+	for _, name := range cipherSuites {
+		if insecureSuites[name] {
+			return nil, fmt.Errorf("TLS cipher suite %s is insecure", name)
+		}
+		cs, found := suites[name]
+		if !found {
+			return nil, fmt.Errorf("unknown TLS cipher suite %q", name)
+		}
+		if !slices.Contains(cs.SupportedVersions, tls.VersionTLS12) {
+			return nil, fmt.Errorf("TLS cipher suite %s is TLS 1.3 only and cannot be configured", name)
+		}
+		tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, cs.ID)
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,53 @@
+package dexnet
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestTLSConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		minVersion string
+		suites     []string
+		wantErr    bool
+		wantMin    uint16
+		wantSuites int
+	}{
+		{name: "defaults", wantMin: tls.VersionTLS12},
+		{name: "1.3", minVersion: "1.3", wantMin: tls.VersionTLS13},
+		{name: "insecure version", minVersion: "1.1", wantErr: true},
+		{name: "unknown version", minVersion: "2", wantErr: true},
+		{
+			name:       "suites",
+			suites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256", "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256"},
+			wantMin:    tls.VersionTLS12,
+			wantSuites: 2,
+		},
+		{name: "suites with 1.3", minVersion: "1.3", suites: []string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"}, wantErr: true},
+		{name: "insecure suite", suites: []string{"TLS_RSA_WITH_RC4_128_SHA"}, wantErr: true},
+		{name: "unknown suite", suites: []string{"TLS_NOPE"}, wantErr: true},
+		{name: "1.3 only suite", suites: []string{"TLS_AES_128_GCM_SHA256"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		cfg, err := TLSConfig(tls.Certificate{}, tt.minVersion, tt.suites)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("%s: no error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if cfg.MinVersion != tt.wantMin {
+			t.Fatalf("%s: wrong min version %d", tt.name, cfg.MinVersion)
+		}
+		if len(cfg.CipherSuites) != tt.wantSuites {
+			t.Fatalf("%s: wrong number of cipher suites %d", tt.name, len(cfg.CipherSuites))
+		}
+		if len(cfg.Certificates) != 1 {
+			t.Fatalf("%s: certificate not set", tt.name)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 )
 
 const (
@@ -52,6 +53,14 @@ type MarketInfo struct {
 	EpochDuration          uint64 // msec
 	MarketBuyBuffer        float64
 	MaxUserCancelsPerEpoch uint32
+	MinOrderLots           uint32 // minimum trade order quantity in lots, 0 for no minimum
+	MaxOpenOrders          uint32 // maximum standing orders per account, 0 for no limit
+	// MinOrderLifetime is how long a standing order must be on the market
+	// before it may be canceled. Zero means no minimum.
+	MinOrderLifetime time.Duration
+	// MaxOrderLifetime is how long a standing order may be on the market before
+	// it is revoked by the server. Zero means no maximum.
+	MaxOrderLifetime time.Duration
 }
 
 func marketName(base, quote string) string {
@@ -98,6 +98,9 @@ const (
 	RPCUpdateRunningBotCfgError          // 80
 	RPCUpdateRunningBotInvError          // 81
 	RPCMMStatusError                     // 82
+	CancelRatioError                     // 83
+	RPCSetPriceBandError                 // 84
+	RPCSetAutoRefundError                // 85
 )
 
 // Routes are destinations for a "payload" of data. The type of data being
@@ -153,6 +156,10 @@ const (
 	// UnsubOrderBookRoute is client-originating request-type message cancelling
 	// an order book subscription.
 	UnsubOrderBookRoute = "unsub_orderbook"
+	// OrderBookPageRoute is the DEX-originating notification-type message
+	// delivering a page of the orders of an order book snapshot that was too
+	// large to send in the 'orderbook' response.
+	OrderBookPageRoute = "orderbook_page"
 	// BookOrderRoute is the DEX-originating notification-type message informing
 	// the client to add the order to the order book.
 	BookOrderRoute = "book_order"
@@ -209,18 +216,29 @@ const (
 	// client of an upcoming trade resumption. This is part of the
 	// subscription-based orderbook notification feed.
 	ResumptionRoute = "resumption"
+	// MarketConfigRoute is the DEX-originating notification-type message
+	// delivering a market's configuration after its lot size or rate step
+	// has changed. The payload is the updated Market.
+	MarketConfigRoute = "market_config"
 	// NotifyRoute is the DEX-originating notification-type message
 	// delivering text messages from the operator.
 	NotifyRoute = "notify"
 	// PenaltyRoute is the DEX-originating notification-type message
 	// informing of a broken rule and the resulting penalty.
 	PenaltyRoute = "penalty"
+	// AnnouncementRoute is the DEX-originating notification-type message
+	// delivering an operator announcement, such as a scheduled maintenance
+	// window. Active announcements are also sent to newly connected clients.
+	AnnouncementRoute = "announcement"
 	// SpotsRoute is the client-originating HTTP or WebSocket request to get the
 	// spot price and volume for the DEX's markets.
 	SpotsRoute = "spots"
 	// FeeRateRoute is the client-originating request asking for the most
 	// recently recorded transaction fee estimate for an asset.
 	FeeRateRoute = "fee_rate"
+	// FeeRateHistoryRoute is the client-originating request asking for the
+	// recent history of the fee rate estimates recorded for an asset.
+	FeeRateHistoryRoute = "fee_rate_history"
 	// PriceFeedRoute is the client-originating request subscribing to the
 	// market overview feed.
 	PriceFeedRoute = "price_feed"
@@ -230,6 +248,42 @@ const (
 	// CandlesRoute is the HTTP request to get the set of candlesticks
 	// representing market activity history.
 	CandlesRoute = "candles"
+	// EpochAuditRoute is the HTTP request to get the commit-reveal record of a
+	// matched epoch, with which the epoch's order shuffling may be verified.
+	EpochAuditRoute = "epoch_audit"
+	// MirrorRoute is the HTTP request to get the read-only copy of another
+	// DEX's order book for a mirrored market.
+	MirrorRoute = "mirror"
+	// RecentTradesRoute is the request to get a market's most recent public
+	// trades.
+	RecentTradesRoute = "recent_trades"
+	// SignedConfigRoute is the client-originating request-type message
+	// requesting the DEX configuration, signed with the server's identity key.
+	SignedConfigRoute = "signed_config"
+	// FeaturesRoute is the client-originating request-type message advertising
+	// the optional protocol features that the client supports. The response is
+	// the set of those features that the server will use on the connection.
+	FeaturesRoute = "features"
+	// CheckTxRoute is the client-originating request-type message asking
+	// whether the server's node for an asset would accept a transaction,
+	// e.g. a swap funding transaction, before the client broadcasts it.
+	CheckTxRoute = "check_tx"
+	// ValidateRedemptionRoute is the client-originating request-type message
+	// asking whether a redemption of a swap contract would be accepted, before
+	// the client broadcasts it.
+	ValidateRedemptionRoute = "validate_redemption"
+)
+
+// Optional protocol features that may be negotiated on a connection with a
+// FeaturesRoute request. A server only uses an optional feature on a
+// connection that has negotiated it.
+const (
+	// FeatureAnnouncements is the AnnouncementRoute notification of operator
+	// announcements.
+	FeatureAnnouncements = "announcements"
+	// FeatureBookPages is the pagination of large order book snapshots with
+	// OrderBookPageRoute notifications.
+	FeatureBookPages = "book_pages"
 )
 
 const errNullRespPayload = dex.ErrorKind("null response payload")
@@ -311,6 +365,10 @@ type ResponsePayload struct {
 // decoded depends on its MessageType.
 type MessageType uint8
 
+// DefaultMaxMessageSize is the default size above which a server splits an order
+// book snapshot into pages for clients that have negotiated FeatureBookPages.
+const DefaultMaxMessageSize = 1 << 20 // 1 MiB
+
 // There are presently three recognized message types: request, response, and
 // notification.
 const (
@@ -885,6 +943,39 @@ type OrderBook struct {
 	// RecentMatches is [rate, qty, timestamp]. Quantity is signed.
 	// Negative means that the maker was a sell order.
 	RecentMatches [][3]int64 `json:"recentMatches"`
+	// BaseFeeRange and QuoteFeeRange are the recommended fee rate ranges for
+	// assets whose backends estimate them.
+	BaseFeeRange  *FeeRateRange `json:"baseFeeRange,omitempty"`
+	QuoteFeeRange *FeeRateRange `json:"quoteFeeRange,omitempty"`
+	// Pages is the number of pages of the snapshot's orders when the snapshot
+	// is too large for one message and the client has negotiated
+	// FeatureBookPages. Orders is the first page, and the rest are
+	// sent as OrderBookPage notifications that follow the response. Zero means
+	// that Orders is the entire book.
+	Pages uint32 `json:"pages,omitempty"`
+}
+
+// OrderBookPage is the payload for a DEX-originating notification-type message
+// delivering a page of the orders of a paginated order book snapshot. Seq is
+// the Seq of the snapshot, and Page is the index of the page, starting at 1
+// since page 0 is in the OrderBook.
+type OrderBookPage struct {
+	MarketID string           `json:"marketid"`
+	Seq      uint64           `json:"seq"`
+	Page     uint32           `json:"page"`
+	Orders   []*BookOrderNote `json:"orders"`
+}
+
+// FeeRateRange is a range of recommended fee rates for an asset. Rates below
+// MinToConfirm are not expected to be mined in a reasonable time, Economical
+// within a few blocks, and Priority in the next block. The range is advisory
+// only. It does not relax the server's requirements: swaps must still pay the
+// prescribed swap fee rate, and unconfirmed funding coins must pay at least 90%
+// of the server's last known fee rate, regardless of MinToConfirm.
+type FeeRateRange struct {
+	MinToConfirm uint64 `json:"minToConfirm"`
+	Economical   uint64 `json:"economical"`
+	Priority     uint64 `json:"priority"`
 }
 
 // MatchProofNote is the match_proof notification payload.
@@ -900,19 +991,19 @@ type MatchProofNote struct {
 // TradeSuspension is the SuspensionRoute notification payload. It is part of
 // the orderbook subscription.
 type TradeSuspension struct {
-	MarketID    string `json:"marketid"`
-	Seq         uint64 `json:"seq,omitempty"`         // only set at suspend time and if Persist==false
-	SuspendTime uint64 `json:"suspendtime,omitempty"` // only set in advance of suspend
-	FinalEpoch  uint64 `json:"finalepoch"`
-	Persist     bool   `json:"persistbook"`
+	MarketID    string    `json:"marketid"`
+	Seq         uint64    `json:"seq,omitempty"`         // only set at suspend time and if Persist==false
+	SuspendTime dex.Stamp `json:"suspendtime,omitempty"` // only set in advance of suspend
+	FinalEpoch  uint64    `json:"finalepoch"`
+	Persist     bool      `json:"persistbook"`
 }
 
 // TradeResumption is the ResumptionRoute notification payload. It is part of
 // the orderbook subscription.
 type TradeResumption struct {
-	MarketID   string `json:"marketid"`
-	ResumeTime uint64 `json:"resumetime,omitempty"` // only set in advance of resume
-	StartEpoch uint64 `json:"startepoch"`
+	MarketID   string    `json:"marketid"`
+	ResumeTime dex.Stamp `json:"resumetime,omitempty"` // only set in advance of resume
+	StartEpoch uint64    `json:"startepoch"`
 	// TODO: ConfigChange bool or entire Config Market here.
 }
 
@@ -966,6 +1057,21 @@ type ConnectResult struct {
 	Score               int32               `json:"score"`
 	ActiveBonds         []*Bond             `json:"activeBonds"`
 	Reputation          *account.Reputation `json:"reputation"`
+	// CancelRatio is only set if the server enforces a maximum cancellation
+	// ratio.
+	CancelRatio *CancelRatio `json:"cancelRatio,omitempty"`
+}
+
+// CancelRatio is a user's ratio of submitted cancel orders to submitted trade
+// orders over a rolling window. New trade orders are refused while the ratio
+// exceeds the Limit.
+type CancelRatio struct {
+	Ratio float64 `json:"ratio"`
+	Limit float64 `json:"limit"`
+	// Window is the duration of the rolling window, in milliseconds.
+	Window  uint64 `json:"window"`
+	Trades  uint32 `json:"trades"`
+	Cancels uint32 `json:"cancels"`
 }
 
 // TierChangedNotification is the dex-originating notification sent when the
@@ -1009,6 +1115,66 @@ type PenaltyNote struct {
 	Penalty *Penalty `json:"penalty"`
 }
 
+// Announcement severities.
+const (
+	AnnouncementInfo     = "info"
+	AnnouncementWarning  = "warning"
+	AnnouncementCritical = "critical"
+)
+
+// Announcement is the payload of an AnnouncementRoute notification.
+type Announcement struct {
+	// ID is unique to the announcement, so that a client does not show an
+	// announcement again when it is resent on reconnect.
+	ID       uint64 `json:"id"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Link     string `json:"link,omitempty"`
+	// Expiry is the time in milliseconds after which the announcement is no
+	// longer relevant.
+	Expiry uint64 `json:"expiry"`
+}
+
+// CheckTxRequest is the payload of a client-originating CheckTxRoute request.
+// Tx is the serialized, signed transaction.
+type CheckTxRequest struct {
+	AssetID uint32 `json:"assetID"`
+	Tx      Bytes  `json:"tx"`
+}
+
+// CheckTxResult is the result of a CheckTxRoute request. If the transaction
+// would not be accepted, Reason is the node's reason for rejecting it.
+type CheckTxResult struct {
+	Accepted bool   `json:"accepted"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ValidateRedemptionRequest is the payload of a client-originating
+// ValidateRedemptionRoute request.
+type ValidateRedemptionRequest struct {
+	AssetID  uint32 `json:"assetID"`
+	Contract Bytes  `json:"contract"`
+	Secret   Bytes  `json:"secret"`
+}
+
+// ValidateRedemptionResult is the result of a ValidateRedemptionRoute request.
+// If the redemption would not be accepted, Reason explains why.
+type ValidateRedemptionResult struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// FeaturesRequest is the payload of a client-originating FeaturesRoute request.
+type FeaturesRequest struct {
+	Features []string `json:"features"`
+}
+
+// FeaturesResult is the result of a FeaturesRoute request. Features is the
+// subset of the requested features that the server supports.
+type FeaturesResult struct {
+	Features []string `json:"features"`
+}
+
 // Penalty is part of the payload for a dex-originating Penalty notification
 // and part of the connect response.
 type Penalty struct {
@@ -1223,7 +1389,15 @@ type Market struct {
 	RateStep        uint64  `json:"ratestep"`
 	MarketBuyBuffer float64 `json:"buybuffer"`
 	ParcelSize      uint32  `json:"parcelSize"`
+	MinOrderLots    uint32  `json:"minOrderLots,omitempty"`
+	MaxOpenOrders   uint32  `json:"maxOpenOrders,omitempty"`
 	MarketStatus    `json:"status"`
+	// MinOrderLifetime is how long, in milliseconds, a standing order must be
+	// on the market before it may be canceled. Zero means no minimum.
+	MinOrderLifetime uint64 `json:"minOrderLifetime,omitempty"`
+	// MaxOrderLifetime is how long, in milliseconds, a standing order may be
+	// on the market before it is revoked by the server. Zero means no maximum.
+	MaxOrderLifetime uint64 `json:"maxOrderLifetime,omitempty"`
 }
 
 // Running indicates if the market should be running given the known StartEpoch,
@@ -1245,6 +1419,22 @@ type Asset struct {
 	MaxFeeRate uint64       `json:"maxfeerate"`
 	SwapConf   uint16       `json:"swapconf"`
 	UnitInfo   dex.UnitInfo `json:"unitinfo"`
+	// DustLimit is the smallest swap contract value that can be redeemed
+	// economically at MaxFeeRate. The server checks orders against the limit
+	// at its current fee rate, to which the limit is proportional.
+	DustLimit uint64 `json:"dustlimit,omitempty"`
+	// Versions are all of the asset versions the server accepts, for
+	// transitions between versions, e.g. of a swap contract. Version is the
+	// preferred version. If empty, only Version is accepted.
+	Versions []uint32 `json:"versions,omitempty"`
+	// FeeAssetID is the asset in which the asset's transaction fees, and
+	// MaxFeeRate, are denominated. For tokens, this is the parent asset. If
+	// nil, the fees are paid in the asset itself.
+	FeeAssetID *uint32 `json:"feeassetid,omitempty"`
+	// DisplaySymbol and Aliases are the operator-configured display symbol
+	// and alternative names of the asset. See dex.Asset.
+	DisplaySymbol string   `json:"displaysymbol,omitempty"`
+	Aliases       []string `json:"aliases,omitempty"`
 }
 
 // BondAsset describes an asset for which fidelity bonds are supported.
@@ -1283,6 +1473,26 @@ type ConfigResult struct {
 	MaxScore         uint32 `json:"maxScore"`
 }
 
+// SignedConfig is the result for the SignedConfigRoute. Config is the
+// JSON-encoded ConfigResult, which is signed along with Stamp by the server's
+// identity key, so that the configuration can be verified with the server's
+// public key regardless of how the document was obtained.
+type SignedConfig struct {
+	Signature
+	Config json.RawMessage `json:"config"`
+	Stamp  uint64          `json:"stamp"`
+}
+
+var _ Signable = (*SignedConfig)(nil)
+
+// Serialize serializes the SignedConfig data.
+func (sc *SignedConfig) Serialize() []byte {
+	// serialization: config (variable) + stamp (8)
+	b := make([]byte, 0, len(sc.Config)+8)
+	b = append(b, sc.Config...)
+	return append(b, uint64Bytes(sc.Stamp)...)
+}
+
 // Spot is a snapshot of a market at the end of a match cycle. A slice of Spot
 // are sent as the response to the SpotsRoute request.
 type Spot struct {
@@ -1305,6 +1515,75 @@ type CandlesRequest struct {
 	NumCandles int    `json:"numCandles,omitempty"` // default and max defined in apidata.
 }
 
+// EpochAuditRequest is a data API request for the commit-reveal record of a
+// matched epoch.
+type EpochAuditRequest struct {
+	BaseID  uint32 `json:"baseID"`
+	QuoteID uint32 `json:"quoteID"`
+	Epoch   uint64 `json:"epoch"`
+}
+
+// RecentTradesRequest is a request for a market's most recent trades.
+type RecentTradesRequest struct {
+	BaseID  uint32 `json:"baseID"`
+	QuoteID uint32 `json:"quoteID"`
+	// N is the maximum number of trades to return. If zero, or more than the
+	// server retains, all retained trades are returned.
+	N int `json:"n,omitempty"`
+}
+
+// FeeRateHistoryRequest is a request for the recent fee rate estimates
+// recorded for an asset.
+type FeeRateHistoryRequest struct {
+	AssetID uint32 `json:"assetID"`
+	// N is the maximum number of fee rates to return. If zero, or more than the
+	// server retains, all retained fee rates are returned.
+	N int `json:"n,omitempty"`
+}
+
+// FeeRateRecord is a fee rate estimate recorded by the server for an asset. A
+// rate is recorded when it differs from the previous estimate.
+type FeeRateRecord struct {
+	Rate uint64 `json:"rate"`
+	// Stamp is the time the rate was recorded.
+	Stamp dex.Stamp `json:"stamp"`
+}
+
+// PublicTrade is a trade on a market's public trade tape. Matches at the same
+// rate and taker side in an epoch are combined, and no order or account
+// information is included.
+type PublicTrade struct {
+	Rate uint64 `json:"rate"`
+	Qty  uint64 `json:"qty"`
+	// Sell is true if the taker was selling the base asset.
+	Sell bool `json:"sell"`
+	// Stamp is the end of the matched epoch, in milliseconds.
+	Stamp uint64 `json:"stamp"`
+}
+
+// EpochAudit is the commit-reveal record of a matched epoch. The order
+// commitments are published in epoch_order notifications, and CSum in the
+// preimage requests, before any preimage is revealed. An EpochAudit is only
+// available once the epoch has been matched.
+type EpochAudit struct {
+	MarketID  string `json:"marketid"`
+	Epoch     uint64 `json:"epoch"`
+	Duration  uint64 `json:"duration"`
+	MatchTime uint64 `json:"matchTime"`
+	CSum      Bytes  `json:"csum"`
+	Seed      Bytes  `json:"seed"`
+	// Orders are all orders in the epoch queue, sorted by commitment.
+	Orders []*EpochAuditOrder `json:"orders"`
+}
+
+// EpochAuditOrder is an order in an EpochAudit. Preimage is empty if the order's
+// preimage was not revealed.
+type EpochAuditOrder struct {
+	OrderID  Bytes `json:"oid"`
+	Commit   Bytes `json:"commit"`
+	Preimage Bytes `json:"preimage,omitempty"`
+}
+
 // Candle is a statistical history of a specified period of market activity.
 type Candle struct {
 	StartStamp  uint64 `json:"startStamp"`
@@ -1375,6 +1654,8 @@ type EpochReportNote struct {
 	// the maker was a sell order.
 	MatchSummary [][2]int64 `json:"matchSummary"`
 	Candle
+	BaseFeeRange  *FeeRateRange `json:"baseFeeRange,omitempty"`
+	QuoteFeeRange *FeeRateRange `json:"quoteFeeRange,omitempty"`
 }
 
 // Convert uint64 to 8 bytes.
@@ -49,6 +49,22 @@ func EncodeCashAddress(btcAddr btcutil.Address, net *chaincfg.Params) (string, e
 	return withPrefix(bchAddr, net), nil
 }
 
+// EncodeLegacyAddress converts a btcutil.Address into the legacy base-58
+// address string that Bitcoin Cash used before the Cash Address format was
+// adopted. The legacy format is the same as Bitcoin's, but is still only for
+// display to users of services that have not adopted Cash Address.
+func EncodeLegacyAddress(btcAddr btcutil.Address, net *chaincfg.Params) (string, error) {
+	switch at := btcAddr.(type) {
+	case *btcutil.AddressPubKeyHash, *btcutil.AddressScriptHash:
+		if !btcAddr.IsForNet(net) {
+			return "", fmt.Errorf("address is not for the %s network", net.Name)
+		}
+		return btcAddr.EncodeAddress(), nil
+	default:
+		return "", fmt.Errorf("no legacy encoding for address type %T", at)
+	}
+}
+
 func BCHAddrtoBTCAddr(bchAddr bchutil.Address, net *chaincfg.Params) (btcutil.Address, error) {
 	switch at := bchAddr.(type) {
 	// From what I can tell, the legacy address formats are probably
@@ -4,6 +4,7 @@
 package bch
 
 import (
+	"strings"
 	"testing"
 
 	"decred.org/dcrdex/dex/encode"
@@ -140,3 +141,58 @@ func testRoundTripFromBTC(t *testing.T, btcAddrStr string, net *chaincfg.Params)
 		t.Fatalf("Decoded address mismatch: %s != %s", reAddr, btcAddrStr)
 	}
 }
+
+func TestLegacyAddress(t *testing.T) {
+	nets := []*chaincfg.Params{MainNetParams, TestNet4Params, RegressionNetParams}
+	for _, net := range nets {
+		pkh, _ := btcutil.NewAddressPubKeyHash(encode.RandomBytes(20), net)
+		sh, _ := btcutil.NewAddressScriptHashFromHash(encode.RandomBytes(20), net)
+		for _, addr := range []btcutil.Address{pkh, sh} {
+			// The canonical format is the Cash Address with the network prefix.
+			cashAddr, err := EncodeCashAddress(addr, net)
+			if err != nil {
+				t.Fatalf("EncodeCashAddress error: %v", err)
+			}
+			if !strings.HasPrefix(cashAddr, net.Bech32HRPSegwit+":") {
+				t.Fatalf("%s address %s does not have the %s prefix", net.Name, cashAddr, net.Bech32HRPSegwit)
+			}
+
+			legacyAddr, err := EncodeLegacyAddress(addr, net)
+			if err != nil {
+				t.Fatalf("EncodeLegacyAddress error: %v", err)
+			}
+			if strings.Contains(legacyAddr, ":") {
+				t.Fatalf("legacy address %s has a prefix", legacyAddr)
+			}
+			// The legacy address must decode to the same address in either
+			// format.
+			decoded, err := DecodeCashAddress(legacyAddr, net)
+			if err != nil {
+				t.Fatalf("error decoding legacy address %s: %v", legacyAddr, err)
+			}
+			reAddr, err := EncodeCashAddress(decoded, net)
+			if err != nil {
+				t.Fatalf("EncodeCashAddress error for decoded legacy address: %v", err)
+			}
+			if reAddr != cashAddr {
+				t.Fatalf("legacy address %s decoded to %s, wanted %s", legacyAddr, reAddr, cashAddr)
+			}
+		}
+
+		// Wrong network.
+		otherNet := MainNetParams
+		if net == MainNetParams {
+			otherNet = TestNet4Params
+		}
+		if _, err := EncodeLegacyAddress(pkh, otherNet); err == nil {
+			t.Fatalf("no error for %s address on %s", net.Name, otherNet.Name)
+		}
+
+		// Pubkey addresses have no legacy format.
+		priv, _ := btcec.NewPrivateKey()
+		pk, _ := btcutil.NewAddressPubKey(priv.PubKey().SerializeCompressed(), net)
+		if _, err := EncodeLegacyAddress(pk, net); err == nil {
+			t.Fatalf("no error for pubkey address")
+		}
+	}
+}
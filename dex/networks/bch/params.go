@@ -81,4 +81,7 @@ func init() {
 			panic("failed to register bch parameters: " + err.Error())
 		}
 	}
+
+	assetID, _ := dex.BipSymbolID("bch")
+	dex.RegisterCoinIDDecoder(assetID, dex.DecodeOutpointCoinID)
 }
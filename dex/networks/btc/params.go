@@ -23,3 +23,8 @@ var UnitInfo = dex.UnitInfo{
 	},
 	FeeRateDenom: "vB",
 }
+
+func init() {
+	assetID, _ := dex.BipSymbolID("btc")
+	dex.RegisterCoinIDDecoder(assetID, dex.DecodeOutpointCoinID)
+}
@@ -912,6 +912,30 @@ func RefundP2WSHContract(contract, sig, pubkey []byte) [][]byte {
 	}
 }
 
+// IsRefundScript checks if the signature script, or the witness for segwit
+// contracts, is of the expected format for the standard swap contract refund.
+// The signature and pubkey data pushes are not validated other than ensuring
+// they are not empty. The provided contract must correspond to the final data
+// push, but it is otherwise not validated either.
+func IsRefundScript(segwit bool, sigScript []byte, witness [][]byte, contract []byte) bool {
+	pushes := witness
+	if !segwit {
+		var err error
+		pushes, err = txscript.PushedData(sigScript)
+		if err != nil {
+			return false
+		}
+	}
+	// sig, pubkey, OP_0 / empty, contract
+	if len(pushes) != 4 {
+		return false
+	}
+	if len(pushes[0]) == 0 || len(pushes[1]) == 0 || len(pushes[2]) != 0 {
+		return false
+	}
+	return len(pushes[3]) == SwapContractSize && bytes.Equal(pushes[3], contract)
+}
+
 // SpendInfo is information about an input and it's previous outpoint.
 type SpendInfo struct {
 	SigScriptSize     uint32
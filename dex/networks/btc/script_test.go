@@ -508,6 +508,46 @@ func testFindKeyPush(t *testing.T, segwit bool) {
 	}
 }
 
+func TestIsRefundScript(t *testing.T) {
+	for _, segwit := range []bool{true, false} {
+		var rAddr, sAddr btcutil.Address
+		if segwit {
+			rAddr, _ = btcutil.NewAddressWitnessPubKeyHash(randBytes(20), tParams)
+			sAddr, _ = btcutil.NewAddressWitnessPubKeyHash(randBytes(20), tParams)
+		} else {
+			rAddr, _ = btcutil.NewAddressPubKeyHash(randBytes(20), tParams)
+			sAddr, _ = btcutil.NewAddressPubKeyHash(randBytes(20), tParams)
+		}
+		secret := randBytes(32)
+		secretHash := sha256.Sum256(secret)
+		contract, _ := MakeContract(rAddr, sAddr, secretHash[:], tStamp, segwit, tParams)
+		otherContract, _ := MakeContract(rAddr, sAddr, randBytes(32), tStamp, segwit, tParams)
+
+		var refundScript, redeemScript []byte
+		var refundWitness, redeemWitness [][]byte
+		if segwit {
+			refundWitness = RefundP2WSHContract(contract, randBytes(73), randBytes(33))
+			redeemWitness = RedeemP2WSHContract(contract, randBytes(73), randBytes(33), secret)
+		} else {
+			refundScript, _ = RefundP2SHContract(contract, randBytes(73), randBytes(33))
+			redeemScript, _ = RedeemP2SHContract(contract, randBytes(73), randBytes(33), secret)
+		}
+
+		if !IsRefundScript(segwit, refundScript, refundWitness, contract) {
+			t.Fatalf("segwit = %t: refund not recognized", segwit)
+		}
+		if IsRefundScript(segwit, redeemScript, redeemWitness, contract) {
+			t.Fatalf("segwit = %t: redeem recognized as refund", segwit)
+		}
+		if IsRefundScript(segwit, refundScript, refundWitness, otherContract) {
+			t.Fatalf("segwit = %t: refund of wrong contract recognized", segwit)
+		}
+		if IsRefundScript(segwit, invalidScript, invalidWitness, contract) {
+			t.Fatalf("segwit = %t: invalid script recognized as refund", segwit)
+		}
+	}
+}
+
 func TestExtractContractHash(t *testing.T) {
 	addrs := testAddresses()
 	// non-hex
@@ -94,4 +94,7 @@ func init() {
 			panic("failed to register dash parameters: " + err.Error())
 		}
 	}
+
+	assetID, _ := dex.BipSymbolID("dash")
+	dex.RegisterCoinIDDecoder(assetID, dex.DecodeOutpointCoinID)
 }
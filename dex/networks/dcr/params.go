@@ -23,3 +23,8 @@ var UnitInfo = dex.UnitInfo{
 	},
 	FeeRateDenom: "B",
 }
+
+func init() {
+	assetID, _ := dex.BipSymbolID("dcr")
+	dex.RegisterCoinIDDecoder(assetID, dex.DecodeOutpointCoinID)
+}
@@ -98,4 +98,7 @@ func init() {
 			panic("failed to register dgb parameters: " + err.Error())
 		}
 	}
+
+	assetID, _ := dex.BipSymbolID("dgb")
+	dex.RegisterCoinIDDecoder(assetID, dex.DecodeOutpointCoinID)
 }
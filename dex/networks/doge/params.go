@@ -92,4 +92,7 @@ func init() {
 			panic("failed to register doge parameters: " + err.Error())
 		}
 	}
+
+	assetID, _ := dex.BipSymbolID("doge")
+	dex.RegisterCoinIDDecoder(assetID, dex.DecodeOutpointCoinID)
 }
@@ -5,12 +5,9 @@ package eth
 
 import (
 	"fmt"
-	"sort"
-	"strings"
 
 	"decred.org/dcrdex/dex"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // DecodeCoinID decodes the coin ID into a common.Hash. For eth, there are no
@@ -26,36 +23,41 @@ func DecodeCoinID(coinID []byte) (common.Hash, error) {
 	return h, nil
 }
 
-// SecretHashSize is the byte-length of the hash of the secret key used in
-// swaps.
-const SecretHashSize = 32
-
-// CheckAPIModules checks that the geth node supports the required modules.
-func CheckAPIModules(c *rpc.Client, endpoint string, log dex.Logger, reqModules []string) (err error) {
-	apis, err := c.SupportedModules()
-	if err != nil {
-		return fmt.Errorf("unable to check supported modules: %v", err)
-	}
-	reqModulesMap := make(map[string]struct{})
-	for _, mod := range reqModules {
-		reqModulesMap[mod] = struct{}{}
-	}
-	haveModules := make([]string, 0, len(apis))
-	for api, version := range apis {
-		_, has := reqModulesMap[api]
-		if has {
-			delete(reqModulesMap, api)
+// DecodeCoinIDComponents decodes an ETH or token coin ID, which is either a
+// transaction hash or an account address. The account address may be the raw
+// 20-byte address or its hex string encoding.
+func DecodeCoinIDComponents(coinID []byte) (*dex.DecodedCoinID, error) {
+	switch len(coinID) {
+	case common.HashLength:
+		return &dex.DecodedCoinID{
+			Kind:   dex.CoinIDTx,
+			TxHash: common.BytesToHash(coinID).String(),
+		}, nil
+	case common.AddressLength:
+		return &dex.DecodedCoinID{
+			Kind:    dex.CoinIDAccount,
+			Address: common.BytesToAddress(coinID).String(),
+		}, nil
+	case common.AddressLength * 2, common.AddressLength*2 + 2: // hex, with or without 0x
+		if common.IsHexAddress(string(coinID)) {
+			return &dex.DecodedCoinID{
+				Kind:    dex.CoinIDAccount,
+				Address: common.HexToAddress(string(coinID)).String(),
+			}, nil
 		}
-		haveModules = append(haveModules, fmt.Sprintf("%s:%s", api, version))
+		return nil, fmt.Errorf("invalid hex address %q", string(coinID))
 	}
-	if len(reqModulesMap) > 0 {
-		reqs := make([]string, 0, len(reqModulesMap))
-		for v := range reqModulesMap {
-			reqs = append(reqs, v)
-		}
-		return fmt.Errorf("needed apis not present: %v.", strings.Join(reqs, " "))
+	return nil, fmt.Errorf("coin ID wrong length. expected %d (tx hash) or %d (address), got %d",
+		common.HashLength, common.AddressLength, len(coinID))
+}
+
+func init() {
+	dex.RegisterCoinIDDecoder(EthBipID, DecodeCoinIDComponents)
+	for tokenID := range Tokens {
+		dex.RegisterCoinIDDecoder(tokenID, DecodeCoinIDComponents)
 	}
-	sort.Strings(haveModules)
-	log.Debugf("API endpoints supported by %s: %s", endpoint, strings.Join(haveModules, " "))
-	return nil
 }
+
+// SecretHashSize is the byte-length of the hash of the secret key used in
+// swaps.
+const SecretHashSize = 32
@@ -0,0 +1,40 @@
+package eth
+
+import (
+	"errors"
+	"testing"
+
+	"decred.org/dcrdex/dex"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDecodeCoinIDComponents(t *testing.T) {
+	const txHash = "0x692cf15b145cb45c0098bedf8a55d067b1ac994973bb62000c046b8453d8b624"
+	const addr = "0x23d8203d8E3c839F359bcC85BFB71cf0d707EDF0"
+
+	s, decoded, err := dex.DecodeCoinID(EthBipID, common.HexToHash(txHash).Bytes())
+	if err != nil {
+		t.Fatalf("DecodeCoinID error: %v", err)
+	}
+	if s != txHash || decoded.Kind != dex.CoinIDTx || decoded.TxHash != txHash {
+		t.Fatalf("wrong decoded tx hash coin ID %q, %+v", s, decoded)
+	}
+
+	// Raw and hex-encoded account addresses, for eth and a token.
+	usdcID, _ := dex.BipSymbolID("usdc.eth")
+	for _, coinID := range [][]byte{common.HexToAddress(addr).Bytes(), []byte(addr), []byte(addr[2:])} {
+		s, decoded, err = dex.DecodeCoinID(usdcID, coinID)
+		if err != nil {
+			t.Fatalf("DecodeCoinID error for %x: %v", coinID, err)
+		}
+		if s != addr || decoded.Kind != dex.CoinIDAccount || decoded.Address != addr {
+			t.Fatalf("wrong decoded address coin ID %q, %+v", s, decoded)
+		}
+	}
+
+	for _, badID := range [][]byte{nil, make([]byte, 31), []byte("0x23d8203d8E3c839F359bcC85BFB71cf0d707EDFz")} {
+		if _, _, err = dex.DecodeCoinID(EthBipID, badID); !errors.Is(err, dex.ErrInvalidCoinID) {
+			t.Fatalf("expected ErrInvalidCoinID for %x, got %v", badID, err)
+		}
+	}
+}
@@ -94,4 +94,7 @@ func init() {
 			panic("failed to register firo parameters: " + err.Error())
 		}
 	}
+
+	assetID, _ := dex.BipSymbolID("firo")
+	dex.RegisterCoinIDDecoder(assetID, dex.DecodeOutpointCoinID)
 }
@@ -87,4 +87,7 @@ func init() {
 			panic("failed to register ltc parameters: " + err.Error())
 		}
 	}
+
+	assetID, _ := dex.BipSymbolID("ltc")
+	dex.RegisterCoinIDDecoder(assetID, dex.DecodeOutpointCoinID)
 }
@@ -377,3 +377,10 @@ var (
 func MaybeReadSimnetAddrs() {
 	dexeth.MaybeReadSimnetAddrsDir("polygon", ContractAddresses, MultiBalanceAddresses, Tokens[usdcTokenID].NetTokens[dex.Simnet], Tokens[usdtTokenID].NetTokens[dex.Simnet])
 }
+
+func init() {
+	dex.RegisterCoinIDDecoder(PolygonBipID, dexeth.DecodeCoinIDComponents)
+	for tokenID := range Tokens {
+		dex.RegisterCoinIDDecoder(tokenID, dexeth.DecodeCoinIDComponents)
+	}
+}
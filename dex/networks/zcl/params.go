@@ -6,6 +6,7 @@ package zcl
 import (
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/networks/btc"
+	"decred.org/dcrdex/dex/networks/zec"
 	"github.com/btcsuite/btcd/chaincfg"
 )
 
@@ -65,4 +66,8 @@ func init() {
 			panic("failed to register zec parameters: " + err.Error())
 		}
 	}
+
+	assetID, _ := dex.BipSymbolID("zcl")
+	// Zclassic shares the Zcash coin ID formats.
+	dex.RegisterCoinIDDecoder(assetID, zec.DecodeCoinIDComponents)
 }
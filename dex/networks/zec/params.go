@@ -7,6 +7,7 @@ import (
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/networks/btc"
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 )
 
 const (
@@ -112,4 +113,17 @@ func init() {
 			panic("failed to register zec parameters: " + err.Error())
 		}
 	}
+
+	assetID, _ := dex.BipSymbolID("zec")
+	dex.RegisterCoinIDDecoder(assetID, DecodeCoinIDComponents)
+}
+
+// DecodeCoinIDComponents decodes a Zcash coin ID. Transparent outputs have the
+// same outpoint coin ID format as Bitcoin, but shielded transactions have no
+// transparent outputs, so the coin ID is just the tx hash.
+func DecodeCoinIDComponents(coinID []byte) (*dex.DecodedCoinID, error) {
+	if len(coinID) == chainhash.HashSize {
+		return dex.DecodeReversedTxCoinID(coinID)
+	}
+	return dex.DecodeOutpointCoinID(coinID)
 }
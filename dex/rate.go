@@ -0,0 +1,131 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dex
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RateEncodingFactor is used when encoding an exchange rate as an integer. A
+// message-rate is the number of atoms of the quote asset per atom of the base
+// asset, multiplied by RateEncodingFactor.
+// https://github.com/decred/dcrdex/blob/master/spec/comm.mediawiki#Rate_Encoding
+const RateEncodingFactor = 1e8
+
+// maxRateDecimals is the precision used to format a rate that has no exact
+// decimal representation, which is only possible for conversion factors that
+// are not powers of 10.
+const maxRateDecimals = 30
+
+var bigRateEncodingFactor = big.NewInt(RateEncodingFactor)
+
+// conventionalRateRat is the message-rate as a rational number of conventional
+// quote units per conventional base unit.
+func conventionalRateRat(msgRate uint64, baseInfo, quoteInfo *UnitInfo) *big.Rat {
+	num := new(big.Int).SetUint64(msgRate)
+	num.Mul(num, new(big.Int).SetUint64(baseInfo.Conventional.ConversionFactor))
+	den := new(big.Int).SetUint64(quoteInfo.Conventional.ConversionFactor)
+	den.Mul(den, bigRateEncodingFactor)
+	return new(big.Rat).SetFrac(num, den)
+}
+
+// DecimalPlaces is the number of decimal places needed to represent the
+// fraction num / den exactly, which is also the number needed to represent any
+// integer multiple of it. If the fraction has no terminating decimal
+// representation, the 30 places used to format such rates are returned. Zero
+// is returned if num or den is zero.
+func DecimalPlaces(num, den *big.Int) int {
+	if num.Sign() == 0 || den.Sign() == 0 {
+		return 0
+	}
+	d := new(big.Int).Abs(new(big.Rat).SetFrac(num, den).Denom())
+	twos := d.TrailingZeroBits()
+	d.Rsh(d, twos)
+	var fives uint
+	five, q, m := big.NewInt(5), new(big.Int), new(big.Int)
+	for {
+		q.QuoRem(d, five, m)
+		if m.Sign() != 0 {
+			break
+		}
+		d.Set(q)
+		fives++
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		return maxRateDecimals // non-terminating
+	}
+	if fives > twos {
+		return int(fives)
+	}
+	return int(twos)
+}
+
+// FormatMessageRate formats the message-rate encoded exchange rate as a decimal
+// string in conventional units of the quote asset per conventional unit of the
+// base asset. The conversion is exact, with no floating point rounding, and
+// trailing zeros are trimmed. "NaN" is returned if either conversion factor is
+// zero.
+func FormatMessageRate(msgRate uint64, baseInfo, quoteInfo UnitInfo) string {
+	if baseInfo.Conventional.ConversionFactor == 0 || quoteInfo.Conventional.ConversionFactor == 0 {
+		return "NaN"
+	}
+	r := conventionalRateRat(msgRate, &baseInfo, &quoteInfo)
+	s := r.FloatString(DecimalPlaces(r.Num(), r.Denom()))
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// ParseMessageRate parses the decimal string representation of a conventional
+// exchange rate, in conventional units of the quote asset per conventional
+// unit of the base asset, to a message-rate. An error is returned if the
+// string is not a non-negative decimal number, or if the rate cannot be
+// represented exactly as a message-rate.
+func ParseMessageRate(s string, baseInfo, quoteInfo UnitInfo) (uint64, error) {
+	baseFactor, quoteFactor := baseInfo.Conventional.ConversionFactor, quoteInfo.Conventional.ConversionFactor
+	if baseFactor == 0 || quoteFactor == 0 {
+		return 0, errors.New("zero conversion factor")
+	}
+	s = strings.TrimSpace(s)
+	if !isDecimal(s) {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid rate %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetFrac(
+		new(big.Int).Mul(bigRateEncodingFactor, new(big.Int).SetUint64(quoteFactor)),
+		new(big.Int).SetUint64(baseFactor),
+	))
+	if !r.IsInt() {
+		return 0, fmt.Errorf("rate %s has more precision than the %s/%s message-rate encoding allows",
+			s, quoteInfo.Conventional.Unit, baseInfo.Conventional.Unit)
+	}
+	if !r.Num().IsUint64() {
+		return 0, fmt.Errorf("rate %s is too large", s)
+	}
+	return r.Num().Uint64(), nil
+}
+
+// isDecimal checks that the string is an unsigned decimal number, with at
+// least one digit and at most one decimal point.
+func isDecimal(s string) bool {
+	var digits, points int
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '.':
+			points++
+		default:
+			return false
+		}
+	}
+	return digits > 0 && points <= 1
+}
@@ -0,0 +1,184 @@
+package dex
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestMessageRateConversion(t *testing.T) {
+	ui := func(unit string, factor uint64) UnitInfo {
+		return UnitInfo{
+			Conventional: Denomination{
+				Unit:             unit,
+				ConversionFactor: factor,
+			},
+		}
+	}
+	btc, dcr := ui("BTC", 1e8), ui("DCR", 1e8)
+	eth := ui("ETH", 1e9)    // gwei atoms
+	tok18 := ui("TOK", 1e18) // 18-decimal token
+	usdc := ui("USDC", 1e6)
+	odd := ui("ODD", 3) // non power of 10
+
+	tests := []struct {
+		name        string
+		msgRate     uint64
+		base, quote UnitInfo
+		exp         string
+	}{
+		{
+			name:    "same factors",
+			msgRate: 12345678,
+			base:    dcr,
+			quote:   btc,
+			exp:     "0.12345678",
+		},
+		{
+			name:    "integer rate",
+			msgRate: 25e8,
+			base:    dcr,
+			quote:   btc,
+			exp:     "25",
+		},
+		{
+			name:    "zero",
+			msgRate: 0,
+			base:    dcr,
+			quote:   btc,
+			exp:     "0",
+		},
+		{
+			name:    "smallest rate",
+			msgRate: 1,
+			base:    dcr,
+			quote:   btc,
+			exp:     "0.00000001",
+		},
+		{
+			name:    "larger base factor",
+			msgRate: 5_432_100,
+			base:    eth,
+			quote:   btc,
+			exp:     "0.54321",
+		},
+		{
+			name:    "larger quote factor",
+			msgRate: 1_234_567_891,
+			base:    btc,
+			quote:   eth,
+			exp:     "1.234567891",
+		},
+		{
+			name:    "18-decimal quote",
+			msgRate: 1_234_567_891_234_567_891,
+			base:    btc,
+			quote:   tok18,
+			exp:     "1.234567891234567891",
+		},
+		{
+			name:    "18-decimal quote, tiny rate",
+			msgRate: 1,
+			base:    btc,
+			quote:   tok18,
+			exp:     "0.000000000000000001",
+		},
+		{
+			name:    "18-decimal base",
+			msgRate: 3,
+			base:    tok18,
+			quote:   usdc,
+			exp:     "30000",
+		},
+		{
+			name:    "6-decimal base, 18-decimal quote",
+			msgRate: math.MaxUint64,
+			base:    usdc,
+			quote:   tok18,
+			exp:     "0.18446744073709551615",
+		},
+		{
+			name:    "non-terminating",
+			msgRate: 1e8,
+			base:    ui("ONE", 1),
+			quote:   odd,
+			exp:     "0.333333333333333333333333333333",
+		},
+	}
+
+	for _, tt := range tests {
+		s := FormatMessageRate(tt.msgRate, tt.base, tt.quote)
+		if s != tt.exp {
+			t.Fatalf("%s: expected %s, got %s", tt.name, tt.exp, s)
+		}
+		if tt.quote.Conventional.ConversionFactor == 3 {
+			continue // not exactly representable
+		}
+		msgRate, err := ParseMessageRate(tt.exp, tt.base, tt.quote)
+		if err != nil {
+			t.Fatalf("%s: parse error: %v", tt.name, err)
+		}
+		if msgRate != tt.msgRate {
+			t.Fatalf("%s: round trip failed. expected %d, got %d", tt.name, tt.msgRate, msgRate)
+		}
+	}
+
+	// Parse errors.
+	for _, s := range []string{"", ".", "abc", "1.2.3", "-1", "+1", "1e5", "1/3", "0x10", "1,5"} {
+		if _, err := ParseMessageRate(s, dcr, btc); err == nil {
+			t.Fatalf("no error parsing %q", s)
+		}
+	}
+	// Too precise.
+	if _, err := ParseMessageRate("0.000000001", dcr, btc); err == nil {
+		t.Fatal("no error for excess precision")
+	}
+	if _, err := ParseMessageRate("0.0000000000000000000001", btc, tok18); err == nil {
+		t.Fatal("no error for excess precision with an 18-decimal quote")
+	}
+	// Too large.
+	if _, err := ParseMessageRate("1000000000000", btc, tok18); err == nil {
+		t.Fatal("no error for overflow")
+	}
+	// Zero conversion factor.
+	if s := FormatMessageRate(1, btc, ui("ZERO", 0)); s != "NaN" {
+		t.Fatalf("expected NaN for zero conversion factor, got %s", s)
+	}
+	if _, err := ParseMessageRate("1", ui("ZERO", 0), btc); err == nil {
+		t.Fatal("no error for zero conversion factor")
+	}
+	// Leading and trailing decimal points and whitespace are fine.
+	for s, exp := range map[string]uint64{".5": 5e7, "2.": 2e8, " 1.25 ": 1.25e8} {
+		msgRate, err := ParseMessageRate(s, dcr, btc)
+		if err != nil {
+			t.Fatalf("error parsing %q: %v", s, err)
+		}
+		if msgRate != exp {
+			t.Fatalf("wrong rate for %q. expected %d, got %d", s, exp, msgRate)
+		}
+	}
+}
+
+func TestDecimalPlaces(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		want     int
+	}{
+		{0, 1e8, 0},
+		{1e8, 0, 0},
+		{1e8, 1e8, 0},
+		{3e8, 1e8, 0},
+		{1e7, 1e8, 1},
+		{1, 1e8, 8},
+		{25, 1e8, 8},
+		{2500, 1e8, 6},
+		{1, 4, 2},
+		{1, 3, maxRateDecimals},
+		{-1, 8, 3},
+	}
+	for _, tt := range tests {
+		if got := DecimalPlaces(big.NewInt(tt.num), big.NewInt(tt.den)); got != tt.want {
+			t.Errorf("DecimalPlaces(%d, %d) = %d, want %d", tt.num, tt.den, got, tt.want)
+		}
+	}
+}
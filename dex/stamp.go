@@ -0,0 +1,64 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dex
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stamp is a Unix timestamp in milliseconds, which is the resolution of the
+// times in the protocol. It is encoded as a JSON number, like a uint64.
+type Stamp uint64
+
+const (
+	// MinStamp is the earliest valid Stamp, 2019-01-01 00:00:00 UTC. A time
+	// in seconds that is mistaken for milliseconds is before MinStamp.
+	MinStamp Stamp = 1546300800000
+	// maxStampFuture is how far in the future a Stamp may be to be valid. A
+	// time in milliseconds that is converted to milliseconds again is far
+	// beyond it.
+	maxStampFuture = 10 * 365 * 24 * time.Hour
+
+	// ErrInvalidStamp is returned by (Stamp).Validate for a Stamp that is out
+	// of range.
+	ErrInvalidStamp = ErrorKind("invalid timestamp")
+)
+
+// NewStamp converts the time.Time to a Stamp. The zero time.Time and times
+// before the Unix epoch are the zero Stamp.
+func NewStamp(t time.Time) Stamp {
+	if t.IsZero() || t.Before(time.Unix(0, 0)) {
+		return 0
+	}
+	return Stamp(t.UnixMilli())
+}
+
+// StampNow is the Stamp of the current time.
+func StampNow() Stamp {
+	return NewStamp(time.Now())
+}
+
+// Time converts the Stamp to a time.Time. The zero Stamp, which often means
+// that the time is not set, is the zero time.Time.
+func (s Stamp) Time() time.Time {
+	if s == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(s))
+}
+
+// Validate checks that the Stamp is a plausible time, between MinStamp and
+// 10 years from now. This catches times in seconds or microseconds that were
+// mistaken for milliseconds. The zero Stamp is not valid, so callers for which
+// zero is meaningful should check for it first.
+func (s Stamp) Validate() error {
+	if s < MinStamp {
+		return fmt.Errorf("%w: %d is before %d", ErrInvalidStamp, s, MinStamp)
+	}
+	if max := NewStamp(time.Now().Add(maxStampFuture)); s > max {
+		return fmt.Errorf("%w: %d is after %d", ErrInvalidStamp, s, max)
+	}
+	return nil
+}
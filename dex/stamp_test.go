@@ -0,0 +1,56 @@
+package dex
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStamp(t *testing.T) {
+	now := time.Now()
+	s := NewStamp(now)
+	if s != Stamp(now.UnixMilli()) {
+		t.Fatalf("wrong stamp %d for time %d", s, now.UnixMilli())
+	}
+	if !s.Time().Equal(now.Truncate(time.Millisecond)) {
+		t.Fatalf("round trip changed time from %v to %v", now, s.Time())
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("current stamp invalid: %v", err)
+	}
+	if StampNow() < s {
+		t.Fatalf("StampNow before earlier stamp")
+	}
+
+	// Zero values.
+	if NewStamp(time.Time{}) != 0 || NewStamp(time.Unix(-1, 0)) != 0 {
+		t.Fatalf("nonzero stamp for zero or pre-epoch time")
+	}
+	if !Stamp(0).Time().IsZero() {
+		t.Fatalf("nonzero time for zero stamp")
+	}
+
+	tests := []struct {
+		name  string
+		stamp Stamp
+		valid bool
+	}{
+		{"zero", 0, false},
+		{"min", MinStamp, true},
+		{"before min", MinStamp - 1, false},
+		{"seconds", Stamp(now.Unix()), false},
+		{"microseconds", Stamp(now.UnixMicro()), false},
+		{"next year", NewStamp(now.AddDate(1, 0, 0)), true},
+		{"far future", NewStamp(now.AddDate(20, 0, 0)), false},
+	}
+	for _, tt := range tests {
+		err := tt.stamp.Validate()
+		if tt.valid {
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.name, err)
+			}
+		} else if !errors.Is(err, ErrInvalidStamp) {
+			t.Fatalf("%s: expected ErrInvalidStamp, got %v", tt.name, err)
+		}
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/order"
 	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/asset"
+	"decred.org/dcrdex/server/db"
 	dexsrv "decred.org/dcrdex/server/dex"
 	"decred.org/dcrdex/server/market"
 	"github.com/go-chi/chi/v5"
@@ -27,6 +29,10 @@ import (
 const (
 	pongStr   = "pong"
 	maxUInt16 = int(^uint16(0))
+
+	// defaultAnnouncementExpiry is how long an announcement remains active if
+	// no expiry is specified.
+	defaultAnnouncementExpiry = 24 * time.Hour
 )
 
 // writeJSON marshals the provided interface and writes the bytes to the
@@ -105,6 +111,101 @@ func (s *Server) apiAsset(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, res)
 }
 
+// apiAssetGas is the handler for the '/asset/{"assetSymbol"}/gas' API
+// request.
+func (s *Server) apiAssetGas(w http.ResponseWriter, r *http.Request) {
+	assetSymbol := strings.ToLower(chi.URLParam(r, assetSymbol))
+	assetID, found := dex.BipSymbolID(assetSymbol)
+	if !found {
+		http.Error(w, fmt.Sprintf("unknown asset %q", assetSymbol), http.StatusBadRequest)
+		return
+	}
+	backedAsset, err := s.core.Asset(assetID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unsupported asset %q / %d", assetSymbol, assetID), http.StatusBadRequest)
+		return
+	}
+	gr, is := backedAsset.Backend.(asset.GasReporter)
+	if !is {
+		http.Error(w, fmt.Sprintf("gas usage is not recorded for %s", assetSymbol), http.StatusBadRequest)
+		return
+	}
+	res := new(AssetGas)
+	res.Swap, res.Redeem = gr.GasStats()
+	if ab, is := backedAsset.Backend.(asset.AccountBalancer); is {
+		res.SwapSize, res.RedeemSize = ab.InitTxSize(), ab.RedeemSize()
+	}
+	writeJSON(w, res)
+}
+
+// apiAssetNodes is the handler for the '/asset/{"assetSymbol"}/nodes' API
+// request.
+func (s *Server) apiAssetNodes(w http.ResponseWriter, r *http.Request) {
+	assetSymbol := strings.ToLower(chi.URLParam(r, assetSymbol))
+	assetID, found := dex.BipSymbolID(assetSymbol)
+	if !found {
+		http.Error(w, fmt.Sprintf("unknown asset %q", assetSymbol), http.StatusBadRequest)
+		return
+	}
+	backedAsset, err := s.core.Asset(assetID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unsupported asset %q / %d", assetSymbol, assetID), http.StatusBadRequest)
+		return
+	}
+	nr, is := backedAsset.Backend.(asset.NodeConnReporter)
+	if !is {
+		http.Error(w, fmt.Sprintf("node connections are not reported for %s", assetSymbol), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, nr.NodeConnStats())
+}
+
+// apiAssetSwaps is the handler for the
+// '/asset/{"assetSymbol"}/swaps?secrethashes=...&account=...' API request. The
+// secret hashes are a comma-separated list of hex-encoded secret hashes. If
+// account is provided, only swaps involving the account and swaps that are not
+// in the contract are returned.
+func (s *Server) apiAssetSwaps(w http.ResponseWriter, r *http.Request) {
+	assetSymbol := strings.ToLower(chi.URLParam(r, assetSymbol))
+	assetID, found := dex.BipSymbolID(assetSymbol)
+	if !found {
+		http.Error(w, fmt.Sprintf("unknown asset %q", assetSymbol), http.StatusBadRequest)
+		return
+	}
+	backedAsset, err := s.core.Asset(assetID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unsupported asset %q / %d", assetSymbol, assetID), http.StatusBadRequest)
+		return
+	}
+	sl, is := backedAsset.Backend.(asset.ContractSwapLister)
+	if !is {
+		http.Error(w, fmt.Sprintf("contract swaps are not available for %s", assetSymbol), http.StatusBadRequest)
+		return
+	}
+	secretHashesStr := r.URL.Query().Get(secretHashesKey)
+	if secretHashesStr == "" {
+		http.Error(w, "no secret hashes provided", http.StatusBadRequest)
+		return
+	}
+	var secretHashes [][32]byte
+	for _, shStr := range strings.Split(secretHashesStr, ",") {
+		b, err := hex.DecodeString(shStr)
+		if err != nil || len(b) != 32 {
+			http.Error(w, fmt.Sprintf("invalid secret hash %q", shStr), http.StatusBadRequest)
+			return
+		}
+		var secretHash [32]byte
+		copy(secretHash[:], b)
+		secretHashes = append(secretHashes, secretHash)
+	}
+	swaps, err := sl.ContractSwaps(secretHashes, r.URL.Query().Get(accountIDKey))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get contract swaps: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, swaps)
+}
+
 // apiSetFeeScale is the handler for the
 // '/asset/{"assetSymbol"}/setfeescale/{"scaleKey"}' API request.
 func (s *Server) apiSetFeeScale(w http.ResponseWriter, r *http.Request) {
@@ -435,6 +536,200 @@ func (s *Server) apiSuspend(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// apiRetune is the handler for the '/market/{marketName}/retune' API request.
+// The new lot size and rate step are specified with the "lotsize" and
+// "ratestep" queries. If either is omitted, the current value is kept.
+func (s *Server) apiRetune(w http.ResponseWriter, r *http.Request) {
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	status := s.core.MarketStatus(mkt)
+	if status == nil {
+		http.Error(w, fmt.Sprintf("unknown market %q", mkt), http.StatusBadRequest)
+		return
+	}
+
+	lotSize, rateStep := status.LotSize, status.RateStep
+	parseQuery := func(key string, v *uint64) bool {
+		str := r.URL.Query().Get(key)
+		if str == "" {
+			return true
+		}
+		var err error
+		if *v, err = strconv.ParseUint(str, 10, 64); err != nil || *v == 0 {
+			http.Error(w, fmt.Sprintf("invalid %s %q", key, str), http.StatusBadRequest)
+			return false
+		}
+		return true
+	}
+	if !parseQuery("lotsize", &lotSize) || !parseQuery("ratestep", &rateStep) {
+		return
+	}
+	if lotSize == status.LotSize && rateStep == status.RateStep {
+		http.Error(w, "no change to lot size or rate step", http.StatusBadRequest)
+		return
+	}
+
+	epochIdx, err := s.core.RetuneMarket(status.Base, status.Quote, lotSize, rateStep)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to retune market: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, &RetuneResult{
+		Market:   mkt,
+		LotSize:  lotSize,
+		RateStep: rateStep,
+		Epoch:    epochIdx,
+	})
+}
+
+// apiMarketActivity is the handler for the '/market/{marketName}/activity' API
+// request. The optional days query parameter sets how far back to retrieve
+// market activity summaries, with a default of one day.
+func (s *Server) apiMarketActivity(w http.ResponseWriter, r *http.Request) {
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	status := s.core.MarketStatus(mkt)
+	if status == nil {
+		http.Error(w, fmt.Sprintf("unknown market %q", mkt), http.StatusBadRequest)
+		return
+	}
+
+	var days uint64 = 1
+	if daysStr := r.URL.Query().Get(daysKey); daysStr != "" {
+		var err error
+		days, err = strconv.ParseUint(daysStr, 10, 16)
+		if err != nil || days == 0 {
+			http.Error(w, fmt.Sprintf("invalid days %q", daysStr), http.StatusBadRequest)
+			return
+		}
+	}
+
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	acts, err := s.core.MarketActivity(status.Base, status.Quote, since)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to retrieve market activity: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if acts == nil {
+		acts = []*db.MarketActivity{}
+	}
+	writeJSON(w, acts)
+}
+
+// apiReplayEpoch is the handler for the '/market/{marketName}/replay/{epoch}'
+// API request. The epoch's matching is replayed from the recorded book and
+// epoch queue, and compared with the recorded matches. The epoch duration may
+// be specified with the dur query parameter, and defaults to the market's
+// current epoch duration.
+func (s *Server) apiReplayEpoch(w http.ResponseWriter, r *http.Request) {
+	mkt := strings.ToLower(chi.URLParam(r, marketNameKey))
+	status := s.core.MarketStatus(mkt)
+	if status == nil {
+		http.Error(w, fmt.Sprintf("unknown market %q", mkt), http.StatusBadRequest)
+		return
+	}
+
+	epochStr := chi.URLParam(r, epochKey)
+	epochIdx, err := strconv.ParseInt(epochStr, 10, 64)
+	if err != nil || epochIdx < 0 {
+		http.Error(w, fmt.Sprintf("invalid epoch %q", epochStr), http.StatusBadRequest)
+		return
+	}
+
+	epochDur := int64(status.EpochDuration)
+	if durStr := r.URL.Query().Get(durKey); durStr != "" {
+		epochDur, err = strconv.ParseInt(durStr, 10, 64)
+		if err != nil || epochDur <= 0 {
+			http.Error(w, fmt.Sprintf("invalid epoch duration %q", durStr), http.StatusBadRequest)
+			return
+		}
+	}
+
+	replay, err := s.core.ReplayEpoch(status.Base, status.Quote, epochIdx, epochDur)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to replay epoch: %v", err), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, replay)
+}
+
+// apiConsistency is the handler for the '/consistency' API request.
+func (s *Server) apiConsistency(w http.ResponseWriter, _ *http.Request) {
+	report := s.core.ConsistencyReport()
+	if report == nil {
+		http.Error(w, "consistency checks are disabled", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, report)
+}
+
+// apiHealth is the handler for the '/health' API request, which returns the
+// full health report of the server's subsystems.
+func (s *Server) apiHealth(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, s.core.Health())
+}
+
+// apiMatchState is the handler for the '/match/{matchID}' API request, which
+// returns the state of an active match, including the on-chain state of the
+// swap contracts.
+func (s *Server) apiMatchState(w http.ResponseWriter, r *http.Request) {
+	matchID, err := order.DecodeMatchID(chi.URLParam(r, matchIDKey))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	state, err := s.core.MatchState(matchID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get the state of match %v: %v", matchID, err), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, state)
+}
+
+// apiReconcileRefund is the handler for the
+// '/match/{matchID}/reconcilerefund?makerrefund=...&takerrefund=...' API
+// request. The refunds are the hex-encoded coin IDs of the transaction inputs
+// that refund the maker's and taker's swaps. The match is revoked without
+// penalty if the refunds of all of its known swaps are verified on-chain. See
+// swap.(*Swapper).ReconcileRefundedMatch. This is a last resort for stuck
+// matches.
+func (s *Server) apiReconcileRefund(w http.ResponseWriter, r *http.Request) {
+	matchID, err := order.DecodeMatchID(chi.URLParam(r, matchIDKey))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var makerRefund, takerRefund []byte
+	if makerRefund, err = hex.DecodeString(r.URL.Query().Get(makerRefundKey)); err != nil {
+		http.Error(w, fmt.Sprintf("invalid %s: %v", makerRefundKey, err), http.StatusBadRequest)
+		return
+	}
+	if takerRefund, err = hex.DecodeString(r.URL.Query().Get(takerRefundKey)); err != nil {
+		http.Error(w, fmt.Sprintf("invalid %s: %v", takerRefundKey, err), http.StatusBadRequest)
+		return
+	}
+	if err := s.core.ReconcileRefundedMatch(matchID, makerRefund, takerRefund); err != nil {
+		http.Error(w, fmt.Sprintf("failed to reconcile match %v: %v", matchID, err), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, fmt.Sprintf("match %v reconciled as refunded and revoked", matchID))
+}
+
+// apiCancelMatch is the handler for the '/match/{matchID}/cancel' API request,
+// which revokes a match without penalty before any swaps are broadcast. See
+// swap.(*Swapper).CancelMatch. This is a last resort for stuck matches.
+func (s *Server) apiCancelMatch(w http.ResponseWriter, r *http.Request) {
+	matchID, err := order.DecodeMatchID(chi.URLParam(r, matchIDKey))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := s.core.CancelMatch(matchID); err != nil {
+		http.Error(w, fmt.Sprintf("failed to cancel match %v: %v", matchID, err), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, fmt.Sprintf("match %v canceled and revoked", matchID))
+}
+
 // apiEnableDataAPI is the handler for the `/enabledataapi/{yes}` API request,
 // used to enable or disable the HTTP data API.
 func (s *Server) apiEnableDataAPI(w http.ResponseWriter, r *http.Request) {
@@ -661,3 +956,53 @@ func (s *Server) apiNotifyAll(w http.ResponseWriter, r *http.Request) {
 	s.core.NotifyAll(msg)
 	w.WriteHeader(http.StatusOK)
 }
+
+// apiAnnounce is the handler for the '/announce' API request. The request body
+// is the announcement message. The severity is specified with the "severity"
+// query (info, warning, or critical; default info), an optional link with the
+// "link" query, and the expiry time in unix milliseconds with the "expiry"
+// query (default defaultAnnouncementExpiry from now).
+func (s *Server) apiAnnounce(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("unable to read request body: %v", err), http.StatusInternalServerError)
+		return
+	}
+	txt := strings.TrimSuffix(string(body), "\n")
+	if len(txt) == 0 {
+		http.Error(w, "no message to announce", http.StatusBadRequest)
+		return
+	}
+	if len(txt) > maxUInt16 {
+		http.Error(w, fmt.Sprintf("cannot send messages larger than %d bytes", maxUInt16), http.StatusBadRequest)
+		return
+	}
+
+	severity := msgjson.AnnouncementInfo
+	if sevStr := r.URL.Query().Get("severity"); sevStr != "" {
+		severity = strings.ToLower(sevStr)
+	}
+
+	expiry := time.Now().Add(defaultAnnouncementExpiry)
+	if expiryStr := r.URL.Query().Get("expiry"); expiryStr != "" {
+		expiryMs, err := strconv.ParseInt(expiryStr, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid expiry time %q: %v", expiryStr, err), http.StatusBadRequest)
+			return
+		}
+		expiry = time.UnixMilli(expiryMs)
+	}
+
+	ann := &msgjson.Announcement{
+		Severity: severity,
+		Message:  txt,
+		Link:     r.URL.Query().Get("link"),
+		Expiry:   uint64(expiry.UnixMilli()),
+	}
+	if err := s.core.Announce(ann); err != nil {
+		http.Error(w, fmt.Sprintf("unable to announce: %v", err), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, ann)
+}
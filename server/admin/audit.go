@@ -0,0 +1,272 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package admin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/db"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// auditRateLimit is the sustained rate of audited admin requests per
+	// second that are served to each client. Requests in excess of the limit
+	// are refused so that a client cannot flood the audit log.
+	auditRateLimit = 5
+	// auditBurst is the number of audited admin requests that a client may
+	// make in a burst above auditRateLimit.
+	auditBurst = 20
+	// auditLimiterExpiry is how long a client's rate limiter is kept after
+	// its last request.
+	auditLimiterExpiry = 10 * time.Minute
+	// maxAuditActions is the maximum number of actions returned by the
+	// audit log endpoint.
+	maxAuditActions = 1000
+	// redacted replaces the values of sensitive parameters in the audit log.
+	redacted = "<redacted>"
+	// maxAuditBody is the maximum length of a request body recorded in the
+	// audit log. Longer bodies are truncated.
+	maxAuditBody = 1024
+	// maxAuditBodyScan is the maximum length of a request body that is read
+	// for redaction before it is recorded. JSON bodies that are longer cannot
+	// be redacted and are not recorded.
+	maxAuditBodyScan = 1 << 20
+	// auditBodyParam is the parameter under which request bodies are
+	// recorded.
+	auditBodyParam = "body"
+)
+
+// sensitiveParams are substrings of the names of parameters whose values
+// should not be recorded in the audit log.
+var sensitiveParams = []string{"pass", "secret", "seed", "priv", "token", "key"}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitiveParams {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactParam(name, value string) string {
+	if isSensitive(name) {
+		return redacted
+	}
+	return value
+}
+
+// redactJSON replaces the values of sensitive fields of decoded JSON objects.
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, vv := range v {
+			if isSensitive(k) {
+				v[k] = redacted
+			} else {
+				v[k] = redactJSON(vv)
+			}
+		}
+	case []any:
+		for i, vv := range v {
+			v[i] = redactJSON(vv)
+		}
+	}
+	return v
+}
+
+// auditBody reads the request body for the audit log, replacing r.Body so that
+// the handler still receives the complete body. Sensitive fields of JSON
+// bodies are redacted, and JSON bodies that cannot be decoded, e.g. because
+// they are too long to scan, are not recorded. The returned body is truncated
+// to maxAuditBody.
+func auditBody(r *http.Request) string {
+	if r.Body == nil || r.Body == http.NoBody {
+		return ""
+	}
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBodyScan))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+	if err != nil || len(b) == 0 {
+		return ""
+	}
+
+	body := string(b)
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		var v any
+		if err := json.Unmarshal(b, &v); err != nil {
+			return redacted
+		}
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(redactJSON(v)); err != nil {
+			return redacted
+		}
+		body = strings.TrimSuffix(buf.String(), "\n")
+	}
+	if len(body) > maxAuditBody {
+		body = strings.ToValidUTF8(body[:maxAuditBody], "") + fmt.Sprintf("... (%d bytes)", len(b))
+	}
+	return body
+}
+
+// clientLimiter is a client's rate limiter of audited admin requests.
+type clientLimiter struct {
+	*rate.Limiter
+	lastHit time.Time
+}
+
+// auditLimiter limits the rate of audited admin requests of each client, by
+// IP address.
+type auditLimiter struct {
+	mtx      sync.Mutex
+	limiters map[dex.IPKey]*clientLimiter
+}
+
+func newAuditLimiter() *auditLimiter {
+	return &auditLimiter{
+		limiters: make(map[dex.IPKey]*clientLimiter),
+	}
+}
+
+// allow reports whether the client at the remote address may make another
+// audited request. Limiters of clients that have not made a request in
+// auditLimiterExpiry are discarded.
+func (l *auditLimiter) allow(remoteAddr string) bool {
+	ip := dex.NewIPKey(remoteAddr)
+	now := time.Now()
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for k, cl := range l.limiters {
+		if now.Sub(cl.lastHit) > auditLimiterExpiry {
+			delete(l.limiters, k)
+		}
+	}
+	cl := l.limiters[ip]
+	if cl == nil {
+		cl = &clientLimiter{Limiter: rate.NewLimiter(auditRateLimit, auditBurst)}
+		l.limiters[ip] = cl
+	}
+	cl.lastHit = now
+	return cl.Allow()
+}
+
+// auditMiddleware rate limits admin requests by client and records each served
+// request in the audit log with the requesting actor, the route, the route and
+// query parameters, the request body, and the response status. The ping route
+// is neither limited nor recorded.
+func (s *Server) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.TrimPrefix(r.URL.Path, "/api") == "/ping" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !s.auditLimiter.allow(r.RemoteAddr) {
+			log.Warnf("admin request rate limit exceeded by ip: %s", r.RemoteAddr)
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+			return
+		}
+
+		stamp := time.Now()
+		body := auditBody(r)
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		rctx := chi.RouteContext(r.Context())
+		route := r.URL.Path
+		if rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				route = pattern
+			}
+		}
+		route = strings.TrimPrefix(route, "/api")
+
+		params := make(map[string]string)
+		if rctx != nil {
+			for i, k := range rctx.URLParams.Keys {
+				if k == "*" || i >= len(rctx.URLParams.Values) {
+					continue
+				}
+				params[k] = redactParam(k, rctx.URLParams.Values[i])
+			}
+		}
+		for k, vs := range r.URL.Query() {
+			params[k] = redactParam(k, strings.Join(vs, ","))
+		}
+		if body != "" {
+			params[auditBodyParam] = body
+		}
+
+		actor := r.RemoteAddr
+		if user, _, ok := r.BasicAuth(); ok && user != "" {
+			actor = user + "@" + actor
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		act := &db.AdminAction{
+			Stamp:  uint64(stamp.UnixMilli()),
+			Actor:  actor,
+			Action: r.Method + " " + route,
+			Params: params,
+			Status: status,
+		}
+		if err := s.core.RecordAdminAction(act); err != nil {
+			log.Errorf("Failed to record admin action %q by %s: %v", act.Action, actor, err)
+		}
+	})
+}
+
+// apiAuditLog is the handler for the '/auditlog' API request. The n query
+// parameter limits the number of most recent actions returned, and the days
+// query parameter limits the actions to those in the specified number of
+// days.
+func (s *Server) apiAuditLog(w http.ResponseWriter, r *http.Request) {
+	n := maxAuditActions
+	if nStr := r.URL.Query().Get(nKey); nStr != "" {
+		n64, err := strconv.ParseUint(nStr, 10, 16)
+		if err != nil || n64 == 0 || n64 > maxAuditActions {
+			http.Error(w, fmt.Sprintf("invalid n %q, must be 1 to %d", nStr, maxAuditActions), http.StatusBadRequest)
+			return
+		}
+		n = int(n64)
+	}
+	var since time.Time
+	if daysStr := r.URL.Query().Get(daysKey); daysStr != "" {
+		days, err := strconv.ParseUint(daysStr, 10, 16)
+		if err != nil || days == 0 {
+			http.Error(w, fmt.Sprintf("invalid days %q", daysStr), http.StatusBadRequest)
+			return
+		}
+		since = time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	}
+
+	acts, err := s.core.AdminActions(since, n)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to retrieve audit log: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if acts == nil {
+		acts = []*db.AdminAction{}
+	}
+	writeJSON(w, acts)
+}
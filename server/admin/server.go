@@ -24,9 +24,11 @@ import (
 	"decred.org/dcrdex/server/account"
 	"decred.org/dcrdex/server/asset"
 	"decred.org/dcrdex/server/auth"
+	"decred.org/dcrdex/server/consistency"
 	"decred.org/dcrdex/server/db"
 	dexsrv "decred.org/dcrdex/server/dex"
 	"decred.org/dcrdex/server/market"
+	"decred.org/dcrdex/server/swap"
 	"github.com/decred/slog"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -49,6 +51,11 @@ const (
 	nKey               = "n"
 	daysKey            = "days"
 	strengthKey        = "strength"
+	epochKey           = "epoch"
+	durKey             = "dur"
+	makerRefundKey     = "makerrefund"
+	takerRefundKey     = "takerrefund"
+	secretHashesKey    = "secrethashes"
 )
 
 var (
@@ -61,6 +68,7 @@ type SvrCore interface {
 	UserMatchFails(aid account.AccountID, n int) ([]*auth.MatchFail, error)
 	Notify(acctID account.AccountID, msg *msgjson.Message)
 	NotifyAll(msg *msgjson.Message)
+	Announce(ann *msgjson.Announcement) error
 	ConfigMsg() json.RawMessage
 	Asset(id uint32) (*asset.BackedAsset, error)
 	SetFeeRateScale(assetID uint32, scale float64)
@@ -70,6 +78,13 @@ type SvrCore interface {
 	MarketStatuses() map[string]*market.Status
 	SuspendMarket(name string, tSusp time.Time, persistBooks bool) (*market.SuspendEpoch, error)
 	ResumeMarket(name string, asSoonAs time.Time) (startEpoch int64, startTime time.Time, err error)
+	RetuneMarket(base, quote uint32, lotSize, rateStep uint64) (epochIdx int64, err error)
+	MarketActivity(base, quote uint32, since time.Time) ([]*db.MarketActivity, error)
+	ReplayEpoch(base, quote uint32, epochIdx, epochDur int64) (*market.EpochReplay, error)
+	RecordAdminAction(act *db.AdminAction) error
+	AdminActions(since time.Time, n int) ([]*db.AdminAction, error)
+	ConsistencyReport() *consistency.Report
+	Health() *dexsrv.HealthReport
 	ForgiveMatchFail(aid account.AccountID, mid order.MatchID) (forgiven, unbanned bool, err error)
 	AccountMatchOutcomesN(user account.AccountID, n int) ([]*auth.MatchOutcome, error)
 	BookOrders(base, quote uint32) (orders []*order.LimitOrder, err error)
@@ -77,6 +92,9 @@ type SvrCore interface {
 	MarketMatchesStreaming(base, quote uint32, includeInactive bool, N int64, f func(*dexsrv.MatchData) error) (int, error)
 	EnableDataAPI(yes bool)
 	CreatePrepaidBonds(n int, strength uint32, durSecs int64) ([][]byte, error)
+	MatchState(mid order.MatchID) (*swap.MatchState, error)
+	ReconcileRefundedMatch(mid order.MatchID, makerRefund, takerRefund []byte) error
+	CancelMatch(mid order.MatchID) error
 }
 
 // Server is a multi-client https server.
@@ -86,6 +104,9 @@ type Server struct {
 	tlsConfig *tls.Config
 	srv       *http.Server
 	authSHA   [32]byte
+	// auditLimiter limits the rate of each client's requests that are served
+	// and recorded in the audit log.
+	auditLimiter *auditLimiter
 }
 
 // SrvConfig holds variables needed to create a new Server.
@@ -137,6 +158,8 @@ func NewServer(cfg *SrvConfig) (*Server, error) {
 		addr:      cfg.Addr,
 		tlsConfig: tlsConfig,
 		authSHA:   cfg.AuthSHA,
+
+		auditLimiter: newAuditLimiter(),
 	}
 
 	// Middleware
@@ -148,6 +171,7 @@ func NewServer(cfg *SrvConfig) (*Server, error) {
 	// api endpoints
 	mux.Route("/api", func(r chi.Router) {
 		r.Use(middleware.AllowContentType("text/plain"))
+		r.Use(s.auditMiddleware)
 		r.Get("/ping", apiPing)
 		r.Get("/config", s.apiConfig)
 		r.Get("/enabledataapi/{"+yesKey+"}", s.apiEnableDataAPI)
@@ -161,8 +185,12 @@ func NewServer(cfg *SrvConfig) (*Server, error) {
 		r.Route("/asset/{"+assetSymbol+"}", func(rm chi.Router) {
 			rm.Get("/", s.apiAsset)
 			rm.Get("/setfeescale/{"+scaleKey+"}", s.apiSetFeeScale)
+			rm.Get("/gas", s.apiAssetGas)
+			rm.Get("/nodes", s.apiAssetNodes)
+			rm.Get("/swaps", s.apiAssetSwaps)
 		})
 		r.Post("/notifyall", s.apiNotifyAll)
+		r.Post("/announce", s.apiAnnounce)
 		r.Get("/markets", s.apiMarkets)
 		r.Route("/market/{"+marketNameKey+"}", func(rm chi.Router) {
 			rm.Get("/", s.apiMarketInfo)
@@ -171,8 +199,19 @@ func NewServer(cfg *SrvConfig) (*Server, error) {
 			rm.Get("/matches", s.apiMarketMatches)
 			rm.Get("/suspend", s.apiSuspend)
 			rm.Get("/resume", s.apiResume)
+			rm.Get("/retune", s.apiRetune)
+			rm.Get("/activity", s.apiMarketActivity)
+			rm.Get("/replay/{"+epochKey+"}", s.apiReplayEpoch)
 		})
 		r.Get("/prepaybonds", s.prepayBonds)
+		r.Get("/consistency", s.apiConsistency)
+		r.Get("/health", s.apiHealth)
+		r.Route("/match/{"+matchIDKey+"}", func(rm chi.Router) {
+			rm.Get("/", s.apiMatchState)
+			rm.Get("/reconcilerefund", s.apiReconcileRefund)
+			rm.Get("/cancel", s.apiCancelMatch)
+		})
+		r.Get("/auditlog", s.apiAuditLog)
 	})
 
 	return s, nil
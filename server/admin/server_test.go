@@ -29,9 +29,11 @@ import (
 	"decred.org/dcrdex/server/account"
 	"decred.org/dcrdex/server/asset"
 	"decred.org/dcrdex/server/auth"
+	"decred.org/dcrdex/server/consistency"
 	"decred.org/dcrdex/server/db"
 	dexsrv "decred.org/dcrdex/server/dex"
 	"decred.org/dcrdex/server/market"
+	"decred.org/dcrdex/server/swap"
 	"github.com/decred/dcrd/certgen"
 	"github.com/decred/slog"
 	"github.com/go-chi/chi/v5"
@@ -51,6 +53,14 @@ type TMarket struct {
 	resumeEpoch int64
 	resumeTime  time.Time
 	persist     bool
+	base, quote uint32
+	lotSize     uint64
+	rateStep    uint64
+	retuneErr   error
+	activity    []*db.MarketActivity
+	activityErr error
+	replay      *market.EpochReplay
+	replayErr   error
 }
 
 type TCore struct {
@@ -68,6 +78,46 @@ type TCore struct {
 	marketMatches    []*dexsrv.MatchData
 	marketMatchesErr error
 	dataEnabled      uint32
+	consistency      *consistency.Report
+	health           *dexsrv.HealthReport
+	announcement     *msgjson.Announcement
+	announceErr      error
+	matchState       *swap.MatchState
+	matchErr         error
+	makerRefund      []byte
+	takerRefund      []byte
+
+	auditMtx sync.Mutex
+	auditLog []*db.AdminAction
+	auditErr error
+}
+
+func (c *TCore) RecordAdminAction(act *db.AdminAction) error {
+	c.auditMtx.Lock()
+	defer c.auditMtx.Unlock()
+	if c.auditErr != nil {
+		return c.auditErr
+	}
+	c.auditLog = append(c.auditLog, act)
+	return nil
+}
+
+func (c *TCore) AdminActions(since time.Time, n int) ([]*db.AdminAction, error) {
+	c.auditMtx.Lock()
+	defer c.auditMtx.Unlock()
+	if c.auditErr != nil {
+		return nil, c.auditErr
+	}
+	var acts []*db.AdminAction
+	for _, act := range c.auditLog {
+		if act.Stamp > uint64(since.UnixMilli()) {
+			acts = append(acts, act)
+		}
+	}
+	if n > 0 && len(acts) > n {
+		acts = acts[len(acts)-n:]
+	}
+	return acts, nil
 }
 
 func (c *TCore) ConfigMsg() json.RawMessage { return nil }
@@ -97,6 +147,40 @@ func (c *TCore) SuspendMarket(name string, tSusp time.Time, persistBooks bool) (
 	return tMkt.suspend, nil
 }
 
+func (c *TCore) RetuneMarket(base, quote uint32, lotSize, rateStep uint64) (epochIdx int64, err error) {
+	name, _ := dex.MarketName(base, quote)
+	tMkt := c.markets[name]
+	if tMkt == nil {
+		return 0, fmt.Errorf("unknown market %s", name)
+	}
+	if tMkt.retuneErr != nil {
+		return 0, tMkt.retuneErr
+	}
+	tMkt.lotSize, tMkt.rateStep = lotSize, rateStep
+	return tMkt.activeEpoch + 1, nil
+}
+
+func (c *TCore) MarketActivity(base, quote uint32, since time.Time) ([]*db.MarketActivity, error) {
+	name, _ := dex.MarketName(base, quote)
+	tMkt := c.markets[name]
+	if tMkt == nil {
+		return nil, fmt.Errorf("unknown market %s", name)
+	}
+	if tMkt.activityErr != nil {
+		return nil, tMkt.activityErr
+	}
+	var acts []*db.MarketActivity
+	for _, act := range tMkt.activity {
+		if act.EndStamp > uint64(since.UnixMilli()) {
+			acts = append(acts, act)
+		}
+	}
+	return acts, nil
+}
+
+func (c *TCore) ConsistencyReport() *consistency.Report { return c.consistency }
+func (c *TCore) Health() *dexsrv.HealthReport           { return c.health }
+
 func (c *TCore) market(name string) *TMarket {
 	if c.markets == nil {
 		return nil
@@ -120,6 +204,10 @@ func (c *TCore) MarketStatus(mktName string) *market.Status {
 		StartEpoch:    mkt.startEpoch,
 		SuspendEpoch:  suspendEpoch,
 		PersistBook:   mkt.persist,
+		Base:          mkt.base,
+		Quote:         mkt.quote,
+		LotSize:       mkt.lotSize,
+		RateStep:      mkt.rateStep,
 	}
 }
 
@@ -214,6 +302,16 @@ func (c *TCore) Unban(_ account.AccountID) error {
 func (c *TCore) ForgiveMatchFail(_ account.AccountID, _ order.MatchID) (bool, bool, error) {
 	return false, false, nil // TODO: tests
 }
+func (c *TCore) MatchState(order.MatchID) (*swap.MatchState, error) {
+	return c.matchState, c.matchErr
+}
+func (c *TCore) ReconcileRefundedMatch(_ order.MatchID, makerRefund, takerRefund []byte) error {
+	c.makerRefund, c.takerRefund = makerRefund, takerRefund
+	return c.matchErr
+}
+func (c *TCore) CancelMatch(order.MatchID) error {
+	return c.matchErr
+}
 func (c *TCore) CreatePrepaidBonds(n int, strength uint32, durSecs int64) ([][]byte, error) {
 	return nil, nil
 }
@@ -222,6 +320,10 @@ func (c *TCore) AccountMatchOutcomesN(user account.AccountID, n int) ([]*auth.Ma
 }
 func (c *TCore) Notify(_ account.AccountID, _ *msgjson.Message) {}
 func (c *TCore) NotifyAll(_ *msgjson.Message)                   {}
+func (c *TCore) Announce(ann *msgjson.Announcement) error {
+	c.announcement = ann
+	return c.announceErr
+}
 
 // genCertPair generates a key/cert pair to the paths provided.
 func genCertPair(certFile, keyFile string) error {
@@ -1095,6 +1197,190 @@ func TestAuthMiddleware(t *testing.T) {
 	}
 }
 
+func TestAuditLog(t *testing.T) {
+	pass := "password123"
+	s, _ := newTServer(t, false, sha256.Sum256([]byte(pass)))
+	core := s.core.(*TCore)
+	core.markets = map[string]*TMarket{
+		"dcr_btc": {
+			running: true,
+			dur:     60_000,
+			base:    42,
+			quote:   0,
+			suspend: &market.SuspendEpoch{},
+		},
+	}
+
+	request := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "https://localhost"+path, nil)
+		r.RemoteAddr = "127.0.0.1:1234"
+		r.SetBasicAuth("alice", pass)
+		s.srv.Handler.ServeHTTP(w, r)
+		return w
+	}
+	lastAction := func() *db.AdminAction {
+		t.Helper()
+		core.auditMtx.Lock()
+		defer core.auditMtx.Unlock()
+		if len(core.auditLog) == 0 {
+			t.Fatalf("no audit records")
+		}
+		return core.auditLog[len(core.auditLog)-1]
+	}
+	numActions := func() int {
+		core.auditMtx.Lock()
+		defer core.auditMtx.Unlock()
+		return len(core.auditLog)
+	}
+
+	// Suspending a market is recorded with the actor, route, parameters and
+	// status.
+	start := uint64(time.Now().UnixMilli())
+	if w := request("/api/market/dcr_btc/suspend?persist=false"); w.Code != http.StatusOK {
+		t.Fatalf("suspend returned code %d: %s", w.Code, w.Body.String())
+	}
+	act := lastAction()
+	expParams := map[string]string{"market": "dcr_btc", "persist": "false"}
+	if act.Actor != "alice@127.0.0.1:1234" || act.Action != "GET /market/{market}/suspend" ||
+		act.Status != http.StatusOK || act.Stamp < start || !reflect.DeepEqual(act.Params, expParams) {
+		t.Fatalf("wrong audit record %+v", act)
+	}
+
+	// Failed actions are recorded with the error status.
+	if w := request("/api/market/xyz_abc/suspend"); w.Code != http.StatusBadRequest {
+		t.Fatalf("suspend returned code %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+	if act = lastAction(); act.Status != http.StatusBadRequest || act.Params["market"] != "xyz_abc" {
+		t.Fatalf("wrong audit record %+v", act)
+	}
+
+	// Sensitive parameters are redacted.
+	request("/api/market/dcr_btc/activity?apiKey=abc&password=def&days=1")
+	act = lastAction()
+	if act.Params["apiKey"] != redacted || act.Params["password"] != redacted || act.Params["days"] != "1" {
+		t.Fatalf("parameters not redacted %+v", act.Params)
+	}
+
+	// Pings are not recorded.
+	n := numActions()
+	if w := request("/api/ping"); w.Code != http.StatusOK {
+		t.Fatalf("ping returned code %d", w.Code)
+	}
+	if numActions() != n {
+		t.Fatalf("ping recorded")
+	}
+
+	// A failure to record the action does not fail the request.
+	core.auditErr = errors.New("test error")
+	if w := request("/api/market/dcr_btc/suspend"); w.Code != http.StatusOK {
+		t.Fatalf("suspend returned code %d", w.Code)
+	}
+	core.auditErr = nil
+
+	// The audit log endpoint.
+	w := request("/api/auditlog?n=2&days=1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("auditlog returned code %d", w.Code)
+	}
+	var acts []*db.AdminAction
+	if err := json.Unmarshal(w.Body.Bytes(), &acts); err != nil {
+		t.Fatalf("failed to unmarshal audit log: %v", err)
+	}
+	if len(acts) != 2 || acts[0].Status != http.StatusBadRequest || acts[1].Action != "GET /market/{market}/activity" {
+		t.Fatalf("wrong audit log %+v", acts)
+	}
+	if act = lastAction(); act.Action != "GET /auditlog" || act.Params["n"] != "2" {
+		t.Fatalf("audit log request not recorded %+v", act)
+	}
+	for _, query := range []string{"?n=0", "?n=abc", "?n=1001", "?days=0", "?days=abc"} {
+		if w := request("/api/auditlog" + query); w.Code != http.StatusBadRequest {
+			t.Fatalf("%q: auditlog returned code %d, expected %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	// Request bodies are recorded, with sensitive JSON fields redacted.
+	post := func(path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodPost, "https://localhost"+path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "text/plain")
+		r.RemoteAddr = "127.0.0.1:1234"
+		r.SetBasicAuth("alice", pass)
+		s.srv.Handler.ServeHTTP(w, r)
+		return w
+	}
+	if w := post("/api/announce", "maintenance soon"); w.Code != http.StatusOK {
+		t.Fatalf("announce returned code %d: %s", w.Code, w.Body.String())
+	}
+	if act = lastAction(); act.Action != "POST /announce" || act.Params[auditBodyParam] != "maintenance soon" {
+		t.Fatalf("wrong audit record %+v", act)
+	}
+	if core.announcement == nil || core.announcement.Message != "maintenance soon" {
+		t.Fatalf("handler did not receive the body: %+v", core.announcement)
+	}
+	post("/api/announce", `{"msg":"hi","nested":{"privKey":"abc"},"password":"def"}`)
+	if body := lastAction().Params[auditBodyParam]; body != `{"msg":"hi","nested":{"privKey":"<redacted>"},"password":"<redacted>"}` {
+		t.Fatalf("body not redacted: %s", body)
+	}
+	post("/api/announce", `{"password":"def"`)
+	if body := lastAction().Params[auditBodyParam]; body != redacted {
+		t.Fatalf("undecodable JSON body recorded: %s", body)
+	}
+	longMsg := strings.Repeat("a", 2*maxAuditBody)
+	post("/api/announce", longMsg)
+	if body := lastAction().Params[auditBodyParam]; !strings.HasPrefix(body, longMsg[:maxAuditBody]) || len(body) >= len(longMsg) {
+		t.Fatalf("long body not truncated: %d bytes", len(body))
+	}
+	if core.announcement.Message != longMsg {
+		t.Fatalf("handler did not receive the full body")
+	}
+
+	// Requests in excess of a client's rate limit are refused and not
+	// recorded. Other clients are not limited.
+	s.auditLimiter = newAuditLimiter()
+	for i := 0; i < auditBurst; i++ {
+		if w := request("/api/market/dcr_btc/suspend"); w.Code != http.StatusOK {
+			t.Fatalf("suspend %d returned code %d", i, w.Code)
+		}
+	}
+	n = numActions()
+	if w := request("/api/market/dcr_btc/suspend"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("rate limited request returned code %d, expected %d", w.Code, http.StatusTooManyRequests)
+	}
+	if numActions() != n {
+		t.Fatalf("rate limited request recorded")
+	}
+	if w := request("/api/ping"); w.Code != http.StatusOK {
+		t.Fatalf("rate limited ping returned code %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "https://localhost/api/market/dcr_btc/suspend", nil)
+	r.RemoteAddr = "127.0.0.2:1234"
+	r.SetBasicAuth("bob", pass)
+	s.srv.Handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("other client's request returned code %d", w.Code)
+	}
+	if numActions() != n+1 {
+		t.Fatalf("other client's request not recorded")
+	}
+
+	// Limiters of idle clients are discarded.
+	s.auditLimiter.mtx.Lock()
+	for _, cl := range s.auditLimiter.limiters {
+		cl.lastHit = time.Now().Add(-auditLimiterExpiry - time.Second)
+	}
+	s.auditLimiter.mtx.Unlock()
+	if w := request("/api/market/dcr_btc/suspend"); w.Code != http.StatusOK {
+		t.Fatalf("request after limiter expiry returned code %d", w.Code)
+	}
+	if len(s.auditLimiter.limiters) != 1 {
+		t.Fatalf("idle client limiters not discarded, have %d", len(s.auditLimiter.limiters))
+	}
+}
+
 func TestAccountInfo(t *testing.T) {
 	core := new(TCore)
 	srv := &Server{
@@ -1324,6 +1610,89 @@ func TestNotifyAll(t *testing.T) {
 	}
 }
 
+func TestAnnounce(t *testing.T) {
+	core := new(TCore)
+	srv := &Server{
+		core: core,
+	}
+	mux := chi.NewRouter()
+	mux.Post("/announce", srv.apiAnnounce)
+
+	expiry := time.Now().Add(time.Hour).UnixMilli()
+	tests := []struct {
+		name, txt, query string
+		announceErr      error
+		wantCode         int
+		wantAnn          *msgjson.Announcement
+	}{{
+		name:     "ok defaults",
+		txt:      "Maintenance at noon.\n",
+		wantCode: http.StatusOK,
+		wantAnn: &msgjson.Announcement{
+			Severity: msgjson.AnnouncementInfo,
+			Message:  "Maintenance at noon.",
+		},
+	}, {
+		name:     "ok all fields",
+		txt:      "Maintenance at noon.",
+		query:    fmt.Sprintf("?severity=Critical&link=https://example.com&expiry=%d", expiry),
+		wantCode: http.StatusOK,
+		wantAnn: &msgjson.Announcement{
+			Severity: msgjson.AnnouncementCritical,
+			Message:  "Maintenance at noon.",
+			Link:     "https://example.com",
+			Expiry:   uint64(expiry),
+		},
+	}, {
+		name:     "no message",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "message too long",
+		txt:      string(make([]byte, maxUInt16+1)),
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:     "bad expiry",
+		txt:      "hi",
+		query:    "?expiry=soon",
+		wantCode: http.StatusBadRequest,
+	}, {
+		name:        "announce error",
+		txt:         "hi",
+		announceErr: errors.New("bad severity"),
+		wantCode:    http.StatusBadRequest,
+	}}
+	for _, test := range tests {
+		core.announcement = nil
+		core.announceErr = test.announceErr
+		w := httptest.NewRecorder()
+		br := bytes.NewReader([]byte(test.txt))
+		r, _ := http.NewRequest("POST", "https://localhost/announce"+test.query, br)
+		r.RemoteAddr = "localhost"
+
+		mux.ServeHTTP(w, r)
+
+		if w.Code != test.wantCode {
+			t.Fatalf("%q: apiAnnounce returned code %d, expected %d", test.name, w.Code, test.wantCode)
+		}
+		if test.wantAnn == nil {
+			continue
+		}
+		ann := core.announcement
+		if ann == nil {
+			t.Fatalf("%q: no announcement", test.name)
+		}
+		if test.wantAnn.Expiry == 0 { // default expiry
+			if ann.Expiry < uint64(time.Now().Add(defaultAnnouncementExpiry-time.Minute).UnixMilli()) {
+				t.Fatalf("%q: wrong default expiry %d", test.name, ann.Expiry)
+			}
+			test.wantAnn.Expiry = ann.Expiry
+		}
+		if *ann != *test.wantAnn {
+			t.Fatalf("%q: wrong announcement %+v, expected %+v", test.name, ann, test.wantAnn)
+		}
+	}
+}
+
 func TestEnableDataAPI(t *testing.T) {
 	core := new(TCore)
 	srv := &Server{
@@ -1374,3 +1743,382 @@ func TestEnableDataAPI(t *testing.T) {
 	}
 
 }
+
+func TestRetune(t *testing.T) {
+	core := &TCore{
+		markets: make(map[string]*TMarket),
+	}
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Get("/market/{"+marketNameKey+"}/retune", srv.apiRetune)
+
+	name := "dcr_btc"
+	retune := func(query string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "https://localhost/market/"+name+"/retune"+query, nil)
+		r.RemoteAddr = "localhost"
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	// Non-existent market
+	if w := retune("?lotsize=2000"); w.Code != http.StatusBadRequest {
+		t.Fatalf("apiRetune returned code %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	tMkt := &TMarket{
+		base:        42,
+		quote:       0,
+		lotSize:     1000,
+		rateStep:    100,
+		activeEpoch: 10,
+	}
+	core.markets[name] = tMkt
+
+	for _, query := range []string{"", "?lotsize=1000&ratestep=100", "?lotsize=abc", "?ratestep=0"} {
+		if w := retune(query); w.Code != http.StatusBadRequest {
+			t.Fatalf("%q: apiRetune returned code %d, expected %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	tMkt.retuneErr = errors.New("test error")
+	if w := retune("?lotsize=2000"); w.Code != http.StatusBadRequest {
+		t.Fatalf("apiRetune returned code %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+	tMkt.retuneErr = nil
+
+	// Change only the lot size.
+	w := retune("?lotsize=2000")
+	if w.Code != http.StatusOK {
+		t.Fatalf("apiRetune returned code %d, expected %d", w.Code, http.StatusOK)
+	}
+	res := new(RetuneResult)
+	if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+		t.Fatalf("Failed to unmarshal result: %v", err)
+	}
+	exp := RetuneResult{Market: name, LotSize: 2000, RateStep: 100, Epoch: 11}
+	if *res != exp {
+		t.Fatalf("wrong result %+v, expected %+v", res, exp)
+	}
+	if tMkt.lotSize != 2000 || tMkt.rateStep != 100 {
+		t.Fatalf("market not retuned")
+	}
+}
+
+func (c *TCore) ReplayEpoch(base, quote uint32, epochIdx, epochDur int64) (*market.EpochReplay, error) {
+	name, _ := dex.MarketName(base, quote)
+	tMkt := c.markets[name]
+	if tMkt == nil {
+		return nil, fmt.Errorf("unknown market %s", name)
+	}
+	if tMkt.replayErr != nil {
+		return nil, tMkt.replayErr
+	}
+	if tMkt.replay == nil || tMkt.replay.Idx != epochIdx || tMkt.replay.Dur != epochDur {
+		return nil, fmt.Errorf("unknown epoch %d, duration %d", epochIdx, epochDur)
+	}
+	return tMkt.replay, nil
+}
+
+func TestMarketActivity(t *testing.T) {
+	core := &TCore{
+		markets: make(map[string]*TMarket),
+	}
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Get("/market/{"+marketNameKey+"}/activity", srv.apiMarketActivity)
+
+	name := "dcr_btc"
+	activity := func(query string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "https://localhost/market/"+name+"/activity"+query, nil)
+		r.RemoteAddr = "localhost"
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	// Non-existent market
+	if w := activity(""); w.Code != http.StatusBadRequest {
+		t.Fatalf("apiMarketActivity returned code %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	const hour = uint64(time.Hour / time.Millisecond)
+	now := uint64(time.Now().UnixMilli())
+	lastHour := now - now%hour
+	tMkt := &TMarket{
+		base:  42,
+		quote: 0,
+		activity: []*db.MarketActivity{
+			{EndStamp: lastHour - 48*hour, Duration: hour, Orders: 1},
+			{EndStamp: lastHour - hour, Duration: hour, Orders: 2, Accounts: 2},
+			{EndStamp: lastHour, Duration: hour, Orders: 3, Matches: 1, MatchVolume: 1e8, Accounts: 2},
+		},
+	}
+	core.markets[name] = tMkt
+
+	for _, query := range []string{"?days=0", "?days=abc", "?days=-1"} {
+		if w := activity(query); w.Code != http.StatusBadRequest {
+			t.Fatalf("%q: apiMarketActivity returned code %d, expected %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	tMkt.activityErr = errors.New("test error")
+	if w := activity(""); w.Code != http.StatusInternalServerError {
+		t.Fatalf("apiMarketActivity returned code %d, expected %d", w.Code, http.StatusInternalServerError)
+	}
+	tMkt.activityErr = nil
+
+	for query, expN := range map[string]int{"": 2, "?days=3": 3} {
+		w := activity(query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: apiMarketActivity returned code %d, expected %d", query, w.Code, http.StatusOK)
+		}
+		var acts []*db.MarketActivity
+		if err := json.Unmarshal(w.Body.Bytes(), &acts); err != nil {
+			t.Fatalf("%q: failed to unmarshal result: %v", query, err)
+		}
+		if len(acts) != expN {
+			t.Fatalf("%q: expected %d summaries, got %d", query, expN, len(acts))
+		}
+		if last := acts[len(acts)-1]; *last != *tMkt.activity[2] {
+			t.Fatalf("%q: wrong summary %+v", query, last)
+		}
+	}
+
+	// No activity is an empty array.
+	tMkt.activity = nil
+	w := activity("")
+	if w.Code != http.StatusOK {
+		t.Fatalf("apiMarketActivity returned code %d, expected %d", w.Code, http.StatusOK)
+	}
+	if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+		t.Fatalf("expected empty array, got %s", body)
+	}
+}
+
+func TestReplayEpoch(t *testing.T) {
+	core := &TCore{
+		markets: make(map[string]*TMarket),
+	}
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Get("/market/{"+marketNameKey+"}/replay/{"+epochKey+"}", srv.apiReplayEpoch)
+
+	name := "dcr_btc"
+	replay := func(epoch string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "https://localhost/market/"+name+"/replay/"+epoch, nil)
+		r.RemoteAddr = "localhost"
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	// Non-existent market
+	if w := replay("10"); w.Code != http.StatusBadRequest {
+		t.Fatalf("apiReplayEpoch returned code %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	var mid order.MatchID
+	mid[0] = 1
+	tMkt := &TMarket{
+		base:  42,
+		quote: 0,
+		dur:   60_000,
+		replay: &market.EpochReplay{
+			Idx:       10,
+			Dur:       60_000,
+			Seed:      []byte{2},
+			Matches:   []*market.ReplayedMatch{{ID: mid, Quantity: 1e8, Rate: 1e6}},
+			Identical: true,
+			SeedMatch: true,
+		},
+	}
+	core.markets[name] = tMkt
+
+	for _, epoch := range []string{"abc", "-1", "10?dur=0", "10?dur=abc"} {
+		if w := replay(epoch); w.Code != http.StatusBadRequest {
+			t.Fatalf("%q: apiReplayEpoch returned code %d, expected %d", epoch, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	// Unknown epoch duration.
+	if w := replay("10?dur=30000"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("apiReplayEpoch returned code %d, expected %d", w.Code, http.StatusInternalServerError)
+	}
+
+	tMkt.replayErr = errors.New("test error")
+	if w := replay("10"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("apiReplayEpoch returned code %d, expected %d", w.Code, http.StatusInternalServerError)
+	}
+	tMkt.replayErr = nil
+
+	for _, epoch := range []string{"10", "10?dur=60000"} {
+		w := replay(epoch)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%q: apiReplayEpoch returned code %d, expected %d", epoch, w.Code, http.StatusOK)
+		}
+		var res struct {
+			Idx       int64 `json:"idx"`
+			Identical bool  `json:"identical"`
+			Matches   []struct {
+				ID   string `json:"id"`
+				Rate uint64 `json:"rate"`
+			} `json:"matches"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("%q: failed to unmarshal result: %v", epoch, err)
+		}
+		if res.Idx != 10 || !res.Identical || len(res.Matches) != 1 ||
+			res.Matches[0].ID != mid.String() || res.Matches[0].Rate != 1e6 {
+			t.Fatalf("%q: wrong replay result %+v", epoch, res)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	core := &TCore{
+		health: &dexsrv.HealthReport{
+			Status: dexsrv.Degraded,
+			Issues: []string{"btc backend not synced"},
+			Assets: []*dexsrv.AssetHealth{{Symbol: "btc", Connected: true}},
+		},
+	}
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Get("/health", srv.apiHealth)
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "https://localhost/health", nil)
+	r.RemoteAddr = "localhost"
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("apiHealth returned code %d, expected %d", w.Code, http.StatusOK)
+	}
+	var report dexsrv.HealthReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to unmarshal health report: %v", err)
+	}
+	if report.Status != dexsrv.Degraded || len(report.Issues) != 1 || len(report.Assets) != 1 {
+		t.Fatalf("wrong health report %+v", report)
+	}
+}
+
+func TestConsistency(t *testing.T) {
+	core := new(TCore)
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Get("/consistency", srv.apiConsistency)
+
+	consistencyReport := func() *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "https://localhost/consistency", nil)
+		r.RemoteAddr = "localhost"
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	// Disabled
+	if w := consistencyReport(); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("apiConsistency returned code %d, expected %d", w.Code, http.StatusServiceUnavailable)
+	}
+
+	core.consistency = &consistency.Report{
+		Checks:        2,
+		Discrepancies: 1,
+		Recent: []*consistency.Discrepancy{{
+			Market:  "dcr_btc",
+			MatchID: order.MatchID{1},
+			Status:  order.TakerSwapCast,
+			Kind:    consistency.MissingCoinID,
+			Details: "no taker swap coin recorded",
+		}},
+	}
+	w := consistencyReport()
+	if w.Code != http.StatusOK {
+		t.Fatalf("apiConsistency returned code %d, expected %d", w.Code, http.StatusOK)
+	}
+	var report struct {
+		Checks        uint64 `json:"checks"`
+		Discrepancies uint64 `json:"discrepancies"`
+		Recent        []struct {
+			MatchID string                      `json:"matchID"`
+			Kind    consistency.DiscrepancyKind `json:"kind"`
+		} `json:"recent"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to unmarshal result: %v", err)
+	}
+	if report.Checks != 2 || report.Discrepancies != 1 || len(report.Recent) != 1 {
+		t.Fatalf("wrong report %+v", report)
+	}
+	if d := report.Recent[0]; d.MatchID != (order.MatchID{1}).String() || d.Kind != consistency.MissingCoinID {
+		t.Fatalf("wrong discrepancy %+v", d)
+	}
+}
+
+func TestMatchAdmin(t *testing.T) {
+	core := new(TCore)
+	srv := &Server{
+		core: core,
+	}
+
+	mux := chi.NewRouter()
+	mux.Route("/match/{"+matchIDKey+"}", func(rm chi.Router) {
+		rm.Get("/", srv.apiMatchState)
+		rm.Get("/reconcilerefund", srv.apiReconcileRefund)
+		rm.Get("/cancel", srv.apiCancelMatch)
+	})
+
+	mid := order.MatchID{0x01}
+	request := func(path string) *httptest.ResponseRecorder {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest(http.MethodGet, "https://localhost/match/"+path, nil)
+		r.RemoteAddr = "localhost"
+		mux.ServeHTTP(w, r)
+		return w
+	}
+	ensureCode := func(tag, path string, wantCode int) {
+		t.Helper()
+		if w := request(path); w.Code != wantCode {
+			t.Fatalf("%s: wanted code %d, got %d, body: %s", tag, wantCode, w.Code, w.Body.String())
+		}
+	}
+
+	core.matchState = &swap.MatchState{MatchID: mid, Status: order.MakerSwapCast.String()}
+	ensureCode("state", mid.String()+"/", http.StatusOK)
+	ensureCode("bad match ID", "abc/", http.StatusBadRequest)
+
+	ensureCode("cancel", mid.String()+"/cancel", http.StatusOK)
+
+	makerRefund := []byte{0x02, 0x03}
+	ensureCode("reconcile", mid.String()+"/reconcilerefund?makerrefund="+hex.EncodeToString(makerRefund), http.StatusOK)
+	if !bytes.Equal(core.makerRefund, makerRefund) || len(core.takerRefund) != 0 {
+		t.Fatalf("wrong refunds %x, %x", core.makerRefund, core.takerRefund)
+	}
+	ensureCode("bad refund", mid.String()+"/reconcilerefund?takerrefund=xyz", http.StatusBadRequest)
+
+	core.matchErr = errors.New("refund not confirmed")
+	ensureCode("state error", mid.String()+"/", http.StatusBadRequest)
+	ensureCode("cancel error", mid.String()+"/cancel", http.StatusBadRequest)
+	ensureCode("reconcile error", mid.String()+"/reconcilerefund?makerrefund=02", http.StatusBadRequest)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/asset"
 )
 
 // AssetPost is the expected structure of the asset POST data.
@@ -25,6 +26,16 @@ type AssetInfo struct {
 	Errors         []string `json:"errors,omitempty"`
 }
 
+// AssetGas is the result of the asset gas GET. The observed gas stats are for
+// recent transactions with a single initiation or redemption, and can be
+// compared to the gas for a single swap and redemption in use by the server.
+type AssetGas struct {
+	SwapSize   uint64          `json:"swapSize"`
+	RedeemSize uint64          `json:"redeemSize"`
+	Swap       *asset.GasStats `json:"swap,omitempty"`
+	Redeem     *asset.GasStats `json:"redeem,omitempty"`
+}
+
 // MarketStatus summarizes the operational status of a market.
 type MarketStatus struct {
 	Name          string `json:"market,omitempty"`
@@ -82,6 +93,16 @@ type ResumeResult struct {
 	StartTime  APITime `json:"starttime"`
 }
 
+// RetuneResult is the result of a market retune request. The new lot size and
+// rate step apply starting with Epoch. Epoch is zero if the market is not
+// running, in which case the change takes effect when the market resumes.
+type RetuneResult struct {
+	Market   string `json:"market"`
+	LotSize  uint64 `json:"lotsize"`
+	RateStep uint64 `json:"ratestep"`
+	Epoch    int64  `json:"epoch"`
+}
+
 // RFC3339Milli is the RFC3339 time formatting with millisecond precision.
 const RFC3339Milli = "2006-01-02T15:04:05.999Z07:00"
 
@@ -0,0 +1,167 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+// Package analytics periodically rolls up market trading activity into
+// summaries stored in the DB.
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/db"
+)
+
+const (
+	// DefaultBinSize is the default duration of the window summarized by
+	// each market activity summary.
+	DefaultBinSize = time.Hour
+	// DefaultRetention is the default age after which market activity
+	// summaries are deleted. No summaries are computed for windows ending
+	// before this age.
+	DefaultRetention = 90 * 24 * time.Hour
+
+	rollupInterval = time.Minute
+)
+
+// DBSource is the DB backend that computes and stores market activity
+// summaries.
+type DBSource interface {
+	ComputeMarketActivity(base, quote uint32, start, end uint64) (*db.MarketActivity, error)
+	InsertMarketActivity(base, quote uint32, act *db.MarketActivity) error
+	LastMarketActivityEndStamp(base, quote uint32) (uint64, error)
+	PruneMarketActivity(base, quote uint32, before uint64) (int64, error)
+}
+
+// Config is the configuration for an Aggregator.
+type Config struct {
+	DB      DBSource
+	Markets []*dex.MarketInfo
+	// BinSize is the duration of the window summarized by each market
+	// activity summary. If zero, DefaultBinSize is used.
+	BinSize time.Duration
+	// Retention is the age after which market activity summaries are
+	// deleted. If zero, DefaultRetention is used.
+	Retention time.Duration
+	Logger    dex.Logger
+}
+
+// Aggregator periodically rolls up the trading activity of each market into
+// summaries of fixed-duration windows. Summaries are only computed for complete
+// windows, and rolling up resumes from the last stored summary, so no window is
+// skipped or summarized twice across restarts.
+type Aggregator struct {
+	db        DBSource
+	markets   []*dex.MarketInfo
+	binSize   uint64 // ms
+	retention uint64 // ms
+	log       dex.Logger
+	now       func() time.Time
+}
+
+// NewAggregator is the constructor for an Aggregator.
+func NewAggregator(cfg *Config) (*Aggregator, error) {
+	binSize, retention := cfg.BinSize, cfg.Retention
+	if binSize == 0 {
+		binSize = DefaultBinSize
+	}
+	if retention == 0 {
+		retention = DefaultRetention
+	}
+	if binSize < time.Minute {
+		return nil, fmt.Errorf("activity bin size %s is less than one minute", binSize)
+	}
+	if retention < binSize {
+		return nil, fmt.Errorf("activity retention %s is less than the bin size %s", retention, binSize)
+	}
+	return &Aggregator{
+		db:        cfg.DB,
+		markets:   cfg.Markets,
+		binSize:   uint64(binSize.Milliseconds()),
+		retention: uint64(retention.Milliseconds()),
+		log:       cfg.Logger,
+		now:       time.Now,
+	}, nil
+}
+
+// Run rolls up market activity until the context is canceled.
+func (a *Aggregator) Run(ctx context.Context) {
+	a.rollup(ctx)
+	ticker := time.NewTicker(rollupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			a.rollup(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// rollup summarizes any complete windows for all markets that have not yet been
+// summarized, and prunes expired summaries.
+func (a *Aggregator) rollup(ctx context.Context) {
+	for _, mkt := range a.markets {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := a.rollupMarket(ctx, mkt); err != nil {
+			a.log.Errorf("Error rolling up %s market activity: %v", mkt.Name, err)
+		}
+	}
+}
+
+// rollupMarket summarizes the market's complete windows that have not yet been
+// summarized, and prunes the market's expired summaries. Each summary is stored
+// as it is computed, so an interrupted rollup resumes at the first window that
+// was not stored.
+func (a *Aggregator) rollupMarket(ctx context.Context, mkt *dex.MarketInfo) error {
+	now := uint64(a.now().UnixMilli())
+	lastComplete := now - now%a.binSize
+	var oldest uint64
+	if lastComplete > a.retention {
+		oldest = lastComplete - a.retention
+	}
+
+	start, err := a.db.LastMarketActivityEndStamp(mkt.Base, mkt.Quote)
+	if err != nil {
+		return fmt.Errorf("LastMarketActivityEndStamp error: %w", err)
+	}
+	if start < oldest {
+		start = oldest
+	}
+
+	var n int
+	for ctx.Err() == nil {
+		// The first window may be short if the bin size has changed.
+		end := start - start%a.binSize + a.binSize
+		if end > lastComplete {
+			break
+		}
+		act, err := a.db.ComputeMarketActivity(mkt.Base, mkt.Quote, start, end)
+		if err != nil {
+			return fmt.Errorf("ComputeMarketActivity error: %w", err)
+		}
+		if err = a.db.InsertMarketActivity(mkt.Base, mkt.Quote, act); err != nil {
+			return fmt.Errorf("InsertMarketActivity error: %w", err)
+		}
+		start = end
+		n++
+	}
+	if n > 0 {
+		a.log.Debugf("Stored %d %s market activity summaries through %s", n, mkt.Name,
+			time.UnixMilli(int64(start)))
+	}
+
+	pruned, err := a.db.PruneMarketActivity(mkt.Base, mkt.Quote, oldest)
+	if err != nil {
+		return fmt.Errorf("PruneMarketActivity error: %w", err)
+	}
+	if pruned > 0 {
+		a.log.Debugf("Pruned %d expired %s market activity summaries", pruned, mkt.Name)
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/db"
+)
+
+var tLogger = dex.StdOutLogger("TEST", dex.LevelTrace)
+
+// tEpoch is the recorded activity of a single epoch.
+type tEpoch struct {
+	end      uint64
+	orders   uint64
+	cancels  uint64
+	matches  uint64
+	vol      uint64
+	quoteVol uint64
+	accts    []account.AccountID
+}
+
+type TDB struct {
+	epochs     []*tEpoch
+	stored     map[uint64]*db.MarketActivity
+	computed   [][2]uint64
+	computeErr error
+	// failAfter fails ComputeMarketActivity after this many successful calls
+	// if non-zero.
+	failAfter    int
+	prunedBefore uint64
+}
+
+func newTDB() *TDB {
+	return &TDB{stored: make(map[uint64]*db.MarketActivity)}
+}
+
+func (tdb *TDB) ComputeMarketActivity(base, quote uint32, start, end uint64) (*db.MarketActivity, error) {
+	if tdb.computeErr != nil {
+		return nil, tdb.computeErr
+	}
+	if tdb.failAfter > 0 && len(tdb.computed) >= tdb.failAfter {
+		return nil, errors.New("test error")
+	}
+	tdb.computed = append(tdb.computed, [2]uint64{start, end})
+	act := &db.MarketActivity{EndStamp: end, Duration: end - start}
+	accts := make(map[account.AccountID]bool)
+	for _, ep := range tdb.epochs {
+		if ep.end <= start || ep.end > end {
+			continue
+		}
+		act.Orders += ep.orders
+		act.Cancels += ep.cancels
+		act.Matches += ep.matches
+		act.MatchVolume += ep.vol
+		act.QuoteVolume += ep.quoteVol
+		for _, aid := range ep.accts {
+			accts[aid] = true
+		}
+	}
+	act.Accounts = uint64(len(accts))
+	return act, nil
+}
+
+func (tdb *TDB) InsertMarketActivity(base, quote uint32, act *db.MarketActivity) error {
+	tdb.stored[act.EndStamp] = act
+	return nil
+}
+
+func (tdb *TDB) LastMarketActivityEndStamp(base, quote uint32) (uint64, error) {
+	var last uint64
+	for end := range tdb.stored {
+		if end > last {
+			last = end
+		}
+	}
+	return last, nil
+}
+
+func (tdb *TDB) PruneMarketActivity(base, quote uint32, before uint64) (int64, error) {
+	tdb.prunedBefore = before
+	var n int64
+	for end := range tdb.stored {
+		if end < before {
+			delete(tdb.stored, end)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func newTestAggregator(t *testing.T, tdb *TDB, now *time.Time) *Aggregator {
+	t.Helper()
+	mkt, err := dex.NewMarketInfoFromSymbols("dcr", "btc", 1e8, 1e3, 10000, 0, 1.5)
+	if err != nil {
+		t.Fatalf("NewMarketInfoFromSymbols error: %v", err)
+	}
+	a, err := NewAggregator(&Config{
+		DB:        tdb,
+		Markets:   []*dex.MarketInfo{mkt},
+		BinSize:   time.Hour,
+		Retention: 24 * time.Hour,
+		Logger:    tLogger,
+	})
+	if err != nil {
+		t.Fatalf("NewAggregator error: %v", err)
+	}
+	a.now = func() time.Time { return *now }
+	return a
+}
+
+func TestRollup(t *testing.T) {
+	const hour = uint64(time.Hour / time.Millisecond)
+	// Start on an hour boundary long after the epoch.
+	t0 := 1000 * 24 * hour
+	now := time.UnixMilli(int64(t0))
+
+	tdb := newTDB()
+	a := newTestAggregator(t, tdb, &now)
+	ctx := context.Background()
+
+	// With no stored summaries, the rollup starts at the retention period.
+	a.rollup(ctx)
+	if len(tdb.stored) != 24 {
+		t.Fatalf("expected 24 initial summaries, got %d", len(tdb.stored))
+	}
+	if first := tdb.computed[0]; first[0] != t0-24*hour || first[1] != t0-23*hour {
+		t.Fatalf("wrong first window %v", first)
+	}
+	if tdb.prunedBefore != t0-24*hour {
+		t.Fatalf("wrong prune time %d", tdb.prunedBefore)
+	}
+
+	// Record activity in the current hour and the next.
+	acctA, acctB, acctC := account.AccountID{0x0a}, account.AccountID{0x0b}, account.AccountID{0x0c}
+	tdb.epochs = []*tEpoch{
+		{end: t0 + 10000, orders: 2, accts: []account.AccountID{acctA, acctB}},
+		{end: t0 + 20000, orders: 1, matches: 2, vol: 3e8, quoteVol: 6e6, accts: []account.AccountID{acctA, acctC}},
+		{end: t0 + hour, cancels: 1, accts: []account.AccountID{acctB}},
+		{end: t0 + hour + 10000, orders: 4, matches: 1, vol: 1e8, quoteVol: 2e6, accts: []account.AccountID{acctC}},
+	}
+
+	// Nothing new until the hour is complete.
+	now = time.UnixMilli(int64(t0 + hour - 1))
+	tdb.computed = nil
+	a.rollup(ctx)
+	if len(tdb.computed) != 0 {
+		t.Fatalf("incomplete window summarized: %v", tdb.computed)
+	}
+
+	now = time.UnixMilli(int64(t0 + hour))
+	a.rollup(ctx)
+	if len(tdb.computed) != 1 {
+		t.Fatalf("expected 1 new summary, got %d", len(tdb.computed))
+	}
+	exp := db.MarketActivity{
+		EndStamp:    t0 + hour,
+		Duration:    hour,
+		Orders:      3,
+		Cancels:     1,
+		Matches:     2,
+		MatchVolume: 3e8,
+		QuoteVolume: 6e6,
+		Accounts:    3,
+	}
+	if act := tdb.stored[t0+hour]; act == nil || *act != exp {
+		t.Fatalf("wrong summary. wanted %+v, got %+v", exp, act)
+	}
+
+	// A failed rollup resumes at the first window not stored.
+	now = time.UnixMilli(int64(t0 + 4*hour + 30000))
+	tdb.computed = nil
+	tdb.failAfter = 1
+	a.rollup(ctx)
+	if len(tdb.stored) != 26 {
+		t.Fatalf("expected 26 summaries after failed rollup, got %d", len(tdb.stored))
+	}
+	tdb.computed = nil
+	tdb.failAfter = 0
+	// A new Aggregator resumes where the last left off.
+	a = newTestAggregator(t, tdb, &now)
+	a.rollup(ctx)
+	if len(tdb.computed) != 2 || tdb.computed[0][0] != t0+2*hour || tdb.computed[1][1] != t0+4*hour {
+		t.Fatalf("wrong resumed windows: %v", tdb.computed)
+	}
+	exp = db.MarketActivity{
+		EndStamp:    t0 + 2*hour,
+		Duration:    hour,
+		Orders:      4,
+		Matches:     1,
+		MatchVolume: 1e8,
+		QuoteVolume: 2e6,
+		Accounts:    1,
+	}
+	if act := tdb.stored[t0+2*hour]; act == nil || *act != exp {
+		t.Fatalf("wrong summary. wanted %+v, got %+v", exp, act)
+	}
+
+	// Expired summaries are pruned. Windows ending t0-20h through t0+4h are
+	// retained.
+	if len(tdb.stored) != 25 {
+		t.Fatalf("expected 25 retained summaries, got %d", len(tdb.stored))
+	}
+	for end := range tdb.stored {
+		if end < t0-20*hour {
+			t.Fatalf("expired summary at %d not pruned", end)
+		}
+	}
+
+	// Errors are not fatal.
+	now = time.UnixMilli(int64(t0 + 5*hour))
+	tdb.computeErr = errors.New("test error")
+	a.rollup(ctx)
+	if _, found := tdb.stored[t0+5*hour]; found {
+		t.Fatalf("summary stored after compute error")
+	}
+}
+
+func TestNewAggregator(t *testing.T) {
+	if _, err := NewAggregator(&Config{BinSize: time.Second}); err == nil {
+		t.Fatalf("no error for short bin size")
+	}
+	if _, err := NewAggregator(&Config{BinSize: time.Hour, Retention: time.Minute}); err == nil {
+		t.Fatalf("no error for retention shorter than bin size")
+	}
+	a, err := NewAggregator(&Config{})
+	if err != nil {
+		t.Fatalf("NewAggregator error: %v", err)
+	}
+	if a.binSize != uint64(DefaultBinSize.Milliseconds()) || a.retention != uint64(DefaultRetention.Milliseconds()) {
+		t.Fatalf("defaults not applied")
+	}
+}
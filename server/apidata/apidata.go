@@ -14,6 +14,7 @@ import (
 	"decred.org/dcrdex/dex/candles"
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/server/comms"
+	"decred.org/dcrdex/server/db"
 	"decred.org/dcrdex/server/matcher"
 )
 
@@ -29,6 +30,7 @@ type DBSource interface {
 	LoadEpochStats(base, quote uint32, caches []*candles.Cache) error
 	LastCandleEndStamp(base, quote uint32, candleDur uint64) (uint64, error)
 	InsertCandles(base, quote uint32, dur uint64, cs []*candles.Candle) error
+	EpochAudit(base, quote uint32, epochIdx, epochDur int64) (*db.EpochAudit, error)
 }
 
 // MarketSource is a source of market information. Markets are added after
@@ -76,6 +78,7 @@ func NewDataAPI(dbSrc DBSource, registerHTTP func(route string, handler comms.HT
 		registerHTTP(msgjson.SpotsRoute, s.handleSpots)
 		registerHTTP(msgjson.CandlesRoute, s.handleCandles)
 		registerHTTP(msgjson.OrderBookRoute, s.handleOrderBook)
+		registerHTTP(msgjson.EpochAuditRoute, s.handleEpochAudit)
 	}
 	return s
 }
@@ -261,6 +264,55 @@ func (s *DataAPI) handleOrderBook(thing any) (any, error) {
 	return s.bookSource.Book(mkt)
 }
 
+// handleEpochAudit implements comms.HTTPHandler for the /epochaudit endpoint.
+func (s *DataAPI) handleEpochAudit(thing any) (any, error) {
+	req, ok := thing.(*msgjson.EpochAuditRequest)
+	if !ok {
+		return nil, fmt.Errorf("unparseable epoch audit request")
+	}
+
+	mkt, err := dex.MarketName(req.BaseID, req.QuoteID)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing market for %d - %d", req.BaseID, req.QuoteID)
+	}
+	epochDur := s.epochDurations[mkt]
+	if epochDur == 0 {
+		return nil, fmt.Errorf("market %s not known", mkt)
+	}
+
+	// The epoch is only recorded after preimage collection and matching, so
+	// preimages are never revealed here before the epoch's commitment checksum
+	// has been published.
+	audit, err := s.db.EpochAudit(req.BaseID, req.QuoteID, int64(req.Epoch), int64(epochDur))
+	if err != nil {
+		if db.IsErrEpochUnknown(err) {
+			return nil, fmt.Errorf("epoch %d has not been matched", req.Epoch)
+		}
+		return nil, fmt.Errorf("error retrieving epoch %d", req.Epoch)
+	}
+
+	resp := &msgjson.EpochAudit{
+		MarketID:  mkt,
+		Epoch:     uint64(audit.Idx),
+		Duration:  uint64(audit.Dur),
+		MatchTime: uint64(audit.MatchTime),
+		CSum:      audit.CSum,
+		Seed:      audit.Seed,
+		Orders:    make([]*msgjson.EpochAuditOrder, 0, len(audit.Orders)),
+	}
+	for _, ord := range audit.Orders {
+		ao := &msgjson.EpochAuditOrder{
+			OrderID: ord.ID.Bytes(),
+			Commit:  ord.Commit[:],
+		}
+		if !ord.Preimage.IsZero() {
+			ao.Preimage = ord.Preimage[:]
+		}
+		resp.Orders = append(resp.Orders, ao)
+	}
+	return resp, nil
+}
+
 func init() {
 	for _, s := range candles.BinSizes {
 		dur, err := time.ParseDuration(s)
@@ -4,6 +4,7 @@
 package apidata
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -11,7 +12,9 @@ import (
 
 	"decred.org/dcrdex/dex/candles"
 	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
 	"decred.org/dcrdex/server/comms"
+	"decred.org/dcrdex/server/db"
 	"decred.org/dcrdex/server/matcher"
 )
 
@@ -26,7 +29,9 @@ func (m *TMarketSource) Base() uint32          { return m.base }
 func (m *TMarketSource) Quote() uint32         { return m.quote }
 
 type TDBSource struct {
-	loadEpochErr error
+	loadEpochErr  error
+	epochAudit    *db.EpochAudit
+	epochAuditErr error
 }
 
 func (db *TDBSource) LoadEpochStats(base, quote uint32, caches []*candles.Cache) error {
@@ -41,6 +46,10 @@ func (db *TDBSource) InsertCandles(base, quote uint32, dur uint64, cs []*candles
 	return nil
 }
 
+func (tdb *TDBSource) EpochAudit(base, quote uint32, epochIdx, epochDur int64) (*db.EpochAudit, error) {
+	return tdb.epochAudit, tdb.epochAuditErr
+}
+
 type TBookSource struct {
 	book *msgjson.OrderBook
 }
@@ -195,3 +204,58 @@ func TestOrderBook(t *testing.T) {
 		t.Fatalf("where did this book come from?")
 	}
 }
+
+func TestEpochAudit(t *testing.T) {
+	rig := newTestRig()
+	req := &msgjson.EpochAuditRequest{
+		BaseID:  42,
+		QuoteID: 0,
+		Epoch:   5,
+	}
+
+	// Unknown market
+	if _, err := rig.api.handleEpochAudit(req); err == nil {
+		t.Fatalf("no error for unknown market")
+	}
+	if err := rig.api.AddMarketSource(&TMarketSource{42, 0}); err != nil {
+		t.Fatalf("AddMarketSource error: %v", err)
+	}
+
+	// Not yet matched
+	rig.db.epochAuditErr = db.ArchiveError{Code: db.ErrUnknownEpoch}
+	if _, err := rig.api.handleEpochAudit(req); err == nil {
+		t.Fatalf("no error for unknown epoch")
+	}
+	rig.db.epochAuditErr = nil
+
+	var pi order.Preimage
+	pi[0] = 1
+	revealed := &db.EpochAuditOrder{ID: order.OrderID{1}, Commit: pi.Commit(), Preimage: pi}
+	missed := &db.EpochAuditOrder{ID: order.OrderID{2}, Commit: order.Commitment{2}}
+	rig.db.epochAudit = &db.EpochAudit{
+		Idx:       5,
+		Dur:       1000,
+		MatchTime: 6001,
+		CSum:      []byte{3},
+		Seed:      []byte{4},
+		Orders:    []*db.EpochAuditOrder{revealed, missed},
+	}
+	auditI, err := rig.api.handleEpochAudit(req)
+	if err != nil {
+		t.Fatalf("handleEpochAudit error: %v", err)
+	}
+	audit, ok := auditI.(*msgjson.EpochAudit)
+	if !ok {
+		t.Fatalf("wrong response type %T", auditI)
+	}
+	if audit.MarketID != "dcr_btc" || audit.Epoch != 5 || audit.Duration != 1000 || audit.MatchTime != 6001 ||
+		!bytes.Equal(audit.CSum, []byte{3}) || !bytes.Equal(audit.Seed, []byte{4}) || len(audit.Orders) != 2 {
+		t.Fatalf("wrong epoch audit %+v", audit)
+	}
+	if ao := audit.Orders[0]; !bytes.Equal(ao.OrderID, revealed.ID[:]) || !bytes.Equal(ao.Preimage, pi[:]) {
+		t.Fatalf("wrong revealed order %+v", ao)
+	}
+	if ao := audit.Orders[1]; !bytes.Equal(ao.Commit, missed.Commit[:]) || len(ao.Preimage) != 0 {
+		t.Fatalf("wrong missed order %+v", ao)
+	}
+}
@@ -32,8 +32,8 @@ func (d *Driver) Version() uint32 {
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Bitcoin Cash.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Bitcoin Cash and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // UnitInfo returns the dex.UnitInfo for the asset.
@@ -0,0 +1,144 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package asset
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBreakerCheckInterval is the default interval between backend
+	// health checks.
+	DefaultBreakerCheckInterval = time.Minute
+	// DefaultBreakerFailThreshold is the default number of consecutive failed
+	// health checks that will trip a CircuitBreaker.
+	DefaultBreakerFailThreshold = 3
+	// DefaultBreakerRecoveryPeriod is the default length of time a backend
+	// must be continuously healthy before a tripped CircuitBreaker is reset.
+	DefaultBreakerRecoveryPeriod = 10 * time.Minute
+)
+
+// errNotSynced is the health check error for a backend that reports no error
+// from Synced, but is not synced.
+var errNotSynced = errors.New("backend not synced")
+
+// CircuitBreakerConfig is the configuration for a CircuitBreaker. Zero values
+// are replaced with the package defaults.
+type CircuitBreakerConfig struct {
+	// CheckInterval is the time between backend health checks.
+	CheckInterval time.Duration
+	// FailThreshold is the number of consecutive failed health checks that
+	// will trip the breaker.
+	FailThreshold int
+	// RecoveryPeriod is how long the backend must pass every health check
+	// before a tripped breaker is reset.
+	RecoveryPeriod time.Duration
+}
+
+// CircuitBreaker monitors the health of an asset Backend via its Synced method.
+// When the health check fails FailThreshold times in a row, the breaker trips
+// and the OnTrip callback is run. A tripped breaker resets, running the OnReset
+// callback, once the backend has been healthy for the RecoveryPeriod.
+type CircuitBreaker struct {
+	assetID uint32
+	be      Backend
+	cfg     CircuitBreakerConfig
+	onTrip  func(assetID uint32, err error)
+	onReset func(assetID uint32)
+
+	mtx          sync.Mutex
+	fails        int
+	tripped      bool
+	healthySince time.Time
+}
+
+// NewCircuitBreaker is the constructor for a CircuitBreaker. The onTrip and
+// onReset callbacks are run synchronously from the health check loop. The
+// breaker does not begin checking the backend until Run is called.
+func NewCircuitBreaker(assetID uint32, be Backend, cfg *CircuitBreakerConfig,
+	onTrip func(assetID uint32, err error), onReset func(assetID uint32)) *CircuitBreaker {
+
+	var c CircuitBreakerConfig
+	if cfg != nil {
+		c = *cfg
+	}
+	if c.CheckInterval <= 0 {
+		c.CheckInterval = DefaultBreakerCheckInterval
+	}
+	if c.FailThreshold <= 0 {
+		c.FailThreshold = DefaultBreakerFailThreshold
+	}
+	if c.RecoveryPeriod <= 0 {
+		c.RecoveryPeriod = DefaultBreakerRecoveryPeriod
+	}
+	return &CircuitBreaker{
+		assetID: assetID,
+		be:      be,
+		cfg:     c,
+		onTrip:  onTrip,
+		onReset: onReset,
+	}
+}
+
+// Run checks the backend health every CheckInterval until the context is
+// canceled. Run satisfies the dex.Runner interface.
+func (cb *CircuitBreaker) Run(ctx context.Context) {
+	ticker := time.NewTicker(cb.cfg.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			cb.check(time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Tripped is true if the breaker has tripped and not yet been reset.
+func (cb *CircuitBreaker) Tripped() bool {
+	cb.mtx.Lock()
+	defer cb.mtx.Unlock()
+	return cb.tripped
+}
+
+// check performs a single health check and trips or resets the breaker as
+// necessary.
+func (cb *CircuitBreaker) check(now time.Time) {
+	synced, err := cb.be.Synced()
+	if err == nil && !synced {
+		err = errNotSynced
+	}
+
+	var trip, reset bool
+	cb.mtx.Lock()
+	if err != nil {
+		cb.fails++
+		cb.healthySince = time.Time{}
+		if !cb.tripped && cb.fails >= cb.cfg.FailThreshold {
+			cb.tripped = true
+			trip = true
+		}
+	} else {
+		cb.fails = 0
+		if cb.healthySince.IsZero() {
+			cb.healthySince = now
+		}
+		if cb.tripped && now.Sub(cb.healthySince) >= cb.cfg.RecoveryPeriod {
+			cb.tripped = false
+			reset = true
+		}
+	}
+	cb.mtx.Unlock()
+
+	switch {
+	case trip && cb.onTrip != nil:
+		cb.onTrip(cb.assetID, err)
+	case reset && cb.onReset != nil:
+		cb.onReset(cb.assetID)
+	}
+}
@@ -0,0 +1,97 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package asset
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type tSyncBackend struct {
+	Backend
+	synced  bool
+	syncErr error
+}
+
+func (be *tSyncBackend) Synced() (bool, error) {
+	return be.synced, be.syncErr
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	be := &tSyncBackend{synced: true}
+	var trips, resets int
+	cb := NewCircuitBreaker(42, be, &CircuitBreakerConfig{
+		FailThreshold:  3,
+		RecoveryPeriod: time.Minute,
+	}, func(assetID uint32, err error) {
+		if assetID != 42 {
+			t.Fatalf("wrong asset ID %d", assetID)
+		}
+		if err == nil {
+			t.Fatalf("tripped without an error")
+		}
+		trips++
+	}, func(uint32) {
+		resets++
+	})
+
+	now := time.Now()
+	step := func() {
+		t.Helper()
+		now = now.Add(time.Second * 10)
+		cb.check(now)
+	}
+	ensure := func(tag string, tripped bool, expTrips, expResets int) {
+		t.Helper()
+		if cb.Tripped() != tripped {
+			t.Fatalf("%s: expected tripped = %t", tag, tripped)
+		}
+		if trips != expTrips || resets != expResets {
+			t.Fatalf("%s: expected %d trips and %d resets, got %d and %d",
+				tag, expTrips, expResets, trips, resets)
+		}
+	}
+
+	step()
+	ensure("healthy", false, 0, 0)
+
+	// Two failures is not enough to trip, and a success resets the count.
+	be.syncErr = errors.New("test error")
+	step()
+	step()
+	ensure("two failures", false, 0, 0)
+	be.syncErr = nil
+	step()
+	be.synced = false
+	step()
+	step()
+	ensure("failure count reset", false, 0, 0)
+
+	// Third consecutive failure trips.
+	step()
+	ensure("tripped", true, 1, 0)
+	// Continued failures don't trip again.
+	step()
+	ensure("still tripped", true, 1, 0)
+
+	// Recovery must be sustained.
+	be.synced = true
+	step()
+	ensure("recovering", true, 1, 0)
+	now = now.Add(time.Second * 30)
+	be.synced = false
+	step()
+	be.synced = true
+	step()
+	now = now.Add(time.Second * 40)
+	step()
+	ensure("interrupted recovery", true, 1, 0)
+
+	now = now.Add(time.Second * 20)
+	step()
+	ensure("reset", false, 1, 1)
+	step()
+	ensure("healthy again", false, 1, 1)
+}
@@ -59,11 +59,8 @@ func (d *Driver) Setup(cfg *asset.BackendConfig) (asset.Backend, error) {
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Bitcoin.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	txid, vout, err := decodeCoinID(coinID)
-	if err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("%v:%d", txid, vout), err
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Version returns the Backend implementation's version number.
@@ -195,6 +192,11 @@ type Backend struct {
 
 // Check that Backend satisfies the Backend interface.
 var _ asset.Backend = (*Backend)(nil)
+var _ asset.FeeRangeEstimator = (*Backend)(nil)
+var _ asset.SyncLagReporter = (*Backend)(nil)
+var _ asset.TxChecker = (*Backend)(nil)
+var _ asset.TxConfirmer = (*Backend)(nil)
+var _ asset.RefundVerifier = (*Backend)(nil)
 var _ srvdex.Bonder = (*Backend)(nil)
 
 // NewBackend is the exported constructor by which the DEX will import the
@@ -357,6 +359,10 @@ type BackendCloneConfig struct {
 	// input and output amounts. This is a temporary measure until zcashd
 	// encodes valueBalanceOrchard in their getrawtransaction RPC results.
 	ShieldedIO func(tx *VerboseTxExtended) (in, out uint64, err error)
+	// NoTestMempoolAccept is for assets whose node does not have the
+	// testmempoolaccept RPC. CheckTransaction will return
+	// asset.ErrTxCheckUnsupported.
+	NoTestMempoolAccept bool
 	// RelayAddr is an address for a NodeRelay.
 	RelayAddr  string
 	FeeFetcher *txfee.FeeFetcher
@@ -540,6 +546,29 @@ func (btc *Backend) Synced() (bool, error) {
 	return !chainInfo.InitialBlockDownload && chainInfo.Headers-chainInfo.Blocks <= 1, nil
 }
 
+// SyncLag is the number of blocks by which the node's best block trails the
+// best known header. Part of the asset.SyncLagReporter interface.
+func (btc *Backend) SyncLag() (int64, error) {
+	chainInfo, err := btc.node.GetBlockChainInfo()
+	if err != nil {
+		return 0, fmt.Errorf("GetBlockChainInfo error: %w", err)
+	}
+	return chainInfo.Headers - chainInfo.Blocks, nil
+}
+
+// CheckTransaction checks whether the node would accept the serialized
+// transaction into its mempool. Part of the asset.TxChecker interface.
+func (btc *Backend) CheckTransaction(rawTx []byte) (accepted bool, reason string, err error) {
+	if btc.cfg.NoTestMempoolAccept {
+		return false, "", asset.ErrTxCheckUnsupported
+	}
+	accepted, reason, err = btc.node.TestMempoolAccept(rawTx)
+	if err != nil {
+		return false, "", fmt.Errorf("TestMempoolAccept error: %w", err)
+	}
+	return accepted, reason, nil
+}
+
 // Redemption is an input that redeems a swap contract.
 func (btc *Backend) Redemption(redemptionID, contractID, _ []byte) (asset.Coin, error) {
 	txHash, vin, err := decodeCoinID(redemptionID)
@@ -560,6 +589,41 @@ func (btc *Backend) Redemption(redemptionID, contractID, _ []byte) (asset.Coin,
 	return input, nil
 }
 
+// Refund returns a Coin for refundID, a transaction input that spends
+// contractID via the refund path of the swap contract. Part of the
+// asset.RefundVerifier interface.
+func (btc *Backend) Refund(refundID, contractID, contractData []byte) (asset.Coin, error) {
+	input, err := btc.Redemption(refundID, contractID, contractData)
+	if err != nil {
+		return nil, err
+	}
+	txHash, vin, _ := decodeCoinID(refundID)
+	verboseTx, err := btc.node.GetRawTransactionVerbose(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("GetRawTransactionVerbose for txid %s: %w", txHash, err)
+	}
+	if int(vin) >= len(verboseTx.Vin) {
+		return nil, fmt.Errorf("tx %v has %d inputs (no vin %d)", txHash, len(verboseTx.Vin), vin)
+	}
+	txIn := verboseTx.Vin[vin]
+	var sigScript []byte
+	if txIn.ScriptSig != nil {
+		if sigScript, err = hex.DecodeString(txIn.ScriptSig.Hex); err != nil {
+			return nil, fmt.Errorf("error decoding sigScript: %w", err)
+		}
+	}
+	witness := make([][]byte, len(txIn.Witness))
+	for i, w := range txIn.Witness {
+		if witness[i], err = hex.DecodeString(w); err != nil {
+			return nil, fmt.Errorf("error decoding witness: %w", err)
+		}
+	}
+	if !dexbtc.IsRefundScript(btc.segwit, sigScript, witness, contractData) {
+		return nil, fmt.Errorf("%x does not refund %x", refundID, contractID)
+	}
+	return input, nil
+}
+
 // FundingCoin is an unspent output.
 func (btc *Backend) FundingCoin(_ context.Context, coinID []byte, redeemScript []byte) (asset.FundingCoin, error) {
 	txHash, vout, err := decodeCoinID(coinID)
@@ -865,6 +929,46 @@ func (btc *Backend) FeeRate(ctx context.Context) (uint64, error) {
 	return btc.estimateFee(ctx)
 }
 
+// Confirmation targets for the fee rate range estimates.
+const (
+	priorityFeeConfs   = 1
+	economicalFeeConfs = 6
+	minFeeConfs        = 144
+)
+
+// FeeRateRange estimates a range of fee rates with estimatesmartfee at several
+// confirmation targets. Part of the asset.FeeRangeEstimator interface.
+func (btc *Backend) FeeRateRange(ctx context.Context) (*asset.FeeRateRange, error) {
+	if btc.cfg.DumbFeeEstimates {
+		return nil, asset.ErrFeeRangeUnsupported
+	}
+	priority, err := btc.node.EstimateSmartFee(priorityFeeConfs, &btcjson.EstimateModeConservative)
+	if err != nil {
+		return nil, fmt.Errorf("error estimating priority fee rate: %w", err)
+	}
+	economical, err := btc.node.EstimateSmartFee(economicalFeeConfs, &btcjson.EstimateModeEconomical)
+	if err != nil {
+		return nil, fmt.Errorf("error estimating economical fee rate: %w", err)
+	}
+	minRate, err := btc.node.EstimateSmartFee(minFeeConfs, &btcjson.EstimateModeEconomical)
+	if err != nil {
+		return nil, fmt.Errorf("error estimating minimum fee rate: %w", err)
+	}
+	// The estimate modes differ, so a longer target does not guarantee a lower
+	// rate.
+	if economical > priority {
+		economical = priority
+	}
+	if minRate > economical {
+		minRate = economical
+	}
+	return &asset.FeeRateRange{
+		MinToConfirm: minRate,
+		Economical:   economical,
+		Priority:     priority,
+	}, nil
+}
+
 // Info provides some general information about the backend.
 func (*Backend) Info() *asset.BackendInfo {
 	return &asset.BackendInfo{}
@@ -872,8 +976,18 @@ func (*Backend) Info() *asset.BackendInfo {
 
 // ValidateFeeRate checks that the transaction fees used to initiate the
 // contract are sufficient.
-func (btc *Backend) ValidateFeeRate(c asset.Coin, reqFeeRate uint64) bool {
-	return c.FeeRate() >= reqFeeRate
+func (btc *Backend) ValidateFeeRate(c asset.Coin, reqFeeRate uint64) error {
+	if feeRate := c.FeeRate(); feeRate < reqFeeRate {
+		return fmt.Errorf("transaction %s fee rate %d atoms/vbyte is less than the required %d atoms/vbyte",
+			c.TxID(), feeRate, reqFeeRate)
+	}
+	return nil
+}
+
+// DustLimit is the smallest swap contract value that can be redeemed to a
+// non-dust output at the given fee rate. Part of the asset.Backend interface.
+func (btc *Backend) DustLimit(feeRate uint64) uint64 {
+	return dexbtc.MinLotSize(feeRate, btc.segwit)
 }
 
 // CheckSwapAddress checks that the given address is parseable, and suitable as
@@ -917,6 +1031,23 @@ func (btc *Backend) TxData(coinID []byte) ([]byte, error) {
 	return txB, nil
 }
 
+// TxConfirmations returns the number of confirmations of the transaction of
+// the coin. Part of the asset.TxConfirmer interface.
+func (btc *Backend) TxConfirmations(coinID []byte) (int64, error) {
+	txHash, _, err := decodeCoinID(coinID)
+	if err != nil {
+		return 0, err
+	}
+	verboseTx, err := btc.node.GetRawTransactionVerbose(txHash)
+	if err != nil {
+		if isTxNotFoundErr(err) {
+			return 0, asset.CoinNotFoundError
+		}
+		return 0, fmt.Errorf("GetRawTransactionVerbose for txid %s: %w", txHash, err)
+	}
+	return int64(verboseTx.Confirmations), nil
+}
+
 // blockInfo returns block information for the verbose transaction data. The
 // current tip hash is also returned as a convenience.
 func (btc *Backend) blockInfo(verboseTx *VerboseTxExtended) (blockHeight uint32, blockHash chainhash.Hash, tipHash *chainhash.Hash, err error) {
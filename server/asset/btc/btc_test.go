@@ -18,6 +18,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -25,6 +26,7 @@ import (
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/config"
 	dexbtc "decred.org/dcrdex/dex/networks/btc"
+	"decred.org/dcrdex/server/asset"
 	"github.com/btcsuite/btcd/btcec/v2"
 	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
 	"github.com/btcsuite/btcd/btcjson"
@@ -388,13 +390,21 @@ func (t *testNode) RawRequest(_ context.Context, method string, params []json.Ra
 		// Unfound is not an error for GetTxOut.
 		return json.Marshal(out)
 	case methodEstimateSmartFee:
-		const optimalFeeRate uint64 = 24
+		var confTarget int64
+		mustUnmarshal(params[0], &confTarget)
+		optimalFeeRate := uint64(24)
+		switch {
+		case confTarget >= minFeeConfs:
+			optimalFeeRate = 4
+		case confTarget >= economicalFeeConfs:
+			optimalFeeRate = 12
+		}
 		optimalRate := float64(optimalFeeRate) * 1e-5
 		return json.Marshal(&btcjson.EstimateSmartFeeResult{
 			Blocks:  2,
 			FeeRate: &optimalRate,
 		})
-	case methodGetBlockchainInfo:
+	case methodGetBlockchainInfo, methodTestMempoolAccept:
 		if t.rawErr != nil {
 			return nil, t.rawErr
 		}
@@ -1540,3 +1550,155 @@ func TestSynced(t *testing.T) {
 	}
 	tNode.rawErr = nil
 }
+
+func TestCheckTransaction(t *testing.T) {
+	btc, shutdown := testBackend(true)
+	defer shutdown()
+	tNode := btc.node.requester.(*testNode)
+	rawTx := []byte{0x01, 0x02, 0x03}
+
+	// Acceptable transaction.
+	tNode.rawResult, _ = json.Marshal([]*btcjson.TestMempoolAcceptResult{{Allowed: true}})
+	accepted, reason, err := btc.CheckTransaction(rawTx)
+	if err != nil {
+		t.Fatalf("CheckTransaction error: %v", err)
+	}
+	if !accepted || reason != "" {
+		t.Fatalf("acceptable transaction rejected: %q", reason)
+	}
+
+	// Rejected transaction.
+	tNode.rawResult, _ = json.Marshal([]*btcjson.TestMempoolAcceptResult{{RejectReason: "min relay fee not met"}})
+	accepted, reason, err = btc.CheckTransaction(rawTx)
+	if err != nil {
+		t.Fatalf("CheckTransaction error: %v", err)
+	}
+	if accepted || reason != "min relay fee not met" {
+		t.Fatalf("wrong result for rejected transaction: %t, %q", accepted, reason)
+	}
+
+	// Wrong number of results.
+	tNode.rawResult, _ = json.Marshal([]*btcjson.TestMempoolAcceptResult{})
+	if _, _, err = btc.CheckTransaction(rawTx); err == nil {
+		t.Fatalf("no error for missing result")
+	}
+
+	// RPC error.
+	tNode.rawErr = fmt.Errorf("test error")
+	if _, _, err = btc.CheckTransaction(rawTx); err == nil {
+		t.Fatalf("testmempoolaccept error not propagated")
+	}
+	tNode.rawErr = nil
+
+	// Clone without testmempoolaccept.
+	btc.cfg.NoTestMempoolAccept = true
+	if _, _, err = btc.CheckTransaction(rawTx); !errors.Is(err, asset.ErrTxCheckUnsupported) {
+		t.Fatalf("wrong error for unsupported node: %v", err)
+	}
+}
+
+func TestDustLimit(t *testing.T) {
+	for _, segwit := range []bool{false, true} {
+		btc, shutdown := testBackend(segwit)
+		defer shutdown()
+
+		outputSize := uint64(dexbtc.P2PKHOutputSize)
+		if segwit {
+			outputSize = dexbtc.P2WPKHOutputSize
+		}
+		redeemSize := dexbtc.RedeemSwapTxSize(segwit)
+
+		for _, feeRate := range []uint64{1, 10, 100} {
+			dustLimit := btc.DustLimit(feeRate)
+			// A contract at the dust limit can be redeemed to a non-dust
+			// output.
+			redeemed := dustLimit - redeemSize*feeRate
+			if dexbtc.IsDustVal(outputSize, redeemed, feeRate, segwit) {
+				t.Fatalf("segwit = %t, fee rate %d: redeeming a contract at the dust limit %d creates dust",
+					segwit, feeRate, dustLimit)
+			}
+			// Clients scale the limit for the max fee rate, so it must be
+			// proportional to the fee rate.
+			if unit := btc.DustLimit(1); dustLimit != unit*feeRate {
+				t.Fatalf("segwit = %t: dust limit %d at fee rate %d is not proportional to the limit %d at fee rate 1",
+					segwit, dustLimit, feeRate, unit)
+			}
+		}
+	}
+}
+
+func TestFeeRateRange(t *testing.T) {
+	btc, shutdown := testBackend(true)
+	defer shutdown()
+
+	feeRange, err := btc.FeeRateRange(context.Background())
+	if err != nil {
+		t.Fatalf("FeeRateRange error: %v", err)
+	}
+	exp := asset.FeeRateRange{MinToConfirm: 4, Economical: 12, Priority: 24}
+	if *feeRange != exp {
+		t.Fatalf("wrong fee rate range. wanted %+v, got %+v", exp, *feeRange)
+	}
+
+	btc.cfg.DumbFeeEstimates = true
+	if _, err = btc.FeeRateRange(context.Background()); !errors.Is(err, asset.ErrFeeRangeUnsupported) {
+		t.Fatalf("wrong error for fee rate range without estimatesmartfee: %v", err)
+	}
+	btc.cfg.DumbFeeEstimates = false
+}
+
+func TestValidateFeeRate(t *testing.T) {
+	for _, segwit := range []bool{false, true} {
+		btc, shutdown := testBackend(segwit)
+		defer shutdown()
+		cleanTestChain()
+
+		// The previous output is worth 1 BTC.
+		prevHash := randomHash()
+		testChainMtx.Lock()
+		testChain.txRaws[*prevHash] = &btcjson.TxRawResult{
+			Txid: prevHash.String(),
+			Vout: []btcjson.Vout{testVout(1, nil)},
+		}
+		testChainMtx.Unlock()
+
+		// The funding tx pays 5,000 sats in fees for 250 vbytes (500 bytes),
+		// an effective fee rate of 20 sats/vbyte (10 sats/byte without
+		// segwit).
+		txHash := randomHash()
+		vin := testVin(prevHash, 0)
+		vout := testVout(0.99995, nil)
+		tx, err := btc.transaction(txHash, &VerboseTxExtended{
+			Txid:  txHash.String(),
+			Size:  500,
+			Vsize: 250,
+			Vin:   []*btcjson.Vin{&vin},
+			Vout:  []*btcjson.Vout{&vout},
+		})
+		if err != nil {
+			t.Fatalf("segwit = %t: transaction error: %v", segwit, err)
+		}
+		coin := &Output{TXIO: TXIO{btc: btc, tx: tx}, value: 99_995_000}
+
+		feeRate := uint64(10)
+		if segwit {
+			feeRate = 20
+		}
+		if coin.FeeRate() != feeRate {
+			t.Fatalf("segwit = %t: wrong fee rate. wanted %d, got %d", segwit, feeRate, coin.FeeRate())
+		}
+
+		// Adequately paying.
+		if err = btc.ValidateFeeRate(coin, feeRate); err != nil {
+			t.Fatalf("segwit = %t: adequate fee rate rejected: %v", segwit, err)
+		}
+		// Underpaying.
+		err = btc.ValidateFeeRate(coin, feeRate+1)
+		if err == nil {
+			t.Fatalf("segwit = %t: low fee rate not rejected", segwit)
+		}
+		if expMsg := fmt.Sprintf("fee rate %d atoms/vbyte is less than the required %d", feeRate, feeRate+1); !strings.Contains(err.Error(), expMsg) {
+			t.Fatalf("segwit = %t: rejection does not report fee rates: %v", segwit, err)
+		}
+	}
+}
@@ -6,6 +6,7 @@ package btc
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -30,6 +31,7 @@ const (
 	methodGetBlockHeader    = "getblockheader"
 	methodGetBlockStats     = "getblockstats"
 	methodGetBlockHash      = "getblockhash"
+	methodTestMempoolAccept = "testmempoolaccept"
 
 	errNoCompetition = dex.ErrorKind("no competition")
 	errNoFeeRate     = dex.ErrorKind("fee rate could not be estimated")
@@ -197,6 +199,20 @@ func (rc *RPCClient) GetTxOut(txHash *chainhash.Hash, index uint32, mempool bool
 		&res)
 }
 
+// TestMempoolAccept checks whether the node would accept the serialized
+// transaction into its mempool, without broadcasting it. If it would not, the
+// node's reason for rejecting it is returned.
+func (rc *RPCClient) TestMempoolAccept(rawTx []byte) (allowed bool, rejectReason string, err error) {
+	var res []*btcjson.TestMempoolAcceptResult
+	if err := rc.call(methodTestMempoolAccept, anylist{[]string{hex.EncodeToString(rawTx)}}, &res); err != nil {
+		return false, "", err
+	}
+	if len(res) != 1 {
+		return false, "", fmt.Errorf("expected 1 result, got %d", len(res))
+	}
+	return res[0].Allowed, res[0].RejectReason, nil
+}
+
 // GetRawTransaction retrieves tx's information.
 func (rc *RPCClient) GetRawTransaction(txHash *chainhash.Hash) ([]byte, error) {
 	var txB dex.Bytes
@@ -22,6 +22,14 @@ type BackendInfo struct {
 const (
 	CoinNotFoundError = dex.ErrorKind("coin not found")
 	ErrRequestTimeout = dex.ErrorKind("request timeout")
+	// ErrFeeRangeUnsupported is returned from FeeRateRange when the backend's
+	// node cannot estimate a fee rate range, e.g. for clones without
+	// estimatesmartfee.
+	ErrFeeRangeUnsupported = dex.ErrorKind("fee rate range not supported")
+	// ErrTxCheckUnsupported is returned from CheckTransaction when the
+	// backend's node cannot test mempool acceptance, e.g. for clones without
+	// testmempoolaccept.
+	ErrTxCheckUnsupported = dex.ErrorKind("transaction checks not supported")
 )
 
 // Backend is a blockchain backend. TODO: Plumb every method with a cancellable
@@ -67,8 +75,15 @@ type Backend interface {
 	// Info provides auxiliary information about a backend.
 	Info() *BackendInfo
 	// ValidateFeeRate checks that the transaction fees used to initiate the
-	// contract are sufficient.
-	ValidateFeeRate(coin Coin, reqFeeRate uint64) bool
+	// contract are sufficient. If they are not, the error describes the
+	// transaction's effective fee rate and the required fee rate.
+	ValidateFeeRate(coin Coin, reqFeeRate uint64) error
+	// DustLimit is the smallest swap contract value that can be redeemed
+	// economically at the given fee rate. Contracts below this value would
+	// cost more to redeem than they are worth, or would produce dust. The
+	// limit must be proportional to the fee rate, since clients scale the
+	// limit advertised for the MaxFeeRate to the current fee rate.
+	DustLimit(feeRate uint64) uint64
 }
 
 // OutputTracker is implemented by backends for UTXO-based blockchains.
@@ -104,6 +119,143 @@ type AccountBalancer interface {
 	InitTxSize() uint64
 }
 
+// VersionedBackend is implemented by Backends that report the asset versions,
+// e.g. swap contract versions, for which they accept swaps.
+type VersionedBackend interface {
+	// SupportedVersions are the asset versions for which the backend accepts
+	// swaps.
+	SupportedVersions() []uint32
+}
+
+// GasStats summarizes the gas used by recently mined transactions.
+type GasStats struct {
+	Samples int    `json:"samples"`
+	P50     uint64 `json:"p50"`
+	P95     uint64 `json:"p95"`
+	Max     uint64 `json:"max"`
+}
+
+// GasReporter is implemented by account-based Backends that record the gas
+// used by swap transactions, so that operators can tune gas configuration
+// with observed values.
+type GasReporter interface {
+	// GasStats returns statistics for the gas used by recent transactions
+	// with a single initiation and with a single redemption. The stats are
+	// nil if there are no observations.
+	GasStats() (swap, redeem *GasStats)
+}
+
+// NodeConnStats describes a connection to an asset's node.
+type NodeConnStats struct {
+	Endpoint string `json:"endpoint"`
+	Priority uint16 `json:"priority"`
+	// Healthy and Outdated are the results of the last health check. A
+	// connection that is neither healthy nor outdated failed its last check
+	// or request.
+	Healthy  bool `json:"healthy"`
+	Outdated bool `json:"outdated"`
+	// Requests is the number of requests made with the connection, and
+	// Failures is the number of those requests that failed.
+	Requests uint64 `json:"requests"`
+	Failures uint64 `json:"failures"`
+}
+
+// NodeConnReporter is implemented by Backends that maintain a pool of
+// connections to their nodes.
+type NodeConnReporter interface {
+	// NodeConnStats returns the stats of each connection in the pool.
+	NodeConnStats() []*NodeConnStats
+}
+
+// SyncLagReporter is implemented by Backends that can report how far their
+// node's chain is behind the network.
+type SyncLagReporter interface {
+	// SyncLag returns the number of blocks by which the node's best block
+	// trails the best known header.
+	SyncLag() (int64, error)
+}
+
+// TxConfirmer is implemented by Backends that can report the number of
+// confirmations of a transaction.
+type TxConfirmer interface {
+	// TxConfirmations returns the number of confirmations of the transaction
+	// of the coin. CoinNotFoundError is returned if the transaction is not
+	// known to the node, e.g. if it was removed by a reorg.
+	TxConfirmations(coinID []byte) (int64, error)
+}
+
+// RefundVerifier is implemented by Backends that can verify that a swap
+// contract was refunded.
+type RefundVerifier interface {
+	// Refund returns a Coin for refundID, a transaction input that spends
+	// contractID, an output containing the swap contract, via the contract's
+	// refund path. An error is returned if the input does not spend the
+	// contract, or if it spends it with the secret, i.e. it is a redemption.
+	Refund(refundID, contractID, contractData []byte) (Coin, error)
+}
+
+// RedemptionValidator is implemented by Backends that can check whether a
+// redemption of a swap contract would be accepted, without broadcasting it.
+type RedemptionValidator interface {
+	// ValidateRedemption checks whether a redemption of the swap contract
+	// described by contractData with the secret would be accepted in the
+	// swap's current state. If it would not be accepted, valid is false and
+	// the reason is given. An error is only returned if the check could not
+	// be performed.
+	ValidateRedemption(contractData, secret []byte) (valid bool, reason string, err error)
+}
+
+// ContractSwap is the state of a swap in an account-based asset's swap
+// contract.
+type ContractSwap struct {
+	SecretHash  dex.Bytes `json:"secretHash"`
+	State       string    `json:"state"`
+	Initiator   string    `json:"initiator"`
+	Participant string    `json:"participant"`
+	// Value is the swapped amount in atomic units of the asset.
+	Value       uint64    `json:"value"`
+	LockTime    time.Time `json:"lockTime"`
+	BlockHeight uint64    `json:"blockHeight"`
+}
+
+// ContractSwapLister is implemented by Backends that can look up the state of
+// swaps in their swap contract, for reconciling the swaps recorded in the DB
+// with the chain.
+type ContractSwapLister interface {
+	// ContractSwaps retrieves the contract state of the swaps with the given
+	// secret hashes. If account is not empty, swaps not involving the account
+	// are omitted, but swaps that are not in the contract are always
+	// returned.
+	ContractSwaps(secretHashes [][32]byte, account string) ([]*ContractSwap, error)
+}
+
+// TxChecker is implemented by Backends that can check whether their node
+// would accept a transaction, without broadcasting it.
+type TxChecker interface {
+	// CheckTransaction checks whether the node would accept the serialized,
+	// signed transaction, e.g. into its mempool. If the transaction would be
+	// rejected, accepted is false and the reason is given. An error is only
+	// returned if the check could not be performed.
+	CheckTransaction(rawTx []byte) (accepted bool, reason string, err error)
+}
+
+// FeeRateRange is a range of recommended fee rates, in atoms / byte. Fee rates
+// below MinToConfirm are not expected to be mined in a reasonable time.
+// Economical is expected to be mined within a few blocks, and Priority in the
+// next block.
+type FeeRateRange struct {
+	MinToConfirm uint64
+	Economical   uint64
+	Priority     uint64
+}
+
+// FeeRangeEstimator is implemented by Backends that can estimate a range of
+// fee rates in addition to the single rate from FeeRate.
+type FeeRangeEstimator interface {
+	// FeeRateRange estimates the current range of recommended fee rates.
+	FeeRateRange(context.Context) (*FeeRateRange, error)
+}
+
 // TokenBacker is implemented by Backends that support degenerate tokens.
 type TokenBacker interface {
 	TokenBackend(assetID uint32, configPath string) (Backend, error)
@@ -165,8 +317,10 @@ type Contract struct {
 
 // BlockUpdate is sent over the update channel when a tip change is detected.
 type BlockUpdate struct {
-	Err   error
-	Reorg bool // TODO: Not used. Remove and update through btc and dcr. Don't really need the BlockUpdate struct at all.
+	Err error
+	// Reorg is true if the block is not a child of the previous best block.
+	// Swaps that have not reached their asset's reorg depth are re-evaluated.
+	Reorg bool
 }
 
 // ConnectionError error should be sent over the block update channel if a
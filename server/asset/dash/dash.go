@@ -24,8 +24,8 @@ func (d *Driver) Setup(cfg *asset.BackendConfig) (asset.Backend, error) {
 
 // DecodeCoinID creates a human-readable representation of a coin ID for Dash.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Dash and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Version returns the Backend implementation's version number.
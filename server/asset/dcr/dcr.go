@@ -55,11 +55,8 @@ func (d *Driver) Setup(cfg *asset.BackendConfig) (asset.Backend, error) {
 
 // DecodeCoinID creates a human-readable representation of a coin ID for Decred.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	txid, vout, err := decodeCoinID(coinID)
-	if err != nil {
-		return "", err
-	}
-	return fmt.Sprintf("%v:%d", txid, vout), err
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // UnitInfo returns the dex.UnitInfo for the asset.
@@ -277,6 +274,9 @@ type Backend struct {
 
 // Check that Backend satisfies the Backend interface.
 var _ asset.Backend = (*Backend)(nil)
+var _ asset.SyncLagReporter = (*Backend)(nil)
+var _ asset.TxConfirmer = (*Backend)(nil)
+var _ asset.RefundVerifier = (*Backend)(nil)
 
 // unconnectedDCR returns a Backend without a node. The node should be set
 // before use.
@@ -432,8 +432,18 @@ func (*Backend) Info() *asset.BackendInfo {
 
 // ValidateFeeRate checks that the transaction fees used to initiate the
 // contract are sufficient.
-func (dcr *Backend) ValidateFeeRate(c asset.Coin, reqFeeRate uint64) bool {
-	return c.FeeRate() >= reqFeeRate
+func (dcr *Backend) ValidateFeeRate(c asset.Coin, reqFeeRate uint64) error {
+	if feeRate := c.FeeRate(); feeRate < reqFeeRate {
+		return fmt.Errorf("transaction %s fee rate %d atoms/byte is less than the required %d atoms/byte",
+			c.TxID(), feeRate, reqFeeRate)
+	}
+	return nil
+}
+
+// DustLimit is the smallest swap contract value that can be redeemed to a
+// non-dust output at the given fee rate. Part of the asset.Backend interface.
+func (dcr *Backend) DustLimit(feeRate uint64) uint64 {
+	return dexdcr.MinLotSize(feeRate)
 }
 
 // BlockChannel creates and returns a new channel on which to receive block
@@ -509,6 +519,18 @@ func (dcr *Backend) Synced() (bool, error) {
 	return !chainInfo.InitialBlockDownload && chainInfo.Headers-chainInfo.Blocks <= 1, nil
 }
 
+// SyncLag is the number of blocks by which the node's best block trails the
+// best known header. Part of the asset.SyncLagReporter interface.
+func (dcr *Backend) SyncLag() (int64, error) {
+	ctx, cancel := context.WithTimeout(dcr.ctx, 2*time.Second)
+	defer cancel()
+	chainInfo, err := dcr.node.GetBlockChainInfo(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("GetBlockChainInfo error: %w", translateRPCCancelErr(err))
+	}
+	return chainInfo.Headers - chainInfo.Blocks, nil
+}
+
 // Redemption is an input that redeems a swap contract.
 func (dcr *Backend) Redemption(redemptionID, contractID, _ []byte) (asset.Coin, error) {
 	txHash, vin, err := decodeCoinID(redemptionID)
@@ -529,6 +551,37 @@ func (dcr *Backend) Redemption(redemptionID, contractID, _ []byte) (asset.Coin,
 	return input, nil
 }
 
+// Refund returns a Coin for refundID, a transaction input that spends
+// contractID via the refund path of the swap contract. Part of the
+// asset.RefundVerifier interface.
+func (dcr *Backend) Refund(refundID, contractID, contractData []byte) (asset.Coin, error) {
+	input, err := dcr.Redemption(refundID, contractID, contractData)
+	if err != nil {
+		return nil, err
+	}
+	txHash, vin, _ := decodeCoinID(refundID)
+	verboseTx, err := dcr.node.GetRawTransactionVerbose(dcr.ctx, txHash)
+	if err != nil {
+		return nil, fmt.Errorf("GetRawTransactionVerbose for txid %s: %w", txHash, err)
+	}
+	if int(vin) >= len(verboseTx.Vin) {
+		return nil, fmt.Errorf("tx %v has %d inputs (no vin %d)", txHash, len(verboseTx.Vin), vin)
+	}
+	txIn := verboseTx.Vin[vin]
+	if txIn.ScriptSig == nil {
+		return nil, fmt.Errorf("%x has no signature script", refundID)
+	}
+	sigScript, err := hex.DecodeString(txIn.ScriptSig.Hex)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding sigScript: %w", err)
+	}
+	// Swap contracts are version 0 scripts.
+	if !dexdcr.IsRefundScript(0, sigScript, contractData) {
+		return nil, fmt.Errorf("%x does not refund %x", refundID, contractID)
+	}
+	return input, nil
+}
+
 // FundingCoin is an unspent output.
 func (dcr *Backend) FundingCoin(ctx context.Context, coinID []byte, redeemScript []byte) (asset.FundingCoin, error) {
 	txHash, vout, err := decodeCoinID(coinID)
@@ -616,6 +669,23 @@ func (dcr *Backend) TxData(coinID []byte) ([]byte, error) {
 	return stdaddrTx.MsgTx().Bytes()
 }
 
+// TxConfirmations returns the number of confirmations of the transaction of
+// the coin. Part of the asset.TxConfirmer interface.
+func (dcr *Backend) TxConfirmations(coinID []byte) (int64, error) {
+	txHash, _, err := decodeCoinID(coinID)
+	if err != nil {
+		return 0, err
+	}
+	verboseTx, err := dcr.node.GetRawTransactionVerbose(dcr.ctx, txHash)
+	if err != nil {
+		if isTxNotFoundErr(err) {
+			return 0, asset.CoinNotFoundError
+		}
+		return 0, fmt.Errorf("GetRawTransactionVerbose for txid %s: %w", txHash, err)
+	}
+	return verboseTx.Confirmations, nil
+}
+
 // VerifyUnspentCoin attempts to verify a coin ID by decoding the coin ID and
 // retrieving the corresponding UTXO. If the coin is not found or no longer
 // unspent, an asset.CoinNotFoundError is returned.
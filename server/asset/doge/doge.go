@@ -29,8 +29,8 @@ func (d *Driver) Setup(cfg *asset.BackendConfig) (asset.Backend, error) {
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Litecoin.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Litecoin and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Version returns the Backend implementation's version number.
@@ -121,6 +121,7 @@ func NewBackend(cfg *asset.BackendConfig) (asset.Backend, error) {
 		MaxFeeBlocks:         maxFeeBlocks,
 		BooleanGetBlockRPC:   true,
 		BlockDeserializer:    dexdoge.DeserializeBlock,
+		NoTestMempoolAccept:  true,
 		RelayAddr:            cfg.RelayAddr,
 	})
 }
@@ -160,6 +160,16 @@ func IsToken(assetID uint32) (is bool, parentID uint32) {
 	return true, token.TokenInfo().ParentID
 }
 
+// FeeAsset returns the ID of the asset in which the transaction fees of the
+// specified asset are paid. For tokens, this is the parent asset, e.g. ETH for
+// an ERC20 token. For all other assets, it is the asset itself.
+func FeeAsset(assetID uint32) uint32 {
+	if isToken, parentID := IsToken(assetID); isToken {
+		return parentID
+	}
+	return assetID
+}
+
 // Tokens returns the child tokens registered for a base chain asset.
 func Tokens(assetID uint32) map[uint32]*dex.Token {
 	m := make(map[uint32]*dex.Token, len(childTokens[assetID]))
@@ -0,0 +1,41 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package asset
+
+import (
+	"testing"
+
+	"decred.org/dcrdex/dex"
+)
+
+type tDriver struct {
+	token *dex.Token
+}
+
+func (d *tDriver) DecodeCoinID(coinID []byte) (string, error) { return "", nil }
+func (d *tDriver) Version() uint32                            { return 0 }
+func (d *tDriver) Name() string                               { return "test" }
+func (d *tDriver) Setup(*BackendConfig) (Backend, error)      { return nil, nil }
+func (d *tDriver) TokenInfo() *dex.Token                      { return d.token }
+func (d *tDriver) UnitInfo() dex.UnitInfo {
+	return dex.UnitInfo{Conventional: dex.Denomination{ConversionFactor: 1e9}}
+}
+
+func TestFeeAsset(t *testing.T) {
+	const ethID, tokenID, otherID = 60, 60001, 42
+	Register(ethID, &tDriver{})
+	RegisterToken(tokenID, &tDriver{token: &dex.Token{ParentID: ethID, Name: "USDC"}})
+
+	// Token fees are paid in the parent asset.
+	if feeAsset := FeeAsset(tokenID); feeAsset != ethID {
+		t.Fatalf("token fees reported in asset %d, not eth", feeAsset)
+	}
+	if feeAsset := FeeAsset(ethID); feeAsset != ethID {
+		t.Fatalf("eth fees reported in asset %d", feeAsset)
+	}
+	// Unregistered and base chain assets pay their own fees.
+	if feeAsset := FeeAsset(otherID); feeAsset != otherID {
+		t.Fatalf("asset %d fees reported in asset %d", otherID, feeAsset)
+	}
+}
@@ -64,6 +64,9 @@ func (be *AssetBackend) newSwapCoin(coinID []byte, contractData []byte) (*swapCo
 	if !ok {
 		return nil, fmt.Errorf("tx %v does not contain initiation with secret hash %x", bc.txHash, bc.secretHash)
 	}
+	if len(inits) == 1 {
+		be.gas.track(bc.txHash, gasOpSwap)
+	}
 
 	if be.assetID == be.baseChainID {
 		sum := new(big.Int)
@@ -129,6 +132,9 @@ func (be *AssetBackend) newRedeemCoin(coinID []byte, contractData []byte) (*rede
 	if !ok {
 		return nil, fmt.Errorf("tx %v does not contain redemption with secret hash %x", bc.txHash, bc.secretHash)
 	}
+	if len(redemptions) == 1 {
+		be.gas.track(bc.txHash, gasOpRedeem)
+	}
 
 	return &redeemCoin{
 		baseCoin: bc,
@@ -232,18 +238,18 @@ func (c *swapCoin) Confirmations(ctx context.Context) (int64, error) {
 	// this swap. Assert that the value, receiver, and locktime are
 	// as expected.
 	if swap.Value.Cmp(c.init.Value) != 0 {
-		return -1, fmt.Errorf("tx data swap val (%d) does not match contract value (%d)",
-			c.init.Value, swap.Value)
+		return -1, fmt.Errorf("%w: tx data swap val (%d) does not match contract value (%d)",
+			ErrContractMismatch, c.init.Value, swap.Value)
 	}
 	if swap.Participant != c.init.Participant {
-		return -1, fmt.Errorf("tx data participant %q does not match contract value %q",
-			c.init.Participant, swap.Participant)
+		return -1, fmt.Errorf("%w: tx data participant %q does not match contract value %q",
+			ErrContractMismatch, c.init.Participant, swap.Participant)
 	}
 
 	// locktime := swap.RefundBlockTimestamp.Int64()
 	if !swap.LockTime.Equal(c.init.LockTime) {
-		return -1, fmt.Errorf("expected swap locktime (%s) does not match expected (%s)",
-			c.init.LockTime, swap.LockTime)
+		return -1, fmt.Errorf("%w: expected swap locktime (%s) does not match expected (%s)",
+			ErrContractMismatch, c.init.LockTime, swap.LockTime)
 	}
 
 	bn, err := c.backend.node.blockNumber(ctx)
@@ -282,6 +288,12 @@ func (c *redeemCoin) Confirmations(ctx context.Context) (int64, error) {
 	// succeed as the swap must already be in the Initialized state
 	// to redeem. If the swap is in the Refunded state, then the
 	// redemption either failed or never happened.
+	switch swap.State {
+	case dexeth.SSNone:
+		return -1, fmt.Errorf("%w: redemption of swap %x", ErrContractNotFound, c.secretHash)
+	case dexeth.SSRefunded:
+		return -1, fmt.Errorf("%w: redemption of swap %x", ErrSwapRefunded, c.secretHash)
+	}
 	return -1, fmt.Errorf("redemption in failed state with swap at %s state", swap.State)
 }
 
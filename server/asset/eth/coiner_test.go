@@ -94,6 +94,7 @@ func TestNewRedeemCoin(t *testing.T) {
 			contractAddr: *contractAddr,
 			assetID:      BipID,
 			log:          tLogger,
+			gas:          newGasRecorder(),
 		}
 		rc, err := eth.newRedeemCoin(txHash[:], test.contract)
 		if test.wantErr {
@@ -210,6 +211,7 @@ func TestNewSwapCoin(t *testing.T) {
 			},
 			contractAddr: *contractAddr,
 			atomize:      dexeth.WeiToGwei,
+			gas:          newGasRecorder(),
 		}
 		sc, err := eth.newSwapCoin(test.coinID, test.contract)
 		if test.wantErr {
@@ -333,6 +335,7 @@ func TestConfirmations(t *testing.T) {
 			},
 			contractAddr: *contractAddr,
 			atomize:      dexeth.WeiToGwei,
+			gas:          newGasRecorder(),
 		}
 
 		swapData := dexeth.EncodeContractData(0, secretHash)
@@ -4,11 +4,28 @@
 package eth
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 var ethHomeDir = dcrutil.AppDataDir("ethereum", false)
 
+// jwtSecretFile is the name of the file in which geth stores the secret used
+// to authenticate requests to its authenticated RPC server.
+const jwtSecretFile = "jwtsecret"
+
 // For tokens, the file at the config path can contain overrides for
 // token gas values. Gas used for token swaps is dependent on the token contract
 // implementation, and can change without notice. The operator can specify
@@ -17,3 +34,49 @@ type configuredTokenGases struct {
 	Swap   uint64 `ini:"swap"`
 	Redeem uint64 `ini:"redeem"`
 }
+
+// loadJWTSecret reads a hex-encoded 32-byte JWT secret, optionally prefixed
+// with 0x, from the file.
+func loadJWTSecret(path string) (*[32]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := strings.TrimPrefix(strings.TrimSpace(string(b)), "0x")
+	var secret [32]byte
+	if n, err := hex.Decode(secret[:], []byte(s)); err != nil || n != len(secret) || len(s) != 2*len(secret) {
+		return nil, fmt.Errorf("invalid JWT secret in %q. expected %d hex-encoded bytes", path, len(secret))
+	}
+	return &secret, nil
+}
+
+// findJWTSecret looks for the JWT secret file of the geth datadir that holds
+// the ipc file. geth writes the file to the geth subdirectory of its datadir,
+// but the datadir itself is also checked. An empty string is returned if the
+// file is not found.
+func findJWTSecret(ipcPath string) string {
+	dataDir := filepath.Dir(ipcPath)
+	for _, path := range []string{
+		filepath.Join(dataDir, "geth", jwtSecretFile),
+		filepath.Join(dataDir, jwtSecretFile),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// jwtAuth authenticates requests with an HS256 JWT signed with the secret, as
+// required by geth's authenticated RPC server.
+func jwtAuth(secret [32]byte) rpc.HTTPAuth {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	return func(h http.Header) error {
+		claims := enc.EncodeToString([]byte(`{"iat":` + strconv.FormatInt(time.Now().Unix(), 10) + `}`))
+		mac := hmac.New(sha256.New, secret[:])
+		mac.Write([]byte(header + "." + claims))
+		h.Set("Authorization", "Bearer "+header+"."+claims+"."+enc.EncodeToString(mac.Sum(nil)))
+		return nil
+	}
+}
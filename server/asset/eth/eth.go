@@ -23,6 +23,7 @@ import (
 	"decred.org/dcrdex/server/asset"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4"
 	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -74,6 +75,24 @@ const (
 	version            = 0
 )
 
+// Errors that can be classified with errors.Is.
+const (
+	// ErrNodeDisconnected is returned when no node connection could service a
+	// request.
+	ErrNodeDisconnected = dex.ErrorKind("no node connection")
+	// ErrChainIDMismatch indicates that a node is on a different chain than
+	// the backend's network.
+	ErrChainIDMismatch = dex.ErrorKind("chain ID mismatch")
+	// ErrContractNotFound indicates that a swap is not in the swap contract.
+	ErrContractNotFound = dex.ErrorKind("swap not found in contract")
+	// ErrContractMismatch indicates that the swap in the swap contract does
+	// not match the initiation transaction.
+	ErrContractMismatch = dex.ErrorKind("swap does not match contract")
+	// ErrSwapRefunded indicates that a swap expected to be redeemed was
+	// refunded.
+	ErrSwapRefunded = dex.ErrorKind("swap refunded")
+)
+
 var (
 	_ asset.Driver      = (*Driver)(nil)
 	_ asset.TokenBacker = (*ETHBackend)(nil)
@@ -173,10 +192,17 @@ type ethFetcher interface {
 	connect(ctx context.Context) error
 	suggestGasTipCap(ctx context.Context) (*big.Int, error)
 	transaction(ctx context.Context, hash common.Hash) (tx *types.Transaction, isMempool bool, err error)
+	transactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	// estimateGas dry runs the call, returning the gas it uses. If the node
+	// rejects the call, e.g. it reverts, the node's reason is returned
+	// instead.
+	estimateGas(ctx context.Context, msg ethereum.CallMsg) (gas uint64, reason string, err error)
 	// token- and asset-specific methods
 	loadToken(ctx context.Context, assetID uint32, vToken *VersionedToken) error
 	swap(ctx context.Context, assetID uint32, secretHash [32]byte) (*dexeth.SwapState, error)
 	accountBalance(ctx context.Context, assetID uint32, addr common.Address) (*big.Int, error)
+	// connStats returns the stats of each node connection.
+	connStats() []*asset.NodeConnStats
 }
 
 type baseBackend struct {
@@ -188,6 +214,7 @@ type baseBackend struct {
 
 	baseChainID     uint32
 	baseChainName   string
+	chainID         *big.Int
 	versionedTokens map[uint32]*VersionedToken
 
 	// bestHeight is the last best known chain tip height. bestHeight is set
@@ -195,6 +222,10 @@ type baseBackend struct {
 	// loop thereafter. Do not use bestHeight outside of the poll loop unless
 	// you change it to an atomic.
 	bestHeight uint64
+	// bestHash is the hash of the block at bestHeight, used to detect reorgs.
+	// It is zero until the first tip change, and only accessed in the poll
+	// loop.
+	bestHash common.Hash
 
 	// A logger will be provided by the DEX. All logging should use the provided
 	// logger.
@@ -223,6 +254,9 @@ type AssetBackend struct {
 	redeemSize uint64
 
 	contractAddr common.Address
+
+	// gas records the gas used by swap transactions.
+	gas *gasRecorder
 }
 
 // ETHBackend implements some Ethereum-specific methods.
@@ -244,6 +278,30 @@ var _ asset.Backend = (*ETHBackend)(nil)
 var _ asset.AccountBalancer = (*TokenBackend)(nil)
 var _ asset.AccountBalancer = (*ETHBackend)(nil)
 
+// Check that Backend satisfies the VersionedBackend interface.
+var _ asset.VersionedBackend = (*TokenBackend)(nil)
+var _ asset.VersionedBackend = (*ETHBackend)(nil)
+
+// Check that Backend satisfies the GasReporter interface.
+var _ asset.GasReporter = (*TokenBackend)(nil)
+var _ asset.GasReporter = (*ETHBackend)(nil)
+
+// Check that Backend satisfies the NodeConnReporter interface.
+var _ asset.NodeConnReporter = (*TokenBackend)(nil)
+var _ asset.NodeConnReporter = (*ETHBackend)(nil)
+
+// Check that Backend satisfies the TxChecker interface.
+var _ asset.TxChecker = (*TokenBackend)(nil)
+var _ asset.TxChecker = (*ETHBackend)(nil)
+
+// Check that Backend satisfies the RedemptionValidator interface.
+var _ asset.RedemptionValidator = (*TokenBackend)(nil)
+var _ asset.RedemptionValidator = (*ETHBackend)(nil)
+
+// Check that Backend satisfies the TxConfirmer interface.
+var _ asset.TxConfirmer = (*TokenBackend)(nil)
+var _ asset.TxConfirmer = (*ETHBackend)(nil)
+
 // unconnectedETH returns a Backend without a node. The node should be set
 // before use.
 func unconnectedETH(bipID uint32, contractAddr common.Address, vTokens map[uint32]*VersionedToken, logger dex.Logger, net dex.Network) (*ETHBackend, error) {
@@ -267,10 +325,20 @@ func unconnectedETH(bipID uint32, contractAddr common.Address, vTokens map[uint3
 		redeemSize:   dexeth.RedeemGas(1, ethContractVersion),
 		assetID:      bipID,
 		atomize:      dexeth.WeiToGwei,
+		gas:          newGasRecorder(),
 	}}, nil
 }
 
-func parseEndpoints(cfg *asset.BackendConfig) ([]endpoint, error) {
+// parseEndpoints parses the RPC endpoints from the relay address and the config
+// file. The config file may also have a line of the form "pool=4" setting the
+// number of connections made to each endpoint, which is returned with the
+// endpoints. A pool size of
+// zero is returned if none is specified. A line of the form "jwt=/path/to/file"
+// specifies the JWT secret with which requests to the websocket and http
+// endpoints in the file are authenticated. If there is no jwt line and an ipc
+// file in a geth datadir is configured, the jwtsecret file that geth writes to
+// the datadir is used.
+func parseEndpoints(cfg *asset.BackendConfig) ([]endpoint, int, error) {
 	var endpoints []endpoint
 	if cfg.RelayAddr != "" {
 		endpoints = append(endpoints, endpoint{
@@ -281,14 +349,17 @@ func parseEndpoints(cfg *asset.BackendConfig) ([]endpoint, error) {
 	file, err := os.Open(cfg.ConfigPath)
 	if err != nil {
 		if os.IsNotExist(err) && len(endpoints) > 0 {
-			return endpoints, nil
+			return endpoints, 0, nil
 		}
-		return nil, err
+		return nil, 0, err
 	}
 	defer file.Close()
 
 	assetName := strings.ToUpper(dex.BipIDSymbol(cfg.AssetID))
 
+	var poolSize int
+	var jwtPath, ipcPath string
+	fileEndpointsStart := len(endpoints)
 	endpointsMap := make(map[string]bool) // to avoid duplicates
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -296,12 +367,24 @@ func parseEndpoints(cfg *asset.BackendConfig) ([]endpoint, error) {
 		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
 			continue
 		}
+		if k, v, found := strings.Cut(line, "="); found && strings.TrimSpace(k) == "pool" {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil || n < 1 || n > maxPoolSize {
+				return nil, 0, fmt.Errorf("invalid %s connection pool size %q. must be between 1 and %d", assetName, v, maxPoolSize)
+			}
+			poolSize = n
+			continue
+		}
+		if k, v, found := strings.Cut(line, "="); found && strings.TrimSpace(k) == "jwt" {
+			jwtPath = dex.CleanAndExpandPath(strings.TrimSpace(v))
+			continue
+		}
 		ethCfgInstructions := "invalid %s config line: \"%s\". " +
 			"Each line must contain URL and optionally a priority (between 0-65535) " +
 			"separated by a comma. Example: \"https://www.infura.io/,2\""
 		parts := strings.Split(line, ",")
 		if len(parts) < 1 || len(parts) > 2 {
-			return nil, fmt.Errorf(ethCfgInstructions, assetName, line)
+			return nil, 0, fmt.Errorf(ethCfgInstructions, assetName, line)
 		}
 
 		url := strings.TrimSpace(parts[0])
@@ -309,13 +392,16 @@ func parseEndpoints(cfg *asset.BackendConfig) ([]endpoint, error) {
 		if len(parts) == 2 {
 			priority64, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 16)
 			if err != nil {
-				return nil, fmt.Errorf(ethCfgInstructions, line)
+				return nil, 0, fmt.Errorf(ethCfgInstructions, assetName, line)
 			}
 			priority = uint16(priority64)
 		}
 		if endpointsMap[url] {
 			continue
 		}
+		if strings.HasSuffix(url, ".ipc") && ipcPath == "" {
+			ipcPath = url
+		}
 		endpointsMap[line] = true
 		endpoints = append(endpoints, endpoint{
 			url:      url,
@@ -323,13 +409,38 @@ func parseEndpoints(cfg *asset.BackendConfig) ([]endpoint, error) {
 		})
 	}
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading %s config file at %q. %v", assetName, cfg.ConfigPath, err)
+		return nil, 0, fmt.Errorf("error reading %s config file at %q. %v", assetName, cfg.ConfigPath, err)
 	}
 	if len(endpoints) == 0 {
-		return nil, fmt.Errorf("no endpoint found in the %s config file at %q", assetName, cfg.ConfigPath)
+		return nil, 0, fmt.Errorf("no endpoint found in the %s config file at %q", assetName, cfg.ConfigPath)
+	}
+
+	var authEndpoints []*endpoint // websocket and http endpoints in the file
+	for i := fileEndpointsStart; i < len(endpoints); i++ {
+		if !strings.HasSuffix(endpoints[i].url, ".ipc") {
+			authEndpoints = append(authEndpoints, &endpoints[i])
+		}
+	}
+	if jwtPath == "" && ipcPath != "" && len(authEndpoints) > 0 {
+		if jwtPath = findJWTSecret(ipcPath); jwtPath != "" {
+			cfg.Logger.Infof("Using %s JWT secret %q found in the datadir of %q", assetName, jwtPath, ipcPath)
+		} else {
+			cfg.Logger.Warnf("No %s JWT secret found in the datadir of %q. Requests to other endpoints "+
+				"will not be authenticated. Specify the secret with a jwt line in the config file if required.",
+				assetName, ipcPath)
+		}
+	}
+	if jwtPath != "" {
+		secret, err := loadJWTSecret(jwtPath)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error loading %s JWT secret: %w", assetName, err)
+		}
+		for _, ep := range authEndpoints {
+			ep.jwtSecret = secret
+		}
 	}
 
-	return endpoints, nil
+	return endpoints, poolSize, nil
 }
 
 // NewEVMBackend is the exported constructor by which the DEX will import the
@@ -341,7 +452,7 @@ func NewEVMBackend(
 	vTokens map[uint32]*VersionedToken,
 ) (*ETHBackend, error) {
 
-	endpoints, err := parseEndpoints(cfg)
+	endpoints, poolSize, err := parseEndpoints(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -363,7 +474,8 @@ func NewEVMBackend(
 		return nil, err
 	}
 
-	eth.node = newRPCClient(baseChainID, chainID, net, endpoints, contractAddr, log.SubLogger("RPC"))
+	eth.chainID = new(big.Int).SetUint64(chainID)
+	eth.node = newRPCClient(baseChainID, chainID, net, endpoints, poolSize, contractAddr, log.SubLogger("RPC"))
 	return eth, nil
 }
 
@@ -460,6 +572,7 @@ func (eth *ETHBackend) TokenBackend(assetID uint32, configPath string) (asset.Ba
 			redeemSize:   gases.Redeem,
 			contractAddr: swapContract.Address,
 			atomize:      vToken.EVMToAtomic,
+			gas:          newGasRecorder(),
 		},
 		VersionedToken: vToken,
 	}
@@ -476,6 +589,9 @@ func (eth *baseBackend) TxData(coinID []byte) ([]byte, error) {
 
 	tx, _, err := eth.node.transaction(eth.ctx, txHash)
 	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return nil, asset.CoinNotFoundError
+		}
 		return nil, fmt.Errorf("error retrieving transaction: %w", err)
 	}
 	if tx == nil { // Possible?
@@ -485,11 +601,105 @@ func (eth *baseBackend) TxData(coinID []byte) ([]byte, error) {
 	return tx.MarshalBinary()
 }
 
+// TxConfirmations returns the number of confirmations of the transaction of
+// the coin, according to its receipt. Part of the asset.TxConfirmer interface.
+func (eth *baseBackend) TxConfirmations(coinID []byte) (int64, error) {
+	txHash, err := dexeth.DecodeCoinID(coinID)
+	if err != nil {
+		return 0, fmt.Errorf("coin ID decoding error: %v", err)
+	}
+	receipt, err := eth.node.transactionReceipt(eth.ctx, txHash)
+	if err != nil {
+		if errors.Is(err, ethereum.NotFound) {
+			return 0, asset.CoinNotFoundError
+		}
+		return 0, fmt.Errorf("error retrieving transaction receipt: %w", err)
+	}
+	if receipt.BlockNumber == nil || receipt.BlockNumber.Sign() == 0 {
+		return 0, nil
+	}
+	bn, err := eth.node.blockNumber(eth.ctx)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch block number: %v", err)
+	}
+	txHeight := receipt.BlockNumber.Uint64()
+	if txHeight > bn {
+		return 0, nil
+	}
+	return int64(bn - txHeight + 1), nil
+}
+
+// CheckTransaction checks whether the node would accept the serialized, signed
+// transaction. There is no mempool acceptance test for an account-based
+// chain, so the transaction's signature and fee cap are checked, and the
+// transaction is dry run with the node's estimateGas. Part of the
+// asset.TxChecker interface.
+func (eth *baseBackend) CheckTransaction(rawTx []byte) (accepted bool, reason string, err error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(rawTx); err != nil {
+		return false, fmt.Sprintf("invalid transaction: %v", err), nil
+	}
+	if tx.ChainId().Cmp(eth.chainID) != 0 {
+		return false, fmt.Sprintf("wrong chain ID %s", tx.ChainId()), nil
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(eth.chainID), tx)
+	if err != nil {
+		return false, fmt.Sprintf("invalid signature: %v", err), nil
+	}
+	hdr, err := eth.node.bestHeader(eth.ctx)
+	if err != nil {
+		return false, "", fmt.Errorf("error getting best header: %w", err)
+	}
+	if hdr.BaseFee != nil && tx.GasFeeCap().Cmp(hdr.BaseFee) < 0 {
+		return false, fmt.Sprintf("gas fee cap %s wei / gas is less than the base fee %s wei / gas",
+			tx.GasFeeCap(), hdr.BaseFee), nil
+	}
+	_, reason, err = eth.node.estimateGas(eth.ctx, ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		GasFeeCap:  tx.GasFeeCap(),
+		GasTipCap:  tx.GasTipCap(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	})
+	if err != nil {
+		return false, "", fmt.Errorf("error estimating gas: %w", err)
+	}
+	return reason == "", reason, nil
+}
+
 // InitTxSize is an upper limit on the gas used for an initiation.
 func (be *AssetBackend) InitTxSize() uint64 {
 	return be.initTxSize
 }
 
+// SupportedVersions reports the swap contract versions for which the backend
+// accepts swaps. Part of the asset.VersionedBackend interface.
+func (eth *ETHBackend) SupportedVersions() []uint32 {
+	return []uint32{ethContractVersion}
+}
+
+// SupportedVersions reports the token swap contract versions for which the
+// backend accepts swaps. Part of the asset.VersionedBackend interface.
+func (eth *TokenBackend) SupportedVersions() []uint32 {
+	return []uint32{eth.VersionedToken.Ver}
+}
+
+// DustLimit is the smallest swap contract value that covers the gas needed to
+// redeem it at the given fee rate (gwei / gas). Part of the asset.Backend
+// interface.
+func (eth *ETHBackend) DustLimit(feeRate uint64) uint64 {
+	return feeRate * eth.RedeemSize()
+}
+
+// DustLimit is zero for tokens, since redemption gas is paid in the parent
+// asset. Part of the asset.Backend interface.
+func (eth *TokenBackend) DustLimit(uint64) uint64 {
+	return 0
+}
+
 // RedeemSize is the same as (dex.Asset).RedeemSize for the asset.
 func (be *AssetBackend) RedeemSize() uint64 {
 	return be.redeemSize
@@ -531,26 +741,32 @@ func (*baseBackend) Info() *asset.BackendInfo {
 // ValidateFeeRate checks that the transaction fees used to initiate the
 // contract are sufficient. For most assets only the contract.FeeRate() cannot
 // be less than reqFeeRate, but for Eth, the gasTipCap must also be checked.
-func (eth *baseBackend) ValidateFeeRate(coin asset.Coin, reqFeeRate uint64) bool {
+func (eth *baseBackend) ValidateFeeRate(coin asset.Coin, reqFeeRate uint64) error {
 	sc, ok := coin.(*swapCoin)
 	if !ok {
-		eth.baseLogger.Error("%v contract coin type must be a swapCoin but got %T", eth.baseChainName, sc)
-		return false
+		return fmt.Errorf("%v contract coin type must be a swapCoin but got %T", eth.baseChainName, coin)
 	}
 
 	// Legacy transactions are also supported. In a legacy transaction, the
 	// gas tip cap will be equal to the gas price.
-	if dexeth.WeiToGwei(sc.gasTipCap) < dexeth.MinGasTipCap {
-		sc.backend.log.Errorf("Transaction %s tip cap %d < %d", dexeth.WeiToGwei(sc.gasTipCap), dexeth.MinGasTipCap)
-		return false
+	if tipCap := dexeth.WeiToGwei(sc.gasTipCap); tipCap < dexeth.MinGasTipCap {
+		return fmt.Errorf("transaction %s gas tip cap %d gwei / gas is less than the minimum %d gwei / gas",
+			sc.TxID(), tipCap, dexeth.MinGasTipCap)
 	}
 
 	if sc.gasFeeCap.Cmp(dexeth.GweiToWei(reqFeeRate)) < 0 {
-		sc.backend.log.Errorf("Transaction %s gas fee cap too low. %s wei / gas < %s gwei / gas", sc.gasFeeCap, reqFeeRate)
-		return false
+		return fmt.Errorf("transaction %s gas fee cap %s wei / gas is less than the required %d gwei / gas",
+			sc.TxID(), sc.gasFeeCap, reqFeeRate)
 	}
 
-	return true
+	return nil
+}
+
+// NodeConnStats returns the stats of each connection in the node connection
+// pool, which is shared by the base chain asset and its tokens. Part of the
+// asset.NodeConnReporter interface.
+func (be *AssetBackend) NodeConnStats() []*asset.NodeConnStats {
+	return be.node.connStats()
 }
 
 // BlockChannel creates and returns a new channel on which to receive block
@@ -624,7 +840,7 @@ func (be *AssetBackend) Contract(coinID, contractData []byte) (*asset.Contract,
 	// mined) to verify the value, counterparty, and lock time.
 	_, err = sc.Confirmations(be.ctx)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get confirmations: %v", err)
+		return nil, fmt.Errorf("unable to get confirmations: %w", err)
 	}
 	return &asset.Contract{
 		Coin:         sc,
@@ -646,6 +862,40 @@ func (eth *baseBackend) ValidateSecret(secret, contractData []byte) bool {
 	return bytes.Equal(sh[:], secretHash[:])
 }
 
+// ValidateRedemption checks that a redemption of the swap with the secret would
+// be accepted by the swap contract, without broadcasting anything. The secret
+// must satisfy the secret hash encoded in contractData, and the swap must be
+// initiated and not yet redeemed or refunded. Clients can use this to avoid
+// paying for a redemption that will fail. Part of the
+// asset.RedemptionValidator interface.
+func (be *AssetBackend) ValidateRedemption(contractData, secret []byte) (bool, string, error) {
+	contractVer, secretHash, err := dexeth.DecodeContractData(contractData)
+	if err != nil {
+		return false, fmt.Sprintf("invalid contract data: %v", err), nil
+	}
+	switch contractVer {
+	case version:
+		return be.validateRedemptionV0(secretHash, secret)
+	default:
+		return false, fmt.Sprintf("contract version %d not supported", contractVer), nil
+	}
+}
+
+// validateRedemptionV0 validates a redemption of a version 0 swap contract.
+func (be *AssetBackend) validateRedemptionV0(secretHash [32]byte, secret []byte) (bool, string, error) {
+	if sh := sha256.Sum256(secret); sh != secretHash {
+		return false, fmt.Sprintf("secret does not satisfy secret hash %x", secretHash), nil
+	}
+	ss, err := be.node.swap(be.ctx, be.assetID, secretHash)
+	if err != nil {
+		return false, "", fmt.Errorf("error retrieving swap %x: %w", secretHash, err)
+	}
+	if ss.State != dexeth.SSInitiated {
+		return false, fmt.Sprintf("swap %x is not redeemable in state %s", secretHash, ss.State), nil
+	}
+	return true, "", nil
+}
+
 // Synced is true if the blockchain is ready for action.
 func (eth *baseBackend) Synced() (bool, error) {
 	bh, err := eth.node.bestHeader(eth.ctx)
@@ -674,7 +924,7 @@ func (be *AssetBackend) Redemption(redeemCoinID, _, contractData []byte) (asset.
 	// is mined. For redeem coins, this is just a swap state check.
 	_, err = rc.Confirmations(be.ctx)
 	if err != nil {
-		return nil, fmt.Errorf("unable to get confirmations: %v", err)
+		return nil, fmt.Errorf("unable to get confirmations: %w", err)
 	}
 	return rc, nil
 }
@@ -703,6 +953,47 @@ func (be *AssetBackend) AccountBalance(addrStr string) (uint64, error) {
 	return be.atomize(bigBal), nil
 }
 
+// ContractSwaps retrieves the contract state of the swaps with the given secret
+// hashes, for reconciling the swaps recorded in the DB with the chain. The
+// swap contract cannot enumerate swaps, so the secret hashes must be provided
+// by the caller. If account is not empty, swaps for which the account is
+// neither the initiator nor the participant are omitted. Swaps that do not
+// exist in the contract are always returned, with state SSNone, since they
+// may indicate a DB record without a contract. Token swaps are looked up in
+// the token's swap contract, and their values are in the token's units.
+func (be *AssetBackend) ContractSwaps(secretHashes [][32]byte, account string) ([]*asset.ContractSwap, error) {
+	var acct common.Address
+	if account != "" {
+		if !common.IsHexAddress(account) {
+			return nil, fmt.Errorf("invalid account address %q", account)
+		}
+		acct = common.HexToAddress(account)
+	}
+	swaps := make([]*asset.ContractSwap, 0, len(secretHashes))
+	for _, secretHash := range secretHashes {
+		ss, err := be.node.swap(be.ctx, be.assetID, secretHash)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving swap %x: %w", secretHash, err)
+		}
+		if ss.State != dexeth.SSNone && account != "" && ss.Initiator != acct && ss.Participant != acct {
+			continue
+		}
+		swap := &asset.ContractSwap{
+			SecretHash:  append([]byte(nil), secretHash[:]...),
+			State:       ss.State.String(),
+			Initiator:   ss.Initiator.String(),
+			Participant: ss.Participant.String(),
+			LockTime:    ss.LockTime,
+			BlockHeight: ss.BlockHeight,
+		}
+		if ss.Value != nil {
+			swap.Value = be.atomize(ss.Value)
+		}
+		swaps = append(swaps, swap)
+	}
+	return swaps, nil
+}
+
 // ValidateSignature checks that the pubkey is correct for the address and
 // that the signature shows ownership of the associated private key.
 func (eth *baseBackend) ValidateSignature(addr string, pubkey, msg, sig []byte) error {
@@ -743,16 +1034,18 @@ func (eth *baseBackend) ValidateSignature(addr string, pubkey, msg, sig []byte)
 	return nil
 }
 
-// poll pulls the best hash from an eth node and compares that to a stored
-// hash. If the same does nothing. If different, updates the stored hash and
-// notifies listeners on block chans.
+// poll pulls the best height from an eth node and compares that to a stored
+// height. If the same does nothing. If different, updates the stored height
+// and hash and notifies listeners on block chans. The update reports a reorg if
+// the previous best block is no longer in the chain.
 func (eth *ETHBackend) poll(ctx context.Context) {
-	send := func(err error) {
+	send := func(err error, reorg bool) {
 		if err != nil {
 			eth.log.Error(err)
 		}
 		u := &asset.BlockUpdate{
-			Err: err,
+			Err:   err,
+			Reorg: reorg,
 		}
 
 		eth.sendBlockUpdate(u)
@@ -763,7 +1056,7 @@ func (eth *ETHBackend) poll(ctx context.Context) {
 	}
 	bn, err := eth.node.blockNumber(ctx)
 	if err != nil {
-		send(fmt.Errorf("error getting best block header: %w", err))
+		send(fmt.Errorf("error getting best block header: %w", err), false)
 		return
 	}
 	if bn == eth.bestHeight {
@@ -771,8 +1064,51 @@ func (eth *ETHBackend) poll(ctx context.Context) {
 		return
 	}
 	eth.log.Debugf("Tip change from %d to %d.", eth.bestHeight, bn)
+	reorg := eth.checkReorg(ctx, bn)
+	if reorg {
+		eth.log.Infof("Reorg detected at height %d.", bn)
+	}
 	eth.bestHeight = bn
-	send(nil)
+	send(nil, reorg)
+
+	// Check for newly mined transactions to record gas used.
+	if err := eth.gas.check(ctx, eth.node.transactionReceipt); err != nil {
+		eth.log.Debugf("Error checking %s transaction gas: %v", eth.baseChainName, err)
+	}
+	for assetID, be := range eth.tokens {
+		if err := be.gas.check(ctx, eth.node.transactionReceipt); err != nil {
+			eth.log.Debugf("Error checking %s transaction gas: %v", dex.BipIDSymbol(assetID), err)
+		}
+	}
+}
+
+// checkReorg checks whether the previous best block is still in the chain with
+// the new tip at height bn, and records the new best block hash. A reorg is
+// not reported if the previous best block hash is unknown.
+func (eth *ETHBackend) checkReorg(ctx context.Context, bn uint64) bool {
+	prevHeight, prevHash := eth.bestHeight, eth.bestHash
+	eth.bestHash = common.Hash{}
+	hdr, err := eth.node.headerByHeight(ctx, bn)
+	if err != nil {
+		eth.log.Errorf("Error getting header at height %d: %v", bn, err)
+		return false
+	}
+	eth.bestHash = hdr.Hash()
+	if prevHash == (common.Hash{}) {
+		return false
+	}
+	switch {
+	case bn <= prevHeight:
+		return true
+	case bn == prevHeight+1:
+		return hdr.ParentHash != prevHash
+	}
+	prevHdr, err := eth.node.headerByHeight(ctx, prevHeight)
+	if err != nil {
+		eth.log.Errorf("Error getting header at height %d: %v", prevHeight, err)
+		return false
+	}
+	return prevHdr.Hash() != prevHash
 }
 
 // run processes the queue and monitors the application context.
@@ -7,13 +7,20 @@ package eth
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -26,6 +33,9 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 const initLocktime = 1632112916
@@ -110,6 +120,15 @@ type testNode struct {
 	txErr            error
 	acctBal          *big.Int
 	acctBalErr       error
+	gasEstReason     string
+	gasEstErr        error
+	gasEstMsg        *ethereum.CallMsg
+
+	// swaps, if set, are returned by swap instead of swp.
+	swaps map[[32]byte]*dexeth.SwapState
+	// hdrs, if set, are returned by headerByHeight instead of hdrByHeight.
+	hdrs    map[uint64]*types.Header
+	receipt *types.Receipt
 }
 
 func (n *testNode) connect(ctx context.Context) error {
@@ -118,6 +137,10 @@ func (n *testNode) connect(ctx context.Context) error {
 
 func (n *testNode) shutdown() {}
 
+func (n *testNode) connStats() []*asset.NodeConnStats {
+	return nil
+}
+
 func (n *testNode) loadToken(context.Context, uint32, *VersionedToken) error {
 	return nil
 }
@@ -127,6 +150,12 @@ func (n *testNode) bestHeader(ctx context.Context) (*types.Header, error) {
 }
 
 func (n *testNode) headerByHeight(ctx context.Context, height uint64) (*types.Header, error) {
+	if n.hdrs != nil && n.hdrByHeightErr == nil {
+		if hdr, found := n.hdrs[height]; found {
+			return hdr, nil
+		}
+		return nil, ethereum.NotFound
+	}
 	return n.hdrByHeight, n.hdrByHeightErr
 }
 
@@ -143,6 +172,12 @@ func (n *testNode) suggestGasTipCap(ctx context.Context) (*big.Int, error) {
 }
 
 func (n *testNode) swap(ctx context.Context, assetID uint32, secretHash [32]byte) (*dexeth.SwapState, error) {
+	if n.swaps != nil && n.swpErr == nil {
+		if ss, found := n.swaps[secretHash]; found {
+			return ss, nil
+		}
+		return new(dexeth.SwapState), nil
+	}
 	return n.swp, n.swpErr
 }
 
@@ -150,6 +185,18 @@ func (n *testNode) transaction(ctx context.Context, hash common.Hash) (tx *types
 	return n.tx, n.txIsMempool, n.txErr
 }
 
+func (n *testNode) transactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if n.receipt == nil {
+		return nil, ethereum.NotFound
+	}
+	return n.receipt, nil
+}
+
+func (n *testNode) estimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, string, error) {
+	n.gasEstMsg = &msg
+	return msg.Gas, n.gasEstReason, n.gasEstErr
+}
+
 func (n *testNode) accountBalance(ctx context.Context, assetID uint32, addr common.Address) (*big.Int, error) {
 	return n.acctBal, n.acctBalErr
 }
@@ -177,6 +224,7 @@ func tNewBackend(assetID uint32) (*AssetBackend, *testNode) {
 		assetID:    assetID,
 		blockChans: make(map[chan *asset.BlockUpdate]struct{}),
 		atomize:    dexeth.WeiToGwei,
+		gas:        newGasRecorder(),
 	}, node
 }
 
@@ -342,6 +390,98 @@ func TestFeeRate(t *testing.T) {
 	}
 }
 
+func TestCheckTransaction(t *testing.T) {
+	chainID := big.NewInt(42)
+	privKey, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(privKey.PublicKey)
+	to := common.HexToAddress("0x2b84C791b79Ee37De042AD2ffF1A253c3ce9bc27")
+	baseFee := big.NewInt(dexeth.GweiFactor * 10)
+	signedTx := func(txChainID *big.Int, gasFeeCap *big.Int) []byte {
+		t.Helper()
+		tx, err := types.SignNewTx(privKey, types.LatestSignerForChainID(txChainID), &types.DynamicFeeTx{
+			ChainID:   txChainID,
+			Nonce:     1,
+			GasTipCap: big.NewInt(dexeth.GweiFactor * 2),
+			GasFeeCap: gasFeeCap,
+			Gas:       21000,
+			To:        &to,
+			Value:     big.NewInt(dexeth.GweiFactor),
+		})
+		if err != nil {
+			t.Fatalf("error signing tx: %v", err)
+		}
+		rawTx, _ := tx.MarshalBinary()
+		return rawTx
+	}
+	goodTx := signedTx(chainID, new(big.Int).Mul(baseFee, big.NewInt(2)))
+
+	tests := []struct {
+		name         string
+		rawTx        []byte
+		hdrErr       error
+		gasEstReason string
+		gasEstErr    error
+		wantAccepted bool
+		wantErr      bool
+	}{{
+		name:         "ok",
+		rawTx:        goodTx,
+		wantAccepted: true,
+	}, {
+		name:  "invalid tx",
+		rawTx: []byte{0x02, 0x01},
+	}, {
+		name:  "wrong chain ID",
+		rawTx: signedTx(big.NewInt(1), new(big.Int).Mul(baseFee, big.NewInt(2))),
+	}, {
+		name:  "fee cap below base fee",
+		rawTx: signedTx(chainID, new(big.Int).Sub(baseFee, big.NewInt(1))),
+	}, {
+		name:         "dry run rejected",
+		rawTx:        goodTx,
+		gasEstReason: "insufficient funds for gas * price + value",
+	}, {
+		name:    "node header err",
+		rawTx:   goodTx,
+		hdrErr:  errors.New(""),
+		wantErr: true,
+	}, {
+		name:      "node estimate gas err",
+		rawTx:     goodTx,
+		gasEstErr: errors.New(""),
+		wantErr:   true,
+	}}
+
+	for _, test := range tests {
+		eth, node := tNewBackend(BipID)
+		eth.chainID = chainID
+		node.bestHdr = &types.Header{BaseFee: baseFee}
+		node.bestHdrErr = test.hdrErr
+		node.gasEstReason = test.gasEstReason
+		node.gasEstErr = test.gasEstErr
+
+		accepted, reason, err := eth.CheckTransaction(test.rawTx)
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("expected error for test %q", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error for test %q: %v", test.name, err)
+		}
+		if accepted != test.wantAccepted {
+			t.Fatalf("want accepted %t got %t (%q) for test %q", test.wantAccepted, accepted, reason, test.name)
+		}
+		if !accepted && reason == "" {
+			t.Fatalf("no reason for rejection for test %q", test.name)
+		}
+		if accepted && (node.gasEstMsg == nil || node.gasEstMsg.From != from || *node.gasEstMsg.To != to) {
+			t.Fatalf("wrong dry run call for test %q: %+v", test.name, node.gasEstMsg)
+		}
+	}
+}
+
 func TestSynced(t *testing.T) {
 	tests := []struct {
 		name                    string
@@ -436,6 +576,7 @@ func TestContract(t *testing.T) {
 		swap           *dexeth.SwapState
 		swapErr, txErr error
 		wantErr        bool
+		wantErrKind    error
 	}{{
 		name:     "ok",
 		tx:       tTx(gasPrice, gasTipCap, txVal, contractAddr, initCalldata),
@@ -456,6 +597,14 @@ func TestContract(t *testing.T) {
 		coinID:   txHash[:],
 		swapErr:  errors.New(""),
 		wantErr:  true,
+	}, {
+		name:        "confirmations error, contract value mismatch",
+		tx:          tTx(gasPrice, gasTipCap, txVal, contractAddr, initCalldata),
+		contract:    dexeth.EncodeContractData(0, secretHash),
+		swap:        tSwap(97, initLocktime, swapVal+1, secret, dexeth.SSInitiated, &initParticipantAddr),
+		coinID:      txHash[:],
+		wantErr:     true,
+		wantErrKind: ErrContractMismatch,
 	}}
 	for _, test := range tests {
 		eth, node := tNewBackend(BipID)
@@ -471,6 +620,9 @@ func TestContract(t *testing.T) {
 			if err == nil {
 				t.Fatalf("expected error for test %q", test.name)
 			}
+			if test.wantErrKind != nil && !errors.Is(err, test.wantErrKind) {
+				t.Fatalf("expected %v error for test %q, got %v", test.wantErrKind, test.name, err)
+			}
 			continue
 		}
 		if err != nil {
@@ -498,16 +650,18 @@ func TestValidateFeeRate(t *testing.T) {
 
 	eth, _ := tNewBackend(BipID)
 
-	if !eth.ValidateFeeRate(contract.Coin, 100) {
-		t.Fatalf("expected valid fee rate, but was not valid")
+	if err := eth.ValidateFeeRate(contract.Coin, 100); err != nil {
+		t.Fatalf("expected valid fee rate, but got %v", err)
 	}
 
-	if eth.ValidateFeeRate(contract.Coin, 101) {
+	if err := eth.ValidateFeeRate(contract.Coin, 101); err == nil {
 		t.Fatalf("expected invalid fee rate, but was valid")
+	} else if !strings.Contains(err.Error(), "100000000000 wei / gas is less than the required 101 gwei / gas") {
+		t.Fatalf("fee rates not reported in error: %v", err)
 	}
 
 	swapCoin.gasTipCap = dexeth.GweiToWei(dexeth.MinGasTipCap - 1)
-	if eth.ValidateFeeRate(contract.Coin, 100) {
+	if err := eth.ValidateFeeRate(contract.Coin, 100); err == nil {
 		t.Fatalf("expected invalid fee rate, but was valid")
 	}
 }
@@ -557,6 +711,7 @@ func TestRedemption(t *testing.T) {
 		txIsMempool        bool
 		swpErr, txErr      error
 		wantErr            bool
+		wantErrKind        error
 	}{{
 		name:       "ok",
 		tx:         tTx(gasPrice, gasTipCap, 0, contractAddr, redeemCalldata),
@@ -570,12 +725,21 @@ func TestRedemption(t *testing.T) {
 		coinID:     txHash[1:],
 		wantErr:    true,
 	}, {
-		name:       "confirmations error, swap wrong state",
-		tx:         tTx(gasPrice, gasTipCap, 0, contractAddr, redeemCalldata),
-		contractID: dexeth.EncodeContractData(0, secretHash),
-		swp:        tSwap(0, 0, 0, secret, dexeth.SSRefunded, receiverAddr),
-		coinID:     txHash[:],
-		wantErr:    true,
+		name:        "confirmations error, swap wrong state",
+		tx:          tTx(gasPrice, gasTipCap, 0, contractAddr, redeemCalldata),
+		contractID:  dexeth.EncodeContractData(0, secretHash),
+		swp:         tSwap(0, 0, 0, secret, dexeth.SSRefunded, receiverAddr),
+		coinID:      txHash[:],
+		wantErr:     true,
+		wantErrKind: ErrSwapRefunded,
+	}, {
+		name:        "confirmations error, swap not in contract",
+		tx:          tTx(gasPrice, gasTipCap, 0, contractAddr, redeemCalldata),
+		contractID:  dexeth.EncodeContractData(0, secretHash),
+		swp:         tSwap(0, 0, 0, secret, dexeth.SSNone, receiverAddr),
+		coinID:      txHash[:],
+		wantErr:     true,
+		wantErrKind: ErrContractNotFound,
 	}, {
 		name:       "validate redeem error",
 		tx:         tTx(gasPrice, gasTipCap, 0, contractAddr, redeemCalldata),
@@ -598,6 +762,9 @@ func TestRedemption(t *testing.T) {
 			if err == nil {
 				t.Fatalf("expected error for test %q", test.name)
 			}
+			if test.wantErrKind != nil && !errors.Is(err, test.wantErrKind) {
+				t.Fatalf("expected %v error for test %q, got %v", test.wantErrKind, test.name, err)
+			}
 			continue
 		}
 		if err != nil {
@@ -648,6 +815,14 @@ func TestTxData(t *testing.T) {
 		t.Fatalf("no error for missing tx")
 	}
 
+	// Transaction not found
+	node.txErr = ethereum.NotFound
+	_, err = eth.TxData(goodCoinID)
+	if !errors.Is(err, asset.CoinNotFoundError) {
+		t.Fatalf("expected CoinNotFoundError for unknown tx, got %v", err)
+	}
+	node.txErr = nil
+
 	// Success again
 	node.tx = tx
 	_, err = eth.TxData(goodCoinID)
@@ -749,6 +924,120 @@ func TestAccountBalance(t *testing.T) {
 	}
 }
 
+func TestContractSwaps(t *testing.T) {
+	eth, node := tNewBackend(BipID)
+
+	acct := common.HexToAddress("0x2b84C791b79Ee37De042AD2ffF1A253c3ce9bc27")
+	other := common.HexToAddress("0x345853e21b1d475582E71cC269124eD5e2dD3422")
+	initiated := tSwap(10, 1e9, 1e9, [32]byte{}, dexeth.SSInitiated, &other)
+	initiated.Initiator = acct
+	redeemed := tSwap(11, 1e9, 2e9, [32]byte{1}, dexeth.SSRedeemed, &acct)
+	redeemed.Initiator = other
+	unrelated := tSwap(12, 1e9, 3e9, [32]byte{}, dexeth.SSRefunded, &other)
+	unrelated.Initiator = other
+	node.swaps = map[[32]byte]*dexeth.SwapState{
+		{1}: initiated,
+		{2}: redeemed,
+		{3}: unrelated,
+	}
+	secretHashes := [][32]byte{{1}, {2}, {3}, {4}}
+
+	// All swaps, including the one not in the contract.
+	swaps, err := eth.ContractSwaps(secretHashes, "")
+	if err != nil {
+		t.Fatalf("ContractSwaps error: %v", err)
+	}
+	if len(swaps) != 4 {
+		t.Fatalf("expected 4 swaps, got %d", len(swaps))
+	}
+	for i, exp := range []struct {
+		state dexeth.SwapStep
+		value uint64
+	}{{dexeth.SSInitiated, 1e9}, {dexeth.SSRedeemed, 2e9}, {dexeth.SSRefunded, 3e9}, {dexeth.SSNone, 0}} {
+		swap := swaps[i]
+		if !bytes.Equal(swap.SecretHash, secretHashes[i][:]) || swap.State != exp.state.String() || swap.Value != exp.value {
+			t.Fatalf("wrong swap %d: %+v", i, swap)
+		}
+	}
+	if swaps[0].Initiator != acct.String() || swaps[0].Participant != other.String() || swaps[0].BlockHeight != 10 ||
+		!swaps[0].LockTime.Equal(time.Unix(1e9, 0)) {
+		t.Fatalf("wrong initiated swap: %+v", swaps[0])
+	}
+
+	// Filtered by account. The missing swap is still reported.
+	swaps, err = eth.ContractSwaps(secretHashes, acct.String())
+	if err != nil {
+		t.Fatalf("ContractSwaps error: %v", err)
+	}
+	if len(swaps) != 3 || !bytes.Equal(swaps[0].SecretHash, secretHashes[0][:]) ||
+		!bytes.Equal(swaps[1].SecretHash, secretHashes[1][:]) || swaps[2].State != dexeth.SSNone.String() {
+		t.Fatalf("wrong swaps for account: %+v", swaps)
+	}
+
+	// Token values are in the token's units.
+	eth.atomize = func(v *big.Int) uint64 { return new(big.Int).Div(v, big.NewInt(1e3)).Uint64() }
+	swaps, _ = eth.ContractSwaps(secretHashes[:1], "")
+	if swaps[0].Value != dexeth.GweiToWei(1e9).Uint64()/1e3 {
+		t.Fatalf("wrong token value %d", swaps[0].Value)
+	}
+
+	if _, err = eth.ContractSwaps(secretHashes, "abc"); err == nil {
+		t.Fatalf("no error for invalid account")
+	}
+	node.swpErr = errors.New("test error")
+	if _, err = eth.ContractSwaps(secretHashes, ""); err == nil {
+		t.Fatalf("no error for swap error")
+	}
+}
+
+func TestValidateRedemption(t *testing.T) {
+	eth, node := tNewBackend(BipID)
+
+	participant := common.HexToAddress("0x345853e21b1d475582E71cC269124eD5e2dD3422")
+	secret := encode.RandomBytes(32)
+	secretHash := sha256.Sum256(secret)
+	contractData := dexeth.EncodeContractData(0, secretHash)
+	node.swaps = map[[32]byte]*dexeth.SwapState{
+		secretHash: tSwap(10, 1e9, 1e9, [32]byte{}, dexeth.SSInitiated, &participant),
+	}
+
+	if valid, reason, err := eth.ValidateRedemption(contractData, secret); err != nil || !valid {
+		t.Fatalf("valid redemption rejected: %q, %v", reason, err)
+	}
+
+	ensureInvalid := func(tag string, contractData, secret []byte) {
+		t.Helper()
+		valid, reason, err := eth.ValidateRedemption(contractData, secret)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tag, err)
+		}
+		if valid || reason == "" {
+			t.Fatalf("%s: redemption not rejected", tag)
+		}
+	}
+
+	ensureInvalid("wrong secret", contractData, encode.RandomBytes(32))
+	ensureInvalid("invalid contract data", contractData[:10], secret)
+	ensureInvalid("unsupported version", dexeth.EncodeContractData(1, secretHash), secret)
+
+	// Swaps that are not initiated, or already redeemed or refunded.
+	for _, state := range []dexeth.SwapStep{dexeth.SSNone, dexeth.SSRedeemed, dexeth.SSRefunded} {
+		node.swaps[secretHash].State = state
+		ensureInvalid(state.String(), contractData, secret)
+	}
+
+	// Unknown swap.
+	delete(node.swaps, secretHash)
+	ensureInvalid("unknown swap", contractData, secret)
+
+	// Node error.
+	node.swaps[secretHash] = tSwap(10, 1e9, 1e9, [32]byte{}, dexeth.SSInitiated, &participant)
+	node.swpErr = errors.New("test error")
+	if _, _, err := eth.ValidateRedemption(contractData, secret); err == nil {
+		t.Fatalf("no error for a node error")
+	}
+}
+
 func TestPoll(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -798,6 +1087,92 @@ func TestPoll(t *testing.T) {
 	}
 }
 
+func TestPollReorg(t *testing.T) {
+	be, node := tNewBackend(BipID)
+	eth := &ETHBackend{be}
+	ch := make(chan *asset.BlockUpdate, 1)
+	eth.blockChans[ch] = struct{}{}
+
+	hdrs := make(map[uint64]*types.Header)
+	node.hdrs = hdrs
+	addBlock := func(height uint64, nonce uint64) {
+		var parentHash common.Hash
+		if parent := hdrs[height-1]; parent != nil {
+			parentHash = parent.Hash()
+		}
+		hdrs[height] = &types.Header{
+			Number:     new(big.Int).SetUint64(height),
+			ParentHash: parentHash,
+			Nonce:      types.EncodeNonce(nonce),
+		}
+	}
+	poll := func(tag string, height uint64, wantReorg bool) {
+		t.Helper()
+		node.blkNum = height
+		eth.poll(context.Background())
+		select {
+		case u := <-ch:
+			if u.Err != nil {
+				t.Fatalf("%s: unexpected error: %v", tag, u.Err)
+			}
+			if u.Reorg != wantReorg {
+				t.Fatalf("%s: wanted reorg = %t, got %t", tag, wantReorg, u.Reorg)
+			}
+		default:
+			t.Fatalf("%s: no block update", tag)
+		}
+	}
+
+	h := be.bestHeight
+	for i := h; i <= h+5; i++ {
+		addBlock(i, 0)
+	}
+	poll("first", h+1, false) // previous hash unknown
+	poll("next", h+2, false)
+	poll("skipped blocks", h+4, false)
+
+	// Replace the tip and extend the chain.
+	addBlock(h+4, 1)
+	addBlock(h+5, 1)
+	poll("reorg", h+5, true)
+
+	// A shorter chain is a reorg.
+	poll("shorter", h+3, true)
+
+	// The next block with a different parent.
+	hdrs[h+4] = &types.Header{Number: new(big.Int).SetUint64(h + 4), Nonce: types.EncodeNonce(2)}
+	poll("wrong parent", h+4, true)
+}
+
+func TestTxConfirmations(t *testing.T) {
+	be, node := tNewBackend(BipID)
+	coinID := common.Hash{0x01}.Bytes()
+
+	if _, err := be.TxConfirmations(coinID); !errors.Is(err, asset.CoinNotFoundError) {
+		t.Fatalf("wrong error for a missing receipt: %v", err)
+	}
+
+	node.receipt = &types.Receipt{}
+	if confs, err := be.TxConfirmations(coinID); err != nil || confs != 0 {
+		t.Fatalf("wrong result for an unmined tx: confs = %d, err = %v", confs, err)
+	}
+
+	node.blkNum = 10
+	node.receipt = &types.Receipt{BlockNumber: big.NewInt(8)}
+	if confs, err := be.TxConfirmations(coinID); err != nil || confs != 3 {
+		t.Fatalf("wrong result for a mined tx: confs = %d, err = %v", confs, err)
+	}
+
+	node.blkNumErr = errors.New("test error")
+	if _, err := be.TxConfirmations(coinID); err == nil {
+		t.Fatalf("no error for blockNumber error")
+	}
+
+	if _, err := be.TxConfirmations([]byte{0x01}); err == nil {
+		t.Fatalf("no error for an invalid coin ID")
+	}
+}
+
 func TestValidateSignature(t *testing.T) {
 	// "ok" values used are the same as tests in client/assets/eth.
 	pkBytes := mustParseHex("04b911d1f39f7792e165767e35aa134083e2f70ac7de6945d7641a3015d09a54561b71112b8d60f63831f0e62c23c6921ec627820afedf8236155b9e9bd82b6523")
@@ -903,6 +1278,7 @@ func TestParseEndpoints(t *testing.T) {
 		relayAddr         string
 		expectedEndpoints []string
 		wantErr           bool
+		expectedPoolSize  int
 	}
 
 	url1 := "http://127.0.0.1:1234"
@@ -931,6 +1307,22 @@ func TestParseEndpoints(t *testing.T) {
 			relayAddr:         relayAddr,
 			expectedEndpoints: []string{relayURL},
 		},
+		{
+			name:              "pool size",
+			fileContents:      "pool = 4\n" + url1,
+			expectedEndpoints: []string{url1},
+			expectedPoolSize:  4,
+		},
+		{
+			name:         "invalid pool size",
+			fileContents: "pool=0\n" + url1,
+			wantErr:      true,
+		},
+		{
+			name:         "pool size too large",
+			fileContents: "pool=100\n" + url1,
+			wantErr:      true,
+		},
 	}
 
 	runTest := func(t *testing.T, tt *test) {
@@ -945,7 +1337,7 @@ func TestParseEndpoints(t *testing.T) {
 			defer f.Close()
 			f.WriteString(tt.fileContents)
 		}
-		endpoints, err := parseEndpoints(&asset.BackendConfig{
+		endpoints, poolSize, err := parseEndpoints(&asset.BackendConfig{
 			ConfigPath: configPath,
 			RelayAddr:  tt.relayAddr,
 		})
@@ -955,6 +1347,12 @@ func TestParseEndpoints(t *testing.T) {
 			}
 			t.Fatalf("parseEndpoints error: %v", err)
 		}
+		if tt.wantErr {
+			t.Fatalf("no parseEndpoints error when expected")
+		}
+		if poolSize != tt.expectedPoolSize {
+			t.Fatalf("wrong pool size. wanted %d, got %d", tt.expectedPoolSize, poolSize)
+		}
 		if len(endpoints) != len(tt.expectedEndpoints) {
 			t.Fatalf("wrong number of endpoints. wanted %d, got %d", len(tt.expectedEndpoints), len(endpoints))
 		}
@@ -970,3 +1368,395 @@ func TestParseEndpoints(t *testing.T) {
 		})
 	}
 }
+
+func TestParseEndpointsJWT(t *testing.T) {
+	var secret, otherSecret [32]byte
+	copy(secret[:], encode.RandomBytes(32))
+	copy(otherSecret[:], encode.RandomBytes(32))
+
+	writeFile := func(path, contents string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("error creating directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatalf("error writing %q: %v", path, err)
+		}
+	}
+
+	// A geth datadir with a jwtsecret, and one without.
+	dataDir := t.TempDir()
+	writeFile(filepath.Join(dataDir, "geth", jwtSecretFile), "0x"+hex.EncodeToString(secret[:])+"\n")
+	ipcPath := filepath.Join(dataDir, "geth.ipc")
+	noSecretIPCPath := filepath.Join(t.TempDir(), "geth.ipc")
+	otherSecretPath := filepath.Join(t.TempDir(), "jwt.hex")
+	writeFile(otherSecretPath, hex.EncodeToString(otherSecret[:]))
+	badSecretPath := filepath.Join(t.TempDir(), "bad.hex")
+	writeFile(badSecretPath, "abcd")
+
+	const wsURL = "ws://127.0.0.1:8551"
+
+	tests := []struct {
+		name         string
+		fileContents string
+		wantSecret   *[32]byte
+		wantErr      bool
+	}{
+		{
+			name:         "secret in ipc datadir",
+			fileContents: ipcPath + "\n" + wsURL,
+			wantSecret:   &secret,
+		},
+		{
+			name:         "no secret in ipc datadir",
+			fileContents: noSecretIPCPath + "\n" + wsURL,
+		},
+		{
+			name:         "explicit secret overrides datadir",
+			fileContents: "jwt=" + otherSecretPath + "\n" + ipcPath + "\n" + wsURL,
+			wantSecret:   &otherSecret,
+		},
+		{
+			name:         "explicit secret without ipc",
+			fileContents: wsURL + "\njwt = " + otherSecretPath,
+			wantSecret:   &otherSecret,
+		},
+		{
+			name:         "invalid explicit secret",
+			fileContents: "jwt=" + badSecretPath + "\n" + wsURL,
+			wantErr:      true,
+		},
+		{
+			name:         "missing explicit secret",
+			fileContents: "jwt=" + filepath.Join(dataDir, "nope") + "\n" + wsURL,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), "eth.conf")
+		writeFile(configPath, tt.fileContents)
+		endpoints, _, err := parseEndpoints(&asset.BackendConfig{
+			ConfigPath: configPath,
+			Logger:     tLogger,
+		})
+		if err != nil {
+			if tt.wantErr {
+				continue
+			}
+			t.Fatalf("%s: parseEndpoints error: %v", tt.name, err)
+		}
+		if tt.wantErr {
+			t.Fatalf("%s: no parseEndpoints error when expected", tt.name)
+		}
+		for _, ep := range endpoints {
+			if strings.HasSuffix(ep.url, ".ipc") {
+				if ep.jwtSecret != nil {
+					t.Fatalf("%s: JWT secret set for ipc endpoint", tt.name)
+				}
+				continue
+			}
+			if (ep.jwtSecret == nil) != (tt.wantSecret == nil) || (ep.jwtSecret != nil && *ep.jwtSecret != *tt.wantSecret) {
+				t.Fatalf("%s: wrong JWT secret for %s", tt.name, ep.url)
+			}
+		}
+	}
+
+	// The token is signed with the secret.
+	h := make(http.Header)
+	if err := jwtAuth(secret)(h); err != nil {
+		t.Fatalf("jwtAuth error: %v", err)
+	}
+	token := strings.TrimPrefix(h.Get("Authorization"), "Bearer ")
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("malformed token %q", token)
+	}
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if sig, _ := base64.RawURLEncoding.DecodeString(parts[2]); !hmac.Equal(sig, mac.Sum(nil)) {
+		t.Fatalf("invalid token signature")
+	}
+}
+
+type tCaller struct {
+	unsupported map[string]bool
+	calls       []string
+}
+
+func (c *tCaller) CallContext(_ context.Context, _ any, method string, _ ...any) error {
+	c.calls = append(c.calls, method)
+	if c.unsupported[method] {
+		return fmt.Errorf("the method %s does not exist/is not available", method)
+	}
+	return nil
+}
+
+func TestUnsupportedFeatures(t *testing.T) {
+	const url = "http://127.0.0.1:8545"
+
+	// A node without the txpool namespace only loses pending balances.
+	caller := &tCaller{unsupported: map[string]bool{"txpool_status": true}}
+	unsupported, err := unsupportedFeatures(context.Background(), caller, url, tLogger)
+	if err != nil {
+		t.Fatalf("unsupportedFeatures error: %v", err)
+	}
+	if len(unsupported) != 1 || !unsupported[pendingBalanceFeature] {
+		t.Fatalf("expected pending balances to be unsupported, got %v", unsupported)
+	}
+	if strings.Join(caller.calls, ",") != "eth_blockNumber,txpool_status" {
+		t.Fatalf("wrong probe calls %v", caller.calls)
+	}
+
+	// A node exposing everything supports every feature.
+	caller = &tCaller{}
+	if unsupported, err = unsupportedFeatures(context.Background(), caller, url, tLogger); err != nil || len(unsupported) != 0 {
+		t.Fatalf("unexpected unsupported features %v, err = %v", unsupported, err)
+	}
+
+	// A node without the eth namespace is rejected.
+	caller = &tCaller{unsupported: map[string]bool{"eth_blockNumber": true}}
+	_, err = unsupportedFeatures(context.Background(), caller, url, tLogger)
+	if err == nil || !strings.Contains(err.Error(), "eth (needed for "+swapFeature.name+")") {
+		t.Fatalf("expected missing eth namespace error, got %v", err)
+	}
+}
+
+func TestNodeErrors(t *testing.T) {
+	// A node on the wrong chain.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID json.RawMessage `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x5"}`, req.ID)
+	}))
+	defer srv.Close()
+	c := newRPCClient(BipID, 1, dex.Simnet, nil, 0, common.Address{}, tLogger)
+	_, err := c.connectToEndpoint(context.Background(), endpoint{url: srv.URL}, true)
+	if !errors.Is(err, ErrChainIDMismatch) {
+		t.Fatalf("expected chain ID mismatch error, got %v", err)
+	}
+
+	// No connected providers.
+	_, err = c.blockNumber(context.Background())
+	if !errors.Is(err, ErrNodeDisconnected) {
+		t.Fatalf("expected node disconnected error, got %v", err)
+	}
+}
+
+// tNodeServer is a JSON-RPC server that serves the requests needed to connect
+// to it and to get block headers.
+func tNodeServer(t *testing.T, chainID uint64) *httptest.Server {
+	hdrB, err := json.Marshal(&types.Header{
+		Number:     big.NewInt(5),
+		Difficulty: new(big.Int),
+		Time:       uint64(time.Now().Unix()),
+	})
+	if err != nil {
+		t.Fatalf("error encoding header: %v", err)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		var res string
+		switch req.Method {
+		case "eth_chainId":
+			res = fmt.Sprintf(`"0x%x"`, chainID)
+		case "eth_blockNumber":
+			res = `"0x5"`
+		case "eth_getBlockByNumber":
+			res = string(hdrB)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"method not found"}}`, req.ID)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, res)
+	}))
+}
+
+func TestConnectionPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv1, srv2, fallbackSrv := tNodeServer(t, 1), tNodeServer(t, 1), tNodeServer(t, 1)
+	defer srv1.Close()
+	defer srv2.Close()
+	defer fallbackSrv.Close()
+
+	c := newRPCClient(BipID, 1, dex.Simnet, []endpoint{
+		{url: srv1.URL, priority: 1},
+		{url: srv2.URL, priority: 1},
+		{url: fallbackSrv.URL},
+	}, 2, common.Address{}, tLogger)
+	if err := c.connect(ctx); err != nil {
+		t.Fatalf("connect error: %v", err)
+	}
+
+	requestCounts := func() map[string]uint64 {
+		counts := make(map[string]uint64)
+		for _, s := range c.connStats() {
+			counts[s.Endpoint] += s.Requests
+		}
+		return counts
+	}
+
+	stats := c.connStats()
+	if len(stats) != 6 {
+		t.Fatalf("expected 6 pooled connections, got %d", len(stats))
+	}
+	for _, s := range stats {
+		if !s.Healthy {
+			t.Fatalf("connection to %s not healthy", s.Endpoint)
+		}
+	}
+
+	// Requests are distributed across the connections to the two endpoints
+	// with the highest priority, and the fallback is not used.
+	const n = 40
+	for i := 0; i < n; i++ {
+		if _, err := c.headerByHeight(ctx, 5); err != nil {
+			t.Fatalf("headerByHeight error: %v", err)
+		}
+	}
+	counts := requestCounts()
+	if counts[srv1.URL] != n/2 || counts[srv2.URL] != n/2 || counts[fallbackSrv.URL] != 0 {
+		t.Fatalf("requests not balanced: %v", counts)
+	}
+	for _, s := range c.connStats()[:4] {
+		if s.Requests != n/4 {
+			t.Fatalf("connection to %s used for %d requests, expected %d", s.Endpoint, s.Requests, n/4)
+		}
+	}
+
+	// A dead connection fails over, and is replaced at the next health check.
+	// Closing an HTTP client has no effect, so point the connection at a
+	// server that has shut down.
+	goneSrv := tNodeServer(t, 1)
+	goneClient, err := rpc.DialContext(ctx, goneSrv.URL)
+	if err != nil {
+		t.Fatalf("DialContext error: %v", err)
+	}
+	goneSrv.Close()
+	dead := c.clientsCopy()[0]
+	dead.Client = ethclient.NewClient(goneClient)
+	for i := 0; i < n; i++ {
+		if _, err := c.headerByHeight(ctx, 5); err != nil {
+			t.Fatalf("headerByHeight error with a dead connection: %v", err)
+		}
+	}
+	if stats = c.connStats(); stats[len(stats)-1].Healthy || stats[len(stats)-1].Failures != 1 {
+		t.Fatalf("dead connection not moved to the back of the pool")
+	}
+	// Expire the cached tip so the health check reaches the node.
+	dead.tipCache.Lock()
+	dead.tipCache.lastUpdate = time.Time{}
+	dead.tipCache.Unlock()
+	if !c.sortConnectionsByHealth(ctx) {
+		t.Fatalf("no healthy connections")
+	}
+	clients := c.clientsCopy()
+	if len(clients) != 6 {
+		t.Fatalf("expected 6 pooled connections after replacement, got %d", len(clients))
+	}
+	var replaced bool
+	for _, ec := range clients {
+		if ec == dead {
+			t.Fatalf("dead connection not replaced")
+		}
+		if ec.endpoint == dead.endpoint && ec.requests.Load() == 0 {
+			replaced = true
+		}
+	}
+	if !replaced {
+		t.Fatalf("no new connection to %s", dead.endpoint)
+	}
+	for _, s := range c.connStats() {
+		if !s.Healthy {
+			t.Fatalf("connection to %s not healthy after replacement", s.Endpoint)
+		}
+	}
+	if _, err := c.headerByHeight(ctx, 5); err != nil {
+		t.Fatalf("headerByHeight error after replacement: %v", err)
+	}
+}
+
+func TestGasRecorder(t *testing.T) {
+	be, _ := tNewBackend(BipID)
+	if swap, redeem := be.GasStats(); swap != nil || redeem != nil {
+		t.Fatalf("stats with no observations")
+	}
+
+	// Shuffled swap observations of 1 through 100 gas.
+	for i := uint64(0); i < 100; i++ {
+		be.gas.record(gasOpSwap, (i*37)%100+1)
+	}
+	swap, redeem := be.GasStats()
+	if redeem != nil {
+		t.Fatalf("redeem stats without redeem observations")
+	}
+	if swap.Samples != 100 || swap.P50 != 50 || swap.P95 != 95 || swap.Max != 100 {
+		t.Fatalf("wrong swap stats %+v", swap)
+	}
+
+	// Only the most recent observations are used.
+	for i := 0; i < 150; i++ {
+		be.gas.record(gasOpRedeem, 40_000)
+	}
+	be.gas.record(gasOpRedeem, 60_000)
+	_, redeem = be.GasStats()
+	if redeem.Samples != gasWindow || redeem.P50 != 40_000 || redeem.P95 != 40_000 || redeem.Max != 60_000 {
+		t.Fatalf("wrong redeem stats %+v", redeem)
+	}
+
+	// Tracked transactions are recorded once mined, unless they failed.
+	r := newGasRecorder()
+	mined, failed, unmined, expired := common.Hash{0x01}, common.Hash{0x02}, common.Hash{0x03}, common.Hash{0x04}
+	for _, h := range []common.Hash{mined, failed, unmined, expired} {
+		r.track(h, gasOpSwap)
+	}
+	r.pending[expired].stamp = time.Now().Add(-pendingGasExpiry - time.Minute)
+	receipts := map[common.Hash]*types.Receipt{
+		mined:  {Status: types.ReceiptStatusSuccessful, GasUsed: 50_000},
+		failed: {Status: types.ReceiptStatusFailed, GasUsed: 30_000},
+	}
+	receipt := func(_ context.Context, h common.Hash) (*types.Receipt, error) {
+		if rec, found := receipts[h]; found {
+			return rec, nil
+		}
+		return nil, ethereum.NotFound
+	}
+	if err := r.check(tCtx, receipt); err != nil {
+		t.Fatalf("check error: %v", err)
+	}
+	if len(r.pending) != 1 || r.pending[unmined] == nil {
+		t.Fatalf("wrong pending transactions after check: %v", r.pending)
+	}
+	if stats := r.stats(gasOpSwap); stats.Samples != 1 || stats.Max != 50_000 {
+		t.Fatalf("wrong stats after check: %+v", stats)
+	}
+
+	// Other errors are reported, and the transaction stays tracked.
+	if err := r.check(tCtx, func(context.Context, common.Hash) (*types.Receipt, error) {
+		return nil, errors.New("test error")
+	}); err == nil {
+		t.Fatalf("no error for receipt error")
+	}
+	if len(r.pending) != 1 {
+		t.Fatalf("transaction dropped after receipt error")
+	}
+
+	// Tracking is bounded.
+	for i := 0; i < maxPendingGasTxs*2; i++ {
+		var h common.Hash
+		binary.BigEndian.PutUint64(h[:], uint64(i)+100)
+		r.track(h, gasOpRedeem)
+	}
+	if len(r.pending) != maxPendingGasTxs {
+		t.Fatalf("expected %d pending transactions, got %d", maxPendingGasTxs, len(r.pending))
+	}
+}
@@ -0,0 +1,153 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/server/asset"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// gasWindow is the number of most recent observations of each operation
+	// used for the gas stats.
+	gasWindow = 100
+	// maxPendingGasTxs is the most transactions that will be tracked while
+	// waiting for them to be mined.
+	maxPendingGasTxs = 256
+	// pendingGasExpiry is how long a transaction is tracked before it is
+	// assumed to have been replaced or dropped.
+	pendingGasExpiry = time.Hour
+)
+
+type gasOp uint8
+
+const (
+	gasOpSwap gasOp = iota
+	gasOpRedeem
+)
+
+// gasRecorder records the gas used by transactions with a single initiation
+// or redemption, which are the transactions that the configured swap and
+// redeem gases describe. Transactions are tracked when they are validated, and
+// their receipts are checked for gas used once they are mined. Both the
+// tracked transactions and the observations are bounded.
+type gasRecorder struct {
+	mtx     sync.Mutex
+	pending map[common.Hash]*pendingGasTx
+	obs     map[gasOp][]uint64
+}
+
+type pendingGasTx struct {
+	op    gasOp
+	stamp time.Time
+}
+
+func newGasRecorder() *gasRecorder {
+	return &gasRecorder{
+		pending: make(map[common.Hash]*pendingGasTx),
+		obs:     make(map[gasOp][]uint64),
+	}
+}
+
+// track adds a transaction to check for gas used after it is mined.
+func (r *gasRecorder) track(txHash common.Hash, op gasOp) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, found := r.pending[txHash]; found || len(r.pending) >= maxPendingGasTxs {
+		return
+	}
+	r.pending[txHash] = &pendingGasTx{op: op, stamp: time.Now()}
+}
+
+// record adds an observation of the gas used by an operation.
+func (r *gasRecorder) record(op gasOp, gas uint64) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	obs := append(r.obs[op], gas)
+	if len(obs) > gasWindow {
+		obs = obs[len(obs)-gasWindow:]
+	}
+	r.obs[op] = obs
+}
+
+// check looks up the receipts of the tracked transactions, recording the gas
+// used by those that were mined successfully. Transactions that are not found
+// are checked again later, until they expire.
+func (r *gasRecorder) check(ctx context.Context, receipt func(context.Context, common.Hash) (*types.Receipt, error)) error {
+	r.mtx.Lock()
+	pending := make(map[common.Hash]*pendingGasTx, len(r.pending))
+	for txHash, p := range r.pending {
+		pending[txHash] = p
+	}
+	r.mtx.Unlock()
+
+	var lastErr error
+	done := make([]common.Hash, 0, len(pending))
+	for txHash, p := range pending {
+		rec, err := receipt(ctx, txHash)
+		if err != nil {
+			if errors.Is(err, ethereum.NotFound) {
+				if time.Since(p.stamp) > pendingGasExpiry {
+					done = append(done, txHash)
+				}
+				continue
+			}
+			lastErr = err
+			continue
+		}
+		done = append(done, txHash)
+		// A failed transaction is not representative of the gas required.
+		if rec.Status == types.ReceiptStatusSuccessful {
+			r.record(p.op, rec.GasUsed)
+		}
+	}
+
+	r.mtx.Lock()
+	for _, txHash := range done {
+		delete(r.pending, txHash)
+	}
+	r.mtx.Unlock()
+	return lastErr
+}
+
+// stats calculates the gas stats for an operation, or returns nil if there
+// are no observations.
+func (r *gasRecorder) stats(op gasOp) *asset.GasStats {
+	r.mtx.Lock()
+	obs := append([]uint64(nil), r.obs[op]...)
+	r.mtx.Unlock()
+	if len(obs) == 0 {
+		return nil
+	}
+	sort.Slice(obs, func(i, j int) bool { return obs[i] < obs[j] })
+	// Nearest-rank percentiles.
+	percentile := func(p int) uint64 {
+		rank := (p*len(obs) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return obs[rank-1]
+	}
+	return &asset.GasStats{
+		Samples: len(obs),
+		P50:     percentile(50),
+		P95:     percentile(95),
+		Max:     obs[len(obs)-1],
+	}
+}
+
+// GasStats returns statistics for the gas used by recent transactions with a
+// single initiation and with a single redemption. Part of the
+// asset.GasReporter interface.
+func (be *AssetBackend) GasStats() (swap, redeem *asset.GasStats) {
+	return be.gas.stats(gasOpSwap), be.gas.stats(gasOpRedeem)
+}
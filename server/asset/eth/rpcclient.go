@@ -13,11 +13,13 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"decred.org/dcrdex/dex"
 	dexeth "decred.org/dcrdex/dex/networks/eth"
 	swapv0 "decred.org/dcrdex/dex/networks/eth/contracts/v0"
+	"decred.org/dcrdex/server/asset"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -36,16 +38,55 @@ var (
 	// failingEndpointsCheckFreq means that endpoints that were never connected
 	// will be attempted every (monitorConnectionsInterval * failingEndpointsCheckFreq).
 	failingEndpointsCheckFreq = 4
+	// defaultPoolSize is the number of connections made to each endpoint if
+	// the config file does not specify a pool size.
+	defaultPoolSize = 1
+	// maxPoolSize is the largest number of connections that may be made to
+	// each endpoint.
+	maxPoolSize = 16
+
+	// swapFeature is the validation of swaps, bonds and token transfers, and
+	// block monitoring, which use the eth namespace.
+	swapFeature = &rpcFeature{
+		name:      "swap validation and block monitoring",
+		namespace: "eth",
+		probe:     "eth_blockNumber",
+	}
+	// pendingBalanceFeature is the accounting for pending transactions in
+	// balance calculations, which uses the txpool namespace.
+	pendingBalanceFeature = &rpcFeature{
+		name:      "pending balance accounting",
+		namespace: "txpool",
+		probe:     "txpool_status",
+		optional:  true,
+	}
+	// rpcFeatures are the backend features that depend on the RPC namespaces
+	// exposed by the node.
+	rpcFeatures = []*rpcFeature{swapFeature, pendingBalanceFeature}
 )
 
+// rpcFeature is a backend feature and the RPC namespace it uses. Not all
+// providers support rpc_modules, so the namespace is probed directly with a
+// harmless call.
+type rpcFeature struct {
+	name      string
+	namespace string
+	probe     string
+	// optional features are disabled at endpoints that do not expose their
+	// namespace. An endpoint missing the namespace of any other feature is
+	// rejected.
+	optional bool
+}
+
 type ContextCaller interface {
 	CallContext(ctx context.Context, result any, method string, args ...any) error
 }
 
 type ethConn struct {
 	*ethclient.Client
-	endpoint string
-	priority uint16
+	endpoint  string
+	priority  uint16
+	jwtSecret *[32]byte
 	// swapContract is the current ETH swapContract.
 	swapContract swapContract
 	// tokens are tokeners for loaded tokens. tokens is not protected by a
@@ -55,6 +96,16 @@ type ethConn struct {
 	// caller is a client for raw calls not implemented by *ethclient.Client.
 	caller          ContextCaller
 	txPoolSupported bool
+	// subscribed is true for the one connection in each endpoint's pool that
+	// subscribes to new block headers, if the endpoint is a websocket.
+	subscribed bool
+	// status is the last known health of the connection. status is protected
+	// by the rpcclient's clientsMtx.
+	status connectionStatus
+	// requests and failures count the requests made with the connection and
+	// the requests that failed.
+	requests atomic.Uint64
+	failures atomic.Uint64
 
 	tipCache struct {
 		sync.Mutex
@@ -129,6 +180,8 @@ func (ec *ethConn) monitorBlocks(ctx context.Context, log dex.Logger) {
 type endpoint struct {
 	url      string
 	priority uint16
+	// jwtSecret, if set, is used to authenticate requests to the endpoint.
+	jwtSecret *[32]byte
 }
 
 func (ec *ethConn) tip(ctx context.Context) (*types.Header, error) {
@@ -172,13 +225,29 @@ type rpcclient struct {
 	healthCheckCounter      int
 	tokensLoaded            map[uint32]*VersionedToken
 	ethContractAddr         common.Address
+	// poolSize is the number of connections made to each endpoint. Requests
+	// are balanced across the connections to the healthy endpoints with the
+	// highest priority.
+	poolSize int
+	// next is incremented with each request to rotate the connection used
+	// first.
+	next atomic.Uint64
 
 	// the order of clients will change based on the health of the connections.
 	clientsMtx sync.RWMutex
 	clients    []*ethConn
+	// balanced is the number of connections at the front of clients across
+	// which requests are balanced. These are the healthy connections with the
+	// highest priority.
+	balanced int
 }
 
-func newRPCClient(baseChainID uint32, chainID uint64, net dex.Network, endpoints []endpoint, ethContractAddr common.Address, log dex.Logger) *rpcclient {
+func newRPCClient(baseChainID uint32, chainID uint64, net dex.Network, endpoints []endpoint, poolSize int,
+	ethContractAddr common.Address, log dex.Logger) *rpcclient {
+
+	if poolSize <= 0 {
+		poolSize = defaultPoolSize
+	}
 	return &rpcclient{
 		baseChainID:     baseChainID,
 		genesisChainID:  chainID,
@@ -188,6 +257,7 @@ func newRPCClient(baseChainID uint32, chainID uint64, net dex.Network, endpoints
 		log:             log,
 		ethContractAddr: ethContractAddr,
 		tokensLoaded:    make(map[uint32]*VersionedToken),
+		poolSize:        poolSize,
 	}
 }
 
@@ -200,10 +270,35 @@ func (c *rpcclient) clientsCopy() []*ethConn {
 	return clients
 }
 
-func (c *rpcclient) connectToEndpoint(ctx context.Context, endpoint endpoint) (*ethConn, error) {
+// connectPool makes poolSize connections to the endpoint. Only the first
+// connection subscribes to block headers. The connections that were made are
+// returned, with an error only if none could be made.
+func (c *rpcclient) connectPool(ctx context.Context, endpoint endpoint) ([]*ethConn, error) {
+	conns := make([]*ethConn, 0, c.poolSize)
+	for i := 0; i < c.poolSize; i++ {
+		ec, err := c.connectToEndpoint(ctx, endpoint, i == 0)
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			c.log.Errorf("Error making pooled connection %d to %q: %v", i, endpoint, err)
+			continue
+		}
+		conns = append(conns, ec)
+	}
+	return conns, nil
+}
+
+// connectToEndpoint connects to the endpoint. If subscribe is true and the
+// endpoint is a websocket, the connection subscribes to new block headers.
+func (c *rpcclient) connectToEndpoint(ctx context.Context, endpoint endpoint, subscribe bool) (*ethConn, error) {
 	var success bool
 
-	client, err := rpc.DialContext(ctx, endpoint.url)
+	var opts []rpc.ClientOption
+	if endpoint.jwtSecret != nil {
+		opts = append(opts, rpc.WithHTTPAuth(jwtAuth(*endpoint.jwtSecret)))
+	}
+	client, err := rpc.DialOptions(ctx, endpoint.url, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -217,11 +312,12 @@ func (c *rpcclient) connectToEndpoint(ctx context.Context, endpoint endpoint) (*
 	}()
 
 	ec := &ethConn{
-		Client:   ethclient.NewClient(client),
-		endpoint: endpoint.url,
-		priority: endpoint.priority,
-		tokens:   make(map[uint32]*tokener),
-		caller:   client,
+		Client:    ethclient.NewClient(client),
+		endpoint:  endpoint.url,
+		priority:  endpoint.priority,
+		jwtSecret: endpoint.jwtSecret,
+		tokens:    make(map[uint32]*tokener),
+		caller:    client,
 	}
 
 	chainID, err := ec.ChainID(ctx)
@@ -229,7 +325,7 @@ func (c *rpcclient) connectToEndpoint(ctx context.Context, endpoint endpoint) (*
 		return nil, fmt.Errorf("error checking chain ID from %q: %w", endpoint.url, err)
 	}
 	if chainID.Uint64() != c.genesisChainID {
-		return nil, fmt.Errorf("wrong chain ID from %q. wanted %d, got %d", endpoint.url, c.genesisChainID, chainID)
+		return nil, fmt.Errorf("%w: wrong chain ID from %q. wanted %d, got %d", ErrChainIDMismatch, endpoint.url, c.genesisChainID, chainID)
 	}
 
 	// ETHBackend will check rpcclient.blockNumber() once per second. For
@@ -240,21 +336,23 @@ func (c *rpcclient) connectToEndpoint(ctx context.Context, endpoint endpoint) (*
 	ec.tipCache.expiration = time.Second * 9 / 10
 	// Websocket endpoints receive headers through a notification feed, so
 	// shouldn't make requests unless something seems wrong.
-	if isWS {
+	// Only one connection to each endpoint subscribes.
+	if isWS && subscribe {
 		ec.tipCache.expiration = headerExpirationTime // time.Minute
-	} else if isRemoteURL(uri) {
+		ec.subscribed = true
+	} else if isWS || isRemoteURL(uri) {
 		// Lower the request rate for non-loopback IPs to avoid running into
 		// rate limits.
 		ec.tipCache.expiration = time.Second * 99 / 10
 	}
 
-	reqModules := []string{"eth", "txpool"}
-	if err := dexeth.CheckAPIModules(client, endpoint.url, c.log, reqModules); err != nil {
-		c.log.Warnf("Error checking required modules at %q: %v", endpoint, err)
+	unsupported, err := unsupportedFeatures(ctx, ec.caller, endpoint.url, c.log)
+	if err != nil {
+		return nil, err
+	}
+	ec.txPoolSupported = !unsupported[pendingBalanceFeature]
+	if !ec.txPoolSupported {
 		c.log.Warnf("Will not account for pending transactions in balance calculations at %q", endpoint)
-		ec.txPoolSupported = false
-	} else {
-		ec.txPoolSupported = true
 	}
 
 	es, err := swapv0.NewETHSwap(c.ethContractAddr, ec.Client)
@@ -271,7 +369,7 @@ func (c *rpcclient) connectToEndpoint(ctx context.Context, endpoint endpoint) (*
 		ec.tokens[assetID] = tkn
 	}
 
-	if isWS {
+	if ec.subscribed {
 		go ec.monitorBlocks(ctx, c.log)
 	}
 
@@ -280,6 +378,29 @@ func (c *rpcclient) connectToEndpoint(ctx context.Context, endpoint endpoint) (*
 	return ec, nil
 }
 
+// unsupportedFeatures probes the node for the RPC namespace of each of the
+// rpcFeatures, returning the optional features whose namespace is not exposed.
+// An error listing the missing namespaces is returned if any non-optional
+// feature is unsupported.
+func unsupportedFeatures(ctx context.Context, caller ContextCaller, url string, log dex.Logger) (map[*rpcFeature]bool, error) {
+	unsupported := make(map[*rpcFeature]bool)
+	var missing []string
+	for _, f := range rpcFeatures {
+		var res any
+		if err := caller.CallContext(ctx, &res, f.probe); err != nil {
+			log.Debugf("RPC namespace %s probe failed at %q: %v", f.namespace, url, err)
+			if !f.optional {
+				missing = append(missing, fmt.Sprintf("%s (needed for %s)", f.namespace, f.name))
+			}
+			unsupported[f] = true
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%q does not expose required RPC namespaces: %s", url, strings.Join(missing, ", "))
+	}
+	return unsupported, nil
+}
+
 type connectionStatus int
 
 const (
@@ -306,13 +427,25 @@ func (c *rpcclient) checkConnectionStatus(ctx context.Context, ec *ethConn) conn
 	return connectionStatusConnected
 }
 
+// replaceConnection makes a new connection to a failed connection's endpoint.
+// The failed connection is closed if the new connection is made.
+func (c *rpcclient) replaceConnection(ctx context.Context, ec *ethConn) (*ethConn, error) {
+	newEC, err := c.connectToEndpoint(ctx, endpoint{url: ec.endpoint, priority: ec.priority, jwtSecret: ec.jwtSecret}, ec.subscribed)
+	if err != nil {
+		return nil, err
+	}
+	ec.Close()
+	return newEC, nil
+}
+
 // sortConnectionsByHealth checks the health of the connections and sorts them
 // based on their health. It does a best header call to each connection and
 // connections with non outdated headers are placed first, ones with outdated
 // headers are placed in the middle, and ones that error are placed last.
-// Every failingEndpointsCheckFreq health checks, the endpoints that have
-// never been successfully connection will be checked. True is returned if
-// there is at least one healthy connection.
+// Failing connections are replaced with new connections to the same endpoint
+// if possible. Every failingEndpointsCheckFreq health checks, the endpoints
+// that have never been successfully connection will be checked. True is
+// returned if there is at least one healthy connection.
 func (c *rpcclient) sortConnectionsByHealth(ctx context.Context) bool {
 	clients := c.clientsCopy()
 
@@ -322,6 +455,16 @@ func (c *rpcclient) sortConnectionsByHealth(ctx context.Context) bool {
 
 	categorizeConnection := func(conn *ethConn) {
 		status := c.checkConnectionStatus(ctx, conn)
+		if status == connectionStatusFailed {
+			newConn, err := c.replaceConnection(ctx, conn)
+			if err != nil {
+				c.log.Errorf("Error replacing failed connection to %q: %v", conn.endpoint, err)
+			} else {
+				c.log.Infof("Replaced failed connection to %q", conn.endpoint)
+				conn = newConn
+				status = c.checkConnectionStatus(ctx, conn)
+			}
+		}
 		switch status {
 		case connectionStatusConnected:
 			healthyConnections = append(healthyConnections, conn)
@@ -340,7 +483,7 @@ func (c *rpcclient) sortConnectionsByHealth(ctx context.Context) bool {
 		stillUnconnectedEndpoints := make([]endpoint, 0, len(c.neverConnectedEndpoints))
 
 		for _, endpoint := range c.neverConnectedEndpoints {
-			ec, err := c.connectToEndpoint(ctx, endpoint)
+			conns, err := c.connectPool(ctx, endpoint)
 			if err != nil {
 				c.log.Errorf("Error connecting to %q: %v", endpoint, err)
 				stillUnconnectedEndpoints = append(stillUnconnectedEndpoints, endpoint)
@@ -349,7 +492,9 @@ func (c *rpcclient) sortConnectionsByHealth(ctx context.Context) bool {
 
 			c.log.Infof("Successfully connected to %q", endpoint)
 
-			categorizeConnection(ec)
+			for _, ec := range conns {
+				categorizeConnection(ec)
+			}
 		}
 
 		c.neverConnectedEndpoints = stillUnconnectedEndpoints
@@ -379,30 +524,54 @@ func (c *rpcclient) sortConnectionsByHealth(ctx context.Context) bool {
 		c.log.Warnf("Failing connections: %v", failingConnections)
 	}
 
+	// Requests are balanced across the healthy connections with the highest
+	// priority.
+	var balanced int
+	for _, ec := range healthyConnections {
+		if ec.priority != healthyConnections[0].priority {
+			break
+		}
+		balanced++
+	}
+
 	c.clientsMtx.Lock()
 	defer c.clientsMtx.Unlock()
+	for _, ec := range healthyConnections {
+		ec.status = connectionStatusConnected
+	}
+	for _, ec := range outdatedConnections {
+		ec.status = connectionStatusOutdated
+	}
+	for _, ec := range failingConnections {
+		ec.status = connectionStatusFailed
+	}
 	c.clients = clientsUpdatedOrder
+	c.balanced = balanced
 	c.healthCheckCounter = (c.healthCheckCounter + 1) % failingEndpointsCheckFreq
 
 	return len(healthyConnections) > 0
 }
 
 // markConnectionAsFailed moves an connection to the end of the client list.
-func (c *rpcclient) markConnectionAsFailed(endpoint string) {
+func (c *rpcclient) markConnectionAsFailed(failed *ethConn) {
 	c.clientsMtx.Lock()
 	defer c.clientsMtx.Unlock()
 
 	var index int = -1
 	for i, ec := range c.clients {
-		if ec.endpoint == endpoint {
+		if ec == failed {
 			index = i
 			break
 		}
 	}
 	if index == -1 {
-		c.log.Errorf("Failed to mark client as failed: %q not found", endpoint)
+		c.log.Errorf("Failed to mark client as failed: %q not found", failed.endpoint)
 		return
 	}
+	if index < c.balanced {
+		c.balanced--
+	}
+	failed.status = connectionStatusFailed
 
 	updatedClients := make([]*ethConn, 0, len(c.clients))
 	updatedClients = append(updatedClients, c.clients[:index]...)
@@ -436,8 +605,26 @@ func (c *rpcclient) monitorConnectionsHealth(ctx context.Context) {
 	}
 }
 
+// requestOrder returns the connections in the order they should be tried for
+// a request. The first connection is rotated through the balanced connections
+// with each call, and the remaining connections follow in order of health.
+func (c *rpcclient) requestOrder() []*ethConn {
+	c.clientsMtx.RLock()
+	defer c.clientsMtx.RUnlock()
+
+	clients := make([]*ethConn, 0, len(c.clients))
+	if c.balanced < 2 {
+		return append(clients, c.clients...)
+	}
+	start := int(c.next.Add(1) % uint64(c.balanced))
+	clients = append(clients, c.clients[start:c.balanced]...)
+	clients = append(clients, c.clients[:start]...)
+	return append(clients, c.clients[c.balanced:]...)
+}
+
 func (c *rpcclient) withClient(f func(ec *ethConn) error, haltOnNotFound ...bool) (err error) {
-	for _, ec := range c.clientsCopy() {
+	for _, ec := range c.requestOrder() {
+		ec.requests.Add(1)
 		err = f(ec)
 		if err == nil {
 			return nil
@@ -446,11 +633,14 @@ func (c *rpcclient) withClient(f func(ec *ethConn) error, haltOnNotFound ...bool
 			return err
 		}
 
+		ec.failures.Add(1)
 		c.log.Errorf("Unpropagated error from %q: %v", ec.endpoint, err)
-		c.markConnectionAsFailed(ec.endpoint)
+		c.markConnectionAsFailed(ec)
 	}
-
-	return fmt.Errorf("all providers failed. last error: %w", err)
+	if err == nil {
+		return fmt.Errorf("%w: no providers", ErrNodeDisconnected)
+	}
+	return fmt.Errorf("%w: all providers failed. last error: %w", ErrNodeDisconnected, err)
 }
 
 // connect will attempt to connect to all the endpoints in the endpoints slice.
@@ -469,7 +659,7 @@ func (c *rpcclient) connect(ctx context.Context) (err error) {
 	c.neverConnectedEndpoints = make([]endpoint, 0, len(c.endpoints))
 
 	for _, endpoint := range c.endpoints {
-		ec, err := c.connectToEndpoint(ctx, endpoint)
+		conns, err := c.connectPool(ctx, endpoint)
 		if err != nil {
 			c.log.Errorf("Error connecting to %q: %v", endpoint, err)
 			c.neverConnectedEndpoints = append(c.neverConnectedEndpoints, endpoint)
@@ -479,17 +669,19 @@ func (c *rpcclient) connect(ctx context.Context) (err error) {
 		defer func() {
 			// If all connections are outdated, we will not start, so close any open connections.
 			if !success {
-				ec.Close()
+				for _, ec := range conns {
+					ec.Close()
+				}
 			}
 		}()
 
-		c.clients = append(c.clients, ec)
+		c.clients = append(c.clients, conns...)
 	}
 
 	success = c.sortConnectionsByHealth(ctx)
 
 	if !success {
-		return fmt.Errorf("failed to connect to an up-to-date %v node", c.baseChainName)
+		return fmt.Errorf("%w: failed to connect to an up-to-date %v node", ErrNodeDisconnected, c.baseChainName)
 	}
 
 	go c.monitorConnectionsHealth(ctx)
@@ -497,6 +689,26 @@ func (c *rpcclient) connect(ctx context.Context) (err error) {
 	return nil
 }
 
+// connStats returns the status and request counts of each connection in the
+// pool, in the order they are tried for requests.
+func (c *rpcclient) connStats() []*asset.NodeConnStats {
+	c.clientsMtx.RLock()
+	defer c.clientsMtx.RUnlock()
+
+	stats := make([]*asset.NodeConnStats, 0, len(c.clients))
+	for _, ec := range c.clients {
+		stats = append(stats, &asset.NodeConnStats{
+			Endpoint: ec.endpoint,
+			Priority: ec.priority,
+			Healthy:  ec.status == connectionStatusConnected,
+			Outdated: ec.status == connectionStatusOutdated,
+			Requests: ec.requests.Load(),
+			Failures: ec.failures.Load(),
+		})
+	}
+	return stats
+}
+
 func (c *rpcclient) headerIsOutdated(hdr *types.Header) bool {
 	return c.net != dex.Simnet && hdr.Time < uint64(time.Now().Add(-headerExpirationTime).Unix())
 }
@@ -584,6 +796,30 @@ func (c *rpcclient) transaction(ctx context.Context, hash common.Hash) (tx *type
 	}, true) // stop on first provider with "not found", because this should be an error if tx does not exist
 }
 
+// transactionReceipt gets the receipt for a mined transaction. Errors with
+// ethereum.NotFound if the transaction is not mined.
+func (c *rpcclient) transactionReceipt(ctx context.Context, hash common.Hash) (r *types.Receipt, err error) {
+	return r, c.withClient(func(ec *ethConn) error {
+		r, err = ec.TransactionReceipt(ctx, hash)
+		return err
+	}, true)
+}
+
+// estimateGas dry runs the call, returning the gas it uses. An error returned
+// by the node for the call itself, e.g. a revert or insufficient funds, is
+// returned as the reason, and does not count as a failure of the connection.
+func (c *rpcclient) estimateGas(ctx context.Context, msg ethereum.CallMsg) (gas uint64, reason string, err error) {
+	return gas, reason, c.withClient(func(ec *ethConn) error {
+		gas, err = ec.EstimateGas(ctx, msg)
+		var rpcErr rpc.Error
+		if errors.As(err, &rpcErr) {
+			reason = rpcErr.Error()
+			return nil
+		}
+		return err
+	})
+}
+
 // dumbBalance gets the account balance, ignoring the effects of unmined
 // transactions.
 func (c *rpcclient) dumbBalance(ctx context.Context, ec *ethConn, assetID uint32, addr common.Address) (bal *big.Int, err error) {
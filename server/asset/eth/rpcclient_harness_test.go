@@ -57,7 +57,7 @@ func TestMain(m *testing.M) {
 			return 1, fmt.Errorf("no contract address for eth version %d on %s", ethContractVersion, dex.Simnet)
 		}
 
-		ethClient = newRPCClient(BipID, 42, dex.Simnet, []endpoint{{url: wsEndpoint}, {url: alphaIPCFile}}, ethContractAddr, log)
+		ethClient = newRPCClient(BipID, 42, dex.Simnet, []endpoint{{url: wsEndpoint}, {url: alphaIPCFile}}, 0, ethContractAddr, log)
 
 		dexeth.ContractAddresses[0][dex.Simnet] = getContractAddrFromFile(contractAddrFile)
 
@@ -203,8 +203,8 @@ func TestHeaderSubscription(t *testing.T) {
 	ctx, cancel := context.WithTimeout(ctx, headerExpirationTime)
 	defer cancel()
 	ept := endpoint{url: wsEndpoint}
-	cl := newRPCClient(BipID, 42, dex.Simnet, []endpoint{ept}, ethClient.ethContractAddr, ethClient.log)
-	ec, err := cl.connectToEndpoint(ctx, ept)
+	cl := newRPCClient(BipID, 42, dex.Simnet, []endpoint{ept}, 0, ethClient.ethContractAddr, ethClient.log)
+	ec, err := cl.connectToEndpoint(ctx, ept, true)
 	if err != nil {
 		t.Fatalf("connectToEndpoint error: %v", err)
 	}
@@ -32,8 +32,8 @@ func (d *Driver) Setup(cfg *asset.BackendConfig) (asset.Backend, error) {
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // DigiByte.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Digibyte and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Version returns the Backend implementation's version number.
@@ -132,8 +132,9 @@ func NewBackend(cfg *asset.BackendConfig) (asset.Backend, error) {
 		// It also doesn't accept an estimate_mode argument.
 		// Neither estimatesmartfee or estimatefee work on testnet v0.14.12.4,
 		// but estimatefee works on simnet, and on mainnet v0.14.12.1.
-		DumbFeeEstimates: true,
-		RelayAddr:        cfg.RelayAddr,
+		DumbFeeEstimates:    true,
+		NoTestMempoolAccept: true,
+		RelayAddr:           cfg.RelayAddr,
 	})
 }
 
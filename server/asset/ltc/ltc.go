@@ -25,8 +25,8 @@ func (d *Driver) Setup(cfg *asset.BackendConfig) (asset.Backend, error) {
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Litecoin.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Litecoin and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // UnitInfo returns the dex.UnitInfo for the asset.
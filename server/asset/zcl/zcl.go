@@ -31,8 +31,8 @@ func (d *Driver) Setup(cfg *asset.BackendConfig) (asset.Backend, error) {
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Zcash.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Zcash and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Version returns the Backend implementation's version number.
@@ -141,6 +141,7 @@ func NewBackend(cfg *asset.BackendConfig) (asset.Backend, error) {
 		BlockFeeTransactions: blockFeeTransactions,
 		NumericGetRawRPC:     true,
 		ShieldedIO:           shieldedIO,
+		NoTestMempoolAccept:  true,
 		RelayAddr:            cfg.RelayAddr,
 	})
 	if err != nil {
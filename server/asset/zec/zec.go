@@ -30,8 +30,8 @@ func (d *Driver) Setup(cfg *asset.BackendConfig) (asset.Backend, error) {
 // DecodeCoinID creates a human-readable representation of a coin ID for
 // Zcash.
 func (d *Driver) DecodeCoinID(coinID []byte) (string, error) {
-	// Zcash and Bitcoin have the same tx hash and output format.
-	return (&btc.Driver{}).DecodeCoinID(coinID)
+	s, _, err := dex.DecodeCoinID(BipID, coinID)
+	return s, err
 }
 
 // Version returns the Backend implementation's version number.
@@ -144,6 +144,7 @@ func NewBackend(cfg *asset.BackendConfig) (asset.Backend, error) {
 		BlockFeeTransactions: blockFeeTransactions,
 		NumericGetRawRPC:     true,
 		ShieldedIO:           shieldedIO,
+		NoTestMempoolAccept:  true,
 		RelayAddr:            cfg.RelayAddr,
 	})
 	if err != nil {
@@ -194,28 +195,29 @@ func (*ZECBackend) ValidateOrderFunding(swapVal, valSum, inputCount, inputsSize,
 	return valSum >= reqVal
 }
 
-func (be *ZECBackend) ValidateFeeRate(ci asset.Coin, _ uint64) bool {
+func (be *ZECBackend) ValidateFeeRate(ci asset.Coin, _ uint64) error {
 	c, is := ci.(interface {
 		InputsValue() uint64
 		RawTx() []byte
 	})
 	if !is {
-		be.log.Error("ValidateFeeRate contract does not implement TXIO methods")
-		return false
+		return fmt.Errorf("ValidateFeeRate contract %T does not implement TXIO methods", ci)
 	}
 	tx, err := dexzec.DeserializeTx(c.RawTx())
 	if err != nil {
-		be.log.Errorf("error deserializing tx for fee validation: %v", err)
-		return false
+		return fmt.Errorf("error deserializing tx for fee validation: %w", err)
 	}
 
 	fees, err := newFeeTx(tx).Fees(c.InputsValue())
 	if err != nil {
-		be.log.Errorf("error calculating tx fees: %v", err)
-		return false
+		return fmt.Errorf("error calculating tx fees: %w", err)
 	}
 
-	return fees >= tx.RequiredTxFeesZIP317()
+	if reqFees := tx.RequiredTxFeesZIP317(); fees < reqFees {
+		return fmt.Errorf("transaction %s fees %d zats are less than the ZIP-317 required fees %d zats",
+			ci.TxID(), fees, reqFees)
+	}
+	return nil
 }
 
 func blockFeeTransactions(rc *btc.RPCClient, blockHash *chainhash.Hash) (feeTxs []btc.FeeTx, prevBlock chainhash.Hash, err error) {
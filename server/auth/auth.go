@@ -241,6 +241,7 @@ type AuthManager struct {
 	freeCancels      bool
 	penaltyThreshold int32
 	cancelThresh     float64
+	cancelRatios     *cancelRatioTracker
 
 	// latencyQ is a queue for fee coin waiters to deal with latency.
 	latencyQ *wait.TickerQueue
@@ -395,6 +396,15 @@ type Config struct {
 	CancelThreshold float64
 	FreeCancels     bool
 
+	// MaxCancelRatio is the maximum ratio of cancel orders to trade orders
+	// that a user may submit over the CancelRatioWindow before new trade
+	// orders are refused. Zero disables enforcement.
+	MaxCancelRatio float64
+	// CancelRatioWindow is the duration of the rolling window over which the
+	// cancellation ratio is computed. If zero, DefaultCancelRatioWindow is
+	// used.
+	CancelRatioWindow time.Duration
+
 	// PenaltyThreshold defines the score deficit at which a user's bond is
 	// revoked.
 	PenaltyThreshold uint32
@@ -430,6 +440,7 @@ func NewAuthManager(cfg *Config) *AuthManager {
 		freeCancels:      cfg.FreeCancels,
 		penaltyThreshold: penaltyThreshold,
 		cancelThresh:     cfg.CancelThreshold,
+		cancelRatios:     newCancelRatioTracker(cfg.MaxCancelRatio, cfg.CancelRatioWindow),
 		latencyQ:         wait.NewTickerQueue(recheckInterval),
 		users:            make(map[account.AccountID]*clientInfo),
 		conns:            make(map[uint64]*clientInfo),
@@ -566,6 +577,7 @@ func (auth *AuthManager) Run(ctx context.Context) {
 			select {
 			case <-t.C:
 				auth.checkBonds()
+				auth.cancelRatios.pruneUsers(time.Now())
 			case <-ctx.Done():
 				return
 			}
@@ -1688,6 +1700,9 @@ func (auth *AuthManager) handleConnect(conn comms.Link, msg *msgjson.Message) *m
 		ActiveBonds:         msgBonds,
 		Reputation:          rep,
 	}
+	if auth.cancelRatios.maxRatio > 0 {
+		resp.CancelRatio = auth.CancelRatio(user)
+	}
 	respMsg, err := msgjson.NewResponse(msg.ID, resp, nil)
 	if err != nil {
 		log.Errorf("handleConnect prepare response error: %v", err)
@@ -9,6 +9,7 @@ import (
 	"crypto/sha256"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -233,6 +234,10 @@ func (c *TRPCClient) CustomID() string {
 
 func (c *TRPCClient) SetCustomID(string) {}
 
+func (c *TRPCClient) Supports(string) bool {
+	return true
+}
+
 var tClientID uint64
 
 func tNewRPCClient() *TRPCClient {
@@ -393,12 +398,14 @@ func waitFor(pred func() bool, timeout time.Duration) (fail bool) {
 var (
 	tBondConfs       int64 = 5
 	tParseBondTxAcct account.AccountID
+	tParseBondTxAmt  int64
+	tParseBondTxLock = time.Minute
 	tParseBondTxErr  error
 )
 
 func tParseBondTx(assetID uint32, ver uint16, rawTx []byte) (bondCoinID []byte, amt int64,
 	lockTime int64, acct account.AccountID, err error) {
-	return nil, 0, time.Now().Add(time.Minute).Unix(), tParseBondTxAcct, tParseBondTxErr
+	return nil, tParseBondTxAmt, time.Now().Add(tParseBondTxLock).Unix(), tParseBondTxAcct, tParseBondTxErr
 }
 
 const (
@@ -434,6 +441,12 @@ func TestMain(m *testing.M) {
 					Confs:   uint32(tBondConfs),
 					Amt:     tRegFee * 10,
 				},
+				"btc": {
+					Version: 0,
+					ID:      0,
+					Confs:   uint32(tBondConfs),
+					Amt:     tRegFee,
+				},
 			},
 			BondTxParser:    tParseBondTx,
 			UserUnbooker:    func(account.AccountID) {},
@@ -1527,3 +1540,178 @@ func Test_checkSigS256(t *testing.T) {
 	sig = []byte{0x30, 1, 0x02, 0x01, 9, 0x2, 0x01, 10}
 	ecdsa.ParseDERSignature(sig) // panic on line 139: rLen := int(sigStr[index]) with index=3 and len = 3
 }
+
+func TestCancelRatio(t *testing.T) {
+	const maxRatio = 0.5
+	origTracker := rig.mgr.cancelRatios
+	defer func() { rig.mgr.cancelRatios = origTracker }()
+	tracker := newCancelRatioTracker(maxRatio, time.Hour)
+	rig.mgr.cancelRatios = tracker
+
+	user := tNewUser(t)
+	now := time.Now()
+	record := func(n int, cancel bool, stamp time.Time) {
+		for i := 0; i < n; i++ {
+			rig.mgr.RecordOrderSubmitted(user.acctID, cancel, stamp)
+		}
+	}
+
+	// Mostly cancels, but not enough trade orders to enforce the limit.
+	record(cancelRatioMinOrders-1, false, now)
+	record(cancelRatioMinOrders, true, now)
+	if err := rig.mgr.CheckCancelRatio(user.acctID); err != nil {
+		t.Fatalf("cancel ratio enforced with too few trade orders: %v", err)
+	}
+
+	// One more trade order and the limit is enforced.
+	record(1, false, now)
+	err := rig.mgr.CheckCancelRatio(user.acctID)
+	if !errors.Is(err, ErrCancelRatioExceeded) {
+		t.Fatalf("expected ErrCancelRatioExceeded, got %v", err)
+	}
+	cr := rig.mgr.CancelRatio(user.acctID)
+	if cr.Trades != cancelRatioMinOrders || cr.Cancels != cancelRatioMinOrders || cr.Ratio != 1 || cr.Limit != maxRatio {
+		t.Fatalf("wrong cancel ratio %+v", cr)
+	}
+
+	// The limit is included in the connect response.
+	rig.signer.sig = user.randomSignature()
+	result := extractConnectResult(t, connectUser(t, user))
+	if result.CancelRatio == nil || result.CancelRatio.Cancels != cancelRatioMinOrders {
+		t.Fatalf("wrong connect response cancel ratio %+v", result.CancelRatio)
+	}
+
+	// Normal trading is not limited.
+	normie := tNewUser(t)
+	for i := 0; i < 20; i++ {
+		rig.mgr.RecordOrderSubmitted(normie.acctID, false, now)
+		if i%4 == 0 {
+			rig.mgr.RecordOrderSubmitted(normie.acctID, true, now)
+		}
+	}
+	if err := rig.mgr.CheckCancelRatio(normie.acctID); err != nil {
+		t.Fatalf("normal trading limited: %v", err)
+	}
+
+	// Orders age out of the window.
+	tracker.mtx.Lock()
+	stamps := tracker.users[user.acctID]
+	for i := range stamps.cancels {
+		stamps.cancels[i] = now.Add(-2 * time.Hour)
+	}
+	tracker.mtx.Unlock()
+	if err := rig.mgr.CheckCancelRatio(user.acctID); err != nil {
+		t.Fatalf("expired cancels counted: %v", err)
+	}
+	if cr = rig.mgr.CancelRatio(user.acctID); cr.Cancels != 0 || cr.Ratio != 0 {
+		t.Fatalf("wrong cancel ratio after expiry %+v", cr)
+	}
+
+	// Users with no orders in the window are no longer tracked.
+	tracker.pruneUsers(now.Add(2 * time.Hour))
+	tracker.mtx.Lock()
+	numUsers := len(tracker.users)
+	tracker.mtx.Unlock()
+	if numUsers != 0 {
+		t.Fatalf("%d users with expired orders still tracked", numUsers)
+	}
+
+	// Nothing is tracked or enforced with no maximum.
+	rig.mgr.cancelRatios = newCancelRatioTracker(0, time.Hour)
+	record(cancelRatioMinOrders, false, now)
+	record(cancelRatioMinOrders*2, true, now)
+	if err := rig.mgr.CheckCancelRatio(user.acctID); err != nil {
+		t.Fatalf("cancel ratio enforced with no maximum: %v", err)
+	}
+	if cr = rig.mgr.CancelRatio(user.acctID); cr.Trades != 0 || cr.Cancels != 0 {
+		t.Fatalf("orders tracked with no maximum: %+v", cr)
+	}
+}
+
+func TestSelectBondAssets(t *testing.T) {
+	supported := map[string]*msgjson.BondAsset{
+		"dcr": {ID: 42, Amt: 1e8, Confs: 2},
+		"btc": {ID: 0, Amt: 1e6, Confs: 1},
+	}
+	backed := func(symbol string) bool {
+		return symbol == "dcr" || symbol == "btc" || symbol == "ltc"
+	}
+
+	// No choice accepts all supported assets.
+	selected, err := SelectBondAssets(supported, backed, nil)
+	if err != nil {
+		t.Fatalf("error selecting default bond assets: %v", err)
+	}
+	if len(selected) != 2 {
+		t.Fatalf("expected 2 default bond assets, got %d", len(selected))
+	}
+
+	// A non-default subset.
+	selected, err = SelectBondAssets(supported, backed, []string{" BTC "})
+	if err != nil {
+		t.Fatalf("error selecting bond assets: %v", err)
+	}
+	if len(selected) != 1 || selected["btc"] != supported["btc"] {
+		t.Fatalf("wrong bond assets selected: %v", selected)
+	}
+
+	// An asset with a backend that is not configured for bonds.
+	if _, err = SelectBondAssets(supported, backed, []string{"dcr", "ltc"}); err == nil {
+		t.Fatalf("no error for an asset not supported for bonds")
+	}
+
+	// An asset with no backend.
+	if _, err = SelectBondAssets(supported, backed, []string{"doge"}); err == nil {
+		t.Fatalf("no error for an asset with no backend")
+	}
+}
+
+func TestPreValidateBondAsset(t *testing.T) {
+	user := tNewUser(t)
+	tParseBondTxAcct = user.acctID
+	tParseBondTxAmt = int64(tRegFee)
+	tParseBondTxLock = 2 * rig.mgr.bondExpiry
+	defer func() {
+		tParseBondTxAcct = account.AccountID{}
+		tParseBondTxAmt = 0
+		tParseBondTxLock = time.Minute
+	}()
+
+	preValidate := func(assetID uint32) *msgjson.Error {
+		t.Helper()
+		preBond := &msgjson.PreValidateBond{
+			AcctPubKey: user.privKey.PubKey().SerializeCompressed(),
+			AssetID:    assetID,
+			RawTx:      randBytes(100),
+		}
+		preBond.SetSig(signMsg(user.privKey, preBond.Serialize()))
+		msg, _ := msgjson.NewRequest(comms.NextID(), msgjson.PreValidateBondRoute, preBond)
+		return rig.mgr.handlePreValidateBond(user.conn, msg)
+	}
+
+	// A bond in an accepted, non-default asset is recognized.
+	if rpcErr := preValidate(0); rpcErr != nil {
+		t.Fatalf("error pre-validating btc bond: %s", rpcErr.Message)
+	}
+	resp := user.conn.getSend()
+	if resp == nil {
+		t.Fatalf("no prevalidatebond response")
+	}
+	res := new(msgjson.PreValidateBondResult)
+	if err := resp.UnmarshalResult(res); err != nil {
+		t.Fatalf("error unmarshaling prevalidatebond result: %v", err)
+	}
+	if res.AssetID != 0 || res.Amount != tRegFee {
+		t.Fatalf("wrong prevalidatebond result: asset %d, amount %d", res.AssetID, res.Amount)
+	}
+
+	// The same amount is insufficient for dcr.
+	if rpcErr := preValidate(42); rpcErr == nil || rpcErr.Code != msgjson.BondError {
+		t.Fatalf("no bond error for an insufficient dcr bond")
+	}
+
+	// A bond in an unaccepted asset is rejected.
+	if rpcErr := preValidate(60); rpcErr == nil || rpcErr.Code != msgjson.BondError {
+		t.Fatalf("no bond error for an unaccepted bond asset")
+	}
+}
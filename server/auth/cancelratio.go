@@ -0,0 +1,171 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/server/account"
+)
+
+const (
+	// DefaultCancelRatioWindow is the default duration of the rolling window
+	// over which a user's cancellation ratio is computed.
+	DefaultCancelRatioWindow = time.Hour
+	// cancelRatioMinOrders is the number of trade orders a user must submit in
+	// the window before the maximum cancellation ratio is enforced.
+	cancelRatioMinOrders = 10
+)
+
+// ErrCancelRatioExceeded is returned by CheckCancelRatio when the user's
+// cancellation ratio exceeds the maximum.
+var ErrCancelRatioExceeded = errors.New("cancellation ratio exceeded")
+
+// orderStamps are the times at which a user submitted trade and cancel orders
+// within the cancellation ratio window, oldest first.
+type orderStamps struct {
+	trades  []time.Time
+	cancels []time.Time
+}
+
+// prune removes the stamps older than the cutoff time.
+func (s *orderStamps) prune(cutoff time.Time) {
+	pruneStamps := func(stamps []time.Time) []time.Time {
+		var i int
+		for i < len(stamps) && stamps[i].Before(cutoff) {
+			i++
+		}
+		return stamps[i:]
+	}
+	s.trades = pruneStamps(s.trades)
+	s.cancels = pruneStamps(s.cancels)
+}
+
+// cancelRatioTracker tracks the trade and cancel orders submitted by users over
+// a rolling window of time. Only user-submitted orders are tracked, so cancels
+// and revocations generated by the server, such as when orders are revoked by a
+// market retune, do not count against the user.
+type cancelRatioTracker struct {
+	maxRatio float64 // zero disables enforcement
+	window   time.Duration
+
+	mtx   sync.Mutex
+	users map[account.AccountID]*orderStamps
+}
+
+func newCancelRatioTracker(maxRatio float64, window time.Duration) *cancelRatioTracker {
+	if window <= 0 {
+		window = DefaultCancelRatioWindow
+	}
+	return &cancelRatioTracker{
+		maxRatio: maxRatio,
+		window:   window,
+		users:    make(map[account.AccountID]*orderStamps),
+	}
+}
+
+// record records a user's submitted trade or cancel order.
+func (t *cancelRatioTracker) record(user account.AccountID, cancel bool, stamp time.Time) {
+	if t.maxRatio <= 0 {
+		return
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	stamps := t.users[user]
+	if stamps == nil {
+		stamps = new(orderStamps)
+		t.users[user] = stamps
+	}
+	stamps.prune(stamp.Add(-t.window))
+	if cancel {
+		stamps.cancels = append(stamps.cancels, stamp)
+	} else {
+		stamps.trades = append(stamps.trades, stamp)
+	}
+}
+
+// counts returns the numbers of trade and cancel orders submitted by the user
+// within the window ending at now.
+func (t *cancelRatioTracker) counts(user account.AccountID, now time.Time) (trades, cancels int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	stamps := t.users[user]
+	if stamps == nil {
+		return 0, 0
+	}
+	stamps.prune(now.Add(-t.window))
+	if len(stamps.trades) == 0 && len(stamps.cancels) == 0 {
+		delete(t.users, user)
+		return 0, 0
+	}
+	return len(stamps.trades), len(stamps.cancels)
+}
+
+// pruneUsers stops tracking the users with no orders in the window ending at
+// now.
+func (t *cancelRatioTracker) pruneUsers(now time.Time) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	cutoff := now.Add(-t.window)
+	for user, stamps := range t.users {
+		stamps.prune(cutoff)
+		if len(stamps.trades) == 0 && len(stamps.cancels) == 0 {
+			delete(t.users, user)
+		}
+	}
+}
+
+// status returns the user's current cancellation ratio.
+func (t *cancelRatioTracker) status(user account.AccountID, now time.Time) *msgjson.CancelRatio {
+	trades, cancels := t.counts(user, now)
+	var ratio float64
+	if trades > 0 {
+		ratio = float64(cancels) / float64(trades)
+	}
+	return &msgjson.CancelRatio{
+		Ratio:   ratio,
+		Limit:   t.maxRatio,
+		Window:  uint64(t.window.Milliseconds()),
+		Trades:  uint32(trades),
+		Cancels: uint32(cancels),
+	}
+}
+
+// exceeded checks whether the cancellation ratio status is over the limit.
+// Users are not throttled until they have submitted cancelRatioMinOrders trade
+// orders in the window.
+func (t *cancelRatioTracker) exceeded(cr *msgjson.CancelRatio) bool {
+	return t.maxRatio > 0 && cr.Trades >= cancelRatioMinOrders && cr.Ratio > t.maxRatio
+}
+
+// RecordOrderSubmitted records a trade or cancel order submitted by the user
+// for cancellation ratio tracking.
+func (auth *AuthManager) RecordOrderSubmitted(user account.AccountID, cancel bool, t time.Time) {
+	auth.cancelRatios.record(user, cancel, t)
+}
+
+// CancelRatio returns the user's cancellation ratio over the rolling window,
+// and the maximum ratio. Limit is zero if the maximum is not enforced.
+func (auth *AuthManager) CancelRatio(user account.AccountID) *msgjson.CancelRatio {
+	return auth.cancelRatios.status(user, time.Now())
+}
+
+// CheckCancelRatio checks that the user's ratio of cancel orders to trade
+// orders submitted over the rolling window does not exceed the configured
+// maximum. If it does, an error wrapping ErrCancelRatioExceeded that explains
+// when the user may trade again is returned, and new trade orders should be
+// rejected.
+func (auth *AuthManager) CheckCancelRatio(user account.AccountID) error {
+	cr := auth.cancelRatios.status(user, time.Now())
+	if !auth.cancelRatios.exceeded(cr) {
+		return nil
+	}
+	return fmt.Errorf("%w: %d cancels for %d trade orders in the last %s is a ratio of %.2f, above the maximum of %.2f. "+
+		"New trade orders are refused until the ratio falls below the maximum",
+		ErrCancelRatioExceeded, cr.Cancels, cr.Trades, auth.cancelRatios.window, cr.Ratio, cr.Limit)
+}
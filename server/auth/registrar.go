@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"decred.org/dcrdex/dex"
@@ -26,6 +27,32 @@ var (
 	txWaitExpiration = 2 * time.Minute
 )
 
+// SelectBondAssets limits the bond assets to those chosen by the operator,
+// identified by symbol. supported are the assets with a backend that can check
+// bonds and a configured bond amount and confirmations, keyed by symbol.
+// backed reports whether an asset has a running backend. An error is returned
+// if a chosen asset has no backend or is not supported for bonds. If none are
+// chosen, all supported assets are accepted.
+func SelectBondAssets(supported map[string]*msgjson.BondAsset, backed func(symbol string) bool,
+	chosen []string) (map[string]*msgjson.BondAsset, error) {
+	if len(chosen) == 0 {
+		return supported, nil
+	}
+	selected := make(map[string]*msgjson.BondAsset, len(chosen))
+	for _, symbol := range chosen {
+		symbol = strings.ToLower(strings.TrimSpace(symbol))
+		if !backed(symbol) {
+			return nil, fmt.Errorf("bond asset %q has no backend", symbol)
+		}
+		bondAsset, ok := supported[symbol]
+		if !ok {
+			return nil, fmt.Errorf("bond asset %q is not supported for bonds. a bond amount and confirmations must be configured", symbol)
+		}
+		selected[symbol] = bondAsset
+	}
+	return selected, nil
+}
+
 // bondKey creates a unique map key for a bond by its asset ID and coin ID.
 func bondKey(assetID uint32, coinID []byte) string {
 	return string(append(encode.Uint32Bytes(assetID), coinID...))
@@ -18,6 +18,7 @@ import (
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/wait"
 	"decred.org/dcrdex/server/admin"
+	"decred.org/dcrdex/server/asset"
 	"decred.org/dcrdex/server/auth"
 	"decred.org/dcrdex/server/book"
 	"decred.org/dcrdex/server/comms"
@@ -55,6 +56,7 @@ const (
 	defaultCancelThresh     = 0.95             // 19 cancels : 1 success
 	defaultBroadcastTimeout = 12 * time.Minute // accommodate certain known long block download timeouts
 	defaultTxWaitExpiration = 2 * time.Minute
+	defaultBookRetention    = 30 * 24 * time.Hour
 )
 
 var (
@@ -68,38 +70,54 @@ type procOpts struct {
 
 // dexConf is the data that is required to setup the dex.
 type dexConf struct {
-	DataDir          string
-	Network          dex.Network
-	DBName           string
-	DBUser           string
-	DBPass           string
-	DBHost           string
-	DBPort           uint16
-	ShowPGConfig     bool
-	MarketsConfPath  string
-	CancelThreshold  float64
-	FreeCancels      bool
-	MaxUserCancels   uint32
-	PenaltyThreshold uint32
-	DEXPrivKeyPath   string
-	RPCCert          string
-	RPCKey           string
-	NoTLS            bool
-	RPCListen        []string
-	HiddenService    string
-	BroadcastTimeout time.Duration
-	TxWaitExpiration time.Duration
-	AltDNSNames      []string
-	LogMaker         *dex.LoggerMaker
-	SigningKeyPW     []byte
-	AdminSrvOn       bool
-	AdminSrvAddr     string
-	AdminSrvPW       []byte
-	AdminSrvNoTLS    bool
-	NoResumeSwaps    bool
-	DisableDataAPI   bool
-	NodeRelayAddr    string
-	ValidateMarkets  bool
+	DataDir           string
+	Network           dex.Network
+	DBName            string
+	DBUser            string
+	DBPass            string
+	DBHost            string
+	DBPort            uint16
+	ShowPGConfig      bool
+	BookRetention     time.Duration
+	MarketsConfPath   string
+	CancelThreshold   float64
+	FreeCancels       bool
+	MaxCancelRatio    float64
+	CancelRatioWindow time.Duration
+	MaxUserCancels    uint32
+	PenaltyThreshold  uint32
+	BondAssets        []string
+	DEXPrivKeyPath    string
+	RPCCert           string
+	RPCKey            string
+	NoTLS             bool
+	RPCListen         []string
+	HiddenService     string
+	RPCUnixSocket     string
+	MaxMessageSize    int64
+	BroadcastTimeout  time.Duration
+	TxWaitExpiration  time.Duration
+	AltDNSNames       []string
+	LogMaker          *dex.LoggerMaker
+	SigningKeyPW      []byte
+	AdminSrvOn        bool
+	AdminSrvAddr      string
+	AdminSrvPW        []byte
+	AdminSrvNoTLS     bool
+	NoResumeSwaps     bool
+	DrainTimeout      time.Duration
+	CircuitBreaker    *asset.CircuitBreakerConfig
+	ActivityRetention time.Duration
+	TradeTapeSize     int
+	DisableDataAPI    bool
+	NodeRelayAddr     string
+	ValidateMarkets   bool
+
+	ConsistencyInterval   time.Duration
+	ConsistencySampleRate float64
+
+	SwapConcurrency int
+	SwapQueueSize   int
 }
 
 type flagsData struct {
@@ -122,16 +140,22 @@ type flagsData struct {
 	NoTLS         bool     `long:"notls" description:"Run without TLS encryption."`
 	AltDNSNames   []string `long:"altdnsnames" description:"A list of hostnames to include in the RPC certificate (X509v3 Subject Alternative Name)."`
 	HiddenService string   `long:"hiddenservice" description:"A host:port on which the RPC server should listen for incoming hidden service connections. No TLS is used for these connections."`
+	RPCUnixSocket string   `long:"rpcunixsocket" description:"Path of a Unix domain socket on which the RPC server should also listen, for use with a reverse proxy that sets the X-Real-IP or X-Forwarded-For header. No TLS is used for these connections."`
+
+	MaxMessageSize int64 `long:"maxmessagesize" description:"Maximum size in bytes of an order book snapshot message. Larger snapshots are paginated for clients that support book pages. Minimum is 65536 (default: 1048576)."`
 
 	MarketsConfPath  string        `long:"marketsconfpath" description:"Path to the markets configuration JSON file."`
 	BroadcastTimeout time.Duration `long:"bcasttimeout" description:"The broadcast timeout specifies how long clients have to broadcast an expected transaction when it is their turn to act. Matches without the expected action by this time are revoked and the actor is penalized (default: 12 minutes)."`
 	TxWaitExpiration time.Duration `long:"txwaitexpiration" description:"How long the server will search for a client-reported transaction before responding to the client with an error indicating that it was not found. This should ideally be less than half of swaps BroadcastTimeout to allow for more than one retry of the client's request (default: 2 minutes)."`
 	DEXPrivKeyPath   string        `long:"dexprivkeypath" description:"The path to a file containing the DEX private key for message signing."`
 
-	CancelThreshold  float64 `long:"cancelthresh" description:"Cancellation rate threshold (cancels/all_completed)."`
-	FreeCancels      bool    `long:"freecancels" description:"No cancellation rate enforcement (unlimited cancel orders)."`
-	MaxUserCancels   uint32  `long:"maxepochcancels" description:"The maximum number of cancel orders allowed for a user in a given epoch."`
-	PenaltyThreshold uint32  `long:"penaltythreshold" description:"The accumulated penalty score at which when a bond is revoked."`
+	CancelThreshold   float64       `long:"cancelthresh" description:"Cancellation rate threshold (cancels/all_completed)."`
+	FreeCancels       bool          `long:"freecancels" description:"No cancellation rate enforcement (unlimited cancel orders)."`
+	MaxCancelRatio    float64       `long:"maxcancelratio" description:"The maximum ratio of cancel orders to trade orders a user may submit over the cancelratiowindow before new trade orders are refused. 0 disables the limit."`
+	CancelRatioWindow time.Duration `long:"cancelratiowindow" description:"The rolling window over which the maxcancelratio is enforced (default: 1h)."`
+	MaxUserCancels    uint32        `long:"maxepochcancels" description:"The maximum number of cancel orders allowed for a user in a given epoch."`
+	PenaltyThreshold  uint32        `long:"penaltythreshold" description:"The accumulated penalty score at which when a bond is revoked."`
+	BondAssets        []string      `long:"bondasset" description:"An asset accepted for bonds, by symbol. Each must have bondAmt and bondConfs set in the markets config. May be repeated. If not set, bonds are accepted in every asset with bondAmt and bondConfs set."`
 
 	HTTPProfile bool   `long:"httpprof" short:"p" description:"Start HTTP profiler."`
 	CPUProfile  string `long:"cpuprofile" description:"File for CPU profiling."`
@@ -147,7 +171,24 @@ type flagsData struct {
 	AdminSrvPassword   string `long:"adminsrvpass" description:"Admin server password. INSECURE. Do not set unless absolutely necessary."`
 	AdminSrvNoTLS      bool   `long:"adminsrvnotls" description:"Run admin server without TLS. Only use this option if you are using a securely configured reverse proxy."`
 
-	NoResumeSwaps bool `long:"noresumeswaps" description:"Do not attempt to resume swaps that are active in the DB."`
+	BookRetention time.Duration `long:"bookretention" description:"How long to keep the recorded epoch books used to replay matching. Set to 0 to keep them indefinitely (default: 720h)."`
+	NoResumeSwaps bool          `long:"noresumeswaps" description:"Do not attempt to resume swaps that are active in the DB."`
+	DrainTimeout  time.Duration `long:"draintimeout" description:"On shutdown, refuse new orders and connections and wait up to this long for the current epochs to close and active swaps to settle before exiting (e.g. 10m). 0 disables draining."`
+
+	CircuitBreaker   bool          `long:"breaker" description:"Automatically suspend markets when an asset backend is unhealthy, and resume them when it recovers."`
+	BreakerInterval  time.Duration `long:"breakerinterval" description:"How often asset backend health is checked for the circuit breakers."`
+	BreakerThreshold int           `long:"breakerthreshold" description:"The number of consecutive failed asset backend health checks that will suspend the asset's markets."`
+	BreakerRecovery  time.Duration `long:"breakerrecovery" description:"How long a failed asset backend must remain healthy before the asset's markets are resumed."`
+
+	ActivityRetention time.Duration `long:"activityretention" description:"How long hourly market activity summaries are kept (default: 2160h)."`
+
+	TradeTapeSize int `long:"tradetape" description:"The number of recent trades retained for each market's public trade tape (default: 500)."`
+
+	ConsistencyInterval   time.Duration `long:"consistencyinterval" description:"The time between checks of the recorded swap state against the asset blockchains (default: 30m). A negative value disables the checks."`
+	ConsistencySampleRate float64       `long:"consistencysamplerate" description:"The fraction of active and recent matches checked each consistencyinterval (default: 0.1)."`
+
+	SwapConcurrency int `long:"swapconcurrency" description:"The maximum number of client-reported swap and redeem transactions of an asset that are looked up on the asset's backend at once (default: 16)."`
+	SwapQueueSize   int `long:"swapqueue" description:"The maximum number of client-reported swap and redeem transactions of an asset that are pending lookup. Further init and redeem requests are refused until the queue drains (default: 1024)."`
 
 	DisableDataAPI bool `long:"nodata" description:"Disable the HTTP data API."`
 
@@ -262,9 +303,13 @@ func loadConfig() (*dexConf, *procOpts, error) {
 		DEXPrivKeyPath:   defaultDEXPrivKeyFilename,
 		BroadcastTimeout: defaultBroadcastTimeout,
 		TxWaitExpiration: defaultTxWaitExpiration,
+		BookRetention:    defaultBookRetention,
 		CancelThreshold:  defaultCancelThresh,
 		MaxUserCancels:   defaultMaxUserCancels,
 		PenaltyThreshold: defaultPenaltyThresh,
+		BreakerInterval:  asset.DefaultBreakerCheckInterval,
+		BreakerThreshold: asset.DefaultBreakerFailThreshold,
+		BreakerRecovery:  asset.DefaultBreakerRecoveryPeriod,
 	}
 
 	// Pre-parse the command line options to see if an alternative config file
@@ -443,6 +488,9 @@ func loadConfig() (*dexConf, *procOpts, error) {
 	if !filepath.IsAbs(cfg.RPCKey) {
 		cfg.RPCKey = filepath.Join(cfg.AppDataDir, cfg.RPCKey)
 	}
+	if cfg.RPCUnixSocket != "" && !filepath.IsAbs(cfg.RPCUnixSocket) {
+		cfg.RPCUnixSocket = filepath.Join(cfg.AppDataDir, cfg.RPCUnixSocket)
+	}
 	if !filepath.IsAbs(cfg.MarketsConfPath) {
 		cfg.MarketsConfPath = filepath.Join(cfg.AppDataDir, cfg.MarketsConfPath)
 	}
@@ -529,39 +577,64 @@ func loadConfig() (*dexConf, *procOpts, error) {
 	// If using {netname} then replace it with the network name.
 	cfg.PGDBName = strings.ReplaceAll(cfg.PGDBName, "{netname}", network.String())
 
+	var breakerCfg *asset.CircuitBreakerConfig
+	if cfg.CircuitBreaker {
+		breakerCfg = &asset.CircuitBreakerConfig{
+			CheckInterval:  cfg.BreakerInterval,
+			FailThreshold:  cfg.BreakerThreshold,
+			RecoveryPeriod: cfg.BreakerRecovery,
+		}
+	}
+
 	dexCfg := &dexConf{
-		DataDir:          cfg.DataDir,
-		Network:          network,
-		DBName:           cfg.PGDBName,
-		DBHost:           dbHost,
-		DBPort:           dbPort,
-		DBUser:           cfg.PGUser,
-		DBPass:           cfg.PGPass,
-		ShowPGConfig:     cfg.ShowPGConfig,
-		MarketsConfPath:  cfg.MarketsConfPath,
-		CancelThreshold:  cfg.CancelThreshold,
-		MaxUserCancels:   cfg.MaxUserCancels,
-		FreeCancels:      cfg.FreeCancels,
-		PenaltyThreshold: cfg.PenaltyThreshold,
-		DEXPrivKeyPath:   cfg.DEXPrivKeyPath,
-		RPCCert:          cfg.RPCCert,
-		RPCKey:           cfg.RPCKey,
-		NoTLS:            cfg.NoTLS,
-		RPCListen:        RPCListen,
-		HiddenService:    HiddenService,
-		BroadcastTimeout: cfg.BroadcastTimeout,
-		TxWaitExpiration: cfg.TxWaitExpiration,
-		AltDNSNames:      cfg.AltDNSNames,
-		LogMaker:         logMaker,
-		SigningKeyPW:     []byte(cfg.SigningKeyPassword),
-		AdminSrvAddr:     adminSrvAddr,
-		AdminSrvOn:       cfg.AdminSrvOn,
-		AdminSrvPW:       []byte(cfg.AdminSrvPassword),
-		AdminSrvNoTLS:    cfg.AdminSrvNoTLS,
-		NoResumeSwaps:    cfg.NoResumeSwaps,
-		DisableDataAPI:   cfg.DisableDataAPI,
-		NodeRelayAddr:    cfg.NodeRelayAddr,
-		ValidateMarkets:  cfg.ValidateMarkets,
+		DataDir:           cfg.DataDir,
+		Network:           network,
+		DBName:            cfg.PGDBName,
+		DBHost:            dbHost,
+		DBPort:            dbPort,
+		DBUser:            cfg.PGUser,
+		DBPass:            cfg.PGPass,
+		ShowPGConfig:      cfg.ShowPGConfig,
+		BookRetention:     cfg.BookRetention,
+		MarketsConfPath:   cfg.MarketsConfPath,
+		CancelThreshold:   cfg.CancelThreshold,
+		MaxUserCancels:    cfg.MaxUserCancels,
+		FreeCancels:       cfg.FreeCancels,
+		MaxCancelRatio:    cfg.MaxCancelRatio,
+		CancelRatioWindow: cfg.CancelRatioWindow,
+		PenaltyThreshold:  cfg.PenaltyThreshold,
+		BondAssets:        cfg.BondAssets,
+		DEXPrivKeyPath:    cfg.DEXPrivKeyPath,
+		RPCCert:           cfg.RPCCert,
+		RPCKey:            cfg.RPCKey,
+		NoTLS:             cfg.NoTLS,
+		RPCListen:         RPCListen,
+		HiddenService:     HiddenService,
+		RPCUnixSocket:     cfg.RPCUnixSocket,
+		MaxMessageSize:    cfg.MaxMessageSize,
+		BroadcastTimeout:  cfg.BroadcastTimeout,
+		TxWaitExpiration:  cfg.TxWaitExpiration,
+		AltDNSNames:       cfg.AltDNSNames,
+		LogMaker:          logMaker,
+		SigningKeyPW:      []byte(cfg.SigningKeyPassword),
+		AdminSrvAddr:      adminSrvAddr,
+		AdminSrvOn:        cfg.AdminSrvOn,
+		AdminSrvPW:        []byte(cfg.AdminSrvPassword),
+		AdminSrvNoTLS:     cfg.AdminSrvNoTLS,
+		NoResumeSwaps:     cfg.NoResumeSwaps,
+		DrainTimeout:      cfg.DrainTimeout,
+		CircuitBreaker:    breakerCfg,
+		ActivityRetention: cfg.ActivityRetention,
+		TradeTapeSize:     cfg.TradeTapeSize,
+		DisableDataAPI:    cfg.DisableDataAPI,
+		NodeRelayAddr:     cfg.NodeRelayAddr,
+		ValidateMarkets:   cfg.ValidateMarkets,
+
+		ConsistencyInterval:   cfg.ConsistencyInterval,
+		ConsistencySampleRate: cfg.ConsistencySampleRate,
+
+		SwapConcurrency: cfg.SwapConcurrency,
+		SwapQueueSize:   cfg.SwapQueueSize,
 	}
 
 	opts := &procOpts{
@@ -57,6 +57,8 @@ var (
 		"MTCH": dex.Disabled,
 		"WAIT": dex.Disabled,
 		"ADMN": dex.Disabled,
+		"ANLY": dex.Disabled,
+		"CNST": dex.Disabled,
 
 		// Individual assets get their own subsystem loggers. This is here to
 		// register the ASSET subsystem ID, allowing the user to set the log
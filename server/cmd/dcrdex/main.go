@@ -87,17 +87,17 @@ func mainCore(ctx context.Context) error {
 		dex.LockTimeMaker(cfg.Network), dex.LockTimeTaker(cfg.Network))
 
 	// Load the market and asset configurations for the given network.
-	markets, assets, err := dexsrv.LoadConfig(cfg.Network, cfg.MarketsConfPath)
+	mktsConf, err := dexsrv.LoadConfig(cfg.Network, cfg.MarketsConfPath)
 	if err != nil {
 		return fmt.Errorf("failed to load market and asset config %q: %v",
 			cfg.MarketsConfPath, err)
 	}
 	log.Infof("Found %d assets, loaded %d markets, for network %s",
-		len(assets), len(markets), strings.ToUpper(cfg.Network.String()))
+		len(mktsConf.Assets), len(mktsConf.Markets), strings.ToUpper(cfg.Network.String()))
 	// NOTE: If MaxUserCancelsPerEpoch is ultimately a setting we want to keep,
 	// bake it into the markets.json file and load it per-market in settings.go.
 	// For now, patch it into each dex.MarketInfo.
-	for _, mkt := range markets {
+	for _, mkt := range mktsConf.Markets {
 		mkt.MaxUserCancelsPerEpoch = cfg.MaxUserCancels
 	}
 
@@ -119,23 +119,27 @@ func mainCore(ctx context.Context) error {
 	dexConf := &dexsrv.DexConf{
 		DataDir:    cfg.DataDir,
 		LogBackend: cfg.LogMaker,
-		Markets:    markets,
-		Assets:     assets,
+		Markets:    mktsConf.Markets,
+		Assets:     mktsConf.Assets,
 		Network:    cfg.Network,
 		DBConf: &dexsrv.DBConf{
-			DBName:       cfg.DBName,
-			Host:         cfg.DBHost,
-			User:         cfg.DBUser,
-			Port:         cfg.DBPort,
-			Pass:         cfg.DBPass,
-			ShowPGConfig: cfg.ShowPGConfig,
+			DBName:        cfg.DBName,
+			Host:          cfg.DBHost,
+			User:          cfg.DBUser,
+			Port:          cfg.DBPort,
+			Pass:          cfg.DBPass,
+			ShowPGConfig:  cfg.ShowPGConfig,
+			BookRetention: cfg.BookRetention,
 		},
-		BroadcastTimeout: cfg.BroadcastTimeout,
-		TxWaitExpiration: cfg.TxWaitExpiration,
-		CancelThreshold:  cfg.CancelThreshold,
-		FreeCancels:      cfg.FreeCancels,
-		PenaltyThreshold: cfg.PenaltyThreshold,
-		DEXPrivKey:       privKey,
+		BroadcastTimeout:  cfg.BroadcastTimeout,
+		TxWaitExpiration:  cfg.TxWaitExpiration,
+		CancelThreshold:   cfg.CancelThreshold,
+		FreeCancels:       cfg.FreeCancels,
+		MaxCancelRatio:    cfg.MaxCancelRatio,
+		CancelRatioWindow: cfg.CancelRatioWindow,
+		PenaltyThreshold:  cfg.PenaltyThreshold,
+		BondAssets:        cfg.BondAssets,
+		DEXPrivKey:        privKey,
 		CommsCfg: &dexsrv.RPCConfig{
 			RPCCert:           cfg.RPCCert,
 			NoTLS:             cfg.NoTLS,
@@ -144,9 +148,22 @@ func mainCore(ctx context.Context) error {
 			AltDNSNames:       cfg.AltDNSNames,
 			DisableDataAPI:    cfg.DisableDataAPI,
 			HiddenServiceAddr: cfg.HiddenService,
+			UnixSocket:        cfg.RPCUnixSocket,
+			MaxMessageSize:    cfg.MaxMessageSize,
 		},
-		NoResumeSwaps: cfg.NoResumeSwaps,
-		NodeRelayAddr: cfg.NodeRelayAddr,
+		NoResumeSwaps:     cfg.NoResumeSwaps,
+		NodeRelayAddr:     cfg.NodeRelayAddr,
+		CircuitBreaker:    cfg.CircuitBreaker,
+		ActivityRetention: cfg.ActivityRetention,
+		TradeTapeSize:     cfg.TradeTapeSize,
+
+		ConsistencyInterval:   cfg.ConsistencyInterval,
+		ConsistencySampleRate: cfg.ConsistencySampleRate,
+		Schedules:             mktsConf.Schedules,
+		Mirrors:               mktsConf.Mirrors,
+
+		SwapConcurrency: cfg.SwapConcurrency,
+		SwapQueueSize:   cfg.SwapQueueSize,
 	}
 	dexMan, err := dexsrv.NewDEX(ctx, dexConf) // ctx cancel just aborts setup; Stop does normal shutdown
 	if err != nil {
@@ -179,6 +196,10 @@ func mainCore(ctx context.Context) error {
 	// Wait for the admin server to finish.
 	wg.Wait()
 
+	if cfg.DrainTimeout > 0 {
+		dexMan.Drain(cfg.DrainTimeout)
+	}
+
 	log.Info("Stopping DEX...")
 	dexMan.Stop()
 	log.Info("Bye!")
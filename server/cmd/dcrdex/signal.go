@@ -10,12 +10,14 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"syscall"
 )
 
 // shutdownRequested checks if the Done channel of the given context has been
 // closed. This could indicate cancellation, expiration, or deadline expiry. But
 // when called for the context provided by withShutdownCancel, it indicates if
-// shutdown has been requested (i.e. via os.Interrupt or requestShutdown).
+// shutdown has been requested (i.e. via os.Interrupt, SIGTERM, or
+// requestShutdown).
 func shutdownRequested(ctx context.Context) bool {
 	select {
 	case <-ctx.Done():
@@ -58,7 +60,7 @@ func requestShutdown() {
 // to be spawned in a new goroutine.
 func shutdownListener() {
 	interruptChannel := make(chan os.Signal, 1)
-	signal.Notify(interruptChannel, os.Interrupt)
+	signal.Notify(interruptChannel, os.Interrupt, syscall.SIGTERM)
 
 	// Listen for the initial shutdown signal.
 	select {
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -40,6 +41,7 @@ func newServer() *Server {
 		dataEnabled: 1,
 		rpcRoutes:   make(map[string]MsgHandler),
 		httpRoutes:  make(map[string]HTTPHandler),
+		maxMsgSize:  msgjson.DefaultMaxMessageSize,
 	}
 	for _, route := range []string{msgjson.ConfigRoute, msgjson.SpotsRoute, msgjson.CandlesRoute, msgjson.OrderBookRoute} {
 		s.RegisterHTTP(route, func(any) (any, error) { return nil, nil })
@@ -672,6 +674,234 @@ func TestClientResponses(t *testing.T) {
 	client.reqMtx.Unlock()
 }
 
+func TestAnnouncements(t *testing.T) {
+	server := newServer()
+	stubAddr := dex.IPKey{}
+	copy(stubAddr[:], []byte("testaddr"))
+
+	var wg sync.WaitGroup
+	defer func() {
+		server.disconnectClients()
+		wg.Wait()
+	}()
+	// connect connects a client that negotiates announcements.
+	connect := func() *wsConnStub {
+		t.Helper()
+		conn := newWsStub()
+		conn.addChan()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			server.websocketHandler(testCtx, conn, stubAddr)
+		}()
+		req, _ := json.Marshal(&msgjson.FeaturesRequest{Features: []string{msgjson.FeatureAnnouncements}})
+		sendToConn(t, conn, msgjson.FeaturesRoute, string(req))
+		select {
+		case b := <-conn.recv:
+			if resp := decodeResponse(t, b); resp.Error != nil {
+				t.Fatalf("features error: %v", resp.Error)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no features response")
+		}
+		return conn
+	}
+	readAnnouncement := func(tag string, conn *wsConnStub) *msgjson.Announcement {
+		t.Helper()
+		var b []byte
+		select {
+		case b = <-conn.recv:
+		case <-time.After(time.Second):
+			t.Fatalf("%s: no announcement received", tag)
+		}
+		msg, err := msgjson.DecodeMessage(b)
+		if err != nil {
+			t.Fatalf("%s: error decoding message: %v", tag, err)
+		}
+		if msg.Type != msgjson.Notification || msg.Route != msgjson.AnnouncementRoute {
+			t.Fatalf("%s: wrong message type %d and route %q", tag, msg.Type, msg.Route)
+		}
+		var ann msgjson.Announcement
+		if err := msg.Unmarshal(&ann); err != nil {
+			t.Fatalf("%s: error decoding announcement: %v", tag, err)
+		}
+		return &ann
+	}
+
+	conn := connect()
+	if !giveItASecond(func() bool { return server.clientCount() == 1 }) {
+		t.Fatalf("client not connected")
+	}
+
+	expiry := uint64(time.Now().Add(time.Hour).UnixMilli())
+	badAnns := map[string]*msgjson.Announcement{
+		"bad severity": {Severity: "dire", Message: "msg", Expiry: expiry},
+		"no message":   {Severity: msgjson.AnnouncementInfo, Expiry: expiry},
+		"expired":      {Severity: msgjson.AnnouncementInfo, Message: "msg", Expiry: expiry - uint64(2*time.Hour.Milliseconds())},
+	}
+	for tag, ann := range badAnns {
+		if err := server.Announce(ann); err == nil {
+			t.Fatalf("%s: no error", tag)
+		}
+	}
+
+	// A broadcast reaches the connected client.
+	ann := &msgjson.Announcement{
+		Severity: msgjson.AnnouncementWarning,
+		Message:  "maintenance at noon",
+		Link:     "https://example.com/maintenance",
+		Expiry:   expiry,
+	}
+	go func() {
+		if err := server.Announce(ann); err != nil {
+			t.Errorf("Announce error: %v", err)
+		}
+	}()
+	recv := readAnnouncement("broadcast", conn)
+	if recv.ID == 0 || recv.Message != ann.Message || recv.Severity != ann.Severity ||
+		recv.Link != ann.Link || recv.Expiry != expiry {
+		t.Fatalf("wrong announcement received: %+v", recv)
+	}
+
+	// A new connection gets the active announcement when it negotiates
+	// announcements.
+	recv2 := readAnnouncement("new connection", connect())
+	if *recv2 != *recv {
+		t.Fatalf("wrong announcement for new connection: %+v != %+v", recv2, recv)
+	}
+
+	// An expired announcement is no longer sent.
+	server.annMtx.Lock()
+	server.announcements[0].Expiry = uint64(time.Now().UnixMilli()) - 1
+	server.annMtx.Unlock()
+	conn3 := connect()
+	select {
+	case <-conn3.recv:
+		t.Fatalf("expired announcement sent")
+	case <-time.After(50 * time.Millisecond):
+	}
+	server.annMtx.Lock()
+	defer server.annMtx.Unlock()
+	if len(server.announcements) != 0 {
+		t.Fatalf("expired announcement not pruned")
+	}
+}
+
+func TestFeatureNegotiation(t *testing.T) {
+	server := newServer()
+	stubAddr := dex.IPKey{}
+	copy(stubAddr[:], []byte("testaddr"))
+
+	var wg sync.WaitGroup
+	defer func() {
+		server.disconnectClients()
+		wg.Wait()
+	}()
+
+	conn := newWsStub()
+	conn.addChan()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		server.websocketHandler(testCtx, conn, stubAddr)
+	}()
+	if !giveItASecond(func() bool { return server.clientCount() == 1 }) {
+		t.Fatalf("client not connected")
+	}
+	server.clientMtx.RLock()
+	var link *wsLink
+	for _, cl := range server.clients {
+		link = cl
+	}
+	server.clientMtx.RUnlock()
+
+	// No feature may be used until the client negotiates it.
+	for feature := range serverFeatures {
+		if link.Supports(feature) {
+			t.Fatalf("feature %q supported before negotiation", feature)
+		}
+	}
+	if link.Supports("unknown") {
+		t.Fatalf("unknown feature supported before negotiation")
+	}
+
+	negotiate := func(features ...string) []string {
+		t.Helper()
+		req, _ := json.Marshal(&msgjson.FeaturesRequest{Features: features})
+		sendToConn(t, conn, msgjson.FeaturesRoute, string(req))
+		var b []byte
+		select {
+		case b = <-conn.recv:
+		case <-time.After(time.Second):
+			t.Fatalf("no features response")
+		}
+		resp := decodeResponse(t, b)
+		if resp.Error != nil {
+			t.Fatalf("features error: %v", resp.Error)
+		}
+		var res msgjson.FeaturesResult
+		if err := json.Unmarshal(resp.Result, &res); err != nil {
+			t.Fatalf("error decoding features result: %v", err)
+		}
+		return res.Features
+	}
+
+	// The agreed features are the intersection, and unknown features are
+	// ignored.
+	agreed := negotiate("unknown", msgjson.FeatureBookPages, msgjson.FeatureBookPages)
+	if len(agreed) != 1 || agreed[0] != msgjson.FeatureBookPages {
+		t.Fatalf("wrong agreed features %v", agreed)
+	}
+	if !link.Supports(msgjson.FeatureBookPages) {
+		t.Fatalf("negotiated feature not supported")
+	}
+	if link.Supports(msgjson.FeatureAnnouncements) || link.Supports("unknown") {
+		t.Fatalf("feature supported without being negotiated")
+	}
+
+	// Announcements are not sent to a client that did not negotiate them.
+	expiry := uint64(time.Now().Add(time.Hour).UnixMilli())
+	ann := &msgjson.Announcement{Severity: msgjson.AnnouncementInfo, Message: "msg", Expiry: expiry}
+	if err := server.Announce(ann); err != nil {
+		t.Fatalf("Announce error: %v", err)
+	}
+	select {
+	case <-conn.recv:
+		t.Fatalf("announcement sent to a client that did not negotiate announcements")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Negotiating again replaces the agreed features.
+	agreed = negotiate(msgjson.FeatureBookPages, msgjson.FeatureAnnouncements)
+	if len(agreed) != 2 || agreed[0] != msgjson.FeatureAnnouncements || agreed[1] != msgjson.FeatureBookPages {
+		t.Fatalf("wrong agreed features %v", agreed)
+	}
+	// The active announcement is sent once announcements are negotiated.
+	select {
+	case <-conn.recv:
+	case <-time.After(time.Second):
+		t.Fatalf("active announcement not sent after negotiating announcements")
+	}
+	go func() {
+		if err := server.Announce(ann); err != nil {
+			t.Errorf("Announce error: %v", err)
+		}
+	}()
+	select {
+	case <-conn.recv:
+	case <-time.After(time.Second):
+		t.Fatalf("announcement not sent after negotiating announcements")
+	}
+
+	// No features at all.
+	if agreed = negotiate(); len(agreed) != 0 {
+		t.Fatalf("features agreed for an empty request: %v", agreed)
+	}
+	if link.Supports(msgjson.FeatureBookPages) {
+		t.Fatalf("feature supported after negotiating none")
+	}
+}
+
 func TestOnline(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -836,9 +1066,118 @@ func TestOnline(t *testing.T) {
 	}) {
 		t.Fatalf("server claiming %d clients. Expected 1", clientCount)
 	}
+
+	// While draining, new connections are refused, but existing clients stay
+	// connected.
+	server.Drain()
+	_, err = newTestBisonWallet(address, rootCAs)
+	if err == nil {
+		t.Fatalf("no websocket connection error while draining")
+	}
+	if clientCount = server.clientCount(); clientCount != 1 {
+		t.Fatalf("server claiming %d clients while draining. Expected 1", clientCount)
+	}
 	conn.Close()
 }
 
+func TestUnixSocket(t *testing.T) {
+	sockPath := filepath.Join(t.TempDir(), "dex.sock")
+	server, err := NewServer(&RPCConfig{
+		ListenAddrs: []string{"127.0.0.1:0"},
+		NoTLS:       true,
+		UnixSocket:  sockPath,
+	})
+	if err != nil {
+		t.Fatalf("server constructor error: %v", err)
+	}
+	fi, err := os.Stat(sockPath)
+	if err != nil {
+		t.Fatalf("socket file not created: %v", err)
+	}
+	if fi.Mode()&os.ModeSocket == 0 || fi.Mode().Perm() != unixSocketMode {
+		t.Fatalf("wrong socket file mode %v", fi.Mode())
+	}
+
+	// A socket with a listening server is not replaced.
+	if _, err = listenUnix(sockPath); err == nil {
+		t.Fatalf("no error listening on a socket in use")
+	}
+
+	type okresult struct {
+		OK bool `json:"ok"`
+	}
+	server.Route("ok", func(c Link, msg *msgjson.Message) *msgjson.Error {
+		resp, _ := msgjson.NewResponse(msg.ID, &okresult{OK: true}, nil)
+		if err := c.Send(resp); err != nil {
+			return msgjson.NewError(500, "%v", err)
+		}
+		return nil
+	})
+
+	ssw := dex.NewStartStopWaiter(server)
+	ssw.Start(testCtx)
+
+	// Connect as a reverse proxy would, forwarding the client's address.
+	dialer := &websocket.Dialer{
+		NetDial: func(string, string) (net.Conn, error) {
+			return net.Dial("unix", sockPath)
+		},
+		HandshakeTimeout: 10 * time.Second,
+	}
+	const clientIP = "10.0.0.1"
+	conn, _, err := dialer.Dial("ws://dex.example/ws", http.Header{"X-Real-IP": []string{clientIP}})
+	if err != nil {
+		t.Fatalf("error connecting over unix socket: %v", err)
+	}
+	defer conn.Close()
+
+	b, _ := json.Marshal(makeReq("ok", "{}"))
+	if err = conn.WriteMessage(websocket.TextMessage, b); err != nil {
+		t.Fatalf("send error: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, b, err = conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	msg, _ := msgjson.DecodeMessage(b)
+	ok := new(okresult)
+	if err = msg.UnmarshalResult(ok); err != nil || !ok.OK {
+		t.Fatalf("bad 'ok' response: %s", string(b))
+	}
+	if n := server.ipConnCount(dex.NewIPKey(clientIP)); n != 1 {
+		t.Fatalf("expected 1 connection from the forwarded address, got %d", n)
+	}
+
+	// The socket file is removed on shutdown.
+	ssw.Stop()
+	ssw.WaitForShutdown()
+	if _, err = os.Stat(sockPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("socket file not removed on shutdown: %v", err)
+	}
+
+	// A file that is not a socket is not replaced.
+	if err = os.WriteFile(sockPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = listenUnix(sockPath); err == nil {
+		t.Fatalf("no error listening on a regular file")
+	}
+
+	// A stale socket file is replaced.
+	os.Remove(sockPath)
+	l, err := net.Listen("unix", sockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+	if l, err = listenUnix(sockPath); err != nil {
+		t.Fatalf("error replacing stale socket: %v", err)
+	}
+	l.Close()
+}
+
 func TestParseListeners(t *testing.T) {
 	ipv6wPort := "[fdc5:f621:d3b4:923f::]:80"
 	ipv6wZonePort := "[a:b:c:d::%123]:45"
@@ -1039,3 +1378,20 @@ func TestWSRateLimiter(t *testing.T) {
 		}
 	}()
 }
+
+func TestMaxMessageSize(t *testing.T) {
+	server := newServer()
+	server.maxMsgSize = minMaxMessageSize
+	// The max message size only applies to book snapshots, so a large
+	// message is not refused. The link is not connected, so sends fail with
+	// ErrPeerDisconnected.
+	link := server.newWSLink("testaddr", newWsStub(), nil, nil)
+	msg := makeNtfn("big", `"`+strings.Repeat("a", minMaxMessageSize)+`"`)
+	if err := link.Send(msg); !errors.Is(err, ws.ErrPeerDisconnected) {
+		t.Fatalf("wanted Send error %v, got %v", ws.ErrPeerDisconnected, err)
+	}
+
+	if _, err := NewServer(&RPCConfig{MaxMessageSize: minMaxMessageSize - 1}); err == nil {
+		t.Fatalf("no error for max message size below the minimum")
+	}
+}
@@ -0,0 +1,53 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package comms
+
+import (
+	"sort"
+
+	"decred.org/dcrdex/dex/msgjson"
+)
+
+// serverFeatures are the optional protocol features that the server supports.
+var serverFeatures = map[string]bool{
+	msgjson.FeatureAnnouncements: true,
+	msgjson.FeatureBookPages:     true,
+}
+
+// Supports checks whether the optional protocol feature was negotiated on the
+// connection. No optional feature may be used until the client negotiates it.
+func (c *wsLink) Supports(feature string) bool {
+	features, _ := c.features.Load().(map[string]bool)
+	return features[feature]
+}
+
+// handleFeatures handles a 'features' request. The agreed features are the
+// features requested by the client that the server supports. Unknown features
+// are ignored. A client may negotiate again, replacing the agreed features.
+func (c *wsLink) handleFeatures(msg *msgjson.Message) *msgjson.Error {
+	req := new(msgjson.FeaturesRequest)
+	if err := msg.Unmarshal(req); err != nil {
+		return msgjson.NewError(msgjson.RPCParseError, "error parsing features request")
+	}
+	features := make(map[string]bool, len(req.Features))
+	agreed := make([]string, 0, len(req.Features))
+	for _, feature := range req.Features {
+		if serverFeatures[feature] && !features[feature] {
+			features[feature] = true
+			agreed = append(agreed, feature)
+		}
+	}
+	sort.Strings(agreed)
+	c.features.Store(features)
+	log.Debugf("Client %d at %s negotiated features %v", c.id, c.Addr(), agreed)
+
+	resp, err := msgjson.NewResponse(msg.ID, &msgjson.FeaturesResult{Features: agreed}, nil)
+	if err != nil {
+		return msgjson.NewError(msgjson.RPCInternalError, "error encoding features response")
+	}
+	if err := c.Send(resp); err != nil {
+		log.Debugf("Error sending features response to %s: %v", c.Addr(), err)
+	}
+	return nil
+}
@@ -55,6 +55,9 @@ type Link interface {
 	SetCustomID(string)
 	// CustomID
 	CustomID() string
+	// Supports checks whether the optional protocol feature, e.g.
+	// msgjson.FeatureBookPages, may be used on the connection.
+	Supports(feature string) bool
 }
 
 // When the DEX sends a request to the client, a responseHandler is created
@@ -83,6 +86,9 @@ type wsLink struct {
 	dataMeter func() (int, error)
 	// wsLimiter is a route-based rate limiter. This applies to rpcRoutes.
 	wsLimiter *routeLimiter
+	// features is the map[string]bool of optional protocol features agreed
+	// with the client. It is not set until the client negotiates features.
+	features atomic.Value
 }
 
 // newWSLink is a constructor for a new wsLink.
@@ -142,6 +148,22 @@ func (s *Server) handleMessage(c *wsLink, msg *msgjson.Message) *msgjson.Error {
 		if msg.ID == 0 {
 			return msgjson.NewError(msgjson.RPCParseError, "request id cannot be zero")
 		}
+		// Feature negotiation is handled by comms, since the agreed features
+		// are a property of the connection.
+		if msg.Route == msgjson.FeaturesRoute {
+			if !c.wsLimiter.allow(msg.Route) {
+				return msgjson.NewError(msgjson.TooManyRequestsError, "too many requests to %s", msg.Route)
+			}
+			hadAnnouncements := c.Supports(msgjson.FeatureAnnouncements)
+			if rpcErr := c.handleFeatures(msg); rpcErr != nil {
+				return rpcErr
+			}
+			if !hadAnnouncements && c.Supports(msgjson.FeatureAnnouncements) {
+				s.sendAnnouncements(c)
+			}
+			return nil
+		}
+
 		// Look for a registered WebSocket route handler. This excludes the data
 		// API routes, which are part of the httpHandler map.
 		handler := s.rpcRoutes[msg.Route]
@@ -175,6 +197,8 @@ func (s *Server) handleMessage(c *wsLink, msg *msgjson.Message) *msgjson.Error {
 			thing = new(msgjson.CandlesRequest)
 		case msgjson.OrderBookRoute:
 			thing = new(msgjson.OrderBookSubscription)
+		case msgjson.EpochAuditRoute:
+			thing = new(msgjson.EpochAuditRequest)
 		}
 		if thing != nil {
 			err := msg.Unmarshal(thing)
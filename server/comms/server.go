@@ -44,6 +44,18 @@ const (
 	// banishTime is the default duration of a client quarantine.
 	banishTime = time.Hour
 
+	// maxAnnouncements is the most active operator announcements that are
+	// stored to be sent to newly connected clients. When exceeded, the oldest
+	// announcement is dropped.
+	maxAnnouncements = 10
+
+	// minMaxMessageSize is the smallest allowed RPCConfig.MaxMessageSize.
+	minMaxMessageSize = 65536
+
+	// unixSocketMode is the file mode of a Unix domain socket listener. Only
+	// the owner and group, e.g. a reverse proxy, may connect.
+	unixSocketMode = 0660
+
 	// Per-ip rate limits for market data API routes.
 	ipMaxRatePerSec = 1
 	ipMaxBurstSize  = 5
@@ -173,6 +185,17 @@ type RPCConfig struct {
 	AltDNSNames []string
 	// DisableDataAPI will disable all traffic to the HTTP data API routes.
 	DisableDataAPI bool
+	// UnixSocket is the path of a Unix domain socket on which the server will
+	// listen in addition to ListenAddrs. TLS is not used on the socket, which
+	// is intended for a reverse proxy that terminates TLS. The proxy must set
+	// the X-Real-IP or X-Forwarded-For header, since the client IP address is
+	// otherwise unknown. The socket file is removed on shutdown.
+	UnixSocket string
+	// MaxMessageSize is the size above which an order book snapshot is split
+	// into pages for clients that support book pages. It does not limit any
+	// other message. The default is msgjson.DefaultMaxMessageSize, and the
+	// minimum is minMaxMessageSize.
+	MaxMessageSize int64
 }
 
 // allower is satisfied by rate.Limiter.
@@ -230,6 +253,20 @@ func newRouteLimiter() *routeLimiter {
 			msgjson.ConfigRoute:  infoLimiter,
 			msgjson.SpotsRoute:   infoLimiter,
 			msgjson.CandlesRoute: infoLimiter,
+			// Fee rate history
+			msgjson.FeeRateHistoryRoute: infoLimiter,
+			// Epoch commit-reveal records
+			msgjson.EpochAuditRoute: infoLimiter,
+			// Public trade tape
+			msgjson.RecentTradesRoute: infoLimiter,
+			// Signed config exports
+			msgjson.SignedConfigRoute: infoLimiter,
+			// Feature negotiation
+			msgjson.FeaturesRoute: infoLimiter,
+			// Transaction acceptance checks
+			msgjson.CheckTxRoute: infoLimiter,
+			// Redemption pre-validation
+			msgjson.ValidateRedemptionRoute: infoLimiter,
 		},
 	}
 }
@@ -267,11 +304,22 @@ type Server struct {
 	quarantine map[dex.IPKey]time.Time
 
 	dataEnabled uint32 // atomic
+	draining    uint32 // atomic, see Drain
 
 	// rpcRoutes maps message routes to the handlers.
 	rpcRoutes map[string]MsgHandler
 	// httpRoutes maps HTTP routes to the handlers.
 	httpRoutes map[string]HTTPHandler
+
+	// announcements are the active operator announcements, which are sent to
+	// clients as they connect until the announcements expire.
+	annMtx        sync.Mutex
+	announcements []*msgjson.Announcement
+	lastAnnID     uint64
+
+	// maxMsgSize is the order book snapshot page size limit. See
+	// RPCConfig.MaxMessageSize.
+	maxMsgSize int64
 }
 
 // NewServer constructs a Server that should be started with Run. The server is
@@ -280,6 +328,12 @@ type Server struct {
 // IP-based quarantine to short-circuit to an error response for misbehaving
 // clients, if necessary.
 func NewServer(cfg *RPCConfig) (*Server, error) {
+	maxMsgSize := cfg.MaxMessageSize
+	if maxMsgSize == 0 {
+		maxMsgSize = msgjson.DefaultMaxMessageSize
+	} else if maxMsgSize < minMaxMessageSize {
+		return nil, fmt.Errorf("max message size %d is less than the minimum of %d", maxMsgSize, minMaxMessageSize)
+	}
 
 	var tlsConfig *tls.Config
 	if !cfg.NoTLS {
@@ -360,6 +414,13 @@ func NewServer(cfg *RPCConfig) (*Server, error) {
 			return nil, err
 		}
 	}
+	if cfg.UnixSocket != "" {
+		listener, err := listenUnix(cfg.UnixSocket)
+		if err != nil {
+			return nil, err
+		}
+		listeners = append(listeners, listener)
+	}
 	if len(listeners) == 0 {
 		return nil, fmt.Errorf("RPCS: No valid listen address")
 	}
@@ -383,11 +444,40 @@ func NewServer(cfg *RPCConfig) (*Server, error) {
 		dataEnabled: dataEnabled,
 		rpcRoutes:   make(map[string]MsgHandler),
 		httpRoutes:  make(map[string]HTTPHandler),
+		maxMsgSize:  maxMsgSize,
 	}, nil
 }
 
 type onionListener struct{ net.Listener }
 
+// listenUnix listens on a Unix domain socket at path, with the socket file
+// permissions set to unixSocketMode. A socket file left by an unclean shutdown
+// is replaced, but not one with a server listening. The socket file is removed
+// when the listener is closed.
+func listenUnix(path string) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("cannot listen on %s: file exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("cannot listen on %s: socket is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("cannot remove stale socket %s: %w", path, err)
+		}
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, unixSocketMode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("cannot set permissions of socket %s: %w", path, err)
+	}
+	return listener, nil
+}
+
 // Run starts the server. Run should be called only after all routes are
 // registered.
 func (s *Server) Run(ctx context.Context) {
@@ -401,6 +491,10 @@ func (s *Server) Run(ctx context.Context) {
 			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 			return
 		}
+		if atomic.LoadUint32(&s.draining) == 1 {
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
 		if s.clientCount() >= rpcMaxClients {
 			http.Error(w, "server at maximum capacity", http.StatusServiceUnavailable)
 			return
@@ -666,6 +760,12 @@ func (s *Server) websocketHandler(ctx context.Context, conn ws.Connection, ip de
 // Broadcast sends a message to all connected clients. The message should be a
 // notification. See msgjson.NewNotification.
 func (s *Server) Broadcast(msg *msgjson.Message) {
+	s.broadcast(msg, "")
+}
+
+// broadcast sends a message to the connected clients that support the optional
+// protocol feature, or to all connected clients if feature is empty.
+func (s *Server) broadcast(msg *msgjson.Message, feature string) {
 	// Marshal and send the bytes to avoid multiple marshals when sending.
 	b, err := json.Marshal(msg)
 	if err != nil {
@@ -682,6 +782,9 @@ func (s *Server) Broadcast(msg *msgjson.Message) {
 	}
 
 	for id, cl := range s.clients {
+		if feature != "" && !cl.Supports(feature) {
+			continue
+		}
 		if err := cl.SendRaw(b); err != nil {
 			log.Debugf("Send to client %d at %s failed: %v", id, cl.Addr(), err)
 			cl.Disconnect() // triggers return of websocketHandler, and removeClient
@@ -689,6 +792,85 @@ func (s *Server) Broadcast(msg *msgjson.Message) {
 	}
 }
 
+// Announce broadcasts an operator announcement to all connected clients, and
+// stores it to be sent to clients that connect before it expires. The
+// announcement's ID is assigned by Announce.
+func (s *Server) Announce(ann *msgjson.Announcement) error {
+	switch ann.Severity {
+	case msgjson.AnnouncementInfo, msgjson.AnnouncementWarning, msgjson.AnnouncementCritical:
+	default:
+		return fmt.Errorf("unknown announcement severity %q", ann.Severity)
+	}
+	if ann.Message == "" {
+		return errors.New("no announcement message")
+	}
+	now := uint64(time.Now().UnixMilli())
+	if ann.Expiry <= now {
+		return fmt.Errorf("announcement expiry %v is not in the future", time.UnixMilli(int64(ann.Expiry)))
+	}
+
+	s.annMtx.Lock()
+	// The ID is a time stamp so that it is not reused after a restart.
+	ann.ID = now
+	if ann.ID <= s.lastAnnID {
+		ann.ID = s.lastAnnID + 1
+	}
+	s.lastAnnID = ann.ID
+	anns := append(s.activeAnnouncements(now), ann)
+	if len(anns) > maxAnnouncements {
+		anns = anns[len(anns)-maxAnnouncements:]
+	}
+	s.announcements = anns
+	s.annMtx.Unlock()
+
+	msg, err := msgjson.NewNotification(msgjson.AnnouncementRoute, ann)
+	if err != nil {
+		return fmt.Errorf("unable to create announcement notification: %w", err)
+	}
+	s.broadcast(msg, msgjson.FeatureAnnouncements)
+	return nil
+}
+
+// activeAnnouncements prunes expired announcements and returns the rest. The
+// annMtx must be held.
+func (s *Server) activeAnnouncements(now uint64) []*msgjson.Announcement {
+	active := s.announcements[:0]
+	for _, ann := range s.announcements {
+		if ann.Expiry > now {
+			active = append(active, ann)
+		}
+	}
+	for i := len(active); i < len(s.announcements); i++ {
+		s.announcements[i] = nil
+	}
+	s.announcements = active
+	return active
+}
+
+// sendAnnouncements sends the active announcements to a client that has just
+// negotiated the announcements feature.
+func (s *Server) sendAnnouncements(client *wsLink) {
+	if !client.Supports(msgjson.FeatureAnnouncements) {
+		return
+	}
+	s.annMtx.Lock()
+	anns := s.activeAnnouncements(uint64(time.Now().UnixMilli()))
+	anns = append([]*msgjson.Announcement(nil), anns...)
+	s.annMtx.Unlock()
+
+	for _, ann := range anns {
+		msg, err := msgjson.NewNotification(msgjson.AnnouncementRoute, ann)
+		if err != nil {
+			log.Errorf("unable to create announcement notification: %v", err)
+			return
+		}
+		if err := client.Send(msg); err != nil {
+			log.Debugf("Send announcement to client %d at %s failed: %v", client.id, client.Addr(), err)
+			return
+		}
+	}
+}
+
 // EnableDataAPI enables or disables the HTTP data API endpoints.
 func (s *Server) EnableDataAPI(yes bool) {
 	if yes {
@@ -698,6 +880,13 @@ func (s *Server) EnableDataAPI(yes bool) {
 	}
 }
 
+// Drain stops the server from accepting new websocket connections in
+// preparation for shutdown. Existing clients remain connected so that they
+// may complete their active swaps.
+func (s *Server) Drain() {
+	atomic.StoreUint32(&s.draining, 1)
+}
+
 // disconnectClients calls disconnect on each wsLink, but does not remove it
 // from the Server's client map.
 func (s *Server) disconnectClients() {
@@ -730,6 +919,17 @@ func (s *Server) removeClient(id uint64) {
 	s.clientMtx.Unlock()
 }
 
+// MaxMessageSize is the size above which order book snapshots are split into
+// pages for clients that support book pages.
+func (s *Server) MaxMessageSize() int64 {
+	return s.maxMsgSize
+}
+
+// NumClients is the number of connected websocket clients.
+func (s *Server) NumClients() uint64 {
+	return s.clientCount()
+}
+
 // Get the number of active clients.
 func (s *Server) clientCount() uint64 {
 	s.clientMtx.RLock()
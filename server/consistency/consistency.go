@@ -0,0 +1,465 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+// Package consistency periodically checks that the swap state recorded in the
+// DB is consistent with the asset blockchains.
+package consistency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/asset"
+	"decred.org/dcrdex/server/db"
+)
+
+const (
+	// DefaultInterval is the default time between consistency checks.
+	DefaultInterval = 30 * time.Minute
+	// DefaultSampleRate is the default fraction of candidate matches that are
+	// checked in each consistency check.
+	DefaultSampleRate = 0.1
+
+	// recentMatches is the number of each market's most recent matches,
+	// including completed and failed matches, that are candidates for
+	// checking in addition to the active matches.
+	recentMatches = 500
+	// defaultLookupDelay is the minimum time between asset backend requests,
+	// limiting the load on the nodes.
+	defaultLookupDelay = 250 * time.Millisecond
+	// maxDiscrepancies is the number of the most recent discrepancies kept for
+	// reporting.
+	maxDiscrepancies = 100
+	// maxPendingCoins is the maximum number of coins with fewer than their
+	// asset's reorg depth confirmations that are tracked for re-checking.
+	maxPendingCoins = 1000
+)
+
+// DBSource is the DB backend from which recorded matches are sampled.
+type DBSource interface {
+	MarketMatchesStreaming(base, quote uint32, includeInactive bool, N int64, f func(*db.MatchDataWithCoins) error) (int, error)
+}
+
+// TxSource is an asset backend that can look up transactions by coin ID. Only
+// asset.CoinNotFoundError is considered an inconsistency. Other errors are
+// logged and the coin is skipped.
+type TxSource interface {
+	TxData(coinID []byte) ([]byte, error)
+}
+
+// Config is the configuration for a Checker.
+type Config struct {
+	DB       DBSource
+	Markets  []*dex.MarketInfo
+	Backends map[uint32]TxSource
+	// Interval is the time between checks. If zero, DefaultInterval is used.
+	Interval time.Duration
+	// SampleRate is the fraction, in (0, 1], of the active and recent
+	// matches that are checked each interval. If zero, DefaultSampleRate is
+	// used.
+	SampleRate float64
+	// ReorgDepths are the numbers of confirmations beyond which the coins of
+	// each asset are considered irreversible. A found coin with fewer
+	// confirmations is re-checked every interval until it reaches the depth,
+	// so that a coin removed by a reorg is detected even if its match is not
+	// sampled again. Only used for backends that implement asset.TxConfirmer.
+	ReorgDepths map[uint32]uint32
+	Logger      dex.Logger
+}
+
+// DiscrepancyKind is the type of inconsistency found.
+type DiscrepancyKind string
+
+const (
+	// MissingCoinID indicates that a match's status requires a coin that is
+	// not recorded.
+	MissingCoinID DiscrepancyKind = "missing coin ID"
+	// CoinNotFound indicates that a recorded coin was not found by the asset
+	// backend.
+	CoinNotFound DiscrepancyKind = "coin not found"
+	// CoinReorged indicates that a coin that was found with fewer than its
+	// asset's reorg depth confirmations was later not found.
+	CoinReorged DiscrepancyKind = "coin reorged"
+)
+
+// Discrepancy is an inconsistency between a match's recorded state and the
+// blockchain.
+type Discrepancy struct {
+	Stamp   time.Time         `json:"stamp"`
+	Market  string            `json:"market"`
+	MatchID order.MatchID     `json:"matchID"`
+	Status  order.MatchStatus `json:"status"`
+	Active  bool              `json:"active"`
+	Kind    DiscrepancyKind   `json:"kind"`
+	Details string            `json:"details"`
+}
+
+// Report is a summary of the consistency checks run so far.
+type Report struct {
+	Checks         uint64    `json:"checks"`
+	LastCheck      time.Time `json:"lastCheck"`
+	MatchesChecked uint64    `json:"matchesChecked"`
+	CoinsChecked   uint64    `json:"coinsChecked"`
+	LookupErrors   uint64    `json:"lookupErrors"`
+	// PendingCoins is the number of coins being re-checked until they reach
+	// their asset's reorg depth.
+	PendingCoins int `json:"pendingCoins"`
+	// Discrepancies is the total number of discrepancies found.
+	Discrepancies uint64 `json:"discrepancies"`
+	// Recent are the most recently found discrepancies, oldest first.
+	Recent []*Discrepancy `json:"recent"`
+}
+
+// Checker periodically samples the active and recently-completed matches of
+// each market and verifies that the coins required by their recorded status
+// are recorded and exist on their blockchains. Coins that have not reached
+// their asset's reorg depth are re-checked every interval until they do, so
+// that coins removed by a reorg are detected. Discrepancies are logged and
+// reported, but never corrected, since the Swapper is the authority on the
+// state of active swaps. The Checker never writes to the DB, and spaces its
+// asset backend requests to limit node load.
+type Checker struct {
+	db          DBSource
+	markets     []*dex.MarketInfo
+	backends    map[uint32]TxSource
+	interval    time.Duration
+	sampleRate  float64
+	reorgDepths map[uint32]uint32
+	lookupDelay time.Duration
+	log         dex.Logger
+
+	// pending are the found coins that have not reached their asset's reorg
+	// depth. Only accessed by check.
+	pending map[pendingKey]*pendingCoin
+
+	reportMtx sync.Mutex
+	report    Report
+}
+
+// NewChecker is the constructor for a Checker.
+func NewChecker(cfg *Config) (*Checker, error) {
+	interval, sampleRate := cfg.Interval, cfg.SampleRate
+	if interval == 0 {
+		interval = DefaultInterval
+	}
+	if sampleRate == 0 {
+		sampleRate = DefaultSampleRate
+	}
+	if interval < time.Minute {
+		return nil, fmt.Errorf("consistency check interval %s is less than one minute", interval)
+	}
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, fmt.Errorf("consistency check sample rate %f is not in the range (0, 1]", sampleRate)
+	}
+	return &Checker{
+		db:          cfg.DB,
+		markets:     cfg.Markets,
+		backends:    cfg.Backends,
+		interval:    interval,
+		sampleRate:  sampleRate,
+		reorgDepths: cfg.ReorgDepths,
+		lookupDelay: defaultLookupDelay,
+		log:         cfg.Logger,
+		pending:     make(map[pendingKey]*pendingCoin),
+	}, nil
+}
+
+// Run checks consistency each interval until the context is canceled.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.check(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Report returns a summary of the consistency checks run so far.
+func (c *Checker) Report() *Report {
+	c.reportMtx.Lock()
+	defer c.reportMtx.Unlock()
+	r := c.report
+	r.Recent = make([]*Discrepancy, len(c.report.Recent))
+	copy(r.Recent, c.report.Recent)
+	return &r
+}
+
+// check samples and checks the matches of every market.
+func (c *Checker) check(ctx context.Context) {
+	var matches uint64
+	pc := c.recheckPending(ctx)
+	coins, lookupErrs := pc.coins, pc.lookupErrs
+	found := pc.discrepancies
+	for _, mkt := range c.markets {
+		if ctx.Err() != nil {
+			return
+		}
+		sample, err := c.sampleMatches(mkt)
+		if err != nil {
+			c.log.Errorf("Error retrieving %s matches for consistency check: %v", mkt.Name, err)
+			continue
+		}
+		for _, m := range sample {
+			if ctx.Err() != nil {
+				return
+			}
+			mc := c.checkMatch(ctx, mkt, m)
+			matches++
+			coins += mc.coins
+			lookupErrs += mc.lookupErrs
+			found = append(found, mc.discrepancies...)
+			for _, p := range mc.pending {
+				if _, tracked := c.pending[p.key()]; !tracked && len(c.pending) >= maxPendingCoins {
+					continue
+				}
+				c.pending[p.key()] = p
+			}
+		}
+	}
+
+	for _, d := range found {
+		c.log.Warnf("Swap state inconsistency in %s match %v (status %v, active = %t): %s: %s",
+			d.Market, d.MatchID, d.Status, d.Active, d.Kind, d.Details)
+	}
+	c.log.Debugf("Consistency check of %d matches and %d coins complete. %d discrepancies, %d lookup errors",
+		matches, coins, len(found), lookupErrs)
+
+	c.reportMtx.Lock()
+	defer c.reportMtx.Unlock()
+	r := &c.report
+	r.Checks++
+	r.LastCheck = time.Now()
+	r.MatchesChecked += matches
+	r.CoinsChecked += coins
+	r.LookupErrors += lookupErrs
+	r.PendingCoins = len(c.pending)
+	r.Discrepancies += uint64(len(found))
+	r.Recent = append(r.Recent, found...)
+	if len(r.Recent) > maxDiscrepancies {
+		r.Recent = r.Recent[len(r.Recent)-maxDiscrepancies:]
+	}
+}
+
+// sampleMatches retrieves the market's active and most recent matches, and
+// returns a random sample of them.
+func (c *Checker) sampleMatches(mkt *dex.MarketInfo) ([]*db.MatchDataWithCoins, error) {
+	var candidates []*db.MatchDataWithCoins
+	seen := make(map[order.MatchID]bool)
+	add := func(m *db.MatchDataWithCoins) error {
+		if !seen[m.ID] {
+			seen[m.ID] = true
+			candidates = append(candidates, m)
+		}
+		return nil
+	}
+	if _, err := c.db.MarketMatchesStreaming(mkt.Base, mkt.Quote, false, 0, add); err != nil {
+		return nil, fmt.Errorf("error retrieving active matches: %w", err)
+	}
+	if _, err := c.db.MarketMatchesStreaming(mkt.Base, mkt.Quote, true, recentMatches, add); err != nil {
+		return nil, fmt.Errorf("error retrieving recent matches: %w", err)
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	n := int(float64(len(candidates))*c.sampleRate + 0.5)
+	if n == 0 {
+		n = 1
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	return candidates[:n], nil
+}
+
+type matchCheck struct {
+	coins         uint64
+	lookupErrs    uint64
+	discrepancies []*Discrepancy
+	pending       []*pendingCoin
+}
+
+type pendingKey struct {
+	assetID uint32
+	coinID  string
+}
+
+// pendingCoin is a found coin that has not reached its asset's reorg depth.
+type pendingCoin struct {
+	market  string
+	matchID order.MatchID
+	status  order.MatchStatus
+	active  bool
+	name    string
+	assetID uint32
+	coinID  []byte
+	confs   int64
+}
+
+func (p *pendingCoin) key() pendingKey {
+	return pendingKey{p.assetID, string(p.coinID)}
+}
+
+// wait delays the next asset backend request by the lookupDelay. false is
+// returned if the context is canceled.
+func (c *Checker) wait(ctx context.Context) bool {
+	if c.lookupDelay <= 0 {
+		return true
+	}
+	select {
+	case <-time.After(c.lookupDelay):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// lookup checks that the coin exists. If the asset has a reorg depth and its
+// backend can report confirmations, final is whether the coin has reached the
+// reorg depth. Otherwise, a found coin is considered final.
+func (c *Checker) lookup(backend TxSource, assetID uint32, coinID []byte) (confs int64, final bool, err error) {
+	depth := c.reorgDepths[assetID]
+	if confirmer, is := backend.(asset.TxConfirmer); is && depth > 0 {
+		confs, err = confirmer.TxConfirmations(coinID)
+		return confs, confs >= int64(depth), err
+	}
+	_, err = backend.TxData(coinID)
+	return 0, true, err
+}
+
+// recheckPending looks up the coins that had not reached their asset's reorg
+// depth. Coins that have reached it are no longer tracked, and coins that are
+// no longer found were removed by a reorg.
+func (c *Checker) recheckPending(ctx context.Context) *matchCheck {
+	mc := new(matchCheck)
+	for k, p := range c.pending {
+		if !c.wait(ctx) {
+			return mc
+		}
+		mc.coins++
+		confs, final, err := c.lookup(c.backends[p.assetID], p.assetID, p.coinID)
+		if err != nil {
+			if errors.Is(err, asset.CoinNotFoundError) {
+				mc.discrepancies = append(mc.discrepancies, &Discrepancy{
+					Stamp:   time.Now(),
+					Market:  p.market,
+					MatchID: p.matchID,
+					Status:  p.status,
+					Active:  p.active,
+					Kind:    CoinReorged,
+					Details: fmt.Sprintf("%s coin %s with %d confirmations is no longer found",
+						p.name, coinIDString(p.assetID, p.coinID), p.confs),
+				})
+				delete(c.pending, k)
+				continue
+			}
+			mc.lookupErrs++
+			c.log.Debugf("Error re-checking %s match %v %s coin %s: %v", p.market, p.matchID, p.name,
+				coinIDString(p.assetID, p.coinID), err)
+			continue
+		}
+		if confs < p.confs {
+			c.log.Infof("%s match %v %s coin %s confirmations dropped from %d to %d", p.market, p.matchID,
+				p.name, coinIDString(p.assetID, p.coinID), p.confs, confs)
+		}
+		if final {
+			delete(c.pending, k)
+			continue
+		}
+		p.confs = confs
+	}
+	return mc
+}
+
+// checkMatch checks that the coins required by the match's status are
+// recorded, and that the recorded coins exist.
+func (c *Checker) checkMatch(ctx context.Context, mkt *dex.MarketInfo, m *db.MatchDataWithCoins) *matchCheck {
+	mc := new(matchCheck)
+	discrepancy := func(kind DiscrepancyKind, details string) {
+		mc.discrepancies = append(mc.discrepancies, &Discrepancy{
+			Stamp:   time.Now(),
+			Market:  mkt.Name,
+			MatchID: m.ID,
+			Status:  m.Status,
+			Active:  m.Active,
+			Kind:    kind,
+			Details: details,
+		})
+	}
+
+	// The maker's swap is on the chain of the asset the maker sells, and the
+	// maker redeems on the chain of the asset the taker sells.
+	makerSwapAsset, takerSwapAsset := mkt.Base, mkt.Quote
+	if m.TakerSell {
+		makerSwapAsset, takerSwapAsset = mkt.Quote, mkt.Base
+	}
+	coins := []struct {
+		name      string
+		coinID    []byte
+		assetID   uint32
+		reqStatus order.MatchStatus
+	}{
+		{"maker swap", m.MakerSwapCoin, makerSwapAsset, order.MakerSwapCast},
+		{"taker swap", m.TakerSwapCoin, takerSwapAsset, order.TakerSwapCast},
+		{"maker redeem", m.MakerRedeemCoin, takerSwapAsset, order.MakerRedeemed},
+		{"taker redeem", m.TakerRedeemCoin, makerSwapAsset, order.MatchComplete},
+	}
+	for _, coin := range coins {
+		if len(coin.coinID) == 0 {
+			if m.Status >= coin.reqStatus {
+				discrepancy(MissingCoinID, fmt.Sprintf("no %s coin recorded", coin.name))
+			}
+			continue
+		}
+		backend := c.backends[coin.assetID]
+		if backend == nil {
+			continue
+		}
+		if !c.wait(ctx) {
+			return mc
+		}
+		mc.coins++
+		confs, final, err := c.lookup(backend, coin.assetID, coin.coinID)
+		if err != nil {
+			if errors.Is(err, asset.CoinNotFoundError) {
+				discrepancy(CoinNotFound, fmt.Sprintf("%s coin %s not found",
+					coin.name, coinIDString(coin.assetID, coin.coinID)))
+				continue
+			}
+			mc.lookupErrs++
+			c.log.Debugf("Error looking up %s match %v %s coin %s: %v", mkt.Name, m.ID, coin.name,
+				coinIDString(coin.assetID, coin.coinID), err)
+			continue
+		}
+		if !final {
+			mc.pending = append(mc.pending, &pendingCoin{
+				market:  mkt.Name,
+				matchID: m.ID,
+				status:  m.Status,
+				active:  m.Active,
+				name:    coin.name,
+				assetID: coin.assetID,
+				coinID:  coin.coinID,
+				confs:   confs,
+			})
+		}
+	}
+	return mc
+}
+
+func coinIDString(assetID uint32, coinID []byte) string {
+	if s, _, err := dex.DecodeCoinID(assetID, coinID); err == nil {
+		return s
+	}
+	return fmt.Sprintf("%x", coinID)
+}
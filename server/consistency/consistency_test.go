@@ -0,0 +1,284 @@
+package consistency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/asset"
+	"decred.org/dcrdex/server/db"
+)
+
+var tLogger = dex.StdOutLogger("TEST", dex.LevelTrace)
+
+type TDB struct {
+	matches []*db.MatchDataWithCoins
+	err     error
+}
+
+func (tdb *TDB) MarketMatchesStreaming(base, quote uint32, includeInactive bool, N int64, f func(*db.MatchDataWithCoins) error) (int, error) {
+	if tdb.err != nil {
+		return 0, tdb.err
+	}
+	var n int
+	for _, m := range tdb.matches {
+		if !m.Active && !includeInactive {
+			continue
+		}
+		if err := f(m); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+type TBackend struct {
+	coins   map[string]bool
+	confs   map[string]int64
+	lookups int
+	err     error
+}
+
+func (b *TBackend) TxData(coinID []byte) ([]byte, error) {
+	b.lookups++
+	if b.err != nil {
+		return nil, b.err
+	}
+	if !b.coins[string(coinID)] {
+		return nil, asset.CoinNotFoundError
+	}
+	return []byte{1}, nil
+}
+
+func (b *TBackend) TxConfirmations(coinID []byte) (int64, error) {
+	b.lookups++
+	if b.err != nil {
+		return 0, b.err
+	}
+	if !b.coins[string(coinID)] {
+		return 0, asset.CoinNotFoundError
+	}
+	return b.confs[string(coinID)], nil
+}
+
+func tCoinID(b byte) []byte {
+	coinID := make([]byte, 36)
+	coinID[0] = b
+	return coinID
+}
+
+func tMatch(id byte, active, takerSell bool, status order.MatchStatus, makerSwap, takerSwap, makerRedeem, takerRedeem []byte) *db.MatchDataWithCoins {
+	return &db.MatchDataWithCoins{
+		MatchData: db.MatchData{
+			ID:        order.MatchID{id},
+			Active:    active,
+			TakerSell: takerSell,
+			Status:    status,
+		},
+		MakerSwapCoin:   makerSwap,
+		TakerSwapCoin:   takerSwap,
+		MakerRedeemCoin: makerRedeem,
+		TakerRedeemCoin: takerRedeem,
+	}
+}
+
+func TestCheck(t *testing.T) {
+	mkt, err := dex.NewMarketInfoFromSymbols("dcr", "btc", 1e8, 1e3, 10000, 0, 1.5)
+	if err != nil {
+		t.Fatalf("NewMarketInfoFromSymbols error: %v", err)
+	}
+	baseBackend := &TBackend{coins: make(map[string]bool)}
+	quoteBackend := &TBackend{coins: make(map[string]bool)}
+	tdb := new(TDB)
+	c, err := NewChecker(&Config{
+		DB:         tdb,
+		Markets:    []*dex.MarketInfo{mkt},
+		Backends:   map[uint32]TxSource{mkt.Base: baseBackend, mkt.Quote: quoteBackend},
+		SampleRate: 1,
+		Logger:     tLogger,
+	})
+	if err != nil {
+		t.Fatalf("NewChecker error: %v", err)
+	}
+	c.lookupDelay = 0
+	ctx := context.Background()
+
+	// A completed match where the taker sold base, so the taker swapped and
+	// the maker redeemed on the base chain, and an active match where the
+	// maker sold base.
+	completeBase, completeQuote := tCoinID(1), tCoinID(2)
+	completeMakerRedeem, completeTakerRedeem := tCoinID(3), tCoinID(4)
+	activeMakerSwap := tCoinID(5)
+	baseBackend.coins[string(completeBase)] = true
+	baseBackend.coins[string(completeMakerRedeem)] = true
+	quoteBackend.coins[string(completeQuote)] = true
+	quoteBackend.coins[string(completeTakerRedeem)] = true
+	baseBackend.coins[string(activeMakerSwap)] = true
+	tdb.matches = []*db.MatchDataWithCoins{
+		tMatch(1, false, true, order.MatchComplete, completeQuote, completeBase, completeMakerRedeem, completeTakerRedeem),
+		tMatch(2, true, false, order.MakerSwapCast, activeMakerSwap, nil, nil, nil),
+	}
+
+	// Consistent.
+	c.check(ctx)
+	r := c.Report()
+	if r.Checks != 1 || r.MatchesChecked != 2 || r.CoinsChecked != 5 || r.Discrepancies != 0 {
+		t.Fatalf("wrong report for consistent state: %+v", r)
+	}
+	if baseBackend.lookups != 3 || quoteBackend.lookups != 2 {
+		t.Fatalf("coins looked up on wrong chains. %d base lookups, %d quote lookups",
+			baseBackend.lookups, quoteBackend.lookups)
+	}
+
+	// Inject inconsistencies. The active match's status requires a taker swap,
+	// and the completed match's maker redeem is no longer found.
+	tdb.matches[1].Status = order.TakerSwapCast
+	delete(baseBackend.coins, string(completeMakerRedeem))
+	c.check(ctx)
+	r = c.Report()
+	if r.Discrepancies != 2 || len(r.Recent) != 2 {
+		t.Fatalf("expected 2 discrepancies, got %+v", r)
+	}
+	found := make(map[order.MatchID]*Discrepancy)
+	for _, d := range r.Recent {
+		found[d.MatchID] = d
+	}
+	if d := found[order.MatchID{1}]; d == nil || d.Kind != CoinNotFound || d.Market != mkt.Name {
+		t.Fatalf("missing coin not detected: %+v", d)
+	}
+	if d := found[order.MatchID{2}]; d == nil || d.Kind != MissingCoinID || !d.Active || d.Status != order.TakerSwapCast {
+		t.Fatalf("missing coin ID not detected: %+v", d)
+	}
+
+	// Lookup errors other than not found are not discrepancies.
+	baseBackend.err = errors.New("test error")
+	c.check(ctx)
+	r = c.Report()
+	if r.Discrepancies != 3 || r.LookupErrors != 3 {
+		t.Fatalf("wrong report after lookup errors: %+v", r)
+	}
+	baseBackend.err = nil
+
+	// Only a sample of the matches is checked.
+	tdb.matches = nil
+	for i := 0; i < 100; i++ {
+		tdb.matches = append(tdb.matches, tMatch(byte(i), false, true, order.NewlyMatched, nil, nil, nil, nil))
+	}
+	c.sampleRate = 0.1
+	c.check(ctx)
+	if r = c.Report(); r.MatchesChecked != 2+2+2+10 {
+		t.Fatalf("expected 10 matches sampled, got %d", r.MatchesChecked-6)
+	}
+
+	// DB errors are not fatal.
+	tdb.err = errors.New("test error")
+	c.check(ctx)
+	if r = c.Report(); r.Checks != 5 {
+		t.Fatalf("wrong number of checks %d", r.Checks)
+	}
+}
+
+func TestReorgDepth(t *testing.T) {
+	mkt, err := dex.NewMarketInfoFromSymbols("dcr", "btc", 1e8, 1e3, 10000, 0, 1.5)
+	if err != nil {
+		t.Fatalf("NewMarketInfoFromSymbols error: %v", err)
+	}
+	backend := &TBackend{coins: make(map[string]bool), confs: make(map[string]int64)}
+	tdb := new(TDB)
+	const reorgDepth = 3
+	c, err := NewChecker(&Config{
+		DB:          tdb,
+		Markets:     []*dex.MarketInfo{mkt},
+		Backends:    map[uint32]TxSource{mkt.Base: backend},
+		SampleRate:  1,
+		ReorgDepths: map[uint32]uint32{mkt.Base: reorgDepth},
+		Logger:      tLogger,
+	})
+	if err != nil {
+		t.Fatalf("NewChecker error: %v", err)
+	}
+	c.lookupDelay = 0
+	ctx := context.Background()
+
+	check := func(tag string, wantLookups, wantPending int, wantDiscrepancies uint64) *Report {
+		t.Helper()
+		backend.lookups = 0
+		c.check(ctx)
+		r := c.Report()
+		if backend.lookups != wantLookups {
+			t.Fatalf("%s: wanted %d lookups, got %d", tag, wantLookups, backend.lookups)
+		}
+		if r.PendingCoins != wantPending {
+			t.Fatalf("%s: wanted %d pending coins, got %d", tag, wantPending, r.PendingCoins)
+		}
+		if r.Discrepancies != wantDiscrepancies {
+			t.Fatalf("%s: wanted %d discrepancies, got %d", tag, wantDiscrepancies, r.Discrepancies)
+		}
+		return r
+	}
+
+	// A swap with fewer than reorgDepth confirmations is not final.
+	swapCoin := tCoinID(1)
+	backend.coins[string(swapCoin)] = true
+	backend.confs[string(swapCoin)] = 1
+	tdb.matches = []*db.MatchDataWithCoins{tMatch(1, true, false, order.MakerSwapCast, swapCoin, nil, nil, nil)}
+	check("unconfirmed", 1, 1, 0)
+
+	// It is re-checked even if its match is not sampled.
+	tdb.matches = nil
+	backend.confs[string(swapCoin)] = reorgDepth - 1
+	check("below depth", 1, 1, 0)
+
+	// Once it reaches reorgDepth, it's final and no longer re-checked.
+	backend.confs[string(swapCoin)] = reorgDepth
+	check("at depth", 1, 0, 0)
+	delete(backend.coins, string(swapCoin))
+	check("final", 0, 0, 0)
+
+	// A coin that is removed by a reorg before it reaches reorgDepth is a
+	// discrepancy.
+	swapCoin = tCoinID(2)
+	backend.coins[string(swapCoin)] = true
+	backend.confs[string(swapCoin)] = reorgDepth - 1
+	tdb.matches = []*db.MatchDataWithCoins{tMatch(2, true, false, order.MakerSwapCast, swapCoin, nil, nil, nil)}
+	check("new swap", 1, 1, 0)
+	tdb.matches = nil
+	delete(backend.coins, string(swapCoin))
+	r := check("reorged", 1, 0, 1)
+	if d := r.Recent[0]; d.Kind != CoinReorged || d.MatchID != (order.MatchID{2}) || !d.Active {
+		t.Fatalf("wrong discrepancy for reorged coin: %+v", d)
+	}
+
+	// Lookup errors don't stop the re-checks.
+	swapCoin = tCoinID(3)
+	backend.coins[string(swapCoin)] = true
+	backend.confs[string(swapCoin)] = 0
+	tdb.matches = []*db.MatchDataWithCoins{tMatch(3, true, false, order.MakerSwapCast, swapCoin, nil, nil, nil)}
+	check("mempool swap", 1, 1, 1)
+	tdb.matches = nil
+	backend.err = errors.New("test error")
+	check("lookup error", 1, 1, 1)
+	backend.err = nil
+	backend.confs[string(swapCoin)] = reorgDepth
+	check("recovered", 1, 0, 1)
+}
+
+func TestNewChecker(t *testing.T) {
+	if _, err := NewChecker(&Config{Interval: time.Second}); err == nil {
+		t.Fatalf("no error for short interval")
+	}
+	if _, err := NewChecker(&Config{SampleRate: 1.5}); err == nil {
+		t.Fatalf("no error for sample rate > 1")
+	}
+	c, err := NewChecker(&Config{})
+	if err != nil {
+		t.Fatalf("NewChecker error: %v", err)
+	}
+	if c.interval != DefaultInterval || c.sampleRate != DefaultSampleRate {
+		t.Fatalf("defaults not applied")
+	}
+}
@@ -0,0 +1,183 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"decred.org/dcrdex/server/db"
+	"decred.org/dcrdex/server/db/driver/pg/internal"
+)
+
+// ComputeMarketActivity summarizes the market's trading activity for epochs
+// ending in the window (start, end], in milliseconds.
+func (a *Archiver) ComputeMarketActivity(base, quote uint32, start, end uint64) (*db.MarketActivity, error) {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return nil, err
+	}
+	if end <= start {
+		return nil, fmt.Errorf("invalid activity window (%d, %d]", start, end)
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	ordersArchived := fullOrderTableName(a.dbName, marketSchema, false)
+	ordersActive := fullOrderTableName(a.dbName, marketSchema, true)
+	cancelsArchived := fullCancelOrderTableName(a.dbName, marketSchema, false)
+	cancelsActive := fullCancelOrderTableName(a.dbName, marketSchema, true)
+	matchesTable := fullMatchesTableName(a.dbName, marketSchema)
+
+	count := func(stmt string, tables ...string) (uint64, error) {
+		var total uint64
+		for _, tableName := range tables {
+			var n fastUint64
+			err := a.db.QueryRowContext(ctx, fmt.Sprintf(stmt, tableName), start, end).Scan(&n)
+			if err != nil {
+				return 0, err
+			}
+			total += uint64(n)
+		}
+		return total, nil
+	}
+
+	act := &db.MarketActivity{
+		EndStamp: end,
+		Duration: end - start,
+	}
+	if act.Orders, err = count(internal.CountOrdersInWindow, ordersArchived, ordersActive); err != nil {
+		return nil, fmt.Errorf("error counting orders: %w", err)
+	}
+	if act.Cancels, err = count(internal.CountOrdersInWindow, cancelsArchived, cancelsActive); err != nil {
+		return nil, fmt.Errorf("error counting cancels: %w", err)
+	}
+	if act.Matches, err = count(internal.CountMatchesInWindow, matchesTable); err != nil {
+		return nil, fmt.Errorf("error counting matches: %w", err)
+	}
+
+	stmt := fmt.Sprintf(internal.SumEpochVolumesInWindow, fullEpochReportsTableName(a.dbName, marketSchema))
+	var matchVol, quoteVol fastUint64
+	if err = a.db.QueryRowContext(ctx, stmt, start, end).Scan(&matchVol, &quoteVol); err != nil {
+		return nil, fmt.Errorf("error summing volumes: %w", err)
+	}
+	act.MatchVolume, act.QuoteVolume = uint64(matchVol), uint64(quoteVol)
+
+	stmt = fmt.Sprintf(internal.CountAccountsInWindow, ordersArchived, ordersActive,
+		cancelsArchived, cancelsActive, matchesTable)
+	var accts fastUint64
+	if err = a.db.QueryRowContext(ctx, stmt, start, end).Scan(&accts); err != nil {
+		return nil, fmt.Errorf("error counting accounts: %w", err)
+	}
+	act.Accounts = uint64(accts)
+
+	return act, nil
+}
+
+// InsertMarketActivity stores a market activity summary, replacing any existing
+// summary with the same end stamp.
+func (a *Archiver) InsertMarketActivity(base, quote uint32, act *db.MarketActivity) error {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf(internal.InsertActivity, fullActivityTableName(a.dbName, marketSchema))
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	_, err = a.db.ExecContext(ctx, stmt, act.EndStamp, act.Duration, act.Orders, act.Cancels,
+		act.Matches, act.MatchVolume, act.QuoteVolume, act.Accounts)
+	if err != nil {
+		a.fatalBackendErr(err)
+	}
+	return err
+}
+
+// LastMarketActivityEndStamp is the end stamp of the most recent stored
+// activity summary for the market, or zero if there are none.
+func (a *Archiver) LastMarketActivityEndStamp(base, quote uint32) (uint64, error) {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return 0, err
+	}
+	stmt := fmt.Sprintf(internal.SelectLastEndStamp, fullActivityTableName(a.dbName, marketSchema))
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	var endStamp fastUint64
+	if err = a.db.QueryRowContext(ctx, stmt).Scan(&endStamp); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return uint64(endStamp), nil
+}
+
+// MarketActivity retrieves the market's stored activity summaries with end
+// stamps after since, sorted by ascending end stamp.
+func (a *Archiver) MarketActivity(base, quote uint32, since uint64) ([]*db.MarketActivity, error) {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return nil, err
+	}
+	stmt := fmt.Sprintf(internal.SelectActivity, fullActivityTableName(a.dbName, marketSchema))
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	rows, err := a.db.QueryContext(ctx, stmt, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var acts []*db.MarketActivity
+	for rows.Next() {
+		var endStamp, dur, orders, cancels, matches, matchVol, quoteVol, accts fastUint64
+		err = rows.Scan(&endStamp, &dur, &orders, &cancels, &matches, &matchVol, &quoteVol, &accts)
+		if err != nil {
+			return nil, err
+		}
+		acts = append(acts, &db.MarketActivity{
+			EndStamp:    uint64(endStamp),
+			Duration:    uint64(dur),
+			Orders:      uint64(orders),
+			Cancels:     uint64(cancels),
+			Matches:     uint64(matches),
+			MatchVolume: uint64(matchVol),
+			QuoteVolume: uint64(quoteVol),
+			Accounts:    uint64(accts),
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return acts, nil
+}
+
+// PruneMarketActivity deletes the market's activity summaries with end stamps
+// before the specified time, returning the number deleted.
+func (a *Archiver) PruneMarketActivity(base, quote uint32, before uint64) (int64, error) {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return 0, err
+	}
+	stmt := fmt.Sprintf(internal.DeleteActivityBefore, fullActivityTableName(a.dbName, marketSchema))
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	res, err := a.db.ExecContext(ctx, stmt, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,139 @@
+//go:build pgonline
+
+package pg
+
+import (
+	"testing"
+
+	"decred.org/dcrdex/dex/calc"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/db"
+)
+
+func TestMarketActivity(t *testing.T) {
+	if err := cleanTables(archie.db); err != nil {
+		t.Fatalf("cleanTables: %v", err)
+	}
+
+	const epochDur = 1000
+
+	// Booked buy received in epoch 10 (ending at 11000).
+	limitBuy := newLimitOrder(false, 4500000, 2, order.StandingTiF, 0)
+	if err := archie.NewEpochOrder(limitBuy, 10, epochDur, 0); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+	if err := archie.BookOrder(limitBuy); err != nil {
+		t.Fatalf("BookOrder error: %v", err)
+	}
+	base, quote := limitBuy.Base(), limitBuy.Quote()
+
+	// Immediate sell received and matched in epoch 12 (ending at 13000).
+	limitSell := newLimitOrder(true, 4500000, 1, order.ImmediateTiF, 10)
+	if err := archie.NewEpochOrder(limitSell, 12, epochDur, 0); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+	if err := archie.ExecuteOrder(limitSell); err != nil {
+		t.Fatalf("ExecuteOrder error: %v", err)
+	}
+	match := newMatch(limitBuy, limitSell, limitSell.Quantity, order.EpochID{Idx: 12, Dur: epochDur})
+	if err := archie.InsertMatch(match); err != nil {
+		t.Fatalf("InsertMatch error: %v", err)
+	}
+	quoteVol := calc.BaseToQuote(limitBuy.Rate, limitSell.Quantity)
+	err := archie.InsertEpoch(&db.EpochResults{
+		MktBase:     base,
+		MktQuote:    quote,
+		Idx:         12,
+		Dur:         epochDur,
+		MatchVolume: limitSell.Quantity,
+		QuoteVolume: quoteVol,
+	})
+	if err != nil {
+		t.Fatalf("InsertEpoch error: %v", err)
+	}
+
+	// Cancel received in epoch 13 (ending at 14000) from another account.
+	co := newCancelOrder(limitBuy.ID(), base, quote, 20)
+	if err := archie.NewEpochOrder(co, 13, epochDur, 3); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+
+	// An order received after the window.
+	laterSell := newLimitOrder(true, 4600000, 1, order.StandingTiF, 30)
+	if err := archie.NewEpochOrder(laterSell, 20, epochDur, 0); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+
+	act, err := archie.ComputeMarketActivity(base, quote, 10000, 15000)
+	if err != nil {
+		t.Fatalf("ComputeMarketActivity error: %v", err)
+	}
+	expAct := &db.MarketActivity{
+		EndStamp:    15000,
+		Duration:    5000,
+		Orders:      2,
+		Cancels:     1,
+		Matches:     1,
+		MatchVolume: limitSell.Quantity,
+		QuoteVolume: quoteVol,
+		Accounts:    3,
+	}
+	if *act != *expAct {
+		t.Fatalf("wrong activity. wanted %+v, got %+v", expAct, act)
+	}
+
+	// Nothing in an earlier window.
+	act, err = archie.ComputeMarketActivity(base, quote, 5000, 10000)
+	if err != nil {
+		t.Fatalf("ComputeMarketActivity error: %v", err)
+	}
+	if act.Orders != 0 || act.Accounts != 0 || act.MatchVolume != 0 {
+		t.Fatalf("unexpected activity in empty window: %+v", act)
+	}
+
+	lastEnd, err := archie.LastMarketActivityEndStamp(base, quote)
+	if err != nil {
+		t.Fatalf("LastMarketActivityEndStamp error: %v", err)
+	}
+	if lastEnd != 0 {
+		t.Fatalf("expected zero initial end stamp, got %d", lastEnd)
+	}
+
+	for _, a := range []*db.MarketActivity{act, expAct} {
+		if err = archie.InsertMarketActivity(base, quote, a); err != nil {
+			t.Fatalf("InsertMarketActivity error: %v", err)
+		}
+	}
+	// Replacing is fine.
+	expAct.Orders = 3
+	if err = archie.InsertMarketActivity(base, quote, expAct); err != nil {
+		t.Fatalf("InsertMarketActivity (replace) error: %v", err)
+	}
+
+	lastEnd, err = archie.LastMarketActivityEndStamp(base, quote)
+	if err != nil {
+		t.Fatalf("LastMarketActivityEndStamp error: %v", err)
+	}
+	if lastEnd != 15000 {
+		t.Fatalf("wrong last end stamp. wanted 15000, got %d", lastEnd)
+	}
+
+	acts, err := archie.MarketActivity(base, quote, 0)
+	if err != nil {
+		t.Fatalf("MarketActivity error: %v", err)
+	}
+	if len(acts) != 2 || acts[0].EndStamp != 10000 || *acts[1] != *expAct {
+		t.Fatalf("wrong stored activity: %+v", acts)
+	}
+
+	n, err := archie.PruneMarketActivity(base, quote, 15000)
+	if err != nil {
+		t.Fatalf("PruneMarketActivity error: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 pruned summary, got %d", n)
+	}
+	if acts, _ = archie.MarketActivity(base, quote, 0); len(acts) != 1 {
+		t.Fatalf("expected 1 summary after pruning, got %d", len(acts))
+	}
+}
@@ -0,0 +1,73 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"decred.org/dcrdex/server/db"
+	"decred.org/dcrdex/server/db/driver/pg/internal"
+)
+
+// InsertAdminAction appends an admin action to the audit log.
+func (a *Archiver) InsertAdminAction(act *db.AdminAction) error {
+	params, err := json.Marshal(act.Params)
+	if err != nil {
+		return fmt.Errorf("error encoding parameters: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+	stmt := fmt.Sprintf(internal.InsertAdminAction, adminActionsTableName)
+	_, err = a.db.ExecContext(ctx, stmt, int64(act.Stamp), act.Actor, act.Action, string(params), act.Status)
+	return err
+}
+
+// AdminActions retrieves the most recent n admin actions with stamps after
+// since, sorted by ascending stamp. All such actions are returned if n is not
+// positive.
+func (a *Archiver) AdminActions(since uint64, n int) ([]*db.AdminAction, error) {
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	var limit sql.NullInt64
+	if n > 0 {
+		limit = sql.NullInt64{Int64: int64(n), Valid: true}
+	}
+	stmt := fmt.Sprintf(internal.SelectAdminActions, adminActionsTableName)
+	rows, err := a.db.QueryContext(ctx, stmt, int64(since), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var acts []*db.AdminAction
+	for rows.Next() {
+		var act db.AdminAction
+		var stamp int64
+		var params sql.NullString
+		var status sql.NullInt32
+		if err = rows.Scan(&stamp, &act.Actor, &act.Action, &params, &status); err != nil {
+			return nil, err
+		}
+		act.Stamp, act.Status = uint64(stamp), int(status.Int32)
+		if params.Valid && params.String != "" {
+			if err = json.Unmarshal([]byte(params.String), &act.Params); err != nil {
+				return nil, fmt.Errorf("error decoding parameters: %w", err)
+			}
+		}
+		acts = append(acts, &act)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Reverse the newest-first query results.
+	for i, j := 0, len(acts)-1; i < j; i, j = i+1, j-1 {
+		acts[i], acts[j] = acts[j], acts[i]
+	}
+	return acts, nil
+}
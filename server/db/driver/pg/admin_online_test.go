@@ -0,0 +1,55 @@
+//go:build pgonline
+
+package pg
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"decred.org/dcrdex/server/db"
+)
+
+func TestAdminActions(t *testing.T) {
+	if err := cleanTables(archie.db); err != nil {
+		t.Fatalf("cleanTables: %v", err)
+	}
+
+	acts := []*db.AdminAction{
+		{Stamp: 1000, Actor: "u@127.0.0.1", Action: "GET /market/{market}/suspend", Params: map[string]string{"market": "dcr_btc"}, Status: 200},
+		{Stamp: 2000, Actor: "u@127.0.0.1", Action: "GET /account/{account}", Params: map[string]string{"account": "abcd"}, Status: 200},
+		{Stamp: 3000, Actor: "u@127.0.0.1", Action: "GET /config", Status: 200},
+	}
+	for _, act := range acts {
+		if err := archie.InsertAdminAction(act); err != nil {
+			t.Fatalf("InsertAdminAction error: %v", err)
+		}
+	}
+
+	check := func(since uint64, n int, exp []*db.AdminAction) {
+		t.Helper()
+		got, err := archie.AdminActions(since, n)
+		if err != nil {
+			t.Fatalf("AdminActions error: %v", err)
+		}
+		if len(got) != len(exp) {
+			t.Fatalf("expected %d actions, got %d", len(exp), len(got))
+		}
+		for i := range got {
+			if !reflect.DeepEqual(got[i], exp[i]) {
+				t.Fatalf("action %d: expected %+v, got %+v", i, exp[i], got[i])
+			}
+		}
+	}
+	check(0, 0, acts)
+	check(1000, 0, acts[1:])
+	check(0, 2, acts[1:])
+
+	// The log is append-only.
+	for _, stmt := range []string{`UPDATE %s SET actor = 'x';`, `DELETE FROM %s;`} {
+		if _, err := archie.db.Exec(fmt.Sprintf(stmt, adminActionsTableName)); err != nil {
+			t.Fatalf("Exec error: %v", err)
+		}
+	}
+	check(0, 0, acts)
+}
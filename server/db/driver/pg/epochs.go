@@ -4,12 +4,14 @@
 package pg
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	"decred.org/dcrdex/dex/candles"
@@ -73,9 +75,22 @@ func (a *Archiver) InsertEpoch(ed *db.EpochResults) error {
 		return err
 	}
 
+	if ed.Book != nil {
+		filled := make(pq.Int64Array, 0, len(ed.Book.Filled))
+		for _, f := range ed.Book.Filled {
+			filled = append(filled, int64(f))
+		}
+		stmt = fmt.Sprintf(internal.InsertEpochBook, fullEpochBooksTableName(a.dbName, marketSchema))
+		_, err = a.db.Exec(stmt, ed.Idx, ed.Dur, int64(ed.Book.LotSize), orderIDs(ed.Book.Orders), filled)
+		if err != nil {
+			a.fatalBackendErr(err)
+			return err
+		}
+	}
+
+	epochEnd := (ed.Idx + 1) * ed.Dur
 	epochReportsTableName := fullEpochReportsTableName(a.dbName, marketSchema)
 	stmt = fmt.Sprintf(internal.InsertEpochReport, epochReportsTableName)
-	epochEnd := (ed.Idx + 1) * ed.Dur
 	_, err = a.db.Exec(stmt, epochEnd, ed.Dur, ed.MatchVolume, ed.QuoteVolume, ed.BookBuys, ed.BookBuys5, ed.BookBuys25,
 		ed.BookSells, ed.BookSells5, ed.BookSells25, ed.HighRate, ed.LowRate, ed.StartRate, ed.EndRate)
 	if err != nil {
@@ -85,6 +100,203 @@ func (a *Archiver) InsertEpoch(ed *db.EpochResults) error {
 	return err
 }
 
+// epochBookPruneInterval is how often the recorded epoch books older than the
+// retention period are deleted.
+const epochBookPruneInterval = time.Hour
+
+// epochBookPruneBatch is the most epoch books deleted by a single statement,
+// so that pruning a large backlog does not hold locks on the table for long.
+const epochBookPruneBatch = 1000
+
+// pruneEpochBooksLoop deletes the recorded epoch books older than the
+// retention period once per epochBookPruneInterval until the Archiver's
+// context is canceled. This must be run as a goroutine.
+func (a *Archiver) pruneEpochBooksLoop() {
+	ticker := time.NewTicker(epochBookPruneInterval)
+	defer ticker.Stop()
+	for {
+		a.pruneAllEpochBooks(time.Now())
+		select {
+		case <-ticker.C:
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
+
+// pruneAllEpochBooks deletes the recorded books of every market for epochs
+// that ended more than the retention period before the specified time.
+func (a *Archiver) pruneAllEpochBooks(now time.Time) {
+	before := now.Add(-a.epochBookRetention).UnixMilli()
+	for schema := range a.markets {
+		if a.ctx.Err() != nil {
+			return
+		}
+		a.pruneEpochBooks(schema, before)
+	}
+}
+
+// pruneEpochBooks deletes the market's recorded books of epochs that ended
+// before the specified time, in milliseconds, in batches of
+// epochBookPruneBatch. Errors are logged since the books are only needed for
+// replay.
+func (a *Archiver) pruneEpochBooks(marketSchema string, before int64) {
+	stmt := fmt.Sprintf(internal.DeleteEpochBooksBefore, fullEpochBooksTableName(a.dbName, marketSchema))
+
+	var pruned int64
+	for a.ctx.Err() == nil {
+		ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+		res, err := a.db.ExecContext(ctx, stmt, before, epochBookPruneBatch)
+		cancel()
+		if err != nil {
+			log.Errorf("Failed to prune epoch books for market %s: %v", marketSchema, err)
+			break
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			log.Errorf("Failed to count pruned epoch books for market %s: %v", marketSchema, err)
+			break
+		}
+		pruned += n
+		if n < epochBookPruneBatch {
+			break
+		}
+	}
+	if pruned > 0 {
+		log.Debugf("Pruned %d epoch books for market %s.", pruned, marketSchema)
+	}
+}
+
+// EpochAudit retrieves the commit-reveal record of a matched epoch. An
+// ArchiveError with code ErrUnknownEpoch is returned if the epoch is not in the
+// epochs table, which is only written after preimage collection and matching.
+func (a *Archiver) EpochAudit(base, quote uint32, epochIdx, epochDur int64) (*db.EpochAudit, error) {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	epochsTableName := fullEpochsTableName(a.dbName, marketSchema)
+	stmt := fmt.Sprintf(internal.SelectEpoch, epochsTableName)
+	audit := &db.EpochAudit{
+		Idx: epochIdx,
+		Dur: epochDur,
+	}
+	var revealed, missed orderIDs
+	err = a.db.QueryRowContext(ctx, stmt, epochIdx, epochDur).Scan(&audit.MatchTime,
+		&audit.CSum, &audit.Seed, &revealed, &missed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, db.ArchiveError{
+				Code:   db.ErrUnknownEpoch,
+				Detail: fmt.Sprintf("epoch %d, duration %d", epochIdx, epochDur),
+			}
+		}
+		return nil, err
+	}
+
+	oids := append(revealed, missed...)
+	if len(oids) == 0 {
+		return audit, nil
+	}
+	oidArr := make(pq.ByteaArray, 0, len(oids))
+	for i := range oids {
+		oidArr = append(oidArr, oids[i][:])
+	}
+
+	// The epoch's orders may be trade or cancel orders, and active or
+	// archived.
+	found := make(map[order.OrderID]*db.EpochAuditOrder, len(oids))
+	for _, active := range []bool{true, false} {
+		for _, tableName := range []string{
+			fullOrderTableName(a.dbName, marketSchema, active),
+			fullCancelOrderTableName(a.dbName, marketSchema, active),
+		} {
+			stmt = fmt.Sprintf(internal.SelectOrderCommitsAndPreimages, tableName)
+			rows, err := a.db.QueryContext(ctx, stmt, oidArr)
+			if err != nil {
+				return nil, err
+			}
+			for rows.Next() {
+				ord := new(db.EpochAuditOrder)
+				if err = rows.Scan(&ord.ID, &ord.Commit, &ord.Preimage); err != nil {
+					rows.Close()
+					return nil, err
+				}
+				found[ord.ID] = ord
+			}
+			rows.Close()
+			if err = rows.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	audit.Orders = make([]*db.EpochAuditOrder, 0, len(oids))
+	for _, oid := range oids {
+		ord := found[oid]
+		if ord == nil {
+			return nil, db.ArchiveError{
+				Code:   db.ErrUnknownOrder,
+				Detail: fmt.Sprintf("order %v in epoch %d", oid, epochIdx),
+			}
+		}
+		audit.Orders = append(audit.Orders, ord)
+	}
+	// Missed orders have no preimage, even if one was later stored.
+	for _, oid := range missed {
+		found[oid].Preimage = order.Preimage{}
+	}
+	sort.Slice(audit.Orders, func(i, j int) bool {
+		return bytes.Compare(audit.Orders[i].Commit[:], audit.Orders[j].Commit[:]) < 0
+	})
+	return audit, nil
+}
+
+// EpochBook retrieves the recorded state of the book at the start of an
+// epoch's matching. An ArchiveError with code ErrUnknownEpoch is returned if no
+// book was recorded for the epoch, which is the case for epochs with no
+// revealed orders.
+func (a *Archiver) EpochBook(base, quote uint32, epochIdx, epochDur int64) (*db.EpochBook, error) {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	stmt := fmt.Sprintf(internal.SelectEpochBook, fullEpochBooksTableName(a.dbName, marketSchema))
+	var lotSize int64
+	var oids orderIDs
+	var filled pq.Int64Array
+	err = a.db.QueryRowContext(ctx, stmt, epochIdx, epochDur).Scan(&lotSize, &oids, &filled)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, db.ArchiveError{
+				Code:   db.ErrUnknownEpoch,
+				Detail: fmt.Sprintf("no book for epoch %d, duration %d", epochIdx, epochDur),
+			}
+		}
+		return nil, err
+	}
+	if len(filled) != len(oids) {
+		return nil, fmt.Errorf("epoch %d book has %d orders but %d fill amounts", epochIdx, len(oids), len(filled))
+	}
+	book := &db.EpochBook{
+		LotSize: uint64(lotSize),
+		Orders:  oids,
+		Filled:  make([]uint64, len(filled)),
+	}
+	for i, f := range filled {
+		book.Filled[i] = uint64(f)
+	}
+	return book, nil
+}
+
 // LastEpochRate gets the EndRate of the last EpochResults inserted for the
 // market. If the database is empty, no error and a rate of zero are returned.
 func (a *Archiver) LastEpochRate(base, quote uint32) (rate uint64, err error) {
@@ -0,0 +1,126 @@
+//go:build pgonline
+
+package pg
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/db"
+)
+
+func TestEpochBook(t *testing.T) {
+	if err := cleanTables(archie.db); err != nil {
+		t.Fatalf("cleanTables: %v", err)
+	}
+
+	const epochIdx, epochDur = 12, 1000
+
+	limitBuy := newLimitOrder(false, 4500000, 2, order.StandingTiF, 0)
+	limitBuy2 := newLimitOrder(false, 4400000, 3, order.StandingTiF, 5)
+	base, quote := limitBuy.Base(), limitBuy.Quote()
+
+	// No book recorded.
+	_, err := archie.EpochBook(base, quote, epochIdx, epochDur)
+	if !db.SameErrorTypes(err, db.ArchiveError{Code: db.ErrUnknownEpoch}) {
+		t.Fatalf("expected unknown epoch error, got %v", err)
+	}
+
+	limitSell := newLimitOrder(true, 4500000, 1, order.ImmediateTiF, 10)
+	if err := archie.NewEpochOrder(limitSell, epochIdx, epochDur, 0); err != nil {
+		t.Fatalf("NewEpochOrder error: %v", err)
+	}
+	match := newMatch(limitBuy, limitSell, limitSell.Quantity, order.EpochID{Idx: epochIdx, Dur: epochDur})
+	if err := archie.InsertMatch(match); err != nil {
+		t.Fatalf("InsertMatch error: %v", err)
+	}
+
+	book := &db.EpochBook{
+		LotSize: LotSize,
+		Orders:  []order.OrderID{limitBuy.ID(), limitBuy2.ID()},
+		Filled:  []uint64{LotSize, 0},
+	}
+	err = archie.InsertEpoch(&db.EpochResults{
+		MktBase:  base,
+		MktQuote: quote,
+		Idx:      epochIdx,
+		Dur:      epochDur,
+		Book:     book,
+	})
+	if err != nil {
+		t.Fatalf("InsertEpoch error: %v", err)
+	}
+
+	gotBook, err := archie.EpochBook(base, quote, epochIdx, epochDur)
+	if err != nil {
+		t.Fatalf("EpochBook error: %v", err)
+	}
+	if !reflect.DeepEqual(gotBook, book) {
+		t.Fatalf("wrong epoch book %+v, expected %+v", gotBook, book)
+	}
+
+	matches, err := archie.EpochMatches(base, quote, epochIdx, epochDur)
+	if err != nil {
+		t.Fatalf("EpochMatches error: %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != match.ID() {
+		t.Fatalf("expected match %v, got %v", match.ID(), matches)
+	}
+
+	matches, err = archie.EpochMatches(base, quote, epochIdx+1, epochDur)
+	if err != nil {
+		t.Fatalf("EpochMatches error: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Fatalf("expected no matches, got %d", len(matches))
+	}
+}
+
+func TestPruneEpochBooks(t *testing.T) {
+	if err := cleanTables(archie.db); err != nil {
+		t.Fatalf("cleanTables: %v", err)
+	}
+
+	defer func(retention time.Duration) { archie.epochBookRetention = retention }(archie.epochBookRetention)
+	archie.epochBookRetention = 2 * time.Hour
+
+	const epochDur = 60 * 1000 // one minute
+	limitBuy := newLimitOrder(false, 4500000, 2, order.StandingTiF, 0)
+	base, quote := limitBuy.Base(), limitBuy.Quote()
+
+	insert := func(epochIdx int64) {
+		t.Helper()
+		err := archie.InsertEpoch(&db.EpochResults{
+			MktBase:  base,
+			MktQuote: quote,
+			Idx:      epochIdx,
+			Dur:      epochDur,
+			Book: &db.EpochBook{
+				LotSize: LotSize,
+				Orders:  []order.OrderID{limitBuy.ID()},
+				Filled:  []uint64{0},
+			},
+		})
+		if err != nil {
+			t.Fatalf("InsertEpoch error: %v", err)
+		}
+	}
+
+	// Epoch 59 ends three hours before epoch 239, so only its book is older
+	// than the retention period at the end of epoch 239.
+	const oldIdx, newIdx = 59, 239
+	insert(oldIdx)
+	insert(newIdx)
+
+	archie.pruneAllEpochBooks(time.UnixMilli((newIdx + 1) * epochDur))
+
+	_, err := archie.EpochBook(base, quote, oldIdx, epochDur)
+	if !db.SameErrorTypes(err, db.ArchiveError{Code: db.ErrUnknownEpoch}) {
+		t.Fatalf("expected old epoch book to be pruned, got %v", err)
+	}
+	if _, err = archie.EpochBook(base, quote, newIdx, epochDur); err != nil {
+		t.Fatalf("EpochBook error: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package internal
+
+const (
+	// CreateActivityTable creates a table that holds market activity
+	// summaries.
+	CreateActivityTable = `CREATE TABLE IF NOT EXISTS %s (
+		end_stamp INT8 PRIMARY KEY, -- end of the summary window, in milliseconds
+		dur INT8,                   -- duration of the summary window, in milliseconds
+		orders INT8,                -- number of trade orders received
+		cancels INT8,               -- number of cancel orders received
+		matches INT8,               -- number of trade matches made
+		match_volume INT8,          -- matched volume in terms of the base asset
+		quote_volume INT8,          -- matched volume in terms of the quote asset
+		accounts INT8               -- number of unique accounts that ordered or matched
+	);`
+
+	// InsertActivity inserts or replaces a market activity summary.
+	InsertActivity = `INSERT INTO %s (
+		end_stamp, dur, orders, cancels, matches, match_volume, quote_volume, accounts
+	)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (end_stamp) DO UPDATE
+	SET dur = $2, orders = $3, cancels = $4, matches = $5, match_volume = $6,
+		quote_volume = $7, accounts = $8;`
+
+	// SelectActivity retrieves the market activity summaries with end stamps
+	// after the specified time.
+	SelectActivity = `SELECT end_stamp, dur, orders, cancels, matches, match_volume,
+		quote_volume, accounts
+	FROM %s
+	WHERE end_stamp > $1
+	ORDER BY end_stamp;`
+
+	// CreateOrdersEpochEndIndex creates an index on the epoch end stamp of a
+	// trade or cancel orders table, as computed by the window queries.
+	CreateOrdersEpochEndIndex = `CREATE INDEX IF NOT EXISTS %s ON %s (((epoch_idx + 1) * epoch_dur));`
+
+	// CreateMatchesEpochEndIndex creates an index on the epoch end stamp of a
+	// matches table, as computed by the window queries.
+	CreateMatchesEpochEndIndex = `CREATE INDEX IF NOT EXISTS %s ON %s (((epochIdx + 1) * epochDur));`
+
+	// DeleteActivityBefore deletes market activity summaries with end stamps
+	// before the specified time.
+	DeleteActivityBefore = `DELETE FROM %s WHERE end_stamp < $1;`
+
+	// CountOrdersInWindow counts the orders, from a trade or cancel orders
+	// table, that were received in epochs ending in the window ($1, $2].
+	// Revocations are not counted.
+	CountOrdersInWindow = `SELECT COUNT(*)
+		FROM %s
+		WHERE epoch_dur > 0 AND (epoch_idx + 1) * epoch_dur > $1 AND (epoch_idx + 1) * epoch_dur <= $2;`
+
+	// CountMatchesInWindow counts the trade matches made in epochs ending in
+	// the window ($1, $2]. Cancel order matches are not counted.
+	CountMatchesInWindow = `SELECT COUNT(*)
+		FROM %s
+		WHERE takerSell IS NOT NULL AND (epochIdx + 1) * epochDur > $1 AND (epochIdx + 1) * epochDur <= $2;`
+
+	// SumEpochVolumesInWindow sums the matched volumes from the epoch_reports
+	// table for epochs ending in the window ($1, $2].
+	SumEpochVolumesInWindow = `SELECT COALESCE(SUM(match_volume), 0)::INT8, COALESCE(SUM(quote_volume), 0)::INT8
+		FROM %s
+		WHERE epoch_end > $1 AND epoch_end <= $2;`
+
+	// CountAccountsInWindow counts the unique accounts that placed orders or
+	// were matched in epochs ending in the window ($1, $2]. The tables are,
+	// in order, the archived and active orders tables, the archived and
+	// active cancels tables, and the matches table.
+	CountAccountsInWindow = `SELECT COUNT(DISTINCT account_id) FROM (
+			SELECT account_id FROM %[1]s
+			WHERE epoch_dur > 0 AND (epoch_idx + 1) * epoch_dur > $1 AND (epoch_idx + 1) * epoch_dur <= $2
+		UNION ALL
+			SELECT account_id FROM %[2]s
+			WHERE epoch_dur > 0 AND (epoch_idx + 1) * epoch_dur > $1 AND (epoch_idx + 1) * epoch_dur <= $2
+		UNION ALL
+			SELECT account_id FROM %[3]s
+			WHERE epoch_dur > 0 AND (epoch_idx + 1) * epoch_dur > $1 AND (epoch_idx + 1) * epoch_dur <= $2
+		UNION ALL
+			SELECT account_id FROM %[4]s
+			WHERE epoch_dur > 0 AND (epoch_idx + 1) * epoch_dur > $1 AND (epoch_idx + 1) * epoch_dur <= $2
+		UNION ALL
+			SELECT makerAccount FROM %[5]s
+			WHERE takerSell IS NOT NULL AND (epochIdx + 1) * epochDur > $1 AND (epochIdx + 1) * epochDur <= $2
+		UNION ALL
+			SELECT takerAccount FROM %[5]s
+			WHERE takerSell IS NOT NULL AND (epochIdx + 1) * epochDur > $1 AND (epochIdx + 1) * epochDur <= $2
+		) AS accts;`
+)
@@ -0,0 +1,32 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package internal
+
+const (
+	// CreateAdminActionsTable creates the append-only audit log of admin
+	// actions. Rules make updates and deletes no-ops, so the records may only
+	// be removed by dropping the table.
+	CreateAdminActionsTable = `CREATE TABLE IF NOT EXISTS %[1]s (
+		id SERIAL8 PRIMARY KEY,
+		stamp INT8 NOT NULL, -- time of the request, in milliseconds
+		actor TEXT NOT NULL,
+		action TEXT NOT NULL,
+		params TEXT,         -- JSON object of request parameters
+		status INT4          -- HTTP status code of the response
+	);
+	CREATE OR REPLACE RULE admin_actions_no_update AS ON UPDATE TO %[1]s DO INSTEAD NOTHING;
+	CREATE OR REPLACE RULE admin_actions_no_delete AS ON DELETE TO %[1]s DO INSTEAD NOTHING;`
+
+	// InsertAdminAction appends an admin action to the audit log.
+	InsertAdminAction = `INSERT INTO %s (stamp, actor, action, params, status)
+		VALUES ($1, $2, $3, $4, $5);`
+
+	// SelectAdminActions retrieves the most recent admin actions with stamps
+	// after $1, limited to $2 actions. A NULL limit returns all such actions.
+	SelectAdminActions = `SELECT stamp, actor, action, params, status
+		FROM %s
+		WHERE stamp > $1
+		ORDER BY id DESC
+		LIMIT $2;`
+)
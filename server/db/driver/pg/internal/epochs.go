@@ -18,6 +18,39 @@ const (
 	InsertEpoch = `INSERT INTO %s (epoch_idx, epoch_dur, match_time, csum, seed, revealed, missed)
 		VALUES ($1, $2, $3, $4, $5, $6, $7);`
 
+	// SelectEpoch retrieves the match proof data of the epoch with the given
+	// index and duration.
+	SelectEpoch = `SELECT match_time, csum, seed, revealed, missed
+		FROM %s
+		WHERE epoch_idx = $1 AND epoch_dur = $2;`
+
+	// CreateEpochBooksTable creates a table specified via the %s printf
+	// specifier for the state of the book at the start of an epoch's matching.
+	CreateEpochBooksTable = `CREATE TABLE IF NOT EXISTS %s (
+		epoch_idx INT8,
+		epoch_dur INT4,       -- epoch duration in milliseconds
+		lot_size INT8,        -- the book's lot size
+		orders BYTEA[],       -- IDs of the booked orders
+		filled INT8[],        -- amount filled of each booked order
+		PRIMARY KEY(epoch_idx, epoch_dur)
+	);`
+
+	// InsertEpochBook inserts the state of the book at the start of an
+	// epoch's matching.
+	InsertEpochBook = `INSERT INTO %s (epoch_idx, epoch_dur, lot_size, orders, filled)
+		VALUES ($1, $2, $3, $4, $5);`
+
+	// DeleteEpochBooksBefore deletes up to $2 of the recorded books of epochs
+	// that ended before the specified time.
+	DeleteEpochBooksBefore = `DELETE FROM %[1]s WHERE ctid = ANY(ARRAY(
+		SELECT ctid FROM %[1]s WHERE (epoch_idx + 1) * epoch_dur < $1 LIMIT $2));`
+
+	// SelectEpochBook retrieves the state of the book at the start of the
+	// matching of the epoch with the given index and duration.
+	SelectEpochBook = `SELECT lot_size, orders, filled
+		FROM %s
+		WHERE epoch_idx = $1 AND epoch_dur = $2;`
+
 	SelectLastEpochRate = `SELECT end_rate
 		FROM %s
 		ORDER BY epoch_end DESC
@@ -109,6 +109,15 @@ const (
 	FROM %s
 	WHERE takerAccount = $1 OR makerAccount = $1;`
 
+	// RetrieveEpochMatches retrieves the trade and cancel matches made in the
+	// epoch with the given index and duration.
+	RetrieveEpochMatches = `SELECT matchid, active, takerSell,
+		takerOrder, takerAccount, takerAddress,
+		makerOrder, makerAccount, makerAddress,
+		epochIdx, epochDur, quantity, rate, baseRate, quoteRate, status
+	FROM %s
+	WHERE epochIdx = $1 AND epochDur = $2;`
+
 	RetrieveActiveUserMatches = `SELECT matchid, takerSell,
 		takerOrder, takerAccount, takerAddress,
 		makerOrder, makerAccount, makerAddress,
@@ -86,6 +86,11 @@ const (
 	// commitment value. This applies to the cancel order tables as well.
 	SelectOrderByCommit = `SELECT oid FROM %s WHERE commit = $1;`
 
+	// SelectOrderCommitsAndPreimages retrieves the commitments and preimages of
+	// the orders with the provided order IDs. This applies to the cancel order
+	// tables as well.
+	SelectOrderCommitsAndPreimages = `SELECT oid, commit, preimage FROM %s WHERE oid = ANY($1);`
+
 	// SelectOrderPreimage retrieves the preimage for the order ID;
 	SelectOrderPreimage = `SELECT preimage FROM %s WHERE oid = $1;`
 
@@ -91,7 +91,29 @@ func createMarketTables(db *sql.DB, marketName string) error {
 		}
 	}
 
-	return nil
+	// Create the activity summaries table.
+	if _, err := createTableStmt(db, internal.CreateActivityTable, marketUID, activityTableName); err != nil {
+		return err
+	}
+
+	// Index the epoch end stamps so the activity backfill does not scan the
+	// entire orders and matches tables for every window.
+	for _, c := range createActivityIndexStatements {
+		err := createIndexStmt(db, c.stmt, c.name+"_epoch_end_idx", marketUID+"."+c.name)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the table for the book states used to replay epoch matching.
+	if _, err := createTableStmt(db, internal.CreateEpochBooksTable, marketUID, epochBooksTableName); err != nil {
+		return err
+	}
+
+	// Index the epoch end stamps of the books so pruning does not scan the
+	// entire table.
+	return createIndexStmt(db, internal.CreateOrdersEpochEndIndex, epochBooksTableName+"_epoch_end_idx",
+		marketUID+"."+epochBooksTableName)
 }
 
 // marketSchema replaces the special token symbol character '.' with the allowed
@@ -287,6 +287,24 @@ func (a *Archiver) UserMatches(aid account.AccountID, base, quote uint32) ([]*db
 	return userMatches(ctx, a.db, matchesTableName, aid, true)
 }
 
+// EpochMatches retrieves the trade and cancel matches made in an epoch.
+func (a *Archiver) EpochMatches(base, quote uint32, epochIdx, epochDur int64) ([]*db.MatchData, error) {
+	marketSchema, err := a.marketSchema(base, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(a.ctx, a.queryTimeout)
+	defer cancel()
+
+	stmt := fmt.Sprintf(internal.RetrieveEpochMatches, fullMatchesTableName(a.dbName, marketSchema))
+	rows, err := a.db.QueryContext(ctx, stmt, epochIdx, epochDur)
+	if err != nil {
+		return nil, err
+	}
+	return rowsToMatchData(rows, true)
+}
+
 func userMatches(ctx context.Context, dbe *sql.DB, tableName string, aid account.AccountID, includeInactive bool) ([]*db.MatchData, error) {
 	query := internal.RetrieveActiveUserMatches
 	if includeInactive {
@@ -50,6 +50,10 @@ type Config struct {
 
 	// MarketCfg specifies all of the markets that the Archiver should prepare.
 	MarketCfg []*dex.MarketInfo
+
+	// EpochBookRetention is how long the recorded epoch books are kept before
+	// they are pruned. Zero keeps them indefinitely.
+	EpochBookRetention time.Duration
 }
 
 // Some frequently used long-form table names.
@@ -70,6 +74,8 @@ type Archiver struct {
 	markets      map[string]*dex.MarketInfo
 	tables       archiverTables
 
+	epochBookRetention time.Duration
+
 	fatalMtx sync.RWMutex
 	fatal    chan struct{}
 	fatalErr error
@@ -84,6 +90,12 @@ func (a *Archiver) LastErr() error {
 	return a.fatalErr
 }
 
+// Ping checks that the database is reachable. Part of the db.DEXArchivist
+// interface.
+func (a *Archiver) Ping(ctx context.Context) error {
+	return a.db.PingContext(ctx)
+}
+
 // Fatal returns a nil or closed channel for select use. Use LastErr to get the
 // latest fatal error.
 func (a *Archiver) Fatal() <-chan struct{} {
@@ -156,7 +168,8 @@ func NewArchiverForRead(ctx context.Context, cfg *Config) (*Archiver, error) {
 			bonds:        fullTableName(cfg.DBName, publicSchema, bondsTableName),
 			prepaidBonds: fullTableName(cfg.DBName, publicSchema, prepaidBondsTableName),
 		},
-		fatal: make(chan struct{}),
+		epochBookRetention: cfg.EpochBookRetention,
+		fatal:              make(chan struct{}),
 	}, nil
 }
 
@@ -192,6 +205,10 @@ func NewArchiver(ctx context.Context, cfg *Config) (*Archiver, error) {
 			len(unbookedSells), len(unbookedBuys), staleMarket)
 	}
 
+	if archiver.epochBookRetention > 0 {
+		go archiver.pruneEpochBooksLoop()
+	}
+
 	return archiver, nil
 }
 
@@ -20,6 +20,7 @@ const (
 	accountsTableName     = "accounts"
 	bondsTableName        = "bonds"
 	prepaidBondsTableName = "prepaid_bonds"
+	adminActionsTableName = "admin_actions"
 
 	indexBondsOnAccountName  = "idx_bonds_on_acct"
 	indexBondsOnLockTimeName = "idx_bonds_on_locktime"
@@ -34,6 +35,8 @@ const (
 	cancelsActiveTableName   = "cancels_active"
 	epochReportsTableName    = "epoch_reports"
 	candlesTableName         = "candles"
+	activityTableName        = "activity"
+	epochBooksTableName      = "epoch_books"
 )
 
 type tableStmt struct {
@@ -44,6 +47,7 @@ type tableStmt struct {
 var createDEXTableStatements = []tableStmt{
 	{marketsTableName, internal.CreateMarketsTable},
 	{metaTableName, internal.CreateMetaTable},
+	{adminActionsTableName, internal.CreateAdminActionsTable},
 }
 
 var createAccountTableStatements = []tableStmt{
@@ -74,6 +78,17 @@ var createMarketTableStatements = []tableStmt{
 	{epochReportsTableName, internal.CreateEpochReportTable},
 }
 
+// createActivityIndexStatements index the market tables scanned by the
+// activity window queries, keyed by table name. The index names are the table
+// name with an "_epoch_end_idx" suffix.
+var createActivityIndexStatements = []tableStmt{
+	{ordersArchivedTableName, internal.CreateOrdersEpochEndIndex},
+	{ordersActiveTableName, internal.CreateOrdersEpochEndIndex},
+	{cancelsArchivedTableName, internal.CreateOrdersEpochEndIndex},
+	{cancelsActiveTableName, internal.CreateOrdersEpochEndIndex},
+	{matchesTableName, internal.CreateMatchesEpochEndIndex},
+}
+
 var tableMap = func() map[string]string {
 	m := make(map[string]string, len(createDEXTableStatements)+
 		len(createMarketTableStatements)+len(createAccountTableStatements))
@@ -123,6 +138,14 @@ func fullEpochReportsTableName(dbName, marketSchema string) string {
 	return dbName + "." + marketSchema + "." + epochReportsTableName
 }
 
+func fullEpochBooksTableName(dbName, marketSchema string) string {
+	return dbName + "." + marketSchema + "." + epochBooksTableName
+}
+
+func fullActivityTableName(dbName, marketSchema string) string {
+	return dbName + "." + marketSchema + "." + activityTableName
+}
+
 func fullCandlesTableName(dbName, marketSchema string, candleDur uint64) string {
 	const fiveMin = 5 * 60 * 1000
 	const oneHour = 60 * 60 * 1000
@@ -189,6 +212,10 @@ func prepareTables(ctx context.Context, db *sql.DB, mktConfig []*dex.MarketInfo)
 	if err = createAccountTables(db); err != nil {
 		return nil, err
 	}
+	// Prepare the admin audit log table.
+	if _, err = createTable(db, publicSchema, adminActionsTableName); err != nil {
+		return nil, fmt.Errorf("failed to create admin actions table: %w", err)
+	}
 	if !created {
 		// Attempt upgrade.
 		if err = upgradeDB(ctx, db); err != nil {
@@ -37,6 +37,43 @@ type EpochResults struct {
 	LowRate           uint64
 	StartRate         uint64
 	EndRate           uint64
+	// Book is the state of the book at the start of matching. It is only set
+	// for epochs with revealed orders.
+	Book *EpochBook
+}
+
+// EpochBook is the state of a market's book at the start of an epoch's
+// matching. Together with the epoch's revealed orders and their preimages (see
+// EpochAudit), it is the complete input to the epoch's matching, from which the
+// matching may be replayed.
+type EpochBook struct {
+	LotSize uint64
+	// Orders are the IDs of the booked orders, and Filled are the amounts of
+	// each that were filled before matching.
+	Orders []order.OrderID
+	Filled []uint64
+}
+
+// EpochAudit is the commit-reveal record of a matched epoch, from which the
+// epoch's commitment checksum, shuffle seed, and order shuffling may be
+// independently verified.
+type EpochAudit struct {
+	Idx       int64
+	Dur       int64
+	MatchTime int64
+	CSum      []byte
+	Seed      []byte
+	// Orders are the orders in the epoch queue, revealed and missed, sorted by
+	// commitment.
+	Orders []*EpochAuditOrder
+}
+
+// EpochAuditOrder is an order in an EpochAudit. Preimage is the zero value if
+// the order's preimage was not revealed.
+type EpochAuditOrder struct {
+	ID       order.OrderID
+	Commit   order.Commitment
+	Preimage order.Preimage
 }
 
 // OrderStatus is the current status of an order.
@@ -68,6 +105,9 @@ type DEXArchivist interface {
 	// unrecoverable error (disconnect, etc.).
 	LastErr() error
 
+	// Ping checks that the database is reachable.
+	Ping(ctx context.Context) error
+
 	// Fatal provides select semantics like Context.Done when there is a fatal
 	// backend error. Use LastErr to get the error.
 	Fatal() <-chan struct{}
@@ -78,6 +118,16 @@ type DEXArchivist interface {
 	// InsertEpoch stores the results of a newly-processed epoch.
 	InsertEpoch(ed *EpochResults) error
 
+	// EpochAudit retrieves the commit-reveal record of a matched epoch. An
+	// ArchiveError with code ErrUnknownEpoch is returned if the epoch has not
+	// been matched.
+	EpochAudit(base, quote uint32, epochIdx, epochDur int64) (*EpochAudit, error)
+
+	// EpochBook retrieves the recorded state of the book at the start of an
+	// epoch's matching. An ArchiveError with code ErrUnknownEpoch is returned
+	// if no book was recorded for the epoch.
+	EpochBook(base, quote uint32, epochIdx, epochDur int64) (*EpochBook, error)
+
 	// LastEpochRate gets the EndRate of the last EpochResults inserted for the
 	// market. If the database is empty, no error and a rate of zero are
 	// returned.
@@ -93,6 +143,78 @@ type DEXArchivist interface {
 	KeyIndexer
 	MatchArchiver
 	SwapArchiver
+	ActivityArchiver
+	AdminAuditor
+}
+
+// MarketActivity is a summary of a market's trading activity over a window of
+// time ending at EndStamp.
+type MarketActivity struct {
+	// EndStamp is the end of the window, in milliseconds. The window includes
+	// epochs that end after EndStamp - Duration and no later than EndStamp.
+	EndStamp uint64 `json:"endStamp"`
+	// Duration is the length of the window, in milliseconds.
+	Duration uint64 `json:"duration"`
+	// Orders is the number of trade orders received.
+	Orders uint64 `json:"orders"`
+	// Cancels is the number of cancel orders received.
+	Cancels uint64 `json:"cancels"`
+	// Matches is the number of trade matches made.
+	Matches uint64 `json:"matches"`
+	// MatchVolume is the matched quantity, in units of the base asset.
+	MatchVolume uint64 `json:"matchVolume"`
+	// QuoteVolume is the matched quantity, in units of the quote asset.
+	QuoteVolume uint64 `json:"quoteVolume"`
+	// Accounts is the number of unique accounts that placed orders or were
+	// matched.
+	Accounts uint64 `json:"accounts"`
+}
+
+// ActivityArchiver is the interface required for computing, storing, and
+// retrieving market activity summaries.
+type ActivityArchiver interface {
+	// ComputeMarketActivity summarizes the market's trading activity for
+	// epochs ending in the window (start, end], in milliseconds.
+	ComputeMarketActivity(base, quote uint32, start, end uint64) (*MarketActivity, error)
+	// InsertMarketActivity stores a market activity summary, replacing any
+	// existing summary with the same end stamp.
+	InsertMarketActivity(base, quote uint32, act *MarketActivity) error
+	// LastMarketActivityEndStamp is the end stamp of the most recent stored
+	// activity summary for the market, or zero if there are none.
+	LastMarketActivityEndStamp(base, quote uint32) (uint64, error)
+	// MarketActivity retrieves the market's stored activity summaries with
+	// end stamps after since, sorted by ascending end stamp.
+	MarketActivity(base, quote uint32, since uint64) ([]*MarketActivity, error)
+	// PruneMarketActivity deletes the market's activity summaries with end
+	// stamps before the specified time, returning the number deleted.
+	PruneMarketActivity(base, quote uint32, before uint64) (int64, error)
+}
+
+// AdminAction is an audit record of a request made to the admin server.
+type AdminAction struct {
+	// Stamp is the time of the request, in milliseconds.
+	Stamp uint64 `json:"stamp"`
+	// Actor identifies who made the request, e.g. the basic auth user name
+	// and remote address.
+	Actor string `json:"actor"`
+	// Action is the request method and route, e.g. "GET /market/{market}/suspend".
+	Action string `json:"action"`
+	// Params are the route and query parameters of the request, with the
+	// values of sensitive parameters redacted.
+	Params map[string]string `json:"params,omitempty"`
+	// Status is the HTTP status code of the response.
+	Status int `json:"status"`
+}
+
+// AdminAuditor is the interface required for storing and retrieving the
+// append-only audit log of admin actions.
+type AdminAuditor interface {
+	// InsertAdminAction appends an admin action to the audit log.
+	InsertAdminAction(act *AdminAction) error
+	// AdminActions retrieves the most recent n admin actions with stamps after
+	// since, sorted by ascending stamp. All such actions are returned if n is
+	// not positive.
+	AdminActions(since uint64, n int) ([]*AdminAction, error)
 }
 
 // OrderArchiver is the interface required for storage and retrieval of all
@@ -394,6 +516,7 @@ type MatchArchiver interface {
 	ForgiveMatchFail(mid order.MatchID) (bool, error)
 	AllActiveUserMatches(aid account.AccountID) ([]*MatchData, error)
 	MarketMatches(base, quote uint32) ([]*MatchDataWithCoins, error)
+	EpochMatches(base, quote uint32, epochIdx, epochDur int64) ([]*MatchData, error)
 	MarketMatchesStreaming(base, quote uint32, includeInactive bool, N int64, f func(*MatchDataWithCoins) error) (int, error)
 	MatchStatuses(aid account.AccountID, base, quote uint32, matchIDs []order.MatchID) ([]*MatchStatus, error)
 }
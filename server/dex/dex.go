@@ -6,6 +6,7 @@ package dex
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -25,11 +26,13 @@ import (
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/order"
 	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/analytics"
 	"decred.org/dcrdex/server/apidata"
 	"decred.org/dcrdex/server/asset"
 	"decred.org/dcrdex/server/auth"
 	"decred.org/dcrdex/server/coinlock"
 	"decred.org/dcrdex/server/comms"
+	"decred.org/dcrdex/server/consistency"
 	"decred.org/dcrdex/server/db"
 	"decred.org/dcrdex/server/db/driver/pg"
 	"decred.org/dcrdex/server/market"
@@ -67,46 +70,185 @@ type Asset struct {
 	BondConfs   uint32 `json:"bondConfs,omitempty"`
 	Disabled    bool   `json:"disabled"`
 	NodeRelayID string `json:"nodeRelayID,omitempty"`
+	// MaxSettlementMins is the moving average time, in minutes, from match to
+	// redemption of swaps on the asset's chain above which the operator is
+	// alerted. Zero disables the alert.
+	MaxSettlementMins uint32 `json:"maxSettlementMinutes,omitempty"`
+	// ReorgDepth is the number of confirmations beyond which a swap or coin
+	// on the asset's chain is considered irreversible. Swaps with fewer
+	// confirmations are re-evaluated on reorgs, and by the consistency
+	// checker. If zero, SwapConf is used. It may not be less than SwapConf.
+	ReorgDepth uint32 `json:"reorgDepth,omitempty"`
+	// DisplaySymbol is the symbol with which clients should display the
+	// asset, e.g. to distinguish tokens that share a ticker across chains. If
+	// empty, clients use the asset's conventional unit.
+	DisplaySymbol string `json:"displaySymbol,omitempty"`
+	// Aliases are additional names for the asset, e.g. of a wrapped variant.
+	// The display symbol and aliases may not be ambiguous with the names of
+	// other assets.
+	Aliases []string `json:"aliases,omitempty"`
 }
 
 // Market represents the markets specified in the Config file.
 type Market struct {
-	Base       string  `json:"base"`
-	Quote      string  `json:"quote"`
-	LotSize    uint64  `json:"lotSize"`
-	ParcelSize uint32  `json:"parcelSize"`
-	RateStep   uint64  `json:"rateStep"`
-	Duration   uint64  `json:"epochDuration"`
-	MBBuffer   float64 `json:"marketBuyBuffer"`
-	Disabled   bool    `json:"disabled"`
+	Base          string  `json:"base"`
+	Quote         string  `json:"quote"`
+	LotSize       uint64  `json:"lotSize"`
+	ParcelSize    uint32  `json:"parcelSize"`
+	RateStep      uint64  `json:"rateStep"`
+	Duration      uint64  `json:"epochDuration"`
+	MBBuffer      float64 `json:"marketBuyBuffer"`
+	MinOrderLots  uint32  `json:"minOrderLots,omitempty"`  // 0 for no minimum
+	MaxOpenOrders uint32  `json:"maxOpenOrders,omitempty"` // 0 for no limit
+	Disabled      bool    `json:"disabled"`
+	// TradingHours, if set, restricts trading to the scheduled hours. The
+	// market is suspended outside of the trading hours.
+	TradingHours *TradingHours `json:"tradingHours,omitempty"`
+	// MinOrderLifetimeSecs is how long a standing order must be on the market
+	// before it may be canceled, which deters spoofing with orders that are
+	// canceled before they can be matched. Zero means no minimum.
+	MinOrderLifetimeSecs uint64 `json:"minOrderLifetimeSecs,omitempty"`
+	// MaxOrderLifetimeSecs is how long a standing order may be on the market
+	// before it is revoked, so that stale orders do not linger on the book.
+	// The order's owner is not penalized. Zero means no maximum.
+	MaxOrderLifetimeSecs uint64 `json:"maxOrderLifetimeSecs,omitempty"`
+}
+
+// TradingHours is a market's trading schedule in the Config file.
+type TradingHours struct {
+	// Timezone is the IANA time zone name of the trading windows, e.g.
+	// "America/New_York". The default is UTC.
+	Timezone string           `json:"timezone"`
+	Windows  []*TradingWindow `json:"windows"`
+}
+
+// TradingWindow is a daily trading window in the Config file.
+type TradingWindow struct {
+	// Days are the days of the week the window opens, e.g. "mon" or "monday".
+	// If empty, the window opens every day.
+	Days []string `json:"days,omitempty"`
+	// Open and Close are 24-hour times of day, e.g. "09:30" and "16:00". A
+	// window that closes at or before its opening time closes the next day.
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// schedule parses the TradingHours into a *market.Schedule.
+func (th *TradingHours) schedule() (*market.Schedule, error) {
+	loc := time.UTC
+	if th.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(th.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", th.Timezone, err)
+		}
+	}
+	windows := make([]*market.TradingWindow, 0, len(th.Windows))
+	for _, w := range th.Windows {
+		openTime, err := parseTimeOfDay(w.Open)
+		if err != nil {
+			return nil, fmt.Errorf("invalid open time: %w", err)
+		}
+		closeTime, err := parseTimeOfDay(w.Close)
+		if err != nil {
+			return nil, fmt.Errorf("invalid close time: %w", err)
+		}
+		days := make([]time.Weekday, 0, len(w.Days))
+		for _, d := range w.Days {
+			day, err := parseWeekday(d)
+			if err != nil {
+				return nil, err
+			}
+			days = append(days, day)
+		}
+		windows = append(windows, &market.TradingWindow{
+			Days:  days,
+			Open:  openTime,
+			Close: closeTime,
+		})
+	}
+	return market.NewSchedule(loc, windows)
+}
+
+// parseTimeOfDay parses a 24-hour "hh:mm" time of day, up to "24:00", as an
+// offset from midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	var h, m int
+	if n, err := fmt.Sscanf(s, "%d:%d", &h, &m); err != nil || n != 2 {
+		return 0, fmt.Errorf("time of day %q is not in hh:mm format", s)
+	}
+	if h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("time of day %q out of range", s)
+	}
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || s == name[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day of the week %q", s)
+}
+
+// MirrorMarket is a read-only mirror of another DEX's market specified in the
+// Config file. The market's order book is displayed, but orders are not
+// accepted for it.
+type MirrorMarket struct {
+	// Source is the host and port of the other DEX.
+	Source string `json:"source"`
+	// Base and Quote are the BIP-44 symbols of the market's assets, which need
+	// not be configured on this DEX.
+	Base  string `json:"base"`
+	Quote string `json:"quote"`
+	// Cert is the path to the other DEX's TLS certificate, if it is not
+	// signed by a certificate authority.
+	Cert string `json:"cert,omitempty"`
+
+	baseID, quoteID uint32
 }
 
 // Config is a market and asset configuration file.
 type Config struct {
 	Markets []*Market         `json:"markets"`
 	Assets  map[string]*Asset `json:"assets"`
+	Mirrors []*MirrorMarket   `json:"mirrors,omitempty"`
+}
+
+// LoadedConfig is the validated market and asset configuration for a network,
+// as loaded from a Config file.
+type LoadedConfig struct {
+	Markets []*dex.MarketInfo
+	Assets  []*Asset
+	// Schedules are the trading schedules of any markets with trading hours,
+	// keyed by market name.
+	Schedules map[string]*market.Schedule
+	// Mirrors are the read-only mirrors of other DEXs' markets.
+	Mirrors []*MirrorMarket
 }
 
 // LoadConfig loads the Config from the specified file.
-func LoadConfig(net dex.Network, filePath string) ([]*dex.MarketInfo, []*Asset, error) {
+func LoadConfig(net dex.Network, filePath string) (*LoadedConfig, error) {
 	src, err := os.Open(filePath)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer src.Close()
 	return loadMarketConf(net, src)
 }
 
-func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset, error) {
+func loadMarketConf(net dex.Network, src io.Reader) (*LoadedConfig, error) {
 	settings, err := io.ReadAll(src)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	var conf Config
 	err = json.Unmarshal(settings, &conf)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	log.Debug("|-------------------- BEGIN parsed markets.json --------------------")
@@ -114,11 +256,11 @@ func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset
 	log.Debug("                  Base         Quote    LotSize     EpochDur")
 	for i, mktConf := range conf.Markets {
 		if mktConf.LotSize == 0 {
-			return nil, nil, fmt.Errorf("market (%s, %s) has NO lot size specified (was an asset setting)",
+			return nil, fmt.Errorf("market (%s, %s) has NO lot size specified (was an asset setting)",
 				mktConf.Base, mktConf.Quote)
 		}
 		if mktConf.RateStep == 0 {
-			return nil, nil, fmt.Errorf("market (%s, %s) has NO rate step specified (was an asset setting)",
+			return nil, fmt.Errorf("market (%s, %s) has NO rate step specified (was an asset setting)",
 				mktConf.Base, mktConf.Quote)
 		}
 		log.Debugf("Market %d: % 12s  % 12s   %6de8  % 8d ms",
@@ -130,11 +272,11 @@ func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset
 	log.Debug("             MaxFeeRate   SwapConf   Network")
 	for asset, assetConf := range conf.Assets {
 		if assetConf.LotSizeOLD > 0 {
-			return nil, nil, fmt.Errorf("asset %s has a lot size (%d) specified, "+
+			return nil, fmt.Errorf("asset %s has a lot size (%d) specified, "+
 				"but this is now a market setting", asset, assetConf.LotSizeOLD)
 		}
 		if assetConf.RateStepOLD > 0 {
-			return nil, nil, fmt.Errorf("asset %s has a rate step (%d) specified, "+
+			return nil, fmt.Errorf("asset %s has a rate step (%d) specified, "+
 				"but this is now a market setting", asset, assetConf.RateStepOLD)
 		}
 		log.Debugf("%-12s % 10d  % 9d % 9s", asset, assetConf.MaxFeeRate, assetConf.SwapConf, assetConf.Network)
@@ -151,7 +293,7 @@ func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset
 		}
 		network, err := dex.NetFromString(assetConf.Network)
 		if err != nil {
-			return nil, nil, fmt.Errorf("unrecognized network %s for asset %s",
+			return nil, fmt.Errorf("unrecognized network %s for asset %s",
 				assetConf.Network, assetName)
 		}
 		if net != network {
@@ -161,11 +303,11 @@ func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset
 		symbol := strings.ToLower(assetConf.Symbol)
 		assetID, found := dex.BipSymbolID(symbol)
 		if !found {
-			return nil, nil, fmt.Errorf("asset %q symbol %q unrecognized", assetName, assetConf.Symbol)
+			return nil, fmt.Errorf("asset %q symbol %q unrecognized", assetName, assetConf.Symbol)
 		}
 
 		if assetConf.MaxFeeRate == 0 {
-			return nil, nil, fmt.Errorf("max fee rate of 0 is invalid for asset %q", assetConf.Symbol)
+			return nil, fmt.Errorf("max fee rate of 0 is invalid for asset %q", assetConf.Symbol)
 		}
 
 		unused[assetID] = assetConf.Symbol
@@ -178,23 +320,24 @@ func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset
 	})
 
 	var markets []*dex.MarketInfo
+	schedules := make(map[string]*market.Schedule)
 	for _, mktConf := range conf.Markets {
 		if mktConf.Disabled {
 			continue
 		}
 		baseConf, ok := conf.Assets[mktConf.Base]
 		if !ok {
-			return nil, nil, fmt.Errorf("missing configuration for asset %s", mktConf.Base)
+			return nil, fmt.Errorf("missing configuration for asset %s", mktConf.Base)
 		}
 		if baseConf.Disabled {
-			return nil, nil, fmt.Errorf("required base asset %s is disabled", mktConf.Base)
+			return nil, fmt.Errorf("required base asset %s is disabled", mktConf.Base)
 		}
 		quoteConf, ok := conf.Assets[mktConf.Quote]
 		if !ok {
-			return nil, nil, fmt.Errorf("missing configuration for asset %s", mktConf.Quote)
+			return nil, fmt.Errorf("missing configuration for asset %s", mktConf.Quote)
 		}
 		if quoteConf.Disabled {
-			return nil, nil, fmt.Errorf("required quote asset %s is disabled", mktConf.Base)
+			return nil, fmt.Errorf("required quote asset %s is disabled", mktConf.Base)
 		}
 
 		baseID, _ := dex.BipSymbolID(baseConf.Symbol)
@@ -205,29 +348,29 @@ func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset
 
 		if is, parentID := asset.IsToken(baseID); is {
 			if _, found := assetMap[parentID]; !found {
-				return nil, nil, fmt.Errorf("parent asset %s not enabled for token %s", dex.BipIDSymbol(parentID), baseConf.Symbol)
+				return nil, fmt.Errorf("parent asset %s not enabled for token %s", dex.BipIDSymbol(parentID), baseConf.Symbol)
 			}
 			delete(unused, parentID)
 		}
 
 		if is, parentID := asset.IsToken(quoteID); is {
 			if _, found := assetMap[parentID]; !found {
-				return nil, nil, fmt.Errorf("parent asset %s not enabled for token %s", dex.BipIDSymbol(parentID), quoteConf.Symbol)
+				return nil, fmt.Errorf("parent asset %s not enabled for token %s", dex.BipIDSymbol(parentID), quoteConf.Symbol)
 			}
 			delete(unused, parentID)
 		}
 
 		baseNet, err := dex.NetFromString(baseConf.Network)
 		if err != nil {
-			return nil, nil, fmt.Errorf("unrecognized network %s", baseConf.Network)
+			return nil, fmt.Errorf("unrecognized network %s", baseConf.Network)
 		}
 		quoteNet, err := dex.NetFromString(quoteConf.Network)
 		if err != nil {
-			return nil, nil, fmt.Errorf("unrecognized network %s", quoteConf.Network)
+			return nil, fmt.Errorf("unrecognized network %s", quoteConf.Network)
 		}
 
 		if baseNet != quoteNet {
-			return nil, nil, fmt.Errorf("assets are for different networks (%s and %s)",
+			return nil, fmt.Errorf("assets are for different networks (%s and %s)",
 				baseConf.Network, quoteConf.Network)
 		}
 
@@ -236,13 +379,32 @@ func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset
 		}
 
 		if mktConf.ParcelSize == 0 {
-			return nil, nil, fmt.Errorf("parcel size cannot be zero")
+			return nil, fmt.Errorf("parcel size cannot be zero")
 		}
 
 		mkt, err := dex.NewMarketInfoFromSymbols(baseConf.Symbol, quoteConf.Symbol,
 			mktConf.LotSize, mktConf.RateStep, mktConf.Duration, mktConf.ParcelSize, mktConf.MBBuffer)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
+		}
+		mkt.MinOrderLots = mktConf.MinOrderLots
+		mkt.MaxOpenOrders = mktConf.MaxOpenOrders
+		mkt.MinOrderLifetime = time.Duration(mktConf.MinOrderLifetimeSecs) * time.Second
+		mkt.MaxOrderLifetime = time.Duration(mktConf.MaxOrderLifetimeSecs) * time.Second
+		if mkt.MaxOrderLifetime > 0 && mkt.MaxOrderLifetime <= mkt.MinOrderLifetime {
+			return nil, fmt.Errorf("max order lifetime %v for market %s is not longer than the min order lifetime %v",
+				mkt.MaxOrderLifetime, mkt.Name, mkt.MinOrderLifetime)
+		}
+		if epochLen := time.Duration(mkt.EpochDuration) * time.Millisecond; mkt.MaxOrderLifetime > 0 && mkt.MaxOrderLifetime < epochLen {
+			return nil, fmt.Errorf("max order lifetime %v for market %s is shorter than the epoch duration %v",
+				mkt.MaxOrderLifetime, mkt.Name, epochLen)
+		}
+		if mktConf.TradingHours != nil {
+			sched, err := mktConf.TradingHours.schedule()
+			if err != nil {
+				return nil, fmt.Errorf("invalid trading hours for market %s: %w", mkt.Name, err)
+			}
+			schedules[mkt.Name] = sched
 		}
 		markets = append(markets, mkt)
 	}
@@ -252,10 +414,49 @@ func loadMarketConf(net dex.Network, src io.Reader) ([]*dex.MarketInfo, []*Asset
 		for _, symbol := range unused {
 			symbols = append(symbols, symbol)
 		}
-		return nil, nil, fmt.Errorf("unused assets %+v", symbols)
+		return nil, fmt.Errorf("unused assets %+v", symbols)
 	}
 
-	return markets, assets, nil
+	mirrors := make([]*MirrorMarket, 0, len(conf.Mirrors))
+	mirrored := make(map[string]bool, len(conf.Mirrors))
+	for _, mirror := range conf.Mirrors {
+		if mirror.Source == "" {
+			return nil, fmt.Errorf("no source for mirror of market (%s, %s)", mirror.Base, mirror.Quote)
+		}
+		baseID, found := dex.BipSymbolID(strings.ToLower(mirror.Base))
+		if !found {
+			return nil, fmt.Errorf("mirrored base asset %q unrecognized", mirror.Base)
+		}
+		quoteID, found := dex.BipSymbolID(strings.ToLower(mirror.Quote))
+		if !found {
+			return nil, fmt.Errorf("mirrored quote asset %q unrecognized", mirror.Quote)
+		}
+		if baseID == quoteID {
+			return nil, fmt.Errorf("mirrored market has the same base and quote asset %s", mirror.Base)
+		}
+		name, err := dex.MarketName(baseID, quoteID)
+		if err != nil {
+			return nil, err
+		}
+		if mirrored[name] {
+			return nil, fmt.Errorf("market %s mirrored more than once", name)
+		}
+		for _, mkt := range markets {
+			if mkt.Name == name {
+				return nil, fmt.Errorf("market %s is both run and mirrored", name)
+			}
+		}
+		mirrored[name] = true
+		mirror.baseID, mirror.quoteID = baseID, quoteID
+		mirrors = append(mirrors, mirror)
+	}
+
+	return &LoadedConfig{
+		Markets:   markets,
+		Assets:    assets,
+		Schedules: schedules,
+		Mirrors:   mirrors,
+	}, nil
 }
 
 // DBConf groups the database configuration parameters.
@@ -266,6 +467,10 @@ type DBConf struct {
 	Host         string
 	Port         uint16
 	ShowPGConfig bool
+
+	// BookRetention is how long recorded epoch books are kept. Zero disables
+	// pruning.
+	BookRetention time.Duration
 }
 
 // ValidateConfigFile validates the market+assets configuration file.
@@ -288,10 +493,11 @@ func ValidateConfigFile(cfgPath string, net dex.Network, log dex.Logger) error {
 
 	log.Debugf("Loaded %d fiat rates from coinpaprika", len(fiatRates))
 
-	markets, assets, err := LoadConfig(net, cfgPath)
+	loaded, err := LoadConfig(net, cfgPath)
 	if err != nil {
 		return fmt.Errorf("error loading config file at %q: %w", cfgPath, err)
 	}
+	markets, assets := loaded.Markets, loaded.Assets
 
 	log.Debugf("Loaded %d markets and %d assets from configuration", len(markets), len(assets))
 
@@ -423,6 +629,43 @@ type DexConf struct {
 	CommsCfg         *RPCConfig
 	NoResumeSwaps    bool
 	NodeRelayAddr    string
+	// BondAssets are the symbols of the assets accepted for bonds. Each must
+	// be configured with a bond amount and confirmations. If empty, bonds are
+	// accepted in every asset so configured.
+	BondAssets []string
+	// CircuitBreaker configures the asset backend circuit breakers that
+	// suspend markets while an asset's backend is unhealthy. If nil, markets
+	// are not suspended automatically.
+	CircuitBreaker *asset.CircuitBreakerConfig
+	// ActivityRetention is how long market activity summaries are kept. If
+	// zero, analytics.DefaultRetention is used.
+	ActivityRetention time.Duration
+	// MaxCancelRatio is the maximum ratio of cancel orders to trade orders a
+	// user may submit over CancelRatioWindow before new trade orders are
+	// refused. Zero disables the limit.
+	MaxCancelRatio    float64
+	CancelRatioWindow time.Duration
+	// ConsistencyInterval is the time between checks of the recorded swap
+	// state against the asset blockchains. If zero,
+	// consistency.DefaultInterval is used. If negative, the checks are
+	// disabled.
+	ConsistencyInterval time.Duration
+	// ConsistencySampleRate is the fraction of the active and recent matches
+	// checked each interval. If zero, consistency.DefaultSampleRate is used.
+	ConsistencySampleRate float64
+	// Schedules are the trading hours of markets that are only open during
+	// certain hours, keyed by market name.
+	Schedules map[string]*market.Schedule
+	// Mirrors are the read-only mirrors of other DEXs' markets.
+	Mirrors []*MirrorMarket
+	// TradeTapeSize is the number of recent trades retained for each market's
+	// public trade tape. If zero, market.DefaultTradeTapeSize is used.
+	TradeTapeSize int
+	// SwapConcurrency and SwapQueueSize limit the Swapper's lookups of the
+	// client-reported transactions of each asset. If zero,
+	// swap.DefaultMaxConcurrentOps and swap.DefaultMaxQueuedOps are used.
+	SwapConcurrency int
+	SwapQueueSize   int
 }
 
 type signer struct {
@@ -463,9 +706,26 @@ type DEX struct {
 	bookRouter  *market.BookRouter
 	subsystems  []subsystem
 	server      *comms.Server
+	checker     *consistency.Checker // nil if disabled
 
 	configRespMtx sync.RWMutex
 	configResp    *configResponse
+
+	breakerMtx sync.Mutex
+	breakers   map[uint32]*asset.CircuitBreaker
+	// breakerSuspended are the markets suspended by a tripped circuit
+	// breaker, which will be resumed when the breakers reset.
+	breakerSuspended map[string]bool
+	// schedules are the trading hours of markets that are only open during
+	// certain hours. breakerMtx is held when opening and closing the markets.
+	schedules map[string]*market.Schedule
+	// mirrors are the read-only mirrors of other DEXs' markets, keyed by
+	// market name.
+	mirrors map[string]*market.Mirror
+
+	healthMtx        sync.Mutex
+	health           *HealthReport // cached, see Health
+	healthRefreshing bool
 }
 
 // configResponse is defined here to leave open the possibility for hot
@@ -535,6 +795,22 @@ func (cr *configResponse) setMktResume(name string, startEpoch uint64) (epochLen
 	return 0
 }
 
+// setMktParams sets the lot size and rate step of the named market, and returns
+// a copy of the updated market configuration.
+func (cr *configResponse) setMktParams(name string, lotSize, rateStep uint64) *msgjson.Market {
+	for _, mkt := range cr.configMsg.Markets {
+		if mkt.Name == name {
+			mkt.LotSize = lotSize
+			mkt.RateStep = rateStep
+			cr.remarshal()
+			mktCopy := *mkt
+			return &mktCopy
+		}
+	}
+	log.Errorf("Failed to update parameters for market %q", name)
+	return nil
+}
+
 func (cr *configResponse) remarshal() {
 	encResult, err := json.Marshal(cr.configMsg)
 	if err != nil {
@@ -559,6 +835,50 @@ func (dm *DEX) Stop() {
 	}
 }
 
+// Drain prepares the DEX for shutdown. New websocket connections and new trade
+// orders are refused immediately, and the running markets are suspended at the
+// end of their current epochs with their books persisted. Drain then waits for
+// the markets to stop and for active swaps to settle, returning when they have
+// or when the timeout expires, whichever comes first. Any swaps that are still
+// active will be resumed on restart. Stop should be called after Drain.
+func (dm *DEX) Drain(timeout time.Duration) {
+	log.Infof("Draining DEX. Waiting up to %v for active swaps to settle.", timeout)
+	dm.server.Drain()
+	dm.orderRouter.Drain()
+	for name, mkt := range dm.markets {
+		if !mkt.Running() {
+			continue
+		}
+		if _, err := dm.SuspendMarket(name, time.Now(), true); err != nil {
+			log.Warnf("Unable to suspend market %s: %v", name, err)
+		}
+	}
+
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		var running int
+		for _, mkt := range dm.markets {
+			if mkt.Running() {
+				running++
+			}
+		}
+		activeSwaps := dm.swapper.ActiveSwaps()
+		if running == 0 && activeSwaps == 0 {
+			log.Infof("DEX drained.")
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			log.Warnf("Drain timeout expired with %d markets running and %d active swaps.",
+				running, activeSwaps)
+			return
+		}
+	}
+}
+
 func marketSubSysName(name string) string {
 	return fmt.Sprintf("Market[%s]", name)
 }
@@ -569,10 +889,114 @@ func (dm *DEX) handleDEXConfig(any) (any, error) {
 	return dm.configResp.configEnc, nil
 }
 
+func (dm *DEX) handleSignedConfig(any) (any, error) {
+	sc := &msgjson.SignedConfig{
+		Config: dm.ConfigMsg(),
+		Stamp:  uint64(time.Now().UnixMilli()),
+	}
+	dm.authMgr.Sign(sc)
+	return sc, nil
+}
+
 func (dm *DEX) handleHealthFlag(any) (any, error) {
 	return dm.Healthy(), nil
 }
 
+// handleMirror is the handler for the HTTP 'mirror' route, which returns the
+// read-only copy of another DEX's order book for a mirrored market.
+func (dm *DEX) handleMirror(thing any) (any, error) {
+	req, ok := thing.(*msgjson.OrderBookSubscription)
+	if !ok {
+		return nil, fmt.Errorf("invalid mirror request type %T", thing)
+	}
+	name, err := dex.MarketName(req.Base, req.Quote)
+	if err != nil {
+		return nil, err
+	}
+	mirror := dm.mirrors[name]
+	if mirror == nil {
+		return nil, fmt.Errorf("market %s is not mirrored", name)
+	}
+	return mirror.Book(), nil
+}
+
+// handleCheckTx is the handler for the non-authenticated 'check_tx' route.
+// Clients use this route to check that the node for an asset would accept a
+// transaction before broadcasting it. Only assets with a backend that
+// implements asset.TxChecker are supported. The dcr backend does not, since
+// dcrd has no testmempoolaccept equivalent.
+func (dm *DEX) handleCheckTx(conn comms.Link, msg *msgjson.Message) *msgjson.Error {
+	req := new(msgjson.CheckTxRequest)
+	if err := msg.Unmarshal(req); err != nil || len(req.Tx) == 0 {
+		return msgjson.NewError(msgjson.RPCParseError, "error parsing check_tx request")
+	}
+	a := dm.assets[req.AssetID]
+	if a == nil {
+		return msgjson.NewError(msgjson.InvalidRequestError, "unknown asset %d", req.AssetID)
+	}
+	checker, is := a.Backend.(asset.TxChecker)
+	if !is {
+		return msgjson.NewError(msgjson.RouteUnavailableError, "transaction checks not supported for %s", a.Symbol)
+	}
+	accepted, reason, err := checker.CheckTransaction(req.Tx)
+	if errors.Is(err, asset.ErrTxCheckUnsupported) {
+		return msgjson.NewError(msgjson.RouteUnavailableError, "transaction checks not supported for %s", a.Symbol)
+	}
+	if err != nil {
+		log.Errorf("Error checking %s transaction: %v", a.Symbol, err)
+		return msgjson.NewError(msgjson.RPCInternalError, "error checking %s transaction", a.Symbol)
+	}
+	resp, err := msgjson.NewResponse(msg.ID, &msgjson.CheckTxResult{
+		Accepted: accepted,
+		Reason:   reason,
+	}, nil)
+	if err != nil {
+		log.Errorf("failed to encode check_tx response: %v", err)
+		return msgjson.NewError(msgjson.RPCInternalError, "internal error")
+	}
+	if err := conn.Send(resp); err != nil {
+		log.Debugf("error sending check_tx response: %v", err)
+	}
+	return nil
+}
+
+// handleValidateRedemption is the handler for the non-authenticated
+// 'validate_redemption' route. Clients use this route to check that a
+// redemption of a swap contract would be accepted before broadcasting it. Only
+// assets with a backend that implements asset.RedemptionValidator are
+// supported.
+func (dm *DEX) handleValidateRedemption(conn comms.Link, msg *msgjson.Message) *msgjson.Error {
+	req := new(msgjson.ValidateRedemptionRequest)
+	if err := msg.Unmarshal(req); err != nil || len(req.Contract) == 0 || len(req.Secret) == 0 {
+		return msgjson.NewError(msgjson.RPCParseError, "error parsing validate_redemption request")
+	}
+	a := dm.assets[req.AssetID]
+	if a == nil {
+		return msgjson.NewError(msgjson.InvalidRequestError, "unknown asset %d", req.AssetID)
+	}
+	validator, is := a.Backend.(asset.RedemptionValidator)
+	if !is {
+		return msgjson.NewError(msgjson.RouteUnavailableError, "redemption validation not supported for %s", a.Symbol)
+	}
+	valid, reason, err := validator.ValidateRedemption(req.Contract, req.Secret)
+	if err != nil {
+		log.Errorf("Error validating %s redemption: %v", a.Symbol, err)
+		return msgjson.NewError(msgjson.RPCInternalError, "error validating %s redemption", a.Symbol)
+	}
+	resp, err := msgjson.NewResponse(msg.ID, &msgjson.ValidateRedemptionResult{
+		Valid:  valid,
+		Reason: reason,
+	}, nil)
+	if err != nil {
+		log.Errorf("failed to encode validate_redemption response: %v", err)
+		return msgjson.NewError(msgjson.RPCInternalError, "internal error")
+	}
+	if err := conn.Send(resp); err != nil {
+		log.Debugf("error sending validate_redemption response: %v", err)
+	}
+	return nil
+}
+
 // FeeCoiner describes a type that can check a transaction output, namely a fee
 // payment, for a particular asset.
 type FeeCoiner interface {
@@ -682,6 +1106,8 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		ShowPGConfig: cfg.DBConf.ShowPGConfig,
 		QueryTimeout: 20 * time.Minute,
 		MarketCfg:    cfg.Markets,
+
+		EpochBookRetention: cfg.DBConf.BookRetention,
 	}
 	// After DEX construction, the storage subsystem should be stopped
 	// gracefully with its Close method, and in coordination with other
@@ -749,10 +1175,19 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	cfgAssets := make([]*msgjson.Asset, 0, len(cfg.Assets))
 	assetLogger := cfg.LogBackend.Logger("ASSET")
 	txDataSources := make(map[uint32]auth.TxDataSource)
+	reorgDepths := make(map[uint32]uint32)
 	feeMgr := NewFeeManager()
 	addAsset := func(assetID uint32, assetConf *Asset) error {
 		symbol := strings.ToLower(assetConf.Symbol)
 
+		reorgDepth := assetConf.ReorgDepth
+		if reorgDepth == 0 {
+			reorgDepth = assetConf.SwapConf
+		} else if reorgDepth < assetConf.SwapConf {
+			return fmt.Errorf("asset %q reorg depth %d is less than swap confs %d",
+				symbol, reorgDepth, assetConf.SwapConf)
+		}
+
 		assetVer, err := asset.Version(assetID)
 		if err != nil {
 			return fmt.Errorf("failed to retrieve asset %q version: %w", symbol, err)
@@ -832,18 +1267,32 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 				MaxFeeRate: assetConf.MaxFeeRate,
 				SwapConf:   assetConf.SwapConf,
 				UnitInfo:   unitInfo,
+
+				DisplaySymbol: assetConf.DisplaySymbol,
+				Aliases:       assetConf.Aliases,
 			},
 			Backend: be,
 		}
 
 		backedAssets[assetID] = ba
 		lockableAssets[assetID] = &swap.SwapperAsset{
-			BackedAsset: ba,
-			Locker:      coinLocker,
+			BackedAsset:       ba,
+			Locker:            coinLocker,
+			MaxSettlementTime: time.Duration(assetConf.MaxSettlementMins) * time.Minute,
+			ReorgDepth:        reorgDepth,
 		}
+		reorgDepths[assetID] = reorgDepth
 		feeMgr.AddFetcher(ba)
 
 		// Prepare assets portion of config response.
+		var versions []uint32
+		if vb, is := be.(asset.VersionedBackend); is {
+			versions = vb.SupportedVersions()
+		}
+		var feeAssetID *uint32
+		if id := asset.FeeAsset(assetID); id != assetID {
+			feeAssetID = &id
+		}
 		cfgAssets = append(cfgAssets, &msgjson.Asset{
 			Symbol:     assetConf.Symbol,
 			ID:         assetID,
@@ -851,6 +1300,12 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 			MaxFeeRate: assetConf.MaxFeeRate,
 			SwapConf:   uint16(assetConf.SwapConf),
 			UnitInfo:   unitInfo,
+			DustLimit:  be.DustLimit(assetConf.MaxFeeRate),
+			Versions:   versions,
+			FeeAssetID: feeAssetID,
+
+			DisplaySymbol: assetConf.DisplaySymbol,
+			Aliases:       assetConf.Aliases,
 		})
 
 		txDataSources[assetID] = be.TxData
@@ -877,6 +1332,35 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		}
 	}
 
+	displayAssets := make([]*dex.Asset, 0, len(backedAssets))
+	for _, ba := range backedAssets {
+		displayAssets = append(displayAssets, &ba.Asset)
+	}
+	if err := dex.ValidateDisplaySymbols(displayAssets); err != nil {
+		return nil, err
+	}
+
+	backed := func(symbol string) bool {
+		for _, ba := range backedAssets {
+			if ba.Symbol == symbol {
+				return true
+			}
+		}
+		return false
+	}
+	bondAssets, err = auth.SelectBondAssets(bondAssets, backed, cfg.BondAssets)
+	if err != nil {
+		return nil, err
+	}
+	for assetID := range bonders {
+		if _, accepted := bondAssets[dex.BipIDSymbol(assetID)]; !accepted {
+			delete(bonders, assetID)
+		}
+	}
+	if len(cfg.BondAssets) > 0 {
+		log.Infof("Bonds limited to %s", strings.Join(cfg.BondAssets, ", "))
+	}
+
 	for _, mkt := range cfg.Markets {
 		mkt.Name = strings.ToLower(mkt.Name)
 	}
@@ -927,19 +1411,21 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	dataAPI := apidata.NewDataAPI(storage, server.RegisterHTTP)
 
 	authCfg := auth.Config{
-		Storage:          storage,
-		Signer:           signer{cfg.DEXPrivKey},
-		BondAssets:       bondAssets,
-		BondTxParser:     bondTxParser,
-		BondChecker:      bondChecker,
-		BondExpiry:       uint64(dex.BondExpiry(cfg.Network)),
-		UserUnbooker:     userUnbookFun,
-		MiaUserTimeout:   cfg.BroadcastTimeout,
-		CancelThreshold:  cfg.CancelThreshold,
-		FreeCancels:      cfg.FreeCancels,
-		PenaltyThreshold: cfg.PenaltyThreshold,
-		TxDataSources:    txDataSources,
-		Route:            server.Route,
+		Storage:           storage,
+		Signer:            signer{cfg.DEXPrivKey},
+		BondAssets:        bondAssets,
+		BondTxParser:      bondTxParser,
+		BondChecker:       bondChecker,
+		BondExpiry:        uint64(dex.BondExpiry(cfg.Network)),
+		UserUnbooker:      userUnbookFun,
+		MiaUserTimeout:    cfg.BroadcastTimeout,
+		CancelThreshold:   cfg.CancelThreshold,
+		FreeCancels:       cfg.FreeCancels,
+		MaxCancelRatio:    cfg.MaxCancelRatio,
+		CancelRatioWindow: cfg.CancelRatioWindow,
+		PenaltyThreshold:  cfg.PenaltyThreshold,
+		TxDataSources:     txDataSources,
+		Route:             server.Route,
 	}
 
 	authMgr := auth.NewAuthManager(&authCfg)
@@ -949,6 +1435,9 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 	if authCfg.FreeCancels {
 		log.Infof("Cancellations are NOT COUNTED (the cancellation rate threshold is ignored).")
 	}
+	if cfg.MaxCancelRatio > 0 {
+		log.Infof("Maximum cancellation ratio %f", cfg.MaxCancelRatio)
+	}
 	log.Infof("Penalty threshold is %v", cfg.PenaltyThreshold)
 
 	// Create a swapDone dispatcher for the Swapper.
@@ -972,6 +1461,8 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		LockTimeMaker:    dex.LockTimeMaker(cfg.Network),
 		SwapDone:         swapDone,
 		NoResume:         cfg.NoResumeSwaps,
+		MaxConcurrentOps: cfg.SwapConcurrency,
+		MaxQueuedOps:     cfg.SwapQueueSize,
 		// TODO: set the AllowPartialRestore bool to allow startup with a
 		// missing asset backend if necessary in an emergency.
 	}
@@ -1011,6 +1502,7 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 
 	// Markets
 	var orderRouter *market.OrderRouter
+	var dexMgr *DEX // for market callbacks, which are not used until the markets run
 	usersWithOrders := make(map[account.AccountID]struct{})
 	for _, mktInf := range cfg.Markets {
 		// nilness of the coin locker signals account-based asset.
@@ -1044,6 +1536,9 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 				return orderRouter.CheckParcelLimit(user, mktInf.Name, calcParcels)
 			},
 			MinimumRate: minRate,
+			ParamsChanged: func(lotSize, rateStep uint64) {
+				dexMgr.marketParamsChanged(mktInf.Name, lotSize, rateStep)
+			},
 		})
 		if err != nil {
 			return nil, fmt.Errorf("NewMarket failed: %w", err)
@@ -1095,14 +1590,18 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 			EpochLen:        mkt.EpochDuration(),
 			MarketBuyBuffer: mkt.MarketBuyBuffer(),
 			ParcelSize:      mkt.ParcelSize(),
+			MinOrderLots:    uint32(mkt.MinOrderLots()),
+			MaxOpenOrders:   mkt.MaxOpenOrders(),
 			MarketStatus: msgjson.MarketStatus{
 				StartEpoch: uint64(startEpochIdx),
 			},
+			MinOrderLifetime: uint64(mkt.MinOrderLifetime().Milliseconds()),
+			MaxOrderLifetime: uint64(mkt.MaxOrderLifetime().Milliseconds()),
 		})
 	}
 
 	// Book router
-	bookRouter := market.NewBookRouter(bookSources, feeMgr, server.Route)
+	bookRouter := market.NewBookRouter(bookSources, feeMgr, cfg.TradeTapeSize, server.MaxMessageSize(), server.Route)
 	startSubSys("BookRouter", bookRouter)
 
 	// The data API gets the order book from the book router.
@@ -1113,6 +1612,32 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		startSubSys(marketSubSysName(name), mkt)
 	}
 
+	// Read-only mirrors of other DEXs' markets. loadMarketConf ensures that
+	// mirrored markets are not also run.
+	mirrors := make(map[string]*market.Mirror, len(cfg.Mirrors))
+	for _, mirrorCfg := range cfg.Mirrors {
+		var cert []byte
+		if mirrorCfg.Cert != "" {
+			if cert, err = os.ReadFile(mirrorCfg.Cert); err != nil {
+				return nil, fmt.Errorf("error reading certificate for mirror source %s: %w", mirrorCfg.Source, err)
+			}
+		}
+		feed, err := market.NewWsMirrorFeed(mirrorCfg.Source, cert, mirrorCfg.baseID, mirrorCfg.quoteID)
+		if err != nil {
+			return nil, fmt.Errorf("error creating feed for mirror source %s: %w", mirrorCfg.Source, err)
+		}
+		mirror, err := market.NewMirror(&market.MirrorConfig{
+			Source: mirrorCfg.Source,
+			Base:   mirrorCfg.baseID,
+			Quote:  mirrorCfg.quoteID,
+			Feed:   feed,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("NewMirror failed: %w", err)
+		}
+		mirrors[mirror.Name()] = mirror
+	}
+
 	// Order router
 	orderRouter = market.NewOrderRouter(&market.OrderRouterConfig{
 		Assets:       backedAssets,
@@ -1121,9 +1646,49 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		FeeSource:    feeMgr,
 		DEXBalancer:  dexBalancer,
 		MatchSwapper: swapper,
+		Schedules:    cfg.Schedules,
+		Mirrors:      mirrors,
 	})
 	startSubSys("OrderRouter", orderRouter)
 
+	for name, mirror := range mirrors {
+		startSubSys(fmt.Sprintf("Mirror[%s]", name), mirror)
+	}
+
+	// Market activity analytics.
+	aggregator, err := analytics.NewAggregator(&analytics.Config{
+		DB:        storage,
+		Markets:   cfg.Markets,
+		Retention: cfg.ActivityRetention,
+		Logger:    cfg.LogBackend.Logger("ANLY"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("NewAggregator failed: %w", err)
+	}
+	startSubSys("Analytics", aggregator)
+
+	// Swap state consistency checks.
+	var checker *consistency.Checker
+	if cfg.ConsistencyInterval >= 0 {
+		txSources := make(map[uint32]consistency.TxSource, len(backedAssets))
+		for assetID, ba := range backedAssets {
+			txSources[assetID] = ba.Backend
+		}
+		checker, err = consistency.NewChecker(&consistency.Config{
+			DB:          storage,
+			Markets:     cfg.Markets,
+			Backends:    txSources,
+			Interval:    cfg.ConsistencyInterval,
+			SampleRate:  cfg.ConsistencySampleRate,
+			ReorgDepths: reorgDepths,
+			Logger:      cfg.LogBackend.Logger("CNST"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("NewChecker failed: %w", err)
+		}
+		startSubSys("Consistency", checker)
+	}
+
 	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
@@ -1133,7 +1698,7 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		return nil, err
 	}
 
-	dexMgr := &DEX{
+	dexMgr = &DEX{
 		network:     cfg.Network,
 		markets:     markets,
 		assets:      lockableAssets,
@@ -1145,10 +1710,46 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		subsystems:  subsystems,
 		server:      server,
 		configResp:  cfgResp,
+		checker:     checker,
+
+		breakers:         make(map[uint32]*asset.CircuitBreaker),
+		breakerSuspended: make(map[string]bool),
+		schedules:        cfg.Schedules,
+		mirrors:          mirrors,
+	}
+
+	if cfg.CircuitBreaker != nil {
+		for _, mkt := range markets {
+			for _, assetID := range []uint32{mkt.Base(), mkt.Quote()} {
+				if dexMgr.breakers[assetID] != nil {
+					continue
+				}
+				ba := backedAssets[assetID]
+				cb := asset.NewCircuitBreaker(assetID, ba.Backend, cfg.CircuitBreaker,
+					dexMgr.assetBreakerTripped, dexMgr.assetBreakerReset)
+				dexMgr.breakers[assetID] = cb
+				startSubSys(fmt.Sprintf("CircuitBreaker[%s]", ba.Symbol), cb)
+			}
+		}
+		dexMgr.subsystems = subsystems
+	}
+
+	if len(cfg.Schedules) > 0 {
+		for name := range cfg.Schedules {
+			if markets[name] == nil {
+				return nil, fmt.Errorf("trading hours specified for unknown market %s", name)
+			}
+		}
+		startSubSys("Scheduler", market.NewScheduler(cfg.Schedules, dexMgr.scheduledOpen, dexMgr.scheduledClose))
+		dexMgr.subsystems = subsystems
 	}
 
 	server.RegisterHTTP(msgjson.ConfigRoute, dexMgr.handleDEXConfig)
+	server.RegisterHTTP(msgjson.SignedConfigRoute, dexMgr.handleSignedConfig)
 	server.RegisterHTTP(msgjson.HealthRoute, dexMgr.handleHealthFlag)
+	server.Route(msgjson.CheckTxRoute, dexMgr.handleCheckTx)
+	server.Route(msgjson.ValidateRedemptionRoute, dexMgr.handleValidateRedemption)
+	server.RegisterHTTP(msgjson.MirrorRoute, dexMgr.handleMirror)
 
 	mux := server.Mux()
 
@@ -1159,13 +1760,20 @@ func NewDEX(ctx context.Context, cfg *DexConf) (*DEX, error) {
 		}
 		rr.Use(server.LimitRate)
 		rr.Get("/config", server.NewRouteHandler(msgjson.ConfigRoute))
+		rr.Get("/signedconfig", server.NewRouteHandler(msgjson.SignedConfigRoute))
 		rr.Get("/healthy", server.NewRouteHandler(msgjson.HealthRoute))
 		rr.Get("/spots", server.NewRouteHandler(msgjson.SpotsRoute))
 		rr.With(candleParamsParser).Get("/candles/{baseSymbol}/{quoteSymbol}/{binSize}", server.NewRouteHandler(msgjson.CandlesRoute))
 		rr.With(candleParamsParser).Get("/candles/{baseSymbol}/{quoteSymbol}/{binSize}/{count}", server.NewRouteHandler(msgjson.CandlesRoute))
 		rr.With(orderBookParamsParser).Get("/orderbook/{baseSymbol}/{quoteSymbol}", server.NewRouteHandler(msgjson.OrderBookRoute))
+		rr.With(epochAuditParamsParser).Get("/epochaudit/{baseSymbol}/{quoteSymbol}/{epoch}", server.NewRouteHandler(msgjson.EpochAuditRoute))
+		rr.With(orderBookParamsParser).Get("/mirror/{baseSymbol}/{quoteSymbol}", server.NewRouteHandler(msgjson.MirrorRoute))
 	})
 
+	// The health endpoint is not subject to the data API's rate limits or
+	// disabling, since it is intended for load balancer health checks.
+	mux.Get("/health", dexMgr.handleHealth)
+
 	startSubSys("Comms Server", server)
 
 	ready = true // don't shut down on return
@@ -1278,7 +1886,7 @@ func (dm *DEX) SuspendMarket(name string, tSusp time.Time, persistBooks bool) (s
 	note, errMsg := msgjson.NewNotification(msgjson.SuspensionRoute, msgjson.TradeSuspension{
 		MarketID:    name,
 		FinalEpoch:  uint64(suspEpoch.Idx),
-		SuspendTime: uint64(suspEpoch.End.UnixMilli()),
+		SuspendTime: dex.NewStamp(suspEpoch.End),
 		Persist:     persistBooks,
 	})
 	if errMsg != nil {
@@ -1350,7 +1958,7 @@ func (dm *DEX) ResumeMarket(name string, asSoonAs time.Time) (startEpoch int64,
 	// Broadcast a TradeResumption notification to all connected clients.
 	note, errMsg := msgjson.NewNotification(msgjson.ResumptionRoute, msgjson.TradeResumption{
 		MarketID:   name,
-		ResumeTime: uint64(startTimeMS),
+		ResumeTime: dex.Stamp(startTimeMS),
 		StartEpoch: uint64(startEpoch),
 	})
 	if errMsg != nil {
@@ -1363,6 +1971,93 @@ func (dm *DEX) ResumeMarket(name string, asSoonAs time.Time) (startEpoch int64,
 	return
 }
 
+// RetuneMarket changes a market's lot size and rate step, starting with the
+// epoch following the active epoch, the index of which is returned. Booked
+// orders that are incompatible with the new parameters are revoked when the
+// change takes effect, which is also when the config response is updated and
+// connected clients are sent the new market configuration. The new lot size
+// must be above the base asset's minimum lot size. The change is not
+// persisted, so the markets configuration file should also be updated.
+func (dm *DEX) RetuneMarket(base, quote uint32, lotSize, rateStep uint64) (epochIdx int64, err error) {
+	name, err := dex.MarketName(base, quote)
+	if err != nil {
+		return 0, err
+	}
+	mkt := dm.markets[name]
+	if mkt == nil {
+		return 0, fmt.Errorf("unknown market %s", name)
+	}
+	b, q := dm.assets[base], dm.assets[quote]
+	if baseMinLotSize, _, _ := asset.Minimums(base, b.Asset.MaxFeeRate); lotSize < baseMinLotSize {
+		return 0, fmt.Errorf("lot size %d is below the %s minimum of %d", lotSize, b.Symbol, baseMinLotSize)
+	}
+	quoteMinLotSize, _, _ := asset.Minimums(quote, q.Asset.MaxFeeRate)
+	minRate := calc.MinimumMarketRate(lotSize, quoteMinLotSize)
+
+	if epochIdx, err = mkt.Retune(lotSize, rateStep, minRate); err != nil {
+		return 0, err
+	}
+
+	log.Warnf("Market %s will use lot size %d and rate step %d starting with epoch %d. "+
+		"Update the markets configuration file to persist the change.", name, lotSize, rateStep, epochIdx)
+	return epochIdx, nil
+}
+
+// marketParamsChanged updates the config response with a market's new lot size
+// and rate step when a retune takes effect, and sends the updated market
+// configuration to all connected clients.
+func (dm *DEX) marketParamsChanged(name string, lotSize, rateStep uint64) {
+	dm.configRespMtx.Lock()
+	mktCfg := dm.configResp.setMktParams(name, lotSize, rateStep)
+	dm.configRespMtx.Unlock()
+	if mktCfg == nil {
+		return
+	}
+
+	note, err := msgjson.NewNotification(msgjson.MarketConfigRoute, mktCfg)
+	if err != nil {
+		log.Errorf("Failed to create market config notification: %v", err)
+		return
+	}
+	dm.server.Broadcast(note)
+}
+
+// MarketActivity returns the market's activity summaries for windows ending
+// after the specified time.
+func (dm *DEX) MarketActivity(base, quote uint32, since time.Time) ([]*db.MarketActivity, error) {
+	return dm.storage.MarketActivity(base, quote, uint64(since.UnixMilli()))
+}
+
+// ReplayEpoch replays the matching of a market epoch from the recorded book
+// and epoch queue, and compares the result with the recorded matches.
+func (dm *DEX) ReplayEpoch(base, quote uint32, epochIdx, epochDur int64) (*market.EpochReplay, error) {
+	return market.ReplayEpoch(dm.storage, base, quote, epochIdx, epochDur)
+}
+
+// RecordAdminAction appends an admin action to the audit log.
+func (dm *DEX) RecordAdminAction(act *db.AdminAction) error {
+	return dm.storage.InsertAdminAction(act)
+}
+
+// AdminActions retrieves the most recent n admin actions recorded after the
+// specified time. All such actions are returned if n is not positive.
+func (dm *DEX) AdminActions(since time.Time, n int) ([]*db.AdminAction, error) {
+	var sinceMs uint64
+	if !since.IsZero() {
+		sinceMs = uint64(since.UnixMilli())
+	}
+	return dm.storage.AdminActions(sinceMs, n)
+}
+
+// ConsistencyReport returns a summary of the swap state consistency checks, or
+// nil if the checks are disabled.
+func (dm *DEX) ConsistencyReport() *consistency.Report {
+	if dm.checker == nil {
+		return nil
+	}
+	return dm.checker.Report()
+}
+
 // AccountInfo returns data for an account.
 func (dm *DEX) AccountInfo(aid account.AccountID) (*db.Account, error) {
 	// TODO: consider asking the auth manager for account info, including tier.
@@ -1370,6 +2065,24 @@ func (dm *DEX) AccountInfo(aid account.AccountID) (*db.Account, error) {
 	return dm.storage.AccountInfo(aid)
 }
 
+// MatchState returns the state of an active match for operator inspection.
+func (dm *DEX) MatchState(mid order.MatchID) (*swap.MatchState, error) {
+	return dm.swapper.MatchState(mid)
+}
+
+// ReconcileRefundedMatch revokes an active match whose swaps were refunded,
+// after verifying the refunds on-chain. This is a last resort for stuck
+// matches.
+func (dm *DEX) ReconcileRefundedMatch(mid order.MatchID, makerRefund, takerRefund []byte) error {
+	return dm.swapper.ReconcileRefundedMatch(mid, makerRefund, takerRefund)
+}
+
+// CancelMatch revokes an active match before any swaps are broadcast. This is
+// a last resort for stuck matches.
+func (dm *DEX) CancelMatch(mid order.MatchID) error {
+	return dm.swapper.CancelMatch(mid)
+}
+
 // ForgiveMatchFail forgives a user for a specific match failure, potentially
 // allowing them to resume trading if their score becomes passing.
 func (dm *DEX) ForgiveMatchFail(aid account.AccountID, mid order.MatchID) (forgiven, unbanned bool, err error) {
@@ -1398,6 +2111,12 @@ func (dm *DEX) NotifyAll(msg *msgjson.Message) {
 	dm.server.Broadcast(msg)
 }
 
+// Announce broadcasts an operator announcement to all connected clients. The
+// announcement is also sent to clients that connect before it expires.
+func (dm *DEX) Announce(ann *msgjson.Announcement) error {
+	return dm.server.Announce(ann)
+}
+
 // BookOrders returns booked orders for market with base and quote.
 func (dm *DEX) BookOrders(base, quote uint32) ([]*order.LimitOrder, error) {
 	return dm.storage.BookOrders(base, quote)
@@ -1422,6 +2141,83 @@ func (dm *DEX) Healthy() bool {
 	return true
 }
 
+// assetBreakerTripped is called when an asset backend's circuit breaker trips.
+// Any running markets for the asset are suspended with their books persisted.
+// Clients are notified of the suspension by SuspendMarket.
+func (dm *DEX) assetBreakerTripped(assetID uint32, err error) {
+	dm.breakerMtx.Lock()
+	defer dm.breakerMtx.Unlock()
+	log.Warnf("Circuit breaker tripped for %s backend: %v", dex.BipIDSymbol(assetID), err)
+	for name, mkt := range dm.markets {
+		if (mkt.Base() != assetID && mkt.Quote() != assetID) || !mkt.Running() {
+			continue
+		}
+		if _, err := dm.SuspendMarket(name, time.Now(), true); err != nil {
+			log.Errorf("Unable to suspend market %s: %v", name, err)
+			continue
+		}
+		log.Warnf("Market %s suspended because the %s backend is unhealthy.",
+			name, dex.BipIDSymbol(assetID))
+		dm.breakerSuspended[name] = true
+	}
+}
+
+// assetBreakerReset is called when an asset backend's circuit breaker resets.
+// Markets that were suspended by a circuit breaker are resumed if the breakers
+// for both of the market's assets are reset.
+func (dm *DEX) assetBreakerReset(assetID uint32) {
+	dm.breakerMtx.Lock()
+	defer dm.breakerMtx.Unlock()
+	log.Infof("Circuit breaker reset for %s backend.", dex.BipIDSymbol(assetID))
+	for name := range dm.breakerSuspended {
+		mkt := dm.markets[name]
+		if mkt.Base() != assetID && mkt.Quote() != assetID {
+			continue
+		}
+		if dm.breakers[mkt.Base()].Tripped() || dm.breakers[mkt.Quote()].Tripped() {
+			continue
+		}
+		if sched := dm.schedules[name]; sched != nil && !sched.IsOpen(time.Now()) {
+			// The Scheduler will resume the market when it opens.
+			log.Infof("Market %s not resumed outside of trading hours.", name)
+			delete(dm.breakerSuspended, name)
+			continue
+		}
+		if _, _, err := dm.ResumeMarket(name, time.Now()); err != nil {
+			log.Errorf("Unable to resume market %s: %v", name, err)
+			continue
+		}
+		log.Infof("Market %s resumed.", name)
+		delete(dm.breakerSuspended, name)
+	}
+}
+
+// scheduledOpen is called by the Scheduler when a market's trading hours
+// begin. A market suspended by a circuit breaker is left for the breaker to
+// resume.
+func (dm *DEX) scheduledOpen(name string) error {
+	dm.breakerMtx.Lock()
+	defer dm.breakerMtx.Unlock()
+	if dm.breakerSuspended[name] {
+		return nil
+	}
+	_, _, err := dm.ResumeMarket(name, time.Now())
+	return err
+}
+
+// scheduledClose is called by the Scheduler when a market's trading hours end.
+// The market is suspended with its book persisted.
+func (dm *DEX) scheduledClose(name string, _ time.Time) error {
+	dm.breakerMtx.Lock()
+	defer dm.breakerMtx.Unlock()
+	if dm.breakerSuspended[name] {
+		// Remains suspended once the breaker resets.
+		return nil
+	}
+	_, err := dm.SuspendMarket(name, time.Now(), true)
+	return err
+}
+
 // MatchData embeds db.MatchData with decoded swap transaction coin IDs.
 type MatchData struct {
 	db.MatchData
@@ -1575,6 +2371,29 @@ func orderBookParamsParser(next http.Handler) http.Handler {
 	})
 }
 
+// epochAuditParamsParser is middleware for the /epochaudit route. Parses the
+// *msgjson.EpochAuditRequest from the URL parameters.
+func epochAuditParamsParser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		baseID, quoteID, errMsg := parseBaseQuoteIDs(r)
+		if errMsg != "" {
+			http.Error(w, errMsg, http.StatusBadRequest)
+			return
+		}
+		epoch, err := strconv.ParseUint(chi.URLParam(r, "epoch"), 10, 63)
+		if err != nil {
+			http.Error(w, "epoch unparseable", http.StatusBadRequest)
+			return
+		}
+		ctx := context.WithValue(r.Context(), comms.CtxThing, &msgjson.EpochAuditRequest{
+			BaseID:  baseID,
+			QuoteID: quoteID,
+			Epoch:   epoch,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 // parseBaseQuoteIDs parses the "baseSymbol" and "quoteSymbol" URL parameters
 // from the request.
 func parseBaseQuoteIDs(r *http.Request) (baseID, quoteID uint32, errMsg string) {
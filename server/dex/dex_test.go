@@ -0,0 +1,448 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dex
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/server/asset"
+	"decred.org/dcrdex/server/comms"
+	"decred.org/dcrdex/server/market"
+	"decred.org/dcrdex/server/swap"
+)
+
+func TestLoadMarketConfDisplaySymbols(t *testing.T) {
+	const conf = `{
+		"markets": [{
+			"base": "ETH_simnet",
+			"quote": "USDC_polygon_simnet",
+			"lotSize": 1000000,
+			"rateStep": 100,
+			"parcelSize": 1,
+			"epochDuration": 6000,
+			"marketBuyBuffer": 1.2
+		}],
+		"assets": {
+			"ETH_simnet": {
+				"bip44symbol": "eth",
+				"network": "simnet",
+				"maxFeeRate": 200,
+				"swapConf": 2
+			},
+			"USDC_polygon_simnet": {
+				"bip44symbol": "usdc.polygon",
+				"network": "simnet",
+				"maxFeeRate": 200,
+				"swapConf": 2,
+				"displaySymbol": "USDC.p",
+				"aliases": ["pUSDC"]
+			}
+		}
+	}`
+	loaded, err := loadMarketConf(dex.Simnet, strings.NewReader(conf))
+	if err != nil {
+		t.Fatalf("loadMarketConf error: %v", err)
+	}
+	var found bool
+	for _, a := range loaded.Assets {
+		switch a.Symbol {
+		case "usdc.polygon":
+			found = true
+			if a.DisplaySymbol != "USDC.p" || !reflect.DeepEqual(a.Aliases, []string{"pUSDC"}) {
+				t.Fatalf("wrong display symbol %q or aliases %v", a.DisplaySymbol, a.Aliases)
+			}
+		case "eth":
+			if a.DisplaySymbol != "" || len(a.Aliases) != 0 {
+				t.Fatalf("unexpected eth display symbol %q or aliases %v", a.DisplaySymbol, a.Aliases)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("usdc.polygon asset not loaded")
+	}
+}
+
+func TestLoadMarketConfMirrors(t *testing.T) {
+	const confTmpl = `{
+		"markets": [{
+			"base": "DCR_simnet",
+			"quote": "BTC_simnet",
+			"lotSize": 100000000,
+			"rateStep": 100,
+			"parcelSize": 1,
+			"epochDuration": 6000,
+			"marketBuyBuffer": 1.2
+		}],
+		"assets": {
+			"DCR_simnet": {
+				"bip44symbol": "dcr",
+				"network": "simnet",
+				"maxFeeRate": 100,
+				"swapConf": 1
+			},
+			"BTC_simnet": {
+				"bip44symbol": "btc",
+				"network": "simnet",
+				"maxFeeRate": 100,
+				"swapConf": 1
+			}
+		},
+		"mirrors": [%s]
+	}`
+	load := func(mirrors ...string) ([]*MirrorMarket, error) {
+		conf := fmt.Sprintf(confTmpl, strings.Join(mirrors, ","))
+		loaded, err := loadMarketConf(dex.Simnet, strings.NewReader(conf))
+		if err != nil {
+			return nil, err
+		}
+		return loaded.Mirrors, nil
+	}
+
+	mirrors, err := load(`{"source": "dex.example.com:7232", "base": "ETH", "quote": "btc", "cert": "/path/to/cert"}`)
+	if err != nil {
+		t.Fatalf("loadMarketConf error: %v", err)
+	}
+	if len(mirrors) != 1 {
+		t.Fatalf("expected 1 mirror, got %d", len(mirrors))
+	}
+	m := mirrors[0]
+	if m.Source != "dex.example.com:7232" || m.Cert != "/path/to/cert" || m.baseID != 60 || m.quoteID != 0 {
+		t.Fatalf("wrong mirror %+v", m)
+	}
+
+	for tag, mirror := range map[string]string{
+		"no source":      `{"base": "eth", "quote": "btc"}`,
+		"unknown asset":  `{"source": "dex.example.com:7232", "base": "abc", "quote": "btc"}`,
+		"invalid market": `{"source": "dex.example.com:7232", "base": "btc", "quote": "btc"}`,
+		"local market":   `{"source": "dex.example.com:7232", "base": "dcr", "quote": "btc"}`,
+	} {
+		if _, err := load(mirror); err == nil {
+			t.Fatalf("%s: no error", tag)
+		}
+	}
+	eth := `{"source": "dex.example.com:7232", "base": "eth", "quote": "btc"}`
+	if _, err := load(eth, eth); err == nil {
+		t.Fatalf("no error for a market mirrored twice")
+	}
+}
+
+func TestLoadMarketConfOrderLifetimes(t *testing.T) {
+	const confTmpl = `{
+		"markets": [{
+			"base": "DCR_simnet",
+			"quote": "BTC_simnet",
+			"lotSize": 100000000,
+			"rateStep": 100,
+			"parcelSize": 1,
+			"epochDuration": 6000,
+			"marketBuyBuffer": 1.2,
+			"minOrderLifetimeSecs": %d,
+			"maxOrderLifetimeSecs": %d
+		}],
+		"assets": {
+			"DCR_simnet": {
+				"bip44symbol": "dcr",
+				"network": "simnet",
+				"maxFeeRate": 100,
+				"swapConf": 1
+			},
+			"BTC_simnet": {
+				"bip44symbol": "btc",
+				"network": "simnet",
+				"maxFeeRate": 100,
+				"swapConf": 1
+			}
+		}
+	}`
+	load := func(minSecs, maxSecs uint64) (*dex.MarketInfo, error) {
+		loaded, err := loadMarketConf(dex.Simnet, strings.NewReader(fmt.Sprintf(confTmpl, minSecs, maxSecs)))
+		if err != nil {
+			return nil, err
+		}
+		return loaded.Markets[0], nil
+	}
+
+	mkt, err := load(30, 86400)
+	if err != nil {
+		t.Fatalf("loadMarketConf error: %v", err)
+	}
+	if mkt.MinOrderLifetime != 30*time.Second || mkt.MaxOrderLifetime != 24*time.Hour {
+		t.Fatalf("wrong order lifetimes %v and %v", mkt.MinOrderLifetime, mkt.MaxOrderLifetime)
+	}
+
+	// No limits.
+	if mkt, err = load(0, 0); err != nil {
+		t.Fatalf("loadMarketConf error: %v", err)
+	}
+	if mkt.MinOrderLifetime != 0 || mkt.MaxOrderLifetime != 0 {
+		t.Fatalf("unexpected order lifetimes %v and %v", mkt.MinOrderLifetime, mkt.MaxOrderLifetime)
+	}
+
+	// The max must be longer than the min and an epoch.
+	if _, err = load(60, 60); err == nil {
+		t.Fatalf("no error for a max order lifetime that is not longer than the min")
+	}
+	if _, err = load(0, 5); err == nil {
+		t.Fatalf("no error for a max order lifetime shorter than an epoch")
+	}
+}
+
+func TestHandleMirror(t *testing.T) {
+	feed, err := market.NewWsMirrorFeed("dex.example.com:7232", nil, 60, 0)
+	if err != nil {
+		t.Fatalf("NewWsMirrorFeed error: %v", err)
+	}
+	mirror, err := market.NewMirror(&market.MirrorConfig{
+		Source: "dex.example.com:7232",
+		Base:   60,
+		Quote:  0,
+		Feed:   feed,
+	})
+	if err != nil {
+		t.Fatalf("NewMirror error: %v", err)
+	}
+	dm := &DEX{mirrors: map[string]*market.Mirror{mirror.Name(): mirror}}
+
+	res, err := dm.handleMirror(&msgjson.OrderBookSubscription{Base: 60, Quote: 0})
+	if err != nil {
+		t.Fatalf("handleMirror error: %v", err)
+	}
+	book, ok := res.(*market.MirroredBook)
+	if !ok {
+		t.Fatalf("wrong result type %T", res)
+	}
+	if !book.Mirrored || book.Source != "dex.example.com:7232" || book.MarketID != "eth_btc" || book.Synced {
+		t.Fatalf("wrong book %+v", book)
+	}
+
+	if _, err := dm.handleMirror(&msgjson.OrderBookSubscription{Base: 42, Quote: 0}); err == nil {
+		t.Fatalf("no error for a market that is not mirrored")
+	}
+	if _, err := dm.handleMirror("eth_btc"); err == nil {
+		t.Fatalf("no error for the wrong request type")
+	}
+}
+
+type tTxCheckBackend struct {
+	asset.Backend
+	accepted bool
+	reason   string
+	err      error
+	rawTx    []byte
+}
+
+func (b *tTxCheckBackend) CheckTransaction(rawTx []byte) (bool, string, error) {
+	b.rawTx = rawTx
+	return b.accepted, b.reason, b.err
+}
+
+type tLink struct {
+	comms.Link
+	sent *msgjson.Message
+}
+
+func (l *tLink) Send(msg *msgjson.Message) error {
+	l.sent = msg
+	return nil
+}
+
+func TestHandleCheckTx(t *testing.T) {
+	checker := &tTxCheckBackend{}
+	dm := &DEX{
+		assets: map[uint32]*swap.SwapperAsset{
+			0: {BackedAsset: &asset.BackedAsset{
+				Asset:   dex.Asset{ID: 0, Symbol: "btc"},
+				Backend: checker,
+			}},
+			42: {BackedAsset: &asset.BackedAsset{
+				Asset:   dex.Asset{ID: 42, Symbol: "dcr"},
+				Backend: struct{ asset.Backend }{},
+			}},
+		},
+	}
+	rawTx := []byte{0x01, 0x02}
+
+	checkTx := func(assetID uint32, tx []byte) (*msgjson.CheckTxResult, *msgjson.Error) {
+		t.Helper()
+		msg, _ := msgjson.NewRequest(1, msgjson.CheckTxRoute, &msgjson.CheckTxRequest{AssetID: assetID, Tx: tx})
+		link := new(tLink)
+		if rpcErr := dm.handleCheckTx(link, msg); rpcErr != nil {
+			return nil, rpcErr
+		}
+		if link.sent == nil {
+			t.Fatalf("no response sent")
+		}
+		res := new(msgjson.CheckTxResult)
+		if err := link.sent.UnmarshalResult(res); err != nil {
+			t.Fatalf("error decoding result: %v", err)
+		}
+		return res, nil
+	}
+
+	// Acceptable transaction.
+	checker.accepted = true
+	res, rpcErr := checkTx(0, rawTx)
+	if rpcErr != nil {
+		t.Fatalf("unexpected error: %s", rpcErr.Message)
+	}
+	if !res.Accepted || res.Reason != "" {
+		t.Fatalf("acceptable transaction rejected: %q", res.Reason)
+	}
+	if string(checker.rawTx) != string(rawTx) {
+		t.Fatalf("wrong transaction checked: %x", checker.rawTx)
+	}
+
+	// Rejected transaction.
+	checker.accepted, checker.reason = false, "insufficient fee"
+	res, rpcErr = checkTx(0, rawTx)
+	if rpcErr != nil {
+		t.Fatalf("unexpected error: %s", rpcErr.Message)
+	}
+	if res.Accepted || res.Reason != "insufficient fee" {
+		t.Fatalf("wrong result for rejected transaction: %+v", res)
+	}
+
+	ensureErr := func(tag string, assetID uint32, tx []byte, code int) {
+		t.Helper()
+		_, rpcErr := checkTx(assetID, tx)
+		if rpcErr == nil || rpcErr.Code != code {
+			t.Fatalf("%s: wanted error code %d, got %v", tag, code, rpcErr)
+		}
+	}
+	ensureErr("no tx", 0, nil, msgjson.RPCParseError)
+	ensureErr("unknown asset", 60, rawTx, msgjson.InvalidRequestError)
+	ensureErr("unsupported backend", 42, rawTx, msgjson.RouteUnavailableError)
+	checker.err = asset.ErrTxCheckUnsupported
+	ensureErr("unsupported node", 0, rawTx, msgjson.RouteUnavailableError)
+	checker.err = fmt.Errorf("test error")
+	ensureErr("backend error", 0, rawTx, msgjson.RPCInternalError)
+}
+
+type tRedemptionValidatorBackend struct {
+	asset.Backend
+	valid  bool
+	reason string
+	err    error
+}
+
+func (b *tRedemptionValidatorBackend) ValidateRedemption(_, _ []byte) (bool, string, error) {
+	return b.valid, b.reason, b.err
+}
+
+func TestHandleValidateRedemption(t *testing.T) {
+	validator := &tRedemptionValidatorBackend{}
+	dm := &DEX{
+		assets: map[uint32]*swap.SwapperAsset{
+			60: {BackedAsset: &asset.BackedAsset{
+				Asset:   dex.Asset{ID: 60, Symbol: "eth"},
+				Backend: validator,
+			}},
+			42: {BackedAsset: &asset.BackedAsset{
+				Asset:   dex.Asset{ID: 42, Symbol: "dcr"},
+				Backend: struct{ asset.Backend }{},
+			}},
+		},
+	}
+	contract, secret := []byte{0x01}, []byte{0x02}
+
+	validate := func(assetID uint32, contract, secret []byte) (*msgjson.ValidateRedemptionResult, *msgjson.Error) {
+		t.Helper()
+		msg, _ := msgjson.NewRequest(1, msgjson.ValidateRedemptionRoute, &msgjson.ValidateRedemptionRequest{
+			AssetID:  assetID,
+			Contract: contract,
+			Secret:   secret,
+		})
+		link := new(tLink)
+		if rpcErr := dm.handleValidateRedemption(link, msg); rpcErr != nil {
+			return nil, rpcErr
+		}
+		if link.sent == nil {
+			t.Fatalf("no response sent")
+		}
+		res := new(msgjson.ValidateRedemptionResult)
+		if err := link.sent.UnmarshalResult(res); err != nil {
+			t.Fatalf("error decoding result: %v", err)
+		}
+		return res, nil
+	}
+
+	// Valid redemption.
+	validator.valid = true
+	res, rpcErr := validate(60, contract, secret)
+	if rpcErr != nil {
+		t.Fatalf("unexpected error: %s", rpcErr.Message)
+	}
+	if !res.Valid || res.Reason != "" {
+		t.Fatalf("valid redemption rejected: %q", res.Reason)
+	}
+
+	// Invalid redemption.
+	validator.valid, validator.reason = false, "already redeemed"
+	res, rpcErr = validate(60, contract, secret)
+	if rpcErr != nil {
+		t.Fatalf("unexpected error: %s", rpcErr.Message)
+	}
+	if res.Valid || res.Reason != "already redeemed" {
+		t.Fatalf("wrong result for invalid redemption: %+v", res)
+	}
+
+	ensureErr := func(tag string, assetID uint32, contract, secret []byte, code int) {
+		t.Helper()
+		_, rpcErr := validate(assetID, contract, secret)
+		if rpcErr == nil || rpcErr.Code != code {
+			t.Fatalf("%s: wanted error code %d, got %v", tag, code, rpcErr)
+		}
+	}
+	ensureErr("no contract", 60, nil, secret, msgjson.RPCParseError)
+	ensureErr("no secret", 60, contract, nil, msgjson.RPCParseError)
+	ensureErr("unknown asset", 0, contract, secret, msgjson.InvalidRequestError)
+	ensureErr("unsupported backend", 42, contract, secret, msgjson.RouteUnavailableError)
+	validator.err = fmt.Errorf("test error")
+	ensureErr("backend error", 60, contract, secret, msgjson.RPCInternalError)
+}
+
+func TestMarketParamsChanged(t *testing.T) {
+	const oldLotSize, oldRateStep = 1e8, 1e4
+	const newLotSize, newRateStep = 2e8, 1e5
+	cfgResp := &configResponse{configMsg: &msgjson.ConfigResult{
+		Markets: []*msgjson.Market{{Name: "dcr_btc", LotSize: oldLotSize, RateStep: oldRateStep}},
+	}}
+	cfgResp.remarshal()
+	comms.UseLogger(dex.StdOutLogger("COMMS", dex.LevelOff))
+	server, err := comms.NewServer(&comms.RPCConfig{NoTLS: true, ListenAddrs: []string{"127.0.0.1:0"}})
+	if err != nil {
+		t.Fatalf("NewServer error: %v", err)
+	}
+	dm := &DEX{configResp: cfgResp, server: server}
+
+	checkParams := func(tag string, lotSize, rateStep uint64) {
+		t.Helper()
+		cfg := new(msgjson.ConfigResult)
+		if err := json.Unmarshal(dm.ConfigMsg(), cfg); err != nil {
+			t.Fatalf("%s: error decoding config: %v", tag, err)
+		}
+		mkt := cfg.Markets[0]
+		if mkt.LotSize != lotSize || mkt.RateStep != rateStep {
+			t.Fatalf("%s: wanted lot size %d and rate step %d, got %d and %d",
+				tag, lotSize, rateStep, mkt.LotSize, mkt.RateStep)
+		}
+	}
+
+	// Until the market applies a retune at the epoch boundary, the config
+	// reports the old parameters.
+	checkParams("before retune", oldLotSize, oldRateStep)
+	dm.marketParamsChanged("dcr_btc", newLotSize, newRateStep)
+	checkParams("after retune", newLotSize, newRateStep)
+
+	// Unknown markets are ignored.
+	dm.marketParamsChanged("eth_btc", oldLotSize, oldRateStep)
+	checkParams("unknown market", newLotSize, newRateStep)
+}
@@ -2,18 +2,103 @@ package dex
 
 import (
 	"context"
+	"errors"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/server/asset"
 	"decred.org/dcrdex/server/market"
 )
 
+const (
+	// feeRangeRefresh is how often the fee rate range is refreshed for assets
+	// with backends that estimate them.
+	feeRangeRefresh = time.Minute
+	// feeRangeExpiry is how long a fee rate range is reported after it was
+	// last refreshed successfully.
+	feeRangeExpiry = 10 * time.Minute
+	// feeRateHistorySize is the number of recorded fee rates retained for each
+	// asset.
+	feeRateHistorySize = 100
+)
+
 // FeeManager manages fee fetchers and a fee cache.
 type FeeManager struct {
 	assets map[uint32]*asset.BackedAsset
 	cache  map[uint32]*uint64
+	ranges map[uint32]*feeRangeCache
+	hists  map[uint32]*feeRateHistory
+}
+
+// feeRangeCache is the last fee rate range estimated by a backend that
+// implements asset.FeeRangeEstimator.
+type feeRangeCache struct {
+	mtx      sync.RWMutex
+	stamp    time.Time // last successful estimate
+	attempt  time.Time
+	feeRange *asset.FeeRateRange
+	// unsupported is set if the backend reports that it cannot estimate
+	// ranges.
+	unsupported bool
+}
+
+// newFeeRangeCache creates a feeRangeCache if the backend estimates fee rate
+// ranges, otherwise nil.
+func newFeeRangeCache(be asset.Backend) *feeRangeCache {
+	if _, is := be.(asset.FeeRangeEstimator); !is {
+		return nil
+	}
+	return new(feeRangeCache)
+}
+
+// feeRateHistory is a rolling history of an asset's fee rates. A rate is
+// recorded when it differs from the last recorded rate.
+type feeRateHistory struct {
+	mtx   sync.RWMutex
+	rates []*msgjson.FeeRateRecord
+}
+
+// record adds the rate to the history if it differs from the last recorded
+// rate. The oldest rate is dropped if the history is full.
+func (h *feeRateHistory) record(rate uint64) {
+	if rate == 0 {
+		return
+	}
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if n := len(h.rates); n > 0 && h.rates[n-1].Rate == rate {
+		return
+	}
+	rec := &msgjson.FeeRateRecord{
+		Rate:  rate,
+		Stamp: dex.StampNow(),
+	}
+	if len(h.rates) < feeRateHistorySize {
+		h.rates = append(h.rates, rec)
+		return
+	}
+	copy(h.rates, h.rates[1:])
+	h.rates[len(h.rates)-1] = rec
+}
+
+// last returns up to n of the most recent rates, oldest first. If n is not
+// positive, all of the rates are returned.
+func (h *feeRateHistory) last(n int) []*msgjson.FeeRateRecord {
+	h.mtx.RLock()
+	defer h.mtx.RUnlock()
+	if n <= 0 || n > len(h.rates) {
+		n = len(h.rates)
+	}
+	rates := make([]*msgjson.FeeRateRecord, 0, n)
+	for _, rec := range h.rates[len(h.rates)-n:] {
+		r := *rec
+		rates = append(rates, &r)
+	}
+	return rates
 }
 
 var _ market.FeeSource = (*FeeManager)(nil)
@@ -23,6 +108,8 @@ func NewFeeManager() *FeeManager {
 	return &FeeManager{
 		assets: make(map[uint32]*asset.BackedAsset),
 		cache:  make(map[uint32]*uint64),
+		ranges: make(map[uint32]*feeRangeCache),
+		hists:  make(map[uint32]*feeRateHistory),
 	}
 }
 
@@ -39,8 +126,15 @@ func (m *FeeManager) AddFetcher(asset *asset.BackedAsset) {
 	if rate > asset.MaxFeeRate {
 		rate = asset.MaxFeeRate
 	}
+	hist := new(feeRateHistory)
+	hist.record(rate)
 	m.cache[asset.ID] = &rate
 	m.assets[asset.ID] = asset
+	m.hists[asset.ID] = hist
+	if ranges := newFeeRangeCache(asset.Backend); ranges != nil {
+		newFeeFetcher(asset, &rate, ranges, hist).refreshRange(ctx)
+		m.ranges[asset.ID] = ranges
+	}
 }
 
 // FeeFetcher creates and returns an asset-specific fetcher that satisfies
@@ -50,7 +144,7 @@ func (m *FeeManager) FeeFetcher(assetID uint32) market.FeeFetcher {
 	if asset == nil {
 		panic("no fetcher for " + strconv.Itoa(int(assetID)))
 	}
-	return newFeeFetcher(asset, m.cache[assetID])
+	return newFeeFetcher(asset, m.cache[assetID], m.ranges[assetID], m.hists[assetID])
 }
 
 // LastRate is the last rate cached for the specified asset.
@@ -62,19 +156,51 @@ func (m *FeeManager) LastRate(assetID uint32) uint64 {
 	return atomic.LoadUint64(r)
 }
 
+// LastRange is the last fee rate range cached for the specified asset, or nil
+// if the asset's backend does not estimate fee rate ranges or the last range
+// has expired. The rates are limited by the asset's MaxFeeRate.
+func (m *FeeManager) LastRange(assetID uint32) *asset.FeeRateRange {
+	r := m.ranges[assetID]
+	if r == nil {
+		return nil
+	}
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if r.feeRange == nil || time.Since(r.stamp) > feeRangeExpiry {
+		return nil
+	}
+	feeRange := *r.feeRange
+	return &feeRange
+}
+
+// RateHistory is up to n of the most recent fee rates recorded for the
+// specified asset, oldest first. If n is not positive, all retained rates are
+// returned. The rates are limited by the asset's MaxFeeRate.
+func (m *FeeManager) RateHistory(assetID uint32, n int) []*msgjson.FeeRateRecord {
+	h := m.hists[assetID]
+	if h == nil {
+		return nil
+	}
+	return h.last(n)
+}
+
 // feeFetcher implements market.FeeFetcher and updates the last fee rate cache.
 type feeFetcher struct {
 	*asset.BackedAsset
 	lastRate *uint64
+	ranges   *feeRangeCache // nil if the backend does not estimate ranges
+	hist     *feeRateHistory
 }
 
 var _ market.FeeFetcher = (*feeFetcher)(nil)
 
 // newFeeFetcher is the constructor for a *feeFetcher.
-func newFeeFetcher(asset *asset.BackedAsset, lastRate *uint64) *feeFetcher {
+func newFeeFetcher(asset *asset.BackedAsset, lastRate *uint64, ranges *feeRangeCache, hist *feeRateHistory) *feeFetcher {
 	return &feeFetcher{
 		BackedAsset: asset,
 		lastRate:    lastRate,
+		ranges:      ranges,
+		hist:        hist,
 	}
 }
 
@@ -92,9 +218,51 @@ func (f *feeFetcher) FeeRate(ctx context.Context) uint64 {
 		r = f.Asset.MaxFeeRate
 	}
 	atomic.StoreUint64(f.lastRate, r)
+	f.hist.record(r)
+	f.refreshRange(ctx)
 	return r
 }
 
+// refreshRange updates the fee rate range cache if the backend estimates fee
+// rate ranges and the range was not refreshed recently. The cache is not
+// locked during the backend's requests.
+func (f *feeFetcher) refreshRange(ctx context.Context) {
+	if f.ranges == nil {
+		return
+	}
+	f.ranges.mtx.Lock()
+	if f.ranges.unsupported || time.Since(f.ranges.attempt) < feeRangeRefresh {
+		f.ranges.mtx.Unlock()
+		return
+	}
+	f.ranges.attempt = time.Now()
+	f.ranges.mtx.Unlock()
+
+	feeRange, err := f.Backend.(asset.FeeRangeEstimator).FeeRateRange(ctx)
+	if err != nil {
+		if errors.Is(err, asset.ErrFeeRangeUnsupported) {
+			// e.g. a btc clone without estimatesmartfee. Don't ask again.
+			log.Debugf("Fee rate ranges not supported for %s", f.Symbol)
+			f.ranges.mtx.Lock()
+			f.ranges.unsupported = true
+			f.ranges.mtx.Unlock()
+			return
+		}
+		log.Errorf("Error retrieving fee rate range for %s: %v", f.Symbol, err)
+		return
+	}
+	maxRate := f.Asset.MaxFeeRate
+	for _, r := range []*uint64{&feeRange.MinToConfirm, &feeRange.Economical, &feeRange.Priority} {
+		if *r > maxRate {
+			*r = maxRate
+		}
+	}
+	f.ranges.mtx.Lock()
+	f.ranges.feeRange = feeRange
+	f.ranges.stamp = time.Now()
+	f.ranges.mtx.Unlock()
+}
+
 // LastRate is the last rate cached. This may be used as a fallback if FeeRate
 // times out, or as a quick rate when rate freshness is not critical.
 func (f *feeFetcher) LastRate() uint64 {
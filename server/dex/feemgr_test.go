@@ -0,0 +1,141 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dex
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/server/asset"
+)
+
+type tFeeBackend struct {
+	asset.Backend
+	rate uint64
+	err  error
+}
+
+func (be *tFeeBackend) FeeRate(context.Context) (uint64, error) {
+	return be.rate, be.err
+}
+
+func TestFeeRateHistory(t *testing.T) {
+	const assetID, maxFeeRate = 42, 100
+	be := &tFeeBackend{rate: 10}
+	m := NewFeeManager()
+	m.AddFetcher(&asset.BackedAsset{
+		Asset:   dex.Asset{ID: assetID, Symbol: "abc", MaxFeeRate: maxFeeRate},
+		Backend: be,
+	})
+	f := m.FeeFetcher(assetID)
+
+	checkRates := func(tag string, n int, exp ...uint64) {
+		t.Helper()
+		rates := m.RateHistory(assetID, n)
+		if len(rates) != len(exp) {
+			t.Fatalf("%s: expected %d rates, got %d", tag, len(exp), len(rates))
+		}
+		for i, rec := range rates {
+			if rec.Rate != exp[i] {
+				t.Fatalf("%s: wrong rate %d. expected %d, got %d", tag, i, exp[i], rec.Rate)
+			}
+			if i > 0 && rec.Stamp < rates[i-1].Stamp {
+				t.Fatalf("%s: rate %d recorded before rate %d", tag, i, i-1)
+			}
+		}
+	}
+	checkRates("primed", 0, 10)
+
+	// Unchanged rates and errors are not recorded, and rates are limited by the
+	// MaxFeeRate.
+	for _, r := range []uint64{10, 20, 20, 15, maxFeeRate * 2} {
+		be.rate = r
+		f.FeeRate(context.Background())
+	}
+	be.err = errors.New("test error")
+	f.FeeRate(context.Background())
+	be.err = nil
+	checkRates("updates", 0, 10, 20, 15, maxFeeRate)
+	checkRates("last 2", 2, 15, maxFeeRate)
+	checkRates("more than retained", 10, 10, 20, 15, maxFeeRate)
+
+	// The oldest rates are dropped from a full history.
+	for i := 1; i <= feeRateHistorySize; i++ {
+		be.rate = uint64(i)
+		f.FeeRate(context.Background())
+	}
+	rates := m.RateHistory(assetID, 0)
+	if len(rates) != feeRateHistorySize {
+		t.Fatalf("expected %d rates in a full history, got %d", feeRateHistorySize, len(rates))
+	}
+	if rates[0].Rate != 1 || rates[len(rates)-1].Rate != feeRateHistorySize {
+		t.Fatalf("wrong rates in a full history. first = %d, last = %d", rates[0].Rate, rates[len(rates)-1].Rate)
+	}
+
+	if rates := m.RateHistory(assetID+1, 0); rates != nil {
+		t.Fatalf("expected no rates for an unknown asset, got %d", len(rates))
+	}
+}
+
+type tFeeRangeBackend struct {
+	tFeeBackend
+	feeRange *asset.FeeRateRange
+	err      error
+	calls    int
+}
+
+func (be *tFeeRangeBackend) FeeRateRange(context.Context) (*asset.FeeRateRange, error) {
+	be.calls++
+	if be.err != nil {
+		return nil, be.err
+	}
+	feeRange := *be.feeRange
+	return &feeRange, nil
+}
+
+func TestFeeRateRange(t *testing.T) {
+	const assetID, maxFeeRate = 42, 100
+	be := &tFeeRangeBackend{
+		tFeeBackend: tFeeBackend{rate: 10},
+		feeRange:    &asset.FeeRateRange{MinToConfirm: 2, Economical: 5, Priority: maxFeeRate * 2},
+	}
+	m := NewFeeManager()
+	m.AddFetcher(&asset.BackedAsset{
+		Asset:   dex.Asset{ID: assetID, Symbol: "abc", MaxFeeRate: maxFeeRate},
+		Backend: be,
+	})
+	exp := asset.FeeRateRange{MinToConfirm: 2, Economical: 5, Priority: maxFeeRate}
+	if feeRange := m.LastRange(assetID); feeRange == nil || *feeRange != exp {
+		t.Fatalf("wrong primed range. wanted %+v, got %+v", exp, feeRange)
+	}
+
+	// The range is not refreshed again before feeRangeRefresh.
+	f := m.FeeFetcher(assetID)
+	f.FeeRate(context.Background())
+	if be.calls != 1 {
+		t.Fatalf("expected 1 range estimate, got %d", be.calls)
+	}
+
+	// A backend that does not support ranges is not asked again.
+	be = &tFeeRangeBackend{
+		tFeeBackend: tFeeBackend{rate: 10},
+		err:         asset.ErrFeeRangeUnsupported,
+	}
+	m = NewFeeManager()
+	m.AddFetcher(&asset.BackedAsset{
+		Asset:   dex.Asset{ID: assetID, Symbol: "abc", MaxFeeRate: maxFeeRate},
+		Backend: be,
+	})
+	m.ranges[assetID].attempt = time.Time{}
+	m.FeeFetcher(assetID).FeeRate(context.Background())
+	if be.calls != 1 {
+		t.Fatalf("expected 1 range estimate for an unsupported backend, got %d", be.calls)
+	}
+	if feeRange := m.LastRange(assetID); feeRange != nil {
+		t.Fatalf("unexpected range %+v for an unsupported backend", feeRange)
+	}
+}
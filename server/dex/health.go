@@ -0,0 +1,253 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"decred.org/dcrdex/server/asset"
+	"decred.org/dcrdex/server/swap"
+)
+
+// HealthStatus is the overall verdict of a health check.
+type HealthStatus string
+
+const (
+	// Healthy means that all subsystems are working.
+	Healthy HealthStatus = "healthy"
+	// Degraded means that the server is serving clients, but some assets or
+	// markets are unavailable.
+	Degraded HealthStatus = "degraded"
+	// Unhealthy means that the server cannot serve clients, because the
+	// database is unreachable or no market is running.
+	Unhealthy HealthStatus = "unhealthy"
+)
+
+const (
+	// healthCacheDuration is how long a HealthReport is reused, which limits
+	// the requests to the asset backends made by frequent health checks.
+	healthCacheDuration = 5 * time.Second
+	// healthDBTimeout is the time limit for the database ping.
+	healthDBTimeout = 3 * time.Second
+)
+
+// AssetHealth is the health of an asset backend.
+type AssetHealth struct {
+	Symbol string `json:"symbol"`
+	// Connected is whether the backend's node responded to the sync check.
+	Connected bool `json:"connected"`
+	Synced    bool `json:"synced"`
+	// SyncLag is the number of blocks by which the node's best block trails
+	// the best known header, for backends that report it.
+	SyncLag *int64 `json:"syncLag,omitempty"`
+	// Tripped is whether the asset's circuit breaker is tripped.
+	Tripped bool `json:"breakerTripped,omitempty"`
+	// SwapQueue is the number of client-reported transactions of the asset
+	// that are pending lookup by the Swapper.
+	SwapQueue int `json:"swapQueue"`
+	// Settlement is the recent swap settlement times on the asset's chain, for
+	// assets with a maximum settlement time configured.
+	Settlement *swap.SettlementStats `json:"settlement,omitempty"`
+	Error      string                `json:"error,omitempty"`
+}
+
+// MarketHealth is the health of a market.
+type MarketHealth struct {
+	Name    string `json:"name"`
+	Running bool   `json:"running"`
+	// Closed is whether the market is outside of its trading hours, in which
+	// case it is not expected to be running.
+	Closed bool `json:"closed,omitempty"`
+}
+
+// DBHealth is the health of the database.
+type DBHealth struct {
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthReport summarizes the health of the server's subsystems. Only the
+// Status is set for a brief report.
+type HealthReport struct {
+	Status HealthStatus `json:"status"`
+	// Issues describe the problems that determined the Status.
+	Issues      []string        `json:"issues,omitempty"`
+	Assets      []*AssetHealth  `json:"assets,omitempty"`
+	Markets     []*MarketHealth `json:"markets,omitempty"`
+	DB          *DBHealth       `json:"db,omitempty"`
+	ActiveSwaps int             `json:"activeSwaps,omitempty"`
+	Clients     uint64          `json:"clients,omitempty"`
+
+	stamp time.Time
+}
+
+// verdict sets the report's Status and Issues from the health of the
+// subsystems. The server is unhealthy if the database is unreachable or if
+// none of the markets that are within their trading hours are running. The
+// server is degraded if any asset backend is unreachable, not synced, or has
+// a tripped circuit breaker, if any asset's swaps are settling slowly, or if
+// any market that is within its trading hours is not running.
+func (r *HealthReport) verdict() {
+	var unhealthy, degraded bool
+	var issues []string
+	if r.DB != nil && !r.DB.Reachable {
+		unhealthy = true
+		issues = append(issues, fmt.Sprintf("database unreachable: %s", r.DB.Error))
+	}
+	for _, a := range r.Assets {
+		switch {
+		case !a.Connected:
+			issues = append(issues, fmt.Sprintf("%s backend unreachable: %s", a.Symbol, a.Error))
+		case a.Tripped:
+			issues = append(issues, fmt.Sprintf("%s circuit breaker tripped", a.Symbol))
+		case !a.Synced:
+			issues = append(issues, fmt.Sprintf("%s backend not synced", a.Symbol))
+		case a.Settlement != nil && a.Settlement.Slow:
+			issues = append(issues, fmt.Sprintf("%s average swap settlement time of %v exceeds %v", a.Symbol,
+				time.Duration(a.Settlement.AverageMS)*time.Millisecond, time.Duration(a.Settlement.ThresholdMS)*time.Millisecond))
+		default:
+			continue
+		}
+		degraded = true
+	}
+	var open, running int
+	for _, m := range r.Markets {
+		if m.Closed {
+			continue
+		}
+		open++
+		if m.Running {
+			running++
+			continue
+		}
+		degraded = true
+		issues = append(issues, fmt.Sprintf("market %s not running", m.Name))
+	}
+	if open > 0 && running == 0 {
+		unhealthy = true
+	}
+
+	r.Issues = issues
+	switch {
+	case unhealthy:
+		r.Status = Unhealthy
+	case degraded:
+		r.Status = Degraded
+	default:
+		r.Status = Healthy
+	}
+}
+
+// Health reports the health of the asset backends, markets, and database, and
+// the number of active swaps and connected clients. A report generated less
+// than healthCacheDuration ago is reused. While a new report is being
+// generated, concurrent callers receive the previous report, if any, so that
+// the backends and database are not queried by concurrent health checks.
+func (dm *DEX) Health() *HealthReport {
+	dm.healthMtx.Lock()
+	if dm.health != nil && (dm.healthRefreshing || time.Since(dm.health.stamp) < healthCacheDuration) {
+		r := dm.health
+		dm.healthMtx.Unlock()
+		return r
+	}
+	dm.healthRefreshing = true
+	dm.healthMtx.Unlock()
+
+	r := dm.checkHealth()
+
+	dm.healthMtx.Lock()
+	dm.health = r
+	dm.healthRefreshing = false
+	dm.healthMtx.Unlock()
+	return r
+}
+
+// checkHealth generates a new HealthReport. The asset backends and database
+// are queried, so the healthMtx must not be held.
+func (dm *DEX) checkHealth() *HealthReport {
+	now := time.Now()
+	r := &HealthReport{
+		DB:          &DBHealth{Reachable: true},
+		ActiveSwaps: dm.swapper.ActiveSwaps(),
+		Clients:     dm.server.NumClients(),
+		stamp:       now,
+	}
+	if err := dm.storage.LastErr(); err != nil {
+		r.DB = &DBHealth{Error: err.Error()}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), healthDBTimeout)
+		err := dm.storage.Ping(ctx)
+		cancel()
+		if err != nil {
+			r.DB = &DBHealth{Error: err.Error()}
+		}
+	}
+
+	// The backends are not queried with the breakerMtx held.
+	tripped := make(map[uint32]bool, len(dm.assets))
+	dm.breakerMtx.Lock()
+	for assetID, cb := range dm.breakers {
+		tripped[assetID] = cb.Tripped()
+	}
+	for name, mkt := range dm.markets {
+		mh := &MarketHealth{Name: name, Running: mkt.Running()}
+		if sched := dm.schedules[name]; sched != nil {
+			mh.Closed = !sched.IsOpen(now)
+		}
+		r.Markets = append(r.Markets, mh)
+	}
+	dm.breakerMtx.Unlock()
+
+	for assetID, a := range dm.assets {
+		ah := &AssetHealth{
+			Symbol:     a.Symbol,
+			Tripped:    tripped[assetID],
+			SwapQueue:  dm.swapper.OpQueueDepth(assetID),
+			Settlement: dm.swapper.SettlementStats(assetID),
+		}
+		synced, err := a.Backend.Synced()
+		if err != nil {
+			ah.Error = err.Error()
+		} else {
+			ah.Connected, ah.Synced = true, synced
+			if lagger, is := a.Backend.(asset.SyncLagReporter); is {
+				if lag, err := lagger.SyncLag(); err == nil {
+					ah.SyncLag = &lag
+				}
+			}
+		}
+		r.Assets = append(r.Assets, ah)
+	}
+
+	sort.Slice(r.Assets, func(i, j int) bool { return r.Assets[i].Symbol < r.Assets[j].Symbol })
+	sort.Slice(r.Markets, func(i, j int) bool { return r.Markets[i].Name < r.Markets[j].Name })
+	r.verdict()
+	return r
+}
+
+// handleHealth is the handler for the public /health endpoint, which is
+// intended for load balancer health checks. The response code is 503 if the
+// server is unhealthy, and 200 otherwise. Only the status is returned. The
+// full report is available from the admin API.
+func (dm *DEX) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	status := dm.Health().Status
+	code := http.StatusOK
+	if status == Unhealthy {
+		code = http.StatusServiceUnavailable
+	}
+	b, err := json.Marshal(&HealthReport{Status: status})
+	if err != nil {
+		log.Errorf("Error encoding health report: %v", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = w.Write(append(b, '\n'))
+}
@@ -0,0 +1,157 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package dex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/server/swap"
+)
+
+func healthyReport() *HealthReport {
+	return &HealthReport{
+		DB: &DBHealth{Reachable: true},
+		Assets: []*AssetHealth{
+			{Symbol: "btc", Connected: true, Synced: true},
+			{Symbol: "dcr", Connected: true, Synced: true},
+		},
+		Markets: []*MarketHealth{
+			{Name: "dcr_btc", Running: true},
+			{Name: "ltc_btc", Running: true},
+		},
+	}
+}
+
+func TestHealthVerdict(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*HealthReport)
+		want   HealthStatus
+	}{
+		{
+			name:   "all healthy",
+			modify: func(*HealthReport) {},
+			want:   Healthy,
+		},
+		{
+			name:   "db unreachable",
+			modify: func(r *HealthReport) { r.DB = &DBHealth{Error: "connection refused"} },
+			want:   Unhealthy,
+		},
+		{
+			name: "backend unreachable",
+			modify: func(r *HealthReport) {
+				r.Assets[0].Connected, r.Assets[0].Synced, r.Assets[0].Error = false, false, "timeout"
+			},
+			want: Degraded,
+		},
+		{
+			name:   "backend not synced",
+			modify: func(r *HealthReport) { r.Assets[1].Synced = false },
+			want:   Degraded,
+		},
+		{
+			name:   "breaker tripped",
+			modify: func(r *HealthReport) { r.Assets[1].Tripped = true },
+			want:   Degraded,
+		},
+		{
+			name: "slow settlement",
+			modify: func(r *HealthReport) {
+				r.Assets[0].Settlement = &swap.SettlementStats{AverageMS: 7200e3, ThresholdMS: 3600e3, Slow: true}
+			},
+			want: Degraded,
+		},
+		{
+			name: "settlement within threshold",
+			modify: func(r *HealthReport) {
+				r.Assets[0].Settlement = &swap.SettlementStats{AverageMS: 60e3, ThresholdMS: 3600e3}
+			},
+			want: Healthy,
+		},
+		{
+			name:   "one market down",
+			modify: func(r *HealthReport) { r.Markets[0].Running = false },
+			want:   Degraded,
+		},
+		{
+			name: "all markets down",
+			modify: func(r *HealthReport) {
+				r.Markets[0].Running = false
+				r.Markets[1].Running = false
+			},
+			want: Unhealthy,
+		},
+		{
+			name: "market outside of trading hours",
+			modify: func(r *HealthReport) {
+				r.Markets[0].Running = false
+				r.Markets[0].Closed = true
+			},
+			want: Healthy,
+		},
+		{
+			name: "all markets outside of trading hours",
+			modify: func(r *HealthReport) {
+				for _, m := range r.Markets {
+					m.Running, m.Closed = false, true
+				}
+			},
+			want: Healthy,
+		},
+	}
+	for _, tt := range tests {
+		r := healthyReport()
+		tt.modify(r)
+		r.verdict()
+		if r.Status != tt.want {
+			t.Fatalf("%s: wanted status %q, got %q", tt.name, tt.want, r.Status)
+		}
+		if (r.Status == Healthy) != (len(r.Issues) == 0) {
+			t.Fatalf("%s: status %q with issues %v", tt.name, r.Status, r.Issues)
+		}
+	}
+}
+
+func TestHandleHealth(t *testing.T) {
+	dm := new(DEX)
+
+	check := func(tag string, report *HealthReport, target string, wantCode int) {
+		t.Helper()
+		report.verdict()
+		report.stamp = time.Now() // cached
+		dm.health = report
+		w := httptest.NewRecorder()
+		dm.handleHealth(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != wantCode {
+			t.Fatalf("%s: wanted code %d, got %d", tag, wantCode, w.Code)
+		}
+		var resp HealthReport
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: error decoding response: %v", tag, err)
+		}
+		if resp.Status != report.Status {
+			t.Fatalf("%s: wanted status %q, got %q", tag, report.Status, resp.Status)
+		}
+		// Details are only available from the admin API.
+		if len(resp.Assets) > 0 || len(resp.Issues) > 0 {
+			t.Fatalf("%s: public response includes details", tag)
+		}
+	}
+
+	check("healthy", healthyReport(), "/health", http.StatusOK)
+	check("healthy verbose", healthyReport(), "/health?verbose", http.StatusOK)
+
+	report := healthyReport()
+	report.Assets[0].Synced = false
+	check("degraded", report, "/health", http.StatusOK)
+
+	report = healthyReport()
+	report.DB = &DBHealth{Error: "connection refused"}
+	check("unhealthy", report, "/health?verbose=1", http.StatusServiceUnavailable)
+}
@@ -12,6 +12,7 @@ import (
 	"decred.org/dcrdex/dex"
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/asset"
 	"decred.org/dcrdex/server/comms"
 	"decred.org/dcrdex/server/matcher"
 )
@@ -133,10 +134,12 @@ type sigDataMatchProof struct {
 }
 
 // BookSource is a source of a market's order book and a feed of updates to the
-// order book and epoch queue.
+// order book and epoch queue. RecentTrades provides the stored trades used to
+// seed the public trade tape, newest first.
 type BookSource interface {
 	Book() (epoch int64, buys []*order.LimitOrder, sells []*order.LimitOrder)
 	OrderFeed() <-chan *updateSignal
+	RecentTrades(n int) ([]*msgjson.PublicTrade, error)
 	Base() uint32
 	Quote() uint32
 }
@@ -198,6 +201,8 @@ type msgBook struct {
 	running       bool
 	orders        map[order.OrderID]*msgjson.BookOrderNote
 	recentMatches [][3]int64
+	tape          []*msgjson.PublicTrade // newest first
+	tapeSize      int
 	epochIdx      int64
 	subs          *subscribers
 	source        BookSource
@@ -221,6 +226,61 @@ func (book *msgBook) addRecentMatches(matches [][3]int64) {
 	}
 }
 
+// addTrades adds the matches of an epoch ending at endStamp to the trade tape,
+// trimming the tape to tapeSize. The matches are [rate, qty] with a positive
+// quantity for a selling taker.
+func (book *msgBook) addTrades(matches [][2]int64, endStamp int64) {
+	if len(matches) == 0 {
+		return
+	}
+	trades := make([]*msgjson.PublicTrade, 0, len(matches)+len(book.tape))
+	// Newest first. The matches of an epoch are in matching order.
+	for i := len(matches) - 1; i >= 0; i-- {
+		rate, qty := matches[i][0], matches[i][1]
+		sell := qty > 0
+		if !sell {
+			qty = -qty
+		}
+		trades = append(trades, &msgjson.PublicTrade{
+			Rate:  uint64(rate),
+			Qty:   uint64(qty),
+			Sell:  sell,
+			Stamp: uint64(endStamp),
+		})
+	}
+
+	book.mtx.Lock()
+	defer book.mtx.Unlock()
+	book.tape = append(trades, book.tape...)
+	if len(book.tape) > book.tapeSize {
+		book.tape = book.tape[:book.tapeSize]
+	}
+}
+
+// setTrades replaces the trade tape with the provided trades, which should be
+// newest first, trimming it to tapeSize.
+func (book *msgBook) setTrades(trades []*msgjson.PublicTrade) {
+	book.mtx.Lock()
+	defer book.mtx.Unlock()
+	if len(trades) > book.tapeSize {
+		trades = trades[:book.tapeSize]
+	}
+	book.tape = trades
+}
+
+// recentTrades returns up to n of the most recent trades, newest first. If n
+// is not positive, the entire tape is returned.
+func (book *msgBook) recentTrades(n int) []*msgjson.PublicTrade {
+	book.mtx.RLock()
+	defer book.mtx.RUnlock()
+	if n <= 0 || n > len(book.tape) {
+		n = len(book.tape)
+	}
+	trades := make([]*msgjson.PublicTrade, n)
+	copy(trades, book.tape)
+	return trades
+}
+
 func (book *msgBook) epoch() int64 {
 	book.mtx.RLock()
 	defer book.mtx.RUnlock()
@@ -283,22 +343,48 @@ func (book *msgBook) addBulkOrders(epoch int64, orderSets ...[]*order.LimitOrder
 // of subscribers, and maintaining an intermediate copy of the orderbook in
 // message payload format for quick, full-book syncing.
 type BookRouter struct {
-	books     map[string]*msgBook
-	feeSource FeeSource
+	books      map[string]*msgBook
+	feeSource  FeeSource
+	maxMsgSize int64
 
 	priceFeeders *subscribers
 	spotsMtx     sync.RWMutex
 	spots        map[string]*msgjson.Spot
 }
 
+// DefaultTradeTapeSize is the number of recent trades retained for each market
+// if no size is specified to NewBookRouter.
+const DefaultTradeTapeSize = 500
+
+// bookPageRatio is the fraction of the max message size above which an order
+// book snapshot is paginated, and which each page is sized to fit.
+const bookPageRatio = 0.5
+
+// msgSizeWarnRatio is the fraction of the max message size above which the
+// size of an order book snapshot that is sent in one message is logged as
+// approaching the limit.
+const msgSizeWarnRatio = 0.75
+
 // NewBookRouter is a constructor for a BookRouter. Routes are registered with
 // comms and a monitoring goroutine is started for each BookSource specified.
 // The input sources is a mapping of market names to sources for order and epoch
-// queue information.
-func NewBookRouter(sources map[string]BookSource, feeSource FeeSource, route func(route string, handler comms.MsgHandler)) *BookRouter {
+// queue information. tapeSize is the number of recent trades retained for each
+// market. If tapeSize is zero, DefaultTradeTapeSize is used. maxMsgSize is the
+// limit on the size of messages to clients, which determines when order book
+// snapshots are paginated. If maxMsgSize is zero,
+// msgjson.DefaultMaxMessageSize is used.
+func NewBookRouter(sources map[string]BookSource, feeSource FeeSource, tapeSize int, maxMsgSize int64,
+	route func(route string, handler comms.MsgHandler)) *BookRouter {
+	if tapeSize <= 0 {
+		tapeSize = DefaultTradeTapeSize
+	}
+	if maxMsgSize <= 0 {
+		maxMsgSize = msgjson.DefaultMaxMessageSize
+	}
 	router := &BookRouter{
-		books:     make(map[string]*msgBook),
-		feeSource: feeSource,
+		books:      make(map[string]*msgBook),
+		feeSource:  feeSource,
+		maxMsgSize: maxMsgSize,
 		priceFeeders: &subscribers{
 			conns: make(map[uint64]comms.Link),
 		},
@@ -309,12 +395,13 @@ func NewBookRouter(sources map[string]BookSource, feeSource FeeSource, route fun
 			conns: make(map[uint64]comms.Link),
 		}
 		book := &msgBook{
-			name:    mkt,
-			orders:  make(map[order.OrderID]*msgjson.BookOrderNote),
-			subs:    subs,
-			source:  src,
-			baseID:  src.Base(),
-			quoteID: src.Quote(),
+			name:     mkt,
+			orders:   make(map[order.OrderID]*msgjson.BookOrderNote),
+			subs:     subs,
+			source:   src,
+			baseID:   src.Base(),
+			quoteID:  src.Quote(),
+			tapeSize: tapeSize,
 		}
 		router.books[mkt] = book
 	}
@@ -322,6 +409,8 @@ func NewBookRouter(sources map[string]BookSource, feeSource FeeSource, route fun
 	route(msgjson.UnsubOrderBookRoute, router.handleUnsubOrderBook)
 	route(msgjson.FeeRateRoute, router.handleFeeRate)
 	route(msgjson.PriceFeedRoute, router.handlePriceFeeder)
+	route(msgjson.RecentTradesRoute, router.handleRecentTrades)
+	route(msgjson.FeeRateHistoryRoute, router.handleFeeRateHistory)
 
 	return router
 }
@@ -346,6 +435,14 @@ func (r *BookRouter) runBook(ctx context.Context, book *msgBook) {
 	book.addBulkOrders(book.source.Book())
 	subs := book.subs
 
+	// Seed the trade tape with the stored matches.
+	trades, err := book.source.RecentTrades(book.tapeSize)
+	if err != nil {
+		log.Errorf("Failed to load recent trades for market %q: %v", book.name, err)
+	} else {
+		book.setTrades(trades)
+	}
+
 	defer func() {
 		book.mtx.Lock()
 		book.running = false
@@ -434,6 +531,7 @@ out:
 						endStamp})
 				}
 				book.addRecentMatches(matchesWithTimestamp)
+				book.addTrades(sigData.matches, endStamp)
 
 				note = &msgjson.EpochReportNote{
 					MarketID:     book.name,
@@ -450,7 +548,9 @@ out:
 						StartRate:   stats.StartRate,
 						EndRate:     stats.EndRate,
 					},
-					MatchSummary: sigData.matches,
+					MatchSummary:  sigData.matches,
+					BaseFeeRange:  msgFeeRange(r.feeSource.LastRange(book.baseID)),
+					QuoteFeeRange: msgFeeRange(r.feeSource.LastRange(book.quoteID)),
 				}
 
 			case sigDataEpochOrder:
@@ -561,21 +661,97 @@ func (r *BookRouter) Book(mktName string) (*msgjson.OrderBook, error) {
 }
 
 // sendBook encodes and sends the the entire order book to the specified client.
+// A snapshot that is too large for one message is paginated if the client
+// supports book pages.
 func (r *BookRouter) sendBook(conn comms.Link, book *msgBook, msgID uint64) {
 	msgOB := r.msgOrderBook(book)
 	if msgOB == nil {
 		conn.SendError(msgID, msgjson.NewError(msgjson.MarketNotRunningError, "market not running"))
 		return
 	}
-	msg, err := msgjson.NewResponse(msgID, msgOB, nil)
+	msgs, err := r.bookMessages(msgOB, msgID, conn.Supports(msgjson.FeatureBookPages))
 	if err != nil {
 		log.Errorf("error encoding 'orderbook' response: %v", err)
 		return
 	}
 
-	err = conn.Send(msg) // consider a synchronous send here
+	for _, b := range msgs {
+		if err := conn.SendRaw(b); err != nil { // consider a synchronous send here
+			log.Debugf("error sending 'orderbook' response: %v", err)
+			return
+		}
+	}
+}
+
+// bookMessages encodes the 'orderbook' response with the snapshot. If paginate
+// is true and the response is larger than bookPageRatio of the max message
+// size, the orders are split into pages that fit, with the first page in the
+// response and the rest in 'orderbook_page' notifications, which must be sent
+// in order after the response.
+func (r *BookRouter) bookMessages(msgOB *msgjson.OrderBook, msgID uint64, paginate bool) ([][]byte, error) {
+	encResp := func(ob *msgjson.OrderBook) ([]byte, error) {
+		msg, err := msgjson.NewResponse(msgID, ob, nil)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(msg)
+	}
+	encBook, err := encResp(msgOB)
 	if err != nil {
-		log.Debugf("error sending 'orderbook' response: %v", err)
+		return nil, err
+	}
+	pageSize := int(float64(r.maxMsgSize) * bookPageRatio)
+	if !paginate || len(encBook) <= pageSize || len(msgOB.Orders) < 2 {
+		if float64(len(encBook)) > float64(r.maxMsgSize)*msgSizeWarnRatio {
+			log.Warnf("Sending %d byte %s order book snapshot without pages, which approaches the max message size of %d bytes",
+				len(encBook), msgOB.MarketID, r.maxMsgSize)
+		}
+		return [][]byte{encBook}, nil
+	}
+
+	// The orders are nearly uniform in size, so start with the page size that
+	// the encoded size suggests, and shrink the pages until they all fit.
+	ords := msgOB.Orders
+	numPages := (len(encBook) + pageSize - 1) / pageSize
+	for perPage := (len(ords) + numPages - 1) / numPages; ; perPage -= 1 + perPage/10 {
+		if perPage < 1 {
+			perPage = 1
+		}
+		numPages = (len(ords) + perPage - 1) / perPage
+		ob := *msgOB
+		ob.Orders, ob.Pages = ords[:perPage], uint32(numPages)
+		b, err := encResp(&ob)
+		if err != nil {
+			return nil, err
+		}
+		msgs := [][]byte{b}
+		fits := len(b) <= pageSize
+		for page := 1; page < numPages; page++ {
+			end := (page + 1) * perPage
+			if end > len(ords) {
+				end = len(ords)
+			}
+			note, err := msgjson.NewNotification(msgjson.OrderBookPageRoute, &msgjson.OrderBookPage{
+				MarketID: msgOB.MarketID,
+				Seq:      msgOB.Seq,
+				Page:     uint32(page),
+				Orders:   ords[page*perPage : end],
+			})
+			if err != nil {
+				return nil, err
+			}
+			b, err := json.Marshal(note)
+			if err != nil {
+				return nil, err
+			}
+			fits = fits && len(b) <= pageSize
+			msgs = append(msgs, b)
+		}
+		if fits || perPage == 1 {
+			log.Debugf("Paginated %d byte %s order book snapshot with %d orders into %d pages",
+				len(encBook), msgOB.MarketID, len(ords), numPages)
+			return msgs, nil
+		}
 	}
 }
 
@@ -604,6 +780,21 @@ func (r *BookRouter) msgOrderBook(book *msgBook) *msgjson.OrderBook {
 		BaseFeeRate:   r.feeSource.LastRate(book.baseID), // MaxFeeRate applied inside feeSource
 		QuoteFeeRate:  r.feeSource.LastRate(book.quoteID),
 		RecentMatches: recentMatches,
+		BaseFeeRange:  msgFeeRange(r.feeSource.LastRange(book.baseID)),
+		QuoteFeeRange: msgFeeRange(r.feeSource.LastRange(book.quoteID)),
+	}
+}
+
+// msgFeeRange converts the asset.FeeRateRange to a msgjson.FeeRateRange, or
+// returns nil for a nil range.
+func msgFeeRange(feeRange *asset.FeeRateRange) *msgjson.FeeRateRange {
+	if feeRange == nil {
+		return nil
+	}
+	return &msgjson.FeeRateRange{
+		MinToConfirm: feeRange.MinToConfirm,
+		Economical:   feeRange.Economical,
+		Priority:     feeRange.Priority,
 	}
 }
 
@@ -639,6 +830,55 @@ func (r *BookRouter) handleOrderBook(conn comms.Link, msg *msgjson.Message) *msg
 	return nil
 }
 
+// RecentTrades returns up to n of the market's most recent trades, newest
+// first. If n is not positive, all retained trades are returned.
+func (r *BookRouter) RecentTrades(mktName string, n int) ([]*msgjson.PublicTrade, error) {
+	book := r.books[mktName]
+	if book == nil {
+		return nil, fmt.Errorf("market %s unknown", mktName)
+	}
+	return book.recentTrades(n), nil
+}
+
+// handleRecentTrades is the handler for the non-authenticated 'recent_trades'
+// route. Clients use this route to retrieve a market's public trade tape.
+func (r *BookRouter) handleRecentTrades(conn comms.Link, msg *msgjson.Message) *msgjson.Error {
+	req := new(msgjson.RecentTradesRequest)
+	err := msg.Unmarshal(&req)
+	if err != nil || req == nil {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "error parsing recent_trades request",
+		}
+	}
+	mkt, err := dex.MarketName(req.BaseID, req.QuoteID)
+	if err != nil {
+		return &msgjson.Error{
+			Code:    msgjson.UnknownMarket,
+			Message: "market name error: " + err.Error(),
+		}
+	}
+	trades, err := r.RecentTrades(mkt, req.N)
+	if err != nil {
+		return &msgjson.Error{
+			Code:    msgjson.UnknownMarket,
+			Message: "unknown market",
+		}
+	}
+	resp, err := msgjson.NewResponse(msg.ID, trades, nil)
+	if err != nil {
+		log.Errorf("error encoding 'recent_trades' response: %v", err)
+		return &msgjson.Error{
+			Code:    msgjson.RPCInternalError,
+			Message: "internal encoding error",
+		}
+	}
+	if err = conn.Send(resp); err != nil {
+		log.Debugf("error sending 'recent_trades' response: %v", err)
+	}
+	return nil
+}
+
 // handleUnsubOrderBook is the handler for the non-authenticated
 // 'unsub_orderbook' route. Clients use this route to unsubscribe from an
 // order book.
@@ -702,6 +942,36 @@ func (r *BookRouter) handleFeeRate(conn comms.Link, msg *msgjson.Message) *msgjs
 	return nil
 }
 
+// handleFeeRateHistory is the handler for the non-authenticated
+// 'fee_rate_history' route. Clients use this route to retrieve the recent fee
+// rates recorded for an asset, oldest first.
+func (r *BookRouter) handleFeeRateHistory(conn comms.Link, msg *msgjson.Message) *msgjson.Error {
+	req := new(msgjson.FeeRateHistoryRequest)
+	err := msg.Unmarshal(&req)
+	if err != nil || req == nil {
+		return &msgjson.Error{
+			Code:    msgjson.RPCParseError,
+			Message: "error parsing fee_rate_history request",
+		}
+	}
+	rates := r.feeSource.RateHistory(req.AssetID, req.N)
+	if rates == nil {
+		rates = []*msgjson.FeeRateRecord{}
+	}
+	resp, err := msgjson.NewResponse(msg.ID, rates, nil)
+	if err != nil {
+		log.Errorf("error encoding 'fee_rate_history' response: %v", err)
+		return &msgjson.Error{
+			Code:    msgjson.RPCInternalError,
+			Message: "internal encoding error",
+		}
+	}
+	if err = conn.Send(resp); err != nil {
+		log.Debugf("error sending 'fee_rate_history' response: %v", err)
+	}
+	return nil
+}
+
 func (r *BookRouter) handlePriceFeeder(conn comms.Link, msg *msgjson.Message) *msgjson.Error {
 	r.spotsMtx.RLock()
 	msg, err := msgjson.NewResponse(msg.ID, r.spots, nil)
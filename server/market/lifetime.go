@@ -0,0 +1,75 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package market
+
+import (
+	"time"
+
+	"decred.org/dcrdex/dex/order"
+)
+
+// MinOrderLifetime returns how long a standing order must be on the market
+// before it may be canceled. Zero means that there is no minimum.
+func (m *Market) MinOrderLifetime() time.Duration {
+	return m.marketInfo.MinOrderLifetime
+}
+
+// MaxOrderLifetime returns how long a standing order may be on the market
+// before it is revoked. Zero means that there is no maximum.
+func (m *Market) MaxOrderLifetime() time.Duration {
+	return m.marketInfo.MaxOrderLifetime
+}
+
+// tooNewToCancel checks if an order placed at the specified time is younger
+// than the market's minimum order lifetime.
+func (m *Market) tooNewToCancel(placed time.Time) bool {
+	minLifetime := m.MinOrderLifetime()
+	return minLifetime > 0 && time.Since(placed) < minLifetime
+}
+
+// expireBookOrders revokes the booked orders that were placed more than the
+// market's max order lifetime before now. The user is not at fault, so the
+// revocations are not counted. This must be called from the epoch processing
+// pipeline before matching.
+func (m *Market) expireBookOrders(now time.Time, notifyChan chan<- *updateSignal) {
+	maxLifetime := m.MaxOrderLifetime()
+	if maxLifetime <= 0 {
+		return
+	}
+	cutoff := now.Add(-maxLifetime)
+
+	m.bookMtx.Lock()
+	var expired []*order.LimitOrder
+	for _, lo := range append(m.book.BuyOrders(), m.book.SellOrders()...) {
+		if !lo.ServerTime.Before(cutoff) {
+			continue
+		}
+		if _, ok := m.book.Remove(lo.ID()); ok {
+			delete(m.settling, lo.ID()) // no order completion credit
+			expired = append(expired, lo)
+		}
+	}
+	m.bookMtx.Unlock()
+
+	if len(expired) == 0 {
+		return
+	}
+	log.Infof("Revoking %d orders on market %s that are older than the max order lifetime of %v.",
+		len(expired), m.marketInfo.Name, maxLifetime)
+
+	for _, lo := range expired {
+		m.unlockOrderCoins(lo)
+		if _, _, err := m.storage.RevokeOrderUncounted(lo); err != nil {
+			log.Errorf("Failed to revoke expired order %v: %v", lo, err)
+		}
+		m.sendRevokeOrderNote(lo.ID(), lo.User())
+		notifyChan <- &updateSignal{
+			action: unbookAction,
+			data: sigDataUnbookedOrder{
+				order:    lo,
+				epochIdx: -1, // NOTE: no epoch
+			},
+		}
+	}
+}
@@ -45,11 +45,13 @@ const (
 	ErrEpochMissed            = Error("order unexpectedly missed its intended epoch")
 	ErrDuplicateOrder         = Error("order already in epoch") // maybe remove since this is ill defined
 	ErrQuantityTooHigh        = Error("order quantity exceeds user limit")
+	ErrTooManyOpenOrders      = Error("too many standing orders on the market")
 	ErrDuplicateCancelOrder   = Error("equivalent cancel order already in epoch")
 	ErrTooManyCancelOrders    = Error("too many cancel orders in current epoch")
 	ErrCancelNotPermitted     = Error("cancel order account does not match targeted order account")
 	ErrTargetNotActive        = Error("target order not active on this market")
 	ErrTargetNotCancelable    = Error("targeted order is not a limit order with standing time-in-force")
+	ErrTargetTooNew           = Error("targeted order is younger than the market's minimum order lifetime")
 	ErrSuspendedAccount       = Error("suspended account")
 	ErrMalformedOrderResponse = Error("malformed order response")
 	ErrInternalServer         = Error("internal server error")
@@ -98,6 +100,9 @@ type Config struct {
 	Balancer         Balancer
 	CheckParcelLimit func(user account.AccountID, calcParcels MarketParcelCalculator) bool
 	MinimumRate      uint64
+	// ParamsChanged, if set, is called when the lot size and rate step of a
+	// retune take effect at the start of the retune's first epoch.
+	ParamsChanged func(lotSize, rateStep uint64)
 }
 
 // Market is the market manager. It should not be overly involved with details
@@ -147,6 +152,7 @@ type Market struct {
 	persistBook      bool
 	epochCommitments map[order.Commitment]order.OrderID
 	epochOrders      map[order.OrderID]order.Order
+	retune           *marketRetune
 
 	matcher *matcher.Matcher
 	swapper Swapper
@@ -173,7 +179,14 @@ type Market struct {
 
 	checkParcelLimit func(user account.AccountID, calcParcels MarketParcelCalculator) bool
 
+	// paramsMtx guards lotSize, rateStep, and minimumRate, which may be
+	// changed by Retune.
+	paramsMtx   sync.RWMutex
+	lotSize     uint64
+	rateStep    uint64
 	minimumRate uint64
+
+	paramsChanged func(lotSize, rateStep uint64)
 }
 
 // Storage is the DB interface required by Market.
@@ -185,6 +198,7 @@ type Storage interface {
 	InsertEpoch(ed *db.EpochResults) error
 	LastEpochRate(base, quote uint32) (uint64, error)
 	MarketMatches(base, quote uint32) ([]*db.MatchDataWithCoins, error)
+	MarketMatchesStreaming(base, quote uint32, includeInactive bool, N int64, f func(*db.MatchDataWithCoins) error) (int, error)
 	InsertMatch(match *order.Match) error
 }
 
@@ -505,7 +519,10 @@ ordersLoop:
 		dataCollector:    cfg.DataCollector,
 		lastRate:         lastEpochEndRate,
 		checkParcelLimit: cfg.CheckParcelLimit,
+		lotSize:          mktInfo.LotSize,
+		rateStep:         mktInfo.RateStep,
 		minimumRate:      cfg.MinimumRate,
+		paramsChanged:    cfg.ParamsChanged,
 	}, nil
 }
 
@@ -621,6 +638,8 @@ type Status struct {
 	SuspendEpoch  int64
 	PersistBook   bool
 	Base, Quote   uint32
+	LotSize       uint64
+	RateStep      uint64
 }
 
 // Status returns the current operating state of the Market.
@@ -636,6 +655,8 @@ func (m *Market) Status() *Status {
 		PersistBook:   m.persistBook,
 		Base:          m.marketInfo.Base,
 		Quote:         m.marketInfo.Quote,
+		LotSize:       m.LotSize(),
+		RateStep:      m.RateStep(),
 	}
 }
 
@@ -664,6 +685,17 @@ func (m *Market) EpochDuration() uint64 {
 	return m.marketInfo.EpochDuration
 }
 
+// MinOrderLots returns the market's minimum trade order quantity in lots.
+func (m *Market) MinOrderLots() uint64 {
+	return uint64(m.marketInfo.MinOrderLots)
+}
+
+// MaxOpenOrders returns the maximum number of standing orders that an account
+// may have on the market. Zero means that there is no limit.
+func (m *Market) MaxOpenOrders() uint32 {
+	return m.marketInfo.MaxOpenOrders
+}
+
 // MarketBuyBuffer returns the Market's market-buy buffer.
 func (m *Market) MarketBuyBuffer() float64 {
 	return m.marketInfo.MarketBuyBuffer
@@ -671,12 +703,16 @@ func (m *Market) MarketBuyBuffer() float64 {
 
 // LotSize returns the market's lot size in units of the base asset.
 func (m *Market) LotSize() uint64 {
-	return m.marketInfo.LotSize
+	m.paramsMtx.RLock()
+	defer m.paramsMtx.RUnlock()
+	return m.lotSize
 }
 
 // RateStep returns the market's rate step in units of the quote asset.
 func (m *Market) RateStep() uint64 {
-	return m.marketInfo.RateStep
+	m.paramsMtx.RLock()
+	defer m.paramsMtx.RUnlock()
+	return m.rateStep
 }
 
 // Base is the base asset ID.
@@ -701,6 +737,34 @@ func (m *Market) OrderFeed() <-chan *updateSignal {
 	return bookUpdates
 }
 
+// RecentTrades retrieves up to n of the market's most recent trades from
+// storage, newest first, for seeding the public trade tape. As with the
+// epoch_report matches, consecutive matches in an epoch at the same rate and
+// taker side are combined.
+func (m *Market) RecentTrades(n int) ([]*msgjson.PublicTrade, error) {
+	var trades []*msgjson.PublicTrade
+	var last *msgjson.PublicTrade
+	_, err := m.storage.MarketMatchesStreaming(m.Base(), m.Quote(), true, int64(n), func(md *db.MatchDataWithCoins) error {
+		stamp := uint64(md.Epoch.End().UnixMilli())
+		if last != nil && last.Stamp == stamp && last.Rate == md.Rate && last.Sell == md.TakerSell {
+			last.Qty += md.Quantity
+			return nil
+		}
+		last = &msgjson.PublicTrade{
+			Rate:  md.Rate,
+			Qty:   md.Quantity,
+			Sell:  md.TakerSell,
+			Stamp: stamp,
+		}
+		trades = append(trades, last)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
+
 // FeedDone informs the market that the caller is finished receiving from the
 // given channel, which should have been obtained from OrderFeed. If the channel
 // was a registered order feed channel from OrderFeed, it is closed and removed
@@ -764,10 +828,15 @@ func (m *Market) processCancelOrderWhileSuspended(rec *orderRecord, errChan chan
 		return
 	}
 
-	if cancelable, _, err := m.CancelableBy(co.TargetOrderID, co.AccountID); !cancelable {
+	cancelable, loTime, err := m.CancelableBy(co.TargetOrderID, co.AccountID)
+	if !cancelable {
 		errChan <- err
 		return
 	}
+	if m.tooNewToCancel(loTime) {
+		errChan <- ErrTargetTooNew
+		return
+	}
 
 	m.bookMtx.Lock()
 	delete(m.settling, co.TargetOrderID)
@@ -1189,7 +1258,7 @@ func (m *Market) AccountPending(acctAddr string, assetID uint32) (qty, lots uint
 		midGap = m.RateStep()
 	}
 
-	lotSize := m.marketInfo.LotSize
+	lotSize := m.LotSize()
 	switch assetID {
 	case base:
 		m.iterateBaseAccount(acctAddr, func(trade *order.Trade, rate uint64) {
@@ -1526,6 +1595,12 @@ func (m *Market) Run(ctx context.Context) {
 		nextEpochIdx = currentEpoch.Epoch + 1
 		m.activeEpochIdx = currentEpoch.Epoch
 
+		// Orders in this epoch and later are validated with any retuned
+		// parameters. The book is retuned in processReadyEpoch.
+		if rt := m.retune; rt != nil && !rt.paramsSet && currentEpoch.Epoch >= rt.epochIdx {
+			m.setRetunedParams(rt)
+		}
+
 		if !running {
 			// Check that both blockchains are synced before actually starting.
 			synced, err := m.swapper.ChainsSynced(m.marketInfo.Base, m.marketInfo.Quote)
@@ -1620,6 +1695,14 @@ func (m *Market) Run(ctx context.Context) {
 				continue
 			}
 
+			// Orders validated just before a retune may not be compatible with
+			// the parameters for the epoch they landed in.
+			if err := m.checkEpochParams(s.rec.order, orderEpoch.Epoch); err != nil {
+				log.Debugf("Order %v incompatible with parameters for epoch %d", s.rec.order, orderEpoch.Epoch)
+				s.errChan <- err
+				continue
+			}
+
 			// Process the order in the target epoch queue.
 			err := m.processOrder(s.rec, orderEpoch, notifyChan, s.errChan)
 			if err != nil {
@@ -1723,7 +1806,7 @@ func (m *Market) analysisHelpers() (
 		if ord.Type() == order.MarketOrderType && !ord.Trade().Sell {
 			// Market buy qty is in quote asset. Convert to base.
 			if midGap == 0 {
-				qty = m.marketInfo.LotSize // no orders on the book; call it 1 lot
+				qty = m.LotSize() // no orders on the book; call it 1 lot
 			} else {
 				qty = calc.QuoteToBase(midGap, qty)
 			}
@@ -1738,6 +1821,24 @@ func (m *Market) ParcelSize() uint32 {
 	return m.marketInfo.ParcelSize
 }
 
+// StandingOrders counts the user's standing limit orders on the market, both
+// booked and in the epoch queue.
+func (m *Market) StandingOrders(user account.AccountID) int {
+	var n int
+	m.epochMtx.RLock()
+	for _, epOrd := range m.epochOrders {
+		if epOrd.User() != user {
+			continue
+		}
+		if lo, ok := epOrd.(*order.LimitOrder); ok && lo.Force == order.StandingTiF {
+			n++
+		}
+	}
+	m.epochMtx.RUnlock()
+	_, _, bookedBuys, bookedSells := m.book.UserOrderTotals(user)
+	return n + int(bookedBuys+bookedSells)
+}
+
 // Parcels calculates the total parcels for the market with the specified
 // settling quantity. Parcels is used as part of order validation for global
 // parcel limits. Parcels is not called for the market for which the order is
@@ -1766,7 +1867,7 @@ func (m *Market) parcels(user account.AccountID, addParcelWeight uint64) float64
 
 	bookedBuyAmt, bookedSellAmt, _, _ := m.book.UserOrderTotals(user)
 	makerQty += bookedBuyAmt + bookedSellAmt
-	return calc.Parcels(makerQty+addParcelWeight, takerQty, m.marketInfo.LotSize, m.marketInfo.ParcelSize)
+	return calc.Parcels(makerQty+addParcelWeight, takerQty, m.LotSize(), m.marketInfo.ParcelSize)
 }
 
 // processOrder performs the following actions:
@@ -1835,10 +1936,29 @@ func (m *Market) processOrder(rec *orderRecord, epoch *EpochQueue, notifyChan ch
 			errChan <- err
 			return nil
 		}
+		if m.tooNewToCancel(loTime) {
+			log.Debugf("Cancel order %v (account=%v) target order %v placed at %v is too new to cancel",
+				co, co.AccountID, co.TargetOrderID, loTime)
+			errChan <- ErrTargetTooNew
+			return nil
+		}
 
 		epochGap = int32(epoch.Epoch - loTime.UnixMilli()/epoch.Duration)
 
 	} else { // Not a cancel order, check user limits.
+		// The order router checks the standing order limit too, but orders
+		// submitted together would all pass that check. Orders are processed
+		// one at a time, so the limit is enforced here.
+		if lo, ok := ord.(*order.LimitOrder); ok && lo.Force == order.StandingTiF {
+			if maxOrders := m.marketInfo.MaxOpenOrders; maxOrders > 0 {
+				if standing := m.StandingOrders(user); standing >= int(maxOrders) {
+					log.Debugf("Received order %s from user %v, who already has %d standing orders (limit %d)",
+						oid, user, standing, maxOrders)
+					errChan <- ErrTooManyOpenOrders
+					return nil
+				}
+			}
+		}
 		likelyTaker, baseQty := m.analysisHelpers()
 		orderWeight := baseQty(ord)
 		if likelyTaker(ord) {
@@ -2344,6 +2464,12 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 		return
 	}
 
+	// Retune the book before matching the first epoch with new parameters.
+	m.retuneBook(epoch.Epoch, notifyChan)
+
+	// Revoke the book orders that are past the market's max order lifetime.
+	m.expireBookOrders(time.Now(), notifyChan)
+
 	// Get the base and quote fee rates.
 	// NOTE: We might consider moving this before the match cycle and abandoning
 	// the match cycle when no fee rate can be found (on mainnet). The only
@@ -2375,6 +2501,12 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 	// Perform order matching using the preimages to shuffle the queue.
 	m.bookMtx.Lock()        // allow a coherent view of book orders with (*Market).Book
 	matchTime := time.Now() // considered as the time at which matched cancel orders are executed
+	// Record the book that the revealed orders are matched against so that the
+	// matching may be replayed.
+	var epochBook *db.EpochBook
+	if len(ordersRevealed) > 0 {
+		epochBook = bookSnapshot(m.book)
+	}
 	seed, matches, _, failed, doneOK, partial, booked, nomatched, unbooked, updates, stats := m.matcher.Match(m.book, ordersRevealed)
 	m.bookEpochIdx = epoch.Epoch + 1
 	epochDur := int64(m.EpochDuration())
@@ -2461,6 +2593,7 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 		LowRate:        stats.LowRate,
 		StartRate:      stats.StartRate,
 		EndRate:        stats.EndRate,
+		Book:           epochBook,
 	})
 	if err != nil {
 		// fatal backend error, do not begin new swaps.
@@ -2676,7 +2809,7 @@ func (m *Market) processReadyEpoch(epoch *readyEpoch, notifyChan chan<- *updateS
 	}
 }
 
-// validateOrder uses db.ValidateOrder to ensure that the provided order is
+// validateOrder uses order.ValidateOrder to ensure that the provided order is
 // valid for the current market with epoch order status.
 func (m *Market) validateOrder(ord order.Order) error {
 	// First check the order commitment before bothering the Market's run loop.
@@ -2686,11 +2819,12 @@ func (m *Market) validateOrder(ord order.Order) error {
 		return ErrInvalidCommitment
 	}
 
-	if !db.ValidateOrder(ord, order.OrderStatusEpoch, m.marketInfo) {
+	lotSize, _, minRate := m.params()
+	if order.ValidateOrder(ord, order.OrderStatusEpoch, lotSize) != nil {
 		return ErrInvalidOrder // non-specific
 	}
 
-	if lo, is := ord.(*order.LimitOrder); is && lo.Rate < m.minimumRate {
+	if lo, is := ord.(*order.LimitOrder); is && lo.Rate < minRate {
 		return ErrInvalidRate
 	}
 
@@ -42,11 +42,13 @@ type TArchivist struct {
 	archivedCancels      []*order.CancelOrder
 	epochInserted        chan struct{}
 	revoked              order.Order
+	matches              []*db.MatchDataWithCoins // newest first
 }
 
-func (ta *TArchivist) Close() error           { return nil }
-func (ta *TArchivist) LastErr() error         { return nil }
-func (ta *TArchivist) Fatal() <-chan struct{} { return nil }
+func (ta *TArchivist) Close() error               { return nil }
+func (ta *TArchivist) LastErr() error             { return nil }
+func (ta *TArchivist) Ping(context.Context) error { return nil }
+func (ta *TArchivist) Fatal() <-chan struct{}     { return nil }
 func (ta *TArchivist) Order(oid order.OrderID, base, quote uint32) (order.Order, order.OrderStatus, error) {
 	return nil, order.OrderStatusUnknown, errors.New("boom")
 }
@@ -61,6 +63,21 @@ func (ta *TArchivist) EpochOrders(base, quote uint32) ([]order.Order, error) {
 func (ta *TArchivist) MarketMatches(base, quote uint32) ([]*db.MatchDataWithCoins, error) {
 	return nil, nil
 }
+func (ta *TArchivist) MarketMatchesStreaming(base, quote uint32, includeInactive bool, N int64, f func(*db.MatchDataWithCoins) error) (int, error) {
+	ta.mtx.Lock()
+	defer ta.mtx.Unlock()
+	var n int
+	for _, m := range ta.matches {
+		if N > 0 && int64(n) == N {
+			break
+		}
+		if err := f(m); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
 func (ta *TArchivist) FlushBook(base, quote uint32) (sells, buys []order.OrderID, err error) {
 	ta.mtx.Lock()
 	defer ta.mtx.Unlock()
@@ -2102,3 +2119,301 @@ func TestMarket_AccountPending(t *testing.T) {
 	checkPending("with-epoch-market-buy-matic", maticAddr, assetMATIC.ID, totalQty, totalBuyLots, redeems)
 	checkPending("with-epoch-market-buy-eth", ethAddr, assetETH.ID, totalSellLots*dcrLotSize, totalSellLots, int(totalBuyLots))
 }
+
+func TestMarket_StandingOrders(t *testing.T) {
+	mkt, _, _, cleanup, err := newTestMarket()
+	if err != nil {
+		t.Fatalf("newTestMarket failure: %v", err)
+	}
+	defer cleanup()
+
+	user := buyer3.Acct
+	checkStanding := func(tag string, exp int) {
+		t.Helper()
+		if n := mkt.StandingOrders(user); n != exp {
+			t.Fatalf("%s: wanted %d standing orders, got %d", tag, exp, n)
+		}
+	}
+	checkStanding("empty", 0)
+
+	// Booked orders are counted.
+	booked := makeLO(buyer3, mkRate3(0.8, 1.0), 1, order.StandingTiF)
+	if !mkt.book.Insert(booked) {
+		t.Fatalf("Failed to Insert order into book.")
+	}
+	checkStanding("booked", 1)
+
+	// Standing orders in the epoch queue are counted, but immediate and
+	// cancel orders are not.
+	lo := makeLO(buyer3, mkRate3(0.8, 1.0), 1, order.StandingTiF)
+	mkt.epochOrders[lo.ID()] = lo
+	lo = makeLO(buyer3, mkRate3(0.8, 1.0), 1, order.ImmediateTiF)
+	mkt.epochOrders[lo.ID()] = lo
+	co := makeCO(buyer3, booked.ID())
+	mkt.epochOrders[co.ID()] = co
+	checkStanding("epoch", 2)
+
+	// Other users' orders are not counted.
+	lo = makeLO(seller3, mkRate3(1.0, 1.2), 1, order.StandingTiF)
+	if !mkt.book.Insert(lo) {
+		t.Fatalf("Failed to Insert order into book.")
+	}
+	checkStanding("other user", 2)
+
+	// A canceled order frees capacity.
+	if _, removed := mkt.book.Remove(booked.ID()); !removed {
+		t.Fatalf("Failed to Remove order from book.")
+	}
+	checkStanding("canceled", 1)
+}
+
+func TestMarket_RecentTrades(t *testing.T) {
+	const epochDur = 500
+	match := func(epochIdx, rate, qty uint64, takerSell bool) *db.MatchDataWithCoins {
+		return &db.MatchDataWithCoins{
+			MatchData: db.MatchData{
+				TakerSell: takerSell,
+				Epoch:     order.EpochID{Idx: epochIdx, Dur: epochDur},
+				Quantity:  qty,
+				Rate:      rate,
+			},
+		}
+	}
+	storage := &TArchivist{
+		matches: []*db.MatchDataWithCoins{
+			match(11, 6e7, 1e8, true),
+			match(11, 6e7, 2e8, true), // combined with the previous
+			match(11, 6e7, 3e8, false),
+			match(10, 6e7, 4e8, false), // same rate and side, but a different epoch
+			match(10, 5e7, 5e8, true),
+		},
+	}
+
+	mkt, _, _, cleanup, err := newTestMarket(storage)
+	if err != nil {
+		t.Fatalf("newTestMarket failure: %v", err)
+	}
+	defer cleanup()
+
+	expTrades := []*msgjson.PublicTrade{
+		{Rate: 6e7, Qty: 3e8, Sell: true, Stamp: 12 * epochDur},
+		{Rate: 6e7, Qty: 3e8, Sell: false, Stamp: 12 * epochDur},
+		{Rate: 6e7, Qty: 4e8, Sell: false, Stamp: 11 * epochDur},
+		{Rate: 5e7, Qty: 5e8, Sell: true, Stamp: 11 * epochDur},
+	}
+	trades, err := mkt.RecentTrades(0)
+	if err != nil {
+		t.Fatalf("RecentTrades error: %v", err)
+	}
+	if len(trades) != len(expTrades) {
+		t.Fatalf("expected %d trades, got %d", len(expTrades), len(trades))
+	}
+	for i, trade := range trades {
+		if *trade != *expTrades[i] {
+			t.Fatalf("wrong trade %d. expected %+v, got %+v", i, expTrades[i], trade)
+		}
+	}
+
+	// The limit applies to the stored matches.
+	trades, err = mkt.RecentTrades(2)
+	if err != nil {
+		t.Fatalf("RecentTrades error: %v", err)
+	}
+	if len(trades) != 1 || *trades[0] != *expTrades[0] {
+		t.Fatalf("wrong trades for a limit of 2: %+v", trades)
+	}
+}
+
+func TestMarket_Retune(t *testing.T) {
+	storage := &TArchivist{}
+	const rate = 100 * dcrLotSize
+	newLotSize, newRateStep := uint64(2*dcrLotSize), uint64(10*btcRateStep)
+	loKeep := makeLO(buyer3, rate, 4, order.StandingTiF)
+	loBadLot := makeLO(buyer3, rate, 3, order.StandingTiF)
+	loBadRate := makeLO(buyer3, rate+btcRateStep, 2, order.StandingTiF)
+	for _, lo := range []*order.LimitOrder{loKeep, loBadLot, loBadRate} {
+		_ = storage.BookOrder(lo) // the stub does not error
+	}
+
+	mkt, _, auth, cleanup, err := newTestMarket(storage)
+	if err != nil {
+		t.Fatalf("newTestMarket failure: %v", err)
+	}
+	defer cleanup()
+
+	var changedLotSize, changedRateStep uint64
+	mkt.paramsChanged = func(lotSize, rateStep uint64) {
+		changedLotSize, changedRateStep = lotSize, rateStep
+	}
+
+	if _, err = mkt.Retune(0, newRateStep, 0); err == nil {
+		t.Fatalf("no error for zero lot size")
+	}
+	if _, err = mkt.Retune(newLotSize, 0, 0); err == nil {
+		t.Fatalf("no error for zero rate step")
+	}
+
+	// The market is not running, so the retune applies to the first epoch.
+	epochIdx, err := mkt.Retune(newLotSize, newRateStep, 0)
+	if err != nil {
+		t.Fatalf("Retune error: %v", err)
+	}
+	if epochIdx != 0 {
+		t.Fatalf("expected epoch 0, got %d", epochIdx)
+	}
+	if mkt.LotSize() != dcrLotSize || mkt.RateStep() != btcRateStep {
+		t.Fatalf("parameters changed before the retune epoch")
+	}
+	if changedLotSize != 0 || changedRateStep != 0 {
+		t.Fatalf("parameter change reported before the retune epoch")
+	}
+
+	// Switch to the new parameters as cycleEpoch would.
+	mkt.epochMtx.Lock()
+	mkt.setRetunedParams(mkt.retune)
+	mkt.epochMtx.Unlock()
+	if mkt.LotSize() != newLotSize || mkt.RateStep() != newRateStep {
+		t.Fatalf("parameters not changed")
+	}
+	if changedLotSize != newLotSize || changedRateStep != newRateStep {
+		t.Fatalf("parameter change not reported")
+	}
+	if _, err = mkt.Retune(dcrLotSize, btcRateStep, 0); err == nil {
+		t.Fatalf("no error for retune in progress")
+	}
+	if err = mkt.checkEpochParams(loBadLot, 0); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("expected ErrInvalidOrder for incompatible lot size, got %v", err)
+	}
+	if err = mkt.checkEpochParams(loKeep, 0); err != nil {
+		t.Fatalf("checkEpochParams error for compatible order: %v", err)
+	}
+
+	// Retune the book before matching.
+	notifyChan := make(chan *updateSignal, 3)
+	mkt.retuneBook(0, notifyChan)
+	if len(notifyChan) != 2 {
+		t.Fatalf("expected 2 unbook notifications, got %d", len(notifyChan))
+	}
+	_, buys, _ := mkt.Book()
+	if len(buys) != 1 || buys[0].ID() != loKeep.ID() {
+		t.Fatalf("expected only the compatible order to remain booked, got %d orders", len(buys))
+	}
+	if mkt.book.LotSize() != newLotSize {
+		t.Fatalf("book lot size not updated")
+	}
+	var revokes int
+	for _, msg := range auth.sends {
+		if msg.Route == msgjson.RevokeOrderRoute {
+			revokes++
+		}
+	}
+	if revokes != 2 {
+		t.Fatalf("expected 2 revoke_order notes, got %d", revokes)
+	}
+	if mkt.retune != nil {
+		t.Fatalf("retune not cleared")
+	}
+}
+
+func TestMarket_OrderLifetimes(t *testing.T) {
+	storage := &TArchivist{}
+	const rate = 100 * dcrLotSize
+	now := time.Now()
+	loOld := makeLO(buyer3, rate, 1, order.StandingTiF)
+	loOld.SetTime(now.Add(-2 * time.Hour))
+	loNew := makeLO(buyer3, rate, 2, order.StandingTiF)
+	loNew.SetTime(now)
+	for _, lo := range []*order.LimitOrder{loOld, loNew} {
+		_ = storage.BookOrder(lo) // the stub does not error
+	}
+
+	mkt, _, auth, cleanup, err := newTestMarket(storage)
+	if err != nil {
+		t.Fatalf("newTestMarket failure: %v", err)
+	}
+	defer cleanup()
+	mkt.marketInfo.MinOrderLifetime = time.Minute
+	mkt.marketInfo.MaxOrderLifetime = time.Hour
+
+	// A cancel of an order younger than the min lifetime is refused.
+	errChan := make(chan error, 1)
+	mkt.processCancelOrderWhileSuspended(&orderRecord{order: makeCO(buyer3, loNew.ID())}, errChan)
+	if err := <-errChan; !errors.Is(err, ErrTargetTooNew) {
+		t.Fatalf("expected ErrTargetTooNew, got %v", err)
+	}
+	if !mkt.book.HaveOrder(loNew.ID()) {
+		t.Fatalf("order unbooked by a refused cancel")
+	}
+	if !mkt.tooNewToCancel(now) || mkt.tooNewToCancel(loOld.ServerTime) {
+		t.Fatalf("wrong min lifetime check")
+	}
+
+	// Orders past the max lifetime are revoked before matching.
+	notifyChan := make(chan *updateSignal, 2)
+	mkt.expireBookOrders(now, notifyChan)
+	if len(notifyChan) != 1 {
+		t.Fatalf("expected 1 unbook notification, got %d", len(notifyChan))
+	}
+	if sig := <-notifyChan; sig.action != unbookAction || sig.data.(sigDataUnbookedOrder).order.ID() != loOld.ID() {
+		t.Fatalf("wrong notification for the expired order: %+v", sig)
+	}
+	_, buys, _ := mkt.Book()
+	if len(buys) != 1 || buys[0].ID() != loNew.ID() {
+		t.Fatalf("expected only the newer order to remain booked, got %d orders", len(buys))
+	}
+	var revokes int
+	for _, msg := range auth.sends {
+		if msg.Route == msgjson.RevokeOrderRoute {
+			revokes++
+		}
+	}
+	if revokes != 1 {
+		t.Fatalf("expected 1 revoke_order note, got %d", revokes)
+	}
+
+	// No maximum.
+	mkt.marketInfo.MaxOrderLifetime = 0
+	mkt.expireBookOrders(now.Add(24*time.Hour), notifyChan)
+	if len(notifyChan) != 0 || !mkt.book.HaveOrder(loNew.ID()) {
+		t.Fatalf("order revoked without a max lifetime")
+	}
+}
+
+func TestMarket_processOrderOpenOrderLimit(t *testing.T) {
+	mkt, _, _, cleanup, err := newTestMarket()
+	if err != nil {
+		t.Fatalf("newTestMarket failure: %v", err)
+	}
+	defer cleanup()
+	mkt.marketInfo.MaxOpenOrders = 1
+
+	epoch := NewEpoch(1, int64(mkt.marketInfo.EpochDuration))
+	notifyChan := make(chan *updateSignal, 1)
+	process := func(lo *order.LimitOrder) error {
+		t.Helper()
+		errChan := make(chan error, 1)
+		if err := mkt.processOrder(&orderRecord{order: lo}, epoch, notifyChan, errChan); err != nil {
+			t.Fatalf("processOrder error: %v", err)
+		}
+		return <-errChan
+	}
+
+	// A burst of orders all pass the order router's check before any of them
+	// enter the epoch queue. Once the first is in the epoch queue, the rest
+	// are refused.
+	first := makeLO(buyer3, mkRate3(0.8, 1.0), 1, order.StandingTiF)
+	mkt.epochOrders[first.ID()] = first
+	lo := makeLO(buyer3, mkRate3(0.8, 1.0), 1, order.StandingTiF)
+	if err := process(lo); !errors.Is(err, ErrTooManyOpenOrders) {
+		t.Fatalf("wrong error for a standing order over the limit in the epoch queue: %v", err)
+	}
+
+	// Booked orders count too.
+	delete(mkt.epochOrders, first.ID())
+	if !mkt.book.Insert(first) {
+		t.Fatalf("Failed to Insert order into book.")
+	}
+	if err := process(lo); !errors.Is(err, ErrTooManyOpenOrders) {
+		t.Fatalf("wrong error for a standing order over the limit on the book: %v", err)
+	}
+}
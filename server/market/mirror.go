@@ -0,0 +1,270 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/msgjson"
+)
+
+// mirrorRetryDelay is how long a Mirror waits before resubscribing to its
+// feed after a subscription fails or ends.
+var mirrorRetryDelay = 10 * time.Second
+
+// MirrorFeed is the public order book feed of a market on another DEX.
+type MirrorFeed interface {
+	// Subscribe subscribes to the market's order book, returning a snapshot
+	// of the book and a channel of the notifications that follow it, e.g.
+	// book_order, unbook_order, update_remaining. The channel is closed if
+	// the subscription ends, and the feed should unsubscribe when the
+	// context is canceled.
+	Subscribe(ctx context.Context) (*msgjson.OrderBook, <-chan *msgjson.Message, error)
+}
+
+// MirrorConfig is the configuration for a Mirror.
+type MirrorConfig struct {
+	// Source identifies the DEX whose book is mirrored, e.g. its host.
+	Source string
+	Base   uint32
+	Quote  uint32
+	Feed   MirrorFeed
+}
+
+// Mirror maintains a read-only copy of another DEX's order book for a market.
+// A mirrored market is for display only. It does no matching, and the
+// OrderRouter rejects any order for a market that is mirrored.
+type Mirror struct {
+	source string
+	name   string
+	feed   MirrorFeed
+
+	mtx     sync.RWMutex
+	synced  bool
+	seq     uint64
+	updated time.Time
+	orders  map[string]*msgjson.BookOrderNote
+}
+
+// NewMirror is the constructor for a Mirror.
+func NewMirror(cfg *MirrorConfig) (*Mirror, error) {
+	name, err := dex.MarketName(cfg.Base, cfg.Quote)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Feed == nil {
+		return nil, errors.New("no mirror feed")
+	}
+	return &Mirror{
+		source: cfg.Source,
+		name:   name,
+		feed:   cfg.Feed,
+		orders: make(map[string]*msgjson.BookOrderNote),
+	}, nil
+}
+
+// Name is the name of the mirrored market.
+func (m *Mirror) Name() string {
+	return m.name
+}
+
+// Source identifies the DEX whose book is mirrored.
+func (m *Mirror) Source() string {
+	return m.source
+}
+
+// Run subscribes to the feed and applies its updates to the book until the
+// context is canceled. If the subscription fails or ends, or an update is
+// missed, the book is marked as unsynced and the feed is resubscribed.
+func (m *Mirror) Run(ctx context.Context) {
+	for {
+		if err := m.follow(ctx); err != nil {
+			log.Errorf("Mirror of market %s from %s: %v", m.name, m.source, err)
+		}
+		m.mtx.Lock()
+		m.synced = false
+		m.mtx.Unlock()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(mirrorRetryDelay):
+		}
+	}
+}
+
+// follow syncs the book with a new subscription to the feed, and applies the
+// subscription's updates until it ends.
+func (m *Mirror) follow(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	book, notes, err := m.feed.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("subscription error: %w", err)
+	}
+	if err := m.sync(book); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case note, ok := <-notes:
+			if !ok {
+				return errors.New("feed closed")
+			}
+			if err := m.apply(note); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sync replaces the book with the snapshot.
+func (m *Mirror) sync(book *msgjson.OrderBook) error {
+	if book.MarketID != m.name {
+		return fmt.Errorf("snapshot for wrong market %s", book.MarketID)
+	}
+	orders := make(map[string]*msgjson.BookOrderNote, len(book.Orders))
+	for _, o := range book.Orders {
+		orders[string(o.OrderID)] = o
+	}
+	m.mtx.Lock()
+	m.orders = orders
+	m.seq = book.Seq
+	m.synced = true
+	m.updated = time.Now()
+	m.mtx.Unlock()
+	return nil
+}
+
+// apply applies a notification from the feed to the book. Notifications that
+// do not update the book are ignored, except that epoch_order notifications
+// still advance the sequence. An error is returned if the notification is out
+// of sequence, in which case the book must be synced again.
+func (m *Mirror) apply(msg *msgjson.Message) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	checkSeq := func(seq uint64) error {
+		if seq != m.seq+1 {
+			return fmt.Errorf("out of sequence %s note. expected seq %d, got %d", msg.Route, m.seq+1, seq)
+		}
+		m.seq = seq
+		m.updated = time.Now()
+		return nil
+	}
+
+	switch msg.Route {
+	case msgjson.BookOrderRoute:
+		note := new(msgjson.BookOrderNote)
+		if err := msg.Unmarshal(note); err != nil {
+			return fmt.Errorf("error decoding %s note: %w", msg.Route, err)
+		}
+		if err := checkSeq(note.Seq); err != nil {
+			return err
+		}
+		m.orders[string(note.OrderID)] = note
+	case msgjson.UnbookOrderRoute:
+		note := new(msgjson.UnbookOrderNote)
+		if err := msg.Unmarshal(note); err != nil {
+			return fmt.Errorf("error decoding %s note: %w", msg.Route, err)
+		}
+		if err := checkSeq(note.Seq); err != nil {
+			return err
+		}
+		delete(m.orders, string(note.OrderID))
+	case msgjson.UpdateRemainingRoute:
+		note := new(msgjson.UpdateRemainingNote)
+		if err := msg.Unmarshal(note); err != nil {
+			return fmt.Errorf("error decoding %s note: %w", msg.Route, err)
+		}
+		if err := checkSeq(note.Seq); err != nil {
+			return err
+		}
+		if o := m.orders[string(note.OrderID)]; o != nil {
+			o.Quantity = note.Remaining
+		}
+	case msgjson.EpochOrderRoute:
+		note := new(msgjson.EpochOrderNote)
+		if err := msg.Unmarshal(note); err != nil {
+			return fmt.Errorf("error decoding %s note: %w", msg.Route, err)
+		}
+		return checkSeq(note.Seq)
+	case msgjson.SuspensionRoute:
+		// A suspension with a sequence number purges the book.
+		note := new(msgjson.TradeSuspension)
+		if err := msg.Unmarshal(note); err != nil {
+			return fmt.Errorf("error decoding %s note: %w", msg.Route, err)
+		}
+		if note.Seq == 0 {
+			return nil
+		}
+		if err := checkSeq(note.Seq); err != nil {
+			return err
+		}
+		m.orders = make(map[string]*msgjson.BookOrderNote)
+	}
+	return nil
+}
+
+// MirroredBook is a display-only copy of another DEX's order book. Buys and
+// Sells are sorted best rate first.
+type MirroredBook struct {
+	// Mirrored is always true, flagging that the book is not this DEX's, and
+	// that orders are not accepted for the market.
+	Mirrored bool   `json:"mirrored"`
+	Source   string `json:"source"`
+	MarketID string `json:"marketid"`
+	// Synced is false if the book is not following the feed, and may be
+	// stale.
+	Synced bool `json:"synced"`
+	// Updated is the time of the last update to the book, in milliseconds.
+	Updated uint64                   `json:"updated"`
+	Buys    []*msgjson.BookOrderNote `json:"buys"`
+	Sells   []*msgjson.BookOrderNote `json:"sells"`
+}
+
+// Book creates a copy of the mirrored book.
+func (m *Mirror) Book() *MirroredBook {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+	book := &MirroredBook{
+		Mirrored: true,
+		Source:   m.source,
+		MarketID: m.name,
+		Synced:   m.synced,
+		Buys:     make([]*msgjson.BookOrderNote, 0, len(m.orders)),
+		Sells:    make([]*msgjson.BookOrderNote, 0, len(m.orders)),
+	}
+	if !m.updated.IsZero() {
+		book.Updated = uint64(m.updated.UnixMilli())
+	}
+	for _, o := range m.orders {
+		oCopy := *o
+		if o.Side == msgjson.SellOrderNum {
+			book.Sells = append(book.Sells, &oCopy)
+		} else {
+			book.Buys = append(book.Buys, &oCopy)
+		}
+	}
+	sort.Slice(book.Buys, func(i, j int) bool {
+		if book.Buys[i].Rate == book.Buys[j].Rate {
+			return book.Buys[i].Time < book.Buys[j].Time
+		}
+		return book.Buys[i].Rate > book.Buys[j].Rate
+	})
+	sort.Slice(book.Sells, func(i, j int) bool {
+		if book.Sells[i].Rate == book.Sells[j].Rate {
+			return book.Sells[i].Time < book.Sells[j].Time
+		}
+		return book.Sells[i].Rate < book.Sells[j].Rate
+	})
+	return book
+}
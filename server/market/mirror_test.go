@@ -0,0 +1,212 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package market
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex/msgjson"
+)
+
+type tMirrorFeed struct {
+	mtx   sync.Mutex
+	book  *msgjson.OrderBook
+	notes chan *msgjson.Message
+	subs  chan struct{}
+}
+
+func newTMirrorFeed(book *msgjson.OrderBook) *tMirrorFeed {
+	return &tMirrorFeed{
+		book: book,
+		subs: make(chan struct{}, 2),
+	}
+}
+
+func (f *tMirrorFeed) Subscribe(ctx context.Context) (*msgjson.OrderBook, <-chan *msgjson.Message, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.notes = make(chan *msgjson.Message, 16)
+	f.subs <- struct{}{}
+	return f.book, f.notes, nil
+}
+
+func (f *tMirrorFeed) send(t *testing.T, route string, note any) {
+	t.Helper()
+	msg, err := msgjson.NewNotification(route, note)
+	if err != nil {
+		t.Fatalf("NewNotification error: %v", err)
+	}
+	f.mtx.Lock()
+	f.notes <- msg
+	f.mtx.Unlock()
+}
+
+func tMirrorOrder(id byte, sell bool, qty, rate, seq uint64) *msgjson.BookOrderNote {
+	var side uint8 = msgjson.BuyOrderNum
+	if sell {
+		side = msgjson.SellOrderNum
+	}
+	return &msgjson.BookOrderNote{
+		OrderNote: msgjson.OrderNote{
+			Seq:      seq,
+			MarketID: "dcr_btc",
+			OrderID:  []byte{id},
+		},
+		TradeNote: msgjson.TradeNote{
+			Side:     side,
+			Quantity: qty,
+			Rate:     rate,
+		},
+	}
+}
+
+func TestMirror(t *testing.T) {
+	defer func(d time.Duration) { mirrorRetryDelay = d }(mirrorRetryDelay)
+	mirrorRetryDelay = time.Millisecond
+
+	feed := newTMirrorFeed(&msgjson.OrderBook{
+		MarketID: "dcr_btc",
+		Seq:      5,
+		Orders: []*msgjson.BookOrderNote{
+			tMirrorOrder(1, false, 10, 100, 0),
+			tMirrorOrder(2, false, 20, 90, 0),
+			tMirrorOrder(3, true, 30, 110, 0),
+		},
+	})
+	mirror, err := NewMirror(&MirrorConfig{
+		Source: "dex.example.com:7232",
+		Base:   dcrID,
+		Quote:  btcID,
+		Feed:   feed,
+	})
+	if err != nil {
+		t.Fatalf("NewMirror error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mirror.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	waitSub := func() {
+		t.Helper()
+		select {
+		case <-feed.subs:
+		case <-time.After(time.Second):
+			t.Fatalf("no subscription")
+		}
+	}
+	// checkBook waits for the book to reflect the expected orders, given as
+	// [id, qty, rate].
+	checkBook := func(tag string, buys, sells [][3]uint64) {
+		t.Helper()
+		matches := func(ords []*msgjson.BookOrderNote, exp [][3]uint64) bool {
+			if len(ords) != len(exp) {
+				return false
+			}
+			for i, o := range ords {
+				if hex.EncodeToString(o.OrderID) != hex.EncodeToString([]byte{byte(exp[i][0])}) ||
+					o.Quantity != exp[i][1] || o.Rate != exp[i][2] {
+					return false
+				}
+			}
+			return true
+		}
+		var book *MirroredBook
+		for i := 0; i < 100; i++ {
+			book = mirror.Book()
+			if book.Synced && matches(book.Buys, buys) && matches(book.Sells, sells) {
+				if !book.Mirrored || book.Source != "dex.example.com:7232" || book.MarketID != "dcr_btc" {
+					t.Fatalf("%s: book not flagged as mirrored: %+v", tag, book)
+				}
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("%s: wrong book. synced = %t, %d buys, %d sells", tag, book.Synced, len(book.Buys), len(book.Sells))
+	}
+
+	waitSub()
+	checkBook("snapshot", [][3]uint64{{1, 10, 100}, {2, 20, 90}}, [][3]uint64{{3, 30, 110}})
+
+	feed.send(t, msgjson.BookOrderRoute, tMirrorOrder(4, true, 40, 105, 6))
+	feed.send(t, msgjson.EpochOrderRoute, &msgjson.EpochOrderNote{BookOrderNote: *tMirrorOrder(5, false, 1, 1, 7)})
+	feed.send(t, msgjson.UpdateRemainingRoute, &msgjson.UpdateRemainingNote{
+		OrderNote: msgjson.OrderNote{Seq: 8, MarketID: "dcr_btc", OrderID: []byte{3}},
+		Remaining: 15,
+	})
+	feed.send(t, msgjson.UnbookOrderRoute, &msgjson.UnbookOrderNote{Seq: 9, MarketID: "dcr_btc", OrderID: []byte{1}})
+	checkBook("updates", [][3]uint64{{2, 20, 90}}, [][3]uint64{{4, 40, 105}, {3, 15, 110}})
+
+	// A missed update resyncs the book from a new subscription.
+	feed.mtx.Lock()
+	feed.book = &msgjson.OrderBook{
+		MarketID: "dcr_btc",
+		Seq:      20,
+		Orders:   []*msgjson.BookOrderNote{tMirrorOrder(6, true, 60, 120, 0)},
+	}
+	feed.mtx.Unlock()
+	feed.send(t, msgjson.BookOrderRoute, tMirrorOrder(7, false, 70, 80, 11))
+	waitSub()
+	checkBook("resync", nil, [][3]uint64{{6, 60, 120}})
+
+	// A suspension that purges the book.
+	feed.send(t, msgjson.SuspensionRoute, &msgjson.TradeSuspension{MarketID: "dcr_btc", Seq: 21})
+	checkBook("purge", nil, nil)
+}
+
+func TestMirroredMarketRejectsOrders(t *testing.T) {
+	mirror, err := NewMirror(&MirrorConfig{
+		Source: "dex.example.com:7232",
+		Base:   dcrID,
+		Quote:  btcID,
+		Feed:   newTMirrorFeed(nil),
+	})
+	if err != nil {
+		t.Fatalf("NewMirror error: %v", err)
+	}
+	oRig.router.mirrors = map[string]*Mirror{mirror.Name(): mirror}
+	defer func() { oRig.router.mirrors = nil }()
+
+	user := oRig.user
+	prefix := msgjson.Prefix{
+		AccountID:  user.acct[:],
+		Base:       dcrID,
+		Quote:      btcID,
+		ClientTime: uint64(nowMs().UnixMilli()),
+	}
+	ensureErr := makeEnsureErr(t)
+
+	limit := &msgjson.LimitOrder{Prefix: prefix, Trade: msgjson.Trade{Side: msgjson.SellOrderNum}}
+	limit.OrderType = msgjson.LimitOrderNum
+	msg, _ := msgjson.NewRequest(1, msgjson.LimitRoute, limit)
+	ensureErr("limit", oRig.router.handleLimit(user.acct, msg), msgjson.MarketNotRunningError)
+
+	market := &msgjson.MarketOrder{Prefix: prefix, Trade: msgjson.Trade{Side: msgjson.SellOrderNum}}
+	market.OrderType = msgjson.MarketOrderNum
+	msg, _ = msgjson.NewRequest(2, msgjson.MarketRoute, market)
+	ensureErr("market", oRig.router.handleMarket(user.acct, msg), msgjson.MarketNotRunningError)
+
+	cancel := &msgjson.CancelOrder{Prefix: prefix, TargetID: make([]byte, 32)}
+	cancel.OrderType = msgjson.CancelOrderNum
+	msg, _ = msgjson.NewRequest(3, msgjson.CancelRoute, cancel)
+	ensureErr("cancel", oRig.router.handleCancel(user.acct, msg), msgjson.MarketNotRunningError)
+
+	// Other markets are unaffected.
+	prefix.Quote = assetETH.ID
+	if _, rpcErr := oRig.router.extractMarket(&prefix); rpcErr != nil {
+		t.Fatalf("unmirrored market rejected: %s", rpcErr.Message)
+	}
+}
@@ -0,0 +1,191 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package market
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"decred.org/dcrdex/dex/msgjson"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// mirrorReadLimit is the largest message accepted from a mirrored DEX,
+	// which must accommodate the order book snapshot.
+	mirrorReadLimit = 1 << 26 // 64 MiB
+	// mirrorWriteWait is the time allowed to write a message to a mirrored
+	// DEX.
+	mirrorWriteWait = 10 * time.Second
+	// mirrorSubscribeTimeout is how long to wait for the order book snapshot
+	// from a mirrored DEX.
+	mirrorSubscribeTimeout = 30 * time.Second
+)
+
+// mirrorPingWait is how long to wait for a ping from a mirrored DEX before the
+// connection is considered lost.
+var mirrorPingWait = 2 * time.Minute
+
+// WsMirrorFeed is a MirrorFeed for the order book of a market on another DEX,
+// received on a websocket connection to the DEX's public API.
+type WsMirrorFeed struct {
+	url    string
+	tlsCfg *tls.Config
+	base   uint32
+	quote  uint32
+}
+
+// NewWsMirrorFeed is the constructor for a WsMirrorFeed. host is the DEX's
+// host and port. If cert is provided, it is the DEX's TLS certificate, which
+// is used in place of the system's certificate authorities.
+func NewWsMirrorFeed(host string, cert []byte, base, quote uint32) (*WsMirrorFeed, error) {
+	var tlsCfg *tls.Config
+	if len(cert) > 0 {
+		pool := x509.NewCertPool()
+		if ok := pool.AppendCertsFromPEM(cert); !ok {
+			return nil, errors.New("invalid certificate")
+		}
+		tlsCfg = &tls.Config{
+			RootCAs:    pool,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+	return &WsMirrorFeed{
+		url:    "wss://" + host + "/ws",
+		tlsCfg: tlsCfg,
+		base:   base,
+		quote:  quote,
+	}, nil
+}
+
+// Subscribe connects to the DEX and subscribes to the market's order book. The
+// connection is closed when the context is canceled. Part of the MirrorFeed
+// interface.
+func (f *WsMirrorFeed) Subscribe(ctx context.Context) (*msgjson.OrderBook, <-chan *msgjson.Message, error) {
+	dialer := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: mirrorSubscribeTimeout,
+		TLSClientConfig:  f.tlsCfg,
+	}
+	ws, _, err := dialer.DialContext(ctx, f.url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error connecting to %s: %w", f.url, err)
+	}
+	ws.SetReadLimit(mirrorReadLimit)
+
+	var writeMtx sync.Mutex
+	ws.SetPingHandler(func(string) error {
+		now := time.Now()
+		if err := ws.SetReadDeadline(now.Add(mirrorPingWait)); err != nil {
+			return err
+		}
+		writeMtx.Lock()
+		defer writeMtx.Unlock()
+		err := ws.WriteControl(websocket.PongMessage, []byte{}, now.Add(mirrorWriteWait))
+		if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+			return err
+		}
+		return nil
+	})
+
+	// Close the connection when the context is canceled, which also ends
+	// the read loop.
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		ws.Close()
+	}()
+
+	book, err := f.subscribe(ws, &writeMtx)
+	if err != nil {
+		close(done)
+		return nil, nil, err
+	}
+
+	notes := make(chan *msgjson.Message, 128)
+	go func() {
+		defer close(done)
+		defer close(notes)
+		for {
+			msg, err := readMirrorMsg(ws)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Debugf("Mirror feed from %s ended: %v", f.url, err)
+				}
+				return
+			}
+			if msg.Type != msgjson.Notification {
+				continue
+			}
+			select {
+			case notes <- msg:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return book, notes, nil
+}
+
+// subscribe sends the order book subscription request and waits for the
+// response with the book snapshot. Notifications received before the response
+// are for the book that the snapshot supersedes, and are discarded.
+func (f *WsMirrorFeed) subscribe(ws *websocket.Conn, writeMtx *sync.Mutex) (*msgjson.OrderBook, error) {
+	const reqID = 1
+	req, err := msgjson.NewRequest(reqID, msgjson.OrderBookRoute, &msgjson.OrderBookSubscription{
+		Base:  f.base,
+		Quote: f.quote,
+	})
+	if err != nil {
+		return nil, err
+	}
+	writeMtx.Lock()
+	ws.SetWriteDeadline(time.Now().Add(mirrorWriteWait))
+	err = ws.WriteJSON(req)
+	writeMtx.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("error sending subscription request: %w", err)
+	}
+
+	if err := ws.SetReadDeadline(time.Now().Add(mirrorSubscribeTimeout)); err != nil {
+		return nil, err
+	}
+	for {
+		msg, err := readMirrorMsg(ws)
+		if err != nil {
+			return nil, fmt.Errorf("error reading subscription response: %w", err)
+		}
+		if msg.Type != msgjson.Response || msg.ID != reqID {
+			continue
+		}
+		book := new(msgjson.OrderBook)
+		if err := msg.UnmarshalResult(book); err != nil {
+			return nil, fmt.Errorf("subscription error: %w", err)
+		}
+		// Subsequent read deadlines are set by the ping handler.
+		if err := ws.SetReadDeadline(time.Now().Add(mirrorPingWait)); err != nil {
+			return nil, err
+		}
+		return book, nil
+	}
+}
+
+// readMirrorMsg reads the next message from the connection.
+func readMirrorMsg(ws *websocket.Conn) (*msgjson.Message, error) {
+	_, b, err := ws.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	return msgjson.DecodeMessage(b)
+}
@@ -0,0 +1,119 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package market
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex/msgjson"
+	"github.com/gorilla/websocket"
+)
+
+func TestWsMirrorFeed(t *testing.T) {
+	book := &msgjson.OrderBook{
+		MarketID: "dcr_btc",
+		Seq:      5,
+		Orders:   []*msgjson.BookOrderNote{tMirrorOrder(1, false, 10, 100, 0)},
+	}
+	reqs := make(chan *msgjson.OrderBookSubscription, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws" {
+			http.NotFound(w, r)
+			return
+		}
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade error: %v", err)
+			return
+		}
+		defer ws.Close()
+		_, b, err := ws.ReadMessage()
+		if err != nil {
+			t.Errorf("error reading request: %v", err)
+			return
+		}
+		msg, err := msgjson.DecodeMessage(b)
+		if err != nil || msg.Type != msgjson.Request || msg.Route != msgjson.OrderBookRoute {
+			t.Errorf("unexpected request: %s", string(b))
+			return
+		}
+		sub := new(msgjson.OrderBookSubscription)
+		if err := msg.Unmarshal(sub); err != nil {
+			t.Errorf("error decoding subscription: %v", err)
+			return
+		}
+		reqs <- sub
+		// A notification before the response is discarded.
+		early, _ := msgjson.NewNotification(msgjson.BookOrderRoute, tMirrorOrder(9, false, 1, 1, 5))
+		resp, _ := msgjson.NewResponse(msg.ID, book, nil)
+		note, _ := msgjson.NewNotification(msgjson.BookOrderRoute, tMirrorOrder(2, true, 20, 110, 6))
+		for _, m := range []*msgjson.Message{early, resp, note} {
+			if err := ws.WriteJSON(m); err != nil {
+				t.Errorf("write error: %v", err)
+				return
+			}
+		}
+		// Hold the connection until the client closes it.
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	host := strings.TrimPrefix(srv.URL, "https://")
+	feed, err := NewWsMirrorFeed(host, cert, dcrID, btcID)
+	if err != nil {
+		t.Fatalf("NewWsMirrorFeed error: %v", err)
+	}
+	if _, err := NewWsMirrorFeed(host, []byte("junk"), dcrID, btcID); err == nil {
+		t.Fatalf("no error for an invalid certificate")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	snap, notes, err := feed.Subscribe(ctx)
+	if err != nil {
+		t.Fatalf("Subscribe error: %v", err)
+	}
+	if sub := <-reqs; sub.Base != dcrID || sub.Quote != btcID {
+		t.Fatalf("wrong subscription %+v", sub)
+	}
+	if snap.MarketID != "dcr_btc" || snap.Seq != 5 || len(snap.Orders) != 1 {
+		t.Fatalf("wrong snapshot %+v", snap)
+	}
+
+	select {
+	case msg := <-notes:
+		note := new(msgjson.BookOrderNote)
+		if err := msg.Unmarshal(note); err != nil {
+			t.Fatalf("error decoding note: %v", err)
+		}
+		if msg.Route != msgjson.BookOrderRoute || note.Seq != 6 {
+			t.Fatalf("wrong note %s %+v", msg.Route, note)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("no notification")
+	}
+
+	// Canceling the context ends the subscription.
+	cancel()
+	select {
+	case _, ok := <-notes:
+		if ok {
+			t.Fatalf("unexpected notification")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("notification channel not closed")
+	}
+}
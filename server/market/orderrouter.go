@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"sync/atomic"
 	"time"
 
 	"decred.org/dcrdex/dex"
@@ -38,6 +39,8 @@ type AuthManager interface {
 	RecordCancel(user account.AccountID, oid, target order.OrderID, epochGap int32, t time.Time)
 	RecordCompletedOrder(user account.AccountID, oid order.OrderID, t time.Time)
 	UserReputation(user account.AccountID) (tier int64, score, maxScore int32, err error)
+	RecordOrderSubmitted(user account.AccountID, cancel bool, t time.Time)
+	CheckCancelRatio(user account.AccountID) error
 }
 
 const (
@@ -64,6 +67,15 @@ type MarketTunnel interface {
 	LotSize() uint64
 	// RateStep is the market's rate step in units of the quote asset.
 	RateStep() uint64
+	// MinOrderLots is the minimum trade order quantity in lots. Zero means
+	// that there is no minimum beyond a single lot.
+	MinOrderLots() uint64
+	// MaxOpenOrders is the maximum number of standing orders that an account
+	// may have on the market. Zero means that there is no limit.
+	MaxOpenOrders() uint32
+	// StandingOrders is the number of the user's standing limit orders that
+	// are booked or in the epoch queue.
+	StandingOrders(user account.AccountID) int
 	// CoinLocked should return true if the CoinID is currently a funding Coin
 	// for an active DEX order. This is required for Coin validation to prevent
 	// a user from submitting multiple orders spending the same Coin. This
@@ -137,6 +149,13 @@ func newAssetSet(base, quote *asset.BackedAsset, sell bool) *assetSet {
 // FeeSource is a source of the last reported tx fee rate estimate for an asset.
 type FeeSource interface {
 	LastRate(assetID uint32) (feeRate uint64)
+	// LastRange is the last recommended fee rate range for the asset, or nil
+	// if the asset's backend does not estimate fee rate ranges.
+	LastRange(assetID uint32) *asset.FeeRateRange
+	// RateHistory is up to n of the most recent fee rates recorded for the
+	// asset, oldest first. If n is not positive, all retained rates are
+	// returned.
+	RateHistory(assetID uint32, n int) []*msgjson.FeeRateRecord
 }
 
 // MatchSwapper is a source for information about settling matches.
@@ -154,6 +173,10 @@ type OrderRouter struct {
 	feeSource   FeeSource
 	dexBalancer *DEXBalancer
 	swapper     MatchSwapper
+	schedules   map[string]*Schedule
+	mirrors     map[string]*Mirror
+
+	draining uint32 // atomic, see Drain
 }
 
 // OrderRouterConfig is the configuration settings for an OrderRouter.
@@ -164,6 +187,12 @@ type OrderRouterConfig struct {
 	FeeSource    FeeSource
 	DEXBalancer  *DEXBalancer
 	MatchSwapper MatchSwapper
+	// Schedules are the trading hours of any markets that are only open
+	// during certain hours, keyed by market name.
+	Schedules map[string]*Schedule
+	// Mirrors are the read-only mirrors of other DEXs' markets, keyed by
+	// market name. Orders are never accepted for a mirrored market.
+	Mirrors map[string]*Mirror
 }
 
 // NewOrderRouter is a constructor for an OrderRouter.
@@ -176,6 +205,8 @@ func NewOrderRouter(cfg *OrderRouterConfig) *OrderRouter {
 		feeSource:   cfg.FeeSource,
 		dexBalancer: cfg.DEXBalancer,
 		swapper:     cfg.MatchSwapper,
+		schedules:   cfg.Schedules,
+		mirrors:     cfg.Mirrors,
 	}
 	cfg.AuthManager.Route(msgjson.LimitRoute, router.handleLimit)
 	cfg.AuthManager.Route(msgjson.MarketRoute, router.handleMarket)
@@ -187,6 +218,19 @@ func (r *OrderRouter) Run(ctx context.Context) {
 	r.latencyQ.Run(ctx)
 }
 
+// marketClosedError is the error for a trade order submitted to a market that
+// is not running. If the market is outside of its trading hours, the error
+// includes the time the market reopens.
+func (r *OrderRouter) marketClosedError(base, quote uint32, now time.Time) *msgjson.Error {
+	mktName, _ := dex.MarketName(base, quote)
+	if sched := r.schedules[mktName]; sched != nil && !r.isDraining() && !sched.IsOpen(now) {
+		reopen := sched.NextOpen(now)
+		return msgjson.NewError(msgjson.MarketNotRunningError, "market %s closed outside of trading hours until %s",
+			mktName, reopen.UTC().Format(time.RFC3339))
+	}
+	return msgjson.NewError(msgjson.MarketNotRunningError, "market %s closed to new orders", mktName)
+}
+
 func (r *OrderRouter) respondError(reqID uint64, user account.AccountID, msgErr *msgjson.Error) {
 	log.Debugf("Error going to user %v: %s", user, msgErr)
 	msg, err := msgjson.NewResponse(reqID, nil, msgErr)
@@ -235,6 +279,10 @@ func (r *OrderRouter) handleLimit(user account.AccountID, msg *msgjson.Message)
 		return msgjson.NewError(msgjson.AccountClosedError, "account %v with tier %d may not submit trade orders", user, tier)
 	}
 
+	if err := r.auth.CheckCancelRatio(user); err != nil {
+		return msgjson.NewError(msgjson.CancelRatioError, "%v", err)
+	}
+
 	tunnel, assets, sell, rpcErr := r.extractMarketDetails(&limit.Prefix, &limit.Trade)
 	if rpcErr != nil {
 		return rpcErr
@@ -242,8 +290,8 @@ func (r *OrderRouter) handleLimit(user account.AccountID, msg *msgjson.Message)
 
 	// Spare some resources if the market is closed now. Any orders that make it
 	// through to a closed market will receive a similar error from SubmitOrder.
-	if !tunnel.Running() {
-		return msgjson.NewError(msgjson.MarketNotRunningError, "market closed to new orders")
+	if !tunnel.Running() || r.isDraining() {
+		return r.marketClosedError(limit.Base, limit.Quote, time.Now())
 	}
 
 	// Check that OrderType is set correctly
@@ -272,6 +320,12 @@ func (r *OrderRouter) handleLimit(user account.AccountID, msg *msgjson.Message)
 		return msgjson.NewError(msgjson.OrderParameterError, "unknown time-in-force")
 	}
 
+	if force == order.StandingTiF {
+		if rpcErr := checkOpenOrderLimit(tunnel, user); rpcErr != nil {
+			return rpcErr
+		}
+	}
+
 	lotSize := tunnel.LotSize()
 	rpcErr = r.checkPrefixTrade(assets, lotSize, &limit.Prefix, &limit.Trade, true)
 	if rpcErr != nil {
@@ -344,14 +398,17 @@ func (r *OrderRouter) handleMarket(user account.AccountID, msg *msgjson.Message)
 		return msgjson.NewError(msgjson.AccountClosedError, "account %v with tier %d may not submit trade orders", user, tier)
 	}
 
+	if err := r.auth.CheckCancelRatio(user); err != nil {
+		return msgjson.NewError(msgjson.CancelRatioError, "%v", err)
+	}
+
 	tunnel, assets, sell, rpcErr := r.extractMarketDetails(&market.Prefix, &market.Trade)
 	if rpcErr != nil {
 		return rpcErr
 	}
 
-	if !tunnel.Running() {
-		mktName, _ := dex.MarketName(market.Base, market.Quote)
-		return msgjson.NewError(msgjson.MarketNotRunningError, "market %s closed to new orders", mktName)
+	if !tunnel.Running() || r.isDraining() {
+		return r.marketClosedError(market.Base, market.Quote, time.Now())
 	}
 
 	// Check that OrderType is set correctly
@@ -417,6 +474,16 @@ func (r *OrderRouter) processTrade(oRecord *orderRecord, tunnel MarketTunnel, as
 	user := oRecord.order.User()
 	trade := oRecord.order.Trade()
 
+	// Reject orders that could create swap contracts that are too small to be
+	// redeemed economically.
+	if msgErr := r.checkDust(tunnel, fundingAsset, sell, rate); msgErr != nil {
+		return msgErr
+	}
+
+	if msgErr := checkMinOrderLots(tunnel, trade.Quantity, sell, rate); msgErr != nil {
+		return msgErr
+	}
+
 	// If the receiving asset is account-based, we need to check that they can
 	// cover fees for the redemption, since they can't be subtracted from the
 	// received amount.
@@ -630,6 +697,64 @@ func (r *OrderRouter) processTrade(oRecord *orderRecord, tunnel MarketTunnel, as
 	return nil
 }
 
+// checkDust checks that a single-lot swap contract funded by the order would
+// not be below the funding asset's dust limit at the last known fee rate. For
+// market buy orders, the rate is zero and the mid-gap rate is used instead.
+func (r *OrderRouter) checkDust(tunnel MarketTunnel, fundingAsset *asset.BackedAsset, sell bool, rate uint64) *msgjson.Error {
+	minSwap := tunnel.LotSize()
+	if !sell {
+		if rate == 0 {
+			rate = safeMidGap(tunnel)
+		}
+		minSwap = calc.BaseToQuote(rate, minSwap)
+	}
+	feeRate := r.feeSource.LastRate(fundingAsset.ID)
+	if dustLimit := fundingAsset.Backend.DustLimit(feeRate); minSwap < dustLimit {
+		return msgjson.NewError(msgjson.FundingError,
+			"single lot swap value %d is below the %s dust limit of %d at the current fee rate of %d",
+			minSwap, fundingAsset.Symbol, dustLimit, feeRate)
+	}
+	return nil
+}
+
+// checkMinOrderLots checks that the order quantity is at least the market's
+// minimum order size. The quantity of a market buy order is in units of the
+// quote asset, and is converted to lots at the mid-gap rate.
+func checkMinOrderLots(tunnel MarketTunnel, qty uint64, sell bool, rate uint64) *msgjson.Error {
+	minLots := tunnel.MinOrderLots()
+	if minLots <= 1 {
+		return nil
+	}
+	lotSize := tunnel.LotSize()
+	baseQty := qty
+	if !sell && rate == 0 {
+		baseQty = calc.QuoteToBase(safeMidGap(tunnel), qty)
+	}
+	if lots := baseQty / lotSize; lots < minLots {
+		return msgjson.NewError(msgjson.OrderParameterError,
+			"order quantity of %d lots is below the market minimum of %d lots (lot size %d)",
+			lots, minLots, lotSize)
+	}
+	return nil
+}
+
+// checkOpenOrderLimit checks that the user has fewer standing orders on the
+// market than the market's limit. This is an early check that spares the
+// funding checks. Orders still being submitted are not counted, so the Market
+// enforces the limit again when the order enters the epoch queue.
+func checkOpenOrderLimit(tunnel MarketTunnel, user account.AccountID) *msgjson.Error {
+	maxOrders := tunnel.MaxOpenOrders()
+	if maxOrders == 0 {
+		return nil
+	}
+	if n := tunnel.StandingOrders(user); n >= int(maxOrders) {
+		return msgjson.NewError(msgjson.OrderParameterError,
+			"account has %d standing orders on this market, the maximum is %d; cancel an order to place another",
+			n, maxOrders)
+	}
+	return nil
+}
+
 // sufficientAccountBalance checks that the user's account-based asset balance
 // is sufficient to support the order, considering the user's other orders and
 // active matches across all DEX markets.
@@ -749,12 +874,16 @@ func (r *OrderRouter) submitOrderToMarket(tunnel MarketTunnel, oRecord *orderRec
 			log.Errorf("Market failed to SubmitOrder: %v", err)
 		case errors.Is(err, ErrQuantityTooHigh):
 			code = msgjson.OrderQuantityTooHigh
-			fallthrough
+			log.Debugf("Market failed to SubmitOrder: %v", err)
+		case errors.Is(err, ErrTooManyOpenOrders):
+			code = msgjson.OrderParameterError
+			log.Debugf("Market failed to SubmitOrder: %v", err)
 		default:
 			log.Debugf("Market failed to SubmitOrder: %v", err)
 		}
 		return msgjson.NewError(code, "%v", err)
 	}
+	r.auth.RecordOrderSubmitted(oRecord.order.User(), false, time.Now())
 	return nil
 }
 
@@ -774,10 +903,10 @@ func (r *OrderRouter) checkZeroConfs(dexCoin asset.FundingCoin, fundingAsset *as
 	lastKnownFeeRate := r.feeSource.LastRate(fundingAsset.ID) // MaxFeeRate applied inside feeSource
 	feeMinimum := uint64(math.Round(float64(lastKnownFeeRate) * ZeroConfFeeRateThreshold))
 
-	if !fundingAsset.Backend.ValidateFeeRate(dexCoin.Coin(), feeMinimum) {
-		log.Debugf("Fees too low %s coin %s: fee mim %d", fundingAsset.Symbol, dexCoin, feeMinimum)
+	if err := fundingAsset.Backend.ValidateFeeRate(dexCoin.Coin(), feeMinimum); err != nil {
+		log.Debugf("Fees too low %s coin %s: %v", fundingAsset.Symbol, dexCoin, err)
 		return msgjson.NewError(msgjson.FundingError,
-			"fee rate for %s is too low. fee min %d", dexCoin, feeMinimum)
+			"fee rate for unconfirmed coin %s is too low: %v", dexCoin, err)
 	}
 	return nil
 }
@@ -857,6 +986,7 @@ func (r *OrderRouter) handleCancel(user account.AccountID, msg *msgjson.Message)
 		}
 		return msgjson.NewError(msgjson.UnknownMarketError, "%v", err)
 	}
+	r.auth.RecordOrderSubmitted(user, true, time.Now())
 	return nil
 }
 
@@ -881,6 +1011,10 @@ func (r *OrderRouter) extractMarket(prefix *msgjson.Prefix) (MarketTunnel, *msgj
 	if err != nil {
 		return nil, msgjson.NewError(msgjson.UnknownMarketError, "asset lookup error: %v", err.Error())
 	}
+	if mirror := r.mirrors[mktName]; mirror != nil {
+		return nil, msgjson.NewError(msgjson.MarketNotRunningError, "market %s is a read-only mirror of %s",
+			mktName, mirror.Source())
+	}
 	tunnel, found := r.tunnels[mktName]
 	if !found {
 		return nil, msgjson.NewError(msgjson.UnknownMarketError, "unknown market %s", mktName)
@@ -933,6 +1067,18 @@ func (r *OrderRouter) Suspend(asSoonAs time.Time, persistBooks bool) map[string]
 	return suspendTimes
 }
 
+// Drain immediately stops the OrderRouter from accepting new limit and market
+// orders in preparation for shutdown. Cancel orders are still accepted. The
+// markets themselves should be suspended with Suspend so that their current
+// epochs are completed.
+func (r *OrderRouter) Drain() {
+	atomic.StoreUint32(&r.draining, 1)
+}
+
+func (r *OrderRouter) isDraining() bool {
+	return atomic.LoadUint32(&r.draining) == 1
+}
+
 // extractMarketDetails finds the MarketTunnel, an assetSet, and market side for
 // the provided prefix.
 func (r *OrderRouter) extractMarketDetails(prefix *msgjson.Prefix, trade *msgjson.Trade) (MarketTunnel, *assetSet, bool, *msgjson.Error) {
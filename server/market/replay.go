@@ -0,0 +1,177 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package market
+
+import (
+	"bytes"
+	"fmt"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/book"
+	"decred.org/dcrdex/server/db"
+	"decred.org/dcrdex/server/matcher"
+)
+
+// ReplayArchive is the DB interface required to replay an epoch's matching.
+type ReplayArchive interface {
+	Order(oid order.OrderID, base, quote uint32) (order.Order, order.OrderStatus, error)
+	EpochAudit(base, quote uint32, epochIdx, epochDur int64) (*db.EpochAudit, error)
+	EpochBook(base, quote uint32, epochIdx, epochDur int64) (*db.EpochBook, error)
+	EpochMatches(base, quote uint32, epochIdx, epochDur int64) ([]*db.MatchData, error)
+}
+
+// ReplayedMatch is a trade match produced by replaying an epoch's matching.
+type ReplayedMatch struct {
+	ID        order.MatchID `json:"id"`
+	Taker     order.OrderID `json:"taker"`
+	Maker     order.OrderID `json:"maker"`
+	TakerSell bool          `json:"takerSell"`
+	Quantity  uint64        `json:"qty"`
+	Rate      uint64        `json:"rate"`
+}
+
+// EpochReplay is the result of replaying an epoch's matching from the recorded
+// book and epoch queue. Identical is true if the replayed shuffle seed and
+// trade matches are the same as those recorded when the epoch was matched
+// live. Missing are the IDs of recorded matches that were not reproduced, and
+// Unexpected are the IDs of replayed matches that were not recorded.
+type EpochReplay struct {
+	Idx        int64            `json:"idx"`
+	Dur        int64            `json:"dur"`
+	Seed       dex.Bytes        `json:"seed"`
+	Matches    []*ReplayedMatch `json:"matches"`
+	Identical  bool             `json:"identical"`
+	SeedMatch  bool             `json:"seedMatch"`
+	Missing    []order.MatchID  `json:"missing,omitempty"`
+	Unexpected []order.MatchID  `json:"unexpected,omitempty"`
+}
+
+// bookSnapshot records the state of the book at the start of an epoch's
+// matching, which is required to replay the matching.
+func bookSnapshot(bk *book.Book) *db.EpochBook {
+	buys, sells := bk.BuyOrders(), bk.SellOrders()
+	snap := &db.EpochBook{
+		LotSize: bk.LotSize(),
+		Orders:  make([]order.OrderID, 0, len(buys)+len(sells)),
+		Filled:  make([]uint64, 0, len(buys)+len(sells)),
+	}
+	for _, lo := range append(buys, sells...) {
+		snap.Orders = append(snap.Orders, lo.ID())
+		snap.Filled = append(snap.Filled, lo.Filled())
+	}
+	return snap
+}
+
+// ReplayEpoch deterministically replays the matching of the specified epoch of
+// a market using the book state and revealed epoch queue recorded when the
+// epoch was matched, and compares the result with the recorded matches. Only
+// trade matches are compared since the matches of cancel orders are recorded
+// with the epoch of the targeted order rather than the epoch of the cancel
+// order.
+func ReplayEpoch(archive ReplayArchive, base, quote uint32, epochIdx, epochDur int64) (*EpochReplay, error) {
+	audit, err := archive.EpochAudit(base, quote, epochIdx, epochDur)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving epoch audit: %w", err)
+	}
+	epochBook, err := archive.EpochBook(base, quote, epochIdx, epochDur)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving epoch book: %w", err)
+	}
+	if len(epochBook.Orders) != len(epochBook.Filled) {
+		return nil, fmt.Errorf("epoch book has %d orders but %d fill amounts",
+			len(epochBook.Orders), len(epochBook.Filled))
+	}
+
+	// Rebuild the book as it was at the start of matching. Orders are loaded
+	// in their current state, so the fill amounts are reset to the recorded
+	// values.
+	bk := book.New(epochBook.LotSize, book.AccountTracking(0))
+	for i, oid := range epochBook.Orders {
+		ord, _, err := archive.Order(oid, base, quote)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving book order %v: %w", oid, err)
+		}
+		lo, ok := ord.(*order.LimitOrder)
+		if !ok {
+			return nil, fmt.Errorf("book order %v is not a limit order", oid)
+		}
+		lo.SetFill(epochBook.Filled[i])
+		if !bk.Insert(lo) {
+			return nil, fmt.Errorf("failed to insert order %v into the book", oid)
+		}
+	}
+
+	// Rebuild the epoch queue from the revealed orders. Orders with no
+	// revealed preimage were not matched.
+	queue := make([]*matcher.OrderRevealed, 0, len(audit.Orders))
+	for _, ao := range audit.Orders {
+		if ao.Preimage.IsZero() {
+			continue
+		}
+		ord, _, err := archive.Order(ao.ID, base, quote)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving epoch order %v: %w", ao.ID, err)
+		}
+		if trade := ord.Trade(); trade != nil {
+			trade.SetFill(0)
+		}
+		queue = append(queue, &matcher.OrderRevealed{
+			Order:    ord,
+			Preimage: ao.Preimage,
+		})
+	}
+
+	seed, matchSets, _, _, _, _, _, _, _, _, _ := matcher.New().Match(bk, queue)
+
+	replay := &EpochReplay{
+		Idx:       epochIdx,
+		Dur:       epochDur,
+		Seed:      seed,
+		Matches:   make([]*ReplayedMatch, 0),
+		SeedMatch: bytes.Equal(seed, audit.Seed),
+	}
+	replayed := make(map[order.MatchID]bool)
+	for _, ms := range matchSets {
+		for _, match := range ms.Matches() {
+			trade := match.Taker.Trade()
+			if trade == nil { // cancel order match
+				continue
+			}
+			mid := match.ID()
+			replayed[mid] = true
+			replay.Matches = append(replay.Matches, &ReplayedMatch{
+				ID:        mid,
+				Taker:     match.Taker.ID(),
+				Maker:     match.Maker.ID(),
+				TakerSell: trade.Sell,
+				Quantity:  match.Quantity,
+				Rate:      match.Rate,
+			})
+		}
+	}
+
+	stored, err := archive.EpochMatches(base, quote, epochIdx, epochDur)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving epoch matches: %w", err)
+	}
+	recorded := make(map[order.MatchID]bool, len(stored))
+	for _, md := range stored {
+		if md.TakerAddr == "" { // cancel order match
+			continue
+		}
+		recorded[md.ID] = true
+		if !replayed[md.ID] {
+			replay.Missing = append(replay.Missing, md.ID)
+		}
+	}
+	for _, m := range replay.Matches {
+		if !recorded[m.ID] {
+			replay.Unexpected = append(replay.Unexpected, m.ID)
+		}
+	}
+
+	replay.Identical = replay.SeedMatch && len(replay.Missing) == 0 && len(replay.Unexpected) == 0
+	return replay, nil
+}
@@ -0,0 +1,199 @@
+package market
+
+import (
+	"bytes"
+	"testing"
+
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/book"
+	"decred.org/dcrdex/server/db"
+	"decred.org/dcrdex/server/matcher"
+)
+
+// tReplayArchive stores orders encoded so that each retrieval returns a new
+// order, as with the DB.
+type tReplayArchive struct {
+	orders  map[order.OrderID][]byte
+	audit   *db.EpochAudit
+	book    *db.EpochBook
+	matches []*db.MatchData
+}
+
+func (a *tReplayArchive) Order(oid order.OrderID, base, quote uint32) (order.Order, order.OrderStatus, error) {
+	b, found := a.orders[oid]
+	if !found {
+		return nil, order.OrderStatusUnknown, db.ArchiveError{Code: db.ErrUnknownOrder}
+	}
+	ord, err := order.DecodeOrder(b)
+	return ord, order.OrderStatusExecuted, err
+}
+
+func (a *tReplayArchive) EpochAudit(base, quote uint32, epochIdx, epochDur int64) (*db.EpochAudit, error) {
+	return a.audit, nil
+}
+
+func (a *tReplayArchive) EpochBook(base, quote uint32, epochIdx, epochDur int64) (*db.EpochBook, error) {
+	if a.book == nil {
+		return nil, db.ArchiveError{Code: db.ErrUnknownEpoch}
+	}
+	return a.book, nil
+}
+
+func (a *tReplayArchive) EpochMatches(base, quote uint32, epochIdx, epochDur int64) ([]*db.MatchData, error) {
+	return a.matches, nil
+}
+
+func TestReplayEpoch(t *testing.T) {
+	const epochIdx, epochDur = 1234, 60_000
+
+	// Standing orders, one of which is partially filled.
+	sell1 := makeLO(seller1, 4_000_000, 2, order.StandingTiF)
+	sell1.SetFill(btcLotSize)
+	sell2 := makeLO(seller1, 4_100_000, 3, order.StandingTiF)
+	sell3 := makeLO(seller1, 4_200_000, 1, order.StandingTiF)
+	buy1 := makeLO(buyer1, 3_900_000, 2, order.StandingTiF)
+	buy2 := makeLO(buyer1, 3_800_000, 4, order.StandingTiF)
+	bookOrders := []*order.LimitOrder{sell1, sell2, sell3, buy1, buy2}
+
+	// The epoch queue, including an order with no revealed preimage.
+	buyLO, buyPI := makeLORevealed(buyer1, 4_100_000, 3, order.ImmediateTiF)
+	sellMO, sellPI := makeMORevealed(seller1, 3)
+	co, coPI := makeCORevealed(seller1, sell3.ID())
+	missed := makeLO(buyer1, 4_200_000, 1, order.StandingTiF)
+	queue := []*matcher.OrderRevealed{
+		{Order: buyLO, Preimage: buyPI},
+		{Order: sellMO, Preimage: sellPI},
+		{Order: co, Preimage: coPI},
+	}
+
+	// Match live, recording the book as the market does.
+	bk := book.New(btcLotSize, book.AccountTracking(0))
+	for _, lo := range bookOrders {
+		if !bk.Insert(lo) {
+			t.Fatalf("failed to insert order %v", lo)
+		}
+	}
+	snap := bookSnapshot(bk)
+	if len(snap.Orders) != len(bookOrders) || snap.LotSize != btcLotSize {
+		t.Fatalf("wrong book snapshot %+v", snap)
+	}
+	seed, matchSets, _, _, _, _, _, _, _, _, _ := matcher.New().Match(bk, queue)
+
+	archive := &tReplayArchive{
+		orders: make(map[order.OrderID][]byte),
+		book:   snap,
+		audit: &db.EpochAudit{
+			Idx:    epochIdx,
+			Dur:    epochDur,
+			Seed:   seed,
+			Orders: []*db.EpochAuditOrder{{ID: missed.ID(), Commit: missed.Commitment()}},
+		},
+	}
+	// Orders are stored in their state after matching.
+	for _, lo := range bookOrders {
+		archive.orders[lo.ID()] = order.EncodeOrder(lo)
+	}
+	for _, or := range queue {
+		archive.orders[or.Order.ID()] = order.EncodeOrder(or.Order)
+		archive.audit.Orders = append(archive.audit.Orders, &db.EpochAuditOrder{
+			ID:       or.Order.ID(),
+			Commit:   or.Order.Commitment(),
+			Preimage: or.Preimage,
+		})
+	}
+	archive.orders[missed.ID()] = order.EncodeOrder(missed)
+
+	var liveMatches []*order.Match
+	for _, ms := range matchSets {
+		for _, match := range ms.Matches() {
+			md := &db.MatchData{
+				ID:       match.ID(),
+				Taker:    match.Taker.ID(),
+				Maker:    match.Maker.ID(),
+				Quantity: match.Quantity,
+				Rate:     match.Rate,
+			}
+			if trade := match.Taker.Trade(); trade != nil {
+				md.TakerAddr = trade.SwapAddress()
+				md.TakerSell = trade.Sell
+				liveMatches = append(liveMatches, match)
+			}
+			archive.matches = append(archive.matches, md)
+		}
+	}
+	if len(liveMatches) < 3 {
+		t.Fatalf("expected at least 3 trade matches, got %d", len(liveMatches))
+	}
+	if len(archive.matches) == len(liveMatches) {
+		t.Fatalf("expected a cancel match")
+	}
+
+	replay, err := ReplayEpoch(archive, mkt1.Base, mkt1.Quote, epochIdx, epochDur)
+	if err != nil {
+		t.Fatalf("ReplayEpoch error: %v", err)
+	}
+	if !replay.Identical || !replay.SeedMatch || len(replay.Missing) > 0 || len(replay.Unexpected) > 0 {
+		t.Fatalf("replay not identical: %+v", replay)
+	}
+	if !bytes.Equal(replay.Seed, seed) {
+		t.Fatalf("wrong seed %x, expected %x", replay.Seed, seed)
+	}
+	if len(replay.Matches) != len(liveMatches) {
+		t.Fatalf("replayed %d matches, expected %d", len(replay.Matches), len(liveMatches))
+	}
+	for i, m := range replay.Matches {
+		live := liveMatches[i]
+		if m.ID != live.ID() || m.Taker != live.Taker.ID() || m.Maker != live.Maker.ID() ||
+			m.Quantity != live.Quantity || m.Rate != live.Rate {
+			t.Fatalf("replayed match %d %+v differs from live match %v", i, m, live)
+		}
+	}
+
+	// A recorded match that is not reproduced.
+	var mid order.MatchID
+	mid[0] = 0x01
+	archive.matches = append(archive.matches, &db.MatchData{ID: mid, TakerAddr: "addr"})
+	replay, err = ReplayEpoch(archive, mkt1.Base, mkt1.Quote, epochIdx, epochDur)
+	if err != nil {
+		t.Fatalf("ReplayEpoch error: %v", err)
+	}
+	if replay.Identical || len(replay.Missing) != 1 || replay.Missing[0] != mid {
+		t.Fatalf("expected missing match %v, got %+v", mid, replay)
+	}
+	archive.matches = archive.matches[:len(archive.matches)-1]
+
+	// A replayed match that was not recorded.
+	recorded := archive.matches
+	archive.matches = nil
+	for _, md := range recorded {
+		if md.ID != liveMatches[0].ID() {
+			archive.matches = append(archive.matches, md)
+		}
+	}
+	replay, err = ReplayEpoch(archive, mkt1.Base, mkt1.Quote, epochIdx, epochDur)
+	if err != nil {
+		t.Fatalf("ReplayEpoch error: %v", err)
+	}
+	if replay.Identical || len(replay.Unexpected) != 1 || replay.Unexpected[0] != liveMatches[0].ID() {
+		t.Fatalf("expected unexpected match %v, got %+v", liveMatches[0].ID(), replay)
+	}
+	archive.matches = recorded
+
+	// A different recorded seed.
+	archive.audit.Seed = []byte{0x01}
+	replay, err = ReplayEpoch(archive, mkt1.Base, mkt1.Quote, epochIdx, epochDur)
+	if err != nil {
+		t.Fatalf("ReplayEpoch error: %v", err)
+	}
+	if replay.Identical || replay.SeedMatch {
+		t.Fatalf("expected seed mismatch, got %+v", replay)
+	}
+	archive.audit.Seed = seed
+
+	// No recorded book.
+	archive.book = nil
+	_, err = ReplayEpoch(archive, mkt1.Base, mkt1.Quote, epochIdx, epochDur)
+	if !db.SameErrorTypes(err, db.ArchiveError{Code: db.ErrUnknownEpoch}) {
+		t.Fatalf("expected unknown epoch error, got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package market
+
+import (
+	"fmt"
+
+	"decred.org/dcrdex/dex/order"
+)
+
+// marketRetune is a scheduled change of a Market's lot size, rate step, and
+// minimum rate.
+type marketRetune struct {
+	// epochIdx is the first epoch with the new parameters.
+	epochIdx int64
+	lotSize  uint64
+	rateStep uint64
+	minRate  uint64
+	// paramsSet indicates that new orders are being validated with the new
+	// parameters, but the book has not yet been retuned.
+	paramsSet bool
+}
+
+// Retune schedules a change of the market's lot size, rate step, and minimum
+// rate. The new parameters apply to orders in the epoch following the active
+// epoch, the index of which is returned. Before that epoch is matched, booked
+// orders with quantities or rates that are incompatible with the new lot size
+// or rate step are revoked, without counting against the users, and the users
+// are sent revoke_order notifications. If the market is not running, the new
+// parameters take effect when it resumes, and the returned epoch index is zero.
+// A scheduled retune that has not begun may be replaced with another.
+func (m *Market) Retune(lotSize, rateStep, minRate uint64) (epochIdx int64, err error) {
+	if lotSize == 0 {
+		return 0, fmt.Errorf("zero lot size")
+	}
+	if rateStep == 0 {
+		return 0, fmt.Errorf("zero rate step")
+	}
+
+	m.epochMtx.Lock()
+	defer m.epochMtx.Unlock()
+	if m.retune != nil && m.retune.paramsSet {
+		return 0, fmt.Errorf("market %s retune to lot size %d, rate step %d already in progress",
+			m.marketInfo.Name, m.retune.lotSize, m.retune.rateStep)
+	}
+	if m.activeEpochIdx != 0 {
+		epochIdx = m.activeEpochIdx + 1
+	}
+	m.retune = &marketRetune{
+		epochIdx: epochIdx,
+		lotSize:  lotSize,
+		rateStep: rateStep,
+		minRate:  minRate,
+	}
+	log.Infof("Market %s scheduled to retune at epoch %d: lot size %d => %d, rate step %d => %d",
+		m.marketInfo.Name, epochIdx, m.LotSize(), lotSize, m.RateStep(), rateStep)
+	return epochIdx, nil
+}
+
+// params returns the market's current lot size, rate step, and minimum rate.
+func (m *Market) params() (lotSize, rateStep, minRate uint64) {
+	m.paramsMtx.RLock()
+	defer m.paramsMtx.RUnlock()
+	return m.lotSize, m.rateStep, m.minimumRate
+}
+
+// setRetunedParams switches the market's parameters to those of the retune,
+// and reports the change to the paramsChanged callback. The epochMtx must be
+// locked.
+func (m *Market) setRetunedParams(rt *marketRetune) {
+	m.paramsMtx.Lock()
+	m.lotSize = rt.lotSize
+	m.rateStep = rt.rateStep
+	m.minimumRate = rt.minRate
+	m.paramsMtx.Unlock()
+	rt.paramsSet = true
+	if m.paramsChanged != nil {
+		m.paramsChanged(rt.lotSize, rt.rateStep)
+	}
+}
+
+// checkEpochParams checks that a trade order is compatible with the parameters
+// for the epoch in which it will be matched.
+func (m *Market) checkEpochParams(ord order.Order, epochIdx int64) error {
+	lotSize, rateStep, minRate := m.params()
+	m.epochMtx.RLock()
+	if rt := m.retune; rt != nil && epochIdx >= rt.epochIdx {
+		lotSize, rateStep, minRate = rt.lotSize, rt.rateStep, rt.minRate
+	}
+	m.epochMtx.RUnlock()
+
+	switch o := ord.(type) {
+	case *order.LimitOrder:
+		if o.Quantity%lotSize != 0 || o.Rate%rateStep != 0 {
+			return ErrInvalidOrder
+		}
+		if o.Rate < minRate {
+			return ErrInvalidRate
+		}
+	case *order.MarketOrder:
+		// Market buy quantity is in units of the quote asset.
+		if o.Sell && o.Quantity%lotSize != 0 {
+			return ErrInvalidOrder
+		}
+	}
+	return nil
+}
+
+// retuneBook applies a scheduled retune to the book if epochIdx is the first
+// epoch with the new parameters. Booked orders that are incompatible with the
+// new lot size or rate step are revoked. This must be called from the epoch
+// processing pipeline before matching.
+func (m *Market) retuneBook(epochIdx int64, notifyChan chan<- *updateSignal) {
+	m.epochMtx.Lock()
+	rt := m.retune
+	if rt == nil || !rt.paramsSet || epochIdx < rt.epochIdx {
+		m.epochMtx.Unlock()
+		return
+	}
+	m.retune = nil
+	m.epochMtx.Unlock()
+
+	compatible := func(lo *order.LimitOrder) bool {
+		return lo.Quantity%rt.lotSize == 0 && lo.FillAmt%rt.lotSize == 0 &&
+			lo.Rate%rt.rateStep == 0
+	}
+
+	m.bookMtx.Lock()
+	var removed []*order.LimitOrder
+	for _, lo := range append(m.book.BuyOrders(), m.book.SellOrders()...) {
+		if compatible(lo) {
+			continue
+		}
+		if _, ok := m.book.Remove(lo.ID()); ok {
+			delete(m.settling, lo.ID()) // no order completion credit
+			removed = append(removed, lo)
+		}
+	}
+	m.book.SetLotSize(rt.lotSize)
+	m.bookMtx.Unlock()
+
+	log.Infof("Market %s retuned at epoch %d with lot size %d and rate step %d. Revoked %d incompatible book orders.",
+		m.marketInfo.Name, epochIdx, rt.lotSize, rt.rateStep, len(removed))
+
+	for _, lo := range removed {
+		m.unlockOrderCoins(lo)
+		// The user is not at fault, so the revocation is not counted.
+		if _, _, err := m.storage.RevokeOrderUncounted(lo); err != nil {
+			log.Errorf("Failed to revoke order %v: %v", lo, err)
+		}
+		m.sendRevokeOrderNote(lo.ID(), lo.User())
+		notifyChan <- &updateSignal{
+			action: unbookAction,
+			data: sigDataUnbookedOrder{
+				order:    lo,
+				epochIdx: -1, // NOTE: no epoch
+			},
+		}
+	}
+}
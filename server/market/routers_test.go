@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -141,6 +142,9 @@ type TAuth struct {
 		score, maxScore int32
 		err             error
 	}
+	cancelRatioErr   error
+	submittedTrades  atomic.Uint32
+	submittedCancels atomic.Uint32
 }
 
 func (a *TAuth) Route(route string, handler func(account.AccountID, *msgjson.Message) *msgjson.Error) {
@@ -258,6 +262,16 @@ func (a *TAuth) UserReputation(user account.AccountID) (tier int64, score, maxSc
 	}
 	return a.rep.tier, a.rep.score, a.rep.maxScore, a.rep.err
 }
+func (a *TAuth) RecordOrderSubmitted(user account.AccountID, cancel bool, t time.Time) {
+	if cancel {
+		a.submittedCancels.Add(1)
+	} else {
+		a.submittedTrades.Add(1)
+	}
+}
+func (a *TAuth) CheckCancelRatio(user account.AccountID) error {
+	return a.cancelRatioErr
+}
 func (a *TAuth) AcctStatus(user account.AccountID) (connected bool, tier int64) {
 	return true, 1
 }
@@ -274,6 +288,9 @@ type TMarketTunnel struct {
 	midGap      uint64
 	lotSize     uint64
 	rateStep    uint64
+	minLots     uint64
+	maxOpen     uint32
+	standing    int
 	mbBuffer    float64
 	epochIdx    uint64
 	epochDur    uint64
@@ -338,6 +355,18 @@ func (m *TMarketTunnel) RateStep() uint64 {
 	return m.rateStep
 }
 
+func (m *TMarketTunnel) MinOrderLots() uint64 {
+	return m.minLots
+}
+
+func (m *TMarketTunnel) MaxOpenOrders() uint32 {
+	return m.maxOpen
+}
+
+func (m *TMarketTunnel) StandingOrders(user account.AccountID) int {
+	return m.standing
+}
+
 func (m *TMarketTunnel) CoinLocked(assetID uint32, coinid order.CoinID) bool {
 	return m.locked
 }
@@ -397,6 +426,7 @@ type TBackend struct {
 	confsMinus2    int64
 	invalidFeeRate bool
 	unfunded       bool
+	dustLimit      uint64
 }
 
 func tNewUTXOBackend() *tUTXOBackend {
@@ -471,8 +501,14 @@ func (b *TBackend) TxData([]byte) ([]byte, error) { return nil, nil }
 func (*TBackend) Info() *asset.BackendInfo {
 	return &asset.BackendInfo{}
 }
-func (b *TBackend) ValidateFeeRate(asset.Coin, uint64) bool {
-	return !b.invalidFeeRate
+func (b *TBackend) ValidateFeeRate(asset.Coin, uint64) error {
+	if b.invalidFeeRate {
+		return errors.New("fee rate too low")
+	}
+	return nil
+}
+func (b *TBackend) DustLimit(uint64) uint64 {
+	return b.dustLimit
 }
 
 type tUTXOBackend struct {
@@ -670,12 +706,37 @@ func makeEnsureErr(t *testing.T) func(tag string, rpcErr *msgjson.Error, code in
 
 type tFeeSource struct {
 	feeMinus10 int64
+
+	rangeMtx sync.Mutex
+	ranges   map[uint32]*asset.FeeRateRange
+
+	hists map[uint32][]*msgjson.FeeRateRecord
 }
 
 func (s *tFeeSource) LastRate(assetID uint32) (feeRate uint64) {
 	return uint64(s.feeMinus10 + 10)
 }
 
+func (s *tFeeSource) LastRange(assetID uint32) *asset.FeeRateRange {
+	s.rangeMtx.Lock()
+	defer s.rangeMtx.Unlock()
+	return s.ranges[assetID]
+}
+
+func (s *tFeeSource) RateHistory(assetID uint32, n int) []*msgjson.FeeRateRecord {
+	rates := s.hists[assetID]
+	if n > 0 && n < len(rates) {
+		rates = rates[len(rates)-n:]
+	}
+	return rates
+}
+
+func (s *tFeeSource) setRanges(ranges map[uint32]*asset.FeeRateRange) {
+	s.rangeMtx.Lock()
+	s.ranges = ranges
+	s.rangeMtx.Unlock()
+}
+
 type tMatchSwapper struct {
 	qtys map[[2]uint32]uint64
 }
@@ -776,7 +837,7 @@ func TestMain(m *testing.M) {
 		// Not counted as coverage, must test Archiver constructor explicitly.
 		var shutdown context.CancelFunc
 		testCtx, shutdown = context.WithCancel(context.Background())
-		rig.router = NewBookRouter(rig.sources(), &tFeeSource{}, func(route string, handler comms.MsgHandler) {})
+		rig.router = NewBookRouter(rig.sources(), &tFeeSource{}, 0, 0, func(route string, handler comms.MsgHandler) {})
 		var wg sync.WaitGroup
 		wg.Add(1)
 		go func() {
@@ -866,6 +927,41 @@ func TestLimit(t *testing.T) {
 	defer func() { oRig.market.added = nil }()
 	ensureSuccess("valid order")
 
+	// A single lot swap below the dust limit is rejected, but one right at the
+	// limit is accepted.
+	oRig.dcr.dustLimit = dcrLotSize + 1
+	ensureErr("dust", sendLimit(), msgjson.FundingError)
+	oRig.dcr.dustLimit = dcrLotSize
+	ensureSuccess("at dust limit")
+	oRig.dcr.dustLimit = 0
+
+	// An order below the market's minimum order size is rejected, but one at
+	// the minimum is accepted.
+	oRig.market.minLots = lots + 1
+	ensureErr("below min lots", sendLimit(), msgjson.OrderParameterError)
+	oRig.market.minLots = lots
+	ensureSuccess("at min lots")
+	oRig.market.minLots = 0
+
+	// A standing order from a user at the market's open order limit is
+	// rejected. Cancelling an order frees capacity for another.
+	oRig.market.maxOpen = 3
+	oRig.market.standing = 3
+	ensureErr("at max open orders", sendLimit(), msgjson.OrderParameterError)
+	oRig.market.standing = 2
+	ensureSuccess("below max open orders")
+
+	// Accepted orders are recorded for the cancellation ratio, and orders from
+	// a user over the maximum cancellation ratio are refused.
+	trades := oRig.auth.submittedTrades.Load()
+	oRig.auth.cancelRatioErr = errors.New("test error")
+	ensureErr("cancel ratio exceeded", sendLimit(), msgjson.CancelRatioError)
+	oRig.auth.cancelRatioErr = nil
+	ensureSuccess("cancel ratio ok")
+	if n := oRig.auth.submittedTrades.Load(); n != trades+1 {
+		t.Fatalf("expected %d recorded trade orders, got %d", trades+1, n)
+	}
+
 	// Check TiF
 	epochOrder := oRecord.order.(*order.LimitOrder)
 	if epochOrder.Force != order.StandingTiF {
@@ -875,6 +971,11 @@ func TestLimit(t *testing.T) {
 	// Now check with immediate TiF.
 	limit.TiF = msgjson.ImmediateOrderNum
 	ensureSuccess("valid immediate order")
+	// Immediate orders are not subject to the open order limit.
+	oRig.market.standing = 3
+	ensureSuccess("immediate order at max open orders")
+	oRig.market.maxOpen = 0
+	oRig.market.standing = 0
 	epochOrder = oRecord.order.(*order.LimitOrder)
 	if epochOrder.Force != order.ImmediateTiF {
 		t.Errorf("Got force %v, expected %v (immediate)", epochOrder.Force, order.ImmediateTiF)
@@ -1254,12 +1355,24 @@ func TestCancel(t *testing.T) {
 	}
 
 	// First just send it through and ensure there are no errors.
+	cancels := oRig.auth.submittedCancels.Load()
 	ensureErr("valid order", sendCancel(), -1)
 	// Make sure the order was submitted to the market
 	oRecord := oRig.market.pop()
 	if oRecord == nil {
 		t.Fatalf("no order submitted to epoch")
 	}
+	if n := oRig.auth.submittedCancels.Load(); n != cancels+1 {
+		t.Fatalf("expected %d recorded cancel orders, got %d", cancels+1, n)
+	}
+
+	// Cancel orders are allowed over the maximum cancellation ratio.
+	oRig.auth.cancelRatioErr = errors.New("test error")
+	ensureErr("cancel ratio exceeded", sendCancel(), -1)
+	oRig.auth.cancelRatioErr = nil
+	if oRig.market.pop() == nil {
+		t.Fatalf("no order submitted to epoch")
+	}
 
 	// Test an invalid payload.
 	msg := new(msgjson.Message)
@@ -1335,6 +1448,70 @@ func TestCancel(t *testing.T) {
 	}
 }
 
+func TestDrain(t *testing.T) {
+	const lots = 2
+	qty := uint64(dcrLotSize) * lots
+	user := oRig.user
+	clientTime := nowMs()
+	pi := ordertest.RandomPreimage()
+	commit := pi.Commit()
+	limit := msgjson.LimitOrder{
+		Prefix: msgjson.Prefix{
+			AccountID:  user.acct[:],
+			Base:       dcrID,
+			Quote:      btcID,
+			OrderType:  msgjson.LimitOrderNum,
+			ClientTime: uint64(clientTime.UnixMilli()),
+			Commit:     commit[:],
+		},
+		Trade: msgjson.Trade{
+			Side:     msgjson.SellOrderNum,
+			Quantity: qty,
+			Coins: []*msgjson.Coin{
+				oRig.signedUTXO(dcrID, qty, 1),
+			},
+			Address: btcAddr,
+		},
+		Rate: uint64(1000) * dcrRateStep,
+		TiF:  msgjson.StandingOrderNum,
+	}
+	mkt := msgjson.MarketOrder{
+		Prefix: limit.Prefix,
+		Trade:  limit.Trade,
+	}
+	mkt.OrderType = msgjson.MarketOrderNum
+	targetID := order.OrderID{245}
+	cancel := msgjson.CancelOrder{
+		Prefix:   limit.Prefix,
+		TargetID: targetID[:],
+	}
+	cancel.OrderType = msgjson.CancelOrderNum
+
+	ensureErr := makeEnsureErr(t)
+
+	oRig.router.Drain()
+	defer atomic.StoreUint32(&oRig.router.draining, 0)
+
+	msg, _ := msgjson.NewRequest(1, msgjson.LimitRoute, limit)
+	ensureErr("limit while draining", oRig.router.handleLimit(user.acct, msg), msgjson.MarketNotRunningError)
+
+	msg, _ = msgjson.NewRequest(2, msgjson.MarketRoute, mkt)
+	ensureErr("market while draining", oRig.router.handleMarket(user.acct, msg), msgjson.MarketNotRunningError)
+
+	if oRecord := oRig.market.pop(); oRecord != nil {
+		t.Fatalf("trade order submitted to epoch while draining")
+	}
+
+	// Cancel orders are still accepted so users can pull their standing
+	// orders before the markets are suspended.
+	msg, _ = msgjson.NewRequest(3, msgjson.CancelRoute, cancel)
+	ensureErr("cancel while draining", oRig.router.handleCancel(user.acct, msg), -1)
+	if oRecord := oRig.market.pop(); oRecord == nil {
+		t.Fatalf("cancel order not submitted to epoch while draining")
+	}
+	oRig.auth.sends = nil
+}
+
 func testPrefix(prefix *msgjson.Prefix, checkCode func(string, int)) {
 	ogAcct := prefix.AccountID
 	oid := ordertest.NextAccount()
@@ -1477,11 +1654,12 @@ func makeCORevealed(writer *ordertest.Writer, targetID order.OrderID) (*order.Ca
 }
 
 type TBookSource struct {
-	buys  []*order.LimitOrder
-	sells []*order.LimitOrder
-	feed  chan *updateSignal
-	base  uint32
-	quote uint32
+	buys   []*order.LimitOrder
+	sells  []*order.LimitOrder
+	trades []*msgjson.PublicTrade
+	feed   chan *updateSignal
+	base   uint32
+	quote  uint32
 }
 
 func (s *TBookSource) Base() uint32 {
@@ -1506,6 +1684,9 @@ func (s *TBookSource) Book() (eidx int64, buys []*order.LimitOrder, sells []*ord
 func (s *TBookSource) OrderFeed() <-chan *updateSignal {
 	return s.feed
 }
+func (s *TBookSource) RecentTrades(n int) ([]*msgjson.PublicTrade, error) {
+	return s.trades, nil
+}
 
 type TLink struct {
 	mtx         sync.Mutex
@@ -1519,6 +1700,9 @@ type TLink struct {
 	on          uint32
 	closed      chan struct{}
 	sendRawErr  error
+	// features are the negotiated features, or nil if the link has not
+	// negotiated features.
+	features map[string]bool
 }
 
 var linkCounter uint64
@@ -1611,6 +1795,9 @@ func (conn *TLink) Disconnect() {
 
 func (conn *TLink) SetCustomID(string) {}
 func (conn *TLink) CustomID() string   { return "" }
+func (conn *TLink) Supports(feature string) bool {
+	return conn.features == nil || conn.features[feature]
+}
 
 type testRig struct {
 	router  *BookRouter
@@ -2040,6 +2227,34 @@ func TestRouter(t *testing.T) {
 	}
 }
 
+func TestFeeRateRange(t *testing.T) {
+	router := rig.router
+	feeSrc := router.feeSource.(*tFeeSource)
+	feeRange := &asset.FeeRateRange{MinToConfirm: 2, Economical: 5, Priority: 9}
+	feeSrc.setRanges(map[uint32]*asset.FeeRateRange{mkt1.Base: feeRange})
+	defer feeSrc.setRanges(nil)
+
+	link, sub := newSubscriber(mkt1)
+	if err := router.handleOrderBook(link, sub); err != nil {
+		t.Fatalf("handleOrderBook: %v", err)
+	}
+	resp, err := link.getSend().Response()
+	if err != nil {
+		t.Fatalf("error parsing response: %v", err)
+	}
+	book := new(msgjson.OrderBook)
+	if err = json.Unmarshal(resp.Result, book); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	exp := msgjson.FeeRateRange{MinToConfirm: 2, Economical: 5, Priority: 9}
+	if book.BaseFeeRange == nil || *book.BaseFeeRange != exp {
+		t.Fatalf("wrong base fee rate range. wanted %+v, got %+v", exp, book.BaseFeeRange)
+	}
+	if book.QuoteFeeRange != nil {
+		t.Fatalf("unexpected quote fee rate range %+v", book.QuoteFeeRange)
+	}
+}
+
 // func TestFeeRateRequest(t *testing.T) {
 // 	router := rig.router
 // 	cl := tNewLink()
@@ -2192,6 +2407,184 @@ func TestPriceFeed(t *testing.T) {
 	}
 }
 
+func TestRecentTrades(t *testing.T) {
+	router := NewBookRouter(rig.sources(), &tFeeSource{}, 3, 0, func(route string, handler comms.MsgHandler) {})
+	book := router.books[mktName1]
+
+	// Two epochs. Positive quantities are for selling takers.
+	book.addTrades([][2]int64{{5e7, 1e8}, {5e7 + 1e5, -2e8}}, 1000)
+	book.addTrades(nil, 2000)
+	book.addTrades([][2]int64{{6e7, -3e8}, {6e7 + 1e5, 4e8}}, 3000)
+
+	expTape := []*msgjson.PublicTrade{
+		{Rate: 6e7 + 1e5, Qty: 4e8, Sell: true, Stamp: 3000},
+		{Rate: 6e7, Qty: 3e8, Sell: false, Stamp: 3000},
+		{Rate: 5e7 + 1e5, Qty: 2e8, Sell: false, Stamp: 1000},
+	}
+
+	request := func(n int) []*msgjson.PublicTrade {
+		t.Helper()
+		link := tNewLink()
+		req, _ := msgjson.NewRequest(1, msgjson.RecentTradesRoute, &msgjson.RecentTradesRequest{
+			BaseID:  mkt1.Base,
+			QuoteID: mkt1.Quote,
+			N:       n,
+		})
+		if rpcErr := router.handleRecentTrades(link, req); rpcErr != nil {
+			t.Fatalf("handleRecentTrades error: %v", rpcErr)
+		}
+		var trades []*msgjson.PublicTrade
+		if err := link.getSend().UnmarshalResult(&trades); err != nil {
+			t.Fatalf("error unmarshaling recent_trades response: %v", err)
+		}
+		return trades
+	}
+
+	for _, n := range []int{0, 2, 10} {
+		trades := request(n)
+		expN := n
+		if n == 0 || n > len(expTape) {
+			expN = len(expTape)
+		}
+		if len(trades) != expN {
+			t.Fatalf("n = %d: expected %d trades, got %d", n, expN, len(trades))
+		}
+		for i, trade := range trades {
+			if *trade != *expTape[i] {
+				t.Fatalf("n = %d: wrong trade %d. expected %+v, got %+v", n, i, expTape[i], trade)
+			}
+		}
+	}
+
+	// Only the public fields are sent.
+	link := tNewLink()
+	req, _ := msgjson.NewRequest(1, msgjson.RecentTradesRoute, &msgjson.RecentTradesRequest{
+		BaseID:  mkt1.Base,
+		QuoteID: mkt1.Quote,
+		N:       1,
+	})
+	router.handleRecentTrades(link, req)
+	var rawTrades []map[string]any
+	if err := link.getSend().UnmarshalResult(&rawTrades); err != nil {
+		t.Fatalf("error unmarshaling raw recent_trades response: %v", err)
+	}
+	for k := range rawTrades[0] {
+		switch k {
+		case "rate", "qty", "sell", "stamp":
+		default:
+			t.Fatalf("unexpected trade field %q", k)
+		}
+	}
+
+	// Unknown market.
+	req, _ = msgjson.NewRequest(1, msgjson.RecentTradesRoute, &msgjson.RecentTradesRequest{
+		BaseID:  mkt2.Base,
+		QuoteID: mkt1.Quote,
+	})
+	rpcErr := router.handleRecentTrades(tNewLink(), req)
+	if rpcErr == nil || rpcErr.Code != msgjson.UnknownMarket {
+		t.Fatalf("expected an unknown market error, got %v", rpcErr)
+	}
+}
+
+func TestRecentTradesSeeded(t *testing.T) {
+	src := tNewBookSource(mkt1.Base, mkt1.Quote)
+	src.trades = []*msgjson.PublicTrade{
+		{Rate: 6e7, Qty: 1e8, Sell: true, Stamp: 3000},
+		{Rate: 5e7, Qty: 2e8, Sell: false, Stamp: 2000},
+		{Rate: 4e7, Qty: 3e8, Sell: true, Stamp: 1000},
+	}
+	router := NewBookRouter(map[string]BookSource{mktName1: src}, &tFeeSource{}, 2,
+		0, func(route string, handler comms.MsgHandler) {})
+	book := router.books[mktName1]
+
+	ctx, cancel := context.WithCancel(testCtx)
+	defer cancel()
+	go router.Run(ctx)
+
+	var trades []*msgjson.PublicTrade
+	for i := 0; i < 50; i++ {
+		if trades = book.recentTrades(0); len(trades) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Trimmed to the tape size.
+	if len(trades) != 2 {
+		t.Fatalf("expected 2 seeded trades, got %d", len(trades))
+	}
+	for i, trade := range trades {
+		if *trade != *src.trades[i] {
+			t.Fatalf("wrong trade %d. expected %+v, got %+v", i, src.trades[i], trade)
+		}
+	}
+
+	// New trades go on top of the seeded ones.
+	book.addTrades([][2]int64{{7e7, -4e8}}, 4000)
+	trades = book.recentTrades(0)
+	if len(trades) != 2 || trades[0].Rate != 7e7 || *trades[1] != *src.trades[0] {
+		t.Fatalf("wrong tape after a new trade: %+v", trades)
+	}
+}
+
+func TestFeeRateHistory(t *testing.T) {
+	rates := []*msgjson.FeeRateRecord{
+		{Rate: 10, Stamp: 1000},
+		{Rate: 20, Stamp: 2000},
+		{Rate: 15, Stamp: 3000},
+	}
+	feeSource := &tFeeSource{hists: map[uint32][]*msgjson.FeeRateRecord{mkt1.Base: rates}}
+	router := NewBookRouter(rig.sources(), feeSource, 0, 0, func(route string, handler comms.MsgHandler) {})
+
+	request := func(assetID uint32, n int) []*msgjson.FeeRateRecord {
+		t.Helper()
+		link := tNewLink()
+		req, _ := msgjson.NewRequest(1, msgjson.FeeRateHistoryRoute, &msgjson.FeeRateHistoryRequest{
+			AssetID: assetID,
+			N:       n,
+		})
+		if rpcErr := router.handleFeeRateHistory(link, req); rpcErr != nil {
+			t.Fatalf("handleFeeRateHistory error: %v", rpcErr)
+		}
+		var recs []*msgjson.FeeRateRecord
+		if err := link.getSend().UnmarshalResult(&recs); err != nil {
+			t.Fatalf("error unmarshaling fee_rate_history response: %v", err)
+		}
+		if recs == nil {
+			t.Fatalf("null fee_rate_history response")
+		}
+		return recs
+	}
+
+	for _, n := range []int{0, 2, 10} {
+		recs := request(mkt1.Base, n)
+		exp := rates
+		if n > 0 && n < len(rates) {
+			exp = rates[len(rates)-n:]
+		}
+		if len(recs) != len(exp) {
+			t.Fatalf("n = %d: expected %d rates, got %d", n, len(exp), len(recs))
+		}
+		for i, rec := range recs {
+			if *rec != *exp[i] {
+				t.Fatalf("n = %d: wrong rate %d. expected %+v, got %+v", n, i, exp[i], rec)
+			}
+		}
+	}
+
+	// No history is an empty list.
+	if recs := request(mkt1.Quote, 0); len(recs) != 0 {
+		t.Fatalf("expected no rates, got %d", len(recs))
+	}
+
+	// Bad request.
+	req, _ := msgjson.NewRequest(1, msgjson.FeeRateHistoryRoute, "abc")
+	rpcErr := router.handleFeeRateHistory(tNewLink(), req)
+	if rpcErr == nil || rpcErr.Code != msgjson.RPCParseError {
+		t.Fatalf("expected a parse error, got %v", rpcErr)
+	}
+}
+
 func TestParcelLimits(t *testing.T) {
 	mkt0 := tNewMarket(oRig.auth)
 	mkt1 := tNewMarket(oRig.auth)
@@ -2289,3 +2682,105 @@ func TestParcelLimits(t *testing.T) {
 	lo.Quantity += lotSize
 	ensureErr()
 }
+
+func TestBookPagination(t *testing.T) {
+	const numOrders = 2000
+	ob := &msgjson.OrderBook{
+		MarketID:      mktName1,
+		Seq:           1234,
+		Epoch:         5678,
+		Orders:        make([]*msgjson.BookOrderNote, 0, numOrders),
+		RecentMatches: [][3]int64{{5e7, 1e8, 1000}},
+	}
+	for i := 0; i < numOrders; i++ {
+		lo := makeLO(seller1, mkRate1(1.0, 1.2), randLots(10), order.StandingTiF)
+		ob.Orders = append(ob.Orders, &msgjson.BookOrderNote{
+			OrderNote: msgjson.OrderNote{OrderID: idToBytes(lo.ID())},
+			TradeNote: msgjson.TradeNote{
+				Side:     msgjson.SellOrderNum,
+				Quantity: lo.Remaining(),
+				Rate:     lo.Rate,
+				TiF:      msgjson.StandingOrderNum,
+				Time:     uint64(lo.ServerTime.UnixMilli()),
+			},
+		})
+	}
+	encSize := len(mustEncode(t, ob))
+
+	test := func(maxMsgSize int64, wantPaged bool) {
+		t.Helper()
+		router := &BookRouter{maxMsgSize: maxMsgSize}
+		msgs, err := router.bookMessages(ob, 1, true)
+		if err != nil {
+			t.Fatalf("bookMessages error: %v", err)
+		}
+		if paged := len(msgs) > 1; paged != wantPaged {
+			t.Fatalf("%d byte book with %d byte limit: wanted paged = %t, got %d messages",
+				encSize, maxMsgSize, wantPaged, len(msgs))
+		}
+
+		var book msgjson.OrderBook
+		for i, b := range msgs {
+			if int64(len(b)) > maxMsgSize {
+				t.Fatalf("message %d of %d bytes exceeds the %d byte limit", i, len(b), maxMsgSize)
+			}
+			msg, err := msgjson.DecodeMessage(b)
+			if err != nil {
+				t.Fatalf("error decoding message %d: %v", i, err)
+			}
+			if i == 0 {
+				if err = msg.UnmarshalResult(&book); err != nil {
+					t.Fatalf("error decoding response: %v", err)
+				}
+				if wantPaged && book.Pages != uint32(len(msgs)) {
+					t.Fatalf("wrong number of pages. wanted %d, got %d", len(msgs), book.Pages)
+				}
+				continue
+			}
+			if msg.Route != msgjson.OrderBookPageRoute {
+				t.Fatalf("wrong route for page %d: %s", i, msg.Route)
+			}
+			var page msgjson.OrderBookPage
+			if err = msg.Unmarshal(&page); err != nil {
+				t.Fatalf("error decoding page %d: %v", i, err)
+			}
+			if page.Page != uint32(i) || page.Seq != ob.Seq || page.MarketID != ob.MarketID {
+				t.Fatalf("wrong page %d, seq %d, or market %s for page %d", page.Page, page.Seq, page.MarketID, i)
+			}
+			book.Orders = append(book.Orders, page.Orders...)
+		}
+		if len(book.Orders) != numOrders {
+			t.Fatalf("wanted %d orders, got %d", numOrders, len(book.Orders))
+		}
+		for i, o := range book.Orders {
+			if !bytes.Equal(o.OrderID, ob.Orders[i].OrderID) {
+				t.Fatalf("wrong order at index %d", i)
+			}
+		}
+	}
+
+	// Small limit.
+	test(65536, true)
+	// Large limit.
+	test(msgjson.DefaultMaxMessageSize, false)
+
+	// A client that does not support book pages gets the whole book, even if
+	// it is too large to send.
+	router := &BookRouter{maxMsgSize: 65536}
+	msgs, err := router.bookMessages(ob, 1, false)
+	if err != nil {
+		t.Fatalf("bookMessages error: %v", err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("book paginated for client without book pages support")
+	}
+}
+
+func mustEncode(t *testing.T, thing any) []byte {
+	t.Helper()
+	b, err := json.Marshal(thing)
+	if err != nil {
+		t.Fatalf("encoding error: %v", err)
+	}
+	return b
+}
@@ -0,0 +1,237 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package market
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// scheduleCheckInterval is the time between trading hours checks by a
+// Scheduler. Failed suspensions and resumptions are retried at this interval.
+const scheduleCheckInterval = 10 * time.Second
+
+// TradingWindow is a daily period during which a market is open.
+type TradingWindow struct {
+	// Days are the days of the week on which the window opens. If empty, the
+	// window opens every day.
+	Days []time.Weekday
+	// Open and Close are the wall clock times of day, as offsets from
+	// midnight, at which the window opens and closes. If Close is not after
+	// Open, the window closes on the following day.
+	Open, Close time.Duration
+}
+
+// Schedule is a market's trading hours. The market is open during any of the
+// trading windows, and closed otherwise.
+type Schedule struct {
+	// Location is the time zone of the trading windows. If nil, UTC is used.
+	Location *time.Location
+	Windows  []*TradingWindow
+}
+
+// NewSchedule is the constructor for a Schedule. The windows are validated.
+func NewSchedule(loc *time.Location, windows []*TradingWindow) (*Schedule, error) {
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("no trading windows")
+	}
+	for i, w := range windows {
+		if w.Open < 0 || w.Open >= 24*time.Hour || w.Close < 0 || w.Close > 24*time.Hour {
+			return nil, fmt.Errorf("trading window %d times are not in a day", i)
+		}
+		if w.Open == w.Close {
+			return nil, fmt.Errorf("trading window %d opens and closes at the same time", i)
+		}
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &Schedule{Location: loc, Windows: windows}, nil
+}
+
+type tradingPeriod struct {
+	open, close time.Time
+}
+
+// periods returns the merged, sorted trading periods that start in the range
+// of days beginning two days before t and ending about a week after t.
+func (s *Schedule) periods(t time.Time) []*tradingPeriod {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	var periods []*tradingPeriod
+	for d := -2; d <= 8; d++ {
+		day := time.Date(t.Year(), t.Month(), t.Day()+d, 0, 0, 0, 0, loc)
+		for _, w := range s.Windows {
+			if !w.opensOn(day.Weekday()) {
+				continue
+			}
+			closeDay := day
+			if w.Close <= w.Open {
+				closeDay = closeDay.AddDate(0, 0, 1)
+			}
+			periods = append(periods, &tradingPeriod{
+				open:  wallClock(day, w.Open),
+				close: wallClock(closeDay, w.Close),
+			})
+		}
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].open.Before(periods[j].open)
+	})
+	// Merge overlapping and adjacent periods so that a market is not closed
+	// and immediately reopened.
+	merged := make([]*tradingPeriod, 0, len(periods))
+	for _, p := range periods {
+		if n := len(merged); n > 0 && !p.open.After(merged[n-1].close) {
+			if p.close.After(merged[n-1].close) {
+				merged[n-1].close = p.close
+			}
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+func (w *TradingWindow) opensOn(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// wallClock returns the time on the day at the wall clock time of day. The day
+// must be midnight in the desired location.
+func wallClock(day time.Time, tod time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, int(tod/time.Second), 0, day.Location())
+}
+
+// IsOpen checks whether the market is scheduled to be open at time t.
+func (s *Schedule) IsOpen(t time.Time) bool {
+	for _, p := range s.periods(t) {
+		if !t.Before(p.open) && t.Before(p.close) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the next time at or after t that the market is scheduled to
+// be open.
+func (s *Schedule) NextOpen(t time.Time) time.Time {
+	for _, p := range s.periods(t) {
+		if t.Before(p.close) {
+			if t.Before(p.open) {
+				return p.open
+			}
+			return t
+		}
+	}
+	return time.Time{} // not possible with at least one window
+}
+
+// NextClose returns the next time after t that the market is scheduled to
+// close. The zero time is returned if the market is not scheduled to close in
+// the next week.
+func (s *Schedule) NextClose(t time.Time) time.Time {
+	periods := s.periods(t)
+	for i, p := range periods {
+		if t.Before(p.close) {
+			if i == len(periods)-1 && p.close.Sub(t) > 7*24*time.Hour {
+				return time.Time{} // open around the clock
+			}
+			return p.close
+		}
+	}
+	return time.Time{}
+}
+
+// Scheduler opens and closes markets according to their trading hours. A
+// market is closed with the onClose callback when its schedule closes, and is
+// reopened with the onOpen callback when its schedule reopens. A callback that
+// returns an error is retried. The Scheduler only acts at the schedule
+// boundaries, so a market suspended or resumed by the operator stays that way
+// until the next boundary.
+type Scheduler struct {
+	schedules map[string]*Schedule
+	onOpen    func(mktName string) error
+	onClose   func(mktName string, reopen time.Time) error
+	now       func() time.Time
+
+	mtx  sync.Mutex
+	open map[string]bool
+}
+
+// NewScheduler is the constructor for a Scheduler. The markets are assumed to
+// be open initially, so markets outside of their trading hours are closed on
+// the first check. The callbacks are run synchronously from Run.
+func NewScheduler(schedules map[string]*Schedule, onOpen func(mktName string) error,
+	onClose func(mktName string, reopen time.Time) error) *Scheduler {
+
+	open := make(map[string]bool, len(schedules))
+	for name := range schedules {
+		open[name] = true
+	}
+	return &Scheduler{
+		schedules: schedules,
+		onOpen:    onOpen,
+		onClose:   onClose,
+		now:       time.Now,
+		open:      open,
+	}
+}
+
+// Run checks the market schedules until the context is canceled.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.check()
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// check opens or closes any markets that have crossed a schedule boundary.
+func (s *Scheduler) check() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	now := s.now()
+	for name, sched := range s.schedules {
+		open := sched.IsOpen(now)
+		if open == s.open[name] {
+			continue
+		}
+		if open {
+			if err := s.onOpen(name); err != nil {
+				log.Errorf("Unable to open market %s for trading hours: %v", name, err)
+				continue
+			}
+			log.Infof("Market %s opened for trading hours. Closing at %v.", name, sched.NextClose(now))
+		} else {
+			reopen := sched.NextOpen(now)
+			if err := s.onClose(name, reopen); err != nil {
+				log.Errorf("Unable to close market %s outside of trading hours: %v", name, err)
+				continue
+			}
+			log.Infof("Market %s closed outside of trading hours. Reopening at %v.", name, reopen)
+		}
+		s.open[name] = open
+	}
+}
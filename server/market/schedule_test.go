@@ -0,0 +1,160 @@
+package market
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"decred.org/dcrdex/dex/msgjson"
+)
+
+func TestSchedule(t *testing.T) {
+	// Weekdays 09:30 to 16:00 in UTC-5, and an overnight session from Sunday
+	// 22:00 to Monday 02:00.
+	loc := time.FixedZone("TEST", -5*3600)
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	sched, err := NewSchedule(loc, []*TradingWindow{
+		{Days: weekdays, Open: 9*time.Hour + 30*time.Minute, Close: 16 * time.Hour},
+		{Days: []time.Weekday{time.Sunday}, Open: 22 * time.Hour, Close: 2 * time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("NewSchedule error: %v", err)
+	}
+	at := func(day, hour, min int) time.Time { // day of March 2024, Friday the 1st
+		return time.Date(2024, time.March, day, hour, min, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name      string
+		t         time.Time
+		open      bool
+		nextOpen  time.Time
+		nextClose time.Time
+	}{
+		{"before open", at(1, 9, 29), false, at(1, 9, 30), at(1, 16, 0)},
+		{"at open", at(1, 9, 30), true, at(1, 9, 30), at(1, 16, 0)},
+		{"at close", at(1, 16, 0), false, at(3, 22, 0), at(4, 2, 0)},
+		{"weekend", at(2, 12, 0), false, at(3, 22, 0), at(4, 2, 0)},
+		{"overnight", at(4, 1, 0), true, at(4, 1, 0), at(4, 2, 0)},
+		{"after overnight", at(4, 2, 0), false, at(4, 9, 30), at(4, 16, 0)},
+		{"utc", at(4, 10, 0).UTC(), true, at(4, 10, 0), at(4, 16, 0)},
+	}
+	for _, tt := range tests {
+		if open := sched.IsOpen(tt.t); open != tt.open {
+			t.Fatalf("%s: expected open = %t, got %t", tt.name, tt.open, open)
+		}
+		if nextOpen := sched.NextOpen(tt.t); !nextOpen.Equal(tt.nextOpen) {
+			t.Fatalf("%s: expected next open %v, got %v", tt.name, tt.nextOpen, nextOpen)
+		}
+		if nextClose := sched.NextClose(tt.t); !nextClose.Equal(tt.nextClose) {
+			t.Fatalf("%s: expected next close %v, got %v", tt.name, tt.nextClose, nextClose)
+		}
+	}
+
+	// Adjacent windows are merged, and a market that is always open never
+	// closes.
+	sched, _ = NewSchedule(nil, []*TradingWindow{
+		{Open: 0, Close: 12 * time.Hour},
+		{Open: 12 * time.Hour, Close: 24 * time.Hour},
+	})
+	if !sched.IsOpen(at(1, 12, 0)) || !sched.NextClose(at(1, 12, 0)).IsZero() {
+		t.Fatalf("always open schedule closes")
+	}
+
+	for _, w := range []*TradingWindow{
+		{Open: time.Hour, Close: time.Hour},
+		{Open: 24 * time.Hour, Close: time.Hour},
+		{Open: time.Hour, Close: 25 * time.Hour},
+	} {
+		if _, err := NewSchedule(nil, []*TradingWindow{w}); err == nil {
+			t.Fatalf("no error for invalid window %+v", w)
+		}
+	}
+	if _, err := NewSchedule(nil, nil); err == nil {
+		t.Fatalf("no error for no windows")
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	sched, _ := NewSchedule(time.UTC, []*TradingWindow{{Open: 9 * time.Hour, Close: 17 * time.Hour}})
+	const mktName = "dcr_btc"
+	var opens, closes int
+	var reopen time.Time
+	var openErr, closeErr error
+	s := NewScheduler(map[string]*Schedule{mktName: sched},
+		func(name string) error {
+			if openErr != nil {
+				return openErr
+			}
+			opens++
+			return nil
+		},
+		func(name string, t time.Time) error {
+			if closeErr != nil {
+				return closeErr
+			}
+			closes++
+			reopen = t
+			return nil
+		})
+	now := time.Date(2024, time.March, 1, 8, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	checkCounts := func(tag string, expOpens, expCloses int) {
+		t.Helper()
+		s.check()
+		if opens != expOpens || closes != expCloses {
+			t.Fatalf("%s: expected %d opens and %d closes, got %d and %d", tag, expOpens, expCloses, opens, closes)
+		}
+	}
+
+	// The market starts outside of its trading hours, so it is closed.
+	checkCounts("startup", 0, 1)
+	if exp := time.Date(2024, time.March, 1, 9, 0, 0, 0, time.UTC); !reopen.Equal(exp) {
+		t.Fatalf("wrong reopen time %v", reopen)
+	}
+	checkCounts("still closed", 0, 1)
+
+	// Opens at the boundary.
+	now = now.Add(time.Hour)
+	checkCounts("open", 1, 1)
+	now = now.Add(time.Hour)
+	checkCounts("still open", 1, 1)
+
+	// Closes at the boundary, with a retry after an error.
+	now = time.Date(2024, time.March, 1, 17, 0, 0, 0, time.UTC)
+	closeErr = errors.New("test error")
+	checkCounts("close error", 1, 1)
+	closeErr = nil
+	checkCounts("close retry", 1, 2)
+
+	// Opens the next day, with a retry after an error.
+	now = now.Add(16 * time.Hour)
+	openErr = errors.New("test error")
+	checkCounts("open error", 1, 2)
+	openErr = nil
+	checkCounts("open retry", 2, 2)
+}
+
+func TestMarketClosedError(t *testing.T) {
+	sched, _ := NewSchedule(time.UTC, []*TradingWindow{{Open: 9 * time.Hour, Close: 17 * time.Hour}})
+	r := &OrderRouter{schedules: map[string]*Schedule{"dcr_btc": sched}}
+	now := time.Date(2024, time.March, 1, 18, 0, 0, 0, time.UTC)
+
+	msgErr := r.marketClosedError(42, 0, now)
+	if msgErr.Code != msgjson.MarketNotRunningError || !strings.Contains(msgErr.Message, "2024-03-02T09:00:00Z") {
+		t.Fatalf("wrong error outside of trading hours: %v", msgErr)
+	}
+
+	// Suspended during trading hours.
+	msgErr = r.marketClosedError(42, 0, now.Add(-2*time.Hour))
+	if msgErr.Code != msgjson.MarketNotRunningError || strings.Contains(msgErr.Message, "trading hours") {
+		t.Fatalf("wrong error during trading hours: %v", msgErr)
+	}
+
+	// Unscheduled market.
+	msgErr = r.marketClosedError(60, 0, now)
+	if msgErr.Code != msgjson.MarketNotRunningError || strings.Contains(msgErr.Message, "trading hours") {
+		t.Fatalf("wrong error for unscheduled market: %v", msgErr)
+	}
+}
@@ -0,0 +1,100 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package matcher
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
+	"github.com/decred/dcrd/crypto/blake256"
+)
+
+// VerifyEpochAudit checks the commit-reveal record of an epoch. Every revealed
+// preimage must hash to its order's commitment, the commitment checksum must be
+// the hash of all of the sorted commitments, and the shuffle seed must be the
+// hash of the revealed preimages sorted by order ID. The IDs of the revealed
+// orders are returned in the shuffled order in which they were matched.
+func VerifyEpochAudit(audit *msgjson.EpochAudit) ([]order.OrderID, error) {
+	if len(audit.Orders) == 0 {
+		if len(audit.CSum) != 0 || len(audit.Seed) != 0 {
+			return nil, fmt.Errorf("non-empty csum or seed for an empty epoch")
+		}
+		return nil, nil
+	}
+
+	type revealedOrder struct {
+		oid order.OrderID
+		pi  order.Preimage
+	}
+	commits := make([]order.Commitment, 0, len(audit.Orders))
+	revealed := make([]*revealedOrder, 0, len(audit.Orders))
+	for _, ord := range audit.Orders {
+		if len(ord.OrderID) != order.OrderIDSize {
+			return nil, fmt.Errorf("invalid order ID length %d", len(ord.OrderID))
+		}
+		if len(ord.Commit) != order.CommitmentSize {
+			return nil, fmt.Errorf("invalid commitment length %d for order %x", len(ord.Commit), ord.OrderID)
+		}
+		var commit order.Commitment
+		copy(commit[:], ord.Commit)
+		commits = append(commits, commit)
+		if len(ord.Preimage) == 0 {
+			continue // missed
+		}
+		if len(ord.Preimage) != order.PreimageSize {
+			return nil, fmt.Errorf("invalid preimage length %d for order %x", len(ord.Preimage), ord.OrderID)
+		}
+		ro := new(revealedOrder)
+		copy(ro.oid[:], ord.OrderID)
+		copy(ro.pi[:], ord.Preimage)
+		if ro.pi.Commit() != commit {
+			return nil, fmt.Errorf("preimage %x for order %v does not match commitment %v", ro.pi[:], ro.oid, commit)
+		}
+		revealed = append(revealed, ro)
+	}
+
+	// The commitment checksum covers all orders, revealed or not.
+	sort.Slice(commits, func(i, j int) bool {
+		return bytes.Compare(commits[i][:], commits[j][:]) < 0
+	})
+	hasher := blake256.New()
+	for i := range commits {
+		hasher.Write(commits[i][:])
+	}
+	if csum := hasher.Sum(nil); !bytes.Equal(csum, audit.CSum) {
+		return nil, fmt.Errorf("commitment checksum mismatch. computed %x, recorded %x", csum, audit.CSum)
+	}
+
+	if len(revealed) == 0 {
+		if len(audit.Seed) != 0 {
+			return nil, fmt.Errorf("non-empty seed with no revealed preimages")
+		}
+		return nil, nil
+	}
+
+	// The seed is the hash of the revealed preimages, sorted by order ID.
+	sort.Slice(revealed, func(i, j int) bool {
+		return bytes.Compare(revealed[i].oid[:], revealed[j].oid[:]) < 0
+	})
+	hasher = blake256.New()
+	for _, ro := range revealed {
+		hasher.Write(ro.pi[:])
+	}
+	seed := hasher.Sum(nil)
+	if !bytes.Equal(seed, audit.Seed) {
+		return nil, fmt.Errorf("seed mismatch. computed %x, recorded %x", seed, audit.Seed)
+	}
+
+	oids := make([]order.OrderID, len(revealed))
+	for i, ro := range revealed {
+		oids[i] = ro.oid
+	}
+	shuffle(seed, len(oids), func(i, j int) {
+		oids[i], oids[j] = oids[j], oids[i]
+	})
+	return oids, nil
+}
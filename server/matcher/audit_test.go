@@ -0,0 +1,116 @@
+package matcher
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"decred.org/dcrdex/dex/msgjson"
+	"decred.org/dcrdex/dex/order"
+)
+
+func TestVerifyEpochAudit(t *testing.T) {
+	// An epoch of five orders, one of which missed preimage collection.
+	var queue []order.Order
+	var revealed []*OrderRevealed
+	for i := 0; i < 5; i++ {
+		or := newLimit(i%2 == 0, 4300000, 1, order.StandingTiF, int64(i))
+		queue = append(queue, or.Order)
+		if i != 3 {
+			revealed = append(revealed, or)
+		}
+	}
+	csum := CSum(queue)
+	shuffled := make([]*OrderRevealed, len(revealed))
+	copy(shuffled, revealed)
+	seed := shuffleQueue(shuffled)
+
+	newAudit := func() *msgjson.EpochAudit {
+		audit := &msgjson.EpochAudit{
+			CSum: csum,
+			Seed: seed,
+		}
+		preimages := make(map[order.OrderID]order.Preimage, len(revealed))
+		for _, or := range revealed {
+			preimages[or.Order.ID()] = or.Preimage
+		}
+		for _, ord := range queue {
+			commit := ord.Commitment()
+			ao := &msgjson.EpochAuditOrder{
+				OrderID: ord.ID().Bytes(),
+				Commit:  commit[:],
+			}
+			if pi, found := preimages[ord.ID()]; found {
+				ao.Preimage = pi[:]
+			}
+			audit.Orders = append(audit.Orders, ao)
+		}
+		sort.Slice(audit.Orders, func(i, j int) bool {
+			return bytes.Compare(audit.Orders[i].Commit, audit.Orders[j].Commit) < 0
+		})
+		return audit
+	}
+
+	// The recorded commitments match the reveals, and the verified ordering is
+	// the shuffled order used for matching, every time.
+	for i := 0; i < 3; i++ {
+		oids, err := VerifyEpochAudit(newAudit())
+		if err != nil {
+			t.Fatalf("VerifyEpochAudit error: %v", err)
+		}
+		if len(oids) != len(shuffled) {
+			t.Fatalf("expected %d order IDs, got %d", len(shuffled), len(oids))
+		}
+		for j, or := range shuffled {
+			if oids[j] != or.Order.ID() {
+				t.Fatalf("wrong order at position %d", j)
+			}
+		}
+	}
+
+	// A preimage that does not match its commitment.
+	audit := newAudit()
+	for _, ao := range audit.Orders {
+		if len(ao.Preimage) > 0 {
+			ao.Preimage = append([]byte{}, ao.Preimage...)
+			ao.Preimage[0] ^= 0x01
+			break
+		}
+	}
+	if _, err := VerifyEpochAudit(audit); err == nil {
+		t.Fatalf("no error for preimage not matching commitment")
+	}
+
+	// An omitted order changes the commitment checksum.
+	audit = newAudit()
+	audit.Orders = audit.Orders[1:]
+	if _, err := VerifyEpochAudit(audit); err == nil {
+		t.Fatalf("no error for omitted order")
+	}
+
+	// A withheld preimage changes the seed.
+	audit = newAudit()
+	for _, ao := range audit.Orders {
+		if len(ao.Preimage) > 0 {
+			ao.Preimage = nil
+			break
+		}
+	}
+	if _, err := VerifyEpochAudit(audit); err == nil {
+		t.Fatalf("no error for withheld preimage")
+	}
+
+	// A wrong seed.
+	audit = newAudit()
+	audit.Seed = append([]byte{}, seed...)
+	audit.Seed[0] ^= 0x01
+	if _, err := VerifyEpochAudit(audit); err == nil {
+		t.Fatalf("no error for wrong seed")
+	}
+
+	// An empty epoch.
+	oids, err := VerifyEpochAudit(&msgjson.EpochAudit{})
+	if err != nil || len(oids) != 0 {
+		t.Fatalf("unexpected result for empty epoch: %v, %v", oids, err)
+	}
+}
@@ -633,19 +633,26 @@ func shuffleQueue(queue []*OrderRevealed) (seed []byte) {
 	// Fisher-Yates shuffle the slice using MT19937 seeded with the hash.
 	seed = hasher.Sum(nil)
 	// seed = HashFunc(hashCat)
+	shuffle(seed, qLen, func(i, j int) {
+		queue[i], queue[j] = queue[j], queue[i]
+	})
+
+	return
+}
 
+// shuffle performs a Fisher-Yates shuffle of n elements using MT19937 seeded
+// with the provided seed.
+func shuffle(seed []byte, n int, swap func(i, j int)) {
 	// This seeded random number generator is used to generate one sequence, and
 	// the seed is revealed then revealed. It need not be cryptographically
 	// secure.
 	mtSrc := mt19937.NewSource()
 	mtSrc.SeedBytes(seed[:])
 	prng := rand.New(mtSrc)
-	for i := range queue {
-		j := prng.Intn(qLen-i) + i
-		queue[i], queue[j] = queue[j], queue[i]
+	for i := 0; i < n; i++ {
+		j := prng.Intn(n-i) + i
+		swap(i, j)
 	}
-
-	return
 }
 
 func midGap(book Booker) uint64 {
@@ -0,0 +1,258 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package swap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"decred.org/dcrdex/dex"
+	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/server/account"
+	"decred.org/dcrdex/server/asset"
+)
+
+// adminCheckTimeout is the timeout for the on-chain checks of the operator's
+// match inspection and reconciliation requests.
+const adminCheckTimeout = 30 * time.Second
+
+// SwapState is the state of one party's side of an active match.
+type SwapState struct {
+	AccountID account.AccountID `json:"accountID"`
+	OrderID   order.OrderID     `json:"orderID"`
+	SwapAsset uint32            `json:"swapAsset"`
+	// Swap is the coin ID of the party's swap contract, if it has been seen.
+	Swap       dex.Bytes `json:"swap,omitempty"`
+	SwapString string    `json:"swapString,omitempty"`
+	LockTime   time.Time `json:"lockTime,omitempty"`
+	// SwapConfs is the number of confirmations of the swap, or -1 if they
+	// could not be determined.
+	SwapConfs     int64     `json:"swapConfs"`
+	SwapTime      time.Time `json:"swapTime,omitempty"`
+	SwapConfirmed time.Time `json:"swapConfirmed,omitempty"`
+	// ContractSpent is whether the swap contract output has been spent, for
+	// assets that track outputs. It is nil if unknown.
+	ContractSpent *bool     `json:"contractSpent,omitempty"`
+	Redemption    dex.Bytes `json:"redemption,omitempty"`
+	RedeemTime    time.Time `json:"redeemTime,omitempty"`
+	// Searching is true while the Swapper is waiting for the party's swap or
+	// redemption transaction to be found.
+	Searching bool `json:"searching"`
+}
+
+// MatchState is the full state of an active match, for operator inspection.
+type MatchState struct {
+	MatchID   order.MatchID `json:"matchID"`
+	Status    string        `json:"status"`
+	Base      uint32        `json:"base"`
+	Quote     uint32        `json:"quote"`
+	Quantity  uint64        `json:"quantity"`
+	Rate      uint64        `json:"rate"`
+	MatchTime time.Time     `json:"matchTime"`
+	Maker     *SwapState    `json:"maker"`
+	Taker     *SwapState    `json:"taker"`
+}
+
+// activeMatch returns the active match with the ID, or an error if there is
+// no such match.
+func (s *Swapper) activeMatch(mid order.MatchID) (*matchTracker, error) {
+	s.matchMtx.RLock()
+	defer s.matchMtx.RUnlock()
+	match, found := s.matches[mid]
+	if !found {
+		return nil, fmt.Errorf("no active match %v", mid)
+	}
+	return match, nil
+}
+
+func (mt *matchTracker) status() order.MatchStatus {
+	mt.mtx.RLock()
+	defer mt.mtx.RUnlock()
+	return mt.Status
+}
+
+// MatchState returns the state of the active match with the ID, including the
+// on-chain state of the parties' swap contracts.
+func (s *Swapper) MatchState(mid order.MatchID) (*MatchState, error) {
+	match, err := s.activeMatch(mid)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), adminCheckTimeout)
+	defer cancel()
+
+	swapState := func(ord order.Order, ss *swapStatus) *SwapState {
+		ss.mtx.RLock()
+		state := &SwapState{
+			AccountID:     ord.User(),
+			OrderID:       ord.ID(),
+			SwapAsset:     ss.swapAsset,
+			SwapConfs:     -1,
+			SwapTime:      ss.swapTime,
+			SwapConfirmed: ss.swapConfirmed,
+			RedeemTime:    ss.redeemTime,
+			Searching:     atomic.LoadUint32(&ss.swapSearching) == 1 || atomic.LoadUint32(&ss.redeemSearching) == 1,
+		}
+		contract := ss.swap
+		if ss.redemption != nil {
+			state.Redemption = ss.redemption.ID()
+		}
+		ss.mtx.RUnlock()
+		if contract == nil {
+			return state
+		}
+
+		state.Swap, state.SwapString, state.LockTime = contract.ID(), contract.String(), contract.LockTime
+		if confs, err := contract.Confirmations(ctx); err == nil {
+			state.SwapConfs = confs
+		} else {
+			log.Warnf("Error getting confirmations of swap %v for match %v: %v", contract, mid, err)
+		}
+		if tracker, is := s.coins[ss.swapAsset].Backend.(asset.OutputTracker); is {
+			err := tracker.VerifyUnspentCoin(ctx, contract.ID())
+			switch {
+			case err == nil:
+				state.ContractSpent = new(bool)
+			case errors.Is(err, asset.CoinNotFoundError):
+				spent := true
+				state.ContractSpent = &spent
+			default:
+				log.Warnf("Error checking swap %v for match %v: %v", contract, mid, err)
+			}
+		}
+		return state
+	}
+
+	return &MatchState{
+		MatchID:   mid,
+		Status:    match.status().String(),
+		Base:      match.Maker.Base(),
+		Quote:     match.Maker.Quote(),
+		Quantity:  match.Quantity,
+		Rate:      match.Rate,
+		MatchTime: match.matchTime,
+		Maker:     swapState(match.Maker, match.makerStatus),
+		Taker:     swapState(match.Taker, match.takerStatus),
+	}, nil
+}
+
+// ReconcileRefundedMatch revokes a match that is stuck after one or both of
+// the parties' swaps were refunded. This is a last resort for matches that the
+// Swapper failed to revoke. The match must be in MakerSwapCast or
+// TakerSwapCast, and the refund coin ID, a transaction input, must be
+// provided for each known swap contract. Before the match is revoked, each
+// contract's lock time must have passed, and the swap asset's backend must
+// verify that the refund spends the contract via the refund path, and that
+// the refund is confirmed. The match is revoked without penalty to either
+// party.
+func (s *Swapper) ReconcileRefundedMatch(mid order.MatchID, makerRefund, takerRefund []byte) error {
+	match, err := s.activeMatch(mid)
+	if err != nil {
+		return err
+	}
+	status := match.status()
+	if status != order.MakerSwapCast && status != order.TakerSwapCast {
+		return fmt.Errorf("match %v is in status %v, not %v or %v", mid, status,
+			order.MakerSwapCast, order.TakerSwapCast)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), adminCheckTimeout)
+	defer cancel()
+	now := time.Now()
+
+	verifyRefund := func(party string, ss *swapStatus, refundID []byte) error {
+		ss.mtx.RLock()
+		contract := ss.swap
+		ss.mtx.RUnlock()
+		if contract == nil {
+			if len(refundID) > 0 {
+				return fmt.Errorf("refund provided for the %s, who has no known swap", party)
+			}
+			return nil
+		}
+		if len(refundID) == 0 {
+			return fmt.Errorf("no refund provided for the %s's swap %v", party, contract)
+		}
+		if !contract.LockTime.Before(now) {
+			return fmt.Errorf("the %s's swap %v does not expire until %v", party, contract, contract.LockTime)
+		}
+		a := s.coins[ss.swapAsset]
+		verifier, is := a.Backend.(asset.RefundVerifier)
+		if !is {
+			return fmt.Errorf("%s backend cannot verify refunds", a.Symbol)
+		}
+		refund, err := verifier.Refund(refundID, contract.ID(), contract.ContractData)
+		if err != nil {
+			return fmt.Errorf("error verifying the refund of the %s's swap %v: %w", party, contract, err)
+		}
+		confs, err := refund.Confirmations(ctx)
+		if err != nil {
+			return fmt.Errorf("error getting the confirmations of the %s's refund %v: %w", party, refund, err)
+		}
+		if confs < 1 {
+			return fmt.Errorf("the %s's refund %v is not confirmed", party, refund)
+		}
+		log.Infof("Verified the %s's refund %v of swap %v for match %v", party, refund, contract, mid)
+		return nil
+	}
+	if err := verifyRefund("maker", match.makerStatus, makerRefund); err != nil {
+		return err
+	}
+	if err := verifyRefund("taker", match.takerStatus, takerRefund); err != nil {
+		return err
+	}
+
+	if err := s.deleteIfUnchanged(match, status); err != nil {
+		return err
+	}
+	log.Warnf("Revoking match %v in status %v, which was reconciled as refunded by the operator", mid, status)
+	s.failMatch(match, false)
+	return nil
+}
+
+// CancelMatch revokes a match for which neither party has broadcast a swap.
+// The match must be in NewlyMatched, and the Swapper must not be waiting for
+// the maker's swap transaction, so that a swap that was reported by the maker
+// cannot be orphaned. The match is revoked without penalty to either party.
+func (s *Swapper) CancelMatch(mid order.MatchID) error {
+	match, err := s.activeMatch(mid)
+	if err != nil {
+		return err
+	}
+	if status := match.status(); status != order.NewlyMatched {
+		return fmt.Errorf("match %v is in status %v, not %v", mid, status, order.NewlyMatched)
+	}
+	if known, _ := match.makerStatus.contractState(); known {
+		return fmt.Errorf("the maker's swap for match %v is known", mid)
+	}
+	if atomic.LoadUint32(&match.makerStatus.swapSearching) == 1 {
+		return fmt.Errorf("waiting for the maker's swap transaction for match %v", mid)
+	}
+
+	if err := s.deleteIfUnchanged(match, order.NewlyMatched); err != nil {
+		return err
+	}
+	log.Warnf("Revoking match %v, which was canceled by the operator before any swaps", mid)
+	s.failMatch(match, false)
+	return nil
+}
+
+// deleteIfUnchanged deletes the match if it is still active and in the
+// specified status, which protects against a match that progressed while the
+// operator's request was being verified.
+func (s *Swapper) deleteIfUnchanged(match *matchTracker, status order.MatchStatus) error {
+	s.matchMtx.Lock()
+	defer s.matchMtx.Unlock()
+	if s.matches[match.ID()] != match {
+		return fmt.Errorf("match %v is no longer active", match.ID())
+	}
+	if newStatus := match.status(); newStatus != status {
+		return fmt.Errorf("match %v advanced from %v to %v", match.ID(), status, newStatus)
+	}
+	s.deleteMatch(match)
+	return nil
+}
@@ -0,0 +1,82 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package swap
+
+import (
+	"sync/atomic"
+	"time"
+
+	"decred.org/dcrdex/dex/wait"
+)
+
+const (
+	// DefaultMaxConcurrentOps is the default limit on the number of coin
+	// waiters for an asset that may run their on-chain operations at once.
+	DefaultMaxConcurrentOps = 16
+	// DefaultMaxQueuedOps is the default limit on the number of pending coin
+	// waiters for an asset.
+	DefaultMaxQueuedOps = 1024
+)
+
+// opLimiter limits the concurrency of the on-chain operations of the coin
+// waiters for an asset, so that a slow backend cannot cause a pileup of
+// goroutines. The number of pending coin waiters is also limited, and new
+// operations are refused when the queue is full.
+type opLimiter struct {
+	sem      chan struct{}
+	maxQueue int64
+	// pending is the number of coin waiters that are waiting for a turn to
+	// run, running, or waiting to be retried. It is accessed atomically.
+	pending int64
+}
+
+func newOpLimiter(concurrency, maxQueue int) *opLimiter {
+	if concurrency <= 0 {
+		concurrency = DefaultMaxConcurrentOps
+	}
+	if maxQueue <= 0 {
+		maxQueue = DefaultMaxQueuedOps
+	}
+	return &opLimiter{
+		sem:      make(chan struct{}, concurrency),
+		maxQueue: int64(maxQueue),
+	}
+}
+
+// enqueue reserves a place in the queue for a new coin waiter, and reports
+// whether there was room. If enqueue returns true, the waiter must be created
+// with waiter, which releases the place when the waiter is done.
+func (l *opLimiter) enqueue() bool {
+	if atomic.AddInt64(&l.pending, 1) > l.maxQueue {
+		atomic.AddInt64(&l.pending, -1)
+		return false
+	}
+	return true
+}
+
+// waiter creates a coin waiter for which each try waits for one of the
+// limited turns to run.
+func (l *opLimiter) waiter(expiration time.Time, try func() wait.TryDirective, expire func()) *wait.Waiter {
+	return &wait.Waiter{
+		Expiration: expiration,
+		TryFunc: func() wait.TryDirective {
+			l.sem <- struct{}{}
+			directive := try()
+			<-l.sem
+			if directive == wait.DontTryAgain {
+				atomic.AddInt64(&l.pending, -1)
+			}
+			return directive
+		},
+		ExpireFunc: func() {
+			atomic.AddInt64(&l.pending, -1)
+			expire()
+		},
+	}
+}
+
+// depth returns the number of pending coin waiters.
+func (l *opLimiter) depth() int {
+	return int(atomic.LoadInt64(&l.pending))
+}
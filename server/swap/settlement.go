@@ -0,0 +1,164 @@
+// This code is available on the terms of the project LICENSE.md file,
+// also available online at https://blueoakcouncil.org/license/1.0.0.
+
+package swap
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// settlementWindow is the number of each asset's most recent settlement
+	// times in the moving average.
+	settlementWindow = 20
+	// minSettlementSamples is the number of samples required before the moving
+	// average is evaluated against the threshold, so that a single slow swap
+	// does not raise an alert.
+	minSettlementSamples = 5
+)
+
+// SettlementStats are the recent times to settle swaps on an asset's chain.
+// Times are in milliseconds.
+type SettlementStats struct {
+	// AverageMS is the moving average settlement time.
+	AverageMS   int64 `json:"averageMS"`
+	ThresholdMS int64 `json:"thresholdMS"`
+	// RecentMS are the recent settlement times, oldest first.
+	RecentMS []int64 `json:"recentMS"`
+	// Slow is whether the operator has been alerted that the average exceeds
+	// the threshold.
+	Slow bool `json:"slow"`
+}
+
+// settlementAlert is a change in an asset's settlement health.
+type settlementAlert struct {
+	assetID   uint32
+	average   time.Duration
+	threshold time.Duration
+	// samples are the recent settlement times, oldest first.
+	samples []time.Duration
+	// recovered is true if the average has dropped back below the threshold.
+	recovered bool
+}
+
+// recordSettlement records the settlement time of a swap on the asset's chain,
+// and alerts the operator if the asset's average settlement time crosses its
+// threshold.
+func (s *Swapper) recordSettlement(assetID uint32, dur time.Duration) {
+	alert := s.settlements.record(assetID, dur)
+	if alert == nil {
+		return
+	}
+	symbol := s.coins[assetID].Symbol
+	if alert.recovered {
+		log.Infof("Average %s swap settlement time of %v is back below the threshold of %v",
+			symbol, alert.average, alert.threshold)
+		return
+	}
+	log.Warnf("Average %s swap settlement time of %v exceeds the threshold of %v, which may indicate "+
+		"chain problems. Recent settlement times: %v", symbol, alert.average, alert.threshold, alert.samples)
+}
+
+// SettlementStats returns the recent swap settlement times on the asset's
+// chain, or nil if the asset's settlement times are not monitored. A Slow
+// result means the operator has been alerted of slow settlement.
+func (s *Swapper) SettlementStats(assetID uint32) *SettlementStats {
+	return s.settlements.stats(assetID)
+}
+
+// settlementMonitor tracks the moving average of the time to settle swaps on
+// each asset's chain, measured from the match to the redemption of the swap
+// on that chain.
+type settlementMonitor struct {
+	thresholds map[uint32]time.Duration
+
+	mtx      sync.Mutex
+	samples  map[uint32][]time.Duration
+	alerting map[uint32]bool
+}
+
+// newSettlementMonitor is the constructor for a settlementMonitor. Assets
+// without a positive threshold are not monitored.
+func newSettlementMonitor(thresholds map[uint32]time.Duration) *settlementMonitor {
+	m := &settlementMonitor{
+		thresholds: make(map[uint32]time.Duration, len(thresholds)),
+		samples:    make(map[uint32][]time.Duration),
+		alerting:   make(map[uint32]bool),
+	}
+	for assetID, threshold := range thresholds {
+		if threshold > 0 {
+			m.thresholds[assetID] = threshold
+		}
+	}
+	return m
+}
+
+// record adds a settlement time for the asset. A non-nil settlementAlert is
+// returned when the asset's moving average crosses its threshold in either
+// direction.
+func (m *settlementMonitor) record(assetID uint32, dur time.Duration) *settlementAlert {
+	threshold, monitored := m.thresholds[assetID]
+	if !monitored {
+		return nil
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	samples := append(m.samples[assetID], dur)
+	if len(samples) > settlementWindow {
+		samples = samples[len(samples)-settlementWindow:]
+	}
+	m.samples[assetID] = samples
+	if len(samples) < minSettlementSamples {
+		return nil
+	}
+
+	avg := averageDuration(samples)
+	slow := avg > threshold
+	if slow == m.alerting[assetID] {
+		return nil
+	}
+	m.alerting[assetID] = slow
+	return &settlementAlert{
+		assetID:   assetID,
+		average:   avg,
+		threshold: threshold,
+		samples:   append([]time.Duration(nil), samples...),
+		recovered: !slow,
+	}
+}
+
+// stats returns the SettlementStats for the asset, or nil if the asset is not
+// monitored.
+func (m *settlementMonitor) stats(assetID uint32) *SettlementStats {
+	threshold, monitored := m.thresholds[assetID]
+	if !monitored {
+		return nil
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	samples := m.samples[assetID]
+	stats := &SettlementStats{
+		ThresholdMS: threshold.Milliseconds(),
+		RecentMS:    make([]int64, 0, len(samples)),
+		Slow:        m.alerting[assetID],
+	}
+	for _, d := range samples {
+		stats.RecentMS = append(stats.RecentMS, d.Milliseconds())
+	}
+	if len(samples) > 0 {
+		stats.AverageMS = averageDuration(samples).Milliseconds()
+	}
+	return stats
+}
+
+// averageDuration is the mean of the non-empty durations.
+func averageDuration(durs []time.Duration) time.Duration {
+	var sum time.Duration
+	for _, d := range durs {
+		sum += d
+	}
+	return sum / time.Duration(len(durs))
+}
@@ -92,6 +92,15 @@ type swapStatus struct {
 	swap     *asset.Contract
 	// The time that the transaction receives its SwapConf'th confirmation.
 	swapConfirmed time.Time
+	// The time that the transaction reaches the swap asset's ReorgDepth, after
+	// which it is no longer re-evaluated when there is a reorg.
+	swapFinal time.Time
+	// finalityConfs is the number of confirmations of a confirmed swap when it
+	// was last checked, and finalityBlocks is the number of blocks seen since.
+	// The swap is not checked again until it could have reached ReorgDepth,
+	// unless there is a reorg.
+	finalityConfs  int64
+	finalityBlocks int64
 	// The time that the swap coordinator sees the user's redemption
 	// transaction.
 	redeemTime time.Time
@@ -101,8 +110,8 @@ type swapStatus struct {
 // String satisfies the Stringer interface for pretty printing. The swapStatus
 // RWMutex should be held for reads when using.
 func (ss *swapStatus) String() string {
-	return fmt.Sprintf("swapAsset: %d, redeemAsset: %d, swapTime: %v, swap: %v, swapConfirmed: %v, redeemTime: %v, redemption: %v",
-		ss.swapAsset, ss.redeemAsset, ss.swapTime, ss.swap, ss.swapConfirmed, ss.redeemTime, ss.redemption)
+	return fmt.Sprintf("swapAsset: %d, redeemAsset: %d, swapTime: %v, swap: %v, swapConfirmed: %v, swapFinal: %v, redeemTime: %v, redemption: %v",
+		ss.swapAsset, ss.redeemAsset, ss.swapTime, ss.swap, ss.swapConfirmed, ss.swapFinal, ss.redeemTime, ss.redemption)
 }
 
 func (ss *swapStatus) startSwapSearch() bool {
@@ -164,6 +173,9 @@ type blockNotification struct {
 	time    time.Time
 	assetID uint32
 	err     error
+	// reorg is true if any of the block updates relayed since the last
+	// notification for the asset reported a reorg.
+	reorg bool
 }
 
 // A stepActor is a structure holding information about one party of a match.
@@ -214,6 +226,15 @@ type stepInformation struct {
 type SwapperAsset struct {
 	*asset.BackedAsset
 	Locker coinlock.CoinLocker // should be *coinlock.AssetCoinLocker
+	// MaxSettlementTime is the moving average time from match to redemption of
+	// swaps on this asset's chain above which the operator is alerted. Zero
+	// disables the alert.
+	MaxSettlementTime time.Duration
+	// ReorgDepth is the number of confirmations beyond which a swap is
+	// considered irreversible. Swaps that have reached SwapConf but not
+	// ReorgDepth are re-evaluated when the asset reports a reorg. If less than
+	// SwapConf, SwapConf is used.
+	ReorgDepth uint32
 }
 
 // Swapper handles order matches by handling authentication and inter-party
@@ -245,6 +266,10 @@ type Swapper struct {
 	lockTimeMaker time.Duration
 	// latencyQ is a queue for coin waiters to deal with network latency.
 	latencyQ *wait.TaperingTickerQueue
+	// limiters limit the concurrency of the coin waiters for each asset.
+	limiters map[uint32]*opLimiter
+	// settlements monitors the settlement times of each asset.
+	settlements *settlementMonitor
 
 	// handlerMtx should be read-locked for the duration of the comms route
 	// handlers (handleInit and handleRedeem) and Negotiate. This blocks
@@ -285,6 +310,14 @@ type Config struct {
 	// SwapDone registers a match with the DEX manager (or other consumer) for a
 	// given order as being finished.
 	SwapDone func(oid order.Order, match *order.Match, fail bool)
+	// MaxConcurrentOps is the maximum number of the coin waiters for each
+	// asset that may query the asset's backend at once. Zero means
+	// DefaultMaxConcurrentOps.
+	MaxConcurrentOps int
+	// MaxQueuedOps is the maximum number of pending coin waiters for each
+	// asset. Init and redeem requests that would exceed it are refused with a
+	// TryAgainLaterError. Zero means DefaultMaxQueuedOps.
+	MaxQueuedOps int
 }
 
 // NewSwapper is a constructor for a Swapper.
@@ -296,7 +329,14 @@ func NewSwapper(cfg *Config) (*Swapper, error) {
 	}
 
 	acctMatches := make(map[uint32]map[string]map[order.MatchID]*matchTracker)
+	settlementThresholds := make(map[uint32]time.Duration, len(cfg.Assets))
+	limiters := make(map[uint32]*opLimiter, len(cfg.Assets))
 	for _, a := range cfg.Assets {
+		limiters[a.ID] = newOpLimiter(cfg.MaxConcurrentOps, cfg.MaxQueuedOps)
+		if a.ReorgDepth < a.SwapConf {
+			a.ReorgDepth = a.SwapConf
+		}
+		settlementThresholds[a.ID] = a.MaxSettlementTime
 		if _, ok := a.Backend.(asset.AccountBalancer); ok {
 			acctMatches[a.ID] = make(map[string]map[order.MatchID]*matchTracker)
 		}
@@ -309,6 +349,7 @@ func NewSwapper(cfg *Config) (*Swapper, error) {
 		authMgr:          authMgr,
 		swapDone:         cfg.SwapDone,
 		latencyQ:         wait.NewTaperingTickerQueue(fastRecheckInterval, taperedRecheckInterval),
+		limiters:         limiters,
 		matches:          make(map[order.MatchID]*matchTracker),
 		userMatches:      make(map[account.AccountID]map[order.MatchID]*matchTracker),
 		acctMatches:      acctMatches,
@@ -316,6 +357,7 @@ func NewSwapper(cfg *Config) (*Swapper, error) {
 		txWaitExpiration: cfg.TxWaitExpiration,
 		lockTimeTaker:    cfg.LockTimeTaker,
 		lockTimeMaker:    cfg.LockTimeMaker,
+		settlements:      newSettlementMonitor(settlementThresholds),
 	}
 
 	// Ensure txWaitExpiration is not greater than broadcast timeout setting.
@@ -521,6 +563,13 @@ func (s *Swapper) AccountStats(acctAddr string, assetID uint32) (qty, swaps uint
 	return stats.qty, stats.swaps, stats.redeems
 }
 
+// ActiveSwaps returns the number of matches that have not yet been settled.
+func (s *Swapper) ActiveSwaps() int {
+	s.matchMtx.RLock()
+	defer s.matchMtx.RUnlock()
+	return len(s.matches)
+}
+
 // ChainsSynced will return true if both specified asset's backends are synced.
 func (s *Swapper) ChainsSynced(base, quote uint32) (bool, error) {
 	b, found := s.coins[base]
@@ -609,6 +658,10 @@ func (s *Swapper) restoreActiveSwaps(allowPartial bool) error {
 				// We don't record the time at which we saw the block that got
 				// the swap to SwapConf, so give the user extra time.
 				ss.swapConfirmed = time.Now().UTC()
+				ss.finalityConfs = swapConfs
+				if swapConfs >= int64(swapAsset.ReorgDepth) {
+					ss.swapFinal = ss.swapConfirmed
+				}
 			}
 		}
 
@@ -790,6 +843,9 @@ func (s *Swapper) Run(ctx context.Context) {
 		wgHelpers.Add(1)
 		go func() {
 			defer wgHelpers.Done()
+			// reorg is set if any block update reports a reorg, and cleared
+			// when a notification is sent.
+			var reorg bool
 			for {
 				select {
 				case blk, ok := <-blockSource:
@@ -797,6 +853,9 @@ func (s *Swapper) Run(ctx context.Context) {
 						log.Errorf("Asset %d has closed the block channel.", assetID)
 						return
 					}
+					if blk.Reorg {
+						reorg = true
+					}
 
 					select {
 					case errIn <- blk.Err:
@@ -814,7 +873,11 @@ func (s *Swapper) Run(ctx context.Context) {
 						time:    time.Now().UTC(),
 						assetID: assetID,
 						err:     blkErr,
+						reorg:   reorg && blkErr == nil,
 					}:
+						if blkErr == nil {
+							reorg = false
+						}
 					}
 
 				case <-ctxHelpers.Done():
@@ -961,16 +1024,67 @@ func (s *Swapper) tryConfirmSwap(ctx context.Context, status *swapStatus, confTi
 		return true
 	}
 
-	swapConf := s.coins[status.swapAsset].SwapConf // swapStatus exists, therefore swapAsset is in the map
-	if confs >= int64(swapConf) {
+	swapAsset := s.coins[status.swapAsset] // swapStatus exists, therefore swapAsset is in the map
+	if confs >= int64(swapAsset.SwapConf) {
 		log.Debugf("Swap %v (%s) has reached %d confirmations (%d required)",
-			status.swap, dex.BipIDSymbol(status.swapAsset), confs, swapConf)
+			status.swap, dex.BipIDSymbol(status.swapAsset), confs, swapAsset.SwapConf)
 		status.swapConfirmed = confTime.UTC()
+		status.finalityConfs, status.finalityBlocks = confs, 0
+		if confs >= int64(swapAsset.ReorgDepth) {
+			status.swapFinal = status.swapConfirmed
+		}
 		final = true
 	}
 	return
 }
 
+// checkSwapFinality checks the confirmations of a swap that has reached
+// SwapConf but not the asset's ReorgDepth. Once the swap reaches ReorgDepth, it
+// is final and is not checked again. Without a reorg, confirmations are only
+// looked up once enough blocks have been seen for the swap to reach
+// ReorgDepth. If the block notification reports a reorg that left the swap
+// with fewer than SwapConf confirmations, the swap is no longer considered
+// confirmed, and it must reach SwapConf again before the counterparty is
+// expected to act.
+func (s *Swapper) checkSwapFinality(ctx context.Context, status *swapStatus, block *blockNotification) {
+	swapAsset := s.coins[status.swapAsset]
+	status.mtx.Lock()
+	confirmed, final := !status.swapConfirmed.IsZero(), !status.swapFinal.IsZero()
+	if !confirmed || final {
+		status.mtx.Unlock()
+		return
+	}
+	status.finalityBlocks++
+	due := block.reorg || status.finalityConfs+status.finalityBlocks >= int64(swapAsset.ReorgDepth)
+	status.mtx.Unlock()
+	if !due {
+		return
+	}
+
+	confs, err := status.swap.Confirmations(ctx)
+	if err != nil {
+		if !errors.Is(err, asset.CoinNotFoundError) {
+			log.Warnf("Unable to get confirmations for swap tx %v: %v", status.swap.TxID(), err)
+			return
+		}
+		confs = 0 // removed by the reorg
+	}
+
+	status.mtx.Lock()
+	defer status.mtx.Unlock()
+	status.finalityConfs, status.finalityBlocks = confs, 0
+	switch {
+	case confs >= int64(swapAsset.ReorgDepth):
+		log.Debugf("Swap %v (%s) has reached the reorg depth of %d confirmations",
+			status.swap, dex.BipIDSymbol(status.swapAsset), swapAsset.ReorgDepth)
+		status.swapFinal = block.time.UTC()
+	case block.reorg && confs < int64(swapAsset.SwapConf):
+		log.Warnf("Swap %v (%s) has %d confirmations after a reorg, fewer than the %d required",
+			status.swap, dex.BipIDSymbol(status.swapAsset), confs, swapAsset.SwapConf)
+		status.swapConfirmed = time.Time{}
+	}
+}
+
 func (s *Swapper) matchSlice() []*matchTracker {
 	s.matchMtx.RLock()
 	defer s.matchMtx.RUnlock()
@@ -986,41 +1100,52 @@ func (s *Swapper) matchSlice() []*matchTracker {
 // requisite number of confirmations, the next-to-act has only duration
 // (Swapper).bTimeout to broadcast the next transaction in the settlement
 // sequence. The timeout is not evaluated here, but in (Swapper).checkInaction.
-// This method simply sets swapConfirmed in the last actor's swapStatus.
+// This method simply sets swapConfirmed in the last actor's swapStatus. Swaps
+// on the block's asset that have not reached the asset's ReorgDepth are also
+// re-evaluated with checkSwapFinality.
 func (s *Swapper) processBlock(ctx context.Context, block *blockNotification) {
 	for _, match := range s.matchSlice() {
 		// If it's neither of the match assets, nothing to do.
 		if match.makerStatus.swapAsset != block.assetID &&
 			match.takerStatus.swapAsset != block.assetID {
-			return
+			continue
 		}
+		s.processMatchBlock(ctx, match, block)
+	}
+}
 
-		// Lock the matchTracker so the following checks and updates are atomic
-		// with respect to Status.
-		match.mtx.RLock()
-		defer match.mtx.RUnlock()
+func (s *Swapper) processMatchBlock(ctx context.Context, match *matchTracker, block *blockNotification) {
+	// Lock the matchTracker so the following checks and updates are atomic
+	// with respect to Status.
+	match.mtx.RLock()
+	defer match.mtx.RUnlock()
 
-		switch match.Status {
-		case order.MakerSwapCast:
-			if match.makerStatus.swapAsset != block.assetID {
-				break
-			}
-			// If the maker has broadcast their transaction, the taker's
-			// broadcast timeout starts once the maker's swap has SwapConf
-			// confs.
-			if s.tryConfirmSwap(ctx, match.makerStatus, block.time) {
-				s.unlockOrderCoins(match.Maker)
-			}
-		case order.TakerSwapCast:
-			if match.takerStatus.swapAsset != block.assetID {
-				break
-			}
-			// If the taker has broadcast their transaction, the maker's
-			// broadcast timeout (for redemption) starts once the taker's swap
-			// has SwapConf confs.
-			if s.tryConfirmSwap(ctx, match.takerStatus, block.time) {
-				s.unlockOrderCoins(match.Taker)
-			}
+	for _, status := range []*swapStatus{match.makerStatus, match.takerStatus} {
+		if status.swapAsset == block.assetID {
+			s.checkSwapFinality(ctx, status, block)
+		}
+	}
+
+	switch match.Status {
+	case order.MakerSwapCast:
+		if match.makerStatus.swapAsset != block.assetID {
+			break
+		}
+		// If the maker has broadcast their transaction, the taker's
+		// broadcast timeout starts once the maker's swap has SwapConf
+		// confs.
+		if s.tryConfirmSwap(ctx, match.makerStatus, block.time) {
+			s.unlockOrderCoins(match.Maker)
+		}
+	case order.TakerSwapCast:
+		if match.takerStatus.swapAsset != block.assetID {
+			break
+		}
+		// If the taker has broadcast their transaction, the maker's
+		// broadcast timeout (for redemption) starts once the taker's swap
+		// has SwapConf confs.
+		if s.tryConfirmSwap(ctx, match.takerStatus, block.time) {
+			s.unlockOrderCoins(match.Taker)
 		}
 	}
 }
@@ -1557,15 +1682,15 @@ func (s *Swapper) processInit(msg *msgjson.Message, params *msgjson.Init, stepIn
 		reqFeeRate = stepInfo.match.FeeRateBase
 	}
 
-	if !chain.ValidateFeeRate(contract.Coin, reqFeeRate) {
+	if err := chain.ValidateFeeRate(contract.Coin, reqFeeRate); err != nil {
 		confs := swapConfs()
 		if confs < 1 {
 			actor.status.endSwapSearch() // allow client retry even before notifying him
-			s.respondError(msg.ID, actor.user, msgjson.ContractError, "low tx fee")
+			s.respondError(msg.ID, actor.user, msgjson.ContractError, "low tx fee: "+err.Error())
 			return wait.DontTryAgain
 		}
-		log.Infof("Swap txn %v (%s) with low fee rate (%v required), accepted with %d confirmations.",
-			contract, stepInfo.asset.Symbol, reqFeeRate, confs)
+		log.Infof("Swap txn %v (%s) with low fee rate, accepted with %d confirmations: %v",
+			contract, stepInfo.asset.Symbol, confs, err)
 	}
 	if contract.SwapAddress != counterParty.order.Trade().SwapAddress() {
 		actor.status.endSwapSearch() // allow client retry even before notifying him
@@ -1831,6 +1956,10 @@ func (s *Swapper) processRedeem(msg *msgjson.Message, params *msgjson.Redeem, st
 		// Neither party's fault. Continue.
 	}
 
+	// The actor redeemed the counterparty's swap on the counterparty's swap
+	// asset chain.
+	s.recordSettlement(counterParty.swapAsset, redeemTime.Sub(match.matchTime))
+
 	// Credit the user for completing the swap, adjusting the user's score.
 	if actor.user != counterParty.user {
 		s.authMgr.SwapSuccess(actor.user, db.MatchID(match.Match), match.Quantity, redeemTime) // maybe call this in swapDone callback
@@ -1997,14 +2126,19 @@ func (s *Swapper) handleInit(user account.AccountID, msg *msgjson.Message) *msgj
 		expireTime, time.Until(expireTime), makerTaker(stepInfo.actor.isMaker),
 		stepInfo.step, matchID, coinStr, stepInfo.asset.Symbol)
 
+	limiter := s.limiters[stepInfo.asset.ID]
+	if !limiter.enqueue() {
+		stepInfo.actor.status.endSwapSearch() // not gonna start the search
+		return s.queueFullError(stepInfo.asset)
+	}
+
 	// Since we have to consider broadcast latency of the asset's network, run
 	// this as a coin waiter.
-	s.latencyQ.Wait(&wait.Waiter{
-		Expiration: expireTime,
-		TryFunc: func() wait.TryDirective {
+	s.latencyQ.Wait(limiter.waiter(expireTime,
+		func() wait.TryDirective {
 			return s.processInit(msg, params, stepInfo)
 		},
-		ExpireFunc: func() {
+		func() {
 			stepInfo.actor.status.endSwapSearch() // allow init retries
 			// NOTE: We may consider a shorter expire time so the client can
 			// receive warning that there may be node or wallet connectivity
@@ -2012,7 +2146,7 @@ func (s *Swapper) handleInit(user account.AccountID, msg *msgjson.Message) *msgj
 			s.respondError(msg.ID, user, msgjson.TransactionUndiscovered,
 				fmt.Sprintf("failed to find contract coin %v", coinStr))
 		},
-	})
+	))
 	return nil
 }
 
@@ -2100,13 +2234,18 @@ func (s *Swapper) handleRedeem(user account.AccountID, msg *msgjson.Message) *ms
 		expireTime, time.Until(expireTime), makerTaker(stepInfo.actor.isMaker),
 		stepInfo.step, matchID, coinStr, stepInfo.asset.Symbol)
 
+	limiter := s.limiters[stepInfo.asset.ID]
+	if !limiter.enqueue() {
+		stepInfo.actor.status.endRedeemSearch() // not gonna start the search
+		return s.queueFullError(stepInfo.asset)
+	}
+
 	// Since we have to consider latency, run this as a coin waiter.
-	s.latencyQ.Wait(&wait.Waiter{
-		Expiration: expireTime,
-		TryFunc: func() wait.TryDirective {
+	s.latencyQ.Wait(limiter.waiter(expireTime,
+		func() wait.TryDirective {
 			return s.processRedeem(msg, params, stepInfo)
 		},
-		ExpireFunc: func() {
+		func() {
 			stepInfo.actor.status.endRedeemSearch()
 			// NOTE: We may consider a shorter expire time so the client can
 			// receive warning that there may be node or wallet connectivity
@@ -2114,10 +2253,32 @@ func (s *Swapper) handleRedeem(user account.AccountID, msg *msgjson.Message) *ms
 			s.respondError(msg.ID, user, msgjson.TransactionUndiscovered,
 				fmt.Sprintf("failed to find redeemed coin %v", coinStr))
 		},
-	})
+	))
 	return nil
 }
 
+// queueFullError is the error for an init or redeem request that is refused
+// because the asset's queue of coin waiters is full.
+func (s *Swapper) queueFullError(a *asset.BackedAsset) *msgjson.Error {
+	log.Warnf("Refusing a request for asset %s. The coin waiter queue is full with %d waiters.",
+		a.Symbol, s.limiters[a.ID].depth())
+	return &msgjson.Error{
+		Code:    msgjson.TryAgainLaterError,
+		Message: fmt.Sprintf("too many pending %s transaction searches. Try again later.", a.Symbol),
+	}
+}
+
+// OpQueueDepth returns the number of pending coin waiters for the asset's
+// client-reported transactions, which are waiting for a turn to query the
+// asset's backend, querying it, or waiting to retry.
+func (s *Swapper) OpQueueDepth(assetID uint32) int {
+	limiter, found := s.limiters[assetID]
+	if !found {
+		return 0
+	}
+	return limiter.depth()
+}
+
 // revoke revokes the match, sending the 'revoke_match' request to each client
 // and processing the acknowledgement. Match Sigs and Status are not accessed.
 func (s *Swapper) revoke(match *matchTracker) {
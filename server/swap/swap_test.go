@@ -26,6 +26,7 @@ import (
 	"decred.org/dcrdex/dex/encode"
 	"decred.org/dcrdex/dex/msgjson"
 	"decred.org/dcrdex/dex/order"
+	"decred.org/dcrdex/dex/wait"
 	"decred.org/dcrdex/server/account"
 	"decred.org/dcrdex/server/asset"
 	"decred.org/dcrdex/server/auth"
@@ -301,6 +302,8 @@ type TStorage struct {
 	fatalErr error
 }
 
+func (ts *TStorage) Ping(context.Context) error { return nil }
+
 func (ts *TStorage) LastErr() error {
 	ts.fatalMtx.RLock()
 	defer ts.fatalMtx.RUnlock()
@@ -370,7 +373,8 @@ type TBackend struct {
 	fundsErr       error
 	redemptions    map[redeemKey]asset.Coin
 	redemptionErr  error
-	bChan          chan *asset.BlockUpdate // to trigger processBlock and eventually (after up to BroadcastTimeout) checkInaction depending on block time
+	refunds        map[redeemKey]asset.Coin // keyed by refund and contract
+	bChan          chan *asset.BlockUpdate  // to trigger processBlock and eventually (after up to BroadcastTimeout) checkInaction depending on block time
 	lbl            string
 	invalidFeeRate bool
 }
@@ -381,6 +385,7 @@ func newTBackend(lbl string) TBackend {
 		lbl:         lbl,
 		contracts:   make(map[string]*asset.Contract),
 		redemptions: make(map[redeemKey]asset.Coin),
+		refunds:     make(map[redeemKey]asset.Coin),
 		fundsErr:    asset.CoinNotFoundError,
 	}
 }
@@ -418,6 +423,20 @@ func (a *TBackend) Redemption(redemptionID, cpSwapCoinID, contractData []byte) (
 	}
 	return redeem, nil
 }
+func (a *TBackend) Refund(refundID, contractID, contractData []byte) (asset.Coin, error) {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	refund, found := a.refunds[redeemKey{string(refundID), string(contractID)}]
+	if !found {
+		return nil, asset.CoinNotFoundError
+	}
+	return refund, nil
+}
+func (a *TBackend) setRefund(refund asset.Coin, contract asset.Coin) {
+	a.mtx.Lock()
+	a.refunds[redeemKey{string(refund.ID()), string(contract.ID())}] = refund
+	a.mtx.Unlock()
+}
 func (a *TBackend) ValidateCoinID(coinID []byte) (string, error) {
 	return "", nil
 }
@@ -464,8 +483,14 @@ func (a *TBackend) setRedemption(redeem asset.Coin, cpSwap asset.Coin, resetErr
 func (*TBackend) Info() *asset.BackendInfo {
 	return &asset.BackendInfo{}
 }
-func (a *TBackend) ValidateFeeRate(asset.Coin, uint64) bool {
-	return !a.invalidFeeRate
+func (a *TBackend) ValidateFeeRate(asset.Coin, uint64) error {
+	if a.invalidFeeRate {
+		return errors.New("fee rate too low")
+	}
+	return nil
+}
+func (*TBackend) DustLimit(uint64) uint64 {
+	return 0
 }
 
 type TUTXOBackend struct {
@@ -598,8 +623,8 @@ func tNewTestRig(matchInfo *tMatch) (*testRig, func()) {
 
 	swapper, err := NewSwapper(&Config{
 		Assets: map[uint32]*SwapperAsset{
-			ABCID:  {abcAsset, abcCoinLocker},
-			XYZID:  {xyzAsset, xyzCoinLocker},
+			ABCID:  {BackedAsset: abcAsset, Locker: abcCoinLocker},
+			XYZID:  {BackedAsset: xyzAsset, Locker: xyzCoinLocker},
 			ACCTID: {BackedAsset: acctAsset}, // no coin locker for account based asset.
 		},
 		Storage:          storage,
@@ -1555,6 +1580,31 @@ func TestSwaps(t *testing.T) {
 	}
 }
 
+func TestActiveSwaps(t *testing.T) {
+	rig, cleanup := tNewTestRig(nil)
+	defer cleanup()
+
+	rig.auth.auditReq = make(chan struct{}, 1)
+	rig.auth.redeemReceived = make(chan struct{}, 2)
+	rig.auth.redemptionReq = make(chan struct{}, 2)
+
+	if n := rig.swapper.ActiveSwaps(); n != 0 {
+		t.Fatalf("expected no active swaps, got %d", n)
+	}
+
+	rig.matches = tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)
+	rig.swapper.Negotiate([]*order.MatchSet{rig.matches.matchSet})
+	if n := rig.swapper.ActiveSwaps(); n != 1 {
+		t.Fatalf("expected 1 active swap, got %d", n)
+	}
+
+	// The swap should be able to complete, e.g. while the DEX is draining.
+	testSwap(t, rig)
+	if n := rig.swapper.ActiveSwaps(); n != 0 {
+		t.Fatalf("expected no active swaps after redeem, got %d", n)
+	}
+}
+
 func TestInvalidFeeRate(t *testing.T) {
 	set := tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)
 	matchInfo := set.matchInfos[0]
@@ -2349,3 +2399,384 @@ func TestAccountTracking(t *testing.T) {
 
 // TODO: TestSwapper_restoreActiveSwaps? It would be almost entirely driven by
 // stubbed out asset backend and storage.
+
+func TestSettlementMonitor(t *testing.T) {
+	const monitored, unmonitored, disabled = 1, 2, 3
+	m := newSettlementMonitor(map[uint32]time.Duration{
+		monitored: time.Hour,
+		disabled:  0,
+	})
+	record := func(assetID uint32, dur time.Duration, n int) (alerts []*settlementAlert) {
+		for i := 0; i < n; i++ {
+			if alert := m.record(assetID, dur); alert != nil {
+				alerts = append(alerts, alert)
+			}
+		}
+		return
+	}
+
+	// Unmonitored assets are not tracked.
+	if stats := m.stats(unmonitored); stats != nil {
+		t.Fatalf("stats for unmonitored asset")
+	}
+	if alerts := record(unmonitored, 10*time.Hour, settlementWindow); len(alerts) != 0 {
+		t.Fatalf("alert for unmonitored asset")
+	}
+	if alerts := record(disabled, 10*time.Hour, settlementWindow); len(alerts) != 0 {
+		t.Fatalf("alert for asset with zero threshold")
+	}
+
+	// Slow swaps don't alert until there are enough samples.
+	if alerts := record(monitored, 10*time.Hour, minSettlementSamples-1); len(alerts) != 0 {
+		t.Fatalf("alert before minimum samples")
+	}
+	m.samples[monitored] = nil
+	if alerts := record(monitored, time.Minute, settlementWindow); len(alerts) != 0 {
+		t.Fatalf("alert for fast settlements")
+	}
+
+	// Slow swaps eventually push the average over the threshold, alerting once.
+	alerts := record(monitored, 3*time.Hour, settlementWindow)
+	if len(alerts) != 1 {
+		t.Fatalf("expected 1 alert for slow settlements, got %d", len(alerts))
+	}
+	alert := alerts[0]
+	if alert.recovered || alert.assetID != monitored || alert.threshold != time.Hour ||
+		alert.average <= time.Hour || len(alert.samples) != settlementWindow {
+		t.Fatalf("wrong alert: %+v", alert)
+	}
+	stats := m.stats(monitored)
+	if !stats.Slow || stats.AverageMS != (3 * time.Hour).Milliseconds() ||
+		stats.ThresholdMS != time.Hour.Milliseconds() || len(stats.RecentMS) != settlementWindow {
+		t.Fatalf("wrong stats while alerting: %+v", stats)
+	}
+
+	// Recovery is reported once the average drops back below the threshold.
+	alerts = record(monitored, time.Minute, settlementWindow)
+	if len(alerts) != 1 || !alerts[0].recovered || alerts[0].average > time.Hour {
+		t.Fatalf("expected a single recovery alert, got %d", len(alerts))
+	}
+	if stats = m.stats(monitored); stats.Slow || stats.AverageMS != time.Minute.Milliseconds() {
+		t.Fatalf("wrong stats after recovery: %+v", stats)
+	}
+}
+
+func TestReorgDepth(t *testing.T) {
+	set := tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)
+	matchInfo := set.matchInfos[0]
+	rig, cleanup := tNewTestRig(matchInfo)
+	defer cleanup()
+
+	rig.auth.swapReceived = make(chan struct{}, 1)
+	rig.auth.auditReq = make(chan struct{}, 1)
+
+	// Set before any blocks are processed.
+	const reorgDepth = 4
+	rig.swapper.coins[ABCID].ReorgDepth = reorgDepth
+
+	rig.swapper.Negotiate([]*order.MatchSet{set.matchSet})
+	if err := rig.ackMatch_maker(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.ackMatch_taker(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.sendSwap_maker(true); err != nil {
+		t.Fatal(err)
+	}
+	status := rig.getTracker().makerStatus
+	coin := matchInfo.db.makerSwap.coin.Coin.(*TCoin)
+
+	processBlock := func(tag string, confs int64, reorg, wantConfirmed, wantFinal bool) {
+		t.Helper()
+		coin.setConfs(confs)
+		rig.swapper.processBlock(testCtx, &blockNotification{
+			time:    time.Now(),
+			assetID: ABCID,
+			reorg:   reorg,
+		})
+		status.mtx.RLock()
+		confirmed, final := !status.swapConfirmed.IsZero(), !status.swapFinal.IsZero()
+		status.mtx.RUnlock()
+		if confirmed != wantConfirmed {
+			t.Fatalf("%s: wanted confirmed = %t, got %t", tag, wantConfirmed, confirmed)
+		}
+		if final != wantFinal {
+			t.Fatalf("%s: wanted final = %t, got %t", tag, wantFinal, final)
+		}
+	}
+
+	swapConf := int64(rig.abc.SwapConf)
+	processBlock("unconfirmed", swapConf-1, false, false, false)
+	processBlock("confirmed", swapConf, false, true, false)
+	// Without a reorg, confirmations can't drop, so a lookup that reports
+	// fewer is not acted on.
+	processBlock("no reorg", swapConf-1, false, true, false)
+	// A reorg within the reorg depth that leaves the swap with fewer than
+	// SwapConf confirmations un-confirms it.
+	processBlock("reorged", swapConf-1, true, false, false)
+	processBlock("reconfirmed", swapConf, false, true, false)
+	// A reorg that leaves enough confirmations does not.
+	processBlock("shallow reorg", swapConf, true, true, false)
+	// Without a reorg, the swap is not checked until enough blocks are seen
+	// for it to reach the reorg depth.
+	for i := swapConf + 1; i < reorgDepth; i++ {
+		processBlock("not due", reorgDepth, false, true, false)
+	}
+	processBlock("final", reorgDepth, false, true, true)
+	// Final swaps are not re-evaluated.
+	processBlock("deep reorg", 0, true, true, true)
+}
+
+func TestReconcileRefundedMatch(t *testing.T) {
+	set := tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)
+	matchInfo := set.matchInfos[0]
+	rig, cleanup := tNewTestRig(matchInfo)
+	defer cleanup()
+
+	rig.auth.swapReceived = make(chan struct{}, 1)
+	rig.auth.auditReq = make(chan struct{}, 1)
+
+	rig.swapper.Negotiate([]*order.MatchSet{set.matchSet})
+	if err := rig.ackMatch_maker(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.ackMatch_taker(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.sendSwap_maker(true); err != nil {
+		t.Fatal(err)
+	}
+	// Stop the Swapper so that the match is not revoked by the inaction
+	// checks once the swap expires, as if it were stuck.
+	cleanup()
+
+	mid := matchInfo.matchID
+	status := rig.getTracker().makerStatus
+	swapCoin := matchInfo.db.makerSwap.coin.Coin
+	refund := &TCoin{id: randBytes(36), confs: 1}
+	setLockTime := func(lockTime time.Time) {
+		status.mtx.Lock()
+		status.swap.LockTime = lockTime
+		status.mtx.Unlock()
+	}
+
+	state, err := rig.swapper.MatchState(mid)
+	if err != nil {
+		t.Fatalf("MatchState error: %v", err)
+	}
+	if state.Status != order.MakerSwapCast.String() || !bytes.Equal(state.Maker.Swap, swapCoin.ID()) || state.Taker.Swap != nil {
+		t.Fatalf("wrong match state: %+v", state)
+	}
+
+	// A pre-swap match cannot be canceled after the maker's swap.
+	if err := rig.swapper.CancelMatch(mid); err == nil {
+		t.Fatalf("no error canceling a match with a swap")
+	}
+
+	ensureFail := func(tag string, makerRefund, takerRefund []byte) {
+		t.Helper()
+		if err := rig.swapper.ReconcileRefundedMatch(mid, makerRefund, takerRefund); err == nil {
+			t.Fatalf("%s: no error", tag)
+		}
+		if rig.getTracker() == nil {
+			t.Fatalf("%s: match deleted", tag)
+		}
+	}
+
+	ensureFail("no refund", nil, nil)
+	ensureFail("not expired", refund.ID(), nil)
+	setLockTime(time.Now().Add(-time.Minute))
+	ensureFail("refund for taker without swap", refund.ID(), refund.ID())
+	ensureFail("refund not on chain", refund.ID(), nil)
+	rig.abcNode.setRefund(refund, swapCoin)
+	refund.setConfs(0)
+	ensureFail("refund unconfirmed", refund.ID(), nil)
+	refund.setConfs(1)
+
+	if err := rig.swapper.ReconcileRefundedMatch(mid, refund.ID(), nil); err != nil {
+		t.Fatalf("ReconcileRefundedMatch error: %v", err)
+	}
+	if rig.getTracker() != nil {
+		t.Fatalf("reconciled match not deleted")
+	}
+	if _, err := rig.swapper.MatchState(mid); err == nil {
+		t.Fatalf("no error for the state of a reconciled match")
+	}
+	// Neither party is penalized.
+	for _, user := range []*tUser{matchInfo.maker, matchInfo.taker} {
+		if found, rule := rig.auth.flushPenalty(user.acct); found {
+			t.Fatalf("%s penalized for rule %v", user.lbl, rule)
+		}
+	}
+}
+
+func TestCancelMatch(t *testing.T) {
+	set := tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)
+	matchInfo := set.matchInfos[0]
+	rig, cleanup := tNewTestRig(matchInfo)
+	defer cleanup()
+
+	rig.swapper.Negotiate([]*order.MatchSet{set.matchSet})
+	if err := rig.ackMatch_maker(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.ackMatch_taker(true); err != nil {
+		t.Fatal(err)
+	}
+
+	mid := matchInfo.matchID
+	if err := rig.swapper.CancelMatch(order.MatchID{0x01}); err == nil {
+		t.Fatalf("no error canceling an unknown match")
+	}
+	// A refund cannot be reconciled before any swaps.
+	if err := rig.swapper.ReconcileRefundedMatch(mid, nil, nil); err == nil {
+		t.Fatalf("no error reconciling a pre-swap match as refunded")
+	}
+
+	makerStatus := rig.getTracker().makerStatus
+	makerStatus.startSwapSearch()
+	if err := rig.swapper.CancelMatch(mid); err == nil {
+		t.Fatalf("no error canceling a match while searching for the maker's swap")
+	}
+	makerStatus.endSwapSearch()
+
+	if err := rig.swapper.CancelMatch(mid); err != nil {
+		t.Fatalf("CancelMatch error: %v", err)
+	}
+	if rig.getTracker() != nil {
+		t.Fatalf("canceled match not deleted")
+	}
+	if found, rule := rig.auth.flushPenalty(matchInfo.maker.acct); found {
+		t.Fatalf("maker penalized for rule %v", rule)
+	}
+}
+
+func TestOpLimiter(t *testing.T) {
+	const concurrency, queueSize = 3, 20
+	l := newOpLimiter(concurrency, queueSize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	q := wait.NewTaperingTickerQueue(time.Millisecond, 5*time.Millisecond)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.Run(ctx)
+	}()
+
+	waitForDepth := func(tag string, depth int) {
+		t.Helper()
+		for i := 0; l.depth() != depth; i++ {
+			if i == 100 {
+				t.Fatalf("%s: wanted queue depth %d, got %d", tag, depth, l.depth())
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// A burst of coin waiters that each need a retry all complete, with no
+	// more than concurrency running at once.
+	var running, maxRunning int64
+	var done sync.WaitGroup
+	done.Add(queueSize)
+	for i := 0; i < queueSize; i++ {
+		if !l.enqueue() {
+			t.Fatalf("queue full after %d waiters", i)
+		}
+		var tries int
+		q.Wait(l.waiter(time.Now().Add(time.Minute), func() wait.TryDirective {
+			n := atomic.AddInt64(&running, 1)
+			for {
+				max := atomic.LoadInt64(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt64(&maxRunning, max, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt64(&running, -1)
+			if tries++; tries < 2 {
+				return wait.TryAgain
+			}
+			done.Done()
+			return wait.DontTryAgain
+		}, func() {
+			t.Errorf("waiter expired")
+		}))
+	}
+	if l.enqueue() {
+		t.Fatalf("waiter queued beyond the queue size")
+	}
+	if depth := l.depth(); depth != queueSize {
+		t.Fatalf("wanted queue depth %d, got %d", queueSize, depth)
+	}
+	done.Wait()
+	if max := atomic.LoadInt64(&maxRunning); max > concurrency {
+		t.Fatalf("%d waiters ran at once, limit is %d", max, concurrency)
+	}
+	waitForDepth("burst", 0)
+
+	// Expired waiters leave the queue.
+	expired := make(chan struct{})
+	if !l.enqueue() {
+		t.Fatalf("queue full after the burst")
+	}
+	q.Wait(l.waiter(time.Now().Add(20*time.Millisecond), func() wait.TryDirective {
+		return wait.TryAgain
+	}, func() {
+		close(expired)
+	}))
+	select {
+	case <-expired:
+	case <-time.After(time.Second):
+		t.Fatalf("waiter did not expire")
+	}
+	waitForDepth("expired", 0)
+}
+
+func TestSwapQueueFull(t *testing.T) {
+	set := tPerfectLimitLimit(uint64(1e8), uint64(1e8), true)
+	matchInfo := set.matchInfos[0]
+	rig, cleanup := tNewTestRig(matchInfo)
+	defer cleanup()
+
+	rig.auth.swapReceived = make(chan struct{}, 1)
+	rig.auth.auditReq = make(chan struct{}, 1)
+	rig.swapper.Negotiate([]*order.MatchSet{set.matchSet})
+	if err := rig.ackMatch_maker(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := rig.ackMatch_taker(true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Fill the queues.
+	for assetID := range rig.swapper.limiters {
+		l := newOpLimiter(1, 1)
+		l.enqueue()
+		rig.swapper.limiters[assetID] = l
+		if depth := rig.swapper.OpQueueDepth(assetID); depth != 1 {
+			t.Fatalf("wanted queue depth 1, got %d", depth)
+		}
+	}
+	err := rig.sendSwap_maker(false)
+	if err == nil || !strings.Contains(err.Error(), strconv.Itoa(msgjson.TryAgainLaterError)) {
+		t.Fatalf("wrong error for a full queue: %v", err)
+	}
+	if err := rig.checkServerResponseFail(matchInfo.maker, msgjson.TryAgainLaterError); err != nil {
+		t.Fatal(err)
+	}
+
+	// The swap search was not started, so the swap can be sent again once
+	// the queue drains.
+	for assetID := range rig.swapper.limiters {
+		rig.swapper.limiters[assetID] = newOpLimiter(1, 1)
+	}
+	if err := rig.sendSwap_maker(true); err != nil {
+		t.Fatal(err)
+	}
+}